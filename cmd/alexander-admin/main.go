@@ -17,6 +17,7 @@ import (
 	"github.com/rs/zerolog/log"
 
 	"github.com/prn-tf/alexander-storage/internal/config"
+	"github.com/prn-tf/alexander-storage/internal/delta"
 	"github.com/prn-tf/alexander-storage/internal/domain"
 	"github.com/prn-tf/alexander-storage/internal/lock"
 	"github.com/prn-tf/alexander-storage/internal/pkg/crypto"
@@ -24,6 +25,7 @@ import (
 	"github.com/prn-tf/alexander-storage/internal/repository/postgres"
 	"github.com/prn-tf/alexander-storage/internal/repository/sqlite"
 	"github.com/prn-tf/alexander-storage/internal/service"
+	"github.com/prn-tf/alexander-storage/internal/storage"
 	"github.com/prn-tf/alexander-storage/internal/storage/filesystem"
 )
 
@@ -61,6 +63,9 @@ func main() {
 	case "encrypt":
 		handleEncryptCommand(os.Args[2:])
 
+	case "chunks":
+		handleChunksCommand(os.Args[2:])
+
 	case "help", "-h", "--help":
 		printUsage()
 
@@ -90,6 +95,7 @@ Commands:
   bucket      Manage buckets (list, delete, set-versioning)
   gc          Run garbage collection for orphan blobs
   encrypt     Encrypt existing unencrypted blobs (SSE-S3 migration)
+  chunks      Inspect or rebuild the CDC chunk index
   version     Print version information
   help        Show this help message
 
@@ -97,10 +103,13 @@ Examples:
   alexander-admin user create --username admin --email admin@example.com --admin
   alexander-admin user list
   alexander-admin accesskey create --user-id 1
+  alexander-admin accesskey create --user-id 1 --namespace-bucket shared --namespace-prefix team-a/
+  alexander-admin accesskey create --user-id 1 --rate-limit-rps 500 --rate-limit-burst 1000
   alexander-admin accesskey list --user-id 1
   alexander-admin bucket list
   alexander-admin gc run --dry-run
   alexander-admin encrypt run --batch-size 100
+  alexander-admin chunks rebuild
 
 Use "alexander-admin <command> --help" for more information about a command.`)
 }
@@ -114,6 +123,7 @@ type adminContext struct {
 	cfg       *config.Config
 	repos     *repository.Repositories
 	encryptor *crypto.Encryptor
+	chunks    delta.ChunkStore // nil for SQLite, which has no chunk index
 	dbCloser  func()
 	logger    zerolog.Logger
 }
@@ -138,6 +148,7 @@ func initAdminContext() (*adminContext, error) {
 
 	ctx := context.Background()
 	var repos *repository.Repositories
+	var chunks delta.ChunkStore
 	var dbCloser func()
 
 	if cfg.Database.Driver == "sqlite" {
@@ -168,12 +179,13 @@ func initAdminContext() (*adminContext, error) {
 		}
 
 		repos = &repository.Repositories{
-			User:      sqlite.NewUserRepository(sqliteDB),
-			AccessKey: sqlite.NewAccessKeyRepository(sqliteDB),
-			Bucket:    sqlite.NewBucketRepository(sqliteDB),
-			Object:    sqlite.NewObjectRepository(sqliteDB),
-			Blob:      sqlite.NewBlobRepository(sqliteDB),
-			Multipart: sqlite.NewMultipartRepository(sqliteDB),
+			User:       sqlite.NewUserRepository(sqliteDB),
+			AccessKey:  sqlite.NewAccessKeyRepository(sqliteDB),
+			Bucket:     sqlite.NewBucketRepository(sqliteDB),
+			Object:     sqlite.NewObjectRepository(sqliteDB),
+			Blob:       sqlite.NewBlobRepository(sqliteDB),
+			Multipart:  sqlite.NewMultipartRepository(sqliteDB),
+			DeltaChain: sqlite.NewDeltaChainRepository(sqliteDB),
 		}
 	} else {
 		// PostgreSQL mode
@@ -184,20 +196,22 @@ func initAdminContext() (*adminContext, error) {
 		dbCloser = func() { pgDB.Close() }
 
 		repos = &repository.Repositories{
-			User:      postgres.NewUserRepository(pgDB),
-			AccessKey: postgres.NewAccessKeyRepository(pgDB),
-			Bucket:    postgres.NewBucketRepository(pgDB),
-			Object:    postgres.NewObjectRepository(pgDB),
-			Blob:      postgres.NewBlobRepository(pgDB),
-			Multipart: postgres.NewMultipartRepository(pgDB),
+			User:       postgres.NewUserRepository(pgDB),
+			AccessKey:  postgres.NewAccessKeyRepository(pgDB),
+			Bucket:     postgres.NewBucketRepository(pgDB),
+			Object:     postgres.NewObjectRepository(pgDB),
+			Blob:       postgres.NewBlobRepository(pgDB),
+			Multipart:  postgres.NewMultipartRepository(pgDB),
+			DeltaChain: postgres.NewDeltaChainRepository(pgDB),
 		}
+		chunks = postgres.NewChunkRepository(pgDB)
 	}
 
 	// Initialize encryptor
-	encryptionKey, err := cfg.Auth.GetEncryptionKey()
+	encryptionKey, err := cfg.Auth.ResolveEncryptionKey(ctx)
 	if err != nil {
 		dbCloser()
-		return nil, fmt.Errorf("invalid encryption key: %w", err)
+		return nil, fmt.Errorf("failed to resolve encryption key: %w", err)
 	}
 	encryptor, err := crypto.NewEncryptor(encryptionKey)
 	if err != nil {
@@ -210,6 +224,7 @@ func initAdminContext() (*adminContext, error) {
 		cfg:       cfg,
 		repos:     repos,
 		encryptor: encryptor,
+		chunks:    chunks,
 		dbCloser:  dbCloser,
 		logger:    log.Logger,
 	}, nil
@@ -518,6 +533,10 @@ func accessKeyCreate(args []string) {
 	userID := fs.Int64("user-id", 0, "User ID (required)")
 	description := fs.String("description", "", "Description for the access key")
 	expiresDays := fs.Int("expires-days", 0, "Days until expiration (0 = never)")
+	namespaceBucket := fs.String("namespace-bucket", "", "Confine the key to --namespace-prefix in this bucket")
+	namespacePrefix := fs.String("namespace-prefix", "", "Object key prefix the key may access in --namespace-bucket")
+	rateLimitRPS := fs.Float64("rate-limit-rps", 0, "Requests per second allowed for the key (0 = server default)")
+	rateLimitBurst := fs.Int("rate-limit-burst", 0, "Request burst allowed for the key (0 = server default)")
 	jsonOutput := fs.Bool("json", false, "Output in JSON format")
 
 	if err := fs.Parse(args); err != nil {
@@ -530,6 +549,16 @@ func accessKeyCreate(args []string) {
 		os.Exit(1)
 	}
 
+	if *namespacePrefix != "" && *namespaceBucket == "" {
+		fmt.Fprintln(os.Stderr, "Error: --namespace-prefix requires --namespace-bucket")
+		os.Exit(1)
+	}
+
+	if *rateLimitRPS < 0 || *rateLimitBurst < 0 {
+		fmt.Fprintln(os.Stderr, "Error: --rate-limit-rps and --rate-limit-burst must not be negative")
+		os.Exit(1)
+	}
+
 	adminCtx, err := initAdminContext()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -546,9 +575,13 @@ func accessKeyCreate(args []string) {
 	}
 
 	output, err := iamService.CreateAccessKey(adminCtx.ctx, service.CreateAccessKeyInput{
-		UserID:      *userID,
-		Description: *description,
-		ExpiresAt:   expiresAt,
+		UserID:          *userID,
+		Description:     *description,
+		ExpiresAt:       expiresAt,
+		NamespaceBucket: *namespaceBucket,
+		NamespacePrefix: *namespacePrefix,
+		RateLimitRPS:    *rateLimitRPS,
+		RateLimitBurst:  *rateLimitBurst,
 	})
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error creating access key: %v\n", err)
@@ -563,6 +596,16 @@ func accessKeyCreate(args []string) {
 		if expiresAt != nil {
 			result["expires_at"] = expiresAt.Format(time.RFC3339)
 		}
+		if *namespaceBucket != "" {
+			result["namespace_bucket"] = *namespaceBucket
+			result["namespace_prefix"] = *namespacePrefix
+		}
+		if *rateLimitRPS > 0 {
+			result["rate_limit_rps"] = *rateLimitRPS
+		}
+		if *rateLimitBurst > 0 {
+			result["rate_limit_burst"] = *rateLimitBurst
+		}
 		jsonBytes, _ := json.MarshalIndent(result, "", "  ")
 		fmt.Println(string(jsonBytes))
 	} else {
@@ -572,6 +615,12 @@ func accessKeyCreate(args []string) {
 		if expiresAt != nil {
 			fmt.Printf("  Expires At:        %s\n", expiresAt.Format(time.RFC3339))
 		}
+		if *namespaceBucket != "" {
+			fmt.Printf("  Namespace:         %s/%s\n", *namespaceBucket, *namespacePrefix)
+		}
+		if *rateLimitRPS > 0 || *rateLimitBurst > 0 {
+			fmt.Printf("  Rate Limit:        %g req/s, burst %d (0 = server default)\n", *rateLimitRPS, *rateLimitBurst)
+		}
 		fmt.Println("\n⚠️  Save the secret access key - it won't be shown again!")
 	}
 }
@@ -694,6 +743,14 @@ func handleBucketCommand(args []string) {
 		bucketDelete(subArgs)
 	case "set-versioning":
 		bucketSetVersioning(subArgs)
+	case "set-max-versions":
+		bucketSetMaxVersions(subArgs)
+	case "set-key-case":
+		bucketSetKeyCase(subArgs)
+	case "set-content-types":
+		bucketSetContentTypes(subArgs)
+	case "set-encryption":
+		bucketSetEncryption(subArgs)
 	case "help", "-h", "--help":
 		printBucketUsage()
 	default:
@@ -710,15 +767,24 @@ Usage:
   alexander-admin bucket <subcommand> [arguments]
 
 Subcommands:
-  list            List all buckets
-  delete          Delete a bucket (must be empty)
-  set-versioning  Enable or disable versioning
+  list              List all buckets
+  delete            Delete a bucket (must be empty)
+  set-versioning    Enable or disable versioning
+  set-max-versions  Limit the number of versions kept per key
+  set-key-case      Make object keys case-sensitive or case-insensitive
+  set-content-types Restrict accepted content types and how objects are served
+  set-encryption    Require uploads to request server-side encryption
 
 Examples:
   alexander-admin bucket list
   alexander-admin bucket list --owner-id 1
   alexander-admin bucket delete --name my-bucket --force
-  alexander-admin bucket set-versioning --name my-bucket --status enabled`)
+  alexander-admin bucket set-versioning --name my-bucket --status enabled
+  alexander-admin bucket set-max-versions --name my-bucket --max 5
+  alexander-admin bucket set-key-case --name my-bucket --mode insensitive
+  alexander-admin bucket set-content-types --name my-bucket --allow 'image/*' --deny image/svg+xml --serve attachment
+  alexander-admin bucket set-content-types --name my-bucket --clear
+  alexander-admin bucket set-encryption --name my-bucket --required=true`)
 }
 
 func bucketList(args []string) {
@@ -861,6 +927,184 @@ func bucketSetVersioning(args []string) {
 	fmt.Printf("Versioning %s for bucket '%s'.\n", *status, *name)
 }
 
+func bucketSetMaxVersions(args []string) {
+	fs := flag.NewFlagSet("bucket set-max-versions", flag.ExitOnError)
+	name := fs.String("name", "", "Bucket name (required)")
+	maxVersions := fs.Int("max", -1, "Maximum versions kept per key, 0 = unlimited (required)")
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	if *name == "" || *maxVersions < 0 {
+		fmt.Fprintln(os.Stderr, "Error: --name and --max are required")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	adminCtx, err := initAdminContext()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer adminCtx.dbCloser()
+
+	bucketService := service.NewBucketService(adminCtx.repos.Bucket, adminCtx.logger)
+
+	if err := bucketService.PutBucketMaxVersions(adminCtx.ctx, service.PutBucketMaxVersionsInput{
+		Name:              *name,
+		MaxVersionsPerKey: *maxVersions,
+		OwnerID:           0, // Admin bypass
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "Error setting max versions: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *maxVersions == 0 {
+		fmt.Printf("Version limit removed for bucket '%s'.\n", *name)
+	} else {
+		fmt.Printf("Bucket '%s' keeps at most %d versions per key.\n", *name, *maxVersions)
+	}
+}
+
+func bucketSetKeyCase(args []string) {
+	fs := flag.NewFlagSet("bucket set-key-case", flag.ExitOnError)
+	name := fs.String("name", "", "Bucket name (required)")
+	mode := fs.String("mode", "", "Key matching: sensitive or insensitive (required)")
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	if *name == "" || *mode == "" {
+		fmt.Fprintln(os.Stderr, "Error: --name and --mode are required")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	*mode = strings.ToLower(*mode)
+	if *mode != "sensitive" && *mode != "insensitive" {
+		fmt.Fprintln(os.Stderr, "Error: --mode must be 'sensitive' or 'insensitive'")
+		os.Exit(1)
+	}
+
+	adminCtx, err := initAdminContext()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer adminCtx.dbCloser()
+
+	bucketService := service.NewBucketService(adminCtx.repos.Bucket, adminCtx.logger)
+
+	if err := bucketService.PutBucketKeyCase(adminCtx.ctx, service.PutBucketKeyCaseInput{
+		Name:            *name,
+		CaseInsensitive: *mode == "insensitive",
+		OwnerID:         0, // Admin bypass
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "Error setting key case: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Bucket '%s' now uses case-%s keys.\n", *name, *mode)
+}
+
+func bucketSetContentTypes(args []string) {
+	fs := flag.NewFlagSet("bucket set-content-types", flag.ExitOnError)
+	name := fs.String("name", "", "Bucket name (required)")
+	allow := fs.String("allow", "", "Comma-separated content types to accept, e.g. image/*,application/pdf")
+	deny := fs.String("deny", "", "Comma-separated content types to reject")
+	serve := fs.String("serve", "", "How objects are served: attachment or safe (default: as stored)")
+	clearPolicy := fs.Bool("clear", false, "Remove the content-type policy")
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	if *name == "" {
+		fmt.Fprintln(os.Stderr, "Error: --name is required")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	var policy *domain.ContentTypePolicy
+	if !*clearPolicy {
+		policy = &domain.ContentTypePolicy{
+			Allow: splitList(*allow),
+			Deny:  splitList(*deny),
+			Serve: domain.ContentServeMode(strings.ToLower(*serve)),
+		}
+		if err := policy.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	adminCtx, err := initAdminContext()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer adminCtx.dbCloser()
+
+	bucketService := service.NewBucketService(adminCtx.repos.Bucket, adminCtx.logger)
+
+	if err := bucketService.PutBucketContentTypePolicy(adminCtx.ctx, service.PutBucketContentTypePolicyInput{
+		Name:    *name,
+		Policy:  policy,
+		OwnerID: 0, // Admin bypass
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "Error setting content-type policy: %v\n", err)
+		os.Exit(1)
+	}
+
+	if policy == nil {
+		fmt.Printf("Bucket '%s' accepts every content type.\n", *name)
+		return
+	}
+	fmt.Printf("Bucket '%s' content-type policy updated.\n", *name)
+}
+
+func bucketSetEncryption(args []string) {
+	fs := flag.NewFlagSet("bucket set-encryption", flag.ExitOnError)
+	name := fs.String("name", "", "Bucket name (required)")
+	required := fs.Bool("required", true, "Reject uploads without x-amz-server-side-encryption or SSE-C headers")
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	if *name == "" {
+		fmt.Fprintln(os.Stderr, "Error: --name is required")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	adminCtx, err := initAdminContext()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer adminCtx.dbCloser()
+
+	bucketService := service.NewBucketService(adminCtx.repos.Bucket, adminCtx.logger)
+
+	if err := bucketService.PutBucketEncryptionRequirement(adminCtx.ctx, service.PutBucketEncryptionRequirementInput{
+		Name:     *name,
+		Required: *required,
+		OwnerID:  0, // Admin bypass
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "Error setting encryption requirement: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *required {
+		fmt.Printf("Bucket '%s' now rejects unencrypted uploads.\n", *name)
+		return
+	}
+	fmt.Printf("Bucket '%s' accepts unencrypted uploads.\n", *name)
+}
+
 // =============================================================================
 // GC Commands
 // =============================================================================
@@ -901,6 +1145,7 @@ Subcommands:
 Examples:
   alexander-admin gc run --dry-run
   alexander-admin gc run --batch-size 500
+  alexander-admin gc run --sweep-storage --dry-run
   alexander-admin gc status`)
 }
 
@@ -909,6 +1154,7 @@ func gcRun(args []string) {
 	dryRun := fs.Bool("dry-run", false, "Show what would be deleted without deleting")
 	batchSize := fs.Int("batch-size", 1000, "Maximum blobs to process per run")
 	gracePeriod := fs.Duration("grace-period", 24*time.Hour, "Grace period before deleting orphans")
+	sweepStorage := fs.Bool("sweep-storage", false, "Also delete blobs in storage that no blob record tracks")
 	jsonOutput := fs.Bool("json", false, "Output in JSON format")
 
 	if err := fs.Parse(args); err != nil {
@@ -924,7 +1170,8 @@ func gcRun(args []string) {
 
 	// Initialize storage backend
 	storageCfg := adminCtx.cfg.Storage
-	storageBackend, err := filesystem.NewStorage(filesystem.Config{
+	var storageBackend storage.Backend
+	storageBackend, err = filesystem.NewStorage(filesystem.Config{
 		DataDir: storageCfg.DataDir,
 		TempDir: storageCfg.TempDir,
 	}, adminCtx.logger)
@@ -933,21 +1180,39 @@ func gcRun(args []string) {
 		os.Exit(1)
 	}
 
+	// Deleting a version stored as a delta releases its base
+	if adminCtx.cfg.Versioning.DeltaEnabled {
+		storageBackend = storage.NewDeltaBackend(storageBackend, adminCtx.repos.DeltaChain, delta.NewFastCDCDefault(), storage.DefaultDeltaConfig(), adminCtx.logger)
+	}
+
 	// Create locker (use NoOp for CLI since we're running manually)
 	locker := lock.NewNoOpLocker()
 
+	retentionService := service.NewRetentionService(
+		adminCtx.repos.Object,
+		adminCtx.repos.Blob,
+		adminCtx.repos.Bucket,
+		adminCtx.logger,
+		service.RetentionConfig{
+			Retention: adminCtx.cfg.GC.SoftDeleteRetention,
+			BatchSize: *batchSize,
+		},
+	)
+
 	gc := service.NewGarbageCollector(
 		adminCtx.repos.Blob,
 		storageBackend,
+		retentionService,
 		locker,
 		nil, // No metrics
 		adminCtx.logger,
 		service.GCConfig{
-			Enabled:     true,
-			Interval:    1 * time.Hour,
-			GracePeriod: *gracePeriod,
-			BatchSize:   *batchSize,
-			DryRun:      *dryRun,
+			Enabled:      true,
+			Interval:     1 * time.Hour,
+			GracePeriod:  *gracePeriod,
+			BatchSize:    *batchSize,
+			DryRun:       *dryRun,
+			SweepStorage: *sweepStorage,
 		},
 	)
 
@@ -964,7 +1229,14 @@ func gcRun(args []string) {
 		fmt.Println(string(jsonBytes))
 	} else {
 		fmt.Printf("\nGC Result:\n")
+		fmt.Printf("  Objects Purged:   %d\n", result.ObjectsPurged)
 		fmt.Printf("  Blobs Deleted:    %d\n", result.BlobsDeleted)
+		if *sweepStorage {
+			fmt.Printf("  Untracked Blobs:  %d\n", result.UntrackedBlobsDeleted)
+		}
+		if result.BlobsSkipped > 0 {
+			fmt.Printf("  Blobs Skipped:    %d (still referenced)\n", result.BlobsSkipped)
+		}
 		fmt.Printf("  Bytes Freed:      %s\n", formatBytes(result.BytesFreed))
 		fmt.Printf("  Errors:           %d\n", result.Errors)
 		fmt.Printf("  Duration:         %s\n", result.Duration.Round(time.Millisecond))
@@ -1021,6 +1293,180 @@ func gcStatus(args []string) {
 	}
 }
 
+// =============================================================================
+// Chunk Index Commands
+// =============================================================================
+
+func handleChunksCommand(args []string) {
+	if len(args) == 0 {
+		printChunksUsage()
+		os.Exit(1)
+	}
+
+	subcommand := args[0]
+	subArgs := args[1:]
+
+	switch subcommand {
+	case "rebuild":
+		chunksRebuild(subArgs)
+	case "stats":
+		chunksStats(subArgs)
+	case "help", "-h", "--help":
+		printChunksUsage()
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown chunks subcommand: %s\n", subcommand)
+		printChunksUsage()
+		os.Exit(1)
+	}
+}
+
+func printChunksUsage() {
+	fmt.Println(`CDC chunk index commands (PostgreSQL only)
+
+Usage:
+  alexander-admin chunks <subcommand> [arguments]
+
+Subcommands:
+  rebuild   Discard the chunk index and re-chunk every stored blob
+  stats     Show chunk deduplication statistics
+
+Examples:
+  alexander-admin chunks rebuild
+  alexander-admin chunks rebuild --batch-size 500 --json
+  alexander-admin chunks stats`)
+}
+
+func chunksRebuild(args []string) {
+	fs := flag.NewFlagSet("chunks rebuild", flag.ExitOnError)
+	batchSize := fs.Int("batch-size", delta.DefaultRebuildBatchSize, "Number of blobs listed per batch")
+	jsonOutput := fs.Bool("json", false, "Output in JSON format")
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	adminCtx, err := initAdminContext()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer adminCtx.dbCloser()
+
+	if adminCtx.chunks == nil {
+		fmt.Fprintln(os.Stderr, "Error: the chunk index requires PostgreSQL (database.driver: postgres)")
+		os.Exit(1)
+	}
+
+	// Initialize storage backend
+	storageCfg := adminCtx.cfg.Storage
+	var storageBackend storage.Backend
+	if storageCfg.Compression.Enabled {
+		storageBackend, err = filesystem.NewCompressedStorage(filesystem.CompressedConfig{
+			DataDir:          storageCfg.DataDir,
+			TempDir:          storageCfg.TempDir,
+			Level:            storageCfg.Compression.Level,
+			SkipContentTypes: storageCfg.Compression.SkipContentTypes,
+		}, adminCtx.logger)
+	} else {
+		storageBackend, err = filesystem.NewStorage(filesystem.Config{
+			DataDir: storageCfg.DataDir,
+			TempDir: storageCfg.TempDir,
+		}, adminCtx.logger)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error initializing storage: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Versions stored as deltas are chunked from their reconstructed content
+	if adminCtx.cfg.Versioning.DeltaEnabled {
+		storageBackend = storage.NewDeltaBackend(storageBackend, adminCtx.repos.DeltaChain, delta.NewFastCDCDefault(), storage.DefaultDeltaConfig(), adminCtx.logger)
+	}
+
+	versioningCfg := adminCtx.cfg.Versioning
+	chunker := delta.NewFastCDC(delta.FastCDCConfig{
+		MinSize:            versioningCfg.MinChunkSize,
+		AvgSize:            versioningCfg.AvgChunkSize,
+		MaxSize:            versioningCfg.MaxChunkSize,
+		NormalizationLevel: delta.DefaultFastCDCConfig().NormalizationLevel,
+	})
+	indexer := delta.NewIndexer(chunker, adminCtx.chunks)
+	indexer.SetMinChunkingSize(versioningCfg.MinChunkingSize)
+	source := service.NewChunkIndexSource(adminCtx.repos.Blob, storageBackend)
+
+	if !*jsonOutput {
+		fmt.Println("Rebuilding chunk index...")
+	}
+
+	start := time.Now()
+	result, err := indexer.Rebuild(adminCtx.ctx, source, *batchSize)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error rebuilding chunk index: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *jsonOutput {
+		jsonBytes, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(jsonBytes))
+	} else {
+		fmt.Printf("\nRebuild Result:\n")
+		fmt.Printf("  Blobs Indexed:  %d\n", result.BlobsIndexed)
+		fmt.Printf("  Blobs Failed:   %d\n", result.BlobsFailed)
+		printChunkStats(result.Stats)
+		fmt.Printf("  Duration:       %s\n", time.Since(start).Round(time.Millisecond))
+	}
+
+	if result.BlobsFailed > 0 {
+		os.Exit(1)
+	}
+}
+
+func chunksStats(args []string) {
+	fs := flag.NewFlagSet("chunks stats", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Output in JSON format")
+
+	if err := fs.Parse(args); err != nil {
+		os.Exit(1)
+	}
+
+	adminCtx, err := initAdminContext()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer adminCtx.dbCloser()
+
+	if adminCtx.chunks == nil {
+		fmt.Fprintln(os.Stderr, "Error: the chunk index requires PostgreSQL (database.driver: postgres)")
+		os.Exit(1)
+	}
+
+	stats, err := adminCtx.chunks.Stats(adminCtx.ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading chunk stats: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *jsonOutput {
+		jsonBytes, _ := json.MarshalIndent(stats, "", "  ")
+		fmt.Println(string(jsonBytes))
+	} else {
+		fmt.Printf("Chunk Index:\n")
+		printChunkStats(stats)
+	}
+}
+
+func printChunkStats(stats *delta.ChunkStats) {
+	if stats == nil {
+		return
+	}
+	fmt.Printf("  Unique Chunks:  %d\n", stats.UniqueChunks)
+	fmt.Printf("  References:     %d\n", stats.TotalReferences)
+	fmt.Printf("  Stored:         %s\n", formatBytes(stats.StoredBytes))
+	fmt.Printf("  Logical:        %s\n", formatBytes(stats.LogicalBytes))
+	fmt.Printf("  Saved:          %s\n", formatBytes(stats.BytesSaved()))
+}
+
 // =============================================================================
 // Utility Functions
 // =============================================================================
@@ -1038,6 +1484,17 @@ func formatBytes(bytes int64) string {
 	return fmt.Sprintf("%.2f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
 }
 
+// splitList splits a comma-separated flag value, dropping empty entries.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func generateSecurePassword(length int) string {
 	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789!@#$%^&*"
 	b := make([]byte, length)
@@ -1241,9 +1698,8 @@ func encryptRun(args []string) {
 				continue
 			}
 
-			// Extract IV (nonce) from the ciphertext for database record
-			// The nonce is the first 12 bytes of the ciphertext
-			iv := fmt.Sprintf("%x", ciphertext[:12])
+			// Extract IV (base nonce) from the ciphertext for database record
+			iv := fmt.Sprintf("%x", crypto.SSENonce(ciphertext))
 
 			// Update database
 			if err := adminCtx.repos.Blob.UpdateEncrypted(adminCtx.ctx, blob.ContentHash, iv); err != nil {
@@ -1448,8 +1904,8 @@ func encryptRotate(args []string) {
 				continue
 			}
 
-			// Update IV in database (first 12 bytes of new ciphertext)
-			newIV := fmt.Sprintf("%x", newCiphertext[:12])
+			// Update IV in database (base nonce of the new ciphertext)
+			newIV := fmt.Sprintf("%x", crypto.SSENonce(newCiphertext))
 			if err := adminCtx.repos.Blob.UpdateEncrypted(adminCtx.ctx, blob.ContentHash, newIV); err != nil {
 				fmt.Fprintf(os.Stderr, "Error updating blob record %s: %v\n", blob.ContentHash, err)
 				totalErrors++
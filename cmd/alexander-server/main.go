@@ -12,16 +12,22 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 
 	"github.com/prn-tf/alexander-storage/internal/auth"
 	"github.com/prn-tf/alexander-storage/internal/cache/memory"
+	"github.com/prn-tf/alexander-storage/internal/cluster"
 	"github.com/prn-tf/alexander-storage/internal/config"
+	"github.com/prn-tf/alexander-storage/internal/delta"
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/events"
 	"github.com/prn-tf/alexander-storage/internal/handler"
 	"github.com/prn-tf/alexander-storage/internal/lock"
 	"github.com/prn-tf/alexander-storage/internal/metrics"
 	"github.com/prn-tf/alexander-storage/internal/middleware"
+	"github.com/prn-tf/alexander-storage/internal/notification"
 	"github.com/prn-tf/alexander-storage/internal/pkg/crypto"
 	"github.com/prn-tf/alexander-storage/internal/repository"
 	"github.com/prn-tf/alexander-storage/internal/repository/postgres"
@@ -29,6 +35,8 @@ import (
 	"github.com/prn-tf/alexander-storage/internal/service"
 	"github.com/prn-tf/alexander-storage/internal/storage"
 	"github.com/prn-tf/alexander-storage/internal/storage/filesystem"
+	"github.com/prn-tf/alexander-storage/internal/tiering"
+	"github.com/prn-tf/alexander-storage/internal/transform"
 )
 
 // Version information (set at build time)
@@ -65,8 +73,10 @@ func main() {
 	// Initialize database and repositories based on driver
 	ctx := context.Background()
 	var repos *repository.Repositories
+	var blobLocations cluster.BlobLocationRepository
 	var dbCloser func()
 	var dbHealth repository.DatabaseHealth
+	var pgDB *postgres.DB
 
 	if cfg.Database.Driver == "sqlite" {
 		// SQLite / Embedded mode
@@ -94,42 +104,70 @@ func main() {
 		dbHealth = sqliteDB
 
 		// Run migrations
-		if err := sqliteDB.Migrate(ctx); err != nil {
-			log.Fatal().Err(err).Msg("Failed to run SQLite migrations")
+		if cfg.Database.AutoMigrate {
+			if err := sqliteDB.Migrate(ctx); err != nil {
+				log.Fatal().Err(err).Msg("Failed to run SQLite migrations")
+			}
 		}
 
 		repos = &repository.Repositories{
-			User:      sqlite.NewUserRepository(sqliteDB),
-			AccessKey: sqlite.NewAccessKeyRepository(sqliteDB),
-			Bucket:    sqlite.NewBucketRepository(sqliteDB),
-			Object:    sqlite.NewObjectRepository(sqliteDB),
-			Blob:      sqlite.NewBlobRepository(sqliteDB),
-			Multipart: sqlite.NewMultipartRepository(sqliteDB),
+			User:               sqlite.NewUserRepository(sqliteDB),
+			AccessKey:          sqlite.NewAccessKeyRepository(sqliteDB),
+			Bucket:             sqlite.NewBucketRepository(sqliteDB),
+			Object:             sqlite.NewObjectRepository(sqliteDB),
+			Blob:               sqlite.NewBlobRepository(sqliteDB),
+			Multipart:          sqlite.NewMultipartRepository(sqliteDB),
+			Lifecycle:          sqlite.NewLifecycleRepository(sqliteDB),
+			BucketPolicy:       sqlite.NewBucketPolicyRepository(sqliteDB),
+			BucketCORS:         sqlite.NewBucketCORSRepository(sqliteDB),
+			BucketNotification: sqlite.NewBucketNotificationRepository(sqliteDB),
+			DeltaChain:         sqlite.NewDeltaChainRepository(sqliteDB),
 		}
 	} else {
 		// PostgreSQL mode (default)
 		log.Info().Str("driver", "postgres").Str("host", cfg.Database.Host).Msg("Using PostgreSQL database")
 
-		pgDB, err := postgres.NewDB(ctx, cfg.Database, log.Logger)
+		pgDB, err = postgres.NewDB(ctx, cfg.Database, log.Logger)
 		if err != nil {
 			log.Fatal().Err(err).Msg("Failed to connect to PostgreSQL database")
 		}
 		dbCloser = func() { pgDB.Close() }
 		dbHealth = pgDB
 
+		// Run migrations
+		if cfg.Database.AutoMigrate {
+			if err := pgDB.Migrate(ctx); err != nil {
+				log.Fatal().Err(err).Msg("Failed to run PostgreSQL migrations")
+			}
+		}
+
 		repos = &repository.Repositories{
-			User:      postgres.NewUserRepository(pgDB),
-			AccessKey: postgres.NewAccessKeyRepository(pgDB),
-			Bucket:    postgres.NewBucketRepository(pgDB),
-			Object:    postgres.NewObjectRepository(pgDB),
-			Blob:      postgres.NewBlobRepository(pgDB),
-			Multipart: postgres.NewMultipartRepository(pgDB),
+			User:               postgres.NewUserRepository(pgDB),
+			AccessKey:          postgres.NewAccessKeyRepository(pgDB),
+			Bucket:             postgres.NewBucketRepository(pgDB),
+			Object:             postgres.NewObjectRepository(pgDB),
+			Blob:               postgres.NewBlobRepository(pgDB),
+			Multipart:          postgres.NewMultipartRepository(pgDB),
+			Lifecycle:          postgres.NewLifecycleRepository(pgDB),
+			BucketPolicy:       postgres.NewBucketPolicyRepository(pgDB),
+			BucketCORS:         postgres.NewBucketCORSRepository(pgDB),
+			BucketNotification: postgres.NewBucketNotificationRepository(pgDB),
+			DeltaChain:         postgres.NewDeltaChainRepository(pgDB),
 		}
+
+		// Blob locations are shared by all cluster nodes through the database
+		blobLocations = postgres.NewBlobLocationRepository(pgDB)
 	}
 	defer dbCloser()
 
 	log.Info().Msg("Connected to database")
 
+	// Cache bucket lookups for write paths (shared so invalidation is immediate;
+	// single-instance deployments only, see config.DatabaseConfig.BucketCacheTTL)
+	if cfg.Database.BucketCacheTTL > 0 {
+		repos.Bucket = service.NewCachedBucketRepository(repos.Bucket, cfg.Database.BucketCacheTTL)
+	}
+
 	// Initialize cache and lock based on mode
 	var memCache *memory.Cache
 	var locker lock.Locker
@@ -153,9 +191,9 @@ func main() {
 	_ = memCache
 
 	// Initialize encryptor
-	encryptionKey, err := cfg.Auth.GetEncryptionKey()
+	encryptionKey, err := cfg.Auth.ResolveEncryptionKey(ctx)
 	if err != nil {
-		log.Fatal().Err(err).Msg("Invalid encryption key")
+		log.Fatal().Err(err).Msg("Failed to resolve encryption key")
 	}
 	encryptor, err := crypto.NewEncryptor(encryptionKey)
 	if err != nil {
@@ -163,7 +201,7 @@ func main() {
 	}
 
 	// Initialize storage backend
-	storageBackend, err := initStorageBackend(cfg, log.Logger)
+	storageBackend, dualWrite, deltaBackend, clusterNode, err := initStorageBackend(ctx, cfg, repos.DeltaChain, blobLocations, log.Logger)
 	if err != nil {
 		log.Fatal().Err(err).Msg("Failed to initialize storage backend")
 	}
@@ -171,14 +209,138 @@ func main() {
 	// Initialize services
 	iamService := service.NewIAMService(repos.AccessKey, repos.User, encryptor, log.Logger)
 	bucketService := service.NewBucketService(repos.Bucket, log.Logger)
+	bucketService.SetDefaultObjectOwnership(domain.ObjectOwnership(cfg.Storage.DefaultObjectOwnership))
 	objectService := service.NewObjectService(repos.Object, repos.Blob, repos.Bucket, storageBackend, locker, log.Logger)
+	objectService.SetDeleteBatchSize(cfg.Versioning.DeleteBatchSize)
+	objectService.SetSizeMismatchPolicy(service.SizeMismatchPolicy(cfg.Storage.SizeMismatchPolicy))
+	objectService.SetMaxKeyDepth(cfg.Server.MaxKeyDepth)
+	objectService.SetAllowAppend(cfg.Server.AllowAppend)
+	objectService.SetOverwriteMode(service.OverwriteMode(cfg.Versioning.OverwriteMode))
 	multipartService := service.NewMultipartService(repos.Multipart, repos.Object, repos.Blob, repos.Bucket, storageBackend, locker, log.Logger)
+	multipartService.SetSizeMismatchPolicy(service.SizeMismatchPolicy(cfg.Storage.SizeMismatchPolicy))
+	multipartService.SetMaxKeyDepth(cfg.Server.MaxKeyDepth)
+	multipartService.SetOverwriteMode(service.OverwriteMode(cfg.Versioning.OverwriteMode))
+	var deltaQueue *service.DeltaQueue
+	if deltaBackend != nil {
+		deltaQueue = service.NewDeltaQueue(deltaBackend, cfg.Versioning.DeltaWorkers, service.DefaultDeltaQueueSize, log.Logger)
+		objectService.SetDeltaQueue(deltaQueue)
+		multipartService.SetDeltaQueue(deltaQueue)
+	}
+
+	// Object events are delivered asynchronously to side-effect consumers
+	eventBus := events.NewBus(events.DefaultQueueSize, log.Logger)
+	eventBus.Subscribe("audit", events.NewAuditLogger(log.Logger))
+	objectService.SetEventBus(eventBus)
+	multipartService.SetEventBus(eventBus)
+
+	// Post-upload transforms derive artifacts from new objects off the write path
+	if cfg.Transform.Enabled {
+		var transformers []transform.Transformer
+		if cfg.Transform.ImageResize.Enabled {
+			resizer, err := transform.NewImageResizer(cfg.Transform.ImageResize.MaxDimension)
+			if err != nil {
+				log.Fatal().Err(err).Msg("Failed to initialize image resize transformer")
+			}
+			transformers = append(transformers, resizer)
+		}
+		if len(transformers) == 0 {
+			transformers = append(transformers, transform.NoOp{})
+		}
+		eventBus.Subscribe("transform", transform.NewHook(objectService, transformers, transform.Config{
+			ContentTypes:  cfg.Transform.ContentTypes,
+			MaxSourceSize: cfg.Transform.MaxSourceSize,
+		}, log.Logger))
+		log.Info().Int("transformers", len(transformers)).Msg("Post-upload transforms enabled")
+	}
+
+	// Tiering decisions need access history that survives restarts
+	var accessTracker *postgres.AccessTrackerRepository
+	if cfg.Tiering.Enabled && pgDB != nil {
+		accessTracker = postgres.NewAccessTrackerRepository(pgDB, postgres.AccessTrackerConfig{}, log.Logger)
+		objectService.SetAccessRecorder(accessTracker)
+		log.Info().Msg("Blob access tracking enabled")
+	} else if cfg.Tiering.Enabled {
+		log.Warn().Msg("Tiering needs blob access history, which requires PostgreSQL; tiering is disabled")
+	}
+
+	// Multipart state lives in the database and survives restarts; drop parts
+	// whose blobs went missing so clients re-upload them
+	if removed, err := multipartService.ReconcileParts(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to reconcile multipart upload parts")
+	} else if removed > 0 {
+		log.Warn().Int("removed", removed).Msg("Removed multipart upload parts with missing blobs")
+	}
+	retentionService := service.NewRetentionService(repos.Object, repos.Blob, repos.Bucket, log.Logger, service.RetentionConfig{
+		Retention: cfg.GC.SoftDeleteRetention,
+		BatchSize: cfg.GC.BatchSize,
+	})
 
 	// Initialize metrics
 	var m *metrics.Metrics
 	if cfg.Metrics.Enabled {
 		m = metrics.New()
+		m.SetBucketLabels(metrics.BucketLabelConfig{
+			Mode:        metrics.BucketLabelMode(cfg.Metrics.BucketLabels),
+			Buckets:     cfg.Metrics.Buckets,
+			HashBuckets: cfg.Metrics.HashBuckets,
+		})
 		log.Info().Int("port", cfg.Metrics.Port).Msg("Prometheus metrics enabled")
+		objectService.SetMetrics(m)
+		multipartService.SetMetrics(m)
+
+		uploadsCtx, stopUploads := context.WithCancel(ctx)
+		defer stopUploads()
+		go multipartService.ReportActiveUploads(uploadsCtx, 15*time.Second)
+
+		// Export connection pool statistics
+		if pgDB != nil {
+			statsCtx, stopStats := context.WithCancel(ctx)
+			defer stopStats()
+			go pgDB.ReportStats(statsCtx, 15*time.Second, func(stat *pgxpool.Stat) {
+				m.RecordDBPoolStats(
+					stat.AcquiredConns(),
+					stat.IdleConns(),
+					stat.TotalConns(),
+					stat.MaxConns(),
+					stat.EmptyAcquireCount(),
+					stat.EmptyAcquireWaitTime(),
+				)
+			})
+		}
+	}
+
+	// Lifecycle configurations are managed through the S3 API
+	lifecycleService := service.NewLifecycleService(
+		repos.Lifecycle,
+		repos.Object,
+		repos.Bucket,
+		repos.Blob,
+		locker,
+		m,
+		log.Logger,
+		service.DefaultLifecycleConfig(),
+	)
+
+	// Bucket policies are managed through the S3 API and evaluated by the authorizer
+	bucketPolicyService := service.NewBucketPolicyService(repos.BucketPolicy, repos.Bucket, log.Logger)
+
+	// CORS configurations answer browser requests from other origins
+	corsService := service.NewCORSService(repos.BucketCORS, repos.Bucket, log.Logger)
+
+	// Bucket webhooks receive object events from their own delivery queue
+	var notificationService *service.NotificationService
+	var notificationDispatcher *notification.Dispatcher
+	if cfg.Notification.Enabled {
+		notificationService = service.NewNotificationService(repos.BucketNotification, repos.Bucket, log.Logger)
+		notificationDispatcher = notification.NewDispatcher(notificationService, notification.Config{
+			QueueSize:    cfg.Notification.QueueSize,
+			Workers:      cfg.Notification.Workers,
+			MaxAttempts:  cfg.Notification.MaxAttempts,
+			Timeout:      cfg.Notification.Timeout,
+			RetryBackoff: cfg.Notification.RetryBackoff,
+			Region:       cfg.Auth.Region,
+		}, log.Logger)
+		eventBus.Subscribe("notification", notificationDispatcher)
 	}
 
 	// Initialize garbage collector
@@ -187,25 +349,42 @@ func main() {
 		gc = service.NewGarbageCollector(
 			repos.Blob,
 			storageBackend,
+			retentionService,
 			locker,
 			m,
 			log.Logger,
 			service.GCConfig{
-				Enabled:     cfg.GC.Enabled,
-				Interval:    cfg.GC.Interval,
-				GracePeriod: cfg.GC.GracePeriod,
-				BatchSize:   cfg.GC.BatchSize,
-				DryRun:      cfg.GC.DryRun,
+				Enabled:      cfg.GC.Enabled,
+				Interval:     cfg.GC.Interval,
+				GracePeriod:  cfg.GC.GracePeriod,
+				BatchSize:    cfg.GC.BatchSize,
+				DryRun:       cfg.GC.DryRun,
+				SweepStorage: cfg.GC.SweepStorage,
 			},
 		)
 		gc.Start()
 		defer gc.Stop()
+		if cfg.GC.RunWhenStorageFull {
+			objectService.SetStorageFullHandler(gc.TriggerEmergencyRun)
+			multipartService.SetStorageFullHandler(gc.TriggerEmergencyRun)
+		}
 		log.Info().
 			Dur("interval", cfg.GC.Interval).
 			Dur("grace_period", cfg.GC.GracePeriod).
+			Dur("soft_delete_retention", cfg.GC.SoftDeleteRetention).
 			Msg("Garbage collector started")
 	}
 
+	// Backfill existing blobs into the new backend of a storage migration
+	if dualWrite != nil {
+		backfill := service.NewStorageBackfill(repos.Blob, dualWrite, log.Logger, service.StorageBackfillConfig{
+			Interval:  cfg.Storage.DualWrite.BackfillInterval,
+			BatchSize: cfg.Storage.DualWrite.BackfillBatchSize,
+		})
+		backfill.Start()
+		defer backfill.Stop()
+	}
+
 	// Initialize rate limiter
 	var rateLimiter *middleware.RateLimiter
 	if cfg.RateLimit.Enabled {
@@ -215,6 +394,7 @@ func main() {
 				BurstSize:         cfg.RateLimit.BurstSize,
 				Enabled:           cfg.RateLimit.Enabled,
 				CleanupInterval:   5 * time.Minute,
+				Identify:          auth.RateLimitClient,
 			},
 			m,
 			log.Logger,
@@ -226,30 +406,115 @@ func main() {
 			Msg("Rate limiting enabled")
 	}
 
+	// Initialize list concurrency limiter (independent of the rate limiter)
+	listLimiter := middleware.NewConcurrencyLimiter("list", cfg.RateLimit.MaxConcurrentLists, m, log.Logger)
+	if listLimiter != nil {
+		log.Info().
+			Int("max_concurrent_lists", cfg.RateLimit.MaxConcurrentLists).
+			Msg("List concurrency limit enabled")
+	}
+
+	// Bound memory held by buffered request bodies (independent of the rate limiter)
+	memoryBudget := middleware.NewMemoryBudget(cfg.RateLimit.MemoryBudget, m, log.Logger)
+	if memoryBudget != nil {
+		log.Info().
+			Int64("memory_budget", cfg.RateLimit.MemoryBudget).
+			Msg("Request memory budget enabled")
+	}
+
 	// Initialize tracing middleware
 	tracing := middleware.NewTracing(m, log.Logger)
 
 	// Initialize auth middleware
 	accessKeyStore := service.NewAccessKeyStoreAdapter(iamService)
 	bucketACLChecker := service.NewBucketACLAdapter(bucketService)
+	bucketPolicyLookup := service.NewBucketPolicyAdapter(bucketPolicyService)
+	skipPaths := []string{"/health", "/healthz", "/readyz"}
+	if cfg.Metrics.Enabled {
+		skipPaths = append(skipPaths, cfg.Metrics.Path)
+	}
+	if cfg.Server.Capabilities == "public" {
+		skipPaths = append(skipPaths, handler.CapabilitiesPath)
+	}
 	authConfig := auth.Config{
-		Region:           cfg.Auth.Region,
-		Service:          cfg.Auth.Service,
-		AllowAnonymous:   false,
-		SkipPaths:        []string{"/health", "/healthz", "/readyz"},
-		BucketACLChecker: bucketACLChecker,
+		Region:             cfg.Auth.Region,
+		Service:            cfg.Auth.Service,
+		AllowAnonymous:     false,
+		SkipPaths:          skipPaths,
+		BucketACLChecker:   bucketACLChecker,
+		BucketPolicyLookup: bucketPolicyLookup,
+		AllowSignatureV2:   cfg.Auth.SignatureV2,
+	}
+	if cfg.Auth.SignatureV2 {
+		log.Warn().Msg("Signature Version 2 compatibility enabled; SigV2 is weaker than SigV4")
 	}
 	authMiddleware := handler.CreateAuthMiddleware(accessKeyStore, authConfig)
 
 	// Initialize handlers
-	bucketHandler := handler.NewBucketHandler(bucketService, log.Logger)
-	objectHandler := handler.NewObjectHandler(objectService, log.Logger)
-	multipartHandler := handler.NewMultipartHandler(multipartService, log.Logger)
+	authorizer := auth.NewDefaultAuthorizer(bucketACLChecker)
+	authorizer.SetPolicyLookup(bucketPolicyLookup)
+	authorizer.SetACLChecker(bucketACLChecker)
+	bucketHandler := handler.NewBucketHandler(bucketService, authorizer, log.Logger)
+	objectHandler := handler.NewObjectHandler(objectService, authorizer, log.Logger)
+	objectHandler.SetChecksumTrailers(cfg.Server.ChecksumTrailers)
+	multipartHandler := handler.NewMultipartHandler(multipartService, authorizer, log.Logger)
+	lifecycleHandler := handler.NewLifecycleHandler(lifecycleService, authorizer, log.Logger)
+	policyHandler := handler.NewBucketPolicyHandler(bucketPolicyService, authorizer, log.Logger)
+	corsHandler := handler.NewCORSHandler(corsService, authorizer, log.Logger)
+	var notificationHandler *handler.NotificationHandler
+	if notificationService != nil {
+		notificationHandler = handler.NewNotificationHandler(notificationService, authorizer, log.Logger)
+	}
+	batchHandler := handler.NewBatchHandler(objectService, authorizer, log.Logger)
+	var tieringAccess tiering.AccessTracker
+	if accessTracker != nil {
+		tieringAccess = accessTracker
+	}
+	adminHandler, tieringController := newAdminHandler(cfg, repos.User, tieringAccess, retentionService, objectService, clusterNode, log.Logger)
+	if tieringController != nil {
+		tieringController.SetMetrics(m)
+		if err := tieringController.Start(ctx); err != nil {
+			log.Fatal().Err(err).Msg("Failed to start tiering controller")
+		}
+		log.Info().Msg("Tiering controller started")
+	}
+
+	var signingDebug *handler.SigningDebugHandler
+	if cfg.Auth.SigningDebug {
+		signingDebug = handler.NewSigningDebugHandler(log.Logger)
+		log.Warn().Str("path", handler.SigningDebugPathPrefix).Msg("Signing debug endpoint enabled; do not use in production")
+	}
+
+	var capabilities *handler.CapabilitiesHandler
+	if cfg.Server.Capabilities != "disabled" {
+		capabilities = handler.NewCapabilitiesHandler(handler.CapabilitiesConfig{
+			// initStorageBackend only builds the plaintext filesystem backend
+			AtRestEncryption: "none",
+			Subsystems: map[string]bool{
+				"tiering":          cfg.Tiering.Enabled,
+				"cluster":          cfg.Cluster.Enabled,
+				"delta_versioning": cfg.Versioning.DeltaEnabled,
+				"transforms":       cfg.Transform.Enabled,
+				"batch_ingestion":  true,
+				"signing_debug":    cfg.Auth.SigningDebug,
+				"signature_v2":     cfg.Auth.SignatureV2,
+			},
+			Logger: log.Logger,
+		})
+	}
+
+	// Report delta/CDC activity when delta storage is enabled
+	var deltaMonitor *delta.Monitor
+	if cfg.Versioning.DeltaEnabled {
+		deltaMonitor = delta.NewMonitor(m)
+		deltaBackend.SetMonitor(deltaMonitor)
+	}
 
 	// Initialize health checker
 	healthChecker := handler.NewHealthChecker(handler.HealthCheckerConfig{
 		DatabaseChecker: dbHealth,
 		StorageBackend:  storageBackend,
+		DeltaMonitor:    deltaMonitor,
 		Logger:          log.Logger,
 		CacheTTL:        5 * time.Second,
 	})
@@ -259,28 +524,43 @@ func main() {
 		BucketHandler:    bucketHandler,
 		ObjectHandler:    objectHandler,
 		MultipartHandler: multipartHandler,
+		LifecycleHandler: lifecycleHandler,
+		PolicyHandler:    policyHandler,
+		CORSHandler:      corsHandler,
+		Notification:     notificationHandler,
+		BatchHandler:     batchHandler,
+		AdminHandler:     adminHandler,
+		SigningDebug:     signingDebug,
+		Capabilities:     capabilities,
 		HealthChecker:    healthChecker,
 		AuthMiddleware:   authMiddleware,
 		RateLimiter:      rateLimiter,
+		ListLimiter:      listLimiter,
+		MemoryBudget:     memoryBudget,
+		MaxMetaHeaders:   cfg.Server.MaxMetadataHeaders,
+		StrictQuery:      cfg.Server.StrictQueryParams,
+		BaseDomain:       cfg.Server.BaseDomain,
 		Tracing:          tracing,
 		Metrics:          m,
+		MetricsPath:      cfg.Metrics.Path,
 		Logger:           log.Logger,
 	})
 
 	// Create HTTP server
 	server := &http.Server{
-		Addr:         fmt.Sprintf(":%d", cfg.Server.Port),
-		Handler:      router.Handler(),
-		ReadTimeout:  cfg.Server.ReadTimeout,
-		WriteTimeout: cfg.Server.WriteTimeout,
-		IdleTimeout:  cfg.Server.IdleTimeout,
+		Addr:           fmt.Sprintf(":%d", cfg.Server.Port),
+		Handler:        router.Handler(),
+		ReadTimeout:    cfg.Server.ReadTimeout,
+		WriteTimeout:   cfg.Server.WriteTimeout,
+		IdleTimeout:    cfg.Server.IdleTimeout,
+		MaxHeaderBytes: cfg.Server.MaxHeaderBytes,
 	}
 
-	// Start metrics server if enabled
+	// Start a separate metrics server if enabled; the API port serves them too
 	var metricsServer *http.Server
-	if cfg.Metrics.Enabled {
+	if cfg.Metrics.Enabled && cfg.Metrics.Port > 0 {
 		metricsMux := http.NewServeMux()
-		metricsMux.Handle(cfg.Metrics.Path, metrics.Handler())
+		metricsMux.Handle(cfg.Metrics.Path, m.Handler())
 		metricsServer = &http.Server{
 			Addr:    fmt.Sprintf(":%d", cfg.Metrics.Port),
 			Handler: metricsMux,
@@ -330,15 +610,265 @@ func main() {
 		log.Error().Err(err).Msg("Server shutdown error")
 	}
 
+	// Store versions queued by the last requests as deltas
+	if deltaQueue != nil {
+		if err := deltaQueue.Close(shutdownCtx); err != nil {
+			log.Error().Err(err).Msg("Delta queue shutdown error")
+		}
+	}
+
+	// Deliver events queued by the last requests
+	if err := eventBus.Close(shutdownCtx); err != nil {
+		log.Error().Err(err).Msg("Event bus shutdown error")
+	}
+
+	// Attempt webhook deliveries queued for those events
+	if notificationDispatcher != nil {
+		if err := notificationDispatcher.Close(shutdownCtx); err != nil {
+			log.Error().Err(err).Msg("Notification dispatcher shutdown error")
+		}
+	}
+
+	// Let running tier migrations finish before their access history closes
+	if tieringController != nil {
+		tieringController.Stop()
+	}
+
+	// Write buffered blob accesses before the database closes
+	if accessTracker != nil {
+		if err := accessTracker.Close(shutdownCtx); err != nil {
+			log.Error().Err(err).Msg("Access tracker shutdown error")
+		}
+	}
+
+	// Finish background replication before leaving the cluster
+	if clusterNode != nil {
+		clusterNode.Close()
+	}
+
 	log.Info().Msg("Server stopped")
 }
 
+// newAdminHandler builds the operator API over the subsystems that are
+// running. With tiering enabled and blob access history available it also
+// creates the tiering controller behind the migrations and tiering resources;
+// the caller starts and stops it. Without a cluster the controller evaluates
+// policies but has no node to move blobs to.
+func newAdminHandler(cfg *config.Config, users handler.UserLookup, access tiering.AccessTracker, objects handler.ObjectRestorer, inspector handler.ObjectInspector, node *clusterNode, logger zerolog.Logger) (*handler.AdminHandler, *tiering.TieringController) {
+	var controller *tiering.TieringController
+	if cfg.Tiering.Enabled && access != nil {
+		var manager cluster.ClusterManager
+		var selector cluster.NodeSelector
+		if node != nil {
+			manager = node.manager
+			selector = cluster.NewCapacitySelector(node.manager)
+		}
+		controller = tiering.NewTieringController(tiering.ControllerConfig{
+			ScanInterval: cfg.Tiering.EvaluationInterval,
+		}, manager, selector, access, logger)
+	}
+
+	var adminHandler *handler.AdminHandler
+	if controller != nil {
+		adminHandler = handler.NewAdminHandler(users, controller, controller, objects, inspector, logger)
+	} else {
+		adminHandler = handler.NewAdminHandler(users, nil, nil, objects, inspector, logger)
+	}
+	if access != nil {
+		adminHandler.SetAccessTracker(access)
+	}
+	if node != nil && node.replicator != nil {
+		adminHandler.SetReplicationStatus(node.replicator)
+	}
+	return adminHandler, controller
+}
+
 // initStorageBackend initializes the storage backend based on configuration.
-func initStorageBackend(cfg *config.Config, logger zerolog.Logger) (storage.Backend, error) {
+// During a storage migration it also returns the dual-write backend, whose
+// existing blobs are backfilled in the background. When clustering is
+// enabled it also starts and returns this node's cluster membership, and new
+// blobs are replicated to other nodes.
+func initStorageBackend(ctx context.Context, cfg *config.Config, deltaChains storage.DeltaChainStore, locations cluster.BlobLocationRepository, logger zerolog.Logger) (storage.Backend, *storage.DualWriteBackend, *storage.DeltaBackend, *clusterNode, error) {
 	// For now, we only support filesystem backend
 	// TODO: Add support for other backends (S3, Azure Blob, etc.)
+	backend, err := newFilesystemBackend(cfg, cfg.Storage.DataDir, cfg.Storage.TempDir, logger)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	var dualWrite *storage.DualWriteBackend
+	if cfg.Storage.DualWrite.Enabled {
+		newBackend, err := newFilesystemBackend(cfg, cfg.Storage.DualWrite.DataDir, cfg.Storage.DualWrite.TempDir, logger)
+		if err != nil {
+			return nil, nil, nil, nil, fmt.Errorf("failed to initialize new storage backend: %w", err)
+		}
+		dualWrite = storage.NewDualWriteBackend(newBackend, backend, logger)
+		backend = dualWrite
+		logger.Warn().
+			Str("old_data_dir", cfg.Storage.DataDir).
+			Str("new_data_dir", cfg.Storage.DualWrite.DataDir).
+			Msg("Storage migration in progress: writing blobs to both backends")
+	}
+
+	if cfg.Storage.Retry.MaxAttempts > 1 {
+		backend = storage.NewRetryingBackend(backend, storage.RetryConfig{
+			MaxAttempts:    cfg.Storage.Retry.MaxAttempts,
+			InitialBackoff: cfg.Storage.Retry.InitialBackoff,
+			MaxBackoff:     cfg.Storage.Retry.MaxBackoff,
+		}, logger)
+	}
+
+	// Other nodes receive and serve blobs from the local backend; blobs
+	// written here are copied to them before the write returns
+	var node *clusterNode
+	if cfg.Cluster.Enabled {
+		node, err = startClusterNode(ctx, cfg, backend, locations, logger)
+		if err != nil {
+			return nil, nil, nil, nil, fmt.Errorf("failed to start cluster node: %w", err)
+		}
+		if node.replicator != nil {
+			backend = cluster.NewReplicatedBackend(node.replicator, logger)
+		}
+	}
+
+	// Versions stored as deltas are rebuilt below the cache, so cached
+	// reads skip the rebuild
+	var deltaBackend *storage.DeltaBackend
+	if cfg.Versioning.DeltaEnabled {
+		deltaBackend = newDeltaBackend(cfg, backend, deltaChains, logger)
+		backend = deltaBackend
+	}
+
+	// The cache sits outside the retries so only cache misses are retried
+	if cfg.Storage.Cache.Enabled {
+		cached, err := storage.NewCachingBackend(backend, storage.CacheConfig{
+			Dir:     cfg.Storage.Cache.Dir,
+			MaxSize: cfg.Storage.Cache.MaxSize,
+		}, logger)
+		if err != nil {
+			if node != nil {
+				node.Close()
+			}
+			return nil, nil, nil, nil, fmt.Errorf("failed to initialize storage cache: %w", err)
+		}
+		backend = cached
+	}
+	return backend, dualWrite, deltaBackend, node, nil
+}
+
+// clusterNode is this node's membership in the cluster.
+type clusterNode struct {
+	server  *cluster.Server
+	manager *cluster.StaticManager
+
+	// replicator is nil when each blob is kept on a single node.
+	replicator *cluster.Replicator
+}
+
+// startClusterNode serves the local backend to the other nodes and starts
+// tracking the configured peers. Blobs are replicated when the replication
+// factor is above 1.
+func startClusterNode(ctx context.Context, cfg *config.Config, local storage.Backend, locations cluster.BlobLocationRepository, logger zerolog.Logger) (*clusterNode, error) {
+	security := cluster.Security{SharedSecret: cfg.Cluster.SharedSecret}
+	if cfg.Cluster.TLSEnabled() {
+		tlsConfig, err := cluster.LoadTLSConfig(cfg.Cluster.TLSCertFile, cfg.Cluster.TLSKeyFile, cfg.Cluster.TLSCAFile)
+		if err != nil {
+			return nil, err
+		}
+		security.TLS = tlsConfig
+	}
+
+	server, err := cluster.NewServer(cluster.ServerConfig{
+		NodeID:            cfg.Cluster.NodeID,
+		Address:           fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Cluster.GRPCPort),
+		Role:              cluster.NodeRole(cfg.Cluster.NodeRole),
+		HeartbeatInterval: cfg.Cluster.HeartbeatInterval,
+		HeartbeatTimeout:  cfg.Cluster.HeartbeatTimeout,
+		Security:          security,
+	}, local, logger)
+	if err != nil {
+		return nil, err
+	}
+	if locations != nil {
+		server.SetLocationRepository(locations)
+	} else {
+		logger.Warn().Msg("Blob locations are kept in memory; they are lost on restart and not shared between nodes")
+	}
+	if err := server.Start(); err != nil {
+		return nil, err
+	}
+
+	peers := make([]string, 0, len(cfg.Cluster.Nodes))
+	for _, peer := range cfg.Cluster.Nodes {
+		peers = append(peers, peer.Address)
+	}
+	manager := cluster.NewStaticManager(cluster.ManagerConfig{
+		Peers:             peers,
+		HeartbeatInterval: cfg.Cluster.HeartbeatInterval,
+		Security:          security,
+	}, server, logger)
+	if err := manager.Start(ctx); err != nil {
+		server.Stop()
+		return nil, err
+	}
+
+	node := &clusterNode{server: server, manager: manager}
+	if cfg.Cluster.ReplicationFactor > 1 {
+		node.replicator = cluster.NewReplicator(cluster.ReplicationConfig{
+			Factor:      cfg.Cluster.ReplicationFactor,
+			WriteQuorum: cfg.Cluster.WriteQuorum,
+		}, cfg.Cluster.NodeID, local, manager, logger)
+	}
+
+	logger.Info().
+		Str("node_id", cfg.Cluster.NodeID).
+		Str("address", server.Addr()).
+		Int("peers", len(peers)).
+		Int("replication_factor", cfg.Cluster.ReplicationFactor).
+		Int("write_quorum", cfg.Cluster.WriteQuorum).
+		Msg("Cluster node started")
+	return node, nil
+}
+
+// Close waits for background replication, then stops tracking peers and
+// serving blobs to them.
+func (n *clusterNode) Close() {
+	if n.replicator != nil {
+		n.replicator.Wait()
+	}
+	n.manager.Close()
+	n.server.Stop()
+}
+
+// newDeltaBackend wraps backend so versions can be stored as deltas.
+func newDeltaBackend(cfg *config.Config, backend storage.Backend, deltaChains storage.DeltaChainStore, logger zerolog.Logger) *storage.DeltaBackend {
+	chunker := delta.NewFastCDC(delta.FastCDCConfig{
+		MinSize:            cfg.Versioning.MinChunkSize,
+		AvgSize:            cfg.Versioning.AvgChunkSize,
+		MaxSize:            cfg.Versioning.MaxChunkSize,
+		NormalizationLevel: delta.DefaultFastCDCConfig().NormalizationLevel,
+	})
+	return storage.NewDeltaBackend(backend, deltaChains, chunker, storage.DeltaConfig{
+		MaxChainDepth: cfg.Versioning.MaxChainDepth,
+		MinSavings:    cfg.Versioning.MinSavingsThreshold,
+		MaxSize:       cfg.Versioning.MaxDeltaObjectSize,
+		TempDir:       cfg.Storage.TempDir,
+	}, logger)
+}
+
+// newFilesystemBackend creates a filesystem backend over dataDir, compressing
+// blobs when storage compression is enabled.
+func newFilesystemBackend(cfg *config.Config, dataDir, tempDir string, logger zerolog.Logger) (storage.Backend, error) {
+	if cfg.Storage.Compression.Enabled {
+		return filesystem.NewCompressedStorage(filesystem.CompressedConfig{
+			DataDir:          dataDir,
+			TempDir:          tempDir,
+			Level:            cfg.Storage.Compression.Level,
+			SkipContentTypes: cfg.Storage.Compression.SkipContentTypes,
+		}, logger)
+	}
 	return filesystem.NewStorage(filesystem.Config{
-		DataDir: cfg.Storage.DataDir,
-		TempDir: cfg.Storage.TempDir,
+		DataDir: dataDir,
+		TempDir: tempDir,
 	}, logger)
 }
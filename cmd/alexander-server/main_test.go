@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/auth"
+	"github.com/prn-tf/alexander-storage/internal/cluster"
+	"github.com/prn-tf/alexander-storage/internal/config"
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/handler"
+	"github.com/prn-tf/alexander-storage/internal/lock"
+	"github.com/prn-tf/alexander-storage/internal/repository/sqlite"
+	"github.com/prn-tf/alexander-storage/internal/service"
+	"github.com/prn-tf/alexander-storage/internal/storage/filesystem"
+	"github.com/prn-tf/alexander-storage/internal/tiering"
+)
+
+func TestInitStorageBackend_ReplicatesPutObject(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	// A peer node with its own storage
+	peerStorage, err := filesystem.NewStorage(filesystem.Config{
+		DataDir: filepath.Join(dir, "peer", "data"),
+		TempDir: filepath.Join(dir, "peer", "tmp"),
+	}, zerolog.Nop())
+	require.NoError(t, err)
+	peer, err := cluster.NewServer(cluster.ServerConfig{NodeID: "node-2", Address: "127.0.0.1:0"}, peerStorage, zerolog.Nop())
+	require.NoError(t, err)
+	require.NoError(t, peer.Start())
+	t.Cleanup(func() { peer.Stop() })
+
+	cfg := &config.Config{}
+	cfg.Server.Host = "127.0.0.1"
+	cfg.Storage.DataDir = filepath.Join(dir, "data")
+	cfg.Storage.TempDir = filepath.Join(dir, "tmp")
+	cfg.Cluster = config.ClusterConfig{
+		Enabled:           true,
+		NodeID:            "node-1",
+		NodeRole:          "hot",
+		Nodes:             []config.NodeConfig{{Address: peer.Addr()}},
+		ReplicationFactor: 2,
+		WriteQuorum:       2,
+	}
+
+	backend, _, _, node, err := initStorageBackend(ctx, cfg, nil, nil, zerolog.Nop())
+	require.NoError(t, err)
+	require.NotNil(t, node)
+	t.Cleanup(node.Close)
+
+	db, err := sqlite.NewDB(ctx, sqlite.DefaultConfig(filepath.Join(dir, "alexander.db")), zerolog.Nop())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, db.Migrate(ctx))
+
+	user := domain.NewUser("tester", "tester@example.com", "hash")
+	require.NoError(t, sqlite.NewUserRepository(db).Create(ctx, user))
+	buckets := sqlite.NewBucketRepository(db)
+	require.NoError(t, buckets.Create(ctx, domain.NewBucket(user.ID, "uploads")))
+
+	objects := service.NewObjectService(sqlite.NewObjectRepository(db), sqlite.NewBlobRepository(db), buckets, backend, lock.NewNoOpLocker(), zerolog.Nop())
+	_, err = objects.PutObject(ctx, service.PutObjectInput{
+		BucketName: "uploads",
+		Key:        "reports/q3.csv",
+		Body:       strings.NewReader("a,b,c"),
+		Size:       5,
+		OwnerID:    user.ID,
+	})
+	require.NoError(t, err)
+	sum := sha256.Sum256([]byte("a,b,c"))
+	contentHash := hex.EncodeToString(sum[:])
+
+	// The write quorum of 2 means the peer holds a copy once PutObject returns
+	exists, err := peerStorage.Exists(ctx, contentHash)
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	status, err := node.replicator.GetReplicationStatus(ctx, contentHash)
+	require.NoError(t, err)
+	require.Equal(t, 2, status.ReplicaCount)
+	require.True(t, status.IsSufficient)
+}
+
+// adminUsers treats user 1 as an active admin.
+type adminUsers struct{}
+
+func (adminUsers) GetByID(ctx context.Context, id int64) (*domain.User, error) {
+	if id != 1 {
+		return nil, domain.ErrUserNotFound
+	}
+	return &domain.User{ID: 1, Username: "admin", IsAdmin: true, IsActive: true}, nil
+}
+
+func TestNewAdminHandler_TieringResources(t *testing.T) {
+	ctx := context.Background()
+	access := tiering.NewMemoryAccessTracker(zerolog.Nop())
+
+	serve := func(h http.Handler, method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, handler.AdminPathPrefix+path, nil)
+		req = req.WithContext(context.WithValue(req.Context(), auth.AuthContextKey, &auth.AuthContext{UserID: 1, Username: "admin"}))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Tiering disabled: the resources do not exist
+	cfg := &config.Config{}
+	h, controller := newAdminHandler(cfg, adminUsers{}, access, nil, nil, nil, zerolog.Nop())
+	require.Nil(t, controller)
+	require.Equal(t, http.StatusNotFound, serve(h, http.MethodGet, "migrations").Code)
+	require.Equal(t, http.StatusNotFound, serve(h, http.MethodPost, "tiering/uploads").Code)
+
+	// Tiering enabled on a single node: policies are evaluated on demand
+	cfg.Tiering.Enabled = true
+	h, controller = newAdminHandler(cfg, adminUsers{}, access, nil, nil, nil, zerolog.Nop())
+	require.NotNil(t, controller)
+
+	require.NoError(t, access.RegisterBlob(ctx, &tiering.BlobAccessInfo{
+		ContentHash:    "abc123",
+		BucketName:     "uploads",
+		Size:           4 * 1024 * 1024,
+		CurrentTier:    tiering.TierHot,
+		LastAccessedAt: time.Now().AddDate(0, 0, -45),
+	}))
+
+	rec := serve(h, http.MethodGet, "migrations")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var migrations handler.MigrationListResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&migrations))
+	require.Empty(t, migrations.Migrations)
+
+	rec = serve(h, http.MethodPost, "tiering/uploads?dryRun=true")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var evaluation handler.TieringEvaluationResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&evaluation))
+	require.True(t, evaluation.DryRun)
+	require.Len(t, evaluation.Decisions, 1)
+	require.Equal(t, tiering.TierWarm, evaluation.Decisions[0].TargetTier)
+}
@@ -0,0 +1,232 @@
+package auth
+
+import (
+	"context"
+	"strings"
+)
+
+// Action is an S3 action name as used in IAM and bucket policies.
+type Action string
+
+// S3 actions checked by the API handlers.
+const (
+	ActionListAllMyBuckets                 Action = "s3:ListAllMyBuckets"
+	ActionCreateBucket                     Action = "s3:CreateBucket"
+	ActionDeleteBucket                     Action = "s3:DeleteBucket"
+	ActionListBucket                       Action = "s3:ListBucket"
+	ActionListBucketVersions               Action = "s3:ListBucketVersions"
+	ActionListBucketMultipartUploads       Action = "s3:ListBucketMultipartUploads"
+	ActionGetBucketVersioning              Action = "s3:GetBucketVersioning"
+	ActionPutBucketVersioning              Action = "s3:PutBucketVersioning"
+	ActionGetBucketAcl                     Action = "s3:GetBucketAcl"
+	ActionPutBucketAcl                     Action = "s3:PutBucketAcl"
+	ActionGetBucketOwnershipControls       Action = "s3:GetBucketOwnershipControls"
+	ActionPutBucketOwnershipControls       Action = "s3:PutBucketOwnershipControls"
+	ActionGetLifecycleConfiguration        Action = "s3:GetLifecycleConfiguration"
+	ActionPutLifecycleConfiguration        Action = "s3:PutLifecycleConfiguration"
+	ActionGetBucketPolicy                  Action = "s3:GetBucketPolicy"
+	ActionPutBucketPolicy                  Action = "s3:PutBucketPolicy"
+	ActionDeleteBucketPolicy               Action = "s3:DeleteBucketPolicy"
+	ActionGetBucketCORS                    Action = "s3:GetBucketCORS"
+	ActionPutBucketCORS                    Action = "s3:PutBucketCORS"
+	ActionGetBucketNotification            Action = "s3:GetBucketNotification"
+	ActionPutBucketNotification            Action = "s3:PutBucketNotification"
+	ActionGetBucketObjectLockConfiguration Action = "s3:GetBucketObjectLockConfiguration"
+	ActionPutBucketObjectLockConfiguration Action = "s3:PutBucketObjectLockConfiguration"
+	ActionGetObject                        Action = "s3:GetObject"
+	ActionPutObject                        Action = "s3:PutObject"
+	ActionDeleteObject                     Action = "s3:DeleteObject"
+	ActionGetObjectAttributes              Action = "s3:GetObjectAttributes"
+	ActionGetObjectAcl                     Action = "s3:GetObjectAcl"
+	ActionPutObjectAcl                     Action = "s3:PutObjectAcl"
+	ActionGetObjectTagging                 Action = "s3:GetObjectTagging"
+	ActionPutObjectTagging                 Action = "s3:PutObjectTagging"
+	ActionDeleteObjectTagging              Action = "s3:DeleteObjectTagging"
+	ActionGetObjectRetention               Action = "s3:GetObjectRetention"
+	ActionPutObjectRetention               Action = "s3:PutObjectRetention"
+	ActionGetObjectLegalHold               Action = "s3:GetObjectLegalHold"
+	ActionPutObjectLegalHold               Action = "s3:PutObjectLegalHold"
+	ActionBypassGovernanceRetention        Action = "s3:BypassGovernanceRetention"
+	ActionAbortMultipartUpload             Action = "s3:AbortMultipartUpload"
+	ActionListMultipartUploadParts         Action = "s3:ListMultipartUploadParts"
+)
+
+// resourceARNPrefix is the ARN prefix of all S3 resources.
+const resourceARNPrefix = "arn:aws:s3:::"
+
+// AllResourcesARN is the resource of actions that are not scoped to a bucket.
+const AllResourcesARN = "*"
+
+// BucketARN returns the resource ARN of a bucket.
+func BucketARN(bucket string) string {
+	return resourceARNPrefix + bucket
+}
+
+// ObjectARN returns the resource ARN of an object.
+func ObjectARN(bucket, key string) string {
+	return resourceARNPrefix + bucket + "/" + key
+}
+
+// ParseResourceARN splits a bucket or object ARN into bucket and key.
+// ok is false for resources that are not S3 ARNs.
+func ParseResourceARN(resource string) (bucket, key string, isObject, ok bool) {
+	rest, found := strings.CutPrefix(resource, resourceARNPrefix)
+	if !found || rest == "" {
+		return "", "", false, false
+	}
+	bucket, key, isObject = strings.Cut(rest, "/")
+	return bucket, key, isObject, true
+}
+
+// Authorizer decides whether a principal may perform an action on a resource.
+// Handlers call it with the resolved S3 action and resource ARN before doing
+// any work; implementations return ErrAccessDenied to reject the request.
+type Authorizer interface {
+	Authorize(ctx context.Context, principal *AuthContext, action Action, resource string) error
+}
+
+// BucketOwnerLookup resolves the owner of a bucket for authorization.
+type BucketOwnerLookup interface {
+	// GetBucketOwner returns the ID of the user owning the bucket.
+	// Returns 0 if the bucket does not exist.
+	GetBucketOwner(ctx context.Context, bucketName string) (int64, error)
+}
+
+// BucketPolicyLookup resolves the policy of a bucket for authorization.
+type BucketPolicyLookup interface {
+	// GetBucketPolicy returns the parsed policy of a bucket.
+	// Returns nil if the bucket has no policy or does not exist.
+	GetBucketPolicy(ctx context.Context, bucketName string) (*Policy, error)
+}
+
+// DefaultAuthorizer grants authenticated principals access to the buckets they
+// own, within the namespace of their access key. A bucket policy is evaluated
+// first: an explicit deny rejects the request and an allow grants it, to
+// anonymous and non-owning principals alike. Otherwise anyone may read objects
+// of buckets whose ACL is public-read or public-read-write. Access granted by
+// a policy or ACL is recorded on the principal, see BucketAccessGranted.
+type DefaultAuthorizer struct {
+	owners   BucketOwnerLookup
+	policies BucketPolicyLookup
+	acls     BucketACLChecker
+}
+
+// NewDefaultAuthorizer creates a DefaultAuthorizer.
+// If owners is nil, bucket ownership is left to the services to enforce.
+func NewDefaultAuthorizer(owners BucketOwnerLookup) *DefaultAuthorizer {
+	return &DefaultAuthorizer{owners: owners}
+}
+
+// SetPolicyLookup enables bucket policy evaluation.
+func (a *DefaultAuthorizer) SetPolicyLookup(policies BucketPolicyLookup) {
+	a.policies = policies
+}
+
+// SetACLChecker enables public reads of objects in public-read buckets.
+func (a *DefaultAuthorizer) SetACLChecker(acls BucketACLChecker) {
+	a.acls = acls
+}
+
+// Authorize implements Authorizer.
+func (a *DefaultAuthorizer) Authorize(ctx context.Context, principal *AuthContext, action Action, resource string) error {
+	if principal == nil {
+		return ErrAccessDenied
+	}
+
+	anonymous := principal.AuthType == AuthTypeAnonymous
+
+	bucket, key, isObject, ok := ParseResourceARN(resource)
+	if !ok {
+		// Account-wide actions such as ListAllMyBuckets
+		if anonymous {
+			return ErrAccessDenied
+		}
+		return nil
+	}
+
+	// Access keys confined to a namespace only reach keys inside it and never
+	// administer the shared bucket itself
+	if isObject && !principal.AllowsObjectKey(bucket, key) {
+		return ErrAccessDenied
+	}
+	if isBucketAdminAction(action) && !principal.AllowsBucketAdmin(bucket) {
+		return ErrAccessDenied
+	}
+
+	switch decision, err := a.evaluatePolicy(ctx, bucket, action, resource); {
+	case err != nil:
+		return err
+	case isBucketPolicyAction(action):
+		// Only the owner manages the policy, and a deny cannot lock them out
+	case decision == PolicyAllowed:
+		principal.GrantBucketAccess(bucket)
+		return nil
+	case decision == PolicyDenied:
+		return ErrAccessDenied
+	}
+
+	// A public bucket ACL lets everyone read objects; signing a request never
+	// grants less than sending it anonymously
+	if action == ActionGetObject && a.allowsPublicRead(ctx, bucket) {
+		principal.GrantBucketAccess(bucket)
+		return nil
+	}
+	if anonymous {
+		return ErrAccessDenied
+	}
+
+	// Creating a bucket needs no owner; a missing bucket is reported by the service
+	if a.owners == nil || action == ActionCreateBucket {
+		return nil
+	}
+	owner, err := a.owners.GetBucketOwner(ctx, bucket)
+	if err != nil {
+		return err
+	}
+	if owner != 0 && owner != principal.UserID {
+		return ErrAccessDenied
+	}
+	return nil
+}
+
+// evaluatePolicy evaluates the policy of bucket, if any, for the request.
+func (a *DefaultAuthorizer) evaluatePolicy(ctx context.Context, bucket string, action Action, resource string) (PolicyDecision, error) {
+	if a.policies == nil {
+		return PolicyNoDecision, nil
+	}
+	policy, err := a.policies.GetBucketPolicy(ctx, bucket)
+	if err != nil || policy == nil {
+		return PolicyNoDecision, err
+	}
+	return policy.Evaluate(action, resource), nil
+}
+
+// allowsPublicRead reports whether the ACL of bucket grants anonymous reads.
+// ACL lookup failures deny access.
+func (a *DefaultAuthorizer) allowsPublicRead(ctx context.Context, bucket string) bool {
+	if a.acls == nil {
+		return false
+	}
+	acl, err := a.acls.GetBucketACL(ctx, bucket)
+	return err == nil && isPublicReadACL(acl)
+}
+
+// isBucketPolicyAction reports whether action reads or changes a bucket policy.
+func isBucketPolicyAction(action Action) bool {
+	return action == ActionGetBucketPolicy || action == ActionPutBucketPolicy || action == ActionDeleteBucketPolicy
+}
+
+// isBucketAdminAction reports whether action changes or deletes a bucket itself.
+func isBucketAdminAction(action Action) bool {
+	switch action {
+	case ActionDeleteBucket, ActionPutBucketVersioning, ActionPutBucketAcl, ActionPutBucketOwnershipControls,
+		ActionPutLifecycleConfiguration, ActionPutBucketPolicy, ActionDeleteBucketPolicy, ActionPutBucketCORS,
+		ActionPutBucketNotification, ActionPutBucketObjectLockConfiguration:
+		return true
+	default:
+		return false
+	}
+}
+
+// Ensure DefaultAuthorizer implements Authorizer.
+var _ Authorizer = (*DefaultAuthorizer)(nil)
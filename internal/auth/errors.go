@@ -31,6 +31,9 @@ var (
 
 	// ErrPresignedURLNotYetValid indicates the presigned URL is not yet valid.
 	ErrPresignedURLNotYetValid = errors.New("request is not yet valid")
+
+	// ErrSignatureV2Disabled indicates a Signature Version 2 request while V2 compatibility is off.
+	ErrSignatureV2Disabled = errors.New("signature version 2 is not supported, please use AWS4-HMAC-SHA256")
 )
 
 // S3ErrorCode represents S3 error codes for proper API responses.
@@ -57,6 +60,9 @@ const (
 
 	// S3ErrorAuthorizationHeaderMalformed maps to HTTP 400
 	S3ErrorAuthorizationHeaderMalformed S3ErrorCode = "AuthorizationHeaderMalformed"
+
+	// S3ErrorAuthorizationQueryParametersError maps to HTTP 400
+	S3ErrorAuthorizationQueryParametersError S3ErrorCode = "AuthorizationQueryParametersError"
 )
 
 // AuthError represents an authentication error with S3-compatible error code.
@@ -119,6 +125,13 @@ func NewAuthError(err error) *AuthError {
 			HTTPStatus: 400,
 		}
 
+	case errors.Is(err, ErrInvalidPresignedURL):
+		return &AuthError{
+			Code:       S3ErrorAuthorizationQueryParametersError,
+			Message:    err.Error(),
+			HTTPStatus: 400,
+		}
+
 	case errors.Is(err, ErrPresignedURLExpired):
 		return &AuthError{
 			Code:       S3ErrorExpiredToken,
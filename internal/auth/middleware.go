@@ -3,11 +3,14 @@ package auth
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/rs/zerolog/log"
+
+	"github.com/prn-tf/alexander-storage/internal/middleware"
 )
 
 // AccessKeyStore defines the interface for retrieving access keys.
@@ -46,6 +49,17 @@ type AccessKeyInfo struct {
 
 	// ExpiresAt is the optional expiration time.
 	ExpiresAt *time.Time
+
+	// NamespaceBucket is the bucket in which the key is confined to NamespacePrefix.
+	NamespaceBucket string
+
+	// NamespacePrefix is the object key prefix the key may access in NamespaceBucket.
+	NamespacePrefix string
+
+	// RateLimitRPS and RateLimitBurst override the server's request rate
+	// limit for the key. Zero uses the server default.
+	RateLimitRPS   float64
+	RateLimitBurst int
 }
 
 // Config contains configuration for the auth middleware.
@@ -64,6 +78,14 @@ type Config struct {
 
 	// BucketACLChecker checks bucket ACL for anonymous access (optional).
 	BucketACLChecker BucketACLChecker
+
+	// BucketPolicyLookup admits anonymous requests to buckets whose policy
+	// grants access, for the authorizer to evaluate (optional).
+	BucketPolicyLookup BucketPolicyLookup
+
+	// AllowSignatureV2 accepts legacy AWS Signature Version 2 requests
+	// alongside SigV4. Off by default.
+	AllowSignatureV2 bool
 }
 
 // DefaultConfig returns the default auth configuration.
@@ -88,6 +110,33 @@ func extractBucketName(path string) string {
 	return ""
 }
 
+// requestBucketName returns the bucket a request addresses: the bucket of a
+// virtual-hosted-style request, or else the first path segment.
+func requestBucketName(r *http.Request) string {
+	if bucketName, ok := VirtualHostBucket(r.Context()); ok {
+		return bucketName
+	}
+	return extractBucketName(r.URL.Path)
+}
+
+// WithVirtualHostBucket returns a context recording the bucket named by the
+// Host header of a virtual-hosted-style request, whose whole path is the
+// object key. It must be set before the auth middleware runs.
+func WithVirtualHostBucket(ctx context.Context, bucketName string) context.Context {
+	return context.WithValue(ctx, virtualHostBucketKey{}, bucketName)
+}
+
+// VirtualHostBucket returns the bucket recorded by WithVirtualHostBucket.
+func VirtualHostBucket(ctx context.Context) (string, bool) {
+	bucketName, ok := ctx.Value(virtualHostBucketKey{}).(string)
+	return bucketName, ok
+}
+
+// isPublicReadACL reports whether a canned bucket ACL grants anonymous reads.
+func isPublicReadACL(acl string) bool {
+	return acl == "public-read" || acl == "public-read-write"
+}
+
 // isReadOperation checks if the HTTP method is a read operation.
 func isReadOperation(method string) bool {
 	return method == http.MethodGet || method == http.MethodHead
@@ -110,30 +159,42 @@ func Middleware(store AccessKeyStore, config Config) func(http.Handler) http.Han
 
 			switch authType {
 			case AuthTypeAnonymous:
+				// Listing all buckets (GET / without a virtual-hosted
+				// bucket) always requires credentials, whatever the
+				// anonymous access settings
+				bucketName := requestBucketName(r)
+				if bucketName == "" {
+					writeAuthError(w, ErrAccessDenied)
+					return
+				}
+
 				// Check if anonymous access is allowed
 				if config.AllowAnonymous {
 					next.ServeHTTP(w, r)
 					return
 				}
 
-				// Check bucket ACL for anonymous access
-				if config.BucketACLChecker != nil {
-					bucketName := extractBucketName(r.URL.Path)
-					if bucketName != "" {
-						acl, err := config.BucketACLChecker.GetBucketACL(r.Context(), bucketName)
-						if err == nil && acl != "" {
-							// Check if ACL allows anonymous access for this operation
-							if isReadOperation(r.Method) && (acl == "public-read" || acl == "public-read-write") {
-								// Allow read operations on public-read buckets
-								next.ServeHTTP(w, r)
-								return
-							}
-							if acl == "public-read-write" {
-								// Allow all operations on public-read-write buckets
-								next.ServeHTTP(w, r)
-								return
-							}
-						}
+				// A bucket policy may grant anonymous access; the request
+				// carries an anonymous principal so that the authorizer
+				// evaluates the policy for the exact action
+				if config.BucketPolicyLookup != nil {
+					policy, err := config.BucketPolicyLookup.GetBucketPolicy(r.Context(), bucketName)
+					if err == nil && policy != nil && policy.AllowsAnything() {
+						authCtx := &AuthContext{AuthType: AuthTypeAnonymous, Region: config.Region}
+						next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), AuthContextKey, authCtx)))
+						return
+					}
+				}
+
+				// A public-read bucket ACL admits anonymous reads; writes
+				// always need credentials. The authorizer decides which
+				// reads the ACL grants
+				if config.BucketACLChecker != nil && isReadOperation(r.Method) {
+					acl, err := config.BucketACLChecker.GetBucketACL(r.Context(), bucketName)
+					if err == nil && isPublicReadACL(acl) {
+						authCtx := &AuthContext{AuthType: AuthTypeAnonymous, Region: config.Region}
+						next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), AuthContextKey, authCtx)))
+						return
 					}
 				}
 
@@ -149,6 +210,19 @@ func Middleware(store AccessKeyStore, config Config) func(http.Handler) http.Han
 				}
 				r = r.WithContext(context.WithValue(r.Context(), AuthContextKey, authCtx))
 
+			case AuthTypeSignedV2:
+				if !config.AllowSignatureV2 {
+					writeAuthError(w, ErrSignatureV2Disabled)
+					return
+				}
+				authCtx, err := handleSignedV2(r, store, config)
+				if err != nil {
+					log.Debug().Err(err).Str("path", r.URL.Path).Msg("SignedV2 authentication failed")
+					writeAuthError(w, err)
+					return
+				}
+				r = r.WithContext(context.WithValue(r.Context(), AuthContextKey, authCtx))
+
 			case AuthTypePresignedV4:
 				authCtx, err := handlePresignedV4(r, store, config)
 				if err != nil {
@@ -212,23 +286,87 @@ func handleSignedV4(r *http.Request, store AccessKeyStore, config Config) (*Auth
 	}()
 
 	return &AuthContext{
-		UserID:      keyInfo.UserID,
-		Username:    keyInfo.Username,
-		AccessKeyID: keyInfo.AccessKeyID,
-		Credential:  signedValues.Credential,
-		AuthType:    AuthTypeSignedV4,
-		RequestTime: requestTime,
-		Region:      signedValues.Credential.Scope.Region,
+		UserID:          keyInfo.UserID,
+		Username:        keyInfo.Username,
+		AccessKeyID:     keyInfo.AccessKeyID,
+		Credential:      signedValues.Credential,
+		AuthType:        AuthTypeSignedV4,
+		RequestTime:     requestTime,
+		Region:          signedValues.Credential.Scope.Region,
+		NamespaceBucket: keyInfo.NamespaceBucket,
+		NamespacePrefix: keyInfo.NamespacePrefix,
+		RateLimitRPS:    keyInfo.RateLimitRPS,
+		RateLimitBurst:  keyInfo.RateLimitBurst,
+	}, nil
+}
+
+// handleSignedV2 handles legacy AWS Signature V2 authentication.
+func handleSignedV2(r *http.Request, store AccessKeyStore, config Config) (*AuthContext, error) {
+	// Parse authorization header
+	accessKey, signature, err := ParseSignV2(r.Header.Get(AuthorizationHeader))
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate request time
+	requestTime, err := GetRequestTimeV2(r)
+	if err != nil {
+		return nil, ErrMissingSecurityHeader
+	}
+
+	if err := ValidateRequestTime(requestTime); err != nil {
+		return nil, err
+	}
+
+	// Lookup access key
+	keyInfo, err := store.GetActiveAccessKey(r.Context(), accessKey)
+	if err != nil {
+		return nil, ErrInvalidAccessKeyID
+	}
+
+	// Check if key is expired
+	if keyInfo.ExpiresAt != nil && time.Now().UTC().After(*keyInfo.ExpiresAt) {
+		return nil, ErrPresignedURLExpired
+	}
+
+	// Verify signature
+	if err := VerifySignatureV2(r, keyInfo.SecretKey, signature); err != nil {
+		return nil, err
+	}
+
+	// Update last used timestamp (async, don't block request)
+	go func() {
+		_ = store.UpdateLastUsed(context.Background(), keyInfo.AccessKeyID)
+	}()
+
+	return &AuthContext{
+		UserID:          keyInfo.UserID,
+		Username:        keyInfo.Username,
+		AccessKeyID:     keyInfo.AccessKeyID,
+		Credential:      CredentialHeader{AccessKey: keyInfo.AccessKeyID},
+		AuthType:        AuthTypeSignedV2,
+		RequestTime:     requestTime,
+		Region:          config.Region,
+		NamespaceBucket: keyInfo.NamespaceBucket,
+		NamespacePrefix: keyInfo.NamespacePrefix,
+		RateLimitRPS:    keyInfo.RateLimitRPS,
+		RateLimitBurst:  keyInfo.RateLimitBurst,
 	}, nil
 }
 
-// handlePresignedV4 handles presigned URL authentication.
+// handlePresignedV4 handles presigned URL authentication. The signature
+// covers the query string, minus X-Amz-Signature, and the signed headers.
 func handlePresignedV4(r *http.Request, store AccessKeyStore, config Config) (*AuthContext, error) {
 	// Parse presigned URL parameters
 	signedValues, expires, err := ParsePresignedV4(r)
 	if err != nil {
 		return nil, err
 	}
+	expiry := time.Duration(expires) * time.Second
+	if expiry < PresignedURLMinExpiry || expiry > PresignedURLMaxExpiry {
+		return nil, fmt.Errorf("%w: X-Amz-Expires must be between %d and %d seconds",
+			ErrInvalidPresignedURL, int(PresignedURLMinExpiry.Seconds()), int(PresignedURLMaxExpiry.Seconds()))
+	}
 
 	// Get request time
 	requestTime, err := GetRequestTime(r)
@@ -236,9 +374,12 @@ func handlePresignedV4(r *http.Request, store AccessKeyStore, config Config) (*A
 		return nil, ErrMissingSecurityHeader
 	}
 
-	// Check if URL has expired
-	expirationTime := requestTime.Add(time.Duration(expires) * time.Second)
-	if time.Now().UTC().After(expirationTime) {
+	// The URL is valid from its signing time, allowing for clock skew, until it expires
+	now := time.Now().UTC()
+	if requestTime.After(now.Add(MaxSkewTime)) {
+		return nil, ErrPresignedURLNotYetValid
+	}
+	if now.After(requestTime.Add(expiry)) {
 		return nil, ErrPresignedURLExpired
 	}
 
@@ -248,24 +389,33 @@ func handlePresignedV4(r *http.Request, store AccessKeyStore, config Config) (*A
 		return nil, ErrInvalidAccessKeyID
 	}
 
-	// For presigned URLs, we need to reconstruct the canonical request
-	// The signed headers are in query params, and we verify against those
-	payloadHash := GetPayloadHash(r)
+	// Check if key is expired
+	if keyInfo.ExpiresAt != nil && now.After(*keyInfo.ExpiresAt) {
+		return nil, ErrPresignedURLExpired
+	}
 
-	// Build canonical request for presigned URL
-	// Note: For presigned URLs, the query string includes auth params which need special handling
-	if err := VerifySignature(r, keyInfo.SecretKey, *signedValues, payloadHash); err != nil {
+	// Verify signature
+	if err := VerifySignature(r, keyInfo.SecretKey, *signedValues, GetPresignedPayloadHash(r)); err != nil {
 		return nil, err
 	}
 
+	// Update last used timestamp (async, don't block request)
+	go func() {
+		_ = store.UpdateLastUsed(context.Background(), keyInfo.AccessKeyID)
+	}()
+
 	return &AuthContext{
-		UserID:      keyInfo.UserID,
-		Username:    keyInfo.Username,
-		AccessKeyID: keyInfo.AccessKeyID,
-		Credential:  signedValues.Credential,
-		AuthType:    AuthTypePresignedV4,
-		RequestTime: requestTime,
-		Region:      signedValues.Credential.Scope.Region,
+		UserID:          keyInfo.UserID,
+		Username:        keyInfo.Username,
+		AccessKeyID:     keyInfo.AccessKeyID,
+		Credential:      signedValues.Credential,
+		AuthType:        AuthTypePresignedV4,
+		RequestTime:     requestTime,
+		Region:          signedValues.Credential.Scope.Region,
+		NamespaceBucket: keyInfo.NamespaceBucket,
+		NamespacePrefix: keyInfo.NamespacePrefix,
+		RateLimitRPS:    keyInfo.RateLimitRPS,
+		RateLimitBurst:  keyInfo.RateLimitBurst,
 	}, nil
 }
 
@@ -273,6 +423,7 @@ func handlePresignedV4(r *http.Request, store AccessKeyStore, config Config) (*A
 func writeAuthError(w http.ResponseWriter, err error) {
 	authErr := NewAuthError(err)
 
+	middleware.SetErrorCode(w, string(authErr.Code))
 	w.Header().Set("Content-Type", "application/xml")
 	w.WriteHeader(authErr.HTTPStatus)
 
@@ -312,3 +463,17 @@ func RequireAuth(ctx context.Context) (*AuthContext, error) {
 	}
 	return authCtx, nil
 }
+
+// RateLimitClient attributes a request to its access key for the rate
+// limiter, with the key's overrides of the server's rate limit. Anonymous
+// requests are not attributed, so they are limited by client address.
+func RateLimitClient(r *http.Request) (string, middleware.RateLimit, bool) {
+	authCtx := GetAuthContext(r.Context())
+	if authCtx == nil || authCtx.AccessKeyID == "" {
+		return "", middleware.RateLimit{}, false
+	}
+	return authCtx.AccessKeyID, middleware.RateLimit{
+		RequestsPerSecond: authCtx.RateLimitRPS,
+		BurstSize:         authCtx.RateLimitBurst,
+	}, true
+}
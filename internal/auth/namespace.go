@@ -0,0 +1,42 @@
+package auth
+
+import "strings"
+
+// restrictsBucket returns true if the access key is confined to a key prefix in bucket.
+func (a *AuthContext) restrictsBucket(bucket string) bool {
+	return a.NamespaceBucket != "" && a.NamespaceBucket == bucket
+}
+
+// AllowsObjectKey returns true if the access key may operate on key in bucket.
+// Keys without a namespace, and buckets other than the namespace bucket, are unrestricted.
+func (a *AuthContext) AllowsObjectKey(bucket, key string) bool {
+	if !a.restrictsBucket(bucket) {
+		return true
+	}
+	return strings.HasPrefix(key, a.NamespacePrefix)
+}
+
+// AllowsBucketAdmin returns true if the access key may change or delete bucket itself.
+// A key confined to a namespace never administers the shared bucket.
+func (a *AuthContext) AllowsBucketAdmin(bucket string) bool {
+	return !a.restrictsBucket(bucket)
+}
+
+// ScopeListPrefix returns the prefix to list with so that results stay inside
+// the access key's namespace. A prefix that already lies inside the namespace is
+// returned unchanged; a broader prefix that contains the namespace is narrowed to
+// it. ok is false if prefix does not overlap the namespace at all.
+func (a *AuthContext) ScopeListPrefix(bucket, prefix string) (scoped string, ok bool) {
+	if !a.restrictsBucket(bucket) {
+		return prefix, true
+	}
+
+	switch {
+	case strings.HasPrefix(prefix, a.NamespacePrefix):
+		return prefix, true
+	case strings.HasPrefix(a.NamespacePrefix, prefix):
+		return a.NamespacePrefix, true
+	default:
+		return "", false
+	}
+}
@@ -35,6 +35,9 @@ func GetAuthType(r *http.Request) AuthType {
 		if strings.HasPrefix(authHeader, SignV4Algorithm) {
 			return AuthTypeSignedV4
 		}
+		if strings.HasPrefix(authHeader, SignV2Prefix) {
+			return AuthTypeSignedV2
+		}
 		return AuthTypeUnknown
 	}
 
@@ -0,0 +1,277 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrMalformedPolicy indicates a bucket policy document is invalid or uses
+// elements that are not supported.
+var ErrMalformedPolicy = errors.New("malformed bucket policy")
+
+// MaxPolicySize is the largest bucket policy document accepted, as in S3.
+const MaxPolicySize = 20 * 1024
+
+// PolicyEffect is the effect of a policy statement.
+type PolicyEffect string
+
+const (
+	// PolicyAllow grants the statement's actions.
+	PolicyAllow PolicyEffect = "Allow"
+
+	// PolicyDeny denies the statement's actions, overriding any grant.
+	PolicyDeny PolicyEffect = "Deny"
+)
+
+// PolicyDecision is the outcome of evaluating a policy for a request.
+type PolicyDecision int
+
+const (
+	// PolicyNoDecision means no statement applies; other checks decide.
+	PolicyNoDecision PolicyDecision = iota
+
+	// PolicyAllowed means a statement allows the request and none denies it.
+	PolicyAllowed
+
+	// PolicyDenied means a statement explicitly denies the request.
+	PolicyDenied
+)
+
+// Policy is a parsed bucket policy.
+//
+// Only the elements needed for public-access policies are supported: the
+// principal must be "*" (everyone, including anonymous requests), and
+// Condition, NotAction, NotResource and NotPrincipal are rejected rather
+// than ignored, so a policy never grants more than it says.
+type Policy struct {
+	Version    string
+	ID         string
+	Statements []PolicyStatement
+}
+
+// PolicyStatement is a single statement of a bucket policy.
+type PolicyStatement struct {
+	Sid       string
+	Effect    PolicyEffect
+	Actions   []string
+	Resources []string
+}
+
+// policyDocument is the JSON form of a bucket policy.
+type policyDocument struct {
+	Version   string          `json:"Version"`
+	ID        string          `json:"Id"`
+	Statement json.RawMessage `json:"Statement"`
+}
+
+// policyStatementDocument is the JSON form of a policy statement.
+type policyStatementDocument struct {
+	Sid          string          `json:"Sid"`
+	Effect       string          `json:"Effect"`
+	Principal    json.RawMessage `json:"Principal"`
+	Action       stringOrList    `json:"Action"`
+	Resource     stringOrList    `json:"Resource"`
+	Condition    json.RawMessage `json:"Condition"`
+	NotAction    json.RawMessage `json:"NotAction"`
+	NotResource  json.RawMessage `json:"NotResource"`
+	NotPrincipal json.RawMessage `json:"NotPrincipal"`
+}
+
+// stringOrList is a policy element given as a string or a list of strings.
+type stringOrList []string
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *stringOrList) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*s = []string{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return errors.New("must be a string or a list of strings")
+	}
+	*s = list
+	return nil
+}
+
+// ParsePolicy parses and validates the policy document of bucket.
+// Errors wrap ErrMalformedPolicy with the reason.
+func ParsePolicy(bucket string, data []byte) (*Policy, error) {
+	if len(data) > MaxPolicySize {
+		return nil, fmt.Errorf("%w: policies are limited to %d bytes", ErrMalformedPolicy, MaxPolicySize)
+	}
+
+	var doc policyDocument
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedPolicy, err)
+	}
+
+	if doc.Version != "" && doc.Version != "2012-10-17" && doc.Version != "2008-10-17" {
+		return nil, fmt.Errorf("%w: invalid Version %q", ErrMalformedPolicy, doc.Version)
+	}
+
+	var statements []policyStatementDocument
+	if len(doc.Statement) > 0 && doc.Statement[0] == '{' {
+		var statement policyStatementDocument
+		if err := json.Unmarshal(doc.Statement, &statement); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrMalformedPolicy, err)
+		}
+		statements = append(statements, statement)
+	} else if err := json.Unmarshal(doc.Statement, &statements); err != nil {
+		return nil, fmt.Errorf("%w: Statement must be an object or a list of objects", ErrMalformedPolicy)
+	}
+	if len(statements) == 0 {
+		return nil, fmt.Errorf("%w: missing required field Statement", ErrMalformedPolicy)
+	}
+
+	policy := &Policy{Version: doc.Version, ID: doc.ID}
+	for i, s := range statements {
+		statement, err := parsePolicyStatement(bucket, s)
+		if err != nil {
+			return nil, fmt.Errorf("%w: statement %d: %v", ErrMalformedPolicy, i+1, err)
+		}
+		policy.Statements = append(policy.Statements, statement)
+	}
+	return policy, nil
+}
+
+// parsePolicyStatement validates a statement of bucket's policy.
+func parsePolicyStatement(bucket string, s policyStatementDocument) (PolicyStatement, error) {
+	switch {
+	case s.Condition != nil:
+		return PolicyStatement{}, errors.New("Condition is not supported")
+	case s.NotAction != nil, s.NotResource != nil, s.NotPrincipal != nil:
+		return PolicyStatement{}, errors.New("NotAction, NotResource and NotPrincipal are not supported")
+	}
+
+	effect := PolicyEffect(s.Effect)
+	if effect != PolicyAllow && effect != PolicyDeny {
+		return PolicyStatement{}, fmt.Errorf("invalid effect %q", s.Effect)
+	}
+	if !isEveryonePrincipal(s.Principal) {
+		return PolicyStatement{}, errors.New(`only the "*" principal is supported`)
+	}
+
+	if len(s.Action) == 0 {
+		return PolicyStatement{}, errors.New("missing required field Action")
+	}
+	for _, action := range s.Action {
+		if action != "*" && !strings.HasPrefix(strings.ToLower(action), "s3:") {
+			return PolicyStatement{}, fmt.Errorf("invalid action %q", action)
+		}
+	}
+
+	// Resources must lie in the bucket the policy is attached to
+	if len(s.Resource) == 0 {
+		return PolicyStatement{}, errors.New("missing required field Resource")
+	}
+	for _, resource := range s.Resource {
+		name, _, _, ok := ParseResourceARN(resource)
+		if !ok || name != bucket {
+			return PolicyStatement{}, fmt.Errorf("policy has invalid resource %q", resource)
+		}
+	}
+
+	return PolicyStatement{
+		Sid:       s.Sid,
+		Effect:    effect,
+		Actions:   s.Action,
+		Resources: s.Resource,
+	}, nil
+}
+
+// isEveryonePrincipal reports whether a Principal element is "*" or {"AWS": "*"}.
+func isEveryonePrincipal(data json.RawMessage) bool {
+	var principal string
+	if err := json.Unmarshal(data, &principal); err == nil {
+		return principal == "*"
+	}
+	var principals map[string]stringOrList
+	if err := json.Unmarshal(data, &principals); err != nil || len(principals) != 1 {
+		return false
+	}
+	aws := principals["AWS"]
+	return len(aws) == 1 && aws[0] == "*"
+}
+
+// Evaluate decides whether the policy allows action on resource. An explicit
+// deny wins over any allow.
+func (p *Policy) Evaluate(action Action, resource string) PolicyDecision {
+	decision := PolicyNoDecision
+	for _, s := range p.Statements {
+		if !s.matches(action, resource) {
+			continue
+		}
+		if s.Effect == PolicyDeny {
+			return PolicyDenied
+		}
+		decision = PolicyAllowed
+	}
+	return decision
+}
+
+// AllowsAnything reports whether any statement grants access, that is,
+// whether anonymous requests to the bucket may be allowed at all.
+func (p *Policy) AllowsAnything() bool {
+	for _, s := range p.Statements {
+		if s.Effect == PolicyAllow {
+			return true
+		}
+	}
+	return false
+}
+
+// matches reports whether the statement covers action on resource.
+// Action names are case-insensitive, resources are not.
+func (s PolicyStatement) matches(action Action, resource string) bool {
+	actionMatched := false
+	for _, pattern := range s.Actions {
+		if matchWildcard(strings.ToLower(pattern), strings.ToLower(string(action))) {
+			actionMatched = true
+			break
+		}
+	}
+	if !actionMatched {
+		return false
+	}
+	for _, pattern := range s.Resources {
+		if matchWildcard(pattern, resource) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchWildcard matches value against pattern, where "*" matches any run of
+// characters and "?" any single character.
+func matchWildcard(pattern, value string) bool {
+	// Iterative matching with backtracking to the last "*"
+	p, v := 0, 0
+	star, match := -1, 0
+	for v < len(value) {
+		switch {
+		case p < len(pattern) && (pattern[p] == '?' || pattern[p] == value[v]):
+			p++
+			v++
+		case p < len(pattern) && pattern[p] == '*':
+			star, match = p, v
+			p++
+		case star >= 0:
+			p = star + 1
+			match++
+			v = match
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const publicReadPolicy = `{
+	"Version": "2012-10-17",
+	"Statement": [{
+		"Sid": "PublicRead",
+		"Effect": "Allow",
+		"Principal": "*",
+		"Action": "s3:GetObject",
+		"Resource": "arn:aws:s3:::photos/*"
+	}]
+}`
+
+func TestParsePolicy(t *testing.T) {
+	policy, err := ParsePolicy("photos", []byte(publicReadPolicy))
+	require.NoError(t, err)
+	require.Len(t, policy.Statements, 1)
+	assert.Equal(t, PolicyAllow, policy.Statements[0].Effect)
+	assert.Equal(t, []string{"s3:GetObject"}, policy.Statements[0].Actions)
+
+	// A single statement object and the {"AWS": "*"} principal are accepted
+	_, err = ParsePolicy("photos", []byte(`{"Statement": {"Effect": "Deny", "Principal": {"AWS": ["*"]}, "Action": ["s3:*"], "Resource": ["arn:aws:s3:::photos"]}}`))
+	require.NoError(t, err)
+
+	malformed := map[string]string{
+		"invalid JSON":        `{"Statement": [`,
+		"unknown field":       `{"Statement": [], "Extra": true}`,
+		"bad version":         `{"Version": "2020-01-01", "Statement": {"Effect": "Allow", "Principal": "*", "Action": "s3:GetObject", "Resource": "arn:aws:s3:::photos/*"}}`,
+		"no statements":       `{"Version": "2012-10-17", "Statement": []}`,
+		"bad effect":          `{"Statement": {"Effect": "Maybe", "Principal": "*", "Action": "s3:GetObject", "Resource": "arn:aws:s3:::photos/*"}}`,
+		"specific principal":  `{"Statement": {"Effect": "Allow", "Principal": {"AWS": "arn:aws:iam::1:root"}, "Action": "s3:GetObject", "Resource": "arn:aws:s3:::photos/*"}}`,
+		"condition":           `{"Statement": {"Effect": "Allow", "Principal": "*", "Action": "s3:GetObject", "Resource": "arn:aws:s3:::photos/*", "Condition": {}}}`,
+		"non-s3 action":       `{"Statement": {"Effect": "Allow", "Principal": "*", "Action": "iam:PassRole", "Resource": "arn:aws:s3:::photos/*"}}`,
+		"other bucket":        `{"Statement": {"Effect": "Allow", "Principal": "*", "Action": "s3:GetObject", "Resource": "arn:aws:s3:::other/*"}}`,
+		"missing resource":    `{"Statement": {"Effect": "Allow", "Principal": "*", "Action": "s3:GetObject"}}`,
+		"action not a string": `{"Statement": {"Effect": "Allow", "Principal": "*", "Action": 7, "Resource": "arn:aws:s3:::photos/*"}}`,
+	}
+	for name, document := range malformed {
+		_, err := ParsePolicy("photos", []byte(document))
+		assert.ErrorIs(t, err, ErrMalformedPolicy, name)
+	}
+}
+
+func TestPolicy_Evaluate(t *testing.T) {
+	policy, err := ParsePolicy("photos", []byte(`{
+		"Version": "2012-10-17",
+		"Statement": [
+			{"Effect": "Allow", "Principal": "*", "Action": ["s3:Get*", "s3:ListBucket"], "Resource": ["arn:aws:s3:::photos", "arn:aws:s3:::photos/*"]},
+			{"Effect": "Deny", "Principal": "*", "Action": "s3:GetObject", "Resource": "arn:aws:s3:::photos/private/*"}
+		]
+	}`))
+	require.NoError(t, err)
+
+	tests := []struct {
+		action   Action
+		resource string
+		want     PolicyDecision
+	}{
+		{ActionGetObject, ObjectARN("photos", "2024/cat.jpg"), PolicyAllowed},
+		{Action("S3:GETOBJECT"), ObjectARN("photos", "cat.jpg"), PolicyAllowed},
+		{ActionListBucket, BucketARN("photos"), PolicyAllowed},
+		{ActionGetObject, ObjectARN("photos", "private/diary.txt"), PolicyDenied},
+		{ActionPutObject, ObjectARN("photos", "cat.jpg"), PolicyNoDecision},
+		{ActionDeleteBucket, BucketARN("photos"), PolicyNoDecision},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, policy.Evaluate(tt.action, tt.resource), "%s %s", tt.action, tt.resource)
+	}
+}
+
+func TestMatchWildcard(t *testing.T) {
+	assert.True(t, matchWildcard("*", ""))
+	assert.True(t, matchWildcard("a*c", "abbbc"))
+	assert.True(t, matchWildcard("a?c", "abc"))
+	assert.True(t, matchWildcard("*/*.jpg", "2024/cat.jpg"))
+	assert.False(t, matchWildcard("a?c", "ac"))
+	assert.False(t, matchWildcard("*.jpg", "cat.png"))
+}
+
+// staticPolicyLookup serves one policy for every bucket.
+type staticPolicyLookup struct {
+	policy *Policy
+}
+
+func (l staticPolicyLookup) GetBucketPolicy(context.Context, string) (*Policy, error) {
+	return l.policy, nil
+}
+
+// staticOwnerLookup reports every bucket as owned by owner.
+type staticOwnerLookup struct {
+	owner int64
+}
+
+func (l staticOwnerLookup) GetBucketOwner(context.Context, string) (int64, error) {
+	return l.owner, nil
+}
+
+func TestDefaultAuthorizer_BucketPolicy(t *testing.T) {
+	ctx := context.Background()
+	policy, err := ParsePolicy("photos", []byte(publicReadPolicy))
+	require.NoError(t, err)
+
+	authorizer := NewDefaultAuthorizer(staticOwnerLookup{owner: 1})
+	authorizer.SetPolicyLookup(staticPolicyLookup{policy: policy})
+
+	anonymous := &AuthContext{AuthType: AuthTypeAnonymous}
+	owner := &AuthContext{AuthType: AuthTypeSignedV4, UserID: 1}
+
+	// The policy grants anonymous reads and nothing else
+	assert.NoError(t, authorizer.Authorize(ctx, anonymous, ActionGetObject, ObjectARN("photos", "cat.jpg")))
+	assert.ErrorIs(t, authorizer.Authorize(ctx, anonymous, ActionPutObject, ObjectARN("photos", "cat.jpg")), ErrAccessDenied)
+	assert.ErrorIs(t, authorizer.Authorize(ctx, anonymous, ActionListBucket, BucketARN("photos")), ErrAccessDenied)
+	assert.ErrorIs(t, authorizer.Authorize(ctx, anonymous, ActionListAllMyBuckets, ""), ErrAccessDenied)
+
+	// Managing the policy stays with the owner
+	assert.ErrorIs(t, authorizer.Authorize(ctx, anonymous, ActionGetBucketPolicy, BucketARN("photos")), ErrAccessDenied)
+	assert.NoError(t, authorizer.Authorize(ctx, owner, ActionPutBucketPolicy, BucketARN("photos")))
+	assert.NoError(t, authorizer.Authorize(ctx, owner, ActionPutObject, ObjectARN("photos", "cat.jpg")))
+}
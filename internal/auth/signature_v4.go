@@ -56,7 +56,7 @@ func GetCanonicalRequest(r *http.Request, signedHeaders []string, payloadHash st
 		r.Method,
 		getCanonicalURI(r.URL.Path),
 		getCanonicalQueryString(r.URL.Query()),
-		getCanonicalHeaders(r.Header, signedHeaders),
+		getCanonicalHeaders(r, signedHeaders),
 		strings.Join(signedHeaders, ";"),
 		payloadHash,
 	)
@@ -110,20 +110,30 @@ func getCanonicalQueryString(query url.Values) string {
 		values := query[key]
 		sort.Strings(values)
 		for _, value := range values {
-			pairs = append(pairs, url.QueryEscape(key)+"="+url.QueryEscape(value))
+			pairs = append(pairs, uriEncode(key)+"="+uriEncode(value))
 		}
 	}
 
 	return strings.Join(pairs, "&")
 }
 
+// uriEncode encodes a query string key or value as SigV4 requires: every
+// byte except unreserved characters is percent-encoded, spaces as %20.
+func uriEncode(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
 // getCanonicalHeaders builds the canonical headers string.
-func getCanonicalHeaders(headers http.Header, signedHeaders []string) string {
+func getCanonicalHeaders(r *http.Request, signedHeaders []string) string {
 	var canonical strings.Builder
 
 	for _, header := range signedHeaders {
-		// Get header value (headers are case-insensitive)
-		value := headers.Get(header)
+		// Get header value (headers are case-insensitive). The server moves
+		// the Host header out of r.Header
+		value := r.Header.Get(header)
+		if strings.EqualFold(header, "host") {
+			value = r.Host
+		}
 
 		// Trim and collapse whitespace
 		value = strings.TrimSpace(value)
@@ -166,19 +176,7 @@ func VerifySignature(
 	signedValues SignedValues,
 	payloadHash string,
 ) error {
-	// Build canonical request
-	canonicalRequest := GetCanonicalRequest(r, signedValues.SignedHeaders, payloadHash)
-
-	// Build string to sign
-	requestTime := signedValues.Credential.Scope.Date
-	// Try to get more precise time from X-Amz-Date header
-	if dateStr := r.Header.Get(XAmzDateHeader); dateStr != "" {
-		if t, err := time.Parse(ISO8601BasicFormat, dateStr); err == nil {
-			requestTime = t
-		}
-	}
-
-	stringToSign := GetStringToSign(canonicalRequest, requestTime, signedValues.Credential.Scope)
+	_, stringToSign := buildStringToSign(r, signedValues, payloadHash)
 
 	// Get signing key
 	signingKey := GetSigningKey(
@@ -199,6 +197,65 @@ func VerifySignature(
 	return nil
 }
 
+// buildStringToSign builds the canonical request and the string to sign of r.
+func buildStringToSign(r *http.Request, signedValues SignedValues, payloadHash string) (canonicalRequest, stringToSign string) {
+	canonicalRequest = GetCanonicalRequest(r, signedValues.SignedHeaders, payloadHash)
+
+	requestTime := signedValues.Credential.Scope.Date
+	// Try to get more precise time from the X-Amz-Date header, or the query
+	// parameter of a presigned URL
+	if dateStr := r.Header.Get(XAmzDateHeader); dateStr != "" {
+		if t, err := time.Parse(ISO8601BasicFormat, dateStr); err == nil {
+			requestTime = t
+		}
+	} else if dateStr := r.URL.Query().Get(XAmzDateHeader); dateStr != "" {
+		if t, err := time.Parse(ISO8601BasicFormat, dateStr); err == nil {
+			requestTime = t
+		}
+	}
+
+	stringToSign = GetStringToSign(canonicalRequest, requestTime, signedValues.Credential.Scope)
+	return canonicalRequest, stringToSign
+}
+
+// DescribeSignature computes the canonical request and string to sign of a
+// signed or presigned request exactly as VerifySignature does, so clients can
+// diff them against their own when troubleshooting SignatureDoesNotMatch.
+// No secret key is involved.
+func DescribeSignature(r *http.Request) (*SignatureDebugInfo, error) {
+	var signedValues *SignedValues
+	var err error
+
+	authType := GetAuthType(r)
+	switch authType {
+	case AuthTypeSignedV4:
+		signedValues, err = ParseSignV4(r.Header.Get(AuthorizationHeader))
+	case AuthTypePresignedV4:
+		signedValues, _, err = ParsePresignedV4(r)
+	default:
+		return nil, ErrInvalidAuthorizationHeader
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	payloadHash := GetPayloadHash(r)
+	if authType == AuthTypePresignedV4 {
+		payloadHash = GetPresignedPayloadHash(r)
+	}
+	canonicalRequest, stringToSign := buildStringToSign(r, *signedValues, payloadHash)
+
+	return &SignatureDebugInfo{
+		AuthType:         authType.String(),
+		AccessKeyID:      signedValues.Credential.AccessKey,
+		CredentialScope:  signedValues.Credential.Scope.String(),
+		SignedHeaders:    signedValues.SignedHeaders,
+		PayloadHash:      payloadHash,
+		CanonicalRequest: canonicalRequest,
+		StringToSign:     stringToSign,
+	}, nil
+}
+
 // =============================================================================
 // Content Hash Extraction
 // =============================================================================
@@ -219,6 +276,16 @@ func GetPayloadHash(r *http.Request) string {
 	return UnsignedPayload
 }
 
+// GetPresignedPayloadHash returns the payload hash of a presigned URL. The
+// body is unknown when the URL is signed, so it is unsigned unless the URL
+// carries an X-Amz-Content-Sha256 parameter.
+func GetPresignedPayloadHash(r *http.Request) string {
+	if hash := r.URL.Query().Get(XAmzContentSHA256Header); hash != "" {
+		return hash
+	}
+	return UnsignedPayload
+}
+
 // =============================================================================
 // Time Validation
 // =============================================================================
@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// presignGetObject presigns a GetObject of key in the "photos" bucket the
+// way the AWS SDK does for a client of serverURL.
+func presignGetObject(t *testing.T, serverURL, key string, expires time.Duration) string {
+	t.Helper()
+
+	client := s3.New(s3.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(serverURL),
+		UsePathStyle: true,
+		Credentials:  credentials.NewStaticCredentialsProvider(testV2AccessKey, testV2SecretKey, ""),
+	})
+	req, err := s3.NewPresignClient(client).PresignGetObject(context.Background(), &s3.GetObjectInput{
+		Bucket: aws.String("photos"),
+		Key:    aws.String(key),
+	}, s3.WithPresignExpires(expires))
+	require.NoError(t, err)
+	return req.URL
+}
+
+func TestMiddleware_PresignedV4(t *testing.T) {
+	var authCtx *AuthContext
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authCtx = GetAuthContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+	server := httptest.NewServer(Middleware(staticKeyStore{}, DefaultConfig())(next))
+	defer server.Close()
+
+	get := func(t *testing.T, rawURL string) (int, string) {
+		t.Helper()
+		resp, err := http.Get(rawURL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(body)
+	}
+
+	t.Run("valid URL", func(t *testing.T) {
+		authCtx = nil
+		status, body := get(t, presignGetObject(t, server.URL, "albums/summer trip/beach.jpg", 15*time.Minute))
+		require.Equal(t, http.StatusOK, status, body)
+		require.NotNil(t, authCtx)
+		assert.Equal(t, AuthTypePresignedV4, authCtx.AuthType)
+		assert.Equal(t, int64(7), authCtx.UserID)
+	})
+
+	t.Run("tampered URL", func(t *testing.T) {
+		u, err := url.Parse(presignGetObject(t, server.URL, "cat.jpg", 15*time.Minute))
+		require.NoError(t, err)
+		u.Path = "/photos/dog.jpg"
+		status, body := get(t, u.String())
+		assert.Equal(t, http.StatusForbidden, status)
+		assert.Contains(t, body, string(S3ErrorSignatureDoesNotMatch))
+	})
+
+	t.Run("expired URL", func(t *testing.T) {
+		u, err := url.Parse(presignGetObject(t, server.URL, "cat.jpg", time.Minute))
+		require.NoError(t, err)
+		query := u.Query()
+		query.Set(XAmzDateHeader, time.Now().UTC().Add(-time.Hour).Format(ISO8601BasicFormat))
+		u.RawQuery = query.Encode()
+		status, body := get(t, u.String())
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.Contains(t, body, string(S3ErrorExpiredToken))
+	})
+
+	t.Run("expiry beyond seven days", func(t *testing.T) {
+		u, err := url.Parse(presignGetObject(t, server.URL, "cat.jpg", time.Minute))
+		require.NoError(t, err)
+		query := u.Query()
+		query.Set(XAmzExpiresHeader, "604801")
+		u.RawQuery = query.Encode()
+		status, body := get(t, u.String())
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.Contains(t, body, string(S3ErrorAuthorizationQueryParametersError))
+	})
+}
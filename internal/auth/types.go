@@ -2,6 +2,7 @@
 package auth
 
 import (
+	"context"
 	"time"
 )
 
@@ -78,6 +79,9 @@ const (
 
 	// AuthTypeStreamingSigned indicates chunked upload with streaming signature.
 	AuthTypeStreamingSigned
+
+	// AuthTypeSignedV2 indicates legacy AWS Signature Version 2 in the Authorization header.
+	AuthTypeSignedV2
 )
 
 // String returns the string representation of the auth type.
@@ -91,6 +95,8 @@ func (at AuthType) String() string {
 		return "PresignedV4"
 	case AuthTypeStreamingSigned:
 		return "StreamingSigned"
+	case AuthTypeSignedV2:
+		return "SignedV2"
 	default:
 		return "Unknown"
 	}
@@ -123,6 +129,38 @@ type AuthContext struct {
 
 	// Region is the region from the credential scope.
 	Region string
+
+	// NamespaceBucket is the bucket in which the access key is confined to NamespacePrefix.
+	// Empty means the key is not namespace-restricted.
+	NamespaceBucket string
+
+	// NamespacePrefix is the object key prefix the access key may access in NamespaceBucket.
+	NamespacePrefix string
+
+	// RateLimitRPS and RateLimitBurst override the server's request rate
+	// limit for the access key. Zero uses the server default.
+	RateLimitRPS   float64
+	RateLimitBurst int
+
+	// grantedBuckets are the buckets the authorizer opened to the principal
+	// during the request through a bucket policy or ACL rather than ownership.
+	grantedBuckets map[string]bool
+}
+
+// GrantBucketAccess records that bucket was opened to the principal by its
+// policy or ACL, so services do not refuse it for not owning the bucket.
+func (a *AuthContext) GrantBucketAccess(bucket string) {
+	if a.grantedBuckets == nil {
+		a.grantedBuckets = make(map[string]bool)
+	}
+	a.grantedBuckets[bucket] = true
+}
+
+// BucketAccessGranted reports whether the authorizer opened bucket to the
+// principal of ctx through a bucket policy or ACL.
+func BucketAccessGranted(ctx context.Context, bucket string) bool {
+	principal := GetAuthContext(ctx)
+	return principal != nil && principal.grantedBuckets[bucket]
 }
 
 // authContextKey is the context key for AuthContext.
@@ -131,6 +169,10 @@ type authContextKey struct{}
 // AuthContextKey is the key used to store AuthContext in request context.
 var AuthContextKey = authContextKey{}
 
+// virtualHostBucketKey is the context key for the bucket named by the Host
+// header of a virtual-hosted-style request.
+type virtualHostBucketKey struct{}
+
 // =============================================================================
 // Presigned URL Types
 // =============================================================================
@@ -229,3 +271,28 @@ func (sts StringToSign) String() string {
 		sts.CredentialScope + "\n" +
 		sts.CanonicalRequestHash
 }
+
+// SignatureDebugInfo describes how the server computed the signature of a request.
+// It never contains the secret key or anything derived from it.
+type SignatureDebugInfo struct {
+	// AuthType is the authentication type of the request.
+	AuthType string `json:"authType"`
+
+	// AccessKeyID is the access key the request was signed with.
+	AccessKeyID string `json:"accessKeyId"`
+
+	// CredentialScope is the credential scope string.
+	CredentialScope string `json:"credentialScope"`
+
+	// SignedHeaders is the list of signed headers.
+	SignedHeaders []string `json:"signedHeaders"`
+
+	// PayloadHash is the payload hash used in the canonical request.
+	PayloadHash string `json:"payloadHash"`
+
+	// CanonicalRequest is the canonical request the server computed.
+	CanonicalRequest string `json:"canonicalRequest"`
+
+	// StringToSign is the string to sign the server computed.
+	StringToSign string `json:"stringToSign"`
+}
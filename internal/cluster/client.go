@@ -6,11 +6,15 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"net/http"
 	"sync"
 	"time"
 
 	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/prn-tf/alexander-storage/internal/cluster/proto"
 )
 
 // ClientConfig contains configuration for connecting to a remote node.
@@ -29,6 +33,9 @@ type ClientConfig struct {
 
 	// RetryDelay is the delay between retries.
 	RetryDelay time.Duration
+
+	// Security must match the remote node's.
+	Security Security
 }
 
 // DefaultClientConfig returns sensible defaults.
@@ -40,18 +47,21 @@ func DefaultClientConfig() ClientConfig {
 	}
 }
 
-// Client implements NodeClient for communicating with a remote node.
-// Note: This is a simplified HTTP-based client. Full gRPC implementation
-// requires generated protobuf code.
+// Client implements NodeClient over the NodeService gRPC API of a remote node.
+// Unary calls are bounded by Timeout and retried on transient failures;
+// streams are bounded by the caller's context, with Timeout applied to
+// opening them.
 type Client struct {
-	config     ClientConfig
-	logger     zerolog.Logger
-	httpClient *http.Client
-	mu         sync.RWMutex
-	closed     bool
+	config ClientConfig
+	logger zerolog.Logger
+	conn   *grpc.ClientConn
+	rpc    pb.NodeServiceClient
+	mu     sync.RWMutex
+	closed bool
 }
 
-// NewClient creates a new client for communicating with a remote node.
+// NewClient creates a new client for communicating with a remote node. The
+// connection is established lazily on the first call.
 func NewClient(config ClientConfig, logger zerolog.Logger) (*Client, error) {
 	if config.Address == "" {
 		return nil, errors.New("address is required")
@@ -66,55 +76,119 @@ func NewClient(config ClientConfig, logger zerolog.Logger) (*Client, error) {
 		config.RetryDelay = DefaultClientConfig().RetryDelay
 	}
 
+	conn, err := grpc.NewClient(config.Address, config.Security.dialOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrConnectionFailed, err)
+	}
+
 	return &Client{
 		config: config,
 		logger: logger.With().
 			Str("component", "cluster-client").
 			Str("remote_address", config.Address).
 			Logger(),
-		httpClient: &http.Client{
-			Timeout: config.Timeout,
-		},
+		conn: conn,
+		rpc:  pb.NewNodeServiceClient(conn),
 	}, nil
 }
 
-// Ping checks if the node is alive and returns its status.
-func (c *Client) Ping(ctx context.Context) (*Node, error) {
+// checkOpen returns an error once the client is closed.
+func (c *Client) checkOpen() error {
 	c.mu.RLock()
+	defer c.mu.RUnlock()
+
 	if c.closed {
-		c.mu.RUnlock()
-		return nil, errors.New("client is closed")
+		return errors.New("client is closed")
 	}
-	c.mu.RUnlock()
+	return nil
+}
+
+// retry runs call until it succeeds, fails permanently or MaxRetries attempts
+// are used up. Each attempt gets its own Timeout.
+func (c *Client) retry(ctx context.Context, method string, call func(ctx context.Context) error) error {
+	var err error
+	for attempt := 0; attempt < c.config.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(c.config.RetryDelay):
+			}
+		}
 
-	// TODO: Implement actual gRPC call when protobuf is generated
-	// For now, return a placeholder indicating the node is reachable
-	c.logger.Debug().Msg("Ping request")
+		attemptCtx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+		err = call(attemptCtx)
+		cancel()
 
-	return &Node{
-		ID:            c.config.NodeID,
+		if err == nil || !isRetryable(err) || ctx.Err() != nil {
+			break
+		}
+
+		c.logger.Debug().
+			Err(err).
+			Str("method", method).
+			Int("attempt", attempt+1).
+			Msg("Retrying cluster call")
+	}
+	return fromStatus(err)
+}
+
+// Ping checks if the node is alive and returns its status.
+func (c *Client) Ping(ctx context.Context) (*Node, error) {
+	if err := c.checkOpen(); err != nil {
+		return nil, err
+	}
+
+	var resp *pb.PingResponse
+	err := c.retry(ctx, "Ping", func(ctx context.Context) error {
+		var err error
+		resp, err = c.rpc.Ping(ctx, &pb.PingRequest{})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	node := &Node{
+		ID:            resp.GetNodeId(),
 		Address:       c.config.Address,
-		Status:        NodeStatusHealthy,
+		Role:          NodeRole(resp.GetRole()),
+		Status:        NodeStatus(resp.GetStatus()),
 		LastHeartbeat: time.Now(),
-	}, nil
+	}
+	if stats := resp.GetStorageStats(); stats != nil {
+		node.Stats = &StorageStats{
+			TotalBytes: stats.GetTotalBytes(),
+			UsedBytes:  stats.GetUsedBytes(),
+			FreeBytes:  stats.GetFreeBytes(),
+			BlobCount:  stats.GetBlobCount(),
+		}
+	}
+	return node, nil
 }
 
-// TransferBlob transfers a blob to this node.
+// TransferBlob transfers a blob to this node. A failed transfer is only
+// retried when nothing was read from reader yet or reader is an io.Seeker
+// that can be rewound.
 func (c *Client) TransferBlob(ctx context.Context, contentHash string, size int64, reader io.Reader) error {
-	c.mu.RLock()
-	if c.closed {
-		c.mu.RUnlock()
-		return errors.New("client is closed")
+	if err := c.checkOpen(); err != nil {
+		return err
 	}
-	c.mu.RUnlock()
 
 	c.logger.Debug().
 		Str("content_hash", contentHash).
 		Int64("size", size).
 		Msg("Initiating blob transfer")
 
-	// TODO: Implement actual gRPC streaming call
-	// For now, simulate transfer with retry logic
+	seeker, _ := reader.(io.Seeker)
+	var start int64
+	if seeker != nil {
+		var err error
+		if start, err = seeker.Seek(0, io.SeekCurrent); err != nil {
+			seeker = nil
+		}
+	}
+
 	var lastErr error
 	for attempt := 0; attempt < c.config.MaxRetries; attempt++ {
 		if attempt > 0 {
@@ -123,59 +197,103 @@ func (c *Client) TransferBlob(ctx context.Context, contentHash string, size int6
 				return ctx.Err()
 			case <-time.After(c.config.RetryDelay):
 			}
+			if seeker != nil {
+				if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+					break
+				}
+			}
 		}
 
-		// Read all data (for retry capability)
-		data, err := io.ReadAll(reader)
-		if err != nil {
-			lastErr = fmt.Errorf("failed to read blob data: %w", err)
-			continue
+		consumed, err := c.transfer(ctx, contentHash, size, reader)
+		if err == nil {
+			c.logger.Info().
+				Str("content_hash", contentHash).
+				Int64("size", size).
+				Int("attempt", attempt+1).
+				Msg("Blob transferred")
+			return nil
 		}
+		lastErr = err
 
-		if int64(len(data)) != size {
-			lastErr = fmt.Errorf("size mismatch: expected %d, got %d", size, len(data))
-			continue
+		if !isRetryable(err) || ctx.Err() != nil || (consumed && seeker == nil) {
+			break
 		}
+	}
 
-		// TODO: Send via gRPC
-		c.logger.Info().
-			Str("content_hash", contentHash).
-			Int64("size", size).
-			Int("attempt", attempt+1).
-			Msg("Blob transfer simulated (gRPC not implemented)")
+	return fmt.Errorf("%w: %w", ErrTransferFailed, fromStatus(lastErr))
+}
 
-		return nil
+// transfer streams the blob once. consumed reports whether reader was read.
+func (c *Client) transfer(ctx context.Context, contentHash string, size int64, reader io.Reader) (consumed bool, err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := c.rpc.TransferBlob(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	err = stream.Send(&pb.TransferBlobRequest{Payload: &pb.TransferBlobRequest_Metadata{
+		Metadata: &pb.BlobMetadata{ContentHash: contentHash, Size: size},
+	}})
+	if err != nil {
+		return false, c.streamError(stream, err)
+	}
+
+	for {
+		// gRPC may hold on to a sent message, so every chunk gets its own buffer
+		buf := make([]byte, transferChunkSize)
+		n, readErr := io.ReadFull(reader, buf)
+		consumed = true
+		if n > 0 {
+			err := stream.Send(&pb.TransferBlobRequest{Payload: &pb.TransferBlobRequest_DataChunk{
+				DataChunk: buf[:n],
+			}})
+			if err != nil {
+				return true, c.streamError(stream, err)
+			}
+		}
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if readErr != nil {
+			return true, fmt.Errorf("failed to read blob data: %w", readErr)
+		}
 	}
 
-	return fmt.Errorf("%w: %v", ErrTransferFailed, lastErr)
+	_, err = stream.CloseAndRecv()
+	return true, err
+}
+
+// streamError returns the status of a stream whose Send failed. Send returns
+// io.EOF when the server ended the stream; the reason comes from the response.
+func (c *Client) streamError(stream pb.NodeService_TransferBlobClient, err error) error {
+	if err == io.EOF {
+		_, err = stream.CloseAndRecv()
+	}
+	return err
 }
 
 // RetrieveBlob retrieves a blob from this node.
 func (c *Client) RetrieveBlob(ctx context.Context, contentHash string) (io.ReadCloser, error) {
-	c.mu.RLock()
-	if c.closed {
-		c.mu.RUnlock()
-		return nil, errors.New("client is closed")
+	if err := c.checkOpen(); err != nil {
+		return nil, err
 	}
-	c.mu.RUnlock()
 
 	c.logger.Debug().
 		Str("content_hash", contentHash).
 		Msg("Retrieving blob")
 
-	// TODO: Implement actual gRPC streaming call
-	// For now, return an error indicating not implemented
-	return nil, errors.New("gRPC not implemented - requires protobuf generation")
+	return c.openBlobStream(ctx, "RetrieveBlob", func(ctx context.Context) (blobStream, error) {
+		return c.rpc.RetrieveBlob(ctx, &pb.RetrieveBlobRequest{ContentHash: contentHash})
+	})
 }
 
 // RetrieveBlobRange retrieves a range of bytes from a blob.
 func (c *Client) RetrieveBlobRange(ctx context.Context, contentHash string, offset, length int64) (io.ReadCloser, error) {
-	c.mu.RLock()
-	if c.closed {
-		c.mu.RUnlock()
-		return nil, errors.New("client is closed")
+	if err := c.checkOpen(); err != nil {
+		return nil, err
 	}
-	c.mu.RUnlock()
 
 	c.logger.Debug().
 		Str("content_hash", contentHash).
@@ -183,46 +301,121 @@ func (c *Client) RetrieveBlobRange(ctx context.Context, contentHash string, offs
 		Int64("length", length).
 		Msg("Retrieving blob range")
 
-	// TODO: Implement actual gRPC streaming call
-	return nil, errors.New("gRPC not implemented - requires protobuf generation")
+	return c.openBlobStream(ctx, "RetrieveBlobRange", func(ctx context.Context) (blobStream, error) {
+		return c.rpc.RetrieveBlobRange(ctx, &pb.RetrieveBlobRangeRequest{
+			ContentHash: contentHash,
+			Offset:      offset,
+			Length:      length,
+		})
+	})
+}
+
+// blobStream is a server stream of blob metadata followed by data chunks.
+type blobStream interface {
+	Recv() (*pb.RetrieveBlobResponse, error)
+}
+
+// openBlobStream opens a blob stream and waits for its metadata, so a missing
+// blob is reported before any data is read. Opening is retried like a unary
+// call; once data flows the stream is bounded only by ctx.
+func (c *Client) openBlobStream(ctx context.Context, method string, open func(ctx context.Context) (blobStream, error)) (io.ReadCloser, error) {
+	var (
+		stream       blobStream
+		streamCancel context.CancelFunc
+	)
+	err := c.retry(ctx, method, func(attemptCtx context.Context) error {
+		// The stream outlives the attempt, so it gets its own context
+		// that the attempt timeout cancels until the metadata arrives
+		sctx, cancel := context.WithCancel(ctx)
+		stop := context.AfterFunc(attemptCtx, cancel)
+
+		s, err := open(sctx)
+		if err == nil {
+			var first *pb.RetrieveBlobResponse
+			first, err = s.Recv()
+			if err == nil && first.GetMetadata() == nil {
+				err = status.Error(codes.Internal, "stream did not start with blob metadata")
+			}
+		}
+		if !stop() || err != nil {
+			cancel()
+			if ctxErr := attemptCtx.Err(); ctxErr != nil {
+				// Report the timeout rather than the cancellation it caused
+				err = status.FromContextError(ctxErr).Err()
+			}
+			return err
+		}
+
+		stream, streamCancel = s, cancel
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &blobStreamReader{
+		chunkReader: chunkReader{recv: func() ([]byte, error) {
+			msg, err := stream.Recv()
+			if err != nil {
+				if err == io.EOF {
+					return nil, io.EOF
+				}
+				return nil, fromStatus(err)
+			}
+			return msg.GetDataChunk(), nil
+		}},
+		cancel: streamCancel,
+	}, nil
+}
+
+// blobStreamReader reads a blob stream; Close cancels the stream.
+type blobStreamReader struct {
+	chunkReader
+	cancel context.CancelFunc
+}
+
+// Close implements io.Closer.
+func (r *blobStreamReader) Close() error {
+	r.cancel()
+	return nil
 }
 
 // DeleteBlob deletes a blob from this node.
 func (c *Client) DeleteBlob(ctx context.Context, contentHash string) error {
-	c.mu.RLock()
-	if c.closed {
-		c.mu.RUnlock()
-		return errors.New("client is closed")
+	if err := c.checkOpen(); err != nil {
+		return err
 	}
-	c.mu.RUnlock()
 
 	c.logger.Debug().
 		Str("content_hash", contentHash).
 		Msg("Deleting blob")
 
-	// TODO: Implement actual gRPC call
-	c.logger.Info().
-		Str("content_hash", contentHash).
-		Msg("Blob deletion simulated (gRPC not implemented)")
-
-	return nil
+	return c.retry(ctx, "DeleteBlob", func(ctx context.Context) error {
+		_, err := c.rpc.DeleteBlob(ctx, &pb.DeleteBlobRequest{ContentHash: contentHash})
+		return err
+	})
 }
 
 // BlobExists checks if a blob exists on this node.
 func (c *Client) BlobExists(ctx context.Context, contentHash string) (bool, error) {
-	c.mu.RLock()
-	if c.closed {
-		c.mu.RUnlock()
-		return false, errors.New("client is closed")
+	if err := c.checkOpen(); err != nil {
+		return false, err
 	}
-	c.mu.RUnlock()
 
 	c.logger.Debug().
 		Str("content_hash", contentHash).
 		Msg("Checking blob existence")
 
-	// TODO: Implement actual gRPC call
-	return false, errors.New("gRPC not implemented - requires protobuf generation")
+	var exists bool
+	err := c.retry(ctx, "BlobExists", func(ctx context.Context) error {
+		resp, err := c.rpc.BlobExists(ctx, &pb.BlobExistsRequest{ContentHash: contentHash})
+		if err != nil {
+			return err
+		}
+		exists = resp.GetExists()
+		return nil
+	})
+	return exists, err
 }
 
 // Close closes the client connection.
@@ -235,23 +428,24 @@ func (c *Client) Close() error {
 	}
 
 	c.closed = true
-	c.httpClient.CloseIdleConnections()
 	c.logger.Debug().Msg("Client closed")
-	return nil
+	return c.conn.Close()
 }
 
 // ClientPool manages a pool of clients to remote nodes.
 type ClientPool struct {
-	mu      sync.RWMutex
-	clients map[string]*Client // nodeID -> client
-	logger  zerolog.Logger
+	mu       sync.RWMutex
+	clients  map[string]*Client // nodeID -> client
+	security Security
+	logger   zerolog.Logger
 }
 
-// NewClientPool creates a new client pool.
-func NewClientPool(logger zerolog.Logger) *ClientPool {
+// NewClientPool creates a new client pool whose clients connect with security.
+func NewClientPool(security Security, logger zerolog.Logger) *ClientPool {
 	return &ClientPool{
-		clients: make(map[string]*Client),
-		logger:  logger.With().Str("component", "client-pool").Logger(),
+		clients:  make(map[string]*Client),
+		security: security,
+		logger:   logger.With().Str("component", "client-pool").Logger(),
 	}
 }
 
@@ -275,8 +469,9 @@ func (p *ClientPool) GetClient(nodeID, address string) (*Client, error) {
 	}
 
 	client, err := NewClient(ClientConfig{
-		NodeID:  nodeID,
-		Address: address,
+		NodeID:   nodeID,
+		Address:  address,
+		Security: p.security,
 	}, p.logger)
 	if err != nil {
 		return nil, err
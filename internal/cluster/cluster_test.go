@@ -112,7 +112,7 @@ func TestClientPool(t *testing.T) {
 	logger := zerolog.Nop()
 
 	// Create a pool
-	pool := NewClientPool(logger)
+	pool := NewClientPool(Security{}, logger)
 
 	// Get client for a node
 	client1, err := pool.GetClient("node-1", "localhost:9001")
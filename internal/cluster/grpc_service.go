@@ -0,0 +1,247 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/prn-tf/alexander-storage/internal/cluster/proto"
+)
+
+// transferChunkSize is the size of the data chunks streamed between nodes,
+// well below the default 4MB gRPC message limit.
+const transferChunkSize = 256 * 1024
+
+// nodeService exposes a Server over gRPC. It only translates between
+// protobuf messages and the Server methods, which hold the actual logic.
+type nodeService struct {
+	pb.UnimplementedNodeServiceServer
+	server *Server
+}
+
+// Ping implements pb.NodeServiceServer.
+func (n *nodeService) Ping(ctx context.Context, req *pb.PingRequest) (*pb.PingResponse, error) {
+	node, err := n.server.Ping(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	if node == nil {
+		return nil, status.Error(codes.Unavailable, "node is not started")
+	}
+
+	resp := &pb.PingResponse{
+		NodeId:        node.ID,
+		Role:          string(node.Role),
+		Status:        string(node.Status),
+		UptimeSeconds: int64(time.Since(n.server.startTime).Seconds()),
+	}
+	if node.Stats != nil {
+		resp.StorageStats = &pb.StorageStats{
+			TotalBytes: node.Stats.TotalBytes,
+			UsedBytes:  node.Stats.UsedBytes,
+			FreeBytes:  node.Stats.FreeBytes,
+			BlobCount:  node.Stats.BlobCount,
+		}
+	}
+	return resp, nil
+}
+
+// TransferBlob implements pb.NodeServiceServer. The first message carries
+// the blob metadata and the remaining messages its data.
+func (n *nodeService) TransferBlob(stream pb.NodeService_TransferBlobServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	metadata := first.GetMetadata()
+	if metadata == nil || metadata.GetContentHash() == "" {
+		return status.Error(codes.InvalidArgument, "first message must carry the blob metadata")
+	}
+
+	reader := &chunkReader{recv: func() ([]byte, error) {
+		msg, err := stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+		if msg.GetMetadata() != nil {
+			return nil, status.Error(codes.InvalidArgument, "blob metadata sent twice")
+		}
+		return msg.GetDataChunk(), nil
+	}}
+
+	if err := n.server.TransferBlob(stream.Context(), metadata.GetContentHash(), metadata.GetSize(), reader); err != nil {
+		return toStatus(err)
+	}
+
+	return stream.SendAndClose(&pb.TransferBlobResponse{
+		Success:     true,
+		ContentHash: metadata.GetContentHash(),
+	})
+}
+
+// RetrieveBlob implements pb.NodeServiceServer.
+func (n *nodeService) RetrieveBlob(req *pb.RetrieveBlobRequest, stream pb.NodeService_RetrieveBlobServer) error {
+	ctx := stream.Context()
+	reader, err := n.server.RetrieveBlob(ctx, req.GetContentHash())
+	if err != nil {
+		return toStatus(err)
+	}
+	defer reader.Close()
+
+	return n.sendBlob(ctx, req.GetContentHash(), reader, stream)
+}
+
+// RetrieveBlobRange implements pb.NodeServiceServer.
+func (n *nodeService) RetrieveBlobRange(req *pb.RetrieveBlobRangeRequest, stream pb.NodeService_RetrieveBlobRangeServer) error {
+	if req.GetOffset() < 0 || req.GetLength() < 0 {
+		return status.Error(codes.InvalidArgument, "offset and length must not be negative")
+	}
+
+	ctx := stream.Context()
+	reader, err := n.server.RetrieveBlobRange(ctx, req.GetContentHash(), req.GetOffset(), req.GetLength())
+	if err != nil {
+		return toStatus(err)
+	}
+	defer reader.Close()
+
+	return n.sendBlob(ctx, req.GetContentHash(), reader, stream)
+}
+
+// sendBlob streams the blob metadata followed by the data read from reader.
+// The metadata size is always the size of the whole blob.
+func (n *nodeService) sendBlob(ctx context.Context, contentHash string, reader io.Reader, stream interface {
+	Send(*pb.RetrieveBlobResponse) error
+}) error {
+	size, err := n.server.storage.GetSize(ctx, contentHash)
+	if err != nil {
+		return toStatus(err)
+	}
+
+	err = stream.Send(&pb.RetrieveBlobResponse{Payload: &pb.RetrieveBlobResponse_Metadata{
+		Metadata: &pb.BlobMetadata{ContentHash: contentHash, Size: size},
+	}})
+	if err != nil {
+		return err
+	}
+
+	for {
+		// gRPC may hold on to a sent message, so every chunk gets its own buffer
+		buf := make([]byte, transferChunkSize)
+		nr, readErr := io.ReadFull(reader, buf)
+		if nr > 0 {
+			err := stream.Send(&pb.RetrieveBlobResponse{Payload: &pb.RetrieveBlobResponse_DataChunk{
+				DataChunk: buf[:nr],
+			}})
+			if err != nil {
+				return err
+			}
+		}
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			return nil
+		}
+		if readErr != nil {
+			return toStatus(readErr)
+		}
+	}
+}
+
+// DeleteBlob implements pb.NodeServiceServer.
+func (n *nodeService) DeleteBlob(ctx context.Context, req *pb.DeleteBlobRequest) (*pb.DeleteBlobResponse, error) {
+	if err := n.server.DeleteBlob(ctx, req.GetContentHash()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.DeleteBlobResponse{Success: true}, nil
+}
+
+// BlobExists implements pb.NodeServiceServer.
+func (n *nodeService) BlobExists(ctx context.Context, req *pb.BlobExistsRequest) (*pb.BlobExistsResponse, error) {
+	exists, err := n.server.BlobExists(ctx, req.GetContentHash())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.BlobExistsResponse{Exists: exists}, nil
+}
+
+// chunkReader adapts a stream of data chunks to io.Reader. recv returns
+// io.EOF once the stream ends.
+type chunkReader struct {
+	recv func() ([]byte, error)
+	buf  []byte
+	err  error
+}
+
+// Read implements io.Reader.
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		r.buf, r.err = r.recv()
+	}
+
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// toStatus converts a cluster error into a gRPC status error.
+func toStatus(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	switch {
+	case errors.Is(err, ErrBlobNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, ErrTransferFailed):
+		return status.Error(codes.DataLoss, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+// fromStatus converts a gRPC status error into a cluster error.
+func fromStatus(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	switch st.Code() {
+	case codes.OK:
+		return nil
+	case codes.NotFound:
+		return ErrBlobNotFound
+	case codes.DataLoss:
+		return fmt.Errorf("%w: %s", ErrTransferFailed, st.Message())
+	case codes.Unavailable:
+		return fmt.Errorf("%w: %s", ErrNodeUnavailable, st.Message())
+	case codes.Unauthenticated:
+		return ErrUnauthenticated
+	case codes.Canceled:
+		return context.Canceled
+	case codes.DeadlineExceeded:
+		return context.DeadlineExceeded
+	default:
+		return fmt.Errorf("remote node: %s", st.Message())
+	}
+}
+
+// isRetryable reports whether a failed call may succeed when repeated.
+func isRetryable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,245 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/pkg/crypto"
+	"github.com/prn-tf/alexander-storage/internal/storage/filesystem"
+)
+
+// startTestNode starts a cluster server on a random local port backed by
+// filesystem storage and returns it with a client connected to it.
+func startTestNode(t *testing.T, nodeID string, role NodeRole) (*Server, *Client) {
+	t.Helper()
+	server := startTestServer(t, ServerConfig{NodeID: nodeID, Role: role})
+	return server, dialTestNode(t, server.Addr(), Security{})
+}
+
+// startTestServer starts a cluster server with config on a random local
+// port backed by filesystem storage.
+func startTestServer(t *testing.T, config ServerConfig) *Server {
+	t.Helper()
+	dir := t.TempDir()
+
+	backend, err := filesystem.NewStorage(filesystem.Config{
+		DataDir: filepath.Join(dir, "data"),
+		TempDir: filepath.Join(dir, "tmp"),
+	}, zerolog.Nop())
+	require.NoError(t, err)
+
+	config.Address = "127.0.0.1:0"
+	server, err := NewServer(config, backend, zerolog.Nop())
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+	t.Cleanup(func() { server.Stop() })
+	return server
+}
+
+// dialTestNode returns a client connected to address with security.
+func dialTestNode(t *testing.T, address string, security Security) *Client {
+	t.Helper()
+	client, err := NewClient(ClientConfig{
+		Address:    address,
+		Timeout:    5 * time.Second,
+		RetryDelay: 10 * time.Millisecond,
+		Security:   security,
+	}, zerolog.Nop())
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestGRPC_TransferBetweenNodes(t *testing.T) {
+	ctx := context.Background()
+	_, hot := startTestNode(t, "hot-1", NodeRoleHot)
+	_, warm := startTestNode(t, "warm-1", NodeRoleWarm)
+
+	node, err := warm.Ping(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "warm-1", node.ID)
+	require.Equal(t, NodeRoleWarm, node.Role)
+	require.Equal(t, NodeStatusHealthy, node.Status)
+
+	// Several chunks, ending with a partial one
+	data := make([]byte, 3*transferChunkSize+1234)
+	_, err = rand.Read(data)
+	require.NoError(t, err)
+	contentHash := crypto.SHA256Hex(data)
+
+	require.NoError(t, hot.TransferBlob(ctx, contentHash, int64(len(data)), bytes.NewReader(data)))
+
+	// Migrate the blob the way the tiering controller does
+	source, err := hot.RetrieveBlob(ctx, contentHash)
+	require.NoError(t, err)
+	err = warm.TransferBlob(ctx, contentHash, int64(len(data)), source)
+	source.Close()
+	require.NoError(t, err)
+
+	exists, err := warm.BlobExists(ctx, contentHash)
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	reader, err := warm.RetrieveBlob(ctx, contentHash)
+	require.NoError(t, err)
+	got, err := io.ReadAll(reader)
+	reader.Close()
+	require.NoError(t, err)
+	require.Equal(t, data, got)
+
+	offset := int64(transferChunkSize - 10)
+	reader, err = warm.RetrieveBlobRange(ctx, contentHash, offset, 100)
+	require.NoError(t, err)
+	got, err = io.ReadAll(reader)
+	reader.Close()
+	require.NoError(t, err)
+	require.Equal(t, data[offset:offset+100], got)
+
+	require.NoError(t, warm.DeleteBlob(ctx, contentHash))
+	exists, err = warm.BlobExists(ctx, contentHash)
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	_, err = warm.RetrieveBlob(ctx, contentHash)
+	require.ErrorIs(t, err, ErrBlobNotFound)
+}
+
+func TestGRPC_TransferRejectsHashMismatch(t *testing.T) {
+	ctx := context.Background()
+	_, client := startTestNode(t, "hot-1", NodeRoleHot)
+
+	data := []byte("not what the hash says")
+	err := client.TransferBlob(ctx, crypto.SHA256Hex([]byte("other")), int64(len(data)), bytes.NewReader(data))
+	require.ErrorIs(t, err, ErrTransferFailed)
+
+	exists, err := client.BlobExists(ctx, crypto.SHA256Hex(data))
+	require.NoError(t, err)
+	require.False(t, exists)
+}
+
+func TestGRPC_UnreachableNode(t *testing.T) {
+	client, err := NewClient(ClientConfig{
+		Address:    "127.0.0.1:1",
+		Timeout:    time.Second,
+		MaxRetries: 2,
+		RetryDelay: 10 * time.Millisecond,
+	}, zerolog.Nop())
+	require.NoError(t, err)
+	defer client.Close()
+
+	_, err = client.Ping(context.Background())
+	require.ErrorIs(t, err, ErrNodeUnavailable)
+}
+
+func TestGRPC_SharedSecretAuthenticatesNodes(t *testing.T) {
+	ctx := context.Background()
+	server := startTestServer(t, ServerConfig{NodeID: "hot-1", Security: Security{SharedSecret: "cluster-secret"}})
+
+	for _, secret := range []string{"", "wrong-secret"} {
+		client := dialTestNode(t, server.Addr(), Security{SharedSecret: secret})
+		_, err := client.Ping(ctx)
+		require.ErrorIs(t, err, ErrUnauthenticated)
+
+		// Streams are checked as well as unary calls
+		data := []byte("intruder")
+		err = client.TransferBlob(ctx, crypto.SHA256Hex(data), int64(len(data)), bytes.NewReader(data))
+		require.ErrorIs(t, err, ErrUnauthenticated)
+	}
+
+	client := dialTestNode(t, server.Addr(), Security{SharedSecret: "cluster-secret"})
+	node, err := client.Ping(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "hot-1", node.ID)
+
+	data := []byte("member")
+	require.NoError(t, client.TransferBlob(ctx, crypto.SHA256Hex(data), int64(len(data)), bytes.NewReader(data)))
+}
+
+func TestGRPC_MutualTLS(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	member := writeTestCA(t, filepath.Join(dir, "member"))
+	outsider := writeTestCA(t, filepath.Join(dir, "outsider"))
+
+	server := startTestServer(t, ServerConfig{NodeID: "hot-1", Security: Security{TLS: member}})
+
+	client := dialTestNode(t, server.Addr(), Security{TLS: member})
+	node, err := client.Ping(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "hot-1", node.ID)
+
+	// Plaintext clients and certificates from another CA are refused
+	for _, security := range []Security{{}, {TLS: outsider}} {
+		client := dialTestNode(t, server.Addr(), security)
+		_, err := client.Ping(ctx)
+		require.Error(t, err)
+	}
+}
+
+// writeTestCA writes a CA and a certificate it signs for 127.0.0.1 to dir
+// and returns the TLS configuration loaded from them.
+func writeTestCA(t *testing.T, dir string) *tls.Config {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(dir, 0o700))
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	ca, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+
+	nodeKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	nodeDER, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "node"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}, ca, &nodeKey.PublicKey, caKey)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(nodeKey)
+	require.NoError(t, err)
+
+	writePEM := func(name, kind string, der []byte) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: kind, Bytes: der}), 0o600))
+		return path
+	}
+	config, err := LoadTLSConfig(
+		writePEM("node.pem", "CERTIFICATE", nodeDER),
+		writePEM("node-key.pem", "EC PRIVATE KEY", keyDER),
+		writePEM("ca.pem", "CERTIFICATE", caDER),
+	)
+	require.NoError(t, err)
+	return config
+}
@@ -90,6 +90,20 @@ type BlobLocation struct {
 	SyncedAt time.Time `json:"synced_at"`
 }
 
+// BlobLocationRepository persists blob locations so they survive restarts and
+// can be shared by several coordinators.
+type BlobLocationRepository interface {
+	// Upsert creates or replaces the location of a blob on a node.
+	Upsert(ctx context.Context, location *BlobLocation) error
+
+	// Get returns all locations of a blob, or none if it is not tracked.
+	Get(ctx context.Context, contentHash string) ([]*BlobLocation, error)
+
+	// Remove deletes the location of a blob on a node.
+	// Removing an unknown location is not an error.
+	Remove(ctx context.Context, contentHash, nodeID string) error
+}
+
 // NodeClient provides methods for communicating with a remote node.
 type NodeClient interface {
 	// Ping checks if the node is alive.
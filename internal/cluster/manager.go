@@ -0,0 +1,170 @@
+package cluster
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// ManagerConfig configures a StaticManager.
+type ManagerConfig struct {
+	// Peers are the gRPC addresses of the other nodes in the cluster.
+	Peers []string
+
+	// HeartbeatInterval is how often peers are pinged.
+	HeartbeatInterval time.Duration
+
+	// Security is used to connect to peers.
+	Security Security
+}
+
+// StaticManager is a ClusterManager for a fixed list of peers. The node
+// registry and blob locations are kept by the local Server; peers are
+// registered and kept healthy by pinging them every HeartbeatInterval, and
+// the Server marks those that stop answering unhealthy.
+type StaticManager struct {
+	config ManagerConfig
+	server *Server
+	pool   *ClientPool
+	logger zerolog.Logger
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewStaticManager creates a StaticManager over the local server, which must
+// be started before the manager.
+func NewStaticManager(config ManagerConfig, server *Server, logger zerolog.Logger) *StaticManager {
+	if config.HeartbeatInterval <= 0 {
+		config.HeartbeatInterval = DefaultServerConfig().HeartbeatInterval
+	}
+
+	return &StaticManager{
+		config: config,
+		server: server,
+		pool:   NewClientPool(config.Security, logger),
+		logger: logger.With().Str("component", "cluster-manager").Logger(),
+		stopCh: make(chan struct{}),
+	}
+}
+
+// Start registers the peers that answer and keeps pinging all of them in
+// the background until Close.
+func (m *StaticManager) Start(ctx context.Context) error {
+	if err := m.RegisterSelf(ctx); err != nil {
+		return err
+	}
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+
+		ticker := time.NewTicker(m.config.HeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-m.stopCh:
+				return
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), m.config.HeartbeatInterval)
+				_ = m.SendHeartbeat(ctx)
+				cancel()
+			}
+		}
+	}()
+	return nil
+}
+
+// RegisterSelf registers the peers that answer a ping. The local node is
+// registered by the Server when it starts.
+func (m *StaticManager) RegisterSelf(ctx context.Context) error {
+	return m.SendHeartbeat(ctx)
+}
+
+// SendHeartbeat refreshes the local node and pings every peer, registering
+// new ones and recording the storage stats of known ones. Peers that do not
+// answer are left to the Server's health check.
+func (m *StaticManager) SendHeartbeat(ctx context.Context) error {
+	if self := m.server.GetSelfInfo(); self != nil {
+		_ = m.server.UpdateHeartbeat(self.ID, self.Stats)
+	}
+
+	for _, address := range m.config.Peers {
+		client, err := m.pool.GetClient(address, address)
+		if err != nil {
+			m.logger.Warn().Err(err).Str("address", address).Msg("failed to connect to peer")
+			continue
+		}
+
+		node, err := client.Ping(ctx)
+		if err != nil {
+			m.logger.Debug().Err(err).Str("address", address).Msg("peer did not answer ping")
+			continue
+		}
+		node.Address = address
+
+		if _, err := m.server.GetNode(node.ID); err != nil {
+			if err := m.server.RegisterNode(node); err != nil {
+				m.logger.Warn().Err(err).Str("address", address).Msg("failed to register peer")
+			}
+			continue
+		}
+		_ = m.server.UpdateHeartbeat(node.ID, node.Stats)
+	}
+	return nil
+}
+
+// GetNodes returns all known nodes, this one included.
+func (m *StaticManager) GetNodes(ctx context.Context) ([]*Node, error) {
+	return m.server.GetNodes(), nil
+}
+
+// GetNode returns a known node by ID.
+func (m *StaticManager) GetNode(ctx context.Context, nodeID string) (*Node, error) {
+	return m.server.GetNode(nodeID)
+}
+
+// GetNodesByRole returns all known nodes with the given role.
+func (m *StaticManager) GetNodesByRole(ctx context.Context, role NodeRole) ([]*Node, error) {
+	return m.server.GetNodesByRole(role), nil
+}
+
+// GetHealthyNodes returns all healthy nodes, this one included.
+func (m *StaticManager) GetHealthyNodes(ctx context.Context) ([]*Node, error) {
+	return m.server.GetHealthyNodes(), nil
+}
+
+// GetBlobLocations returns all locations of a blob.
+func (m *StaticManager) GetBlobLocations(ctx context.Context, contentHash string) ([]*BlobLocation, error) {
+	return m.server.GetBlobLocations(ctx, contentHash)
+}
+
+// RegisterBlobLocation registers a blob location.
+func (m *StaticManager) RegisterBlobLocation(ctx context.Context, location *BlobLocation) error {
+	return m.server.RegisterBlobLocation(ctx, location)
+}
+
+// RemoveBlobLocation removes a blob location.
+func (m *StaticManager) RemoveBlobLocation(ctx context.Context, contentHash, nodeID string) error {
+	return m.server.RemoveBlobLocation(ctx, contentHash, nodeID)
+}
+
+// GetClientForNode returns a client for a known node.
+func (m *StaticManager) GetClientForNode(ctx context.Context, nodeID string) (NodeClient, error) {
+	node, err := m.server.GetNode(nodeID)
+	if err != nil {
+		return nil, err
+	}
+	return m.pool.GetClient(node.Address, node.Address)
+}
+
+// Close stops pinging peers and closes their clients.
+func (m *StaticManager) Close() error {
+	close(m.stopCh)
+	m.wg.Wait()
+	return m.pool.Close()
+}
+
+var _ ClusterManager = (*StaticManager)(nil)
@@ -0,0 +1,447 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/prn-tf/alexander-storage/internal/storage"
+)
+
+// ReplicationConfig configures a Replicator.
+type ReplicationConfig struct {
+	// Factor is the number of nodes, this one included, that hold each blob.
+	Factor int
+
+	// WriteQuorum is the number of copies, the local one included, written
+	// before a store succeeds. The remaining copies are written in the
+	// background, so a quorum of 1 replicates fully asynchronously.
+	WriteQuorum int
+
+	// TransferTimeout bounds each transfer to another node.
+	TransferTimeout time.Duration
+}
+
+// DefaultReplicationConfig returns sensible defaults.
+func DefaultReplicationConfig() ReplicationConfig {
+	return ReplicationConfig{
+		Factor:          1,
+		WriteQuorum:     1,
+		TransferTimeout: 5 * time.Minute,
+	}
+}
+
+// Replicator copies blobs stored on this node to other healthy nodes until
+// each blob has Factor copies, and registers every copy as a BlobLocation.
+type Replicator struct {
+	config  ReplicationConfig
+	nodeID  string
+	local   storage.Backend
+	manager ClusterManager
+	logger  zerolog.Logger
+
+	// Transfers still running after the write quorum was reached
+	wg sync.WaitGroup
+}
+
+// NewReplicator creates a Replicator for the node nodeID whose blobs are
+// stored in local.
+func NewReplicator(config ReplicationConfig, nodeID string, local storage.Backend, manager ClusterManager, logger zerolog.Logger) *Replicator {
+	if config.Factor < 1 {
+		config.Factor = DefaultReplicationConfig().Factor
+	}
+	if config.WriteQuorum < 1 {
+		config.WriteQuorum = DefaultReplicationConfig().WriteQuorum
+	}
+	if config.WriteQuorum > config.Factor {
+		config.WriteQuorum = config.Factor
+	}
+	if config.TransferTimeout <= 0 {
+		config.TransferTimeout = DefaultReplicationConfig().TransferTimeout
+	}
+
+	return &Replicator{
+		config:  config,
+		nodeID:  nodeID,
+		local:   local,
+		manager: manager,
+		logger:  logger.With().Str("component", "cluster-replicator").Logger(),
+	}
+}
+
+// Replicate registers a blob just stored on this node as the primary copy
+// and copies it to other nodes. It returns once WriteQuorum copies exist;
+// the others are written in the background.
+func (r *Replicator) Replicate(ctx context.Context, contentHash string) error {
+	err := r.manager.RegisterBlobLocation(ctx, &BlobLocation{
+		ContentHash: contentHash,
+		NodeID:      r.nodeID,
+		IsPrimary:   true,
+		SyncedAt:    time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return r.replicate(ctx, contentHash, r.config.Factor, r.config.WriteQuorum)
+}
+
+// EnsureReplication copies a blob stored on this node to other nodes until it
+// has factor copies. Unlike Replicate it waits for every transfer.
+func (r *Replicator) EnsureReplication(ctx context.Context, contentHash string, factor int) error {
+	return r.replicate(ctx, contentHash, factor, factor)
+}
+
+// replicate starts transfers to enough nodes for a blob to have factor
+// copies and waits until quorum copies exist.
+func (r *Replicator) replicate(ctx context.Context, contentHash string, factor, quorum int) error {
+	locations, err := r.manager.GetBlobLocations(ctx, contentHash)
+	if err != nil {
+		return err
+	}
+	holders := make(map[string]bool, len(locations)+1)
+	holders[r.nodeID] = true
+	for _, loc := range locations {
+		holders[loc.NodeID] = true
+	}
+	if len(holders) >= factor {
+		return nil
+	}
+
+	targets, err := r.selectTargets(ctx, holders, factor-len(holders))
+	if err != nil {
+		return err
+	}
+	needed := quorum - len(holders)
+	if len(targets) < needed {
+		return fmt.Errorf("%w: %d copies required, %d nodes available", ErrInsufficientNodes, quorum, len(holders)+len(targets))
+	}
+	if len(holders)+len(targets) < factor {
+		r.logger.Warn().
+			Str("content_hash", contentHash).
+			Int("factor", factor).
+			Int("copies", len(holders)+len(targets)).
+			Msg("not enough healthy nodes, blob will be under-replicated")
+	}
+
+	// Transfers outlive the request once the quorum is reached
+	transferCtx := context.WithoutCancel(ctx)
+	results := make(chan error, len(targets))
+	for _, node := range targets {
+		r.wg.Add(1)
+		go func(nodeID string) {
+			defer r.wg.Done()
+
+			ctx, cancel := context.WithTimeout(transferCtx, r.config.TransferTimeout)
+			defer cancel()
+
+			err := r.ReplicateTo(ctx, contentHash, nodeID)
+			if err != nil {
+				r.logger.Error().Err(err).Str("content_hash", contentHash).Str("node_id", nodeID).Msg("failed to replicate blob")
+			}
+			results <- err
+		}(node.ID)
+	}
+
+	failed := 0
+	for written := 0; written < needed; {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-results:
+			if err == nil {
+				written++
+				continue
+			}
+			failed++
+			if len(targets)-failed < needed {
+				return fmt.Errorf("%w: %d of %d copies written", ErrReplicationFailed, len(holders)+written, quorum)
+			}
+		}
+	}
+	return nil
+}
+
+// selectTargets returns up to n healthy nodes that do not hold the blob yet,
+// those with the most free space first.
+func (r *Replicator) selectTargets(ctx context.Context, holders map[string]bool, n int) ([]*Node, error) {
+	nodes, err := r.manager.GetHealthyNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]*Node, 0, len(nodes))
+	for _, node := range nodes {
+		if !holders[node.ID] {
+			candidates = append(candidates, node)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return freeBytes(candidates[i]) > freeBytes(candidates[j])
+	})
+
+	if len(candidates) > n {
+		candidates = candidates[:n]
+	}
+	return candidates, nil
+}
+
+// freeBytes returns the free space reported by a node, 0 if unknown.
+func freeBytes(node *Node) int64 {
+	if node.Stats == nil {
+		return 0
+	}
+	return node.Stats.FreeBytes
+}
+
+// ReplicateTo copies a blob stored on this node to another node and registers
+// the copy. A node that already holds the blob only gets its location registered.
+func (r *Replicator) ReplicateTo(ctx context.Context, contentHash string, targetNodeID string) error {
+	client, err := r.manager.GetClientForNode(ctx, targetNodeID)
+	if err != nil {
+		return err
+	}
+
+	exists, err := client.BlobExists(ctx, contentHash)
+	if err != nil || !exists {
+		if err := r.transfer(ctx, client, contentHash); err != nil {
+			return fmt.Errorf("%w: %v", ErrTransferFailed, err)
+		}
+	}
+
+	return r.manager.RegisterBlobLocation(ctx, &BlobLocation{
+		ContentHash: contentHash,
+		NodeID:      targetNodeID,
+		IsPrimary:   false,
+		SyncedAt:    time.Now(),
+	})
+}
+
+// transfer streams a blob from local storage to a node.
+func (r *Replicator) transfer(ctx context.Context, client NodeClient, contentHash string) error {
+	size, err := r.local.GetSize(ctx, contentHash)
+	if err != nil {
+		return err
+	}
+	reader, err := r.local.Retrieve(ctx, contentHash)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	return client.TransferBlob(ctx, contentHash, size, reader)
+}
+
+// RemoveReplica removes the copy of a blob held by a node.
+func (r *Replicator) RemoveReplica(ctx context.Context, contentHash string, nodeID string) error {
+	if nodeID == r.nodeID {
+		if err := r.local.Delete(ctx, contentHash); err != nil && !storage.IsNotFound(err) {
+			return err
+		}
+	} else {
+		client, err := r.manager.GetClientForNode(ctx, nodeID)
+		if err != nil {
+			return err
+		}
+		if err := client.DeleteBlob(ctx, contentHash); err != nil {
+			return err
+		}
+	}
+
+	return r.manager.RemoveBlobLocation(ctx, contentHash, nodeID)
+}
+
+// GetReplicationStatus returns how many copies of a blob exist and where.
+func (r *Replicator) GetReplicationStatus(ctx context.Context, contentHash string) (*ReplicationStatus, error) {
+	locations, err := r.manager.GetBlobLocations(ctx, contentHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReplicationStatus{
+		ContentHash:  contentHash,
+		ReplicaCount: len(locations),
+		DesiredCount: r.config.Factor,
+		Locations:    locations,
+		IsSufficient: len(locations) >= r.config.Factor,
+	}, nil
+}
+
+// Retrieve reads a blob from the first other node holding a copy, primary
+// copies first. It returns ErrBlobNotFound if no other node can serve it.
+func (r *Replicator) Retrieve(ctx context.Context, contentHash string) (io.ReadCloser, error) {
+	return r.fromReplica(ctx, contentHash, func(client NodeClient) (io.ReadCloser, error) {
+		return client.RetrieveBlob(ctx, contentHash)
+	})
+}
+
+// RetrieveRange reads a byte range of a blob like Retrieve.
+func (r *Replicator) RetrieveRange(ctx context.Context, contentHash string, offset, length int64) (io.ReadCloser, error) {
+	return r.fromReplica(ctx, contentHash, func(client NodeClient) (io.ReadCloser, error) {
+		return client.RetrieveBlobRange(ctx, contentHash, offset, length)
+	})
+}
+
+// fromReplica calls read with the client of each other node holding a blob
+// until one succeeds.
+func (r *Replicator) fromReplica(ctx context.Context, contentHash string, read func(client NodeClient) (io.ReadCloser, error)) (io.ReadCloser, error) {
+	locations, err := r.manager.GetBlobLocations(ctx, contentHash)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(locations, func(i, j int) bool {
+		return locations[i].IsPrimary && !locations[j].IsPrimary
+	})
+
+	lastErr := storage.ErrBlobNotFound
+	for _, loc := range locations {
+		if loc.NodeID == r.nodeID {
+			continue
+		}
+		client, err := r.manager.GetClientForNode(ctx, loc.NodeID)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		reader, err := read(client)
+		if err != nil {
+			r.logger.Warn().Err(err).Str("content_hash", contentHash).Str("node_id", loc.NodeID).Msg("failed to read blob from replica")
+			lastErr = err
+			continue
+		}
+		return reader, nil
+	}
+	return nil, lastErr
+}
+
+// Wait blocks until background transfers have finished.
+func (r *Replicator) Wait() {
+	r.wg.Wait()
+}
+
+var _ ReplicationController = (*Replicator)(nil)
+
+// ReplicatedBackend is a storage.Backend that replicates every blob it stores
+// to other nodes and serves reads from those replicas when the local copy is
+// missing or unreadable.
+type ReplicatedBackend struct {
+	local      storage.Backend
+	replicator *Replicator
+	logger     zerolog.Logger
+}
+
+// NewReplicatedBackend creates a ReplicatedBackend storing blobs in the local
+// backend of replicator.
+func NewReplicatedBackend(replicator *Replicator, logger zerolog.Logger) *ReplicatedBackend {
+	return &ReplicatedBackend{
+		local:      replicator.local,
+		replicator: replicator,
+		logger:     logger.With().Str("component", "storage-replicated").Logger(),
+	}
+}
+
+// Store stores content locally and replicates it. The store fails unless the
+// write quorum is reached. A blob already stored locally is left there on
+// failure, as it may be shared with existing objects.
+func (b *ReplicatedBackend) Store(ctx context.Context, reader io.Reader, size int64) (string, error) {
+	contentHash, err := b.local.Store(ctx, reader, size)
+	if err != nil {
+		return "", err
+	}
+	if err := b.replicator.Replicate(ctx, contentHash); err != nil {
+		return "", fmt.Errorf("failed to replicate blob: %w", err)
+	}
+	return contentHash, nil
+}
+
+// Retrieve retrieves content locally, falling back to a replica.
+func (b *ReplicatedBackend) Retrieve(ctx context.Context, contentHash string) (io.ReadCloser, error) {
+	reader, err := b.local.Retrieve(ctx, contentHash)
+	if err == nil {
+		return reader, nil
+	}
+	return b.fromReplica(contentHash, err, func() (io.ReadCloser, error) {
+		return b.replicator.Retrieve(ctx, contentHash)
+	})
+}
+
+// RetrieveRange retrieves a byte range like Retrieve. It fails if the local
+// backend does not support range reads.
+func (b *ReplicatedBackend) RetrieveRange(ctx context.Context, contentHash string, offset, length int64) (io.ReadCloser, error) {
+	ranged, ok := b.local.(rangeReader)
+	if !ok {
+		return nil, fmt.Errorf("storage backend does not support range requests")
+	}
+
+	reader, err := ranged.RetrieveRange(ctx, contentHash, offset, length)
+	if err == nil {
+		return reader, nil
+	}
+	return b.fromReplica(contentHash, err, func() (io.ReadCloser, error) {
+		return b.replicator.RetrieveRange(ctx, contentHash, offset, length)
+	})
+}
+
+// fromReplica reads a blob from a replica after the local read failed with
+// localErr, which is returned if no replica can serve it either.
+func (b *ReplicatedBackend) fromReplica(contentHash string, localErr error, read func() (io.ReadCloser, error)) (io.ReadCloser, error) {
+	if errors.Is(localErr, context.Canceled) || errors.Is(localErr, context.DeadlineExceeded) {
+		return nil, localErr
+	}
+
+	reader, err := read()
+	if err != nil {
+		return nil, localErr
+	}
+	b.logger.Warn().Err(localErr).Str("content_hash", contentHash).Msg("local blob unavailable, served from replica")
+	return reader, nil
+}
+
+// Delete removes content locally and from every replica. Replicas that
+// cannot be removed are logged; the result is that of the local delete.
+func (b *ReplicatedBackend) Delete(ctx context.Context, contentHash string) error {
+	localErr := b.local.Delete(ctx, contentHash)
+
+	locations, err := b.replicator.manager.GetBlobLocations(ctx, contentHash)
+	if err != nil {
+		b.logger.Error().Err(err).Str("content_hash", contentHash).Msg("failed to get replicas of deleted blob")
+		return localErr
+	}
+	for _, loc := range locations {
+		if err := b.replicator.RemoveReplica(ctx, contentHash, loc.NodeID); err != nil {
+			b.logger.Error().Err(err).Str("content_hash", contentHash).Str("node_id", loc.NodeID).Msg("failed to remove replica")
+		}
+	}
+	return localErr
+}
+
+// Exists checks whether the content is stored locally.
+func (b *ReplicatedBackend) Exists(ctx context.Context, contentHash string) (bool, error) {
+	return b.local.Exists(ctx, contentHash)
+}
+
+// GetSize returns the size of locally stored content.
+func (b *ReplicatedBackend) GetSize(ctx context.Context, contentHash string) (int64, error) {
+	return b.local.GetSize(ctx, contentHash)
+}
+
+// GetPath returns the path of the content in the local backend.
+func (b *ReplicatedBackend) GetPath(contentHash string) string {
+	return b.local.GetPath(contentHash)
+}
+
+// HealthCheck verifies the local backend.
+func (b *ReplicatedBackend) HealthCheck(ctx context.Context) error {
+	return b.local.HealthCheck(ctx)
+}
+
+// Unwrap returns the local backend.
+func (b *ReplicatedBackend) Unwrap() storage.Backend {
+	return b.local
+}
@@ -0,0 +1,228 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/storage"
+	"github.com/prn-tf/alexander-storage/internal/storage/filesystem"
+)
+
+// testClusterManager serves mock nodes and keeps blob locations in a Server.
+type testClusterManager struct {
+	ClusterManager
+	locations *Server
+	nodes     []*Node
+	clients   map[string]NodeClient
+}
+
+func (m *testClusterManager) GetHealthyNodes(ctx context.Context) ([]*Node, error) {
+	return m.nodes, nil
+}
+
+func (m *testClusterManager) GetClientForNode(ctx context.Context, nodeID string) (NodeClient, error) {
+	client, ok := m.clients[nodeID]
+	if !ok {
+		return nil, ErrNodeNotFound
+	}
+	return client, nil
+}
+
+func (m *testClusterManager) GetBlobLocations(ctx context.Context, contentHash string) ([]*BlobLocation, error) {
+	return m.locations.GetBlobLocations(ctx, contentHash)
+}
+
+func (m *testClusterManager) RegisterBlobLocation(ctx context.Context, location *BlobLocation) error {
+	return m.locations.RegisterBlobLocation(ctx, location)
+}
+
+func (m *testClusterManager) RemoveBlobLocation(ctx context.Context, contentHash, nodeID string) error {
+	return m.locations.RemoveBlobLocation(ctx, contentHash, nodeID)
+}
+
+// failingClient is a node whose transfers fail.
+type failingClient struct {
+	*MockClient
+}
+
+func (failingClient) TransferBlob(ctx context.Context, contentHash string, size int64, reader io.Reader) error {
+	return errors.New("connection reset")
+}
+
+// newReplicationTest returns a replicated backend for node "local" and mock
+// clients for three other nodes; node-3 reports the most free space.
+func newReplicationTest(t *testing.T, config ReplicationConfig) (*ReplicatedBackend, *Replicator, *testClusterManager, map[string]*MockClient) {
+	t.Helper()
+	dir := t.TempDir()
+
+	local, err := filesystem.NewStorage(filesystem.Config{
+		DataDir: filepath.Join(dir, "data"),
+		TempDir: filepath.Join(dir, "tmp"),
+	}, zerolog.Nop())
+	require.NoError(t, err)
+
+	manager := &testClusterManager{
+		locations: newLocationTestServer(t, nil),
+		clients:   make(map[string]NodeClient),
+	}
+	mocks := make(map[string]*MockClient)
+	for i, id := range []string{"node-1", "node-2", "node-3"} {
+		mock := NewMockClient(id, id+":9100", NodeRoleHot)
+		mocks[id] = mock
+		manager.clients[id] = mock
+		manager.nodes = append(manager.nodes, &Node{
+			ID:     id,
+			Role:   NodeRoleHot,
+			Status: NodeStatusHealthy,
+			Stats:  &StorageStats{FreeBytes: int64(i+1) * 1000},
+		})
+	}
+
+	replicator := NewReplicator(config, "local", local, manager, zerolog.Nop())
+	return NewReplicatedBackend(replicator, zerolog.Nop()), replicator, manager, mocks
+}
+
+// replicaNodes returns the mock nodes holding a blob.
+func replicaNodes(mocks map[string]*MockClient, contentHash string) []string {
+	var nodes []string
+	for _, id := range []string{"node-1", "node-2", "node-3"} {
+		if _, ok := mocks[id].GetBlobs()[contentHash]; ok {
+			nodes = append(nodes, id)
+		}
+	}
+	return nodes
+}
+
+func TestReplicatedBackend_StoreReachesReplicationFactor(t *testing.T) {
+	ctx := context.Background()
+	backend, replicator, _, mocks := newReplicationTest(t, ReplicationConfig{Factor: 3, WriteQuorum: 3})
+
+	contentHash, err := backend.Store(ctx, strings.NewReader("replicated content"), 18)
+	require.NoError(t, err)
+
+	// Two copies besides the local one, on the nodes with the most free space
+	require.Equal(t, []string{"node-2", "node-3"}, replicaNodes(mocks, contentHash))
+	require.Equal(t, "replicated content", string(mocks["node-3"].GetBlobs()[contentHash]))
+
+	status, err := replicator.GetReplicationStatus(ctx, contentHash)
+	require.NoError(t, err)
+	require.Equal(t, 3, status.ReplicaCount)
+	require.Equal(t, 3, status.DesiredCount)
+	require.True(t, status.IsSufficient)
+	primaries := 0
+	for _, loc := range status.Locations {
+		if loc.IsPrimary {
+			primaries++
+			require.Equal(t, "local", loc.NodeID)
+		}
+	}
+	require.Equal(t, 1, primaries)
+
+	// Storing the same content again does not add copies
+	_, err = backend.Store(ctx, strings.NewReader("replicated content"), 18)
+	require.NoError(t, err)
+	require.Equal(t, []string{"node-2", "node-3"}, replicaNodes(mocks, contentHash))
+}
+
+func TestReplicatedBackend_StoreAsynchronously(t *testing.T) {
+	ctx := context.Background()
+	backend, replicator, _, mocks := newReplicationTest(t, ReplicationConfig{Factor: 4, WriteQuorum: 1})
+
+	contentHash, err := backend.Store(ctx, strings.NewReader("background"), 10)
+	require.NoError(t, err)
+	replicator.Wait()
+
+	require.Equal(t, []string{"node-1", "node-2", "node-3"}, replicaNodes(mocks, contentHash))
+	status, err := replicator.GetReplicationStatus(ctx, contentHash)
+	require.NoError(t, err)
+	require.Equal(t, 4, status.ReplicaCount)
+}
+
+func TestReplicatedBackend_StoreFailsWithoutQuorum(t *testing.T) {
+	ctx := context.Background()
+
+	// Not enough nodes for the quorum
+	backend, _, manager, _ := newReplicationTest(t, ReplicationConfig{Factor: 3, WriteQuorum: 3})
+	manager.nodes = manager.nodes[:1]
+	_, err := backend.Store(ctx, strings.NewReader("lonely"), 6)
+	require.ErrorIs(t, err, ErrInsufficientNodes)
+
+	// Too many transfers fail for the quorum
+	backend, replicator, manager, mocks := newReplicationTest(t, ReplicationConfig{Factor: 3, WriteQuorum: 3})
+	manager.clients["node-3"] = failingClient{MockClient: mocks["node-3"]}
+	_, err = backend.Store(ctx, strings.NewReader("unlucky"), 7)
+	require.ErrorIs(t, err, ErrReplicationFailed)
+
+	// A quorum below the factor tolerates the failure
+	backend, replicator, manager, mocks = newReplicationTest(t, ReplicationConfig{Factor: 3, WriteQuorum: 2})
+	manager.clients["node-3"] = failingClient{MockClient: mocks["node-3"]}
+	contentHash, err := backend.Store(ctx, strings.NewReader("tolerated"), 9)
+	require.NoError(t, err)
+	replicator.Wait()
+	require.Equal(t, []string{"node-2"}, replicaNodes(mocks, contentHash))
+
+	status, err := replicator.GetReplicationStatus(ctx, contentHash)
+	require.NoError(t, err)
+	require.Equal(t, 2, status.ReplicaCount)
+	require.False(t, status.IsSufficient)
+
+	// EnsureReplication completes it once a node is back
+	manager.clients["node-3"] = mocks["node-3"]
+	require.NoError(t, replicator.EnsureReplication(ctx, contentHash, 3))
+	require.Equal(t, []string{"node-2", "node-3"}, replicaNodes(mocks, contentHash))
+}
+
+func TestReplicatedBackend_ReadsFromReplicas(t *testing.T) {
+	ctx := context.Background()
+	backend, _, _, mocks := newReplicationTest(t, ReplicationConfig{Factor: 2, WriteQuorum: 2})
+
+	contentHash, err := backend.Store(ctx, strings.NewReader("0123456789"), 10)
+	require.NoError(t, err)
+
+	// The local copy is lost
+	require.NoError(t, backend.local.Delete(ctx, contentHash))
+
+	reader, err := backend.Retrieve(ctx, contentHash)
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.NoError(t, reader.Close())
+	require.Equal(t, "0123456789", string(data))
+
+	reader, err = backend.RetrieveRange(ctx, contentHash, 2, 3)
+	require.NoError(t, err)
+	data, err = io.ReadAll(reader)
+	require.NoError(t, err)
+	require.Equal(t, "234", string(data))
+
+	// Without replicas the local error is returned
+	require.NoError(t, mocks["node-3"].DeleteBlob(ctx, contentHash))
+	_, err = backend.Retrieve(ctx, contentHash)
+	require.True(t, storage.IsNotFound(err))
+}
+
+func TestReplicatedBackend_DeleteRemovesReplicas(t *testing.T) {
+	ctx := context.Background()
+	backend, replicator, _, mocks := newReplicationTest(t, ReplicationConfig{Factor: 3, WriteQuorum: 3})
+
+	contentHash, err := backend.Store(ctx, strings.NewReader("short-lived"), 11)
+	require.NoError(t, err)
+	require.Len(t, replicaNodes(mocks, contentHash), 2)
+
+	require.NoError(t, backend.Delete(ctx, contentHash))
+	require.Empty(t, replicaNodes(mocks, contentHash))
+	exists, err := backend.Exists(ctx, contentHash)
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	status, err := replicator.GetReplicationStatus(ctx, contentHash)
+	require.NoError(t, err)
+	require.Zero(t, status.ReplicaCount)
+}
@@ -0,0 +1,145 @@
+package cluster
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// nodeSecretKey is the metadata key carrying the cluster shared secret.
+const nodeSecretKey = "x-alexander-node-secret"
+
+// Security protects the traffic between nodes. The zero value sends
+// everything in plaintext and accepts any caller, which is only suitable
+// for tests and trusted networks.
+type Security struct {
+	// TLS encrypts the connections. The same configuration serves both
+	// directions: servers present Certificates and, with ClientCAs and
+	// ClientAuth set, only accept peers presenting a certificate signed by
+	// them (mutual TLS); clients verify servers against RootCAs and present
+	// Certificates when asked.
+	TLS *tls.Config
+
+	// SharedSecret, when set, is sent by clients with every call and
+	// required by servers, so only nodes holding it can use the API.
+	SharedSecret string
+}
+
+// Enabled reports whether traffic is encrypted or callers authenticated.
+func (s Security) Enabled() bool {
+	return s.TLS != nil || s.SharedSecret != ""
+}
+
+// LoadTLSConfig builds a mutual TLS configuration from PEM files: the
+// node's certificate and key, and the CA certificates peers must be signed
+// by. The result can be used as Security.TLS on both servers and clients.
+func LoadTLSConfig(certFile, keyFile, caFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load node certificate: %w", err)
+	}
+
+	caPEM, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificates: %w", err)
+	}
+	cas := x509.NewCertPool()
+	if !cas.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no CA certificates found in %s", caFile)
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      cas,
+		ClientCAs:    cas,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// serverOptions returns the gRPC options enforcing s on a server.
+func (s Security) serverOptions() []grpc.ServerOption {
+	var opts []grpc.ServerOption
+	if s.TLS != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(s.TLS)))
+	}
+	if s.SharedSecret != "" {
+		auth := secretAuthenticator{secret: []byte(s.SharedSecret)}
+		opts = append(opts,
+			grpc.ChainUnaryInterceptor(auth.unary),
+			grpc.ChainStreamInterceptor(auth.stream),
+		)
+	}
+	return opts
+}
+
+// dialOptions returns the gRPC options a client needs to satisfy s.
+func (s Security) dialOptions() []grpc.DialOption {
+	var opts []grpc.DialOption
+	if s.TLS != nil {
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(s.TLS)))
+	} else {
+		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	}
+	if s.SharedSecret != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(secretCredentials{
+			secret:     s.SharedSecret,
+			requireTLS: s.TLS != nil,
+		}))
+	}
+	return opts
+}
+
+// secretCredentials attaches the shared secret to every call.
+type secretCredentials struct {
+	secret     string
+	requireTLS bool
+}
+
+func (c secretCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{nodeSecretKey: c.secret}, nil
+}
+
+func (c secretCredentials) RequireTransportSecurity() bool {
+	return c.requireTLS
+}
+
+// secretAuthenticator rejects calls that do not carry the shared secret.
+type secretAuthenticator struct {
+	secret []byte
+}
+
+// authenticate checks the secret in the incoming metadata of ctx.
+func (a secretAuthenticator) authenticate(ctx context.Context) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get(nodeSecretKey) {
+		if subtle.ConstantTimeCompare([]byte(value), a.secret) == 1 {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "node not authenticated")
+}
+
+func (a secretAuthenticator) unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if err := a.authenticate(ctx); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a secretAuthenticator) stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := a.authenticate(ss.Context()); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
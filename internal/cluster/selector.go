@@ -0,0 +1,100 @@
+package cluster
+
+import (
+	"context"
+	"sort"
+)
+
+// CapacitySelector is a NodeSelector that prefers the healthy nodes with the
+// most free space.
+type CapacitySelector struct {
+	manager ClusterManager
+}
+
+// NewCapacitySelector creates a selector over the nodes known to manager.
+func NewCapacitySelector(manager ClusterManager) *CapacitySelector {
+	return &CapacitySelector{manager: manager}
+}
+
+// SelectForStore returns up to replicationFactor healthy nodes with room for
+// size bytes, most free space first.
+func (s *CapacitySelector) SelectForStore(ctx context.Context, size int64, replicationFactor int) ([]*Node, error) {
+	nodes, err := s.manager.GetHealthyNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []*Node
+	for _, node := range nodes {
+		// Nodes that have not reported stats yet are assumed to have room
+		if node.Stats == nil || node.Stats.FreeBytes >= size {
+			candidates = append(candidates, node)
+		}
+	}
+	sortByFreeSpace(candidates)
+
+	if replicationFactor > 0 && len(candidates) > replicationFactor {
+		candidates = candidates[:replicationFactor]
+	}
+	return candidates, nil
+}
+
+// SelectForRetrieve returns a healthy node holding the blob, preferring its
+// primary copy. It returns nil if no healthy node holds the blob.
+func (s *CapacitySelector) SelectForRetrieve(ctx context.Context, contentHash string) (*Node, error) {
+	locations, err := s.manager.GetBlobLocations(ctx, contentHash)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(locations, func(i, j int) bool {
+		return locations[i].IsPrimary && !locations[j].IsPrimary
+	})
+
+	for _, loc := range locations {
+		node, err := s.manager.GetNode(ctx, loc.NodeID)
+		if err == nil && node.Status == NodeStatusHealthy {
+			return node, nil
+		}
+	}
+	return nil, nil
+}
+
+// SelectForTiering returns the healthy node of targetRole with the most free
+// space that does not already hold the blob. It returns nil if there is none.
+func (s *CapacitySelector) SelectForTiering(ctx context.Context, contentHash string, targetRole NodeRole) (*Node, error) {
+	nodes, err := s.manager.GetNodesByRole(ctx, targetRole)
+	if err != nil {
+		return nil, err
+	}
+	locations, err := s.manager.GetBlobLocations(ctx, contentHash)
+	if err != nil {
+		return nil, err
+	}
+
+	holders := make(map[string]bool, len(locations))
+	for _, loc := range locations {
+		holders[loc.NodeID] = true
+	}
+
+	var candidates []*Node
+	for _, node := range nodes {
+		if node.Status == NodeStatusHealthy && !holders[node.ID] {
+			candidates = append(candidates, node)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+	sortByFreeSpace(candidates)
+	return candidates[0], nil
+}
+
+// sortByFreeSpace orders nodes by free space, most first.
+func sortByFreeSpace(nodes []*Node) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return freeBytes(nodes[i]) > freeBytes(nodes[j])
+	})
+}
+
+// Ensure CapacitySelector implements NodeSelector
+var _ NodeSelector = (*CapacitySelector)(nil)
@@ -0,0 +1,38 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapacitySelector_SelectForTiering(t *testing.T) {
+	ctx := context.Background()
+	server := newLocationTestServer(t, nil)
+	for _, node := range []*Node{
+		{ID: "hot-1", Role: NodeRoleHot, Stats: &StorageStats{FreeBytes: 9000}},
+		{ID: "warm-1", Role: NodeRoleWarm, Stats: &StorageStats{FreeBytes: 1000}},
+		{ID: "warm-2", Role: NodeRoleWarm, Stats: &StorageStats{FreeBytes: 5000}},
+		{ID: "warm-3", Role: NodeRoleWarm, Status: NodeStatusUnhealthy, Stats: &StorageStats{FreeBytes: 8000}},
+	} {
+		require.NoError(t, server.RegisterNode(node))
+	}
+	selector := NewCapacitySelector(NewStaticManager(ManagerConfig{}, server, zerolog.Nop()))
+
+	// The healthy warm node with the most free space
+	node, err := selector.SelectForTiering(ctx, "abc", NodeRoleWarm)
+	require.NoError(t, err)
+	require.Equal(t, "warm-2", node.ID)
+
+	// Nodes already holding the blob are skipped
+	require.NoError(t, server.RegisterBlobLocation(ctx, &BlobLocation{ContentHash: "abc", NodeID: "warm-2"}))
+	node, err = selector.SelectForTiering(ctx, "abc", NodeRoleWarm)
+	require.NoError(t, err)
+	require.Equal(t, "warm-1", node.ID)
+
+	node, err = selector.SelectForTiering(ctx, "abc", NodeRoleCold)
+	require.NoError(t, err)
+	require.Nil(t, node)
+}
@@ -5,12 +5,16 @@ package cluster
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
+	"net"
 	"sync"
 	"time"
 
 	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
 
+	pb "github.com/prn-tf/alexander-storage/internal/cluster/proto"
 	"github.com/prn-tf/alexander-storage/internal/storage"
 )
 
@@ -24,6 +28,7 @@ var (
 	ErrReplicationFailed = errors.New("replication failed")
 	ErrNodeUnavailable   = errors.New("node unavailable")
 	ErrInsufficientNodes = errors.New("insufficient nodes available")
+	ErrUnauthenticated   = errors.New("node not authenticated")
 )
 
 // rangeReader is an optional interface for backends that support range retrieval.
@@ -50,6 +55,9 @@ type ServerConfig struct {
 
 	// HeartbeatTimeout is when a node is considered dead.
 	HeartbeatTimeout time.Duration
+
+	// Security encrypts connections and authenticates calling nodes.
+	Security Security
 }
 
 // DefaultServerConfig returns sensible defaults.
@@ -72,13 +80,21 @@ type Server struct {
 	nodesMu sync.RWMutex
 	nodes   map[string]*Node
 
-	// Blob location tracking
-	locationsMu sync.RWMutex
-	locations   map[string][]*BlobLocation // contentHash -> locations
+	// Blob location tracking. With a repository, locations is a read cache
+	// of it and locationsGen is bumped on every write so a slow read cannot
+	// fill the cache with locations older than the write.
+	locationsMu  sync.RWMutex
+	locations    map[string][]*BlobLocation // contentHash -> locations
+	locationsGen uint64
+	locationRepo BlobLocationRepository
 
 	// Transfer semaphore
 	transferSem chan struct{}
 
+	// gRPC transport
+	grpcServer *grpc.Server
+	listener   net.Listener
+
 	// Shutdown
 	shutdownCh chan struct{}
 	wg         sync.WaitGroup
@@ -117,20 +133,24 @@ func NewServer(config ServerConfig, blobStorage storage.Backend, logger zerolog.
 	}, nil
 }
 
-// Start begins the gRPC server.
-// Note: Full gRPC implementation requires generated protobuf code.
-// This is a placeholder for the server structure.
+// Start listens on the configured address and serves the NodeService gRPC API.
 func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.config.Address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.config.Address, err)
+	}
+	s.listener = listener
+
 	s.logger.Info().
 		Str("node_id", s.config.NodeID).
-		Str("address", s.config.Address).
+		Str("address", listener.Addr().String()).
 		Str("role", string(s.config.Role)).
 		Msg("Starting cluster server")
 
 	// Register self
 	self := &Node{
 		ID:            s.config.NodeID,
-		Address:       s.config.Address,
+		Address:       listener.Addr().String(),
 		Role:          s.config.Role,
 		Status:        NodeStatusHealthy,
 		LastHeartbeat: time.Now(),
@@ -140,6 +160,20 @@ func (s *Server) Start() error {
 	s.nodes[s.config.NodeID] = self
 	s.nodesMu.Unlock()
 
+	if !s.config.Security.Enabled() {
+		s.logger.Warn().Msg("Cluster traffic is not encrypted or authenticated")
+	}
+	s.grpcServer = grpc.NewServer(s.config.Security.serverOptions()...)
+	pb.RegisterNodeServiceServer(s.grpcServer, &nodeService{server: s})
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		if err := s.grpcServer.Serve(listener); err != nil {
+			s.logger.Error().Err(err).Msg("Cluster server stopped serving")
+		}
+	}()
+
 	// Start background tasks
 	s.wg.Add(1)
 	go s.heartbeatChecker()
@@ -147,9 +181,21 @@ func (s *Server) Start() error {
 	return nil
 }
 
-// Stop gracefully shuts down the server.
+// Addr returns the address the server listens on, which differs from the
+// configured address when that uses port 0. It is empty before Start.
+func (s *Server) Addr() string {
+	if s.listener == nil {
+		return ""
+	}
+	return s.listener.Addr().String()
+}
+
+// Stop gracefully shuts down the server, letting in-flight calls finish.
 func (s *Server) Stop() error {
 	s.logger.Info().Msg("Stopping cluster server")
+	if s.grpcServer != nil {
+		s.grpcServer.GracefulStop()
+	}
 	close(s.shutdownCh)
 	s.wg.Wait()
 	return nil
@@ -442,12 +488,32 @@ func (s *Server) GetHealthyNodes() []*Node {
 	return result
 }
 
+// SetLocationRepository persists blob locations in repo. Locations are then
+// written through to the repository and read through the in-memory cache, so
+// they survive restarts and are shared with other coordinators using it.
+func (s *Server) SetLocationRepository(repo BlobLocationRepository) {
+	s.locationsMu.Lock()
+	defer s.locationsMu.Unlock()
+
+	s.locationRepo = repo
+	s.locations = make(map[string][]*BlobLocation)
+	s.locationsGen++
+}
+
 // RegisterBlobLocation registers where a blob is stored.
-func (s *Server) RegisterBlobLocation(location *BlobLocation) error {
+func (s *Server) RegisterBlobLocation(ctx context.Context, location *BlobLocation) error {
 	if location.ContentHash == "" || location.NodeID == "" {
 		return errors.New("content hash and node ID are required")
 	}
 
+	if repo := s.locationRepository(); repo != nil {
+		if err := repo.Upsert(ctx, location); err != nil {
+			return fmt.Errorf("failed to register blob location: %w", err)
+		}
+		s.invalidateLocations(location.ContentHash)
+		return nil
+	}
+
 	s.locationsMu.Lock()
 	defer s.locationsMu.Unlock()
 
@@ -466,13 +532,33 @@ func (s *Server) RegisterBlobLocation(location *BlobLocation) error {
 }
 
 // GetBlobLocations returns all locations for a blob.
-func (s *Server) GetBlobLocations(contentHash string) []*BlobLocation {
+func (s *Server) GetBlobLocations(ctx context.Context, contentHash string) ([]*BlobLocation, error) {
 	s.locationsMu.RLock()
-	defer s.locationsMu.RUnlock()
+	locations, cached := s.locations[contentHash]
+	repo := s.locationRepo
+	gen := s.locationsGen
+	s.locationsMu.RUnlock()
+
+	if !cached && repo != nil {
+		loaded, err := repo.Get(ctx, contentHash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get blob locations: %w", err)
+		}
+		locations = loaded
+
+		// Unknown blobs are not cached so locations registered by other
+		// coordinators are picked up
+		if len(locations) > 0 {
+			s.locationsMu.Lock()
+			if s.locationsGen == gen {
+				s.locations[contentHash] = locations
+			}
+			s.locationsMu.Unlock()
+		}
+	}
 
-	locations := s.locations[contentHash]
 	if len(locations) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	result := make([]*BlobLocation, len(locations))
@@ -480,11 +566,19 @@ func (s *Server) GetBlobLocations(contentHash string) []*BlobLocation {
 		locCopy := *loc
 		result[i] = &locCopy
 	}
-	return result
+	return result, nil
 }
 
 // RemoveBlobLocation removes a blob location.
-func (s *Server) RemoveBlobLocation(contentHash, nodeID string) error {
+func (s *Server) RemoveBlobLocation(ctx context.Context, contentHash, nodeID string) error {
+	if repo := s.locationRepository(); repo != nil {
+		if err := repo.Remove(ctx, contentHash, nodeID); err != nil {
+			return fmt.Errorf("failed to remove blob location: %w", err)
+		}
+		s.invalidateLocations(contentHash)
+		return nil
+	}
+
 	s.locationsMu.Lock()
 	defer s.locationsMu.Unlock()
 
@@ -498,6 +592,22 @@ func (s *Server) RemoveBlobLocation(contentHash, nodeID string) error {
 	return nil
 }
 
+// locationRepository returns the blob location repository, if any.
+func (s *Server) locationRepository() BlobLocationRepository {
+	s.locationsMu.RLock()
+	defer s.locationsMu.RUnlock()
+	return s.locationRepo
+}
+
+// invalidateLocations drops the cached locations of a blob after a write.
+func (s *Server) invalidateLocations(contentHash string) {
+	s.locationsMu.Lock()
+	defer s.locationsMu.Unlock()
+
+	delete(s.locations, contentHash)
+	s.locationsGen++
+}
+
 // limitedReadCloser wraps a limited reader with a closer.
 type limitedReadCloser struct {
 	io.Reader
@@ -0,0 +1,160 @@
+package cluster
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryLocationRepository is an in-memory BlobLocationRepository that
+// outlives the servers using it, like a shared database.
+type memoryLocationRepository struct {
+	mu        sync.Mutex
+	locations map[string]map[string]BlobLocation
+	gets      int
+}
+
+func newMemoryLocationRepository() *memoryLocationRepository {
+	return &memoryLocationRepository{locations: make(map[string]map[string]BlobLocation)}
+}
+
+func (r *memoryLocationRepository) Upsert(ctx context.Context, location *BlobLocation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.locations[location.ContentHash] == nil {
+		r.locations[location.ContentHash] = make(map[string]BlobLocation)
+	}
+	r.locations[location.ContentHash][location.NodeID] = *location
+	return nil
+}
+
+func (r *memoryLocationRepository) Get(ctx context.Context, contentHash string) ([]*BlobLocation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.gets++
+	var result []*BlobLocation
+	for _, loc := range r.locations[contentHash] {
+		locCopy := loc
+		result = append(result, &locCopy)
+	}
+	return result, nil
+}
+
+func (r *memoryLocationRepository) Remove(ctx context.Context, contentHash, nodeID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.locations[contentHash], nodeID)
+	return nil
+}
+
+func (r *memoryLocationRepository) getCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.gets
+}
+
+func newLocationTestServer(t *testing.T, repo BlobLocationRepository) *Server {
+	t.Helper()
+	server, err := NewServer(ServerConfig{NodeID: "coordinator", Address: "127.0.0.1:0"}, nil, zerolog.Nop())
+	require.NoError(t, err)
+	if repo != nil {
+		server.SetLocationRepository(repo)
+	}
+	return server
+}
+
+func TestServer_BlobLocationsInMemory(t *testing.T) {
+	ctx := context.Background()
+	server := newLocationTestServer(t, nil)
+
+	require.NoError(t, server.RegisterBlobLocation(ctx, &BlobLocation{ContentHash: "abc", NodeID: "node-1"}))
+	require.NoError(t, server.RegisterBlobLocation(ctx, &BlobLocation{ContentHash: "abc", NodeID: "node-1", IsPrimary: true}))
+
+	locations, err := server.GetBlobLocations(ctx, "abc")
+	require.NoError(t, err)
+	require.Len(t, locations, 1)
+	require.True(t, locations[0].IsPrimary)
+
+	require.NoError(t, server.RemoveBlobLocation(ctx, "abc", "node-1"))
+	locations, err = server.GetBlobLocations(ctx, "abc")
+	require.NoError(t, err)
+	require.Empty(t, locations)
+
+	require.Error(t, server.RegisterBlobLocation(ctx, &BlobLocation{ContentHash: "abc"}))
+}
+
+func TestServer_BlobLocationsSurviveRestart(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryLocationRepository()
+	syncedAt := time.Now().Add(-time.Minute).UTC()
+
+	server := newLocationTestServer(t, repo)
+	require.NoError(t, server.RegisterBlobLocation(ctx, &BlobLocation{ContentHash: "abc", NodeID: "hot-1", IsPrimary: true, SyncedAt: syncedAt}))
+	require.NoError(t, server.RegisterBlobLocation(ctx, &BlobLocation{ContentHash: "abc", NodeID: "warm-1", SyncedAt: syncedAt}))
+	require.NoError(t, server.RegisterBlobLocation(ctx, &BlobLocation{ContentHash: "def", NodeID: "hot-1"}))
+	require.NoError(t, server.RemoveBlobLocation(ctx, "def", "hot-1"))
+
+	// A recreated server loads the locations registered before the restart
+	restarted := newLocationTestServer(t, repo)
+	locations, err := restarted.GetBlobLocations(ctx, "abc")
+	require.NoError(t, err)
+	require.Len(t, locations, 2)
+	byNode := map[string]*BlobLocation{}
+	for _, loc := range locations {
+		byNode[loc.NodeID] = loc
+	}
+	require.True(t, byNode["hot-1"].IsPrimary)
+	require.False(t, byNode["warm-1"].IsPrimary)
+	require.Equal(t, syncedAt, byNode["warm-1"].SyncedAt)
+
+	locations, err = restarted.GetBlobLocations(ctx, "def")
+	require.NoError(t, err)
+	require.Empty(t, locations)
+}
+
+func TestServer_BlobLocationsCache(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryLocationRepository()
+	server := newLocationTestServer(t, repo)
+	require.NoError(t, server.RegisterBlobLocation(ctx, &BlobLocation{ContentHash: "abc", NodeID: "hot-1"}))
+
+	// Hot reads are served from the cache
+	for i := 0; i < 3; i++ {
+		locations, err := server.GetBlobLocations(ctx, "abc")
+		require.NoError(t, err)
+		require.Len(t, locations, 1)
+	}
+	require.Equal(t, 1, repo.getCount())
+
+	// Returned locations are copies
+	locations, err := server.GetBlobLocations(ctx, "abc")
+	require.NoError(t, err)
+	locations[0].NodeID = "changed"
+
+	// Writes invalidate the cached locations
+	require.NoError(t, server.RegisterBlobLocation(ctx, &BlobLocation{ContentHash: "abc", NodeID: "cold-1"}))
+	locations, err = server.GetBlobLocations(ctx, "abc")
+	require.NoError(t, err)
+	require.Len(t, locations, 2)
+	require.Equal(t, 2, repo.getCount())
+
+	require.NoError(t, server.RemoveBlobLocation(ctx, "abc", "hot-1"))
+	locations, err = server.GetBlobLocations(ctx, "abc")
+	require.NoError(t, err)
+	require.Len(t, locations, 1)
+	require.Equal(t, "cold-1", locations[0].NodeID)
+
+	// Locations registered by another coordinator are seen for unknown blobs
+	other := newLocationTestServer(t, repo)
+	require.NoError(t, other.RegisterBlobLocation(ctx, &BlobLocation{ContentHash: "new", NodeID: "hot-2"}))
+	locations, err = server.GetBlobLocations(ctx, "new")
+	require.NoError(t, err)
+	require.Len(t, locations, 1)
+}
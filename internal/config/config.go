@@ -3,11 +3,14 @@
 package config
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/spf13/viper"
+
+	"github.com/prn-tf/alexander-storage/internal/pkg/secrets"
 )
 
 // Config represents the complete application configuration.
@@ -28,6 +31,9 @@ type Config struct {
 	Cluster    ClusterConfig    `mapstructure:"cluster"`
 	Tiering    TieringConfig    `mapstructure:"tiering"`
 	Migration  MigrationConfig  `mapstructure:"migration"`
+
+	Transform    TransformConfig    `mapstructure:"transform"`
+	Notification NotificationConfig `mapstructure:"notification"`
 }
 
 // ServerConfig holds HTTP server settings.
@@ -39,6 +45,41 @@ type ServerConfig struct {
 	IdleTimeout     time.Duration `mapstructure:"idle_timeout"`
 	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
 	MaxBodySize     int64         `mapstructure:"max_body_size"`
+
+	// MaxHeaderBytes bounds the total size of request headers.
+	// Larger requests are answered with 431 Request Header Fields Too Large.
+	MaxHeaderBytes int `mapstructure:"max_header_bytes"`
+
+	// MaxMetadataHeaders bounds the number of x-amz-meta-* headers per request.
+	// Requests with more are rejected with MetadataTooLarge. 0 means unlimited.
+	MaxMetadataHeaders int `mapstructure:"max_metadata_headers"`
+
+	// StrictQueryParams rejects S3 API requests carrying query parameters the
+	// server does not recognize with InvalidArgument, instead of ignoring
+	// them like S3 does. Meant for catching client bugs during integration.
+	StrictQueryParams bool `mapstructure:"strict_query_params"`
+
+	// MaxKeyDepth bounds the number of "/" delimiters in new object keys,
+	// guarding delimiter listings against pathologically deep hierarchies.
+	// 0 means unlimited.
+	MaxKeyDepth int `mapstructure:"max_key_depth"`
+
+	// AllowAppend accepts PutObject requests with x-amz-write-offset-bytes,
+	// appending the body to the object's current content as a new version.
+	AllowAppend bool `mapstructure:"allow_append"`
+
+	// ChecksumTrailers sends an x-amz-checksum-* HTTP trailer computed while
+	// streaming GetObject responses to clients that send "TE: trailers".
+	ChecksumTrailers bool `mapstructure:"checksum_trailers"`
+
+	// BaseDomain is the endpoint domain (e.g. s3.example.com). When set,
+	// requests to <bucket>.<base domain> use virtual-hosted-style addressing.
+	BaseDomain string `mapstructure:"base_domain"`
+
+	// Capabilities controls the JSON endpoint describing the supported S3
+	// features: "disabled", "authenticated" or "public" (served without
+	// authentication).
+	Capabilities string `mapstructure:"capabilities"`
 }
 
 // DatabaseConfig holds database connection settings.
@@ -60,6 +101,25 @@ type DatabaseConfig struct {
 	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime"`
 	ConnMaxIdleTime time.Duration `mapstructure:"conn_max_idle_time"`
 
+	// StatementCacheMode selects how pgx prepares statements:
+	// "cache_statement" (prepare once per connection and reuse), "cache_describe",
+	// "describe_exec", "exec" or "simple_protocol". Use "describe_exec" or
+	// "simple_protocol" behind transaction-pooling proxies such as PgBouncer.
+	StatementCacheMode string `mapstructure:"statement_cache_mode"`
+
+	// StatementCacheCapacity is the number of prepared statements cached per connection.
+	StatementCacheCapacity int `mapstructure:"statement_cache_capacity"`
+
+	// BucketCacheTTL is how long bucket metadata (owner, versioning) is cached
+	// in-process for write paths. Changes only invalidate the cache of the
+	// instance making them, so enable it only when a single instance serves
+	// the database; it is refused in cluster mode. Default: 0 (disabled).
+	BucketCacheTTL time.Duration `mapstructure:"bucket_cache_ttl"`
+
+	// AutoMigrate applies pending schema migrations at startup.
+	// Disable it when migrations are run separately with the migrate CLI.
+	AutoMigrate bool `mapstructure:"auto_migrate"`
+
 	// SQLite settings (used when Driver is "sqlite")
 	Path            string `mapstructure:"path"`             // Path to SQLite database file
 	JournalMode     string `mapstructure:"journal_mode"`     // WAL, DELETE, TRUNCATE, etc.
@@ -100,11 +160,94 @@ func (c RedisConfig) Addr() string {
 
 // StorageConfig holds blob storage backend settings.
 type StorageConfig struct {
-	Backend   string                `mapstructure:"backend"`
-	DataDir   string                `mapstructure:"data_dir"`
-	TempDir   string                `mapstructure:"temp_dir"`
-	S3        S3StorageConfig       `mapstructure:"s3"`
-	Multipart MultipartUploadConfig `mapstructure:"multipart"`
+	Backend   string                 `mapstructure:"backend"`
+	DataDir   string                 `mapstructure:"data_dir"`
+	TempDir   string                 `mapstructure:"temp_dir"`
+	S3        S3StorageConfig        `mapstructure:"s3"`
+	Multipart MultipartUploadConfig  `mapstructure:"multipart"`
+	Retry     StorageRetryConfig     `mapstructure:"retry"`
+	DualWrite StorageDualWriteConfig `mapstructure:"dual_write"`
+
+	// Cache keeps recently used blobs on local disk in front of a slow or
+	// remote backend.
+	Cache StorageCacheConfig `mapstructure:"cache"`
+
+	// Compression compresses blobs with Zstandard before they are written.
+	Compression StorageCompressionConfig `mapstructure:"compression"`
+
+	// DefaultObjectOwnership is the object ownership of new buckets created
+	// without x-amz-object-ownership: BucketOwnerEnforced (ACLs disabled),
+	// BucketOwnerPreferred or ObjectWriter.
+	DefaultObjectOwnership string `mapstructure:"default_object_ownership"`
+
+	// SizeMismatchPolicy is how uploads whose body does not match the declared
+	// Content-Length are handled: strict rejects them, lenient stores the bytes
+	// actually received and records their size.
+	SizeMismatchPolicy string `mapstructure:"size_mismatch_policy"`
+}
+
+// StorageRetryConfig holds retry settings for transient storage errors.
+type StorageRetryConfig struct {
+	// MaxAttempts is the total number of attempts per operation (1 disables retries).
+	MaxAttempts int `mapstructure:"max_attempts"`
+
+	// InitialBackoff is the delay before the first retry; it doubles per attempt.
+	InitialBackoff time.Duration `mapstructure:"initial_backoff"`
+
+	// MaxBackoff caps the delay between attempts.
+	MaxBackoff time.Duration `mapstructure:"max_backoff"`
+}
+
+// StorageDualWriteConfig holds settings for migrating blobs to a new storage
+// backend without downtime. While enabled, blobs are written to both the
+// backend at storage.data_dir and the new one, reads prefer the new backend,
+// and existing blobs are backfilled in the background. Once the backfill
+// reports completion, point storage.data_dir at the new backend and disable
+// dual writes to retire the old one.
+type StorageDualWriteConfig struct {
+	// Enabled turns on dual writes to the new backend.
+	Enabled bool `mapstructure:"enabled"`
+
+	// DataDir is the blob directory of the new filesystem backend.
+	DataDir string `mapstructure:"data_dir"`
+
+	// TempDir holds in-progress uploads to the new backend.
+	TempDir string `mapstructure:"temp_dir"`
+
+	// BackfillInterval is the pause between backfill batches.
+	BackfillInterval time.Duration `mapstructure:"backfill_interval"`
+
+	// BackfillBatchSize is the number of blobs backfilled per batch.
+	BackfillBatchSize int `mapstructure:"backfill_batch_size"`
+}
+
+// StorageCacheConfig holds settings for the local blob cache. Blobs are
+// cached as they are stored and read, and the least recently used are
+// evicted once the cache holds MaxSize bytes.
+type StorageCacheConfig struct {
+	// Enabled turns on the cache.
+	Enabled bool `mapstructure:"enabled"`
+
+	// Dir is the local directory holding cached blobs.
+	Dir string `mapstructure:"dir"`
+
+	// MaxSize is the most bytes of blobs cached (default: 10GB).
+	MaxSize int64 `mapstructure:"max_size"`
+}
+
+// StorageCompressionConfig holds settings for transparent blob compression.
+// Blobs stored before compression was enabled remain readable; blobs stored
+// while it is enabled can only be read with it enabled.
+type StorageCompressionConfig struct {
+	// Enabled compresses new blobs with Zstandard.
+	Enabled bool `mapstructure:"enabled"`
+
+	// Level is the Zstandard level: fastest, default, better or best.
+	Level string `mapstructure:"level"`
+
+	// SkipContentTypes lists already compressed content types that are stored
+	// as received. A trailing "/*" matches a whole type, e.g. "image/*".
+	SkipContentTypes []string `mapstructure:"skip_content_types"`
 }
 
 // S3StorageConfig holds S3 backend settings (for future use).
@@ -131,6 +274,12 @@ type AuthConfig struct {
 	// Must be exactly 32 bytes (256 bits) for AES-256.
 	EncryptionKey string `mapstructure:"encryption_key"`
 
+	// EncryptionKeySource resolves the encryption key from outside the
+	// configuration instead: "env:<name>", "file:<path>" or
+	// "command:<command line>" (e.g. a KMS or secrets manager client).
+	// Mutually exclusive with EncryptionKey.
+	EncryptionKeySource string `mapstructure:"encryption_key_source"`
+
 	// SSEMasterKey is the hex-encoded 32-byte master key for SSE-S3 encryption.
 	// Used with HKDF to derive per-blob encryption keys.
 	SSEMasterKey string `mapstructure:"sse_master_key"`
@@ -146,6 +295,14 @@ type AuthConfig struct {
 
 	// MaxSignatureAge is the maximum age of a signature before it's considered expired.
 	MaxSignatureAge time.Duration `mapstructure:"max_signature_age"`
+
+	// SigningDebug enables an endpoint that echoes the canonical request and
+	// string to sign computed for a signed request. For development only.
+	SigningDebug bool `mapstructure:"signing_debug"`
+
+	// SignatureV2 accepts legacy AWS Signature Version 2 requests for old
+	// clients that cannot sign with SigV4. Off by default.
+	SignatureV2 bool `mapstructure:"signature_v2"`
 }
 
 // GetEncryptionKey returns the encryption key as a byte slice.
@@ -158,6 +315,20 @@ func (c AuthConfig) GetEncryptionKey() ([]byte, error) {
 	return key, nil
 }
 
+// ResolveEncryptionKey returns the encryption key, reading it from
+// EncryptionKeySource when one is configured. It is meant to be called once
+// at startup; the error names the source but never includes the key.
+func (c AuthConfig) ResolveEncryptionKey(ctx context.Context) ([]byte, error) {
+	if c.EncryptionKeySource == "" {
+		return c.GetEncryptionKey()
+	}
+	provider, err := secrets.Parse(c.EncryptionKeySource)
+	if err != nil {
+		return nil, err
+	}
+	return secrets.ResolveKey(ctx, provider, 32)
+}
+
 // LoggingConfig holds logging settings.
 type LoggingConfig struct {
 	Level      string `mapstructure:"level"`
@@ -171,11 +342,22 @@ type MetricsConfig struct {
 	// Enabled determines if metrics collection is active.
 	Enabled bool `mapstructure:"enabled"`
 
-	// Port is the port for the metrics HTTP server.
+	// Port is the port for a separate metrics HTTP server, 0 for none.
+	// Metrics are also served on the API port, without authentication.
 	Port int `mapstructure:"port"`
 
-	// Path is the URL path for the metrics endpoint.
+	// Path is the URL path for the metrics endpoint on both ports.
 	Path string `mapstructure:"path"`
+
+	// BucketLabels selects the per-bucket request metrics:
+	// "none" (global metrics only), "allowlist" or "hashed".
+	BucketLabels string `mapstructure:"bucket_labels"`
+
+	// Buckets is the allow-list of buckets labeled by name in "allowlist" mode.
+	Buckets []string `mapstructure:"buckets"`
+
+	// HashBuckets is the number of labels bucket names are hashed into in "hashed" mode.
+	HashBuckets int `mapstructure:"hash_buckets"`
 }
 
 // RateLimitConfig holds rate limiting settings.
@@ -183,7 +365,9 @@ type RateLimitConfig struct {
 	// Enabled determines if rate limiting is active.
 	Enabled bool `mapstructure:"enabled"`
 
-	// RequestsPerSecond is the rate of token refill per client.
+	// RequestsPerSecond is the rate of token refill per client. Clients are
+	// access keys, or client addresses for anonymous requests. An access key
+	// may override it and BurstSize.
 	RequestsPerSecond float64 `mapstructure:"requests_per_second"`
 
 	// BurstSize is the maximum number of tokens (burst capacity).
@@ -194,6 +378,15 @@ type RateLimitConfig struct {
 
 	// BytesPerSecond is the bandwidth limit per client (in bytes).
 	BytesPerSecond int64 `mapstructure:"bytes_per_second"`
+
+	// MaxConcurrentLists bounds concurrent list operations across all clients.
+	// It applies even when Enabled is false. 0 means unlimited.
+	MaxConcurrentLists int `mapstructure:"max_concurrent_lists"`
+
+	// MemoryBudget bounds the bytes held by in-flight requests that buffer
+	// their body instead of streaming it, such as XML request documents.
+	// It applies even when Enabled is false. 0 means unlimited.
+	MemoryBudget int64 `mapstructure:"memory_budget"`
 }
 
 // GCConfig holds garbage collection settings.
@@ -212,6 +405,19 @@ type GCConfig struct {
 
 	// DryRun logs what would be deleted without actually deleting.
 	DryRun bool `mapstructure:"dry_run"`
+
+	// SweepStorage also deletes blobs in storage that no blob row tracks,
+	// e.g. left by failed uploads, once they are older than the grace period.
+	// Each run walks the whole data directory.
+	SweepStorage bool `mapstructure:"sweep_storage"`
+
+	// RunWhenStorageFull starts a garbage collection run, at most once a
+	// minute, when a write is rejected because storage is full.
+	RunWhenStorageFull bool `mapstructure:"run_when_storage_full"`
+
+	// SoftDeleteRetention is how long deleted objects can be restored before
+	// they are purged and their blobs become eligible for collection.
+	SoftDeleteRetention time.Duration `mapstructure:"soft_delete_retention"`
 }
 
 // EncryptionConfig holds encryption settings for Fusion Engine.
@@ -229,8 +435,18 @@ type EncryptionConfig struct {
 	MasterKey string `mapstructure:"master_key"`
 }
 
-// VersioningConfig holds delta versioning settings.
+// VersioningConfig holds object versioning settings.
 type VersioningConfig struct {
+	// DeleteBatchSize is the number of versions soft-deleted per statement
+	// when every version of a key is deleted (default: 1000).
+	DeleteBatchSize int `mapstructure:"delete_batch_size"`
+
+	// OverwriteMode is what happens to an object overwritten in a bucket
+	// without versioning: "replace" purges it and releases its blob at once,
+	// "soft_delete" keeps it restorable until gc.soft_delete_retention passes
+	// (default: replace).
+	OverwriteMode string `mapstructure:"overwrite_mode"`
+
 	// DeltaEnabled enables delta versioning for space savings.
 	DeltaEnabled bool `mapstructure:"delta_enabled"`
 
@@ -246,9 +462,25 @@ type VersioningConfig struct {
 	// MaxChunkSize is the maximum CDC chunk size (default: 1MB).
 	MaxChunkSize int `mapstructure:"max_chunk_size"`
 
+	// MinChunkingSize is the blob size below which the chunk index records a
+	// blob as one whole chunk instead of running CDC on it. 0 chunks every
+	// blob (default: 4KB).
+	MinChunkingSize int64 `mapstructure:"min_chunking_size"`
+
 	// MinSavingsThreshold is the minimum savings ratio to use delta (0.0-1.0).
 	// If delta doesn't save at least this much, store full blob instead.
 	MinSavingsThreshold float64 `mapstructure:"min_savings_threshold"`
+
+	// MaxChainDepth is the most deltas applied to rebuild a version. A
+	// version that would be deeper is stored in full (default: 10).
+	MaxChainDepth int `mapstructure:"max_chain_depth"`
+
+	// MaxDeltaObjectSize is the largest version stored as a delta (default: 5GB).
+	MaxDeltaObjectSize int64 `mapstructure:"max_delta_object_size"`
+
+	// DeltaWorkers is the number of versions stored as deltas at once, in
+	// the background after their write completes (default: 2).
+	DeltaWorkers int `mapstructure:"delta_workers"`
 }
 
 // ClusterConfig holds multi-node cluster settings.
@@ -278,8 +510,36 @@ type ClusterConfig struct {
 	// HeartbeatTimeout is how long before a node is considered unhealthy.
 	HeartbeatTimeout time.Duration `mapstructure:"heartbeat_timeout"`
 
-	// ReplicationFactor is the default number of replicas for blobs.
+	// ReplicationFactor is the number of nodes, this one included, that hold
+	// each blob.
 	ReplicationFactor int `mapstructure:"replication_factor"`
+
+	// WriteQuorum is the number of copies, the local one included, written
+	// before a PutObject succeeds. The remaining copies are written in the
+	// background. Must be between 1 and replication_factor.
+	WriteQuorum int `mapstructure:"write_quorum"`
+
+	// TLSCertFile and TLSKeyFile are this node's PEM certificate and key for
+	// inter-node traffic. Nodes use them both to serve and to connect.
+	TLSCertFile string `mapstructure:"tls_cert_file"`
+	TLSKeyFile  string `mapstructure:"tls_key_file"`
+
+	// TLSCAFile holds the PEM CA certificates peer certificates must be
+	// signed by. Peers without such a certificate are refused (mutual TLS).
+	TLSCAFile string `mapstructure:"tls_ca_file"`
+
+	// SharedSecret, when set, must be sent by every calling node. All nodes
+	// of a cluster use the same value.
+	SharedSecret string `mapstructure:"shared_secret"`
+
+	// AllowInsecure permits running the cluster without TLS, sending blobs
+	// in plaintext between nodes. Default: false.
+	AllowInsecure bool `mapstructure:"allow_insecure"`
+}
+
+// TLSEnabled reports whether inter-node TLS is configured.
+func (c ClusterConfig) TLSEnabled() bool {
+	return c.TLSCertFile != "" || c.TLSKeyFile != "" || c.TLSCAFile != ""
 }
 
 // NodeConfig holds configuration for a remote node.
@@ -342,6 +602,55 @@ type MigrationConfig struct {
 	MaxRetries int `mapstructure:"max_retries"`
 }
 
+// TransformConfig holds post-upload transform hook settings.
+type TransformConfig struct {
+	// Enabled runs transformers on newly created objects.
+	Enabled bool `mapstructure:"enabled"`
+
+	// ContentTypes limits which uploads are transformed (empty = all types
+	// some transformer accepts).
+	ContentTypes []string `mapstructure:"content_types"`
+
+	// MaxSourceSize skips objects larger than this many bytes.
+	MaxSourceSize int64 `mapstructure:"max_source_size"`
+
+	// ImageResize configures the reference thumbnail transformer, which is
+	// only available in binaries built with the imagetransform tag.
+	ImageResize ImageResizeConfig `mapstructure:"image_resize"`
+}
+
+// NotificationConfig holds bucket event notification settings.
+type NotificationConfig struct {
+	// Enabled serves the bucket notification API and delivers events to
+	// the webhooks configured on buckets.
+	Enabled bool `mapstructure:"enabled"`
+
+	// QueueSize is the number of deliveries buffered; events are dropped
+	// while the queue is full.
+	QueueSize int `mapstructure:"queue_size"`
+
+	// Workers is the number of concurrent webhook deliveries.
+	Workers int `mapstructure:"workers"`
+
+	// MaxAttempts is how often a delivery is tried before it is given up.
+	MaxAttempts int `mapstructure:"max_attempts"`
+
+	// Timeout bounds a single delivery attempt.
+	Timeout time.Duration `mapstructure:"timeout"`
+
+	// RetryBackoff is the wait before the first retry, doubling per attempt.
+	RetryBackoff time.Duration `mapstructure:"retry_backoff"`
+}
+
+// ImageResizeConfig holds thumbnail transformer settings.
+type ImageResizeConfig struct {
+	// Enabled stores a thumbnail of every JPEG, PNG and GIF upload.
+	Enabled bool `mapstructure:"enabled"`
+
+	// MaxDimension is the largest thumbnail width or height in pixels.
+	MaxDimension int `mapstructure:"max_dimension"`
+}
+
 // Load reads configuration from the specified file and environment variables.
 // Environment variables take precedence over file values.
 // Environment variables are prefixed with ALEXANDER_ and use _ as separator.
@@ -398,6 +707,14 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("server.idle_timeout", 120*time.Second)
 	v.SetDefault("server.shutdown_timeout", 30*time.Second)
 	v.SetDefault("server.max_body_size", 5*1024*1024*1024) // 5GB
+	v.SetDefault("server.max_header_bytes", 1024*1024)     // 1MB
+	v.SetDefault("server.max_metadata_headers", 100)
+	v.SetDefault("server.strict_query_params", false)
+	v.SetDefault("server.max_key_depth", 0)
+	v.SetDefault("server.allow_append", false)
+	v.SetDefault("server.checksum_trailers", false)
+	v.SetDefault("server.base_domain", "")
+	v.SetDefault("server.capabilities", "authenticated")
 
 	// Database defaults
 	v.SetDefault("database.driver", "postgres")
@@ -411,6 +728,10 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("database.max_idle_conns", 5)
 	v.SetDefault("database.conn_max_lifetime", 5*time.Minute)
 	v.SetDefault("database.conn_max_idle_time", 5*time.Minute)
+	v.SetDefault("database.statement_cache_mode", "cache_statement")
+	v.SetDefault("database.statement_cache_capacity", 512)
+	v.SetDefault("database.bucket_cache_ttl", 0)
+	v.SetDefault("database.auto_migrate", true)
 	// SQLite defaults
 	v.SetDefault("database.path", "./data/alexander.db")
 	v.SetDefault("database.journal_mode", "WAL")
@@ -435,13 +756,34 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("storage.multipart.max_part_size", 5*1024*1024*1024) // 5GB
 	v.SetDefault("storage.multipart.max_parts", 10000)
 	v.SetDefault("storage.multipart.upload_expiration", 7*24*time.Hour) // 7 days
+	v.SetDefault("storage.retry.max_attempts", 3)
+	v.SetDefault("storage.retry.initial_backoff", 50*time.Millisecond)
+	v.SetDefault("storage.retry.max_backoff", time.Second)
+	v.SetDefault("storage.dual_write.enabled", false)
+	v.SetDefault("storage.dual_write.backfill_interval", time.Second)
+	v.SetDefault("storage.dual_write.backfill_batch_size", 100)
+	v.SetDefault("storage.cache.enabled", false)
+	v.SetDefault("storage.cache.max_size", int64(10*1024*1024*1024)) // 10GB
+	v.SetDefault("storage.compression.enabled", false)
+	v.SetDefault("storage.compression.level", "default")
+	v.SetDefault("storage.compression.skip_content_types", []string{
+		"image/*", "video/*", "audio/*",
+		"application/zip", "application/gzip", "application/x-gzip",
+		"application/zstd", "application/x-bzip2", "application/x-xz",
+		"application/x-7z-compressed", "application/vnd.rar",
+	})
+	v.SetDefault("storage.default_object_ownership", "BucketOwnerEnforced")
+	v.SetDefault("storage.size_mismatch_policy", "strict")
 
 	// Auth defaults
-	v.SetDefault("auth.encryption_key", "") // Must be provided
+	v.SetDefault("auth.encryption_key", "") // Must be provided, or encryption_key_source
+	v.SetDefault("auth.encryption_key_source", "")
 	v.SetDefault("auth.region", "us-east-1")
 	v.SetDefault("auth.service", "s3")
 	v.SetDefault("auth.presigned_url_expiration", 15*time.Minute)
 	v.SetDefault("auth.max_signature_age", 15*time.Minute)
+	v.SetDefault("auth.signing_debug", false)
+	v.SetDefault("auth.signature_v2", false)
 
 	// Logging defaults
 	v.SetDefault("logging.level", "info")
@@ -453,6 +795,8 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("metrics.enabled", true)
 	v.SetDefault("metrics.port", 9091)
 	v.SetDefault("metrics.path", "/metrics")
+	v.SetDefault("metrics.bucket_labels", "none")
+	v.SetDefault("metrics.hash_buckets", 16)
 
 	// Rate limiting defaults
 	v.SetDefault("rate_limit.enabled", true)
@@ -460,6 +804,8 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("rate_limit.burst_size", 200)
 	v.SetDefault("rate_limit.bandwidth_enabled", false)
 	v.SetDefault("rate_limit.bytes_per_second", 100*1024*1024) // 100 MB/s
+	v.SetDefault("rate_limit.max_concurrent_lists", 64)
+	v.SetDefault("rate_limit.memory_budget", 256*1024*1024) // 256 MB
 
 	// Garbage collection defaults
 	v.SetDefault("gc.enabled", true)
@@ -467,6 +813,9 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("gc.grace_period", 24*time.Hour)
 	v.SetDefault("gc.batch_size", 1000)
 	v.SetDefault("gc.dry_run", false)
+	v.SetDefault("gc.sweep_storage", false)
+	v.SetDefault("gc.run_when_storage_full", false)
+	v.SetDefault("gc.soft_delete_retention", 24*time.Hour)
 
 	// Encryption defaults (Fusion Engine v2.0)
 	v.SetDefault("encryption.scheme", "chacha20-poly1305-stream")
@@ -474,12 +823,18 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("encryption.master_key", "")
 
 	// Versioning defaults (Fusion Engine v2.0)
+	v.SetDefault("versioning.delete_batch_size", 1000)
+	v.SetDefault("versioning.overwrite_mode", "replace")
 	v.SetDefault("versioning.delta_enabled", false)
 	v.SetDefault("versioning.cdc_algorithm", "fastcdc")
 	v.SetDefault("versioning.min_chunk_size", 2*1024)     // 2KB
 	v.SetDefault("versioning.avg_chunk_size", 64*1024)    // 64KB
 	v.SetDefault("versioning.max_chunk_size", 1024*1024)  // 1MB
+	v.SetDefault("versioning.min_chunking_size", 4*1024)  // 4KB
 	v.SetDefault("versioning.min_savings_threshold", 0.2) // 20% minimum savings
+	v.SetDefault("versioning.max_chain_depth", 10)
+	v.SetDefault("versioning.max_delta_object_size", 5*1024*1024*1024) // 5GB
+	v.SetDefault("versioning.delta_workers", 2)
 
 	// Cluster defaults (Fusion Engine v2.0)
 	v.SetDefault("cluster.enabled", false)
@@ -490,6 +845,12 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("cluster.heartbeat_interval", 10*time.Second)
 	v.SetDefault("cluster.heartbeat_timeout", 30*time.Second)
 	v.SetDefault("cluster.replication_factor", 1)
+	v.SetDefault("cluster.write_quorum", 1)
+	v.SetDefault("cluster.tls_cert_file", "")
+	v.SetDefault("cluster.tls_key_file", "")
+	v.SetDefault("cluster.tls_ca_file", "")
+	v.SetDefault("cluster.shared_secret", "")
+	v.SetDefault("cluster.allow_insecure", false)
 
 	// Tiering defaults (Fusion Engine v2.0)
 	v.SetDefault("tiering.enabled", false)
@@ -501,6 +862,20 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("migration.interval", 5*time.Minute)
 	v.SetDefault("migration.lazy_fallback", true)
 	v.SetDefault("migration.max_retries", 3)
+
+	// Transform hook defaults
+	v.SetDefault("transform.enabled", false)
+	v.SetDefault("transform.max_source_size", 32*1024*1024) // 32MB
+	v.SetDefault("transform.image_resize.enabled", false)
+	v.SetDefault("transform.image_resize.max_dimension", 256)
+
+	// Event notification defaults
+	v.SetDefault("notification.enabled", true)
+	v.SetDefault("notification.queue_size", 1000)
+	v.SetDefault("notification.workers", 4)
+	v.SetDefault("notification.max_attempts", 5)
+	v.SetDefault("notification.timeout", 10*time.Second)
+	v.SetDefault("notification.retry_backoff", time.Second)
 }
 
 // Validate checks the configuration for required values and valid ranges.
@@ -509,6 +884,19 @@ func (c *Config) Validate() error {
 	if c.Server.Port < 1 || c.Server.Port > 65535 {
 		return fmt.Errorf("server.port must be between 1 and 65535")
 	}
+	if c.Server.MaxHeaderBytes < 0 {
+		return fmt.Errorf("server.max_header_bytes must not be negative")
+	}
+	if c.Server.MaxMetadataHeaders < 0 {
+		return fmt.Errorf("server.max_metadata_headers must not be negative")
+	}
+	if c.Server.MaxKeyDepth < 0 {
+		return fmt.Errorf("server.max_key_depth must not be negative")
+	}
+	validCapabilities := map[string]bool{"disabled": true, "authenticated": true, "public": true}
+	if !validCapabilities[c.Server.Capabilities] {
+		return fmt.Errorf("server.capabilities must be one of: disabled, authenticated, public")
+	}
 
 	// Validate database configuration
 	validDrivers := map[string]bool{"postgres": true, "sqlite": true}
@@ -526,11 +914,29 @@ func (c *Config) Validate() error {
 		if c.Database.Database == "" {
 			return fmt.Errorf("database.database is required for postgres driver")
 		}
+		if c.Database.MaxOpenConns < 1 {
+			return fmt.Errorf("database.max_open_conns must be at least 1")
+		}
+		if c.Database.MaxIdleConns < 0 || c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+			return fmt.Errorf("database.max_idle_conns must be between 0 and database.max_open_conns")
+		}
+		validCacheModes := map[string]bool{
+			"cache_statement": true, "cache_describe": true, "describe_exec": true, "exec": true, "simple_protocol": true,
+		}
+		if !validCacheModes[c.Database.StatementCacheMode] {
+			return fmt.Errorf("database.statement_cache_mode must be one of: cache_statement, cache_describe, describe_exec, exec, simple_protocol")
+		}
 	} else if c.Database.Driver == "sqlite" {
 		if c.Database.Path == "" {
 			return fmt.Errorf("database.path is required for sqlite driver")
 		}
 	}
+	if c.Database.BucketCacheTTL < 0 {
+		return fmt.Errorf("database.bucket_cache_ttl must not be negative")
+	}
+	if c.Database.BucketCacheTTL > 0 && c.Cluster.Enabled {
+		return fmt.Errorf("database.bucket_cache_ttl must be 0 when the cluster is enabled; other nodes would not see bucket changes")
+	}
 
 	// Validate storage configuration
 	if c.Storage.Backend == "" {
@@ -539,6 +945,120 @@ func (c *Config) Validate() error {
 	if c.Storage.Backend == "filesystem" && c.Storage.DataDir == "" {
 		return fmt.Errorf("storage.data_dir is required for filesystem backend")
 	}
+	if c.Storage.Retry.MaxAttempts < 1 {
+		return fmt.Errorf("storage.retry.max_attempts must be at least 1")
+	}
+	if c.Storage.Retry.MaxBackoff < c.Storage.Retry.InitialBackoff {
+		return fmt.Errorf("storage.retry.max_backoff must not be less than storage.retry.initial_backoff")
+	}
+	if c.Storage.DualWrite.Enabled {
+		if c.Storage.DualWrite.DataDir == "" || c.Storage.DualWrite.TempDir == "" {
+			return fmt.Errorf("storage.dual_write.data_dir and storage.dual_write.temp_dir are required when dual writes are enabled")
+		}
+		if c.Storage.DualWrite.DataDir == c.Storage.DataDir {
+			return fmt.Errorf("storage.dual_write.data_dir must differ from storage.data_dir")
+		}
+		if c.Storage.DualWrite.BackfillBatchSize < 1 {
+			return fmt.Errorf("storage.dual_write.backfill_batch_size must be at least 1")
+		}
+	}
+	if c.Storage.Cache.Enabled {
+		if c.Storage.Cache.Dir == "" {
+			return fmt.Errorf("storage.cache.dir is required when the cache is enabled")
+		}
+		if c.Storage.Cache.Dir == c.Storage.DataDir {
+			return fmt.Errorf("storage.cache.dir must differ from storage.data_dir")
+		}
+		if c.Storage.Cache.MaxSize <= 0 {
+			return fmt.Errorf("storage.cache.max_size must be positive")
+		}
+	}
+	if c.Storage.Compression.Enabled {
+		validLevels := map[string]bool{"fastest": true, "default": true, "better": true, "best": true}
+		if !validLevels[c.Storage.Compression.Level] {
+			return fmt.Errorf("storage.compression.level must be one of: fastest, default, better, best")
+		}
+	}
+	validOwnerships := map[string]bool{"BucketOwnerEnforced": true, "BucketOwnerPreferred": true, "ObjectWriter": true}
+	if !validOwnerships[c.Storage.DefaultObjectOwnership] {
+		return fmt.Errorf("storage.default_object_ownership must be one of: BucketOwnerEnforced, BucketOwnerPreferred, ObjectWriter")
+	}
+	if c.Storage.SizeMismatchPolicy != "strict" && c.Storage.SizeMismatchPolicy != "lenient" {
+		return fmt.Errorf("storage.size_mismatch_policy must be strict or lenient")
+	}
+	if c.Versioning.OverwriteMode != "replace" && c.Versioning.OverwriteMode != "soft_delete" {
+		return fmt.Errorf("versioning.overwrite_mode must be replace or soft_delete")
+	}
+	if c.Versioning.MinChunkingSize < 0 {
+		return fmt.Errorf("versioning.min_chunking_size must not be negative")
+	}
+	if c.Versioning.DeltaEnabled {
+		if c.Versioning.CDCAlgorithm != "fastcdc" {
+			return fmt.Errorf("versioning.cdc_algorithm must be fastcdc")
+		}
+		if c.Versioning.MinChunkSize <= 0 || c.Versioning.MinChunkSize > c.Versioning.AvgChunkSize || c.Versioning.AvgChunkSize > c.Versioning.MaxChunkSize {
+			return fmt.Errorf("versioning chunk sizes must be positive with min_chunk_size <= avg_chunk_size <= max_chunk_size")
+		}
+		if c.Versioning.MinSavingsThreshold < 0 || c.Versioning.MinSavingsThreshold > 1 {
+			return fmt.Errorf("versioning.min_savings_threshold must be between 0 and 1")
+		}
+		if c.Versioning.MaxChainDepth < 1 {
+			return fmt.Errorf("versioning.max_chain_depth must be at least 1")
+		}
+		if c.Versioning.MaxDeltaObjectSize <= 0 {
+			return fmt.Errorf("versioning.max_delta_object_size must be positive")
+		}
+		if c.Versioning.DeltaWorkers < 1 {
+			return fmt.Errorf("versioning.delta_workers must be at least 1")
+		}
+	}
+
+	// Validate garbage collection configuration
+	if c.GC.SoftDeleteRetention < 0 {
+		return fmt.Errorf("gc.soft_delete_retention must not be negative")
+	}
+
+	// Validate cluster configuration
+	if c.Cluster.ReplicationFactor < 1 {
+		return fmt.Errorf("cluster.replication_factor must be at least 1")
+	}
+	if c.Cluster.WriteQuorum < 1 || c.Cluster.WriteQuorum > c.Cluster.ReplicationFactor {
+		return fmt.Errorf("cluster.write_quorum must be between 1 and cluster.replication_factor")
+	}
+	if c.Cluster.Enabled && c.Cluster.NodeID == "" {
+		return fmt.Errorf("cluster.node_id is required when the cluster is enabled")
+	}
+	if c.Cluster.TLSEnabled() && (c.Cluster.TLSCertFile == "" || c.Cluster.TLSKeyFile == "" || c.Cluster.TLSCAFile == "") {
+		return fmt.Errorf("cluster.tls_cert_file, cluster.tls_key_file and cluster.tls_ca_file must be set together")
+	}
+	if c.Cluster.Enabled && !c.Cluster.TLSEnabled() && !c.Cluster.AllowInsecure {
+		return fmt.Errorf("cluster TLS is required when the cluster is enabled; set cluster.allow_insecure to run without it")
+	}
+
+	// Validate metrics configuration
+	validBucketLabels := map[string]bool{"none": true, "allowlist": true, "hashed": true}
+	if !validBucketLabels[c.Metrics.BucketLabels] {
+		return fmt.Errorf("metrics.bucket_labels must be one of: none, allowlist, hashed")
+	}
+	if c.Metrics.BucketLabels == "hashed" && (c.Metrics.HashBuckets < 1 || c.Metrics.HashBuckets > 1000) {
+		return fmt.Errorf("metrics.hash_buckets must be between 1 and 1000")
+	}
+
+	// Validate rate limit configuration
+	if c.RateLimit.Enabled && (c.RateLimit.RequestsPerSecond <= 0 || c.RateLimit.BurstSize < 1) {
+		return fmt.Errorf("rate_limit.requests_per_second and rate_limit.burst_size must be positive when rate limiting is enabled")
+	}
+	if c.RateLimit.MaxConcurrentLists < 0 {
+		return fmt.Errorf("rate_limit.max_concurrent_lists must not be negative")
+	}
+	if c.RateLimit.MemoryBudget < 0 {
+		return fmt.Errorf("rate_limit.memory_budget must not be negative")
+	}
+
+	// Validate notification configuration
+	if c.Notification.QueueSize < 0 || c.Notification.Workers < 0 || c.Notification.MaxAttempts < 0 {
+		return fmt.Errorf("notification.queue_size, notification.workers and notification.max_attempts must not be negative")
+	}
 
 	// Validate auth configuration
 	if c.Auth.EncryptionKey != "" {
@@ -546,6 +1066,14 @@ func (c *Config) Validate() error {
 			return fmt.Errorf("auth.encryption_key must be exactly 32 characters")
 		}
 	}
+	if c.Auth.EncryptionKeySource != "" {
+		if c.Auth.EncryptionKey != "" {
+			return fmt.Errorf("auth.encryption_key and auth.encryption_key_source are mutually exclusive")
+		}
+		if _, err := secrets.Parse(c.Auth.EncryptionKeySource); err != nil {
+			return fmt.Errorf("auth.encryption_key_source: %w", err)
+		}
+	}
 
 	// Validate logging configuration
 	validLevels := map[string]bool{
@@ -78,22 +78,33 @@ func (c *FastCDC) Chunk(ctx context.Context, reader io.Reader) (<-chan Chunk, <-
 		defer close(chunks)
 		defer close(errs)
 
-		// Read all data first - this is simpler and avoids streaming bugs
-		// For very large files, consider using ChunkReader which handles streaming better
-		data, err := io.ReadAll(reader)
-		if err != nil {
-			errs <- err
-			return
-		}
-
-		if len(data) == 0 {
-			return
-		}
-
+		// Only the next MaxSize bytes decide where a chunk ends, so the reader
+		// is consumed through a window of twice that instead of read whole
+		window := max(c.config.MaxSize, 1)
+		buf := make([]byte, 2*window)
+		var start, end int
 		var offset int64
-		remaining := data
+		eof := false
+
+		for {
+			for !eof && end-start < window {
+				if end == len(buf) {
+					end = copy(buf, buf[start:end])
+					start = 0
+				}
+				n, err := reader.Read(buf[end:])
+				end += n
+				if err == io.EOF {
+					eof = true
+				} else if err != nil {
+					errs <- err
+					return
+				}
+			}
+			if start == end {
+				return
+			}
 
-		for len(remaining) > 0 {
 			select {
 			case <-ctx.Done():
 				errs <- ctx.Err()
@@ -102,6 +113,7 @@ func (c *FastCDC) Chunk(ctx context.Context, reader io.Reader) (<-chan Chunk, <-
 			}
 
 			// Find chunk boundary
+			remaining := buf[start:end]
 			chunkSize := c.findBoundary(remaining)
 
 			// Calculate hash of chunk
@@ -125,7 +137,7 @@ func (c *FastCDC) Chunk(ctx context.Context, reader io.Reader) (<-chan Chunk, <-
 			}
 
 			offset += int64(chunkSize)
-			remaining = remaining[chunkSize:]
+			start += chunkSize
 		}
 	}()
 
@@ -7,6 +7,7 @@ import (
 	"io"
 	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -548,3 +549,50 @@ func TestDeltaApplier_MixedInstructions(t *testing.T) {
 	expected := "Hello Beautiful World!"
 	assert.Equal(t, expected, string(result))
 }
+
+func TestFastCDC_ShortReadsChunkLikeWholeReads(t *testing.T) {
+	chunker := NewFastCDC(FastCDCConfig{MinSize: 64, AvgSize: 256, MaxSize: 1024, NormalizationLevel: 2})
+	ctx := context.Background()
+
+	data := make([]byte, 64*1024)
+	_, err := rand.Read(data)
+	require.NoError(t, err)
+
+	whole, err := chunker.ChunkAll(ctx, bytes.NewReader(data))
+	require.NoError(t, err)
+	short, err := chunker.ChunkAll(ctx, iotest.HalfReader(bytes.NewReader(data)))
+	require.NoError(t, err)
+	assert.Equal(t, whole, short)
+}
+
+func TestDeltaApplier_ApplyToStreamsTarget(t *testing.T) {
+	chunker := NewFastCDC(FastCDCConfig{MinSize: 64, AvgSize: 256, MaxSize: 1024, NormalizationLevel: 2})
+	computer := NewComputer(chunker)
+	applier := NewApplier()
+	ctx := context.Background()
+
+	base := make([]byte, 16*1024)
+	_, err := rand.Read(base)
+	require.NoError(t, err)
+	target := bytes.Clone(base)
+	copy(target[8*1024:], "a small edit")
+
+	delta, err := computer.Compute(ctx, bytes.NewReader(base), bytes.NewReader(target))
+	require.NoError(t, err)
+	require.Positive(t, delta.SavingsRatio)
+
+	var deltaData bytes.Buffer
+	n, err := computer.WriteDeltaData(ctx, bytes.NewReader(target), delta, &deltaData)
+	require.NoError(t, err)
+	assert.Equal(t, delta.DeltaSize, n)
+
+	var result bytes.Buffer
+	require.NoError(t, applier.ApplyTo(ctx, bytes.NewReader(base), delta, &deltaData, &result))
+	assert.Equal(t, target, result.Bytes())
+
+	// Instructions that skip part of the target are rejected
+	gapped := *delta
+	gapped.Instructions = delta.Instructions[1:]
+	err = applier.ApplyTo(ctx, bytes.NewReader(base), &gapped, bytes.NewReader(nil), io.Discard)
+	assert.Error(t, err)
+}
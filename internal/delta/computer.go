@@ -7,11 +7,13 @@ import (
 	"encoding/hex"
 	"fmt"
 	"io"
+	"time"
 )
 
 // Computer computes deltas between blobs using content-defined chunking.
 type Computer struct {
 	chunker Chunker
+	monitor *Monitor
 }
 
 // NewComputer creates a new delta computer with the given chunker.
@@ -26,22 +28,48 @@ func NewComputerDefault() *Computer {
 	return NewComputer(NewFastCDCDefault())
 }
 
-// Compute implements DeltaComputer interface.
+// SetMonitor sets the monitor that records chunking and delta savings.
+func (c *Computer) SetMonitor(monitor *Monitor) {
+	c.monitor = monitor
+}
+
+// Compute implements DeltaComputer interface. Chunk data is dropped as the
+// readers are chunked, so memory use does not grow with the blob sizes.
 func (c *Computer) Compute(ctx context.Context, base, target io.Reader) (*Delta, error) {
 	// Chunk both base and target
-	baseChunks, err := c.chunker.ChunkAll(ctx, base)
+	baseChunks, err := c.chunkMetadata(ctx, base)
 	if err != nil {
 		return nil, fmt.Errorf("failed to chunk base: %w", err)
 	}
 
-	targetChunks, err := c.chunker.ChunkAll(ctx, target)
+	// Only the target is new data, so only its chunking is recorded
+	start := time.Now()
+	targetChunks, err := c.chunkMetadata(ctx, target)
 	if err != nil {
 		return nil, fmt.Errorf("failed to chunk target: %w", err)
 	}
+	c.monitor.ObserveChunking(targetChunks, time.Since(start))
 
 	return c.ComputeFromChunks(ctx, baseChunks, targetChunks)
 }
 
+// chunkMetadata chunks reader and returns the chunks without their data.
+func (c *Computer) chunkMetadata(ctx context.Context, reader io.Reader) ([]Chunk, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel() // Stops the chunker if we return early
+
+	var result []Chunk
+	chunkCh, errCh := c.chunker.Chunk(ctx, reader)
+	for chunk := range chunkCh {
+		chunk.Data = nil
+		result = append(result, chunk)
+	}
+	if err := <-errCh; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 // ComputeFromChunks implements DeltaComputer interface.
 func (c *Computer) ComputeFromChunks(ctx context.Context, baseChunks, targetChunks []Chunk) (*Delta, error) {
 	// Build index of base chunks
@@ -98,47 +126,66 @@ func (c *Computer) ComputeFromChunks(ctx context.Context, baseChunks, targetChun
 		savingsRatio = 1.0 - float64(deltaSize)/float64(totalSize)
 	}
 
-	return &Delta{
+	delta := &Delta{
 		SourceHash:   sourceHash,
 		BaseHash:     baseHash,
 		Instructions: instructions,
 		TotalSize:    totalSize,
 		DeltaSize:    deltaSize,
 		SavingsRatio: savingsRatio,
-	}, nil
+	}
+	c.monitor.ObserveDelta(delta)
+
+	return delta, nil
 }
 
 // ExtractDeltaData extracts the insert data from target based on delta instructions.
 // This is the data that needs to be stored alongside the delta.
 func (c *Computer) ExtractDeltaData(ctx context.Context, target io.Reader, delta *Delta) ([]byte, error) {
-	// Read all target data
-	targetData, err := io.ReadAll(target)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read target: %w", err)
+	var result bytes.Buffer
+	result.Grow(int(delta.DeltaSize))
+	if _, err := c.WriteDeltaData(ctx, target, delta, &result); err != nil {
+		return nil, err
 	}
+	return result.Bytes(), nil
+}
 
-	// Calculate total insert size
-	var insertSize int64
+// WriteDeltaData streams the insert data of delta from target to w, in the
+// order the instructions insert it, and returns the number of bytes written.
+// The instructions must be in target order, as Compute produces them.
+func (c *Computer) WriteDeltaData(ctx context.Context, target io.Reader, delta *Delta, w io.Writer) (int64, error) {
+	var pos, written int64
 	for _, inst := range delta.Instructions {
-		if inst.Type == InstructionInsert {
-			insertSize += inst.Length
+		if inst.Type != InstructionInsert {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return written, err
+		}
+		if inst.TargetOffset < pos {
+			return written, fmt.Errorf("delta instructions are not in target order")
 		}
-	}
 
-	// Extract insert data in order
-	result := make([]byte, 0, insertSize)
-	for _, inst := range delta.Instructions {
-		if inst.Type == InstructionInsert {
-			start := inst.TargetOffset
-			end := start + inst.Length
-			if end > int64(len(targetData)) {
-				return nil, fmt.Errorf("instruction exceeds target size")
-			}
-			result = append(result, targetData[start:end]...)
+		// Skip the bytes copied from the base
+		if _, err := io.CopyN(io.Discard, target, inst.TargetOffset-pos); err != nil {
+			return written, targetReadError(err)
 		}
+		n, err := io.CopyN(w, target, inst.Length)
+		written += n
+		if err != nil {
+			return written, targetReadError(err)
+		}
+		pos = inst.TargetOffset + inst.Length
 	}
+	return written, nil
+}
 
-	return result, nil
+// targetReadError describes a failed read of the target blob.
+func targetReadError(err error) error {
+	if err == io.EOF {
+		return fmt.Errorf("instruction exceeds target size")
+	}
+	return fmt.Errorf("failed to read target: %w", err)
 }
 
 // computeChunksHash computes an overall hash for a list of chunks.
@@ -198,15 +245,88 @@ var _ DeltaComputer = (*Computer)(nil)
 var _ ChunkIndex = (*MemoryIndex)(nil)
 
 // Applier reconstructs blobs by applying deltas to base blobs.
-type Applier struct{}
+type Applier struct {
+	monitor *Monitor
+}
 
 // NewApplier creates a new delta applier.
 func NewApplier() *Applier {
 	return &Applier{}
 }
 
+// SetMonitor sets the monitor that records reconstruction failures.
+func (a *Applier) SetMonitor(monitor *Monitor) {
+	a.monitor = monitor
+}
+
 // Apply implements DeltaApplier interface.
 func (a *Applier) Apply(ctx context.Context, base io.ReadSeeker, delta *Delta, deltaData io.Reader) (io.Reader, error) {
+	reader, err := a.apply(ctx, base, delta, deltaData)
+	// A cancelled request says nothing about the health of stored deltas
+	if ctx.Err() == nil {
+		a.monitor.ObserveReconstruction(err)
+	}
+	return reader, err
+}
+
+// ApplyTo reconstructs the target blob from base + delta and streams it to w.
+// Unlike Apply it never holds the blob in memory, so the instructions must
+// cover the target in order and read deltaData sequentially, as Compute
+// produces them.
+func (a *Applier) ApplyTo(ctx context.Context, base io.ReadSeeker, delta *Delta, deltaData io.Reader, w io.Writer) error {
+	err := a.applyTo(ctx, base, delta, deltaData, w)
+	if ctx.Err() == nil {
+		a.monitor.ObserveReconstruction(err)
+	}
+	return err
+}
+
+// applyTo streams the target blob rebuilt from base + delta to w.
+func (a *Applier) applyTo(ctx context.Context, base io.ReadSeeker, delta *Delta, deltaData io.Reader, w io.Writer) error {
+	var targetOffset, insertOffset int64
+	for _, inst := range delta.Instructions {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if inst.TargetOffset != targetOffset || inst.Length < 0 || targetOffset+inst.Length > delta.TotalSize {
+			return fmt.Errorf("delta instructions do not cover the target in order")
+		}
+
+		switch inst.Type {
+		case InstructionCopy:
+			if _, err := base.Seek(inst.SourceOffset, io.SeekStart); err != nil {
+				return fmt.Errorf("failed to seek in base: %w", err)
+			}
+			if _, err := io.CopyN(w, base, inst.Length); err != nil {
+				return fmt.Errorf("failed to read from base: %w", err)
+			}
+
+		case InstructionInsert:
+			if inst.SourceOffset != insertOffset {
+				return fmt.Errorf("delta instructions do not read insert data in order")
+			}
+			if _, err := io.CopyN(w, deltaData, inst.Length); err != nil {
+				if err == io.EOF {
+					return fmt.Errorf("insert data exhausted")
+				}
+				return fmt.Errorf("failed to read delta data: %w", err)
+			}
+			insertOffset += inst.Length
+
+		default:
+			return fmt.Errorf("unknown delta instruction %q", inst.Type)
+		}
+		targetOffset += inst.Length
+	}
+
+	if targetOffset != delta.TotalSize {
+		return fmt.Errorf("delta instructions do not cover the target")
+	}
+	return nil
+}
+
+// apply reconstructs the target blob from base + delta.
+func (a *Applier) apply(ctx context.Context, base io.ReadSeeker, delta *Delta, deltaData io.Reader) (io.Reader, error) {
 	// Read all delta data into memory for random access
 	insertData, err := io.ReadAll(deltaData)
 	if err != nil {
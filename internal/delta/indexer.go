@@ -0,0 +1,213 @@
+package delta
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"time"
+)
+
+// DefaultRebuildBatchSize is the number of blobs listed per page during a rebuild.
+const DefaultRebuildBatchSize = 100
+
+// DefaultMinChunkingSize is the blob size below which blobs are indexed whole.
+// Chunk metadata for tiny blobs costs more than deduplication can save.
+const DefaultMinChunkingSize = 4 * 1024
+
+// Indexer chunks blob content and records the chunks in a ChunkStore.
+type Indexer struct {
+	chunker Chunker
+	store   ChunkStore
+	monitor *Monitor
+
+	minChunkingSize int64
+}
+
+// NewIndexer creates a new chunk indexer.
+func NewIndexer(chunker Chunker, store ChunkStore) *Indexer {
+	return &Indexer{
+		chunker:         chunker,
+		store:           store,
+		minChunkingSize: DefaultMinChunkingSize,
+	}
+}
+
+// SetMinChunkingSize sets the size below which blobs bypass chunking and are
+// indexed as a single whole-blob chunk. 0 chunks every blob.
+func (ix *Indexer) SetMinChunkingSize(size int64) {
+	if size >= 0 {
+		ix.minChunkingSize = size
+	}
+}
+
+// SetMonitor sets the monitor that records chunking and dedup hits.
+func (ix *Indexer) SetMonitor(monitor *Monitor) {
+	ix.monitor = monitor
+}
+
+// IndexResult summarizes the chunks recorded for a single blob.
+type IndexResult struct {
+	// NewChunks is the number of chunks not previously in the store.
+	NewChunks int `json:"new_chunks"`
+
+	// ReusedChunks is the number of chunks already present in the store.
+	ReusedChunks int `json:"reused_chunks"`
+
+	// Whole is true when the blob was below the chunking threshold and was
+	// indexed as a single chunk.
+	Whole bool `json:"whole,omitempty"`
+}
+
+// RebuildResult summarizes a chunk index rebuild.
+type RebuildResult struct {
+	// BlobsIndexed is the number of blobs successfully chunked.
+	BlobsIndexed int `json:"blobs_indexed"`
+
+	// BlobsFailed is the number of blobs that could not be read or chunked.
+	BlobsFailed int `json:"blobs_failed"`
+
+	// Stats are the chunk statistics after the rebuild.
+	Stats *ChunkStats `json:"stats"`
+}
+
+// IndexBlob chunks the content of reader and records every chunk in the store.
+// Blobs smaller than the chunking threshold are recorded as one chunk.
+func (ix *Indexer) IndexBlob(ctx context.Context, reader io.Reader) (*IndexResult, error) {
+	result, _, err := ix.index(ctx, ix.store, reader)
+	return result, err
+}
+
+// chunkWriter is where index records chunks: the live store, or a rebuild.
+type chunkWriter interface {
+	Store(ctx context.Context, chunk *Chunk) (bool, error)
+}
+
+// index records the chunks of reader in store and returns them in blob order.
+func (ix *Indexer) index(ctx context.Context, store chunkWriter, reader io.Reader) (*IndexResult, []Chunk, error) {
+	var chunks []Chunk
+	whole := false
+
+	// Read up to the threshold to tell small blobs apart without knowing the size
+	head, err := io.ReadAll(io.LimitReader(reader, ix.minChunkingSize))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read blob: %w", err)
+	}
+	if int64(len(head)) < ix.minChunkingSize {
+		if len(head) > 0 {
+			sum := sha256.Sum256(head)
+			chunks = []Chunk{{Hash: hex.EncodeToString(sum[:]), Size: int64(len(head))}}
+		}
+		whole = true
+	} else {
+		start := time.Now()
+		chunks, err = ix.chunker.ChunkAll(ctx, io.MultiReader(bytes.NewReader(head), reader))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to chunk blob: %w", err)
+		}
+		ix.monitor.ObserveChunking(chunks, time.Since(start))
+	}
+
+	result := &IndexResult{Whole: whole}
+	for i := range chunks {
+		chunks[i].Data = nil // Only metadata is indexed
+
+		isNew, err := store.Store(ctx, &chunks[i])
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to store chunk %s: %w", chunks[i].Hash, err)
+		}
+		if isNew {
+			result.NewChunks++
+		} else {
+			result.ReusedChunks++
+		}
+	}
+	ix.monitor.ObserveIndex(result)
+
+	return result, chunks, nil
+}
+
+// Rebuild re-creates the chunk index from every blob exposed by source.
+// Blobs are listed in pages of batchSize so memory use stays bounded. A blob
+// that cannot be read is counted as failed and skipped. The new index is
+// staged and only replaces the current one once every blob was visited, so
+// a failed or cancelled rebuild leaves the current index in place.
+func (ix *Indexer) Rebuild(ctx context.Context, source BlobSource, batchSize int) (*RebuildResult, error) {
+	if batchSize <= 0 {
+		batchSize = DefaultRebuildBatchSize
+	}
+
+	rebuild, err := ix.store.BeginRebuild(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin rebuild: %w", err)
+	}
+	// The context may be cancelled already; the staged index is dropped anyway
+	defer rebuild.Abort(context.WithoutCancel(ctx))
+
+	result := &RebuildResult{}
+	after := ""
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		hashes, err := source.ListBlobHashes(ctx, after, batchSize)
+		if err != nil {
+			return result, fmt.Errorf("failed to list blobs: %w", err)
+		}
+		if len(hashes) == 0 {
+			break
+		}
+
+		for _, hash := range hashes {
+			if err := ix.indexFromSource(ctx, rebuild, source, hash); err != nil {
+				if ctx.Err() != nil {
+					return result, ctx.Err()
+				}
+				result.BlobsFailed++
+				continue
+			}
+			result.BlobsIndexed++
+		}
+
+		after = hashes[len(hashes)-1]
+		if len(hashes) < batchSize {
+			break
+		}
+	}
+
+	if err := rebuild.Commit(ctx); err != nil {
+		return result, fmt.Errorf("failed to commit rebuilt chunk index: %w", err)
+	}
+
+	stats, err := ix.store.Stats(ctx)
+	if err != nil {
+		return result, fmt.Errorf("failed to read chunk stats: %w", err)
+	}
+	result.Stats = stats
+
+	return result, nil
+}
+
+// indexFromSource opens a single blob, stages its chunks in rebuild and
+// records the blob's chunk list.
+func (ix *Indexer) indexFromSource(ctx context.Context, rebuild ChunkRebuild, source BlobSource, contentHash string) error {
+	reader, err := source.Open(ctx, contentHash)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	_, chunks, err := ix.index(ctx, rebuild, reader)
+	if err != nil {
+		return err
+	}
+
+	if err := rebuild.RecordBlobChunks(ctx, contentHash, chunks); err != nil {
+		return fmt.Errorf("failed to record chunks of blob %s: %w", contentHash, err)
+	}
+	return nil
+}
@@ -0,0 +1,266 @@
+package delta
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"io"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestIndexer() (*Indexer, *MemoryChunkStore) {
+	store := NewMemoryChunkStore()
+	cdc := NewFastCDC(FastCDCConfig{
+		MinSize:            512,
+		AvgSize:            2048,
+		MaxSize:            8192,
+		NormalizationLevel: 2,
+	})
+	return NewIndexer(cdc, store), store
+}
+
+func TestIndexer_NearIdenticalObjectsShareChunks(t *testing.T) {
+	ix, store := newTestIndexer()
+	ctx := context.Background()
+
+	original := make([]byte, 128*1024)
+	_, err := rand.Read(original)
+	require.NoError(t, err)
+
+	// Second object differs only by a small edit in the middle
+	modified := make([]byte, len(original))
+	copy(modified, original)
+	copy(modified[64*1024:], []byte("a small edit in the middle"))
+
+	first, err := ix.IndexBlob(ctx, bytes.NewReader(original))
+	require.NoError(t, err)
+	assert.Zero(t, first.ReusedChunks)
+
+	second, err := ix.IndexBlob(ctx, bytes.NewReader(modified))
+	require.NoError(t, err)
+	assert.Greater(t, second.ReusedChunks, 0)
+	assert.Less(t, second.NewChunks, second.ReusedChunks)
+
+	stats, err := store.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(second.ReusedChunks), stats.ReusedReferences())
+	assert.Equal(t, int64(len(original)+len(modified)), stats.LogicalBytes)
+	assert.Greater(t, stats.BytesSaved(), int64(len(original)/2))
+}
+
+func TestIndexer_SmallBlobIndexedWhole(t *testing.T) {
+	ix, store := newTestIndexer()
+	monitor := NewMonitor(nil)
+	ix.SetMonitor(monitor)
+	ctx := context.Background()
+
+	small := bytes.Repeat([]byte("x"), 100)
+	result, err := ix.IndexBlob(ctx, bytes.NewReader(small))
+	require.NoError(t, err)
+	assert.True(t, result.Whole)
+	assert.Equal(t, 1, result.NewChunks)
+
+	stats, err := store.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, ChunkStats{UniqueChunks: 1, TotalReferences: 1, StoredBytes: 100, LogicalBytes: 100}, *stats)
+
+	// The chunker never ran
+	assert.Zero(t, monitor.Stats().Chunks)
+
+	// An identical small blob deduplicates against the whole-blob chunk
+	result, err = ix.IndexBlob(ctx, bytes.NewReader(small))
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.ReusedChunks)
+}
+
+func TestIndexer_MinChunkingSize(t *testing.T) {
+	ctx := context.Background()
+
+	data := make([]byte, 16*1024)
+	_, err := rand.Read(data)
+	require.NoError(t, err)
+
+	// Blobs at or above the threshold are chunked, including the bytes read
+	// to check the threshold
+	ix, store := newTestIndexer()
+	ix.SetMinChunkingSize(int64(len(data)))
+	result, err := ix.IndexBlob(ctx, bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.False(t, result.Whole)
+	assert.Greater(t, result.NewChunks, 1)
+
+	stats, err := store.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(data)), stats.StoredBytes)
+
+	// With the guard disabled even small blobs go through the chunker
+	ix, _ = newTestIndexer()
+	ix.SetMinChunkingSize(0)
+	result, err = ix.IndexBlob(ctx, bytes.NewReader(data[:100]))
+	require.NoError(t, err)
+	assert.False(t, result.Whole)
+}
+
+func TestMemoryChunkStore_DecrementRefUpdatesStats(t *testing.T) {
+	store := NewMemoryChunkStore()
+	ctx := context.Background()
+
+	chunk := &Chunk{Hash: "abc", Size: 100}
+	_, err := store.Store(ctx, chunk)
+	require.NoError(t, err)
+	isNew, err := store.Store(ctx, chunk)
+	require.NoError(t, err)
+	assert.False(t, isNew)
+
+	refs, err := store.DecrementRef(ctx, "abc")
+	require.NoError(t, err)
+	assert.Equal(t, 1, refs)
+
+	stats, err := store.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, ChunkStats{UniqueChunks: 1, TotalReferences: 1, StoredBytes: 100, LogicalBytes: 100}, *stats)
+
+	// An orphaned chunk no longer counts as stored, as in the PostgreSQL store
+	refs, err = store.DecrementRef(ctx, "abc")
+	require.NoError(t, err)
+	assert.Equal(t, 0, refs)
+
+	stats, err = store.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, ChunkStats{}, *stats)
+
+	orphans, err := store.ListOrphans(ctx, 0)
+	require.NoError(t, err)
+	assert.Len(t, orphans, 1)
+
+	// Referencing the orphan again restores it
+	require.NoError(t, store.IncrementRef(ctx, "abc"))
+	stats, err = store.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, ChunkStats{UniqueChunks: 1, TotalReferences: 1, StoredBytes: 100, LogicalBytes: 100}, *stats)
+}
+
+// memoryBlobSource serves blobs from a map for rebuild tests.
+type memoryBlobSource struct {
+	blobs  map[string][]byte
+	broken map[string]bool
+}
+
+func (s *memoryBlobSource) ListBlobHashes(ctx context.Context, after string, limit int) ([]string, error) {
+	var hashes []string
+	for hash := range s.blobs {
+		if hash > after {
+			hashes = append(hashes, hash)
+		}
+	}
+	sort.Strings(hashes)
+	if len(hashes) > limit {
+		hashes = hashes[:limit]
+	}
+	return hashes, nil
+}
+
+func (s *memoryBlobSource) Open(ctx context.Context, contentHash string) (io.ReadCloser, error) {
+	if s.broken[contentHash] {
+		return nil, errors.New("blob unreadable")
+	}
+	return io.NopCloser(bytes.NewReader(s.blobs[contentHash])), nil
+}
+
+func TestIndexer_Rebuild(t *testing.T) {
+	ix, store := newTestIndexer()
+	ctx := context.Background()
+
+	data := make([]byte, 32*1024)
+	_, err := rand.Read(data)
+	require.NoError(t, err)
+
+	source := &memoryBlobSource{
+		blobs: map[string][]byte{
+			"a": data,
+			"b": data,
+			"c": data,
+			"d": []byte("unreadable"),
+		},
+		broken: map[string]bool{"d": true},
+	}
+
+	// Pre-populate with stale entries that the rebuild must discard
+	_, err = store.Store(ctx, &Chunk{Hash: "stale", Size: 999})
+	require.NoError(t, err)
+
+	result, err := ix.Rebuild(ctx, source, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.BlobsIndexed)
+	assert.Equal(t, 1, result.BlobsFailed)
+
+	_, err = store.Get(ctx, "stale")
+	assert.Error(t, err)
+
+	require.NotNil(t, result.Stats)
+	assert.Equal(t, int64(len(data)), result.Stats.StoredBytes)
+	assert.Equal(t, int64(3*len(data)), result.Stats.LogicalBytes)
+	assert.Equal(t, 2*result.Stats.UniqueChunks, result.Stats.ReusedReferences())
+
+	// Every readable blob has its chunk list recorded, covering the whole blob
+	chunks := store.BlobChunks("a")
+	require.NotEmpty(t, chunks)
+	var offset int64
+	for _, chunk := range chunks {
+		assert.Equal(t, offset, chunk.Offset)
+		offset += chunk.Size
+	}
+	assert.Equal(t, int64(len(data)), offset)
+	assert.Equal(t, chunks, store.BlobChunks("c"))
+	assert.Nil(t, store.BlobChunks("d"))
+}
+
+// failingBlobSource fails to list blobs after the first page.
+type failingBlobSource struct {
+	*memoryBlobSource
+}
+
+func (s *failingBlobSource) ListBlobHashes(ctx context.Context, after string, limit int) ([]string, error) {
+	if after != "" {
+		return nil, errors.New("listing interrupted")
+	}
+	return s.memoryBlobSource.ListBlobHashes(ctx, after, limit)
+}
+
+func TestIndexer_FailedRebuildKeepsCurrentIndex(t *testing.T) {
+	ix, store := newTestIndexer()
+	ctx := context.Background()
+
+	current := bytes.Repeat([]byte("current"), 1000)
+	_, err := ix.IndexBlob(ctx, bytes.NewReader(current))
+	require.NoError(t, err)
+	require.NoError(t, store.RecordBlobChunks(ctx, "current", []Chunk{{Hash: "kept", Size: int64(len(current))}}))
+	before, err := store.Stats(ctx)
+	require.NoError(t, err)
+
+	source := &memoryBlobSource{blobs: map[string][]byte{
+		"a": bytes.Repeat([]byte("a"), 8192),
+		"b": bytes.Repeat([]byte("b"), 8192),
+	}}
+
+	// Listing fails part way through
+	_, err = ix.Rebuild(ctx, &failingBlobSource{source}, 1)
+	require.Error(t, err)
+
+	// So does a cancelled rebuild
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = ix.Rebuild(cancelled, source, 1)
+	require.ErrorIs(t, err, context.Canceled)
+
+	after, err := store.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, *before, *after)
+	assert.NotEmpty(t, store.BlobChunks("current"))
+	assert.Nil(t, store.BlobChunks("a"))
+}
@@ -133,4 +133,71 @@ type ChunkStore interface {
 
 	// ListOrphans returns chunks with zero references.
 	ListOrphans(ctx context.Context, limit int) ([]Chunk, error)
+
+	// Stats returns aggregate chunk reuse statistics.
+	// Implementations must answer in bounded time regardless of index size.
+	Stats(ctx context.Context) (*ChunkStats, error)
+
+	// BeginRebuild starts building a replacement index. The current index
+	// keeps serving until the returned rebuild is committed.
+	BeginRebuild(ctx context.Context) (ChunkRebuild, error)
+}
+
+// ChunkRebuild stages a replacement chunk index. Abandoning a rebuild, or
+// aborting it, leaves the current index untouched.
+type ChunkRebuild interface {
+	// Store stages a chunk and returns whether it's new to the staged index.
+	Store(ctx context.Context, chunk *Chunk) (isNew bool, err error)
+
+	// RecordBlobChunks stages the chunk list of a blob. Stores that keep no
+	// blob-to-chunk mappings ignore it.
+	RecordBlobChunks(ctx context.Context, blobHash string, chunks []Chunk) error
+
+	// Commit atomically replaces the current index with the staged one.
+	Commit(ctx context.Context) error
+
+	// Abort discards the staged index. It is a no-op after Commit.
+	Abort(ctx context.Context) error
+}
+
+// ChunkStats summarizes chunk-level deduplication.
+type ChunkStats struct {
+	// UniqueChunks is the number of distinct chunks stored.
+	UniqueChunks int64 `json:"unique_chunks"`
+
+	// TotalReferences is the sum of reference counts across all chunks.
+	TotalReferences int64 `json:"total_references"`
+
+	// StoredBytes is the size of all distinct chunks (physical footprint).
+	StoredBytes int64 `json:"stored_bytes"`
+
+	// LogicalBytes is the size of all chunk references (footprint without dedup).
+	LogicalBytes int64 `json:"logical_bytes"`
+}
+
+// ReusedReferences returns the number of chunk references served by an existing chunk.
+func (s ChunkStats) ReusedReferences() int64 {
+	return s.TotalReferences - s.UniqueChunks
+}
+
+// BytesSaved returns the number of bytes avoided through chunk reuse.
+func (s ChunkStats) BytesSaved() int64 {
+	return s.LogicalBytes - s.StoredBytes
+}
+
+// BlobSource enumerates stored blobs for rebuilding the chunk index.
+type BlobSource interface {
+	// ListBlobHashes returns up to limit content hashes greater than after, in ascending order.
+	ListBlobHashes(ctx context.Context, after string, limit int) ([]string, error)
+
+	// Open returns a reader for the plaintext content of a blob.
+	Open(ctx context.Context, contentHash string) (io.ReadCloser, error)
+}
+
+// BlobChunkRecorder is implemented by chunk stores that also keep the
+// ordered list of chunks making up each blob. A rebuild stages the mappings
+// of every blob and replaces them along with the chunks.
+type BlobChunkRecorder interface {
+	// RecordBlobChunks replaces the chunk list of a blob.
+	RecordBlobChunks(ctx context.Context, blobHash string, chunks []Chunk) error
 }
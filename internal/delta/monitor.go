@@ -0,0 +1,150 @@
+package delta
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prn-tf/alexander-storage/internal/metrics"
+)
+
+// Monitor collects delta/CDC activity so operators can tell whether
+// deduplication pays off. It reports to Prometheus when metrics are enabled
+// and keeps running totals for health reporting. A nil Monitor is valid and
+// records nothing.
+type Monitor struct {
+	metrics *metrics.Metrics
+
+	mu    sync.Mutex
+	stats MonitorStats
+}
+
+// MonitorStats summarizes delta/CDC activity since startup.
+type MonitorStats struct {
+	// ChunkedBytes is the number of bytes split into chunks.
+	ChunkedBytes int64 `json:"chunked_bytes"`
+
+	// Chunks is the number of chunks produced.
+	Chunks int64 `json:"chunks"`
+
+	// NewChunks and ReusedChunks count indexed chunks by whether they were
+	// already present in the chunk store.
+	NewChunks    int64 `json:"new_chunks"`
+	ReusedChunks int64 `json:"reused_chunks"`
+
+	// LastSavingsRatio is the savings ratio of the most recent delta.
+	LastSavingsRatio float64 `json:"last_savings_ratio"`
+
+	// ReconstructionFailures is the number of failed delta reconstructions.
+	ReconstructionFailures int64 `json:"reconstruction_failures"`
+
+	// LastReconstructionError is the error of the most recent reconstruction,
+	// empty if it succeeded.
+	LastReconstructionError string `json:"last_reconstruction_error,omitempty"`
+}
+
+// AverageChunkSize returns the mean size of the chunks produced.
+func (s MonitorStats) AverageChunkSize() int64 {
+	if s.Chunks == 0 {
+		return 0
+	}
+	return s.ChunkedBytes / s.Chunks
+}
+
+// DedupHitRate returns the fraction of indexed chunks that were already stored.
+func (s MonitorStats) DedupHitRate() float64 {
+	total := s.NewChunks + s.ReusedChunks
+	if total == 0 {
+		return 0
+	}
+	return float64(s.ReusedChunks) / float64(total)
+}
+
+// NewMonitor creates a new Monitor. m may be nil when metrics are disabled.
+func NewMonitor(m *metrics.Metrics) *Monitor {
+	return &Monitor{metrics: m}
+}
+
+// ObserveChunking records the chunks produced for one blob.
+func (mon *Monitor) ObserveChunking(chunks []Chunk, duration time.Duration) {
+	if mon == nil {
+		return
+	}
+
+	sizes := make([]int64, len(chunks))
+	var total int64
+	for i, chunk := range chunks {
+		sizes[i] = chunk.Size
+		total += chunk.Size
+	}
+
+	mon.mu.Lock()
+	mon.stats.ChunkedBytes += total
+	mon.stats.Chunks += int64(len(chunks))
+	mon.mu.Unlock()
+
+	if mon.metrics != nil {
+		mon.metrics.RecordDeltaChunking(sizes, duration)
+	}
+}
+
+// ObserveIndex records the outcome of indexing one blob's chunks.
+func (mon *Monitor) ObserveIndex(result *IndexResult) {
+	if mon == nil || result == nil {
+		return
+	}
+
+	mon.mu.Lock()
+	mon.stats.NewChunks += int64(result.NewChunks)
+	mon.stats.ReusedChunks += int64(result.ReusedChunks)
+	mon.mu.Unlock()
+
+	if mon.metrics != nil {
+		mon.metrics.RecordDeltaDedup(result.NewChunks, result.ReusedChunks)
+	}
+}
+
+// ObserveDelta records a computed delta.
+func (mon *Monitor) ObserveDelta(delta *Delta) {
+	if mon == nil || delta == nil {
+		return
+	}
+
+	mon.mu.Lock()
+	mon.stats.LastSavingsRatio = delta.SavingsRatio
+	mon.mu.Unlock()
+
+	if mon.metrics != nil {
+		mon.metrics.RecordDelta(delta.SavingsRatio, delta.TotalSize, delta.DeltaSize)
+	}
+}
+
+// ObserveReconstruction records the outcome of applying a delta.
+func (mon *Monitor) ObserveReconstruction(err error) {
+	if mon == nil {
+		return
+	}
+
+	mon.mu.Lock()
+	if err != nil {
+		mon.stats.ReconstructionFailures++
+		mon.stats.LastReconstructionError = err.Error()
+	} else {
+		mon.stats.LastReconstructionError = ""
+	}
+	mon.mu.Unlock()
+
+	if mon.metrics != nil {
+		mon.metrics.RecordDeltaReconstruction(err == nil)
+	}
+}
+
+// Stats returns a snapshot of the collected statistics.
+func (mon *Monitor) Stats() MonitorStats {
+	if mon == nil {
+		return MonitorStats{}
+	}
+
+	mon.mu.Lock()
+	defer mon.mu.Unlock()
+	return mon.stats
+}
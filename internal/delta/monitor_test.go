@@ -0,0 +1,70 @@
+package delta
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/metrics"
+)
+
+func TestMonitor_RecordsDeltaActivity(t *testing.T) {
+	m := metrics.New()
+	monitor := NewMonitor(m)
+
+	chunker := NewFastCDC(FastCDCConfig{
+		MinSize:            64,
+		AvgSize:            256,
+		MaxSize:            1024,
+		NormalizationLevel: 2,
+	})
+	computer := NewComputer(chunker)
+	computer.SetMonitor(monitor)
+	ctx := context.Background()
+
+	// A new version that appends to the original mostly copies from the base
+	original := make([]byte, 8*1024)
+	_, err := rand.Read(original)
+	require.NoError(t, err)
+	updated := append(append([]byte{}, original...), []byte(strings.Repeat("appended ", 64))...)
+
+	delta, err := computer.Compute(ctx, bytes.NewReader(original), bytes.NewReader(updated))
+	require.NoError(t, err)
+	require.Greater(t, delta.SavingsRatio, 0.5)
+
+	assert.Equal(t, delta.SavingsRatio, testutil.ToFloat64(m.DeltaSavingsRatio))
+	assert.Equal(t, float64(delta.TotalSize-delta.DeltaSize), testutil.ToFloat64(m.DeltaBytesSaved))
+	assert.Equal(t, float64(len(updated)), testutil.ToFloat64(m.DeltaChunkedBytes))
+
+	// Indexing the same content twice is served entirely by existing chunks
+	indexer := NewIndexer(chunker, NewMemoryChunkStore())
+	indexer.SetMonitor(monitor)
+	_, err = indexer.IndexBlob(ctx, bytes.NewReader(original))
+	require.NoError(t, err)
+	second, err := indexer.IndexBlob(ctx, bytes.NewReader(original))
+	require.NoError(t, err)
+	assert.Equal(t, float64(second.ReusedChunks), testutil.ToFloat64(m.DeltaChunksTotal.WithLabelValues("reused")))
+	assert.InDelta(t, 0.5, monitor.Stats().DedupHitRate(), 0.001)
+
+	// A failed reconstruction marks the subsystem unhealthy until one succeeds
+	applier := NewApplier()
+	applier.SetMonitor(monitor)
+	_, err = applier.Apply(ctx, bytes.NewReader(original), delta, bytes.NewReader(nil))
+	require.Error(t, err)
+	assert.Equal(t, float64(0), testutil.ToFloat64(m.DeltaReconstructionHealthy))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.DeltaReconstructionFailures))
+	assert.NotEmpty(t, monitor.Stats().LastReconstructionError)
+
+	deltaData, err := computer.ExtractDeltaData(ctx, bytes.NewReader(updated), delta)
+	require.NoError(t, err)
+	_, err = applier.Apply(ctx, bytes.NewReader(original), delta, bytes.NewReader(deltaData))
+	require.NoError(t, err)
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.DeltaReconstructionHealthy))
+	assert.Empty(t, monitor.Stats().LastReconstructionError)
+}
@@ -0,0 +1,195 @@
+package delta
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// MemoryChunkStore is an in-memory implementation of ChunkStore.
+// Chunk data is not retained; only hashes, sizes and reference counts are tracked.
+// Aggregate statistics are maintained incrementally so Stats is O(1). Like the
+// PostgreSQL store, they only count chunks that are still referenced.
+type MemoryChunkStore struct {
+	mu     sync.RWMutex
+	chunks map[string]*chunkEntry
+	blobs  map[string][]Chunk
+	stats  ChunkStats
+}
+
+// chunkEntry is the bookkeeping record for a stored chunk.
+type chunkEntry struct {
+	size int64
+	refs int
+}
+
+// NewMemoryChunkStore creates a new in-memory chunk store.
+func NewMemoryChunkStore() *MemoryChunkStore {
+	return &MemoryChunkStore{
+		chunks: make(map[string]*chunkEntry),
+		blobs:  make(map[string][]Chunk),
+	}
+}
+
+// Store implements ChunkStore interface.
+// Storing a chunk that already exists increments its reference count.
+func (s *MemoryChunkStore) Store(ctx context.Context, chunk *Chunk) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.chunks[chunk.Hash]; ok {
+		s.addRef(entry)
+		return false, nil
+	}
+
+	s.chunks[chunk.Hash] = &chunkEntry{size: chunk.Size, refs: 1}
+	s.stats.UniqueChunks++
+	s.stats.TotalReferences++
+	s.stats.StoredBytes += chunk.Size
+	s.stats.LogicalBytes += chunk.Size
+	return true, nil
+}
+
+// Get implements ChunkStore interface.
+func (s *MemoryChunkStore) Get(ctx context.Context, hash string) (*Chunk, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entry, ok := s.chunks[hash]
+	if !ok {
+		return nil, fmt.Errorf("chunk not found: %s", hash)
+	}
+	return &Chunk{Hash: hash, Size: entry.size}, nil
+}
+
+// IncrementRef implements ChunkStore interface.
+func (s *MemoryChunkStore) IncrementRef(ctx context.Context, hash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.chunks[hash]
+	if !ok {
+		return fmt.Errorf("chunk not found: %s", hash)
+	}
+	s.addRef(entry)
+	return nil
+}
+
+// addRef adds a reference to entry. An orphaned chunk that is referenced
+// again counts towards the stored chunks once more.
+func (s *MemoryChunkStore) addRef(entry *chunkEntry) {
+	if entry.refs == 0 {
+		s.stats.UniqueChunks++
+		s.stats.StoredBytes += entry.size
+	}
+	entry.refs++
+	s.stats.TotalReferences++
+	s.stats.LogicalBytes += entry.size
+}
+
+// DecrementRef implements ChunkStore interface.
+func (s *MemoryChunkStore) DecrementRef(ctx context.Context, hash string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.chunks[hash]
+	if !ok {
+		return 0, fmt.Errorf("chunk not found: %s", hash)
+	}
+	if entry.refs > 0 {
+		entry.refs--
+		s.stats.TotalReferences--
+		s.stats.LogicalBytes -= entry.size
+		if entry.refs == 0 {
+			// Orphans are kept for ListOrphans but no longer count as stored
+			s.stats.UniqueChunks--
+			s.stats.StoredBytes -= entry.size
+		}
+	}
+	return entry.refs, nil
+}
+
+// ListOrphans implements ChunkStore interface.
+func (s *MemoryChunkStore) ListOrphans(ctx context.Context, limit int) ([]Chunk, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var orphans []Chunk
+	for hash, entry := range s.chunks {
+		if entry.refs == 0 {
+			orphans = append(orphans, Chunk{Hash: hash, Size: entry.size})
+		}
+	}
+	sort.Slice(orphans, func(i, j int) bool { return orphans[i].Hash < orphans[j].Hash })
+	if limit > 0 && len(orphans) > limit {
+		orphans = orphans[:limit]
+	}
+	return orphans, nil
+}
+
+// Stats implements ChunkStore interface.
+func (s *MemoryChunkStore) Stats(ctx context.Context) (*ChunkStats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stats := s.stats
+	return &stats, nil
+}
+
+// BeginRebuild implements ChunkStore interface.
+// The rebuild fills a separate store whose contents replace this one on Commit.
+func (s *MemoryChunkStore) BeginRebuild(ctx context.Context) (ChunkRebuild, error) {
+	return &memoryChunkRebuild{MemoryChunkStore: NewMemoryChunkStore(), target: s}, nil
+}
+
+// memoryChunkRebuild stages a rebuilt index for a MemoryChunkStore.
+type memoryChunkRebuild struct {
+	*MemoryChunkStore
+	target *MemoryChunkStore
+}
+
+// Commit implements ChunkRebuild interface.
+func (r *memoryChunkRebuild) Commit(ctx context.Context) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	r.target.mu.Lock()
+	defer r.target.mu.Unlock()
+
+	r.target.chunks = r.chunks
+	r.target.blobs = r.blobs
+	r.target.stats = r.stats
+	return nil
+}
+
+// Abort implements ChunkRebuild interface. The staged maps are left to the
+// garbage collector.
+func (r *memoryChunkRebuild) Abort(ctx context.Context) error {
+	return nil
+}
+
+// RecordBlobChunks implements BlobChunkRecorder interface.
+func (s *MemoryChunkStore) RecordBlobChunks(ctx context.Context, blobHash string, chunks []Chunk) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	recorded := make([]Chunk, len(chunks))
+	for i, chunk := range chunks {
+		recorded[i] = Chunk{Hash: chunk.Hash, Offset: chunk.Offset, Size: chunk.Size}
+	}
+	s.blobs[blobHash] = recorded
+	return nil
+}
+
+// BlobChunks returns the chunk list recorded for a blob, or nil if none was recorded.
+func (s *MemoryChunkStore) BlobChunks(blobHash string) []Chunk {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.blobs[blobHash]
+}
+
+// Ensure MemoryChunkStore implements ChunkStore and BlobChunkRecorder
+var _ ChunkStore = (*MemoryChunkStore)(nil)
+var _ BlobChunkRecorder = (*MemoryChunkStore)(nil)
+var _ ChunkRebuild = (*memoryChunkRebuild)(nil)
@@ -49,6 +49,22 @@ type AccessKey struct {
 
 	// LastUsedAt is the timestamp when the key was last used for authentication.
 	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+
+	// NamespaceBucket is the shared bucket in which this key is confined to
+	// NamespacePrefix. Empty means the key is not namespace-restricted.
+	NamespaceBucket string `json:"namespace_bucket,omitempty"`
+
+	// NamespacePrefix is the object key prefix this key may access within
+	// NamespaceBucket.
+	NamespacePrefix string `json:"namespace_prefix,omitempty"`
+
+	// RateLimitRPS overrides the server's requests per second for this key.
+	// Zero uses the server default.
+	RateLimitRPS float64 `json:"rate_limit_rps,omitempty"`
+
+	// RateLimitBurst overrides the server's request burst for this key.
+	// Zero uses the server default.
+	RateLimitBurst int `json:"rate_limit_burst,omitempty"`
 }
 
 // NewAccessKey creates a new AccessKey with default values.
@@ -76,6 +92,11 @@ func (ak *AccessKey) IsValid() bool {
 	return true
 }
 
+// IsNamespaced returns true if the key is confined to a key prefix in a bucket.
+func (ak *AccessKey) IsNamespaced() bool {
+	return ak.NamespaceBucket != ""
+}
+
 // IsExpired returns true if the access key has expired.
 func (ak *AccessKey) IsExpired() bool {
 	if ak.ExpiresAt == nil {
@@ -3,6 +3,7 @@ package domain
 
 import (
 	"regexp"
+	"strings"
 	"time"
 )
 
@@ -38,6 +39,54 @@ const (
 	ACLPublicReadWrite BucketACL = "public-read-write"
 )
 
+// CannedACLBucketOwnerFullControl is the canned object ACL granting the bucket
+// owner full control. It is the only ACL accepted when ACLs are disabled.
+const CannedACLBucketOwnerFullControl = "bucket-owner-full-control"
+
+// ObjectOwnership controls who owns the objects written to a bucket and
+// whether ACLs are honored.
+type ObjectOwnership string
+
+const (
+	// OwnershipBucketOwnerEnforced disables ACLs; the bucket owner owns
+	// every object and access is governed by the bucket owner alone.
+	OwnershipBucketOwnerEnforced ObjectOwnership = "BucketOwnerEnforced"
+
+	// OwnershipBucketOwnerPreferred honors ACLs; objects written with the
+	// bucket-owner-full-control ACL are owned by the bucket owner.
+	OwnershipBucketOwnerPreferred ObjectOwnership = "BucketOwnerPreferred"
+
+	// OwnershipObjectWriter honors ACLs; the writer owns the object.
+	OwnershipObjectWriter ObjectOwnership = "ObjectWriter"
+)
+
+// IsValidObjectOwnership checks if the given object ownership string is valid.
+func IsValidObjectOwnership(ownership string) bool {
+	switch ObjectOwnership(ownership) {
+	case OwnershipBucketOwnerEnforced, OwnershipBucketOwnerPreferred, OwnershipObjectWriter:
+		return true
+	default:
+		return false
+	}
+}
+
+// ACLsDisabled returns true if ACLs are ignored under this ownership setting.
+func (o ObjectOwnership) ACLsDisabled() bool {
+	return o == OwnershipBucketOwnerEnforced
+}
+
+// BucketState represents the lifecycle state of a bucket.
+type BucketState string
+
+const (
+	// BucketStateActive means the bucket accepts reads and writes (default).
+	BucketStateActive BucketState = "active"
+
+	// BucketStateDeleting means the bucket is being deleted.
+	// New writes are rejected while in-flight writes drain.
+	BucketStateDeleting BucketState = "deleting"
+)
+
 // ValidBucketACLs is the list of valid ACL values.
 var ValidBucketACLs = []BucketACL{ACLPrivate, ACLPublicRead, ACLPublicReadWrite}
 
@@ -51,6 +100,11 @@ func IsValidACL(acl string) bool {
 	}
 }
 
+// IsOwnerOnlyACL returns true if the canned ACL grants access to the bucket owner alone.
+func IsOwnerOnlyACL(acl string) bool {
+	return acl == string(ACLPrivate) || acl == CannedACLBucketOwnerFullControl
+}
+
 // AllowsAnonymousRead returns true if the ACL allows unauthenticated read access.
 func (a BucketACL) AllowsAnonymousRead() bool {
 	return a == ACLPublicRead || a == ACLPublicReadWrite
@@ -87,13 +141,41 @@ type Bucket struct {
 	Versioning VersioningStatus `json:"versioning"`
 
 	// ACL is the canned access control list for the bucket.
-	// Controls anonymous access permissions.
+	// Controls anonymous access permissions unless ACLs are disabled.
 	ACL BucketACL `json:"acl"`
 
+	// ObjectOwnership controls object ownership and whether ACLs are honored.
+	ObjectOwnership ObjectOwnership `json:"object_ownership"`
+
 	// ObjectLock indicates whether object locking (WORM) is enabled.
 	// Once enabled, cannot be disabled.
 	ObjectLock bool `json:"object_lock"`
 
+	// State is the lifecycle state of the bucket.
+	State BucketState `json:"state"`
+
+	// MaxVersionsPerKey caps the number of versions kept per key.
+	// Writes beyond the cap permanently remove the oldest versions.
+	// 0 means unlimited.
+	MaxVersionsPerKey int `json:"max_versions_per_key"`
+
+	// CaseInsensitiveKeys makes keys that differ only in case refer to the
+	// same object. Objects keep the key of their latest write for display.
+	// Keys are case-sensitive by default, as in S3.
+	CaseInsensitiveKeys bool `json:"case_insensitive_keys"`
+
+	// ContentTypePolicy restricts accepted content types and how objects
+	// are served. Nil accepts every type and serves objects as stored.
+	ContentTypePolicy *ContentTypePolicy `json:"content_type_policy,omitempty"`
+
+	// RequireEncryption rejects uploads that are not encrypted with a
+	// customer-provided key (SSE-C), the only server-side encryption offered.
+	RequireEncryption bool `json:"require_encryption"`
+
+	// DefaultRetention is applied to new object versions written without
+	// retention of their own. Only set on object lock buckets.
+	DefaultRetention *DefaultRetention `json:"default_retention,omitempty"`
+
 	// CreatedAt is the timestamp when the bucket was created.
 	CreatedAt time.Time `json:"created_at"`
 }
@@ -101,16 +183,33 @@ type Bucket struct {
 // NewBucket creates a new Bucket with default values.
 func NewBucket(ownerID int64, name string) *Bucket {
 	return &Bucket{
-		OwnerID:    ownerID,
-		Name:       name,
-		Region:     "us-east-1",
-		Versioning: VersioningDisabled,
-		ACL:        ACLPrivate,
-		ObjectLock: false,
-		CreatedAt:  time.Now().UTC(),
+		OwnerID:         ownerID,
+		Name:            name,
+		Region:          "us-east-1",
+		Versioning:      VersioningDisabled,
+		ACL:             ACLPrivate,
+		ObjectOwnership: OwnershipBucketOwnerEnforced,
+		ObjectLock:      false,
+		State:           BucketStateActive,
+		CreatedAt:       time.Now().UTC(),
 	}
 }
 
+// NormalizeKey returns the form of key used for lookups, uniqueness and list
+// ordering. It is key itself unless the bucket has case-insensitive keys.
+func (b *Bucket) NormalizeKey(key string) string {
+	if !b.CaseInsensitiveKeys {
+		return key
+	}
+	return strings.ToLower(key)
+}
+
+// AcceptsEncryption reports whether an upload that is or is not encrypted
+// with a customer-provided key may be written to the bucket.
+func (b *Bucket) AcceptsEncryption(requested bool) bool {
+	return requested || !b.RequireEncryption
+}
+
 // IsVersioningEnabled returns true if versioning is currently active.
 func (b *Bucket) IsVersioningEnabled() bool {
 	return b.Versioning == VersioningEnabled
@@ -121,6 +220,32 @@ func (b *Bucket) IsVersioningEverEnabled() bool {
 	return b.Versioning == VersioningEnabled || b.Versioning == VersioningSuspended
 }
 
+// ACLsDisabled returns true if the bucket ignores ACLs.
+func (b *Bucket) ACLsDisabled() bool {
+	return b.ObjectOwnership.ACLsDisabled()
+}
+
+// EffectiveACL returns the canned ACL that governs anonymous access.
+// Buckets with ACLs disabled are always private.
+func (b *Bucket) EffectiveACL() BucketACL {
+	if b.ACLsDisabled() || b.ACL == "" {
+		return ACLPrivate
+	}
+	return b.ACL
+}
+
+// AcceptsCannedACL returns true if a request carrying the canned ACL may be
+// applied to the bucket. With ACLs disabled, only ACLs granting the bucket
+// owner full control are accepted.
+func (b *Bucket) AcceptsCannedACL(acl string) bool {
+	return !b.ACLsDisabled() || acl == "" || IsOwnerOnlyACL(acl)
+}
+
+// IsDeleting returns true if the bucket is being deleted.
+func (b *Bucket) IsDeleting() bool {
+	return b.State == BucketStateDeleting
+}
+
 // ValidateName checks if the bucket name follows S3 naming conventions.
 func ValidateBucketName(name string) error {
 	if len(name) < 3 || len(name) > 63 {
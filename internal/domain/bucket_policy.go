@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+// BucketPolicy is the JSON access policy document attached to a bucket.
+type BucketPolicy struct {
+	BucketID int64
+
+	// Policy is the document as submitted.
+	Policy string
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NewBucketPolicy creates a policy for a bucket.
+func NewBucketPolicy(bucketID int64, policy string) *BucketPolicy {
+	now := time.Now().UTC()
+	return &BucketPolicy{
+		BucketID:  bucketID,
+		Policy:    policy,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"strings"
+)
+
+// ChecksumAlgorithm is an additional checksum algorithm for object content
+// (x-amz-checksum-algorithm).
+type ChecksumAlgorithm string
+
+const (
+	// ChecksumCRC32 is CRC-32 with the IEEE polynomial.
+	ChecksumCRC32 ChecksumAlgorithm = "CRC32"
+
+	// ChecksumCRC32C is CRC-32 with the Castagnoli polynomial.
+	ChecksumCRC32C ChecksumAlgorithm = "CRC32C"
+
+	// ChecksumSHA1 is SHA-1.
+	ChecksumSHA1 ChecksumAlgorithm = "SHA1"
+
+	// ChecksumSHA256 is SHA-256.
+	ChecksumSHA256 ChecksumAlgorithm = "SHA256"
+)
+
+// ChecksumAlgorithms are the supported checksum algorithms.
+var ChecksumAlgorithms = []ChecksumAlgorithm{ChecksumCRC32, ChecksumCRC32C, ChecksumSHA1, ChecksumSHA256}
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// ParseChecksumAlgorithm parses an algorithm name case-insensitively.
+func ParseChecksumAlgorithm(name string) (ChecksumAlgorithm, bool) {
+	algorithm := ChecksumAlgorithm(strings.ToUpper(name))
+	return algorithm, algorithm.IsValid()
+}
+
+// IsValid reports whether the algorithm is supported.
+func (a ChecksumAlgorithm) IsValid() bool {
+	switch a {
+	case ChecksumCRC32, ChecksumCRC32C, ChecksumSHA1, ChecksumSHA256:
+		return true
+	}
+	return false
+}
+
+// New returns a hash computing the checksum. The algorithm must be valid.
+func (a ChecksumAlgorithm) New() hash.Hash {
+	switch a {
+	case ChecksumCRC32:
+		return crc32.NewIEEE()
+	case ChecksumCRC32C:
+		return crc32.New(crc32cTable)
+	case ChecksumSHA1:
+		return sha1.New()
+	case ChecksumSHA256:
+		return sha256.New()
+	}
+	panic("unsupported checksum algorithm " + string(a))
+}
+
+// ValidateChecksum checks that value is a base64 checksum of the algorithm's size.
+func (a ChecksumAlgorithm) ValidateChecksum(value string) error {
+	sum, err := base64.StdEncoding.DecodeString(value)
+	if err != nil || len(sum) != a.New().Size() {
+		return fmt.Errorf("%w: not a base64 %s checksum", ErrInvalidChecksum, a)
+	}
+	return nil
+}
+
+// CompositeChecksum returns the checksum of a multipart object: the checksum
+// of the concatenated part checksums, followed by "-" and the part count.
+// Part checksums are base64 encoded, as stored.
+func CompositeChecksum(algorithm ChecksumAlgorithm, partChecksums []string) (string, error) {
+	h := algorithm.New()
+	for _, part := range partChecksums {
+		sum, err := base64.StdEncoding.DecodeString(part)
+		if err != nil {
+			return "", fmt.Errorf("%w: part checksum is not base64", ErrInvalidChecksum)
+		}
+		h.Write(sum)
+	}
+	return fmt.Sprintf("%s-%d", base64.StdEncoding.EncodeToString(h.Sum(nil)), len(partChecksums)), nil
+}
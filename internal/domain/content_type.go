@@ -0,0 +1,128 @@
+package domain
+
+import (
+	"mime"
+	"strings"
+)
+
+// ContentServeMode controls how a bucket's objects are returned on GET and HEAD.
+type ContentServeMode string
+
+const (
+	// ServeAsStored returns objects with their stored content type (default).
+	ServeAsStored ContentServeMode = ""
+
+	// ServeAttachment adds Content-Disposition: attachment to every object,
+	// so browsers download instead of rendering it.
+	ServeAttachment ContentServeMode = "attachment"
+
+	// ServeSafe returns active content (HTML, SVG, XML, JavaScript) as
+	// text/plain so browsers never execute it.
+	ServeSafe ContentServeMode = "safe"
+)
+
+// activeContentTypes are media types a browser may execute or render as a document.
+var activeContentTypes = map[string]bool{
+	"text/html":              true,
+	"application/xhtml+xml":  true,
+	"image/svg+xml":          true,
+	"text/xml":               true,
+	"application/xml":        true,
+	"text/javascript":        true,
+	"application/javascript": true,
+	"application/ecmascript": true,
+	"text/ecmascript":        true,
+}
+
+// ContentTypePolicy restricts the content types a bucket accepts and sets how
+// its objects are served. Patterns are media types such as "image/png" or
+// wildcards such as "image/*"; parameters like charset are ignored.
+type ContentTypePolicy struct {
+	// Allow lists accepted content types. Empty accepts every type not denied.
+	Allow []string `json:"allow,omitempty"`
+
+	// Deny lists rejected content types. Deny takes precedence over Allow.
+	Deny []string `json:"deny,omitempty"`
+
+	// Serve controls how objects are returned on GET and HEAD.
+	Serve ContentServeMode `json:"serve,omitempty"`
+}
+
+// Validate checks the patterns and serve mode of the policy.
+func (p *ContentTypePolicy) Validate() error {
+	for _, pattern := range append(append([]string{}, p.Allow...), p.Deny...) {
+		major, minor, ok := strings.Cut(pattern, "/")
+		if !ok || major == "" || minor == "" || strings.ContainsAny(pattern, " ;") || (major == "*" && minor != "*") {
+			return ErrInvalidContentTypePolicy
+		}
+	}
+	switch p.Serve {
+	case ServeAsStored, ServeAttachment, ServeSafe:
+		return nil
+	default:
+		return ErrInvalidContentTypePolicy
+	}
+}
+
+// Allows reports whether an object with contentType may be stored.
+// A nil policy allows every content type.
+func (p *ContentTypePolicy) Allows(contentType string) bool {
+	if p == nil {
+		return true
+	}
+	mediaType := baseMediaType(contentType)
+	for _, pattern := range p.Deny {
+		if matchMediaType(pattern, mediaType) {
+			return false
+		}
+	}
+	if len(p.Allow) == 0 {
+		return true
+	}
+	for _, pattern := range p.Allow {
+		if matchMediaType(pattern, mediaType) {
+			return true
+		}
+	}
+	return false
+}
+
+// ServeHeaders returns the Content-Type and Content-Disposition to serve an
+// object stored with contentType. An empty disposition means none is sent.
+// A nil policy serves objects as stored.
+func (p *ContentTypePolicy) ServeHeaders(contentType string) (string, string) {
+	if p == nil {
+		return contentType, ""
+	}
+	switch p.Serve {
+	case ServeAttachment:
+		return contentType, "attachment"
+	case ServeSafe:
+		if activeContentTypes[baseMediaType(contentType)] {
+			return "text/plain", ""
+		}
+	}
+	return contentType, ""
+}
+
+// baseMediaType returns the lowercased media type without parameters.
+func baseMediaType(contentType string) string {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mediaType
+	}
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	return strings.ToLower(strings.TrimSpace(mediaType))
+}
+
+// matchMediaType matches a media type against a pattern such as "image/png",
+// "image/*" or "*/*".
+func matchMediaType(pattern, mediaType string) bool {
+	pattern = strings.ToLower(pattern)
+	if pattern == "*/*" || pattern == mediaType {
+		return true
+	}
+	if major, ok := strings.CutSuffix(pattern, "/*"); ok {
+		return strings.HasPrefix(mediaType, major+"/")
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MaxCORSRules is the maximum number of rules in a CORS configuration.
+const MaxCORSRules = 100
+
+// corsMethods are the methods a CORS rule may allow.
+var corsMethods = map[string]bool{
+	"GET":    true,
+	"PUT":    true,
+	"POST":   true,
+	"DELETE": true,
+	"HEAD":   true,
+}
+
+// CORSRule is a cross-origin access rule of a bucket.
+type CORSRule struct {
+	ID string `json:"id,omitempty"`
+
+	// AllowedOrigins are origins allowed to make requests, each containing
+	// at most one "*" wildcard.
+	AllowedOrigins []string `json:"allowed_origins"`
+
+	// AllowedMethods are the HTTP methods allowed: GET, PUT, POST, DELETE or HEAD.
+	AllowedMethods []string `json:"allowed_methods"`
+
+	// AllowedHeaders are request headers a preflight may ask for, each
+	// containing at most one "*" wildcard.
+	AllowedHeaders []string `json:"allowed_headers,omitempty"`
+
+	// ExposeHeaders are response headers browsers may expose to scripts.
+	ExposeHeaders []string `json:"expose_headers,omitempty"`
+
+	// MaxAgeSeconds is how long browsers may cache a preflight response
+	// (0 leaves it to the browser).
+	MaxAgeSeconds int `json:"max_age_seconds,omitempty"`
+}
+
+// BucketCORS is the CORS configuration of a bucket.
+type BucketCORS struct {
+	BucketID  int64
+	Rules     []CORSRule
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NewBucketCORS creates a CORS configuration for a bucket.
+func NewBucketCORS(bucketID int64, rules []CORSRule) *BucketCORS {
+	now := time.Now().UTC()
+	return &BucketCORS{
+		BucketID:  bucketID,
+		Rules:     rules,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// ValidateCORSRules checks a CORS configuration. Errors wrap
+// ErrInvalidCORSConfiguration with the reason.
+func ValidateCORSRules(rules []CORSRule) error {
+	if len(rules) == 0 {
+		return fmt.Errorf("%w: at least one CORSRule is required", ErrInvalidCORSConfiguration)
+	}
+	if len(rules) > MaxCORSRules {
+		return fmt.Errorf("%w: at most %d rules are allowed", ErrInvalidCORSConfiguration, MaxCORSRules)
+	}
+	for _, rule := range rules {
+		if len(rule.AllowedOrigins) == 0 || len(rule.AllowedMethods) == 0 {
+			return fmt.Errorf("%w: every rule needs an AllowedOrigin and an AllowedMethod", ErrInvalidCORSConfiguration)
+		}
+		for _, method := range rule.AllowedMethods {
+			if !corsMethods[method] {
+				return fmt.Errorf("%w: unsupported method %q", ErrInvalidCORSConfiguration, method)
+			}
+		}
+		for _, origin := range rule.AllowedOrigins {
+			if strings.Count(origin, "*") > 1 {
+				return fmt.Errorf("%w: AllowedOrigin %q can not have more than one wildcard", ErrInvalidCORSConfiguration, origin)
+			}
+		}
+		for _, header := range rule.AllowedHeaders {
+			if strings.Count(header, "*") > 1 {
+				return fmt.Errorf("%w: AllowedHeader %q can not have more than one wildcard", ErrInvalidCORSConfiguration, header)
+			}
+		}
+		if rule.MaxAgeSeconds < 0 {
+			return fmt.Errorf("%w: MaxAgeSeconds must not be negative", ErrInvalidCORSConfiguration)
+		}
+	}
+	return nil
+}
+
+// MatchCORSRule returns the first rule allowing a request from origin with
+// method and, for preflights, the requested headers. It returns nil if no
+// rule matches.
+func MatchCORSRule(rules []CORSRule, origin, method string, headers []string) *CORSRule {
+	for i := range rules {
+		if rules[i].allows(origin, method, headers) {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+// allows reports whether the rule covers origin, method and headers.
+// Origins are case-sensitive; header names are not.
+func (r *CORSRule) allows(origin, method string, headers []string) bool {
+	if !matchesAny(r.AllowedOrigins, origin, false) || !matchesAny(r.AllowedMethods, method, false) {
+		return false
+	}
+	for _, header := range headers {
+		if !matchesAny(r.AllowedHeaders, header, true) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesAny reports whether value matches one of patterns, where a pattern
+// may contain a single "*" matching any run of characters.
+func matchesAny(patterns []string, value string, foldCase bool) bool {
+	if foldCase {
+		value = strings.ToLower(value)
+	}
+	for _, pattern := range patterns {
+		if foldCase {
+			pattern = strings.ToLower(pattern)
+		}
+		prefix, suffix, wildcard := strings.Cut(pattern, "*")
+		if !wildcard {
+			if pattern == value {
+				return true
+			}
+			continue
+		}
+		if len(value) >= len(prefix)+len(suffix) && strings.HasPrefix(value, prefix) && strings.HasSuffix(value, suffix) {
+			return true
+		}
+	}
+	return false
+}
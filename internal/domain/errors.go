@@ -55,9 +55,40 @@ var (
 	// ErrBucketAlreadyExists indicates a bucket with the same name exists.
 	ErrBucketAlreadyExists = errors.New("bucket already exists")
 
+	// ErrBucketAlreadyOwnedByYou indicates the caller already owns a bucket with the same name.
+	ErrBucketAlreadyOwnedByYou = errors.New("bucket already owned by you")
+
 	// ErrBucketNotEmpty indicates the bucket contains objects and cannot be deleted.
 	ErrBucketNotEmpty = errors.New("bucket is not empty")
 
+	// ErrBucketDeleting indicates the bucket is being deleted and rejects writes.
+	ErrBucketDeleting = errors.New("bucket is being deleted")
+
+	// ErrInvalidContentTypePolicy indicates a malformed content-type pattern or serve mode.
+	ErrInvalidContentTypePolicy = errors.New("invalid content type policy")
+
+	// ErrNoSuchBucketPolicy indicates the bucket has no policy.
+	ErrNoSuchBucketPolicy = errors.New("the bucket policy does not exist")
+
+	// ErrNoSuchCORSConfiguration indicates the bucket has no CORS configuration.
+	ErrNoSuchCORSConfiguration = errors.New("the CORS configuration does not exist")
+
+	// ErrInvalidCORSConfiguration indicates a CORS configuration is invalid.
+	ErrInvalidCORSConfiguration = errors.New("invalid CORS configuration")
+
+	// ErrInvalidNotificationConfiguration indicates a bucket notification configuration is invalid.
+	ErrInvalidNotificationConfiguration = errors.New("invalid notification configuration")
+
+	// ErrObjectLockNotEnabled indicates an object lock request on a bucket without object lock.
+	ErrObjectLockNotEnabled = errors.New("the bucket does not have object lock enabled")
+
+	// ErrObjectLockVersioning indicates object lock and the bucket's versioning state conflict:
+	// object lock needs versioning enabled and keeps it enabled.
+	ErrObjectLockVersioning = errors.New("object lock requires versioning to be enabled")
+
+	// ErrInvalidObjectLockConfiguration indicates an unsupported bucket object lock configuration.
+	ErrInvalidObjectLockConfiguration = errors.New("invalid object lock configuration")
+
 	// ErrBucketNameLength indicates the bucket name length is invalid (3-63 chars).
 	ErrBucketNameLength = errors.New("bucket name must be between 3 and 63 characters")
 
@@ -74,21 +105,92 @@ var (
 	// ErrObjectNotFound indicates the requested object does not exist.
 	ErrObjectNotFound = errors.New("object not found")
 
+	// ErrObjectDataMissing indicates an object record exists but its blob was lost.
+	ErrObjectDataMissing = errors.New("object data is missing from storage")
+
+	// ErrWriteOffsetMismatch indicates an append's write offset is not the object's size.
+	ErrWriteOffsetMismatch = errors.New("write offset does not match the object size")
+
+	// ErrAppendNotEnabled indicates appending to objects is disabled.
+	ErrAppendNotEnabled = errors.New("appending to objects is not enabled")
+
+	// ErrAppendNotSupported indicates the object can not be appended to.
+	ErrAppendNotSupported = errors.New("object does not support appends")
+
 	// ErrObjectKeyEmpty indicates the object key is empty.
 	ErrObjectKeyEmpty = errors.New("object key cannot be empty")
 
 	// ErrObjectKeyTooLong indicates the object key exceeds maximum length.
 	ErrObjectKeyTooLong = errors.New("object key exceeds maximum length of 1024 characters")
 
+	// ErrObjectKeyTooDeep indicates the object key has more "/" delimiters than allowed.
+	ErrObjectKeyTooDeep = errors.New("object key exceeds the maximum depth")
+
+	// ErrContentTypeNotAllowed indicates the bucket's content-type policy rejects the object.
+	ErrContentTypeNotAllowed = errors.New("content type is not allowed in this bucket")
+
+	// ErrEncryptionRequired indicates the bucket rejects uploads that do not request encryption.
+	ErrEncryptionRequired = errors.New("the bucket requires server-side encryption")
+
+	// ErrObjectLocked indicates a retention period or legal hold protects the object version.
+	ErrObjectLocked = errors.New("the object version is protected by object lock")
+
+	// ErrInvalidObjectLock indicates an invalid retention period or legal hold status.
+	ErrInvalidObjectLock = errors.New("invalid object lock request")
+
+	// ErrNoSuchObjectRetention indicates the object version has no retention period.
+	ErrNoSuchObjectRetention = errors.New("the object version has no retention period")
+
 	// ErrObjectDeleted indicates the object has been deleted (is a delete marker).
 	ErrObjectDeleted = errors.New("object has been deleted")
 
+	// ErrObjectRestoreConflict indicates a deleted object cannot be restored
+	// because its key has been written again since the deletion.
+	ErrObjectRestoreConflict = errors.New("object key has been overwritten since the deletion")
+
+	// ErrVersionIsDeleteMarker indicates the requested version ID refers to a delete marker.
+	// S3 answers GET/HEAD on such a version with 405 Method Not Allowed.
+	ErrVersionIsDeleteMarker = errors.New("the specified version is a delete marker")
+
 	// ErrVersionNotFound indicates the requested version does not exist.
 	ErrVersionNotFound = errors.New("version not found")
 
 	// ErrInvalidVersionID indicates the version ID format is invalid.
 	ErrInvalidVersionID = errors.New("invalid version ID format")
 
+	// ErrInvalidObjectExpiry indicates an object expiration time that is not in the future.
+	ErrInvalidObjectExpiry = errors.New("object expiration time must be in the future")
+
+	// ErrInvalidTag indicates an object tag key or value violates the tag limits.
+	ErrInvalidTag = errors.New("invalid object tag")
+
+	// ErrTooManyTags indicates an object tag set has more than MaxObjectTags tags.
+	ErrTooManyTags = errors.New("object tags cannot be greater than 10")
+
+	// ErrInvalidRange indicates the requested byte range lies outside the object.
+	ErrInvalidRange = errors.New("the requested range is not satisfiable")
+
+	// ErrPreconditionFailed indicates a conditional request header did not hold.
+	ErrPreconditionFailed = errors.New("at least one of the preconditions did not hold")
+
+	// ErrNotModified indicates a conditional read found the object unchanged.
+	ErrNotModified = errors.New("not modified")
+
+	// ErrInvalidContinuationToken indicates a list continuation token was not issued by a previous list.
+	ErrInvalidContinuationToken = errors.New("the continuation token provided is incorrect")
+
+	// ErrIncompleteBody indicates an upload body did not match its declared Content-Length.
+	ErrIncompleteBody = errors.New("request body does not match the declared content length")
+
+	// ErrBadDigest indicates an upload body did not match its Content-MD5 header.
+	ErrBadDigest = errors.New("the Content-MD5 you specified did not match what was received")
+
+	// ErrInvalidChecksum indicates a checksum header or algorithm is malformed or unsupported.
+	ErrInvalidChecksum = errors.New("invalid checksum")
+
+	// ErrChecksumMismatch indicates an upload body did not match its x-amz-checksum-* header.
+	ErrChecksumMismatch = errors.New("the checksum you specified did not match the calculated checksum")
+
 	// ===========================================
 	// Blob/Storage Errors
 	// ===========================================
@@ -130,6 +232,10 @@ var (
 	// ErrPartNotFound indicates the specified part does not exist.
 	ErrPartNotFound = errors.New("part not found")
 
+	// ErrPartDataMissing indicates a part record exists but its blob was lost
+	// or no longer holds the recorded part size.
+	ErrPartDataMissing = errors.New("part data is missing from storage")
+
 	// ErrPartETagMismatch indicates the part ETag does not match.
 	ErrPartETagMismatch = errors.New("part ETag mismatch")
 
@@ -16,8 +16,37 @@ const (
 	LifecycleDisabled LifecycleStatus = "Disabled"
 )
 
+// MaxLifecycleRules is the maximum number of lifecycle rules per bucket.
+const MaxLifecycleRules = 1000
+
+// TransitionClass is the storage tier a lifecycle transition moves objects to.
+type TransitionClass string
+
+const (
+	// TransitionWarm moves objects to the warm tier.
+	TransitionWarm TransitionClass = "WARM"
+
+	// TransitionCold moves objects to the cold tier.
+	TransitionCold TransitionClass = "COLD"
+)
+
+// IsValid returns true if the class is a known transition target.
+func (c TransitionClass) IsValid() bool {
+	return c == TransitionWarm || c == TransitionCold
+}
+
+// SatisfiedBy returns the classes of already transitioned objects that need
+// no transition to c: the class itself and any colder one.
+func (c TransitionClass) SatisfiedBy() []TransitionClass {
+	if c == TransitionWarm {
+		return []TransitionClass{TransitionWarm, TransitionCold}
+	}
+	return []TransitionClass{c}
+}
+
 // LifecycleRule represents an object lifecycle management rule.
-// Currently supports expiration rules only (objects are deleted after N days).
+// Rules expire objects (delete them) and/or transition them to a colder
+// storage tier, after N days or from a fixed date.
 type LifecycleRule struct {
 	// ID is the unique database identifier.
 	ID int64 `json:"id"`
@@ -37,6 +66,22 @@ type LifecycleRule struct {
 	// when the object should be deleted. Nil means never expire.
 	ExpirationDays *int `json:"expiration_days,omitempty"`
 
+	// ExpirationDate is the date (midnight UTC) from which matching objects
+	// are deleted. It is exclusive with ExpirationDays.
+	ExpirationDate *time.Time `json:"expiration_date,omitempty"`
+
+	// TransitionDays is the number of days after object creation when the
+	// object is moved to TransitionStorageClass. Nil means no transition.
+	TransitionDays *int `json:"transition_days,omitempty"`
+
+	// TransitionDate is the date (midnight UTC) from which matching objects
+	// are moved to TransitionStorageClass. It is exclusive with TransitionDays.
+	TransitionDate *time.Time `json:"transition_date,omitempty"`
+
+	// TransitionStorageClass is the tier objects are moved to. It is set if
+	// and only if TransitionDays or TransitionDate is.
+	TransitionStorageClass TransitionClass `json:"transition_storage_class,omitempty"`
+
 	// Status indicates whether the rule is enabled.
 	Status LifecycleStatus `json:"status"`
 
@@ -75,9 +120,44 @@ func (r *LifecycleRule) Validate() error {
 	if r.ExpirationDays != nil && *r.ExpirationDays < 1 {
 		return ErrInvalidLifecycleRule
 	}
+	// An expiration date is exclusive with days and must be at midnight UTC
+	if r.ExpirationDate != nil {
+		if r.ExpirationDays != nil || !isMidnightUTC(*r.ExpirationDate) {
+			return ErrInvalidLifecycleRule
+		}
+	}
+	// Transitions follow the same rules and name a target tier
+	if r.TransitionDays != nil && *r.TransitionDays < 1 {
+		return ErrInvalidLifecycleRule
+	}
+	if r.TransitionDate != nil {
+		if r.TransitionDays != nil || !isMidnightUTC(*r.TransitionDate) {
+			return ErrInvalidLifecycleRule
+		}
+	}
+	hasTransition := r.TransitionDays != nil || r.TransitionDate != nil
+	if hasTransition != (r.TransitionStorageClass != "") {
+		return ErrInvalidLifecycleRule
+	}
+	if hasTransition && !r.TransitionStorageClass.IsValid() {
+		return ErrInvalidLifecycleRule
+	}
+	// Objects must be transitioned before they expire
+	if r.TransitionDays != nil && r.ExpirationDays != nil && *r.TransitionDays >= *r.ExpirationDays {
+		return ErrInvalidLifecycleRule
+	}
+	if r.TransitionDate != nil && r.ExpirationDate != nil && !r.TransitionDate.Before(*r.ExpirationDate) {
+		return ErrInvalidLifecycleRule
+	}
 	return nil
 }
 
+// isMidnightUTC returns true if t is at the start of a day in UTC.
+func isMidnightUTC(t time.Time) bool {
+	t = t.UTC()
+	return t.Equal(t.Truncate(24 * time.Hour))
+}
+
 // IsEnabled returns true if the rule is active.
 func (r *LifecycleRule) IsEnabled() bool {
 	return r.Status == LifecycleEnabled
@@ -85,7 +165,45 @@ func (r *LifecycleRule) IsEnabled() bool {
 
 // HasExpiration returns true if the rule has an expiration policy.
 func (r *LifecycleRule) HasExpiration() bool {
-	return r.ExpirationDays != nil && *r.ExpirationDays > 0
+	return (r.ExpirationDays != nil && *r.ExpirationDays > 0) || r.ExpirationDate != nil
+}
+
+// HasTransition returns true if the rule has a transition policy.
+func (r *LifecycleRule) HasTransition() bool {
+	return r.TransitionStorageClass != "" &&
+		((r.TransitionDays != nil && *r.TransitionDays > 0) || r.TransitionDate != nil)
+}
+
+// ExpirationCutoff returns the creation time before which matching objects
+// are expired at now. It returns false when nothing expires yet.
+func (r *LifecycleRule) ExpirationCutoff(now time.Time) (time.Time, bool) {
+	return lifecycleCutoff(r.ExpirationDays, r.ExpirationDate, now)
+}
+
+// TransitionCutoff returns the creation time before which matching objects
+// are transitioned at now. It returns false when nothing transitions yet.
+func (r *LifecycleRule) TransitionCutoff(now time.Time) (time.Time, bool) {
+	if r.TransitionStorageClass == "" {
+		return time.Time{}, false
+	}
+	return lifecycleCutoff(r.TransitionDays, r.TransitionDate, now)
+}
+
+// lifecycleCutoff returns the creation time before which an action after
+// days, or from date, applies at now.
+func lifecycleCutoff(days *int, date *time.Time, now time.Time) (time.Time, bool) {
+	switch {
+	case date != nil:
+		// Once the date has passed, the action applies to every matching object
+		if now.Before(*date) {
+			return time.Time{}, false
+		}
+		return now, true
+	case days != nil && *days > 0:
+		return now.AddDate(0, 0, -*days), true
+	default:
+		return time.Time{}, false
+	}
 }
 
 // MatchesKey returns true if the given object key matches this rule's prefix filter.
@@ -98,12 +216,8 @@ func (r *LifecycleRule) MatchesKey(key string) bool {
 
 // ShouldExpire checks if an object created at the given time should be expired.
 func (r *LifecycleRule) ShouldExpire(createdAt time.Time) bool {
-	if !r.HasExpiration() {
-		return false
-	}
-
-	expirationTime := createdAt.AddDate(0, 0, *r.ExpirationDays)
-	return time.Now().UTC().After(expirationTime)
+	cutoff, ok := r.ExpirationCutoff(time.Now().UTC())
+	return ok && createdAt.Before(cutoff)
 }
 
 // LifecycleConfiguration represents the complete lifecycle configuration for a bucket.
@@ -21,6 +21,14 @@ const (
 	MultipartStatusAborted MultipartStatus = "Aborted"
 )
 
+const (
+	// MaxPartNumber is the highest part number of a multipart upload.
+	MaxPartNumber = 10000
+
+	// MaxPartSize is the largest part a multipart upload accepts (5GB).
+	MaxPartSize int64 = 5 * 1024 * 1024 * 1024
+)
+
 // MultipartUpload represents an in-progress multipart upload.
 // Multipart uploads allow uploading large objects in parts.
 type MultipartUpload struct {
@@ -54,6 +62,17 @@ type MultipartUpload struct {
 
 	// CompletedAt is when the upload was completed (if applicable).
 	CompletedAt *time.Time `json:"completed_at,omitempty"`
+
+	// ChecksumAlgorithm is the checksum algorithm of the parts, used for the
+	// composite checksum of the final object. Empty when none was requested.
+	ChecksumAlgorithm ChecksumAlgorithm `json:"checksum_algorithm,omitempty"`
+
+	// CacheControl, ContentDisposition, ContentEncoding and Expires are the
+	// standard HTTP headers of the final object.
+	CacheControl       string `json:"cache_control,omitempty"`
+	ContentDisposition string `json:"content_disposition,omitempty"`
+	ContentEncoding    string `json:"content_encoding,omitempty"`
+	Expires            string `json:"expires,omitempty"`
 }
 
 // NewMultipartUpload creates a new MultipartUpload.
@@ -105,6 +124,11 @@ type UploadPart struct {
 
 	// CreatedAt is when this part was uploaded.
 	CreatedAt time.Time `json:"created_at"`
+
+	// ChecksumAlgorithm and Checksum are the part's base64 checksum, empty
+	// when the part was uploaded without one.
+	ChecksumAlgorithm ChecksumAlgorithm `json:"checksum_algorithm,omitempty"`
+	Checksum          string            `json:"checksum,omitempty"`
 }
 
 // NewUploadPart creates a new UploadPart.
@@ -121,7 +145,7 @@ func NewUploadPart(uploadID uuid.UUID, partNumber int, contentHash, etag string,
 
 // ValidatePartNumber checks if the part number is valid (1-10000).
 func ValidatePartNumber(partNumber int) error {
-	if partNumber < 1 || partNumber > 10000 {
+	if partNumber < 1 || partNumber > MaxPartNumber {
 		return ErrInvalidPartNumber
 	}
 	return nil
@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// MaxWebhookTargets is the maximum number of webhooks in a notification configuration.
+const MaxWebhookTargets = 100
+
+// notificationEvents are the event names a webhook may subscribe to.
+var notificationEvents = map[string]bool{
+	"s3:ObjectCreated:*":                       true,
+	"s3:ObjectCreated:Put":                     true,
+	"s3:ObjectCreated:Copy":                    true,
+	"s3:ObjectCreated:CompleteMultipartUpload": true,
+	"s3:ObjectRemoved:*":                       true,
+	"s3:ObjectRemoved:Delete":                  true,
+	"s3:ObjectRemoved:DeleteMarkerCreated":     true,
+}
+
+// WebhookTarget is a URL that receives the object events of a bucket.
+type WebhookTarget struct {
+	ID string `json:"id,omitempty"`
+
+	// URL is the http or https endpoint events are POSTed to.
+	URL string `json:"url"`
+
+	// Events are the S3 event names delivered, e.g. "s3:ObjectCreated:*"
+	// or "s3:ObjectRemoved:Delete".
+	Events []string `json:"events"`
+
+	// Prefix and Suffix restrict events to matching object keys.
+	Prefix string `json:"prefix,omitempty"`
+	Suffix string `json:"suffix,omitempty"`
+}
+
+// BucketNotification is the event notification configuration of a bucket.
+type BucketNotification struct {
+	BucketID  int64
+	Webhooks  []WebhookTarget
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// NewBucketNotification creates a notification configuration for a bucket.
+func NewBucketNotification(bucketID int64, webhooks []WebhookTarget) *BucketNotification {
+	now := time.Now().UTC()
+	return &BucketNotification{
+		BucketID:  bucketID,
+		Webhooks:  webhooks,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// ValidateWebhookTargets checks the webhooks of a notification configuration.
+// Errors wrap ErrInvalidNotificationConfiguration with the reason.
+func ValidateWebhookTargets(webhooks []WebhookTarget) error {
+	if len(webhooks) > MaxWebhookTargets {
+		return fmt.Errorf("%w: at most %d webhooks are allowed", ErrInvalidNotificationConfiguration, MaxWebhookTargets)
+	}
+	ids := make(map[string]bool, len(webhooks))
+	for _, webhook := range webhooks {
+		if webhook.ID != "" {
+			if ids[webhook.ID] {
+				return fmt.Errorf("%w: duplicate Id %q", ErrInvalidNotificationConfiguration, webhook.ID)
+			}
+			ids[webhook.ID] = true
+		}
+		u, err := url.Parse(webhook.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%w: webhook URL %q must be an absolute http or https URL", ErrInvalidNotificationConfiguration, webhook.URL)
+		}
+		if len(webhook.Events) == 0 {
+			return fmt.Errorf("%w: every webhook needs an Event", ErrInvalidNotificationConfiguration)
+		}
+		for _, event := range webhook.Events {
+			if !notificationEvents[event] {
+				return fmt.Errorf("%w: unsupported event %q", ErrInvalidNotificationConfiguration, event)
+			}
+		}
+	}
+	return nil
+}
+
+// Matches reports whether the webhook receives an event named eventName,
+// e.g. "s3:ObjectCreated:Put", for an object key.
+func (t *WebhookTarget) Matches(eventName, key string) bool {
+	if !strings.HasPrefix(key, t.Prefix) || !strings.HasSuffix(key, t.Suffix) {
+		return false
+	}
+	for _, event := range t.Events {
+		if event == eventName {
+			return true
+		}
+		if category, ok := strings.CutSuffix(event, "*"); ok && strings.HasPrefix(eventName, category) {
+			return true
+		}
+	}
+	return false
+}
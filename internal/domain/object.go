@@ -37,6 +37,10 @@ type Object struct {
 	// Maximum length: 1024 characters.
 	Key string `json:"key"`
 
+	// NormalizedKey is the key used for lookups and uniqueness, see
+	// Bucket.NormalizeKey. It is only needed on write; empty means Key.
+	NormalizedKey string `json:"-"`
+
 	// VersionID is the unique identifier for this version.
 	// For non-versioned buckets, this is still set but not exposed.
 	VersionID uuid.UUID `json:"version_id"`
@@ -77,6 +81,47 @@ type Object struct {
 	// DeletedAt is the timestamp when this version was deleted.
 	// Only set for hard deletes (specific version deletion).
 	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+
+	// ExpiresAt is the optional time after which the lifecycle worker
+	// deletes the object, independent of bucket lifecycle rules.
+	// Only written on create; reads do not load it.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+
+	// SSECustomerAlgorithm is set when the content is encrypted with a
+	// customer-provided key (SSE-C). Only "AES256" is supported.
+	SSECustomerAlgorithm string `json:"sse_customer_algorithm,omitempty"`
+
+	// SSECustomerKeyMD5 is the base64 MD5 of the customer key, used to check
+	// the key supplied on reads. The key itself is never stored.
+	SSECustomerKeyMD5 string `json:"sse_customer_key_md5,omitempty"`
+
+	// ChecksumAlgorithm is the algorithm of Checksum, empty when the object
+	// was stored without an additional checksum.
+	ChecksumAlgorithm ChecksumAlgorithm `json:"checksum_algorithm,omitempty"`
+
+	// Checksum is the base64 checksum of the content. For multipart objects
+	// it is composite: the checksum of the part checksums, suffixed "-N".
+	Checksum string `json:"checksum,omitempty"`
+
+	// CacheControl, ContentDisposition, ContentEncoding and Expires are the
+	// standard HTTP headers given on upload and returned on reads. Expires is
+	// kept as sent; it does not schedule deletion like ExpiresAt.
+	CacheControl       string `json:"cache_control,omitempty"`
+	ContentDisposition string `json:"content_disposition,omitempty"`
+	ContentEncoding    string `json:"content_encoding,omitempty"`
+	Expires            string `json:"expires,omitempty"`
+
+	// Lock and Tags of a new version are stored in the same transaction as
+	// the version itself, so it is never visible without them. They are not
+	// filled in on reads; see ObjectRepository.GetLock and GetTags.
+	Lock *ObjectLock       `json:"-"`
+	Tags map[string]string `json:"-"`
+}
+
+// IsSSECustomerEncrypted reports whether reading the content requires a
+// customer-provided key.
+func (o *Object) IsSSECustomerEncrypted() bool {
+	return o.SSECustomerAlgorithm != ""
 }
 
 // NewObject creates a new Object with default values.
@@ -122,13 +167,37 @@ func (o *Object) IsDeleted() bool {
 	return o.IsDeleteMarker || o.DeletedAt != nil
 }
 
+// NullVersionID is the S3 version ID of an object written while versioning was
+// disabled or suspended. The null version is stored as uuid.Nil.
+const NullVersionID = "null"
+
+// ParseVersionID parses an S3 version ID as sent by clients.
+// NullVersionID resolves to uuid.Nil; anything else must be a UUID.
+func ParseVersionID(versionID string) (uuid.UUID, error) {
+	if versionID == NullVersionID {
+		return uuid.Nil, nil
+	}
+
+	id, err := uuid.Parse(versionID)
+	if err != nil {
+		return uuid.Nil, ErrInvalidVersionID
+	}
+	return id, nil
+}
+
+// FormatVersionID returns the S3 representation of a stored version ID.
+// Returns NullVersionID for the null version.
+func FormatVersionID(id uuid.UUID) string {
+	if id == uuid.Nil {
+		return NullVersionID
+	}
+	return id.String()
+}
+
 // GetVersionIDString returns the version ID as a string.
 // Returns "null" for null version (suspended versioning).
 func (o *Object) GetVersionIDString() string {
-	if o.VersionID == uuid.Nil {
-		return "null"
-	}
-	return o.VersionID.String()
+	return FormatVersionID(o.VersionID)
 }
 
 // ObjectInfo is a summary of object metadata returned in list operations.
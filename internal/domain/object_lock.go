@@ -0,0 +1,129 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// ObjectLockMode is the retention mode of a locked object version.
+type ObjectLockMode string
+
+const (
+	// ObjectLockModeGovernance retention blocks deleting the version or
+	// shortening its retention unless the caller bypasses governance
+	// retention with the s3:BypassGovernanceRetention permission.
+	ObjectLockModeGovernance ObjectLockMode = "GOVERNANCE"
+
+	// ObjectLockModeCompliance retention blocks deleting or overwriting the
+	// version until the retain-until date, and cannot be shortened.
+	ObjectLockModeCompliance ObjectLockMode = "COMPLIANCE"
+)
+
+// IsValidObjectLockMode reports whether mode is a supported retention mode.
+func IsValidObjectLockMode(mode string) bool {
+	return mode == string(ObjectLockModeGovernance) || mode == string(ObjectLockModeCompliance)
+}
+
+// ObjectLock is the retention and legal hold of an object version.
+// The zero value is an unlocked version.
+type ObjectLock struct {
+	// Mode is the retention mode, empty when the version has no retention.
+	Mode ObjectLockMode `json:"mode,omitempty"`
+
+	// RetainUntil is the end of the retention period. Set with Mode.
+	RetainUntil *time.Time `json:"retain_until,omitempty"`
+
+	// LegalHold blocks deleting or overwriting the version until it is
+	// released, independent of retention.
+	LegalHold bool `json:"legal_hold"`
+}
+
+// HasRetention reports whether the version has a retention period.
+func (l *ObjectLock) HasRetention() bool {
+	return l.Mode != "" && l.RetainUntil != nil
+}
+
+// Protects reports whether the lock blocks deleting or overwriting the
+// version at now. Active GOVERNANCE retention protects the version unless
+// bypassGovernance is set.
+func (l *ObjectLock) Protects(now time.Time, bypassGovernance bool) bool {
+	if l.LegalHold {
+		return true
+	}
+	if l.RetainUntil == nil || !now.Before(*l.RetainUntil) {
+		return false
+	}
+	switch l.Mode {
+	case ObjectLockModeCompliance:
+		return true
+	case ObjectLockModeGovernance:
+		return !bypassGovernance
+	default:
+		return false
+	}
+}
+
+// ValidateRetention checks a retention period requested at now. Mode and
+// retainUntil must be given together; both empty removes the retention.
+// Errors wrap ErrInvalidObjectLock with the reason.
+func ValidateRetention(mode ObjectLockMode, retainUntil *time.Time, now time.Time) error {
+	if mode == "" && retainUntil == nil {
+		return nil
+	}
+	if mode == "" || retainUntil == nil {
+		return fmt.Errorf("%w: the retention mode and retain-until date must be specified together", ErrInvalidObjectLock)
+	}
+	if !IsValidObjectLockMode(string(mode)) {
+		return fmt.Errorf("%w: unknown retention mode %q", ErrInvalidObjectLock, mode)
+	}
+	if !retainUntil.After(now) {
+		return fmt.Errorf("%w: the retain-until date must be in the future", ErrInvalidObjectLock)
+	}
+	return nil
+}
+
+// AllowsRetention reports whether the current lock can be replaced by the
+// given retention at now. Active COMPLIANCE retention can only be extended;
+// active GOVERNANCE retention can only be extended unless bypassGovernance
+// is set.
+func (l *ObjectLock) AllowsRetention(mode ObjectLockMode, retainUntil *time.Time, now time.Time, bypassGovernance bool) bool {
+	if l.RetainUntil == nil || !now.Before(*l.RetainUntil) {
+		return true
+	}
+	extends := retainUntil != nil && !retainUntil.Before(*l.RetainUntil)
+	switch l.Mode {
+	case ObjectLockModeCompliance:
+		return mode == ObjectLockModeCompliance && extends
+	case ObjectLockModeGovernance:
+		return bypassGovernance || (IsValidObjectLockMode(string(mode)) && extends)
+	default:
+		return true
+	}
+}
+
+// DefaultRetention is the retention a bucket applies to new object versions
+// that are written without retention of their own. The period is given in
+// either Days or Years.
+type DefaultRetention struct {
+	Mode  ObjectLockMode `json:"mode"`
+	Days  int            `json:"days,omitempty"`
+	Years int            `json:"years,omitempty"`
+}
+
+// Validate checks the mode and that exactly one positive period is given.
+// Errors wrap ErrInvalidObjectLockConfiguration with the reason.
+func (r *DefaultRetention) Validate() error {
+	if !IsValidObjectLockMode(string(r.Mode)) {
+		return fmt.Errorf("%w: unknown retention mode %q", ErrInvalidObjectLockConfiguration, r.Mode)
+	}
+	if (r.Days > 0) == (r.Years > 0) || r.Days < 0 || r.Years < 0 {
+		return fmt.Errorf("%w: the default retention period must be a positive number of either days or years", ErrInvalidObjectLockConfiguration)
+	}
+	return nil
+}
+
+// Lock returns the lock of a version written at now under the default retention.
+func (r *DefaultRetention) Lock(now time.Time) *ObjectLock {
+	retainUntil := now.AddDate(r.Years, 0, r.Days).UTC()
+	return &ObjectLock{Mode: r.Mode, RetainUntil: &retainUntil}
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// S3 object tagging limits.
+const (
+	// MaxObjectTags is the maximum number of tags on one object version.
+	MaxObjectTags = 10
+
+	// MaxTagKeyLength is the maximum tag key length in characters.
+	MaxTagKeyLength = 128
+
+	// MaxTagValueLength is the maximum tag value length in characters.
+	MaxTagValueLength = 256
+)
+
+// ValidateTags checks a tag set against the S3 tagging limits.
+// Keys must be unique; callers building the set from a list check that.
+func ValidateTags(tags map[string]string) error {
+	if len(tags) > MaxObjectTags {
+		return ErrTooManyTags
+	}
+
+	for key, value := range tags {
+		if key == "" {
+			return fmt.Errorf("%w: tag key cannot be empty", ErrInvalidTag)
+		}
+		if !utf8.ValidString(key) || !utf8.ValidString(value) {
+			return fmt.Errorf("%w: tags must be valid UTF-8", ErrInvalidTag)
+		}
+		if utf8.RuneCountInString(key) > MaxTagKeyLength {
+			return fmt.Errorf("%w: tag key exceeds %d characters", ErrInvalidTag, MaxTagKeyLength)
+		}
+		if utf8.RuneCountInString(value) > MaxTagValueLength {
+			return fmt.Errorf("%w: tag value exceeds %d characters", ErrInvalidTag, MaxTagValueLength)
+		}
+	}
+
+	return nil
+}
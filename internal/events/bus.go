@@ -0,0 +1,162 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	// DefaultQueueSize is the number of events buffered per subscriber.
+	DefaultQueueSize = 1024
+
+	// handleTimeout bounds how long a subscriber may spend on one event.
+	handleTimeout = 10 * time.Second
+)
+
+// Bus fans out published events to subscribers. Each subscriber has its own
+// bounded queue and goroutine, so a slow subscriber only delays itself.
+// When a queue is full the event is dropped for that subscriber and logged.
+//
+// A nil *Bus is valid and discards every event.
+type Bus struct {
+	queueSize int
+	logger    zerolog.Logger
+
+	mu     sync.RWMutex
+	subs   []*subscription
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// subscription is a subscriber with its delivery queue.
+type subscription struct {
+	name  string
+	sub   Subscriber
+	queue chan Event
+}
+
+// NewBus creates a Bus. A queueSize of 0 or less uses DefaultQueueSize.
+func NewBus(queueSize int, logger zerolog.Logger) *Bus {
+	if queueSize <= 0 {
+		queueSize = DefaultQueueSize
+	}
+	return &Bus{
+		queueSize: queueSize,
+		logger:    logger.With().Str("component", "events").Logger(),
+	}
+}
+
+// Subscribe registers a subscriber under name and starts delivering events
+// published from now on. Subscribing to a closed bus is a no-op.
+func (b *Bus) Subscribe(name string, sub Subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+
+	s := &subscription{
+		name:  name,
+		sub:   sub,
+		queue: make(chan Event, b.queueSize),
+	}
+	b.subs = append(b.subs, s)
+
+	b.wg.Add(1)
+	go b.deliver(s)
+}
+
+// Publish queues an event for every subscriber without blocking.
+func (b *Bus) Publish(event Event) {
+	if b == nil {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.closed {
+		return
+	}
+
+	for _, s := range b.subs {
+		select {
+		case s.queue <- event:
+		default:
+			b.logger.Warn().
+				Str("subscriber", s.name).
+				Str("event", string(event.Type)).
+				Str("bucket", event.Bucket).
+				Str("key", event.Key).
+				Msg("event queue full, dropping event")
+		}
+	}
+}
+
+// Close stops accepting events and waits until subscribers have drained
+// their queues or ctx is done.
+func (b *Bus) Close(ctx context.Context) error {
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		for _, s := range b.subs {
+			close(s.queue)
+		}
+	}
+	b.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// deliver hands queued events to a subscriber until its queue is closed.
+func (b *Bus) deliver(s *subscription) {
+	defer b.wg.Done()
+
+	for event := range s.queue {
+		b.handle(s, event)
+	}
+}
+
+// handle delivers one event, recovering from subscriber panics.
+func (b *Bus) handle(s *subscription, event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			b.logger.Error().
+				Interface("panic", r).
+				Str("subscriber", s.name).
+				Str("event", string(event.Type)).
+				Msg("event subscriber panicked")
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), handleTimeout)
+	defer cancel()
+
+	if err := s.sub.HandleEvent(ctx, event); err != nil {
+		b.logger.Warn().
+			Err(err).
+			Str("subscriber", s.name).
+			Str("event", string(event.Type)).
+			Str("bucket", event.Bucket).
+			Str("key", event.Key).
+			Msg("event subscriber failed")
+	}
+}
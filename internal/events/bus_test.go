@@ -0,0 +1,118 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBus_DeliversToEverySubscriber(t *testing.T) {
+	bus := NewBus(0, zerolog.Nop())
+
+	var mu sync.Mutex
+	got := map[string][]Type{}
+	record := func(name string) Subscriber {
+		return SubscriberFunc(func(_ context.Context, event Event) error {
+			mu.Lock()
+			defer mu.Unlock()
+			got[name] = append(got[name], event.Type)
+			return nil
+		})
+	}
+	bus.Subscribe("a", record("a"))
+	bus.Subscribe("b", record("b"))
+
+	bus.Publish(Event{Type: ObjectCreatedPut, Bucket: "docs", Key: "a.txt"})
+	bus.Publish(Event{Type: ObjectRemovedDelete, Bucket: "docs", Key: "a.txt"})
+
+	require.NoError(t, bus.Close(context.Background()))
+
+	want := []Type{ObjectCreatedPut, ObjectRemovedDelete}
+	assert.Equal(t, want, got["a"])
+	assert.Equal(t, want, got["b"])
+
+	// Events published after Close are discarded
+	bus.Publish(Event{Type: ObjectCreatedPut})
+}
+
+func TestBus_SubscriberFailureDoesNotStopDelivery(t *testing.T) {
+	bus := NewBus(0, zerolog.Nop())
+
+	var delivered int
+	bus.Subscribe("flaky", SubscriberFunc(func(_ context.Context, event Event) error {
+		delivered++
+		if event.Key == "panic" {
+			panic("boom")
+		}
+		return errors.New("unavailable")
+	}))
+
+	bus.Publish(Event{Type: ObjectCreatedPut, Key: "panic"})
+	bus.Publish(Event{Type: ObjectCreatedPut, Key: "b"})
+	require.NoError(t, bus.Close(context.Background()))
+
+	assert.Equal(t, 2, delivered)
+}
+
+func TestBus_DropsWhenQueueFull(t *testing.T) {
+	bus := NewBus(1, zerolog.Nop())
+
+	release := make(chan struct{})
+	var delivered int
+	bus.Subscribe("slow", SubscriberFunc(func(context.Context, Event) error {
+		<-release
+		delivered++
+		return nil
+	}))
+
+	// The first event may be in flight; publishing must never block
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 10; i++ {
+			bus.Publish(Event{Type: ObjectCreatedPut})
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Publish blocked on a slow subscriber")
+	}
+
+	close(release)
+	require.NoError(t, bus.Close(context.Background()))
+	assert.Less(t, delivered, 10)
+}
+
+func TestNilBus_Publish(t *testing.T) {
+	var bus *Bus
+	bus.Publish(Event{Type: ObjectCreatedPut})
+}
+
+type fakeRecorder struct {
+	hashes []string
+}
+
+func (f *fakeRecorder) RecordAccess(_ context.Context, contentHash string) error {
+	f.hashes = append(f.hashes, contentHash)
+	return nil
+}
+
+func TestAccessSubscriber_RecordsCreatedObjects(t *testing.T) {
+	recorder := &fakeRecorder{}
+	sub := NewAccessSubscriber(recorder)
+	ctx := context.Background()
+
+	require.NoError(t, sub.HandleEvent(ctx, Event{Type: ObjectCreatedPut, ContentHash: "h1"}))
+	require.NoError(t, sub.HandleEvent(ctx, Event{Type: ObjectCreatedCopy, ContentHash: "h2"}))
+	require.NoError(t, sub.HandleEvent(ctx, Event{Type: ObjectRemovedDelete}))
+
+	assert.Equal(t, []string{"h1", "h2"}, recorder.hashes)
+}
@@ -0,0 +1,75 @@
+// Package events provides an in-process event bus for object operations.
+// Services publish typed events after a write commits; subscribers such as
+// the audit log or the access tracker consume them asynchronously, so side
+// effects never slow down or fail the write path.
+package events
+
+import (
+	"context"
+	"time"
+)
+
+// Type identifies the kind of object event. Values follow the S3 event
+// notification names.
+type Type string
+
+const (
+	// ObjectCreatedPut is published when PutObject stores an object.
+	ObjectCreatedPut Type = "s3:ObjectCreated:Put"
+
+	// ObjectCreatedCopy is published when CopyObject creates an object.
+	ObjectCreatedCopy Type = "s3:ObjectCreated:Copy"
+
+	// ObjectCreatedCompleteMultipartUpload is published when a multipart upload completes.
+	ObjectCreatedCompleteMultipartUpload Type = "s3:ObjectCreated:CompleteMultipartUpload"
+
+	// ObjectRemovedDelete is published when an object version is deleted.
+	ObjectRemovedDelete Type = "s3:ObjectRemoved:Delete"
+
+	// ObjectRemovedDeleteMarkerCreated is published when a delete marker is created.
+	ObjectRemovedDeleteMarkerCreated Type = "s3:ObjectRemoved:DeleteMarkerCreated"
+)
+
+// IsCreated reports whether the event type is one of the ObjectCreated types.
+func (t Type) IsCreated() bool {
+	switch t {
+	case ObjectCreatedPut, ObjectCreatedCopy, ObjectCreatedCompleteMultipartUpload:
+		return true
+	}
+	return false
+}
+
+// Event describes a committed object operation.
+type Event struct {
+	Type      Type
+	Bucket    string
+	Key       string
+	VersionID string // Empty for unversioned buckets
+	ETag      string
+	Size      int64
+
+	// ContentType is the stored content type. Empty for removals.
+	ContentType string
+
+	// ContentHash is the blob backing the object. Empty for removals.
+	ContentHash string
+
+	// OwnerID is the user that made the request (0 for admin operations).
+	OwnerID int64
+
+	Time time.Time
+}
+
+// Subscriber consumes events. HandleEvent runs on the subscriber's own
+// goroutine; a returned error is logged and does not stop delivery.
+type Subscriber interface {
+	HandleEvent(ctx context.Context, event Event) error
+}
+
+// SubscriberFunc adapts a function to the Subscriber interface.
+type SubscriberFunc func(ctx context.Context, event Event) error
+
+// HandleEvent calls f(ctx, event).
+func (f SubscriberFunc) HandleEvent(ctx context.Context, event Event) error {
+	return f(ctx, event)
+}
@@ -0,0 +1,57 @@
+package events
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+)
+
+// AuditLogger writes every event to a structured log.
+type AuditLogger struct {
+	logger zerolog.Logger
+}
+
+// NewAuditLogger creates an audit log subscriber.
+func NewAuditLogger(logger zerolog.Logger) *AuditLogger {
+	return &AuditLogger{logger: logger.With().Str("component", "audit").Logger()}
+}
+
+// HandleEvent logs the event.
+func (a *AuditLogger) HandleEvent(_ context.Context, event Event) error {
+	a.logger.Info().
+		Str("event", string(event.Type)).
+		Str("bucket", event.Bucket).
+		Str("key", event.Key).
+		Str("version_id", event.VersionID).
+		Str("etag", event.ETag).
+		Int64("size", event.Size).
+		Int64("owner_id", event.OwnerID).
+		Time("event_time", event.Time).
+		Msg("object event")
+	return nil
+}
+
+// AccessRecorder records blob accesses for tiering decisions.
+// tiering.AccessTracker implementations satisfy it.
+type AccessRecorder interface {
+	RecordAccess(ctx context.Context, contentHash string) error
+}
+
+// AccessSubscriber records an access for every newly created object, so
+// fresh data starts out hot instead of looking idle to the tiering policy.
+type AccessSubscriber struct {
+	recorder AccessRecorder
+}
+
+// NewAccessSubscriber creates an access-tracking subscriber.
+func NewAccessSubscriber(recorder AccessRecorder) *AccessSubscriber {
+	return &AccessSubscriber{recorder: recorder}
+}
+
+// HandleEvent records an access for ObjectCreated events.
+func (a *AccessSubscriber) HandleEvent(ctx context.Context, event Event) error {
+	if !event.Type.IsCreated() || event.ContentHash == "" {
+		return nil
+	}
+	return a.recorder.RecordAccess(ctx, event.ContentHash)
+}
@@ -0,0 +1,429 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/prn-tf/alexander-storage/internal/auth"
+	"github.com/prn-tf/alexander-storage/internal/cluster"
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/service"
+	"github.com/prn-tf/alexander-storage/internal/tiering"
+)
+
+// AdminPathPrefix is the URL prefix of the operator API.
+// Bucket names cannot start with an underscore, so it never collides with S3 paths.
+const AdminPathPrefix = "/_alexander/admin/"
+
+// MigrationController exposes the in-progress tier migrations and restores.
+type MigrationController interface {
+	GetActiveMigrations() []*tiering.MigrationStatus
+	CancelMigration(contentHash string) error
+}
+
+// TieringEvaluator re-evaluates tiering policies on demand.
+type TieringEvaluator interface {
+	EvaluateBucket(ctx context.Context, bucket string, dryRun bool) []*tiering.TieringDecision
+}
+
+// ObjectRestorer restores deleted objects within their retention period.
+type ObjectRestorer interface {
+	UndeleteObject(ctx context.Context, input service.UndeleteObjectInput) (*service.UndeleteObjectOutput, error)
+}
+
+// ObjectInspector reports storage details of objects.
+type ObjectInspector interface {
+	HeadObject(ctx context.Context, input service.HeadObjectInput) (*service.HeadObjectOutput, error)
+}
+
+// AccessInfoReader reports the access history the tiering policies act on.
+// tiering.AccessTracker implementations satisfy it.
+type AccessInfoReader interface {
+	GetAccessInfo(ctx context.Context, contentHash string) (*tiering.BlobAccessInfo, error)
+}
+
+// ReplicationStatusReader reports where the copies of a blob are kept.
+type ReplicationStatusReader interface {
+	GetReplicationStatus(ctx context.Context, contentHash string) (*cluster.ReplicationStatus, error)
+}
+
+// UserLookup resolves the authenticated user to check admin rights.
+type UserLookup interface {
+	GetByID(ctx context.Context, id int64) (*domain.User, error)
+}
+
+// AdminHandler handles the operator API. Every request requires an admin user.
+// Optional dependencies may be nil, which disables their resources.
+type AdminHandler struct {
+	users      UserLookup
+	migrations MigrationController
+	tiering    TieringEvaluator
+	objects    ObjectRestorer
+	inspector  ObjectInspector
+	access     AccessInfoReader
+	replicas   ReplicationStatusReader
+	logger     zerolog.Logger
+}
+
+// NewAdminHandler creates a new AdminHandler.
+func NewAdminHandler(users UserLookup, migrations MigrationController, tiering TieringEvaluator, objects ObjectRestorer, inspector ObjectInspector, logger zerolog.Logger) *AdminHandler {
+	return &AdminHandler{
+		users:      users,
+		migrations: migrations,
+		tiering:    tiering,
+		objects:    objects,
+		inspector:  inspector,
+		logger:     logger.With().Str("handler", "admin").Logger(),
+	}
+}
+
+// SetAccessTracker adds the access history of an object's blob to the
+// object resource, explaining its tier. Without it the fields are left out.
+func (h *AdminHandler) SetAccessTracker(access AccessInfoReader) {
+	h.access = access
+}
+
+// SetReplicationStatus enables the replication resource, which reports the
+// copies of a blob kept across the cluster.
+func (h *AdminHandler) SetReplicationStatus(replicas ReplicationStatusReader) {
+	h.replicas = replicas
+}
+
+// MigrationListResponse is the JSON response of GET /_alexander/admin/migrations.
+type MigrationListResponse struct {
+	Migrations []*tiering.MigrationStatus `json:"migrations"`
+}
+
+// TieringEvaluationResponse is the JSON response of POST /_alexander/admin/tiering/{bucket}.
+type TieringEvaluationResponse struct {
+	Bucket    string                     `json:"bucket"`
+	DryRun    bool                       `json:"dryRun"`
+	Decisions []*tiering.TieringDecision `json:"decisions"`
+}
+
+// ObjectStorageResponse is the JSON response of GET /_alexander/admin/objects/{bucket}/{key}.
+type ObjectStorageResponse struct {
+	Bucket           string `json:"bucket"`
+	Key              string `json:"key"`
+	VersionID        string `json:"versionId,omitempty"`
+	Size             int64  `json:"size"`
+	ContentHash      string `json:"contentHash,omitempty"`
+	Encrypted        bool   `json:"encrypted"`
+	EncryptionScheme string `json:"encryptionScheme,omitempty"`
+
+	// Access history of the blob, present when the access tracker has a
+	// record of it.
+	Tier           string     `json:"tier,omitempty"`
+	LastAccessedAt *time.Time `json:"lastAccessedAt,omitempty"`
+	AccessCount    int64      `json:"accessCount,omitempty"`
+}
+
+// UndeleteResponse is the JSON response of POST /_alexander/admin/undelete/{bucket}/{key}.
+type UndeleteResponse struct {
+	Bucket    string    `json:"bucket"`
+	Key       string    `json:"key"`
+	VersionID string    `json:"versionId,omitempty"`
+	DeletedAt time.Time `json:"deletedAt"`
+}
+
+// errAdminResourceNotFound is returned for unknown or disabled admin resources.
+var errAdminResourceNotFound = S3Error{
+	Code:           "NotFound",
+	Message:        "The specified admin resource does not exist.",
+	HTTPStatusCode: http.StatusNotFound,
+}
+
+// errAdminMethodNotAllowed is returned for unsupported methods on admin resources.
+var errAdminMethodNotAllowed = S3Error{
+	Code:           "MethodNotAllowed",
+	Message:        "The specified method is not allowed against this resource.",
+	HTTPStatusCode: http.StatusMethodNotAllowed,
+}
+
+// ServeHTTP routes operator API requests:
+//
+//	GET    /_alexander/admin/migrations                 list active migrations and restores
+//	DELETE /_alexander/admin/migrations/{content-hash}  cancel a migration
+//	GET    /_alexander/admin/objects/{bucket}/{key}     report how an object version is stored and last accessed (?versionId=)
+//	GET    /_alexander/admin/replication/{content-hash} report the copies of a blob across the cluster
+//	POST   /_alexander/admin/tiering/{bucket}           re-evaluate tiering policies for one bucket (?dryRun=true to only report)
+//	POST   /_alexander/admin/undelete/{bucket}/{key}    restore a deleted object within its retention
+func (h *AdminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.isAdmin(r) {
+		writeError(w, ErrAccessDenied)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, AdminPathPrefix)
+	resource, rest, _ := strings.Cut(path, "/")
+	switch {
+	case resource == "migrations" && h.migrations != nil:
+		h.serveMigrations(w, r, rest)
+	case resource == "objects" && h.inspector != nil:
+		h.serveObject(w, r, rest)
+	case resource == "replication" && h.replicas != nil:
+		h.serveReplication(w, r, rest)
+	case resource == "tiering" && h.tiering != nil:
+		h.serveTiering(w, r, rest)
+	case resource == "undelete" && h.objects != nil:
+		h.serveUndelete(w, r, rest)
+	default:
+		writeError(w, errAdminResourceNotFound)
+	}
+}
+
+// serveMigrations routes requests on the migrations resource.
+func (h *AdminHandler) serveMigrations(w http.ResponseWriter, r *http.Request, contentHash string) {
+	switch {
+	case contentHash == "" && r.Method == http.MethodGet:
+		h.listMigrations(w)
+	case contentHash != "" && r.Method == http.MethodDelete:
+		h.cancelMigration(w, contentHash)
+	default:
+		writeError(w, errAdminMethodNotAllowed)
+	}
+}
+
+// serveObject reports the storage details of an object version.
+func (h *AdminHandler) serveObject(w http.ResponseWriter, r *http.Request, path string) {
+	if r.Method != http.MethodGet {
+		writeError(w, errAdminMethodNotAllowed)
+		return
+	}
+
+	bucketName, key, _ := strings.Cut(path, "/")
+	if bucketName == "" || key == "" {
+		writeError(w, errAdminResourceNotFound)
+		return
+	}
+
+	output, err := h.inspector.HeadObject(r.Context(), service.HeadObjectInput{
+		BucketName:        bucketName,
+		Key:               key,
+		VersionID:         r.URL.Query().Get("versionId"),
+		IncludeEncryption: true,
+	})
+	switch {
+	case err == nil:
+	case errors.Is(err, domain.ErrBucketNotFound):
+		writeError(w, ErrNoSuchBucket)
+		return
+	case errors.Is(err, domain.ErrObjectNotFound), errors.Is(err, domain.ErrObjectDeleted), errors.Is(err, domain.ErrVersionIsDeleteMarker):
+		writeError(w, S3Error{
+			Code:           "NoSuchKey",
+			Message:        "The specified key does not exist.",
+			Resource:       key,
+			HTTPStatusCode: http.StatusNotFound,
+		})
+		return
+	case errors.Is(err, domain.ErrInvalidVersionID):
+		writeError(w, S3Error{
+			Code:           "InvalidArgument",
+			Message:        "Invalid version id specified.",
+			HTTPStatusCode: http.StatusBadRequest,
+		})
+		return
+	default:
+		h.logger.Error().Err(err).Str("bucket", bucketName).Str("key", key).Msg("failed to inspect object")
+		writeError(w, ErrInternalError)
+		return
+	}
+
+	resp := ObjectStorageResponse{
+		Bucket:    bucketName,
+		Key:       key,
+		VersionID: output.VersionID,
+		Size:      output.ContentLength,
+	}
+	if output.Encryption != nil {
+		resp.ContentHash = output.Encryption.ContentHash
+		resp.Encrypted = output.Encryption.Encrypted
+		resp.EncryptionScheme = string(output.Encryption.Scheme)
+	}
+	if h.access != nil && resp.ContentHash != "" {
+		h.addAccessInfo(r.Context(), &resp)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// addAccessInfo fills in the access history of the object's blob. A blob the
+// tracker has no record of, or a tracker error, leaves the fields empty.
+func (h *AdminHandler) addAccessInfo(ctx context.Context, resp *ObjectStorageResponse) {
+	info, err := h.access.GetAccessInfo(ctx, resp.ContentHash)
+	if err != nil {
+		// The trackers report a missing record with ErrNoTargetNode
+		if !errors.Is(err, tiering.ErrNoTargetNode) {
+			h.logger.Warn().Err(err).Str("content_hash", resp.ContentHash).Msg("failed to read blob access info")
+		}
+		return
+	}
+
+	resp.Tier = string(info.CurrentTier)
+	resp.AccessCount = info.AccessCount
+	if !info.LastAccessedAt.IsZero() {
+		lastAccessedAt := info.LastAccessedAt.UTC()
+		resp.LastAccessedAt = &lastAccessedAt
+	}
+}
+
+// serveReplication reports the copies of a blob across the cluster.
+func (h *AdminHandler) serveReplication(w http.ResponseWriter, r *http.Request, contentHash string) {
+	if r.Method != http.MethodGet {
+		writeError(w, errAdminMethodNotAllowed)
+		return
+	}
+	if contentHash == "" || strings.Contains(contentHash, "/") {
+		writeError(w, errAdminResourceNotFound)
+		return
+	}
+
+	status, err := h.replicas.GetReplicationStatus(r.Context(), contentHash)
+	if err != nil {
+		h.logger.Error().Err(err).Str("content_hash", contentHash).Msg("failed to read replication status")
+		writeError(w, ErrInternalError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(status)
+}
+
+// serveTiering runs the tiering policies against a single bucket.
+func (h *AdminHandler) serveTiering(w http.ResponseWriter, r *http.Request, bucketName string) {
+	if r.Method != http.MethodPost {
+		writeError(w, errAdminMethodNotAllowed)
+		return
+	}
+	if bucketName == "" || strings.Contains(bucketName, "/") {
+		writeError(w, errAdminResourceNotFound)
+		return
+	}
+
+	dryRun := r.URL.Query().Get("dryRun") == "true"
+	decisions := h.tiering.EvaluateBucket(r.Context(), bucketName, dryRun)
+
+	h.logger.Info().
+		Str("bucket", bucketName).
+		Bool("dry_run", dryRun).
+		Int("decisions", len(decisions)).
+		Msg("tiering evaluation triggered by operator")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(TieringEvaluationResponse{
+		Bucket:    bucketName,
+		DryRun:    dryRun,
+		Decisions: decisions,
+	})
+}
+
+// serveUndelete restores the most recently deleted version of an object.
+func (h *AdminHandler) serveUndelete(w http.ResponseWriter, r *http.Request, path string) {
+	if r.Method != http.MethodPost {
+		writeError(w, errAdminMethodNotAllowed)
+		return
+	}
+
+	bucketName, key, _ := strings.Cut(path, "/")
+	if bucketName == "" || key == "" {
+		writeError(w, errAdminResourceNotFound)
+		return
+	}
+
+	output, err := h.objects.UndeleteObject(r.Context(), service.UndeleteObjectInput{
+		BucketName: bucketName,
+		Key:        key,
+	})
+	switch {
+	case err == nil:
+	case errors.Is(err, domain.ErrBucketNotFound):
+		writeError(w, ErrNoSuchBucket)
+		return
+	case errors.Is(err, domain.ErrObjectNotFound):
+		writeError(w, S3Error{
+			Code:           "NoSuchKey",
+			Message:        "There is no deleted object with this key within the retention period.",
+			Resource:       key,
+			HTTPStatusCode: http.StatusNotFound,
+		})
+		return
+	case errors.Is(err, domain.ErrObjectRestoreConflict):
+		writeError(w, S3Error{
+			Code:           "ObjectRestoreConflict",
+			Message:        "The key has been written again since the object was deleted.",
+			Resource:       key,
+			HTTPStatusCode: http.StatusConflict,
+		})
+		return
+	default:
+		h.logger.Error().Err(err).Str("bucket", bucketName).Str("key", key).Msg("failed to undelete object")
+		writeError(w, ErrInternalError)
+		return
+	}
+
+	h.logger.Info().Str("bucket", bucketName).Str("key", key).Msg("object restored by operator")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(UndeleteResponse{
+		Bucket:    bucketName,
+		Key:       key,
+		VersionID: output.VersionID,
+		DeletedAt: output.DeletedAt,
+	})
+}
+
+// listMigrations writes all active migrations and restores.
+func (h *AdminHandler) listMigrations(w http.ResponseWriter) {
+	migrations := h.migrations.GetActiveMigrations()
+	if migrations == nil {
+		migrations = []*tiering.MigrationStatus{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(MigrationListResponse{Migrations: migrations})
+}
+
+// cancelMigration aborts the active migration of a blob.
+func (h *AdminHandler) cancelMigration(w http.ResponseWriter, contentHash string) {
+	err := h.migrations.CancelMigration(contentHash)
+	switch {
+	case err == nil:
+		h.logger.Info().Str("content_hash", contentHash).Msg("migration cancelled by operator")
+		w.WriteHeader(http.StatusNoContent)
+	case errors.Is(err, tiering.ErrMigrationNotFound):
+		writeError(w, S3Error{
+			Code:           "NoSuchMigration",
+			Message:        "There is no active migration for the specified blob.",
+			Resource:       contentHash,
+			HTTPStatusCode: http.StatusNotFound,
+		})
+	default:
+		h.logger.Error().Err(err).Str("content_hash", contentHash).Msg("failed to cancel migration")
+		writeError(w, ErrInternalError)
+	}
+}
+
+// isAdmin reports whether the request was made by an active admin user.
+func (h *AdminHandler) isAdmin(r *http.Request) bool {
+	userCtx, ok := auth.GetUserContext(r.Context())
+	if !ok {
+		return false
+	}
+	user, err := h.users.GetByID(r.Context(), userCtx.UserID)
+	if err != nil {
+		return false
+	}
+	return user.IsAdmin && user.IsActive
+}
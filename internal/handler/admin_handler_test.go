@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/cluster"
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/lock"
+	"github.com/prn-tf/alexander-storage/internal/service"
+	"github.com/prn-tf/alexander-storage/internal/storage/filesystem"
+	"github.com/prn-tf/alexander-storage/internal/tiering"
+)
+
+// adminUserLookup treats user 1 as an active admin.
+type adminUserLookup struct{}
+
+func (adminUserLookup) GetByID(ctx context.Context, id int64) (*domain.User, error) {
+	if id != 1 {
+		return nil, domain.ErrUserNotFound
+	}
+	return &domain.User{ID: 1, Username: "tester", IsAdmin: true, IsActive: true}, nil
+}
+
+func TestAdminHandler_ObjectLastAccess(t *testing.T) {
+	ctx := context.Background()
+
+	store, err := filesystem.NewStorage(filesystem.Config{
+		DataDir: t.TempDir(),
+		TempDir: t.TempDir(),
+	}, zerolog.Nop())
+	require.NoError(t, err)
+	buckets := &stubBucketRepository{buckets: map[string]*domain.Bucket{
+		"uploads": {ID: 1, Name: "uploads", OwnerID: 1, Versioning: domain.VersioningDisabled},
+	}}
+	objects := &memoryObjectRepository{objects: make(map[string]*domain.Object)}
+	blobs := &memoryBlobRepository{refs: make(map[string]int32)}
+	svc := service.NewObjectService(objects, blobs, buckets, store, lock.NewNoOpLocker(), zerolog.Nop())
+
+	_, err = svc.PutObject(ctx, service.PutObjectInput{
+		BucketName: "uploads",
+		Key:        "reports/q3.csv",
+		Body:       strings.NewReader("a,b,c"),
+		Size:       5,
+		OwnerID:    1,
+	})
+	require.NoError(t, err)
+
+	tracker := tiering.NewMemoryAccessTracker(zerolog.Nop())
+	h := NewAdminHandler(adminUserLookup{}, nil, nil, nil, svc, zerolog.Nop())
+	h.SetAccessTracker(tracker)
+
+	inspect := func() ObjectStorageResponse {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, withTestUser(httptest.NewRequest(http.MethodGet, AdminPathPrefix+"objects/uploads/reports/q3.csv", nil)))
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var resp ObjectStorageResponse
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+		return resp
+	}
+
+	// Without a tracker record the access fields are left out
+	resp := inspect()
+	require.NotEmpty(t, resp.ContentHash)
+	require.Nil(t, resp.LastAccessedAt)
+	require.Empty(t, resp.Tier)
+
+	require.NoError(t, tracker.RecordAccess(ctx, resp.ContentHash))
+	recorded, err := tracker.GetAccessInfo(ctx, resp.ContentHash)
+	require.NoError(t, err)
+
+	resp = inspect()
+	require.NotNil(t, resp.LastAccessedAt)
+	require.True(t, recorded.LastAccessedAt.Equal(*resp.LastAccessedAt))
+	require.Equal(t, int64(1), resp.AccessCount)
+	require.Equal(t, string(tiering.TierHot), resp.Tier)
+}
+
+// replicationStatusStub reports two copies of every blob.
+type replicationStatusStub struct{}
+
+func (replicationStatusStub) GetReplicationStatus(ctx context.Context, contentHash string) (*cluster.ReplicationStatus, error) {
+	return &cluster.ReplicationStatus{
+		ContentHash:  contentHash,
+		ReplicaCount: 2,
+		DesiredCount: 2,
+		Locations:    []*cluster.BlobLocation{{ContentHash: contentHash, NodeID: "node-1", IsPrimary: true}, {ContentHash: contentHash, NodeID: "node-2"}},
+		IsSufficient: true,
+	}, nil
+}
+
+func TestAdminHandler_ReplicationStatus(t *testing.T) {
+	h := NewAdminHandler(adminUserLookup{}, nil, nil, nil, nil, zerolog.Nop())
+
+	// Without a replicator the resource does not exist
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, withTestUser(httptest.NewRequest(http.MethodGet, AdminPathPrefix+"replication/abc123", nil)))
+	require.Equal(t, http.StatusNotFound, rec.Code)
+
+	h.SetReplicationStatus(replicationStatusStub{})
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, withTestUser(httptest.NewRequest(http.MethodGet, AdminPathPrefix+"replication/abc123", nil)))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var status cluster.ReplicationStatus
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&status))
+	require.Equal(t, "abc123", status.ContentHash)
+	require.Equal(t, 2, status.ReplicaCount)
+	require.Len(t, status.Locations, 2)
+}
@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/rs/zerolog"
+
+	"github.com/prn-tf/alexander-storage/internal/auth"
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/service"
+)
+
+// BatchPathPrefix is the URL prefix of the batch ingestion endpoint.
+// Bucket names cannot start with an underscore, so it never collides with S3 paths.
+const BatchPathPrefix = "/_alexander/batch/"
+
+// BatchContentType is the media type of a framed batch ingestion stream.
+const BatchContentType = "application/x-alexander-batch"
+
+// MaxBatchRecordSize is the largest object accepted in a batch record.
+// Larger objects should use PutObject or multipart upload.
+const MaxBatchRecordSize = 16 * 1024 * 1024
+
+// BatchHandler handles the non-S3 batch ingestion endpoint.
+type BatchHandler struct {
+	objectService *service.ObjectService
+	authorizer    auth.Authorizer
+	logger        zerolog.Logger
+}
+
+// NewBatchHandler creates a new BatchHandler.
+// If authorizer is nil, the default authorizer is used.
+func NewBatchHandler(objectService *service.ObjectService, authorizer auth.Authorizer, logger zerolog.Logger) *BatchHandler {
+	return &BatchHandler{
+		objectService: objectService,
+		authorizer:    defaultAuthorizer(authorizer),
+		logger:        logger.With().Str("handler", "batch").Logger(),
+	}
+}
+
+// BatchPutResponse is the JSON response of a batch ingestion request.
+type BatchPutResponse struct {
+	Bucket    string                 `json:"bucket"`
+	Succeeded int                    `json:"succeeded"`
+	Failed    int                    `json:"failed"`
+	Error     string                 `json:"error,omitempty"`
+	Results   []BatchPutRecordResult `json:"results"`
+}
+
+// BatchPutRecordResult is the outcome of a single record.
+type BatchPutRecordResult struct {
+	Key       string `json:"key"`
+	ETag      string `json:"etag,omitempty"`
+	VersionID string `json:"version_id,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// BatchPut handles POST /_alexander/batch/{bucket} requests.
+//
+// The body is a sequence of records, each framed as (big-endian):
+//
+//	uint16 key length | key | uint16 content type length | content type | uint64 size | body
+//
+// The stream ends at EOF. Each record is stored like a PutObject request and
+// the response lists the result of every record in order.
+func (h *BatchHandler) BatchPut(w http.ResponseWriter, r *http.Request, bucketName string) {
+	ctx := r.Context()
+
+	userCtx, ok := auth.GetUserContext(ctx)
+	if !ok {
+		h.logger.Error().Msg("no user context found")
+		writeError(w, ErrAccessDenied)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		writeError(w, S3Error{
+			Code:           "MethodNotAllowed",
+			Message:        "The specified method is not allowed against this resource.",
+			HTTPStatusCode: http.StatusMethodNotAllowed,
+		})
+		return
+	}
+
+	output, err := h.objectService.BatchPutObjects(ctx, service.BatchPutObjectsInput{
+		BucketName: bucketName,
+		Records:    newBatchFrameReader(r.Body),
+		OwnerID:    userCtx.UserID,
+		AllowKey: func(key string) bool {
+			return h.authorizer.Authorize(ctx, userCtx, auth.ActionPutObject, auth.ObjectARN(bucketName, key)) == nil
+		},
+	})
+	if output == nil {
+		switch {
+		case errors.Is(err, domain.ErrBucketNotFound):
+			writeError(w, ErrNoSuchBucket)
+		case errors.Is(err, domain.ErrBucketDeleting):
+			writeError(w, ErrOperationAborted)
+		case errors.Is(err, service.ErrBucketAccessDenied):
+			writeError(w, ErrAccessDenied)
+		default:
+			h.logger.Error().Err(err).Str("bucket", bucketName).Msg("batch ingestion failed")
+			writeError(w, ErrInternalError)
+		}
+		return
+	}
+
+	response := BatchPutResponse{
+		Bucket:    bucketName,
+		Succeeded: output.Succeeded,
+		Failed:    output.Failed,
+		Results:   make([]BatchPutRecordResult, len(output.Results)),
+	}
+	for i, res := range output.Results {
+		response.Results[i] = BatchPutRecordResult{
+			Key:       res.Key,
+			ETag:      res.ETag,
+			VersionID: res.VersionID,
+		}
+		if res.Err != nil {
+			response.Results[i].Error = res.Err.Error()
+		}
+	}
+
+	status := http.StatusOK
+	if err != nil {
+		response.Error = err.Error()
+		status = http.StatusBadRequest
+		if !errors.Is(err, service.ErrMalformedBatch) {
+			status = http.StatusInternalServerError
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
+// batchFrameReader decodes framed batch records from a stream.
+type batchFrameReader struct {
+	r       io.Reader
+	current *io.LimitedReader
+}
+
+// newBatchFrameReader creates a reader for the framed batch format.
+func newBatchFrameReader(r io.Reader) *batchFrameReader {
+	return &batchFrameReader{r: r}
+}
+
+// Next implements service.BatchRecordReader.
+// Any unread bytes of the previous record body are discarded first.
+func (f *batchFrameReader) Next() (*service.BatchRecord, error) {
+	if f.current != nil {
+		if _, err := io.Copy(io.Discard, f.current); err != nil {
+			return nil, err
+		}
+		if f.current.N > 0 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		f.current = nil
+	}
+
+	var keyLen uint16
+	if err := binary.Read(f.r, binary.BigEndian, &keyLen); err != nil {
+		// A clean EOF between records ends the stream
+		return nil, err
+	}
+	if keyLen == 0 {
+		return nil, fmt.Errorf("empty key")
+	}
+	key, err := f.readString(int(keyLen))
+	if err != nil {
+		return nil, err
+	}
+
+	var typeLen uint16
+	if err := binary.Read(f.r, binary.BigEndian, &typeLen); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	contentType, err := f.readString(int(typeLen))
+	if err != nil {
+		return nil, err
+	}
+
+	var size uint64
+	if err := binary.Read(f.r, binary.BigEndian, &size); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	if size > MaxBatchRecordSize {
+		return nil, fmt.Errorf("record %q exceeds the maximum size of %d bytes", key, MaxBatchRecordSize)
+	}
+
+	f.current = &io.LimitedReader{R: f.r, N: int64(size)}
+	return &service.BatchRecord{
+		Key:         key,
+		ContentType: contentType,
+		Size:        int64(size),
+		Body:        f.current,
+	}, nil
+}
+
+// readString reads exactly n bytes as a string.
+func (f *batchFrameReader) readString(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(f.r, buf); err != nil {
+		return "", unexpectedEOF(err)
+	}
+	return string(buf), nil
+}
+
+// unexpectedEOF reports a stream that ends inside a record.
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
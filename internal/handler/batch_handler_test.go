@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/lock"
+	"github.com/prn-tf/alexander-storage/internal/repository"
+	"github.com/prn-tf/alexander-storage/internal/service"
+	"github.com/prn-tf/alexander-storage/internal/storage/filesystem"
+)
+
+// memoryObjectRepository keeps the latest object per key.
+type memoryObjectRepository struct {
+	repository.ObjectRepository
+	mu      sync.Mutex
+	objects map[string]*domain.Object
+}
+
+func (r *memoryObjectRepository) GetByKey(ctx context.Context, bucketID int64, key string) (*domain.Object, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if obj, ok := r.objects[key]; ok {
+		return obj, nil
+	}
+	return nil, domain.ErrObjectNotFound
+}
+
+func (r *memoryObjectRepository) MarkNotLatest(ctx context.Context, bucketID int64, key string) error {
+	return nil
+}
+
+func (r *memoryObjectRepository) Create(ctx context.Context, obj *domain.Object) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	obj.ID = int64(len(r.objects) + 1)
+	r.objects[obj.Key] = obj
+	return nil
+}
+
+func (r *memoryObjectRepository) List(ctx context.Context, bucketID int64, opts repository.ObjectListOptions) (*repository.ObjectListResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result := &repository.ObjectListResult{}
+	for key, obj := range r.objects {
+		if strings.HasPrefix(key, opts.Prefix) {
+			result.Objects = append(result.Objects, &domain.ObjectInfo{Key: key, Size: obj.Size, ETag: obj.ETag, LastModified: obj.CreatedAt})
+		}
+	}
+	sort.Slice(result.Objects, func(i, j int) bool { return result.Objects[i].Key < result.Objects[j].Key })
+	return result, nil
+}
+
+func (r *memoryObjectRepository) GetTags(ctx context.Context, objectID int64) (map[string]string, error) {
+	return nil, nil
+}
+
+// memoryBlobRepository counts blob references.
+type memoryBlobRepository struct {
+	repository.BlobRepository
+	mu   sync.Mutex
+	refs map[string]int32
+}
+
+func (r *memoryBlobRepository) UpsertWithRefIncrement(ctx context.Context, contentHash string, size int64, storagePath string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.refs[contentHash]++
+	return r.refs[contentHash] == 1, nil
+}
+
+func (r *memoryBlobRepository) Exists(ctx context.Context, contentHash string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.refs[contentHash] > 0, nil
+}
+
+func (r *memoryBlobRepository) IncrementRef(ctx context.Context, contentHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.refs[contentHash]++
+	return nil
+}
+
+func (r *memoryBlobRepository) GetByHash(ctx context.Context, contentHash string) (*domain.Blob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.refs[contentHash] == 0 {
+		return nil, domain.ErrBlobNotFound
+	}
+	return &domain.Blob{ContentHash: contentHash, RefCount: r.refs[contentHash]}, nil
+}
+
+func writeBatchRecord(buf *bytes.Buffer, key, contentType string, body []byte) {
+	binary.Write(buf, binary.BigEndian, uint16(len(key)))
+	buf.WriteString(key)
+	binary.Write(buf, binary.BigEndian, uint16(len(contentType)))
+	buf.WriteString(contentType)
+	binary.Write(buf, binary.BigEndian, uint64(len(body)))
+	buf.Write(body)
+}
+
+func newBatchTestHandler(t *testing.T) (*BatchHandler, *memoryObjectRepository, *memoryBlobRepository) {
+	t.Helper()
+
+	store, err := filesystem.NewStorage(filesystem.Config{
+		DataDir: t.TempDir(),
+		TempDir: t.TempDir(),
+	}, zerolog.Nop())
+	require.NoError(t, err)
+
+	buckets := &stubBucketRepository{buckets: map[string]*domain.Bucket{
+		"ingest": {ID: 1, Name: "ingest", OwnerID: 1, Versioning: domain.VersioningDisabled},
+	}}
+	objects := &memoryObjectRepository{objects: make(map[string]*domain.Object)}
+	blobs := &memoryBlobRepository{refs: make(map[string]int32)}
+
+	svc := service.NewObjectService(objects, blobs, buckets, store, lock.NewNoOpLocker(), zerolog.Nop())
+	return NewBatchHandler(svc, nil, zerolog.Nop()), objects, blobs
+}
+
+func TestBatchHandler_Ingest1000Objects(t *testing.T) {
+	h, objects, blobs := newBatchTestHandler(t)
+
+	const count = 1000
+	var body bytes.Buffer
+	for i := 0; i < count; i++ {
+		// Every tenth object repeats content to exercise deduplication
+		content := fmt.Sprintf("object-%d", i)
+		if i%10 == 0 {
+			content = "shared"
+		}
+		writeBatchRecord(&body, fmt.Sprintf("logs/%04d.txt", i), "text/plain", []byte(content))
+	}
+
+	req := withTestUser(httptest.NewRequest(http.MethodPost, BatchPathPrefix+"ingest", &body))
+	req.Header.Set("Content-Type", BatchContentType)
+	rec := httptest.NewRecorder()
+
+	h.BatchPut(rec, req, "ingest")
+
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var resp BatchPutResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	require.Equal(t, count, resp.Succeeded)
+	require.Zero(t, resp.Failed)
+	require.Len(t, resp.Results, count)
+	require.Equal(t, "logs/0000.txt", resp.Results[0].Key)
+	require.NotEmpty(t, resp.Results[0].ETag)
+
+	require.Len(t, objects.objects, count)
+	require.Equal(t, "text/plain", objects.objects["logs/0999.txt"].ContentType)
+	require.Len(t, blobs.refs, count-count/10+1)
+}
+
+func TestBatchHandler_PerRecordResults(t *testing.T) {
+	h, _, _ := newBatchTestHandler(t)
+
+	var body bytes.Buffer
+	writeBatchRecord(&body, "ok.txt", "", []byte("fine"))
+	writeBatchRecord(&body, string(bytes.Repeat([]byte("k"), 1025)), "", []byte("key too long"))
+	writeBatchRecord(&body, "also-ok.txt", "", []byte("fine too"))
+
+	req := withTestUser(httptest.NewRequest(http.MethodPost, BatchPathPrefix+"ingest", &body))
+	rec := httptest.NewRecorder()
+
+	h.BatchPut(rec, req, "ingest")
+
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var resp BatchPutResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	require.Equal(t, 2, resp.Succeeded)
+	require.Equal(t, 1, resp.Failed)
+	require.Empty(t, resp.Results[0].Error)
+	require.NotEmpty(t, resp.Results[1].Error)
+	require.Equal(t, "also-ok.txt", resp.Results[2].Key)
+	require.Empty(t, resp.Results[2].Error)
+}
+
+func TestBatchHandler_TruncatedStream(t *testing.T) {
+	h, _, _ := newBatchTestHandler(t)
+
+	var body bytes.Buffer
+	writeBatchRecord(&body, "ok.txt", "", []byte("fine"))
+	writeBatchRecord(&body, "cut.txt", "", []byte("this body is cut short"))
+	body.Truncate(body.Len() - 5)
+
+	req := withTestUser(httptest.NewRequest(http.MethodPost, BatchPathPrefix+"ingest", &body))
+	rec := httptest.NewRecorder()
+
+	h.BatchPut(rec, req, "ingest")
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	var resp BatchPutResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	require.NotEmpty(t, resp.Error)
+	require.Equal(t, 1, resp.Succeeded)
+}
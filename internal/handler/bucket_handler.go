@@ -18,13 +18,16 @@ import (
 // BucketHandler handles bucket-related HTTP requests.
 type BucketHandler struct {
 	bucketService *service.BucketService
+	authorizer    auth.Authorizer
 	logger        zerolog.Logger
 }
 
 // NewBucketHandler creates a new BucketHandler.
-func NewBucketHandler(bucketService *service.BucketService, logger zerolog.Logger) *BucketHandler {
+// If authorizer is nil, the default authorizer is used.
+func NewBucketHandler(bucketService *service.BucketService, authorizer auth.Authorizer, logger zerolog.Logger) *BucketHandler {
 	return &BucketHandler{
 		bucketService: bucketService,
+		authorizer:    defaultAuthorizer(authorizer),
 		logger:        logger.With().Str("handler", "bucket").Logger(),
 	}
 }
@@ -66,6 +69,18 @@ type VersioningConfiguration struct {
 	MFADelete string   `xml:"MfaDelete,omitempty"`
 }
 
+// OwnershipControls is the request/response for bucket object ownership.
+type OwnershipControls struct {
+	XMLName xml.Name                `xml:"OwnershipControls"`
+	Xmlns   string                  `xml:"xmlns,attr,omitempty"`
+	Rules   []OwnershipControlsRule `xml:"Rule"`
+}
+
+// OwnershipControlsRule holds the object ownership setting.
+type OwnershipControlsRule struct {
+	ObjectOwnership string `xml:"ObjectOwnership"`
+}
+
 // =============================================================================
 // Handler Methods
 // =============================================================================
@@ -89,6 +104,10 @@ func (h *BucketHandler) CreateBucket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionCreateBucket, auth.BucketARN(bucketName)) {
+		return
+	}
+
 	// Parse optional location constraint from body
 	var region string
 	if r.ContentLength > 0 {
@@ -112,9 +131,12 @@ func (h *BucketHandler) CreateBucket(w http.ResponseWriter, r *http.Request) {
 
 	// Create bucket
 	output, err := h.bucketService.CreateBucket(ctx, service.CreateBucketInput{
-		OwnerID: userCtx.UserID,
-		Name:    bucketName,
-		Region:  region,
+		OwnerID:           userCtx.UserID,
+		Name:              bucketName,
+		Region:            region,
+		ACL:               r.Header.Get("x-amz-acl"),
+		ObjectOwnership:   domain.ObjectOwnership(r.Header.Get("x-amz-object-ownership")),
+		ObjectLockEnabled: strings.EqualFold(r.Header.Get("x-amz-bucket-object-lock-enabled"), "true"),
 	})
 
 	if err != nil {
@@ -146,6 +168,10 @@ func (h *BucketHandler) DeleteBucket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionDeleteBucket, auth.BucketARN(bucketName)) {
+		return
+	}
+
 	// Delete bucket
 	err := h.bucketService.DeleteBucket(ctx, service.DeleteBucketInput{
 		Name:    bucketName,
@@ -165,14 +191,18 @@ func (h *BucketHandler) DeleteBucket(w http.ResponseWriter, r *http.Request) {
 func (h *BucketHandler) ListBuckets(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	// Get authenticated user from context
+	// Listing all buckets is never allowed anonymously, even when anonymous
+	// access is enabled for bucket and object operations
 	userCtx, ok := auth.GetUserContext(ctx)
-	if !ok {
-		h.logger.Error().Msg("no user context found")
+	if !ok || userCtx.AuthType == auth.AuthTypeAnonymous {
 		writeError(w, ErrAccessDenied)
 		return
 	}
 
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionListAllMyBuckets, auth.AllResourcesARN) {
+		return
+	}
+
 	// List buckets
 	output, err := h.bucketService.ListBuckets(ctx, service.ListBucketsInput{
 		OwnerID: userCtx.UserID,
@@ -225,6 +255,10 @@ func (h *BucketHandler) HeadBucket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionListBucket, auth.BucketARN(bucketName)) {
+		return
+	}
+
 	// Check bucket
 	output, err := h.bucketService.HeadBucket(ctx, service.HeadBucketInput{
 		Name:    bucketName,
@@ -265,6 +299,10 @@ func (h *BucketHandler) GetBucketVersioning(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionGetBucketVersioning, auth.BucketARN(bucketName)) {
+		return
+	}
+
 	// Get versioning status
 	output, err := h.bucketService.GetBucketVersioning(ctx, service.GetBucketVersioningInput{
 		Name:    bucketName,
@@ -311,6 +349,10 @@ func (h *BucketHandler) PutBucketVersioning(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionPutBucketVersioning, auth.BucketARN(bucketName)) {
+		return
+	}
+
 	// Parse request body
 	body, err := io.ReadAll(io.LimitReader(r.Body, 1024*10)) // 10KB limit
 	if err != nil {
@@ -354,6 +396,178 @@ func (h *BucketHandler) PutBucketVersioning(w http.ResponseWriter, r *http.Reque
 	w.WriteHeader(http.StatusOK)
 }
 
+// GetBucketOwnershipControls handles GET /{bucket}?ownershipControls requests.
+func (h *BucketHandler) GetBucketOwnershipControls(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Get authenticated user from context
+	userCtx, ok := auth.GetUserContext(ctx)
+	if !ok {
+		h.logger.Error().Msg("no user context found")
+		writeError(w, ErrAccessDenied)
+		return
+	}
+
+	// Extract bucket name from path
+	bucketName := extractBucketName(r)
+	if bucketName == "" {
+		writeError(w, ErrInvalidBucketName)
+		return
+	}
+
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionGetBucketOwnershipControls, auth.BucketARN(bucketName)) {
+		return
+	}
+
+	output, err := h.bucketService.GetBucketOwnershipControls(ctx, service.GetBucketOwnershipControlsInput{
+		Name:    bucketName,
+		OwnerID: userCtx.UserID,
+	})
+
+	if err != nil {
+		h.handleError(w, err, bucketName)
+		return
+	}
+
+	writeXML(w, http.StatusOK, OwnershipControls{
+		Xmlns: "http://s3.amazonaws.com/doc/2006-03-01/",
+		Rules: []OwnershipControlsRule{{ObjectOwnership: string(output.ObjectOwnership)}},
+	})
+}
+
+// PutBucketOwnershipControls handles PUT /{bucket}?ownershipControls requests.
+func (h *BucketHandler) PutBucketOwnershipControls(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Get authenticated user from context
+	userCtx, ok := auth.GetUserContext(ctx)
+	if !ok {
+		h.logger.Error().Msg("no user context found")
+		writeError(w, ErrAccessDenied)
+		return
+	}
+
+	// Extract bucket name from path
+	bucketName := extractBucketName(r)
+	if bucketName == "" {
+		writeError(w, ErrInvalidBucketName)
+		return
+	}
+
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionPutBucketOwnershipControls, auth.BucketARN(bucketName)) {
+		return
+	}
+
+	// Parse request body
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1024*10)) // 10KB limit
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to read request body")
+		writeError(w, ErrInternalError)
+		return
+	}
+	defer r.Body.Close()
+
+	var config OwnershipControls
+	if err := xml.Unmarshal(body, &config); err != nil || len(config.Rules) != 1 {
+		writeError(w, ErrMalformedXML)
+		return
+	}
+
+	err = h.bucketService.PutBucketOwnershipControls(ctx, service.PutBucketOwnershipControlsInput{
+		Name:            bucketName,
+		OwnerID:         userCtx.UserID,
+		ObjectOwnership: domain.ObjectOwnership(config.Rules[0].ObjectOwnership),
+	})
+
+	if err != nil {
+		h.handleError(w, err, bucketName)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// GetBucketACL handles GET /{bucket}?acl requests.
+func (h *BucketHandler) GetBucketACL(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Get authenticated user from context
+	userCtx, ok := auth.GetUserContext(ctx)
+	if !ok {
+		h.logger.Error().Msg("no user context found")
+		writeError(w, ErrAccessDenied)
+		return
+	}
+
+	// Extract bucket name from path
+	bucketName := extractBucketName(r)
+	if bucketName == "" {
+		writeError(w, ErrInvalidBucketName)
+		return
+	}
+
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionGetBucketAcl, auth.BucketARN(bucketName)) {
+		return
+	}
+
+	output, err := h.bucketService.GetBucket(ctx, service.GetBucketInput{
+		Name:    bucketName,
+		OwnerID: userCtx.UserID,
+	})
+
+	if err != nil {
+		h.handleError(w, err, bucketName)
+		return
+	}
+
+	owner := Owner{ID: userCtx.Username, DisplayName: userCtx.Username}
+	writeXML(w, http.StatusOK, cannedACLPolicy(owner, output.Bucket.EffectiveACL()))
+}
+
+// PutBucketACL handles PUT /{bucket}?acl requests.
+// Only canned ACLs given in the x-amz-acl header are supported.
+func (h *BucketHandler) PutBucketACL(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Get authenticated user from context
+	userCtx, ok := auth.GetUserContext(ctx)
+	if !ok {
+		h.logger.Error().Msg("no user context found")
+		writeError(w, ErrAccessDenied)
+		return
+	}
+
+	// Extract bucket name from path
+	bucketName := extractBucketName(r)
+	if bucketName == "" {
+		writeError(w, ErrInvalidBucketName)
+		return
+	}
+
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionPutBucketAcl, auth.BucketARN(bucketName)) {
+		return
+	}
+
+	acl := r.Header.Get("x-amz-acl")
+	if acl == "" {
+		writeError(w, ErrNotImplemented)
+		return
+	}
+
+	err := h.bucketService.PutBucketACL(ctx, service.PutBucketACLInput{
+		Name:    bucketName,
+		OwnerID: userCtx.UserID,
+		ACL:     acl,
+	})
+
+	if err != nil {
+		h.handleError(w, err, bucketName)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
 // =============================================================================
 // Helper Methods
 // =============================================================================
@@ -361,7 +575,11 @@ func (h *BucketHandler) PutBucketVersioning(w http.ResponseWriter, r *http.Reque
 // extractBucketName extracts the bucket name from the request path.
 // Supports both path-style (/{bucket}) and virtual-hosted style (bucket.host.com).
 func extractBucketName(r *http.Request) string {
-	// For now, we only support path-style addressing
+	// Virtual-hosted-style requests carry the bucket resolved by the router
+	if bucketName, ok := auth.VirtualHostBucket(r.Context()); ok {
+		return bucketName
+	}
+
 	// Path format: /{bucket} or /{bucket}/{key}
 	path := strings.TrimPrefix(r.URL.Path, "/")
 	parts := strings.SplitN(path, "/", 2)
@@ -381,8 +599,12 @@ func (h *BucketHandler) handleError(w http.ResponseWriter, err error, resource s
 		s3Err = ErrNoSuchBucket
 	case errors.Is(err, domain.ErrBucketAlreadyExists):
 		s3Err = ErrBucketAlreadyExists
+	case errors.Is(err, domain.ErrBucketAlreadyOwnedByYou):
+		s3Err = ErrBucketAlreadyOwnedByYou
 	case errors.Is(err, domain.ErrBucketNotEmpty):
 		s3Err = ErrBucketNotEmpty
+	case errors.Is(err, domain.ErrBucketDeleting):
+		s3Err = ErrOperationAborted
 	case errors.Is(err, domain.ErrBucketNameLength),
 		errors.Is(err, domain.ErrBucketNameFormat),
 		errors.Is(err, domain.ErrBucketNameIPFormat):
@@ -392,6 +614,25 @@ func (h *BucketHandler) handleError(w http.ResponseWriter, err error, resource s
 		s3Err = ErrAccessDenied
 	case errors.Is(err, service.ErrInvalidVersioningStatus):
 		s3Err = ErrIllegalVersioningConfigurationException
+	case errors.Is(err, domain.ErrObjectLockNotEnabled):
+		s3Err = ErrNoSuchObjectLockConfiguration
+	case errors.Is(err, domain.ErrObjectLockVersioning):
+		s3Err = ErrObjectLockVersioning
+	case errors.Is(err, domain.ErrInvalidObjectLockConfiguration):
+		s3Err = S3Error{
+			Code:           "InvalidArgument",
+			Message:        err.Error(),
+			HTTPStatusCode: http.StatusBadRequest,
+		}
+	case errors.Is(err, service.ErrACLNotSupported):
+		s3Err = ErrAccessControlListNotSupported
+	case errors.Is(err, service.ErrInvalidObjectOwnership),
+		errors.Is(err, service.ErrInvalidBucketACL):
+		s3Err = S3Error{
+			Code:           "InvalidArgument",
+			Message:        err.Error(),
+			HTTPStatusCode: http.StatusBadRequest,
+		}
 	default:
 		h.logger.Error().Err(err).Str("resource", resource).Msg("unhandled error")
 	}
@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/lock"
+	"github.com/prn-tf/alexander-storage/internal/service"
+)
+
+func newOwnershipTestHandlers(t *testing.T) (*BucketHandler, *ObjectHandler, *stubBucketRepository) {
+	t.Helper()
+
+	buckets := &stubBucketRepository{buckets: map[string]*domain.Bucket{
+		// A public ACL left over from before ACLs were disabled must be ignored
+		"enforced": {ID: 1, Name: "enforced", OwnerID: 1, ACL: domain.ACLPublicRead, ObjectOwnership: domain.OwnershipBucketOwnerEnforced},
+		"legacy":   {ID: 2, Name: "legacy", OwnerID: 1, ACL: domain.ACLPrivate, ObjectOwnership: domain.OwnershipObjectWriter},
+	}}
+	objects := &stubObjectRepository{latest: map[string]*domain.Object{
+		"doc.txt": {ID: 1, BucketID: 1, Key: "doc.txt", IsLatest: true},
+	}}
+
+	bucketHandler := NewBucketHandler(service.NewBucketService(buckets, zerolog.Nop()), nil, zerolog.Nop())
+	objectSvc := service.NewObjectService(objects, nil, buckets, nil, lock.NewNoOpLocker(), zerolog.Nop())
+	return bucketHandler, NewObjectHandler(objectSvc, nil, zerolog.Nop()), buckets
+}
+
+func requireErrorCode(t *testing.T, rec *httptest.ResponseRecorder, status int, code string) {
+	t.Helper()
+
+	require.Equal(t, status, rec.Code)
+	var resp ErrorResponse
+	require.NoError(t, xml.Unmarshal(rec.Body.Bytes(), &resp))
+	require.Equal(t, code, resp.Code)
+}
+
+func TestBucketHandler_ACLsDisabled(t *testing.T) {
+	bucketHandler, objectHandler, buckets := newOwnershipTestHandlers(t)
+
+	tests := []struct {
+		name string
+		req  *http.Request
+		call func(http.ResponseWriter, *http.Request)
+	}{
+		{
+			name: "PutBucketAcl",
+			req:  httptest.NewRequest(http.MethodPut, "/enforced?acl", nil),
+			call: bucketHandler.PutBucketACL,
+		},
+		{
+			name: "PutObjectAcl",
+			req:  httptest.NewRequest(http.MethodPut, "/enforced/doc.txt?acl", nil),
+			call: func(w http.ResponseWriter, r *http.Request) { objectHandler.PutObjectACL(w, r, "enforced", "doc.txt") },
+		},
+		{
+			name: "PutObject with x-amz-acl",
+			req:  httptest.NewRequest(http.MethodPut, "/enforced/new.txt", strings.NewReader("data")),
+			call: func(w http.ResponseWriter, r *http.Request) { objectHandler.PutObject(w, r, "enforced", "new.txt") },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := withTestUser(tt.req)
+			req.Header.Set("x-amz-acl", "public-read")
+			rec := httptest.NewRecorder()
+
+			tt.call(rec, req)
+
+			requireErrorCode(t, rec, http.StatusBadRequest, "AccessControlListNotSupported")
+		})
+	}
+
+	require.Equal(t, domain.ACLPublicRead, buckets.buckets["enforced"].ACL)
+
+	t.Run("PutBucketAcl granting only the owner is accepted", func(t *testing.T) {
+		req := withTestUser(httptest.NewRequest(http.MethodPut, "/enforced?acl", nil))
+		req.Header.Set("x-amz-acl", "private")
+		rec := httptest.NewRecorder()
+
+		bucketHandler.PutBucketACL(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("GetBucketAcl reports owner full control only", func(t *testing.T) {
+		req := withTestUser(httptest.NewRequest(http.MethodGet, "/enforced?acl", nil))
+		rec := httptest.NewRecorder()
+
+		bucketHandler.GetBucketACL(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		var policy AccessControlPolicy
+		require.NoError(t, xml.Unmarshal(rec.Body.Bytes(), &policy))
+		require.Len(t, policy.Grants, 1)
+		require.Equal(t, "FULL_CONTROL", policy.Grants[0].Permission)
+		require.Equal(t, "tester", policy.Grants[0].Grantee.ID)
+	})
+}
+
+func TestBucketHandler_ACLsEnabled(t *testing.T) {
+	bucketHandler, objectHandler, buckets := newOwnershipTestHandlers(t)
+
+	req := withTestUser(httptest.NewRequest(http.MethodPut, "/legacy?acl", nil))
+	req.Header.Set("x-amz-acl", "public-read")
+	rec := httptest.NewRecorder()
+
+	bucketHandler.PutBucketACL(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, domain.ACLPublicRead, buckets.buckets["legacy"].ACL)
+
+	// Objects have no ACLs of their own
+	req = withTestUser(httptest.NewRequest(http.MethodPut, "/legacy/doc.txt?acl", nil))
+	req.Header.Set("x-amz-acl", "public-read")
+	rec = httptest.NewRecorder()
+
+	objectHandler.PutObjectACL(rec, req, "legacy", "doc.txt")
+
+	requireErrorCode(t, rec, http.StatusNotImplemented, "NotImplemented")
+}
+
+func TestBucketHandler_OwnershipControls(t *testing.T) {
+	bucketHandler, _, buckets := newOwnershipTestHandlers(t)
+
+	body := `<OwnershipControls><Rule><ObjectOwnership>BucketOwnerEnforced</ObjectOwnership></Rule></OwnershipControls>`
+	req := withTestUser(httptest.NewRequest(http.MethodPut, "/legacy?ownershipControls", strings.NewReader(body)))
+	rec := httptest.NewRecorder()
+
+	bucketHandler.PutBucketOwnershipControls(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, domain.OwnershipBucketOwnerEnforced, buckets.buckets["legacy"].ObjectOwnership)
+
+	req = withTestUser(httptest.NewRequest(http.MethodGet, "/legacy?ownershipControls", nil))
+	rec = httptest.NewRecorder()
+
+	bucketHandler.GetBucketOwnershipControls(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var controls OwnershipControls
+	require.NoError(t, xml.Unmarshal(rec.Body.Bytes(), &controls))
+	require.Len(t, controls.Rules, 1)
+	require.Equal(t, "BucketOwnerEnforced", controls.Rules[0].ObjectOwnership)
+
+	body = `<OwnershipControls><Rule><ObjectOwnership>Nobody</ObjectOwnership></Rule></OwnershipControls>`
+	req = withTestUser(httptest.NewRequest(http.MethodPut, "/legacy?ownershipControls", strings.NewReader(body)))
+	rec = httptest.NewRecorder()
+
+	bucketHandler.PutBucketOwnershipControls(rec, req)
+
+	requireErrorCode(t, rec, http.StatusBadRequest, "InvalidArgument")
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/rs/zerolog"
+
+	"github.com/prn-tf/alexander-storage/internal/auth"
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/service"
+)
+
+// BucketPolicyHandler handles bucket policy HTTP requests.
+type BucketPolicyHandler struct {
+	policyService *service.BucketPolicyService
+	authorizer    auth.Authorizer
+	logger        zerolog.Logger
+}
+
+// NewBucketPolicyHandler creates a new BucketPolicyHandler.
+// If authorizer is nil, the default authorizer is used.
+func NewBucketPolicyHandler(policyService *service.BucketPolicyService, authorizer auth.Authorizer, logger zerolog.Logger) *BucketPolicyHandler {
+	return &BucketPolicyHandler{
+		policyService: policyService,
+		authorizer:    defaultAuthorizer(authorizer),
+		logger:        logger.With().Str("handler", "bucket_policy").Logger(),
+	}
+}
+
+// GetBucketPolicy handles GET /{bucket}?policy requests.
+func (h *BucketPolicyHandler) GetBucketPolicy(w http.ResponseWriter, r *http.Request, bucketName string) {
+	ctx := r.Context()
+
+	// Get authenticated user from context
+	userCtx, ok := auth.GetUserContext(ctx)
+	if !ok {
+		h.logger.Error().Msg("no user context found")
+		writeError(w, ErrAccessDenied)
+		return
+	}
+
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionGetBucketPolicy, auth.BucketARN(bucketName)) {
+		return
+	}
+
+	policy, err := h.policyService.GetBucketPolicy(ctx, bucketName, userCtx.UserID)
+	if err != nil {
+		h.handleError(w, err, bucketName)
+		return
+	}
+
+	// The document is returned as stored, not as XML
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = io.WriteString(w, policy)
+}
+
+// PutBucketPolicy handles PUT /{bucket}?policy requests.
+// The policy replaces any existing one.
+func (h *BucketPolicyHandler) PutBucketPolicy(w http.ResponseWriter, r *http.Request, bucketName string) {
+	ctx := r.Context()
+
+	// Get authenticated user from context
+	userCtx, ok := auth.GetUserContext(ctx)
+	if !ok {
+		h.logger.Error().Msg("no user context found")
+		writeError(w, ErrAccessDenied)
+		return
+	}
+
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionPutBucketPolicy, auth.BucketARN(bucketName)) {
+		return
+	}
+
+	// Read one byte past the limit so oversized policies are rejected
+	document, err := io.ReadAll(io.LimitReader(r.Body, auth.MaxPolicySize+1))
+	if err != nil {
+		writeError(w, ErrIncompleteBody)
+		return
+	}
+
+	err = h.policyService.PutBucketPolicy(ctx, service.PutBucketPolicyInput{
+		BucketName: bucketName,
+		OwnerID:    userCtx.UserID,
+		Policy:     document,
+	})
+	if err != nil {
+		h.handleError(w, err, bucketName)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteBucketPolicy handles DELETE /{bucket}?policy requests.
+func (h *BucketPolicyHandler) DeleteBucketPolicy(w http.ResponseWriter, r *http.Request, bucketName string) {
+	ctx := r.Context()
+
+	// Get authenticated user from context
+	userCtx, ok := auth.GetUserContext(ctx)
+	if !ok {
+		h.logger.Error().Msg("no user context found")
+		writeError(w, ErrAccessDenied)
+		return
+	}
+
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionDeleteBucketPolicy, auth.BucketARN(bucketName)) {
+		return
+	}
+
+	if err := h.policyService.DeleteBucketPolicy(ctx, bucketName, userCtx.UserID); err != nil {
+		h.handleError(w, err, bucketName)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleError maps service errors to S3 error responses.
+func (h *BucketPolicyHandler) handleError(w http.ResponseWriter, err error, resource string) {
+	s3Err := ErrInternalError
+
+	switch {
+	case errors.Is(err, domain.ErrBucketNotFound):
+		s3Err = ErrNoSuchBucket
+	case errors.Is(err, service.ErrBucketAccessDenied):
+		s3Err = ErrAccessDenied
+	case errors.Is(err, domain.ErrNoSuchBucketPolicy):
+		s3Err = ErrNoSuchBucketPolicy
+	case errors.Is(err, auth.ErrMalformedPolicy):
+		s3Err = ErrMalformedPolicy
+		s3Err.Message = err.Error()
+	default:
+		h.logger.Error().Err(err).Str("resource", resource).Msg("unhandled error")
+	}
+
+	s3Err.Resource = resource
+	writeError(w, s3Err)
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/auth"
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/repository"
+	"github.com/prn-tf/alexander-storage/internal/service"
+)
+
+// memoryBucketPolicyRepository keeps bucket policies in memory.
+type memoryBucketPolicyRepository struct {
+	policies map[int64]*domain.BucketPolicy
+}
+
+func (r *memoryBucketPolicyRepository) Get(ctx context.Context, bucketID int64) (*domain.BucketPolicy, error) {
+	policy, ok := r.policies[bucketID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return policy, nil
+}
+
+func (r *memoryBucketPolicyRepository) Put(ctx context.Context, policy *domain.BucketPolicy) error {
+	r.policies[policy.BucketID] = policy
+	return nil
+}
+
+func (r *memoryBucketPolicyRepository) Delete(ctx context.Context, bucketID int64) error {
+	delete(r.policies, bucketID)
+	return nil
+}
+
+const publicReadGetPolicy = `{
+	"Version": "2012-10-17",
+	"Statement": [{
+		"Sid": "PublicReadGetObject",
+		"Effect": "Allow",
+		"Principal": "*",
+		"Action": "s3:GetObject",
+		"Resource": "arn:aws:s3:::uploads/*"
+	}]
+}`
+
+// newBucketPolicyTestRouter returns a router whose authorizer and auth
+// middleware evaluate the policies of the "uploads" bucket, owned by user 1.
+func newBucketPolicyTestRouter(t *testing.T) *Router {
+	t.Helper()
+
+	objectHandler, _, _ := newPutObjectTestHandler(t)
+	buckets := &stubBucketRepository{buckets: map[string]*domain.Bucket{
+		"uploads": {ID: 1, Name: "uploads", OwnerID: 1, Versioning: domain.VersioningDisabled},
+	}}
+	policySvc := service.NewBucketPolicyService(&memoryBucketPolicyRepository{policies: make(map[int64]*domain.BucketPolicy)}, buckets, zerolog.Nop())
+	policies := service.NewBucketPolicyAdapter(policySvc)
+
+	authorizer := auth.NewDefaultAuthorizer(nil)
+	authorizer.SetPolicyLookup(policies)
+	objectHandler.authorizer = authorizer
+
+	config := auth.DefaultConfig()
+	config.BucketPolicyLookup = policies
+
+	return NewRouter(RouterConfig{
+		BucketHandler:  NewBucketHandler(service.NewBucketService(buckets, zerolog.Nop()), authorizer, zerolog.Nop()),
+		ObjectHandler:  objectHandler,
+		PolicyHandler:  NewBucketPolicyHandler(policySvc, authorizer, zerolog.Nop()),
+		AuthMiddleware: auth.Middleware(nil, config),
+		Logger:         zerolog.Nop(),
+	})
+}
+
+func TestBucketPolicyHandler_PutGetDelete(t *testing.T) {
+	rt := newBucketPolicyTestRouter(t)
+	serve := func(method, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		rt.handleS3Request(rec, withTestUser(httptest.NewRequest(method, "/uploads?policy", strings.NewReader(body))))
+		return rec
+	}
+
+	requireErrorCode(t, serve(http.MethodGet, ""), http.StatusNotFound, "NoSuchBucketPolicy")
+	requireErrorCode(t, serve(http.MethodPut, `{"Statement": [`), http.StatusBadRequest, "MalformedPolicy")
+	requireErrorCode(t, serve(http.MethodPut, strings.Replace(publicReadGetPolicy, "uploads/*", "other/*", 1)), http.StatusBadRequest, "MalformedPolicy")
+
+	require.Equal(t, http.StatusNoContent, serve(http.MethodPut, publicReadGetPolicy).Code)
+	rec := serve(http.MethodGet, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, publicReadGetPolicy, rec.Body.String())
+
+	require.Equal(t, http.StatusNoContent, serve(http.MethodDelete, "").Code)
+	requireErrorCode(t, serve(http.MethodGet, ""), http.StatusNotFound, "NoSuchBucketPolicy")
+}
+
+func TestRouter_PublicReadPolicyAllowsAnonymousGet(t *testing.T) {
+	rt := newBucketPolicyTestRouter(t)
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		rt.Handler().ServeHTTP(rec, req)
+		return rec
+	}
+
+	// The owner's requests skip the middleware, which would need a signature
+	ownerPut := withTestUser(httptest.NewRequest(http.MethodPut, "/uploads/cat.txt", strings.NewReader("meow")))
+	rec := httptest.NewRecorder()
+	rt.handleS3Request(rec, ownerPut)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	// Without a policy the bucket is private
+	requireErrorCode(t, serve(httptest.NewRequest(http.MethodGet, "/uploads/cat.txt", nil)), http.StatusForbidden, "AccessDenied")
+
+	rec = httptest.NewRecorder()
+	rt.handleS3Request(rec, withTestUser(httptest.NewRequest(http.MethodPut, "/uploads?policy", strings.NewReader(publicReadGetPolicy))))
+	require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+
+	rec = serve(httptest.NewRequest(http.MethodGet, "/uploads/cat.txt", nil))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.Equal(t, "meow", rec.Body.String())
+
+	// The policy grants reads only
+	requireErrorCode(t, serve(httptest.NewRequest(http.MethodPut, "/uploads/dog.txt", strings.NewReader("woof"))), http.StatusForbidden, "AccessDenied")
+	requireErrorCode(t, serve(httptest.NewRequest(http.MethodGet, "/uploads", nil)), http.StatusForbidden, "AccessDenied")
+	requireErrorCode(t, serve(httptest.NewRequest(http.MethodGet, "/uploads?policy", nil)), http.StatusForbidden, "AccessDenied")
+}
@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/rs/zerolog"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/pkg/crypto"
+	"github.com/prn-tf/alexander-storage/internal/service"
+)
+
+// CapabilitiesPath is the URL path of the capabilities endpoint.
+const CapabilitiesPath = "/_alexander/capabilities"
+
+// CapabilitiesConfig describes the parts of an instance that depend on its
+// configuration. Supported operations and limits come from the code itself.
+type CapabilitiesConfig struct {
+	// AtRestEncryption is the scheme blobs are encrypted with on disk, or
+	// "none" when they are stored in plaintext.
+	AtRestEncryption string
+
+	// Subsystems reports which optional subsystems (tiering, cluster, ...)
+	// are enabled.
+	Subsystems map[string]bool
+
+	Logger zerolog.Logger
+}
+
+// CapabilitiesHandler serves a JSON description of the S3 features an
+// instance supports, so clients and test harnesses can adapt to it.
+type CapabilitiesHandler struct {
+	response capabilitiesResponse
+	logger   zerolog.Logger
+}
+
+// capabilitiesResponse is the body of a capabilities request.
+type capabilitiesResponse struct {
+	Operations     []string          `json:"operations"`
+	NotImplemented []string          `json:"not_implemented"`
+	Features       map[string]bool   `json:"features"`
+	Encryption     encryptionSupport `json:"encryption"`
+	Limits         capabilityLimits  `json:"limits"`
+	Subsystems     map[string]bool   `json:"subsystems"`
+}
+
+// encryptionSupport lists the supported encryption schemes.
+type encryptionSupport struct {
+	CustomerKeys []string `json:"customer_keys"`
+	AtRest       string   `json:"at_rest"`
+}
+
+// capabilityLimits are the request limits the server enforces.
+type capabilityLimits struct {
+	MaxPartSize      int64 `json:"max_part_size"`
+	MaxParts         int   `json:"max_parts"`
+	MaxUploadSize    int64 `json:"max_multipart_object_size"`
+	MaxKeys          int   `json:"max_keys"`
+	MaxDeleteObjects int   `json:"max_delete_objects"`
+	MaxObjectTags    int   `json:"max_object_tags"`
+}
+
+// s3Features are named groups of operations. A feature is supported when
+// every one of its operations is implemented.
+var s3Features = map[string][]string{
+	"versioning":    {"GetBucketVersioning", "PutBucketVersioning", "ListObjectVersions"},
+	"multipart":     {"CreateMultipartUpload", "UploadPart", "UploadPartCopy", "CompleteMultipartUpload", "AbortMultipartUpload", "ListParts", "ListMultipartUploads"},
+	"acl":           {"GetBucketAcl", "PutBucketAcl", "GetObjectAcl", "PutObjectAcl"},
+	"tagging":       {"GetObjectTagging", "PutObjectTagging", "DeleteObjectTagging"},
+	"lifecycle":     {"GetBucketLifecycleConfiguration", "PutBucketLifecycleConfiguration", "DeleteBucketLifecycle"},
+	"cors":          {"GetBucketCors", "PutBucketCors", "DeleteBucketCors"},
+	"policy":        {"GetBucketPolicy", "PutBucketPolicy", "DeleteBucketPolicy"},
+	"object_lock":   {"GetObjectLockConfiguration", "PutObjectLockConfiguration", "GetObjectRetention", "PutObjectRetention", "GetObjectLegalHold", "PutObjectLegalHold"},
+	"notifications": {"GetBucketNotificationConfiguration", "PutBucketNotificationConfiguration"},
+	"replication":   {"GetBucketReplication", "PutBucketReplication", "DeleteBucketReplication"},
+	"website":       {"GetBucketWebsite", "PutBucketWebsite", "DeleteBucketWebsite"},
+}
+
+// NewCapabilitiesHandler creates a new CapabilitiesHandler. The response is
+// built once, since nothing it describes changes while the server runs.
+func NewCapabilitiesHandler(config CapabilitiesConfig) *CapabilitiesHandler {
+	implemented := make(map[string]bool)
+	operations := append([]string(nil), baseOperations...)
+	var notImplemented []string
+	for _, op := range s3Operations {
+		for _, name := range op.Operations {
+			implemented[name] = op.Implemented
+			if op.Implemented {
+				operations = append(operations, name)
+			} else {
+				notImplemented = append(notImplemented, name)
+			}
+		}
+	}
+	sort.Strings(operations)
+	sort.Strings(notImplemented)
+
+	features := make(map[string]bool, len(s3Features))
+	for feature, names := range s3Features {
+		supported := true
+		for _, name := range names {
+			supported = supported && implemented[name]
+		}
+		features[feature] = supported
+	}
+
+	atRest := config.AtRestEncryption
+	if atRest == "" {
+		atRest = "none"
+	}
+	subsystems := config.Subsystems
+	if subsystems == nil {
+		subsystems = map[string]bool{}
+	}
+
+	return &CapabilitiesHandler{
+		response: capabilitiesResponse{
+			Operations:     operations,
+			NotImplemented: notImplemented,
+			Features:       features,
+			Encryption: encryptionSupport{
+				CustomerKeys: []string{crypto.SSECAlgorithmAES256},
+				AtRest:       atRest,
+			},
+			Limits: capabilityLimits{
+				MaxPartSize:      domain.MaxPartSize,
+				MaxParts:         domain.MaxPartNumber,
+				MaxUploadSize:    domain.MaxPartSize * domain.MaxPartNumber,
+				MaxKeys:          service.MaxListKeys,
+				MaxDeleteObjects: maxDeleteObjects,
+				MaxObjectTags:    domain.MaxObjectTags,
+			},
+			Subsystems: subsystems,
+		},
+		logger: config.Logger.With().Str("handler", "capabilities").Logger(),
+	}
+}
+
+// ServeHTTP handles capabilities requests.
+func (h *CapabilitiesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeError(w, S3Error{
+			Code:           "MethodNotAllowed",
+			Message:        "The specified method is not allowed against this resource.",
+			HTTPStatusCode: http.StatusMethodNotAllowed,
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodHead {
+		return
+	}
+	if err := json.NewEncoder(w).Encode(h.response); err != nil {
+		h.logger.Debug().Err(err).Msg("failed to write capabilities")
+	}
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+)
+
+func TestCapabilities_ReportsSupportedFeatures(t *testing.T) {
+	rt := NewRouter(RouterConfig{
+		Capabilities: NewCapabilitiesHandler(CapabilitiesConfig{
+			Subsystems: map[string]bool{"tiering": false, "cluster": true},
+			Logger:     zerolog.Nop(),
+		}),
+		AuthMiddleware: func(next http.Handler) http.Handler { return next },
+		Logger:         zerolog.Nop(),
+	})
+
+	rec := httptest.NewRecorder()
+	rt.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, CapabilitiesPath, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var caps capabilitiesResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &caps))
+
+	assert.True(t, caps.Features["versioning"])
+	assert.True(t, caps.Features["multipart"])
+	assert.True(t, caps.Features["lifecycle"])
+	assert.True(t, caps.Features["cors"])
+	assert.True(t, caps.Features["object_lock"])
+	assert.False(t, caps.Features["website"])
+	assert.Contains(t, caps.Operations, "PutBucketVersioning")
+	assert.Contains(t, caps.Operations, "CompleteMultipartUpload")
+	assert.Contains(t, caps.Operations, "PutObject")
+
+	// Every registry entry is reported on exactly one side
+	for _, op := range s3Operations {
+		for _, name := range op.Operations {
+			if op.Implemented {
+				assert.Contains(t, caps.Operations, name)
+				assert.NotContains(t, caps.NotImplemented, name)
+			} else {
+				assert.Contains(t, caps.NotImplemented, name)
+				assert.NotContains(t, caps.Operations, name)
+			}
+		}
+	}
+
+	assert.Equal(t, "none", caps.Encryption.AtRest)
+	assert.Equal(t, []string{"AES256"}, caps.Encryption.CustomerKeys)
+	assert.Equal(t, domain.MaxPartSize, caps.Limits.MaxPartSize)
+	assert.Equal(t, domain.MaxPartNumber, caps.Limits.MaxParts)
+	assert.Equal(t, map[string]bool{"tiering": false, "cluster": true}, caps.Subsystems)
+}
+
+func TestCapabilities_RejectsWrites(t *testing.T) {
+	h := NewCapabilitiesHandler(CapabilitiesConfig{Logger: zerolog.Nop()})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, CapabilitiesPath, nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"net/http"
+	"strings"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+)
+
+// defaultChecksumAlgorithm is used for trailers when the client does not
+// pick an algorithm with x-amz-checksum-algorithm.
+const defaultChecksumAlgorithm = "SHA256"
+
+// checksumTrailer computes a checksum over a response body and sends it as
+// an x-amz-checksum-* HTTP trailer once the body is written.
+type checksumTrailer struct {
+	name string
+	hash hash.Hash
+}
+
+// newChecksumTrailer returns a trailer for the requested algorithm, or nil if
+// the client did not advertise trailer support with "TE: trailers".
+// ok is false if the algorithm is not supported.
+func newChecksumTrailer(r *http.Request) (trailer *checksumTrailer, ok bool) {
+	if !acceptsTrailers(r) {
+		return nil, true
+	}
+
+	name := r.Header.Get("x-amz-checksum-algorithm")
+	if name == "" {
+		name = defaultChecksumAlgorithm
+	}
+	algorithm, ok := domain.ParseChecksumAlgorithm(name)
+	if !ok {
+		return nil, false
+	}
+
+	return &checksumTrailer{
+		name: checksumHeader(algorithm),
+		hash: algorithm.New(),
+	}, true
+}
+
+// acceptsTrailers reports whether the request's TE header lists "trailers".
+func acceptsTrailers(r *http.Request) bool {
+	for _, value := range r.Header.Values("TE") {
+		for _, token := range strings.Split(value, ",") {
+			name, _, _ := strings.Cut(token, ";")
+			if strings.EqualFold(strings.TrimSpace(name), "trailers") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// declare announces the trailer. It must be called before WriteHeader.
+// Trailers are only sent with chunked encoding, so Content-Length is dropped.
+func (c *checksumTrailer) declare(w http.ResponseWriter) {
+	w.Header().Del("Content-Length")
+	w.Header().Add("Trailer", c.name)
+}
+
+// Write adds body bytes to the checksum.
+func (c *checksumTrailer) Write(p []byte) (int, error) {
+	return c.hash.Write(p)
+}
+
+// send sets the trailer value. It must be called after the body is written.
+func (c *checksumTrailer) send(w http.ResponseWriter) {
+	w.Header().Set(c.name, base64.StdEncoding.EncodeToString(c.hash.Sum(nil)))
+}
+
+// checksumHeader returns the x-amz-checksum-* header for an algorithm.
+func checksumHeader(algorithm domain.ChecksumAlgorithm) string {
+	return "x-amz-checksum-" + strings.ToLower(string(algorithm))
+}
+
+// parseChecksumHeaders returns the additional checksum a client sent with an
+// upload: the algorithm from x-amz-sdk-checksum-algorithm or the
+// x-amz-checksum-* header present, and that header's value. Both are empty
+// without a checksum; the value is empty when only the algorithm is given.
+func parseChecksumHeaders(r *http.Request) (domain.ChecksumAlgorithm, string, error) {
+	var algorithm domain.ChecksumAlgorithm
+	var value string
+	for _, candidate := range domain.ChecksumAlgorithms {
+		v := r.Header.Get(checksumHeader(candidate))
+		if v == "" {
+			continue
+		}
+		if algorithm != "" {
+			return "", "", fmt.Errorf("%w: expecting a single x-amz-checksum- header", domain.ErrInvalidChecksum)
+		}
+		algorithm, value = candidate, v
+	}
+
+	if name := r.Header.Get("x-amz-sdk-checksum-algorithm"); name != "" {
+		declared, ok := domain.ParseChecksumAlgorithm(name)
+		if !ok {
+			return "", "", fmt.Errorf("%w: unsupported algorithm %q", domain.ErrInvalidChecksum, name)
+		}
+		if algorithm != "" && algorithm != declared {
+			return "", "", fmt.Errorf("%w: x-amz-sdk-checksum-algorithm does not match the x-amz-checksum- header", domain.ErrInvalidChecksum)
+		}
+		algorithm = declared
+	}
+
+	return algorithm, value, nil
+}
+
+// setChecksumHeader sets the x-amz-checksum-* header if there is a checksum.
+func setChecksumHeader(w http.ResponseWriter, algorithm domain.ChecksumAlgorithm, checksum string) {
+	if algorithm == "" || checksum == "" {
+		return
+	}
+	w.Header().Set(checksumHeader(algorithm), checksum)
+}
+
+// checksumModeEnabled reports whether the client asked for the stored
+// checksum with x-amz-checksum-mode: ENABLED.
+func checksumModeEnabled(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("x-amz-checksum-mode"), "ENABLED")
+}
+
+// ChecksumElements are the Checksum<Algorithm> elements of an XML response;
+// at most one is set.
+type ChecksumElements struct {
+	ChecksumCRC32  string `xml:"ChecksumCRC32,omitempty"`
+	ChecksumCRC32C string `xml:"ChecksumCRC32C,omitempty"`
+	ChecksumSHA1   string `xml:"ChecksumSHA1,omitempty"`
+	ChecksumSHA256 string `xml:"ChecksumSHA256,omitempty"`
+}
+
+// newChecksumElements returns the elements for a stored checksum.
+func newChecksumElements(algorithm domain.ChecksumAlgorithm, checksum string) ChecksumElements {
+	var elements ChecksumElements
+	switch algorithm {
+	case domain.ChecksumCRC32:
+		elements.ChecksumCRC32 = checksum
+	case domain.ChecksumCRC32C:
+		elements.ChecksumCRC32C = checksum
+	case domain.ChecksumSHA1:
+		elements.ChecksumSHA1 = checksum
+	case domain.ChecksumSHA256:
+		elements.ChecksumSHA256 = checksum
+	}
+	return elements
+}
@@ -3,8 +3,17 @@ package handler
 
 import (
 	"encoding/xml"
+	"errors"
 	"net/http"
+	"net/url"
 	"time"
+	"unicode/utf8"
+
+	"github.com/rs/zerolog"
+
+	"github.com/prn-tf/alexander-storage/internal/auth"
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/middleware"
 )
 
 // Common S3 XML response types
@@ -15,6 +24,61 @@ type Owner struct {
 	DisplayName string `xml:"DisplayName"`
 }
 
+// AccessControlPolicy is the response for GetBucketAcl and GetObjectAcl.
+type AccessControlPolicy struct {
+	XMLName xml.Name `xml:"AccessControlPolicy"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	Owner   Owner    `xml:"Owner"`
+	Grants  []Grant  `xml:"AccessControlList>Grant"`
+}
+
+// Grant is a permission given to a grantee in an access control list.
+type Grant struct {
+	Grantee    Grantee `xml:"Grantee"`
+	Permission string  `xml:"Permission"`
+}
+
+// Grantee is the receiver of a grant: a canonical user or a group.
+type Grantee struct {
+	XmlnsXsi    string `xml:"xmlns:xsi,attr"`
+	Type        string `xml:"xsi:type,attr"`
+	ID          string `xml:"ID,omitempty"`
+	DisplayName string `xml:"DisplayName,omitempty"`
+	URI         string `xml:"URI,omitempty"`
+}
+
+// allUsersGroupURI identifies anonymous users in access control lists.
+const allUsersGroupURI = "http://acs.amazonaws.com/groups/global/AllUsers"
+
+// cannedACLPolicy renders a canned ACL as an access control policy: the owner
+// has full control, and public ACLs grant anonymous users read or write.
+func cannedACLPolicy(owner Owner, acl domain.BucketACL) AccessControlPolicy {
+	const xsi = "http://www.w3.org/2001/XMLSchema-instance"
+
+	grants := []Grant{{
+		Grantee:    Grantee{XmlnsXsi: xsi, Type: "CanonicalUser", ID: owner.ID, DisplayName: owner.DisplayName},
+		Permission: "FULL_CONTROL",
+	}}
+	if acl.AllowsAnonymousRead() {
+		grants = append(grants, Grant{
+			Grantee:    Grantee{XmlnsXsi: xsi, Type: "Group", URI: allUsersGroupURI},
+			Permission: "READ",
+		})
+	}
+	if acl.AllowsAnonymousWrite() {
+		grants = append(grants, Grant{
+			Grantee:    Grantee{XmlnsXsi: xsi, Type: "Group", URI: allUsersGroupURI},
+			Permission: "WRITE",
+		})
+	}
+
+	return AccessControlPolicy{
+		Xmlns:  "http://s3.amazonaws.com/doc/2006-03-01/",
+		Owner:  owner,
+		Grants: grants,
+	}
+}
+
 // writeXML writes an XML response with the given status code.
 func writeXML(w http.ResponseWriter, statusCode int, v interface{}) {
 	w.Header().Set("Content-Type", "application/xml")
@@ -26,6 +90,7 @@ func writeXML(w http.ResponseWriter, statusCode int, v interface{}) {
 
 // writeError writes an S3-compatible error response.
 func writeError(w http.ResponseWriter, err S3Error) {
+	middleware.SetErrorCode(w, err.Code)
 	writeXML(w, err.HTTPStatusCode, ErrorResponse{
 		Code:      err.Code,
 		Message:   err.Message,
@@ -34,6 +99,106 @@ func writeError(w http.ResponseWriter, err S3Error) {
 	})
 }
 
+// authorize asks the authorizer whether principal may perform action on
+// resource. If not, it writes the error response and returns false.
+func authorize(w http.ResponseWriter, r *http.Request, authorizer auth.Authorizer, logger zerolog.Logger, principal *auth.AuthContext, action auth.Action, resource string) bool {
+	err := authorizer.Authorize(r.Context(), principal, action, resource)
+	if err == nil {
+		return true
+	}
+	if errors.Is(err, auth.ErrAccessDenied) {
+		writeError(w, ErrAccessDenied)
+		return false
+	}
+	logger.Error().Err(err).Str("action", string(action)).Str("resource", resource).Msg("failed to authorize request")
+	writeError(w, ErrInternalError)
+	return false
+}
+
+// defaultAuthorizer returns authorizer, or the default authorizer if it is nil.
+func defaultAuthorizer(authorizer auth.Authorizer) auth.Authorizer {
+	if authorizer == nil {
+		return auth.NewDefaultAuthorizer(nil)
+	}
+	return authorizer
+}
+
+// encodingTypeURL is the only encoding-type accepted by the list APIs.
+const encodingTypeURL = "url"
+
+// parseEncodingType validates the encoding-type parameter of a list request.
+// If it is invalid, it writes the error response and returns false.
+func parseEncodingType(w http.ResponseWriter, r *http.Request) (string, bool) {
+	encodingType := r.URL.Query().Get("encoding-type")
+	if encodingType != "" && encodingType != encodingTypeURL {
+		writeError(w, S3Error{
+			Code:           "InvalidArgument",
+			Message:        "Invalid Encoding Method specified in Request",
+			HTTPStatusCode: http.StatusBadRequest,
+		})
+		return "", false
+	}
+	return encodingType, true
+}
+
+// listEncoder encodes the keys and prefixes of a list response. Values are
+// URL-encoded when the client asked for encoding-type=url, or when any value
+// cannot be carried verbatim in XML 1.0; in that case the response reports
+// EncodingType url so that clients decode them.
+type listEncoder struct {
+	url bool
+}
+
+// newListEncoder creates a listEncoder for the requested encoding type.
+func newListEncoder(encodingType string) *listEncoder {
+	return &listEncoder{url: encodingType == encodingTypeURL}
+}
+
+// check switches the encoder to URL encoding if any value is not XML-safe.
+func (e *listEncoder) check(values ...string) {
+	for _, v := range values {
+		if !e.url && !isXMLSafe(v) {
+			e.url = true
+		}
+	}
+}
+
+// encode returns v as it must appear in the response.
+func (e *listEncoder) encode(v string) string {
+	if !e.url {
+		return v
+	}
+	return url.QueryEscape(v)
+}
+
+// encodingType returns the EncodingType to report in the response.
+func (e *listEncoder) encodingType() string {
+	if e.url {
+		return encodingTypeURL
+	}
+	return ""
+}
+
+// isXMLSafe reports whether s survives an XML 1.0 round trip unchanged: it must
+// be valid UTF-8 and contain only characters allowed in XML documents. Carriage
+// returns are rejected too, as parsers normalize them to line feeds.
+func isXMLSafe(s string) bool {
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			return false
+		}
+		if !(r == '\t' || r == '\n' ||
+			(r >= 0x20 && r <= 0xD7FF) ||
+			(r >= 0xE000 && r <= 0xFFFD) ||
+			(r >= 0x10000 && r <= utf8.MaxRune)) {
+			return false
+		}
+		i += size
+	}
+	return true
+}
+
 // ErrorResponse is the S3-compatible error response format.
 type ErrorResponse struct {
 	XMLName   xml.Name `xml:"Error"`
@@ -78,6 +243,18 @@ var (
 		HTTPStatusCode: http.StatusConflict,
 	}
 
+	ErrOperationAborted = S3Error{
+		Code:           "OperationAborted",
+		Message:        "A conflicting conditional operation is currently in progress against this resource. Please try again.",
+		HTTPStatusCode: http.StatusConflict,
+	}
+
+	ErrPreconditionFailed = S3Error{
+		Code:           "PreconditionFailed",
+		Message:        "At least one of the preconditions you specified did not hold.",
+		HTTPStatusCode: http.StatusPreconditionFailed,
+	}
+
 	ErrNoSuchBucket = S3Error{
 		Code:           "NoSuchBucket",
 		Message:        "The specified bucket does not exist.",
@@ -96,6 +273,36 @@ var (
 		HTTPStatusCode: http.StatusInternalServerError,
 	}
 
+	ErrNoSuchLifecycleConfiguration = S3Error{
+		Code:           "NoSuchLifecycleConfiguration",
+		Message:        "The lifecycle configuration does not exist.",
+		HTTPStatusCode: http.StatusNotFound,
+	}
+
+	ErrNoSuchBucketPolicy = S3Error{
+		Code:           "NoSuchBucketPolicy",
+		Message:        "The bucket policy does not exist.",
+		HTTPStatusCode: http.StatusNotFound,
+	}
+
+	ErrMalformedPolicy = S3Error{
+		Code:           "MalformedPolicy",
+		Message:        "Policies must be valid JSON and the first byte must be '{'.",
+		HTTPStatusCode: http.StatusBadRequest,
+	}
+
+	ErrNoSuchCORSConfiguration = S3Error{
+		Code:           "NoSuchCORSConfiguration",
+		Message:        "The CORS configuration does not exist.",
+		HTTPStatusCode: http.StatusNotFound,
+	}
+
+	ErrCORSForbidden = S3Error{
+		Code:           "AccessForbidden",
+		Message:        "CORSResponse: This CORS request is not allowed.",
+		HTTPStatusCode: http.StatusForbidden,
+	}
+
 	ErrMalformedXML = S3Error{
 		Code:           "MalformedXML",
 		Message:        "The XML you provided was not well-formed or did not validate against our published schema.",
@@ -107,9 +314,209 @@ var (
 		Message:        "The versioning configuration specified in the request is invalid.",
 		HTTPStatusCode: http.StatusBadRequest,
 	}
+
+	ErrAccessControlListNotSupported = S3Error{
+		Code:           "AccessControlListNotSupported",
+		Message:        "The bucket does not allow ACLs.",
+		HTTPStatusCode: http.StatusBadRequest,
+	}
+
+	ErrMetadataTooLarge = S3Error{
+		Code:           "MetadataTooLarge",
+		Message:        "Your metadata headers exceed the maximum allowed metadata size.",
+		HTTPStatusCode: http.StatusBadRequest,
+	}
+
+	ErrInvalidObjectExpiry = S3Error{
+		Code:           "InvalidArgument",
+		Message:        "x-amz-expires-at must be a future RFC 3339 or HTTP date.",
+		HTTPStatusCode: http.StatusBadRequest,
+	}
+
+	ErrInvalidChecksumAlgorithm = S3Error{
+		Code:           "InvalidArgument",
+		Message:        "x-amz-checksum-algorithm must be CRC32, CRC32C, SHA1 or SHA256.",
+		HTTPStatusCode: http.StatusBadRequest,
+	}
+
+	ErrInvalidTag = S3Error{
+		Code:           "InvalidTag",
+		Message:        "The tag provided was not a valid tag.",
+		HTTPStatusCode: http.StatusBadRequest,
+	}
+
+	ErrTooManyTags = S3Error{
+		Code:           "BadRequest",
+		Message:        "Object tags cannot be greater than 10.",
+		HTTPStatusCode: http.StatusBadRequest,
+	}
+
+	ErrObjectLocked = S3Error{
+		Code:           "AccessDenied",
+		Message:        "Access Denied because object protected by object lock.",
+		HTTPStatusCode: http.StatusForbidden,
+	}
+
+	ErrObjectLockNotEnabled = S3Error{
+		Code:           "InvalidRequest",
+		Message:        "Bucket is missing Object Lock Configuration.",
+		HTTPStatusCode: http.StatusBadRequest,
+	}
+
+	ErrNoSuchObjectLockConfiguration = S3Error{
+		Code:           "ObjectLockConfigurationNotFoundError",
+		Message:        "Object Lock configuration does not exist for this bucket.",
+		HTTPStatusCode: http.StatusNotFound,
+	}
+
+	ErrNoSuchObjectRetention = S3Error{
+		Code:           "NoSuchObjectLockConfiguration",
+		Message:        "The specified object does not have a retention configuration.",
+		HTTPStatusCode: http.StatusNotFound,
+	}
+
+	ErrObjectLockVersioning = S3Error{
+		Code:           "InvalidBucketState",
+		Message:        "Object Lock requires versioning to be enabled on the bucket, and keeps it enabled.",
+		HTTPStatusCode: http.StatusConflict,
+	}
+
+	ErrInvalidCopySource = S3Error{
+		Code:           "InvalidArgument",
+		Message:        "Invalid x-amz-copy-source header.",
+		HTTPStatusCode: http.StatusBadRequest,
+	}
+
+	ErrInvalidCopySourceRange = S3Error{
+		Code:           "InvalidArgument",
+		Message:        "The x-amz-copy-source-range value must be of the form bytes=first-last.",
+		HTTPStatusCode: http.StatusBadRequest,
+	}
+
+	ErrInvalidRange = S3Error{
+		Code:           "InvalidRange",
+		Message:        "The requested range is not satisfiable.",
+		HTTPStatusCode: http.StatusRequestedRangeNotSatisfiable,
+	}
+
+	ErrInvalidContinuationToken = S3Error{
+		Code:           "InvalidArgument",
+		Message:        "The continuation token provided is incorrect.",
+		HTTPStatusCode: http.StatusBadRequest,
+	}
+
+	ErrIncompleteBody = S3Error{
+		Code:           "IncompleteBody",
+		Message:        "You did not provide the number of bytes specified by the Content-Length HTTP header.",
+		HTTPStatusCode: http.StatusBadRequest,
+	}
+
+	ErrBadDigest = S3Error{
+		Code:           "BadDigest",
+		Message:        "The Content-MD5 you specified did not match what was received.",
+		HTTPStatusCode: http.StatusBadRequest,
+	}
+
+	ErrChecksumMismatch = S3Error{
+		Code:           "BadDigest",
+		Message:        "The checksum you specified did not match the calculated checksum.",
+		HTTPStatusCode: http.StatusBadRequest,
+	}
+
+	ErrInvalidDigest = S3Error{
+		Code:           "InvalidDigest",
+		Message:        "The Content-MD5 you specified is not valid.",
+		HTTPStatusCode: http.StatusBadRequest,
+	}
+
+	ErrContentTypeNotAllowed = S3Error{
+		Code:           "InvalidArgument",
+		Message:        "The content type is not allowed in this bucket.",
+		HTTPStatusCode: http.StatusBadRequest,
+	}
+
+	ErrEncryptionRequired = S3Error{
+		Code:           "AccessDenied",
+		Message:        "The bucket requires server-side encryption; send an SSE-C customer key.",
+		HTTPStatusCode: http.StatusForbidden,
+	}
+
+	ErrInvalidEncryptionAlgorithm = S3Error{
+		Code:           "InvalidEncryptionAlgorithmError",
+		Message:        "The encryption request you specified is not valid. The valid value is AES256.",
+		HTTPStatusCode: http.StatusBadRequest,
+	}
+
+	ErrInvalidSSECustomerKey = S3Error{
+		Code:           "InvalidArgument",
+		Message:        "The secret key was invalid for the specified algorithm.",
+		HTTPStatusCode: http.StatusBadRequest,
+	}
+
+	ErrSSECustomerKeyMD5Mismatch = S3Error{
+		Code:           "InvalidArgument",
+		Message:        "The calculated MD5 hash of the key did not match the hash that was provided.",
+		HTTPStatusCode: http.StatusBadRequest,
+	}
+
+	ErrSSECustomerKeyRequired = S3Error{
+		Code:           "InvalidArgument",
+		Message:        "The object was stored using a form of Server Side Encryption. The correct parameters must be provided to retrieve the object.",
+		HTTPStatusCode: http.StatusBadRequest,
+	}
+
+	ErrSSECustomerKeyNotExpected = S3Error{
+		Code:           "InvalidArgument",
+		Message:        "The encryption parameters are not applicable to this object.",
+		HTTPStatusCode: http.StatusBadRequest,
+	}
+
+	ErrSSECustomerKeyMismatch = S3Error{
+		Code:           "AccessDenied",
+		Message:        "The provided customer key does not match the key the object was stored with.",
+		HTTPStatusCode: http.StatusForbidden,
+	}
+
+	ErrServerSideEncryptionNotImplemented = S3Error{
+		Code:           "NotImplemented",
+		Message:        "Server-side encryption with server-managed keys is not supported; use customer-provided keys (SSE-C).",
+		HTTPStatusCode: http.StatusNotImplemented,
+	}
+
+	ErrSSECustomerMultipartNotImplemented = S3Error{
+		Code:           "NotImplemented",
+		Message:        "Multipart uploads with customer-provided encryption keys are not supported.",
+		HTTPStatusCode: http.StatusNotImplemented,
+	}
+
+	ErrSlowDown = S3Error{
+		Code:           "SlowDown",
+		Message:        "Please reduce your request rate.",
+		HTTPStatusCode: http.StatusServiceUnavailable,
+	}
+
+	ErrInsufficientStorage = S3Error{
+		Code:           "InsufficientStorage",
+		Message:        "The server does not have enough storage space left to save the request.",
+		HTTPStatusCode: http.StatusInsufficientStorage,
+	}
+
+	ErrNotImplemented = S3Error{
+		Code:           "NotImplemented",
+		Message:        "A header you provided implies functionality that is not implemented.",
+		HTTPStatusCode: http.StatusNotImplemented,
+	}
 )
 
 // formatS3Time formats a time in S3's expected format.
 func formatS3Time(t time.Time) string {
 	return t.UTC().Format(time.RFC3339)
 }
+
+// setVersionIDHeader sets x-amz-version-id when the service reported a version.
+// Objects in suspended buckets report the literal "null" version.
+func setVersionIDHeader(w http.ResponseWriter, versionID string) {
+	if versionID != "" {
+		w.Header().Set("x-amz-version-id", versionID)
+	}
+}
@@ -0,0 +1,274 @@
+package handler
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog"
+
+	"github.com/prn-tf/alexander-storage/internal/auth"
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/service"
+)
+
+// maxCORSBodySize bounds the PutBucketCors request body, which S3 limits to 64KB.
+const maxCORSBodySize = 64 * 1024
+
+// CORSHandler handles bucket CORS configuration HTTP requests and answers
+// cross-origin requests against buckets.
+type CORSHandler struct {
+	corsService *service.CORSService
+	authorizer  auth.Authorizer
+	logger      zerolog.Logger
+}
+
+// NewCORSHandler creates a new CORSHandler.
+// If authorizer is nil, the default authorizer is used.
+func NewCORSHandler(corsService *service.CORSService, authorizer auth.Authorizer, logger zerolog.Logger) *CORSHandler {
+	return &CORSHandler{
+		corsService: corsService,
+		authorizer:  defaultAuthorizer(authorizer),
+		logger:      logger.With().Str("handler", "cors").Logger(),
+	}
+}
+
+// =============================================================================
+// XML Types
+// =============================================================================
+
+// CORSConfiguration is the request/response for bucket CORS configuration.
+type CORSConfiguration struct {
+	XMLName xml.Name   `xml:"CORSConfiguration"`
+	Xmlns   string     `xml:"xmlns,attr,omitempty"`
+	Rules   []CORSRule `xml:"CORSRule"`
+}
+
+// CORSRule is a single rule of a CORS configuration.
+type CORSRule struct {
+	ID             string   `xml:"ID,omitempty"`
+	AllowedOrigins []string `xml:"AllowedOrigin"`
+	AllowedMethods []string `xml:"AllowedMethod"`
+	AllowedHeaders []string `xml:"AllowedHeader,omitempty"`
+	ExposeHeaders  []string `xml:"ExposeHeader,omitempty"`
+	MaxAgeSeconds  int      `xml:"MaxAgeSeconds,omitempty"`
+}
+
+// =============================================================================
+// Handler Methods
+// =============================================================================
+
+// GetBucketCORS handles GET /{bucket}?cors requests.
+func (h *CORSHandler) GetBucketCORS(w http.ResponseWriter, r *http.Request, bucketName string) {
+	ctx := r.Context()
+
+	// Get authenticated user from context
+	userCtx, ok := auth.GetUserContext(ctx)
+	if !ok {
+		h.logger.Error().Msg("no user context found")
+		writeError(w, ErrAccessDenied)
+		return
+	}
+
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionGetBucketCORS, auth.BucketARN(bucketName)) {
+		return
+	}
+
+	rules, err := h.corsService.GetBucketCORS(ctx, bucketName, userCtx.UserID)
+	if err != nil {
+		h.handleError(w, err, bucketName)
+		return
+	}
+
+	response := CORSConfiguration{
+		Xmlns: "http://s3.amazonaws.com/doc/2006-03-01/",
+		Rules: make([]CORSRule, len(rules)),
+	}
+	for i, rule := range rules {
+		response.Rules[i] = CORSRule(rule)
+	}
+
+	writeXML(w, http.StatusOK, response)
+}
+
+// PutBucketCORS handles PUT /{bucket}?cors requests.
+// The configuration replaces any existing one.
+func (h *CORSHandler) PutBucketCORS(w http.ResponseWriter, r *http.Request, bucketName string) {
+	ctx := r.Context()
+
+	// Get authenticated user from context
+	userCtx, ok := auth.GetUserContext(ctx)
+	if !ok {
+		h.logger.Error().Msg("no user context found")
+		writeError(w, ErrAccessDenied)
+		return
+	}
+
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionPutBucketCORS, auth.BucketARN(bucketName)) {
+		return
+	}
+
+	// Parse request body
+	var config CORSConfiguration
+	if err := xml.NewDecoder(io.LimitReader(r.Body, maxCORSBodySize)).Decode(&config); err != nil {
+		writeError(w, ErrMalformedXML)
+		return
+	}
+
+	rules := make([]domain.CORSRule, len(config.Rules))
+	for i, rule := range config.Rules {
+		rules[i] = domain.CORSRule(rule)
+	}
+
+	err := h.corsService.PutBucketCORS(ctx, service.PutBucketCORSInput{
+		BucketName: bucketName,
+		OwnerID:    userCtx.UserID,
+		Rules:      rules,
+	})
+	if err != nil {
+		h.handleError(w, err, bucketName)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// DeleteBucketCORS handles DELETE /{bucket}?cors requests.
+func (h *CORSHandler) DeleteBucketCORS(w http.ResponseWriter, r *http.Request, bucketName string) {
+	ctx := r.Context()
+
+	// Get authenticated user from context
+	userCtx, ok := auth.GetUserContext(ctx)
+	if !ok {
+		h.logger.Error().Msg("no user context found")
+		writeError(w, ErrAccessDenied)
+		return
+	}
+
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionPutBucketCORS, auth.BucketARN(bucketName)) {
+		return
+	}
+
+	if err := h.corsService.DeleteBucketCORS(ctx, bucketName, userCtx.UserID); err != nil {
+		h.handleError(w, err, bucketName)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Preflight answers an OPTIONS preflight request against a bucket. It
+// returns 403 unless a CORS rule of the bucket allows the origin, method
+// and headers asked for.
+func (h *CORSHandler) Preflight(w http.ResponseWriter, r *http.Request, bucketName string) {
+	origin := r.Header.Get("Origin")
+	method := r.Header.Get("Access-Control-Request-Method")
+	if origin == "" || method == "" {
+		writeError(w, S3Error{
+			Code:           "BadRequest",
+			Message:        "Insufficient information. Origin request header needed.",
+			HTTPStatusCode: http.StatusBadRequest,
+		})
+		return
+	}
+	headers := requestedHeaders(r.Header.Get("Access-Control-Request-Headers"))
+
+	rules, err := h.corsService.GetBucketCORS(r.Context(), bucketName, 0)
+	if err != nil && !errors.Is(err, domain.ErrNoSuchCORSConfiguration) {
+		h.handleError(w, err, bucketName)
+		return
+	}
+
+	rule := domain.MatchCORSRule(rules, origin, method, headers)
+	if rule == nil {
+		s3Err := ErrCORSForbidden
+		s3Err.Resource = bucketName
+		writeError(w, s3Err)
+		return
+	}
+
+	setCORSHeaders(w.Header(), rule, origin)
+	w.Header().Set("Access-Control-Allow-Methods", strings.Join(rule.AllowedMethods, ", "))
+	if len(headers) > 0 {
+		w.Header().Set("Access-Control-Allow-Headers", strings.Join(headers, ", "))
+	}
+	if rule.MaxAgeSeconds > 0 {
+		w.Header().Set("Access-Control-Max-Age", strconv.Itoa(rule.MaxAgeSeconds))
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// AddResponseHeaders adds the CORS headers of the rule matching an actual
+// cross-origin request to its response. Requests no rule allows get no CORS
+// headers, so browsers hide the response from the calling script.
+func (h *CORSHandler) AddResponseHeaders(w http.ResponseWriter, r *http.Request, bucketName string) {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return
+	}
+
+	rules, err := h.corsService.GetBucketCORS(r.Context(), bucketName, 0)
+	if err != nil {
+		if !errors.Is(err, domain.ErrNoSuchCORSConfiguration) && !errors.Is(err, domain.ErrBucketNotFound) {
+			h.logger.Error().Err(err).Str("bucket", bucketName).Msg("failed to load CORS configuration")
+		}
+		return
+	}
+
+	if rule := domain.MatchCORSRule(rules, origin, r.Method, nil); rule != nil {
+		setCORSHeaders(w.Header(), rule, origin)
+	}
+}
+
+// =============================================================================
+// Helper Methods
+// =============================================================================
+
+// setCORSHeaders sets the headers shared by preflight and actual responses.
+func setCORSHeaders(header http.Header, rule *domain.CORSRule, origin string) {
+	header.Set("Access-Control-Allow-Origin", origin)
+	if len(rule.ExposeHeaders) > 0 {
+		header.Set("Access-Control-Expose-Headers", strings.Join(rule.ExposeHeaders, ", "))
+	}
+	// The response depends on the origin, so shared caches must key on it
+	header.Add("Vary", "Origin")
+}
+
+// requestedHeaders splits an Access-Control-Request-Headers value.
+func requestedHeaders(value string) []string {
+	var headers []string
+	for _, header := range strings.Split(value, ",") {
+		if header = strings.TrimSpace(header); header != "" {
+			headers = append(headers, header)
+		}
+	}
+	return headers
+}
+
+// handleError maps service errors to S3 error responses.
+func (h *CORSHandler) handleError(w http.ResponseWriter, err error, resource string) {
+	s3Err := ErrInternalError
+
+	switch {
+	case errors.Is(err, domain.ErrBucketNotFound):
+		s3Err = ErrNoSuchBucket
+	case errors.Is(err, service.ErrBucketAccessDenied):
+		s3Err = ErrAccessDenied
+	case errors.Is(err, domain.ErrNoSuchCORSConfiguration):
+		s3Err = ErrNoSuchCORSConfiguration
+	case errors.Is(err, domain.ErrInvalidCORSConfiguration):
+		s3Err = S3Error{
+			Code:           "InvalidRequest",
+			Message:        err.Error(),
+			HTTPStatusCode: http.StatusBadRequest,
+		}
+	default:
+		h.logger.Error().Err(err).Str("resource", resource).Msg("unhandled error")
+	}
+
+	s3Err.Resource = resource
+	writeError(w, s3Err)
+}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/auth"
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/repository"
+	"github.com/prn-tf/alexander-storage/internal/service"
+)
+
+// memoryBucketCORSRepository keeps bucket CORS configurations in memory.
+type memoryBucketCORSRepository struct {
+	configs map[int64]*domain.BucketCORS
+}
+
+func (r *memoryBucketCORSRepository) Get(ctx context.Context, bucketID int64) (*domain.BucketCORS, error) {
+	cors, ok := r.configs[bucketID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return cors, nil
+}
+
+func (r *memoryBucketCORSRepository) Put(ctx context.Context, cors *domain.BucketCORS) error {
+	r.configs[cors.BucketID] = cors
+	return nil
+}
+
+func (r *memoryBucketCORSRepository) Delete(ctx context.Context, bucketID int64) error {
+	delete(r.configs, bucketID)
+	return nil
+}
+
+const testCORSConfiguration = `<CORSConfiguration>
+	<CORSRule>
+		<AllowedOrigin>https://*.example.com</AllowedOrigin>
+		<AllowedMethod>GET</AllowedMethod>
+		<AllowedMethod>PUT</AllowedMethod>
+		<AllowedHeader>Content-*</AllowedHeader>
+		<ExposeHeader>ETag</ExposeHeader>
+		<MaxAgeSeconds>600</MaxAgeSeconds>
+	</CORSRule>
+</CORSConfiguration>`
+
+// newCORSTestRouter returns a router serving the CORS configuration of the
+// "uploads" bucket, owned by user 1.
+func newCORSTestRouter(t *testing.T) *Router {
+	t.Helper()
+
+	objectHandler, _, _ := newPutObjectTestHandler(t)
+	buckets := &stubBucketRepository{buckets: map[string]*domain.Bucket{
+		"uploads": {ID: 1, Name: "uploads", OwnerID: 1, Versioning: domain.VersioningDisabled},
+	}}
+	corsSvc := service.NewCORSService(&memoryBucketCORSRepository{configs: make(map[int64]*domain.BucketCORS)}, buckets, zerolog.Nop())
+
+	return NewRouter(RouterConfig{
+		BucketHandler:  NewBucketHandler(service.NewBucketService(buckets, zerolog.Nop()), nil, zerolog.Nop()),
+		ObjectHandler:  objectHandler,
+		CORSHandler:    NewCORSHandler(corsSvc, nil, zerolog.Nop()),
+		AuthMiddleware: auth.Middleware(nil, auth.DefaultConfig()),
+		Logger:         zerolog.Nop(),
+	})
+}
+
+func TestCORSHandler_PutGetDelete(t *testing.T) {
+	rt := newCORSTestRouter(t)
+	serve := func(method, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		rt.handleS3Request(rec, withTestUser(httptest.NewRequest(method, "/uploads?cors", strings.NewReader(body))))
+		return rec
+	}
+
+	requireErrorCode(t, serve(http.MethodGet, ""), http.StatusNotFound, "NoSuchCORSConfiguration")
+	requireErrorCode(t, serve(http.MethodPut, "<CORSConfiguration>"), http.StatusBadRequest, "MalformedXML")
+	requireErrorCode(t, serve(http.MethodPut, strings.Replace(testCORSConfiguration, "PUT", "PATCH", 1)), http.StatusBadRequest, "InvalidRequest")
+
+	require.Equal(t, http.StatusOK, serve(http.MethodPut, testCORSConfiguration).Code)
+	rec := serve(http.MethodGet, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), "<AllowedOrigin>https://*.example.com</AllowedOrigin>")
+	require.Contains(t, rec.Body.String(), "<MaxAgeSeconds>600</MaxAgeSeconds>")
+
+	require.Equal(t, http.StatusNoContent, serve(http.MethodDelete, "").Code)
+	requireErrorCode(t, serve(http.MethodGet, ""), http.StatusNotFound, "NoSuchCORSConfiguration")
+}
+
+func TestRouter_CORSPreflight(t *testing.T) {
+	rt := newCORSTestRouter(t)
+	preflight := func(origin, method, headers string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodOptions, "/uploads/cat.txt", nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", method)
+		if headers != "" {
+			req.Header.Set("Access-Control-Request-Headers", headers)
+		}
+		rec := httptest.NewRecorder()
+		rt.Handler().ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Without a configuration every cross-origin request is refused
+	requireErrorCode(t, preflight("https://app.example.com", http.MethodGet, ""), http.StatusForbidden, "AccessForbidden")
+
+	rec := httptest.NewRecorder()
+	rt.handleS3Request(rec, withTestUser(httptest.NewRequest(http.MethodPut, "/uploads?cors", strings.NewReader(testCORSConfiguration))))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rec = preflight("https://app.example.com", http.MethodPut, "content-type")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.Equal(t, "https://app.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	require.Equal(t, "GET, PUT", rec.Header().Get("Access-Control-Allow-Methods"))
+	require.Equal(t, "content-type", rec.Header().Get("Access-Control-Allow-Headers"))
+	require.Equal(t, "ETag", rec.Header().Get("Access-Control-Expose-Headers"))
+	require.Equal(t, "600", rec.Header().Get("Access-Control-Max-Age"))
+	require.Equal(t, "Origin", rec.Header().Get("Vary"))
+
+	requireErrorCode(t, preflight("https://evil.test", http.MethodGet, ""), http.StatusForbidden, "AccessForbidden")
+	requireErrorCode(t, preflight("https://app.example.com", http.MethodDelete, ""), http.StatusForbidden, "AccessForbidden")
+	requireErrorCode(t, preflight("https://app.example.com", http.MethodGet, "x-amz-acl"), http.StatusForbidden, "AccessForbidden")
+}
+
+func TestRouter_CORSHeadersOnActualRequests(t *testing.T) {
+	rt := newCORSTestRouter(t)
+
+	rec := httptest.NewRecorder()
+	rt.handleS3Request(rec, withTestUser(httptest.NewRequest(http.MethodPut, "/uploads?cors", strings.NewReader(testCORSConfiguration))))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	// Unsigned requests are still denied, but browsers may read the error
+	req := httptest.NewRequest(http.MethodGet, "/uploads/cat.txt", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	rec = httptest.NewRecorder()
+	rt.Handler().ServeHTTP(rec, req)
+	requireErrorCode(t, rec, http.StatusForbidden, "AccessDenied")
+	require.Equal(t, "https://app.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	require.Equal(t, "ETag", rec.Header().Get("Access-Control-Expose-Headers"))
+
+	req = httptest.NewRequest(http.MethodGet, "/uploads/cat.txt", nil)
+	req.Header.Set("Origin", "https://evil.test")
+	rec = httptest.NewRecorder()
+	rt.Handler().ServeHTTP(rec, req)
+	require.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+}
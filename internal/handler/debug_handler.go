@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/rs/zerolog"
+
+	"github.com/prn-tf/alexander-storage/internal/auth"
+)
+
+// SigningDebugPathPrefix is the URL prefix of the request signing debug endpoint.
+// A client sends a signed request to this prefix followed by the path it
+// signed, e.g. /_alexander/debug/signature/bucket/key, and gets back the
+// canonical request and string to sign the server computed for that path.
+const SigningDebugPathPrefix = "/_alexander/debug/signature"
+
+// SigningDebugHandler echoes the server side of the SigV4 computation to help
+// troubleshoot SignatureDoesNotMatch. It is meant for development only and
+// never looks up or returns secret keys.
+type SigningDebugHandler struct {
+	logger zerolog.Logger
+}
+
+// NewSigningDebugHandler creates a new SigningDebugHandler.
+func NewSigningDebugHandler(logger zerolog.Logger) *SigningDebugHandler {
+	return &SigningDebugHandler{
+		logger: logger.With().Str("handler", "signing-debug").Logger(),
+	}
+}
+
+// ServeHTTP handles signing debug requests.
+func (h *SigningDebugHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Describe the request as it would have been sent to the signed path
+	signed := r.Clone(r.Context())
+	signed.URL.Path = strings.TrimPrefix(r.URL.Path, SigningDebugPathPrefix)
+	signed.URL.RawPath = ""
+	if signed.URL.Path == "" {
+		signed.URL.Path = "/"
+	}
+
+	info, err := auth.DescribeSignature(signed)
+	if err != nil {
+		h.logger.Debug().Err(err).Str("path", signed.URL.Path).Msg("failed to describe request signature")
+		writeError(w, S3Error{
+			Code:           "AuthorizationHeaderMalformed",
+			Message:        "The request is not signed with AWS Signature Version 4: " + err.Error(),
+			HTTPStatusCode: http.StatusBadRequest,
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(info)
+}
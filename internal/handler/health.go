@@ -10,6 +10,7 @@ import (
 
 	"github.com/rs/zerolog"
 
+	"github.com/prn-tf/alexander-storage/internal/delta"
 	"github.com/prn-tf/alexander-storage/internal/storage"
 )
 
@@ -17,6 +18,7 @@ import (
 type HealthChecker struct {
 	dbChecker      DatabaseChecker
 	storageBackend storage.Backend
+	deltaMonitor   *delta.Monitor
 	logger         zerolog.Logger
 
 	// Cached status for efficiency
@@ -35,8 +37,12 @@ type DatabaseChecker interface {
 type HealthCheckerConfig struct {
 	DatabaseChecker DatabaseChecker
 	StorageBackend  storage.Backend
-	Logger          zerolog.Logger
-	CacheTTL        time.Duration
+
+	// DeltaMonitor reports the delta/CDC subsystem. Nil if delta storage is disabled.
+	DeltaMonitor *delta.Monitor
+
+	Logger   zerolog.Logger
+	CacheTTL time.Duration
 }
 
 // NewHealthChecker creates a new health checker.
@@ -49,6 +55,7 @@ func NewHealthChecker(config HealthCheckerConfig) *HealthChecker {
 	return &HealthChecker{
 		dbChecker:      config.DatabaseChecker,
 		storageBackend: config.StorageBackend,
+		deltaMonitor:   config.DeltaMonitor,
 		logger:         config.Logger.With().Str("handler", "health").Logger(),
 		cacheTTL:       cacheTTL,
 	}
@@ -170,6 +177,11 @@ func (h *HealthChecker) checkComponents(ctx context.Context) *HealthStatus {
 	storageStatus := h.checkStorage(ctx)
 	status.Components["storage"] = storageStatus
 
+	// Check delta storage, if enabled
+	if h.deltaMonitor != nil {
+		status.Components["delta"] = h.checkDelta()
+	}
+
 	// Determine overall status
 	for _, comp := range status.Components {
 		if comp.Status == StatusUnhealthy {
@@ -253,6 +265,33 @@ func (h *HealthChecker) checkStorage(ctx context.Context) *ComponentStatus {
 	}
 }
 
+// checkDelta reports delta/CDC statistics. Failed chunk reconstruction
+// degrades the status, as reads of delta-encoded blobs may be failing.
+func (h *HealthChecker) checkDelta() *ComponentStatus {
+	stats := h.deltaMonitor.Stats()
+
+	details := map[string]interface{}{
+		"chunked_bytes":           stats.ChunkedBytes,
+		"average_chunk_size":      stats.AverageChunkSize(),
+		"dedup_hit_rate":          stats.DedupHitRate(),
+		"last_savings_ratio":      stats.LastSavingsRatio,
+		"reconstruction_failures": stats.ReconstructionFailures,
+	}
+
+	if stats.LastReconstructionError != "" {
+		return &ComponentStatus{
+			Status:  StatusDegraded,
+			Error:   "delta reconstruction failed: " + stats.LastReconstructionError,
+			Details: details,
+		}
+	}
+
+	return &ComponentStatus{
+		Status:  StatusHealthy,
+		Details: details,
+	}
+}
+
 // SimpleHealth returns a simple JSON health response.
 // Used as a lightweight endpoint.
 func SimpleHealth(w http.ResponseWriter, r *http.Request) {
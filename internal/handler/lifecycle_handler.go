@@ -0,0 +1,308 @@
+package handler
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+
+	"github.com/prn-tf/alexander-storage/internal/auth"
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/service"
+)
+
+// maxLifecycleBodySize bounds the PutBucketLifecycleConfiguration request
+// body: 1000 rules with a prefix of up to 1KB each, plus markup.
+const maxLifecycleBodySize = 2 * 1024 * 1024
+
+// lifecycleDateFormat is the format of expiration dates in lifecycle rules.
+const lifecycleDateFormat = "2006-01-02T15:04:05.000Z"
+
+// LifecycleHandler handles bucket lifecycle configuration HTTP requests.
+type LifecycleHandler struct {
+	lifecycleService *service.LifecycleService
+	authorizer       auth.Authorizer
+	logger           zerolog.Logger
+}
+
+// NewLifecycleHandler creates a new LifecycleHandler.
+// If authorizer is nil, the default authorizer is used.
+func NewLifecycleHandler(lifecycleService *service.LifecycleService, authorizer auth.Authorizer, logger zerolog.Logger) *LifecycleHandler {
+	return &LifecycleHandler{
+		lifecycleService: lifecycleService,
+		authorizer:       defaultAuthorizer(authorizer),
+		logger:           logger.With().Str("handler", "lifecycle").Logger(),
+	}
+}
+
+// =============================================================================
+// XML Types
+// =============================================================================
+
+// LifecycleConfiguration is the request/response for bucket lifecycle configuration.
+type LifecycleConfiguration struct {
+	XMLName xml.Name        `xml:"LifecycleConfiguration"`
+	Xmlns   string          `xml:"xmlns,attr,omitempty"`
+	Rules   []LifecycleRule `xml:"Rule"`
+}
+
+// LifecycleRule is a single rule of a lifecycle configuration.
+type LifecycleRule struct {
+	ID          string                `xml:"ID,omitempty"`
+	Filter      *LifecycleFilter      `xml:"Filter,omitempty"`
+	Prefix      *string               `xml:"Prefix,omitempty"` // Deprecated form of Filter.Prefix
+	Status      string                `xml:"Status"`
+	Expiration  *LifecycleExpiration  `xml:"Expiration,omitempty"`
+	Transitions []LifecycleTransition `xml:"Transition,omitempty"`
+}
+
+// LifecycleFilter selects the objects a rule applies to.
+type LifecycleFilter struct {
+	Prefix string `xml:"Prefix"`
+}
+
+// LifecycleExpiration is when objects matching a rule expire.
+type LifecycleExpiration struct {
+	Days int    `xml:"Days,omitempty"`
+	Date string `xml:"Date,omitempty"`
+}
+
+// LifecycleTransition is when objects matching a rule move to a storage tier.
+// StorageClass is WARM or COLD.
+type LifecycleTransition struct {
+	Days         int    `xml:"Days,omitempty"`
+	Date         string `xml:"Date,omitempty"`
+	StorageClass string `xml:"StorageClass"`
+}
+
+// =============================================================================
+// Handler Methods
+// =============================================================================
+
+// GetBucketLifecycleConfiguration handles GET /{bucket}?lifecycle requests.
+func (h *LifecycleHandler) GetBucketLifecycleConfiguration(w http.ResponseWriter, r *http.Request, bucketName string) {
+	ctx := r.Context()
+
+	// Get authenticated user from context
+	userCtx, ok := auth.GetUserContext(ctx)
+	if !ok {
+		h.logger.Error().Msg("no user context found")
+		writeError(w, ErrAccessDenied)
+		return
+	}
+
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionGetLifecycleConfiguration, auth.BucketARN(bucketName)) {
+		return
+	}
+
+	rules, err := h.lifecycleService.GetBucketLifecycle(ctx, bucketName, userCtx.UserID)
+	if err != nil {
+		h.handleError(w, err, bucketName)
+		return
+	}
+
+	response := LifecycleConfiguration{
+		Xmlns: "http://s3.amazonaws.com/doc/2006-03-01/",
+		Rules: make([]LifecycleRule, len(rules)),
+	}
+	for i, rule := range rules {
+		response.Rules[i] = LifecycleRule{
+			ID:     rule.RuleID,
+			Filter: &LifecycleFilter{Prefix: rule.Prefix},
+			Status: string(rule.Status),
+		}
+		if rule.HasExpiration() {
+			expiration := &LifecycleExpiration{}
+			if rule.ExpirationDate != nil {
+				expiration.Date = rule.ExpirationDate.UTC().Format(lifecycleDateFormat)
+			} else {
+				expiration.Days = *rule.ExpirationDays
+			}
+			response.Rules[i].Expiration = expiration
+		}
+		if rule.HasTransition() {
+			transition := LifecycleTransition{StorageClass: string(rule.TransitionStorageClass)}
+			if rule.TransitionDate != nil {
+				transition.Date = rule.TransitionDate.UTC().Format(lifecycleDateFormat)
+			} else {
+				transition.Days = *rule.TransitionDays
+			}
+			response.Rules[i].Transitions = []LifecycleTransition{transition}
+		}
+	}
+
+	writeXML(w, http.StatusOK, response)
+}
+
+// PutBucketLifecycleConfiguration handles PUT /{bucket}?lifecycle requests.
+// The configuration replaces any existing one.
+func (h *LifecycleHandler) PutBucketLifecycleConfiguration(w http.ResponseWriter, r *http.Request, bucketName string) {
+	ctx := r.Context()
+
+	// Get authenticated user from context
+	userCtx, ok := auth.GetUserContext(ctx)
+	if !ok {
+		h.logger.Error().Msg("no user context found")
+		writeError(w, ErrAccessDenied)
+		return
+	}
+
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionPutLifecycleConfiguration, auth.BucketARN(bucketName)) {
+		return
+	}
+
+	// Parse request body
+	var config LifecycleConfiguration
+	if err := xml.NewDecoder(io.LimitReader(r.Body, maxLifecycleBodySize)).Decode(&config); err != nil {
+		writeError(w, ErrMalformedXML)
+		return
+	}
+	if len(config.Rules) == 0 {
+		writeError(w, ErrMalformedXML)
+		return
+	}
+
+	rules := make([]*domain.LifecycleRule, len(config.Rules))
+	for i, rule := range config.Rules {
+		parsed, ok := parseLifecycleRule(rule)
+		if !ok {
+			writeError(w, ErrMalformedXML)
+			return
+		}
+		rules[i] = parsed
+	}
+
+	err := h.lifecycleService.PutBucketLifecycle(ctx, service.PutBucketLifecycleInput{
+		BucketName: bucketName,
+		OwnerID:    userCtx.UserID,
+		Rules:      rules,
+	})
+	if err != nil {
+		h.handleError(w, err, bucketName)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// DeleteBucketLifecycle handles DELETE /{bucket}?lifecycle requests.
+func (h *LifecycleHandler) DeleteBucketLifecycle(w http.ResponseWriter, r *http.Request, bucketName string) {
+	ctx := r.Context()
+
+	// Get authenticated user from context
+	userCtx, ok := auth.GetUserContext(ctx)
+	if !ok {
+		h.logger.Error().Msg("no user context found")
+		writeError(w, ErrAccessDenied)
+		return
+	}
+
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionPutLifecycleConfiguration, auth.BucketARN(bucketName)) {
+		return
+	}
+
+	if err := h.lifecycleService.DeleteBucketLifecycle(ctx, bucketName, userCtx.UserID); err != nil {
+		h.handleError(w, err, bucketName)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// =============================================================================
+// Helper Methods
+// =============================================================================
+
+// parseLifecycleRule converts a rule from the request into a domain rule.
+// Rules without an ID are given a random one, as S3 does. ok is false when
+// the rule is structurally invalid; its values are validated by the service.
+func parseLifecycleRule(rule LifecycleRule) (*domain.LifecycleRule, bool) {
+	// The prefix is either in the filter or, in the deprecated form, in the rule
+	if rule.Filter != nil && rule.Prefix != nil {
+		return nil, false
+	}
+	// A rule has an expiration, a transition or both, and a single transition
+	if len(rule.Transitions) > 1 || (rule.Expiration == nil && len(rule.Transitions) == 0) {
+		return nil, false
+	}
+
+	id := rule.ID
+	if id == "" {
+		id = uuid.NewString()
+	}
+	parsed := domain.NewLifecycleRule(0, id)
+	parsed.Status = domain.LifecycleStatus(rule.Status)
+	switch {
+	case rule.Filter != nil:
+		parsed.Prefix = rule.Filter.Prefix
+	case rule.Prefix != nil:
+		parsed.Prefix = *rule.Prefix
+	}
+
+	var ok bool
+	if rule.Expiration != nil {
+		parsed.ExpirationDays, parsed.ExpirationDate, ok = parseLifecycleTrigger(rule.Expiration.Days, rule.Expiration.Date)
+		if !ok {
+			return nil, false
+		}
+	}
+	if len(rule.Transitions) == 1 {
+		transition := rule.Transitions[0]
+		parsed.TransitionDays, parsed.TransitionDate, ok = parseLifecycleTrigger(transition.Days, transition.Date)
+		if !ok {
+			return nil, false
+		}
+		parsed.TransitionStorageClass = domain.TransitionClass(transition.StorageClass)
+	}
+
+	return parsed, true
+}
+
+// parseLifecycleTrigger converts the Days or Date of an expiration or
+// transition. ok is false when the date is not a valid timestamp.
+func parseLifecycleTrigger(days int, date string) (*int, *time.Time, bool) {
+	var parsedDays *int
+	var parsedDate *time.Time
+	if date != "" {
+		t, err := time.Parse(time.RFC3339, date)
+		if err != nil {
+			return nil, nil, false
+		}
+		t = t.UTC()
+		parsedDate = &t
+	}
+	if days != 0 {
+		parsedDays = &days
+	}
+	return parsedDays, parsedDate, true
+}
+
+// handleError maps service errors to S3 error responses.
+func (h *LifecycleHandler) handleError(w http.ResponseWriter, err error, resource string) {
+	s3Err := ErrInternalError
+
+	switch {
+	case errors.Is(err, domain.ErrBucketNotFound):
+		s3Err = ErrNoSuchBucket
+	case errors.Is(err, service.ErrBucketAccessDenied):
+		s3Err = ErrAccessDenied
+	case errors.Is(err, service.ErrNoSuchLifecycleConfig):
+		s3Err = ErrNoSuchLifecycleConfiguration
+	case errors.Is(err, service.ErrInvalidLifecycleRule),
+		errors.Is(err, service.ErrTooManyLifecycleRules):
+		s3Err = S3Error{
+			Code:           "InvalidArgument",
+			Message:        err.Error(),
+			HTTPStatusCode: http.StatusBadRequest,
+		}
+	default:
+		h.logger.Error().Err(err).Str("resource", resource).Msg("unhandled error")
+	}
+
+	s3Err.Resource = resource
+	writeError(w, s3Err)
+}
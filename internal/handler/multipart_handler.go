@@ -4,8 +4,11 @@ package handler
 import (
 	"encoding/xml"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/rs/zerolog"
 
@@ -17,13 +20,16 @@ import (
 // MultipartHandler handles multipart upload HTTP requests.
 type MultipartHandler struct {
 	multipartService *service.MultipartService
+	authorizer       auth.Authorizer
 	logger           zerolog.Logger
 }
 
 // NewMultipartHandler creates a new MultipartHandler.
-func NewMultipartHandler(multipartService *service.MultipartService, logger zerolog.Logger) *MultipartHandler {
+// If authorizer is nil, the default authorizer is used.
+func NewMultipartHandler(multipartService *service.MultipartService, authorizer auth.Authorizer, logger zerolog.Logger) *MultipartHandler {
 	return &MultipartHandler{
 		multipartService: multipartService,
+		authorizer:       defaultAuthorizer(authorizer),
 		logger:           logger.With().Str("handler", "multipart").Logger(),
 	}
 }
@@ -49,6 +55,7 @@ type CompleteMultipartUploadResult struct {
 	Bucket   string   `xml:"Bucket"`
 	Key      string   `xml:"Key"`
 	ETag     string   `xml:"ETag"`
+	ChecksumElements
 }
 
 // CompleteMultipartUploadRequest is the request body for CompleteMultipartUpload.
@@ -57,6 +64,10 @@ type CompleteMultipartUploadRequest struct {
 	Parts   []CompletedPartRequest `xml:"Part"`
 }
 
+// maxCompleteMultipartBodySize bounds the CompleteMultipartUpload request
+// body: 10000 parts with checksums, plus markup.
+const maxCompleteMultipartBodySize = 4 * 1024 * 1024
+
 // CompletedPartRequest represents a part in the completion request.
 type CompletedPartRequest struct {
 	PartNumber int    `xml:"PartNumber"`
@@ -78,6 +89,15 @@ type ListMultipartUploadsResult struct {
 	IsTruncated        bool            `xml:"IsTruncated"`
 	Uploads            []UploadElement `xml:"Upload,omitempty"`
 	CommonPrefixes     []CommonPrefix  `xml:"CommonPrefixes,omitempty"`
+	EncodingType       string          `xml:"EncodingType,omitempty"`
+}
+
+// CopyPartResult is the response for UploadPartCopy.
+type CopyPartResult struct {
+	XMLName      xml.Name `xml:"CopyPartResult"`
+	Xmlns        string   `xml:"xmlns,attr"`
+	LastModified string   `xml:"LastModified"`
+	ETag         string   `xml:"ETag"`
 }
 
 // UploadElement represents an upload in list uploads response.
@@ -127,6 +147,16 @@ func (h *MultipartHandler) InitiateMultipartUpload(w http.ResponseWriter, r *htt
 		return
 	}
 
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionPutObject, auth.ObjectARN(bucketName, objectKey)) {
+		return
+	}
+
+	// Parts are stored unencrypted, so SSE-C uploads must not be accepted
+	if hasSSECustomerHeaders(r) {
+		writeError(w, ErrSSECustomerMultipartNotImplemented)
+		return
+	}
+
 	// Get content type and metadata
 	contentType := r.Header.Get("Content-Type")
 	metadata := parseMetadata(r)
@@ -140,14 +170,36 @@ func (h *MultipartHandler) InitiateMultipartUpload(w http.ResponseWriter, r *htt
 		storageClass = domain.StorageClassStandard
 	}
 
+	// Reject requests for encryption with server-managed keys
+	if s3Err, ok := checkServerSideEncryption(r); !ok {
+		writeError(w, s3Err)
+		return
+	}
+
+	// Get the checksum algorithm of the parts, if any
+	var checksumAlgorithm domain.ChecksumAlgorithm
+	if name := r.Header.Get("x-amz-checksum-algorithm"); name != "" {
+		var ok bool
+		if checksumAlgorithm, ok = domain.ParseChecksumAlgorithm(name); !ok {
+			writeError(w, ErrInvalidChecksumAlgorithm)
+			return
+		}
+	}
+
 	// Initiate upload
 	output, err := h.multipartService.InitiateMultipartUpload(ctx, service.InitiateMultipartUploadInput{
-		BucketName:   bucketName,
-		Key:          objectKey,
-		ContentType:  contentType,
-		Metadata:     metadata,
-		StorageClass: storageClass,
-		OwnerID:      userCtx.UserID,
+		BucketName:         bucketName,
+		Key:                objectKey,
+		ContentType:        contentType,
+		Metadata:           metadata,
+		StorageClass:       storageClass,
+		CacheControl:       r.Header.Get("Cache-Control"),
+		ContentDisposition: r.Header.Get("Content-Disposition"),
+		ContentEncoding:    r.Header.Get("Content-Encoding"),
+		Expires:            r.Header.Get("Expires"),
+		ACL:                r.Header.Get("x-amz-acl"),
+		OwnerID:            userCtx.UserID,
+		ChecksumAlgorithm:  checksumAlgorithm,
 	})
 
 	if err != nil {
@@ -155,6 +207,10 @@ func (h *MultipartHandler) InitiateMultipartUpload(w http.ResponseWriter, r *htt
 		return
 	}
 
+	if checksumAlgorithm != "" {
+		w.Header().Set("x-amz-checksum-algorithm", string(checksumAlgorithm))
+	}
+
 	// Return XML response
 	response := InitiateMultipartUploadResult{
 		Xmlns:    "http://s3.amazonaws.com/doc/2006-03-01/",
@@ -178,28 +234,17 @@ func (h *MultipartHandler) UploadPart(w http.ResponseWriter, r *http.Request, bu
 		return
 	}
 
-	query := r.URL.Query()
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionPutObject, auth.ObjectARN(bucketName, objectKey)) {
+		return
+	}
 
-	// Get upload ID
-	uploadID := query.Get("uploadId")
-	if uploadID == "" {
-		writeError(w, S3Error{
-			Code:           "InvalidArgument",
-			Message:        "Missing uploadId parameter.",
-			HTTPStatusCode: http.StatusBadRequest,
-		})
+	if hasSSECustomerHeaders(r) {
+		writeError(w, ErrSSECustomerMultipartNotImplemented)
 		return
 	}
 
-	// Get part number
-	partNumberStr := query.Get("partNumber")
-	partNumber, err := strconv.Atoi(partNumberStr)
-	if err != nil || partNumber < 1 || partNumber > 10000 {
-		writeError(w, S3Error{
-			Code:           "InvalidArgument",
-			Message:        "Part number must be an integer between 1 and 10000.",
-			HTTPStatusCode: http.StatusBadRequest,
-		})
+	uploadID, partNumber, ok := parsePartParams(w, r)
+	if !ok {
 		return
 	}
 
@@ -214,15 +259,24 @@ func (h *MultipartHandler) UploadPart(w http.ResponseWriter, r *http.Request, bu
 		return
 	}
 
+	// Parse the optional additional checksum (x-amz-checksum-*)
+	checksumAlgorithm, checksum, err := parseChecksumHeaders(r)
+	if err != nil {
+		h.handleMultipartError(w, err, bucketName, objectKey)
+		return
+	}
+
 	// Upload part
 	output, err := h.multipartService.UploadPart(ctx, service.UploadPartInput{
-		BucketName: bucketName,
-		Key:        objectKey,
-		UploadID:   uploadID,
-		PartNumber: partNumber,
-		Body:       r.Body,
-		Size:       contentLength,
-		OwnerID:    userCtx.UserID,
+		BucketName:        bucketName,
+		Key:               objectKey,
+		UploadID:          uploadID,
+		PartNumber:        partNumber,
+		Body:              r.Body,
+		Size:              contentLength,
+		OwnerID:           userCtx.UserID,
+		ChecksumAlgorithm: checksumAlgorithm,
+		Checksum:          checksum,
 	})
 
 	if err != nil {
@@ -232,9 +286,89 @@ func (h *MultipartHandler) UploadPart(w http.ResponseWriter, r *http.Request, bu
 
 	// Set ETag header
 	w.Header().Set("ETag", output.ETag)
+	setChecksumHeader(w, output.ChecksumAlgorithm, output.Checksum)
 	w.WriteHeader(http.StatusOK)
 }
 
+// UploadPartCopy handles PUT /{bucket}/{key}?partNumber=N&uploadId=X requests
+// with an x-amz-copy-source header.
+func (h *MultipartHandler) UploadPartCopy(w http.ResponseWriter, r *http.Request, bucketName, objectKey string) {
+	ctx := r.Context()
+
+	// Get authenticated user from context
+	userCtx, ok := auth.GetUserContext(ctx)
+	if !ok {
+		h.logger.Error().Msg("no user context found")
+		writeError(w, ErrAccessDenied)
+		return
+	}
+
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionPutObject, auth.ObjectARN(bucketName, objectKey)) {
+		return
+	}
+
+	if hasSSECustomerHeaders(r) {
+		writeError(w, ErrSSECustomerMultipartNotImplemented)
+		return
+	}
+
+	uploadID, partNumber, ok := parsePartParams(w, r)
+	if !ok {
+		return
+	}
+
+	sourceBucket, sourceKey, sourceVersionID, ok := parseCopySource(r.Header.Get("x-amz-copy-source"))
+	if !ok {
+		writeError(w, ErrInvalidCopySource)
+		return
+	}
+
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionGetObject, auth.ObjectARN(sourceBucket, sourceKey)) {
+		return
+	}
+
+	// Parse the optional source byte range
+	var sourceRange *service.ByteRange
+	if value := r.Header.Get("x-amz-copy-source-range"); value != "" {
+		var err error
+		sourceRange, err = parseCopySourceRange(value)
+		if err != nil {
+			writeError(w, ErrInvalidCopySourceRange)
+			return
+		}
+	}
+
+	output, err := h.multipartService.UploadPartCopy(ctx, service.UploadPartCopyInput{
+		BucketName:      bucketName,
+		Key:             objectKey,
+		UploadID:        uploadID,
+		PartNumber:      partNumber,
+		SourceBucket:    sourceBucket,
+		SourceKey:       sourceKey,
+		SourceVersionID: sourceVersionID,
+		SourceRange:     sourceRange,
+		Conditions:      parseCopySourceConditions(r),
+		OwnerID:         userCtx.UserID,
+	})
+
+	if err != nil {
+		h.handleMultipartError(w, err, bucketName, objectKey)
+		return
+	}
+
+	if output.SourceVersionID != "" {
+		w.Header().Set("x-amz-copy-source-version-id", output.SourceVersionID)
+	}
+
+	response := CopyPartResult{
+		Xmlns:        "http://s3.amazonaws.com/doc/2006-03-01/",
+		LastModified: formatS3Time(output.LastModified),
+		ETag:         output.ETag,
+	}
+
+	writeXML(w, http.StatusOK, response)
+}
+
 // CompleteMultipartUpload handles POST /{bucket}/{key}?uploadId=X requests.
 func (h *MultipartHandler) CompleteMultipartUpload(w http.ResponseWriter, r *http.Request, bucketName, objectKey string) {
 	ctx := r.Context()
@@ -247,6 +381,10 @@ func (h *MultipartHandler) CompleteMultipartUpload(w http.ResponseWriter, r *htt
 		return
 	}
 
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionPutObject, auth.ObjectARN(bucketName, objectKey)) {
+		return
+	}
+
 	// Get upload ID
 	uploadID := r.URL.Query().Get("uploadId")
 	if uploadID == "" {
@@ -260,7 +398,7 @@ func (h *MultipartHandler) CompleteMultipartUpload(w http.ResponseWriter, r *htt
 
 	// Parse request body
 	var req CompleteMultipartUploadRequest
-	if err := xml.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := xml.NewDecoder(io.LimitReader(r.Body, maxCompleteMultipartBodySize)).Decode(&req); err != nil {
 		writeError(w, S3Error{
 			Code:           "MalformedXML",
 			Message:        "The XML you provided was not well-formed.",
@@ -293,17 +431,16 @@ func (h *MultipartHandler) CompleteMultipartUpload(w http.ResponseWriter, r *htt
 	}
 
 	// Set version ID header if applicable
-	if output.VersionID != "" && output.VersionID != "null" {
-		w.Header().Set("x-amz-version-id", output.VersionID)
-	}
+	setVersionIDHeader(w, output.VersionID)
 
 	// Return XML response
 	response := CompleteMultipartUploadResult{
-		Xmlns:    "http://s3.amazonaws.com/doc/2006-03-01/",
-		Location: output.Location,
-		Bucket:   output.Bucket,
-		Key:      output.Key,
-		ETag:     output.ETag,
+		Xmlns:            "http://s3.amazonaws.com/doc/2006-03-01/",
+		Location:         output.Location,
+		Bucket:           output.Bucket,
+		Key:              output.Key,
+		ETag:             output.ETag,
+		ChecksumElements: newChecksumElements(output.ChecksumAlgorithm, output.Checksum),
 	}
 
 	writeXML(w, http.StatusOK, response)
@@ -321,6 +458,10 @@ func (h *MultipartHandler) AbortMultipartUpload(w http.ResponseWriter, r *http.R
 		return
 	}
 
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionAbortMultipartUpload, auth.ObjectARN(bucketName, objectKey)) {
+		return
+	}
+
 	// Get upload ID
 	uploadID := r.URL.Query().Get("uploadId")
 	if uploadID == "" {
@@ -362,6 +503,22 @@ func (h *MultipartHandler) ListMultipartUploads(w http.ResponseWriter, r *http.R
 
 	query := r.URL.Query()
 
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionListBucketMultipartUploads, auth.BucketARN(bucketName)) {
+		return
+	}
+
+	encodingType, ok := parseEncodingType(w, r)
+	if !ok {
+		return
+	}
+
+	// Confine the listing to the access key's namespace, if any
+	prefix, ok := userCtx.ScopeListPrefix(bucketName, query.Get("prefix"))
+	if !ok {
+		writeError(w, ErrAccessDenied)
+		return
+	}
+
 	// Parse parameters
 	maxUploads, _ := strconv.Atoi(query.Get("max-uploads"))
 	if maxUploads <= 0 {
@@ -371,7 +528,7 @@ func (h *MultipartHandler) ListMultipartUploads(w http.ResponseWriter, r *http.R
 	// List uploads
 	output, err := h.multipartService.ListMultipartUploads(ctx, service.ListMultipartUploadsInput{
 		BucketName:     bucketName,
-		Prefix:         query.Get("prefix"),
+		Prefix:         prefix,
 		Delimiter:      query.Get("delimiter"),
 		KeyMarker:      query.Get("key-marker"),
 		UploadIDMarker: query.Get("upload-id-marker"),
@@ -385,10 +542,17 @@ func (h *MultipartHandler) ListMultipartUploads(w http.ResponseWriter, r *http.R
 	}
 
 	// Build response
+	enc := newListEncoder(encodingType)
+	enc.check(output.Prefix, output.Delimiter, output.KeyMarker, output.NextKeyMarker)
+	for _, u := range output.Uploads {
+		enc.check(u.Key)
+	}
+	enc.check(output.CommonPrefixes...)
+
 	uploads := make([]UploadElement, len(output.Uploads))
 	for i, u := range output.Uploads {
 		uploads[i] = UploadElement{
-			Key:          u.Key,
+			Key:          enc.encode(u.Key),
 			UploadId:     u.UploadID,
 			Initiated:    formatS3Time(u.Initiated),
 			StorageClass: string(u.StorageClass),
@@ -397,22 +561,23 @@ func (h *MultipartHandler) ListMultipartUploads(w http.ResponseWriter, r *http.R
 
 	commonPrefixes := make([]CommonPrefix, len(output.CommonPrefixes))
 	for i, prefix := range output.CommonPrefixes {
-		commonPrefixes[i] = CommonPrefix{Prefix: prefix}
+		commonPrefixes[i] = CommonPrefix{Prefix: enc.encode(prefix)}
 	}
 
 	response := ListMultipartUploadsResult{
 		Xmlns:              "http://s3.amazonaws.com/doc/2006-03-01/",
 		Bucket:             output.Bucket,
-		KeyMarker:          output.KeyMarker,
+		KeyMarker:          enc.encode(output.KeyMarker),
 		UploadIdMarker:     output.UploadIDMarker,
-		NextKeyMarker:      output.NextKeyMarker,
+		NextKeyMarker:      enc.encode(output.NextKeyMarker),
 		NextUploadIdMarker: output.NextUploadIDMarker,
-		Prefix:             output.Prefix,
-		Delimiter:          output.Delimiter,
+		Prefix:             enc.encode(output.Prefix),
+		Delimiter:          enc.encode(output.Delimiter),
 		MaxUploads:         output.MaxUploads,
 		IsTruncated:        output.IsTruncated,
 		Uploads:            uploads,
 		CommonPrefixes:     commonPrefixes,
+		EncodingType:       enc.encodingType(),
 	}
 
 	writeXML(w, http.StatusOK, response)
@@ -430,6 +595,10 @@ func (h *MultipartHandler) ListParts(w http.ResponseWriter, r *http.Request, buc
 		return
 	}
 
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionListMultipartUploadParts, auth.ObjectARN(bucketName, objectKey)) {
+		return
+	}
+
 	query := r.URL.Query()
 
 	// Get upload ID
@@ -496,6 +665,62 @@ func (h *MultipartHandler) ListParts(w http.ResponseWriter, r *http.Request, buc
 // Helper Methods
 // =============================================================================
 
+// parsePartParams reads the uploadId and partNumber query parameters of a
+// part upload, writing an error response if either is invalid.
+func parsePartParams(w http.ResponseWriter, r *http.Request) (string, int, bool) {
+	query := r.URL.Query()
+
+	// Get upload ID
+	uploadID := query.Get("uploadId")
+	if uploadID == "" {
+		writeError(w, S3Error{
+			Code:           "InvalidArgument",
+			Message:        "Missing uploadId parameter.",
+			HTTPStatusCode: http.StatusBadRequest,
+		})
+		return "", 0, false
+	}
+
+	// Get part number
+	partNumberStr := query.Get("partNumber")
+	partNumber, err := strconv.Atoi(partNumberStr)
+	if err != nil || partNumber < 1 || partNumber > 10000 {
+		writeError(w, S3Error{
+			Code:           "InvalidArgument",
+			Message:        "Part number must be an integer between 1 and 10000.",
+			HTTPStatusCode: http.StatusBadRequest,
+		})
+		return "", 0, false
+	}
+
+	return uploadID, partNumber, true
+}
+
+// parseCopySourceRange parses an x-amz-copy-source-range value. Unlike the
+// Range header, both offsets are required: bytes=first-last.
+func parseCopySourceRange(value string) (*service.ByteRange, error) {
+	spec, ok := strings.CutPrefix(value, "bytes=")
+	if !ok {
+		return nil, fmt.Errorf("invalid copy source range format")
+	}
+
+	firstStr, lastStr, ok := strings.Cut(spec, "-")
+	if !ok {
+		return nil, fmt.Errorf("invalid copy source range format")
+	}
+
+	first, err := strconv.ParseInt(firstStr, 10, 64)
+	if err != nil || first < 0 {
+		return nil, fmt.Errorf("invalid copy source range start")
+	}
+	last, err := strconv.ParseInt(lastStr, 10, 64)
+	if err != nil || last < first {
+		return nil, fmt.Errorf("invalid copy source range end")
+	}
+
+	return &service.ByteRange{Start: first, End: last}, nil
+}
+
 // handleMultipartError maps service errors to S3 error responses.
 func (h *MultipartHandler) handleMultipartError(w http.ResponseWriter, err error, bucket, key string) {
 	var s3Err S3Error
@@ -507,6 +732,22 @@ func (h *MultipartHandler) handleMultipartError(w http.ResponseWriter, err error
 	switch {
 	case errors.Is(err, domain.ErrBucketNotFound):
 		s3Err = ErrNoSuchBucket
+	case errors.Is(err, domain.ErrBucketDeleting):
+		s3Err = ErrOperationAborted
+	case errors.Is(err, domain.ErrContentTypeNotAllowed):
+		s3Err = ErrContentTypeNotAllowed
+	case errors.Is(err, domain.ErrEncryptionRequired):
+		s3Err = ErrEncryptionRequired
+	case errors.Is(err, domain.ErrIncompleteBody):
+		s3Err = ErrIncompleteBody
+	case errors.Is(err, domain.ErrChecksumMismatch):
+		s3Err = ErrChecksumMismatch
+	case errors.Is(err, domain.ErrInvalidChecksum):
+		s3Err = S3Error{
+			Code:           "InvalidRequest",
+			Message:        err.Error(),
+			HTTPStatusCode: http.StatusBadRequest,
+		}
 	case errors.Is(err, domain.ErrMultipartUploadNotFound):
 		s3Err = S3Error{
 			Code:           "NoSuchUpload",
@@ -555,6 +796,12 @@ func (h *MultipartHandler) handleMultipartError(w http.ResponseWriter, err error
 			Message:        "One or more of the specified parts could not be found.",
 			HTTPStatusCode: http.StatusBadRequest,
 		}
+	case errors.Is(err, domain.ErrPartDataMissing):
+		s3Err = S3Error{
+			Code:           "InvalidPart",
+			Message:        "One or more of the specified parts is missing its data and must be uploaded again.",
+			HTTPStatusCode: http.StatusBadRequest,
+		}
 	case errors.Is(err, domain.ErrPartETagMismatch):
 		s3Err = S3Error{
 			Code:           "InvalidPart",
@@ -573,6 +820,29 @@ func (h *MultipartHandler) handleMultipartError(w http.ResponseWriter, err error
 			Message:        "The XML you provided did not have the required number of parts.",
 			HTTPStatusCode: http.StatusBadRequest,
 		}
+	case errors.Is(err, domain.ErrObjectNotFound), errors.Is(err, domain.ErrObjectDeleted):
+		s3Err = S3Error{
+			Code:           "NoSuchKey",
+			Message:        "The specified key does not exist.",
+			HTTPStatusCode: http.StatusNotFound,
+		}
+	case errors.Is(err, domain.ErrInvalidVersionID):
+		s3Err = S3Error{
+			Code:           "InvalidArgument",
+			Message:        "Invalid version id specified.",
+			HTTPStatusCode: http.StatusBadRequest,
+		}
+	case errors.Is(err, domain.ErrStorageFull):
+		s3Err = ErrInsufficientStorage
+	case errors.Is(err, domain.ErrObjectDataMissing):
+		s3Err = ErrInternalError
+		s3Err.Message = "The object exists but its data is missing from storage."
+	case errors.Is(err, domain.ErrPreconditionFailed):
+		s3Err = ErrPreconditionFailed
+	case errors.Is(err, domain.ErrInvalidRange):
+		s3Err = ErrInvalidRange
+	case errors.Is(err, service.ErrSSECustomerKeyRequired):
+		s3Err = ErrSSECustomerKeyRequired
 	case errors.Is(err, domain.ErrObjectKeyEmpty):
 		s3Err = S3Error{
 			Code:           "InvalidArgument",
@@ -585,8 +855,16 @@ func (h *MultipartHandler) handleMultipartError(w http.ResponseWriter, err error
 			Message:        "Your key is too long.",
 			HTTPStatusCode: http.StatusBadRequest,
 		}
+	case errors.Is(err, domain.ErrObjectKeyTooDeep):
+		s3Err = S3Error{
+			Code:           "InvalidArgument",
+			Message:        "Your key has too many path segments.",
+			HTTPStatusCode: http.StatusBadRequest,
+		}
 	case errors.Is(err, service.ErrBucketAccessDenied):
 		s3Err = ErrAccessDenied
+	case errors.Is(err, service.ErrACLNotSupported):
+		s3Err = ErrAccessControlListNotSupported
 	default:
 		h.logger.Error().Err(err).Str("bucket", bucket).Str("key", key).Msg("unhandled error")
 		s3Err = ErrInternalError
@@ -0,0 +1,223 @@
+package handler
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/rs/zerolog"
+
+	"github.com/prn-tf/alexander-storage/internal/auth"
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/service"
+)
+
+// maxNotificationBodySize bounds the PutBucketNotificationConfiguration request body.
+const maxNotificationBodySize = 64 * 1024
+
+// NotificationHandler handles bucket notification configuration HTTP requests.
+type NotificationHandler struct {
+	notificationService *service.NotificationService
+	authorizer          auth.Authorizer
+	logger              zerolog.Logger
+}
+
+// NewNotificationHandler creates a new NotificationHandler.
+// If authorizer is nil, the default authorizer is used.
+func NewNotificationHandler(notificationService *service.NotificationService, authorizer auth.Authorizer, logger zerolog.Logger) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+		authorizer:          defaultAuthorizer(authorizer),
+		logger:              logger.With().Str("handler", "notification").Logger(),
+	}
+}
+
+// =============================================================================
+// XML Types
+// =============================================================================
+
+// NotificationConfiguration is the request/response for bucket notification
+// configuration. Events are delivered to webhooks only; the SNS, SQS and
+// Lambda destinations of S3 are recognized so they can be rejected.
+type NotificationConfiguration struct {
+	XMLName  xml.Name               `xml:"NotificationConfiguration"`
+	Xmlns    string                 `xml:"xmlns,attr,omitempty"`
+	Webhooks []WebhookConfiguration `xml:"WebhookConfiguration"`
+
+	Topics         []struct{} `xml:"TopicConfiguration"`
+	Queues         []struct{} `xml:"QueueConfiguration"`
+	CloudFunctions []struct{} `xml:"CloudFunctionConfiguration"`
+	EventBridge    []struct{} `xml:"EventBridgeConfiguration"`
+}
+
+// WebhookConfiguration is a webhook receiving the events of a bucket.
+type WebhookConfiguration struct {
+	ID     string              `xml:"Id,omitempty"`
+	URL    string              `xml:"Url"`
+	Events []string            `xml:"Event"`
+	Filter *NotificationFilter `xml:"Filter,omitempty"`
+}
+
+// NotificationFilter restricts a webhook to object keys with a prefix and/or suffix.
+type NotificationFilter struct {
+	S3Key struct {
+		Rules []FilterRule `xml:"FilterRule"`
+	} `xml:"S3Key"`
+}
+
+// FilterRule is a "prefix" or "suffix" key filter.
+type FilterRule struct {
+	Name  string `xml:"Name"`
+	Value string `xml:"Value"`
+}
+
+// =============================================================================
+// Handler Methods
+// =============================================================================
+
+// GetBucketNotificationConfiguration handles GET /{bucket}?notification requests.
+// A bucket without webhooks returns an empty configuration.
+func (h *NotificationHandler) GetBucketNotificationConfiguration(w http.ResponseWriter, r *http.Request, bucketName string) {
+	ctx := r.Context()
+
+	// Get authenticated user from context
+	userCtx, ok := auth.GetUserContext(ctx)
+	if !ok {
+		h.logger.Error().Msg("no user context found")
+		writeError(w, ErrAccessDenied)
+		return
+	}
+
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionGetBucketNotification, auth.BucketARN(bucketName)) {
+		return
+	}
+
+	webhooks, err := h.notificationService.GetBucketNotification(ctx, bucketName, userCtx.UserID)
+	if err != nil {
+		h.handleError(w, err, bucketName)
+		return
+	}
+
+	response := NotificationConfiguration{
+		Xmlns:    "http://s3.amazonaws.com/doc/2006-03-01/",
+		Webhooks: make([]WebhookConfiguration, len(webhooks)),
+	}
+	for i, webhook := range webhooks {
+		response.Webhooks[i] = WebhookConfiguration{
+			ID:     webhook.ID,
+			URL:    webhook.URL,
+			Events: webhook.Events,
+		}
+		if webhook.Prefix != "" || webhook.Suffix != "" {
+			filter := &NotificationFilter{}
+			if webhook.Prefix != "" {
+				filter.S3Key.Rules = append(filter.S3Key.Rules, FilterRule{Name: "prefix", Value: webhook.Prefix})
+			}
+			if webhook.Suffix != "" {
+				filter.S3Key.Rules = append(filter.S3Key.Rules, FilterRule{Name: "suffix", Value: webhook.Suffix})
+			}
+			response.Webhooks[i].Filter = filter
+		}
+	}
+
+	writeXML(w, http.StatusOK, response)
+}
+
+// PutBucketNotificationConfiguration handles PUT /{bucket}?notification requests.
+// The configuration replaces any existing one; an empty one turns notifications off.
+func (h *NotificationHandler) PutBucketNotificationConfiguration(w http.ResponseWriter, r *http.Request, bucketName string) {
+	ctx := r.Context()
+
+	// Get authenticated user from context
+	userCtx, ok := auth.GetUserContext(ctx)
+	if !ok {
+		h.logger.Error().Msg("no user context found")
+		writeError(w, ErrAccessDenied)
+		return
+	}
+
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionPutBucketNotification, auth.BucketARN(bucketName)) {
+		return
+	}
+
+	// Parse request body
+	var config NotificationConfiguration
+	if err := xml.NewDecoder(io.LimitReader(r.Body, maxNotificationBodySize)).Decode(&config); err != nil {
+		writeError(w, ErrMalformedXML)
+		return
+	}
+	if len(config.Topics)+len(config.Queues)+len(config.CloudFunctions)+len(config.EventBridge) > 0 {
+		writeError(w, invalidNotificationArgument("only WebhookConfiguration destinations are supported", bucketName))
+		return
+	}
+
+	webhooks := make([]domain.WebhookTarget, len(config.Webhooks))
+	for i, webhook := range config.Webhooks {
+		webhooks[i] = domain.WebhookTarget{
+			ID:     webhook.ID,
+			URL:    strings.TrimSpace(webhook.URL),
+			Events: webhook.Events,
+		}
+		if webhook.Filter == nil {
+			continue
+		}
+		for _, rule := range webhook.Filter.S3Key.Rules {
+			switch strings.ToLower(rule.Name) {
+			case "prefix":
+				webhooks[i].Prefix = rule.Value
+			case "suffix":
+				webhooks[i].Suffix = rule.Value
+			default:
+				writeError(w, invalidNotificationArgument("filter rule name must be either prefix or suffix", bucketName))
+				return
+			}
+		}
+	}
+
+	err := h.notificationService.PutBucketNotification(ctx, service.PutBucketNotificationInput{
+		BucketName: bucketName,
+		OwnerID:    userCtx.UserID,
+		Webhooks:   webhooks,
+	})
+	if err != nil {
+		h.handleError(w, err, bucketName)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// =============================================================================
+// Helper Methods
+// =============================================================================
+
+// invalidNotificationArgument is the response for an unacceptable notification configuration.
+func invalidNotificationArgument(message, resource string) S3Error {
+	return S3Error{
+		Code:           "InvalidArgument",
+		Message:        message,
+		HTTPStatusCode: http.StatusBadRequest,
+		Resource:       resource,
+	}
+}
+
+// handleError maps service errors to S3 error responses.
+func (h *NotificationHandler) handleError(w http.ResponseWriter, err error, resource string) {
+	s3Err := ErrInternalError
+
+	switch {
+	case errors.Is(err, domain.ErrBucketNotFound):
+		s3Err = ErrNoSuchBucket
+	case errors.Is(err, service.ErrBucketAccessDenied):
+		s3Err = ErrAccessDenied
+	case errors.Is(err, domain.ErrInvalidNotificationConfiguration):
+		s3Err = invalidNotificationArgument(err.Error(), resource)
+	default:
+		h.logger.Error().Err(err).Str("resource", resource).Msg("unhandled error")
+	}
+
+	s3Err.Resource = resource
+	writeError(w, s3Err)
+}
@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/auth"
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/events"
+	"github.com/prn-tf/alexander-storage/internal/lock"
+	"github.com/prn-tf/alexander-storage/internal/notification"
+	"github.com/prn-tf/alexander-storage/internal/repository"
+	"github.com/prn-tf/alexander-storage/internal/service"
+	"github.com/prn-tf/alexander-storage/internal/storage/filesystem"
+)
+
+// memoryBucketNotificationRepository keeps bucket notification configurations in memory.
+type memoryBucketNotificationRepository struct {
+	configs map[int64]*domain.BucketNotification
+}
+
+func (r *memoryBucketNotificationRepository) Get(ctx context.Context, bucketID int64) (*domain.BucketNotification, error) {
+	config, ok := r.configs[bucketID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return config, nil
+}
+
+func (r *memoryBucketNotificationRepository) Put(ctx context.Context, config *domain.BucketNotification) error {
+	r.configs[config.BucketID] = config
+	return nil
+}
+
+func (r *memoryBucketNotificationRepository) Delete(ctx context.Context, bucketID int64) error {
+	delete(r.configs, bucketID)
+	return nil
+}
+
+// testNotificationConfiguration subscribes url to object creations under images/.
+func testNotificationConfiguration(url string) string {
+	return `<NotificationConfiguration>
+	<WebhookConfiguration>
+		<Id>thumbnails</Id>
+		<Url>` + url + `</Url>
+		<Event>s3:ObjectCreated:*</Event>
+		<Filter><S3Key><FilterRule><Name>prefix</Name><Value>images/</Value></FilterRule></S3Key></Filter>
+	</WebhookConfiguration>
+</NotificationConfiguration>`
+}
+
+// newNotificationTestRouter returns a router serving objects and the
+// notification configuration of the "uploads" bucket, owned by user 1,
+// with events dispatched to its webhooks.
+func newNotificationTestRouter(t *testing.T) (*Router, *events.Bus, *notification.Dispatcher) {
+	t.Helper()
+
+	store, err := filesystem.NewStorage(filesystem.Config{
+		DataDir: t.TempDir(),
+		TempDir: t.TempDir(),
+	}, zerolog.Nop())
+	require.NoError(t, err)
+
+	buckets := &stubBucketRepository{buckets: map[string]*domain.Bucket{
+		"uploads": {ID: 1, Name: "uploads", OwnerID: 1, Versioning: domain.VersioningDisabled},
+	}}
+	objects := &memoryObjectRepository{objects: make(map[string]*domain.Object)}
+	blobs := &memoryBlobRepository{refs: make(map[string]int32)}
+	objectSvc := service.NewObjectService(objects, blobs, buckets, store, lock.NewNoOpLocker(), zerolog.Nop())
+	notificationSvc := service.NewNotificationService(&memoryBucketNotificationRepository{configs: make(map[int64]*domain.BucketNotification)}, buckets, zerolog.Nop())
+
+	bus := events.NewBus(0, zerolog.Nop())
+	dispatcher := notification.NewDispatcher(notificationSvc, notification.Config{RetryBackoff: time.Millisecond}, zerolog.Nop())
+	bus.Subscribe("notification", dispatcher)
+	objectSvc.SetEventBus(bus)
+
+	return NewRouter(RouterConfig{
+		BucketHandler:  NewBucketHandler(service.NewBucketService(buckets, zerolog.Nop()), nil, zerolog.Nop()),
+		ObjectHandler:  NewObjectHandler(objectSvc, nil, zerolog.Nop()),
+		Notification:   NewNotificationHandler(notificationSvc, nil, zerolog.Nop()),
+		AuthMiddleware: auth.Middleware(nil, auth.DefaultConfig()),
+		Logger:         zerolog.Nop(),
+	}), bus, dispatcher
+}
+
+func TestNotificationHandler_PutGet(t *testing.T) {
+	rt, _, _ := newNotificationTestRouter(t)
+	serve := func(method, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		rt.handleS3Request(rec, withTestUser(httptest.NewRequest(method, "/uploads?notification", strings.NewReader(body))))
+		return rec
+	}
+
+	// Without a configuration the response is empty
+	rec := serve(http.MethodGet, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NotContains(t, rec.Body.String(), "WebhookConfiguration")
+
+	requireErrorCode(t, serve(http.MethodPut, "<NotificationConfiguration>"), http.StatusBadRequest, "MalformedXML")
+	requireErrorCode(t, serve(http.MethodPut, testNotificationConfiguration("ftp://hooks.example.com")), http.StatusBadRequest, "InvalidArgument")
+	requireErrorCode(t, serve(http.MethodPut, strings.Replace(testNotificationConfiguration("https://hooks.example.com"), "ObjectCreated:*", "ObjectRestore:*", 1)), http.StatusBadRequest, "InvalidArgument")
+	requireErrorCode(t, serve(http.MethodPut, "<NotificationConfiguration><QueueConfiguration><Queue>arn:aws:sqs:::q</Queue></QueueConfiguration></NotificationConfiguration>"), http.StatusBadRequest, "InvalidArgument")
+
+	require.Equal(t, http.StatusOK, serve(http.MethodPut, testNotificationConfiguration("https://hooks.example.com/s3")).Code)
+	rec = serve(http.MethodGet, "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), "<Url>https://hooks.example.com/s3</Url>")
+	require.Contains(t, rec.Body.String(), "<Event>s3:ObjectCreated:*</Event>")
+	require.Contains(t, rec.Body.String(), "<Name>prefix</Name><Value>images/</Value>")
+
+	// An empty configuration turns notifications off
+	require.Equal(t, http.StatusOK, serve(http.MethodPut, "<NotificationConfiguration></NotificationConfiguration>").Code)
+	require.NotContains(t, serve(http.MethodGet, "").Body.String(), "WebhookConfiguration")
+}
+
+func TestNotificationHandler_PutObjectFiresCreateEvent(t *testing.T) {
+	received := make(chan notification.EventMessage, 10)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var message notification.EventMessage
+		if json.Unmarshal(body, &message) == nil {
+			received <- message
+		}
+	}))
+	defer webhook.Close()
+
+	rt, bus, dispatcher := newNotificationTestRouter(t)
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		rt.handleS3Request(rec, withTestUser(httptest.NewRequest(method, target, strings.NewReader(body))))
+		return rec
+	}
+
+	require.Equal(t, http.StatusOK, serve(http.MethodPut, "/uploads?notification", testNotificationConfiguration(webhook.URL)).Code)
+	require.Equal(t, http.StatusOK, serve(http.MethodPut, "/uploads/notes.txt", "not an image").Code)
+	require.Equal(t, http.StatusOK, serve(http.MethodPut, "/uploads/images/cat.png", "meow").Code)
+
+	require.NoError(t, bus.Close(context.Background()))
+	require.NoError(t, dispatcher.Close(context.Background()))
+	close(received)
+
+	var messages []notification.EventMessage
+	for message := range received {
+		messages = append(messages, message)
+	}
+	require.Len(t, messages, 1)
+	record := messages[0].Records[0]
+	require.Equal(t, "ObjectCreated:Put", record.EventName)
+	require.Equal(t, "images/cat.png", record.S3.Object.Key)
+	require.Equal(t, int64(4), record.S3.Object.Size)
+	require.Equal(t, "uploads", record.S3.Bucket.Name)
+	require.Equal(t, "thumbnails", record.S3.ConfigurationID)
+}
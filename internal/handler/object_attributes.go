@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/prn-tf/alexander-storage/internal/auth"
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/service"
+)
+
+// GetObjectAttributesResponse is the response body of GetObjectAttributes.
+// Only the attributes requested in x-amz-object-attributes are set.
+type GetObjectAttributesResponse struct {
+	XMLName      xml.Name          `xml:"GetObjectAttributesResponse"`
+	Xmlns        string            `xml:"xmlns,attr"`
+	ETag         string            `xml:"ETag,omitempty"`
+	Checksum     *ChecksumElements `xml:"Checksum,omitempty"`
+	StorageClass string            `xml:"StorageClass,omitempty"`
+	ObjectSize   *int64            `xml:"ObjectSize,omitempty"`
+}
+
+// objectAttributes are the supported values of x-amz-object-attributes.
+// ObjectParts is accepted but not returned, as part boundaries are not kept
+// once an upload completes.
+var objectAttributes = map[string]bool{
+	"ETag":         true,
+	"Checksum":     true,
+	"ObjectParts":  true,
+	"StorageClass": true,
+	"ObjectSize":   true,
+}
+
+// GetObjectAttributes handles GET /{bucket}/{key}?attributes requests.
+func (h *ObjectHandler) GetObjectAttributes(w http.ResponseWriter, r *http.Request, bucketName, objectKey string) {
+	ctx := r.Context()
+
+	// Get authenticated user from context
+	userCtx, ok := auth.GetUserContext(ctx)
+	if !ok {
+		h.logger.Error().Msg("no user context found")
+		writeError(w, ErrAccessDenied)
+		return
+	}
+
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionGetObjectAttributes, auth.ObjectARN(bucketName, objectKey)) {
+		return
+	}
+
+	requested, err := parseObjectAttributes(r.Header.Values("x-amz-object-attributes"))
+	if err != nil {
+		writeError(w, S3Error{
+			Code:           "InvalidArgument",
+			Message:        err.Error(),
+			HTTPStatusCode: http.StatusBadRequest,
+		})
+		return
+	}
+
+	customerKey, err := parseSSECustomerKey(r, sseCustomerHeaderPrefix)
+	if err != nil {
+		h.handleObjectError(w, err, bucketName, objectKey)
+		return
+	}
+
+	versionID := r.URL.Query().Get("versionId")
+	output, err := h.objectService.HeadObject(ctx, service.HeadObjectInput{
+		BucketName:     bucketName,
+		Key:            objectKey,
+		VersionID:      versionID,
+		OwnerID:        userCtx.UserID,
+		SSECustomerKey: customerKey,
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrVersionIsDeleteMarker) {
+			w.Header().Set("x-amz-version-id", versionID)
+		}
+		h.handleObjectError(w, err, bucketName, objectKey)
+		return
+	}
+
+	response := GetObjectAttributesResponse{
+		Xmlns: "http://s3.amazonaws.com/doc/2006-03-01/",
+	}
+	if requested["ETag"] {
+		response.ETag = strings.Trim(output.ETag, `"`)
+	}
+	if requested["Checksum"] && output.Checksum != "" {
+		checksum := newChecksumElements(output.ChecksumAlgorithm, output.Checksum)
+		response.Checksum = &checksum
+	}
+	if requested["StorageClass"] {
+		response.StorageClass = string(output.StorageClass)
+	}
+	if requested["ObjectSize"] {
+		size := output.ContentLength
+		response.ObjectSize = &size
+	}
+
+	w.Header().Set("Last-Modified", output.LastModified.UTC().Format(http.TimeFormat))
+	setVersionIDHeader(w, output.VersionID)
+	writeXML(w, http.StatusOK, response)
+}
+
+// parseObjectAttributes parses the comma-separated x-amz-object-attributes
+// header values. At least one attribute is required.
+func parseObjectAttributes(values []string) (map[string]bool, error) {
+	requested := make(map[string]bool)
+	for _, value := range values {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if !objectAttributes[name] {
+				return nil, errors.New("Invalid attribute name specified: " + name)
+			}
+			requested[name] = true
+		}
+	}
+	if len(requested) == 0 {
+		return nil, errors.New("The x-amz-object-attributes header specifying the attributes to be retrieved is either missing or empty.")
+	}
+	return requested, nil
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"hash/crc32"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObjectHandler_ChecksumRoundTrip(t *testing.T) {
+	h, _, _ := newPutObjectTestHandler(t)
+
+	const content = "the quick brown fox"
+	sum := crc32.Checksum([]byte(content), crc32.MakeTable(crc32.Castagnoli))
+	checksum := base64.StdEncoding.EncodeToString([]byte{byte(sum >> 24), byte(sum >> 16), byte(sum >> 8), byte(sum)})
+
+	// A mismatching checksum is rejected with BadDigest
+	req := withTestUser(httptest.NewRequest(http.MethodPut, "/uploads/fox.txt", strings.NewReader(content)))
+	req.Header.Set("x-amz-checksum-crc32c", "AAAAAA==")
+	rec := httptest.NewRecorder()
+	h.PutObject(rec, req, "uploads", "fox.txt")
+	requireErrorCode(t, rec, http.StatusBadRequest, "BadDigest")
+
+	// Two checksums at once are ambiguous
+	req = withTestUser(httptest.NewRequest(http.MethodPut, "/uploads/fox.txt", strings.NewReader(content)))
+	req.Header.Set("x-amz-checksum-crc32c", checksum)
+	req.Header.Set("x-amz-checksum-crc32", checksum)
+	rec = httptest.NewRecorder()
+	h.PutObject(rec, req, "uploads", "fox.txt")
+	requireErrorCode(t, rec, http.StatusBadRequest, "InvalidRequest")
+
+	req = withTestUser(httptest.NewRequest(http.MethodPut, "/uploads/fox.txt", strings.NewReader(content)))
+	req.Header.Set("x-amz-checksum-crc32c", checksum)
+	rec = httptest.NewRecorder()
+	h.PutObject(rec, req, "uploads", "fox.txt")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, checksum, rec.Header().Get("x-amz-checksum-crc32c"))
+
+	// Reads return the checksum only in checksum mode
+	rec = httptest.NewRecorder()
+	h.HeadObject(rec, withTestUser(httptest.NewRequest(http.MethodHead, "/uploads/fox.txt", nil)), "uploads", "fox.txt")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("x-amz-checksum-crc32c"))
+
+	req = withTestUser(httptest.NewRequest(http.MethodGet, "/uploads/fox.txt", nil))
+	req.Header.Set("x-amz-checksum-mode", "ENABLED")
+	rec = httptest.NewRecorder()
+	h.GetObject(rec, req, "uploads", "fox.txt")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, checksum, rec.Header().Get("x-amz-checksum-crc32c"))
+	assert.Equal(t, content, rec.Body.String())
+
+	// GetObjectAttributes returns the requested attributes only
+	req = withTestUser(httptest.NewRequest(http.MethodGet, "/uploads/fox.txt?attributes", nil))
+	req.Header.Set("x-amz-object-attributes", "ETag,Checksum,ObjectSize")
+	rec = httptest.NewRecorder()
+	h.GetObjectAttributes(rec, req, "uploads", "fox.txt")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var attrs GetObjectAttributesResponse
+	require.NoError(t, xml.Unmarshal(rec.Body.Bytes(), &attrs))
+	assert.NotEmpty(t, attrs.ETag)
+	assert.NotContains(t, attrs.ETag, `"`)
+	require.NotNil(t, attrs.Checksum)
+	assert.Equal(t, checksum, attrs.Checksum.ChecksumCRC32C)
+	require.NotNil(t, attrs.ObjectSize)
+	assert.Equal(t, int64(len(content)), *attrs.ObjectSize)
+	assert.Empty(t, attrs.StorageClass)
+	assert.NotEmpty(t, rec.Header().Get("Last-Modified"))
+}
+
+func TestObjectHandler_GetObjectAttributesRequiresAttributes(t *testing.T) {
+	h, _, _ := newPutObjectTestHandler(t)
+
+	for _, header := range []string{"", "ETag,Color"} {
+		req := withTestUser(httptest.NewRequest(http.MethodGet, "/uploads/fox.txt?attributes", nil))
+		if header != "" {
+			req.Header.Set("x-amz-object-attributes", header)
+		}
+		rec := httptest.NewRecorder()
+		h.GetObjectAttributes(rec, req, "uploads", "fox.txt")
+		requireErrorCode(t, rec, http.StatusBadRequest, "InvalidArgument")
+	}
+}
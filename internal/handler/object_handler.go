@@ -2,36 +2,51 @@
 package handler
 
 import (
+	"crypto/md5"
+	"encoding/base64"
 	"encoding/xml"
 	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/rs/zerolog"
 
 	"github.com/prn-tf/alexander-storage/internal/auth"
 	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/pkg/crypto"
 	"github.com/prn-tf/alexander-storage/internal/service"
 )
 
 // ObjectHandler handles object-related HTTP requests.
 type ObjectHandler struct {
-	objectService *service.ObjectService
-	logger        zerolog.Logger
+	objectService    *service.ObjectService
+	authorizer       auth.Authorizer
+	checksumTrailers bool
+	logger           zerolog.Logger
 }
 
 // NewObjectHandler creates a new ObjectHandler.
-func NewObjectHandler(objectService *service.ObjectService, logger zerolog.Logger) *ObjectHandler {
+// If authorizer is nil, the default authorizer is used.
+func NewObjectHandler(objectService *service.ObjectService, authorizer auth.Authorizer, logger zerolog.Logger) *ObjectHandler {
 	return &ObjectHandler{
 		objectService: objectService,
+		authorizer:    defaultAuthorizer(authorizer),
 		logger:        logger.With().Str("handler", "object").Logger(),
 	}
 }
 
+// SetChecksumTrailers enables x-amz-checksum-* trailers on GetObject
+// responses for clients that send "TE: trailers".
+func (h *ObjectHandler) SetChecksumTrailers(enabled bool) {
+	h.checksumTrailers = enabled
+}
+
 // =============================================================================
 // XML Types
 // =============================================================================
@@ -49,6 +64,7 @@ type ListBucketResult struct {
 	Contents       []S3Object     `xml:"Contents,omitempty"`
 	CommonPrefixes []CommonPrefix `xml:"CommonPrefixes,omitempty"`
 	NextMarker     string         `xml:"NextMarker,omitempty"`
+	EncodingType   string         `xml:"EncodingType,omitempty"`
 }
 
 // ListBucketResultV2 is the response for ListObjectsV2.
@@ -66,6 +82,7 @@ type ListBucketResultV2 struct {
 	Contents              []S3Object     `xml:"Contents,omitempty"`
 	CommonPrefixes        []CommonPrefix `xml:"CommonPrefixes,omitempty"`
 	KeyCount              int            `xml:"KeyCount"`
+	EncodingType          string         `xml:"EncodingType,omitempty"`
 }
 
 // S3Object represents an object in list responses.
@@ -99,6 +116,43 @@ type DeleteResult struct {
 	VersionID             string   `xml:"VersionId,omitempty"`
 }
 
+// DeleteObjectsRequest is the request body for DeleteObjects.
+type DeleteObjectsRequest struct {
+	XMLName xml.Name           `xml:"Delete"`
+	Quiet   bool               `xml:"Quiet"`
+	Objects []ObjectIdentifier `xml:"Object"`
+}
+
+// ObjectIdentifier identifies an object (version) in a DeleteObjects request.
+type ObjectIdentifier struct {
+	Key       string `xml:"Key"`
+	VersionId string `xml:"VersionId,omitempty"`
+}
+
+// DeleteObjectsResult is the response for DeleteObjects.
+type DeleteObjectsResult struct {
+	XMLName xml.Name        `xml:"DeleteResult"`
+	Xmlns   string          `xml:"xmlns,attr"`
+	Deleted []DeletedObject `xml:"Deleted,omitempty"`
+	Errors  []DeleteError   `xml:"Error,omitempty"`
+}
+
+// DeletedObject is a successfully deleted entry in a DeleteObjects response.
+type DeletedObject struct {
+	Key                   string `xml:"Key"`
+	VersionId             string `xml:"VersionId,omitempty"`
+	DeleteMarker          bool   `xml:"DeleteMarker,omitempty"`
+	DeleteMarkerVersionId string `xml:"DeleteMarkerVersionId,omitempty"`
+}
+
+// DeleteError is a failed entry in a DeleteObjects response.
+type DeleteError struct {
+	Key       string `xml:"Key"`
+	VersionId string `xml:"VersionId,omitempty"`
+	Code      string `xml:"Code"`
+	Message   string `xml:"Message"`
+}
+
 // ListVersionsResult is the response for ListObjectVersions.
 type ListVersionsResult struct {
 	XMLName             xml.Name          `xml:"ListVersionsResult"`
@@ -115,6 +169,7 @@ type ListVersionsResult struct {
 	Versions            []S3ObjectVersion `xml:"Version,omitempty"`
 	DeleteMarkers       []S3DeleteMarker  `xml:"DeleteMarker,omitempty"`
 	CommonPrefixes      []CommonPrefix    `xml:"CommonPrefixes,omitempty"`
+	EncodingType        string            `xml:"EncodingType,omitempty"`
 }
 
 // S3ObjectVersion represents an object version in list versions responses.
@@ -152,6 +207,10 @@ func (h *ObjectHandler) PutObject(w http.ResponseWriter, r *http.Request, bucket
 		return
 	}
 
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionPutObject, auth.ObjectARN(bucketName, objectKey)) {
+		return
+	}
+
 	// Get content length
 	contentLength := r.ContentLength
 	if contentLength < 0 {
@@ -169,15 +228,98 @@ func (h *ObjectHandler) PutObject(w http.ResponseWriter, r *http.Request, bucket
 	// Parse metadata from x-amz-meta-* headers
 	metadata := parseMetadata(r)
 
+	// Parse the optional per-object expiration time
+	var expiresAt *time.Time
+	if value := r.Header.Get("x-amz-expires-at"); value != "" {
+		t, err := parseExpiresAt(value)
+		if err != nil {
+			writeError(w, ErrInvalidObjectExpiry)
+			return
+		}
+		expiresAt = &t
+	}
+
+	// Parse the optional initial tag set
+	tags, err := parseTaggingHeader(r.Header.Get("x-amz-tagging"))
+	if err != nil {
+		h.handleObjectError(w, err, bucketName, objectKey)
+		return
+	}
+
+	// Parse the optional initial retention and legal hold
+	objectLock, err := parseObjectLockHeaders(r)
+	if err != nil {
+		h.handleObjectError(w, err, bucketName, objectKey)
+		return
+	}
+
+	// Parse the optional customer-provided encryption key (SSE-C)
+	customerKey, err := parseSSECustomerKey(r, sseCustomerHeaderPrefix)
+	if err != nil {
+		h.handleObjectError(w, err, bucketName, objectKey)
+		return
+	}
+
+	// Reject requests for encryption with server-managed keys
+	if s3Err, ok := checkServerSideEncryption(r); !ok {
+		writeError(w, s3Err)
+		return
+	}
+
+	// Parse the optional Content-MD5 the body is verified against
+	var contentMD5 []byte
+	if value := r.Header.Get("Content-MD5"); value != "" {
+		contentMD5, err = base64.StdEncoding.DecodeString(value)
+		if err != nil || len(contentMD5) != md5.Size {
+			writeError(w, ErrInvalidDigest)
+			return
+		}
+	}
+
+	// Parse the optional additional checksum (x-amz-checksum-*)
+	checksumAlgorithm, checksum, err := parseChecksumHeaders(r)
+	if err != nil {
+		h.handleObjectError(w, err, bucketName, objectKey)
+		return
+	}
+
+	// Parse the optional append offset (x-amz-write-offset-bytes)
+	var writeOffset *int64
+	if value := r.Header.Get("x-amz-write-offset-bytes"); value != "" {
+		offset, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || offset < 0 {
+			writeError(w, S3Error{
+				Code:           "InvalidArgument",
+				Message:        "The write offset must be a non-negative integer.",
+				HTTPStatusCode: http.StatusBadRequest,
+			})
+			return
+		}
+		writeOffset = &offset
+	}
+
 	// Store object
 	output, err := h.objectService.PutObject(ctx, service.PutObjectInput{
-		BucketName:  bucketName,
-		Key:         objectKey,
-		Body:        r.Body,
-		Size:        contentLength,
-		ContentType: contentType,
-		Metadata:    metadata,
-		OwnerID:     userCtx.UserID,
+		BucketName:         bucketName,
+		Key:                objectKey,
+		Body:               r.Body,
+		Size:               contentLength,
+		ContentType:        contentType,
+		Metadata:           metadata,
+		CacheControl:       r.Header.Get("Cache-Control"),
+		ContentDisposition: r.Header.Get("Content-Disposition"),
+		ContentEncoding:    r.Header.Get("Content-Encoding"),
+		Expires:            r.Header.Get("Expires"),
+		ACL:                r.Header.Get("x-amz-acl"),
+		OwnerID:            userCtx.UserID,
+		ExpiresAt:          expiresAt,
+		Tags:               tags,
+		ObjectLock:         objectLock,
+		SSECustomerKey:     customerKey,
+		ContentMD5:         contentMD5,
+		ChecksumAlgorithm:  checksumAlgorithm,
+		Checksum:           checksum,
+		WriteOffset:        writeOffset,
 	})
 
 	if err != nil {
@@ -187,9 +329,9 @@ func (h *ObjectHandler) PutObject(w http.ResponseWriter, r *http.Request, bucket
 
 	// Success response
 	w.Header().Set("ETag", output.ETag)
-	if output.VersionID != "" && output.VersionID != "null" {
-		w.Header().Set("x-amz-version-id", output.VersionID)
-	}
+	setVersionIDHeader(w, output.VersionID)
+	setSSECustomerHeaders(w, output.SSECustomerKeyMD5)
+	setChecksumHeader(w, output.ChecksumAlgorithm, output.Checksum)
 	w.WriteHeader(http.StatusOK)
 }
 
@@ -205,65 +347,107 @@ func (h *ObjectHandler) GetObject(w http.ResponseWriter, r *http.Request, bucket
 		return
 	}
 
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionGetObject, auth.ObjectARN(bucketName, objectKey)) {
+		return
+	}
+
 	// Parse version ID
 	versionID := r.URL.Query().Get("versionId")
 
 	// Parse range header
-	var byteRange *service.ByteRange
-	rangeHeader := r.Header.Get("Range")
-	if rangeHeader != "" {
-		var err error
-		byteRange, err = parseRangeHeader(rangeHeader)
-		if err != nil {
-			writeError(w, S3Error{
-				Code:           "InvalidRange",
-				Message:        "The requested range is not satisfiable.",
-				HTTPStatusCode: http.StatusRequestedRangeNotSatisfiable,
-			})
+	byteRange, ok := parseRequestRange(r)
+	if !ok {
+		writeError(w, ErrInvalidRange)
+		return
+	}
+
+	responseHeaders, ok := parseResponseHeaders(w, r, userCtx)
+	if !ok {
+		return
+	}
+
+	// Checksum trailer, if enabled and the client can receive it
+	var trailer *checksumTrailer
+	if h.checksumTrailers {
+		if trailer, ok = newChecksumTrailer(r); !ok {
+			writeError(w, ErrInvalidChecksumAlgorithm)
 			return
 		}
 	}
 
+	// SSE-C objects can only be read with the key they were stored with
+	customerKey, err := parseSSECustomerKey(r, sseCustomerHeaderPrefix)
+	if err != nil {
+		h.handleObjectError(w, err, bucketName, objectKey)
+		return
+	}
+
 	// Get object
 	output, err := h.objectService.GetObject(ctx, service.GetObjectInput{
-		BucketName: bucketName,
-		Key:        objectKey,
-		VersionID:  versionID,
-		OwnerID:    userCtx.UserID,
-		Range:      byteRange,
+		BucketName:      bucketName,
+		Key:             objectKey,
+		VersionID:       versionID,
+		OwnerID:         userCtx.UserID,
+		Range:           byteRange,
+		Conditions:      parseObjectConditions(r),
+		ResponseHeaders: responseHeaders,
+		SSECustomerKey:  customerKey,
 	})
 
 	if err != nil {
+		if errors.Is(err, domain.ErrVersionIsDeleteMarker) {
+			w.Header().Set("x-amz-version-id", versionID)
+		}
 		h.handleObjectError(w, err, bucketName, objectKey)
 		return
 	}
+	if output.NotModified {
+		writeNotModified(w, output.ETag, output.LastModified, output.VersionID)
+		return
+	}
 	defer output.Body.Close()
 
 	// Set response headers
-	w.Header().Set("Content-Type", output.ContentType)
-	w.Header().Set("Content-Length", strconv.FormatInt(output.ContentLength, 10))
-	w.Header().Set("ETag", output.ETag)
-	w.Header().Set("Last-Modified", output.LastModified.UTC().Format(http.TimeFormat))
+	setObjectHeaders(w, objectHeaders{
+		ContentType:        output.ContentType,
+		ContentLength:      output.ContentLength,
+		ContentRange:       output.ContentRange,
+		ContentDisposition: output.ContentDisposition,
+		CacheControl:       output.CacheControl,
+		ContentEncoding:    output.ContentEncoding,
+		ContentLanguage:    output.ContentLanguage,
+		Expires:            output.Expires,
+		ETag:               output.ETag,
+		LastModified:       output.LastModified,
+		VersionID:          output.VersionID,
+		StorageClass:       output.StorageClass,
+		SSECustomerKeyMD5:  output.SSECustomerKeyMD5,
+		Metadata:           output.Metadata,
+	})
 
-	if output.VersionID != "" && output.VersionID != "null" {
-		w.Header().Set("x-amz-version-id", output.VersionID)
+	// When the stored checksum is sent the trailer would be a second checksum
+	if sendsStoredChecksum(r, output.ContentRange, output.Checksum) {
+		setChecksumHeader(w, output.ChecksumAlgorithm, output.Checksum)
+		trailer = nil
 	}
 
-	// Set metadata headers
-	for key, value := range output.Metadata {
-		w.Header().Set("x-amz-meta-"+key, value)
+	if trailer != nil {
+		trailer.declare(w)
 	}
 
-	// Handle range response
-	if output.ContentRange != "" {
-		w.Header().Set("Content-Range", output.ContentRange)
-		w.WriteHeader(http.StatusPartialContent)
-	} else {
-		w.WriteHeader(http.StatusOK)
-	}
+	w.WriteHeader(objectStatus(output.ContentRange))
 
 	// Stream content
-	io.Copy(w, output.Body)
+	if trailer == nil {
+		io.Copy(w, output.Body)
+		return
+	}
+
+	// The checksum covers exactly the bytes sent; an incomplete body gets no trailer
+	if _, err := io.Copy(io.MultiWriter(w, trailer), output.Body); err != nil {
+		return
+	}
+	trailer.send(w)
 }
 
 // HeadObject handles HEAD /{bucket}/{key} requests.
@@ -278,39 +462,77 @@ func (h *ObjectHandler) HeadObject(w http.ResponseWriter, r *http.Request, bucke
 		return
 	}
 
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionGetObject, auth.ObjectARN(bucketName, objectKey)) {
+		return
+	}
+
 	// Parse version ID
 	versionID := r.URL.Query().Get("versionId")
 
+	// Parse range header; a ranged HEAD describes the ranged GET
+	byteRange, ok := parseRequestRange(r)
+	if !ok {
+		writeError(w, ErrInvalidRange)
+		return
+	}
+
+	responseHeaders, ok := parseResponseHeaders(w, r, userCtx)
+	if !ok {
+		return
+	}
+
+	customerKey, err := parseSSECustomerKey(r, sseCustomerHeaderPrefix)
+	if err != nil {
+		h.handleObjectError(w, err, bucketName, objectKey)
+		return
+	}
+
 	// Get object metadata
 	output, err := h.objectService.HeadObject(ctx, service.HeadObjectInput{
-		BucketName: bucketName,
-		Key:        objectKey,
-		VersionID:  versionID,
-		OwnerID:    userCtx.UserID,
+		BucketName:      bucketName,
+		Key:             objectKey,
+		VersionID:       versionID,
+		OwnerID:         userCtx.UserID,
+		Conditions:      parseObjectConditions(r),
+		Range:           byteRange,
+		ResponseHeaders: responseHeaders,
+		SSECustomerKey:  customerKey,
 	})
 
 	if err != nil {
+		if errors.Is(err, domain.ErrVersionIsDeleteMarker) {
+			w.Header().Set("x-amz-version-id", versionID)
+		}
 		h.handleObjectError(w, err, bucketName, objectKey)
 		return
 	}
-
-	// Set response headers
-	w.Header().Set("Content-Type", output.ContentType)
-	w.Header().Set("Content-Length", strconv.FormatInt(output.ContentLength, 10))
-	w.Header().Set("ETag", output.ETag)
-	w.Header().Set("Last-Modified", output.LastModified.UTC().Format(http.TimeFormat))
-	w.Header().Set("x-amz-storage-class", string(output.StorageClass))
-
-	if output.VersionID != "" && output.VersionID != "null" {
-		w.Header().Set("x-amz-version-id", output.VersionID)
+	if output.NotModified {
+		writeNotModified(w, output.ETag, output.LastModified, output.VersionID)
+		return
 	}
 
-	// Set metadata headers
-	for key, value := range output.Metadata {
-		w.Header().Set("x-amz-meta-"+key, value)
+	// Set the headers GET would; net/http sends no body for HEAD
+	setObjectHeaders(w, objectHeaders{
+		ContentType:        output.ContentType,
+		ContentLength:      output.ContentLength,
+		ContentRange:       output.ContentRange,
+		ContentDisposition: output.ContentDisposition,
+		CacheControl:       output.CacheControl,
+		ContentEncoding:    output.ContentEncoding,
+		ContentLanguage:    output.ContentLanguage,
+		Expires:            output.Expires,
+		ETag:               output.ETag,
+		LastModified:       output.LastModified,
+		VersionID:          output.VersionID,
+		StorageClass:       output.StorageClass,
+		SSECustomerKeyMD5:  output.SSECustomerKeyMD5,
+		Metadata:           output.Metadata,
+	})
+	if sendsStoredChecksum(r, output.ContentRange, output.Checksum) {
+		setChecksumHeader(w, output.ChecksumAlgorithm, output.Checksum)
 	}
 
-	w.WriteHeader(http.StatusOK)
+	w.WriteHeader(objectStatus(output.ContentRange))
 }
 
 // DeleteObject handles DELETE /{bucket}/{key} requests.
@@ -325,15 +547,27 @@ func (h *ObjectHandler) DeleteObject(w http.ResponseWriter, r *http.Request, buc
 		return
 	}
 
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionDeleteObject, auth.ObjectARN(bucketName, objectKey)) {
+		return
+	}
+
 	// Parse version ID
 	versionID := r.URL.Query().Get("versionId")
 
+	bypassGovernance, err := bypassGovernanceRetention(r, h.authorizer, userCtx, auth.ObjectARN(bucketName, objectKey))
+	if err != nil {
+		h.logger.Error().Err(err).Str("bucket", bucketName).Str("key", objectKey).Msg("failed to authorize governance bypass")
+		writeError(w, ErrInternalError)
+		return
+	}
+
 	// Delete object
 	output, err := h.objectService.DeleteObject(ctx, service.DeleteObjectInput{
-		BucketName: bucketName,
-		Key:        objectKey,
-		VersionID:  versionID,
-		OwnerID:    userCtx.UserID,
+		BucketName:                bucketName,
+		Key:                       objectKey,
+		VersionID:                 versionID,
+		OwnerID:                   userCtx.UserID,
+		BypassGovernanceRetention: bypassGovernance,
 	})
 
 	if err != nil {
@@ -345,13 +579,90 @@ func (h *ObjectHandler) DeleteObject(w http.ResponseWriter, r *http.Request, buc
 	if output.DeleteMarker {
 		w.Header().Set("x-amz-delete-marker", "true")
 	}
-	if output.VersionID != "" {
-		w.Header().Set("x-amz-version-id", output.VersionID)
-	}
+	setVersionIDHeader(w, output.VersionID)
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// maxDeleteObjects is the maximum number of keys in one DeleteObjects request.
+const maxDeleteObjects = 1000
+
+// maxDeleteObjectsBodySize bounds the DeleteObjects request body: 1000 keys
+// of up to 1KB each, plus markup.
+const maxDeleteObjectsBodySize = 2 * 1024 * 1024
+
+// DeleteObjects handles POST /{bucket}?delete requests.
+// Each key is authorized and deleted on its own; failures are reported per
+// key in the response rather than failing the whole request.
+func (h *ObjectHandler) DeleteObjects(w http.ResponseWriter, r *http.Request, bucketName string) {
+	ctx := r.Context()
+
+	// Get authenticated user from context
+	userCtx, ok := auth.GetUserContext(ctx)
+	if !ok {
+		h.logger.Error().Msg("no user context found")
+		writeError(w, ErrAccessDenied)
+		return
+	}
+
+	// Parse request body
+	var req DeleteObjectsRequest
+	if err := xml.NewDecoder(io.LimitReader(r.Body, maxDeleteObjectsBodySize)).Decode(&req); err != nil {
+		writeError(w, ErrMalformedXML)
+		return
+	}
+	if len(req.Objects) == 0 || len(req.Objects) > maxDeleteObjects {
+		writeError(w, ErrMalformedXML)
+		return
+	}
+
+	result := DeleteObjectsResult{Xmlns: "http://s3.amazonaws.com/doc/2006-03-01/"}
+	for _, obj := range req.Objects {
+		if err := h.authorizer.Authorize(ctx, userCtx, auth.ActionDeleteObject, auth.ObjectARN(bucketName, obj.Key)); err != nil {
+			s3Err := ErrAccessDenied
+			if !errors.Is(err, auth.ErrAccessDenied) {
+				h.logger.Error().Err(err).Str("bucket", bucketName).Str("key", obj.Key).Msg("failed to authorize request")
+				s3Err = ErrInternalError
+			}
+			result.Errors = append(result.Errors, DeleteError{Key: obj.Key, VersionId: obj.VersionId, Code: s3Err.Code, Message: s3Err.Message})
+			continue
+		}
+
+		bypassGovernance, err := bypassGovernanceRetention(r, h.authorizer, userCtx, auth.ObjectARN(bucketName, obj.Key))
+		if err != nil {
+			h.logger.Error().Err(err).Str("bucket", bucketName).Str("key", obj.Key).Msg("failed to authorize governance bypass")
+			result.Errors = append(result.Errors, DeleteError{Key: obj.Key, VersionId: obj.VersionId, Code: ErrInternalError.Code, Message: ErrInternalError.Message})
+			continue
+		}
+
+		output, err := h.objectService.DeleteObject(ctx, service.DeleteObjectInput{
+			BucketName:                bucketName,
+			Key:                       obj.Key,
+			VersionID:                 obj.VersionId,
+			OwnerID:                   userCtx.UserID,
+			BypassGovernanceRetention: bypassGovernance,
+		})
+		if err != nil {
+			s3Err := h.objectS3Error(err, bucketName, obj.Key)
+			result.Errors = append(result.Errors, DeleteError{Key: obj.Key, VersionId: obj.VersionId, Code: s3Err.Code, Message: s3Err.Message})
+			continue
+		}
+
+		// Quiet mode only reports failures
+		if req.Quiet {
+			continue
+		}
+		result.Deleted = append(result.Deleted, DeletedObject{
+			Key:                   obj.Key,
+			VersionId:             obj.VersionId,
+			DeleteMarker:          output.DeleteMarker,
+			DeleteMarkerVersionId: output.DeleteMarkerVersionID,
+		})
+	}
+
+	writeXML(w, http.StatusOK, result)
+}
+
 // ListObjects handles GET /{bucket} requests (v1).
 func (h *ObjectHandler) ListObjects(w http.ResponseWriter, r *http.Request, bucketName string) {
 	ctx := r.Context()
@@ -372,6 +683,22 @@ func (h *ObjectHandler) ListObjects(w http.ResponseWriter, r *http.Request, buck
 		return
 	}
 
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionListBucket, auth.BucketARN(bucketName)) {
+		return
+	}
+
+	encodingType, ok := parseEncodingType(w, r)
+	if !ok {
+		return
+	}
+
+	// Confine the listing to the access key's namespace, if any
+	prefix, ok := userCtx.ScopeListPrefix(bucketName, query.Get("prefix"))
+	if !ok {
+		writeError(w, ErrAccessDenied)
+		return
+	}
+
 	// Parse parameters
 	maxKeys, _ := strconv.Atoi(query.Get("max-keys"))
 	if maxKeys <= 0 {
@@ -381,7 +708,7 @@ func (h *ObjectHandler) ListObjects(w http.ResponseWriter, r *http.Request, buck
 	// List objects
 	output, err := h.objectService.ListObjects(ctx, service.ListObjectsInput{
 		BucketName: bucketName,
-		Prefix:     query.Get("prefix"),
+		Prefix:     prefix,
 		Delimiter:  query.Get("delimiter"),
 		Marker:     query.Get("marker"),
 		MaxKeys:    maxKeys,
@@ -394,10 +721,17 @@ func (h *ObjectHandler) ListObjects(w http.ResponseWriter, r *http.Request, buck
 	}
 
 	// Build response
+	enc := newListEncoder(encodingType)
+	enc.check(output.Prefix, output.Delimiter, query.Get("marker"), output.NextMarker)
+	for _, obj := range output.Contents {
+		enc.check(obj.Key)
+	}
+	enc.check(output.CommonPrefixes...)
+
 	contents := make([]S3Object, len(output.Contents))
 	for i, obj := range output.Contents {
 		contents[i] = S3Object{
-			Key:          obj.Key,
+			Key:          enc.encode(obj.Key),
 			LastModified: formatS3Time(obj.LastModified),
 			ETag:         obj.ETag,
 			Size:         obj.Size,
@@ -407,25 +741,31 @@ func (h *ObjectHandler) ListObjects(w http.ResponseWriter, r *http.Request, buck
 
 	commonPrefixes := make([]CommonPrefix, len(output.CommonPrefixes))
 	for i, prefix := range output.CommonPrefixes {
-		commonPrefixes[i] = CommonPrefix{Prefix: prefix}
+		commonPrefixes[i] = CommonPrefix{Prefix: enc.encode(prefix)}
 	}
 
 	response := ListBucketResult{
 		Xmlns:          "http://s3.amazonaws.com/doc/2006-03-01/",
 		Name:           bucketName,
-		Prefix:         output.Prefix,
-		Marker:         query.Get("marker"),
+		Prefix:         enc.encode(output.Prefix),
+		Marker:         enc.encode(query.Get("marker")),
 		MaxKeys:        output.MaxKeys,
-		Delimiter:      output.Delimiter,
+		Delimiter:      enc.encode(output.Delimiter),
 		IsTruncated:    output.IsTruncated,
 		Contents:       contents,
 		CommonPrefixes: commonPrefixes,
-		NextMarker:     output.NextMarker,
+		NextMarker:     enc.encode(output.NextMarker),
+		EncodingType:   enc.encodingType(),
 	}
 
 	writeXML(w, http.StatusOK, response)
 }
 
+// ListSnapshotParam is the ListObjectsV2 query parameter that, set to "true",
+// lists every page of a paginated scan as of when the first page was listed.
+// Backup tools use it to copy a consistent point-in-time view of a bucket.
+const ListSnapshotParam = "x-alexander-snapshot"
+
 // ListObjectsV2 handles GET /{bucket}?list-type=2 requests.
 func (h *ObjectHandler) ListObjectsV2(w http.ResponseWriter, r *http.Request, bucketName string) {
 	ctx := r.Context()
@@ -440,6 +780,22 @@ func (h *ObjectHandler) ListObjectsV2(w http.ResponseWriter, r *http.Request, bu
 
 	query := r.URL.Query()
 
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionListBucket, auth.BucketARN(bucketName)) {
+		return
+	}
+
+	encodingType, ok := parseEncodingType(w, r)
+	if !ok {
+		return
+	}
+
+	// Confine the listing to the access key's namespace, if any
+	prefix, ok := userCtx.ScopeListPrefix(bucketName, query.Get("prefix"))
+	if !ok {
+		writeError(w, ErrAccessDenied)
+		return
+	}
+
 	// Parse parameters
 	maxKeys, _ := strconv.Atoi(query.Get("max-keys"))
 	if maxKeys <= 0 {
@@ -449,12 +805,13 @@ func (h *ObjectHandler) ListObjectsV2(w http.ResponseWriter, r *http.Request, bu
 	// List objects
 	output, err := h.objectService.ListObjects(ctx, service.ListObjectsInput{
 		BucketName:        bucketName,
-		Prefix:            query.Get("prefix"),
+		Prefix:            prefix,
 		Delimiter:         query.Get("delimiter"),
 		StartAfter:        query.Get("start-after"),
 		ContinuationToken: query.Get("continuation-token"),
 		MaxKeys:           maxKeys,
 		OwnerID:           userCtx.UserID,
+		Snapshot:          query.Get(ListSnapshotParam) == "true",
 	})
 
 	if err != nil {
@@ -463,10 +820,17 @@ func (h *ObjectHandler) ListObjectsV2(w http.ResponseWriter, r *http.Request, bu
 	}
 
 	// Build response
+	enc := newListEncoder(encodingType)
+	enc.check(output.Prefix, output.Delimiter, query.Get("start-after"))
+	for _, obj := range output.Contents {
+		enc.check(obj.Key)
+	}
+	enc.check(output.CommonPrefixes...)
+
 	contents := make([]S3Object, len(output.Contents))
 	for i, obj := range output.Contents {
 		contents[i] = S3Object{
-			Key:          obj.Key,
+			Key:          enc.encode(obj.Key),
 			LastModified: formatS3Time(obj.LastModified),
 			ETag:         obj.ETag,
 			Size:         obj.Size,
@@ -476,22 +840,23 @@ func (h *ObjectHandler) ListObjectsV2(w http.ResponseWriter, r *http.Request, bu
 
 	commonPrefixes := make([]CommonPrefix, len(output.CommonPrefixes))
 	for i, prefix := range output.CommonPrefixes {
-		commonPrefixes[i] = CommonPrefix{Prefix: prefix}
+		commonPrefixes[i] = CommonPrefix{Prefix: enc.encode(prefix)}
 	}
 
 	response := ListBucketResultV2{
 		Xmlns:                 "http://s3.amazonaws.com/doc/2006-03-01/",
 		Name:                  bucketName,
-		Prefix:                output.Prefix,
-		StartAfter:            query.Get("start-after"),
+		Prefix:                enc.encode(output.Prefix),
+		StartAfter:            enc.encode(query.Get("start-after")),
 		ContinuationToken:     query.Get("continuation-token"),
 		NextContinuationToken: output.NextContinuationToken,
 		MaxKeys:               output.MaxKeys,
-		Delimiter:             output.Delimiter,
+		Delimiter:             enc.encode(output.Delimiter),
 		IsTruncated:           output.IsTruncated,
 		Contents:              contents,
 		CommonPrefixes:        commonPrefixes,
 		KeyCount:              output.KeyCount,
+		EncodingType:          enc.encodingType(),
 	}
 
 	writeXML(w, http.StatusOK, response)
@@ -511,6 +876,22 @@ func (h *ObjectHandler) ListObjectVersions(w http.ResponseWriter, r *http.Reques
 
 	query := r.URL.Query()
 
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionListBucketVersions, auth.BucketARN(bucketName)) {
+		return
+	}
+
+	encodingType, ok := parseEncodingType(w, r)
+	if !ok {
+		return
+	}
+
+	// Confine the listing to the access key's namespace, if any
+	prefix, ok := userCtx.ScopeListPrefix(bucketName, query.Get("prefix"))
+	if !ok {
+		writeError(w, ErrAccessDenied)
+		return
+	}
+
 	// Parse parameters
 	maxKeys, _ := strconv.Atoi(query.Get("max-keys"))
 	if maxKeys <= 0 {
@@ -520,7 +901,7 @@ func (h *ObjectHandler) ListObjectVersions(w http.ResponseWriter, r *http.Reques
 	// List versions
 	output, err := h.objectService.ListObjectVersions(ctx, service.ListObjectVersionsInput{
 		BucketName:      bucketName,
-		Prefix:          query.Get("prefix"),
+		Prefix:          prefix,
 		Delimiter:       query.Get("delimiter"),
 		KeyMarker:       query.Get("key-marker"),
 		VersionIDMarker: query.Get("version-id-marker"),
@@ -534,10 +915,20 @@ func (h *ObjectHandler) ListObjectVersions(w http.ResponseWriter, r *http.Reques
 	}
 
 	// Build response
+	enc := newListEncoder(encodingType)
+	enc.check(output.Prefix, output.Delimiter, output.KeyMarker, output.NextKeyMarker)
+	for _, ver := range output.Versions {
+		enc.check(ver.Key)
+	}
+	for _, dm := range output.DeleteMarkers {
+		enc.check(dm.Key)
+	}
+	enc.check(output.CommonPrefixes...)
+
 	versions := make([]S3ObjectVersion, len(output.Versions))
 	for i, ver := range output.Versions {
 		versions[i] = S3ObjectVersion{
-			Key:          ver.Key,
+			Key:          enc.encode(ver.Key),
 			VersionId:    ver.VersionID,
 			IsLatest:     ver.IsLatest,
 			LastModified: formatS3Time(ver.LastModified),
@@ -550,7 +941,7 @@ func (h *ObjectHandler) ListObjectVersions(w http.ResponseWriter, r *http.Reques
 	deleteMarkers := make([]S3DeleteMarker, len(output.DeleteMarkers))
 	for i, dm := range output.DeleteMarkers {
 		deleteMarkers[i] = S3DeleteMarker{
-			Key:          dm.Key,
+			Key:          enc.encode(dm.Key),
 			VersionId:    dm.VersionID,
 			IsLatest:     dm.IsLatest,
 			LastModified: formatS3Time(dm.LastModified),
@@ -559,23 +950,24 @@ func (h *ObjectHandler) ListObjectVersions(w http.ResponseWriter, r *http.Reques
 
 	commonPrefixes := make([]CommonPrefix, len(output.CommonPrefixes))
 	for i, prefix := range output.CommonPrefixes {
-		commonPrefixes[i] = CommonPrefix{Prefix: prefix}
+		commonPrefixes[i] = CommonPrefix{Prefix: enc.encode(prefix)}
 	}
 
 	response := ListVersionsResult{
 		Xmlns:               "http://s3.amazonaws.com/doc/2006-03-01/",
 		Name:                bucketName,
-		Prefix:              output.Prefix,
-		KeyMarker:           output.KeyMarker,
+		Prefix:              enc.encode(output.Prefix),
+		KeyMarker:           enc.encode(output.KeyMarker),
 		VersionIdMarker:     output.VersionIDMarker,
-		NextKeyMarker:       output.NextKeyMarker,
+		NextKeyMarker:       enc.encode(output.NextKeyMarker),
 		NextVersionIdMarker: output.NextVersionIDMarker,
 		MaxKeys:             output.MaxKeys,
-		Delimiter:           output.Delimiter,
+		Delimiter:           enc.encode(output.Delimiter),
 		IsTruncated:         output.IsTruncated,
 		Versions:            versions,
 		DeleteMarkers:       deleteMarkers,
 		CommonPrefixes:      commonPrefixes,
+		EncodingType:        enc.encodingType(),
 	}
 
 	writeXML(w, http.StatusOK, response)
@@ -593,6 +985,10 @@ func (h *ObjectHandler) CopyObject(w http.ResponseWriter, r *http.Request, destB
 		return
 	}
 
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionPutObject, auth.ObjectARN(destBucket, destKey)) {
+		return
+	}
+
 	copySource := r.Header.Get("x-amz-copy-source")
 	if copySource == "" {
 		writeError(w, S3Error{
@@ -603,27 +999,14 @@ func (h *ObjectHandler) CopyObject(w http.ResponseWriter, r *http.Request, destB
 		return
 	}
 
-	// Parse copy source: /bucket/key or bucket/key
-	copySource, _ = url.PathUnescape(copySource)
-	copySource = strings.TrimPrefix(copySource, "/")
-	parts := strings.SplitN(copySource, "/", 2)
-	if len(parts) != 2 {
-		writeError(w, S3Error{
-			Code:           "InvalidArgument",
-			Message:        "Invalid x-amz-copy-source header.",
-			HTTPStatusCode: http.StatusBadRequest,
-		})
+	sourceBucket, sourceKey, sourceVersionID, ok := parseCopySource(copySource)
+	if !ok {
+		writeError(w, ErrInvalidCopySource)
 		return
 	}
 
-	sourceBucket := parts[0]
-	sourceKey := parts[1]
-
-	// Check for version ID in source
-	var sourceVersionID string
-	if idx := strings.Index(sourceKey, "?versionId="); idx != -1 {
-		sourceVersionID = sourceKey[idx+11:]
-		sourceKey = sourceKey[:idx]
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionGetObject, auth.ObjectARN(sourceBucket, sourceKey)) {
+		return
 	}
 
 	// Get metadata directive
@@ -632,6 +1015,27 @@ func (h *ObjectHandler) CopyObject(w http.ResponseWriter, r *http.Request, destB
 		metadataDirective = "COPY"
 	}
 
+	// Get tagging directive
+	taggingDirective := r.Header.Get("x-amz-tagging-directive")
+	var tags map[string]string
+	switch taggingDirective {
+	case "", service.TaggingDirectiveCopy:
+	case service.TaggingDirectiveReplace:
+		var err error
+		tags, err = parseTaggingHeader(r.Header.Get("x-amz-tagging"))
+		if err != nil {
+			h.handleObjectError(w, err, destBucket, destKey)
+			return
+		}
+	default:
+		writeError(w, S3Error{
+			Code:           "InvalidArgument",
+			Message:        "Unknown tagging directive.",
+			HTTPStatusCode: http.StatusBadRequest,
+		})
+		return
+	}
+
 	// Get content type override
 	contentType := r.Header.Get("Content-Type")
 
@@ -641,17 +1045,52 @@ func (h *ObjectHandler) CopyObject(w http.ResponseWriter, r *http.Request, destB
 		metadata = parseMetadata(r)
 	}
 
+	// Parse the SSE-C keys of the source and the copy
+	sourceCustomerKey, err := parseSSECustomerKey(r, copySourceSSECustomerHeaderPrefix)
+	if err != nil {
+		h.handleObjectError(w, err, destBucket, destKey)
+		return
+	}
+	customerKey, err := parseSSECustomerKey(r, sseCustomerHeaderPrefix)
+	if err != nil {
+		h.handleObjectError(w, err, destBucket, destKey)
+		return
+	}
+	if s3Err, ok := checkServerSideEncryption(r); !ok {
+		writeError(w, s3Err)
+		return
+	}
+
+	// Parse the copy's own retention and legal hold
+	objectLock, err := parseObjectLockHeaders(r)
+	if err != nil {
+		h.handleObjectError(w, err, destBucket, destKey)
+		return
+	}
+
 	// Copy object
 	output, err := h.objectService.CopyObject(ctx, service.CopyObjectInput{
-		SourceBucket:      sourceBucket,
-		SourceKey:         sourceKey,
-		SourceVersionID:   sourceVersionID,
-		DestBucket:        destBucket,
-		DestKey:           destKey,
-		ContentType:       contentType,
-		Metadata:          metadata,
-		MetadataDirective: metadataDirective,
-		OwnerID:           userCtx.UserID,
+		SourceBucket:         sourceBucket,
+		SourceKey:            sourceKey,
+		SourceVersionID:      sourceVersionID,
+		DestBucket:           destBucket,
+		DestKey:              destKey,
+		ContentType:          contentType,
+		Metadata:             metadata,
+		MetadataDirective:    metadataDirective,
+		CacheControl:         r.Header.Get("Cache-Control"),
+		ContentDisposition:   r.Header.Get("Content-Disposition"),
+		ContentEncoding:      r.Header.Get("Content-Encoding"),
+		Expires:              r.Header.Get("Expires"),
+		StorageClass:         domain.StorageClass(r.Header.Get("x-amz-storage-class")),
+		Conditions:           parseCopySourceConditions(r),
+		ACL:                  r.Header.Get("x-amz-acl"),
+		TaggingDirective:     taggingDirective,
+		Tags:                 tags,
+		OwnerID:              userCtx.UserID,
+		SourceSSECustomerKey: sourceCustomerKey,
+		SSECustomerKey:       customerKey,
+		ObjectLock:           objectLock,
 	})
 
 	if err != nil {
@@ -659,10 +1098,12 @@ func (h *ObjectHandler) CopyObject(w http.ResponseWriter, r *http.Request, destB
 		return
 	}
 
-	// Set version ID header
-	if output.VersionID != "" && output.VersionID != "null" {
-		w.Header().Set("x-amz-version-id", output.VersionID)
+	// Set version ID headers
+	setVersionIDHeader(w, output.VersionID)
+	if output.SourceVersionID != "" {
+		w.Header().Set("x-amz-copy-source-version-id", output.SourceVersionID)
 	}
+	setSSECustomerHeaders(w, output.SSECustomerKeyMD5)
 
 	// Return XML response
 	response := CopyObjectResult{
@@ -674,6 +1115,79 @@ func (h *ObjectHandler) CopyObject(w http.ResponseWriter, r *http.Request, destB
 	writeXML(w, http.StatusOK, response)
 }
 
+// GetObjectACL handles GET /{bucket}/{key}?acl requests.
+// Objects carry no ACLs of their own; the bucket owner has full control.
+func (h *ObjectHandler) GetObjectACL(w http.ResponseWriter, r *http.Request, bucketName, objectKey string) {
+	ctx := r.Context()
+
+	// Get authenticated user from context
+	userCtx, ok := auth.GetUserContext(ctx)
+	if !ok {
+		h.logger.Error().Msg("no user context found")
+		writeError(w, ErrAccessDenied)
+		return
+	}
+
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionGetObjectAcl, auth.ObjectARN(bucketName, objectKey)) {
+		return
+	}
+
+	// The object must exist
+	_, err := h.objectService.HeadObject(ctx, service.HeadObjectInput{
+		BucketName: bucketName,
+		Key:        objectKey,
+		VersionID:  r.URL.Query().Get("versionId"),
+		OwnerID:    userCtx.UserID,
+	})
+
+	if err != nil {
+		h.handleObjectError(w, err, bucketName, objectKey)
+		return
+	}
+
+	owner := Owner{ID: userCtx.Username, DisplayName: userCtx.Username}
+	writeXML(w, http.StatusOK, cannedACLPolicy(owner, domain.ACLPrivate))
+}
+
+// PutObjectACL handles PUT /{bucket}/{key}?acl requests.
+// Only canned ACLs given in the x-amz-acl header are supported.
+func (h *ObjectHandler) PutObjectACL(w http.ResponseWriter, r *http.Request, bucketName, objectKey string) {
+	ctx := r.Context()
+
+	// Get authenticated user from context
+	userCtx, ok := auth.GetUserContext(ctx)
+	if !ok {
+		h.logger.Error().Msg("no user context found")
+		writeError(w, ErrAccessDenied)
+		return
+	}
+
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionPutObjectAcl, auth.ObjectARN(bucketName, objectKey)) {
+		return
+	}
+
+	acl := r.Header.Get("x-amz-acl")
+	if acl == "" {
+		writeError(w, ErrNotImplemented)
+		return
+	}
+
+	err := h.objectService.PutObjectACL(ctx, service.PutObjectACLInput{
+		BucketName: bucketName,
+		Key:        objectKey,
+		VersionID:  r.URL.Query().Get("versionId"),
+		ACL:        acl,
+		OwnerID:    userCtx.UserID,
+	})
+
+	if err != nil {
+		h.handleObjectError(w, err, bucketName, objectKey)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
 // =============================================================================
 // Helper Methods
 // =============================================================================
@@ -691,6 +1205,225 @@ func parseMetadata(r *http.Request) map[string]string {
 	return metadata
 }
 
+// parseResponseHeaders reads the response-* query parameters that override
+// the headers of a GET or HEAD response. As in S3 they are rejected on
+// anonymous requests; ok is false when an error was written.
+func parseResponseHeaders(w http.ResponseWriter, r *http.Request, userCtx *auth.AuthContext) (service.ResponseHeaders, bool) {
+	query := r.URL.Query()
+	headers := service.ResponseHeaders{
+		ContentType:        query.Get("response-content-type"),
+		ContentDisposition: query.Get("response-content-disposition"),
+		ContentLanguage:    query.Get("response-content-language"),
+		CacheControl:       query.Get("response-cache-control"),
+		ContentEncoding:    query.Get("response-content-encoding"),
+		Expires:            query.Get("response-expires"),
+	}
+	if !headers.IsZero() && userCtx.AuthType == auth.AuthTypeAnonymous {
+		writeError(w, S3Error{
+			Code:           "InvalidRequest",
+			Message:        "Request specific response headers cannot be used for anonymous GET requests.",
+			HTTPStatusCode: http.StatusBadRequest,
+		})
+		return service.ResponseHeaders{}, false
+	}
+	return headers, true
+}
+
+// parseObjectConditions extracts the If-Match, If-None-Match,
+// If-Modified-Since and If-Unmodified-Since headers.
+// Dates that fail to parse are ignored, as S3 does.
+func parseObjectConditions(r *http.Request) service.ObjectConditions {
+	conditions := service.ObjectConditions{
+		IfMatch:     r.Header.Get("If-Match"),
+		IfNoneMatch: r.Header.Get("If-None-Match"),
+	}
+	if t, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil {
+		conditions.IfModifiedSince = &t
+	}
+	if t, err := http.ParseTime(r.Header.Get("If-Unmodified-Since")); err == nil {
+		conditions.IfUnmodifiedSince = &t
+	}
+	return conditions
+}
+
+// objectHeaders are the headers of a GET or HEAD object response. Both are
+// built from the same fields so a HEAD reports exactly what the GET would.
+type objectHeaders struct {
+	ContentType        string
+	ContentLength      int64
+	ContentRange       string
+	ContentDisposition string
+	CacheControl       string
+	ContentEncoding    string
+	ContentLanguage    string
+	Expires            string
+	ETag               string
+	LastModified       time.Time
+	VersionID          string
+	StorageClass       domain.StorageClass
+	SSECustomerKeyMD5  string
+	Metadata           map[string]string
+}
+
+// setObjectHeaders sets the headers of a GET or HEAD object response.
+func setObjectHeaders(w http.ResponseWriter, h objectHeaders) {
+	w.Header().Set("Content-Type", h.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(h.ContentLength, 10))
+	w.Header().Set("Accept-Ranges", "bytes")
+	if h.ContentRange != "" {
+		w.Header().Set("Content-Range", h.ContentRange)
+	}
+	w.Header().Set("ETag", h.ETag)
+	w.Header().Set("Last-Modified", h.LastModified.UTC().Format(http.TimeFormat))
+	if h.StorageClass != "" {
+		w.Header().Set("x-amz-storage-class", string(h.StorageClass))
+	}
+	if h.ContentDisposition != "" {
+		w.Header().Set("Content-Disposition", h.ContentDisposition)
+	}
+	if h.CacheControl != "" {
+		w.Header().Set("Cache-Control", h.CacheControl)
+	}
+	if h.ContentEncoding != "" {
+		w.Header().Set("Content-Encoding", h.ContentEncoding)
+	}
+	if h.ContentLanguage != "" {
+		w.Header().Set("Content-Language", h.ContentLanguage)
+	}
+	if h.Expires != "" {
+		w.Header().Set("Expires", h.Expires)
+	}
+
+	setVersionIDHeader(w, h.VersionID)
+	setSSECustomerHeaders(w, h.SSECustomerKeyMD5)
+
+	// Set metadata headers. Metadata stored by older versions or other tools
+	// may not fit in a header: names that are not header tokens are left out
+	// and counted in x-amz-missing-meta, as S3 does, and values are encoded
+	// so they can not split the response.
+	missing := 0
+	for key, value := range h.Metadata {
+		if !isHeaderToken(key) {
+			missing++
+			continue
+		}
+		w.Header().Set("x-amz-meta-"+key, metadataHeaderValue(value))
+	}
+	if missing > 0 {
+		w.Header().Set("x-amz-missing-meta", strconv.Itoa(missing))
+	}
+}
+
+// metadataHeaderValue returns a metadata value safe to send in a header.
+// Values holding CR, LF or other control characters are RFC 2047 encoded,
+// the encoding S3 uses for metadata that is not plain text.
+func metadataHeaderValue(value string) string {
+	for i := 0; i < len(value); i++ {
+		if c := value[i]; (c < ' ' && c != '\t') || c == 0x7f {
+			return mime.QEncoding.Encode("UTF-8", value)
+		}
+	}
+	return value
+}
+
+// isHeaderToken reports whether name is a valid HTTP header field name.
+func isHeaderToken(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if c <= ' ' || c >= 0x7f || strings.IndexByte(`"(),/:;<=>?@[\]{}`, c) >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// objectStatus is the status of a GET or HEAD object response.
+func objectStatus(contentRange string) int {
+	if contentRange != "" {
+		return http.StatusPartialContent
+	}
+	return http.StatusOK
+}
+
+// sendsStoredChecksum reports whether a GET or HEAD response carries the
+// stored checksum. It covers the whole object, so a range gets none.
+func sendsStoredChecksum(r *http.Request, contentRange, checksum string) bool {
+	return checksumModeEnabled(r) && contentRange == "" && checksum != ""
+}
+
+// parseRequestRange parses the optional Range header. ok is false when the
+// header is present but malformed.
+func parseRequestRange(r *http.Request) (byteRange *service.ByteRange, ok bool) {
+	rangeHeader := r.Header.Get("Range")
+	if rangeHeader == "" {
+		return nil, true
+	}
+	byteRange, err := parseRangeHeader(rangeHeader)
+	if err != nil {
+		return nil, false
+	}
+	return byteRange, true
+}
+
+// writeNotModified writes a 304 response carrying the object's validators.
+func writeNotModified(w http.ResponseWriter, etag string, lastModified time.Time, versionID string) {
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	setVersionIDHeader(w, versionID)
+	w.WriteHeader(http.StatusNotModified)
+}
+
+// parseCopySourceConditions extracts the x-amz-copy-source-if-* headers.
+// Dates that fail to parse are ignored, as S3 does.
+func parseCopySourceConditions(r *http.Request) service.CopySourceConditions {
+	conditions := service.CopySourceConditions{
+		IfMatch:     r.Header.Get("x-amz-copy-source-if-match"),
+		IfNoneMatch: r.Header.Get("x-amz-copy-source-if-none-match"),
+	}
+	if t, err := http.ParseTime(r.Header.Get("x-amz-copy-source-if-modified-since")); err == nil {
+		conditions.IfModifiedSince = &t
+	}
+	if t, err := http.ParseTime(r.Header.Get("x-amz-copy-source-if-unmodified-since")); err == nil {
+		conditions.IfUnmodifiedSince = &t
+	}
+	return conditions
+}
+
+// parseCopySource parses an x-amz-copy-source value: /bucket/key or
+// bucket/key with URL-encoded parts, optionally followed by ?versionId=.
+// The query is split off at the last "?" and only when it carries a
+// versionId, so keys with an unencoded "?" still parse.
+func parseCopySource(copySource string) (bucket, key, versionID string, ok bool) {
+	path := copySource
+	if idx := strings.LastIndex(copySource, "?"); idx != -1 {
+		if query, err := url.ParseQuery(copySource[idx+1:]); err == nil && query.Has("versionId") {
+			path, versionID = copySource[:idx], query.Get("versionId")
+		}
+	}
+
+	path, err := url.PathUnescape(path)
+	if err != nil {
+		return "", "", "", false
+	}
+	bucket, key, found := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	if !found || bucket == "" || key == "" {
+		return "", "", "", false
+	}
+	return bucket, key, versionID, true
+}
+
+// parseExpiresAt parses an x-amz-expires-at value, given as an RFC 3339
+// timestamp or an HTTP date.
+func parseExpiresAt(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return http.ParseTime(value)
+}
+
 // parseRangeHeader parses a Range header into start/end bytes.
 func parseRangeHeader(rangeHeader string) (*service.ByteRange, error) {
 	// Format: bytes=start-end
@@ -704,24 +1437,33 @@ func parseRangeHeader(rangeHeader string) (*service.ByteRange, error) {
 		return nil, fmt.Errorf("invalid range format")
 	}
 
-	var start, end int64
-	var err error
-
-	if parts[0] != "" {
-		start, err = strconv.ParseInt(parts[0], 10, 64)
+	// bytes=-N requests the last N bytes; the service resolves it against the object size
+	if parts[0] == "" {
+		suffix, err := strconv.ParseInt(parts[1], 10, 64)
 		if err != nil {
 			return nil, err
 		}
+		if suffix <= 0 {
+			return nil, fmt.Errorf("invalid suffix length")
+		}
+		return &service.ByteRange{SuffixLength: suffix}, nil
+	}
+
+	start, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || start < 0 {
+		return nil, fmt.Errorf("invalid range start")
 	}
 
+	// bytes=N- reads through the last byte
+	end := int64(-1)
 	if parts[1] != "" {
 		end, err = strconv.ParseInt(parts[1], 10, 64)
 		if err != nil {
 			return nil, err
 		}
-	} else {
-		// If end is not specified, we need to handle it in the service
-		end = -1
+		if end < start {
+			return nil, fmt.Errorf("range end before start")
+		}
 	}
 
 	return &service.ByteRange{Start: start, End: end}, nil
@@ -729,15 +1471,70 @@ func parseRangeHeader(rangeHeader string) (*service.ByteRange, error) {
 
 // handleObjectError maps service errors to S3 error responses.
 func (h *ObjectHandler) handleObjectError(w http.ResponseWriter, err error, bucket, key string) {
-	var s3Err S3Error
-	resource := "/" + bucket
+	switch {
+	case errors.Is(err, domain.ErrObjectDeleted):
+		w.Header().Set("x-amz-delete-marker", "true")
+	case errors.Is(err, domain.ErrVersionIsDeleteMarker):
+		w.Header().Set("x-amz-delete-marker", "true")
+		w.Header().Set("Allow", http.MethodDelete)
+	}
+
+	s3Err := h.objectS3Error(err, bucket, key)
+	s3Err.Resource = "/" + bucket
 	if key != "" {
-		resource += "/" + key
+		s3Err.Resource += "/" + key
 	}
+	writeError(w, s3Err)
+}
 
+// objectS3Error returns the S3 error for a service error.
+func (h *ObjectHandler) objectS3Error(err error, bucket, key string) S3Error {
+	var s3Err S3Error
 	switch {
 	case errors.Is(err, domain.ErrBucketNotFound):
 		s3Err = ErrNoSuchBucket
+	case errors.Is(err, domain.ErrBucketDeleting):
+		s3Err = ErrOperationAborted
+	case errors.Is(err, domain.ErrContentTypeNotAllowed):
+		s3Err = ErrContentTypeNotAllowed
+	case errors.Is(err, domain.ErrEncryptionRequired):
+		s3Err = ErrEncryptionRequired
+	case errors.Is(err, domain.ErrPreconditionFailed):
+		s3Err = ErrPreconditionFailed
+	case errors.Is(err, domain.ErrInvalidRange):
+		s3Err = ErrInvalidRange
+	case errors.Is(err, domain.ErrIncompleteBody):
+		s3Err = ErrIncompleteBody
+	case errors.Is(err, domain.ErrBadDigest):
+		s3Err = ErrBadDigest
+	case errors.Is(err, domain.ErrChecksumMismatch):
+		s3Err = ErrChecksumMismatch
+	case errors.Is(err, domain.ErrInvalidChecksum):
+		s3Err = S3Error{
+			Code:           "InvalidRequest",
+			Message:        err.Error(),
+			HTTPStatusCode: http.StatusBadRequest,
+		}
+	case errors.Is(err, domain.ErrInvalidContinuationToken):
+		s3Err = ErrInvalidContinuationToken
+	case errors.Is(err, domain.ErrInvalidObjectExpiry):
+		s3Err = ErrInvalidObjectExpiry
+	case errors.Is(err, domain.ErrTooManyTags):
+		s3Err = ErrTooManyTags
+	case errors.Is(err, domain.ErrInvalidTag):
+		s3Err = ErrInvalidTag
+	case errors.Is(err, domain.ErrObjectLocked):
+		s3Err = ErrObjectLocked
+	case errors.Is(err, domain.ErrObjectLockNotEnabled):
+		s3Err = ErrObjectLockNotEnabled
+	case errors.Is(err, domain.ErrNoSuchObjectRetention):
+		s3Err = ErrNoSuchObjectRetention
+	case errors.Is(err, domain.ErrInvalidObjectLock):
+		s3Err = S3Error{
+			Code:           "InvalidArgument",
+			Message:        err.Error(),
+			HTTPStatusCode: http.StatusBadRequest,
+		}
 	case errors.Is(err, domain.ErrObjectNotFound):
 		s3Err = S3Error{
 			Code:           "NoSuchKey",
@@ -750,6 +1547,12 @@ func (h *ObjectHandler) handleObjectError(w http.ResponseWriter, err error, buck
 			Message:        "The specified key does not exist.",
 			HTTPStatusCode: http.StatusNotFound,
 		}
+	case errors.Is(err, domain.ErrVersionIsDeleteMarker):
+		s3Err = S3Error{
+			Code:           "MethodNotAllowed",
+			Message:        "The specified method is not allowed against this resource.",
+			HTTPStatusCode: http.StatusMethodNotAllowed,
+		}
 	case errors.Is(err, domain.ErrObjectKeyEmpty):
 		s3Err = S3Error{
 			Code:           "InvalidArgument",
@@ -762,19 +1565,61 @@ func (h *ObjectHandler) handleObjectError(w http.ResponseWriter, err error, buck
 			Message:        "Your key is too long.",
 			HTTPStatusCode: http.StatusBadRequest,
 		}
+	case errors.Is(err, domain.ErrObjectKeyTooDeep):
+		s3Err = S3Error{
+			Code:           "InvalidArgument",
+			Message:        "Your key has too many path segments.",
+			HTTPStatusCode: http.StatusBadRequest,
+		}
 	case errors.Is(err, domain.ErrInvalidVersionID):
 		s3Err = S3Error{
 			Code:           "InvalidArgument",
 			Message:        "Invalid version id specified.",
 			HTTPStatusCode: http.StatusBadRequest,
 		}
+	case errors.Is(err, domain.ErrWriteOffsetMismatch):
+		s3Err = S3Error{
+			Code:           "InvalidWriteOffset",
+			Message:        "The write offset does not match the current object size.",
+			HTTPStatusCode: http.StatusConflict,
+		}
+	case errors.Is(err, domain.ErrAppendNotEnabled):
+		s3Err = ErrNotImplemented
+		s3Err.Message = "Appending to objects is not enabled."
+	case errors.Is(err, domain.ErrAppendNotSupported):
+		s3Err = S3Error{
+			Code:           "InvalidRequest",
+			Message:        "Objects encrypted with a customer-provided key can not be appended to.",
+			HTTPStatusCode: http.StatusBadRequest,
+		}
+	case errors.Is(err, domain.ErrStorageFull):
+		s3Err = ErrInsufficientStorage
+	case errors.Is(err, domain.ErrObjectDataMissing):
+		s3Err = ErrInternalError
+		s3Err.Message = "The object exists but its data is missing from storage."
+	case errors.Is(err, crypto.ErrSSECInvalidAlgorithm):
+		s3Err = ErrInvalidEncryptionAlgorithm
+	case errors.Is(err, crypto.ErrSSECInvalidKey):
+		s3Err = ErrInvalidSSECustomerKey
+	case errors.Is(err, crypto.ErrSSECKeyMD5Mismatch):
+		s3Err = ErrSSECustomerKeyMD5Mismatch
+	case errors.Is(err, service.ErrSSECustomerKeyRequired):
+		s3Err = ErrSSECustomerKeyRequired
+	case errors.Is(err, service.ErrSSECustomerKeyMismatch):
+		s3Err = ErrSSECustomerKeyMismatch
+	case errors.Is(err, service.ErrSSECustomerKeyNotExpected):
+		s3Err = ErrSSECustomerKeyNotExpected
 	case errors.Is(err, service.ErrBucketAccessDenied):
 		s3Err = ErrAccessDenied
+	case errors.Is(err, service.ErrACLNotSupported):
+		s3Err = ErrAccessControlListNotSupported
+	case errors.Is(err, service.ErrObjectACLNotImplemented):
+		s3Err = ErrNotImplemented
+		s3Err.Message = "Object ACLs are not supported; use the bucket ACL instead."
 	default:
 		h.logger.Error().Err(err).Str("bucket", bucket).Str("key", key).Msg("unhandled error")
 		s3Err = ErrInternalError
 	}
 
-	s3Err.Resource = resource
-	writeError(w, s3Err)
+	return s3Err
 }
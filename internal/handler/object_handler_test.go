@@ -0,0 +1,1310 @@
+package handler
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/auth"
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/lock"
+	"github.com/prn-tf/alexander-storage/internal/metrics"
+	"github.com/prn-tf/alexander-storage/internal/repository"
+	"github.com/prn-tf/alexander-storage/internal/service"
+	"github.com/prn-tf/alexander-storage/internal/storage"
+	"github.com/prn-tf/alexander-storage/internal/storage/filesystem"
+)
+
+// stubBucketRepository serves a fixed set of buckets; unused methods panic via the nil embed.
+type stubBucketRepository struct {
+	repository.BucketRepository
+	buckets map[string]*domain.Bucket
+}
+
+func (r *stubBucketRepository) GetByName(ctx context.Context, name string) (*domain.Bucket, error) {
+	if b, ok := r.buckets[name]; ok {
+		return b, nil
+	}
+	return nil, domain.ErrBucketNotFound
+}
+
+func (r *stubBucketRepository) UpdateObjectOwnership(ctx context.Context, id int64, ownership domain.ObjectOwnership) error {
+	for _, b := range r.buckets {
+		if b.ID == id {
+			b.ObjectOwnership = ownership
+			return nil
+		}
+	}
+	return domain.ErrBucketNotFound
+}
+
+func (r *stubBucketRepository) UpdateACL(ctx context.Context, id int64, acl domain.BucketACL) error {
+	for _, b := range r.buckets {
+		if b.ID == id {
+			b.ACL = acl
+			return nil
+		}
+	}
+	return domain.ErrBucketNotFound
+}
+
+func (r *stubBucketRepository) UpdateDefaultRetention(ctx context.Context, id int64, retention *domain.DefaultRetention) error {
+	for _, b := range r.buckets {
+		if b.ID == id {
+			b.DefaultRetention = retention
+			return nil
+		}
+	}
+	return domain.ErrBucketNotFound
+}
+
+// stubObjectRepository serves a fixed set of object versions and latest objects.
+type stubObjectRepository struct {
+	repository.ObjectRepository
+	versions map[uuid.UUID]*domain.Object
+	latest   map[string]*domain.Object
+
+	// listOpts records the options of the last List call.
+	listOpts *repository.ObjectListOptions
+
+	// listResult is returned by List, if set.
+	listResult *repository.ObjectListResult
+
+	// tags holds tag sets by object ID.
+	tags map[int64]map[string]string
+
+	// locks holds object locks by object ID.
+	locks map[int64]*domain.ObjectLock
+
+	// deleted records the IDs passed to Delete.
+	deleted []int64
+}
+
+func (r *stubObjectRepository) GetLock(ctx context.Context, objectID int64) (*domain.ObjectLock, error) {
+	if l, ok := r.locks[objectID]; ok {
+		copied := *l
+		return &copied, nil
+	}
+	return &domain.ObjectLock{}, nil
+}
+
+func (r *stubObjectRepository) PutLock(ctx context.Context, objectID int64, lock *domain.ObjectLock) error {
+	if r.locks == nil {
+		r.locks = make(map[int64]*domain.ObjectLock)
+	}
+	r.locks[objectID] = lock
+	return nil
+}
+
+func (r *stubObjectRepository) Delete(ctx context.Context, id int64) error {
+	r.deleted = append(r.deleted, id)
+	return nil
+}
+
+func (r *stubObjectRepository) GetTags(ctx context.Context, objectID int64) (map[string]string, error) {
+	tags := make(map[string]string)
+	for k, v := range r.tags[objectID] {
+		tags[k] = v
+	}
+	return tags, nil
+}
+
+func (r *stubObjectRepository) PutTags(ctx context.Context, objectID int64, tags map[string]string) error {
+	if r.tags == nil {
+		r.tags = make(map[int64]map[string]string)
+	}
+	r.tags[objectID] = tags
+	return nil
+}
+
+func (r *stubObjectRepository) GetByKey(ctx context.Context, bucketID int64, key string) (*domain.Object, error) {
+	if obj, ok := r.latest[key]; ok && obj.BucketID == bucketID {
+		return obj, nil
+	}
+	return nil, domain.ErrObjectNotFound
+}
+
+func (r *stubObjectRepository) List(ctx context.Context, bucketID int64, opts repository.ObjectListOptions) (*repository.ObjectListResult, error) {
+	r.listOpts = &opts
+	if r.listResult != nil {
+		return r.listResult, nil
+	}
+	return &repository.ObjectListResult{}, nil
+}
+
+func (r *stubObjectRepository) GetByKeyAndVersion(ctx context.Context, bucketID int64, key string, versionID uuid.UUID) (*domain.Object, error) {
+	if obj, ok := r.versions[versionID]; ok && obj.BucketID == bucketID && obj.Key == key {
+		return obj, nil
+	}
+	return nil, domain.ErrObjectNotFound
+}
+
+func newDeleteMarkerTestHandler(t *testing.T) (*ObjectHandler, uuid.UUID) {
+	t.Helper()
+
+	markerID := uuid.New()
+	buckets := &stubBucketRepository{buckets: map[string]*domain.Bucket{
+		"versioned": {ID: 1, Name: "versioned", OwnerID: 1, Versioning: domain.VersioningEnabled},
+	}}
+	objects := &stubObjectRepository{versions: map[uuid.UUID]*domain.Object{
+		markerID: {ID: 1, BucketID: 1, Key: "doc.txt", VersionID: markerID, IsDeleteMarker: true},
+	}}
+
+	svc := service.NewObjectService(objects, nil, buckets, nil, lock.NewNoOpLocker(), zerolog.Nop())
+	return NewObjectHandler(svc, nil, zerolog.Nop()), markerID
+}
+
+func withTestUser(r *http.Request) *http.Request {
+	return withAuthContext(r, &auth.AuthContext{UserID: 1, Username: "tester"})
+}
+
+func withAuthContext(r *http.Request, authCtx *auth.AuthContext) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), auth.AuthContextKey, authCtx))
+}
+
+func TestObjectHandler_DeleteMarkerVersion(t *testing.T) {
+	h, markerID := newDeleteMarkerTestHandler(t)
+
+	tests := []struct {
+		name   string
+		method string
+		call   func(http.ResponseWriter, *http.Request, string, string)
+	}{
+		{name: "GET", method: http.MethodGet, call: h.GetObject},
+		{name: "HEAD", method: http.MethodHead, call: h.HeadObject},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := withTestUser(httptest.NewRequest(tt.method, "/versioned/doc.txt?versionId="+markerID.String(), nil))
+			rec := httptest.NewRecorder()
+
+			tt.call(rec, req, "versioned", "doc.txt")
+
+			require.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+			require.Equal(t, http.MethodDelete, rec.Header().Get("Allow"))
+			require.Equal(t, "true", rec.Header().Get("x-amz-delete-marker"))
+			require.Equal(t, markerID.String(), rec.Header().Get("x-amz-version-id"))
+		})
+	}
+}
+
+func newNamespaceTestHandler(t *testing.T) (*ObjectHandler, *stubObjectRepository) {
+	t.Helper()
+
+	buckets := &stubBucketRepository{buckets: map[string]*domain.Bucket{
+		"shared": {ID: 1, Name: "shared", OwnerID: 1, Versioning: domain.VersioningDisabled},
+	}}
+	objects := &stubObjectRepository{latest: map[string]*domain.Object{
+		"team-a/report.csv": {ID: 1, BucketID: 1, Key: "team-a/report.csv", IsLatest: true},
+		"team-b/report.csv": {ID: 2, BucketID: 1, Key: "team-b/report.csv", IsLatest: true},
+	}}
+
+	svc := service.NewObjectService(objects, nil, buckets, nil, lock.NewNoOpLocker(), zerolog.Nop())
+	return NewObjectHandler(svc, nil, zerolog.Nop()), objects
+}
+
+func withNamespacedUser(r *http.Request) *http.Request {
+	return withAuthContext(r, &auth.AuthContext{
+		UserID:          1,
+		Username:        "team-a",
+		NamespaceBucket: "shared",
+		NamespacePrefix: "team-a/",
+	})
+}
+
+func TestObjectHandler_NamespaceObjectAccess(t *testing.T) {
+	h, _ := newNamespaceTestHandler(t)
+
+	tests := []struct {
+		name       string
+		method     string
+		key        string
+		call       func(http.ResponseWriter, *http.Request, string, string)
+		wantStatus int
+	}{
+		{name: "HEAD in prefix", method: http.MethodHead, key: "team-a/report.csv", call: h.HeadObject, wantStatus: http.StatusOK},
+		{name: "HEAD out of prefix", method: http.MethodHead, key: "team-b/report.csv", call: h.HeadObject, wantStatus: http.StatusForbidden},
+		{name: "GET out of prefix", method: http.MethodGet, key: "team-b/report.csv", call: h.GetObject, wantStatus: http.StatusForbidden},
+		{name: "PUT out of prefix", method: http.MethodPut, key: "team-b/new.csv", call: h.PutObject, wantStatus: http.StatusForbidden},
+		{name: "DELETE out of prefix", method: http.MethodDelete, key: "team-b/report.csv", call: h.DeleteObject, wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := withNamespacedUser(httptest.NewRequest(tt.method, "/shared/"+tt.key, strings.NewReader("data")))
+			rec := httptest.NewRecorder()
+
+			tt.call(rec, req, "shared", tt.key)
+
+			require.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
+
+func TestObjectHandler_NamespaceScopesListing(t *testing.T) {
+	h, objects := newNamespaceTestHandler(t)
+
+	tests := []struct {
+		name       string
+		prefix     string
+		wantStatus int
+		wantPrefix string
+	}{
+		{name: "no prefix is narrowed", prefix: "", wantStatus: http.StatusOK, wantPrefix: "team-a/"},
+		{name: "partial prefix is narrowed", prefix: "team", wantStatus: http.StatusOK, wantPrefix: "team-a/"},
+		{name: "prefix inside namespace is kept", prefix: "team-a/2024/", wantStatus: http.StatusOK, wantPrefix: "team-a/2024/"},
+		{name: "prefix outside namespace is denied", prefix: "team-b/", wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects.listOpts = nil
+			req := withNamespacedUser(httptest.NewRequest(http.MethodGet, "/shared?list-type=2&prefix="+tt.prefix, nil))
+			rec := httptest.NewRecorder()
+
+			h.ListObjectsV2(rec, req, "shared")
+
+			require.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantStatus != http.StatusOK {
+				require.Nil(t, objects.listOpts)
+				return
+			}
+			require.NotNil(t, objects.listOpts)
+			require.Equal(t, tt.wantPrefix, objects.listOpts.Prefix)
+		})
+	}
+}
+
+func TestObjectHandler_ListEncodesXMLUnsafeKeys(t *testing.T) {
+	h, objects := newNamespaceTestHandler(t)
+
+	const unsafeKey = "logs/\x01bell&<tag>.txt"
+	objects.listResult = &repository.ObjectListResult{
+		Objects: []*domain.ObjectInfo{
+			{Key: unsafeKey, ETag: `"abc"`, Size: 4, StorageClass: domain.StorageClassStandard},
+			{Key: "logs/plain & simple.txt", ETag: `"def"`, Size: 4, StorageClass: domain.StorageClassStandard},
+		},
+		KeyCount: 2,
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		call  func(http.ResponseWriter, *http.Request, string)
+	}{
+		{name: "v1", query: "", call: h.ListObjects},
+		{name: "v2", query: "list-type=2", call: h.ListObjectsV2},
+		{name: "v2 encoding-type=url", query: "list-type=2&encoding-type=url", call: h.ListObjectsV2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := withTestUser(httptest.NewRequest(http.MethodGet, "/shared?"+tt.query, nil))
+			rec := httptest.NewRecorder()
+
+			tt.call(rec, req, "shared")
+			require.Equal(t, http.StatusOK, rec.Code)
+
+			// The document must parse as XML
+			var result struct {
+				EncodingType string `xml:"EncodingType"`
+				Contents     []struct {
+					Key string `xml:"Key"`
+				} `xml:"Contents"`
+			}
+			require.NoError(t, xml.Unmarshal(rec.Body.Bytes(), &result))
+
+			// A key XML cannot carry forces URL encoding, which must round-trip
+			require.Equal(t, "url", result.EncodingType)
+			require.Len(t, result.Contents, 2)
+			keys := make([]string, len(result.Contents))
+			for i, c := range result.Contents {
+				key, err := url.QueryUnescape(c.Key)
+				require.NoError(t, err)
+				keys[i] = key
+			}
+			require.Equal(t, []string{unsafeKey, "logs/plain & simple.txt"}, keys)
+		})
+	}
+
+	t.Run("invalid encoding-type", func(t *testing.T) {
+		req := withTestUser(httptest.NewRequest(http.MethodGet, "/shared?encoding-type=base64", nil))
+		rec := httptest.NewRecorder()
+
+		h.ListObjects(rec, req, "shared")
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestObjectHandler_ListEscapesXMLSpecialCharacters(t *testing.T) {
+	h, objects := newNamespaceTestHandler(t)
+
+	const key = "a&b<c>\"d'e.txt"
+	objects.listResult = &repository.ObjectListResult{
+		Objects:  []*domain.ObjectInfo{{Key: key, ETag: `"abc"`, StorageClass: domain.StorageClassStandard}},
+		KeyCount: 1,
+	}
+
+	req := withTestUser(httptest.NewRequest(http.MethodGet, "/shared?list-type=2", nil))
+	rec := httptest.NewRecorder()
+
+	h.ListObjectsV2(rec, req, "shared")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var result ListBucketResultV2
+	require.NoError(t, xml.Unmarshal(rec.Body.Bytes(), &result))
+	require.Empty(t, result.EncodingType)
+	require.Len(t, result.Contents, 1)
+	require.Equal(t, key, result.Contents[0].Key)
+}
+
+func TestObjectHandler_NullVersionHeader(t *testing.T) {
+	buckets := &stubBucketRepository{buckets: map[string]*domain.Bucket{
+		"suspended": {ID: 1, Name: "suspended", OwnerID: 1, Versioning: domain.VersioningSuspended},
+	}}
+	objects := &stubObjectRepository{versions: map[uuid.UUID]*domain.Object{
+		uuid.Nil: {ID: 1, BucketID: 1, Key: "doc.txt", VersionID: uuid.Nil, IsLatest: true},
+	}}
+	svc := service.NewObjectService(objects, nil, buckets, nil, lock.NewNoOpLocker(), zerolog.Nop())
+	h := NewObjectHandler(svc, nil, zerolog.Nop())
+
+	req := withTestUser(httptest.NewRequest(http.MethodHead, "/suspended/doc.txt?versionId=null", nil))
+	rec := httptest.NewRecorder()
+
+	h.HeadObject(rec, req, "suspended", "doc.txt")
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, domain.NullVersionID, rec.Header().Get("x-amz-version-id"))
+}
+
+func TestObjectHandler_CopySourcePreconditionFailed(t *testing.T) {
+	contentHash := "abc123hash"
+	buckets := &stubBucketRepository{buckets: map[string]*domain.Bucket{
+		"src": {ID: 1, Name: "src", OwnerID: 1},
+	}}
+	objects := &stubObjectRepository{latest: map[string]*domain.Object{
+		"doc.txt": {
+			ID: 1, BucketID: 1, Key: "doc.txt", ETag: `"abc123"`, ContentHash: &contentHash, IsLatest: true,
+			CreatedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		},
+	}}
+	svc := service.NewObjectService(objects, nil, buckets, nil, lock.NewNoOpLocker(), zerolog.Nop())
+	h := NewObjectHandler(svc, nil, zerolog.Nop())
+
+	tests := []struct {
+		name   string
+		header string
+		value  string
+	}{
+		{name: "if-match", header: "x-amz-copy-source-if-match", value: `"other"`},
+		{name: "if-none-match", header: "x-amz-copy-source-if-none-match", value: `"abc123"`},
+		{name: "if-modified-since", header: "x-amz-copy-source-if-modified-since", value: "Thu, 02 May 2024 12:00:00 GMT"},
+		{name: "if-unmodified-since", header: "x-amz-copy-source-if-unmodified-since", value: "Tue, 30 Apr 2024 12:00:00 GMT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := withTestUser(httptest.NewRequest(http.MethodPut, "/src/copy.txt", nil))
+			req.Header.Set("x-amz-copy-source", "/src/doc.txt")
+			req.Header.Set(tt.header, tt.value)
+			rec := httptest.NewRecorder()
+
+			h.CopyObject(rec, req, "src", "copy.txt")
+
+			require.Equal(t, http.StatusPreconditionFailed, rec.Code)
+			require.Contains(t, rec.Body.String(), "<Code>PreconditionFailed</Code>")
+		})
+	}
+}
+
+func TestObjectHandler_ConditionalRead(t *testing.T) {
+	const body = "cached body"
+	hash := "abc123hash"
+	buckets := &stubBucketRepository{buckets: map[string]*domain.Bucket{
+		"cdn": {ID: 1, Name: "cdn", OwnerID: 1},
+	}}
+	objects := &stubObjectRepository{latest: map[string]*domain.Object{
+		"app.js": {
+			ID: 1, BucketID: 1, Key: "app.js", ETag: `"abc123"`, ContentHash: &hash, Size: int64(len(body)), IsLatest: true,
+			CreatedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		},
+	}}
+	blobs := &stubStorage{blobs: map[string]string{hash: body}}
+	svc := service.NewObjectService(objects, nil, buckets, blobs, lock.NewNoOpLocker(), zerolog.Nop())
+	h := NewObjectHandler(svc, nil, zerolog.Nop())
+
+	const (
+		before = "Tue, 30 Apr 2024 12:00:00 GMT"
+		at     = "Wed, 01 May 2024 12:00:00 GMT"
+		after  = "Thu, 02 May 2024 12:00:00 GMT"
+	)
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    int
+	}{
+		{name: "if-none-match matching", headers: map[string]string{"If-None-Match": `"abc123"`}, want: http.StatusNotModified},
+		{name: "if-none-match in list", headers: map[string]string{"If-None-Match": `"other", W/"abc123"`}, want: http.StatusNotModified},
+		{name: "if-none-match wildcard", headers: map[string]string{"If-None-Match": "*"}, want: http.StatusNotModified},
+		{name: "if-none-match other", headers: map[string]string{"If-None-Match": `"other"`}, want: http.StatusOK},
+		{name: "if-match matching", headers: map[string]string{"If-Match": `"abc123"`}, want: http.StatusOK},
+		{name: "if-match wildcard", headers: map[string]string{"If-Match": "*"}, want: http.StatusOK},
+		{name: "if-match other", headers: map[string]string{"If-Match": `"other"`}, want: http.StatusPreconditionFailed},
+		{name: "if-modified-since before", headers: map[string]string{"If-Modified-Since": before}, want: http.StatusOK},
+		{name: "if-modified-since at", headers: map[string]string{"If-Modified-Since": at}, want: http.StatusNotModified},
+		{name: "if-modified-since after", headers: map[string]string{"If-Modified-Since": after}, want: http.StatusNotModified},
+		{name: "if-unmodified-since after", headers: map[string]string{"If-Unmodified-Since": after}, want: http.StatusOK},
+		{name: "if-unmodified-since before", headers: map[string]string{"If-Unmodified-Since": before}, want: http.StatusPreconditionFailed},
+		{name: "if-modified-since invalid date ignored", headers: map[string]string{"If-Modified-Since": "yesterday"}, want: http.StatusOK},
+		{
+			name:    "if-none-match overrides if-modified-since",
+			headers: map[string]string{"If-None-Match": `"other"`, "If-Modified-Since": after},
+			want:    http.StatusOK,
+		},
+		{
+			name:    "if-match overrides if-unmodified-since",
+			headers: map[string]string{"If-Match": `"abc123"`, "If-Unmodified-Since": before},
+			want:    http.StatusOK,
+		},
+		{
+			name:    "precondition failure wins over not modified",
+			headers: map[string]string{"If-Match": `"other"`, "If-None-Match": `"abc123"`},
+			want:    http.StatusPreconditionFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		for _, method := range []string{http.MethodGet, http.MethodHead} {
+			t.Run(method+" "+tt.name, func(t *testing.T) {
+				req := withTestUser(httptest.NewRequest(method, "/cdn/app.js", nil))
+				for k, v := range tt.headers {
+					req.Header.Set(k, v)
+				}
+				rec := httptest.NewRecorder()
+
+				if method == http.MethodGet {
+					h.GetObject(rec, req, "cdn", "app.js")
+				} else {
+					h.HeadObject(rec, req, "cdn", "app.js")
+				}
+
+				require.Equal(t, tt.want, rec.Code)
+				switch tt.want {
+				case http.StatusNotModified:
+					require.Empty(t, rec.Body.String())
+					require.Equal(t, `"abc123"`, rec.Header().Get("ETag"))
+					require.Equal(t, at, rec.Header().Get("Last-Modified"))
+				case http.StatusPreconditionFailed:
+					require.Empty(t, rec.Header().Get("ETag"))
+				case http.StatusOK:
+					if method == http.MethodGet {
+						require.Equal(t, body, rec.Body.String())
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestObjectHandler_CopyTaggingDirective(t *testing.T) {
+	contentHash := "abc123hash"
+	buckets := &stubBucketRepository{buckets: map[string]*domain.Bucket{
+		"src": {ID: 1, Name: "src", OwnerID: 1},
+	}}
+	objects := &stubObjectRepository{latest: map[string]*domain.Object{
+		"doc.txt": {ID: 1, BucketID: 1, Key: "doc.txt", ETag: `"abc123"`, ContentHash: &contentHash, IsLatest: true},
+	}}
+	svc := service.NewObjectService(objects, nil, buckets, nil, lock.NewNoOpLocker(), zerolog.Nop())
+	h := NewObjectHandler(svc, nil, zerolog.Nop())
+
+	tests := []struct {
+		name      string
+		directive string
+		tagging   string
+		status    int
+		code      string
+	}{
+		{name: "unknown directive", directive: "MERGE", status: http.StatusBadRequest, code: "InvalidArgument"},
+		{name: "replace with duplicate tag keys", directive: "REPLACE", tagging: "team=a&team=b", status: http.StatusBadRequest, code: "InvalidTag"},
+		{name: "replace with too many tags", directive: "REPLACE", tagging: "a=1&b=2&c=3&d=4&e=5&f=6&g=7&h=8&i=9&j=10&k=11", status: http.StatusBadRequest, code: "BadRequest"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := withTestUser(httptest.NewRequest(http.MethodPut, "/src/copy.txt", nil))
+			req.Header.Set("x-amz-copy-source", "/src/doc.txt")
+			req.Header.Set("x-amz-tagging-directive", tt.directive)
+			if tt.tagging != "" {
+				req.Header.Set("x-amz-tagging", tt.tagging)
+			}
+			rec := httptest.NewRecorder()
+
+			h.CopyObject(rec, req, "src", "copy.txt")
+
+			requireErrorCode(t, rec, tt.status, tt.code)
+		})
+	}
+}
+
+func TestParseCopySource(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		bucket    string
+		key       string
+		versionID string
+		ok        bool
+	}{
+		{name: "leading slash", value: "/src/doc.txt", bucket: "src", key: "doc.txt", ok: true},
+		{name: "no leading slash", value: "src/dir/doc.txt", bucket: "src", key: "dir/doc.txt", ok: true},
+		{name: "version", value: "/src/doc.txt?versionId=v%2B1", bucket: "src", key: "doc.txt", versionID: "v+1", ok: true},
+		{name: "literal question mark", value: "/src/a?b/c.txt", bucket: "src", key: "a?b/c.txt", ok: true},
+		{name: "literal question mark with version", value: "/src/a?b/c.txt?versionId=v1", bucket: "src", key: "a?b/c.txt", versionID: "v1", ok: true},
+		{name: "encoded question mark", value: "/src/a%3FversionId=x", bucket: "src", key: "a?versionId=x", ok: true},
+		{name: "encoded space", value: "/src/my%20file.txt", bucket: "src", key: "my file.txt", ok: true},
+		{name: "encoded slash", value: "src%2Fdir%2Fdoc.txt", bucket: "src", key: "dir/doc.txt", ok: true},
+		{name: "missing key", value: "/src/", ok: false},
+		{name: "bucket only", value: "src", ok: false},
+		{name: "invalid escape", value: "/src/100%.txt", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bucket, key, versionID, ok := parseCopySource(tt.value)
+			require.Equal(t, tt.ok, ok)
+			require.Equal(t, tt.bucket, bucket)
+			require.Equal(t, tt.key, key)
+			require.Equal(t, tt.versionID, versionID)
+		})
+	}
+}
+
+func TestObjectHandler_CopyObjectSourceKeys(t *testing.T) {
+	h, _, _ := newPutObjectTestHandler(t)
+
+	tests := []struct {
+		name       string
+		sourceKey  string
+		copySource string
+	}{
+		{name: "literal question mark", sourceKey: "a?b/c.txt", copySource: "/uploads/a?b/c.txt"},
+		{name: "encoded space", sourceKey: "my file.txt", copySource: "/uploads/my%20file.txt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.PutObject(rec, withTestUser(httptest.NewRequest(http.MethodPut, "/uploads/x", strings.NewReader(tt.name))), "uploads", tt.sourceKey)
+			require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+			destKey := "copies/" + tt.name
+			req := withTestUser(httptest.NewRequest(http.MethodPut, "/uploads/x", nil))
+			req.Header.Set("x-amz-copy-source", tt.copySource)
+			rec = httptest.NewRecorder()
+			h.CopyObject(rec, req, "uploads", destKey)
+			require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+			rec = httptest.NewRecorder()
+			h.GetObject(rec, withTestUser(httptest.NewRequest(http.MethodGet, "/uploads/x", nil)), "uploads", destKey)
+			require.Equal(t, http.StatusOK, rec.Code)
+			require.Equal(t, tt.name, rec.Body.String())
+		})
+	}
+}
+
+// denyActionAuthorizer rejects one action and records every authorized resource.
+type denyActionAuthorizer struct {
+	denied    auth.Action
+	resources []string
+}
+
+func (a *denyActionAuthorizer) Authorize(ctx context.Context, principal *auth.AuthContext, action auth.Action, resource string) error {
+	a.resources = append(a.resources, resource)
+	if action == a.denied {
+		return auth.ErrAccessDenied
+	}
+	return nil
+}
+
+func TestObjectHandler_AuthorizerDeniesAction(t *testing.T) {
+	buckets := &stubBucketRepository{buckets: map[string]*domain.Bucket{
+		"photos": {ID: 1, Name: "photos", OwnerID: 1, Versioning: domain.VersioningDisabled},
+	}}
+	objects := &stubObjectRepository{latest: map[string]*domain.Object{
+		"cat.jpg": {ID: 1, BucketID: 1, Key: "cat.jpg", IsLatest: true},
+	}}
+	authorizer := &denyActionAuthorizer{denied: auth.ActionDeleteObject}
+	svc := service.NewObjectService(objects, nil, buckets, nil, lock.NewNoOpLocker(), zerolog.Nop())
+	h := NewObjectHandler(svc, authorizer, zerolog.Nop())
+
+	// The denied action is rejected before reaching the service
+	req := withTestUser(httptest.NewRequest(http.MethodDelete, "/photos/cat.jpg", nil))
+	rec := httptest.NewRecorder()
+	h.DeleteObject(rec, req, "photos", "cat.jpg")
+
+	require.Equal(t, http.StatusForbidden, rec.Code)
+	require.Contains(t, rec.Body.String(), "<Code>AccessDenied</Code>")
+	require.Equal(t, []string{"arn:aws:s3:::photos/cat.jpg"}, authorizer.resources)
+
+	// Other actions on the same object are allowed
+	req = withTestUser(httptest.NewRequest(http.MethodHead, "/photos/cat.jpg", nil))
+	rec = httptest.NewRecorder()
+	h.HeadObject(rec, req, "photos", "cat.jpg")
+
+	require.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestParseRangeHeader(t *testing.T) {
+	tests := []struct {
+		header  string
+		want    service.ByteRange
+		wantErr bool
+	}{
+		{header: "bytes=0-99", want: service.ByteRange{Start: 0, End: 99}},
+		{header: "bytes=500-", want: service.ByteRange{Start: 500, End: -1}},
+		{header: "bytes=-500", want: service.ByteRange{SuffixLength: 500}},
+		{header: "bytes=-0", wantErr: true},
+		{header: "bytes=-", wantErr: true},
+		{header: "bytes=10-5", wantErr: true},
+		{header: "items=0-1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			got, err := parseRangeHeader(tt.header)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, *got)
+		})
+	}
+}
+
+func TestObjectHandler_DeleteObjects(t *testing.T) {
+	h, _ := newNamespaceTestHandler(t)
+
+	body := `<Delete>
+		<Object><Key>team-a/missing.csv</Key></Object>
+		<Object><Key>team-b/report.csv</Key></Object>
+		<Object><Key>team-a/old.csv</Key><VersionId>not-a-version</VersionId></Object>
+	</Delete>`
+
+	t.Run("partial failure", func(t *testing.T) {
+		req := withNamespacedUser(httptest.NewRequest(http.MethodPost, "/shared?delete", strings.NewReader(body)))
+		rec := httptest.NewRecorder()
+
+		h.DeleteObjects(rec, req, "shared")
+
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var result DeleteObjectsResult
+		require.NoError(t, xml.Unmarshal(rec.Body.Bytes(), &result))
+		require.Equal(t, []DeletedObject{{Key: "team-a/missing.csv"}}, result.Deleted)
+		require.Len(t, result.Errors, 2)
+		require.Equal(t, DeleteError{Key: "team-b/report.csv", Code: "AccessDenied", Message: "Access Denied"}, result.Errors[0])
+		require.Equal(t, "team-a/old.csv", result.Errors[1].Key)
+		require.Equal(t, "not-a-version", result.Errors[1].VersionId)
+		require.Equal(t, "InvalidArgument", result.Errors[1].Code)
+	})
+
+	t.Run("quiet", func(t *testing.T) {
+		quiet := strings.Replace(body, "<Delete>", "<Delete><Quiet>true</Quiet>", 1)
+		req := withNamespacedUser(httptest.NewRequest(http.MethodPost, "/shared?delete", strings.NewReader(quiet)))
+		rec := httptest.NewRecorder()
+
+		h.DeleteObjects(rec, req, "shared")
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.NotContains(t, rec.Body.String(), "<Deleted>")
+		require.Equal(t, 2, strings.Count(rec.Body.String(), "<Error>"))
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		req := withNamespacedUser(httptest.NewRequest(http.MethodPost, "/shared?delete", strings.NewReader("<Delete></Delete>")))
+		rec := httptest.NewRecorder()
+
+		h.DeleteObjects(rec, req, "shared")
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Contains(t, rec.Body.String(), "<Code>MalformedXML</Code>")
+	})
+}
+
+// stubStorage serves fixed blob contents by hash.
+type stubStorage struct {
+	storage.Backend
+	blobs map[string]string
+}
+
+func (s *stubStorage) Retrieve(ctx context.Context, contentHash string) (io.ReadCloser, error) {
+	content, ok := s.blobs[contentHash]
+	if !ok {
+		return nil, storage.ErrBlobNotFound
+	}
+	return io.NopCloser(strings.NewReader(content)), nil
+}
+
+func TestObjectHandler_GetObjectChecksumTrailer(t *testing.T) {
+	const body = "trailing checksum body"
+	hash := "abc123"
+
+	buckets := &stubBucketRepository{buckets: map[string]*domain.Bucket{
+		"photos": {ID: 1, Name: "photos", OwnerID: 1},
+	}}
+	objects := &stubObjectRepository{latest: map[string]*domain.Object{
+		"cat.txt": {ID: 1, BucketID: 1, Key: "cat.txt", IsLatest: true, ContentHash: &hash, Size: int64(len(body))},
+	}}
+	blobs := &stubStorage{blobs: map[string]string{hash: body}}
+	svc := service.NewObjectService(objects, nil, buckets, blobs, lock.NewNoOpLocker(), zerolog.Nop())
+	h := NewObjectHandler(svc, nil, zerolog.Nop())
+	h.SetChecksumTrailers(true)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.GetObject(w, withTestUser(r), "photos", "cat.txt")
+	}))
+	defer server.Close()
+
+	get := func(headers map[string]string) *http.Response {
+		req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		resp, err := server.Client().Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	sha := sha256.Sum256([]byte(body))
+	crc := crc32.ChecksumIEEE([]byte(body))
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		trailer string
+		want    string
+	}{
+		{name: "default SHA256", headers: map[string]string{"TE": "trailers"}, trailer: "X-Amz-Checksum-Sha256", want: base64.StdEncoding.EncodeToString(sha[:])},
+		{name: "CRC32", headers: map[string]string{"TE": "trailers", "x-amz-checksum-algorithm": "crc32"}, trailer: "X-Amz-Checksum-Crc32", want: base64.StdEncoding.EncodeToString(binary.BigEndian.AppendUint32(nil, crc))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := get(tt.headers)
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+
+			// Trailers are only populated once the body has been read
+			data, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.Equal(t, body, string(data))
+			require.Equal(t, tt.want, resp.Trailer.Get(tt.trailer))
+		})
+	}
+
+	t.Run("not advertised", func(t *testing.T) {
+		resp := get(nil)
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Equal(t, body, string(data))
+		require.Empty(t, resp.Trailer)
+		require.Equal(t, int64(len(body)), resp.ContentLength)
+	})
+
+	t.Run("unsupported algorithm", func(t *testing.T) {
+		resp := get(map[string]string{"TE": "trailers", "x-amz-checksum-algorithm": "MD5"})
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+// newPutObjectTestHandler returns a handler that stores objects in the
+// "uploads" bucket on a filesystem backend.
+func newPutObjectTestHandler(t *testing.T) (*ObjectHandler, *memoryObjectRepository, storage.Backend) {
+	t.Helper()
+
+	store, err := filesystem.NewStorage(filesystem.Config{
+		DataDir: t.TempDir(),
+		TempDir: t.TempDir(),
+	}, zerolog.Nop())
+	require.NoError(t, err)
+
+	buckets := &stubBucketRepository{buckets: map[string]*domain.Bucket{
+		"uploads": {ID: 1, Name: "uploads", OwnerID: 1, Versioning: domain.VersioningDisabled},
+	}}
+	objects := &memoryObjectRepository{objects: make(map[string]*domain.Object)}
+	blobs := &memoryBlobRepository{refs: make(map[string]int32)}
+	svc := service.NewObjectService(objects, blobs, buckets, store, lock.NewNoOpLocker(), zerolog.Nop())
+	return NewObjectHandler(svc, nil, zerolog.Nop()), objects, store
+}
+
+func TestObjectHandler_PutObjectRequireEncryption(t *testing.T) {
+	store, err := filesystem.NewStorage(filesystem.Config{
+		DataDir: t.TempDir(),
+		TempDir: t.TempDir(),
+	}, zerolog.Nop())
+	require.NoError(t, err)
+
+	buckets := &stubBucketRepository{buckets: map[string]*domain.Bucket{
+		"secure": {ID: 1, Name: "secure", OwnerID: 1, Versioning: domain.VersioningDisabled, RequireEncryption: true},
+	}}
+	objects := &memoryObjectRepository{objects: make(map[string]*domain.Object)}
+	blobs := &memoryBlobRepository{refs: make(map[string]int32)}
+	svc := service.NewObjectService(objects, blobs, buckets, store, lock.NewNoOpLocker(), zerolog.Nop())
+	h := NewObjectHandler(svc, nil, zerolog.Nop())
+
+	customerKey := make([]byte, 32)
+	customerKeyMD5 := md5.Sum(customerKey)
+
+	tests := []struct {
+		name   string
+		sse    string
+		ssec   bool
+		status int
+		code   string
+	}{
+		{name: "unencrypted", status: http.StatusForbidden, code: "AccessDenied"},
+		// Nothing encrypts blobs at rest with server-managed keys
+		{name: "sse-s3", sse: "AES256", status: http.StatusNotImplemented, code: "NotImplemented"},
+		{name: "unknown algorithm", sse: "aws:kms", status: http.StatusBadRequest, code: "InvalidEncryptionAlgorithmError"},
+		{name: "sse-c", ssec: true, status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := strings.ReplaceAll(tt.name, " ", "-") + ".txt"
+			req := withTestUser(httptest.NewRequest(http.MethodPut, "/secure/"+key, strings.NewReader("secret")))
+			if tt.sse != "" {
+				req.Header.Set("x-amz-server-side-encryption", tt.sse)
+			}
+			if tt.ssec {
+				req.Header.Set("x-amz-server-side-encryption-customer-algorithm", "AES256")
+				req.Header.Set("x-amz-server-side-encryption-customer-key", base64.StdEncoding.EncodeToString(customerKey))
+				req.Header.Set("x-amz-server-side-encryption-customer-key-MD5", base64.StdEncoding.EncodeToString(customerKeyMD5[:]))
+			}
+			rec := httptest.NewRecorder()
+
+			h.PutObject(rec, req, "secure", key)
+
+			if tt.code != "" {
+				requireErrorCode(t, rec, tt.status, tt.code)
+				require.NotContains(t, objects.objects, key)
+				return
+			}
+			require.Equal(t, tt.status, rec.Code)
+			require.Contains(t, objects.objects, key)
+		})
+	}
+}
+
+func TestObjectHandler_PutObjectContentMD5(t *testing.T) {
+	h, objects, _ := newPutObjectTestHandler(t)
+
+	const content = "the quick brown fox"
+	sum := md5.Sum([]byte(content))
+	wrong := md5.Sum([]byte("something else"))
+
+	tests := []struct {
+		name       string
+		contentMD5 string
+		status     int
+		code       string
+	}{
+		{name: "correct digest", contentMD5: base64.StdEncoding.EncodeToString(sum[:]), status: http.StatusOK},
+		{name: "missing header", status: http.StatusOK},
+		{name: "wrong digest", contentMD5: base64.StdEncoding.EncodeToString(wrong[:]), status: http.StatusBadRequest, code: "BadDigest"},
+		{name: "malformed digest", contentMD5: "not-base64!", status: http.StatusBadRequest, code: "InvalidDigest"},
+		{name: "short digest", contentMD5: base64.StdEncoding.EncodeToString(sum[:8]), status: http.StatusBadRequest, code: "InvalidDigest"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := strings.ReplaceAll(tt.name, " ", "-") + ".txt"
+			req := withTestUser(httptest.NewRequest(http.MethodPut, "/uploads/"+key, strings.NewReader(content)))
+			if tt.contentMD5 != "" {
+				req.Header.Set("Content-MD5", tt.contentMD5)
+			}
+			rec := httptest.NewRecorder()
+
+			h.PutObject(rec, req, "uploads", key)
+
+			if tt.code != "" {
+				requireErrorCode(t, rec, tt.status, tt.code)
+				require.NotContains(t, objects.objects, key)
+				return
+			}
+			require.Equal(t, tt.status, rec.Code)
+			require.Contains(t, objects.objects, key)
+		})
+	}
+}
+
+func TestObjectHandler_PutObjectBadDigestDiscardsBlob(t *testing.T) {
+	h, _, store := newPutObjectTestHandler(t)
+
+	put := func(key, body, contentMD5 string) *httptest.ResponseRecorder {
+		req := withTestUser(httptest.NewRequest(http.MethodPut, "/uploads/"+key, strings.NewReader(body)))
+		req.Header.Set("Content-MD5", contentMD5)
+		rec := httptest.NewRecorder()
+		h.PutObject(rec, req, "uploads", key)
+		return rec
+	}
+	wrong := md5.Sum([]byte("something else"))
+	badDigest := base64.StdEncoding.EncodeToString(wrong[:])
+	ctx := context.Background()
+
+	// Rejected content that no object references is not kept
+	requireErrorCode(t, put("new.txt", "corrupted", badDigest), http.StatusBadRequest, "BadDigest")
+	hash := sha256.Sum256([]byte("corrupted"))
+	exists, err := store.Exists(ctx, hex.EncodeToString(hash[:]))
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	// Rejected content shared with a stored object is kept
+	sum := md5.Sum([]byte("shared"))
+	require.Equal(t, http.StatusOK, put("first.txt", "shared", base64.StdEncoding.EncodeToString(sum[:])).Code)
+	requireErrorCode(t, put("second.txt", "shared", badDigest), http.StatusBadRequest, "BadDigest")
+	hash = sha256.Sum256([]byte("shared"))
+	exists, err = store.Exists(ctx, hex.EncodeToString(hash[:]))
+	require.NoError(t, err)
+	require.True(t, exists)
+}
+
+// fullStorage is a backend whose disk is full: every Store fails with ENOSPC.
+type fullStorage struct {
+	storage.Backend
+}
+
+func (fullStorage) Store(ctx context.Context, reader io.Reader, size int64) (string, error) {
+	_, _ = io.Copy(io.Discard, reader)
+	return "", fmt.Errorf("failed to write to temp file: %w", syscall.ENOSPC)
+}
+
+func TestObjectHandler_PutObjectStorageFull(t *testing.T) {
+	store, err := filesystem.NewStorage(filesystem.Config{
+		DataDir: t.TempDir(),
+		TempDir: t.TempDir(),
+	}, zerolog.Nop())
+	require.NoError(t, err)
+
+	buckets := &stubBucketRepository{buckets: map[string]*domain.Bucket{
+		"uploads": {ID: 1, Name: "uploads", OwnerID: 1, Versioning: domain.VersioningDisabled},
+	}}
+	objects := &memoryObjectRepository{objects: make(map[string]*domain.Object)}
+	blobs := &memoryBlobRepository{refs: make(map[string]int32)}
+	svc := service.NewObjectService(objects, blobs, buckets, fullStorage{Backend: store}, lock.NewNoOpLocker(), zerolog.Nop())
+	m := metrics.NewWithRegisterer(prometheus.NewRegistry())
+	svc.SetMetrics(m)
+	full := 0
+	svc.SetStorageFullHandler(func() { full++ })
+	h := NewObjectHandler(svc, nil, zerolog.Nop())
+
+	req := withTestUser(httptest.NewRequest(http.MethodPut, "/uploads/big.bin", strings.NewReader("no room for this")))
+	rec := httptest.NewRecorder()
+	h.PutObject(rec, req, "uploads", "big.bin")
+
+	requireErrorCode(t, rec, http.StatusInsufficientStorage, "InsufficientStorage")
+	require.NotContains(t, objects.objects, "big.bin")
+	require.Equal(t, 1, full)
+	require.Equal(t, 1.0, testutil.ToFloat64(m.StorageFullTotal))
+}
+
+func TestObjectHandler_HeadMatchesGet(t *testing.T) {
+	h, _, _ := newPutObjectTestHandler(t)
+
+	const content = "0123456789abcdefghij"
+	req := withTestUser(httptest.NewRequest(http.MethodPut, "/uploads/digits.txt", strings.NewReader(content)))
+	req.Header.Set("Content-Type", "text/plain")
+	req.Header.Set("x-amz-meta-origin", "test")
+	rec := httptest.NewRecorder()
+	h.PutObject(rec, req, "uploads", "digits.txt")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	tests := []struct {
+		name         string
+		rangeHeader  string
+		status       int
+		length       string
+		contentRange string
+	}{
+		{name: "whole object", status: http.StatusOK, length: "20"},
+		{name: "range", rangeHeader: "bytes=5-9", status: http.StatusPartialContent, length: "5", contentRange: "bytes 5-9/20"},
+		{name: "suffix range", rangeHeader: "bytes=-4", status: http.StatusPartialContent, length: "4", contentRange: "bytes 16-19/20"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			do := func(method string) *httptest.ResponseRecorder {
+				req := withTestUser(httptest.NewRequest(method, "/uploads/digits.txt", nil))
+				if tt.rangeHeader != "" {
+					req.Header.Set("Range", tt.rangeHeader)
+				}
+				rec := httptest.NewRecorder()
+				if method == http.MethodHead {
+					h.HeadObject(rec, req, "uploads", "digits.txt")
+				} else {
+					h.GetObject(rec, req, "uploads", "digits.txt")
+				}
+				return rec
+			}
+
+			get, head := do(http.MethodGet), do(http.MethodHead)
+			require.Equal(t, tt.status, get.Code)
+			require.Equal(t, tt.status, head.Code)
+			require.Equal(t, get.Header(), head.Header())
+
+			require.Equal(t, tt.length, head.Header().Get("Content-Length"))
+			require.Equal(t, tt.contentRange, head.Header().Get("Content-Range"))
+			require.Equal(t, "bytes", head.Header().Get("Accept-Ranges"))
+			require.Equal(t, "test", head.Header().Get("x-amz-meta-origin"))
+			require.Equal(t, tt.length, strconv.Itoa(get.Body.Len()))
+		})
+	}
+
+	// An unsatisfiable range fails the same way for both
+	for _, method := range []string{http.MethodGet, http.MethodHead} {
+		req := withTestUser(httptest.NewRequest(method, "/uploads/digits.txt", nil))
+		req.Header.Set("Range", "bytes=50-60")
+		rec := httptest.NewRecorder()
+		if method == http.MethodHead {
+			h.HeadObject(rec, req, "uploads", "digits.txt")
+		} else {
+			h.GetObject(rec, req, "uploads", "digits.txt")
+		}
+		require.Equal(t, http.StatusRequestedRangeNotSatisfiable, rec.Code, method)
+	}
+}
+
+func TestObjectHandler_GetObjectSanitizesStoredMetadata(t *testing.T) {
+	h, objects, _ := newPutObjectTestHandler(t)
+
+	req := withTestUser(httptest.NewRequest(http.MethodPut, "/uploads/legacy.txt", strings.NewReader("data")))
+	rec := httptest.NewRecorder()
+	h.PutObject(rec, req, "uploads", "legacy.txt")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	// Metadata written before it was validated
+	objects.objects["legacy.txt"].Metadata = map[string]string{
+		"note":       "line one\r\nX-Injected: yes",
+		"plain":      "kept as is",
+		"bad\r\nkey": "value",
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			h.HeadObject(w, withTestUser(r), "uploads", "legacy.txt")
+			return
+		}
+		h.GetObject(w, withTestUser(r), "uploads", "legacy.txt")
+	}))
+	defer server.Close()
+
+	for _, method := range []string{http.MethodGet, http.MethodHead} {
+		req, err := http.NewRequest(method, server.URL+"/uploads/legacy.txt", nil)
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		require.Equal(t, http.StatusOK, resp.StatusCode, method)
+		require.Empty(t, resp.Header.Get("X-Injected"), method)
+		require.Equal(t, "=?UTF-8?q?line_one=0D=0AX-Injected:_yes?=", resp.Header.Get("x-amz-meta-note"), method)
+		require.Equal(t, "kept as is", resp.Header.Get("x-amz-meta-plain"), method)
+		require.Equal(t, "1", resp.Header.Get("x-amz-missing-meta"), method)
+
+		decoded, err := new(mime.WordDecoder).DecodeHeader(resp.Header.Get("x-amz-meta-note"))
+		require.NoError(t, err)
+		require.Equal(t, "line one\r\nX-Injected: yes", decoded)
+	}
+}
+
+func TestObjectHandler_CopyObjectMetadataDirective(t *testing.T) {
+	h, _, _ := newPutObjectTestHandler(t)
+
+	req := withTestUser(httptest.NewRequest(http.MethodPut, "/uploads/src.txt", strings.NewReader("hello")))
+	req.Header.Set("Content-Type", "text/plain")
+	req.Header.Set("Cache-Control", "max-age=60")
+	req.Header.Set("Content-Disposition", `attachment; filename="src.txt"`)
+	req.Header.Set("Content-Encoding", "identity")
+	req.Header.Set("x-amz-meta-owner", "alice")
+	rec := httptest.NewRecorder()
+	h.PutObject(rec, req, "uploads", "src.txt")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    map[string]string
+	}{
+		{
+			name:    "copy keeps everything",
+			headers: map[string]string{},
+			want: map[string]string{
+				"Content-Type":        "text/plain",
+				"Cache-Control":       "max-age=60",
+				"Content-Disposition": `attachment; filename="src.txt"`,
+				"Content-Encoding":    "identity",
+				"x-amz-meta-owner":    "alice",
+				"x-amz-storage-class": "STANDARD",
+			},
+		},
+		{
+			name: "replace changes only the content type",
+			headers: map[string]string{
+				"x-amz-metadata-directive": "REPLACE",
+				"Content-Type":             "application/json",
+				"Cache-Control":            "max-age=60",
+				"Content-Disposition":      `attachment; filename="src.txt"`,
+				"Content-Encoding":         "identity",
+				"x-amz-meta-owner":         "alice",
+			},
+			want: map[string]string{
+				"Content-Type":        "application/json",
+				"Cache-Control":       "max-age=60",
+				"Content-Disposition": `attachment; filename="src.txt"`,
+				"Content-Encoding":    "identity",
+				"x-amz-meta-owner":    "alice",
+				"x-amz-storage-class": "STANDARD",
+			},
+		},
+		{
+			name: "replace takes every header from the request",
+			headers: map[string]string{
+				"x-amz-metadata-directive": "REPLACE",
+				"Content-Type":             "application/json",
+				"x-amz-storage-class":      "REDUCED_REDUNDANCY",
+			},
+			want: map[string]string{
+				"Content-Type":        "application/json",
+				"Cache-Control":       "",
+				"Content-Disposition": "",
+				"Content-Encoding":    "",
+				"x-amz-meta-owner":    "",
+				"x-amz-storage-class": "REDUCED_REDUNDANCY",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			destKey := "copies/" + tt.name
+			req := withTestUser(httptest.NewRequest(http.MethodPut, "/uploads/x", nil))
+			req.Header.Set("x-amz-copy-source", "/uploads/src.txt")
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			rec := httptest.NewRecorder()
+			h.CopyObject(rec, req, "uploads", destKey)
+			require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+			rec = httptest.NewRecorder()
+			h.HeadObject(rec, withTestUser(httptest.NewRequest(http.MethodHead, "/uploads/x", nil)), "uploads", destKey)
+			require.Equal(t, http.StatusOK, rec.Code)
+			for name, value := range tt.want {
+				require.Equal(t, value, rec.Header().Get(name), name)
+			}
+		})
+	}
+}
+
+func TestObjectHandler_StandardHeaders(t *testing.T) {
+	h, _, _ := newPutObjectTestHandler(t)
+
+	req := withTestUser(httptest.NewRequest(http.MethodPut, "/uploads/x.pdf", strings.NewReader("%PDF-1.7")))
+	req.Header.Set("Content-Type", "application/pdf")
+	req.Header.Set("Content-Disposition", `attachment; filename="x.pdf"`)
+	req.Header.Set("Cache-Control", "no-cache")
+	req.Header.Set("Expires", "Thu, 01 Dec 2033 16:00:00 GMT")
+	rec := httptest.NewRecorder()
+	h.PutObject(rec, req, "uploads", "x.pdf")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	tests := []struct {
+		name  string
+		query string
+		want  map[string]string
+	}{
+		{
+			name: "stored headers",
+			want: map[string]string{
+				"Content-Type":        "application/pdf",
+				"Content-Disposition": `attachment; filename="x.pdf"`,
+				"Cache-Control":       "no-cache",
+				"Expires":             "Thu, 01 Dec 2033 16:00:00 GMT",
+				"Content-Language":    "",
+			},
+		},
+		{
+			name:  "query overrides",
+			query: "?response-content-type=application%2Foctet-stream&response-content-disposition=inline&response-content-language=en&response-cache-control=max-age%3D60",
+			want: map[string]string{
+				"Content-Type":        "application/octet-stream",
+				"Content-Disposition": "inline",
+				"Cache-Control":       "max-age=60",
+				"Expires":             "Thu, 01 Dec 2033 16:00:00 GMT",
+				"Content-Language":    "en",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.GetObject(rec, withTestUser(httptest.NewRequest(http.MethodGet, "/uploads/x.pdf"+tt.query, nil)), "uploads", "x.pdf")
+			require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+			require.Equal(t, "%PDF-1.7", rec.Body.String())
+			for name, value := range tt.want {
+				require.Equal(t, value, rec.Header().Get(name), name)
+			}
+
+			rec = httptest.NewRecorder()
+			h.HeadObject(rec, withTestUser(httptest.NewRequest(http.MethodHead, "/uploads/x.pdf"+tt.query, nil)), "uploads", "x.pdf")
+			require.Equal(t, http.StatusOK, rec.Code)
+			for name, value := range tt.want {
+				require.Equal(t, value, rec.Header().Get(name), name)
+			}
+		})
+	}
+
+}
+
+func TestParseResponseHeaders_Anonymous(t *testing.T) {
+	anonymous := &auth.AuthContext{AuthType: auth.AuthTypeAnonymous}
+
+	rec := httptest.NewRecorder()
+	_, ok := parseResponseHeaders(rec, httptest.NewRequest(http.MethodGet, "/uploads/x.pdf?response-content-type=text%2Fhtml", nil), anonymous)
+	require.False(t, ok)
+	requireErrorCode(t, rec, http.StatusBadRequest, "InvalidRequest")
+
+	// Without overrides an anonymous read is left to the authorizer
+	rec = httptest.NewRecorder()
+	headers, ok := parseResponseHeaders(rec, httptest.NewRequest(http.MethodGet, "/uploads/x.pdf", nil), anonymous)
+	require.True(t, ok)
+	require.True(t, headers.IsZero())
+}
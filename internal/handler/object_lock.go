@@ -0,0 +1,416 @@
+package handler
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prn-tf/alexander-storage/internal/auth"
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/service"
+)
+
+// ObjectLockConfiguration is the request and response body of the bucket
+// object lock operations.
+type ObjectLockConfiguration struct {
+	XMLName           xml.Name `xml:"ObjectLockConfiguration"`
+	Xmlns             string   `xml:"xmlns,attr,omitempty"`
+	ObjectLockEnabled string   `xml:"ObjectLockEnabled,omitempty"`
+
+	// Rule holds the default retention of new object versions.
+	Rule *ObjectLockRule `xml:"Rule,omitempty"`
+}
+
+// ObjectLockRule is the rule of an object lock configuration.
+type ObjectLockRule struct {
+	DefaultRetention *DefaultRetention `xml:"DefaultRetention"`
+}
+
+// DefaultRetention is a bucket's default retention: a mode and a period in
+// either Days or Years.
+type DefaultRetention struct {
+	Mode  string `xml:"Mode"`
+	Days  int    `xml:"Days,omitempty"`
+	Years int    `xml:"Years,omitempty"`
+}
+
+// ObjectRetention is the request and response body of the object retention operations.
+type ObjectRetention struct {
+	XMLName         xml.Name `xml:"Retention"`
+	Xmlns           string   `xml:"xmlns,attr,omitempty"`
+	Mode            string   `xml:"Mode,omitempty"`
+	RetainUntilDate string   `xml:"RetainUntilDate,omitempty"`
+}
+
+// ObjectLegalHold is the request and response body of the object legal hold operations.
+type ObjectLegalHold struct {
+	XMLName xml.Name `xml:"LegalHold"`
+	Xmlns   string   `xml:"xmlns,attr,omitempty"`
+	Status  string   `xml:"Status"`
+}
+
+// Legal hold statuses.
+const (
+	legalHoldOn  = "ON"
+	legalHoldOff = "OFF"
+)
+
+// objectLockDateFormat is the format of retain-until dates in responses.
+const objectLockDateFormat = "2006-01-02T15:04:05.000Z"
+
+// maxObjectLockBodySize bounds the object lock request bodies.
+const maxObjectLockBodySize = 16 * 1024
+
+// GetObjectLockConfiguration handles GET /{bucket}?object-lock requests.
+func (h *BucketHandler) GetObjectLockConfiguration(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Get authenticated user from context
+	userCtx, ok := auth.GetUserContext(ctx)
+	if !ok {
+		h.logger.Error().Msg("no user context found")
+		writeError(w, ErrAccessDenied)
+		return
+	}
+
+	// Extract bucket name from path
+	bucketName := extractBucketName(r)
+	if bucketName == "" {
+		writeError(w, ErrInvalidBucketName)
+		return
+	}
+
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionGetBucketObjectLockConfiguration, auth.BucketARN(bucketName)) {
+		return
+	}
+
+	output, err := h.bucketService.GetObjectLockConfiguration(ctx, service.GetObjectLockConfigurationInput{
+		Name:    bucketName,
+		OwnerID: userCtx.UserID,
+	})
+	if err != nil {
+		h.handleError(w, err, bucketName)
+		return
+	}
+
+	config := ObjectLockConfiguration{
+		Xmlns:             "http://s3.amazonaws.com/doc/2006-03-01/",
+		ObjectLockEnabled: "Enabled",
+	}
+	if retention := output.DefaultRetention; retention != nil {
+		config.Rule = &ObjectLockRule{DefaultRetention: &DefaultRetention{
+			Mode:  string(retention.Mode),
+			Days:  retention.Days,
+			Years: retention.Years,
+		}}
+	}
+	writeXML(w, http.StatusOK, config)
+}
+
+// PutObjectLockConfiguration handles PUT /{bucket}?object-lock requests.
+// It enables object lock and replaces the bucket's default retention.
+func (h *BucketHandler) PutObjectLockConfiguration(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Get authenticated user from context
+	userCtx, ok := auth.GetUserContext(ctx)
+	if !ok {
+		h.logger.Error().Msg("no user context found")
+		writeError(w, ErrAccessDenied)
+		return
+	}
+
+	// Extract bucket name from path
+	bucketName := extractBucketName(r)
+	if bucketName == "" {
+		writeError(w, ErrInvalidBucketName)
+		return
+	}
+
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionPutBucketObjectLockConfiguration, auth.BucketARN(bucketName)) {
+		return
+	}
+
+	// Parse request body
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxObjectLockBodySize))
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to read request body")
+		writeError(w, ErrInternalError)
+		return
+	}
+	defer r.Body.Close()
+
+	var config ObjectLockConfiguration
+	if err := xml.Unmarshal(body, &config); err != nil {
+		writeError(w, ErrMalformedXML)
+		return
+	}
+	if config.ObjectLockEnabled != "Enabled" {
+		writeError(w, ErrMalformedXML)
+		return
+	}
+
+	var defaultRetention *domain.DefaultRetention
+	if config.Rule != nil {
+		if config.Rule.DefaultRetention == nil {
+			writeError(w, ErrMalformedXML)
+			return
+		}
+		defaultRetention = &domain.DefaultRetention{
+			Mode:  domain.ObjectLockMode(config.Rule.DefaultRetention.Mode),
+			Days:  config.Rule.DefaultRetention.Days,
+			Years: config.Rule.DefaultRetention.Years,
+		}
+	}
+
+	err = h.bucketService.PutObjectLockConfiguration(ctx, service.PutObjectLockConfigurationInput{
+		Name:             bucketName,
+		OwnerID:          userCtx.UserID,
+		DefaultRetention: defaultRetention,
+	})
+	if err != nil {
+		h.handleError(w, err, bucketName)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// GetObjectRetention handles GET /{bucket}/{key}?retention requests.
+func (h *ObjectHandler) GetObjectRetention(w http.ResponseWriter, r *http.Request, bucketName, objectKey string) {
+	ctx := r.Context()
+
+	// Get authenticated user from context
+	userCtx, ok := auth.GetUserContext(ctx)
+	if !ok {
+		h.logger.Error().Msg("no user context found")
+		writeError(w, ErrAccessDenied)
+		return
+	}
+
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionGetObjectRetention, auth.ObjectARN(bucketName, objectKey)) {
+		return
+	}
+
+	output, err := h.objectService.GetObjectRetention(ctx, service.GetObjectRetentionInput{
+		BucketName: bucketName,
+		Key:        objectKey,
+		VersionID:  r.URL.Query().Get("versionId"),
+		OwnerID:    userCtx.UserID,
+	})
+	if err != nil {
+		h.handleObjectError(w, err, bucketName, objectKey)
+		return
+	}
+
+	setVersionIDHeader(w, output.VersionID)
+	writeXML(w, http.StatusOK, ObjectRetention{
+		Xmlns:           "http://s3.amazonaws.com/doc/2006-03-01/",
+		Mode:            string(output.Mode),
+		RetainUntilDate: output.RetainUntil.UTC().Format(objectLockDateFormat),
+	})
+}
+
+// PutObjectRetention handles PUT /{bucket}/{key}?retention requests.
+func (h *ObjectHandler) PutObjectRetention(w http.ResponseWriter, r *http.Request, bucketName, objectKey string) {
+	ctx := r.Context()
+
+	// Get authenticated user from context
+	userCtx, ok := auth.GetUserContext(ctx)
+	if !ok {
+		h.logger.Error().Msg("no user context found")
+		writeError(w, ErrAccessDenied)
+		return
+	}
+
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionPutObjectRetention, auth.ObjectARN(bucketName, objectKey)) {
+		return
+	}
+
+	// Parse request body
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxObjectLockBodySize))
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to read request body")
+		writeError(w, ErrInternalError)
+		return
+	}
+	defer r.Body.Close()
+
+	var retention ObjectRetention
+	if err := xml.Unmarshal(body, &retention); err != nil {
+		writeError(w, ErrMalformedXML)
+		return
+	}
+
+	var retainUntil *time.Time
+	if retention.RetainUntilDate != "" {
+		t, err := time.Parse(time.RFC3339, retention.RetainUntilDate)
+		if err != nil {
+			writeError(w, ErrMalformedXML)
+			return
+		}
+		retainUntil = &t
+	}
+
+	bypassGovernance, err := bypassGovernanceRetention(r, h.authorizer, userCtx, auth.ObjectARN(bucketName, objectKey))
+	if err != nil {
+		h.logger.Error().Err(err).Str("bucket", bucketName).Str("key", objectKey).Msg("failed to authorize governance bypass")
+		writeError(w, ErrInternalError)
+		return
+	}
+
+	output, err := h.objectService.PutObjectRetention(ctx, service.PutObjectRetentionInput{
+		BucketName:                bucketName,
+		Key:                       objectKey,
+		VersionID:                 r.URL.Query().Get("versionId"),
+		OwnerID:                   userCtx.UserID,
+		Mode:                      domain.ObjectLockMode(retention.Mode),
+		RetainUntil:               retainUntil,
+		BypassGovernanceRetention: bypassGovernance,
+	})
+	if err != nil {
+		h.handleObjectError(w, err, bucketName, objectKey)
+		return
+	}
+
+	setVersionIDHeader(w, output.VersionID)
+	w.WriteHeader(http.StatusOK)
+}
+
+// GetObjectLegalHold handles GET /{bucket}/{key}?legal-hold requests.
+func (h *ObjectHandler) GetObjectLegalHold(w http.ResponseWriter, r *http.Request, bucketName, objectKey string) {
+	ctx := r.Context()
+
+	// Get authenticated user from context
+	userCtx, ok := auth.GetUserContext(ctx)
+	if !ok {
+		h.logger.Error().Msg("no user context found")
+		writeError(w, ErrAccessDenied)
+		return
+	}
+
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionGetObjectLegalHold, auth.ObjectARN(bucketName, objectKey)) {
+		return
+	}
+
+	output, err := h.objectService.GetObjectLegalHold(ctx, service.GetObjectLegalHoldInput{
+		BucketName: bucketName,
+		Key:        objectKey,
+		VersionID:  r.URL.Query().Get("versionId"),
+		OwnerID:    userCtx.UserID,
+	})
+	if err != nil {
+		h.handleObjectError(w, err, bucketName, objectKey)
+		return
+	}
+
+	status := legalHoldOff
+	if output.LegalHold {
+		status = legalHoldOn
+	}
+
+	setVersionIDHeader(w, output.VersionID)
+	writeXML(w, http.StatusOK, ObjectLegalHold{
+		Xmlns:  "http://s3.amazonaws.com/doc/2006-03-01/",
+		Status: status,
+	})
+}
+
+// PutObjectLegalHold handles PUT /{bucket}/{key}?legal-hold requests.
+func (h *ObjectHandler) PutObjectLegalHold(w http.ResponseWriter, r *http.Request, bucketName, objectKey string) {
+	ctx := r.Context()
+
+	// Get authenticated user from context
+	userCtx, ok := auth.GetUserContext(ctx)
+	if !ok {
+		h.logger.Error().Msg("no user context found")
+		writeError(w, ErrAccessDenied)
+		return
+	}
+
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionPutObjectLegalHold, auth.ObjectARN(bucketName, objectKey)) {
+		return
+	}
+
+	// Parse request body
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxObjectLockBodySize))
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to read request body")
+		writeError(w, ErrInternalError)
+		return
+	}
+	defer r.Body.Close()
+
+	var legalHold ObjectLegalHold
+	if err := xml.Unmarshal(body, &legalHold); err != nil {
+		writeError(w, ErrMalformedXML)
+		return
+	}
+	if legalHold.Status != legalHoldOn && legalHold.Status != legalHoldOff {
+		writeError(w, ErrMalformedXML)
+		return
+	}
+
+	output, err := h.objectService.PutObjectLegalHold(ctx, service.PutObjectLegalHoldInput{
+		BucketName: bucketName,
+		Key:        objectKey,
+		VersionID:  r.URL.Query().Get("versionId"),
+		OwnerID:    userCtx.UserID,
+		LegalHold:  legalHold.Status == legalHoldOn,
+	})
+	if err != nil {
+		h.handleObjectError(w, err, bucketName, objectKey)
+		return
+	}
+
+	setVersionIDHeader(w, output.VersionID)
+	w.WriteHeader(http.StatusOK)
+}
+
+// parseObjectLockHeaders parses the x-amz-object-lock-* headers of PutObject
+// and CopyObject.
+// It returns nil when none are present; the retention itself is validated
+// by the service.
+func parseObjectLockHeaders(r *http.Request) (*domain.ObjectLock, error) {
+	mode := r.Header.Get("x-amz-object-lock-mode")
+	retainUntil := r.Header.Get("x-amz-object-lock-retain-until-date")
+	legalHold := r.Header.Get("x-amz-object-lock-legal-hold")
+	if mode == "" && retainUntil == "" && legalHold == "" {
+		return nil, nil
+	}
+
+	objLock := &domain.ObjectLock{Mode: domain.ObjectLockMode(mode)}
+	if retainUntil != "" {
+		t, err := time.Parse(time.RFC3339, retainUntil)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid retain-until date %q", domain.ErrInvalidObjectLock, retainUntil)
+		}
+		objLock.RetainUntil = &t
+	}
+	switch legalHold {
+	case "", legalHoldOff:
+	case legalHoldOn:
+		objLock.LegalHold = true
+	default:
+		return nil, fmt.Errorf("%w: legal hold status must be ON or OFF", domain.ErrInvalidObjectLock)
+	}
+	return objLock, nil
+}
+
+// bypassGovernanceRetention reports whether the request asks to bypass
+// GOVERNANCE retention with x-amz-bypass-governance-retention and the
+// principal is allowed to. Without the permission the retention applies.
+func bypassGovernanceRetention(r *http.Request, authorizer auth.Authorizer, principal *auth.AuthContext, resource string) (bool, error) {
+	if !strings.EqualFold(r.Header.Get("x-amz-bypass-governance-retention"), "true") {
+		return false, nil
+	}
+	err := authorizer.Authorize(r.Context(), principal, auth.ActionBypassGovernanceRetention, resource)
+	if errors.Is(err, auth.ErrAccessDenied) {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/lock"
+	"github.com/prn-tf/alexander-storage/internal/service"
+)
+
+func newObjectLockTestHandler(t *testing.T, objLock *domain.ObjectLock) (*ObjectHandler, *stubObjectRepository, uuid.UUID) {
+	t.Helper()
+
+	versionID := uuid.New()
+	buckets := &stubBucketRepository{buckets: map[string]*domain.Bucket{
+		"vault": {ID: 1, Name: "vault", OwnerID: 1, Versioning: domain.VersioningEnabled, ObjectLock: true},
+		"plain": {ID: 2, Name: "plain", OwnerID: 1, Versioning: domain.VersioningEnabled},
+	}}
+	objects := &stubObjectRepository{
+		versions: map[uuid.UUID]*domain.Object{
+			versionID: {ID: 1, BucketID: 1, Key: "ledger.csv", VersionID: versionID, IsLatest: true},
+		},
+		latest: map[string]*domain.Object{
+			"ledger.csv": {ID: 2, BucketID: 2, Key: "ledger.csv", IsLatest: true},
+		},
+		locks: map[int64]*domain.ObjectLock{1: objLock},
+	}
+	svc := service.NewObjectService(objects, nil, buckets, nil, lock.NewNoOpLocker(), zerolog.Nop())
+	return NewObjectHandler(svc, nil, zerolog.Nop()), objects, versionID
+}
+
+func TestObjectHandler_DeleteLockedVersion(t *testing.T) {
+	future := time.Now().Add(24 * time.Hour)
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name    string
+		lock    *domain.ObjectLock
+		bypass  bool
+		blocked bool
+	}{
+		{name: "compliance retention", lock: &domain.ObjectLock{Mode: domain.ObjectLockModeCompliance, RetainUntil: &future}, blocked: true},
+		{name: "legal hold", lock: &domain.ObjectLock{LegalHold: true}, blocked: true},
+		{name: "expired retention", lock: &domain.ObjectLock{Mode: domain.ObjectLockModeCompliance, RetainUntil: &past}},
+		{name: "governance retention", lock: &domain.ObjectLock{Mode: domain.ObjectLockModeGovernance, RetainUntil: &future}, blocked: true},
+		{name: "bypassed governance retention", lock: &domain.ObjectLock{Mode: domain.ObjectLockModeGovernance, RetainUntil: &future}, bypass: true},
+		{name: "bypass does not lift compliance", lock: &domain.ObjectLock{Mode: domain.ObjectLockModeCompliance, RetainUntil: &future}, bypass: true, blocked: true},
+		{name: "unlocked", lock: &domain.ObjectLock{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, objects, versionID := newObjectLockTestHandler(t, tt.lock)
+
+			req := withTestUser(httptest.NewRequest(http.MethodDelete, "/vault/ledger.csv?versionId="+versionID.String(), nil))
+			if tt.bypass {
+				req.Header.Set("x-amz-bypass-governance-retention", "true")
+			}
+			rec := httptest.NewRecorder()
+
+			h.DeleteObject(rec, req, "vault", "ledger.csv")
+
+			if tt.blocked {
+				requireErrorCode(t, rec, http.StatusForbidden, "AccessDenied")
+				require.Empty(t, objects.deleted)
+				return
+			}
+			require.Equal(t, http.StatusNoContent, rec.Code)
+			require.Equal(t, []int64{1}, objects.deleted)
+		})
+	}
+}
+
+func TestBucketHandler_ObjectLockDefaultRetention(t *testing.T) {
+	buckets := &stubBucketRepository{buckets: map[string]*domain.Bucket{
+		"vault": {ID: 1, Name: "vault", OwnerID: 1, Versioning: domain.VersioningEnabled, ObjectLock: true},
+	}}
+	h := NewBucketHandler(service.NewBucketService(buckets, zerolog.Nop()), nil, zerolog.Nop())
+
+	putConfig := func(body string) *httptest.ResponseRecorder {
+		req := withTestUser(httptest.NewRequest(http.MethodPut, "/vault?object-lock", strings.NewReader(body)))
+		rec := httptest.NewRecorder()
+		h.PutObjectLockConfiguration(rec, req)
+		return rec
+	}
+
+	rec := putConfig(`<ObjectLockConfiguration><ObjectLockEnabled>Enabled</ObjectLockEnabled>` +
+		`<Rule><DefaultRetention><Mode>GOVERNANCE</Mode><Days>30</Days></DefaultRetention></Rule></ObjectLockConfiguration>`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, &domain.DefaultRetention{Mode: domain.ObjectLockModeGovernance, Days: 30}, buckets.buckets["vault"].DefaultRetention)
+
+	rec = httptest.NewRecorder()
+	h.GetObjectLockConfiguration(rec, withTestUser(httptest.NewRequest(http.MethodGet, "/vault?object-lock", nil)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var config ObjectLockConfiguration
+	require.NoError(t, xml.Unmarshal(rec.Body.Bytes(), &config))
+	require.NotNil(t, config.Rule)
+	require.Equal(t, DefaultRetention{Mode: "GOVERNANCE", Days: 30}, *config.Rule.DefaultRetention)
+
+	// A period needs exactly one of Days and Years
+	rec = putConfig(`<ObjectLockConfiguration><ObjectLockEnabled>Enabled</ObjectLockEnabled>` +
+		`<Rule><DefaultRetention><Mode>COMPLIANCE</Mode><Days>1</Days><Years>1</Years></DefaultRetention></Rule></ObjectLockConfiguration>`)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// Without a rule the default retention is removed
+	rec = putConfig(`<ObjectLockConfiguration><ObjectLockEnabled>Enabled</ObjectLockEnabled></ObjectLockConfiguration>`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Nil(t, buckets.buckets["vault"].DefaultRetention)
+}
+
+func TestObjectHandler_ObjectRetention(t *testing.T) {
+	h, objects, versionID := newObjectLockTestHandler(t, &domain.ObjectLock{})
+	target := "/vault/ledger.csv?retention&versionId=" + versionID.String()
+
+	putRetention := func(mode string, until time.Time) *httptest.ResponseRecorder {
+		body := `<Retention><Mode>` + mode + `</Mode><RetainUntilDate>` + until.UTC().Format(time.RFC3339) + `</RetainUntilDate></Retention>`
+		req := withTestUser(httptest.NewRequest(http.MethodPut, target, strings.NewReader(body)))
+		rec := httptest.NewRecorder()
+		h.PutObjectRetention(rec, req, "vault", "ledger.csv")
+		return rec
+	}
+
+	// No retention yet
+	rec := httptest.NewRecorder()
+	h.GetObjectRetention(rec, withTestUser(httptest.NewRequest(http.MethodGet, target, nil)), "vault", "ledger.csv")
+	requireErrorCode(t, rec, http.StatusNotFound, "NoSuchObjectLockConfiguration")
+
+	until := time.Now().Add(48 * time.Hour).Truncate(time.Second)
+	require.Equal(t, http.StatusOK, putRetention("COMPLIANCE", until).Code)
+	require.Equal(t, domain.ObjectLockModeCompliance, objects.locks[1].Mode)
+
+	rec = httptest.NewRecorder()
+	h.GetObjectRetention(rec, withTestUser(httptest.NewRequest(http.MethodGet, target, nil)), "vault", "ledger.csv")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var retention ObjectRetention
+	require.NoError(t, xml.Unmarshal(rec.Body.Bytes(), &retention))
+	require.Equal(t, "COMPLIANCE", retention.Mode)
+	require.Equal(t, until.UTC().Format(objectLockDateFormat), retention.RetainUntilDate)
+
+	// Compliance retention can be extended but not shortened
+	requireErrorCode(t, putRetention("COMPLIANCE", until.Add(-time.Hour)), http.StatusForbidden, "AccessDenied")
+	require.Equal(t, http.StatusOK, putRetention("COMPLIANCE", until.Add(time.Hour)).Code)
+
+	// Dates in the past are rejected
+	requireErrorCode(t, putRetention("GOVERNANCE", time.Now().Add(-time.Hour)), http.StatusBadRequest, "InvalidArgument")
+}
+
+func TestObjectHandler_ObjectLegalHold(t *testing.T) {
+	h, objects, versionID := newObjectLockTestHandler(t, &domain.ObjectLock{})
+
+	req := withTestUser(httptest.NewRequest(http.MethodPut, "/vault/ledger.csv?legal-hold&versionId="+versionID.String(),
+		strings.NewReader(`<LegalHold><Status>ON</Status></LegalHold>`)))
+	rec := httptest.NewRecorder()
+	h.PutObjectLegalHold(rec, req, "vault", "ledger.csv")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.True(t, objects.locks[1].LegalHold)
+
+	req = withTestUser(httptest.NewRequest(http.MethodGet, "/vault/ledger.csv?legal-hold&versionId="+versionID.String(), nil))
+	rec = httptest.NewRecorder()
+	h.GetObjectLegalHold(rec, req, "vault", "ledger.csv")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var legalHold ObjectLegalHold
+	require.NoError(t, xml.Unmarshal(rec.Body.Bytes(), &legalHold))
+	require.Equal(t, "ON", legalHold.Status)
+
+	// Buckets without object lock have no legal holds
+	req = withTestUser(httptest.NewRequest(http.MethodGet, "/plain/ledger.csv?legal-hold", nil))
+	rec = httptest.NewRecorder()
+	h.GetObjectLegalHold(rec, req, "plain", "ledger.csv")
+	requireErrorCode(t, rec, http.StatusBadRequest, "InvalidRequest")
+}
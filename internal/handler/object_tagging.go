@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+
+	"github.com/prn-tf/alexander-storage/internal/auth"
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/service"
+)
+
+// Tagging is the request and response body of the object tagging operations.
+type Tagging struct {
+	XMLName xml.Name `xml:"Tagging"`
+	Xmlns   string   `xml:"xmlns,attr,omitempty"`
+	TagSet  []Tag    `xml:"TagSet>Tag"`
+}
+
+// Tag is a single key/value pair in a tag set.
+type Tag struct {
+	Key   string `xml:"Key"`
+	Value string `xml:"Value"`
+}
+
+// maxTaggingBodySize bounds the PutObjectTagging request body. Ten tags at
+// the maximum key and value lengths fit comfortably.
+const maxTaggingBodySize = 64 * 1024
+
+// GetObjectTagging handles GET /{bucket}/{key}?tagging requests.
+func (h *ObjectHandler) GetObjectTagging(w http.ResponseWriter, r *http.Request, bucketName, objectKey string) {
+	ctx := r.Context()
+
+	// Get authenticated user from context
+	userCtx, ok := auth.GetUserContext(ctx)
+	if !ok {
+		h.logger.Error().Msg("no user context found")
+		writeError(w, ErrAccessDenied)
+		return
+	}
+
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionGetObjectTagging, auth.ObjectARN(bucketName, objectKey)) {
+		return
+	}
+
+	output, err := h.objectService.GetObjectTagging(ctx, service.GetObjectTaggingInput{
+		BucketName: bucketName,
+		Key:        objectKey,
+		VersionID:  r.URL.Query().Get("versionId"),
+		OwnerID:    userCtx.UserID,
+	})
+
+	if err != nil {
+		h.handleObjectError(w, err, bucketName, objectKey)
+		return
+	}
+
+	// Emit tags in key order so responses are stable
+	keys := make([]string, 0, len(output.Tags))
+	for key := range output.Tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	response := Tagging{
+		Xmlns:  "http://s3.amazonaws.com/doc/2006-03-01/",
+		TagSet: make([]Tag, 0, len(keys)),
+	}
+	for _, key := range keys {
+		response.TagSet = append(response.TagSet, Tag{Key: key, Value: output.Tags[key]})
+	}
+
+	setVersionIDHeader(w, output.VersionID)
+	writeXML(w, http.StatusOK, response)
+}
+
+// PutObjectTagging handles PUT /{bucket}/{key}?tagging requests.
+// The request replaces the whole tag set.
+func (h *ObjectHandler) PutObjectTagging(w http.ResponseWriter, r *http.Request, bucketName, objectKey string) {
+	ctx := r.Context()
+
+	// Get authenticated user from context
+	userCtx, ok := auth.GetUserContext(ctx)
+	if !ok {
+		h.logger.Error().Msg("no user context found")
+		writeError(w, ErrAccessDenied)
+		return
+	}
+
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionPutObjectTagging, auth.ObjectARN(bucketName, objectKey)) {
+		return
+	}
+
+	// Parse request body
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxTaggingBodySize))
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to read request body")
+		writeError(w, ErrInternalError)
+		return
+	}
+	defer r.Body.Close()
+
+	var tagging Tagging
+	if err := xml.Unmarshal(body, &tagging); err != nil {
+		writeError(w, ErrMalformedXML)
+		return
+	}
+
+	tags := make(map[string]string, len(tagging.TagSet))
+	for _, tag := range tagging.TagSet {
+		if _, dup := tags[tag.Key]; dup {
+			h.handleObjectError(w, fmt.Errorf("%w: duplicate tag key %q", domain.ErrInvalidTag, tag.Key), bucketName, objectKey)
+			return
+		}
+		tags[tag.Key] = tag.Value
+	}
+
+	output, err := h.objectService.PutObjectTagging(ctx, service.PutObjectTaggingInput{
+		BucketName: bucketName,
+		Key:        objectKey,
+		VersionID:  r.URL.Query().Get("versionId"),
+		OwnerID:    userCtx.UserID,
+		Tags:       tags,
+	})
+
+	if err != nil {
+		h.handleObjectError(w, err, bucketName, objectKey)
+		return
+	}
+
+	setVersionIDHeader(w, output.VersionID)
+	w.WriteHeader(http.StatusOK)
+}
+
+// DeleteObjectTagging handles DELETE /{bucket}/{key}?tagging requests.
+func (h *ObjectHandler) DeleteObjectTagging(w http.ResponseWriter, r *http.Request, bucketName, objectKey string) {
+	ctx := r.Context()
+
+	// Get authenticated user from context
+	userCtx, ok := auth.GetUserContext(ctx)
+	if !ok {
+		h.logger.Error().Msg("no user context found")
+		writeError(w, ErrAccessDenied)
+		return
+	}
+
+	if !authorize(w, r, h.authorizer, h.logger, userCtx, auth.ActionDeleteObjectTagging, auth.ObjectARN(bucketName, objectKey)) {
+		return
+	}
+
+	output, err := h.objectService.DeleteObjectTagging(ctx, service.DeleteObjectTaggingInput{
+		BucketName: bucketName,
+		Key:        objectKey,
+		VersionID:  r.URL.Query().Get("versionId"),
+		OwnerID:    userCtx.UserID,
+	})
+
+	if err != nil {
+		h.handleObjectError(w, err, bucketName, objectKey)
+		return
+	}
+
+	setVersionIDHeader(w, output.VersionID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// parseTaggingHeader parses an x-amz-tagging header, which carries the tag
+// set URL-encoded as a query string ("k1=v1&k2=v2"). Limits are enforced by
+// the service.
+func parseTaggingHeader(value string) (map[string]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	values, err := url.ParseQuery(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidTag, err)
+	}
+
+	tags := make(map[string]string, len(values))
+	for key, vals := range values {
+		if len(vals) > 1 {
+			return nil, fmt.Errorf("%w: duplicate tag key %q", domain.ErrInvalidTag, key)
+		}
+		tags[key] = vals[0]
+	}
+	return tags, nil
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/lock"
+	"github.com/prn-tf/alexander-storage/internal/service"
+)
+
+func newTaggingTestHandler(t *testing.T) (*ObjectHandler, *stubObjectRepository) {
+	t.Helper()
+
+	contentHash := "abc123hash"
+	buckets := &stubBucketRepository{buckets: map[string]*domain.Bucket{
+		"tagged": {ID: 1, Name: "tagged", OwnerID: 1},
+	}}
+	objects := &stubObjectRepository{latest: map[string]*domain.Object{
+		"doc.txt": {ID: 7, BucketID: 1, Key: "doc.txt", ContentHash: &contentHash, IsLatest: true},
+	}}
+
+	svc := service.NewObjectService(objects, nil, buckets, nil, lock.NewNoOpLocker(), zerolog.Nop())
+	return NewObjectHandler(svc, nil, zerolog.Nop()), objects
+}
+
+func TestObjectHandler_ObjectTagging(t *testing.T) {
+	h, objects := newTaggingTestHandler(t)
+
+	body := `<Tagging><TagSet>` +
+		`<Tag><Key>team</Key><Value>storage</Value></Tag>` +
+		`<Tag><Key>env</Key><Value>prod</Value></Tag>` +
+		`</TagSet></Tagging>`
+	req := withTestUser(httptest.NewRequest(http.MethodPut, "/tagged/doc.txt?tagging", strings.NewReader(body)))
+	rec := httptest.NewRecorder()
+	h.PutObjectTagging(rec, req, "tagged", "doc.txt")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, map[string]string{"team": "storage", "env": "prod"}, objects.tags[7])
+
+	req = withTestUser(httptest.NewRequest(http.MethodGet, "/tagged/doc.txt?tagging", nil))
+	rec = httptest.NewRecorder()
+	h.GetObjectTagging(rec, req, "tagged", "doc.txt")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(),
+		`<TagSet><Tag><Key>env</Key><Value>prod</Value></Tag><Tag><Key>team</Key><Value>storage</Value></Tag></TagSet>`)
+
+	req = withTestUser(httptest.NewRequest(http.MethodDelete, "/tagged/doc.txt?tagging", nil))
+	rec = httptest.NewRecorder()
+	h.DeleteObjectTagging(rec, req, "tagged", "doc.txt")
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Empty(t, objects.tags[7])
+}
+
+func TestObjectHandler_PutObjectTaggingInvalid(t *testing.T) {
+	h, _ := newTaggingTestHandler(t)
+
+	tag := func(key, value string) string {
+		return "<Tag><Key>" + key + "</Key><Value>" + value + "</Value></Tag>"
+	}
+	var eleven string
+	for i := 0; i < 11; i++ {
+		eleven += tag(string(rune('a'+i)), "v")
+	}
+
+	tests := []struct {
+		name string
+		body string
+		code string
+	}{
+		{name: "too many tags", body: eleven, code: "BadRequest"},
+		{name: "duplicate key", body: tag("team", "a") + tag("team", "b"), code: "InvalidTag"},
+		{name: "key too long", body: tag(strings.Repeat("k", 129), "v"), code: "InvalidTag"},
+		{name: "value too long", body: tag("k", strings.Repeat("v", 257)), code: "InvalidTag"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := "<Tagging><TagSet>" + tt.body + "</TagSet></Tagging>"
+			req := withTestUser(httptest.NewRequest(http.MethodPut, "/tagged/doc.txt?tagging", strings.NewReader(body)))
+			rec := httptest.NewRecorder()
+
+			h.PutObjectTagging(rec, req, "tagged", "doc.txt")
+
+			requireErrorCode(t, rec, http.StatusBadRequest, tt.code)
+		})
+	}
+}
+
+func TestParseTaggingHeader(t *testing.T) {
+	tags, err := parseTaggingHeader("team=storage&cost%20center=a%26b&empty=")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "storage", "cost center": "a&b", "empty": ""}, tags)
+
+	_, err = parseTaggingHeader("team=a&team=b")
+	assert.ErrorIs(t, err, domain.ErrInvalidTag)
+}
@@ -0,0 +1,297 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// operationScope is the kind of resource an S3 operation addresses.
+type operationScope string
+
+const (
+	scopeBucket operationScope = "bucket"
+	scopeObject operationScope = "object"
+)
+
+// s3Operation is a group of S3 API operations selected by a sub-resource
+// query parameter, e.g. GET/PUT/DELETE /{bucket}?lifecycle.
+type s3Operation struct {
+	// SubResource is the query parameter that selects the operations.
+	SubResource string
+
+	// Scope is whether the operations address a bucket or an object.
+	Scope operationScope
+
+	// Operations are the S3 API names of the operations.
+	Operations []string
+
+	// Implemented reports whether the router serves the operations. Requests
+	// for unimplemented operations get a 501 NotImplemented response instead
+	// of falling through to the plain bucket or object handlers.
+	Implemented bool
+}
+
+// baseOperations are the S3 API operations the router serves without a
+// sub-resource query parameter.
+var baseOperations = []string{
+	"ListBuckets", "CreateBucket", "DeleteBucket", "HeadBucket",
+	"ListObjects", "ListObjectsV2",
+	"PutObject", "GetObject", "HeadObject", "DeleteObject", "CopyObject",
+}
+
+// s3Operations is the registry of sub-resource operations the router
+// recognizes. Flip Implemented when adding the routing for an entry; the
+// capabilities endpoint reports from it as well.
+var s3Operations = []s3Operation{
+	// Bucket sub-resources
+	{SubResource: "versioning", Scope: scopeBucket, Operations: []string{"GetBucketVersioning", "PutBucketVersioning"}, Implemented: true},
+	{SubResource: "delete", Scope: scopeBucket, Operations: []string{"DeleteObjects"}, Implemented: true},
+	{SubResource: "versions", Scope: scopeBucket, Operations: []string{"ListObjectVersions"}, Implemented: true},
+	{SubResource: "uploads", Scope: scopeBucket, Operations: []string{"ListMultipartUploads"}, Implemented: true},
+	{SubResource: "ownershipControls", Scope: scopeBucket, Operations: []string{"GetBucketOwnershipControls", "PutBucketOwnershipControls"}, Implemented: true},
+	{SubResource: "acl", Scope: scopeBucket, Operations: []string{"GetBucketAcl", "PutBucketAcl"}, Implemented: true},
+	{SubResource: "accelerate", Scope: scopeBucket, Operations: []string{"GetBucketAccelerateConfiguration", "PutBucketAccelerateConfiguration"}},
+	{SubResource: "analytics", Scope: scopeBucket, Operations: []string{"GetBucketAnalyticsConfiguration", "PutBucketAnalyticsConfiguration", "DeleteBucketAnalyticsConfiguration", "ListBucketAnalyticsConfigurations"}},
+	{SubResource: "cors", Scope: scopeBucket, Operations: []string{"GetBucketCors", "PutBucketCors", "DeleteBucketCors"}, Implemented: true},
+	{SubResource: "encryption", Scope: scopeBucket, Operations: []string{"GetBucketEncryption", "PutBucketEncryption", "DeleteBucketEncryption"}},
+	{SubResource: "intelligent-tiering", Scope: scopeBucket, Operations: []string{"GetBucketIntelligentTieringConfiguration", "PutBucketIntelligentTieringConfiguration", "DeleteBucketIntelligentTieringConfiguration", "ListBucketIntelligentTieringConfigurations"}},
+	{SubResource: "inventory", Scope: scopeBucket, Operations: []string{"GetBucketInventoryConfiguration", "PutBucketInventoryConfiguration", "DeleteBucketInventoryConfiguration", "ListBucketInventoryConfigurations"}},
+	{SubResource: "lifecycle", Scope: scopeBucket, Operations: []string{"GetBucketLifecycleConfiguration", "PutBucketLifecycleConfiguration", "DeleteBucketLifecycle"}, Implemented: true},
+	{SubResource: "logging", Scope: scopeBucket, Operations: []string{"GetBucketLogging", "PutBucketLogging"}},
+	{SubResource: "metrics", Scope: scopeBucket, Operations: []string{"GetBucketMetricsConfiguration", "PutBucketMetricsConfiguration", "DeleteBucketMetricsConfiguration", "ListBucketMetricsConfigurations"}},
+	{SubResource: "notification", Scope: scopeBucket, Operations: []string{"GetBucketNotificationConfiguration", "PutBucketNotificationConfiguration"}, Implemented: true},
+	{SubResource: "object-lock", Scope: scopeBucket, Operations: []string{"GetObjectLockConfiguration", "PutObjectLockConfiguration"}, Implemented: true},
+	{SubResource: "policy", Scope: scopeBucket, Operations: []string{"GetBucketPolicy", "PutBucketPolicy", "DeleteBucketPolicy"}, Implemented: true},
+	{SubResource: "policyStatus", Scope: scopeBucket, Operations: []string{"GetBucketPolicyStatus"}},
+	{SubResource: "publicAccessBlock", Scope: scopeBucket, Operations: []string{"GetPublicAccessBlock", "PutPublicAccessBlock", "DeletePublicAccessBlock"}},
+	{SubResource: "replication", Scope: scopeBucket, Operations: []string{"GetBucketReplication", "PutBucketReplication", "DeleteBucketReplication"}},
+	{SubResource: "requestPayment", Scope: scopeBucket, Operations: []string{"GetBucketRequestPayment", "PutBucketRequestPayment"}},
+	{SubResource: "tagging", Scope: scopeBucket, Operations: []string{"GetBucketTagging", "PutBucketTagging", "DeleteBucketTagging"}},
+	{SubResource: "website", Scope: scopeBucket, Operations: []string{"GetBucketWebsite", "PutBucketWebsite", "DeleteBucketWebsite"}},
+
+	// Object sub-resources
+	{SubResource: "uploads", Scope: scopeObject, Operations: []string{"CreateMultipartUpload"}, Implemented: true},
+	{SubResource: "uploadId", Scope: scopeObject, Operations: []string{"UploadPart", "UploadPartCopy", "CompleteMultipartUpload", "AbortMultipartUpload", "ListParts"}, Implemented: true},
+	{SubResource: "acl", Scope: scopeObject, Operations: []string{"GetObjectAcl", "PutObjectAcl"}, Implemented: true},
+	{SubResource: "tagging", Scope: scopeObject, Operations: []string{"GetObjectTagging", "PutObjectTagging", "DeleteObjectTagging"}, Implemented: true},
+	{SubResource: "attributes", Scope: scopeObject, Operations: []string{"GetObjectAttributes"}, Implemented: true},
+	{SubResource: "legal-hold", Scope: scopeObject, Operations: []string{"GetObjectLegalHold", "PutObjectLegalHold"}, Implemented: true},
+	{SubResource: "restore", Scope: scopeObject, Operations: []string{"RestoreObject"}},
+	{SubResource: "retention", Scope: scopeObject, Operations: []string{"GetObjectRetention", "PutObjectRetention"}, Implemented: true},
+	{SubResource: "select", Scope: scopeObject, Operations: []string{"SelectObjectContent"}},
+	{SubResource: "torrent", Scope: scopeObject, Operations: []string{"GetObjectTorrent"}},
+}
+
+// unimplementedOperation returns the registry entry for the first
+// unimplemented sub-resource present in query.
+func unimplementedOperation(scope operationScope, query map[string][]string) (s3Operation, bool) {
+	for _, op := range s3Operations {
+		if op.Scope != scope || op.Implemented {
+			continue
+		}
+		if _, ok := query[op.SubResource]; ok {
+			return op, true
+		}
+	}
+	return s3Operation{}, false
+}
+
+// notImplementedError is the response for a recognized but unimplemented operation.
+func notImplementedError(op s3Operation) S3Error {
+	return S3Error{
+		Code:           "NotImplemented",
+		Message:        fmt.Sprintf("The %s %s sub-resource is not implemented.", op.Scope, op.SubResource),
+		HTTPStatusCode: http.StatusNotImplemented,
+	}
+}
+
+// unknownOperation labels S3 API requests that match no operation, e.g.
+// because the method is not allowed on the resource.
+const unknownOperation = "Unknown"
+
+// operationName returns the S3 API name of the operation a request invokes,
+// following the routing of handleS3Request.
+func (rt *Router) operationName(r *http.Request) string {
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	var objectKey string
+	if _, ok := rt.virtualHostBucket(r); ok {
+		objectKey = path
+	} else if path == "" {
+		if r.Method == http.MethodGet {
+			return "ListBuckets"
+		}
+		return unknownOperation
+	} else {
+		_, objectKey, _ = strings.Cut(path, "/")
+	}
+
+	query := r.URL.Query()
+	if objectKey != "" {
+		return objectOperationName(r, query)
+	}
+	return bucketOperationName(r, query)
+}
+
+// bucketOperationName returns the name of a bucket-level operation.
+func bucketOperationName(r *http.Request, query url.Values) string {
+	if _, ok := query["delete"]; ok && r.Method == http.MethodPost {
+		return "DeleteObjects"
+	}
+	if op, ok := subResourceOperation(scopeBucket, query); ok {
+		return operationForMethod(op, r.Method)
+	}
+
+	switch r.Method {
+	case http.MethodHead:
+		return "HeadBucket"
+	case http.MethodGet:
+		if query.Get("list-type") == "2" {
+			return "ListObjectsV2"
+		}
+		return "ListObjects"
+	case http.MethodPut:
+		return "CreateBucket"
+	case http.MethodDelete:
+		return "DeleteBucket"
+	}
+	return unknownOperation
+}
+
+// objectOperationName returns the name of an object-level operation.
+func objectOperationName(r *http.Request, query url.Values) string {
+	copySource := r.Header.Get("x-amz-copy-source") != ""
+
+	if _, ok := query["uploads"]; ok && r.Method == http.MethodPost {
+		return "CreateMultipartUpload"
+	}
+	if query.Get("uploadId") != "" {
+		switch r.Method {
+		case http.MethodPut:
+			if copySource {
+				return "UploadPartCopy"
+			}
+			return "UploadPart"
+		case http.MethodPost:
+			return "CompleteMultipartUpload"
+		case http.MethodDelete:
+			return "AbortMultipartUpload"
+		case http.MethodGet:
+			return "ListParts"
+		}
+	}
+	if op, ok := subResourceOperation(scopeObject, query); ok {
+		return operationForMethod(op, r.Method)
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		return "GetObject"
+	case http.MethodHead:
+		return "HeadObject"
+	case http.MethodPut:
+		if copySource {
+			return "CopyObject"
+		}
+		return "PutObject"
+	case http.MethodDelete:
+		return "DeleteObject"
+	}
+	return unknownOperation
+}
+
+// subResourceOperation returns the registry entry for the first sub-resource
+// present in query. The multipart sub-resources are matched by the callers.
+func subResourceOperation(scope operationScope, query url.Values) (s3Operation, bool) {
+	for _, op := range s3Operations {
+		if op.Scope != scope || op.SubResource == "uploadId" || (op.Scope == scopeObject && op.SubResource == "uploads") {
+			continue
+		}
+		if _, ok := query[op.SubResource]; ok {
+			return op, true
+		}
+	}
+	return s3Operation{}, false
+}
+
+// operationMethodPrefixes are the operation name prefixes each method
+// selects within a sub-resource, e.g. GET ?lifecycle is GetBucketLifecycleConfiguration.
+var operationMethodPrefixes = map[string][]string{
+	http.MethodGet:    {"Get", "List"},
+	http.MethodPut:    {"Put"},
+	http.MethodDelete: {"Delete"},
+}
+
+// operationForMethod returns the operation of op a request with method invokes.
+func operationForMethod(op s3Operation, method string) string {
+	for _, prefix := range operationMethodPrefixes[method] {
+		for _, name := range op.Operations {
+			if strings.HasPrefix(name, prefix) {
+				return name
+			}
+		}
+	}
+	return unknownOperation
+}
+
+// queryParameters are the query parameters, besides the sub-resources in
+// s3Operations, that the router and handlers read or that clients send with
+// every request of some kind.
+var queryParameters = map[string]bool{
+	// Listings
+	"prefix": true, "delimiter": true, "marker": true, "max-keys": true,
+	"list-type": true, "continuation-token": true, "start-after": true,
+	"fetch-owner": true, "encoding-type": true,
+	"key-marker": true, "version-id-marker": true, "upload-id-marker": true,
+	"max-uploads": true, "max-parts": true, "part-number-marker": true,
+
+	// Versions and parts
+	"versionId": true, "partNumber": true,
+
+	// Arguments of sub-resource operations, e.g. ?inventory&id=daily
+	"id": true, "select-type": true,
+
+	// GetObject response header overrides
+	"response-cache-control": true, "response-content-disposition": true,
+	"response-content-encoding": true, "response-content-language": true,
+	"response-content-type": true, "response-expires": true,
+
+	// Presigned Signature Version 2 URLs; V4 parameters start with X-Amz-
+	"AWSAccessKeyId": true, "Signature": true, "Expires": true,
+
+	// Operation hint added by the AWS SDKs
+	"x-id": true,
+}
+
+// unknownQueryParameter returns the first query parameter, in sorted order,
+// that is neither a sub-resource nor another parameter the server recognizes.
+func unknownQueryParameter(query url.Values) (string, bool) {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if queryParameters[name] || strings.HasPrefix(strings.ToLower(name), "x-amz-") {
+			continue
+		}
+		if !slices.ContainsFunc(s3Operations, func(op s3Operation) bool { return op.SubResource == name }) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// unknownQueryParameterError is the strict mode response for an unrecognized
+// query parameter.
+func unknownQueryParameterError(name string) S3Error {
+	return S3Error{
+		Code:           "InvalidArgument",
+		Message:        fmt.Sprintf("Unrecognized query parameter: %s.", name),
+		HTTPStatusCode: http.StatusBadRequest,
+	}
+}
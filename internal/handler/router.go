@@ -2,6 +2,7 @@
 package handler
 
 import (
+	"net"
 	"net/http"
 	"strings"
 
@@ -17,12 +18,26 @@ type Router struct {
 	bucketHandler     *BucketHandler
 	objectHandler     *ObjectHandler
 	multipartHandler  *MultipartHandler
+	lifecycleHandler  *LifecycleHandler
+	policyHandler     *BucketPolicyHandler
+	corsHandler       *CORSHandler
+	notification      *NotificationHandler
+	batchHandler      *BatchHandler
+	adminHandler      *AdminHandler
+	signingDebug      *SigningDebugHandler
+	capabilities      *CapabilitiesHandler
 	healthChecker     *HealthChecker
 	authMiddleware    func(http.Handler) http.Handler
 	rateLimiter       *middleware.RateLimiter
+	listLimiter       *middleware.ConcurrencyLimiter
+	memoryBudget      *middleware.MemoryBudget
+	maxMetaHeaders    int
+	strictQuery       bool
+	baseDomain        string
 	tracing           *middleware.Tracing
 	metricsMiddleware *middleware.MetricsMiddleware
 	metrics           *metrics.Metrics
+	metricsPath       string
 	logger            zerolog.Logger
 }
 
@@ -31,11 +46,25 @@ type RouterConfig struct {
 	BucketHandler    *BucketHandler
 	ObjectHandler    *ObjectHandler
 	MultipartHandler *MultipartHandler
+	LifecycleHandler *LifecycleHandler    // Optional - enables the bucket lifecycle configuration API
+	PolicyHandler    *BucketPolicyHandler // Optional - enables the bucket policy API
+	CORSHandler      *CORSHandler         // Optional - enables the bucket CORS API and cross-origin requests
+	Notification     *NotificationHandler // Optional - enables the bucket notification API
+	BatchHandler     *BatchHandler        // Optional - enables the batch ingestion endpoint
+	AdminHandler     *AdminHandler        // Optional - enables the operator API
+	SigningDebug     *SigningDebugHandler // Optional - enables the signing debug endpoint
+	Capabilities     *CapabilitiesHandler // Optional - enables the capabilities endpoint
 	HealthChecker    *HealthChecker
 	AuthMiddleware   func(http.Handler) http.Handler
 	RateLimiter      *middleware.RateLimiter
+	ListLimiter      *middleware.ConcurrencyLimiter // Optional - bounds concurrent list operations
+	MemoryBudget     *middleware.MemoryBudget       // Optional - bounds memory held by buffered request bodies
+	MaxMetaHeaders   int                            // Optional - maximum x-amz-meta-* headers per request (0 = unlimited)
+	StrictQuery      bool                           // Optional - rejects unrecognized query parameters with InvalidArgument
+	BaseDomain       string                         // Optional - endpoint domain enabling virtual-hosted-style <bucket>.<domain> requests
 	Tracing          *middleware.Tracing
 	Metrics          *metrics.Metrics
+	MetricsPath      string // Optional - serves Metrics at this path on the API port
 	Logger           zerolog.Logger
 }
 
@@ -50,12 +79,26 @@ func NewRouter(config RouterConfig) *Router {
 		bucketHandler:     config.BucketHandler,
 		objectHandler:     config.ObjectHandler,
 		multipartHandler:  config.MultipartHandler,
+		lifecycleHandler:  config.LifecycleHandler,
+		policyHandler:     config.PolicyHandler,
+		corsHandler:       config.CORSHandler,
+		notification:      config.Notification,
+		batchHandler:      config.BatchHandler,
+		adminHandler:      config.AdminHandler,
+		signingDebug:      config.SigningDebug,
+		capabilities:      config.Capabilities,
 		healthChecker:     config.HealthChecker,
 		authMiddleware:    config.AuthMiddleware,
 		rateLimiter:       config.RateLimiter,
+		listLimiter:       config.ListLimiter,
+		memoryBudget:      config.MemoryBudget,
+		maxMetaHeaders:    config.MaxMetaHeaders,
+		strictQuery:       config.StrictQuery,
+		baseDomain:        strings.ToLower(strings.TrimSuffix(config.BaseDomain, ".")),
 		tracing:           config.Tracing,
 		metricsMiddleware: metricsMiddleware,
 		metrics:           config.Metrics,
+		metricsPath:       config.MetricsPath,
 		logger:            config.Logger.With().Str("component", "router").Logger(),
 	}
 }
@@ -73,22 +116,58 @@ func (rt *Router) Handler() http.Handler {
 		mux.HandleFunc("/health", rt.handleHealth)
 	}
 
+	// Prometheus metrics endpoint (no auth, like the health checks)
+	if rt.metrics != nil && rt.metricsPath != "" {
+		mux.Handle(rt.metricsPath, rt.metrics.Handler())
+	}
+
+	// Batch ingestion endpoint (authenticated, outside the S3 API surface)
+	if rt.batchHandler != nil {
+		mux.HandleFunc(BatchPathPrefix, rt.handleBatchRequest)
+	}
+
+	// Operator API (authenticated, admin users only)
+	if rt.adminHandler != nil {
+		mux.Handle(AdminPathPrefix, rt.adminHandler)
+	}
+
+	// Capabilities endpoint (authenticated unless its path skips auth)
+	if rt.capabilities != nil {
+		mux.Handle(CapabilitiesPath, rt.capabilities)
+	}
+
 	// Main S3 API handler
 	mux.HandleFunc("/", rt.handleS3Request)
 
 	// Build middleware chain (innermost to outermost)
 	var handler http.Handler = mux
 
-	// Auth middleware (innermost - after tracing, before rate limiting)
-	handler = rt.authMiddleware(handler)
-
-	// Rate limiting middleware
+	// Rate limiting middleware (after auth, so that authenticated requests
+	// are limited per access key)
 	if rt.rateLimiter != nil {
 		handler = rt.rateLimiter.Middleware(handler)
 	}
 
-	// Metrics middleware (track in-flight requests)
+	// Auth middleware
+	handler = rt.authMiddleware(handler)
+
+	// Resolve the bucket of virtual-hosted-style requests before auth, which
+	// checks its ACL and policy and signs it into SigV2 canonical resources
+	handler = rt.withVirtualHostBucket(handler)
+
+	// Signing debug endpoint. It bypasses auth because the signature it
+	// describes was computed for another path, and answers 404 when disabled.
+	handler = rt.withSigningDebug(handler)
+
+	// Reject pathological metadata before signature verification parses it
+	handler = rt.withMetadataHeaderLimit(handler)
+
+	// CORS preflights carry no credentials, so they are answered before auth
+	handler = rt.withCORS(handler)
+
+	// Metrics middleware (track in-flight requests and S3 operations)
 	if rt.metricsMiddleware != nil {
+		handler = rt.withOperation(mux, handler)
 		handler = rt.metricsMiddleware.Middleware(handler)
 	}
 
@@ -100,6 +179,118 @@ func (rt *Router) Handler() http.Handler {
 	return handler
 }
 
+// withOperation attributes S3 API requests to the operation they invoke for
+// the metrics middleware. It runs before auth and rate limiting so rejected
+// requests are counted against their operation too.
+func (rt *Router) withOperation(mux *http.ServeMux, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Only the catch-all pattern serves the S3 API
+		if _, pattern := mux.Handler(r); pattern == "/" && !strings.HasPrefix(r.URL.Path, SigningDebugPathPrefix) {
+			middleware.SetOperation(w, rt.operationName(r))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// withVirtualHostBucket records the bucket of virtual-hosted-style requests
+// in the request context.
+func (rt *Router) withVirtualHostBucket(next http.Handler) http.Handler {
+	if rt.baseDomain == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if bucketName, ok := rt.virtualHostBucket(r); ok {
+			r = r.WithContext(auth.WithVirtualHostBucket(r.Context(), bucketName))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// withMetadataHeaderLimit rejects requests carrying more x-amz-meta-* headers
+// than allowed. The total header size is bounded by the server's MaxHeaderBytes.
+func (rt *Router) withMetadataHeaderLimit(next http.Handler) http.Handler {
+	if rt.maxMetaHeaders <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count := 0
+		for key, values := range r.Header {
+			if strings.HasPrefix(strings.ToLower(key), "x-amz-meta-") {
+				count += len(values)
+			}
+		}
+		if count > rt.maxMetaHeaders {
+			rt.logger.Warn().
+				Str("path", r.URL.Path).
+				Int("metadata_headers", count).
+				Int("limit", rt.maxMetaHeaders).
+				Msg("request rejected: too many metadata headers")
+			writeError(w, ErrMetadataTooLarge)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// withSigningDebug routes signing debug requests away from next.
+func (rt *Router) withSigningDebug(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != SigningDebugPathPrefix && !strings.HasPrefix(r.URL.Path, SigningDebugPathPrefix+"/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if rt.signingDebug == nil {
+			writeError(w, S3Error{
+				Code:           "NotFound",
+				Message:        "The signing debug endpoint is disabled.",
+				HTTPStatusCode: http.StatusNotFound,
+			})
+			return
+		}
+		rt.signingDebug.ServeHTTP(w, r)
+	})
+}
+
+// withCORS answers CORS preflight requests against buckets and adds the
+// Access-Control-* headers of the matching CORS rule to actual cross-origin
+// requests.
+func (rt *Router) withCORS(next http.Handler) http.Handler {
+	if rt.corsHandler == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bucketName, ok := rt.corsBucket(r)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if r.Method == http.MethodOptions {
+			rt.corsHandler.Preflight(w, r, bucketName)
+			return
+		}
+		rt.corsHandler.AddResponseHeaders(w, r, bucketName)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// corsBucket returns the bucket a request addresses, for requests that are
+// subject to the bucket's CORS configuration.
+func (rt *Router) corsBucket(r *http.Request) (string, bool) {
+	if bucketName, ok := rt.virtualHostBucket(r); ok {
+		return bucketName, true
+	}
+	switch r.URL.Path {
+	case "/health", "/healthz", "/readyz":
+		return "", false
+	}
+	bucketName, _, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	// Bucket names can not start with "_", which marks the server's own endpoints
+	if bucketName == "" || strings.HasPrefix(bucketName, "_") {
+		return "", false
+	}
+	return bucketName, true
+}
+
 // handleHealth handles health check requests.
 func (rt *Router) handleHealth(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -107,11 +298,41 @@ func (rt *Router) handleHealth(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(`{"status":"healthy"}`))
 }
 
+// handleBatchRequest routes batch ingestion requests.
+func (rt *Router) handleBatchRequest(w http.ResponseWriter, r *http.Request) {
+	bucketName := strings.TrimPrefix(r.URL.Path, BatchPathPrefix)
+	if bucketName == "" || strings.Contains(bucketName, "/") {
+		writeError(w, ErrInvalidBucketName)
+		return
+	}
+	rt.batchHandler.BatchPut(w, r, bucketName)
+}
+
 // handleS3Request routes S3 API requests to appropriate handlers.
 func (rt *Router) handleS3Request(w http.ResponseWriter, r *http.Request) {
 	path := r.URL.Path
 	query := r.URL.Query()
 
+	// S3 ignores unknown query parameters; strict mode reports them to
+	// catch client bugs such as a misspelled sub-resource
+	if rt.strictQuery {
+		if name, ok := unknownQueryParameter(query); ok {
+			writeError(w, unknownQueryParameterError(name))
+			return
+		}
+	}
+
+	// Virtual-hosted-style: the bucket is in the Host header and the whole path is the key
+	if bucketName, ok := rt.virtualHostBucket(r); ok {
+		r = r.WithContext(auth.WithVirtualHostBucket(r.Context(), bucketName))
+		if objectKey := strings.TrimPrefix(path, "/"); objectKey != "" {
+			rt.handleObjectRequest(w, r, bucketName, objectKey)
+			return
+		}
+		rt.handleBucketRequest(w, r, bucketName, query)
+		return
+	}
+
 	// Root path - list all buckets
 	if path == "/" {
 		if r.Method == http.MethodGet {
@@ -146,8 +367,34 @@ func (rt *Router) handleS3Request(w http.ResponseWriter, r *http.Request) {
 	rt.handleBucketRequest(w, r, bucketName, query)
 }
 
+// virtualHostBucket returns the bucket named by a <bucket>.<base domain> Host
+// header. Requests to the base domain itself, or to any other host, are
+// path-style.
+func (rt *Router) virtualHostBucket(r *http.Request) (string, bool) {
+	if rt.baseDomain == "" {
+		return "", false
+	}
+
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+
+	bucketName, ok := strings.CutSuffix(host, "."+rt.baseDomain)
+	if !ok || bucketName == "" {
+		return "", false
+	}
+	return bucketName, true
+}
+
 // handleBucketRequest routes bucket-level requests.
 func (rt *Router) handleBucketRequest(w http.ResponseWriter, r *http.Request, bucketName string, query map[string][]string) {
+	if op, ok := unimplementedOperation(scopeBucket, query); ok {
+		writeError(w, notImplementedError(op))
+		return
+	}
+
 	// Check for sub-resource operations
 	if _, ok := query["versioning"]; ok {
 		switch r.Method {
@@ -165,10 +412,28 @@ func (rt *Router) handleBucketRequest(w http.ResponseWriter, r *http.Request, bu
 		return
 	}
 
+	// Check for delete sub-resource (DeleteObjects)
+	if _, ok := query["delete"]; ok {
+		if r.Method == http.MethodPost {
+			rt.withMemoryBudget(w, maxDeleteObjectsBodySize, func() {
+				rt.objectHandler.DeleteObjects(w, r, bucketName)
+			})
+			return
+		}
+		writeError(w, S3Error{
+			Code:           "MethodNotAllowed",
+			Message:        "The specified method is not allowed against this resource.",
+			HTTPStatusCode: http.StatusMethodNotAllowed,
+		})
+		return
+	}
+
 	// Check for versions sub-resource (ListObjectVersions)
 	if _, ok := query["versions"]; ok {
 		if r.Method == http.MethodGet {
-			rt.objectHandler.ListObjectVersions(w, r, bucketName)
+			rt.withListLimit(w, func() {
+				rt.objectHandler.ListObjectVersions(w, r, bucketName)
+			})
 			return
 		}
 		writeError(w, S3Error{
@@ -182,7 +447,9 @@ func (rt *Router) handleBucketRequest(w http.ResponseWriter, r *http.Request, bu
 	// Check for uploads sub-resource (ListMultipartUploads)
 	if _, ok := query["uploads"]; ok {
 		if r.Method == http.MethodGet {
-			rt.multipartHandler.ListMultipartUploads(w, r, bucketName)
+			rt.withListLimit(w, func() {
+				rt.multipartHandler.ListMultipartUploads(w, r, bucketName)
+			})
 			return
 		}
 		writeError(w, S3Error{
@@ -193,7 +460,156 @@ func (rt *Router) handleBucketRequest(w http.ResponseWriter, r *http.Request, bu
 		return
 	}
 
-	// TODO: Add more sub-resources (lifecycle, policy, acl, etc.)
+	// Check for ownershipControls sub-resource
+	if _, ok := query["ownershipControls"]; ok {
+		switch r.Method {
+		case http.MethodGet:
+			rt.bucketHandler.GetBucketOwnershipControls(w, r)
+		case http.MethodPut:
+			rt.bucketHandler.PutBucketOwnershipControls(w, r)
+		default:
+			writeError(w, S3Error{
+				Code:           "MethodNotAllowed",
+				Message:        "The specified method is not allowed against this resource.",
+				HTTPStatusCode: http.StatusMethodNotAllowed,
+			})
+		}
+		return
+	}
+
+	// Check for object-lock sub-resource
+	if _, ok := query["object-lock"]; ok {
+		switch r.Method {
+		case http.MethodGet:
+			rt.bucketHandler.GetObjectLockConfiguration(w, r)
+		case http.MethodPut:
+			rt.withMemoryBudget(w, maxObjectLockBodySize, func() {
+				rt.bucketHandler.PutObjectLockConfiguration(w, r)
+			})
+		default:
+			writeError(w, S3Error{
+				Code:           "MethodNotAllowed",
+				Message:        "The specified method is not allowed against this resource.",
+				HTTPStatusCode: http.StatusMethodNotAllowed,
+			})
+		}
+		return
+	}
+
+	// Check for lifecycle sub-resource
+	if _, ok := query["lifecycle"]; ok {
+		if rt.lifecycleHandler == nil {
+			writeError(w, ErrNotImplemented)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			rt.lifecycleHandler.GetBucketLifecycleConfiguration(w, r, bucketName)
+		case http.MethodPut:
+			rt.withMemoryBudget(w, maxLifecycleBodySize, func() {
+				rt.lifecycleHandler.PutBucketLifecycleConfiguration(w, r, bucketName)
+			})
+		case http.MethodDelete:
+			rt.lifecycleHandler.DeleteBucketLifecycle(w, r, bucketName)
+		default:
+			writeError(w, S3Error{
+				Code:           "MethodNotAllowed",
+				Message:        "The specified method is not allowed against this resource.",
+				HTTPStatusCode: http.StatusMethodNotAllowed,
+			})
+		}
+		return
+	}
+
+	// Check for cors sub-resource
+	if _, ok := query["cors"]; ok {
+		if rt.corsHandler == nil {
+			writeError(w, ErrNotImplemented)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			rt.corsHandler.GetBucketCORS(w, r, bucketName)
+		case http.MethodPut:
+			rt.withMemoryBudget(w, maxCORSBodySize, func() {
+				rt.corsHandler.PutBucketCORS(w, r, bucketName)
+			})
+		case http.MethodDelete:
+			rt.corsHandler.DeleteBucketCORS(w, r, bucketName)
+		default:
+			writeError(w, S3Error{
+				Code:           "MethodNotAllowed",
+				Message:        "The specified method is not allowed against this resource.",
+				HTTPStatusCode: http.StatusMethodNotAllowed,
+			})
+		}
+		return
+	}
+
+	// Check for notification sub-resource
+	if _, ok := query["notification"]; ok {
+		if rt.notification == nil {
+			writeError(w, ErrNotImplemented)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			rt.notification.GetBucketNotificationConfiguration(w, r, bucketName)
+		case http.MethodPut:
+			rt.withMemoryBudget(w, maxNotificationBodySize, func() {
+				rt.notification.PutBucketNotificationConfiguration(w, r, bucketName)
+			})
+		default:
+			writeError(w, S3Error{
+				Code:           "MethodNotAllowed",
+				Message:        "The specified method is not allowed against this resource.",
+				HTTPStatusCode: http.StatusMethodNotAllowed,
+			})
+		}
+		return
+	}
+
+	// Check for policy sub-resource
+	if _, ok := query["policy"]; ok {
+		if rt.policyHandler == nil {
+			writeError(w, ErrNotImplemented)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			rt.policyHandler.GetBucketPolicy(w, r, bucketName)
+		case http.MethodPut:
+			rt.withMemoryBudget(w, auth.MaxPolicySize, func() {
+				rt.policyHandler.PutBucketPolicy(w, r, bucketName)
+			})
+		case http.MethodDelete:
+			rt.policyHandler.DeleteBucketPolicy(w, r, bucketName)
+		default:
+			writeError(w, S3Error{
+				Code:           "MethodNotAllowed",
+				Message:        "The specified method is not allowed against this resource.",
+				HTTPStatusCode: http.StatusMethodNotAllowed,
+			})
+		}
+		return
+	}
+
+	// Check for acl sub-resource
+	if _, ok := query["acl"]; ok {
+		switch r.Method {
+		case http.MethodGet:
+			rt.bucketHandler.GetBucketACL(w, r)
+		case http.MethodPut:
+			rt.bucketHandler.PutBucketACL(w, r)
+		default:
+			writeError(w, S3Error{
+				Code:           "MethodNotAllowed",
+				Message:        "The specified method is not allowed against this resource.",
+				HTTPStatusCode: http.StatusMethodNotAllowed,
+			})
+		}
+		return
+	}
 
 	// Basic bucket operations
 	switch r.Method {
@@ -220,6 +636,11 @@ func (rt *Router) handleBucketRequest(w http.ResponseWriter, r *http.Request, bu
 func (rt *Router) handleObjectRequest(w http.ResponseWriter, r *http.Request, bucketName, objectKey string) {
 	query := r.URL.Query()
 
+	if op, ok := unimplementedOperation(scopeObject, query); ok {
+		writeError(w, notImplementedError(op))
+		return
+	}
+
 	// Check for multipart upload operations
 	uploadID := query.Get("uploadId")
 	_, hasUploads := query["uploads"]
@@ -234,12 +655,19 @@ func (rt *Router) handleObjectRequest(w http.ResponseWriter, r *http.Request, bu
 	if uploadID != "" {
 		switch r.Method {
 		case http.MethodPut:
+			// UploadPartCopy: PUT /{bucket}/{key}?partNumber=N&uploadId=X with x-amz-copy-source
+			if r.Header.Get("x-amz-copy-source") != "" {
+				rt.multipartHandler.UploadPartCopy(w, r, bucketName, objectKey)
+				return
+			}
 			// UploadPart: PUT /{bucket}/{key}?partNumber=N&uploadId=X
 			rt.multipartHandler.UploadPart(w, r, bucketName, objectKey)
 			return
 		case http.MethodPost:
 			// CompleteMultipartUpload: POST /{bucket}/{key}?uploadId=X
-			rt.multipartHandler.CompleteMultipartUpload(w, r, bucketName, objectKey)
+			rt.withMemoryBudget(w, maxCompleteMultipartBodySize, func() {
+				rt.multipartHandler.CompleteMultipartUpload(w, r, bucketName, objectKey)
+			})
 			return
 		case http.MethodDelete:
 			// AbortMultipartUpload: DELETE /{bucket}/{key}?uploadId=X
@@ -252,6 +680,96 @@ func (rt *Router) handleObjectRequest(w http.ResponseWriter, r *http.Request, bu
 		}
 	}
 
+	// Object ACL operations: GET/PUT /{bucket}/{key}?acl
+	if _, ok := query["acl"]; ok {
+		switch r.Method {
+		case http.MethodGet:
+			rt.objectHandler.GetObjectACL(w, r, bucketName, objectKey)
+		case http.MethodPut:
+			rt.objectHandler.PutObjectACL(w, r, bucketName, objectKey)
+		default:
+			writeError(w, S3Error{
+				Code:           "MethodNotAllowed",
+				Message:        "The specified method is not allowed against this resource.",
+				HTTPStatusCode: http.StatusMethodNotAllowed,
+			})
+		}
+		return
+	}
+
+	// Object tagging operations: GET/PUT/DELETE /{bucket}/{key}?tagging
+	if _, ok := query["tagging"]; ok {
+		switch r.Method {
+		case http.MethodGet:
+			rt.objectHandler.GetObjectTagging(w, r, bucketName, objectKey)
+		case http.MethodPut:
+			rt.withMemoryBudget(w, maxTaggingBodySize, func() {
+				rt.objectHandler.PutObjectTagging(w, r, bucketName, objectKey)
+			})
+		case http.MethodDelete:
+			rt.objectHandler.DeleteObjectTagging(w, r, bucketName, objectKey)
+		default:
+			writeError(w, S3Error{
+				Code:           "MethodNotAllowed",
+				Message:        "The specified method is not allowed against this resource.",
+				HTTPStatusCode: http.StatusMethodNotAllowed,
+			})
+		}
+		return
+	}
+
+	// Object retention: GET/PUT /{bucket}/{key}?retention
+	if _, ok := query["retention"]; ok {
+		switch r.Method {
+		case http.MethodGet:
+			rt.objectHandler.GetObjectRetention(w, r, bucketName, objectKey)
+		case http.MethodPut:
+			rt.withMemoryBudget(w, maxObjectLockBodySize, func() {
+				rt.objectHandler.PutObjectRetention(w, r, bucketName, objectKey)
+			})
+		default:
+			writeError(w, S3Error{
+				Code:           "MethodNotAllowed",
+				Message:        "The specified method is not allowed against this resource.",
+				HTTPStatusCode: http.StatusMethodNotAllowed,
+			})
+		}
+		return
+	}
+
+	// Object legal hold: GET/PUT /{bucket}/{key}?legal-hold
+	if _, ok := query["legal-hold"]; ok {
+		switch r.Method {
+		case http.MethodGet:
+			rt.objectHandler.GetObjectLegalHold(w, r, bucketName, objectKey)
+		case http.MethodPut:
+			rt.withMemoryBudget(w, maxObjectLockBodySize, func() {
+				rt.objectHandler.PutObjectLegalHold(w, r, bucketName, objectKey)
+			})
+		default:
+			writeError(w, S3Error{
+				Code:           "MethodNotAllowed",
+				Message:        "The specified method is not allowed against this resource.",
+				HTTPStatusCode: http.StatusMethodNotAllowed,
+			})
+		}
+		return
+	}
+
+	// Object attributes: GET /{bucket}/{key}?attributes
+	if _, ok := query["attributes"]; ok {
+		if r.Method != http.MethodGet {
+			writeError(w, S3Error{
+				Code:           "MethodNotAllowed",
+				Message:        "The specified method is not allowed against this resource.",
+				HTTPStatusCode: http.StatusMethodNotAllowed,
+			})
+			return
+		}
+		rt.objectHandler.GetObjectAttributes(w, r, bucketName, objectKey)
+		return
+	}
+
 	// Standard object operations
 	switch r.Method {
 	case http.MethodGet:
@@ -280,14 +798,47 @@ func (rt *Router) handleObjectRequest(w http.ResponseWriter, r *http.Request, bu
 func (rt *Router) handleListObjects(w http.ResponseWriter, r *http.Request, bucketName string) {
 	query := r.URL.Query()
 
-	// Check for list-type=2 (ListObjectsV2)
-	if query.Get("list-type") == "2" {
-		rt.objectHandler.ListObjectsV2(w, r, bucketName)
+	rt.withListLimit(w, func() {
+		// Check for list-type=2 (ListObjectsV2)
+		if query.Get("list-type") == "2" {
+			rt.objectHandler.ListObjectsV2(w, r, bucketName)
+			return
+		}
+
+		// ListObjectsV1
+		rt.objectHandler.ListObjects(w, r, bucketName)
+	})
+}
+
+// withListLimit runs list unless too many list operations are already in
+// flight, in which case the client is asked to slow down. Listings are
+// bounded separately from the request rate limiter because a few expensive
+// delimiter/prefix scans can saturate the database on their own.
+func (rt *Router) withListLimit(w http.ResponseWriter, list func()) {
+	release, ok := rt.listLimiter.TryAcquire()
+	if !ok {
+		w.Header().Set("Retry-After", "1")
+		writeError(w, ErrSlowDown)
+		return
+	}
+	defer release()
+
+	list()
+}
+
+// withMemoryBudget runs handle, which buffers up to size bytes of the request
+// body, unless the memory budget cannot cover it, in which case the client is
+// asked to slow down. The reservation is released when handle returns.
+func (rt *Router) withMemoryBudget(w http.ResponseWriter, size int64, handle func()) {
+	release, ok := rt.memoryBudget.TryReserve(size)
+	if !ok {
+		w.Header().Set("Retry-After", "1")
+		writeError(w, ErrSlowDown)
 		return
 	}
+	defer release()
 
-	// ListObjectsV1
-	rt.objectHandler.ListObjects(w, r, bucketName)
+	handle()
 }
 
 // CreateAuthMiddleware creates an authentication middleware using the provided store.
@@ -0,0 +1,649 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/auth"
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/lock"
+	"github.com/prn-tf/alexander-storage/internal/metrics"
+	"github.com/prn-tf/alexander-storage/internal/middleware"
+	"github.com/prn-tf/alexander-storage/internal/repository"
+	"github.com/prn-tf/alexander-storage/internal/service"
+)
+
+// blockingListRepository holds every List call until release is closed.
+type blockingListRepository struct {
+	stubObjectRepository
+	started chan struct{}
+	release chan struct{}
+}
+
+func (r *blockingListRepository) List(ctx context.Context, bucketID int64, opts repository.ObjectListOptions) (*repository.ObjectListResult, error) {
+	r.started <- struct{}{}
+	<-r.release
+	return &repository.ObjectListResult{}, nil
+}
+
+func TestRouter_ListConcurrencyLimit(t *testing.T) {
+	const limit = 2
+
+	buckets := &stubBucketRepository{buckets: map[string]*domain.Bucket{
+		"logs": {ID: 1, Name: "logs", OwnerID: 1},
+	}}
+	objects := &blockingListRepository{
+		started: make(chan struct{}, limit),
+		release: make(chan struct{}),
+	}
+	svc := service.NewObjectService(objects, nil, buckets, nil, lock.NewNoOpLocker(), zerolog.Nop())
+	rt := NewRouter(RouterConfig{
+		ObjectHandler: NewObjectHandler(svc, nil, zerolog.Nop()),
+		ListLimiter:   middleware.NewConcurrencyLimiter("list", limit, nil, zerolog.Nop()),
+		Logger:        zerolog.Nop(),
+	})
+
+	list := func(target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		rt.handleS3Request(rec, withTestUser(httptest.NewRequest(http.MethodGet, target, nil)))
+		return rec
+	}
+
+	// Saturate the limiter with listings stuck in the repository
+	var wg sync.WaitGroup
+	codes := make([]int, limit)
+	for i := 0; i < limit; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			codes[i] = list("/logs?list-type=2").Code
+		}(i)
+	}
+	for i := 0; i < limit; i++ {
+		<-objects.started
+	}
+
+	for _, target := range []string{"/logs", "/logs?list-type=2", "/logs?versions"} {
+		rec := list(target)
+		requireErrorCode(t, rec, http.StatusServiceUnavailable, "SlowDown")
+		require.Equal(t, "1", rec.Header().Get("Retry-After"))
+	}
+
+	close(objects.release)
+	wg.Wait()
+	for _, code := range codes {
+		require.Equal(t, http.StatusOK, code)
+	}
+
+	// Slots are returned once the listings finish
+	require.Equal(t, http.StatusOK, list("/logs").Code)
+}
+
+func TestRouter_MemoryBudget(t *testing.T) {
+	buckets := &stubBucketRepository{buckets: map[string]*domain.Bucket{
+		"logs": {ID: 1, Name: "logs", OwnerID: 1},
+	}}
+	svc := service.NewObjectService(&stubObjectRepository{}, nil, buckets, nil, lock.NewNoOpLocker(), zerolog.Nop())
+	budget := middleware.NewMemoryBudget(maxDeleteObjectsBodySize, nil, zerolog.Nop())
+	rt := NewRouter(RouterConfig{
+		ObjectHandler: NewObjectHandler(svc, nil, zerolog.Nop()),
+		MemoryBudget:  budget,
+		Logger:        zerolog.Nop(),
+	})
+
+	deleteObjects := func() *httptest.ResponseRecorder {
+		body := strings.NewReader(`<Delete><Object><Key>app.log</Key></Object></Delete>`)
+		rec := httptest.NewRecorder()
+		rt.handleS3Request(rec, withTestUser(httptest.NewRequest(http.MethodPost, "/logs?delete", body)))
+		return rec
+	}
+
+	// Reserve the whole budget, as in-flight buffering requests would
+	release, ok := budget.TryReserve(maxDeleteObjectsBodySize)
+	require.True(t, ok)
+
+	rec := deleteObjects()
+	requireErrorCode(t, rec, http.StatusServiceUnavailable, "SlowDown")
+	require.Equal(t, "1", rec.Header().Get("Retry-After"))
+
+	// The request is served once memory is released, and releases its own reservation
+	release()
+	require.Equal(t, http.StatusOK, deleteObjects().Code)
+	require.Zero(t, budget.Reserved())
+}
+
+func TestRouter_RateLimitPerAccessKey(t *testing.T) {
+	keys := map[string]*auth.AuthContext{
+		"AKIADEFAULT": {UserID: 1, AccessKeyID: "AKIADEFAULT"},
+		"AKIABULK":    {UserID: 1, AccessKeyID: "AKIABULK", RateLimitBurst: 3},
+	}
+	rateLimiter := middleware.NewRateLimiter(middleware.RateLimiterConfig{
+		RequestsPerSecond: 0.001,
+		BurstSize:         1,
+		Enabled:           true,
+		CleanupInterval:   time.Minute,
+		Identify:          auth.RateLimitClient,
+	}, nil, zerolog.Nop())
+	defer rateLimiter.Stop()
+
+	rt := NewRouter(RouterConfig{
+		// Attributes requests to the access key named in the test header
+		AuthMiddleware: func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if authCtx, ok := keys[r.Header.Get("X-Test-Access-Key")]; ok {
+					r = r.WithContext(context.WithValue(r.Context(), auth.AuthContextKey, authCtx))
+				}
+				next.ServeHTTP(w, r)
+			})
+		},
+		RateLimiter: rateLimiter,
+		Logger:      zerolog.Nop(),
+	})
+	handler := rt.Handler()
+
+	health := func(accessKey string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/health", nil)
+		req.Header.Set("X-Test-Access-Key", accessKey)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	require.Equal(t, http.StatusOK, health("AKIADEFAULT").Code)
+	rec := health("AKIADEFAULT")
+	requireErrorCode(t, rec, http.StatusServiceUnavailable, "SlowDown")
+	require.NotEmpty(t, rec.Header().Get("Retry-After"))
+
+	// The key's own burst applies, independently of other keys
+	for i := 0; i < 3; i++ {
+		require.Equal(t, http.StatusOK, health("AKIABULK").Code)
+	}
+	requireErrorCode(t, health("AKIABULK"), http.StatusServiceUnavailable, "SlowDown")
+}
+
+func TestRouter_MetadataHeaderLimit(t *testing.T) {
+	rt := NewRouter(RouterConfig{
+		AuthMiddleware: func(next http.Handler) http.Handler { return next },
+		MaxMetaHeaders: 100,
+		Logger:         zerolog.Nop(),
+	})
+	reached := false
+	handler := rt.withMetadataHeaderLimit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached = true
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	put := func(headers int) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/logs/app.log", nil)
+		for i := 0; i < headers; i++ {
+			req.Header.Set(fmt.Sprintf("x-amz-meta-field-%d", i), "value")
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	requireErrorCode(t, put(5000), http.StatusBadRequest, "MetadataTooLarge")
+	require.False(t, reached)
+
+	require.Equal(t, http.StatusOK, put(100).Code)
+	require.True(t, reached)
+
+	// The limit applies before authentication in the full chain
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/logs/app.log", nil)
+	for i := 0; i < 5000; i++ {
+		req.Header.Set(fmt.Sprintf("x-amz-meta-field-%d", i), "value")
+	}
+	rt.Handler().ServeHTTP(rec, req)
+	requireErrorCode(t, rec, http.StatusBadRequest, "MetadataTooLarge")
+}
+
+// publicACLChecker reports every bucket as public-read.
+type publicACLChecker struct{}
+
+func (publicACLChecker) GetBucketACL(context.Context, string) (string, error) {
+	return string(domain.ACLPublicRead), nil
+}
+
+func TestRouter_AnonymousListBucketsDenied(t *testing.T) {
+	bucketHandler, objectHandler, _ := newOwnershipTestHandlers(t)
+
+	anonymousEnabled := auth.DefaultConfig()
+	anonymousEnabled.AllowAnonymous = true
+	publicBuckets := auth.DefaultConfig()
+	publicBuckets.BucketACLChecker = publicACLChecker{}
+
+	for name, config := range map[string]auth.Config{
+		"anonymous access enabled": anonymousEnabled,
+		"public bucket ACLs":       publicBuckets,
+	} {
+		t.Run(name, func(t *testing.T) {
+			rt := NewRouter(RouterConfig{
+				BucketHandler:  bucketHandler,
+				ObjectHandler:  objectHandler,
+				AuthMiddleware: auth.Middleware(nil, config),
+				Logger:         zerolog.Nop(),
+			})
+
+			rec := httptest.NewRecorder()
+			rt.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			requireErrorCode(t, rec, http.StatusForbidden, "AccessDenied")
+		})
+	}
+
+	// The handler also rejects an anonymous principal on its own
+	rec := httptest.NewRecorder()
+	bucketHandler.ListBuckets(rec, withAuthContext(httptest.NewRequest(http.MethodGet, "/", nil), &auth.AuthContext{AuthType: auth.AuthTypeAnonymous}))
+	requireErrorCode(t, rec, http.StatusForbidden, "AccessDenied")
+}
+
+// staticACLChecker reports the ACLs of a fixed set of buckets.
+type staticACLChecker map[string]domain.BucketACL
+
+func (c staticACLChecker) GetBucketACL(ctx context.Context, bucketName string) (string, error) {
+	return string(c[bucketName]), nil
+}
+
+func TestRouter_AnonymousReadOfPublicReadBucket(t *testing.T) {
+	objectHandler, objects, _ := newPutObjectTestHandler(t)
+	acls := staticACLChecker{"uploads": domain.ACLPublicRead}
+
+	authorizer := auth.NewDefaultAuthorizer(nil)
+	authorizer.SetACLChecker(acls)
+	objectHandler.authorizer = authorizer
+
+	config := auth.DefaultConfig()
+	config.BucketACLChecker = acls
+	rt := NewRouter(RouterConfig{
+		ObjectHandler:  objectHandler,
+		AuthMiddleware: auth.Middleware(nil, config),
+		Logger:         zerolog.Nop(),
+	})
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		rt.Handler().ServeHTTP(rec, req)
+		return rec
+	}
+
+	// The owner uploads with credentials
+	rec := httptest.NewRecorder()
+	rt.handleS3Request(rec, withTestUser(httptest.NewRequest(http.MethodPut, "/uploads/cat.txt", strings.NewReader("meow"))))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rec = serve(httptest.NewRequest(http.MethodGet, "/uploads/cat.txt", nil))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.Equal(t, "meow", rec.Body.String())
+
+	rec = serve(httptest.NewRequest(http.MethodHead, "/uploads/cat.txt", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "4", rec.Header().Get("Content-Length"))
+
+	// Signing a request never grants less than sending it anonymously
+	guest := func(req *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		rt.handleS3Request(rec, withAuthContext(req, &auth.AuthContext{UserID: 2, Username: "guest", AuthType: auth.AuthTypeSignedV4}))
+		return rec
+	}
+	rec = guest(httptest.NewRequest(http.MethodGet, "/uploads/cat.txt", nil))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.Equal(t, "meow", rec.Body.String())
+	requireErrorCode(t, guest(httptest.NewRequest(http.MethodPut, "/uploads/dog.txt", strings.NewReader("woof"))), http.StatusForbidden, "AccessDenied")
+
+	// Writes stay authenticated, even with public-read-write
+	for _, acl := range []domain.BucketACL{domain.ACLPublicRead, domain.ACLPublicReadWrite} {
+		acls["uploads"] = acl
+		requireErrorCode(t, serve(httptest.NewRequest(http.MethodPut, "/uploads/dog.txt", strings.NewReader("woof"))), http.StatusForbidden, "AccessDenied")
+		requireErrorCode(t, serve(httptest.NewRequest(http.MethodDelete, "/uploads/cat.txt", nil)), http.StatusForbidden, "AccessDenied")
+	}
+	require.NotContains(t, objects.objects, "dog.txt")
+
+	// Only objects are public, not the bucket's other read operations
+	requireErrorCode(t, serve(httptest.NewRequest(http.MethodGet, "/uploads/cat.txt?acl", nil)), http.StatusForbidden, "AccessDenied")
+
+	// A private bucket needs credentials
+	acls["uploads"] = domain.ACLPrivate
+	requireErrorCode(t, serve(httptest.NewRequest(http.MethodGet, "/uploads/cat.txt", nil)), http.StatusForbidden, "AccessDenied")
+}
+
+func TestRouter_PolicyOpensBucketToSignedNonOwner(t *testing.T) {
+	objectHandler, objects, _ := newPutObjectTestHandler(t)
+	policy, err := auth.ParsePolicy("uploads", []byte(`{
+		"Version": "2012-10-17",
+		"Statement": [{"Effect": "Allow", "Principal": "*", "Action": ["s3:GetObject", "s3:PutObject"], "Resource": "arn:aws:s3:::uploads/shared/*"}]
+	}`))
+	require.NoError(t, err)
+
+	authorizer := auth.NewDefaultAuthorizer(nil)
+	authorizer.SetPolicyLookup(staticPolicyLookup{"uploads": policy})
+	objectHandler.authorizer = authorizer
+	rt := NewRouter(RouterConfig{ObjectHandler: objectHandler, Logger: zerolog.Nop()})
+
+	// User 2 signs its requests but does not own the bucket
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		rt.handleS3Request(rec, withAuthContext(req, &auth.AuthContext{UserID: 2, Username: "guest", AuthType: auth.AuthTypeSignedV4}))
+		return rec
+	}
+
+	rec := serve(httptest.NewRequest(http.MethodPut, "/uploads/shared/cat.txt", strings.NewReader("meow")))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.Contains(t, objects.objects, "shared/cat.txt")
+
+	rec = serve(httptest.NewRequest(http.MethodGet, "/uploads/shared/cat.txt", nil))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.Equal(t, "meow", rec.Body.String())
+
+	// Outside the policy the bucket stays its owner's
+	requireErrorCode(t, serve(httptest.NewRequest(http.MethodPut, "/uploads/private.txt", strings.NewReader("woof"))), http.StatusForbidden, "AccessDenied")
+	requireErrorCode(t, serve(httptest.NewRequest(http.MethodDelete, "/uploads/shared/cat.txt", nil)), http.StatusForbidden, "AccessDenied")
+	require.NotContains(t, objects.objects, "private.txt")
+	require.Contains(t, objects.objects, "shared/cat.txt")
+}
+
+// staticPolicyLookup returns fixed bucket policies.
+type staticPolicyLookup map[string]*auth.Policy
+
+func (l staticPolicyLookup) GetBucketPolicy(ctx context.Context, bucketName string) (*auth.Policy, error) {
+	return l[bucketName], nil
+}
+
+// staticAccessKeyStore holds a single access key of user 1.
+type staticAccessKeyStore struct{}
+
+func (staticAccessKeyStore) GetActiveAccessKey(ctx context.Context, accessKeyID string) (*auth.AccessKeyInfo, error) {
+	if accessKeyID != "AKIDVIRTUALHOST" {
+		return nil, domain.ErrAccessKeyNotFound
+	}
+	return &auth.AccessKeyInfo{AccessKeyID: accessKeyID, SecretKey: "virtual-host-secret", UserID: 1, IsActive: true}, nil
+}
+
+func (staticAccessKeyStore) UpdateLastUsed(ctx context.Context, accessKeyID string) error { return nil }
+
+func TestRouter_VirtualHostedAuth(t *testing.T) {
+	objectHandler, _, _ := newPutObjectTestHandler(t)
+	acls := staticACLChecker{"uploads": domain.ACLPublicRead}
+	listPolicy, err := auth.ParsePolicy("uploads", []byte(`{
+		"Version": "2012-10-17",
+		"Statement": [{"Effect": "Allow", "Principal": "*", "Action": "s3:ListBucket", "Resource": "arn:aws:s3:::uploads"}]
+	}`))
+	require.NoError(t, err)
+	policies := staticPolicyLookup{}
+
+	authorizer := auth.NewDefaultAuthorizer(nil)
+	authorizer.SetACLChecker(acls)
+	authorizer.SetPolicyLookup(policies)
+	objectHandler.authorizer = authorizer
+
+	config := auth.DefaultConfig()
+	config.BucketACLChecker = acls
+	config.BucketPolicyLookup = policies
+	config.AllowSignatureV2 = true
+	rt := NewRouter(RouterConfig{
+		ObjectHandler:  objectHandler,
+		AuthMiddleware: auth.Middleware(staticAccessKeyStore{}, config),
+		BaseDomain:     "s3.example.com",
+		Logger:         zerolog.Nop(),
+	})
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		req.Host = "uploads.s3.example.com"
+		rec := httptest.NewRecorder()
+		rt.Handler().ServeHTTP(rec, req)
+		return rec
+	}
+
+	// The owner uploads with credentials
+	rec := httptest.NewRecorder()
+	rt.handleS3Request(rec, withTestUser(httptest.NewRequest(http.MethodPut, "/uploads/cat.txt", strings.NewReader("meow"))))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	// The public-read ACL of the bucket in the Host header admits anonymous reads
+	rec = serve(httptest.NewRequest(http.MethodGet, "/cat.txt", nil))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.Equal(t, "meow", rec.Body.String())
+
+	// GET / lists the bucket rather than all buckets, so a policy can open it
+	requireErrorCode(t, serve(httptest.NewRequest(http.MethodGet, "/", nil)), http.StatusForbidden, "AccessDenied")
+	policies["uploads"] = listPolicy
+	rec = serve(httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.Contains(t, rec.Body.String(), "<Key>cat.txt</Key>")
+
+	// SigV2 signs the bucket from the Host header as the first path segment
+	acls["uploads"] = domain.ACLPrivate
+	signedV2 := func(resource string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/cat.txt", nil)
+		req.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+		stringToSign := "GET\n\n\n" + req.Header.Get("Date") + "\n" + resource
+		req.Header.Set("Authorization", auth.SignV2Prefix+"AKIDVIRTUALHOST:"+auth.GetSignatureV2("virtual-host-secret", stringToSign))
+		return req
+	}
+	rec = serve(signedV2("/uploads/cat.txt"))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.Equal(t, "meow", rec.Body.String())
+	requireErrorCode(t, serve(signedV2("/cat.txt")), http.StatusForbidden, "SignatureDoesNotMatch")
+}
+
+func TestRouter_VirtualHostedStyle(t *testing.T) {
+	buckets := &stubBucketRepository{buckets: map[string]*domain.Bucket{
+		"photos":         {ID: 1, Name: "photos", OwnerID: 1, Versioning: domain.VersioningEnabled},
+		"my.dotted.site": {ID: 2, Name: "my.dotted.site", OwnerID: 1, Versioning: domain.VersioningSuspended},
+	}}
+	objects := &stubObjectRepository{latest: map[string]*domain.Object{
+		"2024/cat.jpg": {ID: 1, BucketID: 1, Key: "2024/cat.jpg", IsLatest: true},
+		"index.html":   {ID: 2, BucketID: 2, Key: "index.html", IsLatest: true},
+	}}
+	objectSvc := service.NewObjectService(objects, nil, buckets, nil, lock.NewNoOpLocker(), zerolog.Nop())
+	rt := NewRouter(RouterConfig{
+		BucketHandler: NewBucketHandler(service.NewBucketService(buckets, zerolog.Nop()), nil, zerolog.Nop()),
+		ObjectHandler: NewObjectHandler(objectSvc, nil, zerolog.Nop()),
+		BaseDomain:    "s3.example.com",
+		Logger:        zerolog.Nop(),
+	})
+
+	tests := []struct {
+		name       string
+		method     string
+		host       string
+		target     string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "path-style object", method: http.MethodHead, host: "s3.example.com", target: "/photos/2024/cat.jpg", wantStatus: http.StatusOK},
+		{name: "path-style on another host", method: http.MethodHead, host: "localhost:8080", target: "/photos/2024/cat.jpg", wantStatus: http.StatusOK},
+		{name: "virtual-hosted object", method: http.MethodHead, host: "photos.s3.example.com", target: "/2024/cat.jpg", wantStatus: http.StatusOK},
+		{name: "virtual-hosted with port", method: http.MethodHead, host: "photos.S3.example.com:8443", target: "/2024/cat.jpg", wantStatus: http.StatusOK},
+		{name: "virtual-hosted path is the whole key", method: http.MethodHead, host: "photos.s3.example.com", target: "/photos/2024/cat.jpg", wantStatus: http.StatusNotFound},
+		{name: "dotted bucket object", method: http.MethodHead, host: "my.dotted.site.s3.example.com", target: "/index.html", wantStatus: http.StatusOK},
+		{name: "dotted bucket sub-resource", method: http.MethodGet, host: "my.dotted.site.s3.example.com", target: "/?versioning", wantStatus: http.StatusOK, wantBody: "Suspended"},
+		{name: "virtual-hosted sub-resource", method: http.MethodGet, host: "photos.s3.example.com", target: "/?versioning", wantStatus: http.StatusOK, wantBody: "Enabled"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := withTestUser(httptest.NewRequest(tt.method, tt.target, nil))
+			req.Host = tt.host
+			rec := httptest.NewRecorder()
+
+			rt.handleS3Request(rec, req)
+
+			require.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+			require.Contains(t, rec.Body.String(), tt.wantBody)
+		})
+	}
+}
+
+func TestRouter_UnimplementedOperations(t *testing.T) {
+	rt := NewRouter(RouterConfig{Logger: zerolog.Nop()})
+
+	tests := []struct {
+		method string
+		target string
+	}{
+		{http.MethodGet, "/photos?lifecycle"},
+		{http.MethodPut, "/photos?replication"},
+		{http.MethodGet, "/photos?inventory&id=daily"},
+		{http.MethodDelete, "/photos?analytics&id=report"},
+		{http.MethodPut, "/photos?intelligent-tiering&id=archive"},
+		{http.MethodGet, "/photos?tagging"},
+		{http.MethodPost, "/photos/data.csv?select&select-type=2"},
+		{http.MethodPost, "/photos/data.csv?restore"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.target, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			rt.handleS3Request(rec, withTestUser(httptest.NewRequest(tt.method, tt.target, nil)))
+			requireErrorCode(t, rec, http.StatusNotImplemented, "NotImplemented")
+		})
+	}
+}
+
+func TestRouter_StrictQuery(t *testing.T) {
+	buckets := &stubBucketRepository{buckets: map[string]*domain.Bucket{
+		"photos": {ID: 1, Name: "photos", OwnerID: 1, Versioning: domain.VersioningEnabled},
+	}}
+	objectSvc := service.NewObjectService(&stubObjectRepository{}, nil, buckets, nil, lock.NewNoOpLocker(), zerolog.Nop())
+	newRouter := func(strict bool) *Router {
+		return NewRouter(RouterConfig{
+			BucketHandler: NewBucketHandler(service.NewBucketService(buckets, zerolog.Nop()), nil, zerolog.Nop()),
+			ObjectHandler: NewObjectHandler(objectSvc, nil, zerolog.Nop()),
+			StrictQuery:   strict,
+			Logger:        zerolog.Nop(),
+		})
+	}
+	get := func(rt *Router, target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		rt.handleS3Request(rec, withTestUser(httptest.NewRequest(http.MethodGet, target, nil)))
+		return rec
+	}
+
+	// Lenient mode ignores the misspelled sub-resource and lists the bucket
+	rec := get(newRouter(false), "/photos?versionng")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), "ListBucketResult")
+
+	strict := newRouter(true)
+	rec = get(strict, "/photos?versionng")
+	requireErrorCode(t, rec, http.StatusBadRequest, "InvalidArgument")
+	require.Contains(t, rec.Body.String(), "versionng")
+
+	// Recognized parameters, signing parameters and unimplemented sub-resources pass
+	require.Equal(t, http.StatusOK, get(strict, "/photos?versioning").Code)
+	require.Equal(t, http.StatusOK, get(strict, "/photos?list-type=2&prefix=2024/&max-keys=10&X-Amz-Date=20240101T000000Z&x-id=ListObjectsV2").Code)
+	requireErrorCode(t, get(strict, "/photos?inventory&id=daily"), http.StatusNotImplemented, "NotImplemented")
+}
+
+func TestOperationsRegistry(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, op := range s3Operations {
+		key := string(op.Scope) + "?" + op.SubResource
+		require.False(t, seen[key], "duplicate registry entry %s", key)
+		seen[key] = true
+		require.NotEmpty(t, op.Operations, key)
+	}
+}
+
+func TestRouter_Metrics(t *testing.T) {
+	objectHandler, _, _ := newPutObjectTestHandler(t)
+	m := metrics.NewWithRegisterer(prometheus.NewRegistry())
+
+	withUser := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, withTestUser(r))
+		})
+	}
+	serve := func(authMiddleware func(http.Handler) http.Handler, req *http.Request) *httptest.ResponseRecorder {
+		rt := NewRouter(RouterConfig{
+			ObjectHandler:  objectHandler,
+			AuthMiddleware: authMiddleware,
+			Metrics:        m,
+			MetricsPath:    "/metrics",
+			Logger:         zerolog.Nop(),
+		})
+		rec := httptest.NewRecorder()
+		rt.Handler().ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve(withUser, httptest.NewRequest(http.MethodPut, "/uploads/cat.txt", strings.NewReader("meow")))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	rec = serve(withUser, httptest.NewRequest(http.MethodGet, "/uploads/cat.txt", nil))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.Equal(t, 4.0, testutil.ToFloat64(m.S3RequestBytes.WithLabelValues("PutObject")))
+	require.Equal(t, 4.0, testutil.ToFloat64(m.S3ResponseBytes.WithLabelValues("GetObject")))
+	requireErrorCode(t, serve(withUser, httptest.NewRequest(http.MethodGet, "/uploads/dog.txt", nil)), http.StatusNotFound, "NoSuchKey")
+
+	// Requests rejected before routing count against their operation too
+	authMiddleware := auth.Middleware(nil, auth.DefaultConfig())
+	requireErrorCode(t, serve(authMiddleware, httptest.NewRequest(http.MethodPut, "/uploads/dog.txt", strings.NewReader("woof"))), http.StatusForbidden, "AccessDenied")
+
+	require.Equal(t, 1.0, testutil.ToFloat64(m.S3RequestsTotal.WithLabelValues("PutObject", "OK")))
+	require.Equal(t, 1.0, testutil.ToFloat64(m.S3RequestsTotal.WithLabelValues("GetObject", "OK")))
+	require.Equal(t, 1.0, testutil.ToFloat64(m.S3RequestsTotal.WithLabelValues("GetObject", "NoSuchKey")))
+	require.Equal(t, 1.0, testutil.ToFloat64(m.S3RequestsTotal.WithLabelValues("PutObject", "AccessDenied")))
+
+	// The endpoint itself needs no credentials and is not an S3 operation
+	rec = serve(authMiddleware, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.Contains(t, rec.Body.String(), `alexander_s3_requests_total{code="NoSuchKey",operation="GetObject"} 1`)
+	require.Equal(t, 4, testutil.CollectAndCount(m.S3RequestsTotal))
+}
+
+func TestRouter_OperationName(t *testing.T) {
+	rt := NewRouter(RouterConfig{BaseDomain: "s3.example.com", Logger: zerolog.Nop()})
+
+	tests := []struct {
+		method     string
+		target     string
+		host       string
+		copySource bool
+		want       string
+	}{
+		{method: http.MethodGet, target: "/", want: "ListBuckets"},
+		{method: http.MethodPost, target: "/", want: "Unknown"},
+		{method: http.MethodPut, target: "/photos", want: "CreateBucket"},
+		{method: http.MethodGet, target: "/photos?list-type=2&prefix=a", want: "ListObjectsV2"},
+		{method: http.MethodGet, target: "/photos?versioning", want: "GetBucketVersioning"},
+		{method: http.MethodDelete, target: "/photos?lifecycle", want: "DeleteBucketLifecycle"},
+		{method: http.MethodPost, target: "/photos?delete", want: "DeleteObjects"},
+		{method: http.MethodGet, target: "/photos?uploads", want: "ListMultipartUploads"},
+		{method: http.MethodGet, target: "/photos?inventory&id=daily", want: "GetBucketInventoryConfiguration"},
+		{method: http.MethodPut, target: "/photos/cat.jpg", want: "PutObject"},
+		{method: http.MethodPut, target: "/photos/cat.jpg", copySource: true, want: "CopyObject"},
+		{method: http.MethodHead, target: "/photos/2024/cat.jpg", want: "HeadObject"},
+		{method: http.MethodPost, target: "/photos/cat.jpg?uploads", want: "CreateMultipartUpload"},
+		{method: http.MethodPut, target: "/photos/cat.jpg?partNumber=1&uploadId=u", want: "UploadPart"},
+		{method: http.MethodPut, target: "/photos/cat.jpg?partNumber=1&uploadId=u", copySource: true, want: "UploadPartCopy"},
+		{method: http.MethodPost, target: "/photos/cat.jpg?uploadId=u", want: "CompleteMultipartUpload"},
+		{method: http.MethodPut, target: "/photos/cat.jpg?tagging", want: "PutObjectTagging"},
+		{method: http.MethodPatch, target: "/photos/cat.jpg", want: "Unknown"},
+		{method: http.MethodGet, target: "/", host: "photos.s3.example.com", want: "ListObjects"},
+		{method: http.MethodGet, target: "/2024/cat.jpg", host: "photos.s3.example.com", want: "GetObject"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.host+tt.target, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			if tt.host != "" {
+				req.Host = tt.host
+			}
+			if tt.copySource {
+				req.Header.Set("x-amz-copy-source", "/photos/dog.jpg")
+			}
+			require.Equal(t, tt.want, rt.operationName(req))
+		})
+	}
+}
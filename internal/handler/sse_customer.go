@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/prn-tf/alexander-storage/internal/pkg/crypto"
+)
+
+// SSE-C header prefixes. The customer key of the request's object uses the
+// first; CopyObject passes the source object's key with the second.
+const (
+	sseCustomerHeaderPrefix           = "x-amz-server-side-encryption-customer-"
+	copySourceSSECustomerHeaderPrefix = "x-amz-copy-source-server-side-encryption-customer-"
+)
+
+// serverSideEncryptionHeader requests server-side encryption with
+// server-managed keys (SSE-S3).
+const serverSideEncryptionHeader = "x-amz-server-side-encryption"
+
+// checkServerSideEncryption rejects requests for SSE-S3. Blobs are not
+// encrypted at rest with server-managed keys, so only customer-provided keys
+// (SSE-C) encrypt objects. ok is false when the header is present.
+func checkServerSideEncryption(r *http.Request) (s3Err S3Error, ok bool) {
+	switch r.Header.Get(serverSideEncryptionHeader) {
+	case "":
+		return S3Error{}, true
+	case crypto.SSECAlgorithmAES256:
+		return ErrServerSideEncryptionNotImplemented, false
+	default:
+		return ErrInvalidEncryptionAlgorithm, false
+	}
+}
+
+// parseSSECustomerKey reads the algorithm, key and key-MD5 headers with the
+// given prefix. It returns nil when none are present. The key is only held
+// in memory for the request and must never be logged.
+func parseSSECustomerKey(r *http.Request, prefix string) (*crypto.SSECustomerKey, error) {
+	algorithm := r.Header.Get(prefix + "algorithm")
+	key := r.Header.Get(prefix + "key")
+	keyMD5 := r.Header.Get(prefix + "key-MD5")
+	if algorithm == "" && key == "" && keyMD5 == "" {
+		return nil, nil
+	}
+
+	return crypto.ParseSSECustomerKey(algorithm, key, keyMD5)
+}
+
+// hasSSECustomerHeaders reports whether the request carries any SSE-C header.
+func hasSSECustomerHeaders(r *http.Request) bool {
+	for _, prefix := range []string{sseCustomerHeaderPrefix, copySourceSSECustomerHeaderPrefix} {
+		if r.Header.Get(prefix+"algorithm") != "" || r.Header.Get(prefix+"key") != "" || r.Header.Get(prefix+"key-MD5") != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// setSSECustomerHeaders echoes the SSE-C algorithm and key MD5 of an object
+// stored with a customer-provided key.
+func setSSECustomerHeaders(w http.ResponseWriter, keyMD5 string) {
+	if keyMD5 == "" {
+		return
+	}
+	w.Header().Set(sseCustomerHeaderPrefix+"algorithm", crypto.SSECAlgorithmAES256)
+	w.Header().Set(sseCustomerHeaderPrefix+"key-MD5", keyMD5)
+}
@@ -0,0 +1,107 @@
+package metrics
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net/http"
+)
+
+// BucketLabelMode selects how requests are attributed to buckets in the
+// per-bucket metrics. Labeling every bucket by name would create a series per
+// bucket, which does not scale to many buckets.
+type BucketLabelMode string
+
+const (
+	// BucketLabelsNone emits no per-bucket series; requests are only counted
+	// by the global HTTP metrics.
+	BucketLabelsNone BucketLabelMode = "none"
+
+	// BucketLabelsAllowList labels the allow-listed buckets by name and
+	// aggregates all other buckets under OtherBucketsLabel.
+	BucketLabelsAllowList BucketLabelMode = "allowlist"
+
+	// BucketLabelsHashed hashes bucket names into a fixed number of labels.
+	BucketLabelsHashed BucketLabelMode = "hashed"
+)
+
+// OtherBucketsLabel is the label of buckets missing from the allow-list.
+const OtherBucketsLabel = "_other"
+
+// DefaultHashBuckets is the number of labels used by BucketLabelsHashed when
+// none is configured.
+const DefaultHashBuckets = 16
+
+// BucketLabelConfig configures the per-bucket metrics.
+type BucketLabelConfig struct {
+	// Mode selects the labeling; empty means BucketLabelsNone.
+	Mode BucketLabelMode
+
+	// Buckets is the allow-list for BucketLabelsAllowList.
+	Buckets []string
+
+	// HashBuckets is the number of labels for BucketLabelsHashed.
+	HashBuckets int
+}
+
+// bucketLabeler maps bucket names to metric labels.
+type bucketLabeler struct {
+	mode        BucketLabelMode
+	allowed     map[string]bool
+	hashBuckets uint32
+}
+
+// newBucketLabeler creates a labeler for cfg.
+func newBucketLabeler(cfg BucketLabelConfig) bucketLabeler {
+	l := bucketLabeler{mode: cfg.Mode}
+	switch cfg.Mode {
+	case BucketLabelsAllowList:
+		l.allowed = make(map[string]bool, len(cfg.Buckets))
+		for _, name := range cfg.Buckets {
+			l.allowed[name] = true
+		}
+	case BucketLabelsHashed:
+		l.hashBuckets = DefaultHashBuckets
+		if cfg.HashBuckets > 0 {
+			l.hashBuckets = uint32(cfg.HashBuckets)
+		}
+	default:
+		l.mode = BucketLabelsNone
+	}
+	return l
+}
+
+// label returns the label of bucket, or false when no per-bucket series
+// should be recorded.
+func (l bucketLabeler) label(bucket string) (string, bool) {
+	switch l.mode {
+	case BucketLabelsAllowList:
+		if l.allowed[bucket] {
+			return bucket, true
+		}
+		return OtherBucketsLabel, true
+	case BucketLabelsHashed:
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(bucket))
+		return fmt.Sprintf("hash-%02d", h.Sum32()%l.hashBuckets), true
+	default:
+		return "", false
+	}
+}
+
+// SetBucketLabels configures the per-bucket metrics. It must be called before
+// requests are recorded; the default emits no per-bucket series.
+func (m *Metrics) SetBucketLabels(cfg BucketLabelConfig) {
+	m.bucketLabels = newBucketLabeler(cfg)
+}
+
+// RecordBucketRequest records a request addressed to bucket.
+func (m *Metrics) RecordBucketRequest(bucket, method string, statusCode int, size int64) {
+	label, ok := m.bucketLabels.label(bucket)
+	if !ok {
+		return
+	}
+	m.BucketRequestsTotal.WithLabelValues(label, method, http.StatusText(statusCode)).Inc()
+	if size > 0 {
+		m.BucketResponseBytes.WithLabelValues(label).Add(float64(size))
+	}
+}
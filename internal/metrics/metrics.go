@@ -3,6 +3,7 @@ package metrics
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
@@ -17,12 +18,20 @@ type Metrics struct {
 	HTTPRequestsInFlight prometheus.Gauge
 	HTTPResponseSize     *prometheus.HistogramVec
 
+	// S3 API Metrics, labeled by the operation the router matched
+	S3RequestsTotal   *prometheus.CounterVec
+	S3RequestDuration *prometheus.HistogramVec
+	S3RequestBytes    *prometheus.CounterVec
+	S3ResponseBytes   *prometheus.CounterVec
+
 	// Storage Metrics
 	StorageOperationsTotal   *prometheus.CounterVec
 	StorageOperationDuration *prometheus.HistogramVec
 	StorageBytesTotal        *prometheus.CounterVec
 	BlobsTotal               prometheus.Gauge
 	BlobsSize                prometheus.Gauge
+	BlobsMissingTotal        prometheus.Counter
+	StorageFullTotal         prometheus.Counter
 
 	// Object Metrics
 	ObjectsTotal   *prometheus.GaugeVec
@@ -36,6 +45,8 @@ type Metrics struct {
 	DBQueryDuration       *prometheus.HistogramVec
 	DBTransactionsTotal   *prometheus.CounterVec
 	DBTransactionDuration *prometheus.HistogramVec
+	DBPoolWaitCount       prometheus.Gauge
+	DBPoolWaitDuration    prometheus.Gauge
 
 	// Cache Metrics
 	CacheHitsTotal   *prometheus.CounterVec
@@ -56,16 +67,44 @@ type Metrics struct {
 
 	// Rate Limiting Metrics
 	RateLimitedRequests *prometheus.CounterVec
+
+	// Per-Bucket Request Metrics, labeled according to bucketLabels
+	BucketRequestsTotal *prometheus.CounterVec
+	BucketResponseBytes *prometheus.CounterVec
+	bucketLabels        bucketLabeler
+
+	// Delta/CDC Metrics
+	DeltaChunkedBytes           prometheus.Counter
+	DeltaChunkingDuration       prometheus.Histogram
+	DeltaChunkSize              prometheus.Histogram
+	DeltaChunksTotal            *prometheus.CounterVec
+	DeltaSavingsRatio           prometheus.Gauge
+	DeltaBytesSaved             prometheus.Counter
+	DeltaReconstructionFailures prometheus.Counter
+	DeltaReconstructionHealthy  prometheus.Gauge
+
+	// Tiering Metrics
+	TieringMigrationsTotal *prometheus.CounterVec
+	TieringBytesMigrated   prometheus.Counter
+
+	// gatherer serves the registry the metrics were registered with
+	gatherer prometheus.Gatherer
 }
 
 // namespace for all Alexander metrics
 const namespace = "alexander"
 
-// New creates and registers all Prometheus metrics.
+// New creates all Prometheus metrics and registers them with the default registry.
 func New() *Metrics {
+	return NewWithRegisterer(prometheus.DefaultRegisterer)
+}
+
+// NewWithRegisterer creates all Prometheus metrics and registers them with reg.
+func NewWithRegisterer(reg prometheus.Registerer) *Metrics {
+	factory := promauto.With(reg)
 	m := &Metrics{
 		// HTTP Metrics
-		HTTPRequestsTotal: promauto.NewCounterVec(
+		HTTPRequestsTotal: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: namespace,
 				Subsystem: "http",
@@ -74,7 +113,7 @@ func New() *Metrics {
 			},
 			[]string{"method", "path", "status"},
 		),
-		HTTPRequestDuration: promauto.NewHistogramVec(
+		HTTPRequestDuration: factory.NewHistogramVec(
 			prometheus.HistogramOpts{
 				Namespace: namespace,
 				Subsystem: "http",
@@ -84,7 +123,7 @@ func New() *Metrics {
 			},
 			[]string{"method", "path"},
 		),
-		HTTPRequestsInFlight: promauto.NewGauge(
+		HTTPRequestsInFlight: factory.NewGauge(
 			prometheus.GaugeOpts{
 				Namespace: namespace,
 				Subsystem: "http",
@@ -92,7 +131,7 @@ func New() *Metrics {
 				Help:      "Current number of HTTP requests being processed.",
 			},
 		),
-		HTTPResponseSize: promauto.NewHistogramVec(
+		HTTPResponseSize: factory.NewHistogramVec(
 			prometheus.HistogramOpts{
 				Namespace: namespace,
 				Subsystem: "http",
@@ -103,8 +142,47 @@ func New() *Metrics {
 			[]string{"method", "path"},
 		),
 
+		// S3 API Metrics
+		S3RequestsTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: "s3",
+				Name:      "requests_total",
+				Help:      "Total number of S3 API requests by operation and S3 error code (OK for success).",
+			},
+			[]string{"operation", "code"},
+		),
+		S3RequestDuration: factory.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Subsystem: "s3",
+				Name:      "request_duration_seconds",
+				Help:      "S3 API request duration in seconds.",
+				Buckets:   []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+			},
+			[]string{"operation"},
+		),
+		S3RequestBytes: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: "s3",
+				Name:      "received_bytes_total",
+				Help:      "Total request body bytes received by S3 API operations.",
+			},
+			[]string{"operation"},
+		),
+		S3ResponseBytes: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: "s3",
+				Name:      "sent_bytes_total",
+				Help:      "Total response body bytes sent by S3 API operations.",
+			},
+			[]string{"operation"},
+		),
+
 		// Storage Metrics
-		StorageOperationsTotal: promauto.NewCounterVec(
+		StorageOperationsTotal: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: namespace,
 				Subsystem: "storage",
@@ -113,7 +191,7 @@ func New() *Metrics {
 			},
 			[]string{"operation", "status"},
 		),
-		StorageOperationDuration: promauto.NewHistogramVec(
+		StorageOperationDuration: factory.NewHistogramVec(
 			prometheus.HistogramOpts{
 				Namespace: namespace,
 				Subsystem: "storage",
@@ -123,7 +201,7 @@ func New() *Metrics {
 			},
 			[]string{"operation"},
 		),
-		StorageBytesTotal: promauto.NewCounterVec(
+		StorageBytesTotal: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: namespace,
 				Subsystem: "storage",
@@ -132,7 +210,7 @@ func New() *Metrics {
 			},
 			[]string{"operation"},
 		),
-		BlobsTotal: promauto.NewGauge(
+		BlobsTotal: factory.NewGauge(
 			prometheus.GaugeOpts{
 				Namespace: namespace,
 				Subsystem: "storage",
@@ -140,7 +218,7 @@ func New() *Metrics {
 				Help:      "Total number of unique blobs in storage.",
 			},
 		),
-		BlobsSize: promauto.NewGauge(
+		BlobsSize: factory.NewGauge(
 			prometheus.GaugeOpts{
 				Namespace: namespace,
 				Subsystem: "storage",
@@ -148,9 +226,26 @@ func New() *Metrics {
 				Help:      "Total size of all blobs in bytes.",
 			},
 		),
+		BlobsMissingTotal: factory.NewCounter(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: "storage",
+				Name:      "blobs_missing_total",
+				Help:      "Total reads of objects whose blob is missing from storage.",
+			},
+		),
+
+		StorageFullTotal: factory.NewCounter(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: "storage",
+				Name:      "full_errors_total",
+				Help:      "Total writes rejected because the storage had no space left.",
+			},
+		),
 
 		// Object Metrics
-		ObjectsTotal: promauto.NewGaugeVec(
+		ObjectsTotal: factory.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace: namespace,
 				Subsystem: "objects",
@@ -159,7 +254,7 @@ func New() *Metrics {
 			},
 			[]string{"bucket"},
 		),
-		ObjectsSize: promauto.NewGaugeVec(
+		ObjectsSize: factory.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace: namespace,
 				Subsystem: "objects",
@@ -168,7 +263,7 @@ func New() *Metrics {
 			},
 			[]string{"bucket"},
 		),
-		BucketsTotal: promauto.NewGauge(
+		BucketsTotal: factory.NewGauge(
 			prometheus.GaugeOpts{
 				Namespace: namespace,
 				Subsystem: "objects",
@@ -176,7 +271,7 @@ func New() *Metrics {
 				Help:      "Total number of buckets.",
 			},
 		),
-		VersionsTotal: promauto.NewGauge(
+		VersionsTotal: factory.NewGauge(
 			prometheus.GaugeOpts{
 				Namespace: namespace,
 				Subsystem: "objects",
@@ -184,7 +279,7 @@ func New() *Metrics {
 				Help:      "Total number of object versions.",
 			},
 		),
-		MultipartTotal: promauto.NewGauge(
+		MultipartTotal: factory.NewGauge(
 			prometheus.GaugeOpts{
 				Namespace: namespace,
 				Subsystem: "objects",
@@ -194,7 +289,7 @@ func New() *Metrics {
 		),
 
 		// Database Metrics
-		DBConnectionsTotal: promauto.NewGaugeVec(
+		DBConnectionsTotal: factory.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace: namespace,
 				Subsystem: "db",
@@ -203,7 +298,7 @@ func New() *Metrics {
 			},
 			[]string{"state"},
 		),
-		DBQueryDuration: promauto.NewHistogramVec(
+		DBQueryDuration: factory.NewHistogramVec(
 			prometheus.HistogramOpts{
 				Namespace: namespace,
 				Subsystem: "db",
@@ -213,7 +308,7 @@ func New() *Metrics {
 			},
 			[]string{"query"},
 		),
-		DBTransactionsTotal: promauto.NewCounterVec(
+		DBTransactionsTotal: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: namespace,
 				Subsystem: "db",
@@ -222,7 +317,7 @@ func New() *Metrics {
 			},
 			[]string{"status"},
 		),
-		DBTransactionDuration: promauto.NewHistogramVec(
+		DBTransactionDuration: factory.NewHistogramVec(
 			prometheus.HistogramOpts{
 				Namespace: namespace,
 				Subsystem: "db",
@@ -232,9 +327,25 @@ func New() *Metrics {
 			},
 			[]string{"status"},
 		),
+		DBPoolWaitCount: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "db",
+				Name:      "pool_wait_count",
+				Help:      "Cumulative number of connection acquires that waited for a free connection.",
+			},
+		),
+		DBPoolWaitDuration: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "db",
+				Name:      "pool_wait_duration_seconds",
+				Help:      "Cumulative time spent waiting for a free connection in seconds.",
+			},
+		),
 
 		// Cache Metrics
-		CacheHitsTotal: promauto.NewCounterVec(
+		CacheHitsTotal: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: namespace,
 				Subsystem: "cache",
@@ -243,7 +354,7 @@ func New() *Metrics {
 			},
 			[]string{"cache"},
 		),
-		CacheMissesTotal: promauto.NewCounterVec(
+		CacheMissesTotal: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: namespace,
 				Subsystem: "cache",
@@ -252,7 +363,7 @@ func New() *Metrics {
 			},
 			[]string{"cache"},
 		),
-		CacheEvictions: promauto.NewCounter(
+		CacheEvictions: factory.NewCounter(
 			prometheus.CounterOpts{
 				Namespace: namespace,
 				Subsystem: "cache",
@@ -262,7 +373,7 @@ func New() *Metrics {
 		),
 
 		// Auth Metrics
-		AuthAttemptsTotal: promauto.NewCounterVec(
+		AuthAttemptsTotal: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: namespace,
 				Subsystem: "auth",
@@ -271,7 +382,7 @@ func New() *Metrics {
 			},
 			[]string{"method"},
 		),
-		AuthFailuresTotal: promauto.NewCounterVec(
+		AuthFailuresTotal: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: namespace,
 				Subsystem: "auth",
@@ -282,7 +393,7 @@ func New() *Metrics {
 		),
 
 		// Garbage Collection Metrics
-		GCRunsTotal: promauto.NewCounter(
+		GCRunsTotal: factory.NewCounter(
 			prometheus.CounterOpts{
 				Namespace: namespace,
 				Subsystem: "gc",
@@ -290,7 +401,7 @@ func New() *Metrics {
 				Help:      "Total number of garbage collection runs.",
 			},
 		),
-		GCBlobsDeleted: promauto.NewCounter(
+		GCBlobsDeleted: factory.NewCounter(
 			prometheus.CounterOpts{
 				Namespace: namespace,
 				Subsystem: "gc",
@@ -298,7 +409,7 @@ func New() *Metrics {
 				Help:      "Total number of blobs deleted by garbage collection.",
 			},
 		),
-		GCBytesFreed: promauto.NewCounter(
+		GCBytesFreed: factory.NewCounter(
 			prometheus.CounterOpts{
 				Namespace: namespace,
 				Subsystem: "gc",
@@ -306,7 +417,7 @@ func New() *Metrics {
 				Help:      "Total bytes freed by garbage collection.",
 			},
 		),
-		GCDuration: promauto.NewHistogram(
+		GCDuration: factory.NewHistogram(
 			prometheus.HistogramOpts{
 				Namespace: namespace,
 				Subsystem: "gc",
@@ -315,7 +426,7 @@ func New() *Metrics {
 				Buckets:   []float64{.1, .5, 1, 5, 10, 30, 60, 120},
 			},
 		),
-		GCOrphanBlobs: promauto.NewGauge(
+		GCOrphanBlobs: factory.NewGauge(
 			prometheus.GaugeOpts{
 				Namespace: namespace,
 				Subsystem: "gc",
@@ -323,7 +434,7 @@ func New() *Metrics {
 				Help:      "Current number of orphan blobs pending garbage collection.",
 			},
 		),
-		GCLastRunTime: promauto.NewGauge(
+		GCLastRunTime: factory.NewGauge(
 			prometheus.GaugeOpts{
 				Namespace: namespace,
 				Subsystem: "gc",
@@ -333,7 +444,7 @@ func New() *Metrics {
 		),
 
 		// Rate Limiting Metrics
-		RateLimitedRequests: promauto.NewCounterVec(
+		RateLimitedRequests: factory.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: namespace,
 				Subsystem: "ratelimit",
@@ -342,8 +453,128 @@ func New() *Metrics {
 			},
 			[]string{"limit_type"},
 		),
+
+		// Per-Bucket Request Metrics
+		BucketRequestsTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: "bucket",
+				Name:      "requests_total",
+				Help:      "Total number of S3 requests per bucket label.",
+			},
+			[]string{"bucket", "method", "status"},
+		),
+		BucketResponseBytes: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: "bucket",
+				Name:      "response_bytes_total",
+				Help:      "Total response bytes per bucket label.",
+			},
+			[]string{"bucket"},
+		),
+
+		// Delta/CDC Metrics
+		DeltaChunkedBytes: factory.NewCounter(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: "delta",
+				Name:      "chunked_bytes_total",
+				Help:      "Total bytes split into content-defined chunks.",
+			},
+		),
+		DeltaChunkingDuration: factory.NewHistogram(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Subsystem: "delta",
+				Name:      "chunking_duration_seconds",
+				Help:      "Time spent chunking a blob in seconds.",
+				Buckets:   []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+			},
+		),
+		DeltaChunkSize: factory.NewHistogram(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Subsystem: "delta",
+				Name:      "chunk_size_bytes",
+				Help:      "Size of content-defined chunks in bytes.",
+				Buckets:   prometheus.ExponentialBuckets(1024, 2, 11), // 1KB to 1MB
+			},
+		),
+		DeltaChunksTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: "delta",
+				Name:      "chunks_total",
+				Help:      "Total number of indexed chunks, by whether they were new or deduplicated.",
+			},
+			[]string{"result"},
+		),
+		DeltaSavingsRatio: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "delta",
+				Name:      "savings_ratio",
+				Help:      "Fraction of bytes saved by the most recent delta (1 - delta_size/total_size).",
+			},
+		),
+		DeltaBytesSaved: factory.NewCounter(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: "delta",
+				Name:      "bytes_saved_total",
+				Help:      "Total bytes copied from base blobs instead of being stored again.",
+			},
+		),
+		DeltaReconstructionFailures: factory.NewCounter(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: "delta",
+				Name:      "reconstruction_failures_total",
+				Help:      "Total number of blobs that could not be reconstructed from a delta.",
+			},
+		),
+		DeltaReconstructionHealthy: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: "delta",
+				Name:      "reconstruction_healthy",
+				Help:      "1 if the most recent delta reconstruction succeeded, 0 if it failed.",
+			},
+		),
+
+		// Tiering Metrics
+		TieringMigrationsTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: "tiering",
+				Name:      "migrations_total",
+				Help:      "Total number of finished blob migrations by source tier, target tier and outcome.",
+			},
+			[]string{"source_tier", "target_tier", "status"},
+		),
+		TieringBytesMigrated: factory.NewCounter(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: "tiering",
+				Name:      "migrated_bytes_total",
+				Help:      "Total bytes transferred by completed blob migrations.",
+			},
+		),
 	}
 
+	// Serve what was registered with reg, where it can be gathered
+	m.gatherer = prometheus.DefaultGatherer
+	if gatherer, ok := reg.(prometheus.Gatherer); ok {
+		m.gatherer = gatherer
+	}
+
+	// No reconstruction has failed yet
+	m.DeltaReconstructionHealthy.Set(1)
+
+	// Per-bucket series are opt-in
+	m.bucketLabels = newBucketLabeler(BucketLabelConfig{})
+
 	return m
 }
 
@@ -352,6 +583,14 @@ func Handler() http.Handler {
 	return promhttp.Handler()
 }
 
+// Handler returns an HTTP handler serving the registry m was created with.
+func (m *Metrics) Handler() http.Handler {
+	if m.gatherer == prometheus.DefaultGatherer {
+		return Handler()
+	}
+	return promhttp.HandlerFor(m.gatherer, promhttp.HandlerOpts{})
+}
+
 // RecordHTTPRequest records HTTP request metrics.
 func (m *Metrics) RecordHTTPRequest(method, path, status string, duration float64, size int64) {
 	m.HTTPRequestsTotal.WithLabelValues(method, path, status).Inc()
@@ -359,6 +598,19 @@ func (m *Metrics) RecordHTTPRequest(method, path, status string, duration float6
 	m.HTTPResponseSize.WithLabelValues(method, path).Observe(float64(size))
 }
 
+// RecordS3Request records an S3 API request. code is the S3 error code of
+// the response, or "OK" for a successful one.
+func (m *Metrics) RecordS3Request(operation, code string, duration float64, received, sent int64) {
+	m.S3RequestsTotal.WithLabelValues(operation, code).Inc()
+	m.S3RequestDuration.WithLabelValues(operation).Observe(duration)
+	if received > 0 {
+		m.S3RequestBytes.WithLabelValues(operation).Add(float64(received))
+	}
+	if sent > 0 {
+		m.S3ResponseBytes.WithLabelValues(operation).Add(float64(sent))
+	}
+}
+
 // RecordStorageOperation records storage operation metrics.
 func (m *Metrics) RecordStorageOperation(operation, status string, duration float64, bytes int64) {
 	m.StorageOperationsTotal.WithLabelValues(operation, status).Inc()
@@ -368,6 +620,16 @@ func (m *Metrics) RecordStorageOperation(operation, status string, duration floa
 	}
 }
 
+// RecordMissingBlob records a read of an object whose blob is missing.
+func (m *Metrics) RecordMissingBlob() {
+	m.BlobsMissingTotal.Inc()
+}
+
+// RecordStorageFull records a write rejected because storage is full.
+func (m *Metrics) RecordStorageFull() {
+	m.StorageFullTotal.Inc()
+}
+
 // RecordAuthAttempt records an authentication attempt.
 func (m *Metrics) RecordAuthAttempt(method string, success bool, reason string) {
 	m.AuthAttemptsTotal.WithLabelValues(method).Inc()
@@ -393,7 +655,64 @@ func (m *Metrics) RecordGCRun(duration float64, blobsDeleted int, bytesFreed int
 	m.GCBytesFreed.Add(float64(bytesFreed))
 }
 
+// RecordDBPoolStats records a snapshot of database connection pool statistics.
+func (m *Metrics) RecordDBPoolStats(inUse, idle, total, max int32, waitCount int64, waitDuration time.Duration) {
+	m.DBConnectionsTotal.WithLabelValues("in_use").Set(float64(inUse))
+	m.DBConnectionsTotal.WithLabelValues("idle").Set(float64(idle))
+	m.DBConnectionsTotal.WithLabelValues("total").Set(float64(total))
+	m.DBConnectionsTotal.WithLabelValues("max").Set(float64(max))
+	m.DBPoolWaitCount.Set(float64(waitCount))
+	m.DBPoolWaitDuration.Set(waitDuration.Seconds())
+}
+
+// RecordDeltaChunking records a blob split into chunks of the given sizes.
+func (m *Metrics) RecordDeltaChunking(chunkSizes []int64, duration time.Duration) {
+	var total int64
+	for _, size := range chunkSizes {
+		m.DeltaChunkSize.Observe(float64(size))
+		total += size
+	}
+	m.DeltaChunkedBytes.Add(float64(total))
+	m.DeltaChunkingDuration.Observe(duration.Seconds())
+}
+
+// RecordDeltaDedup records chunks indexed as new or reused.
+func (m *Metrics) RecordDeltaDedup(newChunks, reusedChunks int) {
+	m.DeltaChunksTotal.WithLabelValues("new").Add(float64(newChunks))
+	m.DeltaChunksTotal.WithLabelValues("reused").Add(float64(reusedChunks))
+}
+
+// RecordDelta records a computed delta.
+func (m *Metrics) RecordDelta(savingsRatio float64, totalSize, deltaSize int64) {
+	m.DeltaSavingsRatio.Set(savingsRatio)
+	m.DeltaBytesSaved.Add(float64(totalSize - deltaSize))
+}
+
+// RecordDeltaReconstruction records the outcome of reconstructing a blob from a delta.
+func (m *Metrics) RecordDeltaReconstruction(success bool) {
+	if success {
+		m.DeltaReconstructionHealthy.Set(1)
+		return
+	}
+	m.DeltaReconstructionFailures.Inc()
+	m.DeltaReconstructionHealthy.Set(0)
+}
+
 // RecordRateLimited records a rate limited request.
 func (m *Metrics) RecordRateLimited(limitType string) {
 	m.RateLimitedRequests.WithLabelValues(limitType).Inc()
 }
+
+// RecordTieringMigration records a finished blob migration. bytes is the
+// amount transferred, counted for completed migrations only.
+func (m *Metrics) RecordTieringMigration(sourceTier, targetTier, status string, bytes int64) {
+	m.TieringMigrationsTotal.WithLabelValues(sourceTier, targetTier, status).Inc()
+	if status == "completed" && bytes > 0 {
+		m.TieringBytesMigrated.Add(float64(bytes))
+	}
+}
+
+// SetMultipartUploads records the number of in-progress multipart uploads.
+func (m *Metrics) SetMultipartUploads(n int64) {
+	m.MultipartTotal.Set(float64(n))
+}
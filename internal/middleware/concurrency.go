@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"github.com/rs/zerolog"
+
+	"github.com/prn-tf/alexander-storage/internal/metrics"
+)
+
+// ConcurrencyLimiter bounds the number of operations of one kind that may run
+// at the same time. Unlike RateLimiter it is global rather than per client, and
+// rejects work immediately instead of queueing it.
+type ConcurrencyLimiter struct {
+	name    string
+	slots   chan struct{}
+	metrics *metrics.Metrics
+	logger  zerolog.Logger
+}
+
+// NewConcurrencyLimiter creates a limiter allowing at most limit concurrent
+// operations. name identifies the operation kind in logs and metrics.
+// A limit of zero or less returns nil, which never limits.
+func NewConcurrencyLimiter(name string, limit int, m *metrics.Metrics, logger zerolog.Logger) *ConcurrencyLimiter {
+	if limit <= 0 {
+		return nil
+	}
+	return &ConcurrencyLimiter{
+		name:    name,
+		slots:   make(chan struct{}, limit),
+		metrics: m,
+		logger:  logger.With().Str("component", "concurrency_limiter").Str("operation", name).Logger(),
+	}
+}
+
+// TryAcquire reserves a slot without blocking. When ok is true the caller
+// must call release once the operation finishes.
+func (cl *ConcurrencyLimiter) TryAcquire() (release func(), ok bool) {
+	if cl == nil {
+		return func() {}, true
+	}
+
+	select {
+	case cl.slots <- struct{}{}:
+		return func() { <-cl.slots }, true
+	default:
+		cl.logger.Warn().
+			Int("limit", cap(cl.slots)).
+			Msg("Concurrency limit exceeded")
+		if cl.metrics != nil {
+			cl.metrics.RecordRateLimited(cl.name)
+		}
+		return nil, false
+	}
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"sync"
+
+	"github.com/rs/zerolog"
+
+	"github.com/prn-tf/alexander-storage/internal/metrics"
+)
+
+// MemoryBudget accounts for memory held by in-flight operations that buffer
+// data instead of streaming it, such as XML request bodies. Operations reserve
+// their worst-case size up front and are rejected immediately when the budget
+// cannot cover it, so many concurrent buffering requests cannot exhaust the
+// process memory together.
+type MemoryBudget struct {
+	limit   int64
+	metrics *metrics.Metrics
+	logger  zerolog.Logger
+
+	mu       sync.Mutex
+	reserved int64
+}
+
+// NewMemoryBudget creates a budget of limit bytes shared by all buffering
+// operations. A limit of zero or less returns nil, which never limits.
+func NewMemoryBudget(limit int64, m *metrics.Metrics, logger zerolog.Logger) *MemoryBudget {
+	if limit <= 0 {
+		return nil
+	}
+	return &MemoryBudget{
+		limit:   limit,
+		metrics: m,
+		logger:  logger.With().Str("component", "memory_budget").Logger(),
+	}
+}
+
+// TryReserve reserves n bytes without blocking. When ok is true the caller
+// must call release once the buffered data is no longer referenced; calling
+// it more than once has no further effect.
+func (b *MemoryBudget) TryReserve(n int64) (release func(), ok bool) {
+	if b == nil {
+		return func() {}, true
+	}
+
+	b.mu.Lock()
+	if b.reserved+n > b.limit {
+		reserved := b.reserved
+		b.mu.Unlock()
+
+		b.logger.Warn().
+			Int64("requested", n).
+			Int64("reserved", reserved).
+			Int64("limit", b.limit).
+			Msg("Memory budget exhausted")
+		if b.metrics != nil {
+			b.metrics.RecordRateLimited("memory")
+		}
+		return nil, false
+	}
+	b.reserved += n
+	b.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.mu.Lock()
+			b.reserved -= n
+			b.mu.Unlock()
+		})
+	}, true
+}
+
+// Reserved returns the number of bytes currently reserved.
+func (b *MemoryBudget) Reserved() int64 {
+	if b == nil {
+		return 0
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.reserved
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryBudget(t *testing.T) {
+	budget := NewMemoryBudget(100, nil, zerolog.Nop())
+
+	releaseA, ok := budget.TryReserve(60)
+	require.True(t, ok)
+	releaseB, ok := budget.TryReserve(40)
+	require.True(t, ok)
+	require.Equal(t, int64(100), budget.Reserved())
+
+	// The budget is exhausted
+	_, ok = budget.TryReserve(1)
+	require.False(t, ok)
+
+	// Releasing is idempotent
+	releaseA()
+	releaseA()
+	require.Equal(t, int64(40), budget.Reserved())
+
+	_, ok = budget.TryReserve(61)
+	require.False(t, ok)
+	releaseC, ok := budget.TryReserve(60)
+	require.True(t, ok)
+
+	releaseB()
+	releaseC()
+	require.Zero(t, budget.Reserved())
+
+	// Reservations larger than the whole budget never succeed
+	_, ok = budget.TryReserve(101)
+	require.False(t, ok)
+}
+
+func TestMemoryBudget_Unlimited(t *testing.T) {
+	budget := NewMemoryBudget(0, nil, zerolog.Nop())
+	require.Nil(t, budget)
+
+	release, ok := budget.TryReserve(1 << 40)
+	require.True(t, ok)
+	release()
+	require.Zero(t, budget.Reserved())
+}
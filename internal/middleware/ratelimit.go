@@ -2,7 +2,10 @@
 package middleware
 
 import (
+	"math"
+	"net"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
@@ -11,12 +14,16 @@ import (
 	"github.com/prn-tf/alexander-storage/internal/metrics"
 )
 
-// RateLimiter implements token bucket rate limiting.
+// RateLimiter implements token bucket rate limiting per client. Clients are
+// identified by access key when a ClientIdentifier attributes the request to
+// one, and by address otherwise.
 type RateLimiter struct {
 	// Configuration
 	requestsPerSecond float64
 	burstSize         int
 	enabled           bool
+	identify          ClientIdentifier
+	now               func() time.Time
 
 	// Per-client buckets
 	buckets sync.Map // map[string]*bucket
@@ -50,8 +57,24 @@ type RateLimiterConfig struct {
 
 	// CleanupInterval is how often to clean up stale buckets.
 	CleanupInterval time.Duration
+
+	// Identify attributes requests to clients (optional). Requests it does
+	// not attribute are limited by client address.
+	Identify ClientIdentifier
+}
+
+// RateLimit overrides the default rate limit of a client. Zero fields use
+// the limiter's defaults.
+type RateLimit struct {
+	RequestsPerSecond float64
+	BurstSize         int
 }
 
+// ClientIdentifier returns the client a request is attributed to, such as its
+// access key, and the client's rate limit overrides. ok is false for requests
+// it cannot attribute.
+type ClientIdentifier func(r *http.Request) (clientID string, limit RateLimit, ok bool)
+
 // DefaultRateLimiterConfig returns sensible defaults.
 func DefaultRateLimiterConfig() RateLimiterConfig {
 	return RateLimiterConfig{
@@ -68,6 +91,8 @@ func NewRateLimiter(config RateLimiterConfig, m *metrics.Metrics, logger zerolog
 		requestsPerSecond: config.RequestsPerSecond,
 		burstSize:         config.BurstSize,
 		enabled:           config.Enabled,
+		identify:          config.Identify,
+		now:               time.Now,
 		metrics:           m,
 		logger:            logger.With().Str("component", "ratelimiter").Logger(),
 		cleanupInterval:   config.CleanupInterval,
@@ -89,11 +114,11 @@ func (rl *RateLimiter) Middleware(next http.Handler) http.Handler {
 			return
 		}
 
-		// Get client identifier (IP address or access key)
-		clientID := rl.getClientID(r)
+		// Get client identifier (access key or IP address)
+		clientID, limit := rl.getClient(r)
 
 		// Check rate limit
-		if !rl.allow(clientID) {
+		if retryAfter, ok := rl.allow(clientID, limit); !ok {
 			rl.logger.Warn().
 				Str("client_id", clientID).
 				Str("path", r.URL.Path).
@@ -103,9 +128,10 @@ func (rl *RateLimiter) Middleware(next http.Handler) http.Handler {
 				rl.metrics.RecordRateLimited("request")
 			}
 
+			SetErrorCode(w, "SlowDown")
 			w.Header().Set("Content-Type", "application/xml")
-			w.Header().Set("Retry-After", "1")
-			w.WriteHeader(http.StatusTooManyRequests)
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			w.WriteHeader(http.StatusServiceUnavailable)
 			w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
 <Error>
     <Code>SlowDown</Code>
@@ -118,33 +144,54 @@ func (rl *RateLimiter) Middleware(next http.Handler) http.Handler {
 	})
 }
 
-// getClientID extracts the client identifier from the request.
-func (rl *RateLimiter) getClientID(r *http.Request) string {
+// getClient identifies the client of a request and its rate limit.
+func (rl *RateLimiter) getClient(r *http.Request) (string, RateLimit) {
+	limit := RateLimit{RequestsPerSecond: rl.requestsPerSecond, BurstSize: rl.burstSize}
+	if rl.identify != nil {
+		if clientID, override, ok := rl.identify(r); ok {
+			if override.RequestsPerSecond > 0 {
+				limit.RequestsPerSecond = override.RequestsPerSecond
+			}
+			if override.BurstSize > 0 {
+				limit.BurstSize = override.BurstSize
+			}
+			return "key:" + clientID, limit
+		}
+	}
+	return "ip:" + rl.getClientIP(r), limit
+}
+
+// getClientIP extracts the client address from the request.
+func (rl *RateLimiter) getClientIP(r *http.Request) string {
 	// Try to get X-Forwarded-For header first (for proxied requests)
 	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
 		return xff
 	}
 
-	// Fall back to remote address
+	// Fall back to remote address, without the port of the connection
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
 	return r.RemoteAddr
 }
 
-// allow checks if a request is allowed under the rate limit.
-func (rl *RateLimiter) allow(clientID string) bool {
-	b := rl.getBucket(clientID)
+// allow checks if a request is allowed under the rate limit. When it is not,
+// retryAfter is the number of seconds until a token is available.
+func (rl *RateLimiter) allow(clientID string, limit RateLimit) (retryAfter int, ok bool) {
+	b := rl.getBucket(clientID, limit)
 
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	now := time.Now()
+	now := rl.now()
 
 	// Refill tokens based on time elapsed
 	elapsed := now.Sub(b.lastRefill).Seconds()
-	b.tokens += elapsed * rl.requestsPerSecond
+	b.tokens += elapsed * limit.RequestsPerSecond
 
 	// Cap at burst size
-	if b.tokens > float64(rl.burstSize) {
-		b.tokens = float64(rl.burstSize)
+	if b.tokens > float64(limit.BurstSize) {
+		b.tokens = float64(limit.BurstSize)
 	}
 
 	b.lastRefill = now
@@ -152,22 +199,22 @@ func (rl *RateLimiter) allow(clientID string) bool {
 	// Check if we have at least 1 token
 	if b.tokens >= 1 {
 		b.tokens--
-		return true
+		return 0, true
 	}
 
-	return false
+	return max(1, int(math.Ceil((1-b.tokens)/limit.RequestsPerSecond))), false
 }
 
 // getBucket gets or creates a bucket for the client.
-func (rl *RateLimiter) getBucket(clientID string) *bucket {
+func (rl *RateLimiter) getBucket(clientID string, limit RateLimit) *bucket {
 	if b, ok := rl.buckets.Load(clientID); ok {
 		return b.(*bucket)
 	}
 
 	// Create new bucket with full tokens
 	b := &bucket{
-		tokens:     float64(rl.burstSize),
-		lastRefill: time.Now(),
+		tokens:     float64(limit.BurstSize),
+		lastRefill: rl.now(),
 	}
 
 	actual, _ := rl.buckets.LoadOrStore(clientID, b)
@@ -191,7 +238,7 @@ func (rl *RateLimiter) cleanupLoop() {
 
 // cleanup removes buckets that haven't been accessed recently.
 func (rl *RateLimiter) cleanup() {
-	threshold := time.Now().Add(-rl.cleanupInterval)
+	threshold := rl.now().Add(-rl.cleanupInterval)
 	deleted := 0
 
 	rl.buckets.Range(func(key, value interface{}) bool {
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter_SlowDownAndRecover(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	identify := func(r *http.Request) (string, RateLimit, bool) {
+		switch key := r.Header.Get("X-Test-Key"); key {
+		case "":
+			return "", RateLimit{}, false
+		case "bulk":
+			return key, RateLimit{RequestsPerSecond: 10, BurstSize: 5}, true
+		default:
+			return key, RateLimit{}, true
+		}
+	}
+	rl := NewRateLimiter(RateLimiterConfig{
+		RequestsPerSecond: 0.5,
+		BurstSize:         2,
+		Enabled:           true,
+		CleanupInterval:   time.Minute,
+		Identify:          identify,
+	}, nil, zerolog.Nop())
+	defer rl.Stop()
+	rl.now = func() time.Time { return now }
+
+	handler := rl.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	do := func(key, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/bucket/key", nil)
+		req.RemoteAddr = remoteAddr
+		if key != "" {
+			req.Header.Set("X-Test-Key", key)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// The burst is served, then the key is throttled wherever it connects from
+	require.Equal(t, http.StatusOK, do("alice", "10.0.0.1:1000").Code)
+	require.Equal(t, http.StatusOK, do("alice", "10.0.0.2:1000").Code)
+	rec := do("alice", "10.0.0.3:1000")
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	require.Contains(t, rec.Body.String(), "<Code>SlowDown</Code>")
+	require.Equal(t, "2", rec.Header().Get("Retry-After"))
+
+	// Other keys and anonymous clients have their own buckets
+	require.Equal(t, http.StatusOK, do("bob", "10.0.0.1:1000").Code)
+	require.Equal(t, http.StatusOK, do("", "10.0.0.1:1000").Code)
+	require.Equal(t, http.StatusOK, do("", "10.0.0.1:1001").Code)
+	require.Equal(t, http.StatusServiceUnavailable, do("", "10.0.0.1:1002").Code, "anonymous clients are limited by IP, not connection")
+
+	// Per-key overrides replace the defaults
+	for i := 0; i < 5; i++ {
+		require.Equal(t, http.StatusOK, do("bulk", "10.0.0.1:1000").Code)
+	}
+	require.Equal(t, http.StatusServiceUnavailable, do("bulk", "10.0.0.1:1000").Code)
+
+	// Tokens refill over time
+	now = now.Add(2 * time.Second)
+	require.Equal(t, http.StatusOK, do("alice", "10.0.0.1:1000").Code)
+	require.Equal(t, http.StatusServiceUnavailable, do("alice", "10.0.0.1:1000").Code)
+	require.Equal(t, http.StatusOK, do("bulk", "10.0.0.1:1000").Code)
+}
@@ -3,12 +3,15 @@ package middleware
 
 import (
 	"context"
+	"io"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/rs/zerolog"
 
+	"github.com/prn-tf/alexander-storage/internal/domain"
 	"github.com/prn-tf/alexander-storage/internal/metrics"
 )
 
@@ -116,6 +119,9 @@ func (t *Tracing) Middleware(next http.Handler) http.Handler {
 				duration.Seconds(),
 				int64(wrapped.bytesWritten),
 			)
+			if bucket, ok := bucketFromPath(r.URL.Path); ok {
+				t.metrics.RecordBucketRequest(bucket, r.Method, wrapped.statusCode, int64(wrapped.bytesWritten))
+			}
 		}
 
 		// Log request completion
@@ -198,6 +204,20 @@ func normalizePath(path string) string {
 	return "/{bucket}/{key}"
 }
 
+// bucketFromPath returns the bucket addressed by a path-style S3 request.
+func bucketFromPath(path string) (string, bool) {
+	switch path {
+	case "/health", "/healthz", "/readyz", "/metrics":
+		return "", false
+	}
+
+	parts := splitPath(path)
+	if len(parts) == 0 || domain.ValidateBucketName(parts[0]) != nil {
+		return "", false
+	}
+	return parts[0], true
+}
+
 // splitPath splits a path into segments.
 func splitPath(path string) []string {
 	var parts []string
@@ -275,13 +295,103 @@ func NewMetricsMiddleware(m *metrics.Metrics) *MetricsMiddleware {
 	return &MetricsMiddleware{metrics: m}
 }
 
-// Middleware returns the metrics middleware.
+// Middleware returns the metrics middleware. Requests an inner handler
+// attributes to an S3 operation with SetOperation are recorded per operation
+// and S3 error code, along with the bytes they received and sent.
 func (m *MetricsMiddleware) Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Track in-flight requests
 		m.metrics.HTTPRequestsInFlight.Inc()
 		defer m.metrics.HTTPRequestsInFlight.Dec()
 
-		next.ServeHTTP(w, r)
+		start := time.Now()
+		body := &countingReadCloser{ReadCloser: r.Body}
+		if r.Body != nil {
+			r = r.WithContext(r.Context())
+			r.Body = body
+		}
+		recorder := &operationRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+
+		next.ServeHTTP(recorder, r)
+
+		if recorder.operation == "" {
+			return
+		}
+		m.metrics.RecordS3Request(
+			recorder.operation,
+			recorder.code(),
+			time.Since(start).Seconds(),
+			body.n,
+			recorder.bytesWritten,
+		)
 	})
 }
+
+// SetOperation attributes the request w answers to an S3 operation for the
+// metrics middleware. It does nothing when metrics are disabled.
+func SetOperation(w http.ResponseWriter, operation string) {
+	if recorder, ok := w.(*operationRecorder); ok {
+		recorder.operation = operation
+	}
+}
+
+// SetErrorCode records the S3 error code of the response written to w for
+// the metrics middleware. It does nothing when metrics are disabled.
+func SetErrorCode(w http.ResponseWriter, code string) {
+	if recorder, ok := w.(*operationRecorder); ok {
+		recorder.errorCode = code
+	}
+}
+
+// operationRecorder wraps http.ResponseWriter to capture the S3 operation,
+// error code and size of a response.
+type operationRecorder struct {
+	http.ResponseWriter
+	operation    string
+	errorCode    string
+	statusCode   int
+	bytesWritten int64
+}
+
+func (rec *operationRecorder) WriteHeader(code int) {
+	rec.statusCode = code
+	rec.ResponseWriter.WriteHeader(code)
+}
+
+func (rec *operationRecorder) Write(b []byte) (int, error) {
+	n, err := rec.ResponseWriter.Write(b)
+	rec.bytesWritten += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher for streaming responses.
+func (rec *operationRecorder) Flush() {
+	if f, ok := rec.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// code returns the S3 error code of the response. Errors written without
+// one are labeled with their HTTP status.
+func (rec *operationRecorder) code() string {
+	switch {
+	case rec.errorCode != "":
+		return rec.errorCode
+	case rec.statusCode >= http.StatusBadRequest:
+		return strconv.Itoa(rec.statusCode)
+	default:
+		return "OK"
+	}
+}
+
+// countingReadCloser counts the bytes read from a request body.
+type countingReadCloser struct {
+	io.ReadCloser
+	n int64
+}
+
+func (c *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.n += int64(n)
+	return n, err
+}
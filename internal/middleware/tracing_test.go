@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/metrics"
+)
+
+// bucketLabels serves a request for each of 50 buckets with the given
+// per-bucket metrics configuration and returns the bucket labels of the
+// emitted series.
+func bucketLabels(t *testing.T, cfg metrics.BucketLabelConfig) map[string]bool {
+	t.Helper()
+	reg := prometheus.NewRegistry()
+	m := metrics.NewWithRegisterer(reg)
+	m.SetBucketLabels(cfg)
+
+	handler := NewTracing(m, zerolog.Nop()).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	for i := 0; i < 50; i++ {
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/bucket-%02d/key", i), nil)
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+
+	labels := make(map[string]bool)
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			for _, pair := range metric.GetLabel() {
+				if pair.GetName() == "bucket" {
+					labels[pair.GetValue()] = true
+				}
+			}
+		}
+	}
+	return labels
+}
+
+func TestTracing_BucketMetrics(t *testing.T) {
+	t.Run("Disabled", func(t *testing.T) {
+		require.Empty(t, bucketLabels(t, metrics.BucketLabelConfig{Mode: metrics.BucketLabelsNone}))
+		require.Empty(t, bucketLabels(t, metrics.BucketLabelConfig{}))
+	})
+
+	t.Run("AllowList", func(t *testing.T) {
+		labels := bucketLabels(t, metrics.BucketLabelConfig{
+			Mode:    metrics.BucketLabelsAllowList,
+			Buckets: []string{"bucket-07", "bucket-42"},
+		})
+		require.Equal(t, map[string]bool{"bucket-07": true, "bucket-42": true, metrics.OtherBucketsLabel: true}, labels)
+	})
+
+	t.Run("Hashed", func(t *testing.T) {
+		labels := bucketLabels(t, metrics.BucketLabelConfig{Mode: metrics.BucketLabelsHashed, HashBuckets: 4})
+		require.NotEmpty(t, labels)
+		require.LessOrEqual(t, len(labels), 4)
+	})
+}
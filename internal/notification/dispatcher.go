@@ -0,0 +1,274 @@
+// Package notification delivers S3 event notifications to the webhooks
+// configured on buckets. The Dispatcher subscribes to the event bus and
+// POSTs each matching event from its own bounded queue, retrying failed
+// deliveries, so slow or unreachable webhooks never hold up a request or
+// the other event subscribers.
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/events"
+)
+
+const (
+	// DefaultQueueSize is the number of deliveries buffered when no size is configured.
+	DefaultQueueSize = 1000
+
+	// DefaultWorkers is the number of concurrent deliveries when none is configured.
+	DefaultWorkers = 4
+
+	// DefaultMaxAttempts is how often a delivery is tried when no limit is configured.
+	DefaultMaxAttempts = 5
+
+	// DefaultTimeout bounds a single delivery attempt when no timeout is configured.
+	DefaultTimeout = 10 * time.Second
+
+	// DefaultRetryBackoff is the wait before the first retry when none is
+	// configured; it doubles with every further attempt.
+	DefaultRetryBackoff = time.Second
+)
+
+// ConfigSource returns the webhooks of a bucket. An ownerID of 0 skips the
+// ownership check. service.NotificationService satisfies it.
+type ConfigSource interface {
+	GetBucketNotification(ctx context.Context, bucketName string, ownerID int64) ([]domain.WebhookTarget, error)
+}
+
+// Config configures the Dispatcher.
+type Config struct {
+	// QueueSize is the number of deliveries buffered; events matching a
+	// webhook while the queue is full are dropped and logged.
+	QueueSize int
+
+	// Workers is the number of deliveries in flight at once.
+	Workers int
+
+	// MaxAttempts is how often a delivery is tried before it is given up.
+	MaxAttempts int
+
+	// Timeout bounds a single delivery attempt.
+	Timeout time.Duration
+
+	// RetryBackoff is the wait before the first retry, doubling per attempt.
+	RetryBackoff time.Duration
+
+	// Region is reported as the awsRegion of event records.
+	Region string
+}
+
+// delivery is an encoded event on its way to a webhook.
+type delivery struct {
+	url     string
+	bucket  string
+	key     string
+	event   events.Type
+	payload []byte
+}
+
+// Dispatcher is an event subscriber that delivers object events to the
+// webhooks of their bucket.
+type Dispatcher struct {
+	source ConfigSource
+	config Config
+	client *http.Client
+	logger zerolog.Logger
+
+	queue chan delivery
+
+	// stopping is closed by Close to cut retry waits short, so the queue
+	// drains with one attempt per remaining delivery.
+	stopping chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// NewDispatcher creates a Dispatcher reading webhooks from source and starts
+// its delivery workers.
+func NewDispatcher(source ConfigSource, config Config, logger zerolog.Logger) *Dispatcher {
+	if config.QueueSize <= 0 {
+		config.QueueSize = DefaultQueueSize
+	}
+	if config.Workers <= 0 {
+		config.Workers = DefaultWorkers
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = DefaultMaxAttempts
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = DefaultRetryBackoff
+	}
+
+	d := &Dispatcher{
+		source:   source,
+		config:   config,
+		client:   &http.Client{Timeout: config.Timeout},
+		logger:   logger.With().Str("component", "notification").Logger(),
+		queue:    make(chan delivery, config.QueueSize),
+		stopping: make(chan struct{}),
+	}
+
+	d.wg.Add(config.Workers)
+	for i := 0; i < config.Workers; i++ {
+		go d.work()
+	}
+	return d
+}
+
+// HandleEvent queues the event for every webhook of its bucket that
+// subscribes to it. It never waits for a delivery.
+func (d *Dispatcher) HandleEvent(ctx context.Context, event events.Event) error {
+	webhooks, err := d.source.GetBucketNotification(ctx, event.Bucket, 0)
+	if err != nil {
+		// The bucket may be gone by the time its last events are handled
+		if errors.Is(err, domain.ErrBucketNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to load notification configuration: %w", err)
+	}
+
+	for i := range webhooks {
+		webhook := &webhooks[i]
+		if !webhook.Matches(string(event.Type), event.Key) {
+			continue
+		}
+
+		payload, err := json.Marshal(newEventMessage(event, webhook.ID, d.config.Region))
+		if err != nil {
+			return fmt.Errorf("failed to encode event: %w", err)
+		}
+		d.enqueue(delivery{
+			url:     webhook.URL,
+			bucket:  event.Bucket,
+			key:     event.Key,
+			event:   event.Type,
+			payload: payload,
+		})
+	}
+	return nil
+}
+
+// Close stops accepting deliveries and waits until the queued ones have
+// been attempted or ctx is done. Pending retries are not waited for.
+func (d *Dispatcher) Close(ctx context.Context) error {
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		close(d.stopping)
+		close(d.queue)
+	}
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// enqueue queues a delivery without blocking, dropping it when the queue is full.
+func (d *Dispatcher) enqueue(dl delivery) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.closed {
+		return
+	}
+
+	select {
+	case d.queue <- dl:
+	default:
+		d.logger.Warn().
+			Str("url", dl.url).
+			Str("event", string(dl.event)).
+			Str("bucket", dl.bucket).
+			Str("key", dl.key).
+			Msg("notification queue full, dropping event")
+	}
+}
+
+// work delivers queued events until the queue is closed.
+func (d *Dispatcher) work() {
+	defer d.wg.Done()
+
+	for dl := range d.queue {
+		d.deliver(dl)
+	}
+}
+
+// deliver POSTs an event to its webhook, retrying with exponential backoff
+// until it succeeds, the attempts run out or the dispatcher closes.
+func (d *Dispatcher) deliver(dl delivery) {
+	backoff := d.config.RetryBackoff
+	var err error
+retry:
+	for attempt := 1; attempt <= d.config.MaxAttempts; attempt++ {
+		if err = d.post(dl); err == nil {
+			return
+		}
+		if attempt == d.config.MaxAttempts {
+			break
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-d.stopping:
+			timer.Stop()
+			break retry
+		}
+		backoff *= 2
+	}
+
+	d.logger.Warn().
+		Err(err).
+		Str("url", dl.url).
+		Str("event", string(dl.event)).
+		Str("bucket", dl.bucket).
+		Str("key", dl.key).
+		Msg("failed to deliver event notification")
+}
+
+// post makes one delivery attempt. Any 2xx response counts as delivered.
+func (d *Dispatcher) post(dl delivery) error {
+	req, err := http.NewRequest(http.MethodPost, dl.url, bytes.NewReader(dl.payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/events"
+)
+
+// staticSource serves fixed webhooks for every bucket.
+type staticSource []domain.WebhookTarget
+
+func (s staticSource) GetBucketNotification(ctx context.Context, bucketName string, ownerID int64) ([]domain.WebhookTarget, error) {
+	return s, nil
+}
+
+// webhookServer records the messages POSTed to it. The first failures
+// requests are answered with 503.
+type webhookServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	failures int
+	attempts int
+	messages []EventMessage
+}
+
+func newWebhookServer(t *testing.T, failures int) *webhookServer {
+	t.Helper()
+	ws := &webhookServer{failures: failures}
+	ws.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws.mu.Lock()
+		defer ws.mu.Unlock()
+
+		ws.attempts++
+		if ws.attempts <= ws.failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var message EventMessage
+		if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		ws.messages = append(ws.messages, message)
+	}))
+	t.Cleanup(ws.Close)
+	return ws
+}
+
+func (ws *webhookServer) received() ([]EventMessage, int) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	return append([]EventMessage(nil), ws.messages...), ws.attempts
+}
+
+func TestDispatcher_DeliversCreateEvent(t *testing.T) {
+	ws := newWebhookServer(t, 0)
+	d := NewDispatcher(staticSource{{
+		ID:     "images",
+		URL:    ws.URL,
+		Events: []string{"s3:ObjectCreated:*"},
+		Prefix: "photos/",
+	}}, Config{Region: "us-east-1"}, zerolog.Nop())
+
+	eventTime := time.Date(2026, 10, 15, 12, 30, 0, 0, time.UTC)
+	require.NoError(t, d.HandleEvent(context.Background(), events.Event{
+		Type:    events.ObjectCreatedPut,
+		Bucket:  "uploads",
+		Key:     "photos/summer cat.jpg",
+		ETag:    `"d41d8cd98f00b204e9800998ecf8427e"`,
+		Size:    42,
+		OwnerID: 7,
+		Time:    eventTime,
+	}))
+	// Neither the key prefix nor the event type match
+	require.NoError(t, d.HandleEvent(context.Background(), events.Event{Type: events.ObjectCreatedPut, Bucket: "uploads", Key: "docs/a.txt", Time: eventTime}))
+	require.NoError(t, d.HandleEvent(context.Background(), events.Event{Type: events.ObjectRemovedDelete, Bucket: "uploads", Key: "photos/a.jpg", Time: eventTime}))
+	require.NoError(t, d.Close(context.Background()))
+
+	messages, _ := ws.received()
+	require.Len(t, messages, 1)
+	require.Len(t, messages[0].Records, 1)
+
+	record := messages[0].Records[0]
+	require.Equal(t, "ObjectCreated:Put", record.EventName)
+	require.Equal(t, "photos/summer+cat.jpg", record.S3.Object.Key)
+	require.Equal(t, int64(42), record.S3.Object.Size)
+	require.Equal(t, "d41d8cd98f00b204e9800998ecf8427e", record.S3.Object.ETag)
+	require.Equal(t, "uploads", record.S3.Bucket.Name)
+	require.Equal(t, "images", record.S3.ConfigurationID)
+	require.Equal(t, "7", record.UserIdentity.PrincipalID)
+	require.Equal(t, "us-east-1", record.AWSRegion)
+	require.Equal(t, "2026-10-15T12:30:00.000Z", record.EventTime)
+}
+
+func TestDispatcher_RetriesFailedDeliveries(t *testing.T) {
+	ws := newWebhookServer(t, 2)
+	d := NewDispatcher(staticSource{{URL: ws.URL, Events: []string{"s3:ObjectRemoved:*"}}}, Config{
+		MaxAttempts:  3,
+		RetryBackoff: time.Millisecond,
+	}, zerolog.Nop())
+
+	require.NoError(t, d.HandleEvent(context.Background(), events.Event{Type: events.ObjectRemovedDelete, Bucket: "uploads", Key: "old.txt"}))
+	require.Eventually(t, func() bool {
+		messages, _ := ws.received()
+		return len(messages) == 1
+	}, 5*time.Second, 5*time.Millisecond)
+	require.NoError(t, d.Close(context.Background()))
+
+	messages, attempts := ws.received()
+	require.Equal(t, 3, attempts)
+	require.Equal(t, "ObjectRemoved:Delete", messages[0].Records[0].EventName)
+	require.Zero(t, messages[0].Records[0].S3.Object.Size)
+}
+
+func TestDispatcher_SlowWebhookDoesNotBlock(t *testing.T) {
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer slow.Close()
+	defer close(release)
+
+	d := NewDispatcher(staticSource{{URL: slow.URL, Events: []string{"s3:ObjectCreated:*"}}}, Config{
+		QueueSize: 2,
+		Workers:   1,
+	}, zerolog.Nop())
+
+	// HandleEvent returns at once even though deliveries pile up and,
+	// beyond the queue, are dropped
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		require.NoError(t, d.HandleEvent(context.Background(), events.Event{Type: events.ObjectCreatedPut, Bucket: "uploads", Key: "a.txt"}))
+	}
+	require.Less(t, time.Since(start), time.Second)
+	require.LessOrEqual(t, len(d.queue), 2)
+}
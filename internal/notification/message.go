@@ -0,0 +1,102 @@
+package notification
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/prn-tf/alexander-storage/internal/events"
+)
+
+// eventSource identifies the service in event records, like "aws:s3" in AWS.
+const eventSource = "alexander:s3"
+
+// EventMessage is the JSON body POSTed to webhooks, shaped like an S3 event
+// notification message.
+type EventMessage struct {
+	Records []EventRecord `json:"Records"`
+}
+
+// EventRecord describes one object event.
+type EventRecord struct {
+	EventVersion string       `json:"eventVersion"`
+	EventSource  string       `json:"eventSource"`
+	AWSRegion    string       `json:"awsRegion"`
+	EventTime    string       `json:"eventTime"`
+	EventName    string       `json:"eventName"`
+	UserIdentity UserIdentity `json:"userIdentity"`
+	S3           S3Entity     `json:"s3"`
+}
+
+// UserIdentity identifies the user that caused the event.
+type UserIdentity struct {
+	PrincipalID string `json:"principalId"`
+}
+
+// S3Entity describes the bucket and object of an event.
+type S3Entity struct {
+	SchemaVersion   string       `json:"s3SchemaVersion"`
+	ConfigurationID string       `json:"configurationId"`
+	Bucket          BucketEntity `json:"bucket"`
+	Object          ObjectEntity `json:"object"`
+}
+
+// BucketEntity is the bucket of an event.
+type BucketEntity struct {
+	Name string `json:"name"`
+	ARN  string `json:"arn"`
+}
+
+// ObjectEntity is the object of an event. As in S3, the key is URL-encoded
+// and size and ETag are left out for removals.
+type ObjectEntity struct {
+	Key       string `json:"key"`
+	Size      int64  `json:"size,omitempty"`
+	ETag      string `json:"eTag,omitempty"`
+	VersionID string `json:"versionId,omitempty"`
+	Sequencer string `json:"sequencer"`
+}
+
+// newEventMessage builds the message for an event delivered to the webhook
+// with the given configuration ID.
+func newEventMessage(event events.Event, configurationID, region string) EventMessage {
+	object := ObjectEntity{
+		Key:       encodeKey(event.Key),
+		VersionID: event.VersionID,
+		// Events of one key are ordered by their time
+		Sequencer: fmt.Sprintf("%016X", event.Time.UnixNano()),
+	}
+	if event.Type.IsCreated() {
+		object.Size = event.Size
+		object.ETag = strings.Trim(event.ETag, `"`)
+	}
+
+	return EventMessage{Records: []EventRecord{{
+		EventVersion: "2.1",
+		EventSource:  eventSource,
+		AWSRegion:    region,
+		EventTime:    event.Time.UTC().Format("2006-01-02T15:04:05.000Z"),
+		EventName:    strings.TrimPrefix(string(event.Type), "s3:"),
+		UserIdentity: UserIdentity{PrincipalID: strconv.FormatInt(event.OwnerID, 10)},
+		S3: S3Entity{
+			SchemaVersion:   "1.0",
+			ConfigurationID: configurationID,
+			Bucket: BucketEntity{
+				Name: event.Bucket,
+				ARN:  "arn:aws:s3:::" + event.Bucket,
+			},
+			Object: object,
+		},
+	}}}
+}
+
+// encodeKey URL-encodes an object key the way S3 event messages do, keeping
+// the "/" separators.
+func encodeKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.QueryEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
@@ -2,6 +2,7 @@
 package crypto
 
 import (
+	"bytes"
 	"crypto/cipher"
 	"crypto/rand"
 	"encoding/binary"
@@ -275,6 +276,63 @@ func (r *DecryptingReader) Read(p []byte) (int, error) {
 	return copied, nil
 }
 
+// NewRangeDecryptingReader creates a reader that decrypts length plaintext
+// bytes starting at offset. Chunks before the range are skipped by reading
+// only their headers, so just the chunks overlapping the range are decrypted.
+// A length of zero or less reads to the end of the blob.
+func (e *ChaChaStreamEncryptor) NewRangeDecryptingReader(source io.ReadSeeker, salt []byte, offset, length int64) (io.Reader, error) {
+	if offset < 0 {
+		return nil, fmt.Errorf("invalid range offset: %d", offset)
+	}
+
+	header := make([]byte, ChaChaHeaderSize)
+	for {
+		_, err := io.ReadFull(source, header)
+		if err == io.EOF {
+			// The range starts past the end of the blob
+			return bytes.NewReader(nil), nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read chunk header: %w", err)
+		}
+
+		ciphertextSize := int64(binary.BigEndian.Uint32(header[0:4]))
+		if ciphertextSize < ChaChaOverhead {
+			return nil, ErrInvalidChunk
+		}
+
+		plaintextSize := ciphertextSize - ChaChaOverhead
+		if offset < plaintextSize {
+			if _, err := source.Seek(-ChaChaHeaderSize, io.SeekCurrent); err != nil {
+				return nil, fmt.Errorf("failed to seek to chunk: %w", err)
+			}
+			break
+		}
+
+		offset -= plaintextSize
+		if _, err := source.Seek(ciphertextSize, io.SeekCurrent); err != nil {
+			return nil, fmt.Errorf("failed to skip chunk: %w", err)
+		}
+	}
+
+	decryptingReader, err := e.NewDecryptingReader(source, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	// Discard the part of the first chunk before the range
+	if offset > 0 {
+		if _, err := io.CopyN(io.Discard, decryptingReader, offset); err != nil {
+			return nil, err
+		}
+	}
+
+	if length > 0 {
+		return io.LimitReader(decryptingReader, length), nil
+	}
+	return decryptingReader, nil
+}
+
 // EncryptBlob encrypts an entire blob using streaming chunks.
 // Returns the complete encrypted data.
 // For large files, prefer NewEncryptingReader for streaming.
@@ -158,3 +158,41 @@ func (h *HashingWriter) Sum() string {
 func (h *HashingWriter) Size() int64 {
 	return h.size
 }
+
+// MD5Reader wraps an io.Reader and computes MD5 while reading.
+// This is useful for verifying a Content-MD5 header against a body that
+// is streamed to another destination.
+type MD5Reader struct {
+	reader io.Reader
+	md5    hash.Hash
+	size   int64
+}
+
+// NewMD5Reader creates a new MD5Reader.
+func NewMD5Reader(r io.Reader) *MD5Reader {
+	return &MD5Reader{
+		reader: r,
+		md5:    md5.New(),
+	}
+}
+
+// Read implements io.Reader and updates hash computation.
+func (h *MD5Reader) Read(p []byte) (n int, err error) {
+	n, err = h.reader.Read(p)
+	if n > 0 {
+		h.md5.Write(p[:n])
+		h.size += int64(n)
+	}
+	return n, err
+}
+
+// Sum returns the raw MD5 digest.
+// Should only be called after reading is complete.
+func (h *MD5Reader) Sum() []byte {
+	return h.md5.Sum(nil)
+}
+
+// Size returns the total number of bytes read.
+func (h *MD5Reader) Size() int64 {
+	return h.size
+}
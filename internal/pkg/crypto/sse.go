@@ -4,10 +4,13 @@
 package crypto
 
 import (
+	"bufio"
+	"bytes"
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
 	"crypto/sha256"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
@@ -30,10 +33,26 @@ const (
 	// Smaller chunks allow for better streaming but more overhead.
 	SSEChunkSize = 64 * 1024
 
+	// SSEFormatChunked is the on-disk format version written after the magic
+	// bytes: base nonce (12 bytes) followed by SSEChunkSize chunks, each
+	// sealed separately as ciphertext || tag (16 bytes). Blobs without the
+	// header use the legacy single-seal format: nonce || ciphertext || tag.
+	SSEFormatChunked byte = 2
+
+	// SSEHeaderSize is the size of the chunked format header.
+	SSEHeaderSize = 6 + 1 + SSENonceSize
+
 	// SSEHKDFInfo is the context info for HKDF key derivation.
 	SSEHKDFInfo = "alexander-sse-s3-blob-encryption"
+
+	// AESEncryptionScheme is the identifier for this encryption scheme.
+	AESEncryptionScheme = "aes-256-gcm"
 )
 
+// sseMagic starts every chunked blob. A legacy blob starts with a random
+// nonce and matches it with negligible probability.
+var sseMagic = []byte("AXSSE\x00")
+
 // SSE errors
 var (
 	// ErrSSEInvalidMasterKey indicates the master key is invalid.
@@ -50,6 +69,7 @@ var (
 // It derives per-blob keys from the master key using HKDF.
 type SSEEncryptor struct {
 	masterKey []byte
+	info      string // HKDF context, separates SSE-S3 and SSE-C keys
 }
 
 // NewSSEEncryptor creates a new SSE encryptor with the given master key.
@@ -63,7 +83,7 @@ func NewSSEEncryptor(masterKey []byte) (*SSEEncryptor, error) {
 	keyCopy := make([]byte, SSEKeySize)
 	copy(keyCopy, masterKey)
 
-	return &SSEEncryptor{masterKey: keyCopy}, nil
+	return &SSEEncryptor{masterKey: keyCopy, info: SSEHKDFInfo}, nil
 }
 
 // NewSSEEncryptorFromHex creates an SSE encryptor from a hex-encoded master key.
@@ -82,7 +102,7 @@ func (e *SSEEncryptor) DeriveKey(blobHash string) ([]byte, error) {
 	salt := []byte(blobHash)
 
 	// Create HKDF reader
-	reader := hkdf.New(sha256.New, e.masterKey, salt, []byte(SSEHKDFInfo))
+	reader := hkdf.New(sha256.New, e.masterKey, salt, []byte(e.info))
 
 	// Derive key
 	key := make([]byte, SSEKeySize)
@@ -93,65 +113,52 @@ func (e *SSEEncryptor) DeriveKey(blobHash string) ([]byte, error) {
 	return key, nil
 }
 
-// EncryptBlob encrypts blob content using AES-256-GCM.
-// Returns the encrypted data with prepended nonce.
-// Format: nonce (12 bytes) || ciphertext || tag (16 bytes)
+// EncryptBlob encrypts blob content using AES-256-GCM in the chunked format.
+// It is a convenience for small blobs; prefer EncryptReader for large ones.
 func (e *SSEEncryptor) EncryptBlob(plaintext []byte, blobHash string) ([]byte, error) {
-	// Derive per-blob key
-	key, err := e.DeriveKey(blobHash)
+	reader, err := e.EncryptReader(bytes.NewReader(plaintext), blobHash)
 	if err != nil {
 		return nil, err
 	}
-	defer zeroBytes(key)
+	defer reader.Close()
 
-	// Create cipher
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	ciphertext := make([]byte, 0, CalculateEncryptedSize(int64(len(plaintext))))
+	buf := bytes.NewBuffer(ciphertext)
+	if _, err := io.Copy(buf, reader); err != nil {
+		return nil, err
 	}
+	return buf.Bytes(), nil
+}
 
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create GCM: %w", err)
+// DecryptBlob decrypts blob content encrypted with EncryptBlob or
+// EncryptReader. Legacy single-seal blobs are also accepted.
+func (e *SSEEncryptor) DecryptBlob(ciphertext []byte, blobHash string) ([]byte, error) {
+	if !isSSEChunked(ciphertext) {
+		return e.decryptSingleSeal(ciphertext, blobHash)
 	}
 
-	// Generate random nonce
-	nonce := make([]byte, SSENonceSize)
-	if _, err := rand.Read(nonce); err != nil {
-		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	reader, err := e.DecryptReader(bytes.NewReader(ciphertext), blobHash)
+	if err != nil {
+		return nil, err
 	}
+	defer reader.Close()
 
-	// Encrypt: output is nonce || ciphertext || tag
-	ciphertext := gcm.Seal(nonce, nonce, plaintext, nil)
-
-	return ciphertext, nil
+	return io.ReadAll(reader)
 }
 
-// DecryptBlob decrypts blob content encrypted with EncryptBlob.
-// Expects format: nonce (12 bytes) || ciphertext || tag (16 bytes)
-func (e *SSEEncryptor) DecryptBlob(ciphertext []byte, blobHash string) ([]byte, error) {
+// decryptSingleSeal decrypts the legacy format, written before chunking:
+// nonce (12 bytes) || ciphertext || tag (16 bytes), sealed as one message.
+func (e *SSEEncryptor) decryptSingleSeal(ciphertext []byte, blobHash string) ([]byte, error) {
 	// Minimum size: nonce + tag
 	if len(ciphertext) < SSENonceSize+SSETagSize {
 		return nil, ErrSSEInvalidData
 	}
 
-	// Derive per-blob key
-	key, err := e.DeriveKey(blobHash)
+	gcm, key, err := e.newGCM(blobHash)
 	if err != nil {
 		return nil, err
 	}
-	defer zeroBytes(key)
-
-	// Create cipher
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create cipher: %w", err)
-	}
-
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create GCM: %w", err)
-	}
+	zeroBytes(key)
 
 	// Extract nonce
 	nonce := ciphertext[:SSENonceSize]
@@ -166,123 +173,135 @@ func (e *SSEEncryptor) DecryptBlob(ciphertext []byte, blobHash string) ([]byte,
 	return plaintext, nil
 }
 
-// EncryptReader wraps a reader to encrypt data on the fly.
-// Returns an io.Reader that produces encrypted data.
-func (e *SSEEncryptor) EncryptReader(reader io.Reader, blobHash string) (*SSEEncryptingReader, error) {
+// newGCM derives the blob key and creates its AES-GCM cipher. The caller
+// zeroes the returned key once it is no longer needed.
+func (e *SSEEncryptor) newGCM(blobHash string) (cipher.AEAD, []byte, error) {
 	key, err := e.DeriveKey(blobHash)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	block, err := aes.NewCipher(key)
 	if err != nil {
 		zeroBytes(key)
-		return nil, fmt.Errorf("failed to create cipher: %w", err)
+		return nil, nil, fmt.Errorf("failed to create cipher: %w", err)
 	}
 
 	gcm, err := cipher.NewGCM(block)
 	if err != nil {
 		zeroBytes(key)
-		return nil, fmt.Errorf("failed to create GCM: %w", err)
+		return nil, nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+
+	return gcm, key, nil
+}
+
+// EncryptReader wraps a reader to encrypt data on the fly in the chunked
+// format. Memory use is bounded by SSEChunkSize regardless of blob size.
+func (e *SSEEncryptor) EncryptReader(reader io.Reader, blobHash string) (*SSEEncryptingReader, error) {
+	gcm, key, err := e.newGCM(blobHash)
+	if err != nil {
+		return nil, err
 	}
 
-	// Generate nonce
+	// Generate base nonce
 	nonce := make([]byte, SSENonceSize)
 	if _, err := rand.Read(nonce); err != nil {
 		zeroBytes(key)
 		return nil, fmt.Errorf("failed to generate nonce: %w", err)
 	}
 
+	header := make([]byte, 0, SSEHeaderSize)
+	header = append(header, sseMagic...)
+	header = append(header, SSEFormatChunked)
+	header = append(header, nonce...)
+
 	return &SSEEncryptingReader{
-		reader:      reader,
-		gcm:         gcm,
-		nonce:       nonce,
-		key:         key,
-		nonceSent:   false,
-		buffer:      nil,
-		bufferIndex: 0,
-		done:        false,
+		reader:    bufio.NewReader(reader),
+		gcm:       gcm,
+		baseNonce: nonce,
+		key:       key,
+		chunk:     make([]byte, SSEChunkSize),
+		sealed:    make([]byte, 0, SSEChunkSize+SSETagSize),
+		pending:   header,
 	}, nil
 }
 
-// DecryptReader wraps a reader to decrypt data on the fly.
-// The input reader must provide data in the format: nonce || ciphertext || tag
+// DecryptReader wraps a reader to decrypt data on the fly. Chunked blobs are
+// decrypted one chunk at a time; legacy single-seal blobs can only be
+// authenticated as a whole and are buffered.
 func (e *SSEEncryptor) DecryptReader(reader io.Reader, blobHash string) (*SSEDecryptingReader, error) {
-	key, err := e.DeriveKey(blobHash)
+	gcm, key, err := e.newGCM(blobHash)
 	if err != nil {
 		return nil, err
 	}
 
 	return &SSEDecryptingReader{
-		reader:    reader,
+		reader:    bufio.NewReader(reader),
+		gcm:       gcm,
 		key:       key,
 		blobHash:  blobHash,
 		encryptor: e,
-		nonce:     nil,
-		buffer:    nil,
 	}, nil
 }
 
 // SSEEncryptingReader encrypts data as it's read.
 type SSEEncryptingReader struct {
-	reader      io.Reader
-	gcm         cipher.AEAD
-	nonce       []byte
-	key         []byte
-	nonceSent   bool
-	buffer      []byte
-	bufferIndex int
-	done        bool
+	reader    *bufio.Reader
+	gcm       cipher.AEAD
+	baseNonce []byte
+	key       []byte
+	chunk     []byte
+	sealed    []byte
+	chunkNum  uint64
+	pending   []byte
+	done      bool
 }
 
 // Read implements io.Reader for streaming encryption.
-// Note: For simplicity, this reads all data into memory.
-// For very large files, a chunked approach would be better.
 func (r *SSEEncryptingReader) Read(p []byte) (int, error) {
-	// First, send the nonce
-	if !r.nonceSent {
-		n := copy(p, r.nonce[r.bufferIndex:])
-		r.bufferIndex += n
-		if r.bufferIndex >= len(r.nonce) {
-			r.nonceSent = true
-			r.bufferIndex = 0
+	for len(r.pending) == 0 {
+		if r.done {
+			return 0, io.EOF
 		}
-		return n, nil
-	}
-
-	// If we have buffered encrypted data, send it
-	if r.buffer != nil && r.bufferIndex < len(r.buffer) {
-		n := copy(p, r.buffer[r.bufferIndex:])
-		r.bufferIndex += n
-		if r.bufferIndex >= len(r.buffer) {
-			// Clean up
-			zeroBytes(r.key)
-			return n, io.EOF
+		if err := r.sealNext(); err != nil {
+			return 0, err
 		}
-		return n, nil
 	}
 
-	if r.done {
-		return 0, io.EOF
-	}
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
 
-	// Read all remaining data and encrypt
-	plaintext, err := io.ReadAll(r.reader)
-	if err != nil {
-		return 0, err
+// sealNext encrypts the next chunk of the source into pending. A chunk is
+// final when the source ends within or right after it; an empty source
+// still produces one (empty) final chunk.
+func (r *SSEEncryptingReader) sealNext() error {
+	n, err := io.ReadFull(r.reader, r.chunk)
+	final := false
+	switch {
+	case err == io.EOF || err == io.ErrUnexpectedEOF:
+		final = true
+	case err != nil:
+		return fmt.Errorf("failed to read source: %w", err)
+	default:
+		if _, err := r.reader.Peek(1); err == io.EOF {
+			final = true
+		} else if err != nil {
+			return fmt.Errorf("failed to read source: %w", err)
+		}
 	}
 
-	r.buffer = r.gcm.Seal(nil, r.nonce, plaintext, nil)
-	r.bufferIndex = 0
-	r.done = true
+	nonce := sseChunkNonce(r.baseNonce, r.chunkNum)
+	r.pending = r.gcm.Seal(r.sealed[:0], nonce, r.chunk[:n], sseChunkAAD(r.chunkNum, final))
+	r.chunkNum++
 
-	n := copy(p, r.buffer)
-	r.bufferIndex = n
-	if r.bufferIndex >= len(r.buffer) {
+	if final {
+		r.done = true
 		zeroBytes(r.key)
-		return n, io.EOF
 	}
-	return n, nil
+	return nil
 }
 
 // Close cleans up resources.
@@ -293,56 +312,112 @@ func (r *SSEEncryptingReader) Close() error {
 
 // SSEDecryptingReader decrypts data as it's read.
 type SSEDecryptingReader struct {
-	reader      io.Reader
-	key         []byte
-	blobHash    string
-	encryptor   *SSEEncryptor
-	nonce       []byte
-	buffer      []byte
-	bufferIndex int
-	done        bool
+	reader    *bufio.Reader
+	gcm       cipher.AEAD
+	key       []byte
+	blobHash  string
+	encryptor *SSEEncryptor
+
+	started   bool
+	baseNonce []byte
+	chunk     []byte
+	opened    []byte
+	chunkNum  uint64
+	pending   []byte
+	done      bool
 }
 
 // Read implements io.Reader for streaming decryption.
-// Note: For simplicity, this reads all data into memory then decrypts.
 func (r *SSEDecryptingReader) Read(p []byte) (int, error) {
-	// If we have buffered decrypted data, send it
-	if r.buffer != nil && r.bufferIndex < len(r.buffer) {
-		n := copy(p, r.buffer[r.bufferIndex:])
-		r.bufferIndex += n
-		if r.bufferIndex >= len(r.buffer) {
-			return n, io.EOF
+	if !r.started {
+		if err := r.start(); err != nil {
+			return 0, err
 		}
-		return n, nil
 	}
 
-	if r.done {
-		return 0, io.EOF
+	for len(r.pending) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.openNext(); err != nil {
+			return 0, err
+		}
 	}
 
-	// Read all encrypted data
-	ciphertext, err := io.ReadAll(r.reader)
-	if err != nil {
-		return 0, err
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+// start reads the format header. Data without one is a legacy single-seal
+// blob, which is read and decrypted in full.
+func (r *SSEDecryptingReader) start() error {
+	r.started = true
+
+	header, err := r.reader.Peek(SSEHeaderSize)
+	if err != nil && err != io.EOF {
+		return fmt.Errorf("failed to read header: %w", err)
+	}
+	if !isSSEChunked(header) {
+		ciphertext, err := io.ReadAll(r.reader)
+		if err != nil {
+			return err
+		}
+		plaintext, err := r.encryptor.decryptSingleSeal(ciphertext, r.blobHash)
+		if err != nil {
+			return err
+		}
+		r.pending = plaintext
+		r.done = true
+		zeroBytes(r.key)
+		return nil
 	}
 
-	// Decrypt
-	plaintext, err := r.encryptor.DecryptBlob(ciphertext, r.blobHash)
-	if err != nil {
-		return 0, err
+	r.baseNonce = append([]byte(nil), header[len(sseMagic)+1:]...)
+	if _, err := r.reader.Discard(SSEHeaderSize); err != nil {
+		return fmt.Errorf("failed to read header: %w", err)
 	}
+	r.chunk = make([]byte, SSEChunkSize+SSETagSize)
+	r.opened = make([]byte, 0, SSEChunkSize)
+	return nil
+}
 
-	r.buffer = plaintext
-	r.bufferIndex = 0
-	r.done = true
-	zeroBytes(r.key)
+// openNext decrypts the next chunk into pending. Running out of data before
+// the final chunk means the blob was truncated.
+func (r *SSEDecryptingReader) openNext() error {
+	n, err := io.ReadFull(r.reader, r.chunk)
+	final := false
+	switch {
+	case err == io.EOF:
+		return ErrSSEInvalidData
+	case err == io.ErrUnexpectedEOF:
+		final = true
+	case err != nil:
+		return fmt.Errorf("failed to read chunk: %w", err)
+	default:
+		if _, err := r.reader.Peek(1); err == io.EOF {
+			final = true
+		} else if err != nil {
+			return fmt.Errorf("failed to read chunk: %w", err)
+		}
+	}
+	if n < SSETagSize {
+		return ErrSSEInvalidData
+	}
 
-	n := copy(p, r.buffer)
-	r.bufferIndex = n
-	if r.bufferIndex >= len(r.buffer) {
-		return n, io.EOF
+	nonce := sseChunkNonce(r.baseNonce, r.chunkNum)
+	plaintext, err := r.gcm.Open(r.opened[:0], nonce, r.chunk[:n], sseChunkAAD(r.chunkNum, final))
+	if err != nil {
+		return ErrSSEDecryptionFailed
 	}
-	return n, nil
+	r.chunkNum++
+	r.pending = plaintext
+
+	if final {
+		r.done = true
+		zeroBytes(r.key)
+	}
+	return nil
 }
 
 // Close cleans up resources.
@@ -351,6 +426,49 @@ func (r *SSEDecryptingReader) Close() error {
 	return nil
 }
 
+// isSSEChunked reports whether data starts with the chunked format header.
+func isSSEChunked(data []byte) bool {
+	return len(data) >= SSEHeaderSize &&
+		bytes.HasPrefix(data, sseMagic) &&
+		data[len(sseMagic)] == SSEFormatChunked
+}
+
+// sseChunkNonce derives the nonce of a chunk by XORing the chunk number into
+// the last 8 bytes of the base nonce.
+func sseChunkNonce(baseNonce []byte, chunkNum uint64) []byte {
+	nonce := make([]byte, SSENonceSize)
+	copy(nonce, baseNonce)
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], chunkNum)
+	for i := range counter {
+		nonce[SSENonceSize-8+i] ^= counter[i]
+	}
+	return nonce
+}
+
+// sseChunkAAD binds a chunk to its position and marks the last one, so
+// chunks cannot be reordered and the blob cannot be truncated unnoticed.
+func sseChunkAAD(chunkNum uint64, final bool) []byte {
+	aad := make([]byte, 9)
+	binary.BigEndian.PutUint64(aad, chunkNum)
+	if final {
+		aad[8] = 1
+	}
+	return aad
+}
+
+// SSENonce returns the base nonce of an encrypted blob, for either format.
+// It returns nil if data is too short to hold one.
+func SSENonce(data []byte) []byte {
+	if isSSEChunked(data) {
+		return data[len(sseMagic)+1 : SSEHeaderSize]
+	}
+	if len(data) < SSENonceSize {
+		return nil
+	}
+	return data[:SSENonceSize]
+}
+
 // zeroBytes zeros out a byte slice for security.
 func zeroBytes(b []byte) {
 	for i := range b {
@@ -359,16 +477,22 @@ func zeroBytes(b []byte) {
 }
 
 // CalculateEncryptedSize returns the size of encrypted data given plaintext size.
-// Encrypted format: nonce (12) + ciphertext (same as plaintext) + tag (16)
+// Encrypted format: header (19) + one tag (16) per started chunk, with at
+// least one chunk.
 func CalculateEncryptedSize(plaintextSize int64) int64 {
-	return int64(SSENonceSize) + plaintextSize + int64(SSETagSize)
+	chunks := (plaintextSize + SSEChunkSize - 1) / SSEChunkSize
+	if chunks == 0 {
+		chunks = 1
+	}
+	return SSEHeaderSize + plaintextSize + chunks*SSETagSize
 }
 
 // CalculatePlaintextSize returns the original size given encrypted size.
 func CalculatePlaintextSize(encryptedSize int64) int64 {
-	overhead := int64(SSENonceSize + SSETagSize)
-	if encryptedSize < overhead {
+	body := encryptedSize - SSEHeaderSize
+	if body < SSETagSize {
 		return 0
 	}
-	return encryptedSize - overhead
+	chunks := (body + SSEChunkSize + SSETagSize - 1) / (SSEChunkSize + SSETagSize)
+	return body - chunks*SSETagSize
 }
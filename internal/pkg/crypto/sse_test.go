@@ -0,0 +1,131 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSSEEncryptor(t *testing.T) *SSEEncryptor {
+	t.Helper()
+	e, err := NewSSEEncryptor(bytes.Repeat([]byte{0x42}, SSEKeySize))
+	require.NoError(t, err)
+	return e
+}
+
+func TestSSEEncryptor_ChunkedRoundTrip(t *testing.T) {
+	e := newTestSSEEncryptor(t)
+
+	sizes := []int{0, 1, SSEChunkSize - 1, SSEChunkSize, SSEChunkSize + 1, 3*SSEChunkSize + 5}
+	for _, size := range sizes {
+		plaintext := bytes.Repeat([]byte{0xab}, size)
+		hash := SHA256Hex(plaintext)
+
+		ciphertext, err := e.EncryptBlob(plaintext, hash)
+		require.NoError(t, err, "size %d", size)
+		assert.Equal(t, CalculateEncryptedSize(int64(size)), int64(len(ciphertext)), "size %d", size)
+		assert.Equal(t, int64(size), CalculatePlaintextSize(int64(len(ciphertext))), "size %d", size)
+
+		decrypted, err := e.DecryptBlob(ciphertext, hash)
+		require.NoError(t, err, "size %d", size)
+		assert.Equal(t, size, len(decrypted))
+		assert.True(t, bytes.Equal(plaintext, decrypted), "size %d", size)
+	}
+}
+
+func TestSSEEncryptor_StreamingReadersBoundMemory(t *testing.T) {
+	e := newTestSSEEncryptor(t)
+
+	plaintext := bytes.Repeat([]byte("stream"), SSEChunkSize)
+	hash := SHA256Hex(plaintext)
+
+	enc, err := e.EncryptReader(bytes.NewReader(plaintext), hash)
+	require.NoError(t, err)
+	dec, err := e.DecryptReader(enc, hash)
+	require.NoError(t, err)
+
+	// Each Read hands out at most one chunk
+	buf := make([]byte, 4*SSEChunkSize)
+	var got []byte
+	for {
+		n, err := dec.Read(buf)
+		assert.LessOrEqual(t, n, SSEChunkSize)
+		got = append(got, buf[:n]...)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+	}
+	assert.True(t, bytes.Equal(plaintext, got))
+}
+
+func TestSSEEncryptor_DecryptSingleSeal(t *testing.T) {
+	e := newTestSSEEncryptor(t)
+
+	plaintext := []byte("written by the single-seal encryptor")
+	hash := SHA256Hex(plaintext)
+
+	key, err := e.DeriveKey(hash)
+	require.NoError(t, err)
+	block, err := aes.NewCipher(key)
+	require.NoError(t, err)
+	gcm, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	nonce := bytes.Repeat([]byte{0x01}, SSENonceSize)
+	legacy := gcm.Seal(nonce, nonce, plaintext, nil)
+
+	decrypted, err := e.DecryptBlob(legacy, hash)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+
+	reader, err := e.DecryptReader(bytes.NewReader(legacy), hash)
+	require.NoError(t, err)
+	decrypted, err = io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+
+	assert.Equal(t, nonce, SSENonce(legacy))
+}
+
+func TestSSEEncryptor_DetectsTampering(t *testing.T) {
+	e := newTestSSEEncryptor(t)
+
+	plaintext := bytes.Repeat([]byte{0xcd}, 2*SSEChunkSize+10)
+	hash := SHA256Hex(plaintext)
+	ciphertext, err := e.EncryptBlob(plaintext, hash)
+	require.NoError(t, err)
+
+	sealedChunk := SSEChunkSize + SSETagSize
+	firstChunk := ciphertext[SSEHeaderSize : SSEHeaderSize+sealedChunk]
+	secondChunk := ciphertext[SSEHeaderSize+sealedChunk : SSEHeaderSize+2*sealedChunk]
+
+	swapped := append([]byte(nil), ciphertext[:SSEHeaderSize]...)
+	swapped = append(swapped, secondChunk...)
+	swapped = append(swapped, firstChunk...)
+	swapped = append(swapped, ciphertext[SSEHeaderSize+2*sealedChunk:]...)
+
+	flipped := append([]byte(nil), ciphertext...)
+	flipped[len(flipped)-1] ^= 0x01
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "truncated at chunk boundary", data: ciphertext[:SSEHeaderSize+2*sealedChunk]},
+		{name: "header only", data: ciphertext[:SSEHeaderSize]},
+		{name: "chunks reordered", data: swapped},
+		{name: "bit flipped", data: flipped},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := e.DecryptBlob(tt.data, hash)
+			assert.Error(t, err)
+		})
+	}
+}
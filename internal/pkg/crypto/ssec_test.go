@@ -0,0 +1,92 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ssecHeaders(key []byte) (string, string) {
+	sum := md5.Sum(key)
+	return base64.StdEncoding.EncodeToString(key), base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func TestParseSSECustomerKey(t *testing.T) {
+	key := bytes.Repeat([]byte{0x11}, SSEKeySize)
+	keyB64, md5B64 := ssecHeaders(key)
+
+	parsed, err := ParseSSECustomerKey(SSECAlgorithmAES256, keyB64, md5B64)
+	require.NoError(t, err)
+	assert.Equal(t, key, parsed.Key)
+	assert.Equal(t, md5B64, parsed.KeyMD5)
+	assert.True(t, parsed.Matches(md5B64))
+
+	_, err = ParseSSECustomerKey(SSECAlgorithmAES256, keyB64, "")
+	assert.ErrorIs(t, err, ErrSSECKeyMD5Mismatch)
+
+	_, err = ParseSSECustomerKey("aws:kms", keyB64, md5B64)
+	assert.ErrorIs(t, err, ErrSSECInvalidAlgorithm)
+
+	shortB64, shortMD5 := ssecHeaders(key[:16])
+	_, err = ParseSSECustomerKey(SSECAlgorithmAES256, shortB64, shortMD5)
+	assert.ErrorIs(t, err, ErrSSECInvalidKey)
+
+	_, err = ParseSSECustomerKey(SSECAlgorithmAES256, "not base64!", md5B64)
+	assert.ErrorIs(t, err, ErrSSECInvalidKey)
+
+	_, otherMD5 := ssecHeaders(bytes.Repeat([]byte{0x22}, SSEKeySize))
+	_, err = ParseSSECustomerKey(SSECAlgorithmAES256, keyB64, otherMD5)
+	assert.ErrorIs(t, err, ErrSSECKeyMD5Mismatch)
+}
+
+func TestSSECustomerKey_NeverFormatsKey(t *testing.T) {
+	key := bytes.Repeat([]byte{0x33}, SSEKeySize)
+	keyB64, md5B64 := ssecHeaders(key)
+
+	parsed, err := ParseSSECustomerKey(SSECAlgorithmAES256, keyB64, md5B64)
+	require.NoError(t, err)
+
+	for _, s := range []string{fmt.Sprint(parsed), fmt.Sprintf("%v", parsed), fmt.Sprintf("%#v", parsed)} {
+		assert.NotContains(t, s, keyB64)
+		assert.NotContains(t, s, string(key))
+		assert.Contains(t, s, md5B64)
+	}
+}
+
+func TestSSECEncryptor_RoundTrip(t *testing.T) {
+	key := bytes.Repeat([]byte{0x44}, SSEKeySize)
+	keyB64, md5B64 := ssecHeaders(key)
+	customerKey, err := ParseSSECustomerKey(SSECAlgorithmAES256, keyB64, md5B64)
+	require.NoError(t, err)
+
+	e, err := NewSSECEncryptor(customerKey)
+	require.NoError(t, err)
+
+	plaintext := bytes.Repeat([]byte("customer"), SSEChunkSize/4)
+	ciphertext, err := e.EncryptBlob(plaintext, "bucket/key")
+	require.NoError(t, err)
+
+	decrypted, err := e.DecryptBlob(ciphertext, "bucket/key")
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+
+	// Another customer key cannot decrypt
+	other, err := NewSSECEncryptor(&SSECustomerKey{Key: bytes.Repeat([]byte{0x55}, SSEKeySize)})
+	require.NoError(t, err)
+	_, err = other.DecryptBlob(ciphertext, "bucket/key")
+	assert.ErrorIs(t, err, ErrSSEDecryptionFailed)
+
+	// Neither can the master key path, even with identical key bytes
+	sse, err := NewSSEEncryptor(key)
+	require.NoError(t, err)
+	_, err = sse.DecryptBlob(ciphertext, "bucket/key")
+	assert.ErrorIs(t, err, ErrSSEDecryptionFailed)
+
+	_, err = NewSSECEncryptor(&SSECustomerKey{Key: key[:8]})
+	assert.ErrorIs(t, err, ErrSSECInvalidKey)
+}
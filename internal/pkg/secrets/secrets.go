@@ -0,0 +1,204 @@
+// Package secrets resolves secrets such as the encryption master key from
+// sources outside the configuration file.
+//
+// A source is written as "scheme:reference":
+//
+//	env:ALEXANDER_MASTER_KEY           the value of an environment variable
+//	file:/run/secrets/master_key       the contents of a file
+//	command:/usr/local/bin/fetch-key   the output of a command, e.g. a KMS or
+//	                                   secrets manager client
+//
+// Trailing whitespace is trimmed from file contents and command output.
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// Secret source errors
+var (
+	// ErrInvalidSource indicates a source is not of the form "scheme:reference"
+	// with a known scheme.
+	ErrInvalidSource = errors.New("invalid secret source: must be env:<name>, file:<path> or command:<command line>")
+
+	// ErrSecretNotFound indicates a source holds no secret.
+	ErrSecretNotFound = errors.New("secret not found")
+)
+
+// Provider resolves a secret.
+type Provider interface {
+	// Secret returns the secret. It is called at startup, so implementations
+	// may block on a remote service but should honour ctx.
+	Secret(ctx context.Context) ([]byte, error)
+
+	// String describes the source for error messages. It must not include
+	// the secret.
+	String() string
+}
+
+// Parse returns a cached provider for a "scheme:reference" source.
+func Parse(source string) (Provider, error) {
+	scheme, ref, ok := strings.Cut(source, ":")
+	if !ok || ref == "" {
+		return nil, ErrInvalidSource
+	}
+
+	var provider Provider
+	switch scheme {
+	case "env":
+		provider = EnvProvider{Name: ref}
+	case "file":
+		provider = FileProvider{Path: ref}
+	case "command":
+		args := strings.Fields(ref)
+		if len(args) == 0 {
+			return nil, ErrInvalidSource
+		}
+		provider = CommandProvider{Path: args[0], Args: args[1:]}
+	default:
+		return nil, ErrInvalidSource
+	}
+	return Cache(provider), nil
+}
+
+// ResolveKey resolves a key from provider and checks it is exactly size bytes.
+func ResolveKey(ctx context.Context, provider Provider, size int) ([]byte, error) {
+	key, err := provider.Secret(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve key from %s: %w", provider, err)
+	}
+	if len(key) != size {
+		return nil, fmt.Errorf("key from %s must be exactly %d bytes, got %d", provider, size, len(key))
+	}
+	return key, nil
+}
+
+// =============================================================================
+// Providers
+// =============================================================================
+
+// EnvProvider reads a secret from an environment variable.
+type EnvProvider struct {
+	Name string
+}
+
+// Secret implements Provider.
+func (p EnvProvider) Secret(ctx context.Context) ([]byte, error) {
+	value, ok := os.LookupEnv(p.Name)
+	if !ok || value == "" {
+		return nil, ErrSecretNotFound
+	}
+	return []byte(value), nil
+}
+
+// String implements Provider.
+func (p EnvProvider) String() string {
+	return "env:" + p.Name
+}
+
+// FileProvider reads a secret from a file, such as a mounted Docker or
+// Kubernetes secret.
+type FileProvider struct {
+	Path string
+}
+
+// Secret implements Provider.
+func (p FileProvider) Secret(ctx context.Context) ([]byte, error) {
+	data, err := os.ReadFile(p.Path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrSecretNotFound
+		}
+		return nil, err
+	}
+	data = bytes.TrimRight(data, " \t\r\n")
+	if len(data) == 0 {
+		return nil, ErrSecretNotFound
+	}
+	return data, nil
+}
+
+// String implements Provider.
+func (p FileProvider) String() string {
+	return "file:" + p.Path
+}
+
+// CommandProvider reads a secret from the standard output of a command. This
+// integrates external KMS and secrets manager clients without linking them in.
+type CommandProvider struct {
+	Path string
+	Args []string
+}
+
+// Secret implements Provider.
+func (p CommandProvider) Secret(ctx context.Context) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.Path, p.Args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	out = bytes.TrimRight(out, " \t\r\n")
+	if len(out) == 0 {
+		return nil, ErrSecretNotFound
+	}
+	return out, nil
+}
+
+// String implements Provider. The arguments are left out as they may
+// identify the secret more precisely than an operator wants logged.
+func (p CommandProvider) String() string {
+	return "command:" + p.Path
+}
+
+// =============================================================================
+// Caching
+// =============================================================================
+
+// cachedProvider resolves a secret once and serves it from memory afterwards.
+// Failures are not cached, so a later call retries.
+type cachedProvider struct {
+	provider Provider
+
+	mu     sync.Mutex
+	secret []byte
+}
+
+// Cache wraps provider so the secret is resolved only once.
+func Cache(provider Provider) Provider {
+	if _, ok := provider.(*cachedProvider); ok {
+		return provider
+	}
+	return &cachedProvider{provider: provider}
+}
+
+// Secret implements Provider.
+func (p *cachedProvider) Secret(ctx context.Context) ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.secret == nil {
+		secret, err := p.provider.Secret(ctx)
+		if err != nil {
+			return nil, err
+		}
+		p.secret = secret
+	}
+	return bytes.Clone(p.secret), nil
+}
+
+// String implements Provider.
+func (p *cachedProvider) String() string {
+	return p.provider.String()
+}
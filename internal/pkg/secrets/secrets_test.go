@@ -0,0 +1,119 @@
+package secrets
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testKey = "0123456789abcdef0123456789abcdef"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{source: "env:MASTER_KEY", want: "env:MASTER_KEY"},
+		{source: "file:/run/secrets/key", want: "file:/run/secrets/key"},
+		{source: "command:/usr/bin/fetch-key --id master", want: "command:/usr/bin/fetch-key"},
+	}
+	for _, tt := range tests {
+		provider, err := Parse(tt.source)
+		require.NoError(t, err, tt.source)
+		assert.Equal(t, tt.want, provider.String())
+	}
+
+	for _, source := range []string{"", "MASTER_KEY", "env:", "command: ", "vault:secret/key"} {
+		_, err := Parse(source)
+		assert.ErrorIs(t, err, ErrInvalidSource, source)
+	}
+}
+
+func TestEnvProvider(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv("ALEXANDER_TEST_MASTER_KEY", testKey)
+
+	provider, err := Parse("env:ALEXANDER_TEST_MASTER_KEY")
+	require.NoError(t, err)
+	key, err := ResolveKey(ctx, provider, 32)
+	require.NoError(t, err)
+	assert.Equal(t, []byte(testKey), key)
+
+	_, err = ResolveKey(ctx, EnvProvider{Name: "ALEXANDER_TEST_UNSET_KEY"}, 32)
+	require.ErrorIs(t, err, ErrSecretNotFound)
+	assert.Contains(t, err.Error(), "env:ALEXANDER_TEST_UNSET_KEY")
+}
+
+func TestFileProvider(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "master_key")
+	require.NoError(t, os.WriteFile(path, []byte(testKey+"\n"), 0o600))
+
+	// The trailing newline of the file is not part of the key
+	key, err := ResolveKey(ctx, FileProvider{Path: path}, 32)
+	require.NoError(t, err)
+	assert.Equal(t, []byte(testKey), key)
+
+	_, err = ResolveKey(ctx, FileProvider{Path: filepath.Join(t.TempDir(), "missing")}, 32)
+	require.ErrorIs(t, err, ErrSecretNotFound)
+}
+
+func TestResolveKey_WrongLength(t *testing.T) {
+	t.Setenv("ALEXANDER_TEST_MASTER_KEY", "too-short")
+
+	_, err := ResolveKey(context.Background(), EnvProvider{Name: "ALEXANDER_TEST_MASTER_KEY"}, 32)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "must be exactly 32 bytes, got 9")
+	assert.NotContains(t, err.Error(), "too-short", "error leaks the key")
+}
+
+func TestCommandProvider_Fails(t *testing.T) {
+	_, err := ResolveKey(context.Background(), CommandProvider{Path: "sh", Args: []string{"-c", "echo access denied >&2; exit 3"}}, 32)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "command:sh")
+	assert.Contains(t, err.Error(), "access denied")
+}
+
+// countingProvider counts how often the secret is resolved.
+type countingProvider struct {
+	calls int
+	err   error
+}
+
+func (p *countingProvider) Secret(ctx context.Context) ([]byte, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return []byte(testKey), nil
+}
+
+func (p *countingProvider) String() string {
+	return "counting"
+}
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+
+	inner := &countingProvider{}
+	cached := Cache(inner)
+	for i := 0; i < 3; i++ {
+		key, err := cached.Secret(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []byte(testKey), key)
+	}
+	assert.Equal(t, 1, inner.calls)
+
+	// Failures are retried
+	failing := &countingProvider{err: ErrSecretNotFound}
+	cached = Cache(failing)
+	_, err := cached.Secret(ctx)
+	require.ErrorIs(t, err, ErrSecretNotFound)
+	_, err = cached.Secret(ctx)
+	require.ErrorIs(t, err, ErrSecretNotFound)
+	assert.Equal(t, 2, failing.calls)
+}
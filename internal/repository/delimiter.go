@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+)
+
+// ListWithDelimiter lists one page of the latest objects in a bucket like
+// ObjectRepository.List, rolling up keys that contain opts.Delimiter after
+// opts.Prefix into CommonPrefixes. Each common prefix counts as one key
+// towards MaxKeys and is returned once, however many keys it covers.
+//
+// A key equal to the prefix, or without the delimiter after it, is listed
+// in Objects. A common prefix ends a page like a key does, so
+// NextContinuationToken may be a common prefix; passing it back as
+// StartAfter continues after all of its keys.
+//
+// opts.Prefix and opts.StartAfter must be normalized for the bucket.
+func ListWithDelimiter(ctx context.Context, repo ObjectRepository, bucket *domain.Bucket, opts ObjectListOptions) (*ObjectListResult, error) {
+	if opts.Delimiter == "" {
+		return repo.List(ctx, bucket.ID, opts)
+	}
+
+	maxKeys := opts.MaxKeys
+	if maxKeys <= 0 {
+		maxKeys = 1000
+	}
+
+	result := &ObjectListResult{}
+
+	// group is the normalized common prefix returned last. A marker that is
+	// itself a common prefix was returned by the previous page.
+	var group string
+	if prefix, ok := commonPrefix(opts.StartAfter, len(opts.Prefix), opts.Delimiter); ok && prefix == opts.StartAfter {
+		group = prefix
+	}
+
+	var last string
+	cursor := opts.StartAfter
+	for {
+		// Keys under the common prefix returned last are skipped with one
+		// query rather than read page by page. Keys are ordered bytewise, so
+		// they all sort before the prefix followed by the largest code point.
+		if skip := group + maxKeyRune; group != "" && strings.HasPrefix(cursor, group) && skip > cursor {
+			cursor = skip
+		}
+
+		page, err := repo.List(ctx, bucket.ID, ObjectListOptions{
+			Prefix:     opts.Prefix,
+			StartAfter: cursor,
+			MaxKeys:    maxKeys,
+			Snapshot:   opts.Snapshot,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, obj := range page.Objects {
+			cursor = bucket.NormalizeKey(obj.Key)
+
+			prefix, grouped := commonPrefix(obj.Key, len(opts.Prefix), opts.Delimiter)
+			if grouped && bucket.NormalizeKey(prefix) == group {
+				continue
+			}
+
+			if result.KeyCount == maxKeys {
+				result.IsTruncated = true
+				result.NextContinuationToken = last
+				return result, nil
+			}
+
+			if grouped {
+				result.CommonPrefixes = append(result.CommonPrefixes, prefix)
+				group = bucket.NormalizeKey(prefix)
+				last = prefix
+			} else {
+				result.Objects = append(result.Objects, obj)
+				last = obj.Key
+			}
+			result.KeyCount++
+		}
+
+		if !page.IsTruncated {
+			return result, nil
+		}
+	}
+}
+
+// ListUploadsWithDelimiter lists one page of the in-progress multipart
+// uploads of a bucket like MultipartUploadRepository.List, rolling up keys
+// that contain opts.Delimiter after opts.Prefix into CommonPrefixes. Each
+// common prefix counts as one upload towards MaxUploads and is returned once.
+//
+// A common prefix ends a page like an upload does, with NextKeyMarker set to
+// the prefix and no NextUploadIDMarker; passing it back as KeyMarker
+// continues after all of its uploads.
+func ListUploadsWithDelimiter(ctx context.Context, repo MultipartUploadRepository, bucketID int64, opts MultipartListOptions) (*MultipartListResult, error) {
+	if opts.Delimiter == "" {
+		return repo.List(ctx, bucketID, opts)
+	}
+
+	maxUploads := opts.MaxUploads
+	if maxUploads <= 0 {
+		maxUploads = 1000
+	}
+
+	result := &MultipartListResult{}
+
+	var group string
+	if prefix, ok := commonPrefix(opts.KeyMarker, len(opts.Prefix), opts.Delimiter); ok && prefix == opts.KeyMarker {
+		group = prefix
+	}
+
+	var count int
+	var lastKey, lastUploadID string
+	keyMarker, uploadIDMarker := opts.KeyMarker, opts.UploadIDMarker
+	for {
+		// Uploads under the common prefix returned last are skipped with one
+		// query, as in ListWithDelimiter
+		if skip := group + maxKeyRune; group != "" && strings.HasPrefix(keyMarker, group) && skip > keyMarker {
+			keyMarker, uploadIDMarker = skip, ""
+		}
+
+		page, err := repo.List(ctx, bucketID, MultipartListOptions{
+			Prefix:         opts.Prefix,
+			KeyMarker:      keyMarker,
+			UploadIDMarker: uploadIDMarker,
+			MaxUploads:     maxUploads,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, upload := range page.Uploads {
+			keyMarker, uploadIDMarker = upload.Key, upload.UploadID
+
+			prefix, grouped := commonPrefix(upload.Key, len(opts.Prefix), opts.Delimiter)
+			if grouped && prefix == group {
+				continue
+			}
+
+			if count == maxUploads {
+				result.IsTruncated = true
+				result.NextKeyMarker = lastKey
+				result.NextUploadIDMarker = lastUploadID
+				return result, nil
+			}
+
+			if grouped {
+				result.CommonPrefixes = append(result.CommonPrefixes, prefix)
+				group = prefix
+				lastKey, lastUploadID = prefix, ""
+			} else {
+				result.Uploads = append(result.Uploads, upload)
+				lastKey, lastUploadID = upload.Key, upload.UploadID
+			}
+			count++
+		}
+
+		if !page.IsTruncated {
+			return result, nil
+		}
+	}
+}
+
+// maxKeyRune is the largest code point, see ListWithDelimiter.
+const maxKeyRune = "\U0010FFFF"
+
+// commonPrefix returns the part of key up to and including the first
+// delimiter after its first prefixLen bytes. ok is false when the key has
+// no delimiter there.
+func commonPrefix(key string, prefixLen int, delimiter string) (string, bool) {
+	if len(key) < prefixLen {
+		return "", false
+	}
+	i := strings.Index(key[prefixLen:], delimiter)
+	if i < 0 {
+		return "", false
+	}
+	return key[:prefixLen+i+len(delimiter)], true
+}
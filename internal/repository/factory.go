@@ -9,16 +9,22 @@ import (
 	"github.com/rs/zerolog"
 
 	"github.com/prn-tf/alexander-storage/internal/config"
+	"github.com/prn-tf/alexander-storage/internal/storage"
 )
 
 // Repositories holds all repository instances.
 type Repositories struct {
-	User      UserRepository
-	AccessKey AccessKeyRepository
-	Bucket    BucketRepository
-	Object    ObjectRepository
-	Blob      BlobRepository
-	Multipart MultipartUploadRepository
+	User               UserRepository
+	AccessKey          AccessKeyRepository
+	Bucket             BucketRepository
+	Object             ObjectRepository
+	Blob               BlobRepository
+	Multipart          MultipartUploadRepository
+	Lifecycle          LifecycleRepository
+	BucketPolicy       BucketPolicyRepository
+	BucketCORS         BucketCORSRepository
+	BucketNotification BucketNotificationRepository
+	DeltaChain         storage.DeltaChainStore
 }
 
 // DatabaseHealth is an interface for database health checks.
@@ -110,6 +110,32 @@ type BucketRepository interface {
 	// UpdateACL updates the ACL of a bucket.
 	UpdateACL(ctx context.Context, id int64, acl domain.BucketACL) error
 
+	// UpdateObjectOwnership updates the object ownership setting of a bucket.
+	UpdateObjectOwnership(ctx context.Context, id int64, ownership domain.ObjectOwnership) error
+
+	// UpdateState updates the lifecycle state of a bucket.
+	UpdateState(ctx context.Context, id int64, state domain.BucketState) error
+
+	// UpdateMaxVersionsPerKey updates the per-key version limit of a bucket.
+	UpdateMaxVersionsPerKey(ctx context.Context, id int64, maxVersions int) error
+
+	// UpdateCaseInsensitiveKeys updates whether a bucket matches keys case-insensitively.
+	UpdateCaseInsensitiveKeys(ctx context.Context, id int64, enabled bool) error
+
+	// UpdateContentTypePolicy replaces the content-type policy of a bucket (nil clears it).
+	UpdateContentTypePolicy(ctx context.Context, id int64, policy *domain.ContentTypePolicy) error
+
+	// UpdateRequireEncryption updates whether a bucket rejects uploads that do
+	// not request server-side encryption.
+	UpdateRequireEncryption(ctx context.Context, id int64, required bool) error
+
+	// EnableObjectLock turns on object lock for a bucket. It cannot be turned off.
+	EnableObjectLock(ctx context.Context, id int64) error
+
+	// UpdateDefaultRetention replaces the retention applied to new object
+	// versions of a bucket (nil clears it).
+	UpdateDefaultRetention(ctx context.Context, id int64, retention *domain.DefaultRetention) error
+
 	// Delete deletes a bucket by ID.
 	Delete(ctx context.Context, id int64) error
 
@@ -119,6 +145,11 @@ type BucketRepository interface {
 	// ExistsByName checks if a bucket with the given name exists.
 	ExistsByName(ctx context.Context, name string) (bool, error)
 
+	// LockName blocks until it holds an exclusive lock on a bucket name,
+	// serializing creation and deletion of the same name across instances.
+	// The returned function releases the lock.
+	LockName(ctx context.Context, name string) (unlock func(), err error)
+
 	// IsEmpty checks if a bucket contains any objects.
 	IsEmpty(ctx context.Context, id int64) (bool, error)
 
@@ -159,6 +190,11 @@ type BlobRepository interface {
 	// Should only be called when ref_count is 0.
 	Delete(ctx context.Context, contentHash string) error
 
+	// IsReferenced reports whether any object version, including soft-deleted
+	// ones, or any multipart upload part references the blob, whatever its
+	// ref_count. Garbage collection checks it before deleting content.
+	IsReferenced(ctx context.Context, contentHash string) (bool, error)
+
 	// ListOrphans returns blobs with ref_count = 0 that are older than the grace period.
 	// Used by garbage collection.
 	ListOrphans(ctx context.Context, gracePeriod time.Duration, limit int) ([]*domain.Blob, error)
@@ -191,6 +227,11 @@ type BlobRepository interface {
 	// ListAll returns all blobs up to the limit.
 	// Used for encryption status reporting.
 	ListAll(ctx context.Context, limit int) ([]*domain.Blob, error)
+
+	// ListAfter returns up to limit blobs ordered by content hash, starting
+	// after afterHash (from the first blob when empty).
+	// Used to walk every blob, e.g. to backfill a new storage backend.
+	ListAfter(ctx context.Context, afterHash string, limit int) ([]*domain.Blob, error)
 }
 
 // =============================================================================
@@ -198,6 +239,8 @@ type BlobRepository interface {
 // =============================================================================
 
 // ObjectRepository defines the interface for object data access.
+// Keys passed to lookups, and list prefixes and markers, are matched against
+// the normalized key (see domain.Bucket.NormalizeKey); callers normalize them.
 type ObjectRepository interface {
 	// Create creates a new object.
 	Create(ctx context.Context, obj *domain.Object) error
@@ -217,10 +260,18 @@ type ObjectRepository interface {
 	// ListVersions returns all versions of objects in a bucket.
 	ListVersions(ctx context.Context, bucketID int64, opts ObjectListOptions) (*ObjectVersionListResult, error)
 
+	// ListSnapshot returns the current point in time, for listings whose
+	// pages must all be read at the same point.
+	ListSnapshot(ctx context.Context) (*ListSnapshot, error)
+
 	// ListExpiredObjects returns latest objects older than cutoff, with optional prefix.
 	// Used by lifecycle service for expiration processing.
 	ListExpiredObjects(ctx context.Context, bucketID int64, prefix string, olderThan time.Time, limit int) ([]*domain.Object, error)
 
+	// ListPastExpiry returns latest objects, across all buckets, whose own
+	// expiration time is at or before now, soonest first.
+	ListPastExpiry(ctx context.Context, now time.Time, limit int) ([]*domain.Object, error)
+
 	// Update updates an existing object.
 	Update(ctx context.Context, obj *domain.Object) error
 
@@ -228,11 +279,38 @@ type ObjectRepository interface {
 	// Used when creating a new version.
 	MarkNotLatest(ctx context.Context, bucketID int64, key string) error
 
-	// Delete hard-deletes an object by ID.
+	// Delete soft-deletes an object by ID.
+	// The row is kept until Purge removes it after the retention period.
 	Delete(ctx context.Context, id int64) error
 
-	// DeleteAllVersions deletes all versions of an object.
-	DeleteAllVersions(ctx context.Context, bucketID int64, key string) error
+	// DeleteAllVersions soft-deletes up to limit live versions of an object,
+	// including delete markers, and returns how many were deleted. Callers
+	// repeat it until it returns fewer than limit so that no single statement
+	// touches an unbounded number of rows.
+	DeleteAllVersions(ctx context.Context, bucketID int64, key string, limit int) (int64, error)
+
+	// ListAfterID returns up to limit live object versions in a bucket,
+	// including delete markers, whose ID is greater than afterID, in ID order.
+	// It is the keyset page behind ObjectIterator.
+	ListAfterID(ctx context.Context, bucketID int64, afterID int64, limit int) ([]*domain.Object, error)
+
+	// ListKeyVersions returns all live versions of an object, including
+	// delete markers, newest first.
+	ListKeyVersions(ctx context.Context, bucketID int64, key string) ([]*domain.Object, error)
+
+	// GetLatestDeleted retrieves the most recently soft-deleted version of an object.
+	GetLatestDeleted(ctx context.Context, bucketID int64, key string) (*domain.Object, error)
+
+	// ListSoftDeleted returns objects soft-deleted before the given time, oldest first.
+	// Used by the garbage collector to purge objects past their retention.
+	ListSoftDeleted(ctx context.Context, deletedBefore time.Time, limit int) ([]*domain.Object, error)
+
+	// Undelete restores a soft-deleted object.
+	Undelete(ctx context.Context, id int64) error
+
+	// Purge permanently removes a soft-deleted object.
+	// Returns ErrObjectNotFound if the object does not exist or is not deleted.
+	Purge(ctx context.Context, id int64) error
 
 	// CountByBucket returns the number of objects in a bucket.
 	CountByBucket(ctx context.Context, bucketID int64) (int64, error)
@@ -240,6 +318,67 @@ type ObjectRepository interface {
 	// GetContentHashForVersion retrieves the content hash for a specific version.
 	// Used for ref_count management.
 	GetContentHashForVersion(ctx context.Context, bucketID int64, key string, versionID uuid.UUID) (*string, error)
+
+	// GetTags returns the tag set of an object version (empty if untagged).
+	GetTags(ctx context.Context, objectID int64) (map[string]string, error)
+
+	// PutTags replaces the tag set of an object version.
+	// An empty set removes all tags.
+	PutTags(ctx context.Context, objectID int64, tags map[string]string) error
+
+	// GetLock returns the retention and legal hold of an object version
+	// (the zero lock if it has neither).
+	GetLock(ctx context.Context, objectID int64) (*domain.ObjectLock, error)
+
+	// PutLock replaces the retention and legal hold of an object version.
+	PutLock(ctx context.Context, objectID int64, lock *domain.ObjectLock) error
+}
+
+// ObjectBatchWriter is implemented by object repositories that can store the
+// metadata of many new objects in a single transaction.
+type ObjectBatchWriter interface {
+	// CreateBatch applies every write in one transaction: the blob reference
+	// is taken, the version the write replaces is soft-deleted, the key's
+	// previous latest version is demoted and the object is created. Each
+	// write runs under its own savepoint, so a write that fails is rolled
+	// back alone and reports its error in Err while the others commit. The
+	// returned error is set only when the transaction itself fails, in which
+	// case no write was stored.
+	CreateBatch(ctx context.Context, writes []*ObjectWrite) error
+}
+
+// ReplaceMode selects the existing version an ObjectWrite replaces.
+type ReplaceMode int
+
+const (
+	// ReplaceNone keeps every existing version (versioning enabled).
+	ReplaceNone ReplaceMode = iota
+
+	// ReplaceNullVersion replaces the null version (versioning suspended).
+	ReplaceNullVersion
+
+	// ReplaceLatest replaces the current object (versioning never enabled).
+	ReplaceLatest
+)
+
+// ObjectWrite is a new object stored by ObjectBatchWriter.CreateBatch.
+type ObjectWrite struct {
+	// Object is the object to create. Its blob must already be in storage.
+	Object *domain.Object
+
+	// StoredSize and StoragePath describe the blob for its metadata row.
+	StoredSize  int64
+	StoragePath string
+
+	// Replace selects the version the write replaces.
+	Replace ReplaceMode
+
+	// Replaced is set to the soft-deleted version the write replaced, if any.
+	// Only its ID and ContentHash are filled in.
+	Replaced *domain.Object
+
+	// Err is set when this write failed and was rolled back.
+	Err error
 }
 
 // ObjectListOptions contains options for listing objects.
@@ -253,11 +392,37 @@ type ObjectListOptions struct {
 	// StartAfter lists objects after this key (for pagination).
 	StartAfter string
 
+	// VersionIDMarker lists the versions of StartAfter older than this
+	// version before moving on to later keys (ListVersions only). It is the
+	// canonical UUID string of the version.
+	VersionIDMarker string
+
 	// ContinuationToken for pagination (opaque token from previous response).
 	ContinuationToken string
 
 	// MaxKeys is the maximum number of keys to return.
 	MaxKeys int
+
+	// Snapshot, if set, lists the objects as they were when the snapshot
+	// was taken rather than as they are now (List only).
+	Snapshot *ListSnapshot
+}
+
+// ListSnapshot is a point in time a listing is read at, so writes during a
+// multi-page scan neither add, remove nor replace keys in later pages.
+//
+// A version is part of the snapshot if it was created before it and not
+// deleted before it; the newest such version of each key is listed unless it
+// is a delete marker. Deleted versions are only kept for the soft-delete
+// retention period, so a scan must finish within it to stay consistent.
+type ListSnapshot struct {
+	// MaxID is the largest object row ID when the snapshot was taken.
+	MaxID int64
+
+	// TakenAt is when the snapshot was taken. It is read before MaxID, so a
+	// version replaced in between is seen along with its replacement, of
+	// which only the newer one is listed.
+	TakenAt time.Time
 }
 
 // ObjectListResult contains the result of a list objects operation.
@@ -323,6 +488,9 @@ type MultipartUploadRepository interface {
 	// DeleteExpired deletes expired multipart uploads.
 	DeleteExpired(ctx context.Context) (int64, error)
 
+	// CountInProgress returns the number of in-progress multipart uploads.
+	CountInProgress(ctx context.Context) (int64, error)
+
 	// --- Part operations ---
 
 	// CreatePart creates a new upload part.
@@ -339,6 +507,12 @@ type MultipartUploadRepository interface {
 
 	// GetPartsForCompletion returns parts in order for completing the upload.
 	GetPartsForCompletion(ctx context.Context, uploadID uuid.UUID, partNumbers []int) ([]*domain.UploadPart, error)
+
+	// ListInProgressParts returns the parts of every in-progress upload.
+	ListInProgressParts(ctx context.Context) ([]*domain.UploadPart, error)
+
+	// DeletePart deletes a single part.
+	DeletePart(ctx context.Context, uploadID uuid.UUID, partNumber int) error
 }
 
 // MultipartListOptions contains options for listing multipart uploads.
@@ -524,4 +698,65 @@ type LifecycleRepository interface {
 	// ListAllEnabled returns all enabled lifecycle rules across all buckets.
 	// Used by the lifecycle service scheduler.
 	ListAllEnabled(ctx context.Context) ([]*domain.LifecycleRule, error)
+
+	// ListTransitionCandidates returns latest objects older than cutoff, with
+	// optional prefix, that were not transitioned to any of the given classes.
+	// Used by the lifecycle service for transition processing.
+	ListTransitionCandidates(ctx context.Context, bucketID int64, prefix string, olderThan time.Time, satisfiedBy []domain.TransitionClass, limit int) ([]*domain.Object, error)
+
+	// MarkTransitioned records that an object was moved to a storage class.
+	MarkTransitioned(ctx context.Context, objectID int64, class domain.TransitionClass) error
+}
+
+// =============================================================================
+// Bucket Policy Repository
+// =============================================================================
+
+// BucketPolicyRepository defines the interface for bucket policy data access.
+type BucketPolicyRepository interface {
+	// Get retrieves the policy of a bucket.
+	// Returns ErrNotFound if the bucket has no policy.
+	Get(ctx context.Context, bucketID int64) (*domain.BucketPolicy, error)
+
+	// Put creates or replaces the policy of a bucket.
+	Put(ctx context.Context, policy *domain.BucketPolicy) error
+
+	// Delete removes the policy of a bucket. Removing a missing policy is not an error.
+	Delete(ctx context.Context, bucketID int64) error
+}
+
+// =============================================================================
+// Bucket CORS Repository
+// =============================================================================
+
+// BucketCORSRepository defines the interface for bucket CORS configuration data access.
+type BucketCORSRepository interface {
+	// Get retrieves the CORS configuration of a bucket.
+	// Returns ErrNotFound if the bucket has no CORS configuration.
+	Get(ctx context.Context, bucketID int64) (*domain.BucketCORS, error)
+
+	// Put creates or replaces the CORS configuration of a bucket.
+	Put(ctx context.Context, cors *domain.BucketCORS) error
+
+	// Delete removes the CORS configuration of a bucket. Removing a missing
+	// configuration is not an error.
+	Delete(ctx context.Context, bucketID int64) error
+}
+
+// =============================================================================
+// Bucket Notification Repository
+// =============================================================================
+
+// BucketNotificationRepository defines the interface for bucket notification configuration data access.
+type BucketNotificationRepository interface {
+	// Get retrieves the notification configuration of a bucket.
+	// Returns ErrNotFound if the bucket has no notification configuration.
+	Get(ctx context.Context, bucketID int64) (*domain.BucketNotification, error)
+
+	// Put creates or replaces the notification configuration of a bucket.
+	Put(ctx context.Context, notification *domain.BucketNotification) error
+
+	// Delete removes the notification configuration of a bucket. Removing a
+	// missing configuration is not an error.
+	Delete(ctx context.Context, bucketID int64) error
 }
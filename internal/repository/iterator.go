@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+)
+
+// DefaultIteratorBatchSize is the page size used when none is given.
+const DefaultIteratorBatchSize = 500
+
+// ObjectIterator walks every live object version in a bucket, delete markers
+// included, in ID order. It pages with ListAfterID, so memory use is bounded
+// by the batch size and objects created while iterating are picked up if
+// their ID is past the cursor.
+//
+// Maintenance jobs (GC, lifecycle, reconciliation, re-encryption) should use
+// it instead of paging by hand:
+//
+//	it := repository.NewObjectIterator(objectRepo, bucket.ID, 0, 0)
+//	for it.Next(ctx) {
+//		for _, obj := range it.Batch() {
+//			...
+//		}
+//	}
+//	if err := it.Err(); err != nil {
+//		// resume later from it.Cursor()
+//	}
+type ObjectIterator struct {
+	repo      ObjectRepository
+	bucketID  int64
+	batchSize int
+
+	cursor int64
+	batch  []*domain.Object
+	done   bool
+	err    error
+}
+
+// NewObjectIterator creates an iterator over the bucket's objects with an ID
+// greater than afterID (0 starts at the beginning). A batchSize of 0 or less
+// uses DefaultIteratorBatchSize.
+func NewObjectIterator(repo ObjectRepository, bucketID, afterID int64, batchSize int) *ObjectIterator {
+	if batchSize <= 0 {
+		batchSize = DefaultIteratorBatchSize
+	}
+	return &ObjectIterator{
+		repo:      repo,
+		bucketID:  bucketID,
+		batchSize: batchSize,
+		cursor:    afterID,
+	}
+}
+
+// Next loads the next batch. It returns false when the bucket is exhausted,
+// the context is canceled or a query fails; Err tells these apart.
+// The cursor advances only when a batch is returned, so a canceled iteration
+// can be resumed from Cursor without skipping or repeating objects.
+func (it *ObjectIterator) Next(ctx context.Context) bool {
+	it.batch = nil
+	if it.done || it.err != nil {
+		return false
+	}
+
+	if err := ctx.Err(); err != nil {
+		it.err = err
+		return false
+	}
+
+	batch, err := it.repo.ListAfterID(ctx, it.bucketID, it.cursor, it.batchSize)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	if len(batch) < it.batchSize {
+		it.done = true
+	}
+	if len(batch) == 0 {
+		return false
+	}
+
+	it.batch = batch
+	it.cursor = batch[len(batch)-1].ID
+	return true
+}
+
+// Batch returns the objects loaded by the last successful Next.
+func (it *ObjectIterator) Batch() []*domain.Object {
+	return it.batch
+}
+
+// Cursor returns the ID of the last object handed out. Passing it as afterID
+// to NewObjectIterator continues where this iterator stopped.
+func (it *ObjectIterator) Cursor() int64 {
+	return it.cursor
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *ObjectIterator) Err() error {
+	return it.err
+}
+
+// ForEachObject calls fn for every live object version in the bucket, in ID
+// order. It stops at the first error returned by fn and checks ctx between
+// objects, returning ctx.Err() on cancellation.
+func ForEachObject(ctx context.Context, repo ObjectRepository, bucketID int64, batchSize int, fn func(*domain.Object) error) error {
+	it := NewObjectIterator(repo, bucketID, 0, batchSize)
+	for it.Next(ctx) {
+		for _, obj := range it.Batch() {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := fn(obj); err != nil {
+				return err
+			}
+		}
+	}
+	return it.Err()
+}
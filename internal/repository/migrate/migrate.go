@@ -0,0 +1,151 @@
+// Package migrate applies versioned SQL schema migrations.
+//
+// Migrations are files named NNNNNN_description.up.sql (the golang-migrate
+// layout). The Runner applies every migration newer than the version recorded
+// by the database driver, in order, one transaction per migration.
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// Migration is a single versioned schema change.
+type Migration struct {
+	Version int
+	Name    string
+	UpSQL   string
+}
+
+// Driver applies migrations to one database and records the applied versions.
+type Driver interface {
+	// EnsureVersionTable creates the version bookkeeping table if needed.
+	EnsureVersionTable(ctx context.Context) error
+
+	// Version returns the latest applied version, or 0 for a fresh database.
+	Version(ctx context.Context) (int, error)
+
+	// Apply runs the migration and records its version atomically.
+	Apply(ctx context.Context, m Migration) error
+}
+
+// Load reads the up migrations in dir of fsys, ordered by version.
+func Load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	var migrations []Migration
+	seen := make(map[int]string)
+	for _, entry := range entries {
+		name := entry.Name()
+		base, ok := strings.CutSuffix(name, ".up.sql")
+		if entry.IsDir() || !ok {
+			continue
+		}
+
+		versionStr, description, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("invalid migration file name: %s", name)
+		}
+		version, err := strconv.Atoi(versionStr)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration version: %s", name)
+		}
+		if other, ok := seen[version]; ok {
+			return nil, fmt.Errorf("duplicate migration version %d: %s and %s", version, other, name)
+		}
+		seen[version] = name
+
+		upSQL, err := fs.ReadFile(fsys, path.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", name, err)
+		}
+
+		migrations = append(migrations, Migration{
+			Version: version,
+			Name:    description,
+			UpSQL:   string(upSQL),
+		})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Runner applies pending migrations through a Driver.
+type Runner struct {
+	driver     Driver
+	migrations []Migration
+	logger     zerolog.Logger
+}
+
+// NewRunner creates a migration runner. migrations must be ordered by version,
+// as returned by Load.
+func NewRunner(driver Driver, migrations []Migration, logger zerolog.Logger) *Runner {
+	return &Runner{
+		driver:     driver,
+		migrations: migrations,
+		logger:     logger.With().Str("component", "migrate").Logger(),
+	}
+}
+
+// Latest returns the version of the newest known migration.
+func (r *Runner) Latest() int {
+	if len(r.migrations) == 0 {
+		return 0
+	}
+	return r.migrations[len(r.migrations)-1].Version
+}
+
+// Version returns the version currently recorded in the database.
+func (r *Runner) Version(ctx context.Context) (int, error) {
+	if err := r.driver.EnsureVersionTable(ctx); err != nil {
+		return 0, fmt.Errorf("failed to create migrations table: %w", err)
+	}
+	return r.driver.Version(ctx)
+}
+
+// Up applies every migration newer than the recorded version and returns the
+// number applied. Running it again on an up-to-date database is a no-op.
+func (r *Runner) Up(ctx context.Context) (int, error) {
+	current, err := r.Version(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get current migration version: %w", err)
+	}
+
+	// A database newer than this binary is left alone rather than guessed at
+	if current > r.Latest() {
+		r.logger.Warn().
+			Int("current_version", current).
+			Int("latest_version", r.Latest()).
+			Msg("database schema is newer than this build")
+		return 0, nil
+	}
+
+	r.logger.Info().Int("current_version", current).Int("latest_version", r.Latest()).Msg("checking migrations")
+
+	applied := 0
+	for _, m := range r.migrations {
+		if m.Version <= current {
+			continue
+		}
+
+		if err := r.driver.Apply(ctx, m); err != nil {
+			return applied, fmt.Errorf("failed to apply migration %d (%s): %w", m.Version, m.Name, err)
+		}
+		applied++
+
+		r.logger.Info().Int("version", m.Version).Str("name", m.Name).Msg("applied migration")
+	}
+
+	return applied, nil
+}
@@ -0,0 +1,72 @@
+package migrate
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memDriver records applied migrations in memory.
+type memDriver struct {
+	applied []int
+}
+
+func (d *memDriver) EnsureVersionTable(context.Context) error { return nil }
+
+func (d *memDriver) Version(context.Context) (int, error) {
+	if len(d.applied) == 0 {
+		return 0, nil
+	}
+	return d.applied[len(d.applied)-1], nil
+}
+
+func (d *memDriver) Apply(_ context.Context, m Migration) error {
+	d.applied = append(d.applied, m.Version)
+	return nil
+}
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"sql/000002_second.up.sql":   {Data: []byte("SELECT 2;")},
+		"sql/000002_second.down.sql": {Data: []byte("SELECT -2;")},
+		"sql/000001_first.up.sql":    {Data: []byte("SELECT 1;")},
+		"sql/README.md":              {Data: []byte("docs")},
+	}
+
+	migrations, err := Load(fsys, "sql")
+	require.NoError(t, err)
+	assert.Equal(t, []Migration{
+		{Version: 1, Name: "first", UpSQL: "SELECT 1;"},
+		{Version: 2, Name: "second", UpSQL: "SELECT 2;"},
+	}, migrations)
+
+	fsys["sql/000002_again.up.sql"] = &fstest.MapFile{Data: []byte("SELECT 2;")}
+	_, err = Load(fsys, "sql")
+	assert.ErrorContains(t, err, "duplicate migration version 2")
+}
+
+func TestRunner_UpIsIdempotent(t *testing.T) {
+	ctx := context.Background()
+	driver := &memDriver{}
+	runner := NewRunner(driver, []Migration{{Version: 1}, {Version: 2}, {Version: 5}}, zerolog.Nop())
+
+	applied, err := runner.Up(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, applied)
+	assert.Equal(t, []int{1, 2, 5}, driver.applied)
+
+	applied, err = runner.Up(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, applied)
+
+	// Only newer migrations are applied to an existing database
+	runner = NewRunner(driver, []Migration{{Version: 1}, {Version: 5}, {Version: 6}}, zerolog.Nop())
+	applied, err = runner.Up(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, applied)
+	assert.Equal(t, []int{1, 2, 5, 6}, driver.applied)
+}
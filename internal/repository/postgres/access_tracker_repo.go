@@ -0,0 +1,445 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+
+	"github.com/prn-tf/alexander-storage/internal/tiering"
+)
+
+// Access tracker defaults
+const (
+	// DefaultAccessFlushInterval is how often buffered accesses are written.
+	DefaultAccessFlushInterval = 5 * time.Second
+
+	// DefaultAccessMaxPending is the number of distinct buffered blobs that
+	// triggers an early flush.
+	DefaultAccessMaxPending = 1000
+)
+
+// AccessTrackerConfig configures an AccessTrackerRepository.
+type AccessTrackerConfig struct {
+	// FlushInterval is how often buffered accesses are written.
+	FlushInterval time.Duration
+
+	// MaxPending is the number of distinct buffered blobs that triggers an
+	// early flush.
+	MaxPending int
+}
+
+// pendingAccess is the buffered accesses of one blob since the last flush.
+type pendingAccess struct {
+	count int64
+	first time.Time
+	last  time.Time
+}
+
+// AccessTrackerRepository is a PostgreSQL implementation of
+// tiering.AccessTracker and tiering.BlobAccessTracker backed by the
+// blob_access table, so access history survives restarts.
+//
+// RecordAccess only updates an in-memory buffer; buffered increments are
+// written in one statement every FlushInterval, when MaxPending blobs are
+// buffered, and on Close. Reads flush first so they see every recorded access.
+type AccessTrackerRepository struct {
+	db     *DB
+	config AccessTrackerConfig
+	logger zerolog.Logger
+
+	mu      sync.Mutex
+	pending map[string]*pendingAccess
+
+	// flushMu serializes flushes so increments are never applied twice
+	flushMu sync.Mutex
+
+	stopCh    chan struct{}
+	doneCh    chan struct{}
+	closeOnce sync.Once
+}
+
+// NewAccessTrackerRepository creates a PostgreSQL access tracker and starts
+// its background flush loop. Call Close to write buffered accesses on shutdown.
+func NewAccessTrackerRepository(db *DB, config AccessTrackerConfig, logger zerolog.Logger) *AccessTrackerRepository {
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = DefaultAccessFlushInterval
+	}
+	if config.MaxPending <= 0 {
+		config.MaxPending = DefaultAccessMaxPending
+	}
+
+	t := &AccessTrackerRepository{
+		db:      db,
+		config:  config,
+		logger:  logger.With().Str("component", "postgres-access-tracker").Logger(),
+		pending: make(map[string]*pendingAccess),
+		stopCh:  make(chan struct{}),
+		doneCh:  make(chan struct{}),
+	}
+
+	go t.flushLoop()
+
+	return t
+}
+
+// flushLoop writes buffered accesses every FlushInterval until Close.
+func (t *AccessTrackerRepository) flushLoop() {
+	defer close(t.doneCh)
+
+	ticker := time.NewTicker(t.config.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.stopCh:
+			return
+		case <-ticker.C:
+			if err := t.Flush(context.Background()); err != nil {
+				t.logger.Error().Err(err).Msg("failed to flush blob accesses")
+			}
+		}
+	}
+}
+
+// Close stops the flush loop and writes any buffered accesses.
+func (t *AccessTrackerRepository) Close(ctx context.Context) error {
+	t.closeOnce.Do(func() {
+		close(t.stopCh)
+	})
+	<-t.doneCh
+
+	return t.Flush(ctx)
+}
+
+// RecordAccess records an access to a blob.
+func (t *AccessTrackerRepository) RecordAccess(ctx context.Context, contentHash string) error {
+	now := time.Now()
+
+	t.mu.Lock()
+	p, exists := t.pending[contentHash]
+	if !exists {
+		p = &pendingAccess{first: now}
+		t.pending[contentHash] = p
+	}
+	p.count++
+	p.last = now
+	full := len(t.pending) >= t.config.MaxPending
+	t.mu.Unlock()
+
+	if full {
+		return t.Flush(ctx)
+	}
+	return nil
+}
+
+// Flush writes buffered accesses. Blobs seen for the first time are created
+// in the hot tier. On failure the accesses are buffered again for the next flush.
+func (t *AccessTrackerRepository) Flush(ctx context.Context) error {
+	t.flushMu.Lock()
+	defer t.flushMu.Unlock()
+
+	t.mu.Lock()
+	batch := t.pending
+	t.pending = make(map[string]*pendingAccess, len(batch))
+	t.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	hashes := make([]string, 0, len(batch))
+	firsts := make([]time.Time, 0, len(batch))
+	lasts := make([]time.Time, 0, len(batch))
+	counts := make([]int64, 0, len(batch))
+	for hash, p := range batch {
+		hashes = append(hashes, hash)
+		firsts = append(firsts, p.first)
+		lasts = append(lasts, p.last)
+		counts = append(counts, p.count)
+	}
+
+	query := `
+		INSERT INTO blob_access (content_hash, current_tier, created_at, last_accessed_at, access_count)
+		SELECT hash, 'hot', first_at, last_at, n
+		FROM unnest($1::text[], $2::timestamptz[], $3::timestamptz[], $4::bigint[]) AS batch(hash, first_at, last_at, n)
+		ON CONFLICT (content_hash) DO UPDATE SET
+			access_count = blob_access.access_count + EXCLUDED.access_count,
+			last_accessed_at = GREATEST(blob_access.last_accessed_at, EXCLUDED.last_accessed_at)
+	`
+
+	if _, err := t.db.Pool.Exec(ctx, query, hashes, firsts, lasts, counts); err != nil {
+		t.requeue(batch)
+		return fmt.Errorf("failed to flush blob accesses: %w", err)
+	}
+
+	return nil
+}
+
+// requeue merges a batch that failed to flush back into the buffer.
+func (t *AccessTrackerRepository) requeue(batch map[string]*pendingAccess) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for hash, p := range batch {
+		current, exists := t.pending[hash]
+		if !exists {
+			t.pending[hash] = p
+			continue
+		}
+		current.count += p.count
+		if p.first.Before(current.first) {
+			current.first = p.first
+		}
+		if p.last.After(current.last) {
+			current.last = p.last
+		}
+	}
+}
+
+// accessInfoColumns are the blob_access columns scanned by scanAccessInfo.
+const accessInfoColumns = `content_hash, current_tier, size, created_at, last_accessed_at, access_count, bucket_name`
+
+// scanAccessInfo scans a blob_access row selected with accessInfoColumns.
+func scanAccessInfo(row pgx.Row) (*tiering.BlobAccessInfo, error) {
+	info := &tiering.BlobAccessInfo{}
+	var tier string
+	err := row.Scan(
+		&info.ContentHash,
+		&tier,
+		&info.Size,
+		&info.CreatedAt,
+		&info.LastAccessedAt,
+		&info.AccessCount,
+		&info.BucketName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	info.CurrentTier = tiering.Tier(tier)
+	return info, nil
+}
+
+// getAccessInfo returns the stored row for a blob or pgx.ErrNoRows.
+func (t *AccessTrackerRepository) getAccessInfo(ctx context.Context, contentHash string) (*tiering.BlobAccessInfo, error) {
+	if err := t.Flush(ctx); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + accessInfoColumns + ` FROM blob_access WHERE content_hash = $1`
+
+	return scanAccessInfo(t.db.Pool.QueryRow(ctx, query, contentHash))
+}
+
+// GetAccessInfo returns access information for a blob.
+func (t *AccessTrackerRepository) GetAccessInfo(ctx context.Context, contentHash string) (*tiering.BlobAccessInfo, error) {
+	info, err := t.getAccessInfo(ctx, contentHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Same error as the in-memory tracker
+			return nil, tiering.ErrNoTargetNode
+		}
+		return nil, fmt.Errorf("failed to get blob access info: %w", err)
+	}
+	return info, nil
+}
+
+// GetBlobsForTiering returns blobs that may need tiering based on access
+// patterns, least recently accessed first. The bucket filter must be a valid
+// Go regular expression and is evaluated by PostgreSQL, so it should stick to
+// syntax both engines share.
+func (t *AccessTrackerRepository) GetBlobsForTiering(ctx context.Context, policy tiering.PolicyConfig, limit int) ([]*tiering.BlobAccessInfo, error) {
+	if policy.BucketFilter != "" {
+		if _, err := regexp.Compile(policy.BucketFilter); err != nil {
+			return nil, fmt.Errorf("%w: bucket filter: %v", tiering.ErrInvalidPolicy, err)
+		}
+	}
+
+	if err := t.Flush(ctx); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	hotCutoff := now.Add(-time.Duration(policy.HotToWarmDays) * 24 * time.Hour)
+	warmCutoff := now.Add(-time.Duration(policy.WarmToColdDays) * 24 * time.Hour)
+
+	var limitArg any
+	if limit > 0 {
+		limitArg = limit
+	}
+
+	query := `
+		SELECT ` + accessInfoColumns + `
+		FROM blob_access
+		WHERE ((current_tier = 'hot' AND last_accessed_at <= $1)
+			OR (current_tier = 'warm' AND last_accessed_at <= $2))
+		  AND ($3::bigint = 0 OR size >= $3::bigint)
+		  AND ($4::bigint = 0 OR size <= $4::bigint)
+		  AND ($5::text = '' OR bucket_name ~ $5::text)
+		ORDER BY last_accessed_at
+		LIMIT $6
+	`
+
+	rows, err := t.db.Pool.Query(ctx, query, hotCutoff, warmCutoff, policy.MinSize, policy.MaxSize, policy.BucketFilter, limitArg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list blobs for tiering: %w", err)
+	}
+	defer rows.Close()
+
+	var candidates []*tiering.BlobAccessInfo
+	for rows.Next() {
+		info, err := scanAccessInfo(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan blob access info: %w", err)
+		}
+		candidates = append(candidates, info)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating blob access rows: %w", err)
+	}
+
+	return candidates, nil
+}
+
+// GetAccessCount returns the access count for a blob.
+func (t *AccessTrackerRepository) GetAccessCount(ctx context.Context, contentHash string) (int, error) {
+	info, err := t.getAccessInfo(ctx, contentHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to get blob access count: %w", err)
+	}
+	return int(info.AccessCount), nil
+}
+
+// GetLastAccess returns the last access time for a blob.
+func (t *AccessTrackerRepository) GetLastAccess(ctx context.Context, contentHash string) (time.Time, error) {
+	info, err := t.getAccessInfo(ctx, contentHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, fmt.Errorf("failed to get blob last access: %w", err)
+	}
+	return info.LastAccessedAt, nil
+}
+
+// GetAccessStats returns full access statistics for a blob. Like the
+// in-memory tracker, individual access times are not kept, so the recent
+// access counts equal the total.
+func (t *AccessTrackerRepository) GetAccessStats(ctx context.Context, contentHash string) (*tiering.AccessStats, error) {
+	info, err := t.getAccessInfo(ctx, contentHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get blob access stats: %w", err)
+	}
+
+	count := int(info.AccessCount)
+	return &tiering.AccessStats{
+		ContentHash:      info.ContentHash,
+		TotalAccessCount: count,
+		LastAccessTime:   info.LastAccessedAt,
+		FirstAccessTime:  info.CreatedAt,
+		AccessesLast24h:  count,
+		AccessesLast7d:   count,
+		AccessesLast30d:  count,
+	}, nil
+}
+
+// Cleanup removes old access records.
+func (t *AccessTrackerRepository) Cleanup(ctx context.Context, olderThan time.Duration) error {
+	if err := t.Flush(ctx); err != nil {
+		return err
+	}
+
+	query := `DELETE FROM blob_access WHERE last_accessed_at < $1`
+
+	if _, err := t.db.Pool.Exec(ctx, query, time.Now().Add(-olderThan)); err != nil {
+		return fmt.Errorf("failed to clean up blob accesses: %w", err)
+	}
+
+	return nil
+}
+
+// RegisterBlob registers a blob with initial access info, replacing any
+// existing record.
+func (t *AccessTrackerRepository) RegisterBlob(ctx context.Context, info *tiering.BlobAccessInfo) error {
+	now := time.Now()
+	createdAt := info.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = now
+	}
+	lastAccessedAt := info.LastAccessedAt
+	if lastAccessedAt.IsZero() {
+		lastAccessedAt = now
+	}
+	tier := info.CurrentTier
+	if tier == "" {
+		tier = tiering.TierHot
+	}
+
+	// Buffered accesses from before registration would be added on top
+	if err := t.Flush(ctx); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO blob_access (content_hash, current_tier, size, created_at, last_accessed_at, access_count, bucket_name)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (content_hash) DO UPDATE SET
+			current_tier = EXCLUDED.current_tier,
+			size = EXCLUDED.size,
+			created_at = EXCLUDED.created_at,
+			last_accessed_at = EXCLUDED.last_accessed_at,
+			access_count = EXCLUDED.access_count,
+			bucket_name = EXCLUDED.bucket_name
+	`
+
+	_, err := t.db.Pool.Exec(ctx, query,
+		info.ContentHash,
+		string(tier),
+		info.Size,
+		createdAt,
+		lastAccessedAt,
+		info.AccessCount,
+		info.BucketName,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register blob access info: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateTier updates the current tier of a blob.
+func (t *AccessTrackerRepository) UpdateTier(ctx context.Context, contentHash string, tier tiering.Tier) error {
+	if err := t.Flush(ctx); err != nil {
+		return err
+	}
+
+	query := `UPDATE blob_access SET current_tier = $2 WHERE content_hash = $1`
+
+	result, err := t.db.Pool.Exec(ctx, query, contentHash, string(tier))
+	if err != nil {
+		return fmt.Errorf("failed to update blob tier: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return tiering.ErrNoTargetNode
+	}
+
+	return nil
+}
+
+// Verify interface compliance
+var _ tiering.AccessTracker = (*AccessTrackerRepository)(nil)
+var _ tiering.BlobAccessTracker = (*AccessTrackerRepository)(nil)
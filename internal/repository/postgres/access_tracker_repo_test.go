@@ -0,0 +1,151 @@
+package postgres
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/tiering"
+)
+
+func testContentHash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// openAccessTrackerDB needs a live database; set ALEXANDER_TEST_POSTGRES_DSN to run it.
+func openAccessTrackerDB(t *testing.T) *DB {
+	t.Helper()
+	dsn := os.Getenv("ALEXANDER_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("ALEXANDER_TEST_POSTGRES_DSN not set")
+	}
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, dsn)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	db := &DB{Pool: pool, logger: zerolog.Nop()}
+	require.NoError(t, db.Migrate(ctx))
+	_, err = pool.Exec(ctx, "TRUNCATE blob_access")
+	require.NoError(t, err)
+	return db
+}
+
+func TestAccessTrackerRepository_SurvivesRestart(t *testing.T) {
+	db := openAccessTrackerDB(t)
+	ctx := context.Background()
+	hot := testContentHash("hot")
+	idle := testContentHash("idle")
+
+	// A long interval so only Close writes the buffered accesses
+	tracker := NewAccessTrackerRepository(db, AccessTrackerConfig{FlushInterval: time.Hour}, zerolog.Nop())
+	require.NoError(t, tracker.RegisterBlob(ctx, &tiering.BlobAccessInfo{
+		ContentHash:    idle,
+		Size:           4096,
+		BucketName:     "archive",
+		LastAccessedAt: time.Now().Add(-40 * 24 * time.Hour),
+	}))
+	for i := 0; i < 25; i++ {
+		require.NoError(t, tracker.RecordAccess(ctx, hot))
+	}
+
+	var stored int64
+	require.NoError(t, db.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM blob_access WHERE content_hash = $1", hot).Scan(&stored))
+	assert.Zero(t, stored, "accesses are buffered until flushed")
+
+	require.NoError(t, tracker.Close(ctx))
+
+	// Simulated restart: a new tracker sees the history of the old one
+	restarted := NewAccessTrackerRepository(db, AccessTrackerConfig{}, zerolog.Nop())
+	defer restarted.Close(ctx)
+
+	count, err := restarted.GetAccessCount(ctx, hot)
+	require.NoError(t, err)
+	assert.Equal(t, 25, count)
+
+	info, err := restarted.GetAccessInfo(ctx, idle)
+	require.NoError(t, err)
+	assert.Equal(t, tiering.TierHot, info.CurrentTier)
+	assert.Equal(t, int64(4096), info.Size)
+	assert.Equal(t, "archive", info.BucketName)
+
+	// More accesses add to the persisted count
+	require.NoError(t, restarted.RecordAccess(ctx, hot))
+	stats, err := restarted.GetAccessStats(ctx, hot)
+	require.NoError(t, err)
+	assert.Equal(t, 26, stats.TotalAccessCount)
+
+	policy := tiering.DefaultPolicyConfig()
+	candidates, err := restarted.GetBlobsForTiering(ctx, policy, 10)
+	require.NoError(t, err)
+	require.Len(t, candidates, 1)
+	assert.Equal(t, idle, candidates[0].ContentHash)
+
+	policy.BucketFilter = "^logs-"
+	candidates, err = restarted.GetBlobsForTiering(ctx, policy, 10)
+	require.NoError(t, err)
+	assert.Empty(t, candidates)
+
+	policy.BucketFilter = "("
+	_, err = restarted.GetBlobsForTiering(ctx, policy, 10)
+	assert.ErrorIs(t, err, tiering.ErrInvalidPolicy)
+
+	require.NoError(t, restarted.UpdateTier(ctx, idle, tiering.TierWarm))
+	info, err = restarted.GetAccessInfo(ctx, idle)
+	require.NoError(t, err)
+	assert.Equal(t, tiering.TierWarm, info.CurrentTier)
+
+	_, err = restarted.GetAccessInfo(ctx, testContentHash("missing"))
+	assert.ErrorIs(t, err, tiering.ErrNoTargetNode)
+
+	require.NoError(t, restarted.Cleanup(ctx, 30*24*time.Hour))
+	count, err = restarted.GetAccessCount(ctx, idle)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestAccessTrackerRepository_FlushesWhenBufferFull(t *testing.T) {
+	db := openAccessTrackerDB(t)
+	ctx := context.Background()
+
+	tracker := NewAccessTrackerRepository(db, AccessTrackerConfig{FlushInterval: time.Hour, MaxPending: 3}, zerolog.Nop())
+	defer tracker.Close(ctx)
+
+	for _, name := range []string{"a", "b", "c"} {
+		require.NoError(t, tracker.RecordAccess(ctx, testContentHash(name)))
+	}
+
+	var stored int64
+	require.NoError(t, db.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM blob_access").Scan(&stored))
+	assert.Equal(t, int64(3), stored)
+}
+
+func TestAccessTrackerRepository_KeepsAccessesWhenFlushFails(t *testing.T) {
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, "host=127.0.0.1 port=1 user=alexander dbname=alexander connect_timeout=1")
+	require.NoError(t, err)
+	pool.Close()
+
+	tracker := NewAccessTrackerRepository(&DB{Pool: pool, logger: zerolog.Nop()}, AccessTrackerConfig{FlushInterval: time.Hour}, zerolog.Nop())
+	hash := testContentHash("retry")
+	require.NoError(t, tracker.RecordAccess(ctx, hash))
+	require.NoError(t, tracker.RecordAccess(ctx, hash))
+
+	require.Error(t, tracker.Flush(ctx))
+	require.NoError(t, tracker.RecordAccess(ctx, hash))
+
+	// Failed increments are kept for the next flush
+	require.Error(t, tracker.Close(ctx))
+	require.Contains(t, tracker.pending, hash)
+	assert.Equal(t, int64(3), tracker.pending[hash].count)
+}
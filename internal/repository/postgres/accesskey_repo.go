@@ -25,8 +25,8 @@ func NewAccessKeyRepository(db *DB) repository.AccessKeyRepository {
 // Create creates a new access key.
 func (r *accessKeyRepository) Create(ctx context.Context, key *domain.AccessKey) error {
 	query := `
-		INSERT INTO access_keys (user_id, access_key_id, encrypted_secret, description, status, created_at, expires_at, last_used_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO access_keys (user_id, access_key_id, encrypted_secret, description, status, created_at, expires_at, last_used_at, namespace_bucket, namespace_prefix, rate_limit_rps, rate_limit_burst)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		RETURNING id
 	`
 
@@ -39,6 +39,10 @@ func (r *accessKeyRepository) Create(ctx context.Context, key *domain.AccessKey)
 		key.CreatedAt,
 		key.ExpiresAt,
 		key.LastUsedAt,
+		key.NamespaceBucket,
+		key.NamespacePrefix,
+		key.RateLimitRPS,
+		key.RateLimitBurst,
 	).Scan(&key.ID)
 
 	if err != nil {
@@ -54,7 +58,7 @@ func (r *accessKeyRepository) Create(ctx context.Context, key *domain.AccessKey)
 // GetByID retrieves an access key by ID.
 func (r *accessKeyRepository) GetByID(ctx context.Context, id int64) (*domain.AccessKey, error) {
 	query := `
-		SELECT id, user_id, access_key_id, encrypted_secret, description, status, created_at, expires_at, last_used_at
+		SELECT id, user_id, access_key_id, encrypted_secret, description, status, created_at, expires_at, last_used_at, namespace_bucket, namespace_prefix, rate_limit_rps, rate_limit_burst
 		FROM access_keys
 		WHERE id = $1
 	`
@@ -70,6 +74,10 @@ func (r *accessKeyRepository) GetByID(ctx context.Context, id int64) (*domain.Ac
 		&key.CreatedAt,
 		&key.ExpiresAt,
 		&key.LastUsedAt,
+		&key.NamespaceBucket,
+		&key.NamespacePrefix,
+		&key.RateLimitRPS,
+		&key.RateLimitBurst,
 	)
 
 	if err != nil {
@@ -85,7 +93,7 @@ func (r *accessKeyRepository) GetByID(ctx context.Context, id int64) (*domain.Ac
 // GetByAccessKeyID retrieves an access key by access key ID (20-char identifier).
 func (r *accessKeyRepository) GetByAccessKeyID(ctx context.Context, accessKeyID string) (*domain.AccessKey, error) {
 	query := `
-		SELECT id, user_id, access_key_id, encrypted_secret, description, status, created_at, expires_at, last_used_at
+		SELECT id, user_id, access_key_id, encrypted_secret, description, status, created_at, expires_at, last_used_at, namespace_bucket, namespace_prefix, rate_limit_rps, rate_limit_burst
 		FROM access_keys
 		WHERE access_key_id = $1
 	`
@@ -101,6 +109,10 @@ func (r *accessKeyRepository) GetByAccessKeyID(ctx context.Context, accessKeyID
 		&key.CreatedAt,
 		&key.ExpiresAt,
 		&key.LastUsedAt,
+		&key.NamespaceBucket,
+		&key.NamespacePrefix,
+		&key.RateLimitRPS,
+		&key.RateLimitBurst,
 	)
 
 	if err != nil {
@@ -116,7 +128,7 @@ func (r *accessKeyRepository) GetByAccessKeyID(ctx context.Context, accessKeyID
 // GetActiveByAccessKeyID retrieves an active, non-expired access key.
 func (r *accessKeyRepository) GetActiveByAccessKeyID(ctx context.Context, accessKeyID string) (*domain.AccessKey, error) {
 	query := `
-		SELECT id, user_id, access_key_id, encrypted_secret, description, status, created_at, expires_at, last_used_at
+		SELECT id, user_id, access_key_id, encrypted_secret, description, status, created_at, expires_at, last_used_at, namespace_bucket, namespace_prefix, rate_limit_rps, rate_limit_burst
 		FROM access_keys
 		WHERE access_key_id = $1 
 			AND status = $2 
@@ -134,6 +146,10 @@ func (r *accessKeyRepository) GetActiveByAccessKeyID(ctx context.Context, access
 		&key.CreatedAt,
 		&key.ExpiresAt,
 		&key.LastUsedAt,
+		&key.NamespaceBucket,
+		&key.NamespacePrefix,
+		&key.RateLimitRPS,
+		&key.RateLimitBurst,
 	)
 
 	if err != nil {
@@ -149,7 +165,7 @@ func (r *accessKeyRepository) GetActiveByAccessKeyID(ctx context.Context, access
 // ListByUserID retrieves all access keys for a user.
 func (r *accessKeyRepository) ListByUserID(ctx context.Context, userID int64) ([]*domain.AccessKey, error) {
 	query := `
-		SELECT id, user_id, access_key_id, encrypted_secret, description, status, created_at, expires_at, last_used_at
+		SELECT id, user_id, access_key_id, encrypted_secret, description, status, created_at, expires_at, last_used_at, namespace_bucket, namespace_prefix, rate_limit_rps, rate_limit_burst
 		FROM access_keys
 		WHERE user_id = $1
 		ORDER BY created_at DESC
@@ -174,6 +190,10 @@ func (r *accessKeyRepository) ListByUserID(ctx context.Context, userID int64) ([
 			&key.CreatedAt,
 			&key.ExpiresAt,
 			&key.LastUsedAt,
+			&key.NamespaceBucket,
+			&key.NamespacePrefix,
+			&key.RateLimitRPS,
+			&key.RateLimitBurst,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan access key: %w", err)
@@ -192,7 +212,7 @@ func (r *accessKeyRepository) ListByUserID(ctx context.Context, userID int64) ([
 func (r *accessKeyRepository) Update(ctx context.Context, key *domain.AccessKey) error {
 	query := `
 		UPDATE access_keys
-		SET description = $2, status = $3, expires_at = $4
+		SET description = $2, status = $3, expires_at = $4, namespace_bucket = $5, namespace_prefix = $6, rate_limit_rps = $7, rate_limit_burst = $8
 		WHERE id = $1
 	`
 
@@ -201,6 +221,10 @@ func (r *accessKeyRepository) Update(ctx context.Context, key *domain.AccessKey)
 		key.Description,
 		key.Status,
 		key.ExpiresAt,
+		key.NamespaceBucket,
+		key.NamespacePrefix,
+		key.RateLimitRPS,
+		key.RateLimitBurst,
 	)
 
 	if err != nil {
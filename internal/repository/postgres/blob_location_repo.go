@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/prn-tf/alexander-storage/internal/cluster"
+)
+
+// BlobLocationRepository is a PostgreSQL implementation of
+// cluster.BlobLocationRepository backed by the blob_locations table.
+type BlobLocationRepository struct {
+	db *DB
+}
+
+// NewBlobLocationRepository creates a new PostgreSQL blob location repository.
+func NewBlobLocationRepository(db *DB) *BlobLocationRepository {
+	return &BlobLocationRepository{db: db}
+}
+
+// Upsert creates or replaces the location of a blob on a node.
+func (r *BlobLocationRepository) Upsert(ctx context.Context, location *cluster.BlobLocation) error {
+	query := `
+		INSERT INTO blob_locations (content_hash, node_id, is_primary, synced_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (content_hash, node_id) DO UPDATE SET
+			is_primary = EXCLUDED.is_primary,
+			synced_at = EXCLUDED.synced_at
+	`
+
+	_, err := r.db.Pool.Exec(ctx, query,
+		location.ContentHash,
+		location.NodeID,
+		location.IsPrimary,
+		location.SyncedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to upsert blob location: %w", err)
+	}
+
+	return nil
+}
+
+// Get returns all locations of a blob, primary first.
+func (r *BlobLocationRepository) Get(ctx context.Context, contentHash string) ([]*cluster.BlobLocation, error) {
+	query := `
+		SELECT content_hash, node_id, is_primary, synced_at
+		FROM blob_locations
+		WHERE content_hash = $1
+		ORDER BY is_primary DESC, node_id
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, contentHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blob locations: %w", err)
+	}
+	defer rows.Close()
+
+	var locations []*cluster.BlobLocation
+	for rows.Next() {
+		loc := &cluster.BlobLocation{}
+		if err := rows.Scan(&loc.ContentHash, &loc.NodeID, &loc.IsPrimary, &loc.SyncedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan blob location: %w", err)
+		}
+		locations = append(locations, loc)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate blob locations: %w", err)
+	}
+
+	return locations, nil
+}
+
+// Remove deletes the location of a blob on a node.
+func (r *BlobLocationRepository) Remove(ctx context.Context, contentHash, nodeID string) error {
+	query := `DELETE FROM blob_locations WHERE content_hash = $1 AND node_id = $2`
+
+	if _, err := r.db.Pool.Exec(ctx, query, contentHash, nodeID); err != nil {
+		return fmt.Errorf("failed to remove blob location: %w", err)
+	}
+
+	return nil
+}
+
+var _ cluster.BlobLocationRepository = (*BlobLocationRepository)(nil)
@@ -0,0 +1,47 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/cluster"
+)
+
+func TestBlobLocationRepository_UpsertGetRemove(t *testing.T) {
+	db := openAccessTrackerDB(t)
+	ctx := context.Background()
+	_, err := db.Pool.Exec(ctx, "TRUNCATE blob_locations")
+	require.NoError(t, err)
+
+	hash := testContentHash("located")
+	syncedAt := time.Now().Add(-time.Hour).UTC().Truncate(time.Microsecond)
+
+	repo := NewBlobLocationRepository(db)
+	require.NoError(t, repo.Upsert(ctx, &cluster.BlobLocation{ContentHash: hash, NodeID: "warm-1", SyncedAt: syncedAt}))
+	require.NoError(t, repo.Upsert(ctx, &cluster.BlobLocation{ContentHash: hash, NodeID: "hot-1", SyncedAt: syncedAt}))
+	require.NoError(t, repo.Upsert(ctx, &cluster.BlobLocation{ContentHash: hash, NodeID: "hot-1", IsPrimary: true, SyncedAt: syncedAt}))
+
+	// A new repository sees the locations, primary first
+	locations, err := NewBlobLocationRepository(db).Get(ctx, hash)
+	require.NoError(t, err)
+	require.Len(t, locations, 2)
+	assert.Equal(t, "hot-1", locations[0].NodeID)
+	assert.True(t, locations[0].IsPrimary)
+	assert.Equal(t, "warm-1", locations[1].NodeID)
+	assert.True(t, syncedAt.Equal(locations[1].SyncedAt))
+
+	require.NoError(t, repo.Remove(ctx, hash, "hot-1"))
+	require.NoError(t, repo.Remove(ctx, hash, "missing"))
+	locations, err = repo.Get(ctx, hash)
+	require.NoError(t, err)
+	require.Len(t, locations, 1)
+	assert.Equal(t, "warm-1", locations[0].NodeID)
+
+	locations, err = repo.Get(ctx, testContentHash("unknown"))
+	require.NoError(t, err)
+	assert.Empty(t, locations)
+}
@@ -68,7 +68,8 @@ func (r *blobRepository) UpsertEncrypted(ctx context.Context, contentHash string
 // GetByHash retrieves a blob by its content hash (primary key).
 func (r *blobRepository) GetByHash(ctx context.Context, contentHash string) (*domain.Blob, error) {
 	query := `
-		SELECT content_hash, size, storage_path, ref_count, is_encrypted, created_at, last_accessed
+		SELECT content_hash, size, storage_path, ref_count, is_encrypted,
+			COALESCE(encryption_scheme, ''), created_at, last_accessed
 		FROM blobs
 		WHERE content_hash = $1
 	`
@@ -80,6 +81,7 @@ func (r *blobRepository) GetByHash(ctx context.Context, contentHash string) (*do
 		&blob.StoragePath,
 		&blob.RefCount,
 		&blob.IsEncrypted,
+		&blob.EncryptionScheme,
 		&blob.CreatedAt,
 		&blob.LastAccessed,
 	)
@@ -159,6 +161,24 @@ func (r *blobRepository) Exists(ctx context.Context, contentHash string) (bool,
 	return exists, nil
 }
 
+// IsReferenced reports whether an object version, upload part, composite
+// blob or delta still references the blob.
+func (r *blobRepository) IsReferenced(ctx context.Context, contentHash string) (bool, error) {
+	query := `
+		SELECT EXISTS(SELECT 1 FROM objects WHERE content_hash = $1)
+			OR EXISTS(SELECT 1 FROM upload_parts WHERE content_hash = $1)
+			OR EXISTS(SELECT 1 FROM blob_parts WHERE part_hash = $1)
+			OR EXISTS(SELECT 1 FROM blob_deltas WHERE base_hash = $1)
+			OR EXISTS(SELECT 1 FROM delta_chains WHERE base_hash = $1 OR data_hash = $1)
+	`
+
+	var referenced bool
+	if err := r.db.Pool.QueryRow(ctx, query, contentHash).Scan(&referenced); err != nil {
+		return false, fmt.Errorf("failed to check blob references: %w", err)
+	}
+	return referenced, nil
+}
+
 // Delete deletes a blob by its content hash.
 func (r *blobRepository) Delete(ctx context.Context, contentHash string) error {
 	query := `DELETE FROM blobs WHERE content_hash = $1 AND ref_count <= 0`
@@ -440,5 +460,51 @@ func (r *blobRepository) ListAll(ctx context.Context, limit int) ([]*domain.Blob
 	return blobs, nil
 }
 
+// ListAfter returns blobs ordered by content hash, starting after afterHash.
+func (r *blobRepository) ListAfter(ctx context.Context, afterHash string, limit int) ([]*domain.Blob, error) {
+	query := `
+		SELECT content_hash, size, storage_path, ref_count, is_encrypted, encryption_iv, created_at, last_accessed
+		FROM blobs
+		WHERE content_hash > $1
+		ORDER BY content_hash ASC
+		LIMIT $2
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, afterHash, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list blobs: %w", err)
+	}
+	defer rows.Close()
+
+	var blobs []*domain.Blob
+	for rows.Next() {
+		blob := &domain.Blob{}
+		var iv *string
+		err := rows.Scan(
+			&blob.ContentHash,
+			&blob.Size,
+			&blob.StoragePath,
+			&blob.RefCount,
+			&blob.IsEncrypted,
+			&iv,
+			&blob.CreatedAt,
+			&blob.LastAccessed,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan blob: %w", err)
+		}
+		if iv != nil {
+			blob.EncryptionIV = iv
+		}
+		blobs = append(blobs, blob)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating blobs: %w", err)
+	}
+
+	return blobs, nil
+}
+
 // Ensure blobRepository implements repository.BlobRepository
 var _ repository.BlobRepository = (*blobRepository)(nil)
@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/repository"
+)
+
+// bucketCORSRepository implements repository.BucketCORSRepository.
+type bucketCORSRepository struct {
+	db *DB
+}
+
+// NewBucketCORSRepository creates a new PostgreSQL bucket CORS repository.
+func NewBucketCORSRepository(db *DB) repository.BucketCORSRepository {
+	return &bucketCORSRepository{db: db}
+}
+
+// Get retrieves the CORS configuration of a bucket.
+func (r *bucketCORSRepository) Get(ctx context.Context, bucketID int64) (*domain.BucketCORS, error) {
+	query := `
+		SELECT bucket_id, rules, created_at, updated_at
+		FROM bucket_cors
+		WHERE bucket_id = $1
+	`
+
+	cors := &domain.BucketCORS{}
+	var rules []byte
+	err := r.db.Pool.QueryRow(ctx, query, bucketID).Scan(
+		&cors.BucketID,
+		&rules,
+		&cors.CreatedAt,
+		&cors.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get bucket CORS configuration: %w", err)
+	}
+
+	if err := json.Unmarshal(rules, &cors.Rules); err != nil {
+		return nil, fmt.Errorf("failed to decode bucket CORS rules: %w", err)
+	}
+
+	return cors, nil
+}
+
+// Put creates or replaces the CORS configuration of a bucket.
+func (r *bucketCORSRepository) Put(ctx context.Context, cors *domain.BucketCORS) error {
+	rules, err := json.Marshal(cors.Rules)
+	if err != nil {
+		return fmt.Errorf("failed to encode bucket CORS rules: %w", err)
+	}
+
+	query := `
+		INSERT INTO bucket_cors (bucket_id, rules, created_at, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (bucket_id) DO UPDATE SET
+			rules = EXCLUDED.rules,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	_, err = r.db.Pool.Exec(ctx, query,
+		cors.BucketID,
+		rules,
+		cors.CreatedAt,
+		cors.UpdatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to put bucket CORS configuration: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the CORS configuration of a bucket.
+func (r *bucketCORSRepository) Delete(ctx context.Context, bucketID int64) error {
+	query := `DELETE FROM bucket_cors WHERE bucket_id = $1`
+
+	if _, err := r.db.Pool.Exec(ctx, query, bucketID); err != nil {
+		return fmt.Errorf("failed to delete bucket CORS configuration: %w", err)
+	}
+
+	return nil
+}
+
+// Ensure bucketCORSRepository implements repository.BucketCORSRepository.
+var _ repository.BucketCORSRepository = (*bucketCORSRepository)(nil)
@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/repository"
+)
+
+// bucketNotificationRepository implements repository.BucketNotificationRepository.
+type bucketNotificationRepository struct {
+	db *DB
+}
+
+// NewBucketNotificationRepository creates a new PostgreSQL bucket notification repository.
+func NewBucketNotificationRepository(db *DB) repository.BucketNotificationRepository {
+	return &bucketNotificationRepository{db: db}
+}
+
+// Get retrieves the notification configuration of a bucket.
+func (r *bucketNotificationRepository) Get(ctx context.Context, bucketID int64) (*domain.BucketNotification, error) {
+	query := `
+		SELECT bucket_id, webhooks, created_at, updated_at
+		FROM bucket_notifications
+		WHERE bucket_id = $1
+	`
+
+	notification := &domain.BucketNotification{}
+	var webhooks []byte
+	err := r.db.Pool.QueryRow(ctx, query, bucketID).Scan(
+		&notification.BucketID,
+		&webhooks,
+		&notification.CreatedAt,
+		&notification.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get bucket notification configuration: %w", err)
+	}
+
+	if err := json.Unmarshal(webhooks, &notification.Webhooks); err != nil {
+		return nil, fmt.Errorf("failed to decode bucket notification webhooks: %w", err)
+	}
+
+	return notification, nil
+}
+
+// Put creates or replaces the notification configuration of a bucket.
+func (r *bucketNotificationRepository) Put(ctx context.Context, notification *domain.BucketNotification) error {
+	webhooks, err := json.Marshal(notification.Webhooks)
+	if err != nil {
+		return fmt.Errorf("failed to encode bucket notification webhooks: %w", err)
+	}
+
+	query := `
+		INSERT INTO bucket_notifications (bucket_id, webhooks, created_at, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (bucket_id) DO UPDATE SET
+			webhooks = EXCLUDED.webhooks,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	_, err = r.db.Pool.Exec(ctx, query,
+		notification.BucketID,
+		webhooks,
+		notification.CreatedAt,
+		notification.UpdatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to put bucket notification configuration: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the notification configuration of a bucket.
+func (r *bucketNotificationRepository) Delete(ctx context.Context, bucketID int64) error {
+	query := `DELETE FROM bucket_notifications WHERE bucket_id = $1`
+
+	if _, err := r.db.Pool.Exec(ctx, query, bucketID); err != nil {
+		return fmt.Errorf("failed to delete bucket notification configuration: %w", err)
+	}
+
+	return nil
+}
+
+// Ensure bucketNotificationRepository implements repository.BucketNotificationRepository.
+var _ repository.BucketNotificationRepository = (*bucketNotificationRepository)(nil)
@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/repository"
+)
+
+// bucketPolicyRepository implements repository.BucketPolicyRepository.
+type bucketPolicyRepository struct {
+	db *DB
+}
+
+// NewBucketPolicyRepository creates a new PostgreSQL bucket policy repository.
+func NewBucketPolicyRepository(db *DB) repository.BucketPolicyRepository {
+	return &bucketPolicyRepository{db: db}
+}
+
+// Get retrieves the policy of a bucket.
+func (r *bucketPolicyRepository) Get(ctx context.Context, bucketID int64) (*domain.BucketPolicy, error) {
+	query := `
+		SELECT bucket_id, policy, created_at, updated_at
+		FROM bucket_policies
+		WHERE bucket_id = $1
+	`
+
+	policy := &domain.BucketPolicy{}
+	err := r.db.Pool.QueryRow(ctx, query, bucketID).Scan(
+		&policy.BucketID,
+		&policy.Policy,
+		&policy.CreatedAt,
+		&policy.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get bucket policy: %w", err)
+	}
+
+	return policy, nil
+}
+
+// Put creates or replaces the policy of a bucket.
+func (r *bucketPolicyRepository) Put(ctx context.Context, policy *domain.BucketPolicy) error {
+	query := `
+		INSERT INTO bucket_policies (bucket_id, policy, created_at, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (bucket_id) DO UPDATE SET
+			policy = EXCLUDED.policy,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := r.db.Pool.Exec(ctx, query,
+		policy.BucketID,
+		policy.Policy,
+		policy.CreatedAt,
+		policy.UpdatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to put bucket policy: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the policy of a bucket.
+func (r *bucketPolicyRepository) Delete(ctx context.Context, bucketID int64) error {
+	query := `DELETE FROM bucket_policies WHERE bucket_id = $1`
+
+	if _, err := r.db.Pool.Exec(ctx, query, bucketID); err != nil {
+		return fmt.Errorf("failed to delete bucket policy: %w", err)
+	}
+
+	return nil
+}
+
+// Ensure bucketPolicyRepository implements repository.BucketPolicyRepository.
+var _ repository.BucketPolicyRepository = (*bucketPolicyRepository)(nil)
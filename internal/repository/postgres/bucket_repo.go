@@ -24,8 +24,8 @@ func NewBucketRepository(db *DB) repository.BucketRepository {
 // Create creates a new bucket.
 func (r *bucketRepository) Create(ctx context.Context, bucket *domain.Bucket) error {
 	query := `
-		INSERT INTO buckets (owner_id, name, region, versioning, acl, object_lock, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO buckets (owner_id, name, region, versioning, acl, object_ownership, object_lock, state, max_versions_per_key, case_insensitive_keys, content_type_policy, require_encryption, default_retention, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 		RETURNING id
 	`
 
@@ -35,7 +35,14 @@ func (r *bucketRepository) Create(ctx context.Context, bucket *domain.Bucket) er
 		bucket.Region,
 		bucket.Versioning,
 		bucket.ACL,
+		bucket.ObjectOwnership,
 		bucket.ObjectLock,
+		bucket.State,
+		bucket.MaxVersionsPerKey,
+		bucket.CaseInsensitiveKeys,
+		bucket.ContentTypePolicy,
+		bucket.RequireEncryption,
+		bucket.DefaultRetention,
 		bucket.CreatedAt,
 	).Scan(&bucket.ID)
 
@@ -52,7 +59,7 @@ func (r *bucketRepository) Create(ctx context.Context, bucket *domain.Bucket) er
 // GetByID retrieves a bucket by ID.
 func (r *bucketRepository) GetByID(ctx context.Context, id int64) (*domain.Bucket, error) {
 	query := `
-		SELECT id, owner_id, name, region, versioning, acl, object_lock, created_at
+		SELECT id, owner_id, name, region, versioning, acl, object_ownership, object_lock, state, max_versions_per_key, case_insensitive_keys, content_type_policy, require_encryption, default_retention, created_at
 		FROM buckets
 		WHERE id = $1
 	`
@@ -65,7 +72,14 @@ func (r *bucketRepository) GetByID(ctx context.Context, id int64) (*domain.Bucke
 		&bucket.Region,
 		&bucket.Versioning,
 		&bucket.ACL,
+		&bucket.ObjectOwnership,
 		&bucket.ObjectLock,
+		&bucket.State,
+		&bucket.MaxVersionsPerKey,
+		&bucket.CaseInsensitiveKeys,
+		&bucket.ContentTypePolicy,
+		&bucket.RequireEncryption,
+		&bucket.DefaultRetention,
 		&bucket.CreatedAt,
 	)
 
@@ -82,7 +96,7 @@ func (r *bucketRepository) GetByID(ctx context.Context, id int64) (*domain.Bucke
 // GetByName retrieves a bucket by name.
 func (r *bucketRepository) GetByName(ctx context.Context, name string) (*domain.Bucket, error) {
 	query := `
-		SELECT id, owner_id, name, region, versioning, acl, object_lock, created_at
+		SELECT id, owner_id, name, region, versioning, acl, object_ownership, object_lock, state, max_versions_per_key, case_insensitive_keys, content_type_policy, require_encryption, default_retention, created_at
 		FROM buckets
 		WHERE name = $1
 	`
@@ -95,7 +109,14 @@ func (r *bucketRepository) GetByName(ctx context.Context, name string) (*domain.
 		&bucket.Region,
 		&bucket.Versioning,
 		&bucket.ACL,
+		&bucket.ObjectOwnership,
 		&bucket.ObjectLock,
+		&bucket.State,
+		&bucket.MaxVersionsPerKey,
+		&bucket.CaseInsensitiveKeys,
+		&bucket.ContentTypePolicy,
+		&bucket.RequireEncryption,
+		&bucket.DefaultRetention,
 		&bucket.CreatedAt,
 	)
 
@@ -117,7 +138,7 @@ func (r *bucketRepository) List(ctx context.Context, userID int64) ([]*domain.Bu
 
 	if userID > 0 {
 		query = `
-			SELECT id, owner_id, name, region, versioning, acl, object_lock, created_at
+			SELECT id, owner_id, name, region, versioning, acl, object_ownership, object_lock, state, max_versions_per_key, case_insensitive_keys, content_type_policy, require_encryption, default_retention, created_at
 			FROM buckets
 			WHERE owner_id = $1
 			ORDER BY name ASC
@@ -125,7 +146,7 @@ func (r *bucketRepository) List(ctx context.Context, userID int64) ([]*domain.Bu
 		rows, err = r.db.Pool.Query(ctx, query, userID)
 	} else {
 		query = `
-			SELECT id, owner_id, name, region, versioning, acl, object_lock, created_at
+			SELECT id, owner_id, name, region, versioning, acl, object_ownership, object_lock, state, max_versions_per_key, case_insensitive_keys, content_type_policy, require_encryption, default_retention, created_at
 			FROM buckets
 			ORDER BY name ASC
 		`
@@ -147,7 +168,14 @@ func (r *bucketRepository) List(ctx context.Context, userID int64) ([]*domain.Bu
 			&bucket.Region,
 			&bucket.Versioning,
 			&bucket.ACL,
+			&bucket.ObjectOwnership,
 			&bucket.ObjectLock,
+			&bucket.State,
+			&bucket.MaxVersionsPerKey,
+			&bucket.CaseInsensitiveKeys,
+			&bucket.ContentTypePolicy,
+			&bucket.RequireEncryption,
+			&bucket.DefaultRetention,
 			&bucket.CreatedAt,
 		)
 		if err != nil {
@@ -220,6 +248,143 @@ func (r *bucketRepository) UpdateACL(ctx context.Context, id int64, acl domain.B
 	return nil
 }
 
+// UpdateObjectOwnership updates the object ownership setting of a bucket.
+func (r *bucketRepository) UpdateObjectOwnership(ctx context.Context, id int64, ownership domain.ObjectOwnership) error {
+	query := `UPDATE buckets SET object_ownership = $2 WHERE id = $1`
+
+	result, err := r.db.Pool.Exec(ctx, query, id, ownership)
+	if err != nil {
+		return fmt.Errorf("failed to update object ownership: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrBucketNotFound
+	}
+
+	return nil
+}
+
+// UpdateState updates the lifecycle state of a bucket.
+func (r *bucketRepository) UpdateState(ctx context.Context, id int64, state domain.BucketState) error {
+	query := `UPDATE buckets SET state = $2 WHERE id = $1`
+
+	result, err := r.db.Pool.Exec(ctx, query, id, state)
+	if err != nil {
+		return fmt.Errorf("failed to update bucket state: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrBucketNotFound
+	}
+
+	return nil
+}
+
+// UpdateCaseInsensitiveKeys updates whether a bucket matches keys case-insensitively.
+// Turning it off resets every normalized key to the stored key.
+func (r *bucketRepository) UpdateCaseInsensitiveKeys(ctx context.Context, id int64, enabled bool) error {
+	return r.db.WithTx(ctx, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		result, err := tx.Exec(ctx, `UPDATE buckets SET case_insensitive_keys = $2 WHERE id = $1`, id, enabled)
+		if err != nil {
+			return fmt.Errorf("failed to update key case sensitivity: %w", err)
+		}
+
+		if result.RowsAffected() == 0 {
+			return domain.ErrBucketNotFound
+		}
+
+		if !enabled {
+			if _, err := tx.Exec(ctx, `UPDATE objects SET normalized_key = key WHERE bucket_id = $1`, id); err != nil {
+				return fmt.Errorf("failed to reset normalized keys: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// UpdateContentTypePolicy replaces the content-type policy of a bucket.
+// A nil policy clears it.
+func (r *bucketRepository) UpdateContentTypePolicy(ctx context.Context, id int64, policy *domain.ContentTypePolicy) error {
+	query := `UPDATE buckets SET content_type_policy = $2 WHERE id = $1`
+
+	result, err := r.db.Pool.Exec(ctx, query, id, policy)
+	if err != nil {
+		return fmt.Errorf("failed to update content type policy: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrBucketNotFound
+	}
+
+	return nil
+}
+
+// UpdateMaxVersionsPerKey updates the per-key version limit of a bucket.
+func (r *bucketRepository) UpdateMaxVersionsPerKey(ctx context.Context, id int64, maxVersions int) error {
+	query := `UPDATE buckets SET max_versions_per_key = $2 WHERE id = $1`
+
+	result, err := r.db.Pool.Exec(ctx, query, id, maxVersions)
+	if err != nil {
+		return fmt.Errorf("failed to update max versions per key: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrBucketNotFound
+	}
+
+	return nil
+}
+
+// UpdateRequireEncryption updates whether a bucket rejects uploads that do
+// not request server-side encryption.
+func (r *bucketRepository) UpdateRequireEncryption(ctx context.Context, id int64, required bool) error {
+	query := `UPDATE buckets SET require_encryption = $2 WHERE id = $1`
+
+	result, err := r.db.Pool.Exec(ctx, query, id, required)
+	if err != nil {
+		return fmt.Errorf("failed to update encryption requirement: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrBucketNotFound
+	}
+
+	return nil
+}
+
+// EnableObjectLock turns on object lock for a bucket.
+func (r *bucketRepository) EnableObjectLock(ctx context.Context, id int64) error {
+	query := `UPDATE buckets SET object_lock = TRUE WHERE id = $1`
+
+	result, err := r.db.Pool.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to enable object lock: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrBucketNotFound
+	}
+
+	return nil
+}
+
+// UpdateDefaultRetention replaces the default retention of a bucket.
+func (r *bucketRepository) UpdateDefaultRetention(ctx context.Context, id int64, retention *domain.DefaultRetention) error {
+	query := `UPDATE buckets SET default_retention = $2 WHERE id = $1`
+
+	result, err := r.db.Pool.Exec(ctx, query, id, retention)
+	if err != nil {
+		return fmt.Errorf("failed to update default retention: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrBucketNotFound
+	}
+
+	return nil
+}
+
 // Delete deletes a bucket by ID.
 func (r *bucketRepository) Delete(ctx context.Context, id int64) error {
 	query := `DELETE FROM buckets WHERE id = $1`
@@ -262,6 +427,29 @@ func (r *bucketRepository) ExistsByName(ctx context.Context, name string) (bool,
 	return exists, nil
 }
 
+// bucketNameLockSpace is the first key of the two-key advisory locks taken on
+// bucket names; the second key is the hash of the name.
+const bucketNameLockSpace = 1651864428
+
+// LockName takes a session advisory lock on the bucket name. The lock is held
+// on a dedicated connection until unlock is called.
+func (r *bucketRepository) LockName(ctx context.Context, name string) (func(), error) {
+	conn, err := r.db.Pool.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1, hashtext($2))`, bucketNameLockSpace, name); err != nil {
+		conn.Release()
+		return nil, fmt.Errorf("failed to lock bucket name: %w", err)
+	}
+
+	return func() {
+		_, _ = conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1, hashtext($2))`, bucketNameLockSpace, name)
+		conn.Release()
+	}, nil
+}
+
 // IsEmpty checks if a bucket contains any objects.
 func (r *bucketRepository) IsEmpty(ctx context.Context, id int64) (bool, error) {
 	var count int64
@@ -0,0 +1,300 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/prn-tf/alexander-storage/internal/delta"
+)
+
+// chunkRepository implements delta.ChunkStore on the cdc_chunks table and
+// delta.BlobChunkRecorder on the blob_chunks table.
+type chunkRepository struct {
+	db *DB
+}
+
+// NewChunkRepository creates a new PostgreSQL CDC chunk store.
+func NewChunkRepository(db *DB) delta.ChunkStore {
+	return &chunkRepository{db: db}
+}
+
+// Store inserts a chunk or increments its ref_count if it already exists.
+func (r *chunkRepository) Store(ctx context.Context, chunk *delta.Chunk) (bool, error) {
+	query := `
+		INSERT INTO cdc_chunks (chunk_hash, chunk_size, ref_count)
+		VALUES ($1, $2, 1)
+		ON CONFLICT (chunk_hash) DO UPDATE
+		SET ref_count = cdc_chunks.ref_count + 1
+		RETURNING (xmax = 0) AS is_new
+	`
+
+	var isNew bool
+	if err := r.db.Pool.QueryRow(ctx, query, chunk.Hash, chunk.Size).Scan(&isNew); err != nil {
+		return false, fmt.Errorf("failed to store chunk: %w", err)
+	}
+
+	return isNew, nil
+}
+
+// Get retrieves chunk metadata by hash.
+func (r *chunkRepository) Get(ctx context.Context, hash string) (*delta.Chunk, error) {
+	query := `SELECT chunk_hash, chunk_size FROM cdc_chunks WHERE chunk_hash = $1`
+
+	chunk := &delta.Chunk{}
+	if err := r.db.Pool.QueryRow(ctx, query, hash).Scan(&chunk.Hash, &chunk.Size); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("chunk not found: %s", hash)
+		}
+		return nil, fmt.Errorf("failed to get chunk: %w", err)
+	}
+
+	return chunk, nil
+}
+
+// IncrementRef increments the reference count for a chunk.
+func (r *chunkRepository) IncrementRef(ctx context.Context, hash string) error {
+	query := `UPDATE cdc_chunks SET ref_count = ref_count + 1 WHERE chunk_hash = $1`
+
+	result, err := r.db.Pool.Exec(ctx, query, hash)
+	if err != nil {
+		return fmt.Errorf("failed to increment chunk ref: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("chunk not found: %s", hash)
+	}
+
+	return nil
+}
+
+// DecrementRef decrements the reference count and returns the new count.
+func (r *chunkRepository) DecrementRef(ctx context.Context, hash string) (int, error) {
+	query := `
+		UPDATE cdc_chunks SET ref_count = GREATEST(ref_count - 1, 0)
+		WHERE chunk_hash = $1
+		RETURNING ref_count
+	`
+
+	var refCount int
+	if err := r.db.Pool.QueryRow(ctx, query, hash).Scan(&refCount); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, fmt.Errorf("chunk not found: %s", hash)
+		}
+		return 0, fmt.Errorf("failed to decrement chunk ref: %w", err)
+	}
+
+	return refCount, nil
+}
+
+// ListOrphans returns chunks with zero references.
+func (r *chunkRepository) ListOrphans(ctx context.Context, limit int) ([]delta.Chunk, error) {
+	query := `
+		SELECT chunk_hash, chunk_size FROM cdc_chunks
+		WHERE ref_count = 0
+		ORDER BY chunk_hash
+		LIMIT $1
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list orphan chunks: %w", err)
+	}
+	defer rows.Close()
+
+	var chunks []delta.Chunk
+	for rows.Next() {
+		var chunk delta.Chunk
+		if err := rows.Scan(&chunk.Hash, &chunk.Size); err != nil {
+			return nil, fmt.Errorf("failed to scan chunk: %w", err)
+		}
+		chunks = append(chunks, chunk)
+	}
+
+	return chunks, rows.Err()
+}
+
+// Stats returns aggregate chunk reuse statistics. The counters are kept
+// current by triggers on cdc_chunks, so this reads a single row.
+func (r *chunkRepository) Stats(ctx context.Context) (*delta.ChunkStats, error) {
+	query := `
+		SELECT unique_chunks, total_references, stored_bytes, logical_bytes
+		FROM cdc_chunk_stats
+		WHERE id = 1
+	`
+
+	stats := &delta.ChunkStats{}
+	err := r.db.Pool.QueryRow(ctx, query).Scan(
+		&stats.UniqueChunks,
+		&stats.TotalReferences,
+		&stats.StoredBytes,
+		&stats.LogicalBytes,
+	)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get chunk stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+// RecordBlobChunks replaces the blob_chunks rows of a blob with chunks, in order.
+func (r *chunkRepository) RecordBlobChunks(ctx context.Context, blobHash string, chunks []delta.Chunk) error {
+	return r.db.WithTx(ctx, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `DELETE FROM blob_chunks WHERE blob_hash = $1`, blobHash); err != nil {
+			return fmt.Errorf("failed to clear blob chunks: %w", err)
+		}
+
+		batch := &pgx.Batch{}
+		for i, chunk := range chunks {
+			batch.Queue(`
+				INSERT INTO blob_chunks (blob_hash, chunk_index, chunk_hash, chunk_offset)
+				VALUES ($1, $2, $3, $4)
+			`, blobHash, i, chunk.Hash, chunk.Offset)
+		}
+		if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+			return fmt.Errorf("failed to record blob chunks: %w", err)
+		}
+		return nil
+	})
+}
+
+// BeginRebuild stages a rebuilt index in temporary tables on a dedicated
+// connection. cdc_chunks and blob_chunks are only touched by Commit, which
+// swaps the staged rows in within one transaction.
+func (r *chunkRepository) BeginRebuild(ctx context.Context) (delta.ChunkRebuild, error) {
+	conn, err := r.db.Pool.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	rebuild := &chunkRebuild{conn: conn}
+
+	statements := []string{
+		`CREATE TEMP TABLE cdc_chunks_rebuild (
+			chunk_hash VARCHAR(64) PRIMARY KEY,
+			chunk_size INTEGER NOT NULL,
+			ref_count INTEGER NOT NULL
+		)`,
+		`CREATE TEMP TABLE blob_chunks_rebuild (
+			blob_hash VARCHAR(64) NOT NULL,
+			chunk_index INTEGER NOT NULL,
+			chunk_hash VARCHAR(64) NOT NULL,
+			chunk_offset BIGINT NOT NULL,
+			PRIMARY KEY (blob_hash, chunk_index)
+		)`,
+	}
+	for _, stmt := range statements {
+		if _, err := conn.Exec(ctx, stmt); err != nil {
+			_ = rebuild.Abort(context.WithoutCancel(ctx))
+			return nil, fmt.Errorf("failed to create rebuild tables: %w", err)
+		}
+	}
+
+	return rebuild, nil
+}
+
+// chunkRebuild is a chunk index rebuild staged in temporary tables. The
+// tables live on conn, so they are private to the rebuild.
+type chunkRebuild struct {
+	conn *pgxpool.Conn
+}
+
+// Store stages a chunk or increments its staged ref_count.
+func (b *chunkRebuild) Store(ctx context.Context, chunk *delta.Chunk) (bool, error) {
+	query := `
+		INSERT INTO cdc_chunks_rebuild (chunk_hash, chunk_size, ref_count)
+		VALUES ($1, $2, 1)
+		ON CONFLICT (chunk_hash) DO UPDATE
+		SET ref_count = cdc_chunks_rebuild.ref_count + 1
+		RETURNING (xmax = 0) AS is_new
+	`
+
+	var isNew bool
+	if err := b.conn.QueryRow(ctx, query, chunk.Hash, chunk.Size).Scan(&isNew); err != nil {
+		return false, fmt.Errorf("failed to stage chunk: %w", err)
+	}
+
+	return isNew, nil
+}
+
+// RecordBlobChunks stages the chunk list of a blob, in order.
+func (b *chunkRebuild) RecordBlobChunks(ctx context.Context, blobHash string, chunks []delta.Chunk) error {
+	batch := &pgx.Batch{}
+	batch.Queue(`DELETE FROM blob_chunks_rebuild WHERE blob_hash = $1`, blobHash)
+	for i, chunk := range chunks {
+		batch.Queue(`
+			INSERT INTO blob_chunks_rebuild (blob_hash, chunk_index, chunk_hash, chunk_offset)
+			VALUES ($1, $2, $3, $4)
+		`, blobHash, i, chunk.Hash, chunk.Offset)
+	}
+	if err := b.conn.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to stage blob chunks: %w", err)
+	}
+	return nil
+}
+
+// Commit replaces the chunk index with the staged one in a single
+// transaction. Mappings of blobs deleted during the rebuild are dropped.
+func (b *chunkRebuild) Commit(ctx context.Context) error {
+	if b.conn == nil {
+		return errors.New("chunk rebuild already finished")
+	}
+
+	tx, err := b.conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM blob_chunks`); err != nil {
+		return fmt.Errorf("failed to clear blob chunks: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM cdc_chunks`); err != nil {
+		return fmt.Errorf("failed to clear chunks: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO cdc_chunks (chunk_hash, chunk_size, ref_count)
+		SELECT chunk_hash, chunk_size, ref_count FROM cdc_chunks_rebuild
+	`); err != nil {
+		return fmt.Errorf("failed to swap in chunks: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO blob_chunks (blob_hash, chunk_index, chunk_hash, chunk_offset)
+		SELECT r.blob_hash, r.chunk_index, r.chunk_hash, r.chunk_offset
+		FROM blob_chunks_rebuild r
+		JOIN blobs b ON b.content_hash = r.blob_hash
+	`); err != nil {
+		return fmt.Errorf("failed to swap in blob chunks: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	// The new index is live; failing to drop the staging tables only costs
+	// the connection
+	_ = b.Abort(context.WithoutCancel(ctx))
+	return nil
+}
+
+// Abort drops the staging tables and returns the connection to the pool.
+func (b *chunkRebuild) Abort(ctx context.Context) error {
+	if b.conn == nil {
+		return nil
+	}
+	conn := b.conn
+	b.conn = nil
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `DROP TABLE IF EXISTS cdc_chunks_rebuild, blob_chunks_rebuild`); err != nil {
+		// A connection left in an unknown state must not go back to the pool
+		_ = conn.Conn().Close(context.Background())
+		return fmt.Errorf("failed to drop rebuild tables: %w", err)
+	}
+	return nil
+}
+
+// Ensure chunkRepository records blob chunk mappings
+var _ delta.BlobChunkRecorder = (*chunkRepository)(nil)
+var _ delta.ChunkRebuild = (*chunkRebuild)(nil)
@@ -0,0 +1,80 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/delta"
+)
+
+func TestChunkRepository_StatsAndStagedRebuild(t *testing.T) {
+	db := openAccessTrackerDB(t)
+	ctx := context.Background()
+	// DELETE rather than TRUNCATE, which would bypass the stats triggers
+	_, err := db.Pool.Exec(ctx, "DELETE FROM blob_chunks")
+	require.NoError(t, err)
+	_, err = db.Pool.Exec(ctx, "DELETE FROM cdc_chunks")
+	require.NoError(t, err)
+
+	repo := NewChunkRepository(db)
+	shared := &delta.Chunk{Hash: testContentHash("shared"), Size: 100}
+	single := &delta.Chunk{Hash: testContentHash("single"), Size: 10}
+	for _, chunk := range []*delta.Chunk{shared, shared, single} {
+		_, err := repo.Store(ctx, chunk)
+		require.NoError(t, err)
+	}
+
+	stats, err := repo.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, delta.ChunkStats{UniqueChunks: 2, TotalReferences: 3, StoredBytes: 110, LogicalBytes: 210}, *stats)
+
+	// Orphaned chunks no longer count
+	refs, err := repo.DecrementRef(ctx, single.Hash)
+	require.NoError(t, err)
+	require.Zero(t, refs)
+	stats, err = repo.Stats(ctx)
+	require.NoError(t, err)
+	current := delta.ChunkStats{UniqueChunks: 1, TotalReferences: 2, StoredBytes: 100, LogicalBytes: 200}
+	assert.Equal(t, current, *stats)
+
+	// An aborted rebuild leaves the index alone
+	rebuild, err := repo.BeginRebuild(ctx)
+	require.NoError(t, err)
+	_, err = rebuild.Store(ctx, &delta.Chunk{Hash: testContentHash("staged"), Size: 50})
+	require.NoError(t, err)
+	require.NoError(t, rebuild.Abort(ctx))
+	stats, err = repo.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, current, *stats)
+
+	// A committed one replaces it
+	blobHash := testContentHash("rebuilt blob")
+	_, err = db.Pool.Exec(ctx, `
+		INSERT INTO blobs (content_hash, size, storage_path) VALUES ($1, 50, 'rebuilt')
+		ON CONFLICT (content_hash) DO NOTHING
+	`, blobHash)
+	require.NoError(t, err)
+
+	staged := delta.Chunk{Hash: testContentHash("staged"), Size: 50}
+	rebuild, err = repo.BeginRebuild(ctx)
+	require.NoError(t, err)
+	isNew, err := rebuild.Store(ctx, &staged)
+	require.NoError(t, err)
+	assert.True(t, isNew)
+	require.NoError(t, rebuild.RecordBlobChunks(ctx, blobHash, []delta.Chunk{staged}))
+	require.NoError(t, rebuild.Commit(ctx))
+	require.NoError(t, rebuild.Abort(ctx))
+
+	stats, err = repo.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, delta.ChunkStats{UniqueChunks: 1, TotalReferences: 1, StoredBytes: 50, LogicalBytes: 50}, *stats)
+	_, err = repo.Get(ctx, shared.Hash)
+	assert.Error(t, err)
+
+	var mapped string
+	require.NoError(t, db.Pool.QueryRow(ctx, `SELECT chunk_hash FROM blob_chunks WHERE blob_hash = $1`, blobHash).Scan(&mapped))
+	assert.Equal(t, staged.Hash, mapped)
+}
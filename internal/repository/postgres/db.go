@@ -26,14 +26,9 @@ func NewDB(ctx context.Context, cfg config.DatabaseConfig, logger zerolog.Logger
 		return nil, fmt.Errorf("failed to parse database config: %w", err)
 	}
 
-	// Configure pool settings
-	poolConfig.MaxConns = int32(cfg.MaxOpenConns)
-	poolConfig.MinConns = int32(cfg.MaxIdleConns)
-	poolConfig.MaxConnLifetime = cfg.ConnMaxLifetime
-	poolConfig.MaxConnIdleTime = cfg.ConnMaxIdleTime
-
-	// Configure connection settings
-	poolConfig.ConnConfig.ConnectTimeout = 10 * time.Second
+	if err := applyPoolSettings(poolConfig, cfg); err != nil {
+		return nil, err
+	}
 
 	// Add query tracer for debugging (optional)
 	if logger.GetLevel() <= zerolog.DebugLevel {
@@ -56,6 +51,8 @@ func NewDB(ctx context.Context, cfg config.DatabaseConfig, logger zerolog.Logger
 		Int("port", cfg.Port).
 		Str("database", cfg.Database).
 		Int("max_conns", cfg.MaxOpenConns).
+		Str("exec_mode", poolConfig.ConnConfig.DefaultQueryExecMode.String()).
+		Int("statement_cache_capacity", poolConfig.ConnConfig.StatementCacheCapacity).
 		Msg("connected to PostgreSQL")
 
 	return &DB{
@@ -64,6 +61,44 @@ func NewDB(ctx context.Context, cfg config.DatabaseConfig, logger zerolog.Logger
 	}, nil
 }
 
+// queryExecModes maps config names to pgx query execution modes.
+var queryExecModes = map[string]pgx.QueryExecMode{
+	"cache_statement": pgx.QueryExecModeCacheStatement,
+	"cache_describe":  pgx.QueryExecModeCacheDescribe,
+	"describe_exec":   pgx.QueryExecModeDescribeExec,
+	"exec":            pgx.QueryExecModeExec,
+	"simple_protocol": pgx.QueryExecModeSimpleProtocol,
+}
+
+// applyPoolSettings applies pool sizing and statement caching from cfg.
+// With the default "cache_statement" mode every connection prepares each
+// distinct SQL string once and reuses the prepared statement afterwards.
+func applyPoolSettings(poolConfig *pgxpool.Config, cfg config.DatabaseConfig) error {
+	// Configure pool settings
+	poolConfig.MaxConns = int32(cfg.MaxOpenConns)
+	poolConfig.MinConns = int32(cfg.MaxIdleConns)
+	poolConfig.MaxConnLifetime = cfg.ConnMaxLifetime
+	poolConfig.MaxConnIdleTime = cfg.ConnMaxIdleTime
+
+	// Configure connection settings
+	poolConfig.ConnConfig.ConnectTimeout = 10 * time.Second
+
+	// Configure statement caching
+	if cfg.StatementCacheMode != "" {
+		mode, ok := queryExecModes[cfg.StatementCacheMode]
+		if !ok {
+			return fmt.Errorf("invalid statement cache mode: %s", cfg.StatementCacheMode)
+		}
+		poolConfig.ConnConfig.DefaultQueryExecMode = mode
+	}
+	if cfg.StatementCacheCapacity > 0 {
+		poolConfig.ConnConfig.StatementCacheCapacity = cfg.StatementCacheCapacity
+		poolConfig.ConnConfig.DescriptionCacheCapacity = cfg.StatementCacheCapacity
+	}
+
+	return nil
+}
+
 // Close closes the database connection pool.
 func (db *DB) Close() error {
 	db.Pool.Close()
@@ -86,6 +121,23 @@ func (db *DB) Stats() *pgxpool.Stat {
 	return db.Pool.Stat()
 }
 
+// ReportStats calls report with a pool statistics snapshot every interval
+// until ctx is cancelled.
+func (db *DB) ReportStats(ctx context.Context, interval time.Duration, report func(*pgxpool.Stat)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		report(db.Pool.Stat())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
 // BeginTx starts a new transaction with the given options.
 func (db *DB) BeginTx(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, error) {
 	return db.Pool.BeginTx(ctx, opts)
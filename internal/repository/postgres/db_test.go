@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"context"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/config"
+)
+
+func TestApplyPoolSettings(t *testing.T) {
+	poolConfig, err := pgxpool.ParseConfig("host=localhost user=alexander dbname=alexander")
+	require.NoError(t, err)
+
+	err = applyPoolSettings(poolConfig, config.DatabaseConfig{
+		MaxOpenConns:           8,
+		MaxIdleConns:           2,
+		ConnMaxLifetime:        time.Minute,
+		StatementCacheMode:     "cache_statement",
+		StatementCacheCapacity: 64,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, int32(8), poolConfig.MaxConns)
+	require.Equal(t, int32(2), poolConfig.MinConns)
+	require.Equal(t, time.Minute, poolConfig.MaxConnLifetime)
+	require.Equal(t, pgx.QueryExecModeCacheStatement, poolConfig.ConnConfig.DefaultQueryExecMode)
+	require.Equal(t, 64, poolConfig.ConnConfig.StatementCacheCapacity)
+
+	err = applyPoolSettings(poolConfig, config.DatabaseConfig{MaxOpenConns: 1, StatementCacheMode: "bogus"})
+	require.Error(t, err)
+}
+
+// TestPoolRespectsMaxConns needs a live database; set ALEXANDER_TEST_POSTGRES_DSN to run it.
+func TestPoolRespectsMaxConns(t *testing.T) {
+	dsn := os.Getenv("ALEXANDER_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("ALEXANDER_TEST_POSTGRES_DSN not set")
+	}
+
+	const maxConns = 3
+	poolConfig, err := pgxpool.ParseConfig(dsn)
+	require.NoError(t, err)
+	require.NoError(t, applyPoolSettings(poolConfig, config.DatabaseConfig{
+		MaxOpenConns:       maxConns,
+		StatementCacheMode: "cache_statement",
+	}))
+
+	ctx := context.Background()
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	var peak atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			conn, err := pool.Acquire(ctx)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer conn.Release()
+
+			inUse := pool.Stat().AcquiredConns()
+			for {
+				current := peak.Load()
+				if inUse <= current || peak.CompareAndSwap(current, inUse) {
+					break
+				}
+			}
+
+			_, err = conn.Exec(ctx, "SELECT pg_sleep(0.05)")
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	stat := pool.Stat()
+	require.LessOrEqual(t, peak.Load(), int32(maxConns))
+	require.LessOrEqual(t, stat.TotalConns(), int32(maxConns))
+	require.Greater(t, stat.EmptyAcquireCount(), int64(0))
+}
@@ -0,0 +1,162 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/prn-tf/alexander-storage/internal/storage"
+)
+
+// deltaChainRepository implements storage.DeltaChainStore on the
+// delta_chains table.
+type deltaChainRepository struct {
+	db *DB
+}
+
+// NewDeltaChainRepository creates a new PostgreSQL delta chain store.
+func NewDeltaChainRepository(db *DB) storage.DeltaChainStore {
+	return &deltaChainRepository{db: db}
+}
+
+// GetDelta returns the link of a blob stored as a delta.
+func (r *deltaChainRepository) GetDelta(ctx context.Context, contentHash string) (*storage.DeltaLink, error) {
+	query := `
+		SELECT content_hash, base_hash, data_hash, depth, size, data_size, instructions, created_at
+		FROM delta_chains
+		WHERE content_hash = $1
+	`
+
+	link := &storage.DeltaLink{}
+	var instructions []byte
+
+	err := r.db.Pool.QueryRow(ctx, query, contentHash).Scan(
+		&link.ContentHash,
+		&link.BaseHash,
+		&link.DataHash,
+		&link.Depth,
+		&link.Size,
+		&link.DataSize,
+		&instructions,
+		&link.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, storage.ErrDeltaNotFound
+		}
+		return nil, fmt.Errorf("failed to get delta: %w", err)
+	}
+
+	if err := json.Unmarshal(instructions, &link.Instructions); err != nil {
+		return nil, fmt.Errorf("failed to decode delta instructions: %w", err)
+	}
+
+	return link, nil
+}
+
+// CreateDelta records a link and takes a reference on its base and data blobs.
+func (r *deltaChainRepository) CreateDelta(ctx context.Context, link *storage.DeltaLink) (bool, error) {
+	instructions, err := json.Marshal(link.Instructions)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode delta instructions: %w", err)
+	}
+
+	created := false
+	err = r.db.WithTx(ctx, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		result, err := tx.Exec(ctx, `
+			INSERT INTO delta_chains (content_hash, base_hash, data_hash, depth, size, data_size, instructions, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			ON CONFLICT (content_hash) DO NOTHING
+		`,
+			link.ContentHash,
+			link.BaseHash,
+			link.DataHash,
+			link.Depth,
+			link.Size,
+			link.DataSize,
+			instructions,
+			link.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert delta: %w", err)
+		}
+		if result.RowsAffected() == 0 {
+			return nil
+		}
+
+		result, err = tx.Exec(ctx,
+			`UPDATE blobs SET ref_count = ref_count + 1 WHERE content_hash = $1`,
+			link.BaseHash,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to reference delta base: %w", err)
+		}
+		if result.RowsAffected() == 0 {
+			return storage.ErrBlobNotFound
+		}
+
+		_, err = tx.Exec(ctx, `
+			INSERT INTO blobs (content_hash, size, storage_path, ref_count, created_at)
+			VALUES ($1, $2, $3, 1, $4)
+			ON CONFLICT (content_hash) DO UPDATE SET ref_count = blobs.ref_count + 1
+		`, link.DataHash, link.DataSize, link.DataPath, time.Now().UTC())
+		if err != nil {
+			return fmt.Errorf("failed to reference delta data: %w", err)
+		}
+
+		created = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return created, nil
+}
+
+// DeleteDelta removes the link of a blob and releases its references.
+func (r *deltaChainRepository) DeleteDelta(ctx context.Context, contentHash string) error {
+	return r.db.WithTx(ctx, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		var baseHash, dataHash string
+		err := tx.QueryRow(ctx,
+			`DELETE FROM delta_chains WHERE content_hash = $1 RETURNING base_hash, data_hash`,
+			contentHash,
+		).Scan(&baseHash, &dataHash)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return storage.ErrDeltaNotFound
+			}
+			return fmt.Errorf("failed to delete delta: %w", err)
+		}
+
+		// The base and data blob may be the same blob, referenced twice
+		for _, hash := range []string{baseHash, dataHash} {
+			_, err := tx.Exec(ctx,
+				`UPDATE blobs SET ref_count = GREATEST(ref_count - 1, 0) WHERE content_hash = $1`,
+				hash,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to release delta reference: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// HasDeltas reports whether any link uses the blob as its base.
+func (r *deltaChainRepository) HasDeltas(ctx context.Context, baseHash string) (bool, error) {
+	var exists bool
+	err := r.db.Pool.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM delta_chains WHERE base_hash = $1)`,
+		baseHash,
+	).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check delta dependents: %w", err)
+	}
+	return exists, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 
@@ -24,8 +25,8 @@ func NewLifecycleRepository(db *DB) repository.LifecycleRepository {
 // Create creates a new lifecycle rule.
 func (r *lifecycleRepository) Create(ctx context.Context, rule *domain.LifecycleRule) error {
 	query := `
-		INSERT INTO lifecycle_rules (bucket_id, rule_id, prefix, expiration_days, status, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO lifecycle_rules (bucket_id, rule_id, prefix, expiration_days, expiration_date, transition_days, transition_date, transition_storage_class, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		RETURNING id
 	`
 
@@ -34,6 +35,10 @@ func (r *lifecycleRepository) Create(ctx context.Context, rule *domain.Lifecycle
 		rule.RuleID,
 		rule.Prefix,
 		rule.ExpirationDays,
+		rule.ExpirationDate,
+		rule.TransitionDays,
+		rule.TransitionDate,
+		rule.TransitionStorageClass,
 		rule.Status,
 		rule.CreatedAt,
 		rule.UpdatedAt,
@@ -52,7 +57,7 @@ func (r *lifecycleRepository) Create(ctx context.Context, rule *domain.Lifecycle
 // GetByID retrieves a lifecycle rule by ID.
 func (r *lifecycleRepository) GetByID(ctx context.Context, id int64) (*domain.LifecycleRule, error) {
 	query := `
-		SELECT id, bucket_id, rule_id, prefix, expiration_days, status, created_at, updated_at
+		SELECT id, bucket_id, rule_id, prefix, expiration_days, expiration_date, transition_days, transition_date, transition_storage_class, status, created_at, updated_at
 		FROM lifecycle_rules
 		WHERE id = $1
 	`
@@ -64,6 +69,10 @@ func (r *lifecycleRepository) GetByID(ctx context.Context, id int64) (*domain.Li
 		&rule.RuleID,
 		&rule.Prefix,
 		&rule.ExpirationDays,
+		&rule.ExpirationDate,
+		&rule.TransitionDays,
+		&rule.TransitionDate,
+		&rule.TransitionStorageClass,
 		&rule.Status,
 		&rule.CreatedAt,
 		&rule.UpdatedAt,
@@ -82,7 +91,7 @@ func (r *lifecycleRepository) GetByID(ctx context.Context, id int64) (*domain.Li
 // GetByBucketAndRuleID retrieves a rule by bucket ID and rule ID.
 func (r *lifecycleRepository) GetByBucketAndRuleID(ctx context.Context, bucketID int64, ruleID string) (*domain.LifecycleRule, error) {
 	query := `
-		SELECT id, bucket_id, rule_id, prefix, expiration_days, status, created_at, updated_at
+		SELECT id, bucket_id, rule_id, prefix, expiration_days, expiration_date, transition_days, transition_date, transition_storage_class, status, created_at, updated_at
 		FROM lifecycle_rules
 		WHERE bucket_id = $1 AND rule_id = $2
 	`
@@ -94,6 +103,10 @@ func (r *lifecycleRepository) GetByBucketAndRuleID(ctx context.Context, bucketID
 		&rule.RuleID,
 		&rule.Prefix,
 		&rule.ExpirationDays,
+		&rule.ExpirationDate,
+		&rule.TransitionDays,
+		&rule.TransitionDate,
+		&rule.TransitionStorageClass,
 		&rule.Status,
 		&rule.CreatedAt,
 		&rule.UpdatedAt,
@@ -112,7 +125,7 @@ func (r *lifecycleRepository) GetByBucketAndRuleID(ctx context.Context, bucketID
 // ListByBucket returns all lifecycle rules for a bucket.
 func (r *lifecycleRepository) ListByBucket(ctx context.Context, bucketID int64) ([]*domain.LifecycleRule, error) {
 	query := `
-		SELECT id, bucket_id, rule_id, prefix, expiration_days, status, created_at, updated_at
+		SELECT id, bucket_id, rule_id, prefix, expiration_days, expiration_date, transition_days, transition_date, transition_storage_class, status, created_at, updated_at
 		FROM lifecycle_rules
 		WHERE bucket_id = $1
 		ORDER BY rule_id ASC
@@ -133,6 +146,10 @@ func (r *lifecycleRepository) ListByBucket(ctx context.Context, bucketID int64)
 			&rule.RuleID,
 			&rule.Prefix,
 			&rule.ExpirationDays,
+			&rule.ExpirationDate,
+			&rule.TransitionDays,
+			&rule.TransitionDate,
+			&rule.TransitionStorageClass,
 			&rule.Status,
 			&rule.CreatedAt,
 			&rule.UpdatedAt,
@@ -153,7 +170,7 @@ func (r *lifecycleRepository) ListByBucket(ctx context.Context, bucketID int64)
 // ListEnabledByBucket returns only enabled rules for a bucket.
 func (r *lifecycleRepository) ListEnabledByBucket(ctx context.Context, bucketID int64) ([]*domain.LifecycleRule, error) {
 	query := `
-		SELECT id, bucket_id, rule_id, prefix, expiration_days, status, created_at, updated_at
+		SELECT id, bucket_id, rule_id, prefix, expiration_days, expiration_date, transition_days, transition_date, transition_storage_class, status, created_at, updated_at
 		FROM lifecycle_rules
 		WHERE bucket_id = $1 AND status = 'Enabled'
 		ORDER BY rule_id ASC
@@ -174,6 +191,10 @@ func (r *lifecycleRepository) ListEnabledByBucket(ctx context.Context, bucketID
 			&rule.RuleID,
 			&rule.Prefix,
 			&rule.ExpirationDays,
+			&rule.ExpirationDate,
+			&rule.TransitionDays,
+			&rule.TransitionDate,
+			&rule.TransitionStorageClass,
 			&rule.Status,
 			&rule.CreatedAt,
 			&rule.UpdatedAt,
@@ -195,7 +216,8 @@ func (r *lifecycleRepository) ListEnabledByBucket(ctx context.Context, bucketID
 func (r *lifecycleRepository) Update(ctx context.Context, rule *domain.LifecycleRule) error {
 	query := `
 		UPDATE lifecycle_rules
-		SET prefix = $2, expiration_days = $3, status = $4, updated_at = NOW()
+		SET prefix = $2, expiration_days = $3, expiration_date = $4, transition_days = $5, transition_date = $6,
+			transition_storage_class = $7, status = $8, updated_at = NOW()
 		WHERE id = $1
 	`
 
@@ -203,6 +225,10 @@ func (r *lifecycleRepository) Update(ctx context.Context, rule *domain.Lifecycle
 		rule.ID,
 		rule.Prefix,
 		rule.ExpirationDays,
+		rule.ExpirationDate,
+		rule.TransitionDays,
+		rule.TransitionDate,
+		rule.TransitionStorageClass,
 		rule.Status,
 	)
 
@@ -264,7 +290,7 @@ func (r *lifecycleRepository) DeleteByBucket(ctx context.Context, bucketID int64
 // ListAllEnabled returns all enabled lifecycle rules across all buckets.
 func (r *lifecycleRepository) ListAllEnabled(ctx context.Context) ([]*domain.LifecycleRule, error) {
 	query := `
-		SELECT id, bucket_id, rule_id, prefix, expiration_days, status, created_at, updated_at
+		SELECT id, bucket_id, rule_id, prefix, expiration_days, expiration_date, transition_days, transition_date, transition_storage_class, status, created_at, updated_at
 		FROM lifecycle_rules
 		WHERE status = 'Enabled'
 		ORDER BY bucket_id ASC, rule_id ASC
@@ -285,6 +311,10 @@ func (r *lifecycleRepository) ListAllEnabled(ctx context.Context) ([]*domain.Lif
 			&rule.RuleID,
 			&rule.Prefix,
 			&rule.ExpirationDays,
+			&rule.ExpirationDate,
+			&rule.TransitionDays,
+			&rule.TransitionDate,
+			&rule.TransitionStorageClass,
 			&rule.Status,
 			&rule.CreatedAt,
 			&rule.UpdatedAt,
@@ -302,5 +332,57 @@ func (r *lifecycleRepository) ListAllEnabled(ctx context.Context) ([]*domain.Lif
 	return rules, nil
 }
 
+// ListTransitionCandidates returns latest objects older than cutoff, with optional
+// prefix, that were not transitioned to any of the given classes.
+func (r *lifecycleRepository) ListTransitionCandidates(ctx context.Context, bucketID int64, prefix string, olderThan time.Time, satisfiedBy []domain.TransitionClass, limit int) ([]*domain.Object, error) {
+	query := `
+		SELECT o.id, o.bucket_id, o.key, o.version_id, o.is_latest, o.is_delete_marker,
+			o.content_hash, o.size, o.content_type, o.etag, o.storage_class, o.metadata, o.created_at, o.deleted_at,
+			o.sse_customer_algorithm, o.sse_customer_key_md5, o.checksum_algorithm, o.checksum,
+			o.cache_control, o.content_disposition, o.content_encoding, o.expires
+		FROM objects o
+		WHERE o.bucket_id = $1
+			AND o.is_latest = TRUE
+			AND o.is_delete_marker = FALSE
+			AND o.deleted_at IS NULL
+			AND o.created_at < $2
+			AND ($3 = '' OR o.key LIKE $3 || '%')
+			AND NOT EXISTS (
+				SELECT 1 FROM object_transitions t
+				WHERE t.object_id = o.id AND t.storage_class = ANY($4)
+			)
+		ORDER BY o.created_at ASC
+		LIMIT $5
+	`
+
+	classes := make([]string, len(satisfiedBy))
+	for i, class := range satisfiedBy {
+		classes[i] = string(class)
+	}
+
+	rows, err := r.db.Pool.Query(ctx, query, bucketID, olderThan, prefix, classes, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transition candidates: %w", err)
+	}
+	defer rows.Close()
+
+	return scanObjects(rows)
+}
+
+// MarkTransitioned records that an object was moved to a storage class.
+func (r *lifecycleRepository) MarkTransitioned(ctx context.Context, objectID int64, class domain.TransitionClass) error {
+	query := `
+		INSERT INTO object_transitions (object_id, storage_class, transitioned_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (object_id) DO UPDATE SET storage_class = EXCLUDED.storage_class, transitioned_at = EXCLUDED.transitioned_at
+	`
+
+	if _, err := r.db.Pool.Exec(ctx, query, objectID, string(class)); err != nil {
+		return fmt.Errorf("failed to mark object transitioned: %w", err)
+	}
+
+	return nil
+}
+
 // Ensure lifecycleRepository implements repository.LifecycleRepository
 var _ repository.LifecycleRepository = (*lifecycleRepository)(nil)
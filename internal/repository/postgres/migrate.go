@@ -0,0 +1,116 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/prn-tf/alexander-storage/internal/repository/migrate"
+	"github.com/prn-tf/alexander-storage/migrations"
+)
+
+// migrationLockKey is the advisory lock that serializes migrations across
+// server instances starting at the same time.
+const migrationLockKey = 7243010531
+
+// Migrate applies the embedded migrations that are newer than the recorded
+// schema version. It is safe to call on every startup and from several
+// instances at once.
+func (db *DB) Migrate(ctx context.Context) error {
+	all, err := migrate.Load(migrations.Postgres, "postgres")
+	if err != nil {
+		return err
+	}
+
+	conn, err := db.Pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, migrationLockKey); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer func() {
+		_, _ = conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockKey)
+	}()
+
+	_, err = migrate.NewRunner(&migrationDriver{conn: conn}, all, db.logger).Up(ctx)
+	return err
+}
+
+// SchemaVersion returns the latest applied migration version.
+func (db *DB) SchemaVersion(ctx context.Context) (int, error) {
+	conn, err := db.Pool.Acquire(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	return migrate.NewRunner(&migrationDriver{conn: conn}, nil, db.logger).Version(ctx)
+}
+
+// errDirtyMigration is returned when a migration applied by an external tool
+// failed halfway and left the schema in an unknown state.
+var errDirtyMigration = errors.New("database schema is dirty; fix it and force the version with the migrate CLI")
+
+// migrationDriver keeps the golang-migrate schema_migrations layout (a single
+// version/dirty row), so databases migrated with `make migrate-up` are picked
+// up where they left off.
+type migrationDriver struct {
+	conn *pgxpool.Conn
+}
+
+// EnsureVersionTable creates the schema_migrations table.
+func (d *migrationDriver) EnsureVersionTable(ctx context.Context) error {
+	_, err := d.conn.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT NOT NULL PRIMARY KEY,
+			dirty BOOLEAN NOT NULL
+		)
+	`)
+	return err
+}
+
+// Version returns the recorded version.
+func (d *migrationDriver) Version(ctx context.Context) (int, error) {
+	var version int64
+	var dirty bool
+	err := d.conn.QueryRow(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if dirty {
+		return 0, fmt.Errorf("%w (version %d)", errDirtyMigration, version)
+	}
+	return int(version), nil
+}
+
+// Apply runs the migration and records it in one transaction.
+func (d *migrationDriver) Apply(ctx context.Context, m migrate.Migration) error {
+	tx, err := d.conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// Without arguments pgx uses the simple protocol, which allows the
+	// multi-statement migration files
+	if _, err := tx.Exec(ctx, m.UpSQL); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM schema_migrations`); err != nil {
+		return fmt.Errorf("failed to record migration: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)`, m.Version); err != nil {
+		return fmt.Errorf("failed to record migration: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
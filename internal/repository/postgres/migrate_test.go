@@ -0,0 +1,43 @@
+package postgres
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/repository/migrate"
+	"github.com/prn-tf/alexander-storage/migrations"
+)
+
+// TestDB_Migrate needs a live, empty database; set ALEXANDER_TEST_POSTGRES_DSN to run it.
+func TestDB_Migrate(t *testing.T) {
+	dsn := os.Getenv("ALEXANDER_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("ALEXANDER_TEST_POSTGRES_DSN not set")
+	}
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, dsn)
+	require.NoError(t, err)
+	defer pool.Close()
+	db := &DB{Pool: pool, logger: zerolog.Nop()}
+
+	all, err := migrate.Load(migrations.Postgres, "postgres")
+	require.NoError(t, err)
+	latest := all[len(all)-1].Version
+
+	require.NoError(t, db.Migrate(ctx))
+	version, err := db.SchemaVersion(ctx)
+	require.NoError(t, err)
+	require.Equal(t, latest, version)
+
+	// Re-running is a no-op
+	require.NoError(t, db.Migrate(ctx))
+	version, err = db.SchemaVersion(ctx)
+	require.NoError(t, err)
+	require.Equal(t, latest, version)
+}
@@ -26,8 +26,9 @@ func NewMultipartRepository(db *DB) repository.MultipartUploadRepository {
 // Create creates a new multipart upload.
 func (r *multipartRepository) Create(ctx context.Context, upload *domain.MultipartUpload) error {
 	query := `
-		INSERT INTO multipart_uploads (id, bucket_id, key, initiator_id, status, storage_class, metadata, initiated_at, expires_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO multipart_uploads (id, bucket_id, key, initiator_id, status, storage_class, metadata, initiated_at, expires_at,
+			checksum_algorithm, cache_control, content_disposition, content_encoding, expires)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 	`
 
 	_, err := r.db.Pool.Exec(ctx, query,
@@ -40,6 +41,11 @@ func (r *multipartRepository) Create(ctx context.Context, upload *domain.Multipa
 		upload.Metadata,
 		upload.InitiatedAt,
 		upload.ExpiresAt,
+		upload.ChecksumAlgorithm,
+		upload.CacheControl,
+		upload.ContentDisposition,
+		upload.ContentEncoding,
+		upload.Expires,
 	)
 
 	if err != nil {
@@ -52,7 +58,8 @@ func (r *multipartRepository) Create(ctx context.Context, upload *domain.Multipa
 // GetByID retrieves a multipart upload by ID.
 func (r *multipartRepository) GetByID(ctx context.Context, uploadID uuid.UUID) (*domain.MultipartUpload, error) {
 	query := `
-		SELECT id, bucket_id, key, initiator_id, status, storage_class, metadata, initiated_at, expires_at, completed_at
+		SELECT id, bucket_id, key, initiator_id, status, storage_class, metadata, initiated_at, expires_at, completed_at,
+			checksum_algorithm, cache_control, content_disposition, content_encoding, expires
 		FROM multipart_uploads
 		WHERE id = $1
 	`
@@ -69,6 +76,11 @@ func (r *multipartRepository) GetByID(ctx context.Context, uploadID uuid.UUID) (
 		&upload.InitiatedAt,
 		&upload.ExpiresAt,
 		&upload.CompletedAt,
+		&upload.ChecksumAlgorithm,
+		&upload.CacheControl,
+		&upload.ContentDisposition,
+		&upload.ContentEncoding,
+		&upload.Expires,
 	)
 
 	if err != nil {
@@ -88,17 +100,21 @@ func (r *multipartRepository) List(ctx context.Context, bucketID int64, opts rep
 		maxUploads = 1000
 	}
 
+	// The upload ID marker continues within the key marker, after the
+	// marker upload; without one the listing continues after the key
 	query := `
 		SELECT id, key, initiated_at, storage_class
 		FROM multipart_uploads
 		WHERE bucket_id = $1 AND status = $2
 			AND ($3 = '' OR key LIKE $3 || '%')
-			AND ($4 = '' OR key > $4)
-		ORDER BY key ASC, initiated_at ASC
-		LIMIT $5
+			AND ($4 = '' OR key > $4 OR (key = $4 AND $5 <> '' AND (initiated_at, id) > (
+				SELECT initiated_at, id FROM multipart_uploads WHERE id::text = $5
+			)))
+		ORDER BY key ASC, initiated_at ASC, id ASC
+		LIMIT $6
 	`
 
-	rows, err := r.db.Pool.Query(ctx, query, bucketID, domain.MultipartStatusInProgress, opts.Prefix, opts.KeyMarker, maxUploads+1)
+	rows, err := r.db.Pool.Query(ctx, query, bucketID, domain.MultipartStatusInProgress, escapeLike(opts.Prefix), opts.KeyMarker, opts.UploadIDMarker, maxUploads+1)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list uploads: %w", err)
 	}
@@ -182,6 +198,18 @@ func (r *multipartRepository) Delete(ctx context.Context, uploadID uuid.UUID) er
 	})
 }
 
+// CountInProgress returns the number of in-progress multipart uploads.
+func (r *multipartRepository) CountInProgress(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.Pool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM multipart_uploads WHERE status = $1
+	`, domain.MultipartStatusInProgress).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count in-progress uploads: %w", err)
+	}
+	return count, nil
+}
+
 // DeleteExpired deletes expired multipart uploads.
 func (r *multipartRepository) DeleteExpired(ctx context.Context) (int64, error) {
 	// First delete parts for expired uploads
@@ -211,10 +239,11 @@ func (r *multipartRepository) DeleteExpired(ctx context.Context) (int64, error)
 // CreatePart creates a new upload part.
 func (r *multipartRepository) CreatePart(ctx context.Context, part *domain.UploadPart) error {
 	query := `
-		INSERT INTO upload_parts (upload_id, part_number, content_hash, size, etag, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO upload_parts (upload_id, part_number, content_hash, size, etag, created_at, checksum_algorithm, checksum)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		ON CONFLICT (upload_id, part_number) DO UPDATE
-		SET content_hash = EXCLUDED.content_hash, size = EXCLUDED.size, etag = EXCLUDED.etag, created_at = EXCLUDED.created_at
+		SET content_hash = EXCLUDED.content_hash, size = EXCLUDED.size, etag = EXCLUDED.etag, created_at = EXCLUDED.created_at,
+			checksum_algorithm = EXCLUDED.checksum_algorithm, checksum = EXCLUDED.checksum
 		RETURNING id
 	`
 
@@ -225,6 +254,8 @@ func (r *multipartRepository) CreatePart(ctx context.Context, part *domain.Uploa
 		part.Size,
 		part.ETag,
 		part.CreatedAt,
+		part.ChecksumAlgorithm,
+		part.Checksum,
 	).Scan(&part.ID)
 
 	if err != nil {
@@ -237,7 +268,7 @@ func (r *multipartRepository) CreatePart(ctx context.Context, part *domain.Uploa
 // GetPart retrieves a specific part.
 func (r *multipartRepository) GetPart(ctx context.Context, uploadID uuid.UUID, partNumber int) (*domain.UploadPart, error) {
 	query := `
-		SELECT id, upload_id, part_number, content_hash, size, etag, created_at
+		SELECT id, upload_id, part_number, content_hash, size, etag, created_at, checksum_algorithm, checksum
 		FROM upload_parts
 		WHERE upload_id = $1 AND part_number = $2
 	`
@@ -251,6 +282,8 @@ func (r *multipartRepository) GetPart(ctx context.Context, uploadID uuid.UUID, p
 		&part.Size,
 		&part.ETag,
 		&part.CreatedAt,
+		&part.ChecksumAlgorithm,
+		&part.Checksum,
 	)
 
 	if err != nil {
@@ -328,7 +361,7 @@ func (r *multipartRepository) DeleteParts(ctx context.Context, uploadID uuid.UUI
 // GetPartsForCompletion returns parts in order for completing the upload.
 func (r *multipartRepository) GetPartsForCompletion(ctx context.Context, uploadID uuid.UUID, partNumbers []int) ([]*domain.UploadPart, error) {
 	query := `
-		SELECT id, upload_id, part_number, content_hash, size, etag, created_at
+		SELECT id, upload_id, part_number, content_hash, size, etag, created_at, checksum_algorithm, checksum
 		FROM upload_parts
 		WHERE upload_id = $1 AND part_number = ANY($2)
 		ORDER BY part_number ASC
@@ -340,6 +373,49 @@ func (r *multipartRepository) GetPartsForCompletion(ctx context.Context, uploadI
 	}
 	defer rows.Close()
 
+	var parts []*domain.UploadPart
+	for rows.Next() {
+		part := &domain.UploadPart{}
+		err := rows.Scan(
+			&part.ID,
+			&part.UploadID,
+			&part.PartNumber,
+			&part.ContentHash,
+			&part.Size,
+			&part.ETag,
+			&part.CreatedAt,
+			&part.ChecksumAlgorithm,
+			&part.Checksum,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan part: %w", err)
+		}
+		parts = append(parts, part)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating parts: %w", err)
+	}
+
+	return parts, nil
+}
+
+// ListInProgressParts returns the parts of every in-progress upload.
+func (r *multipartRepository) ListInProgressParts(ctx context.Context) ([]*domain.UploadPart, error) {
+	query := `
+		SELECT p.id, p.upload_id, p.part_number, p.content_hash, p.size, p.etag, p.created_at
+		FROM upload_parts p
+		JOIN multipart_uploads u ON u.id = p.upload_id
+		WHERE u.status = $1
+		ORDER BY p.upload_id, p.part_number
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, domain.MultipartStatusInProgress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list in-progress parts: %w", err)
+	}
+	defer rows.Close()
+
 	var parts []*domain.UploadPart
 	for rows.Next() {
 		part := &domain.UploadPart{}
@@ -365,5 +441,19 @@ func (r *multipartRepository) GetPartsForCompletion(ctx context.Context, uploadI
 	return parts, nil
 }
 
+// DeletePart deletes a single part.
+func (r *multipartRepository) DeletePart(ctx context.Context, uploadID uuid.UUID, partNumber int) error {
+	result, err := r.db.Pool.Exec(ctx, `DELETE FROM upload_parts WHERE upload_id = $1 AND part_number = $2`, uploadID, partNumber)
+	if err != nil {
+		return fmt.Errorf("failed to delete part: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrPartNotFound
+	}
+
+	return nil
+}
+
 // Ensure multipartRepository implements repository.MultipartUploadRepository
 var _ repository.MultipartUploadRepository = (*multipartRepository)(nil)
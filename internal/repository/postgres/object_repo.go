@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -25,16 +26,45 @@ func NewObjectRepository(db *DB) repository.ObjectRepository {
 
 // Create creates a new object.
 func (r *objectRepository) Create(ctx context.Context, obj *domain.Object) error {
+	return r.db.WithTx(ctx, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		return r.create(ctx, tx, obj)
+	})
+}
+
+// create inserts obj, with its lock and tags, within tx. It first takes a share lock on the bucket row
+// for the rest of the transaction: DeleteBucket cannot mark the bucket as
+// deleting until the object is committed, and an object is never inserted
+// into a bucket already marked.
+func (r *objectRepository) create(ctx context.Context, tx pgx.Tx, obj *domain.Object) error {
+	var state domain.BucketState
+	err := tx.QueryRow(ctx, `SELECT state FROM buckets WHERE id = $1 FOR SHARE`, obj.BucketID).Scan(&state)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return domain.ErrBucketNotFound
+	case err != nil:
+		return fmt.Errorf("failed to lock bucket: %w", err)
+	case state != domain.BucketStateActive:
+		return domain.ErrBucketDeleting
+	}
+
 	query := `
-		INSERT INTO objects (bucket_id, key, version_id, is_latest, is_delete_marker, 
-			content_hash, size, content_type, etag, storage_class, metadata, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		INSERT INTO objects (bucket_id, key, normalized_key, version_id, is_latest, is_delete_marker, 
+			content_hash, size, content_type, etag, storage_class, metadata, created_at, expires_at,
+			sse_customer_algorithm, sse_customer_key_md5, checksum_algorithm, checksum,
+			cache_control, content_disposition, content_encoding, expires)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22)
 		RETURNING id
 	`
 
-	err := r.db.Pool.QueryRow(ctx, query,
+	normalizedKey := obj.NormalizedKey
+	if normalizedKey == "" {
+		normalizedKey = obj.Key
+	}
+
+	err = tx.QueryRow(ctx, query,
 		obj.BucketID,
 		obj.Key,
+		normalizedKey,
 		obj.VersionID,
 		obj.IsLatest,
 		obj.IsDeleteMarker,
@@ -45,20 +75,122 @@ func (r *objectRepository) Create(ctx context.Context, obj *domain.Object) error
 		obj.StorageClass,
 		obj.Metadata,
 		obj.CreatedAt,
+		obj.ExpiresAt,
+		obj.SSECustomerAlgorithm,
+		obj.SSECustomerKeyMD5,
+		obj.ChecksumAlgorithm,
+		obj.Checksum,
+		obj.CacheControl,
+		obj.ContentDisposition,
+		obj.ContentEncoding,
+		obj.Expires,
 	).Scan(&obj.ID)
 
 	if err != nil {
 		return fmt.Errorf("failed to create object: %w", err)
 	}
 
-	return nil
+	if obj.Lock != nil {
+		if err := putLock(ctx, tx, obj.ID, obj.Lock); err != nil {
+			return err
+		}
+	}
+	return insertTags(ctx, tx, obj.ID, obj.Tags)
+}
+
+// CreateBatch stores the writes in one transaction, each under a savepoint.
+func (r *objectRepository) CreateBatch(ctx context.Context, writes []*repository.ObjectWrite) error {
+	return r.db.WithTx(ctx, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		for _, w := range writes {
+			// A nested transaction is a savepoint
+			savepoint, err := tx.Begin(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to create savepoint: %w", err)
+			}
+			w.Replaced, w.Err = r.applyWrite(ctx, savepoint, w)
+			if w.Err != nil {
+				if err := savepoint.Rollback(ctx); err != nil {
+					return fmt.Errorf("failed to roll back to savepoint: %w", err)
+				}
+				continue
+			}
+			if err := savepoint.Commit(ctx); err != nil {
+				return fmt.Errorf("failed to release savepoint: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// applyWrite takes the blob reference of w, replaces the version it
+// replaces and creates its object, returning the replaced version.
+func (r *objectRepository) applyWrite(ctx context.Context, tx pgx.Tx, w *repository.ObjectWrite) (*domain.Object, error) {
+	obj := w.Object
+
+	if obj.ContentHash != nil {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO blobs (content_hash, size, storage_path, ref_count, is_encrypted, created_at)
+			VALUES ($1, $2, $3, 1, true, $4)
+			ON CONFLICT (content_hash) DO UPDATE
+			SET ref_count = blobs.ref_count + 1
+		`, *obj.ContentHash, w.StoredSize, w.StoragePath, time.Now().UTC())
+		if err != nil {
+			return nil, fmt.Errorf("failed to upsert blob: %w", err)
+		}
+	}
+
+	key := obj.NormalizedKey
+	if key == "" {
+		key = obj.Key
+	}
+
+	var replaced *domain.Object
+	var row pgx.Row
+	switch w.Replace {
+	case repository.ReplaceLatest:
+		row = tx.QueryRow(ctx, `
+			UPDATE objects SET deleted_at = NOW()
+			WHERE bucket_id = $1 AND normalized_key = $2 AND is_latest = TRUE AND deleted_at IS NULL
+			RETURNING id, content_hash
+		`, obj.BucketID, key)
+	case repository.ReplaceNullVersion:
+		row = tx.QueryRow(ctx, `
+			UPDATE objects SET deleted_at = NOW()
+			WHERE bucket_id = $1 AND normalized_key = $2 AND version_id = $3 AND deleted_at IS NULL
+			RETURNING id, content_hash
+		`, obj.BucketID, key, uuid.Nil)
+	}
+	if row != nil {
+		replaced = &domain.Object{}
+		err := row.Scan(&replaced.ID, &replaced.ContentHash)
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			replaced = nil
+		case err != nil:
+			return nil, fmt.Errorf("failed to delete replaced object: %w", err)
+		}
+	}
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE objects SET is_latest = FALSE WHERE bucket_id = $1 AND normalized_key = $2 AND is_latest = TRUE`,
+		obj.BucketID, key,
+	); err != nil {
+		return nil, fmt.Errorf("failed to mark as not latest: %w", err)
+	}
+
+	if err := r.create(ctx, tx, obj); err != nil {
+		return nil, err
+	}
+	return replaced, nil
 }
 
 // GetByID retrieves an object by ID.
 func (r *objectRepository) GetByID(ctx context.Context, id int64) (*domain.Object, error) {
 	query := `
 		SELECT id, bucket_id, key, version_id, is_latest, is_delete_marker, 
-			content_hash, size, content_type, etag, storage_class, metadata, created_at, deleted_at
+			content_hash, size, content_type, etag, storage_class, metadata, created_at, deleted_at,
+			sse_customer_algorithm, sse_customer_key_md5, checksum_algorithm, checksum,
+			cache_control, content_disposition, content_encoding, expires
 		FROM objects
 		WHERE id = $1
 	`
@@ -79,6 +211,14 @@ func (r *objectRepository) GetByID(ctx context.Context, id int64) (*domain.Objec
 		&obj.Metadata,
 		&obj.CreatedAt,
 		&obj.DeletedAt,
+		&obj.SSECustomerAlgorithm,
+		&obj.SSECustomerKeyMD5,
+		&obj.ChecksumAlgorithm,
+		&obj.Checksum,
+		&obj.CacheControl,
+		&obj.ContentDisposition,
+		&obj.ContentEncoding,
+		&obj.Expires,
 	)
 
 	if err != nil {
@@ -95,9 +235,11 @@ func (r *objectRepository) GetByID(ctx context.Context, id int64) (*domain.Objec
 func (r *objectRepository) GetByKey(ctx context.Context, bucketID int64, key string) (*domain.Object, error) {
 	query := `
 		SELECT id, bucket_id, key, version_id, is_latest, is_delete_marker, 
-			content_hash, size, content_type, etag, storage_class, metadata, created_at, deleted_at
+			content_hash, size, content_type, etag, storage_class, metadata, created_at, deleted_at,
+			sse_customer_algorithm, sse_customer_key_md5, checksum_algorithm, checksum,
+			cache_control, content_disposition, content_encoding, expires
 		FROM objects
-		WHERE bucket_id = $1 AND key = $2 AND is_latest = TRUE AND deleted_at IS NULL
+		WHERE bucket_id = $1 AND normalized_key = $2 AND is_latest = TRUE AND deleted_at IS NULL
 	`
 
 	obj := &domain.Object{}
@@ -116,6 +258,14 @@ func (r *objectRepository) GetByKey(ctx context.Context, bucketID int64, key str
 		&obj.Metadata,
 		&obj.CreatedAt,
 		&obj.DeletedAt,
+		&obj.SSECustomerAlgorithm,
+		&obj.SSECustomerKeyMD5,
+		&obj.ChecksumAlgorithm,
+		&obj.Checksum,
+		&obj.CacheControl,
+		&obj.ContentDisposition,
+		&obj.ContentEncoding,
+		&obj.Expires,
 	)
 
 	if err != nil {
@@ -132,9 +282,11 @@ func (r *objectRepository) GetByKey(ctx context.Context, bucketID int64, key str
 func (r *objectRepository) GetByKeyAndVersion(ctx context.Context, bucketID int64, key string, versionID uuid.UUID) (*domain.Object, error) {
 	query := `
 		SELECT id, bucket_id, key, version_id, is_latest, is_delete_marker, 
-			content_hash, size, content_type, etag, storage_class, metadata, created_at, deleted_at
+			content_hash, size, content_type, etag, storage_class, metadata, created_at, deleted_at,
+			sse_customer_algorithm, sse_customer_key_md5, checksum_algorithm, checksum,
+			cache_control, content_disposition, content_encoding, expires
 		FROM objects
-		WHERE bucket_id = $1 AND key = $2 AND version_id = $3
+		WHERE bucket_id = $1 AND normalized_key = $2 AND version_id = $3
 	`
 
 	obj := &domain.Object{}
@@ -153,6 +305,14 @@ func (r *objectRepository) GetByKeyAndVersion(ctx context.Context, bucketID int6
 		&obj.Metadata,
 		&obj.CreatedAt,
 		&obj.DeletedAt,
+		&obj.SSECustomerAlgorithm,
+		&obj.SSECustomerKeyMD5,
+		&obj.ChecksumAlgorithm,
+		&obj.Checksum,
+		&obj.CacheControl,
+		&obj.ContentDisposition,
+		&obj.ContentEncoding,
+		&obj.Expires,
 	)
 
 	if err != nil {
@@ -176,13 +336,33 @@ func (r *objectRepository) List(ctx context.Context, bucketID int64, opts reposi
 		SELECT key, version_id, is_latest, size, etag, created_at, storage_class
 		FROM objects
 		WHERE bucket_id = $1 AND is_latest = TRUE AND deleted_at IS NULL
-			AND ($2 = '' OR key LIKE $2 || '%')
-			AND ($3 = '' OR key > $3)
-		ORDER BY key ASC
+			AND ($2 = '' OR normalized_key LIKE $2 || '%')
+			AND ($3 = '' OR normalized_key > $3)
+		ORDER BY normalized_key ASC
 		LIMIT $4
 	`
+	args := []any{bucketID, escapeLike(opts.Prefix), opts.StartAfter, maxKeys + 1}
+
+	if snapshot := opts.Snapshot; snapshot != nil {
+		// The newest version visible at the snapshot, unless a delete marker
+		query = `
+			SELECT key, version_id, TRUE, size, etag, created_at, storage_class
+			FROM objects o
+			WHERE bucket_id = $1 AND id <= $5 AND (deleted_at IS NULL OR deleted_at >= $6) AND is_delete_marker = FALSE
+				AND NOT EXISTS (
+					SELECT 1 FROM objects n
+					WHERE n.bucket_id = o.bucket_id AND n.normalized_key = o.normalized_key
+						AND n.id > o.id AND n.id <= $5 AND (n.deleted_at IS NULL OR n.deleted_at >= $6)
+				)
+				AND ($2 = '' OR normalized_key LIKE $2 || '%')
+				AND ($3 = '' OR normalized_key > $3)
+			ORDER BY normalized_key ASC
+			LIMIT $4
+		`
+		args = append(args, snapshot.MaxID, snapshot.TakenAt)
+	}
 
-	rows, err := r.db.Pool.Query(ctx, query, bucketID, opts.Prefix, opts.StartAfter, maxKeys+1)
+	rows, err := r.db.Pool.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list objects: %w", err)
 	}
@@ -204,7 +384,7 @@ func (r *objectRepository) List(ctx context.Context, bucketID int64, opts reposi
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan object: %w", err)
 		}
-		obj.VersionID = versionID.String()
+		obj.VersionID = domain.FormatVersionID(versionID)
 		objects = append(objects, obj)
 	}
 
@@ -212,9 +392,7 @@ func (r *objectRepository) List(ctx context.Context, bucketID int64, opts reposi
 		return nil, fmt.Errorf("error iterating objects: %w", err)
 	}
 
-	result := &repository.ObjectListResult{
-		KeyCount: len(objects),
-	}
+	result := &repository.ObjectListResult{}
 
 	if len(objects) > maxKeys {
 		result.IsTruncated = true
@@ -223,10 +401,21 @@ func (r *objectRepository) List(ctx context.Context, bucketID int64, opts reposi
 	} else {
 		result.Objects = objects
 	}
+	result.KeyCount = len(result.Objects)
 
 	return result, nil
 }
 
+// ListSnapshot returns the current point in time for snapshot listings,
+// truncated to the microsecond precision timestamps are stored with.
+func (r *objectRepository) ListSnapshot(ctx context.Context) (*repository.ListSnapshot, error) {
+	snapshot := &repository.ListSnapshot{TakenAt: time.Now().UTC().Truncate(time.Microsecond)}
+	if err := r.db.Pool.QueryRow(ctx, `SELECT COALESCE(MAX(id), 0) FROM objects`).Scan(&snapshot.MaxID); err != nil {
+		return nil, fmt.Errorf("failed to take list snapshot: %w", err)
+	}
+	return snapshot, nil
+}
+
 // ListVersions returns all versions of objects in a bucket.
 func (r *objectRepository) ListVersions(ctx context.Context, bucketID int64, opts repository.ObjectListOptions) (*repository.ObjectVersionListResult, error) {
 	maxKeys := opts.MaxKeys
@@ -234,24 +423,30 @@ func (r *objectRepository) ListVersions(ctx context.Context, bucketID int64, opt
 		maxKeys = 1000
 	}
 
+	// Versions and delete markers are paged together, newest first within a
+	// key. The id breaks ties between versions with the same timestamp.
 	query := `
-		SELECT key, version_id, is_latest, is_delete_marker, size, etag, created_at, storage_class
-		FROM objects
-		WHERE bucket_id = $1 AND deleted_at IS NULL
-			AND ($2 = '' OR key LIKE $2 || '%')
-			AND ($3 = '' OR key > $3)
-		ORDER BY key ASC, created_at DESC
-		LIMIT $4
+		SELECT o.key, o.version_id, o.is_latest, o.is_delete_marker, o.size, o.etag, o.created_at, o.storage_class
+		FROM objects o
+		WHERE o.bucket_id = $1 AND o.deleted_at IS NULL
+			AND ($2 = '' OR o.normalized_key LIKE $2 || '%')
+			AND ($3 = '' OR o.normalized_key > $3 OR (o.normalized_key = $3 AND EXISTS (
+				SELECT 1 FROM objects m
+				WHERE m.bucket_id = o.bucket_id AND m.normalized_key = o.normalized_key AND m.version_id::text = $4
+					AND (o.created_at < m.created_at OR (o.created_at = m.created_at AND o.id < m.id))
+			)))
+		ORDER BY o.normalized_key ASC, o.created_at DESC, o.id DESC
+		LIMIT $5
 	`
 
-	rows, err := r.db.Pool.Query(ctx, query, bucketID, opts.Prefix, opts.StartAfter, maxKeys+1)
+	rows, err := r.db.Pool.Query(ctx, query, bucketID, escapeLike(opts.Prefix), opts.StartAfter, opts.VersionIDMarker, maxKeys+1)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list versions: %w", err)
 	}
 	defer rows.Close()
 
-	var versions []*domain.ObjectVersion
-	var deleteMarkers []*domain.ObjectVersion
+	result := &repository.ObjectVersionListResult{}
+	var last *domain.ObjectVersion
 
 	for rows.Next() {
 		ver := &domain.ObjectVersion{}
@@ -270,28 +465,28 @@ func (r *objectRepository) ListVersions(ctx context.Context, bucketID int64, opt
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan version: %w", err)
 		}
-		ver.VersionID = versionID.String()
+		ver.VersionID = domain.FormatVersionID(versionID)
 		ver.IsDeleteMarker = isDeleteMarker
 
+		if len(result.Versions)+len(result.DeleteMarkers) == maxKeys {
+			result.IsTruncated = true
+			break
+		}
 		if isDeleteMarker {
-			deleteMarkers = append(deleteMarkers, ver)
+			result.DeleteMarkers = append(result.DeleteMarkers, ver)
 		} else {
-			versions = append(versions, ver)
+			result.Versions = append(result.Versions, ver)
 		}
+		last = ver
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating versions: %w", err)
 	}
 
-	result := &repository.ObjectVersionListResult{
-		Versions:      versions,
-		DeleteMarkers: deleteMarkers,
-	}
-
-	totalCount := len(versions) + len(deleteMarkers)
-	if totalCount > maxKeys {
-		result.IsTruncated = true
+	if result.IsTruncated {
+		result.NextKeyMarker = last.Key
+		result.NextVersionIDMarker = last.VersionID
 	}
 
 	return result, nil
@@ -328,7 +523,7 @@ func (r *objectRepository) MarkNotLatest(ctx context.Context, bucketID int64, ke
 	query := `
 		UPDATE objects
 		SET is_latest = FALSE
-		WHERE bucket_id = $1 AND key = $2 AND is_latest = TRUE
+		WHERE bucket_id = $1 AND normalized_key = $2 AND is_latest = TRUE
 	`
 
 	_, err := r.db.Pool.Exec(ctx, query, bucketID, key)
@@ -339,7 +534,7 @@ func (r *objectRepository) MarkNotLatest(ctx context.Context, bucketID int64, ke
 	return nil
 }
 
-// Delete hard-deletes an object by ID.
+// Delete soft-deletes an object by ID.
 func (r *objectRepository) Delete(ctx context.Context, id int64) error {
 	query := `UPDATE objects SET deleted_at = $2 WHERE id = $1`
 
@@ -355,13 +550,148 @@ func (r *objectRepository) Delete(ctx context.Context, id int64) error {
 	return nil
 }
 
-// DeleteAllVersions deletes all versions of an object.
-func (r *objectRepository) DeleteAllVersions(ctx context.Context, bucketID int64, key string) error {
-	query := `UPDATE objects SET deleted_at = $3 WHERE bucket_id = $1 AND key = $2`
+// DeleteAllVersions soft-deletes up to limit live versions of an object.
+func (r *objectRepository) DeleteAllVersions(ctx context.Context, bucketID int64, key string, limit int) (int64, error) {
+	query := `
+		UPDATE objects SET deleted_at = $3
+		WHERE id IN (
+			SELECT id FROM objects
+			WHERE bucket_id = $1 AND normalized_key = $2 AND deleted_at IS NULL
+			ORDER BY id ASC
+			LIMIT $4
+		)
+	`
 
-	_, err := r.db.Pool.Exec(ctx, query, bucketID, key, time.Now().UTC())
+	result, err := r.db.Pool.Exec(ctx, query, bucketID, key, time.Now().UTC(), limit)
 	if err != nil {
-		return fmt.Errorf("failed to delete all versions: %w", err)
+		return 0, fmt.Errorf("failed to delete all versions: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// ListAfterID returns the next keyset page of live object versions in a bucket.
+func (r *objectRepository) ListAfterID(ctx context.Context, bucketID int64, afterID int64, limit int) ([]*domain.Object, error) {
+	query := `
+		SELECT id, bucket_id, key, version_id, is_latest, is_delete_marker, 
+			content_hash, size, content_type, etag, storage_class, metadata, created_at, deleted_at,
+			sse_customer_algorithm, sse_customer_key_md5, checksum_algorithm, checksum,
+			cache_control, content_disposition, content_encoding, expires
+		FROM objects
+		WHERE bucket_id = $1 AND id > $2 AND deleted_at IS NULL
+		ORDER BY id ASC
+		LIMIT $3
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, bucketID, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list objects: %w", err)
+	}
+	defer rows.Close()
+
+	return scanObjects(rows)
+}
+
+// ListKeyVersions returns all live versions of an object, newest first.
+func (r *objectRepository) ListKeyVersions(ctx context.Context, bucketID int64, key string) ([]*domain.Object, error) {
+	query := `
+		SELECT id, bucket_id, key, version_id, is_latest, is_delete_marker, 
+			content_hash, size, content_type, etag, storage_class, metadata, created_at, deleted_at,
+			sse_customer_algorithm, sse_customer_key_md5, checksum_algorithm, checksum,
+			cache_control, content_disposition, content_encoding, expires
+		FROM objects
+		WHERE bucket_id = $1 AND normalized_key = $2 AND deleted_at IS NULL
+		ORDER BY created_at DESC, id DESC
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, bucketID, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list object versions: %w", err)
+	}
+	defer rows.Close()
+
+	return scanObjects(rows)
+}
+
+// GetLatestDeleted retrieves the most recently soft-deleted version of an object.
+func (r *objectRepository) GetLatestDeleted(ctx context.Context, bucketID int64, key string) (*domain.Object, error) {
+	query := `
+		SELECT id, bucket_id, key, version_id, is_latest, is_delete_marker, 
+			content_hash, size, content_type, etag, storage_class, metadata, created_at, deleted_at,
+			sse_customer_algorithm, sse_customer_key_md5, checksum_algorithm, checksum,
+			cache_control, content_disposition, content_encoding, expires
+		FROM objects
+		WHERE bucket_id = $1 AND normalized_key = $2 AND deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC, id DESC
+		LIMIT 1
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, bucketID, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deleted object: %w", err)
+	}
+	defer rows.Close()
+
+	objects, err := scanObjects(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(objects) == 0 {
+		return nil, domain.ErrObjectNotFound
+	}
+
+	return objects[0], nil
+}
+
+// ListSoftDeleted returns objects soft-deleted before the given time, oldest first.
+func (r *objectRepository) ListSoftDeleted(ctx context.Context, deletedBefore time.Time, limit int) ([]*domain.Object, error) {
+	query := `
+		SELECT id, bucket_id, key, version_id, is_latest, is_delete_marker, 
+			content_hash, size, content_type, etag, storage_class, metadata, created_at, deleted_at,
+			sse_customer_algorithm, sse_customer_key_md5, checksum_algorithm, checksum,
+			cache_control, content_disposition, content_encoding, expires
+		FROM objects
+		WHERE deleted_at IS NOT NULL AND deleted_at < $1
+		ORDER BY deleted_at ASC
+		LIMIT $2
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, deletedBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list soft-deleted objects: %w", err)
+	}
+	defer rows.Close()
+
+	return scanObjects(rows)
+}
+
+// Undelete clears the deletion time of a soft-deleted object.
+func (r *objectRepository) Undelete(ctx context.Context, id int64) error {
+	query := `UPDATE objects SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL`
+
+	result, err := r.db.Pool.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to undelete object: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrObjectNotFound
+	}
+
+	return nil
+}
+
+// Purge permanently removes a soft-deleted object.
+func (r *objectRepository) Purge(ctx context.Context, id int64) error {
+	query := `DELETE FROM objects WHERE id = $1 AND deleted_at IS NOT NULL`
+
+	result, err := r.db.Pool.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to purge object: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return domain.ErrObjectNotFound
 	}
 
 	return nil
@@ -380,7 +710,7 @@ func (r *objectRepository) CountByBucket(ctx context.Context, bucketID int64) (i
 // GetContentHashForVersion retrieves the content hash for a specific version.
 func (r *objectRepository) GetContentHashForVersion(ctx context.Context, bucketID int64, key string, versionID uuid.UUID) (*string, error) {
 	var contentHash *string
-	err := r.db.Pool.QueryRow(ctx, `SELECT content_hash FROM objects WHERE bucket_id = $1 AND key = $2 AND version_id = $3`, bucketID, key, versionID).Scan(&contentHash)
+	err := r.db.Pool.QueryRow(ctx, `SELECT content_hash FROM objects WHERE bucket_id = $1 AND normalized_key = $2 AND version_id = $3`, bucketID, key, versionID).Scan(&contentHash)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, domain.ErrObjectNotFound
@@ -390,12 +720,100 @@ func (r *objectRepository) GetContentHashForVersion(ctx context.Context, bucketI
 	return contentHash, nil
 }
 
+// GetTags returns the tag set of an object version.
+func (r *objectRepository) GetTags(ctx context.Context, objectID int64) (map[string]string, error) {
+	rows, err := r.db.Pool.Query(ctx, `SELECT tag_key, tag_value FROM object_tags WHERE object_id = $1`, objectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan object tag: %w", err)
+		}
+		tags[key] = value
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate object tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+// PutTags replaces the tag set of an object version.
+func (r *objectRepository) PutTags(ctx context.Context, objectID int64, tags map[string]string) error {
+	return r.db.WithTx(ctx, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `DELETE FROM object_tags WHERE object_id = $1`, objectID); err != nil {
+			return fmt.Errorf("failed to clear object tags: %w", err)
+		}
+		return insertTags(ctx, tx, objectID, tags)
+	})
+}
+
+// insertTags adds tags to an object within tx.
+func insertTags(ctx context.Context, tx pgx.Tx, objectID int64, tags map[string]string) error {
+	for key, value := range tags {
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO object_tags (object_id, tag_key, tag_value) VALUES ($1, $2, $3)`,
+			objectID, key, value,
+		); err != nil {
+			return fmt.Errorf("failed to insert object tag: %w", err)
+		}
+	}
+	return nil
+}
+
+// GetLock returns the retention and legal hold of an object version.
+func (r *objectRepository) GetLock(ctx context.Context, objectID int64) (*domain.ObjectLock, error) {
+	lock := &domain.ObjectLock{}
+	var mode string
+	err := r.db.Pool.QueryRow(ctx,
+		`SELECT mode, retain_until, legal_hold FROM object_locks WHERE object_id = $1`, objectID,
+	).Scan(&mode, &lock.RetainUntil, &lock.LegalHold)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return lock, nil
+		}
+		return nil, fmt.Errorf("failed to get object lock: %w", err)
+	}
+	lock.Mode = domain.ObjectLockMode(mode)
+	return lock, nil
+}
+
+// PutLock replaces the retention and legal hold of an object version.
+func (r *objectRepository) PutLock(ctx context.Context, objectID int64, lock *domain.ObjectLock) error {
+	return r.db.WithTx(ctx, pgx.TxOptions{}, func(tx pgx.Tx) error {
+		return putLock(ctx, tx, objectID, lock)
+	})
+}
+
+// putLock replaces the lock of an object version within tx.
+func putLock(ctx context.Context, tx pgx.Tx, objectID int64, lock *domain.ObjectLock) error {
+	query := `
+		INSERT INTO object_locks (object_id, mode, retain_until, legal_hold)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (object_id) DO UPDATE SET
+			mode = EXCLUDED.mode,
+			retain_until = EXCLUDED.retain_until,
+			legal_hold = EXCLUDED.legal_hold
+	`
+	if _, err := tx.Exec(ctx, query, objectID, string(lock.Mode), lock.RetainUntil, lock.LegalHold); err != nil {
+		return fmt.Errorf("failed to put object lock: %w", err)
+	}
+	return nil
+}
+
 // ListExpiredObjects returns latest objects older than cutoff, with optional prefix.
 // Used by lifecycle service for expiration processing.
 func (r *objectRepository) ListExpiredObjects(ctx context.Context, bucketID int64, prefix string, olderThan time.Time, limit int) ([]*domain.Object, error) {
 	query := `
 		SELECT id, bucket_id, key, version_id, is_latest, is_delete_marker, 
-			content_hash, size, content_type, etag, storage_class, metadata, created_at, deleted_at
+			content_hash, size, content_type, etag, storage_class, metadata, created_at, deleted_at,
+			sse_customer_algorithm, sse_customer_key_md5, checksum_algorithm, checksum,
+			cache_control, content_disposition, content_encoding, expires
 		FROM objects
 		WHERE bucket_id = $1 
 			AND is_latest = TRUE 
@@ -413,6 +831,52 @@ func (r *objectRepository) ListExpiredObjects(ctx context.Context, bucketID int6
 	}
 	defer rows.Close()
 
+	return scanObjects(rows)
+}
+
+// ListPastExpiry returns latest objects whose own expiration time has passed.
+func (r *objectRepository) ListPastExpiry(ctx context.Context, now time.Time, limit int) ([]*domain.Object, error) {
+	query := `
+		SELECT id, bucket_id, key, version_id, is_latest, is_delete_marker, 
+			content_hash, size, content_type, etag, storage_class, metadata, created_at, deleted_at,
+			sse_customer_algorithm, sse_customer_key_md5, checksum_algorithm, checksum,
+			cache_control, content_disposition, content_encoding, expires
+		FROM objects
+		WHERE expires_at IS NOT NULL
+			AND expires_at <= $1
+			AND is_latest = TRUE
+			AND is_delete_marker = FALSE
+			AND deleted_at IS NULL
+		ORDER BY expires_at ASC
+		LIMIT $2
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list objects past expiry: %w", err)
+	}
+	defer rows.Close()
+
+	return scanObjects(rows)
+}
+
+// Ensure objectRepository implements repository.ObjectRepository
+var _ repository.ObjectRepository = (*objectRepository)(nil)
+
+// Ensure objectRepository implements repository.ObjectBatchWriter
+var _ repository.ObjectBatchWriter = (*objectRepository)(nil)
+
+// likeEscaper escapes the LIKE wildcards, using the default escape character.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike escapes a key prefix for use in a LIKE pattern, so keys with
+// '%' or '_' match literally.
+func escapeLike(prefix string) string {
+	return likeEscaper.Replace(prefix)
+}
+
+// scanObjects scans all rows of an object query.
+func scanObjects(rows pgx.Rows) ([]*domain.Object, error) {
 	var objects []*domain.Object
 	for rows.Next() {
 		obj := &domain.Object{}
@@ -431,6 +895,14 @@ func (r *objectRepository) ListExpiredObjects(ctx context.Context, bucketID int6
 			&obj.Metadata,
 			&obj.CreatedAt,
 			&obj.DeletedAt,
+			&obj.SSECustomerAlgorithm,
+			&obj.SSECustomerKeyMD5,
+			&obj.ChecksumAlgorithm,
+			&obj.Checksum,
+			&obj.CacheControl,
+			&obj.ContentDisposition,
+			&obj.ContentEncoding,
+			&obj.Expires,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan object: %w", err)
@@ -444,9 +916,3 @@ func (r *objectRepository) ListExpiredObjects(ctx context.Context, bucketID int6
 
 	return objects, nil
 }
-
-// Ensure objectRepository implements repository.ObjectRepository
-var _ repository.ObjectRepository = (*objectRepository)(nil)
-
-// Ensure objectRepository implements repository.ObjectRepository
-var _ repository.ObjectRepository = (*objectRepository)(nil)
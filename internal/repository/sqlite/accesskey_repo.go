@@ -23,8 +23,8 @@ func NewAccessKeyRepository(db *DB) repository.AccessKeyRepository {
 // Create creates a new access key.
 func (r *accessKeyRepository) Create(ctx context.Context, key *domain.AccessKey) error {
 	query := `
-		INSERT INTO access_keys (user_id, access_key_id, encrypted_secret, description, status, created_at, expires_at, last_used_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO access_keys (user_id, access_key_id, encrypted_secret, description, status, created_at, expires_at, last_used_at, namespace_bucket, namespace_prefix, rate_limit_rps, rate_limit_burst)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	var expiresAt, lastUsedAt sql.NullString
@@ -44,6 +44,10 @@ func (r *accessKeyRepository) Create(ctx context.Context, key *domain.AccessKey)
 		key.CreatedAt.Format(time.RFC3339),
 		expiresAt,
 		lastUsedAt,
+		key.NamespaceBucket,
+		key.NamespacePrefix,
+		key.RateLimitRPS,
+		key.RateLimitBurst,
 	)
 
 	if err != nil {
@@ -65,7 +69,7 @@ func (r *accessKeyRepository) Create(ctx context.Context, key *domain.AccessKey)
 // GetByID retrieves an access key by ID.
 func (r *accessKeyRepository) GetByID(ctx context.Context, id int64) (*domain.AccessKey, error) {
 	query := `
-		SELECT id, user_id, access_key_id, encrypted_secret, description, status, created_at, expires_at, last_used_at
+		SELECT id, user_id, access_key_id, encrypted_secret, description, status, created_at, expires_at, last_used_at, namespace_bucket, namespace_prefix, rate_limit_rps, rate_limit_burst
 		FROM access_keys
 		WHERE id = ?
 	`
@@ -75,7 +79,7 @@ func (r *accessKeyRepository) GetByID(ctx context.Context, id int64) (*domain.Ac
 // GetByAccessKeyID retrieves an access key by access key ID (20-char identifier).
 func (r *accessKeyRepository) GetByAccessKeyID(ctx context.Context, accessKeyID string) (*domain.AccessKey, error) {
 	query := `
-		SELECT id, user_id, access_key_id, encrypted_secret, description, status, created_at, expires_at, last_used_at
+		SELECT id, user_id, access_key_id, encrypted_secret, description, status, created_at, expires_at, last_used_at, namespace_bucket, namespace_prefix, rate_limit_rps, rate_limit_burst
 		FROM access_keys
 		WHERE access_key_id = ?
 	`
@@ -85,7 +89,7 @@ func (r *accessKeyRepository) GetByAccessKeyID(ctx context.Context, accessKeyID
 // GetActiveByAccessKeyID retrieves an active, non-expired access key.
 func (r *accessKeyRepository) GetActiveByAccessKeyID(ctx context.Context, accessKeyID string) (*domain.AccessKey, error) {
 	query := `
-		SELECT id, user_id, access_key_id, encrypted_secret, description, status, created_at, expires_at, last_used_at
+		SELECT id, user_id, access_key_id, encrypted_secret, description, status, created_at, expires_at, last_used_at, namespace_bucket, namespace_prefix, rate_limit_rps, rate_limit_burst
 		FROM access_keys
 		WHERE access_key_id = ? 
 			AND status = ? 
@@ -111,6 +115,10 @@ func (r *accessKeyRepository) scanAccessKey(row *sql.Row) (*domain.AccessKey, er
 		&createdAt,
 		&expiresAt,
 		&lastUsedAt,
+		&key.NamespaceBucket,
+		&key.NamespacePrefix,
+		&key.RateLimitRPS,
+		&key.RateLimitBurst,
 	)
 
 	if err != nil {
@@ -139,7 +147,7 @@ func (r *accessKeyRepository) scanAccessKey(row *sql.Row) (*domain.AccessKey, er
 // ListByUserID retrieves all access keys for a user.
 func (r *accessKeyRepository) ListByUserID(ctx context.Context, userID int64) ([]*domain.AccessKey, error) {
 	query := `
-		SELECT id, user_id, access_key_id, encrypted_secret, description, status, created_at, expires_at, last_used_at
+		SELECT id, user_id, access_key_id, encrypted_secret, description, status, created_at, expires_at, last_used_at, namespace_bucket, namespace_prefix, rate_limit_rps, rate_limit_burst
 		FROM access_keys
 		WHERE user_id = ?
 		ORDER BY created_at DESC
@@ -167,6 +175,10 @@ func (r *accessKeyRepository) ListByUserID(ctx context.Context, userID int64) ([
 			&createdAt,
 			&expiresAt,
 			&lastUsedAt,
+			&key.NamespaceBucket,
+			&key.NamespacePrefix,
+			&key.RateLimitRPS,
+			&key.RateLimitBurst,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan access key: %w", err)
@@ -199,7 +211,7 @@ func (r *accessKeyRepository) ListByUserID(ctx context.Context, userID int64) ([
 func (r *accessKeyRepository) Update(ctx context.Context, key *domain.AccessKey) error {
 	query := `
 		UPDATE access_keys
-		SET description = ?, status = ?, expires_at = ?
+		SET description = ?, status = ?, expires_at = ?, namespace_bucket = ?, namespace_prefix = ?, rate_limit_rps = ?, rate_limit_burst = ?
 		WHERE id = ?
 	`
 
@@ -212,6 +224,10 @@ func (r *accessKeyRepository) Update(ctx context.Context, key *domain.AccessKey)
 		key.Description,
 		key.Status,
 		expiresAt,
+		key.NamespaceBucket,
+		key.NamespacePrefix,
+		key.RateLimitRPS,
+		key.RateLimitBurst,
 		key.ID,
 	)
 	if err != nil {
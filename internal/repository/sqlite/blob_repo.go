@@ -105,7 +105,8 @@ func (r *blobRepository) UpsertEncrypted(ctx context.Context, contentHash string
 // GetByHash retrieves a blob by its content hash.
 func (r *blobRepository) GetByHash(ctx context.Context, contentHash string) (*domain.Blob, error) {
 	query := `
-		SELECT content_hash, size, storage_path, ref_count, is_encrypted, encryption_iv, created_at, last_accessed
+		SELECT content_hash, size, storage_path, ref_count, is_encrypted, encryption_iv,
+			COALESCE(encryption_scheme, ''), created_at, last_accessed
 		FROM blobs
 		WHERE content_hash = ?
 	`
@@ -122,6 +123,7 @@ func (r *blobRepository) GetByHash(ctx context.Context, contentHash string) (*do
 		&blob.RefCount,
 		&isEncrypted,
 		&encryptionIV,
+		&blob.EncryptionScheme,
 		&createdAt,
 		&lastAccessed,
 	)
@@ -249,6 +251,22 @@ func (r *blobRepository) Exists(ctx context.Context, contentHash string) (bool,
 	return count > 0, nil
 }
 
+// IsReferenced reports whether an object version, upload part or delta
+// still references the blob.
+func (r *blobRepository) IsReferenced(ctx context.Context, contentHash string) (bool, error) {
+	query := `
+		SELECT EXISTS(SELECT 1 FROM objects WHERE content_hash = ?)
+			OR EXISTS(SELECT 1 FROM upload_parts WHERE content_hash = ?)
+			OR EXISTS(SELECT 1 FROM delta_chains WHERE base_hash = ? OR data_hash = ?)
+	`
+
+	var referenced bool
+	if err := r.db.QueryRowContext(ctx, query, contentHash, contentHash, contentHash, contentHash).Scan(&referenced); err != nil {
+		return false, fmt.Errorf("failed to check blob references: %w", err)
+	}
+	return referenced, nil
+}
+
 // Delete deletes a blob by its content hash.
 func (r *blobRepository) Delete(ctx context.Context, contentHash string) error {
 	query := `DELETE FROM blobs WHERE content_hash = ? AND ref_count <= 0`
@@ -533,5 +551,59 @@ func (r *blobRepository) ListAll(ctx context.Context, limit int) ([]*domain.Blob
 	return blobs, nil
 }
 
+// ListAfter returns blobs ordered by content hash, starting after afterHash.
+func (r *blobRepository) ListAfter(ctx context.Context, afterHash string, limit int) ([]*domain.Blob, error) {
+	query := `
+		SELECT content_hash, size, storage_path, ref_count, is_encrypted, encryption_iv, created_at, last_accessed
+		FROM blobs
+		WHERE content_hash > ?
+		ORDER BY content_hash ASC
+		LIMIT ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, afterHash, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list blobs: %w", err)
+	}
+	defer rows.Close()
+
+	var blobs []*domain.Blob
+	for rows.Next() {
+		blob := &domain.Blob{}
+		var isEncrypted int
+		var encryptionIV *string
+		var createdAt, lastAccessed string
+
+		err := rows.Scan(
+			&blob.ContentHash,
+			&blob.Size,
+			&blob.StoragePath,
+			&blob.RefCount,
+			&isEncrypted,
+			&encryptionIV,
+			&createdAt,
+			&lastAccessed,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan blob: %w", err)
+		}
+
+		blob.IsEncrypted = isEncrypted == 1
+		if encryptionIV != nil {
+			blob.EncryptionIV = encryptionIV
+		}
+		blob.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+		blob.LastAccessed, _ = time.Parse(time.RFC3339, lastAccessed)
+
+		blobs = append(blobs, blob)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating blobs: %w", err)
+	}
+
+	return blobs, nil
+}
+
 // Ensure blobRepository implements repository.BlobRepository.
 var _ repository.BlobRepository = (*blobRepository)(nil)
@@ -0,0 +1,98 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/repository"
+)
+
+// bucketCORSRepository implements repository.BucketCORSRepository for SQLite.
+type bucketCORSRepository struct {
+	db *DB
+}
+
+// NewBucketCORSRepository creates a new SQLite bucket CORS repository.
+func NewBucketCORSRepository(db *DB) repository.BucketCORSRepository {
+	return &bucketCORSRepository{db: db}
+}
+
+// Get retrieves the CORS configuration of a bucket.
+func (r *bucketCORSRepository) Get(ctx context.Context, bucketID int64) (*domain.BucketCORS, error) {
+	query := `
+		SELECT bucket_id, rules, created_at, updated_at
+		FROM bucket_cors
+		WHERE bucket_id = ?
+	`
+
+	cors := &domain.BucketCORS{}
+	var rules, createdAt, updatedAt string
+
+	err := r.db.QueryRowContext(ctx, query, bucketID).Scan(
+		&cors.BucketID,
+		&rules,
+		&createdAt,
+		&updatedAt,
+	)
+
+	if err != nil {
+		if isNoRows(err) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get bucket CORS configuration: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(rules), &cors.Rules); err != nil {
+		return nil, fmt.Errorf("failed to decode bucket CORS rules: %w", err)
+	}
+	cors.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+	cors.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
+
+	return cors, nil
+}
+
+// Put creates or replaces the CORS configuration of a bucket.
+func (r *bucketCORSRepository) Put(ctx context.Context, cors *domain.BucketCORS) error {
+	rules, err := json.Marshal(cors.Rules)
+	if err != nil {
+		return fmt.Errorf("failed to encode bucket CORS rules: %w", err)
+	}
+
+	query := `
+		INSERT INTO bucket_cors (bucket_id, rules, created_at, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (bucket_id) DO UPDATE SET
+			rules = excluded.rules,
+			updated_at = excluded.updated_at
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		cors.BucketID,
+		string(rules),
+		cors.CreatedAt.Format(time.RFC3339),
+		cors.UpdatedAt.Format(time.RFC3339),
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to put bucket CORS configuration: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the CORS configuration of a bucket.
+func (r *bucketCORSRepository) Delete(ctx context.Context, bucketID int64) error {
+	query := `DELETE FROM bucket_cors WHERE bucket_id = ?`
+
+	if _, err := r.db.ExecContext(ctx, query, bucketID); err != nil {
+		return fmt.Errorf("failed to delete bucket CORS configuration: %w", err)
+	}
+
+	return nil
+}
+
+// Ensure bucketCORSRepository implements repository.BucketCORSRepository.
+var _ repository.BucketCORSRepository = (*bucketCORSRepository)(nil)
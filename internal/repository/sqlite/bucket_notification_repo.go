@@ -0,0 +1,98 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/repository"
+)
+
+// bucketNotificationRepository implements repository.BucketNotificationRepository for SQLite.
+type bucketNotificationRepository struct {
+	db *DB
+}
+
+// NewBucketNotificationRepository creates a new SQLite bucket notification repository.
+func NewBucketNotificationRepository(db *DB) repository.BucketNotificationRepository {
+	return &bucketNotificationRepository{db: db}
+}
+
+// Get retrieves the notification configuration of a bucket.
+func (r *bucketNotificationRepository) Get(ctx context.Context, bucketID int64) (*domain.BucketNotification, error) {
+	query := `
+		SELECT bucket_id, webhooks, created_at, updated_at
+		FROM bucket_notifications
+		WHERE bucket_id = ?
+	`
+
+	notification := &domain.BucketNotification{}
+	var webhooks, createdAt, updatedAt string
+
+	err := r.db.QueryRowContext(ctx, query, bucketID).Scan(
+		&notification.BucketID,
+		&webhooks,
+		&createdAt,
+		&updatedAt,
+	)
+
+	if err != nil {
+		if isNoRows(err) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get bucket notification configuration: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(webhooks), &notification.Webhooks); err != nil {
+		return nil, fmt.Errorf("failed to decode bucket notification webhooks: %w", err)
+	}
+	notification.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+	notification.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
+
+	return notification, nil
+}
+
+// Put creates or replaces the notification configuration of a bucket.
+func (r *bucketNotificationRepository) Put(ctx context.Context, notification *domain.BucketNotification) error {
+	webhooks, err := json.Marshal(notification.Webhooks)
+	if err != nil {
+		return fmt.Errorf("failed to encode bucket notification webhooks: %w", err)
+	}
+
+	query := `
+		INSERT INTO bucket_notifications (bucket_id, webhooks, created_at, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (bucket_id) DO UPDATE SET
+			webhooks = excluded.webhooks,
+			updated_at = excluded.updated_at
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		notification.BucketID,
+		string(webhooks),
+		notification.CreatedAt.Format(time.RFC3339),
+		notification.UpdatedAt.Format(time.RFC3339),
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to put bucket notification configuration: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the notification configuration of a bucket.
+func (r *bucketNotificationRepository) Delete(ctx context.Context, bucketID int64) error {
+	query := `DELETE FROM bucket_notifications WHERE bucket_id = ?`
+
+	if _, err := r.db.ExecContext(ctx, query, bucketID); err != nil {
+		return fmt.Errorf("failed to delete bucket notification configuration: %w", err)
+	}
+
+	return nil
+}
+
+// Ensure bucketNotificationRepository implements repository.BucketNotificationRepository.
+var _ repository.BucketNotificationRepository = (*bucketNotificationRepository)(nil)
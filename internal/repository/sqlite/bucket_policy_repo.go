@@ -0,0 +1,89 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/repository"
+)
+
+// bucketPolicyRepository implements repository.BucketPolicyRepository for SQLite.
+type bucketPolicyRepository struct {
+	db *DB
+}
+
+// NewBucketPolicyRepository creates a new SQLite bucket policy repository.
+func NewBucketPolicyRepository(db *DB) repository.BucketPolicyRepository {
+	return &bucketPolicyRepository{db: db}
+}
+
+// Get retrieves the policy of a bucket.
+func (r *bucketPolicyRepository) Get(ctx context.Context, bucketID int64) (*domain.BucketPolicy, error) {
+	query := `
+		SELECT bucket_id, policy, created_at, updated_at
+		FROM bucket_policies
+		WHERE bucket_id = ?
+	`
+
+	policy := &domain.BucketPolicy{}
+	var createdAt, updatedAt string
+
+	err := r.db.QueryRowContext(ctx, query, bucketID).Scan(
+		&policy.BucketID,
+		&policy.Policy,
+		&createdAt,
+		&updatedAt,
+	)
+
+	if err != nil {
+		if isNoRows(err) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get bucket policy: %w", err)
+	}
+
+	policy.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+	policy.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
+
+	return policy, nil
+}
+
+// Put creates or replaces the policy of a bucket.
+func (r *bucketPolicyRepository) Put(ctx context.Context, policy *domain.BucketPolicy) error {
+	query := `
+		INSERT INTO bucket_policies (bucket_id, policy, created_at, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (bucket_id) DO UPDATE SET
+			policy = excluded.policy,
+			updated_at = excluded.updated_at
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		policy.BucketID,
+		policy.Policy,
+		policy.CreatedAt.Format(time.RFC3339),
+		policy.UpdatedAt.Format(time.RFC3339),
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to put bucket policy: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the policy of a bucket.
+func (r *bucketPolicyRepository) Delete(ctx context.Context, bucketID int64) error {
+	query := `DELETE FROM bucket_policies WHERE bucket_id = ?`
+
+	if _, err := r.db.ExecContext(ctx, query, bucketID); err != nil {
+		return fmt.Errorf("failed to delete bucket policy: %w", err)
+	}
+
+	return nil
+}
+
+// Ensure bucketPolicyRepository implements repository.BucketPolicyRepository.
+var _ repository.BucketPolicyRepository = (*bucketPolicyRepository)(nil)
@@ -2,6 +2,8 @@ package sqlite
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -22,17 +24,33 @@ func NewBucketRepository(db *DB) repository.BucketRepository {
 // Create creates a new bucket.
 func (r *bucketRepository) Create(ctx context.Context, bucket *domain.Bucket) error {
 	query := `
-		INSERT INTO buckets (owner_id, name, region, versioning, acl, object_lock, created_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO buckets (owner_id, name, region, versioning, acl, object_ownership, object_lock, state, max_versions_per_key, case_insensitive_keys, content_type_policy, require_encryption, default_retention, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
+	contentTypePolicy, err := encodeContentTypePolicy(bucket.ContentTypePolicy)
+	if err != nil {
+		return err
+	}
+	defaultRetention, err := encodeDefaultRetention(bucket.DefaultRetention)
+	if err != nil {
+		return err
+	}
+
 	result, err := r.db.ExecContext(ctx, query,
 		bucket.OwnerID,
 		bucket.Name,
 		bucket.Region,
 		bucket.Versioning,
 		bucket.ACL,
+		bucket.ObjectOwnership,
 		boolToInt(bucket.ObjectLock),
+		bucket.State,
+		bucket.MaxVersionsPerKey,
+		boolToInt(bucket.CaseInsensitiveKeys),
+		contentTypePolicy,
+		boolToInt(bucket.RequireEncryption),
+		defaultRetention,
 		bucket.CreatedAt.Format(time.RFC3339),
 	)
 
@@ -55,13 +73,17 @@ func (r *bucketRepository) Create(ctx context.Context, bucket *domain.Bucket) er
 // GetByID retrieves a bucket by ID.
 func (r *bucketRepository) GetByID(ctx context.Context, id int64) (*domain.Bucket, error) {
 	query := `
-		SELECT id, owner_id, name, region, versioning, acl, object_lock, created_at
+		SELECT id, owner_id, name, region, versioning, acl, object_ownership, object_lock, state, max_versions_per_key, case_insensitive_keys, content_type_policy, require_encryption, default_retention, created_at
 		FROM buckets
 		WHERE id = ?
 	`
 
 	bucket := &domain.Bucket{}
 	var objectLock int
+	var caseInsensitiveKeys int
+	var contentTypePolicy sql.NullString
+	var requireEncryption int
+	var defaultRetention sql.NullString
 	var createdAt string
 
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
@@ -71,7 +93,14 @@ func (r *bucketRepository) GetByID(ctx context.Context, id int64) (*domain.Bucke
 		&bucket.Region,
 		&bucket.Versioning,
 		&bucket.ACL,
+		&bucket.ObjectOwnership,
 		&objectLock,
+		&bucket.State,
+		&bucket.MaxVersionsPerKey,
+		&caseInsensitiveKeys,
+		&contentTypePolicy,
+		&requireEncryption,
+		&defaultRetention,
 		&createdAt,
 	)
 
@@ -83,6 +112,10 @@ func (r *bucketRepository) GetByID(ctx context.Context, id int64) (*domain.Bucke
 	}
 
 	bucket.ObjectLock = objectLock != 0
+	bucket.CaseInsensitiveKeys = caseInsensitiveKeys != 0
+	bucket.ContentTypePolicy = decodeContentTypePolicy(contentTypePolicy)
+	bucket.RequireEncryption = requireEncryption != 0
+	bucket.DefaultRetention = decodeDefaultRetention(defaultRetention)
 	bucket.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
 
 	return bucket, nil
@@ -91,13 +124,17 @@ func (r *bucketRepository) GetByID(ctx context.Context, id int64) (*domain.Bucke
 // GetByName retrieves a bucket by name.
 func (r *bucketRepository) GetByName(ctx context.Context, name string) (*domain.Bucket, error) {
 	query := `
-		SELECT id, owner_id, name, region, versioning, acl, object_lock, created_at
+		SELECT id, owner_id, name, region, versioning, acl, object_ownership, object_lock, state, max_versions_per_key, case_insensitive_keys, content_type_policy, require_encryption, default_retention, created_at
 		FROM buckets
 		WHERE name = ?
 	`
 
 	bucket := &domain.Bucket{}
 	var objectLock int
+	var caseInsensitiveKeys int
+	var contentTypePolicy sql.NullString
+	var requireEncryption int
+	var defaultRetention sql.NullString
 	var createdAt string
 
 	err := r.db.QueryRowContext(ctx, query, name).Scan(
@@ -107,7 +144,14 @@ func (r *bucketRepository) GetByName(ctx context.Context, name string) (*domain.
 		&bucket.Region,
 		&bucket.Versioning,
 		&bucket.ACL,
+		&bucket.ObjectOwnership,
 		&objectLock,
+		&bucket.State,
+		&bucket.MaxVersionsPerKey,
+		&caseInsensitiveKeys,
+		&contentTypePolicy,
+		&requireEncryption,
+		&defaultRetention,
 		&createdAt,
 	)
 
@@ -119,6 +163,10 @@ func (r *bucketRepository) GetByName(ctx context.Context, name string) (*domain.
 	}
 
 	bucket.ObjectLock = objectLock != 0
+	bucket.CaseInsensitiveKeys = caseInsensitiveKeys != 0
+	bucket.ContentTypePolicy = decodeContentTypePolicy(contentTypePolicy)
+	bucket.RequireEncryption = requireEncryption != 0
+	bucket.DefaultRetention = decodeDefaultRetention(defaultRetention)
 	bucket.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
 
 	return bucket, nil
@@ -131,7 +179,7 @@ func (r *bucketRepository) List(ctx context.Context, userID int64) ([]*domain.Bu
 
 	if userID > 0 {
 		query = `
-			SELECT id, owner_id, name, region, versioning, acl, object_lock, created_at
+			SELECT id, owner_id, name, region, versioning, acl, object_ownership, object_lock, state, max_versions_per_key, case_insensitive_keys, content_type_policy, require_encryption, default_retention, created_at
 			FROM buckets
 			WHERE owner_id = ?
 			ORDER BY name ASC
@@ -139,7 +187,7 @@ func (r *bucketRepository) List(ctx context.Context, userID int64) ([]*domain.Bu
 		args = []interface{}{userID}
 	} else {
 		query = `
-			SELECT id, owner_id, name, region, versioning, acl, object_lock, created_at
+			SELECT id, owner_id, name, region, versioning, acl, object_ownership, object_lock, state, max_versions_per_key, case_insensitive_keys, content_type_policy, require_encryption, default_retention, created_at
 			FROM buckets
 			ORDER BY name ASC
 		`
@@ -155,6 +203,10 @@ func (r *bucketRepository) List(ctx context.Context, userID int64) ([]*domain.Bu
 	for rows.Next() {
 		bucket := &domain.Bucket{}
 		var objectLock int
+		var caseInsensitiveKeys int
+		var contentTypePolicy sql.NullString
+		var requireEncryption int
+		var defaultRetention sql.NullString
 		var createdAt string
 
 		err := rows.Scan(
@@ -164,7 +216,14 @@ func (r *bucketRepository) List(ctx context.Context, userID int64) ([]*domain.Bu
 			&bucket.Region,
 			&bucket.Versioning,
 			&bucket.ACL,
+			&bucket.ObjectOwnership,
 			&objectLock,
+			&bucket.State,
+			&bucket.MaxVersionsPerKey,
+			&caseInsensitiveKeys,
+			&contentTypePolicy,
+			&requireEncryption,
+			&defaultRetention,
 			&createdAt,
 		)
 		if err != nil {
@@ -172,6 +231,10 @@ func (r *bucketRepository) List(ctx context.Context, userID int64) ([]*domain.Bu
 		}
 
 		bucket.ObjectLock = objectLock != 0
+		bucket.CaseInsensitiveKeys = caseInsensitiveKeys != 0
+		bucket.ContentTypePolicy = decodeContentTypePolicy(contentTypePolicy)
+		bucket.RequireEncryption = requireEncryption != 0
+		bucket.DefaultRetention = decodeDefaultRetention(defaultRetention)
 		bucket.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
 
 		buckets = append(buckets, bucket)
@@ -244,6 +307,159 @@ func (r *bucketRepository) UpdateACL(ctx context.Context, id int64, acl domain.B
 	return nil
 }
 
+// UpdateObjectOwnership updates the object ownership setting of a bucket.
+func (r *bucketRepository) UpdateObjectOwnership(ctx context.Context, id int64, ownership domain.ObjectOwnership) error {
+	query := `UPDATE buckets SET object_ownership = ? WHERE id = ?`
+
+	result, err := r.db.ExecContext(ctx, query, ownership, id)
+	if err != nil {
+		return fmt.Errorf("failed to update object ownership: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return domain.ErrBucketNotFound
+	}
+
+	return nil
+}
+
+// UpdateState updates the lifecycle state of a bucket.
+func (r *bucketRepository) UpdateState(ctx context.Context, id int64, state domain.BucketState) error {
+	query := `UPDATE buckets SET state = ? WHERE id = ?`
+
+	result, err := r.db.ExecContext(ctx, query, state, id)
+	if err != nil {
+		return fmt.Errorf("failed to update bucket state: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return domain.ErrBucketNotFound
+	}
+
+	return nil
+}
+
+// UpdateCaseInsensitiveKeys updates whether a bucket matches keys case-insensitively.
+// Turning it off resets every normalized key to the stored key.
+func (r *bucketRepository) UpdateCaseInsensitiveKeys(ctx context.Context, id int64, enabled bool) error {
+	return r.db.WithTx(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, `UPDATE buckets SET case_insensitive_keys = ? WHERE id = ?`, boolToInt(enabled), id)
+		if err != nil {
+			return fmt.Errorf("failed to update key case sensitivity: %w", err)
+		}
+
+		rowsAffected, _ := result.RowsAffected()
+		if rowsAffected == 0 {
+			return domain.ErrBucketNotFound
+		}
+
+		if !enabled {
+			if _, err := tx.ExecContext(ctx, `UPDATE objects SET normalized_key = key WHERE bucket_id = ?`, id); err != nil {
+				return fmt.Errorf("failed to reset normalized keys: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// UpdateContentTypePolicy replaces the content-type policy of a bucket.
+// A nil policy clears it.
+func (r *bucketRepository) UpdateContentTypePolicy(ctx context.Context, id int64, policy *domain.ContentTypePolicy) error {
+	value, err := encodeContentTypePolicy(policy)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx, `UPDATE buckets SET content_type_policy = ? WHERE id = ?`, value, id)
+	if err != nil {
+		return fmt.Errorf("failed to update content type policy: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return domain.ErrBucketNotFound
+	}
+
+	return nil
+}
+
+// UpdateMaxVersionsPerKey updates the per-key version limit of a bucket.
+func (r *bucketRepository) UpdateMaxVersionsPerKey(ctx context.Context, id int64, maxVersions int) error {
+	query := `UPDATE buckets SET max_versions_per_key = ? WHERE id = ?`
+
+	result, err := r.db.ExecContext(ctx, query, maxVersions, id)
+	if err != nil {
+		return fmt.Errorf("failed to update max versions per key: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return domain.ErrBucketNotFound
+	}
+
+	return nil
+}
+
+// UpdateRequireEncryption updates whether a bucket rejects uploads that do
+// not request server-side encryption.
+func (r *bucketRepository) UpdateRequireEncryption(ctx context.Context, id int64, required bool) error {
+	query := `UPDATE buckets SET require_encryption = ? WHERE id = ?`
+
+	result, err := r.db.ExecContext(ctx, query, boolToInt(required), id)
+	if err != nil {
+		return fmt.Errorf("failed to update encryption requirement: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return domain.ErrBucketNotFound
+	}
+
+	return nil
+}
+
+// EnableObjectLock turns on object lock for a bucket.
+func (r *bucketRepository) EnableObjectLock(ctx context.Context, id int64) error {
+	query := `UPDATE buckets SET object_lock = 1 WHERE id = ?`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to enable object lock: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return domain.ErrBucketNotFound
+	}
+
+	return nil
+}
+
+// UpdateDefaultRetention replaces the default retention of a bucket.
+func (r *bucketRepository) UpdateDefaultRetention(ctx context.Context, id int64, retention *domain.DefaultRetention) error {
+	defaultRetention, err := encodeDefaultRetention(retention)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE buckets SET default_retention = ? WHERE id = ?`
+
+	result, err := r.db.ExecContext(ctx, query, defaultRetention, id)
+	if err != nil {
+		return fmt.Errorf("failed to update default retention: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return domain.ErrBucketNotFound
+	}
+
+	return nil
+}
+
 // Delete deletes a bucket by ID.
 func (r *bucketRepository) Delete(ctx context.Context, id int64) error {
 	query := `DELETE FROM buckets WHERE id = ?`
@@ -288,6 +504,12 @@ func (r *bucketRepository) ExistsByName(ctx context.Context, name string) (bool,
 	return count > 0, nil
 }
 
+// LockName locks the bucket name within this process. SQLite databases are
+// not shared between server instances, so a process-local lock suffices.
+func (r *bucketRepository) LockName(ctx context.Context, name string) (func(), error) {
+	return r.db.nameLocks.lock(ctx, name)
+}
+
 // IsEmpty checks if a bucket contains any objects.
 func (r *bucketRepository) IsEmpty(ctx context.Context, id int64) (bool, error) {
 	var count int
@@ -313,3 +535,54 @@ func (r *bucketRepository) GetACLByName(ctx context.Context, name string) (domai
 
 // Ensure bucketRepository implements repository.BucketRepository.
 var _ repository.BucketRepository = (*bucketRepository)(nil)
+
+// encodeContentTypePolicy serializes a policy for the content_type_policy column.
+func encodeContentTypePolicy(policy *domain.ContentTypePolicy) (sql.NullString, error) {
+	if policy == nil {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(policy)
+	if err != nil {
+		return sql.NullString{}, fmt.Errorf("failed to encode content type policy: %w", err)
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+// decodeContentTypePolicy parses the content_type_policy column; NULL or
+// unreadable values mean no policy.
+func decodeContentTypePolicy(value sql.NullString) *domain.ContentTypePolicy {
+	if !value.Valid || value.String == "" {
+		return nil
+	}
+	policy := &domain.ContentTypePolicy{}
+	if err := json.Unmarshal([]byte(value.String), policy); err != nil {
+		return nil
+	}
+	return policy
+}
+
+// encodeDefaultRetention serializes a default retention for the
+// default_retention column.
+func encodeDefaultRetention(retention *domain.DefaultRetention) (sql.NullString, error) {
+	if retention == nil {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(retention)
+	if err != nil {
+		return sql.NullString{}, fmt.Errorf("failed to encode default retention: %w", err)
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+// decodeDefaultRetention parses the default_retention column; NULL or
+// unreadable values mean no default retention.
+func decodeDefaultRetention(value sql.NullString) *domain.DefaultRetention {
+	if !value.Valid || value.String == "" {
+		return nil
+	}
+	retention := &domain.DefaultRetention{}
+	if err := json.Unmarshal([]byte(value.String), retention); err != nil {
+		return nil
+	}
+	return retention
+}
@@ -13,6 +13,8 @@ import (
 	_ "modernc.org/sqlite"
 
 	"github.com/rs/zerolog"
+
+	"github.com/prn-tf/alexander-storage/internal/repository/migrate"
 )
 
 //go:embed migrations/*.sql
@@ -65,6 +67,9 @@ type DB struct {
 	db     *sql.DB
 	logger zerolog.Logger
 	path   string
+
+	// nameLocks serializes creation and deletion of the same bucket name.
+	nameLocks *keyedLocks
 }
 
 // NewDB creates a new SQLite database connection.
@@ -105,9 +110,10 @@ func NewDB(ctx context.Context, cfg Config, logger zerolog.Logger) (*DB, error)
 		Msg("connected to SQLite database")
 
 	return &DB{
-		db:     db,
-		logger: logger,
-		path:   cfg.Path,
+		db:        db,
+		logger:    logger,
+		path:      cfg.Path,
+		nameLocks: newKeyedLocks(),
 	}, nil
 }
 
@@ -182,49 +188,73 @@ func (db *DB) QueryRowContext(ctx context.Context, query string, args ...interfa
 	return db.db.QueryRowContext(ctx, query, args...)
 }
 
-// Migrate runs database migrations.
+// querier is implemented by both DB and *sql.Tx, so statements can run
+// inside or outside a transaction.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Ensure both DB and Tx implement querier
+var (
+	_ querier = (*DB)(nil)
+	_ querier = (*sql.Tx)(nil)
+)
+
+// Migrate applies the embedded migrations that are newer than the recorded
+// schema version. It is safe to call on every startup.
 func (db *DB) Migrate(ctx context.Context) error {
-	// Create migrations table if not exists
-	_, err := db.db.ExecContext(ctx, `
+	migrations, err := migrate.Load(migrationsFS, "migrations")
+	if err != nil {
+		return err
+	}
+
+	_, err = migrate.NewRunner(&migrationDriver{db: db.db}, migrations, db.logger).Up(ctx)
+	return err
+}
+
+// SchemaVersion returns the latest applied migration version.
+func (db *DB) SchemaVersion(ctx context.Context) (int, error) {
+	return migrate.NewRunner(&migrationDriver{db: db.db}, nil, db.logger).Version(ctx)
+}
+
+// migrationDriver records one schema_migrations row per applied version.
+type migrationDriver struct {
+	db *sql.DB
+}
+
+// EnsureVersionTable creates the schema_migrations table.
+func (d *migrationDriver) EnsureVersionTable(ctx context.Context) error {
+	_, err := d.db.ExecContext(ctx, `
 		CREATE TABLE IF NOT EXISTS schema_migrations (
 			version INTEGER PRIMARY KEY,
 			applied_at TEXT NOT NULL DEFAULT (datetime('now'))
 		)
 	`)
-	if err != nil {
-		return fmt.Errorf("failed to create migrations table: %w", err)
-	}
+	return err
+}
+
+// Version returns the highest applied version.
+func (d *migrationDriver) Version(ctx context.Context) (int, error) {
+	var version int
+	err := d.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
+	return version, err
+}
 
-	// Get current version
-	var currentVersion int
-	err = db.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&currentVersion)
+// Apply runs the migration and records it in one transaction.
+func (d *migrationDriver) Apply(ctx context.Context, m migrate.Migration) error {
+	tx, err := d.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to get current migration version: %w", err)
+		return err
 	}
+	defer func() { _ = tx.Rollback() }()
 
-	db.logger.Info().Int("current_version", currentVersion).Msg("checking migrations")
-
-	// For now, we apply all migrations in a single file
-	// In a production system, you'd iterate through migration files
-	if currentVersion < 1 {
-		// Read and execute the init migration
-		migration, err := migrationsFS.ReadFile("migrations/000001_init.up.sql")
-		if err != nil {
-			// If embedded migrations not found, try to continue (migrations may be applied externally)
-			db.logger.Warn().Msg("embedded migrations not found, skipping auto-migration")
-			return nil
-		}
-
-		if _, err := db.db.ExecContext(ctx, string(migration)); err != nil {
-			return fmt.Errorf("failed to apply migration 1: %w", err)
-		}
-
-		if _, err := db.db.ExecContext(ctx, `INSERT INTO schema_migrations (version) VALUES (1)`); err != nil {
-			return fmt.Errorf("failed to record migration: %w", err)
-		}
-
-		db.logger.Info().Int("version", 1).Msg("applied migration")
+	if _, err := tx.ExecContext(ctx, m.UpSQL); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version) VALUES (?)`, m.Version); err != nil {
+		return fmt.Errorf("failed to record migration: %w", err)
 	}
 
-	return nil
+	return tx.Commit()
 }
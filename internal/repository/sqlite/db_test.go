@@ -0,0 +1,46 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/repository/migrate"
+)
+
+func TestDB_Migrate(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := NewDB(ctx, DefaultConfig(filepath.Join(t.TempDir(), "alexander.db")), zerolog.Nop())
+	require.NoError(t, err)
+	defer db.Close()
+
+	migrations, err := migrate.Load(migrationsFS, "migrations")
+	require.NoError(t, err)
+	latest := migrations[len(migrations)-1].Version
+
+	version, err := db.SchemaVersion(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, version)
+
+	require.NoError(t, db.Migrate(ctx))
+
+	version, err = db.SchemaVersion(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, latest, version)
+
+	// Re-running applies nothing and leaves the recorded versions unchanged
+	require.NoError(t, db.Migrate(ctx))
+
+	var applied int
+	require.NoError(t, db.QueryRowContext(ctx, `SELECT COUNT(*) FROM schema_migrations`).Scan(&applied))
+	assert.Equal(t, len(migrations), applied)
+
+	// The schema is usable
+	_, err = db.ExecContext(ctx, `SELECT id, expires_at FROM objects LIMIT 1`)
+	assert.NoError(t, err)
+}
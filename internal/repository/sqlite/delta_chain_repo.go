@@ -0,0 +1,165 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/prn-tf/alexander-storage/internal/storage"
+)
+
+// deltaChainRepository implements storage.DeltaChainStore for SQLite.
+type deltaChainRepository struct {
+	db *DB
+}
+
+// NewDeltaChainRepository creates a new SQLite delta chain store.
+func NewDeltaChainRepository(db *DB) storage.DeltaChainStore {
+	return &deltaChainRepository{db: db}
+}
+
+// GetDelta returns the link of a blob stored as a delta.
+func (r *deltaChainRepository) GetDelta(ctx context.Context, contentHash string) (*storage.DeltaLink, error) {
+	query := `
+		SELECT content_hash, base_hash, data_hash, depth, size, data_size, instructions, created_at
+		FROM delta_chains
+		WHERE content_hash = ?
+	`
+
+	link := &storage.DeltaLink{}
+	var instructions, createdAt string
+
+	err := r.db.QueryRowContext(ctx, query, contentHash).Scan(
+		&link.ContentHash,
+		&link.BaseHash,
+		&link.DataHash,
+		&link.Depth,
+		&link.Size,
+		&link.DataSize,
+		&instructions,
+		&createdAt,
+	)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, storage.ErrDeltaNotFound
+		}
+		return nil, fmt.Errorf("failed to get delta: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(instructions), &link.Instructions); err != nil {
+		return nil, fmt.Errorf("failed to decode delta instructions: %w", err)
+	}
+	link.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+
+	return link, nil
+}
+
+// CreateDelta records a link and takes a reference on its base and data blobs.
+func (r *deltaChainRepository) CreateDelta(ctx context.Context, link *storage.DeltaLink) (bool, error) {
+	instructions, err := json.Marshal(link.Instructions)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode delta instructions: %w", err)
+	}
+
+	created := false
+	err = r.db.WithTx(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO delta_chains (content_hash, base_hash, data_hash, depth, size, data_size, instructions, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (content_hash) DO NOTHING
+		`,
+			link.ContentHash,
+			link.BaseHash,
+			link.DataHash,
+			link.Depth,
+			link.Size,
+			link.DataSize,
+			string(instructions),
+			link.CreatedAt.Format(time.RFC3339),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert delta: %w", err)
+		}
+		if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+			return nil
+		}
+
+		result, err = tx.ExecContext(ctx,
+			`UPDATE blobs SET ref_count = ref_count + 1 WHERE content_hash = ?`,
+			link.BaseHash,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to reference delta base: %w", err)
+		}
+		if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+			return storage.ErrBlobNotFound
+		}
+
+		now := time.Now().UTC().Format(time.RFC3339)
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO blobs (content_hash, size, storage_path, ref_count, is_encrypted, encryption_iv, created_at, last_accessed)
+			VALUES (?, ?, ?, 1, 0, NULL, ?, ?)
+			ON CONFLICT (content_hash) DO UPDATE SET ref_count = blobs.ref_count + 1
+		`, link.DataHash, link.DataSize, link.DataPath, now, now)
+		if err != nil {
+			return fmt.Errorf("failed to reference delta data: %w", err)
+		}
+
+		created = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return created, nil
+}
+
+// DeleteDelta removes the link of a blob and releases its references.
+func (r *deltaChainRepository) DeleteDelta(ctx context.Context, contentHash string) error {
+	return r.db.WithTx(ctx, func(tx *sql.Tx) error {
+		var baseHash, dataHash string
+		err := tx.QueryRowContext(ctx,
+			`SELECT base_hash, data_hash FROM delta_chains WHERE content_hash = ?`,
+			contentHash,
+		).Scan(&baseHash, &dataHash)
+		if err != nil {
+			if isNoRows(err) {
+				return storage.ErrDeltaNotFound
+			}
+			return fmt.Errorf("failed to get delta: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM delta_chains WHERE content_hash = ?`, contentHash); err != nil {
+			return fmt.Errorf("failed to delete delta: %w", err)
+		}
+
+		// The base and data blob may be the same blob, referenced twice
+		for _, hash := range []string{baseHash, dataHash} {
+			_, err := tx.ExecContext(ctx,
+				`UPDATE blobs SET ref_count = MAX(ref_count - 1, 0) WHERE content_hash = ?`,
+				hash,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to release delta reference: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// HasDeltas reports whether any link uses the blob as its base.
+func (r *deltaChainRepository) HasDeltas(ctx context.Context, baseHash string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx,
+		`SELECT EXISTS(SELECT 1 FROM delta_chains WHERE base_hash = ?)`,
+		baseHash,
+	).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check delta dependents: %w", err)
+	}
+	return exists, nil
+}
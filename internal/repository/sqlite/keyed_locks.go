@@ -0,0 +1,63 @@
+package sqlite
+
+import (
+	"context"
+	"sync"
+)
+
+// keyedLocks is a set of mutexes addressed by key. Entries exist only while
+// the key is locked or waited for.
+type keyedLocks struct {
+	mu    sync.Mutex
+	locks map[string]*keyedLock
+}
+
+// keyedLock is a single lock; the channel holds a token while it is free.
+type keyedLock struct {
+	token   chan struct{}
+	waiters int
+}
+
+// newKeyedLocks creates an empty lock set.
+func newKeyedLocks() *keyedLocks {
+	return &keyedLocks{locks: make(map[string]*keyedLock)}
+}
+
+// lock blocks until it holds the lock for key or ctx is done.
+func (k *keyedLocks) lock(ctx context.Context, key string) (func(), error) {
+	k.mu.Lock()
+	l, ok := k.locks[key]
+	if !ok {
+		l = &keyedLock{token: make(chan struct{}, 1)}
+		l.token <- struct{}{}
+		k.locks[key] = l
+	}
+	l.waiters++
+	k.mu.Unlock()
+
+	select {
+	case <-l.token:
+	case <-ctx.Done():
+		k.leave(key, l)
+		return nil, ctx.Err()
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.token <- struct{}{}
+			k.leave(key, l)
+		})
+	}, nil
+}
+
+// leave drops the caller's interest in l and forgets it once unused.
+func (k *keyedLocks) leave(key string, l *keyedLock) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	l.waiters--
+	if l.waiters == 0 {
+		delete(k.locks, key)
+	}
+}
@@ -2,7 +2,9 @@ package sqlite
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/prn-tf/alexander-storage/internal/domain"
@@ -22,8 +24,8 @@ func NewLifecycleRepository(db *DB) repository.LifecycleRepository {
 // Create creates a new lifecycle rule.
 func (r *lifecycleRepository) Create(ctx context.Context, rule *domain.LifecycleRule) error {
 	query := `
-		INSERT INTO lifecycle_rules (bucket_id, rule_id, prefix, expiration_days, status, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO lifecycle_rules (bucket_id, rule_id, prefix, expiration_days, expiration_date, transition_days, transition_date, transition_storage_class, status, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	result, err := r.db.ExecContext(ctx, query,
@@ -31,6 +33,10 @@ func (r *lifecycleRepository) Create(ctx context.Context, rule *domain.Lifecycle
 		rule.RuleID,
 		rule.Prefix,
 		rule.ExpirationDays,
+		formatLifecycleDate(rule.ExpirationDate),
+		rule.TransitionDays,
+		formatLifecycleDate(rule.TransitionDate),
+		rule.TransitionStorageClass,
 		rule.Status,
 		rule.CreatedAt.Format(time.RFC3339),
 		rule.UpdatedAt.Format(time.RFC3339),
@@ -55,13 +61,14 @@ func (r *lifecycleRepository) Create(ctx context.Context, rule *domain.Lifecycle
 // GetByID retrieves a lifecycle rule by ID.
 func (r *lifecycleRepository) GetByID(ctx context.Context, id int64) (*domain.LifecycleRule, error) {
 	query := `
-		SELECT id, bucket_id, rule_id, prefix, expiration_days, status, created_at, updated_at
+		SELECT id, bucket_id, rule_id, prefix, expiration_days, expiration_date, transition_days, transition_date, transition_storage_class, status, created_at, updated_at
 		FROM lifecycle_rules
 		WHERE id = ?
 	`
 
 	rule := &domain.LifecycleRule{}
 	var createdAt, updatedAt string
+	var expirationDate, transitionDate sql.NullString
 
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&rule.ID,
@@ -69,6 +76,10 @@ func (r *lifecycleRepository) GetByID(ctx context.Context, id int64) (*domain.Li
 		&rule.RuleID,
 		&rule.Prefix,
 		&rule.ExpirationDays,
+		&expirationDate,
+		&rule.TransitionDays,
+		&transitionDate,
+		&rule.TransitionStorageClass,
 		&rule.Status,
 		&createdAt,
 		&updatedAt,
@@ -83,6 +94,8 @@ func (r *lifecycleRepository) GetByID(ctx context.Context, id int64) (*domain.Li
 
 	rule.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
 	rule.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
+	rule.ExpirationDate = parseLifecycleDate(expirationDate)
+	rule.TransitionDate = parseLifecycleDate(transitionDate)
 
 	return rule, nil
 }
@@ -90,13 +103,14 @@ func (r *lifecycleRepository) GetByID(ctx context.Context, id int64) (*domain.Li
 // GetByBucketAndRuleID retrieves a rule by bucket ID and rule ID.
 func (r *lifecycleRepository) GetByBucketAndRuleID(ctx context.Context, bucketID int64, ruleID string) (*domain.LifecycleRule, error) {
 	query := `
-		SELECT id, bucket_id, rule_id, prefix, expiration_days, status, created_at, updated_at
+		SELECT id, bucket_id, rule_id, prefix, expiration_days, expiration_date, transition_days, transition_date, transition_storage_class, status, created_at, updated_at
 		FROM lifecycle_rules
 		WHERE bucket_id = ? AND rule_id = ?
 	`
 
 	rule := &domain.LifecycleRule{}
 	var createdAt, updatedAt string
+	var expirationDate, transitionDate sql.NullString
 
 	err := r.db.QueryRowContext(ctx, query, bucketID, ruleID).Scan(
 		&rule.ID,
@@ -104,6 +118,10 @@ func (r *lifecycleRepository) GetByBucketAndRuleID(ctx context.Context, bucketID
 		&rule.RuleID,
 		&rule.Prefix,
 		&rule.ExpirationDays,
+		&expirationDate,
+		&rule.TransitionDays,
+		&transitionDate,
+		&rule.TransitionStorageClass,
 		&rule.Status,
 		&createdAt,
 		&updatedAt,
@@ -118,6 +136,8 @@ func (r *lifecycleRepository) GetByBucketAndRuleID(ctx context.Context, bucketID
 
 	rule.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
 	rule.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
+	rule.ExpirationDate = parseLifecycleDate(expirationDate)
+	rule.TransitionDate = parseLifecycleDate(transitionDate)
 
 	return rule, nil
 }
@@ -125,7 +145,7 @@ func (r *lifecycleRepository) GetByBucketAndRuleID(ctx context.Context, bucketID
 // ListByBucket returns all lifecycle rules for a bucket.
 func (r *lifecycleRepository) ListByBucket(ctx context.Context, bucketID int64) ([]*domain.LifecycleRule, error) {
 	query := `
-		SELECT id, bucket_id, rule_id, prefix, expiration_days, status, created_at, updated_at
+		SELECT id, bucket_id, rule_id, prefix, expiration_days, expiration_date, transition_days, transition_date, transition_storage_class, status, created_at, updated_at
 		FROM lifecycle_rules
 		WHERE bucket_id = ?
 		ORDER BY rule_id ASC
@@ -141,6 +161,7 @@ func (r *lifecycleRepository) ListByBucket(ctx context.Context, bucketID int64)
 	for rows.Next() {
 		rule := &domain.LifecycleRule{}
 		var createdAt, updatedAt string
+		var expirationDate, transitionDate sql.NullString
 
 		err := rows.Scan(
 			&rule.ID,
@@ -148,6 +169,10 @@ func (r *lifecycleRepository) ListByBucket(ctx context.Context, bucketID int64)
 			&rule.RuleID,
 			&rule.Prefix,
 			&rule.ExpirationDays,
+			&expirationDate,
+			&rule.TransitionDays,
+			&transitionDate,
+			&rule.TransitionStorageClass,
 			&rule.Status,
 			&createdAt,
 			&updatedAt,
@@ -158,6 +183,8 @@ func (r *lifecycleRepository) ListByBucket(ctx context.Context, bucketID int64)
 
 		rule.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
 		rule.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
+		rule.ExpirationDate = parseLifecycleDate(expirationDate)
+		rule.TransitionDate = parseLifecycleDate(transitionDate)
 
 		rules = append(rules, rule)
 	}
@@ -172,7 +199,7 @@ func (r *lifecycleRepository) ListByBucket(ctx context.Context, bucketID int64)
 // ListEnabledByBucket returns only enabled rules for a bucket.
 func (r *lifecycleRepository) ListEnabledByBucket(ctx context.Context, bucketID int64) ([]*domain.LifecycleRule, error) {
 	query := `
-		SELECT id, bucket_id, rule_id, prefix, expiration_days, status, created_at, updated_at
+		SELECT id, bucket_id, rule_id, prefix, expiration_days, expiration_date, transition_days, transition_date, transition_storage_class, status, created_at, updated_at
 		FROM lifecycle_rules
 		WHERE bucket_id = ? AND status = 'Enabled'
 		ORDER BY rule_id ASC
@@ -188,6 +215,7 @@ func (r *lifecycleRepository) ListEnabledByBucket(ctx context.Context, bucketID
 	for rows.Next() {
 		rule := &domain.LifecycleRule{}
 		var createdAt, updatedAt string
+		var expirationDate, transitionDate sql.NullString
 
 		err := rows.Scan(
 			&rule.ID,
@@ -195,6 +223,10 @@ func (r *lifecycleRepository) ListEnabledByBucket(ctx context.Context, bucketID
 			&rule.RuleID,
 			&rule.Prefix,
 			&rule.ExpirationDays,
+			&expirationDate,
+			&rule.TransitionDays,
+			&transitionDate,
+			&rule.TransitionStorageClass,
 			&rule.Status,
 			&createdAt,
 			&updatedAt,
@@ -205,6 +237,8 @@ func (r *lifecycleRepository) ListEnabledByBucket(ctx context.Context, bucketID
 
 		rule.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
 		rule.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
+		rule.ExpirationDate = parseLifecycleDate(expirationDate)
+		rule.TransitionDate = parseLifecycleDate(transitionDate)
 
 		rules = append(rules, rule)
 	}
@@ -220,13 +254,17 @@ func (r *lifecycleRepository) ListEnabledByBucket(ctx context.Context, bucketID
 func (r *lifecycleRepository) Update(ctx context.Context, rule *domain.LifecycleRule) error {
 	query := `
 		UPDATE lifecycle_rules
-		SET prefix = ?, expiration_days = ?, status = ?, updated_at = ?
+		SET prefix = ?, expiration_days = ?, expiration_date = ?, transition_days = ?, transition_date = ?, transition_storage_class = ?, status = ?, updated_at = ?
 		WHERE id = ?
 	`
 
 	result, err := r.db.ExecContext(ctx, query,
 		rule.Prefix,
 		rule.ExpirationDays,
+		formatLifecycleDate(rule.ExpirationDate),
+		rule.TransitionDays,
+		formatLifecycleDate(rule.TransitionDate),
+		rule.TransitionStorageClass,
 		rule.Status,
 		time.Now().UTC().Format(time.RFC3339),
 		rule.ID,
@@ -293,7 +331,7 @@ func (r *lifecycleRepository) DeleteByBucket(ctx context.Context, bucketID int64
 // ListAllEnabled returns all enabled lifecycle rules across all buckets.
 func (r *lifecycleRepository) ListAllEnabled(ctx context.Context) ([]*domain.LifecycleRule, error) {
 	query := `
-		SELECT id, bucket_id, rule_id, prefix, expiration_days, status, created_at, updated_at
+		SELECT id, bucket_id, rule_id, prefix, expiration_days, expiration_date, transition_days, transition_date, transition_storage_class, status, created_at, updated_at
 		FROM lifecycle_rules
 		WHERE status = 'Enabled'
 		ORDER BY bucket_id ASC, rule_id ASC
@@ -309,6 +347,7 @@ func (r *lifecycleRepository) ListAllEnabled(ctx context.Context) ([]*domain.Lif
 	for rows.Next() {
 		rule := &domain.LifecycleRule{}
 		var createdAt, updatedAt string
+		var expirationDate, transitionDate sql.NullString
 
 		err := rows.Scan(
 			&rule.ID,
@@ -316,6 +355,10 @@ func (r *lifecycleRepository) ListAllEnabled(ctx context.Context) ([]*domain.Lif
 			&rule.RuleID,
 			&rule.Prefix,
 			&rule.ExpirationDays,
+			&expirationDate,
+			&rule.TransitionDays,
+			&transitionDate,
+			&rule.TransitionStorageClass,
 			&rule.Status,
 			&createdAt,
 			&updatedAt,
@@ -326,6 +369,8 @@ func (r *lifecycleRepository) ListAllEnabled(ctx context.Context) ([]*domain.Lif
 
 		rule.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
 		rule.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
+		rule.ExpirationDate = parseLifecycleDate(expirationDate)
+		rule.TransitionDate = parseLifecycleDate(transitionDate)
 
 		rules = append(rules, rule)
 	}
@@ -337,5 +382,82 @@ func (r *lifecycleRepository) ListAllEnabled(ctx context.Context) ([]*domain.Lif
 	return rules, nil
 }
 
+// ListTransitionCandidates returns latest objects older than cutoff, with optional
+// prefix, that were not transitioned to any of the given classes.
+func (r *lifecycleRepository) ListTransitionCandidates(ctx context.Context, bucketID int64, prefix string, olderThan time.Time, satisfiedBy []domain.TransitionClass, limit int) ([]*domain.Object, error) {
+	classes := make([]string, len(satisfiedBy))
+	args := []interface{}{bucketID, olderThan.Format(time.RFC3339), prefix, prefix}
+	for i, class := range satisfiedBy {
+		classes[i] = "?"
+		args = append(args, string(class))
+	}
+	args = append(args, limit)
+
+	// An empty IN list is valid in SQLite and matches nothing
+	query := `
+		SELECT o.id, o.bucket_id, o.key, o.version_id, o.is_latest, o.is_delete_marker,
+			o.content_hash, o.size, o.content_type, o.etag, o.storage_class, o.metadata, o.created_at, o.deleted_at,
+			o.sse_customer_algorithm, o.sse_customer_key_md5, o.checksum_algorithm, o.checksum,
+			o.cache_control, o.content_disposition, o.content_encoding, o.expires
+		FROM objects o
+		WHERE o.bucket_id = ?
+			AND o.is_latest = 1
+			AND o.is_delete_marker = 0
+			AND o.deleted_at IS NULL
+			AND o.created_at < ?
+			AND (? = '' OR o.key LIKE ? || '%')
+			AND NOT EXISTS (
+				SELECT 1 FROM object_transitions t
+				WHERE t.object_id = o.id AND t.storage_class IN (` + strings.Join(classes, ", ") + `)
+			)
+		ORDER BY o.created_at ASC
+		LIMIT ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transition candidates: %w", err)
+	}
+	defer rows.Close()
+
+	return scanObjects(rows)
+}
+
+// MarkTransitioned records that an object was moved to a storage class.
+func (r *lifecycleRepository) MarkTransitioned(ctx context.Context, objectID int64, class domain.TransitionClass) error {
+	query := `
+		INSERT INTO object_transitions (object_id, storage_class, transitioned_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT (object_id) DO UPDATE SET storage_class = excluded.storage_class, transitioned_at = excluded.transitioned_at
+	`
+
+	_, err := r.db.ExecContext(ctx, query, objectID, string(class), time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("failed to mark object transitioned: %w", err)
+	}
+
+	return nil
+}
+
 // Ensure lifecycleRepository implements repository.LifecycleRepository.
 var _ repository.LifecycleRepository = (*lifecycleRepository)(nil)
+
+// formatLifecycleDate converts an optional rule date to its column value.
+func formatLifecycleDate(date *time.Time) sql.NullString {
+	if date == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: date.UTC().Format(time.RFC3339), Valid: true}
+}
+
+// parseLifecycleDate converts a rule date column value back.
+func parseLifecycleDate(value sql.NullString) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	date, err := time.Parse(time.RFC3339, value.String)
+	if err != nil {
+		return nil
+	}
+	return &date
+}
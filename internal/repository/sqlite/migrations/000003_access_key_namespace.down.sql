@@ -0,0 +1,4 @@
+-- Rollback: 000003_access_key_namespace (requires SQLite 3.35+)
+
+ALTER TABLE access_keys DROP COLUMN namespace_prefix;
+ALTER TABLE access_keys DROP COLUMN namespace_bucket;
@@ -0,0 +1,6 @@
+-- Alexander Storage Database Schema for SQLite
+-- Migration: 000003_access_key_namespace
+-- Description: Confine an access key to a key prefix inside a shared bucket
+
+ALTER TABLE access_keys ADD COLUMN namespace_bucket TEXT NOT NULL DEFAULT '';
+ALTER TABLE access_keys ADD COLUMN namespace_prefix TEXT NOT NULL DEFAULT '';
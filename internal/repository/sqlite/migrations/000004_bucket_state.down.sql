@@ -0,0 +1,3 @@
+-- Rollback: 000004_bucket_state (requires SQLite 3.35+)
+
+ALTER TABLE buckets DROP COLUMN state;
@@ -0,0 +1,5 @@
+-- Alexander Storage Database Schema for SQLite
+-- Migration: 000004_bucket_state
+-- Description: Track bucket lifecycle state for two-phase bucket deletion
+
+ALTER TABLE buckets ADD COLUMN state TEXT NOT NULL DEFAULT 'active';
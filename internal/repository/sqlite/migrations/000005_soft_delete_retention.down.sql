@@ -0,0 +1,3 @@
+-- Rollback: 000005_soft_delete_retention
+
+DROP INDEX IF EXISTS idx_objects_deleted_at;
@@ -0,0 +1,12 @@
+-- Alexander Storage Database Schema for SQLite
+-- Migration: 000005_soft_delete_retention
+-- Description: Keep blob references of soft-deleted objects until they are purged
+
+-- Objects deleted before this migration already released their blob reference,
+-- so they cannot be restored and are removed now.
+DELETE FROM objects WHERE deleted_at IS NOT NULL;
+
+-- Index for the purge job (oldest deletions first)
+CREATE INDEX IF NOT EXISTS idx_objects_deleted_at
+    ON objects (deleted_at)
+    WHERE deleted_at IS NOT NULL;
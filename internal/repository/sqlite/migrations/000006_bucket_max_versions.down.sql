@@ -0,0 +1,3 @@
+-- Rollback: 000006_bucket_max_versions (requires SQLite 3.35+)
+
+ALTER TABLE buckets DROP COLUMN max_versions_per_key;
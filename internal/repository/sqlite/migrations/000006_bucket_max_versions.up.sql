@@ -0,0 +1,5 @@
+-- Alexander Storage Database Schema for SQLite
+-- Migration: 000006_bucket_max_versions
+-- Description: Cap the number of versions kept per object key
+
+ALTER TABLE buckets ADD COLUMN max_versions_per_key INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+-- Rollback: 000007_bucket_object_ownership (requires SQLite 3.35+)
+
+ALTER TABLE buckets DROP COLUMN object_ownership;
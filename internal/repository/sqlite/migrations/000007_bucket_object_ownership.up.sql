@@ -0,0 +1,7 @@
+-- Alexander Storage Database Schema for SQLite
+-- Migration: 000007_bucket_object_ownership
+-- Description: Object ownership controls; BucketOwnerEnforced disables ACLs
+
+-- Existing buckets keep honoring their canned ACLs
+ALTER TABLE buckets ADD COLUMN object_ownership TEXT NOT NULL DEFAULT 'ObjectWriter'
+    CHECK (object_ownership IN ('BucketOwnerEnforced', 'BucketOwnerPreferred', 'ObjectWriter'));
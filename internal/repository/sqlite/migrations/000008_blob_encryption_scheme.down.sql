@@ -0,0 +1,3 @@
+-- Rollback: 000008_blob_encryption_scheme (requires SQLite 3.35+)
+
+ALTER TABLE blobs DROP COLUMN encryption_scheme;
@@ -0,0 +1,6 @@
+-- Alexander Storage Database Schema for SQLite
+-- Migration: 000008_blob_encryption_scheme
+-- Description: Record the encryption scheme per blob so AES and ChaCha blobs can coexist
+
+-- NULL means the backend's default scheme
+ALTER TABLE blobs ADD COLUMN encryption_scheme TEXT;
@@ -0,0 +1,3 @@
+-- Rollback: 000009_blob_encryption_iv (requires SQLite 3.35+)
+
+ALTER TABLE blobs DROP COLUMN encryption_iv;
@@ -0,0 +1,5 @@
+-- Alexander Storage Database Schema for SQLite
+-- Migration: 000009_blob_encryption_iv
+-- Description: Add the blobs.encryption_iv column the blob repository already writes
+
+ALTER TABLE blobs ADD COLUMN encryption_iv TEXT;
@@ -0,0 +1,11 @@
+-- Rollback: 000010_case_insensitive_keys (requires SQLite 3.35+)
+
+DROP INDEX IF EXISTS idx_objects_normalized_versions;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_objects_latest
+    ON objects (bucket_id, key)
+    WHERE is_latest = 1;
+DROP INDEX IF EXISTS idx_objects_latest_normalized;
+
+ALTER TABLE objects DROP COLUMN normalized_key;
+ALTER TABLE buckets DROP COLUMN case_insensitive_keys;
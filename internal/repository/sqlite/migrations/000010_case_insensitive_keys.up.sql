@@ -0,0 +1,17 @@
+-- Alexander Storage Database Schema for SQLite
+-- Migration: 000010_case_insensitive_keys
+-- Description: Optional per-bucket case-insensitive keys via a normalized key column
+
+ALTER TABLE buckets ADD COLUMN case_insensitive_keys INTEGER NOT NULL DEFAULT 0;
+
+-- normalized_key equals key unless the bucket is case-insensitive
+ALTER TABLE objects ADD COLUMN normalized_key TEXT NOT NULL DEFAULT '';
+UPDATE objects SET normalized_key = key;
+
+-- Uniqueness and lookups move from key to normalized_key
+CREATE UNIQUE INDEX IF NOT EXISTS idx_objects_latest_normalized
+    ON objects (bucket_id, normalized_key)
+    WHERE is_latest = 1;
+DROP INDEX IF EXISTS idx_objects_latest;
+
+CREATE INDEX IF NOT EXISTS idx_objects_normalized_versions ON objects (bucket_id, normalized_key, created_at DESC);
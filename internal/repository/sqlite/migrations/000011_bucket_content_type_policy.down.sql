@@ -0,0 +1,3 @@
+-- Rollback: 000011_bucket_content_type_policy (requires SQLite 3.35+)
+
+ALTER TABLE buckets DROP COLUMN content_type_policy;
@@ -0,0 +1,5 @@
+-- Alexander Storage Database Schema for SQLite
+-- Migration: 000011_bucket_content_type_policy
+-- Description: Optional per-bucket content-type allow/deny policy
+
+ALTER TABLE buckets ADD COLUMN content_type_policy TEXT;
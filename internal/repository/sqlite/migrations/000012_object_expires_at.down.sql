@@ -0,0 +1,5 @@
+-- Rollback: 000012_object_expires_at (requires SQLite 3.35+)
+
+DROP INDEX IF EXISTS idx_objects_expires_at;
+
+ALTER TABLE objects DROP COLUMN expires_at;
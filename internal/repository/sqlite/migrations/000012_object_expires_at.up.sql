@@ -0,0 +1,9 @@
+-- Alexander Storage Database Schema for SQLite
+-- Migration: 000012_object_expires_at
+-- Description: Optional per-object expiration time enforced by the lifecycle worker
+
+ALTER TABLE objects ADD COLUMN expires_at TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_objects_expires_at
+    ON objects (expires_at)
+    WHERE expires_at IS NOT NULL AND is_latest = 1 AND deleted_at IS NULL;
@@ -0,0 +1,3 @@
+-- Rollback: 000013_object_tags
+
+DROP TABLE IF EXISTS object_tags;
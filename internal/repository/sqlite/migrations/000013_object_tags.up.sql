@@ -0,0 +1,10 @@
+-- Alexander Storage Database Schema for SQLite
+-- Migration: 000013_object_tags
+-- Description: Object tags (x-amz-tagging), stored per object version
+
+CREATE TABLE IF NOT EXISTS object_tags (
+    object_id   INTEGER NOT NULL REFERENCES objects(id) ON DELETE CASCADE,
+    tag_key     TEXT NOT NULL,
+    tag_value   TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (object_id, tag_key)
+);
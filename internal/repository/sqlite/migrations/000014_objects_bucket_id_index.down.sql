@@ -0,0 +1,3 @@
+-- Rollback: 000014_objects_bucket_id_index
+
+DROP INDEX IF EXISTS idx_objects_bucket_id;
@@ -0,0 +1,7 @@
+-- Alexander Storage Database Schema for SQLite
+-- Migration: 000014_objects_bucket_id_index
+-- Description: Keyset index for iterating every live version in a bucket
+
+CREATE INDEX IF NOT EXISTS idx_objects_bucket_id
+    ON objects (bucket_id, id)
+    WHERE deleted_at IS NULL;
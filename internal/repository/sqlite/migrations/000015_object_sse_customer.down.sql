@@ -0,0 +1,5 @@
+-- Rollback: 000015_object_sse_customer (requires SQLite 3.35+)
+
+ALTER TABLE objects DROP COLUMN sse_customer_key_md5;
+
+ALTER TABLE objects DROP COLUMN sse_customer_algorithm;
@@ -0,0 +1,6 @@
+-- Alexander Storage Database Schema for SQLite
+-- Migration: 000015_object_sse_customer
+-- Description: SSE-C algorithm and key MD5 of objects encrypted with customer-provided keys
+
+ALTER TABLE objects ADD COLUMN sse_customer_algorithm TEXT NOT NULL DEFAULT '';
+ALTER TABLE objects ADD COLUMN sse_customer_key_md5 TEXT NOT NULL DEFAULT '';
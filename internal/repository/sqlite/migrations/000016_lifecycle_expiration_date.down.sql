@@ -0,0 +1,3 @@
+-- Rollback: 000016_lifecycle_expiration_date (requires SQLite 3.35+)
+
+ALTER TABLE lifecycle_rules DROP COLUMN expiration_date;
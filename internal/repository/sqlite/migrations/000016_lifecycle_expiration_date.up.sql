@@ -0,0 +1,5 @@
+-- Alexander Storage Database Schema for SQLite
+-- Migration: 000016_lifecycle_expiration_date
+-- Description: Lifecycle rules expiring objects on a fixed date
+
+ALTER TABLE lifecycle_rules ADD COLUMN expiration_date TEXT;
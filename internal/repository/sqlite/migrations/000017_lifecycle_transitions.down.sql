@@ -0,0 +1,7 @@
+-- Rollback: 000017_lifecycle_transitions (requires SQLite 3.35+)
+
+DROP TABLE IF EXISTS object_transitions;
+
+ALTER TABLE lifecycle_rules DROP COLUMN transition_storage_class;
+ALTER TABLE lifecycle_rules DROP COLUMN transition_date;
+ALTER TABLE lifecycle_rules DROP COLUMN transition_days;
@@ -0,0 +1,13 @@
+-- Alexander Storage Database Schema for SQLite
+-- Migration: 000017_lifecycle_transitions
+-- Description: Lifecycle rules moving objects to the warm or cold tier
+
+ALTER TABLE lifecycle_rules ADD COLUMN transition_days INTEGER;
+ALTER TABLE lifecycle_rules ADD COLUMN transition_date TEXT;
+ALTER TABLE lifecycle_rules ADD COLUMN transition_storage_class TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS object_transitions (
+    object_id       INTEGER PRIMARY KEY REFERENCES objects(id) ON DELETE CASCADE,
+    storage_class   TEXT NOT NULL,
+    transitioned_at TEXT NOT NULL
+);
@@ -0,0 +1,9 @@
+-- Rollback: 000018_object_checksums (requires SQLite 3.35+)
+
+ALTER TABLE upload_parts DROP COLUMN checksum;
+ALTER TABLE upload_parts DROP COLUMN checksum_algorithm;
+
+ALTER TABLE multipart_uploads DROP COLUMN checksum_algorithm;
+
+ALTER TABLE objects DROP COLUMN checksum;
+ALTER TABLE objects DROP COLUMN checksum_algorithm;
@@ -0,0 +1,3 @@
+-- Rollback: 000019_bucket_policies
+
+DROP TABLE IF EXISTS bucket_policies;
@@ -0,0 +1,10 @@
+-- Alexander Storage Database Schema for SQLite
+-- Migration: 000019_bucket_policies
+-- Description: JSON access policies attached to buckets
+
+CREATE TABLE IF NOT EXISTS bucket_policies (
+    bucket_id   INTEGER PRIMARY KEY REFERENCES buckets(id) ON DELETE CASCADE,
+    policy      TEXT NOT NULL,
+    created_at  TEXT NOT NULL,
+    updated_at  TEXT NOT NULL
+);
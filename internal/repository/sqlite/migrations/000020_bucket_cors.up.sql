@@ -0,0 +1,10 @@
+-- Alexander Storage Database Schema for SQLite
+-- Migration: 000020_bucket_cors
+-- Description: CORS configurations attached to buckets
+
+CREATE TABLE IF NOT EXISTS bucket_cors (
+    bucket_id   INTEGER PRIMARY KEY REFERENCES buckets(id) ON DELETE CASCADE,
+    rules       TEXT NOT NULL,
+    created_at  TEXT NOT NULL,
+    updated_at  TEXT NOT NULL
+);
@@ -0,0 +1,3 @@
+-- Rollback: 000021_bucket_require_encryption (requires SQLite 3.35+)
+
+ALTER TABLE buckets DROP COLUMN require_encryption;
@@ -0,0 +1,5 @@
+-- Alexander Storage Database Schema for SQLite
+-- Migration: 000021_bucket_require_encryption
+-- Description: Per-bucket requirement that uploads request server-side encryption
+
+ALTER TABLE buckets ADD COLUMN require_encryption INTEGER NOT NULL DEFAULT 0;
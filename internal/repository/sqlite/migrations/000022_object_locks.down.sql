@@ -0,0 +1,3 @@
+-- Rollback: 000022_object_locks
+
+DROP TABLE IF EXISTS object_locks;
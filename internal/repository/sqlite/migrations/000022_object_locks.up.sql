@@ -0,0 +1,10 @@
+-- Alexander Storage Database Schema for SQLite
+-- Migration: 000022_object_locks
+-- Description: Object lock retention and legal hold, stored per object version
+
+CREATE TABLE IF NOT EXISTS object_locks (
+    object_id    INTEGER PRIMARY KEY REFERENCES objects(id) ON DELETE CASCADE,
+    mode         TEXT NOT NULL DEFAULT '',
+    retain_until TEXT,
+    legal_hold   INTEGER NOT NULL DEFAULT 0
+);
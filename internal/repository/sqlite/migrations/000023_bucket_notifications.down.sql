@@ -0,0 +1,3 @@
+-- Rollback: 000023_bucket_notifications
+
+DROP TABLE IF EXISTS bucket_notifications;
@@ -0,0 +1,10 @@
+-- Alexander Storage Database Schema for SQLite
+-- Migration: 000023_bucket_notifications
+-- Description: Event notification webhooks attached to buckets
+
+CREATE TABLE IF NOT EXISTS bucket_notifications (
+    bucket_id   INTEGER PRIMARY KEY REFERENCES buckets(id) ON DELETE CASCADE,
+    webhooks    TEXT NOT NULL,
+    created_at  TEXT NOT NULL,
+    updated_at  TEXT NOT NULL
+);
@@ -0,0 +1,5 @@
+-- Rollback: 000024_object_http_headers (requires SQLite 3.35+)
+
+ALTER TABLE objects DROP COLUMN content_encoding;
+ALTER TABLE objects DROP COLUMN content_disposition;
+ALTER TABLE objects DROP COLUMN cache_control;
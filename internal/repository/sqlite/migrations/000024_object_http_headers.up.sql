@@ -0,0 +1,7 @@
+-- Alexander Storage Database Schema for SQLite
+-- Migration: 000024_object_http_headers
+-- Description: Standard HTTP headers stored with objects
+
+ALTER TABLE objects ADD COLUMN cache_control TEXT NOT NULL DEFAULT '';
+ALTER TABLE objects ADD COLUMN content_disposition TEXT NOT NULL DEFAULT '';
+ALTER TABLE objects ADD COLUMN content_encoding TEXT NOT NULL DEFAULT '';
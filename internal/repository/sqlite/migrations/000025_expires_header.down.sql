@@ -0,0 +1,8 @@
+-- Rollback: 000025_expires_header (requires SQLite 3.35+)
+
+ALTER TABLE multipart_uploads DROP COLUMN expires;
+ALTER TABLE multipart_uploads DROP COLUMN content_encoding;
+ALTER TABLE multipart_uploads DROP COLUMN content_disposition;
+ALTER TABLE multipart_uploads DROP COLUMN cache_control;
+
+ALTER TABLE objects DROP COLUMN expires;
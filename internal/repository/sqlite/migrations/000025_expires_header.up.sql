@@ -0,0 +1,10 @@
+-- Alexander Storage Database Schema for SQLite
+-- Migration: 000025_expires_header
+-- Description: Expires header of objects, and standard HTTP headers of multipart uploads
+
+ALTER TABLE objects ADD COLUMN expires TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE multipart_uploads ADD COLUMN cache_control TEXT NOT NULL DEFAULT '';
+ALTER TABLE multipart_uploads ADD COLUMN content_disposition TEXT NOT NULL DEFAULT '';
+ALTER TABLE multipart_uploads ADD COLUMN content_encoding TEXT NOT NULL DEFAULT '';
+ALTER TABLE multipart_uploads ADD COLUMN expires TEXT NOT NULL DEFAULT '';
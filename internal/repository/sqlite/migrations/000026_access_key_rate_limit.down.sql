@@ -0,0 +1,4 @@
+-- Rollback: 000026_access_key_rate_limit (requires SQLite 3.35+)
+
+ALTER TABLE access_keys DROP COLUMN rate_limit_burst;
+ALTER TABLE access_keys DROP COLUMN rate_limit_rps;
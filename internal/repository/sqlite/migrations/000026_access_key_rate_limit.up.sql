@@ -0,0 +1,6 @@
+-- Alexander Storage Database Schema for SQLite
+-- Migration: 000026_access_key_rate_limit
+-- Description: Per access key overrides of the request rate limit
+
+ALTER TABLE access_keys ADD COLUMN rate_limit_rps REAL NOT NULL DEFAULT 0;
+ALTER TABLE access_keys ADD COLUMN rate_limit_burst INTEGER NOT NULL DEFAULT 0;
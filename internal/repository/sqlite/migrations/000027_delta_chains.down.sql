@@ -0,0 +1,3 @@
+-- Rollback: 000027_delta_chains
+
+DROP TABLE IF EXISTS delta_chains;
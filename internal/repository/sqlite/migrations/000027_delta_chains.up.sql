@@ -0,0 +1,19 @@
+-- Alexander Storage Database Schema for SQLite
+-- Migration: 000027_delta_chains
+-- Description: Blobs stored as deltas against a base blob
+
+CREATE TABLE IF NOT EXISTS delta_chains (
+    content_hash    TEXT PRIMARY KEY,           -- Blob rebuilt from the delta
+    base_hash       TEXT NOT NULL,              -- Blob the delta copies from
+    data_hash       TEXT NOT NULL,              -- Blob holding the inserted bytes
+    depth           INTEGER NOT NULL,           -- Deltas between this blob and a full blob
+    size            INTEGER NOT NULL,
+    data_size       INTEGER NOT NULL,
+    instructions    TEXT NOT NULL,              -- JSON array of copy/insert instructions
+    created_at      TEXT NOT NULL,
+
+    CONSTRAINT delta_chains_depth_positive CHECK (depth > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_delta_chains_base_hash ON delta_chains (base_hash);
+CREATE INDEX IF NOT EXISTS idx_delta_chains_data_hash ON delta_chains (data_hash);
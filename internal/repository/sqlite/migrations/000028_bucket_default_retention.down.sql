@@ -0,0 +1,3 @@
+-- Rollback: 000028_bucket_default_retention (requires SQLite 3.35+)
+
+ALTER TABLE buckets DROP COLUMN default_retention;
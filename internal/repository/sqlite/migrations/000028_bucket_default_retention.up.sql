@@ -0,0 +1,5 @@
+-- Alexander Storage Database Schema for SQLite
+-- Migration: 000028_bucket_default_retention
+-- Description: Default object lock retention applied to new object versions
+
+ALTER TABLE buckets ADD COLUMN default_retention TEXT;
@@ -27,8 +27,9 @@ func NewMultipartRepository(db *DB) repository.MultipartUploadRepository {
 // Create creates a new multipart upload.
 func (r *multipartRepository) Create(ctx context.Context, upload *domain.MultipartUpload) error {
 	query := `
-		INSERT INTO multipart_uploads (id, bucket_id, key, initiator_id, status, storage_class, metadata, initiated_at, expires_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO multipart_uploads (id, bucket_id, key, initiator_id, status, storage_class, metadata, initiated_at, expires_at,
+			checksum_algorithm, cache_control, content_disposition, content_encoding, expires)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	var metadataJSON string
@@ -49,6 +50,11 @@ func (r *multipartRepository) Create(ctx context.Context, upload *domain.Multipa
 		metadataJSON,
 		upload.InitiatedAt.Format(time.RFC3339),
 		upload.ExpiresAt.Format(time.RFC3339),
+		upload.ChecksumAlgorithm,
+		upload.CacheControl,
+		upload.ContentDisposition,
+		upload.ContentEncoding,
+		upload.Expires,
 	)
 
 	if err != nil {
@@ -61,7 +67,8 @@ func (r *multipartRepository) Create(ctx context.Context, upload *domain.Multipa
 // GetByID retrieves a multipart upload by ID.
 func (r *multipartRepository) GetByID(ctx context.Context, uploadID uuid.UUID) (*domain.MultipartUpload, error) {
 	query := `
-		SELECT id, bucket_id, key, initiator_id, status, storage_class, metadata, initiated_at, expires_at, completed_at
+		SELECT id, bucket_id, key, initiator_id, status, storage_class, metadata, initiated_at, expires_at, completed_at,
+			checksum_algorithm, cache_control, content_disposition, content_encoding, expires
 		FROM multipart_uploads
 		WHERE id = ?
 	`
@@ -83,6 +90,11 @@ func (r *multipartRepository) GetByID(ctx context.Context, uploadID uuid.UUID) (
 		&initiatedAt,
 		&expiresAt,
 		&completedAt,
+		&upload.ChecksumAlgorithm,
+		&upload.CacheControl,
+		&upload.ContentDisposition,
+		&upload.ContentEncoding,
+		&upload.Expires,
 	)
 
 	if err != nil {
@@ -113,21 +125,25 @@ func (r *multipartRepository) List(ctx context.Context, bucketID int64, opts rep
 		maxUploads = 1000
 	}
 
+	// The upload ID marker continues within the key marker, after the
+	// marker upload; without one the listing continues after the key
 	query := `
 		SELECT id, key, initiated_at, storage_class
 		FROM multipart_uploads
 		WHERE bucket_id = ? AND status = ?
-			AND (? = '' OR key LIKE ? || '%')
-			AND (? = '' OR key > ?)
-		ORDER BY key ASC, initiated_at ASC
+			AND (? = '' OR substr(key, 1, length(?)) = ?)
+			AND (? = '' OR key > ? OR (key = ? AND ? != '' AND (initiated_at, id) > (
+				SELECT initiated_at, id FROM multipart_uploads WHERE id = ?
+			)))
+		ORDER BY key ASC, initiated_at ASC, id ASC
 		LIMIT ?
 	`
 
 	rows, err := r.db.QueryContext(ctx, query,
 		bucketID,
 		domain.MultipartStatusInProgress,
-		opts.Prefix, opts.Prefix,
-		opts.KeyMarker, opts.KeyMarker,
+		opts.Prefix, opts.Prefix, opts.Prefix,
+		opts.KeyMarker, opts.KeyMarker, opts.KeyMarker, opts.UploadIDMarker, opts.UploadIDMarker,
 		maxUploads+1,
 	)
 	if err != nil {
@@ -217,6 +233,18 @@ func (r *multipartRepository) Delete(ctx context.Context, uploadID uuid.UUID) er
 	})
 }
 
+// CountInProgress returns the number of in-progress multipart uploads.
+func (r *multipartRepository) CountInProgress(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM multipart_uploads WHERE status = ?
+	`, domain.MultipartStatusInProgress).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count in-progress uploads: %w", err)
+	}
+	return count, nil
+}
+
 // DeleteExpired deletes expired multipart uploads.
 func (r *multipartRepository) DeleteExpired(ctx context.Context) (int64, error) {
 	now := time.Now().UTC().Format(time.RFC3339)
@@ -249,13 +277,15 @@ func (r *multipartRepository) DeleteExpired(ctx context.Context) (int64, error)
 func (r *multipartRepository) CreatePart(ctx context.Context, part *domain.UploadPart) error {
 	// SQLite uses INSERT OR REPLACE for upsert
 	query := `
-		INSERT INTO upload_parts (upload_id, part_number, content_hash, size, etag, created_at)
-		VALUES (?, ?, ?, ?, ?, ?)
+		INSERT INTO upload_parts (upload_id, part_number, content_hash, size, etag, created_at, checksum_algorithm, checksum)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(upload_id, part_number) DO UPDATE SET
 			content_hash = excluded.content_hash,
 			size = excluded.size,
 			etag = excluded.etag,
-			created_at = excluded.created_at
+			created_at = excluded.created_at,
+			checksum_algorithm = excluded.checksum_algorithm,
+			checksum = excluded.checksum
 	`
 
 	result, err := r.db.ExecContext(ctx, query,
@@ -265,6 +295,8 @@ func (r *multipartRepository) CreatePart(ctx context.Context, part *domain.Uploa
 		part.Size,
 		part.ETag,
 		part.CreatedAt.Format(time.RFC3339),
+		part.ChecksumAlgorithm,
+		part.Checksum,
 	)
 
 	if err != nil {
@@ -280,7 +312,7 @@ func (r *multipartRepository) CreatePart(ctx context.Context, part *domain.Uploa
 // GetPart retrieves a specific part.
 func (r *multipartRepository) GetPart(ctx context.Context, uploadID uuid.UUID, partNumber int) (*domain.UploadPart, error) {
 	query := `
-		SELECT id, upload_id, part_number, content_hash, size, etag, created_at
+		SELECT id, upload_id, part_number, content_hash, size, etag, created_at, checksum_algorithm, checksum
 		FROM upload_parts
 		WHERE upload_id = ? AND part_number = ?
 	`
@@ -297,6 +329,8 @@ func (r *multipartRepository) GetPart(ctx context.Context, uploadID uuid.UUID, p
 		&part.Size,
 		&part.ETag,
 		&createdAt,
+		&part.ChecksumAlgorithm,
+		&part.Checksum,
 	)
 
 	if err != nil {
@@ -393,7 +427,7 @@ func (r *multipartRepository) GetPartsForCompletion(ctx context.Context, uploadI
 	}
 
 	query := fmt.Sprintf(`
-		SELECT id, upload_id, part_number, content_hash, size, etag, created_at
+		SELECT id, upload_id, part_number, content_hash, size, etag, created_at, checksum_algorithm, checksum
 		FROM upload_parts
 		WHERE upload_id = ? AND part_number IN (%s)
 		ORDER BY part_number ASC
@@ -405,6 +439,55 @@ func (r *multipartRepository) GetPartsForCompletion(ctx context.Context, uploadI
 	}
 	defer rows.Close()
 
+	var parts []*domain.UploadPart
+	for rows.Next() {
+		part := &domain.UploadPart{}
+		var uploadIDStr string
+		var createdAt string
+
+		err := rows.Scan(
+			&part.ID,
+			&uploadIDStr,
+			&part.PartNumber,
+			&part.ContentHash,
+			&part.Size,
+			&part.ETag,
+			&createdAt,
+			&part.ChecksumAlgorithm,
+			&part.Checksum,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan part: %w", err)
+		}
+
+		part.UploadID = uuid.MustParse(uploadIDStr)
+		part.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+		parts = append(parts, part)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating parts: %w", err)
+	}
+
+	return parts, nil
+}
+
+// ListInProgressParts returns the parts of every in-progress upload.
+func (r *multipartRepository) ListInProgressParts(ctx context.Context) ([]*domain.UploadPart, error) {
+	query := `
+		SELECT p.id, p.upload_id, p.part_number, p.content_hash, p.size, p.etag, p.created_at
+		FROM upload_parts p
+		JOIN multipart_uploads u ON u.id = p.upload_id
+		WHERE u.status = ?
+		ORDER BY p.upload_id, p.part_number
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, string(domain.MultipartStatusInProgress))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list in-progress parts: %w", err)
+	}
+	defer rows.Close()
+
 	var parts []*domain.UploadPart
 	for rows.Next() {
 		part := &domain.UploadPart{}
@@ -436,5 +519,20 @@ func (r *multipartRepository) GetPartsForCompletion(ctx context.Context, uploadI
 	return parts, nil
 }
 
+// DeletePart deletes a single part.
+func (r *multipartRepository) DeletePart(ctx context.Context, uploadID uuid.UUID, partNumber int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM upload_parts WHERE upload_id = ? AND part_number = ?`, uploadID.String(), partNumber)
+	if err != nil {
+		return fmt.Errorf("failed to delete part: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return domain.ErrPartNotFound
+	}
+
+	return nil
+}
+
 // Ensure multipartRepository implements repository.MultipartUploadRepository.
 var _ repository.MultipartUploadRepository = (*multipartRepository)(nil)
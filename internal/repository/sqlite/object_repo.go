@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -25,12 +26,32 @@ func NewObjectRepository(db *DB) repository.ObjectRepository {
 
 // Create creates a new object.
 func (r *objectRepository) Create(ctx context.Context, obj *domain.Object) error {
+	return r.db.WithTx(ctx, func(tx *sql.Tx) error {
+		return r.create(ctx, tx, obj)
+	})
+}
+
+// create inserts obj, with its lock and tags, through q.
+func (r *objectRepository) create(ctx context.Context, q querier, obj *domain.Object) error {
 	query := `
-		INSERT INTO objects (bucket_id, key, version_id, is_latest, is_delete_marker, 
-			content_hash, size, content_type, etag, storage_class, metadata, created_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO objects (bucket_id, key, normalized_key, version_id, is_latest, is_delete_marker, 
+			content_hash, size, content_type, etag, storage_class, metadata, created_at, expires_at,
+			sse_customer_algorithm, sse_customer_key_md5, checksum_algorithm, checksum,
+			cache_control, content_disposition, content_encoding, expires)
+		SELECT ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+		WHERE EXISTS (SELECT 1 FROM buckets WHERE id = ? AND state = 'active')
 	`
 
+	var expiresAt sql.NullString
+	if obj.ExpiresAt != nil {
+		expiresAt = sql.NullString{String: obj.ExpiresAt.UTC().Format(time.RFC3339), Valid: true}
+	}
+
+	normalizedKey := obj.NormalizedKey
+	if normalizedKey == "" {
+		normalizedKey = obj.Key
+	}
+
 	var metadataJSON string
 	if obj.Metadata != nil {
 		data, _ := json.Marshal(obj.Metadata)
@@ -39,9 +60,10 @@ func (r *objectRepository) Create(ctx context.Context, obj *domain.Object) error
 		metadataJSON = "{}"
 	}
 
-	result, err := r.db.ExecContext(ctx, query,
+	result, err := q.ExecContext(ctx, query,
 		obj.BucketID,
 		obj.Key,
+		normalizedKey,
 		obj.VersionID.String(),
 		boolToInt(obj.IsLatest),
 		boolToInt(obj.IsDeleteMarker),
@@ -52,26 +74,147 @@ func (r *objectRepository) Create(ctx context.Context, obj *domain.Object) error
 		obj.StorageClass,
 		metadataJSON,
 		obj.CreatedAt.Format(time.RFC3339),
+		expiresAt,
+		obj.SSECustomerAlgorithm,
+		obj.SSECustomerKeyMD5,
+		obj.ChecksumAlgorithm,
+		obj.Checksum,
+		obj.CacheControl,
+		obj.ContentDisposition,
+		obj.ContentEncoding,
+		obj.Expires,
+		obj.BucketID,
 	)
 
 	if err != nil {
 		return fmt.Errorf("failed to create object: %w", err)
 	}
 
+	// Nothing is inserted once DeleteBucket has marked the bucket
+	if rows, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	} else if rows == 0 {
+		return inactiveBucketError(ctx, q, obj.BucketID)
+	}
+
 	id, err := result.LastInsertId()
 	if err != nil {
 		return fmt.Errorf("failed to get last insert ID: %w", err)
 	}
 	obj.ID = id
 
-	return nil
+	if obj.Lock != nil {
+		if err := putLock(ctx, q, obj.ID, obj.Lock); err != nil {
+			return err
+		}
+	}
+	return insertTags(ctx, q, obj.ID, obj.Tags)
+}
+
+// inactiveBucketError explains why an object could not be written to the
+// bucket: it is gone or being deleted.
+func inactiveBucketError(ctx context.Context, q querier, bucketID int64) error {
+	var state string
+	err := q.QueryRowContext(ctx, `SELECT state FROM buckets WHERE id = ?`, bucketID).Scan(&state)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return domain.ErrBucketNotFound
+	case err != nil:
+		return fmt.Errorf("failed to get bucket state: %w", err)
+	}
+	return domain.ErrBucketDeleting
+}
+
+// CreateBatch stores the writes in one transaction, each under a savepoint.
+func (r *objectRepository) CreateBatch(ctx context.Context, writes []*repository.ObjectWrite) error {
+	return r.db.WithTx(ctx, func(tx *sql.Tx) error {
+		for _, w := range writes {
+			if _, err := tx.ExecContext(ctx, `SAVEPOINT object_write`); err != nil {
+				return fmt.Errorf("failed to create savepoint: %w", err)
+			}
+			w.Replaced, w.Err = r.applyWrite(ctx, tx, w)
+			if w.Err != nil {
+				if _, err := tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT object_write`); err != nil {
+					return fmt.Errorf("failed to roll back to savepoint: %w", err)
+				}
+			}
+			if _, err := tx.ExecContext(ctx, `RELEASE SAVEPOINT object_write`); err != nil {
+				return fmt.Errorf("failed to release savepoint: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// applyWrite takes the blob reference of w, replaces the version it
+// replaces and creates its object, returning the replaced version.
+func (r *objectRepository) applyWrite(ctx context.Context, tx *sql.Tx, w *repository.ObjectWrite) (*domain.Object, error) {
+	obj := w.Object
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	if obj.ContentHash != nil {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO blobs (content_hash, size, storage_path, ref_count, is_encrypted, encryption_iv, created_at, last_accessed)
+			VALUES (?, ?, ?, 1, 0, NULL, ?, ?)
+			ON CONFLICT (content_hash) DO UPDATE SET ref_count = ref_count + 1, last_accessed = excluded.last_accessed
+		`, *obj.ContentHash, w.StoredSize, w.StoragePath, now, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to upsert blob: %w", err)
+		}
+	}
+
+	key := obj.NormalizedKey
+	if key == "" {
+		key = obj.Key
+	}
+
+	var replaced *domain.Object
+	var row *sql.Row
+	switch w.Replace {
+	case repository.ReplaceLatest:
+		row = tx.QueryRowContext(ctx,
+			`SELECT id, content_hash FROM objects WHERE bucket_id = ? AND normalized_key = ? AND is_latest = 1 AND deleted_at IS NULL`,
+			obj.BucketID, key)
+	case repository.ReplaceNullVersion:
+		row = tx.QueryRowContext(ctx,
+			`SELECT id, content_hash FROM objects WHERE bucket_id = ? AND normalized_key = ? AND version_id = ? AND deleted_at IS NULL`,
+			obj.BucketID, key, uuid.Nil.String())
+	}
+	if row != nil {
+		replaced = &domain.Object{}
+		err := row.Scan(&replaced.ID, &replaced.ContentHash)
+		switch {
+		case isNoRows(err):
+			replaced = nil
+		case err != nil:
+			return nil, fmt.Errorf("failed to get replaced object: %w", err)
+		default:
+			if _, err := tx.ExecContext(ctx, `UPDATE objects SET deleted_at = ? WHERE id = ?`, now, replaced.ID); err != nil {
+				return nil, fmt.Errorf("failed to delete replaced object: %w", err)
+			}
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE objects SET is_latest = 0 WHERE bucket_id = ? AND normalized_key = ? AND is_latest = 1`,
+		obj.BucketID, key,
+	); err != nil {
+		return nil, fmt.Errorf("failed to mark as not latest: %w", err)
+	}
+
+	if err := r.create(ctx, tx, obj); err != nil {
+		return nil, err
+	}
+	return replaced, nil
 }
 
 // GetByID retrieves an object by ID.
 func (r *objectRepository) GetByID(ctx context.Context, id int64) (*domain.Object, error) {
 	query := `
 		SELECT id, bucket_id, key, version_id, is_latest, is_delete_marker, 
-			content_hash, size, content_type, etag, storage_class, metadata, created_at, deleted_at
+			content_hash, size, content_type, etag, storage_class, metadata, created_at, deleted_at,
+			sse_customer_algorithm, sse_customer_key_md5, checksum_algorithm, checksum,
+			cache_control, content_disposition, content_encoding, expires
 		FROM objects
 		WHERE id = ?
 	`
@@ -82,9 +225,11 @@ func (r *objectRepository) GetByID(ctx context.Context, id int64) (*domain.Objec
 func (r *objectRepository) GetByKey(ctx context.Context, bucketID int64, key string) (*domain.Object, error) {
 	query := `
 		SELECT id, bucket_id, key, version_id, is_latest, is_delete_marker, 
-			content_hash, size, content_type, etag, storage_class, metadata, created_at, deleted_at
+			content_hash, size, content_type, etag, storage_class, metadata, created_at, deleted_at,
+			sse_customer_algorithm, sse_customer_key_md5, checksum_algorithm, checksum,
+			cache_control, content_disposition, content_encoding, expires
 		FROM objects
-		WHERE bucket_id = ? AND key = ? AND is_latest = 1 AND deleted_at IS NULL
+		WHERE bucket_id = ? AND normalized_key = ? AND is_latest = 1 AND deleted_at IS NULL
 	`
 	return r.scanObject(r.db.QueryRowContext(ctx, query, bucketID, key))
 }
@@ -93,9 +238,11 @@ func (r *objectRepository) GetByKey(ctx context.Context, bucketID int64, key str
 func (r *objectRepository) GetByKeyAndVersion(ctx context.Context, bucketID int64, key string, versionID uuid.UUID) (*domain.Object, error) {
 	query := `
 		SELECT id, bucket_id, key, version_id, is_latest, is_delete_marker, 
-			content_hash, size, content_type, etag, storage_class, metadata, created_at, deleted_at
+			content_hash, size, content_type, etag, storage_class, metadata, created_at, deleted_at,
+			sse_customer_algorithm, sse_customer_key_md5, checksum_algorithm, checksum,
+			cache_control, content_disposition, content_encoding, expires
 		FROM objects
-		WHERE bucket_id = ? AND key = ? AND version_id = ?
+		WHERE bucket_id = ? AND normalized_key = ? AND version_id = ?
 	`
 	return r.scanObject(r.db.QueryRowContext(ctx, query, bucketID, key, versionID.String()))
 }
@@ -126,6 +273,14 @@ func (r *objectRepository) scanObject(row *sql.Row) (*domain.Object, error) {
 		&metadataJSON,
 		&createdAt,
 		&deletedAt,
+		&obj.SSECustomerAlgorithm,
+		&obj.SSECustomerKeyMD5,
+		&obj.ChecksumAlgorithm,
+		&obj.Checksum,
+		&obj.CacheControl,
+		&obj.ContentDisposition,
+		&obj.ContentEncoding,
+		&obj.Expires,
 	)
 
 	if err != nil {
@@ -167,13 +322,34 @@ func (r *objectRepository) List(ctx context.Context, bucketID int64, opts reposi
 		SELECT key, version_id, is_latest, size, etag, created_at, storage_class
 		FROM objects
 		WHERE bucket_id = ? AND is_latest = 1 AND deleted_at IS NULL
-			AND (? = '' OR key LIKE ? || '%')
-			AND (? = '' OR key > ?)
-		ORDER BY key ASC
+			AND (? = '' OR substr(normalized_key, 1, length(?)) = ?)
+			AND (? = '' OR normalized_key > ?)
+		ORDER BY normalized_key ASC
 		LIMIT ?
 	`
-
-	rows, err := r.db.QueryContext(ctx, query, bucketID, opts.Prefix, opts.Prefix, opts.StartAfter, opts.StartAfter, maxKeys+1)
+	args := []any{bucketID, opts.Prefix, opts.Prefix, opts.Prefix, opts.StartAfter, opts.StartAfter, maxKeys + 1}
+
+	if snapshot := opts.Snapshot; snapshot != nil {
+		// The newest version visible at the snapshot, unless a delete marker
+		query = `
+			SELECT key, version_id, 1, size, etag, created_at, storage_class
+			FROM objects o
+			WHERE bucket_id = ? AND id <= ? AND (deleted_at IS NULL OR deleted_at >= ?) AND is_delete_marker = 0
+				AND NOT EXISTS (
+					SELECT 1 FROM objects n
+					WHERE n.bucket_id = o.bucket_id AND n.normalized_key = o.normalized_key
+						AND n.id > o.id AND n.id <= ? AND (n.deleted_at IS NULL OR n.deleted_at >= ?)
+				)
+				AND (? = '' OR substr(normalized_key, 1, length(?)) = ?)
+				AND (? = '' OR normalized_key > ?)
+			ORDER BY normalized_key ASC
+			LIMIT ?
+		`
+		takenAt := snapshot.TakenAt.UTC().Format(time.RFC3339)
+		args = append([]any{bucketID, snapshot.MaxID, takenAt, snapshot.MaxID, takenAt}, args[1:]...)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list objects: %w", err)
 	}
@@ -198,7 +374,7 @@ func (r *objectRepository) List(ctx context.Context, bucketID int64, opts reposi
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan object: %w", err)
 		}
-		obj.VersionID = versionIDStr
+		obj.VersionID = formatVersionID(versionIDStr)
 		if etag.Valid {
 			obj.ETag = etag.String
 		}
@@ -210,9 +386,7 @@ func (r *objectRepository) List(ctx context.Context, bucketID int64, opts reposi
 		return nil, fmt.Errorf("error iterating objects: %w", err)
 	}
 
-	result := &repository.ObjectListResult{
-		KeyCount: len(objects),
-	}
+	result := &repository.ObjectListResult{}
 
 	if len(objects) > maxKeys {
 		result.IsTruncated = true
@@ -221,10 +395,22 @@ func (r *objectRepository) List(ctx context.Context, bucketID int64, opts reposi
 	} else {
 		result.Objects = objects
 	}
+	result.KeyCount = len(result.Objects)
 
 	return result, nil
 }
 
+// ListSnapshot returns the current point in time for snapshot listings.
+// Timestamps are stored with second precision, so the snapshot time is
+// truncated to the second: versions deleted in the same second are kept.
+func (r *objectRepository) ListSnapshot(ctx context.Context) (*repository.ListSnapshot, error) {
+	snapshot := &repository.ListSnapshot{TakenAt: time.Now().UTC().Truncate(time.Second)}
+	if err := r.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(id), 0) FROM objects`).Scan(&snapshot.MaxID); err != nil {
+		return nil, fmt.Errorf("failed to take list snapshot: %w", err)
+	}
+	return snapshot, nil
+}
+
 // ListVersions returns all versions of objects in a bucket.
 func (r *objectRepository) ListVersions(ctx context.Context, bucketID int64, opts repository.ObjectListOptions) (*repository.ObjectVersionListResult, error) {
 	maxKeys := opts.MaxKeys
@@ -232,24 +418,31 @@ func (r *objectRepository) ListVersions(ctx context.Context, bucketID int64, opt
 		maxKeys = 1000
 	}
 
+	// Versions and delete markers are paged together, newest first within a
+	// key. The id breaks ties between versions created in the same second.
 	query := `
-		SELECT key, version_id, is_latest, is_delete_marker, size, etag, created_at, storage_class
-		FROM objects
-		WHERE bucket_id = ? AND deleted_at IS NULL
-			AND (? = '' OR key LIKE ? || '%')
-			AND (? = '' OR key > ?)
-		ORDER BY key ASC, created_at DESC
+		SELECT o.key, o.version_id, o.is_latest, o.is_delete_marker, o.size, o.etag, o.created_at, o.storage_class
+		FROM objects o
+		WHERE o.bucket_id = ? AND o.deleted_at IS NULL
+			AND (? = '' OR substr(o.normalized_key, 1, length(?)) = ?)
+			AND (? = '' OR o.normalized_key > ? OR (o.normalized_key = ? AND EXISTS (
+				SELECT 1 FROM objects m
+				WHERE m.bucket_id = o.bucket_id AND m.normalized_key = o.normalized_key AND m.version_id = ?
+					AND (o.created_at < m.created_at OR (o.created_at = m.created_at AND o.id < m.id))
+			)))
+		ORDER BY o.normalized_key ASC, o.created_at DESC, o.id DESC
 		LIMIT ?
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, bucketID, opts.Prefix, opts.Prefix, opts.StartAfter, opts.StartAfter, maxKeys+1)
+	rows, err := r.db.QueryContext(ctx, query, bucketID, opts.Prefix, opts.Prefix, opts.Prefix,
+		opts.StartAfter, opts.StartAfter, opts.StartAfter, opts.VersionIDMarker, maxKeys+1)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list versions: %w", err)
 	}
 	defer rows.Close()
 
-	var versions []*domain.ObjectVersion
-	var deleteMarkers []*domain.ObjectVersion
+	result := &repository.ObjectVersionListResult{}
+	var last *domain.ObjectVersion
 
 	for rows.Next() {
 		ver := &domain.ObjectVersion{}
@@ -271,7 +464,7 @@ func (r *objectRepository) ListVersions(ctx context.Context, bucketID int64, opt
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan version: %w", err)
 		}
-		ver.VersionID = versionIDStr
+		ver.VersionID = formatVersionID(versionIDStr)
 		ver.IsLatest = isLatest != 0
 		ver.IsDeleteMarker = isDeleteMarker != 0
 		if etag.Valid {
@@ -279,25 +472,25 @@ func (r *objectRepository) ListVersions(ctx context.Context, bucketID int64, opt
 		}
 		ver.LastModified, _ = time.Parse(time.RFC3339, createdAt)
 
+		if len(result.Versions)+len(result.DeleteMarkers) == maxKeys {
+			result.IsTruncated = true
+			break
+		}
 		if ver.IsDeleteMarker {
-			deleteMarkers = append(deleteMarkers, ver)
+			result.DeleteMarkers = append(result.DeleteMarkers, ver)
 		} else {
-			versions = append(versions, ver)
+			result.Versions = append(result.Versions, ver)
 		}
+		last = ver
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating versions: %w", err)
 	}
 
-	result := &repository.ObjectVersionListResult{
-		Versions:      versions,
-		DeleteMarkers: deleteMarkers,
-	}
-
-	totalCount := len(versions) + len(deleteMarkers)
-	if totalCount > maxKeys {
-		result.IsTruncated = true
+	if result.IsTruncated {
+		result.NextKeyMarker = last.Key
+		result.NextVersionIDMarker = last.VersionID
 	}
 
 	return result, nil
@@ -343,7 +536,7 @@ func (r *objectRepository) MarkNotLatest(ctx context.Context, bucketID int64, ke
 	query := `
 		UPDATE objects
 		SET is_latest = 0
-		WHERE bucket_id = ? AND key = ? AND is_latest = 1
+		WHERE bucket_id = ? AND normalized_key = ? AND is_latest = 1
 	`
 
 	_, err := r.db.ExecContext(ctx, query, bucketID, key)
@@ -371,13 +564,136 @@ func (r *objectRepository) Delete(ctx context.Context, id int64) error {
 	return nil
 }
 
-// DeleteAllVersions deletes all versions of an object.
-func (r *objectRepository) DeleteAllVersions(ctx context.Context, bucketID int64, key string) error {
-	query := `UPDATE objects SET deleted_at = ? WHERE bucket_id = ? AND key = ?`
+// DeleteAllVersions soft-deletes up to limit live versions of an object.
+func (r *objectRepository) DeleteAllVersions(ctx context.Context, bucketID int64, key string, limit int) (int64, error) {
+	query := `
+		UPDATE objects SET deleted_at = ?
+		WHERE id IN (
+			SELECT id FROM objects
+			WHERE bucket_id = ? AND normalized_key = ? AND deleted_at IS NULL
+			ORDER BY id ASC
+			LIMIT ?
+		)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, time.Now().UTC().Format(time.RFC3339), bucketID, key, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete all versions: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected, nil
+}
+
+// ListAfterID returns the next keyset page of live object versions in a bucket.
+func (r *objectRepository) ListAfterID(ctx context.Context, bucketID int64, afterID int64, limit int) ([]*domain.Object, error) {
+	query := `
+		SELECT id, bucket_id, key, version_id, is_latest, is_delete_marker, 
+			content_hash, size, content_type, etag, storage_class, metadata, created_at, deleted_at,
+			sse_customer_algorithm, sse_customer_key_md5, checksum_algorithm, checksum,
+			cache_control, content_disposition, content_encoding, expires
+		FROM objects
+		WHERE bucket_id = ? AND id > ? AND deleted_at IS NULL
+		ORDER BY id ASC
+		LIMIT ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, bucketID, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list objects: %w", err)
+	}
+	defer rows.Close()
+
+	return scanObjects(rows)
+}
+
+// ListKeyVersions returns all live versions of an object, newest first.
+func (r *objectRepository) ListKeyVersions(ctx context.Context, bucketID int64, key string) ([]*domain.Object, error) {
+	query := `
+		SELECT id, bucket_id, key, version_id, is_latest, is_delete_marker, 
+			content_hash, size, content_type, etag, storage_class, metadata, created_at, deleted_at,
+			sse_customer_algorithm, sse_customer_key_md5, checksum_algorithm, checksum,
+			cache_control, content_disposition, content_encoding, expires
+		FROM objects
+		WHERE bucket_id = ? AND normalized_key = ? AND deleted_at IS NULL
+		ORDER BY created_at DESC, id DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, bucketID, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list object versions: %w", err)
+	}
+	defer rows.Close()
+
+	return scanObjects(rows)
+}
+
+// GetLatestDeleted retrieves the most recently soft-deleted version of an object.
+func (r *objectRepository) GetLatestDeleted(ctx context.Context, bucketID int64, key string) (*domain.Object, error) {
+	query := `
+		SELECT id, bucket_id, key, version_id, is_latest, is_delete_marker, 
+			content_hash, size, content_type, etag, storage_class, metadata, created_at, deleted_at,
+			sse_customer_algorithm, sse_customer_key_md5, checksum_algorithm, checksum,
+			cache_control, content_disposition, content_encoding, expires
+		FROM objects
+		WHERE bucket_id = ? AND normalized_key = ? AND deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC, id DESC
+		LIMIT 1
+	`
+	return r.scanObject(r.db.QueryRowContext(ctx, query, bucketID, key))
+}
+
+// ListSoftDeleted returns objects soft-deleted before the given time, oldest first.
+func (r *objectRepository) ListSoftDeleted(ctx context.Context, deletedBefore time.Time, limit int) ([]*domain.Object, error) {
+	query := `
+		SELECT id, bucket_id, key, version_id, is_latest, is_delete_marker, 
+			content_hash, size, content_type, etag, storage_class, metadata, created_at, deleted_at,
+			sse_customer_algorithm, sse_customer_key_md5, checksum_algorithm, checksum,
+			cache_control, content_disposition, content_encoding, expires
+		FROM objects
+		WHERE deleted_at IS NOT NULL AND deleted_at < ?
+		ORDER BY deleted_at ASC
+		LIMIT ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, deletedBefore.UTC().Format(time.RFC3339), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list soft-deleted objects: %w", err)
+	}
+	defer rows.Close()
+
+	return scanObjects(rows)
+}
+
+// Undelete clears the deletion time of a soft-deleted object.
+func (r *objectRepository) Undelete(ctx context.Context, id int64) error {
+	query := `UPDATE objects SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to undelete object: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return domain.ErrObjectNotFound
+	}
+
+	return nil
+}
+
+// Purge permanently removes a soft-deleted object.
+func (r *objectRepository) Purge(ctx context.Context, id int64) error {
+	query := `DELETE FROM objects WHERE id = ? AND deleted_at IS NOT NULL`
 
-	_, err := r.db.ExecContext(ctx, query, time.Now().UTC().Format(time.RFC3339), bucketID, key)
+	result, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
-		return fmt.Errorf("failed to delete all versions: %w", err)
+		return fmt.Errorf("failed to purge object: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return domain.ErrObjectNotFound
 	}
 
 	return nil
@@ -400,7 +716,7 @@ func (r *objectRepository) CountByBucket(ctx context.Context, bucketID int64) (i
 func (r *objectRepository) GetContentHashForVersion(ctx context.Context, bucketID int64, key string, versionID uuid.UUID) (*string, error) {
 	var contentHash sql.NullString
 	err := r.db.QueryRowContext(ctx,
-		`SELECT content_hash FROM objects WHERE bucket_id = ? AND key = ? AND version_id = ?`,
+		`SELECT content_hash FROM objects WHERE bucket_id = ? AND normalized_key = ? AND version_id = ?`,
 		bucketID, key, versionID.String(),
 	).Scan(&contentHash)
 	if err != nil {
@@ -415,12 +731,112 @@ func (r *objectRepository) GetContentHashForVersion(ctx context.Context, bucketI
 	return nil, nil
 }
 
+// GetTags returns the tag set of an object version.
+func (r *objectRepository) GetTags(ctx context.Context, objectID int64) (map[string]string, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT tag_key, tag_value FROM object_tags WHERE object_id = ?`, objectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan object tag: %w", err)
+		}
+		tags[key] = value
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate object tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+// PutTags replaces the tag set of an object version.
+func (r *objectRepository) PutTags(ctx context.Context, objectID int64, tags map[string]string) error {
+	return r.db.WithTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM object_tags WHERE object_id = ?`, objectID); err != nil {
+			return fmt.Errorf("failed to clear object tags: %w", err)
+		}
+		return insertTags(ctx, tx, objectID, tags)
+	})
+}
+
+// insertTags adds tags to an object through q.
+func insertTags(ctx context.Context, q querier, objectID int64, tags map[string]string) error {
+	for key, value := range tags {
+		if _, err := q.ExecContext(ctx,
+			`INSERT INTO object_tags (object_id, tag_key, tag_value) VALUES (?, ?, ?)`,
+			objectID, key, value,
+		); err != nil {
+			return fmt.Errorf("failed to insert object tag: %w", err)
+		}
+	}
+	return nil
+}
+
+// GetLock returns the retention and legal hold of an object version.
+func (r *objectRepository) GetLock(ctx context.Context, objectID int64) (*domain.ObjectLock, error) {
+	lock := &domain.ObjectLock{}
+	var mode string
+	var retainUntil sql.NullString
+	var legalHold int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT mode, retain_until, legal_hold FROM object_locks WHERE object_id = ?`, objectID,
+	).Scan(&mode, &retainUntil, &legalHold)
+	if err != nil {
+		if isNoRows(err) {
+			return lock, nil
+		}
+		return nil, fmt.Errorf("failed to get object lock: %w", err)
+	}
+
+	lock.Mode = domain.ObjectLockMode(mode)
+	lock.LegalHold = legalHold != 0
+	if retainUntil.Valid {
+		t, _ := time.Parse(time.RFC3339, retainUntil.String)
+		lock.RetainUntil = &t
+	}
+	return lock, nil
+}
+
+// PutLock replaces the retention and legal hold of an object version.
+func (r *objectRepository) PutLock(ctx context.Context, objectID int64, lock *domain.ObjectLock) error {
+	return putLock(ctx, r.db, objectID, lock)
+}
+
+// putLock replaces the lock of an object version through q.
+func putLock(ctx context.Context, q querier, objectID int64, lock *domain.ObjectLock) error {
+	query := `
+		INSERT INTO object_locks (object_id, mode, retain_until, legal_hold)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (object_id) DO UPDATE SET
+			mode = excluded.mode,
+			retain_until = excluded.retain_until,
+			legal_hold = excluded.legal_hold
+	`
+
+	var retainUntil sql.NullString
+	if lock.RetainUntil != nil {
+		retainUntil = sql.NullString{String: lock.RetainUntil.UTC().Format(time.RFC3339), Valid: true}
+	}
+
+	if _, err := q.ExecContext(ctx, query, objectID, string(lock.Mode), retainUntil, boolToInt(lock.LegalHold)); err != nil {
+		return fmt.Errorf("failed to put object lock: %w", err)
+	}
+	return nil
+}
+
 // ListExpiredObjects returns latest objects older than cutoff, with optional prefix.
 // Used by lifecycle service for expiration processing.
 func (r *objectRepository) ListExpiredObjects(ctx context.Context, bucketID int64, prefix string, olderThan time.Time, limit int) ([]*domain.Object, error) {
 	query := `
 		SELECT id, bucket_id, key, version_id, is_latest, is_delete_marker, 
-			content_hash, size, content_type, etag, storage_class, metadata, created_at, deleted_at
+			content_hash, size, content_type, etag, storage_class, metadata, created_at, deleted_at,
+			sse_customer_algorithm, sse_customer_key_md5, checksum_algorithm, checksum,
+			cache_control, content_disposition, content_encoding, expires
 		FROM objects
 		WHERE bucket_id = ? 
 			AND is_latest = 1 
@@ -438,6 +854,37 @@ func (r *objectRepository) ListExpiredObjects(ctx context.Context, bucketID int6
 	}
 	defer rows.Close()
 
+	return scanObjects(rows)
+}
+
+// ListPastExpiry returns latest objects whose own expiration time has passed.
+func (r *objectRepository) ListPastExpiry(ctx context.Context, now time.Time, limit int) ([]*domain.Object, error) {
+	query := `
+		SELECT id, bucket_id, key, version_id, is_latest, is_delete_marker, 
+			content_hash, size, content_type, etag, storage_class, metadata, created_at, deleted_at,
+			sse_customer_algorithm, sse_customer_key_md5, checksum_algorithm, checksum,
+			cache_control, content_disposition, content_encoding, expires
+		FROM objects
+		WHERE expires_at IS NOT NULL
+			AND expires_at <= ?
+			AND is_latest = 1
+			AND is_delete_marker = 0
+			AND deleted_at IS NULL
+		ORDER BY expires_at ASC
+		LIMIT ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, now.UTC().Format(time.RFC3339), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list objects past expiry: %w", err)
+	}
+	defer rows.Close()
+
+	return scanObjects(rows)
+}
+
+// scanObjects scans all rows of an object query.
+func scanObjects(rows *sql.Rows) ([]*domain.Object, error) {
 	var objects []*domain.Object
 	for rows.Next() {
 		obj := &domain.Object{}
@@ -462,6 +909,14 @@ func (r *objectRepository) ListExpiredObjects(ctx context.Context, bucketID int6
 			&metadataJSON,
 			&createdAt,
 			&deletedAt,
+			&obj.SSECustomerAlgorithm,
+			&obj.SSECustomerKeyMD5,
+			&obj.ChecksumAlgorithm,
+			&obj.Checksum,
+			&obj.CacheControl,
+			&obj.ContentDisposition,
+			&obj.ContentEncoding,
+			&obj.Expires,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan object: %w", err)
@@ -503,3 +958,14 @@ func (r *objectRepository) ListExpiredObjects(ctx context.Context, bucketID int6
 
 // Ensure objectRepository implements repository.ObjectRepository.
 var _ repository.ObjectRepository = (*objectRepository)(nil)
+var _ repository.ObjectBatchWriter = (*objectRepository)(nil)
+
+// formatVersionID converts a stored version ID to its S3 representation,
+// reporting the null version as "null".
+func formatVersionID(s string) string {
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return s
+	}
+	return domain.FormatVersionID(id)
+}
@@ -0,0 +1,179 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/repository"
+)
+
+// setupObjectRepo returns an object repository and a bucket holding n objects.
+func setupObjectRepo(t *testing.T, n int) (repository.ObjectRepository, int64) {
+	t.Helper()
+	ctx := context.Background()
+
+	db, err := NewDB(ctx, DefaultConfig(filepath.Join(t.TempDir(), "alexander.db")), zerolog.Nop())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, db.Migrate(ctx))
+
+	user := domain.NewUser("tester", "tester@example.com", "hash")
+	require.NoError(t, NewUserRepository(db).Create(ctx, user))
+	bucket := domain.NewBucket(user.ID, "iterate")
+	require.NoError(t, NewBucketRepository(db).Create(ctx, bucket))
+
+	repo := NewObjectRepository(db)
+	for i := 0; i < n; i++ {
+		key := fmt.Sprintf("obj-%05d", i)
+		obj := domain.NewObject(bucket.ID, key, "hash", "text/plain", `"etag"`, 1)
+		obj.NormalizedKey = key
+		require.NoError(t, repo.Create(ctx, obj))
+	}
+
+	return repo, bucket.ID
+}
+
+func TestObjectIterator_CancelAndResume(t *testing.T) {
+	const total = 5000
+	repo, bucketID := setupObjectRepo(t, total)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	seen := make(map[int64]bool)
+	it := repository.NewObjectIterator(repo, bucketID, 0, 300)
+	for it.Next(ctx) {
+		assert.LessOrEqual(t, len(it.Batch()), 300)
+		for _, obj := range it.Batch() {
+			seen[obj.ID] = true
+		}
+		if len(seen) >= total/2 {
+			cancel()
+		}
+	}
+	require.ErrorIs(t, it.Err(), context.Canceled)
+	assert.Nil(t, it.Batch())
+	assert.False(t, it.Next(context.Background()), "a stopped iterator stays stopped")
+
+	// Everything up to the cursor was handed out, nothing after it
+	stoppedAt := len(seen)
+	assert.Less(t, stoppedAt, total)
+	for id := range seen {
+		assert.LessOrEqual(t, id, it.Cursor())
+	}
+
+	// Resuming from the cursor visits exactly the remaining objects
+	resumed := repository.NewObjectIterator(repo, bucketID, it.Cursor(), 0)
+	for resumed.Next(context.Background()) {
+		for _, obj := range resumed.Batch() {
+			assert.False(t, seen[obj.ID], "object %d visited twice", obj.ID)
+			seen[obj.ID] = true
+		}
+	}
+	require.NoError(t, resumed.Err())
+	assert.Len(t, seen, total)
+}
+
+func TestForEachObject(t *testing.T) {
+	repo, bucketID := setupObjectRepo(t, 25)
+
+	var keys []string
+	err := repository.ForEachObject(context.Background(), repo, bucketID, 10, func(obj *domain.Object) error {
+		keys = append(keys, obj.Key)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, keys, 25)
+	assert.Equal(t, "obj-00000", keys[0])
+	assert.Equal(t, "obj-00024", keys[24])
+
+	// Cancellation stops between objects, not only between batches
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	visited := 0
+	err = repository.ForEachObject(ctx, repo, bucketID, 10, func(obj *domain.Object) error {
+		visited++
+		if visited == 5 {
+			cancel()
+		}
+		return nil
+	})
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 5, visited)
+}
+
+func TestObjectRepository_CreateBatch(t *testing.T) {
+	ctx := context.Background()
+	repo, bucketID := setupObjectRepo(t, 0)
+	writer := repo.(repository.ObjectBatchWriter)
+	blobs := NewBlobRepository(repo.(*objectRepository).db)
+
+	newWrite := func(bucketID int64, key, hash string) *repository.ObjectWrite {
+		obj := domain.NewObject(bucketID, key, hash, "text/plain", `"etag"`, 1)
+		obj.NormalizedKey = key
+		obj.VersionID = uuid.Nil
+		return &repository.ObjectWrite{Object: obj, StoredSize: 1, StoragePath: hash, Replace: repository.ReplaceLatest}
+	}
+	hash1, hash2 := strings.Repeat("1", 64), strings.Repeat("2", 64)
+	writes := []*repository.ObjectWrite{
+		newWrite(bucketID, "a", hash1),
+		newWrite(bucketID, "a", hash2),
+		newWrite(bucketID, "a", hash1),
+	}
+	// Rejected by the objects table after replacing the first write
+	writes[1].Object.StorageClass = "BOGUS"
+	require.NoError(t, writer.CreateBatch(ctx, writes))
+
+	// The failing write is rolled back alone, blob reference and replaced
+	// version included
+	require.NoError(t, writes[0].Err)
+	require.Error(t, writes[1].Err)
+	require.NoError(t, writes[2].Err)
+	exists, err := blobs.Exists(ctx, hash2)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	// The last write of "a" replaced the first
+	require.Nil(t, writes[0].Replaced)
+	require.NotNil(t, writes[2].Replaced)
+	assert.Equal(t, writes[0].Object.ID, writes[2].Replaced.ID)
+	latest, err := repo.GetByKey(ctx, bucketID, "a")
+	require.NoError(t, err)
+	assert.Equal(t, writes[2].Object.ID, latest.ID)
+
+	blob, err := blobs.GetByHash(ctx, hash1)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), blob.RefCount)
+}
+
+func TestObjectRepository_CreateRefusedForDeletingBucket(t *testing.T) {
+	ctx := context.Background()
+	repo, bucketID := setupObjectRepo(t, 0)
+	db := repo.(*objectRepository).db
+	require.NoError(t, NewBucketRepository(db).UpdateState(ctx, bucketID, domain.BucketStateDeleting))
+
+	obj := domain.NewObject(bucketID, "late", "hash", "text/plain", `"etag"`, 1)
+	obj.NormalizedKey = "late"
+	assert.ErrorIs(t, repo.Create(ctx, obj), domain.ErrBucketDeleting)
+
+	hash := strings.Repeat("3", 64)
+	write := &repository.ObjectWrite{Object: domain.NewObject(bucketID, "late", hash, "text/plain", `"etag"`, 1), StoredSize: 1, StoragePath: hash}
+	require.NoError(t, repo.(repository.ObjectBatchWriter).CreateBatch(ctx, []*repository.ObjectWrite{write}))
+	assert.ErrorIs(t, write.Err, domain.ErrBucketDeleting)
+
+	empty, err := NewBucketRepository(db).IsEmpty(ctx, bucketID)
+	require.NoError(t, err)
+	assert.True(t, empty)
+
+	obj.BucketID = bucketID + 1
+	assert.ErrorIs(t, repo.Create(ctx, obj), domain.ErrBucketNotFound)
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	// accessRecordTimeout bounds how long a read waits for the access tracker.
+	accessRecordTimeout = 100 * time.Millisecond
+
+	// accessBreakerThreshold is the number of consecutive failures that opens the breaker.
+	accessBreakerThreshold = 5
+
+	// accessBreakerCooldown is how long the breaker stays open before retrying the tracker.
+	accessBreakerCooldown = 30 * time.Second
+)
+
+// AccessRecorder records blob reads for tiering decisions.
+// tiering.AccessTracker implementations satisfy it.
+type AccessRecorder interface {
+	RecordAccess(ctx context.Context, contentHash string) error
+}
+
+// accessBreaker calls an AccessRecorder on a best-effort basis. After
+// accessBreakerThreshold consecutive failures it stops calling the recorder
+// for accessBreakerCooldown, so an unavailable tracker does not add latency
+// to every read.
+type accessBreaker struct {
+	recorder AccessRecorder
+	logger   zerolog.Logger
+	now      func() time.Time
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+func newAccessBreaker(recorder AccessRecorder, logger zerolog.Logger) *accessBreaker {
+	return &accessBreaker{
+		recorder: recorder,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// record records an access unless the breaker is open. Errors are logged, never returned.
+func (b *accessBreaker) record(ctx context.Context, contentHash string) {
+	b.mu.Lock()
+	if b.now().Before(b.openUntil) {
+		b.mu.Unlock()
+		return
+	}
+	b.mu.Unlock()
+
+	// The read must not be slowed down or cancelled by the tracker
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), accessRecordTimeout)
+	defer cancel()
+
+	err := b.recorder.RecordAccess(ctx, contentHash)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err == nil {
+		if b.failures >= accessBreakerThreshold {
+			b.logger.Info().Msg("access tracker recovered")
+		}
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.failures < accessBreakerThreshold {
+		b.logger.Warn().Err(err).Str("content_hash", contentHash).Msg("failed to record access")
+		return
+	}
+
+	b.openUntil = b.now().Add(accessBreakerCooldown)
+	b.logger.Error().
+		Err(err).
+		Int("consecutive_failures", b.failures).
+		Dur("cooldown", accessBreakerCooldown).
+		Msg("access tracker unavailable, pausing access recording")
+}
+
+// SetAccessRecorder enables access recording on reads. Recording is
+// best-effort: tracker failures never fail or noticeably slow down a read.
+func (s *ObjectService) SetAccessRecorder(recorder AccessRecorder) {
+	if recorder == nil {
+		s.access = nil
+		return
+	}
+	s.access = newAccessBreaker(recorder, s.logger)
+}
+
+// recordAccess records a read of a blob if access recording is enabled.
+func (s *ObjectService) recordAccess(ctx context.Context, contentHash string) {
+	if s.access != nil {
+		s.access.record(ctx, contentHash)
+	}
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+)
+
+// failingRecorder fails every access and counts the attempts.
+type failingRecorder struct {
+	calls atomic.Int32
+}
+
+func (r *failingRecorder) RecordAccess(ctx context.Context, contentHash string) error {
+	r.calls.Add(1)
+	return errors.New("connection refused")
+}
+
+func TestObjectService_GetObject_AccessTrackerDown(t *testing.T) {
+	svc, objRepo, _, bucketRepo, storageBackend := newTestObjectService()
+
+	contentHash := "abc123hash"
+	bucketRepo.On("GetByName", mock.Anything, "test-bucket").Return(&domain.Bucket{ID: 1, Name: "test-bucket", OwnerID: 1}, nil)
+	objRepo.On("GetByKey", mock.Anything, int64(1), "test-key.txt").Return(&domain.Object{
+		ID:          1,
+		BucketID:    1,
+		Key:         "test-key.txt",
+		Size:        11,
+		ContentHash: &contentHash,
+		IsLatest:    true,
+	}, nil)
+	storageBackend.On("Retrieve", mock.Anything, contentHash).Return(io.NopCloser(bytes.NewReader(nil)), nil)
+
+	recorder := &failingRecorder{}
+	svc.SetAccessRecorder(recorder)
+	now := time.Now()
+	svc.access.now = func() time.Time { return now }
+
+	get := func() {
+		t.Helper()
+		output, err := svc.GetObject(context.Background(), GetObjectInput{BucketName: "test-bucket", Key: "test-key.txt", OwnerID: 1})
+		require.NoError(t, err)
+		require.Equal(t, int64(11), output.ContentLength)
+		require.NoError(t, output.Body.Close())
+	}
+
+	// Reads succeed while the tracker fails, until the breaker opens
+	for i := 0; i < accessBreakerThreshold+3; i++ {
+		get()
+	}
+	require.Equal(t, int32(accessBreakerThreshold), recorder.calls.Load())
+
+	// After the cooldown the tracker is tried again
+	now = now.Add(accessBreakerCooldown)
+	get()
+	require.Equal(t, int32(accessBreakerThreshold+1), recorder.calls.Load())
+}
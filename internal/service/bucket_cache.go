@@ -0,0 +1,207 @@
+// Package service provides business logic services for Alexander Storage.
+package service
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/repository"
+)
+
+// DefaultBucketCacheTTL is the default lifetime of a cached bucket entry.
+const DefaultBucketCacheTTL = 30 * time.Second
+
+// CachedBucketRepository wraps a BucketRepository with a small in-process TTL cache
+// for GetByName lookups. Write paths (PutObject, DeleteObject, multipart) resolve the
+// bucket on every request to read its owner and versioning state; caching those
+// lookups avoids a database round-trip per write on busy buckets.
+//
+// Every mutating method invalidates the affected entry before returning, so a
+// versioning change made through the same instance is visible immediately.
+// Share a single instance between services for invalidation to be effective.
+// A lookup racing with a change does not cache the row it read before the
+// change: invalidations bump a generation and fills from an older one are
+// dropped.
+type CachedBucketRepository struct {
+	repository.BucketRepository
+
+	ttl     time.Duration
+	mu      sync.RWMutex
+	entries map[string]bucketCacheEntry
+
+	// gens counts the invalidations of each name, and idGen those by ID,
+	// which may target a bucket whose name is not cached yet
+	gens  map[string]uint64
+	idGen uint64
+}
+
+// bucketCacheEntry is a cached bucket snapshot.
+type bucketCacheEntry struct {
+	bucket    domain.Bucket
+	expiresAt time.Time
+}
+
+// NewCachedBucketRepository creates a caching decorator around repo.
+// If ttl is <= 0, DefaultBucketCacheTTL is used.
+func NewCachedBucketRepository(repo repository.BucketRepository, ttl time.Duration) *CachedBucketRepository {
+	if ttl <= 0 {
+		ttl = DefaultBucketCacheTTL
+	}
+	return &CachedBucketRepository{
+		BucketRepository: repo,
+		ttl:              ttl,
+		entries:          make(map[string]bucketCacheEntry),
+		gens:             make(map[string]uint64),
+	}
+}
+
+// GetByName returns the bucket from cache if present, otherwise loads it from the
+// underlying repository. Callers receive a copy and may modify it freely.
+func (r *CachedBucketRepository) GetByName(ctx context.Context, name string) (*domain.Bucket, error) {
+	r.mu.RLock()
+	entry, ok := r.entries[name]
+	gen, idGen := r.gens[name], r.idGen
+	r.mu.RUnlock()
+
+	if ok && time.Now().Before(entry.expiresAt) {
+		return copyBucket(&entry.bucket), nil
+	}
+
+	bucket, err := r.BucketRepository.GetByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only cache the row if no change was committed while it was read
+	r.mu.Lock()
+	if r.gens[name] == gen && r.idGen == idGen {
+		r.entries[name] = bucketCacheEntry{
+			bucket:    *copyBucket(bucket),
+			expiresAt: time.Now().Add(r.ttl),
+		}
+	}
+	r.mu.Unlock()
+
+	return copyBucket(bucket), nil
+}
+
+// copyBucket returns a copy of bucket that shares no memory with it.
+func copyBucket(bucket *domain.Bucket) *domain.Bucket {
+	result := *bucket
+	if policy := bucket.ContentTypePolicy; policy != nil {
+		result.ContentTypePolicy = &domain.ContentTypePolicy{
+			Allow: slices.Clone(policy.Allow),
+			Deny:  slices.Clone(policy.Deny),
+			Serve: policy.Serve,
+		}
+	}
+	if retention := bucket.DefaultRetention; retention != nil {
+		copied := *retention
+		result.DefaultRetention = &copied
+	}
+	return &result
+}
+
+// Update updates a bucket and invalidates its cache entry.
+func (r *CachedBucketRepository) Update(ctx context.Context, bucket *domain.Bucket) error {
+	defer r.Invalidate(bucket.Name)
+	return r.BucketRepository.Update(ctx, bucket)
+}
+
+// UpdateVersioning updates the versioning status and invalidates the cache entry.
+func (r *CachedBucketRepository) UpdateVersioning(ctx context.Context, id int64, status domain.VersioningStatus) error {
+	defer r.invalidateByID(id)
+	return r.BucketRepository.UpdateVersioning(ctx, id, status)
+}
+
+// UpdateACL updates the ACL and invalidates the cache entry.
+func (r *CachedBucketRepository) UpdateACL(ctx context.Context, id int64, acl domain.BucketACL) error {
+	defer r.invalidateByID(id)
+	return r.BucketRepository.UpdateACL(ctx, id, acl)
+}
+
+// UpdateState updates the lifecycle state and invalidates the cache entry.
+func (r *CachedBucketRepository) UpdateState(ctx context.Context, id int64, state domain.BucketState) error {
+	defer r.invalidateByID(id)
+	return r.BucketRepository.UpdateState(ctx, id, state)
+}
+
+// UpdateObjectOwnership updates the object ownership and invalidates the cache entry.
+func (r *CachedBucketRepository) UpdateObjectOwnership(ctx context.Context, id int64, ownership domain.ObjectOwnership) error {
+	defer r.invalidateByID(id)
+	return r.BucketRepository.UpdateObjectOwnership(ctx, id, ownership)
+}
+
+// UpdateMaxVersionsPerKey updates the per-key version limit and invalidates the cache entry.
+func (r *CachedBucketRepository) UpdateMaxVersionsPerKey(ctx context.Context, id int64, maxVersions int) error {
+	defer r.invalidateByID(id)
+	return r.BucketRepository.UpdateMaxVersionsPerKey(ctx, id, maxVersions)
+}
+
+// UpdateCaseInsensitiveKeys updates key case sensitivity and invalidates the cache entry.
+func (r *CachedBucketRepository) UpdateCaseInsensitiveKeys(ctx context.Context, id int64, enabled bool) error {
+	defer r.invalidateByID(id)
+	return r.BucketRepository.UpdateCaseInsensitiveKeys(ctx, id, enabled)
+}
+
+// UpdateContentTypePolicy updates the content-type policy and invalidates the cache entry.
+func (r *CachedBucketRepository) UpdateContentTypePolicy(ctx context.Context, id int64, policy *domain.ContentTypePolicy) error {
+	defer r.invalidateByID(id)
+	return r.BucketRepository.UpdateContentTypePolicy(ctx, id, policy)
+}
+
+// UpdateRequireEncryption updates the encryption requirement and invalidates the cache entry.
+func (r *CachedBucketRepository) UpdateRequireEncryption(ctx context.Context, id int64, required bool) error {
+	defer r.invalidateByID(id)
+	return r.BucketRepository.UpdateRequireEncryption(ctx, id, required)
+}
+
+// EnableObjectLock enables object lock and invalidates the cache entry.
+func (r *CachedBucketRepository) EnableObjectLock(ctx context.Context, id int64) error {
+	defer r.invalidateByID(id)
+	return r.BucketRepository.EnableObjectLock(ctx, id)
+}
+
+// UpdateDefaultRetention updates the default retention and invalidates the cache entry.
+func (r *CachedBucketRepository) UpdateDefaultRetention(ctx context.Context, id int64, retention *domain.DefaultRetention) error {
+	defer r.invalidateByID(id)
+	return r.BucketRepository.UpdateDefaultRetention(ctx, id, retention)
+}
+
+// Delete deletes a bucket and invalidates its cache entry.
+func (r *CachedBucketRepository) Delete(ctx context.Context, id int64) error {
+	defer r.invalidateByID(id)
+	return r.BucketRepository.Delete(ctx, id)
+}
+
+// DeleteByName deletes a bucket and invalidates its cache entry.
+func (r *CachedBucketRepository) DeleteByName(ctx context.Context, name string) error {
+	defer r.Invalidate(name)
+	return r.BucketRepository.DeleteByName(ctx, name)
+}
+
+// Invalidate removes a bucket from the cache.
+func (r *CachedBucketRepository) Invalidate(name string) {
+	r.mu.Lock()
+	delete(r.entries, name)
+	r.gens[name]++
+	r.mu.Unlock()
+}
+
+// invalidateByID removes any cached entry for the bucket with the given ID.
+func (r *CachedBucketRepository) invalidateByID(id int64) {
+	r.mu.Lock()
+	r.idGen++
+	for name, entry := range r.entries {
+		if entry.bucket.ID == id {
+			delete(r.entries, name)
+		}
+	}
+	r.mu.Unlock()
+}
+
+// Ensure CachedBucketRepository implements repository.BucketRepository.
+var _ repository.BucketRepository = (*CachedBucketRepository)(nil)
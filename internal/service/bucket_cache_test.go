@@ -0,0 +1,163 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/lock"
+	"github.com/prn-tf/alexander-storage/internal/repository"
+)
+
+func TestCachedBucketRepository_RepeatedWritesHitDBOnce(t *testing.T) {
+	objectRepo := new(mockObjectRepository)
+	blobRepo := new(mockBlobRepository2)
+	bucketRepo := new(mockBucketRepository)
+	storageBackend := new(mockStorageBackend2)
+
+	cached := NewCachedBucketRepository(bucketRepo, time.Minute)
+	svc := NewObjectService(objectRepo, blobRepo, cached, storageBackend, lock.NewNoOpLocker(), zerolog.Nop())
+
+	bucket := &domain.Bucket{ID: 1, Name: "test-bucket", OwnerID: 1, Versioning: domain.VersioningEnabled}
+	bucketRepo.On("GetByName", mock.Anything, "test-bucket").Return(bucket, nil).Once()
+
+	storageBackend.On("Store", mock.Anything, mock.Anything, int64(5)).Return("abc123hash", nil)
+	storageBackend.On("GetPath", "abc123hash").Return("/data/ab/c1/abc123hash")
+	blobRepo.On("UpsertWithRefIncrement", mock.Anything, "abc123hash", int64(5), "/data/ab/c1/abc123hash").Return(false, nil)
+	objectRepo.On("MarkNotLatest", mock.Anything, int64(1), mock.Anything).Return(nil)
+	objectRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Object")).Return(nil)
+
+	for i := 0; i < 5; i++ {
+		_, err := svc.PutObject(context.Background(), PutObjectInput{
+			BucketName: "test-bucket",
+			Key:        "key.txt",
+			Body:       bytes.NewReader([]byte("hello")),
+			Size:       5,
+			OwnerID:    1,
+		})
+		require.NoError(t, err)
+	}
+
+	bucketRepo.AssertNumberOfCalls(t, "GetByName", 1)
+}
+
+func TestCachedBucketRepository_VersioningChangeVisibleImmediately(t *testing.T) {
+	repo := NewMockBucketRepository()
+	cached := NewCachedBucketRepository(repo, time.Hour)
+	bucketSvc := NewBucketService(cached, zerolog.Nop())
+	ctx := context.Background()
+
+	_, err := bucketSvc.CreateBucket(ctx, CreateBucketInput{OwnerID: 1, Name: "versioned-bucket"})
+	require.NoError(t, err)
+
+	bucket, err := cached.GetByName(ctx, "versioned-bucket")
+	require.NoError(t, err)
+	require.Equal(t, domain.VersioningDisabled, bucket.Versioning)
+
+	err = bucketSvc.PutBucketVersioning(ctx, PutBucketVersioningInput{
+		Name:    "versioned-bucket",
+		OwnerID: 1,
+		Status:  domain.VersioningEnabled,
+	})
+	require.NoError(t, err)
+
+	bucket, err = cached.GetByName(ctx, "versioned-bucket")
+	require.NoError(t, err)
+	require.Equal(t, domain.VersioningEnabled, bucket.Versioning)
+}
+
+func TestCachedBucketRepository_DeleteInvalidates(t *testing.T) {
+	repo := NewMockBucketRepository()
+	cached := NewCachedBucketRepository(repo, time.Hour)
+	bucketSvc := NewBucketService(cached, zerolog.Nop())
+	ctx := context.Background()
+
+	_, err := bucketSvc.CreateBucket(ctx, CreateBucketInput{OwnerID: 1, Name: "doomed-bucket"})
+	require.NoError(t, err)
+
+	_, err = cached.GetByName(ctx, "doomed-bucket")
+	require.NoError(t, err)
+
+	err = bucketSvc.DeleteBucket(ctx, DeleteBucketInput{Name: "doomed-bucket", OwnerID: 1})
+	require.NoError(t, err)
+
+	_, err = cached.GetByName(ctx, "doomed-bucket")
+	require.ErrorIs(t, err, domain.ErrBucketNotFound)
+}
+
+func TestCachedBucketRepository_ReturnsCopies(t *testing.T) {
+	repo := NewMockBucketRepository()
+	require.NoError(t, repo.Create(context.Background(), &domain.Bucket{
+		Name:              "copy-bucket",
+		OwnerID:           1,
+		ContentTypePolicy: &domain.ContentTypePolicy{Allow: []string{"image/png"}},
+		DefaultRetention:  &domain.DefaultRetention{Mode: domain.ObjectLockModeGovernance, Days: 1},
+	}))
+
+	var cached repository.BucketRepository = NewCachedBucketRepository(repo, time.Hour)
+
+	first, err := cached.GetByName(context.Background(), "copy-bucket")
+	require.NoError(t, err)
+	first.OwnerID = 99
+	first.ContentTypePolicy.Allow[0] = "text/html"
+	first.DefaultRetention.Days = 365
+
+	second, err := cached.GetByName(context.Background(), "copy-bucket")
+	require.NoError(t, err)
+	require.Equal(t, int64(1), second.OwnerID)
+	require.Equal(t, []string{"image/png"}, second.ContentTypePolicy.Allow)
+	require.Equal(t, 1, second.DefaultRetention.Days)
+}
+
+// pausingBucketRepository snapshots a bucket like a database read and then
+// waits for resume before returning it.
+type pausingBucketRepository struct {
+	repository.BucketRepository
+	read   chan struct{}
+	resume chan struct{}
+}
+
+func (r *pausingBucketRepository) GetByName(ctx context.Context, name string) (*domain.Bucket, error) {
+	bucket, err := r.BucketRepository.GetByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := *bucket
+	r.read <- struct{}{}
+	<-r.resume
+	return &snapshot, nil
+}
+
+func TestCachedBucketRepository_DropsFillRacingWithUpdate(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMockBucketRepository()
+	require.NoError(t, repo.Create(ctx, &domain.Bucket{Name: "racy-bucket", OwnerID: 1, Versioning: domain.VersioningDisabled}))
+	bucketID := repo.buckets["racy-bucket"].ID
+
+	paused := &pausingBucketRepository{BucketRepository: repo, read: make(chan struct{}), resume: make(chan struct{})}
+	cached := NewCachedBucketRepository(paused, time.Hour)
+
+	// A lookup reads the row, then versioning is enabled before it caches it
+	done := make(chan *domain.Bucket)
+	go func() {
+		bucket, err := cached.GetByName(ctx, "racy-bucket")
+		require.NoError(t, err)
+		done <- bucket
+	}()
+	<-paused.read
+	require.NoError(t, cached.UpdateVersioning(ctx, bucketID, domain.VersioningEnabled))
+	close(paused.resume)
+	require.Equal(t, domain.VersioningDisabled, (<-done).Versioning)
+
+	// The stale row was not cached, so the next lookup reads the change
+	go func() { <-paused.read }()
+	bucket, err := cached.GetByName(ctx, "racy-bucket")
+	require.NoError(t, err)
+	require.Equal(t, domain.VersioningEnabled, bucket.Versioning)
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog"
+
+	"github.com/prn-tf/alexander-storage/internal/auth"
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/repository"
+)
+
+// BucketPolicyService handles bucket policies.
+type BucketPolicyService struct {
+	policyRepo repository.BucketPolicyRepository
+	bucketRepo repository.BucketRepository
+	logger     zerolog.Logger
+}
+
+// NewBucketPolicyService creates a new BucketPolicyService.
+func NewBucketPolicyService(
+	policyRepo repository.BucketPolicyRepository,
+	bucketRepo repository.BucketRepository,
+	logger zerolog.Logger,
+) *BucketPolicyService {
+	return &BucketPolicyService{
+		policyRepo: policyRepo,
+		bucketRepo: bucketRepo,
+		logger:     logger.With().Str("service", "bucket_policy").Logger(),
+	}
+}
+
+// PutBucketPolicyInput contains the data needed to set a bucket policy.
+type PutBucketPolicyInput struct {
+	BucketName string
+	OwnerID    int64
+
+	// Policy is the JSON policy document.
+	Policy []byte
+}
+
+// PutBucketPolicy validates and stores the policy of a bucket, replacing any
+// existing one. Invalid documents are rejected with auth.ErrMalformedPolicy.
+func (s *BucketPolicyService) PutBucketPolicy(ctx context.Context, input PutBucketPolicyInput) error {
+	bucket, err := s.ownedBucket(ctx, input.BucketName, input.OwnerID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := auth.ParsePolicy(bucket.Name, input.Policy); err != nil {
+		return err
+	}
+
+	if err := s.policyRepo.Put(ctx, domain.NewBucketPolicy(bucket.ID, string(input.Policy))); err != nil {
+		return fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+
+	s.logger.Info().Str("bucket", bucket.Name).Msg("bucket policy replaced")
+
+	return nil
+}
+
+// GetBucketPolicy returns the policy document of a bucket.
+func (s *BucketPolicyService) GetBucketPolicy(ctx context.Context, bucketName string, ownerID int64) (string, error) {
+	bucket, err := s.ownedBucket(ctx, bucketName, ownerID)
+	if err != nil {
+		return "", err
+	}
+
+	policy, err := s.policyRepo.Get(ctx, bucket.ID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return "", domain.ErrNoSuchBucketPolicy
+		}
+		return "", fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+
+	return policy.Policy, nil
+}
+
+// DeleteBucketPolicy removes the policy of a bucket.
+func (s *BucketPolicyService) DeleteBucketPolicy(ctx context.Context, bucketName string, ownerID int64) error {
+	bucket, err := s.ownedBucket(ctx, bucketName, ownerID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.policyRepo.Delete(ctx, bucket.ID); err != nil {
+		return fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+
+	s.logger.Info().Str("bucket", bucket.Name).Msg("bucket policy deleted")
+
+	return nil
+}
+
+// ownedBucket returns the named bucket after checking it belongs to ownerID.
+func (s *BucketPolicyService) ownedBucket(ctx context.Context, bucketName string, ownerID int64) (*domain.Bucket, error) {
+	bucket, err := s.bucketRepo.GetByName(ctx, bucketName)
+	if err != nil {
+		if errors.Is(err, domain.ErrBucketNotFound) {
+			return nil, domain.ErrBucketNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+	if ownerID > 0 && bucket.OwnerID != ownerID {
+		return nil, ErrBucketAccessDenied
+	}
+	return bucket, nil
+}
+
+// BucketPolicyAdapter adapts BucketPolicyService to auth.BucketPolicyLookup.
+type BucketPolicyAdapter struct {
+	policyService *BucketPolicyService
+}
+
+// NewBucketPolicyAdapter creates a new adapter.
+func NewBucketPolicyAdapter(policyService *BucketPolicyService) *BucketPolicyAdapter {
+	return &BucketPolicyAdapter{policyService: policyService}
+}
+
+// GetBucketPolicy implements auth.BucketPolicyLookup.
+func (a *BucketPolicyAdapter) GetBucketPolicy(ctx context.Context, bucketName string) (*auth.Policy, error) {
+	document, err := a.policyService.GetBucketPolicy(ctx, bucketName, 0)
+	if err != nil {
+		if errors.Is(err, domain.ErrBucketNotFound) || errors.Is(err, domain.ErrNoSuchBucketPolicy) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return auth.ParsePolicy(bucketName, []byte(document))
+}
+
+// Ensure BucketPolicyAdapter implements auth.BucketPolicyLookup
+var _ auth.BucketPolicyLookup = (*BucketPolicyAdapter)(nil)
@@ -16,8 +16,9 @@ import (
 
 // BucketService handles bucket operations.
 type BucketService struct {
-	bucketRepo repository.BucketRepository
-	logger     zerolog.Logger
+	bucketRepo       repository.BucketRepository
+	defaultOwnership domain.ObjectOwnership
+	logger           zerolog.Logger
 }
 
 // NewBucketService creates a new BucketService.
@@ -26,11 +27,18 @@ func NewBucketService(
 	logger zerolog.Logger,
 ) *BucketService {
 	return &BucketService{
-		bucketRepo: bucketRepo,
-		logger:     logger.With().Str("service", "bucket").Logger(),
+		bucketRepo:       bucketRepo,
+		defaultOwnership: domain.OwnershipBucketOwnerEnforced,
+		logger:           logger.With().Str("service", "bucket").Logger(),
 	}
 }
 
+// SetDefaultObjectOwnership sets the object ownership of buckets created
+// without one. The default is BucketOwnerEnforced, which disables ACLs.
+func (s *BucketService) SetDefaultObjectOwnership(ownership domain.ObjectOwnership) {
+	s.defaultOwnership = ownership
+}
+
 // =============================================================================
 // Input/Output Structs
 // =============================================================================
@@ -40,6 +48,16 @@ type CreateBucketInput struct {
 	OwnerID int64
 	Name    string
 	Region  string
+
+	// ACL is the canned ACL of the bucket. Defaults to private.
+	ACL string
+
+	// ObjectOwnership overrides the default object ownership setting.
+	ObjectOwnership domain.ObjectOwnership
+
+	// ObjectLockEnabled creates the bucket with object lock, which also
+	// enables versioning.
+	ObjectLockEnabled bool
 }
 
 // CreateBucketOutput contains the result of creating a bucket.
@@ -104,26 +122,132 @@ type PutBucketVersioningInput struct {
 	Status  domain.VersioningStatus
 }
 
+// PutBucketMaxVersionsInput contains the data needed to set the per-key version limit.
+type PutBucketMaxVersionsInput struct {
+	Name    string
+	OwnerID int64
+
+	// MaxVersionsPerKey is the number of versions kept per key. 0 removes the limit.
+	MaxVersionsPerKey int
+}
+
+// PutBucketKeyCaseInput contains the data needed to set key case sensitivity.
+type PutBucketKeyCaseInput struct {
+	Name    string
+	OwnerID int64
+
+	// CaseInsensitive makes keys that differ only in case address the same object.
+	CaseInsensitive bool
+}
+
+// PutBucketContentTypePolicyInput contains the data needed to set a content-type policy.
+type PutBucketContentTypePolicyInput struct {
+	Name    string
+	OwnerID int64
+
+	// Policy restricts uploads and sets the serve mode. Nil removes the policy.
+	Policy *domain.ContentTypePolicy
+}
+
+// PutBucketEncryptionRequirementInput contains the data needed to require encrypted uploads.
+type PutBucketEncryptionRequirementInput struct {
+	Name    string
+	OwnerID int64
+
+	// Required rejects uploads that are not encrypted with a customer-provided
+	// key (SSE-C).
+	Required bool
+}
+
+// GetObjectLockConfigurationInput contains the data needed to get the object lock configuration.
+type GetObjectLockConfigurationInput struct {
+	Name    string
+	OwnerID int64
+}
+
+// GetObjectLockConfigurationOutput contains the object lock configuration.
+type GetObjectLockConfigurationOutput struct {
+	// DefaultRetention is nil when the bucket has no default retention.
+	DefaultRetention *domain.DefaultRetention
+}
+
+// PutObjectLockConfigurationInput contains the data needed to enable object lock.
+type PutObjectLockConfigurationInput struct {
+	Name    string
+	OwnerID int64
+
+	// DefaultRetention is applied to new object versions written without
+	// retention of their own. Nil removes the default retention.
+	DefaultRetention *domain.DefaultRetention
+}
+
+// GetBucketOwnershipControlsInput contains the data needed to get object ownership.
+type GetBucketOwnershipControlsInput struct {
+	Name    string
+	OwnerID int64
+}
+
+// GetBucketOwnershipControlsOutput contains the object ownership setting.
+type GetBucketOwnershipControlsOutput struct {
+	ObjectOwnership domain.ObjectOwnership
+}
+
+// PutBucketOwnershipControlsInput contains the data needed to set object ownership.
+type PutBucketOwnershipControlsInput struct {
+	Name            string
+	OwnerID         int64
+	ObjectOwnership domain.ObjectOwnership
+}
+
+// PutBucketACLInput contains the data needed to set the canned ACL of a bucket.
+type PutBucketACLInput struct {
+	Name    string
+	OwnerID int64
+	ACL     string
+}
+
 // =============================================================================
 // Service Methods
 // =============================================================================
 
-// CreateBucket creates a new bucket.
+// createBucketAttempts bounds the retries of a create that conflicted with a
+// bucket deleted concurrently.
+const createBucketAttempts = 3
+
+// CreateBucket creates a new bucket. Creating a bucket the caller already owns
+// returns domain.ErrBucketAlreadyOwnedByYou.
 func (s *BucketService) CreateBucket(ctx context.Context, input CreateBucketInput) (*CreateBucketOutput, error) {
 	// Validate bucket name
 	if err := domain.ValidateBucketName(input.Name); err != nil {
 		return nil, err
 	}
 
-	// Check if bucket already exists
-	exists, err := s.bucketRepo.ExistsByName(ctx, input.Name)
+	// Resolve ownership and ACL; with ACLs disabled only a private bucket can be created
+	ownership := input.ObjectOwnership
+	if ownership == "" {
+		ownership = s.defaultOwnership
+	}
+	if !domain.IsValidObjectOwnership(string(ownership)) {
+		return nil, ErrInvalidObjectOwnership
+	}
+	acl := domain.ACLPrivate
+	if input.ACL != "" {
+		if !domain.IsValidACL(input.ACL) {
+			return nil, ErrInvalidBucketACL
+		}
+		acl = domain.BucketACL(input.ACL)
+	}
+	if ownership.ACLsDisabled() && acl != domain.ACLPrivate {
+		return nil, ErrACLNotSupported
+	}
+
+	// Serialize with other creates and deletes of the same name
+	unlock, err := s.bucketRepo.LockName(ctx, input.Name)
 	if err != nil {
-		s.logger.Error().Err(err).Str("bucket", input.Name).Msg("failed to check bucket existence")
+		s.logger.Error().Err(err).Str("bucket", input.Name).Msg("failed to lock bucket name")
 		return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
 	}
-	if exists {
-		return nil, domain.ErrBucketAlreadyExists
-	}
+	defer unlock()
 
 	// Set default region if not specified
 	region := input.Region
@@ -133,20 +257,48 @@ func (s *BucketService) CreateBucket(ctx context.Context, input CreateBucketInpu
 
 	// Create bucket
 	bucket := &domain.Bucket{
-		OwnerID:    input.OwnerID,
-		Name:       input.Name,
-		Region:     region,
-		Versioning: domain.VersioningDisabled,
-		ObjectLock: false,
-		CreatedAt:  time.Now().UTC(),
+		OwnerID:         input.OwnerID,
+		Name:            input.Name,
+		Region:          region,
+		Versioning:      domain.VersioningDisabled,
+		ACL:             acl,
+		ObjectOwnership: ownership,
+		ObjectLock:      false,
+		State:           domain.BucketStateActive,
+		CreatedAt:       time.Now().UTC(),
+	}
+	if input.ObjectLockEnabled {
+		bucket.ObjectLock = true
+		bucket.Versioning = domain.VersioningEnabled
 	}
 
-	if err := s.bucketRepo.Create(ctx, bucket); err != nil {
-		if errors.Is(err, domain.ErrBucketAlreadyExists) {
+	// The unique constraint on the name decides between concurrent creates.
+	// A conflicting bucket that is gone by the time it is looked up was
+	// deleted concurrently, so the create is retried.
+	for attempt := 1; ; attempt++ {
+		err := s.bucketRepo.Create(ctx, bucket)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, domain.ErrBucketAlreadyExists) {
+			s.logger.Error().Err(err).Str("bucket", input.Name).Msg("failed to create bucket")
+			return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
+		}
+
+		existing, err := s.bucketRepo.GetByName(ctx, input.Name)
+		if err == nil {
+			if existing.OwnerID == input.OwnerID {
+				return nil, domain.ErrBucketAlreadyOwnedByYou
+			}
+			return nil, domain.ErrBucketAlreadyExists
+		}
+		if !errors.Is(err, domain.ErrBucketNotFound) {
+			s.logger.Error().Err(err).Str("bucket", input.Name).Msg("failed to get conflicting bucket")
+			return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
+		}
+		if attempt == createBucketAttempts {
 			return nil, domain.ErrBucketAlreadyExists
 		}
-		s.logger.Error().Err(err).Str("bucket", input.Name).Msg("failed to create bucket")
-		return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
 	}
 
 	s.logger.Info().
@@ -172,7 +324,7 @@ func (s *BucketService) GetBucket(ctx context.Context, input GetBucketInput) (*G
 	}
 
 	// Verify ownership if OwnerID is specified
-	if input.OwnerID > 0 && bucket.OwnerID != input.OwnerID {
+	if !bucketAccessAllowed(ctx, bucket, input.OwnerID) {
 		return nil, ErrBucketAccessDenied
 	}
 
@@ -194,8 +346,19 @@ func (s *BucketService) ListBuckets(ctx context.Context, input ListBucketsInput)
 	}, nil
 }
 
-// DeleteBucket deletes a bucket.
+// DeleteBucket deletes a bucket in two phases: the bucket is first marked as
+// deleting so new writes are rejected, in-flight writes are drained, and the
+// bucket is removed only if it is still empty. On failure the bucket returns
+// to the active state.
 func (s *BucketService) DeleteBucket(ctx context.Context, input DeleteBucketInput) error {
+	// Serialize with other creates and deletes of the same name
+	unlock, err := s.bucketRepo.LockName(ctx, input.Name)
+	if err != nil {
+		s.logger.Error().Err(err).Str("bucket", input.Name).Msg("failed to lock bucket name")
+		return fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+	defer unlock()
+
 	// Get bucket to verify it exists and check ownership
 	bucket, err := s.bucketRepo.GetByName(ctx, input.Name)
 	if err != nil {
@@ -207,11 +370,16 @@ func (s *BucketService) DeleteBucket(ctx context.Context, input DeleteBucketInpu
 	}
 
 	// Verify ownership
-	if input.OwnerID > 0 && bucket.OwnerID != input.OwnerID {
+	if !bucketAccessAllowed(ctx, bucket, input.OwnerID) {
 		return ErrBucketAccessDenied
 	}
 
-	// Check if bucket is empty
+	// Another deletion is already in progress
+	if bucket.IsDeleting() {
+		return domain.ErrBucketDeleting
+	}
+
+	// Reject an obviously non-empty bucket before touching its state
 	isEmpty, err := s.bucketRepo.IsEmpty(ctx, bucket.ID)
 	if err != nil {
 		s.logger.Error().Err(err).Str("bucket", input.Name).Msg("failed to check if bucket is empty")
@@ -221,8 +389,36 @@ func (s *BucketService) DeleteBucket(ctx context.Context, input DeleteBucketInpu
 		return domain.ErrBucketNotEmpty
 	}
 
-	// Delete bucket
+	// Phase 1: mark the bucket as deleting so new writes are rejected
+	if err := s.bucketRepo.UpdateState(ctx, bucket.ID, domain.BucketStateDeleting); err != nil {
+		if errors.Is(err, domain.ErrBucketNotFound) {
+			return domain.ErrBucketNotFound
+		}
+		s.logger.Error().Err(err).Str("bucket", input.Name).Msg("failed to mark bucket as deleting")
+		return fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+
+	// Phase 2: wait for writes that started before the state change
+	if err := bucketWrites.Drain(ctx, bucket.Name); err != nil {
+		s.restoreBucketState(bucket)
+		return err
+	}
+
+	// Writes that completed while draining may have added objects
+	isEmpty, err = s.bucketRepo.IsEmpty(ctx, bucket.ID)
+	if err != nil {
+		s.restoreBucketState(bucket)
+		s.logger.Error().Err(err).Str("bucket", input.Name).Msg("failed to check if bucket is empty")
+		return fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+	if !isEmpty {
+		s.restoreBucketState(bucket)
+		return domain.ErrBucketNotEmpty
+	}
+
+	// Phase 3: remove the bucket
 	if err := s.bucketRepo.Delete(ctx, bucket.ID); err != nil {
+		s.restoreBucketState(bucket)
 		s.logger.Error().Err(err).Str("bucket", input.Name).Msg("failed to delete bucket")
 		return fmt.Errorf("%w: %v", ErrInternalError, err)
 	}
@@ -235,6 +431,17 @@ func (s *BucketService) DeleteBucket(ctx context.Context, input DeleteBucketInpu
 	return nil
 }
 
+// restoreBucketState returns a bucket to the active state after an aborted deletion.
+func (s *BucketService) restoreBucketState(bucket *domain.Bucket) {
+	// Use a fresh context: the request context may already be canceled
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := s.bucketRepo.UpdateState(ctx, bucket.ID, domain.BucketStateActive); err != nil {
+		s.logger.Error().Err(err).Str("bucket", bucket.Name).Msg("failed to restore bucket state")
+	}
+}
+
 // HeadBucket checks if a bucket exists and returns its region.
 func (s *BucketService) HeadBucket(ctx context.Context, input HeadBucketInput) (*HeadBucketOutput, error) {
 	bucket, err := s.bucketRepo.GetByName(ctx, input.Name)
@@ -249,7 +456,7 @@ func (s *BucketService) HeadBucket(ctx context.Context, input HeadBucketInput) (
 	}
 
 	// Verify ownership if OwnerID is specified
-	if input.OwnerID > 0 && bucket.OwnerID != input.OwnerID {
+	if !bucketAccessAllowed(ctx, bucket, input.OwnerID) {
 		return nil, ErrBucketAccessDenied
 	}
 
@@ -271,7 +478,7 @@ func (s *BucketService) GetBucketVersioning(ctx context.Context, input GetBucket
 	}
 
 	// Verify ownership
-	if input.OwnerID > 0 && bucket.OwnerID != input.OwnerID {
+	if !bucketAccessAllowed(ctx, bucket, input.OwnerID) {
 		return nil, ErrBucketAccessDenied
 	}
 
@@ -298,10 +505,15 @@ func (s *BucketService) PutBucketVersioning(ctx context.Context, input PutBucket
 	}
 
 	// Verify ownership
-	if input.OwnerID > 0 && bucket.OwnerID != input.OwnerID {
+	if !bucketAccessAllowed(ctx, bucket, input.OwnerID) {
 		return ErrBucketAccessDenied
 	}
 
+	// Locked versions must stay versions, so object lock pins versioning on
+	if bucket.ObjectLock && input.Status != domain.VersioningEnabled {
+		return domain.ErrObjectLockVersioning
+	}
+
 	// Update versioning status
 	if err := s.bucketRepo.UpdateVersioning(ctx, bucket.ID, input.Status); err != nil {
 		s.logger.Error().Err(err).Str("bucket", input.Name).Msg("failed to update versioning")
@@ -316,23 +528,328 @@ func (s *BucketService) PutBucketVersioning(ctx context.Context, input PutBucket
 	return nil
 }
 
-// GetBucketACL retrieves the ACL for a bucket.
+// PutBucketMaxVersions sets how many versions a bucket keeps per key.
+// Older versions are removed on the next write to each key.
+func (s *BucketService) PutBucketMaxVersions(ctx context.Context, input PutBucketMaxVersionsInput) error {
+	if input.MaxVersionsPerKey < 0 {
+		return ErrInvalidMaxVersions
+	}
+
+	bucket, err := s.bucketRepo.GetByName(ctx, input.Name)
+	if err != nil {
+		if errors.Is(err, domain.ErrBucketNotFound) {
+			return domain.ErrBucketNotFound
+		}
+		s.logger.Error().Err(err).Str("bucket", input.Name).Msg("failed to get bucket")
+		return fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+
+	// Verify ownership
+	if !bucketAccessAllowed(ctx, bucket, input.OwnerID) {
+		return ErrBucketAccessDenied
+	}
+
+	if err := s.bucketRepo.UpdateMaxVersionsPerKey(ctx, bucket.ID, input.MaxVersionsPerKey); err != nil {
+		s.logger.Error().Err(err).Str("bucket", input.Name).Msg("failed to update max versions per key")
+		return fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+
+	s.logger.Info().
+		Str("bucket", input.Name).
+		Int("max_versions_per_key", input.MaxVersionsPerKey).
+		Msg("bucket version limit updated")
+
+	return nil
+}
+
+// PutBucketKeyCase switches a bucket between case-sensitive and
+// case-insensitive keys. Enabling case-insensitive keys requires an empty
+// bucket, since existing keys may collide once normalized.
+func (s *BucketService) PutBucketKeyCase(ctx context.Context, input PutBucketKeyCaseInput) error {
+	bucket, err := s.bucketRepo.GetByName(ctx, input.Name)
+	if err != nil {
+		if errors.Is(err, domain.ErrBucketNotFound) {
+			return domain.ErrBucketNotFound
+		}
+		s.logger.Error().Err(err).Str("bucket", input.Name).Msg("failed to get bucket")
+		return fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+
+	// Verify ownership
+	if !bucketAccessAllowed(ctx, bucket, input.OwnerID) {
+		return ErrBucketAccessDenied
+	}
+
+	if bucket.CaseInsensitiveKeys == input.CaseInsensitive {
+		return nil
+	}
+
+	if input.CaseInsensitive {
+		isEmpty, err := s.bucketRepo.IsEmpty(ctx, bucket.ID)
+		if err != nil {
+			s.logger.Error().Err(err).Str("bucket", input.Name).Msg("failed to check if bucket is empty")
+			return fmt.Errorf("%w: %v", ErrInternalError, err)
+		}
+		if !isEmpty {
+			return domain.ErrBucketNotEmpty
+		}
+	}
+
+	if err := s.bucketRepo.UpdateCaseInsensitiveKeys(ctx, bucket.ID, input.CaseInsensitive); err != nil {
+		s.logger.Error().Err(err).Str("bucket", input.Name).Msg("failed to update key case sensitivity")
+		return fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+
+	s.logger.Info().
+		Str("bucket", input.Name).
+		Bool("case_insensitive_keys", input.CaseInsensitive).
+		Msg("bucket key case sensitivity updated")
+
+	return nil
+}
+
+// PutBucketContentTypePolicy sets or clears the content-type policy of a bucket.
+// The policy only applies to writes made after it is set.
+func (s *BucketService) PutBucketContentTypePolicy(ctx context.Context, input PutBucketContentTypePolicyInput) error {
+	if input.Policy != nil {
+		if err := input.Policy.Validate(); err != nil {
+			return err
+		}
+	}
+
+	bucket, err := s.bucketRepo.GetByName(ctx, input.Name)
+	if err != nil {
+		if errors.Is(err, domain.ErrBucketNotFound) {
+			return domain.ErrBucketNotFound
+		}
+		s.logger.Error().Err(err).Str("bucket", input.Name).Msg("failed to get bucket")
+		return fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+
+	// Verify ownership
+	if !bucketAccessAllowed(ctx, bucket, input.OwnerID) {
+		return ErrBucketAccessDenied
+	}
+
+	if err := s.bucketRepo.UpdateContentTypePolicy(ctx, bucket.ID, input.Policy); err != nil {
+		s.logger.Error().Err(err).Str("bucket", input.Name).Msg("failed to update content type policy")
+		return fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+
+	s.logger.Info().
+		Str("bucket", input.Name).
+		Bool("content_type_policy", input.Policy != nil).
+		Msg("bucket content type policy updated")
+
+	return nil
+}
+
+// PutBucketEncryptionRequirement sets whether a bucket rejects uploads that
+// are not encrypted with a customer-provided key (SSE-C). Existing objects
+// are not affected.
+func (s *BucketService) PutBucketEncryptionRequirement(ctx context.Context, input PutBucketEncryptionRequirementInput) error {
+	bucket, err := s.bucketRepo.GetByName(ctx, input.Name)
+	if err != nil {
+		if errors.Is(err, domain.ErrBucketNotFound) {
+			return domain.ErrBucketNotFound
+		}
+		s.logger.Error().Err(err).Str("bucket", input.Name).Msg("failed to get bucket")
+		return fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+
+	// Verify ownership
+	if !bucketAccessAllowed(ctx, bucket, input.OwnerID) {
+		return ErrBucketAccessDenied
+	}
+
+	if err := s.bucketRepo.UpdateRequireEncryption(ctx, bucket.ID, input.Required); err != nil {
+		s.logger.Error().Err(err).Str("bucket", input.Name).Msg("failed to update encryption requirement")
+		return fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+
+	s.logger.Info().
+		Str("bucket", input.Name).
+		Bool("require_encryption", input.Required).
+		Msg("bucket encryption requirement updated")
+
+	return nil
+}
+
+// GetObjectLockConfiguration returns the object lock configuration of a
+// bucket. Buckets without object lock have no configuration.
+func (s *BucketService) GetObjectLockConfiguration(ctx context.Context, input GetObjectLockConfigurationInput) (*GetObjectLockConfigurationOutput, error) {
+	bucket, err := s.bucketRepo.GetByName(ctx, input.Name)
+	if err != nil {
+		if errors.Is(err, domain.ErrBucketNotFound) {
+			return nil, domain.ErrBucketNotFound
+		}
+		s.logger.Error().Err(err).Str("bucket", input.Name).Msg("failed to get bucket")
+		return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+
+	// Verify ownership
+	if !bucketAccessAllowed(ctx, bucket, input.OwnerID) {
+		return nil, ErrBucketAccessDenied
+	}
+
+	if !bucket.ObjectLock {
+		return nil, domain.ErrObjectLockNotEnabled
+	}
+	return &GetObjectLockConfigurationOutput{DefaultRetention: bucket.DefaultRetention}, nil
+}
+
+// PutObjectLockConfiguration enables object lock on a bucket and replaces its
+// default retention. Versioning must already be enabled, and object lock
+// cannot be disabled again.
+func (s *BucketService) PutObjectLockConfiguration(ctx context.Context, input PutObjectLockConfigurationInput) error {
+	if input.DefaultRetention != nil {
+		if err := input.DefaultRetention.Validate(); err != nil {
+			return err
+		}
+	}
+
+	bucket, err := s.bucketRepo.GetByName(ctx, input.Name)
+	if err != nil {
+		if errors.Is(err, domain.ErrBucketNotFound) {
+			return domain.ErrBucketNotFound
+		}
+		s.logger.Error().Err(err).Str("bucket", input.Name).Msg("failed to get bucket")
+		return fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+
+	// Verify ownership
+	if !bucketAccessAllowed(ctx, bucket, input.OwnerID) {
+		return ErrBucketAccessDenied
+	}
+
+	if !bucket.ObjectLock {
+		if bucket.Versioning != domain.VersioningEnabled {
+			return domain.ErrObjectLockVersioning
+		}
+
+		if err := s.bucketRepo.EnableObjectLock(ctx, bucket.ID); err != nil {
+			s.logger.Error().Err(err).Str("bucket", input.Name).Msg("failed to enable object lock")
+			return fmt.Errorf("%w: %v", ErrInternalError, err)
+		}
+
+		s.logger.Info().
+			Str("bucket", input.Name).
+			Msg("bucket object lock enabled")
+	}
+
+	if bucket.DefaultRetention == nil && input.DefaultRetention == nil {
+		return nil
+	}
+	if err := s.bucketRepo.UpdateDefaultRetention(ctx, bucket.ID, input.DefaultRetention); err != nil {
+		s.logger.Error().Err(err).Str("bucket", input.Name).Msg("failed to update default retention")
+		return fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+
+	s.logger.Info().
+		Str("bucket", input.Name).
+		Bool("default_retention", input.DefaultRetention != nil).
+		Msg("bucket default retention updated")
+
+	return nil
+}
+
+// GetBucketOwnershipControls returns the object ownership setting of a bucket.
+func (s *BucketService) GetBucketOwnershipControls(ctx context.Context, input GetBucketOwnershipControlsInput) (*GetBucketOwnershipControlsOutput, error) {
+	output, err := s.GetBucket(ctx, GetBucketInput(input))
+	if err != nil {
+		return nil, err
+	}
+
+	return &GetBucketOwnershipControlsOutput{
+		ObjectOwnership: output.Bucket.ObjectOwnership,
+	}, nil
+}
+
+// PutBucketOwnershipControls sets the object ownership setting of a bucket.
+// BucketOwnerEnforced disables ACLs: the bucket's canned ACL stops granting
+// anonymous access and ACL changes are rejected.
+func (s *BucketService) PutBucketOwnershipControls(ctx context.Context, input PutBucketOwnershipControlsInput) error {
+	if !domain.IsValidObjectOwnership(string(input.ObjectOwnership)) {
+		return ErrInvalidObjectOwnership
+	}
+
+	output, err := s.GetBucket(ctx, GetBucketInput{Name: input.Name, OwnerID: input.OwnerID})
+	if err != nil {
+		return err
+	}
+
+	if err := s.bucketRepo.UpdateObjectOwnership(ctx, output.Bucket.ID, input.ObjectOwnership); err != nil {
+		s.logger.Error().Err(err).Str("bucket", input.Name).Msg("failed to update object ownership")
+		return fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+
+	s.logger.Info().
+		Str("bucket", input.Name).
+		Str("object_ownership", string(input.ObjectOwnership)).
+		Msg("bucket object ownership updated")
+
+	return nil
+}
+
+// PutBucketACL sets the canned ACL of a bucket.
+// With ACLs disabled, only the private ACL is accepted and nothing changes.
+func (s *BucketService) PutBucketACL(ctx context.Context, input PutBucketACLInput) error {
+	if !domain.IsValidACL(input.ACL) {
+		return ErrInvalidBucketACL
+	}
+
+	output, err := s.GetBucket(ctx, GetBucketInput{Name: input.Name, OwnerID: input.OwnerID})
+	if err != nil {
+		return err
+	}
+	bucket := output.Bucket
+
+	if bucket.ACLsDisabled() {
+		if !bucket.AcceptsCannedACL(input.ACL) {
+			return ErrACLNotSupported
+		}
+		return nil
+	}
+
+	if err := s.bucketRepo.UpdateACL(ctx, bucket.ID, domain.BucketACL(input.ACL)); err != nil {
+		s.logger.Error().Err(err).Str("bucket", input.Name).Msg("failed to update ACL")
+		return fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+
+	s.logger.Info().
+		Str("bucket", input.Name).
+		Str("acl", input.ACL).
+		Msg("bucket ACL updated")
+
+	return nil
+}
+
+// GetBucketACL retrieves the ACL governing anonymous access to a bucket.
+// Buckets with ACLs disabled are reported as private.
 func (s *BucketService) GetBucketACL(ctx context.Context, bucketName string) (domain.BucketACL, error) {
-	acl, err := s.bucketRepo.GetACLByName(ctx, bucketName)
+	bucket, err := s.bucketRepo.GetByName(ctx, bucketName)
 	if err != nil {
 		if errors.Is(err, domain.ErrBucketNotFound) {
 			return "", nil // Return empty string for not found
 		}
 		return "", fmt.Errorf("%w: %v", ErrInternalError, err)
 	}
-	return acl, nil
+	return bucket.EffectiveACL(), nil
+}
+
+// bucketAccessAllowed reports whether the caller with ownerID may use bucket:
+// ownership is not checked (ownerID 0), the caller owns the bucket, or the
+// authorizer opened it to the caller through its policy or ACL.
+func bucketAccessAllowed(ctx context.Context, bucket *domain.Bucket, ownerID int64) bool {
+	return ownerID <= 0 || bucket.OwnerID == ownerID || auth.BucketAccessGranted(ctx, bucket.Name)
 }
 
 // =============================================================================
 // BucketACLAdapter
 // =============================================================================
 
-// BucketACLAdapter adapts BucketService to implement auth.BucketACLChecker interface.
+// BucketACLAdapter adapts BucketService to implement the auth.BucketACLChecker
+// and auth.BucketOwnerLookup interfaces.
 type BucketACLAdapter struct {
 	bucketService *BucketService
 }
@@ -351,5 +868,20 @@ func (a *BucketACLAdapter) GetBucketACL(ctx context.Context, bucketName string)
 	return string(acl), nil
 }
 
+// GetBucketOwner implements auth.BucketOwnerLookup.
+func (a *BucketACLAdapter) GetBucketOwner(ctx context.Context, bucketName string) (int64, error) {
+	output, err := a.bucketService.GetBucket(ctx, GetBucketInput{Name: bucketName})
+	if err != nil {
+		if errors.Is(err, domain.ErrBucketNotFound) {
+			return 0, nil // Let the service report the missing bucket
+		}
+		return 0, err
+	}
+	return output.Bucket.OwnerID, nil
+}
+
 // Ensure BucketACLAdapter implements auth.BucketACLChecker
 var _ auth.BucketACLChecker = (*BucketACLAdapter)(nil)
+
+// Ensure BucketACLAdapter implements auth.BucketOwnerLookup
+var _ auth.BucketOwnerLookup = (*BucketACLAdapter)(nil)
@@ -2,12 +2,22 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
 
 	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/lock"
+	"github.com/prn-tf/alexander-storage/internal/repository"
+	"github.com/prn-tf/alexander-storage/internal/repository/sqlite"
+	"github.com/prn-tf/alexander-storage/internal/storage"
 )
 
 // MockBucketRepository is a mock implementation of repository.BucketRepository.
@@ -120,6 +130,10 @@ func (m *MockBucketRepository) ExistsByName(ctx context.Context, name string) (b
 	return exists, nil
 }
 
+func (m *MockBucketRepository) LockName(ctx context.Context, name string) (func(), error) {
+	return func() {}, nil
+}
+
 func (m *MockBucketRepository) IsEmpty(ctx context.Context, id int64) (bool, error) {
 	count, exists := m.objects[id]
 	if !exists {
@@ -145,6 +159,86 @@ func (m *MockBucketRepository) UpdateACL(ctx context.Context, id int64, acl doma
 	return domain.ErrBucketNotFound
 }
 
+func (m *MockBucketRepository) UpdateObjectOwnership(ctx context.Context, id int64, ownership domain.ObjectOwnership) error {
+	for _, b := range m.buckets {
+		if b.ID == id {
+			b.ObjectOwnership = ownership
+			return nil
+		}
+	}
+	return domain.ErrBucketNotFound
+}
+
+func (m *MockBucketRepository) UpdateState(ctx context.Context, id int64, state domain.BucketState) error {
+	for _, b := range m.buckets {
+		if b.ID == id {
+			b.State = state
+			return nil
+		}
+	}
+	return domain.ErrBucketNotFound
+}
+
+func (m *MockBucketRepository) UpdateMaxVersionsPerKey(ctx context.Context, id int64, maxVersions int) error {
+	for _, b := range m.buckets {
+		if b.ID == id {
+			b.MaxVersionsPerKey = maxVersions
+			return nil
+		}
+	}
+	return domain.ErrBucketNotFound
+}
+
+func (m *MockBucketRepository) UpdateCaseInsensitiveKeys(ctx context.Context, id int64, enabled bool) error {
+	for _, b := range m.buckets {
+		if b.ID == id {
+			b.CaseInsensitiveKeys = enabled
+			return nil
+		}
+	}
+	return domain.ErrBucketNotFound
+}
+
+func (m *MockBucketRepository) UpdateContentTypePolicy(ctx context.Context, id int64, policy *domain.ContentTypePolicy) error {
+	for _, b := range m.buckets {
+		if b.ID == id {
+			b.ContentTypePolicy = policy
+			return nil
+		}
+	}
+	return domain.ErrBucketNotFound
+}
+
+func (m *MockBucketRepository) UpdateRequireEncryption(ctx context.Context, id int64, required bool) error {
+	for _, b := range m.buckets {
+		if b.ID == id {
+			b.RequireEncryption = required
+			return nil
+		}
+	}
+	return domain.ErrBucketNotFound
+}
+
+func (m *MockBucketRepository) EnableObjectLock(ctx context.Context, id int64) error {
+	for _, b := range m.buckets {
+		if b.ID == id {
+			b.ObjectLock = true
+			return nil
+		}
+	}
+	return domain.ErrBucketNotFound
+}
+
+func (m *MockBucketRepository) UpdateDefaultRetention(ctx context.Context, id int64, retention *domain.DefaultRetention) error {
+	for _, b := range m.buckets {
+		if b.ID == id {
+			b.DefaultRetention = retention
+			return nil
+		}
+	}
+	return domain.ErrBucketNotFound
+}
+
 // Helper to add objects to a bucket for testing
 func (m *MockBucketRepository) AddObjects(bucketID int64, count int64) {
 	m.objects[bucketID] = count
@@ -196,11 +290,54 @@ func TestBucketService_CreateBucket(t *testing.T) {
 			wantErr: domain.ErrBucketNameFormat,
 		},
 		{
-			name: "already exists",
+			name: "public ACL with ACLs disabled by default",
+			input: CreateBucketInput{
+				OwnerID: 1,
+				Name:    "public-bucket",
+				ACL:     "public-read",
+			},
+			wantErr: ErrACLNotSupported,
+		},
+		{
+			name: "public ACL with object writer ownership",
+			input: CreateBucketInput{
+				OwnerID:         1,
+				Name:            "public-bucket",
+				ACL:             "public-read",
+				ObjectOwnership: domain.OwnershipObjectWriter,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "invalid object ownership",
+			input: CreateBucketInput{
+				OwnerID:         1,
+				Name:            "my-bucket",
+				ObjectOwnership: "Nobody",
+			},
+			wantErr: ErrInvalidObjectOwnership,
+		},
+		{
+			name: "already owned by caller",
 			input: CreateBucketInput{
 				OwnerID: 1,
 				Name:    "existing-bucket",
 			},
+			wantErr: domain.ErrBucketAlreadyOwnedByYou,
+			setupRepo: func(m *MockBucketRepository) {
+				m.buckets["existing-bucket"] = &domain.Bucket{
+					ID:      1,
+					OwnerID: 1,
+					Name:    "existing-bucket",
+				}
+			},
+		},
+		{
+			name: "already exists",
+			input: CreateBucketInput{
+				OwnerID: 2,
+				Name:    "existing-bucket",
+			},
 			wantErr: domain.ErrBucketAlreadyExists,
 			setupRepo: func(m *MockBucketRepository) {
 				m.buckets["existing-bucket"] = &domain.Bucket{
@@ -250,6 +387,10 @@ func TestBucketService_CreateBucket(t *testing.T) {
 			if tt.input.Region == "" && output.Bucket.Region != "us-east-1" {
 				t.Errorf("expected default region us-east-1, got %s", output.Bucket.Region)
 			}
+
+			if tt.input.ObjectOwnership == "" && output.Bucket.ObjectOwnership != domain.OwnershipBucketOwnerEnforced {
+				t.Errorf("expected default object ownership BucketOwnerEnforced, got %s", output.Bucket.ObjectOwnership)
+			}
 		})
 	}
 }
@@ -300,6 +441,22 @@ func TestBucketService_DeleteBucket(t *testing.T) {
 				m.AddObjects(1, 5) // 5 objects in bucket
 			},
 		},
+		{
+			name: "deletion already in progress",
+			input: DeleteBucketInput{
+				Name:    "deleting-bucket",
+				OwnerID: 1,
+			},
+			wantErr: domain.ErrBucketDeleting,
+			setupRepo: func(m *MockBucketRepository) {
+				m.buckets["deleting-bucket"] = &domain.Bucket{
+					ID:      1,
+					OwnerID: 1,
+					Name:    "deleting-bucket",
+					State:   domain.BucketStateDeleting,
+				}
+			},
+		},
 		{
 			name: "access denied - different owner",
 			input: DeleteBucketInput{
@@ -345,6 +502,268 @@ func TestBucketService_DeleteBucket(t *testing.T) {
 	}
 }
 
+// raceStore is a goroutine-safe in-memory bucket and object store for
+// exercising DeleteBucket against concurrent writes. Unlike the database it
+// has no foreign keys, so an object written after its bucket was removed
+// survives as an orphan.
+type raceStore struct {
+	mu      sync.Mutex
+	bucket  *domain.Bucket
+	objects map[int64]int
+}
+
+func newRaceStore() *raceStore {
+	return &raceStore{
+		bucket: &domain.Bucket{
+			ID:         1,
+			OwnerID:    1,
+			Name:       "race-bucket",
+			Versioning: domain.VersioningEnabled,
+			State:      domain.BucketStateActive,
+		},
+		objects: make(map[int64]int),
+	}
+}
+
+type raceBucketRepository struct {
+	repository.BucketRepository
+	store *raceStore
+}
+
+func (r *raceBucketRepository) GetByName(ctx context.Context, name string) (*domain.Bucket, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if r.store.bucket == nil || r.store.bucket.Name != name {
+		return nil, domain.ErrBucketNotFound
+	}
+	bucket := *r.store.bucket
+	return &bucket, nil
+}
+
+func (r *raceBucketRepository) LockName(ctx context.Context, name string) (func(), error) {
+	return func() {}, nil
+}
+
+func (r *raceBucketRepository) UpdateState(ctx context.Context, id int64, state domain.BucketState) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if r.store.bucket == nil {
+		return domain.ErrBucketNotFound
+	}
+	r.store.bucket.State = state
+	return nil
+}
+
+func (r *raceBucketRepository) IsEmpty(ctx context.Context, id int64) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return r.store.objects[id] == 0, nil
+}
+
+func (r *raceBucketRepository) Delete(ctx context.Context, id int64) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if r.store.bucket == nil {
+		return domain.ErrBucketNotFound
+	}
+	r.store.bucket = nil
+	return nil
+}
+
+type raceObjectRepository struct {
+	repository.ObjectRepository
+	store *raceStore
+}
+
+func (r *raceObjectRepository) MarkNotLatest(ctx context.Context, bucketID int64, key string) error {
+	return nil
+}
+
+func (r *raceObjectRepository) Create(ctx context.Context, obj *domain.Object) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	r.store.objects[obj.BucketID]++
+	return nil
+}
+
+type raceBlobRepository struct {
+	repository.BlobRepository
+}
+
+func (r *raceBlobRepository) UpsertWithRefIncrement(ctx context.Context, contentHash string, size int64, storagePath string) (bool, error) {
+	return true, nil
+}
+
+// raceStorage blocks Store until release is closed, keeping writes in flight.
+type raceStorage struct {
+	storage.Backend
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *raceStorage) Store(ctx context.Context, reader io.Reader, size int64) (string, error) {
+	s.started <- struct{}{}
+	<-s.release
+	return "racehash", nil
+}
+
+func (s *raceStorage) GetPath(contentHash string) string {
+	return "/data/" + contentHash
+}
+
+func TestBucketService_DeleteBucketDrainsInFlightWrites(t *testing.T) {
+	const writers = 8
+
+	store := newRaceStore()
+	backend := &raceStorage{
+		started: make(chan struct{}, writers),
+		release: make(chan struct{}),
+	}
+	bucketRepo := &raceBucketRepository{store: store}
+	objectSvc := NewObjectService(&raceObjectRepository{store: store}, &raceBlobRepository{}, bucketRepo, backend, lock.NewNoOpLocker(), zerolog.Nop())
+	bucketSvc := NewBucketService(bucketRepo, zerolog.Nop())
+	ctx := context.Background()
+
+	// Start writes that have passed the bucket check but not created objects yet
+	putErrs := make(chan error, writers)
+	for i := 0; i < writers; i++ {
+		go func(i int) {
+			_, err := objectSvc.PutObject(ctx, PutObjectInput{
+				BucketName: "race-bucket",
+				Key:        fmt.Sprintf("object-%d", i),
+				Body:       strings.NewReader("data"),
+				Size:       4,
+				OwnerID:    1,
+			})
+			putErrs <- err
+		}(i)
+	}
+	for i := 0; i < writers; i++ {
+		<-backend.started
+	}
+
+	// The bucket is still empty, so deletion proceeds to drain the writes
+	deleteErr := make(chan error, 1)
+	go func() {
+		deleteErr <- bucketSvc.DeleteBucket(ctx, DeleteBucketInput{Name: "race-bucket", OwnerID: 1})
+	}()
+
+	// Wait until the bucket is marked as deleting, then verify new writes are rejected
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		bucket, err := bucketRepo.GetByName(ctx, "race-bucket")
+		if err != nil {
+			t.Fatalf("bucket disappeared before in-flight writes finished: %v", err)
+		}
+		if bucket.IsDeleting() {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("bucket was never marked as deleting")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	_, err := objectSvc.PutObject(ctx, PutObjectInput{
+		BucketName: "race-bucket",
+		Key:        "late-object",
+		Body:       strings.NewReader("data"),
+		Size:       4,
+		OwnerID:    1,
+	})
+	if !errors.Is(err, domain.ErrBucketDeleting) {
+		t.Fatalf("expected %v for a write during deletion, got %v", domain.ErrBucketDeleting, err)
+	}
+
+	close(backend.release)
+	for i := 0; i < writers; i++ {
+		if err := <-putErrs; err != nil {
+			t.Errorf("in-flight write failed: %v", err)
+		}
+	}
+
+	// The drained writes made the bucket non-empty, so deletion is aborted
+	if err := <-deleteErr; !errors.Is(err, domain.ErrBucketNotEmpty) {
+		t.Fatalf("expected %v, got %v", domain.ErrBucketNotEmpty, err)
+	}
+
+	bucket, err := bucketRepo.GetByName(ctx, "race-bucket")
+	if err != nil {
+		t.Fatalf("bucket with objects was deleted: %v", err)
+	}
+	if bucket.State != domain.BucketStateActive {
+		t.Errorf("expected bucket to return to %q, got %q", domain.BucketStateActive, bucket.State)
+	}
+}
+
+func TestBucketService_DeleteBucketWhileWriting(t *testing.T) {
+	const writers = 8
+
+	for iter := 0; iter < 50; iter++ {
+		store := newRaceStore()
+		backend := &raceStorage{
+			started: make(chan struct{}, writers),
+			release: make(chan struct{}),
+		}
+		close(backend.release)
+		bucketRepo := &raceBucketRepository{store: store}
+		objectSvc := NewObjectService(&raceObjectRepository{store: store}, &raceBlobRepository{}, bucketRepo, backend, lock.NewNoOpLocker(), zerolog.Nop())
+		bucketSvc := NewBucketService(bucketRepo, zerolog.Nop())
+		ctx := context.Background()
+
+		var wg sync.WaitGroup
+		start := make(chan struct{})
+		for i := 0; i < writers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				<-start
+				_, err := objectSvc.PutObject(ctx, PutObjectInput{
+					BucketName: "race-bucket",
+					Key:        fmt.Sprintf("object-%d", i),
+					Body:       strings.NewReader("data"),
+					Size:       4,
+					OwnerID:    1,
+				})
+				if err != nil && !errors.Is(err, domain.ErrBucketDeleting) && !errors.Is(err, domain.ErrBucketNotFound) {
+					t.Errorf("unexpected write error: %v", err)
+				}
+			}(i)
+		}
+
+		var deleteErr error
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			deleteErr = bucketSvc.DeleteBucket(ctx, DeleteBucketInput{Name: "race-bucket", OwnerID: 1})
+		}()
+
+		close(start)
+		wg.Wait()
+
+		store.mu.Lock()
+		bucket, objects := store.bucket, store.objects[1]
+		store.mu.Unlock()
+
+		switch {
+		case deleteErr == nil:
+			if bucket != nil {
+				t.Fatalf("iteration %d: bucket survived a successful delete", iter)
+			}
+			if objects != 0 {
+				t.Fatalf("iteration %d: %d orphaned objects survived bucket deletion", iter, objects)
+			}
+		case errors.Is(deleteErr, domain.ErrBucketNotEmpty):
+			if bucket == nil || bucket.State != domain.BucketStateActive {
+				t.Fatalf("iteration %d: aborted delete left bucket in state %v", iter, bucket)
+			}
+		default:
+			t.Fatalf("iteration %d: unexpected delete error: %v", iter, deleteErr)
+		}
+	}
+}
+
 func TestBucketService_ListBuckets(t *testing.T) {
 	repo := NewMockBucketRepository()
 
@@ -472,3 +891,136 @@ func TestBucketService_PutBucketVersioning(t *testing.T) {
 		})
 	}
 }
+
+func TestBucketService_ConcurrentCreateAndDelete(t *testing.T) {
+	ctx := context.Background()
+	inst := startMultipartInstance(t, t.TempDir())
+	defer inst.db.Close()
+
+	users := sqlite.NewUserRepository(inst.db)
+	alice := domain.NewUser("alice", "alice@example.com", "hash")
+	require.NoError(t, users.Create(ctx, alice))
+	bob := domain.NewUser("bob", "bob@example.com", "hash")
+	require.NoError(t, users.Create(ctx, bob))
+
+	svc := NewBucketService(sqlite.NewBucketRepository(inst.db), zerolog.Nop())
+
+	t.Run("CreateSameName", func(t *testing.T) {
+		const workers = 32
+		owners := make([]int64, workers)
+		errs := make([]error, workers)
+		var wg sync.WaitGroup
+		for i := 0; i < workers; i++ {
+			owners[i] = alice.ID
+			if i%2 == 1 {
+				owners[i] = bob.ID
+			}
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				_, errs[i] = svc.CreateBucket(ctx, CreateBucketInput{OwnerID: owners[i], Name: "contested"})
+			}(i)
+		}
+		wg.Wait()
+
+		bucket, err := sqlite.NewBucketRepository(inst.db).GetByName(ctx, "contested")
+		require.NoError(t, err)
+
+		created := 0
+		for i, err := range errs {
+			switch {
+			case err == nil:
+				created++
+				require.Equal(t, bucket.OwnerID, owners[i])
+			case owners[i] == bucket.OwnerID:
+				require.ErrorIs(t, err, domain.ErrBucketAlreadyOwnedByYou)
+			default:
+				require.ErrorIs(t, err, domain.ErrBucketAlreadyExists)
+			}
+		}
+		require.Equal(t, 1, created)
+	})
+
+	t.Run("CreateAndDeleteRace", func(t *testing.T) {
+		const workers = 16
+		errs := make(chan error, workers*10)
+		var wg sync.WaitGroup
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 10; j++ {
+					if (i+j)%2 == 0 {
+						_, err := svc.CreateBucket(ctx, CreateBucketInput{OwnerID: alice.ID, Name: "churn"})
+						if err != nil && !errors.Is(err, domain.ErrBucketAlreadyOwnedByYou) {
+							errs <- err
+						}
+					} else {
+						err := svc.DeleteBucket(ctx, DeleteBucketInput{OwnerID: alice.ID, Name: "churn"})
+						if err != nil && !errors.Is(err, domain.ErrBucketNotFound) {
+							errs <- err
+						}
+					}
+				}
+			}(i)
+		}
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
+
+// staleBucketRepository reports every bucket as active, like a node whose
+// view of the bucket predates a deletion started elsewhere.
+type staleBucketRepository struct {
+	repository.BucketRepository
+}
+
+func (r *staleBucketRepository) GetByName(ctx context.Context, name string) (*domain.Bucket, error) {
+	bucket, err := r.BucketRepository.GetByName(ctx, name)
+	if err == nil {
+		bucket.State = domain.BucketStateActive
+	}
+	return bucket, err
+}
+
+func TestBucketService_DeletingBucketRefusesWritesFromOtherNodes(t *testing.T) {
+	ctx := context.Background()
+	dir, ownerID := setupMultipartRestart(t)
+	inst := startMultipartInstance(t, dir)
+	defer inst.db.Close()
+
+	bucketRepo := sqlite.NewBucketRepository(inst.db)
+	blobRepo := sqlite.NewBlobRepository(inst.db)
+	bucket, err := bucketRepo.GetByName(ctx, "uploads")
+	require.NoError(t, err)
+	require.NoError(t, bucketRepo.UpdateState(ctx, bucket.ID, domain.BucketStateDeleting))
+
+	// The write passes the in-process checks but not the database's
+	objectSvc := NewObjectService(sqlite.NewObjectRepository(inst.db), blobRepo, &staleBucketRepository{bucketRepo}, inst.storage, lock.NewNoOpLocker(), zerolog.Nop())
+	_, err = objectSvc.PutObject(ctx, PutObjectInput{
+		BucketName: "uploads",
+		Key:        "late-object",
+		Body:       strings.NewReader("data"),
+		Size:       4,
+		OwnerID:    ownerID,
+	})
+	if !errors.Is(err, domain.ErrBucketDeleting) {
+		t.Fatalf("expected %v, got %v", domain.ErrBucketDeleting, err)
+	}
+
+	// The bucket stays empty and the stored blob is left unreferenced
+	isEmpty, err := bucketRepo.IsEmpty(ctx, bucket.ID)
+	require.NoError(t, err)
+	if !isEmpty {
+		t.Fatal("write reached a bucket marked as deleting")
+	}
+	refCount, err := blobRepo.GetRefCount(ctx, "3a6eb0790f39ac87c94f3856b2dd2c5d110e6811602261a9a923d3bb23adc8b7")
+	require.NoError(t, err)
+	if refCount != 0 {
+		t.Errorf("expected the blob reference to be released, got %d", refCount)
+	}
+}
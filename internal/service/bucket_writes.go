@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+)
+
+// bucketWriteTracker counts in-flight writes per bucket so a bucket deletion
+// can wait for them to finish after the bucket was marked as deleting.
+//
+// Writers register before they look up the bucket, so any write that saw the
+// bucket as active is visible to a concurrent Drain. Tracking is in-process;
+// deletion re-checks that the bucket is empty after draining to cover writes
+// served by other nodes, and the object insert itself is refused once the
+// bucket is marked.
+type bucketWriteTracker struct {
+	mu      sync.Mutex
+	active  map[string]int
+	changed chan struct{}
+}
+
+// bucketWrites is shared by all services of the process.
+var bucketWrites = newBucketWriteTracker()
+
+// newBucketWriteTracker creates an empty tracker.
+func newBucketWriteTracker() *bucketWriteTracker {
+	return &bucketWriteTracker{
+		active:  make(map[string]int),
+		changed: make(chan struct{}),
+	}
+}
+
+// Begin registers a write to the named bucket.
+// The returned function must be called once the write has finished.
+func (t *bucketWriteTracker) Begin(bucketName string) func() {
+	t.mu.Lock()
+	t.active[bucketName]++
+	t.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.active[bucketName]--
+			if t.active[bucketName] <= 0 {
+				delete(t.active, bucketName)
+			}
+			close(t.changed)
+			t.changed = make(chan struct{})
+		})
+	}
+}
+
+// Drain blocks until no writes to the named bucket are in flight.
+func (t *bucketWriteTracker) Drain(ctx context.Context, bucketName string) error {
+	for {
+		t.mu.Lock()
+		if t.active[bucketName] == 0 {
+			t.mu.Unlock()
+			return nil
+		}
+		changed := t.changed
+		t.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// createObjectError maps a failed object insert to the error returned to the
+// client. The insert itself is refused once a bucket is marked as deleting,
+// which also covers writes served by other nodes that the tracker misses.
+func createObjectError(err error) error {
+	if errors.Is(err, domain.ErrBucketDeleting) || errors.Is(err, domain.ErrBucketNotFound) {
+		return err
+	}
+	return fmt.Errorf("%w: %v", ErrInternalError, err)
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+)
+
+// checksumBody computes an additional checksum (x-amz-checksum-*) over an
+// upload body while it is stored.
+type checksumBody struct {
+	algorithm domain.ChecksumAlgorithm
+	expected  string
+	hash      hash.Hash
+}
+
+// newChecksumBody returns the reader to store body from and the checksum
+// computed over it. expected is the client-supplied base64 checksum, empty
+// when only the algorithm is known. Without an algorithm the body is
+// returned as is and the checksum is nil.
+func newChecksumBody(body io.Reader, algorithm domain.ChecksumAlgorithm, expected string) (io.Reader, *checksumBody, error) {
+	if algorithm == "" {
+		if expected != "" {
+			return nil, nil, fmt.Errorf("%w: checksum without an algorithm", domain.ErrInvalidChecksum)
+		}
+		return body, nil, nil
+	}
+	if !algorithm.IsValid() {
+		return nil, nil, fmt.Errorf("%w: unsupported algorithm %q", domain.ErrInvalidChecksum, algorithm)
+	}
+	if expected != "" {
+		if err := algorithm.ValidateChecksum(expected); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	c := &checksumBody{algorithm: algorithm, expected: expected, hash: algorithm.New()}
+	return io.TeeReader(body, c.hash), c, nil
+}
+
+// verify returns the base64 checksum of the body once it is read, or
+// domain.ErrChecksumMismatch if it differs from the expected one.
+// A nil checksum verifies as empty.
+func (c *checksumBody) verify() (string, error) {
+	if c == nil {
+		return "", nil
+	}
+	sum := base64.StdEncoding.EncodeToString(c.hash.Sum(nil))
+	if c.expected != "" && sum != c.expected {
+		return "", domain.ErrChecksumMismatch
+	}
+	return sum, nil
+}
+
+// algorithmOrEmpty returns the checksum's algorithm, or empty for nil.
+func (c *checksumBody) algorithmOrEmpty() domain.ChecksumAlgorithm {
+	if c == nil {
+		return ""
+	}
+	return c.algorithm
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"io"
+
+	"github.com/prn-tf/alexander-storage/internal/delta"
+	"github.com/prn-tf/alexander-storage/internal/repository"
+	"github.com/prn-tf/alexander-storage/internal/storage"
+)
+
+// ChunkIndexSource exposes every blob tracked by the blob repository to a
+// chunk index rebuild, reading content from the storage backend.
+type ChunkIndexSource struct {
+	blobRepo repository.BlobRepository
+	storage  storage.Backend
+}
+
+// NewChunkIndexSource creates a blob source for delta.Indexer.Rebuild.
+func NewChunkIndexSource(blobRepo repository.BlobRepository, backend storage.Backend) *ChunkIndexSource {
+	return &ChunkIndexSource{
+		blobRepo: blobRepo,
+		storage:  backend,
+	}
+}
+
+// ListBlobHashes implements delta.BlobSource interface.
+func (s *ChunkIndexSource) ListBlobHashes(ctx context.Context, after string, limit int) ([]string, error) {
+	blobs, err := s.blobRepo.ListAfter(ctx, after, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([]string, len(blobs))
+	for i, blob := range blobs {
+		hashes[i] = blob.ContentHash
+	}
+	return hashes, nil
+}
+
+// Open implements delta.BlobSource interface. Encrypted blobs are read
+// through the scheme recorded in their metadata, as for GetObject.
+func (s *ChunkIndexSource) Open(ctx context.Context, contentHash string) (io.ReadCloser, error) {
+	return retrieveBlob(ctx, s.storage, s.blobRepo, contentHash)
+}
+
+// Ensure ChunkIndexSource implements delta.BlobSource
+var _ delta.BlobSource = (*ChunkIndexSource)(nil)
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/delta"
+	"github.com/prn-tf/alexander-storage/internal/repository/sqlite"
+)
+
+func TestChunkIndexSource_RebuildIndexesStoredBlobs(t *testing.T) {
+	ctx := context.Background()
+	dir, ownerID := setupMultipartRestart(t)
+	inst := startMultipartInstance(t, dir)
+	defer inst.db.Close()
+
+	data := make([]byte, 1024*1024)
+	_, err := rand.Read(data)
+	require.NoError(t, err)
+	edited := append(append([]byte{}, data...), []byte("appended")...)
+
+	for key, content := range map[string][]byte{"a.bin": data, "b.bin": edited, "c.txt": []byte("tiny")} {
+		_, err := inst.objects.PutObject(ctx, PutObjectInput{
+			BucketName: "uploads",
+			Key:        key,
+			Body:       bytes.NewReader(content),
+			Size:       int64(len(content)),
+			OwnerID:    ownerID,
+		})
+		require.NoError(t, err)
+	}
+
+	store := delta.NewMemoryChunkStore()
+	indexer := delta.NewIndexer(delta.NewFastCDCDefault(), store)
+	source := NewChunkIndexSource(sqlite.NewBlobRepository(inst.db), inst.storage)
+
+	result, err := indexer.Rebuild(ctx, source, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.BlobsIndexed)
+	assert.Equal(t, 0, result.BlobsFailed)
+
+	// The edited copy reuses the chunks of the original
+	require.NotNil(t, result.Stats)
+	assert.Equal(t, int64(2*len(data)+len("appended")+len("tiny")), result.Stats.LogicalBytes)
+	assert.Less(t, result.Stats.StoredBytes, result.Stats.LogicalBytes)
+	assert.Positive(t, result.Stats.ReusedReferences())
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/rs/zerolog"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/storage"
+)
+
+// SizeMismatchPolicy decides what happens when an upload body is not as long
+// as its declared Content-Length.
+type SizeMismatchPolicy string
+
+const (
+	// SizeMismatchStrict rejects the upload, so a stored object is always
+	// exactly what the client declared.
+	SizeMismatchStrict SizeMismatchPolicy = "strict"
+
+	// SizeMismatchLenient stores the bytes actually received and records
+	// their size, for clients that misreport the length. A body that ends
+	// early is treated as complete.
+	SizeMismatchLenient SizeMismatchPolicy = "lenient"
+)
+
+// receivedBody counts the bytes read from an upload body under the lenient
+// policy. It ends the body at an unexpected EOF instead of failing.
+type receivedBody struct {
+	reader io.Reader
+	n      int64
+}
+
+// Read implements io.Reader.
+func (b *receivedBody) Read(p []byte) (int, error) {
+	n, err := b.reader.Read(p)
+	b.n += int64(n)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	return n, err
+}
+
+// prepareBody returns the reader to store an upload body from and the size
+// the storage backend should verify it against. Under the lenient policy the
+// body is counted instead of verified; received reports its size afterwards.
+func (p SizeMismatchPolicy) prepareBody(body io.Reader, declared int64) (reader io.Reader, expected int64, received *receivedBody) {
+	if p != SizeMismatchLenient {
+		return body, declared, nil
+	}
+	received = &receivedBody{reader: body}
+	return received, 0, received
+}
+
+// receivedSize returns the size to persist for an upload body stored with
+// prepareBody, logging a warning when it differs from the declared size.
+func receivedSize(logger zerolog.Logger, key string, declared int64, received *receivedBody) int64 {
+	if received == nil || received.n == declared {
+		return declared
+	}
+	logger.Warn().
+		Str("key", key).
+		Int64("declared_size", declared).
+		Int64("received_size", received.n).
+		Msg("upload body does not match Content-Length, storing the received bytes")
+	return received.n
+}
+
+// storeBodyError converts a failure to store an upload body into a service
+// error. A body that does not match its declared size is the client's fault.
+func storeBodyError(err error) error {
+	if errors.Is(err, storage.ErrSizeMismatch) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: %v", domain.ErrIncompleteBody, err)
+	}
+	return fmt.Errorf("%w: %v", ErrInternalError, err)
+}
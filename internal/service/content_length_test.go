@@ -0,0 +1,181 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/repository/sqlite"
+)
+
+// sizeMismatchCases are bodies whose declared size is off from their actual size.
+var sizeMismatchCases = []struct {
+	name  string
+	delta int64
+}{
+	{name: "over-reported", delta: 10},
+	{name: "under-reported", delta: -10},
+}
+
+func TestPutObject_SizeMismatchPolicy(t *testing.T) {
+	ctx := context.Background()
+	content := bytes.Repeat([]byte("payload "), 128)
+
+	for _, tc := range sizeMismatchCases {
+		t.Run(tc.name+"/strict", func(t *testing.T) {
+			dir, ownerID := setupMultipartRestart(t)
+			inst := startMultipartInstance(t, dir)
+			defer inst.db.Close()
+
+			_, err := inst.objects.PutObject(ctx, PutObjectInput{
+				BucketName: "uploads",
+				Key:        "data.bin",
+				Body:       bytes.NewReader(content),
+				Size:       int64(len(content)) + tc.delta,
+				OwnerID:    ownerID,
+			})
+			require.ErrorIs(t, err, domain.ErrIncompleteBody)
+
+			_, err = sqlite.NewObjectRepository(inst.db).GetByKey(ctx, 1, "data.bin")
+			assert.ErrorIs(t, err, domain.ErrObjectNotFound)
+		})
+
+		t.Run(tc.name+"/lenient", func(t *testing.T) {
+			dir, ownerID := setupMultipartRestart(t)
+			inst := startMultipartInstance(t, dir)
+			defer inst.db.Close()
+			inst.objects.SetSizeMismatchPolicy(SizeMismatchLenient)
+
+			_, err := inst.objects.PutObject(ctx, PutObjectInput{
+				BucketName: "uploads",
+				Key:        "data.bin",
+				Body:       bytes.NewReader(content),
+				Size:       int64(len(content)) + tc.delta,
+				OwnerID:    ownerID,
+			})
+			require.NoError(t, err)
+
+			// The received size is persisted and served
+			obj, err := sqlite.NewObjectRepository(inst.db).GetByKey(ctx, 1, "data.bin")
+			require.NoError(t, err)
+			assert.Equal(t, int64(len(content)), obj.Size)
+
+			blob, err := sqlite.NewBlobRepository(inst.db).GetByHash(ctx, *obj.ContentHash)
+			require.NoError(t, err)
+			assert.Equal(t, int64(len(content)), blob.Size)
+
+			got, err := inst.objects.GetObject(ctx, GetObjectInput{BucketName: "uploads", Key: "data.bin", OwnerID: ownerID})
+			require.NoError(t, err)
+			defer got.Body.Close()
+			assert.Equal(t, int64(len(content)), got.ContentLength)
+			data, err := io.ReadAll(got.Body)
+			require.NoError(t, err)
+			assert.Equal(t, content, data)
+		})
+	}
+}
+
+func TestPutObject_LenientAcceptsTruncatedBody(t *testing.T) {
+	ctx := context.Background()
+	dir, ownerID := setupMultipartRestart(t)
+	inst := startMultipartInstance(t, dir)
+	defer inst.db.Close()
+	inst.objects.SetSizeMismatchPolicy(SizeMismatchLenient)
+
+	// An HTTP body that ends before its Content-Length fails with ErrUnexpectedEOF
+	content := []byte("cut short")
+	body := io.MultiReader(bytes.NewReader(content), iotest.ErrReader(io.ErrUnexpectedEOF))
+
+	_, err := inst.objects.PutObject(ctx, PutObjectInput{
+		BucketName: "uploads",
+		Key:        "short.bin",
+		Body:       body,
+		Size:       100,
+		OwnerID:    ownerID,
+	})
+	require.NoError(t, err)
+
+	obj, err := sqlite.NewObjectRepository(inst.db).GetByKey(ctx, 1, "short.bin")
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(content)), obj.Size)
+}
+
+func TestPutObject_LenientSSEC(t *testing.T) {
+	ctx := context.Background()
+	inst, ownerID := setupSSECBucket(t)
+	inst.objects.SetSizeMismatchPolicy(SizeMismatchLenient)
+	key := newTestCustomerKey(t, 0x07)
+
+	content := bytes.Repeat([]byte("sealed "), 100)
+	_, err := inst.objects.PutObject(ctx, PutObjectInput{
+		BucketName:     "secrets",
+		Key:            "sealed.bin",
+		Body:           bytes.NewReader(content),
+		Size:           int64(len(content)) + 50,
+		OwnerID:        ownerID,
+		SSECustomerKey: key,
+	})
+	require.NoError(t, err)
+
+	data := readSSECObject(t, inst.objects, GetObjectInput{
+		BucketName:     "secrets",
+		Key:            "sealed.bin",
+		OwnerID:        ownerID,
+		SSECustomerKey: key,
+	})
+	assert.Equal(t, content, data)
+}
+
+func TestUploadPart_SizeMismatchPolicy(t *testing.T) {
+	ctx := context.Background()
+	content := bytes.Repeat([]byte("p"), 1024)
+
+	for _, tc := range sizeMismatchCases {
+		for _, policy := range []SizeMismatchPolicy{SizeMismatchStrict, SizeMismatchLenient} {
+			t.Run(tc.name+"/"+string(policy), func(t *testing.T) {
+				dir, ownerID := setupMultipartRestart(t)
+				inst := startMultipartInstance(t, dir)
+				defer inst.db.Close()
+				inst.multipart.SetSizeMismatchPolicy(policy)
+
+				initiated, err := inst.multipart.InitiateMultipartUpload(ctx, InitiateMultipartUploadInput{
+					BucketName: "uploads",
+					Key:        "video.bin",
+					OwnerID:    ownerID,
+				})
+				require.NoError(t, err)
+
+				_, err = inst.multipart.UploadPart(ctx, UploadPartInput{
+					BucketName: "uploads",
+					Key:        "video.bin",
+					UploadID:   initiated.UploadID,
+					PartNumber: 1,
+					Body:       bytes.NewReader(content),
+					Size:       int64(len(content)) + tc.delta,
+					OwnerID:    ownerID,
+				})
+				if policy == SizeMismatchStrict {
+					require.ErrorIs(t, err, domain.ErrIncompleteBody)
+					return
+				}
+				require.NoError(t, err)
+
+				parts, err := inst.multipart.ListParts(ctx, ListPartsInput{
+					BucketName: "uploads",
+					Key:        "video.bin",
+					UploadID:   initiated.UploadID,
+					OwnerID:    ownerID,
+				})
+				require.NoError(t, err)
+				require.Len(t, parts.Parts, 1)
+				assert.Equal(t, int64(len(content)), parts.Parts[0].Size)
+			})
+		}
+	}
+}
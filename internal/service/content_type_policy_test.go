@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/repository/sqlite"
+)
+
+// setupContentTypeBucket creates a bucket named "uploads" with the given policy.
+func setupContentTypeBucket(t *testing.T, policy *domain.ContentTypePolicy) (*ObjectService, int64) {
+	t.Helper()
+	ctx := context.Background()
+
+	inst := startMultipartInstance(t, t.TempDir())
+	t.Cleanup(func() { inst.db.Close() })
+
+	user := domain.NewUser("tester", "tester@example.com", "hash")
+	require.NoError(t, sqlite.NewUserRepository(inst.db).Create(ctx, user))
+
+	buckets := NewBucketService(sqlite.NewBucketRepository(inst.db), zerolog.Nop())
+	_, err := buckets.CreateBucket(ctx, CreateBucketInput{Name: "uploads", OwnerID: user.ID})
+	require.NoError(t, err)
+	require.NoError(t, buckets.PutBucketContentTypePolicy(ctx, PutBucketContentTypePolicyInput{
+		Name:    "uploads",
+		OwnerID: user.ID,
+		Policy:  policy,
+	}))
+
+	return inst.objects, user.ID
+}
+
+func putTypedObject(svc *ObjectService, key, contentType string, ownerID int64) error {
+	body := "payload"
+	_, err := svc.PutObject(context.Background(), PutObjectInput{
+		BucketName:  "uploads",
+		Key:         key,
+		Body:        strings.NewReader(body),
+		Size:        int64(len(body)),
+		ContentType: contentType,
+		OwnerID:     ownerID,
+	})
+	return err
+}
+
+func TestContentTypePolicy_AllowsListedType(t *testing.T) {
+	svc, ownerID := setupContentTypeBucket(t, &domain.ContentTypePolicy{
+		Allow: []string{"image/*"},
+		Deny:  []string{"image/svg+xml"},
+	})
+
+	require.NoError(t, putTypedObject(svc, "cat.png", "image/png", ownerID))
+
+	out, err := svc.HeadObject(context.Background(), HeadObjectInput{BucketName: "uploads", Key: "cat.png", OwnerID: ownerID})
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", out.ContentType)
+	assert.Empty(t, out.ContentDisposition)
+}
+
+func TestContentTypePolicy_RejectsDisallowedType(t *testing.T) {
+	svc, ownerID := setupContentTypeBucket(t, &domain.ContentTypePolicy{
+		Allow: []string{"image/*"},
+		Deny:  []string{"image/svg+xml"},
+	})
+
+	// Denied explicitly, even though it matches the allow list
+	err := putTypedObject(svc, "logo.svg", "image/svg+xml", ownerID)
+	assert.ErrorIs(t, err, domain.ErrContentTypeNotAllowed)
+
+	// Not on the allow list; a missing type defaults to application/octet-stream
+	err = putTypedObject(svc, "page.html", "text/html; charset=utf-8", ownerID)
+	assert.ErrorIs(t, err, domain.ErrContentTypeNotAllowed)
+	err = putTypedObject(svc, "blob", "", ownerID)
+	assert.ErrorIs(t, err, domain.ErrContentTypeNotAllowed)
+
+	_, err = svc.HeadObject(context.Background(), HeadObjectInput{BucketName: "uploads", Key: "logo.svg", OwnerID: ownerID})
+	assert.ErrorIs(t, err, domain.ErrObjectNotFound)
+}
+
+func TestContentTypePolicy_ForcesAttachment(t *testing.T) {
+	svc, ownerID := setupContentTypeBucket(t, &domain.ContentTypePolicy{Serve: domain.ServeAttachment})
+
+	require.NoError(t, putTypedObject(svc, "index.html", "text/html", ownerID))
+
+	out, err := svc.GetObject(context.Background(), GetObjectInput{BucketName: "uploads", Key: "index.html", OwnerID: ownerID})
+	require.NoError(t, err)
+	out.Body.Close()
+	assert.Equal(t, "text/html", out.ContentType)
+	assert.Equal(t, "attachment", out.ContentDisposition)
+}
+
+func TestContentTypePolicy_SafeModeServesActiveContentAsText(t *testing.T) {
+	svc, ownerID := setupContentTypeBucket(t, &domain.ContentTypePolicy{Serve: domain.ServeSafe})
+
+	require.NoError(t, putTypedObject(svc, "index.html", "text/html; charset=utf-8", ownerID))
+	require.NoError(t, putTypedObject(svc, "cat.png", "image/png", ownerID))
+
+	out, err := svc.GetObject(context.Background(), GetObjectInput{BucketName: "uploads", Key: "index.html", OwnerID: ownerID})
+	require.NoError(t, err)
+	out.Body.Close()
+	assert.Equal(t, "text/plain", out.ContentType)
+
+	head, err := svc.HeadObject(context.Background(), HeadObjectInput{BucketName: "uploads", Key: "cat.png", OwnerID: ownerID})
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", head.ContentType)
+}
+
+func TestPutBucketContentTypePolicy_RejectsInvalidPattern(t *testing.T) {
+	repo := NewMockBucketRepository()
+	svc := NewBucketService(repo, zerolog.Nop())
+
+	err := svc.PutBucketContentTypePolicy(context.Background(), PutBucketContentTypePolicyInput{
+		Name:   "uploads",
+		Policy: &domain.ContentTypePolicy{Allow: []string{"image"}},
+	})
+	assert.ErrorIs(t, err, domain.ErrInvalidContentTypePolicy)
+}
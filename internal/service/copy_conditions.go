@@ -0,0 +1,66 @@
+package service
+
+import (
+	"strings"
+	"time"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+)
+
+// CopySourceConditions holds the x-amz-copy-source-if-* headers of a copy request.
+// Empty strings and nil times mean the header was not sent.
+type CopySourceConditions struct {
+	IfMatch           string
+	IfNoneMatch       string
+	IfModifiedSince   *time.Time
+	IfUnmodifiedSince *time.Time
+}
+
+// Evaluate checks the conditions against the source object's ETag and
+// last-modified time and returns domain.ErrPreconditionFailed if they fail.
+//
+// S3 combines the headers as follows:
+//   - if-match succeeding overrides a failing if-unmodified-since.
+//   - if-none-match failing fails the copy even if if-modified-since succeeds,
+//     and a succeeding if-none-match overrides if-modified-since.
+func (c CopySourceConditions) Evaluate(etag string, lastModified time.Time) error {
+	// HTTP dates have second precision
+	lastModified = lastModified.UTC().Truncate(time.Second)
+
+	if c.IfMatch != "" {
+		if !etagMatches(c.IfMatch, etag) {
+			return domain.ErrPreconditionFailed
+		}
+	} else if c.IfUnmodifiedSince != nil && lastModified.After(*c.IfUnmodifiedSince) {
+		return domain.ErrPreconditionFailed
+	}
+
+	if c.IfNoneMatch != "" {
+		if etagMatches(c.IfNoneMatch, etag) {
+			return domain.ErrPreconditionFailed
+		}
+	} else if c.IfModifiedSince != nil && !lastModified.After(*c.IfModifiedSince) {
+		return domain.ErrPreconditionFailed
+	}
+
+	return nil
+}
+
+// etagMatches reports whether etag is in the comma-separated header list.
+// The wildcard "*" matches any ETag; quotes and weak prefixes are ignored.
+func etagMatches(header, etag string) bool {
+	etag = normalizeETag(etag)
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || normalizeETag(candidate) == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeETag strips the weak validator prefix and surrounding quotes.
+func normalizeETag(etag string) string {
+	etag = strings.TrimPrefix(strings.TrimSpace(etag), "W/")
+	return strings.Trim(etag, `"`)
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/repository"
+)
+
+// CORSService handles bucket CORS configurations.
+type CORSService struct {
+	corsRepo   repository.BucketCORSRepository
+	bucketRepo repository.BucketRepository
+	logger     zerolog.Logger
+}
+
+// NewCORSService creates a new CORSService.
+func NewCORSService(
+	corsRepo repository.BucketCORSRepository,
+	bucketRepo repository.BucketRepository,
+	logger zerolog.Logger,
+) *CORSService {
+	return &CORSService{
+		corsRepo:   corsRepo,
+		bucketRepo: bucketRepo,
+		logger:     logger.With().Str("service", "cors").Logger(),
+	}
+}
+
+// PutBucketCORSInput contains the data needed to set a bucket CORS configuration.
+type PutBucketCORSInput struct {
+	BucketName string
+	OwnerID    int64
+	Rules      []domain.CORSRule
+}
+
+// PutBucketCORS validates and stores the CORS configuration of a bucket,
+// replacing any existing one. Invalid rules are rejected with
+// domain.ErrInvalidCORSConfiguration.
+func (s *CORSService) PutBucketCORS(ctx context.Context, input PutBucketCORSInput) error {
+	bucket, err := s.ownedBucket(ctx, input.BucketName, input.OwnerID)
+	if err != nil {
+		return err
+	}
+
+	if err := domain.ValidateCORSRules(input.Rules); err != nil {
+		return err
+	}
+
+	if err := s.corsRepo.Put(ctx, domain.NewBucketCORS(bucket.ID, input.Rules)); err != nil {
+		return fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+
+	s.logger.Info().Str("bucket", bucket.Name).Int("rules", len(input.Rules)).Msg("bucket CORS configuration replaced")
+
+	return nil
+}
+
+// GetBucketCORS returns the CORS rules of a bucket. An ownerID of 0 skips
+// the ownership check, for answering cross-origin requests.
+func (s *CORSService) GetBucketCORS(ctx context.Context, bucketName string, ownerID int64) ([]domain.CORSRule, error) {
+	bucket, err := s.ownedBucket(ctx, bucketName, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	cors, err := s.corsRepo.Get(ctx, bucket.ID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrNoSuchCORSConfiguration
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+
+	return cors.Rules, nil
+}
+
+// DeleteBucketCORS removes the CORS configuration of a bucket.
+func (s *CORSService) DeleteBucketCORS(ctx context.Context, bucketName string, ownerID int64) error {
+	bucket, err := s.ownedBucket(ctx, bucketName, ownerID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.corsRepo.Delete(ctx, bucket.ID); err != nil {
+		return fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+
+	s.logger.Info().Str("bucket", bucket.Name).Msg("bucket CORS configuration deleted")
+
+	return nil
+}
+
+// ownedBucket returns the named bucket after checking it belongs to ownerID.
+func (s *CORSService) ownedBucket(ctx context.Context, bucketName string, ownerID int64) (*domain.Bucket, error) {
+	bucket, err := s.bucketRepo.GetByName(ctx, bucketName)
+	if err != nil {
+		if errors.Is(err, domain.ErrBucketNotFound) {
+			return nil, domain.ErrBucketNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+	if !bucketAccessAllowed(ctx, bucket, ownerID) {
+		return nil, ErrBucketAccessDenied
+	}
+	return bucket, nil
+}
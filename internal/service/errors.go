@@ -30,6 +30,22 @@ var (
 	// Bucket errors
 	ErrBucketAccessDenied      = errors.New("access denied to bucket")
 	ErrInvalidVersioningStatus = errors.New("invalid versioning status: must be Enabled or Suspended")
+	ErrInvalidMaxVersions      = errors.New("invalid max versions per key: must not be negative")
+	ErrInvalidObjectOwnership  = errors.New("invalid object ownership: must be BucketOwnerEnforced, BucketOwnerPreferred or ObjectWriter")
+	ErrInvalidBucketACL        = errors.New("invalid canned ACL: must be private, public-read or public-read-write")
+	ErrACLNotSupported         = errors.New("access control lists are disabled for this bucket")
+
+	// Object errors
+	ErrObjectACLNotImplemented = errors.New("object ACLs are not supported")
+	ErrInvalidTaggingDirective = errors.New("invalid tagging directive: must be COPY or REPLACE")
+
+	// SSE-C errors
+	ErrSSECustomerKeyRequired    = errors.New("the object was stored using a customer-provided key; the key must be supplied")
+	ErrSSECustomerKeyMismatch    = errors.New("the customer-provided key does not match the key the object was stored with")
+	ErrSSECustomerKeyNotExpected = errors.New("the object was not stored using a customer-provided key")
+
+	// Batch ingestion errors
+	ErrMalformedBatch = errors.New("malformed batch stream")
 
 	// Session errors
 	ErrSessionNotFound = errors.New("session not found")
@@ -40,6 +56,8 @@ var (
 	ErrLifecycleRuleNotFound      = errors.New("lifecycle rule not found")
 	ErrLifecycleRuleAlreadyExists = errors.New("lifecycle rule already exists")
 	ErrInvalidLifecycleRule       = errors.New("invalid lifecycle rule")
+	ErrNoSuchLifecycleConfig      = errors.New("the lifecycle configuration does not exist")
+	ErrTooManyLifecycleRules      = errors.New("a lifecycle configuration can have at most 1000 rules")
 
 	// General errors
 	ErrEncryptionFailed = errors.New("encryption failed")
@@ -3,11 +3,13 @@ package service
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"time"
 
 	"github.com/rs/zerolog"
 
+	"github.com/prn-tf/alexander-storage/internal/domain"
 	"github.com/prn-tf/alexander-storage/internal/lock"
 	"github.com/prn-tf/alexander-storage/internal/metrics"
 	"github.com/prn-tf/alexander-storage/internal/repository"
@@ -16,20 +18,30 @@ import (
 
 // GarbageCollector handles cleanup of orphan blobs.
 type GarbageCollector struct {
-	blobRepo repository.BlobRepository
-	storage  storage.Backend
-	locker   lock.Locker
-	metrics  *metrics.Metrics
-	logger   zerolog.Logger
-	config   GCConfig
+	blobRepo  repository.BlobRepository
+	storage   storage.Backend
+	retention *RetentionService
+	locker    lock.Locker
+	metrics   *metrics.Metrics
+	logger    zerolog.Logger
+	config    GCConfig
 
 	// Control
 	mu       sync.Mutex
 	running  bool
 	stopChan chan struct{}
 	doneChan chan struct{}
+
+	// Emergency runs started by TriggerEmergencyRun
+	emergencyMu      sync.Mutex
+	emergencyRunning bool
+	lastEmergency    time.Time
 }
 
+// emergencyGCInterval is the minimum time between emergency runs, so a burst
+// of writes rejected for lack of space starts a single run.
+const emergencyGCInterval = time.Minute
+
 // GCConfig contains garbage collection configuration.
 type GCConfig struct {
 	// Enabled determines if GC runs automatically.
@@ -47,6 +59,11 @@ type GCConfig struct {
 
 	// DryRun logs what would be deleted without actually deleting.
 	DryRun bool
+
+	// SweepStorage also walks the storage backend for blobs that no blob
+	// row tracks, such as those left by uploads that failed after storing
+	// content, and deletes them once they are older than GracePeriod.
+	SweepStorage bool
 }
 
 // DefaultGCConfig returns sensible defaults.
@@ -61,23 +78,27 @@ func DefaultGCConfig() GCConfig {
 }
 
 // NewGarbageCollector creates a new garbage collector.
+// If retention is not nil, every run first purges soft-deleted objects past
+// their retention period.
 func NewGarbageCollector(
 	blobRepo repository.BlobRepository,
 	storage storage.Backend,
+	retention *RetentionService,
 	locker lock.Locker,
 	m *metrics.Metrics,
 	logger zerolog.Logger,
 	config GCConfig,
 ) *GarbageCollector {
 	return &GarbageCollector{
-		blobRepo: blobRepo,
-		storage:  storage,
-		locker:   locker,
-		metrics:  m,
-		logger:   logger.With().Str("service", "gc").Logger(),
-		config:   config,
-		stopChan: make(chan struct{}),
-		doneChan: make(chan struct{}),
+		blobRepo:  blobRepo,
+		storage:   storage,
+		retention: retention,
+		locker:    locker,
+		metrics:   m,
+		logger:    logger.With().Str("service", "gc").Logger(),
+		config:    config,
+		stopChan:  make(chan struct{}),
+		doneChan:  make(chan struct{}),
 	}
 }
 
@@ -143,6 +164,37 @@ func (gc *GarbageCollector) RunOnce(ctx context.Context) GCResult {
 	return gc.runWithContext(ctx)
 }
 
+// TriggerEmergencyRun starts a garbage collection run in the background to
+// free space after a write found storage full. It returns immediately and
+// does nothing while an emergency run is in progress or one started less
+// than emergencyGCInterval ago.
+func (gc *GarbageCollector) TriggerEmergencyRun() {
+	gc.emergencyMu.Lock()
+	if gc.emergencyRunning || time.Since(gc.lastEmergency) < emergencyGCInterval {
+		gc.emergencyMu.Unlock()
+		return
+	}
+	gc.emergencyRunning = true
+	gc.lastEmergency = time.Now()
+	gc.emergencyMu.Unlock()
+
+	gc.logger.Warn().Msg("Storage is full, starting emergency garbage collection")
+
+	go func() {
+		defer func() {
+			gc.emergencyMu.Lock()
+			gc.emergencyRunning = false
+			gc.emergencyMu.Unlock()
+		}()
+
+		result := gc.runWithContext(context.Background())
+		gc.logger.Warn().
+			Int("blobs_deleted", result.BlobsDeleted+result.UntrackedBlobsDeleted).
+			Int64("bytes_freed", result.BytesFreed).
+			Msg("Emergency garbage collection finished")
+	}()
+}
+
 // runOnce is called by the scheduler loop.
 func (gc *GarbageCollector) runOnce() {
 	ctx := context.Background()
@@ -151,9 +203,20 @@ func (gc *GarbageCollector) runOnce() {
 
 // GCResult contains the result of a garbage collection run.
 type GCResult struct {
+	// ObjectsPurged is the number of soft-deleted objects purged.
+	ObjectsPurged int
+
 	// BlobsDeleted is the number of blobs deleted.
 	BlobsDeleted int
 
+	// BlobsSkipped is the number of blobs with a zero ref count that were
+	// kept because an object version or upload part still references them.
+	BlobsSkipped int
+
+	// UntrackedBlobsDeleted is the number of blobs without a blob row
+	// deleted from storage by the storage sweep.
+	UntrackedBlobsDeleted int
+
 	// BytesFreed is the total bytes freed.
 	BytesFreed int64
 
@@ -199,6 +262,21 @@ func (gc *GarbageCollector) runWithContext(ctx context.Context) GCResult {
 		}
 	}()
 
+	// Purge soft-deleted objects first; the blobs they release become
+	// orphans and are collected once their grace period has passed
+	if gc.retention != nil && !gc.config.DryRun {
+		purged, err := gc.retention.PurgeExpired(ctx)
+		if err != nil {
+			gc.logger.Error().Err(err).Msg("Failed to purge soft-deleted objects")
+			result.Errors++
+		}
+		result.ObjectsPurged = purged
+	}
+
+	if gc.config.SweepStorage {
+		gc.sweepStorage(ctx, &result)
+	}
+
 	// Get orphan blobs
 	orphans, err := gc.blobRepo.ListOrphans(ctx, gc.config.GracePeriod, gc.config.BatchSize)
 	if err != nil {
@@ -228,6 +306,25 @@ func (gc *GarbageCollector) runWithContext(ctx context.Context) GCResult {
 
 	// Process each orphan blob
 	for _, blob := range orphans {
+		// A ref count can drift from the rows it counts; content still
+		// referenced, e.g. by a part of an upload in flight, is kept
+		inUse, err := gc.blobInUse(ctx, blob.ContentHash)
+		if err != nil {
+			gc.logger.Error().
+				Err(err).
+				Str("content_hash", blob.ContentHash).
+				Msg("Failed to check blob references")
+			result.Errors++
+			continue
+		}
+		if inUse {
+			gc.logger.Warn().
+				Str("content_hash", blob.ContentHash).
+				Msg("Orphan blob is still referenced, skipping")
+			result.BlobsSkipped++
+			continue
+		}
+
 		if gc.config.DryRun {
 			gc.logger.Info().
 				Str("content_hash", blob.ContentHash).
@@ -292,7 +389,9 @@ func (gc *GarbageCollector) runWithContext(ctx context.Context) GCResult {
 	}
 
 	gc.logger.Info().
+		Int("objects_purged", result.ObjectsPurged).
 		Int("blobs_deleted", result.BlobsDeleted).
+		Int("blobs_skipped", result.BlobsSkipped).
 		Int64("bytes_freed", result.BytesFreed).
 		Int("errors", result.Errors).
 		Dur("duration", result.Duration).
@@ -301,6 +400,117 @@ func (gc *GarbageCollector) runWithContext(ctx context.Context) GCResult {
 	return result
 }
 
+// blobInUse reports whether a blob gained references since it was listed as
+// an orphan, or is referenced despite its ref count.
+func (gc *GarbageCollector) blobInUse(ctx context.Context, contentHash string) (bool, error) {
+	refCount, err := gc.blobRepo.GetRefCount(ctx, contentHash)
+	if err != nil && !errors.Is(err, domain.ErrBlobNotFound) {
+		return false, err
+	}
+	if refCount > 0 {
+		return true, nil
+	}
+	return gc.blobRepo.IsReferenced(ctx, contentHash)
+}
+
+// errSweepBatchDone stops a storage sweep that deleted a full batch.
+var errSweepBatchDone = errors.New("sweep batch done")
+
+// sweepStorage deletes blobs in storage that no blob row tracks and nothing
+// references, once they are older than the grace period. Up to BatchSize
+// blobs are deleted per run.
+func (gc *GarbageCollector) sweepStorage(ctx context.Context, result *GCResult) {
+	sweeper, ok := blobSweeper(gc.storage)
+	if !ok {
+		gc.logger.Warn().Msg("Storage backend cannot list its blobs, skipping storage sweep")
+		return
+	}
+
+	cutoff := time.Now().Add(-gc.config.GracePeriod)
+	err := sweeper.WalkBlobs(ctx, func(contentHash string, size int64, modTime time.Time) error {
+		// Recent blobs may belong to uploads that have not recorded them yet
+		if modTime.After(cutoff) {
+			return nil
+		}
+
+		tracked, err := gc.blobRepo.Exists(ctx, contentHash)
+		if err != nil {
+			return err
+		}
+		if tracked {
+			return nil // collected through its ref count
+		}
+		referenced, err := gc.blobRepo.IsReferenced(ctx, contentHash)
+		if err != nil {
+			return err
+		}
+		if referenced {
+			gc.logger.Warn().
+				Str("content_hash", contentHash).
+				Msg("Untracked blob is still referenced, skipping")
+			result.BlobsSkipped++
+			return nil
+		}
+
+		if gc.config.DryRun {
+			gc.logger.Info().
+				Str("content_hash", contentHash).
+				Int64("size", size).
+				Msg("[DRY RUN] Would delete untracked blob")
+		} else {
+			deleted, err := sweeper.DeleteIfUnmodifiedSince(ctx, contentHash, cutoff)
+			if err != nil && !storage.IsNotFound(err) {
+				gc.logger.Error().
+					Err(err).
+					Str("content_hash", contentHash).
+					Msg("Failed to delete untracked blob from storage")
+				result.Errors++
+				return nil
+			}
+			if !deleted {
+				return nil // stored again or deleted since it was listed
+			}
+			gc.logger.Debug().
+				Str("content_hash", contentHash).
+				Int64("size", size).
+				Msg("Deleted untracked blob")
+		}
+
+		result.UntrackedBlobsDeleted++
+		result.BytesFreed += size
+		if result.UntrackedBlobsDeleted >= gc.config.BatchSize {
+			return errSweepBatchDone
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errSweepBatchDone) {
+		gc.logger.Error().Err(err).Msg("Failed to sweep storage")
+		result.Errors++
+	}
+
+	if result.UntrackedBlobsDeleted > 0 {
+		gc.logger.Info().
+			Int("count", result.UntrackedBlobsDeleted).
+			Bool("dry_run", gc.config.DryRun).
+			Msg("Swept untracked blobs from storage")
+	}
+}
+
+// blobSweeper returns the storage.BlobSweeper behind backend, looking
+// through decorators such as storage.RetryingBackend.
+func blobSweeper(backend storage.Backend) (storage.BlobSweeper, bool) {
+	for {
+		if sweeper, ok := backend.(storage.BlobSweeper); ok {
+			return sweeper, true
+		}
+		wrapper, ok := backend.(interface{ Unwrap() storage.Backend })
+		if !ok {
+			return nil, false
+		}
+		backend = wrapper.Unwrap()
+	}
+}
+
 // CleanupExpiredMultipartUploads cleans up expired multipart uploads.
 // This is called separately from blob GC.
 func (gc *GarbageCollector) CleanupExpiredMultipartUploads(ctx context.Context, multipartRepo repository.MultipartUploadRepository) (int64, error) {
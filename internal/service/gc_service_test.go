@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/lock"
+	"github.com/prn-tf/alexander-storage/internal/pkg/crypto"
+	"github.com/prn-tf/alexander-storage/internal/repository/sqlite"
+)
+
+// newTestGarbageCollector returns a collector over inst whose grace and
+// retention periods have already passed for everything written so far.
+func newTestGarbageCollector(inst *multipartInstance, sweep bool) *GarbageCollector {
+	objectRepo := sqlite.NewObjectRepository(inst.db)
+	blobRepo := sqlite.NewBlobRepository(inst.db)
+	retention := NewRetentionService(objectRepo, blobRepo, sqlite.NewBucketRepository(inst.db), zerolog.Nop(), RetentionConfig{
+		Retention: -time.Minute,
+	})
+	return NewGarbageCollector(blobRepo, inst.storage, retention, lock.NewNoOpLocker(), nil, zerolog.Nop(), GCConfig{
+		Interval:     time.Hour,
+		GracePeriod:  -time.Minute,
+		BatchSize:    100,
+		SweepStorage: sweep,
+	})
+}
+
+func TestGarbageCollector_KeepsBlobSharedByAnotherObject(t *testing.T) {
+	ctx := context.Background()
+	dir, ownerID := setupMultipartRestart(t)
+	inst := startMultipartInstance(t, dir)
+	defer inst.db.Close()
+
+	putListKeys(t, inst, ownerID, "a.txt", "b.txt")
+	_, err := inst.objects.DeleteObject(ctx, DeleteObjectInput{BucketName: "uploads", Key: "a.txt", OwnerID: ownerID})
+	require.NoError(t, err)
+
+	result := newTestGarbageCollector(inst, false).RunOnce(ctx)
+	assert.Equal(t, 1, result.ObjectsPurged)
+	assert.Zero(t, result.BlobsDeleted)
+	assert.Zero(t, result.Errors)
+
+	out, err := inst.objects.GetObject(ctx, GetObjectInput{BucketName: "uploads", Key: "b.txt", OwnerID: ownerID})
+	require.NoError(t, err)
+	data, err := io.ReadAll(out.Body)
+	out.Body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "x", string(data))
+
+	// Deleting the last object releases the blob
+	_, err = inst.objects.DeleteObject(ctx, DeleteObjectInput{BucketName: "uploads", Key: "b.txt", OwnerID: ownerID})
+	require.NoError(t, err)
+
+	result = newTestGarbageCollector(inst, false).RunOnce(ctx)
+	assert.Equal(t, 1, result.ObjectsPurged)
+	assert.Equal(t, 1, result.BlobsDeleted)
+	assert.Zero(t, result.Errors)
+}
+
+func TestGarbageCollector_SkipsReferencedBlobWithZeroRefCount(t *testing.T) {
+	ctx := context.Background()
+	dir, ownerID := setupMultipartRestart(t)
+	inst := startMultipartInstance(t, dir)
+	defer inst.db.Close()
+
+	putListKeys(t, inst, ownerID, "a.txt")
+
+	// A ref count that drifted to zero must not cost the object its content
+	_, err := inst.db.ExecContext(ctx, `UPDATE blobs SET ref_count = 0`)
+	require.NoError(t, err)
+
+	result := newTestGarbageCollector(inst, false).RunOnce(ctx)
+	assert.Zero(t, result.BlobsDeleted)
+	assert.Equal(t, 1, result.BlobsSkipped)
+
+	get, err := inst.objects.GetObject(ctx, GetObjectInput{BucketName: "uploads", Key: "a.txt", OwnerID: ownerID})
+	require.NoError(t, err)
+	get.Body.Close()
+}
+
+func TestGarbageCollector_SweepsUntrackedBlobs(t *testing.T) {
+	ctx := context.Background()
+	dir, ownerID := setupMultipartRestart(t)
+	inst := startMultipartInstance(t, dir)
+	defer inst.db.Close()
+
+	putListKeys(t, inst, ownerID, "a.txt")
+
+	// A part of an upload in flight is tracked and referenced
+	initiated, err := inst.multipart.InitiateMultipartUpload(ctx, InitiateMultipartUploadInput{
+		BucketName: "uploads",
+		Key:        "video.bin",
+		OwnerID:    ownerID,
+	})
+	require.NoError(t, err)
+	uploadTestPart(t, inst.multipart, initiated.UploadID, 1, []byte("part one"), ownerID)
+
+	// Content stored by an upload that failed before recording it
+	untracked, err := inst.storage.Store(ctx, strings.NewReader("left behind"), 11)
+	require.NoError(t, err)
+
+	// Stored too recently to tell apart from an upload still in progress
+	gc := newTestGarbageCollector(inst, true)
+	gc.config.GracePeriod = time.Hour
+	result := gc.RunOnce(ctx)
+	assert.Zero(t, result.UntrackedBlobsDeleted)
+	assertBlobStored(t, inst, untracked, true)
+
+	result = newTestGarbageCollector(inst, true).RunOnce(ctx)
+	assert.Equal(t, 1, result.UntrackedBlobsDeleted)
+	assert.Zero(t, result.BlobsDeleted)
+	assert.Zero(t, result.Errors)
+	assertBlobStored(t, inst, untracked, false)
+
+	get, err := inst.objects.GetObject(ctx, GetObjectInput{BucketName: "uploads", Key: "a.txt", OwnerID: ownerID})
+	require.NoError(t, err)
+	get.Body.Close()
+	assertBlobStored(t, inst, crypto.SHA256Hex([]byte("part one")), true)
+}
+
+func assertBlobStored(t *testing.T, inst *multipartInstance, contentHash string, stored bool) {
+	t.Helper()
+
+	exists, err := inst.storage.Exists(context.Background(), contentHash)
+	require.NoError(t, err)
+	assert.Equal(t, stored, exists)
+}
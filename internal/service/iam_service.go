@@ -47,6 +47,16 @@ type CreateAccessKeyInput struct {
 	UserID      int64
 	Description string
 	ExpiresAt   *time.Time
+
+	// NamespaceBucket and NamespacePrefix optionally confine the key to a
+	// key prefix inside a shared bucket.
+	NamespaceBucket string
+	NamespacePrefix string
+
+	// RateLimitRPS and RateLimitBurst optionally override the server's
+	// request rate limit for the key. Zero uses the server default.
+	RateLimitRPS   float64
+	RateLimitBurst int
 }
 
 // CreateAccessKeyOutput contains the result of creating an access key.
@@ -109,6 +119,10 @@ func (s *IAMService) CreateAccessKey(ctx context.Context, input CreateAccessKeyI
 	accessKey := domain.NewAccessKey(input.UserID, accessKeyID, encryptedSecret)
 	accessKey.Description = input.Description
 	accessKey.ExpiresAt = input.ExpiresAt
+	accessKey.NamespaceBucket = input.NamespaceBucket
+	accessKey.NamespacePrefix = input.NamespacePrefix
+	accessKey.RateLimitRPS = input.RateLimitRPS
+	accessKey.RateLimitBurst = input.RateLimitBurst
 
 	if err := s.accessKeyRepo.Create(ctx, accessKey); err != nil {
 		s.logger.Error().Err(err).Str("access_key_id", accessKeyID).Msg("failed to create access key")
@@ -280,12 +294,16 @@ func (s *IAMService) VerifyAccessKey(ctx context.Context, accessKeyID string) (*
 	}
 
 	return &auth.AccessKeyInfo{
-		AccessKeyID: key.AccessKeyID,
-		SecretKey:   secretKey,
-		UserID:      key.UserID,
-		Username:    user.Username,
-		IsActive:    key.Status == domain.AccessKeyStatusActive,
-		ExpiresAt:   key.ExpiresAt,
+		AccessKeyID:     key.AccessKeyID,
+		SecretKey:       secretKey,
+		UserID:          key.UserID,
+		Username:        user.Username,
+		IsActive:        key.Status == domain.AccessKeyStatusActive,
+		ExpiresAt:       key.ExpiresAt,
+		NamespaceBucket: key.NamespaceBucket,
+		NamespacePrefix: key.NamespacePrefix,
+		RateLimitRPS:    key.RateLimitRPS,
+		RateLimitBurst:  key.RateLimitBurst,
 	}, nil
 }
 
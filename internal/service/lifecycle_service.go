@@ -3,6 +3,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -13,8 +14,15 @@ import (
 	"github.com/prn-tf/alexander-storage/internal/lock"
 	"github.com/prn-tf/alexander-storage/internal/metrics"
 	"github.com/prn-tf/alexander-storage/internal/repository"
+	"github.com/prn-tf/alexander-storage/internal/tiering"
 )
 
+// TierMover moves blobs between storage tiers.
+// tiering.TieringController satisfies it.
+type TierMover interface {
+	ForceMove(ctx context.Context, contentHash string, targetTier tiering.Tier) error
+}
+
 // LifecycleService handles object lifecycle rules and expiration.
 type LifecycleService struct {
 	lifecycleRepo repository.LifecycleRepository
@@ -24,7 +32,9 @@ type LifecycleService struct {
 	locker        lock.Locker
 	metrics       *metrics.Metrics
 	logger        zerolog.Logger
+	tierMover     TierMover
 	config        LifecycleConfig
+	now           func() time.Time
 
 	// Scheduler control
 	mu       sync.Mutex
@@ -78,11 +88,18 @@ func NewLifecycleService(
 		metrics:       m,
 		logger:        logger.With().Str("service", "lifecycle").Logger(),
 		config:        config,
+		now:           time.Now,
 		stopChan:      make(chan struct{}),
 		doneChan:      make(chan struct{}),
 	}
 }
 
+// SetTierMover enables lifecycle transitions. Without a mover, transition
+// rules are kept but not applied.
+func (s *LifecycleService) SetTierMover(mover TierMover) {
+	s.tierMover = mover
+}
+
 // CreateRuleInput contains data to create a lifecycle rule.
 type CreateRuleInput struct {
 	BucketName     string
@@ -174,6 +191,7 @@ func (s *LifecycleService) UpdateRule(ctx context.Context, ruleID int64, expirat
 
 	if expirationDays > 0 {
 		rule.ExpirationDays = &expirationDays
+		rule.ExpirationDate = nil
 	}
 	if status != "" {
 		rule.Status = domain.LifecycleStatus(status)
@@ -191,7 +209,7 @@ func (s *LifecycleService) UpdateRule(ctx context.Context, ruleID int64, expirat
 
 	s.logger.Info().
 		Int64("rule_id", ruleID).
-		Int("expiration_days", *rule.ExpirationDays).
+		Int("expiration_days", expirationDays).
 		Str("status", string(rule.Status)).
 		Msg("lifecycle rule updated")
 
@@ -238,6 +256,113 @@ func (s *LifecycleService) DeleteRuleByName(ctx context.Context, bucketName, rul
 	return ErrLifecycleRuleNotFound
 }
 
+// GetBucketLifecycle returns the lifecycle configuration of a bucket owned by
+// ownerID (0 skips the ownership check).
+func (s *LifecycleService) GetBucketLifecycle(ctx context.Context, bucketName string, ownerID int64) ([]*domain.LifecycleRule, error) {
+	bucket, err := s.ownedBucket(ctx, bucketName, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := s.lifecycleRepo.ListByBucket(ctx, bucket.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+	if len(rules) == 0 {
+		return nil, ErrNoSuchLifecycleConfig
+	}
+
+	return rules, nil
+}
+
+// PutBucketLifecycleInput contains data to replace the lifecycle configuration of a bucket.
+type PutBucketLifecycleInput struct {
+	BucketName string
+	OwnerID    int64
+	Rules      []*domain.LifecycleRule
+}
+
+// PutBucketLifecycle replaces the lifecycle configuration of a bucket.
+func (s *LifecycleService) PutBucketLifecycle(ctx context.Context, input PutBucketLifecycleInput) error {
+	if len(input.Rules) == 0 {
+		return fmt.Errorf("%w: at least one rule is required", ErrInvalidLifecycleRule)
+	}
+	if len(input.Rules) > domain.MaxLifecycleRules {
+		return ErrTooManyLifecycleRules
+	}
+
+	bucket, err := s.ownedBucket(ctx, input.BucketName, input.OwnerID)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool, len(input.Rules))
+	for _, rule := range input.Rules {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("%w: rule '%s'", ErrInvalidLifecycleRule, rule.RuleID)
+		}
+		if !rule.HasExpiration() && !rule.HasTransition() {
+			return fmt.Errorf("%w: rule '%s' has no expiration or transition", ErrInvalidLifecycleRule, rule.RuleID)
+		}
+		if seen[rule.RuleID] {
+			return fmt.Errorf("%w: rule ID '%s' is not unique", ErrInvalidLifecycleRule, rule.RuleID)
+		}
+		seen[rule.RuleID] = true
+	}
+
+	if err := s.lifecycleRepo.DeleteByBucket(ctx, bucket.ID); err != nil {
+		return fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+	now := time.Now().UTC()
+	for _, rule := range input.Rules {
+		rule.BucketID = bucket.ID
+		rule.CreatedAt = now
+		rule.UpdatedAt = now
+		if err := s.lifecycleRepo.Create(ctx, rule); err != nil {
+			s.logger.Error().Err(err).Str("bucket", input.BucketName).Str("rule_id", rule.RuleID).Msg("failed to create lifecycle rule")
+			return fmt.Errorf("%w: %v", ErrInternalError, err)
+		}
+	}
+
+	s.logger.Info().
+		Str("bucket", input.BucketName).
+		Int("rules", len(input.Rules)).
+		Msg("lifecycle configuration replaced")
+
+	return nil
+}
+
+// DeleteBucketLifecycle removes all lifecycle rules of a bucket.
+func (s *LifecycleService) DeleteBucketLifecycle(ctx context.Context, bucketName string, ownerID int64) error {
+	bucket, err := s.ownedBucket(ctx, bucketName, ownerID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.lifecycleRepo.DeleteByBucket(ctx, bucket.ID); err != nil {
+		return fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+
+	s.logger.Info().Str("bucket", bucketName).Msg("lifecycle configuration deleted")
+
+	return nil
+}
+
+// ownedBucket returns the named bucket after checking it belongs to ownerID.
+func (s *LifecycleService) ownedBucket(ctx context.Context, bucketName string, ownerID int64) (*domain.Bucket, error) {
+	bucket, err := s.bucketRepo.GetByName(ctx, bucketName)
+	if err != nil {
+		if errors.Is(err, domain.ErrBucketNotFound) {
+			return nil, domain.ErrBucketNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+	if !bucketAccessAllowed(ctx, bucket, ownerID) {
+		return nil, ErrBucketAccessDenied
+	}
+	return bucket, nil
+}
+
 // Start begins the lifecycle scheduler.
 func (s *LifecycleService) Start() {
 	s.mu.Lock()
@@ -295,12 +420,13 @@ func (s *LifecycleService) runLoop() {
 
 // LifecycleResult contains the result of a lifecycle evaluation run.
 type LifecycleResult struct {
-	ObjectsExpired   int
-	BytesFreed       int64
-	RulesEvaluated   int
-	BucketsProcessed int
-	Errors           int
-	Duration         time.Duration
+	ObjectsExpired      int
+	ObjectsTransitioned int
+	BytesFreed          int64
+	RulesEvaluated      int
+	BucketsProcessed    int
+	Errors              int
+	Duration            time.Duration
 }
 
 // RunOnce executes a single lifecycle evaluation run.
@@ -346,6 +472,12 @@ func (s *LifecycleService) runWithContext(ctx context.Context) LifecycleResult {
 		}
 	}()
 
+	// Objects with their own expiration time do not need a bucket rule
+	expired, bytes, errs := s.expireScheduledObjects(ctx)
+	result.ObjectsExpired += expired
+	result.BytesFreed += bytes
+	result.Errors += errs
+
 	// Get all enabled rules
 	rules, err := s.lifecycleRepo.ListAllEnabled(ctx)
 	if err != nil {
@@ -372,17 +504,19 @@ func (s *LifecycleService) runWithContext(ctx context.Context) LifecycleResult {
 
 	// Process each bucket
 	for bucketID, bucketRules := range rulesByBucket {
-		expired, bytes, errs := s.processBucketRules(ctx, bucketID, bucketRules)
+		expired, transitioned, bytes, errs := s.processBucketRules(ctx, bucketID, bucketRules)
 		result.ObjectsExpired += expired
+		result.ObjectsTransitioned += transitioned
 		result.BytesFreed += bytes
 		result.Errors += errs
 	}
 
 	result.Duration = time.Since(start)
 
-	if result.ObjectsExpired > 0 || result.Errors > 0 {
+	if result.ObjectsExpired > 0 || result.ObjectsTransitioned > 0 || result.Errors > 0 {
 		s.logger.Info().
 			Int("objects_expired", result.ObjectsExpired).
+			Int("objects_transitioned", result.ObjectsTransitioned).
 			Int64("bytes_freed", result.BytesFreed).
 			Int("rules_evaluated", result.RulesEvaluated).
 			Int("buckets_processed", result.BucketsProcessed).
@@ -400,12 +534,13 @@ func (s *LifecycleService) runWithContext(ctx context.Context) LifecycleResult {
 }
 
 // processBucketRules evaluates lifecycle rules for a single bucket.
-func (s *LifecycleService) processBucketRules(ctx context.Context, bucketID int64, rules []*domain.LifecycleRule) (expired int, bytesFreed int64, errors int) {
+// Expirations run first so that no object is moved just before it is deleted.
+func (s *LifecycleService) processBucketRules(ctx context.Context, bucketID int64, rules []*domain.LifecycleRule) (expired, transitioned int, bytesFreed int64, errors int) {
 	// Get bucket info for logging
 	bucket, err := s.bucketRepo.GetByID(ctx, bucketID)
 	if err != nil {
 		s.logger.Error().Err(err).Int64("bucket_id", bucketID).Msg("Failed to get bucket")
-		return 0, 0, 1
+		return 0, 0, 0, 1
 	}
 
 	for _, rule := range rules {
@@ -415,24 +550,108 @@ func (s *LifecycleService) processBucketRules(ctx context.Context, bucketID int6
 		errors += errs
 	}
 
-	return expired, bytesFreed, errors
+	for _, rule := range rules {
+		t, errs := s.transitionRule(ctx, bucket, rule)
+		transitioned += t
+		errors += errs
+	}
+
+	return expired, transitioned, bytesFreed, errors
+}
+
+// transitionRule moves the objects matching a rule's transition to its
+// storage tier. Each object is moved once; objects sharing a blob are only
+// moved together.
+func (s *LifecycleService) transitionRule(ctx context.Context, bucket *domain.Bucket, rule *domain.LifecycleRule) (transitioned, failures int) {
+	if s.tierMover == nil {
+		return 0, 0
+	}
+
+	// Skip rules without transition, or whose transition date is still ahead
+	cutoff, ok := rule.TransitionCutoff(s.now().UTC())
+	if !ok {
+		return 0, 0
+	}
+
+	objects, err := s.lifecycleRepo.ListTransitionCandidates(ctx, bucket.ID, rule.Prefix, cutoff, rule.TransitionStorageClass.SatisfiedBy(), s.config.BatchSize)
+	if err != nil {
+		s.logger.Error().Err(err).Str("bucket", bucket.Name).Str("rule_id", rule.RuleID).Msg("Failed to list transition candidates")
+		return 0, 1
+	}
+
+	tier := transitionTier(rule.TransitionStorageClass)
+	moved := make(map[string]bool)
+	for _, obj := range objects {
+		if obj.ContentHash == nil {
+			continue
+		}
+		hash := *obj.ContentHash
+
+		if s.config.DryRun {
+			s.logger.Info().
+				Str("bucket", bucket.Name).
+				Str("key", obj.Key).
+				Str("storage_class", string(rule.TransitionStorageClass)).
+				Msg("[DRY RUN] Would transition object")
+			transitioned++
+			continue
+		}
+
+		if !moved[hash] {
+			if err := s.tierMover.ForceMove(ctx, hash, tier); err != nil {
+				if errors.Is(err, tiering.ErrTieringInProgress) {
+					// Retried on the next run
+					continue
+				}
+				s.logger.Error().Err(err).
+					Str("bucket", bucket.Name).
+					Str("key", obj.Key).
+					Str("storage_class", string(rule.TransitionStorageClass)).
+					Msg("Failed to transition object")
+				failures++
+				continue
+			}
+			moved[hash] = true
+		}
+
+		if err := s.lifecycleRepo.MarkTransitioned(ctx, obj.ID, rule.TransitionStorageClass); err != nil {
+			s.logger.Error().Err(err).Str("bucket", bucket.Name).Str("key", obj.Key).Msg("Failed to record object transition")
+			failures++
+			continue
+		}
+
+		transitioned++
+
+		s.logger.Debug().
+			Str("bucket", bucket.Name).
+			Str("key", obj.Key).
+			Str("storage_class", string(rule.TransitionStorageClass)).
+			Msg("Object transitioned")
+	}
+
+	return transitioned, failures
+}
+
+// transitionTier returns the storage tier of a transition storage class.
+func transitionTier(class domain.TransitionClass) tiering.Tier {
+	if class == domain.TransitionCold {
+		return tiering.TierCold
+	}
+	return tiering.TierWarm
 }
 
 // evaluateRule evaluates a single lifecycle rule against objects in a bucket.
 func (s *LifecycleService) evaluateRule(ctx context.Context, bucket *domain.Bucket, rule *domain.LifecycleRule) (expired int, bytesFreed int64, errors int) {
-	// Skip rules without expiration
-	if !rule.HasExpiration() {
+	// Skip rules without expiration, or whose expiration date is still ahead
+	cutoff, ok := rule.ExpirationCutoff(s.now().UTC())
+	if !ok {
 		return 0, 0, 0
 	}
 
-	// Calculate expiration cutoff time
-	cutoff := time.Now().UTC().AddDate(0, 0, -*rule.ExpirationDays)
-
 	s.logger.Debug().
 		Str("bucket", bucket.Name).
 		Str("rule_id", rule.RuleID).
 		Str("prefix", rule.Prefix).
-		Int("expiration_days", *rule.ExpirationDays).
 		Time("cutoff", cutoff).
 		Msg("Evaluating lifecycle rule")
 
@@ -479,31 +698,76 @@ func (s *LifecycleService) evaluateRule(ctx context.Context, bucket *domain.Buck
 	return expired, bytesFreed, errors
 }
 
+// expireScheduledObjects deletes objects whose own expiration time has
+// passed (x-amz-expires-at), across all buckets.
+func (s *LifecycleService) expireScheduledObjects(ctx context.Context) (expired int, bytesFreed int64, errors int) {
+	objects, err := s.objectRepo.ListPastExpiry(ctx, s.now().UTC(), s.config.BatchSize)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to list objects past their expiration time")
+		return 0, 0, 1
+	}
+
+	buckets := make(map[int64]*domain.Bucket)
+	for _, obj := range objects {
+		bucket, ok := buckets[obj.BucketID]
+		if !ok {
+			bucket, err = s.bucketRepo.GetByID(ctx, obj.BucketID)
+			if err != nil {
+				s.logger.Error().Err(err).Int64("bucket_id", obj.BucketID).Msg("Failed to get bucket")
+				errors++
+				continue
+			}
+			buckets[obj.BucketID] = bucket
+		}
+
+		if s.config.DryRun {
+			s.logger.Info().
+				Str("bucket", bucket.Name).
+				Str("key", obj.Key).
+				Msg("[DRY RUN] Would expire object past its expiration time")
+			expired++
+			bytesFreed += obj.Size
+			continue
+		}
+
+		if err := s.expireObject(ctx, bucket, obj); err != nil {
+			s.logger.Error().Err(err).
+				Str("bucket", bucket.Name).
+				Str("key", obj.Key).
+				Msg("Failed to expire object")
+			errors++
+			continue
+		}
+
+		expired++
+		bytesFreed += obj.Size
+
+		s.logger.Debug().
+			Str("bucket", bucket.Name).
+			Str("key", obj.Key).
+			Int64("size", obj.Size).
+			Msg("Object reached its expiration time")
+	}
+
+	return expired, bytesFreed, errors
+}
+
 // expireObject deletes an object due to lifecycle expiration.
 func (s *LifecycleService) expireObject(ctx context.Context, bucket *domain.Bucket, obj *domain.Object) error {
 	// For versioned buckets, insert a delete marker
 	// For non-versioned buckets, delete the object directly
 
-	if bucket.Versioning == domain.VersioningEnabled {
-		// Create delete marker
+	if bucket.IsVersioningEverEnabled() {
+		// Demote the current version before the marker takes its place,
+		// as DeleteObject does
 		deleteMarker := domain.NewDeleteMarker(bucket.ID, obj.Key)
+		deleteMarker.NormalizedKey = bucket.NormalizeKey(obj.Key)
+		deleteMarker.VersionID, _ = prepareObjectWrite(ctx, s.objectRepo, bucket, obj.Key)
 		if err := s.objectRepo.Create(ctx, deleteMarker); err != nil {
 			return fmt.Errorf("failed to create delete marker: %w", err)
 		}
-
-		// Mark previous version as not latest
-		if err := s.objectRepo.MarkNotLatest(ctx, bucket.ID, obj.Key); err != nil {
-			return fmt.Errorf("failed to mark not latest: %w", err)
-		}
 	} else {
-		// Decrement blob reference count
-		if obj.ContentHash != nil {
-			if _, err := s.blobRepo.DecrementRef(ctx, *obj.ContentHash); err != nil {
-				s.logger.Warn().Err(err).Str("content_hash", *obj.ContentHash).Msg("Failed to decrement blob ref")
-			}
-		}
-
-		// Delete object
+		// Soft-delete object; the blob reference is released on purge
 		if err := s.objectRepo.Delete(ctx, obj.ID); err != nil {
 			return fmt.Errorf("failed to delete object: %w", err)
 		}
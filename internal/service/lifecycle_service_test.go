@@ -0,0 +1,300 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/lock"
+	"github.com/prn-tf/alexander-storage/internal/repository/sqlite"
+	"github.com/prn-tf/alexander-storage/internal/tiering"
+)
+
+func TestLifecycleService_ExpiresObjectsPastTheirExpiry(t *testing.T) {
+	ctx := context.Background()
+
+	inst := startMultipartInstance(t, t.TempDir())
+	defer inst.db.Close()
+
+	user := domain.NewUser("tester", "tester@example.com", "hash")
+	require.NoError(t, sqlite.NewUserRepository(inst.db).Create(ctx, user))
+
+	buckets := NewBucketService(sqlite.NewBucketRepository(inst.db), zerolog.Nop())
+	_, err := buckets.CreateBucket(ctx, CreateBucketInput{Name: "scratch", OwnerID: user.ID})
+	require.NoError(t, err)
+
+	put := func(key string, expiresAt *time.Time) {
+		body := "ephemeral"
+		_, err := inst.objects.PutObject(ctx, PutObjectInput{
+			BucketName: "scratch",
+			Key:        key,
+			Body:       strings.NewReader(body),
+			Size:       int64(len(body)),
+			OwnerID:    user.ID,
+			ExpiresAt:  expiresAt,
+		})
+		require.NoError(t, err)
+	}
+
+	start := time.Now()
+	expiresAt := start.Add(time.Minute)
+	put("build.log", &expiresAt)
+	put("keep.txt", nil)
+
+	lifecycle := NewLifecycleService(
+		sqlite.NewLifecycleRepository(inst.db),
+		sqlite.NewObjectRepository(inst.db),
+		sqlite.NewBucketRepository(inst.db),
+		sqlite.NewBlobRepository(inst.db),
+		lock.NewNoOpLocker(),
+		nil,
+		zerolog.Nop(),
+		DefaultLifecycleConfig(),
+	)
+
+	exists := func(key string) bool {
+		_, err := inst.objects.HeadObject(ctx, HeadObjectInput{BucketName: "scratch", Key: key, OwnerID: user.ID})
+		if err != nil {
+			require.ErrorIs(t, err, domain.ErrObjectNotFound)
+			return false
+		}
+		return true
+	}
+
+	// Before the TTL elapses nothing is deleted
+	result := lifecycle.RunOnce(ctx)
+	assert.Equal(t, 0, result.ObjectsExpired)
+	assert.True(t, exists("build.log"))
+
+	// After it elapses the worker deletes the object without any bucket rule
+	lifecycle.now = func() time.Time { return start.Add(2 * time.Minute) }
+	result = lifecycle.RunOnce(ctx)
+	assert.Equal(t, 1, result.ObjectsExpired)
+	assert.Equal(t, 0, result.Errors)
+	assert.False(t, exists("build.log"))
+	assert.True(t, exists("keep.txt"))
+}
+
+func TestObjectService_PutObjectRejectsPastExpiry(t *testing.T) {
+	svc := NewObjectService(nil, nil, nil, nil, lock.NewNoOpLocker(), zerolog.Nop())
+
+	past := time.Now().Add(-time.Minute)
+	_, err := svc.PutObject(context.Background(), PutObjectInput{
+		BucketName: "scratch",
+		Key:        "late.txt",
+		Body:       strings.NewReader("x"),
+		Size:       1,
+		ExpiresAt:  &past,
+	})
+	assert.ErrorIs(t, err, domain.ErrInvalidObjectExpiry)
+}
+
+func TestLifecycleService_BucketLifecycleRoundTrip(t *testing.T) {
+	ctx := context.Background()
+
+	inst := startMultipartInstance(t, t.TempDir())
+	defer inst.db.Close()
+
+	user := domain.NewUser("tester", "tester@example.com", "hash")
+	require.NoError(t, sqlite.NewUserRepository(inst.db).Create(ctx, user))
+
+	buckets := NewBucketService(sqlite.NewBucketRepository(inst.db), zerolog.Nop())
+	_, err := buckets.CreateBucket(ctx, CreateBucketInput{Name: "scratch", OwnerID: user.ID})
+	require.NoError(t, err)
+
+	lifecycle := NewLifecycleService(
+		sqlite.NewLifecycleRepository(inst.db),
+		sqlite.NewObjectRepository(inst.db),
+		sqlite.NewBucketRepository(inst.db),
+		sqlite.NewBlobRepository(inst.db),
+		lock.NewNoOpLocker(),
+		nil,
+		zerolog.Nop(),
+		DefaultLifecycleConfig(),
+	)
+
+	_, err = lifecycle.GetBucketLifecycle(ctx, "scratch", user.ID)
+	require.ErrorIs(t, err, ErrNoSuchLifecycleConfig)
+
+	days := 30
+	date := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	logs := domain.NewLifecycleRule(0, "expire-logs")
+	logs.Prefix = "logs/"
+	logs.ExpirationDays = &days
+	tmp := domain.NewLifecycleRule(0, "expire-tmp")
+	tmp.Prefix = "tmp/"
+	tmp.Status = domain.LifecycleDisabled
+	tmp.ExpirationDate = &date
+
+	require.NoError(t, lifecycle.PutBucketLifecycle(ctx, PutBucketLifecycleInput{
+		BucketName: "scratch",
+		OwnerID:    user.ID,
+		Rules:      []*domain.LifecycleRule{logs, tmp},
+	}))
+
+	rules, err := lifecycle.GetBucketLifecycle(ctx, "scratch", user.ID)
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	byID := map[string]*domain.LifecycleRule{rules[0].RuleID: rules[0], rules[1].RuleID: rules[1]}
+	require.Contains(t, byID, "expire-logs")
+	require.Contains(t, byID, "expire-tmp")
+	assert.Equal(t, "logs/", byID["expire-logs"].Prefix)
+	require.NotNil(t, byID["expire-logs"].ExpirationDays)
+	assert.Equal(t, 30, *byID["expire-logs"].ExpirationDays)
+	assert.Nil(t, byID["expire-logs"].ExpirationDate)
+	assert.Equal(t, domain.LifecycleDisabled, byID["expire-tmp"].Status)
+	require.NotNil(t, byID["expire-tmp"].ExpirationDate)
+	assert.True(t, date.Equal(*byID["expire-tmp"].ExpirationDate))
+
+	// A new configuration replaces the old one
+	require.NoError(t, lifecycle.PutBucketLifecycle(ctx, PutBucketLifecycleInput{
+		BucketName: "scratch",
+		OwnerID:    user.ID,
+		Rules:      []*domain.LifecycleRule{logs},
+	}))
+	rules, err = lifecycle.GetBucketLifecycle(ctx, "scratch", user.ID)
+	require.NoError(t, err)
+	assert.Len(t, rules, 1)
+
+	// Duplicate IDs and more than the maximum number of rules are rejected
+	err = lifecycle.PutBucketLifecycle(ctx, PutBucketLifecycleInput{
+		BucketName: "scratch",
+		OwnerID:    user.ID,
+		Rules:      []*domain.LifecycleRule{logs, logs},
+	})
+	assert.ErrorIs(t, err, ErrInvalidLifecycleRule)
+	tooMany := make([]*domain.LifecycleRule, domain.MaxLifecycleRules+1)
+	for i := range tooMany {
+		tooMany[i] = logs
+	}
+	err = lifecycle.PutBucketLifecycle(ctx, PutBucketLifecycleInput{BucketName: "scratch", OwnerID: user.ID, Rules: tooMany})
+	assert.ErrorIs(t, err, ErrTooManyLifecycleRules)
+
+	require.NoError(t, lifecycle.DeleteBucketLifecycle(ctx, "scratch", user.ID))
+	_, err = lifecycle.GetBucketLifecycle(ctx, "scratch", user.ID)
+	assert.ErrorIs(t, err, ErrNoSuchLifecycleConfig)
+}
+
+// recordingTierMover records the blobs it was asked to move.
+type recordingTierMover struct {
+	moves map[string]tiering.Tier
+}
+
+func (m *recordingTierMover) ForceMove(ctx context.Context, contentHash string, targetTier tiering.Tier) error {
+	m.moves[contentHash] = targetTier
+	return nil
+}
+
+func TestLifecycleService_TransitionsObjectsToWarm(t *testing.T) {
+	ctx := context.Background()
+
+	inst := startMultipartInstance(t, t.TempDir())
+	defer inst.db.Close()
+
+	user := domain.NewUser("tester", "tester@example.com", "hash")
+	require.NoError(t, sqlite.NewUserRepository(inst.db).Create(ctx, user))
+
+	buckets := NewBucketService(sqlite.NewBucketRepository(inst.db), zerolog.Nop())
+	_, err := buckets.CreateBucket(ctx, CreateBucketInput{Name: "archive", OwnerID: user.ID})
+	require.NoError(t, err)
+
+	put := func(key, body string) {
+		_, err := inst.objects.PutObject(ctx, PutObjectInput{
+			BucketName: "archive",
+			Key:        key,
+			Body:       strings.NewReader(body),
+			Size:       int64(len(body)),
+			OwnerID:    user.ID,
+		})
+		require.NoError(t, err)
+	}
+	put("logs/app.log", "log lines")
+	put("reports/q1.csv", "report rows")
+
+	lifecycle := NewLifecycleService(
+		sqlite.NewLifecycleRepository(inst.db),
+		sqlite.NewObjectRepository(inst.db),
+		sqlite.NewBucketRepository(inst.db),
+		sqlite.NewBlobRepository(inst.db),
+		lock.NewNoOpLocker(),
+		nil,
+		zerolog.Nop(),
+		DefaultLifecycleConfig(),
+	)
+	mover := &recordingTierMover{moves: map[string]tiering.Tier{}}
+	lifecycle.SetTierMover(mover)
+
+	days := 30
+	rule := domain.NewLifecycleRule(0, "warm-logs")
+	rule.Prefix = "logs/"
+	rule.TransitionDays = &days
+	rule.TransitionStorageClass = domain.TransitionWarm
+	require.NoError(t, lifecycle.PutBucketLifecycle(ctx, PutBucketLifecycleInput{
+		BucketName: "archive",
+		OwnerID:    user.ID,
+		Rules:      []*domain.LifecycleRule{rule},
+	}))
+
+	start := time.Now()
+
+	// Before the threshold nothing moves
+	lifecycle.now = func() time.Time { return start.AddDate(0, 0, 29) }
+	result := lifecycle.RunOnce(ctx)
+	assert.Equal(t, 0, result.ObjectsTransitioned)
+	assert.Empty(t, mover.moves)
+
+	// After 30 days the matching object is scheduled to move to WARM
+	lifecycle.now = func() time.Time { return start.AddDate(0, 0, 31) }
+	result = lifecycle.RunOnce(ctx)
+	assert.Equal(t, 1, result.ObjectsTransitioned)
+	assert.Equal(t, 0, result.ObjectsExpired)
+	assert.Equal(t, 0, result.Errors)
+
+	bucket, err := sqlite.NewBucketRepository(inst.db).GetByName(ctx, "archive")
+	require.NoError(t, err)
+	logObj, err := sqlite.NewObjectRepository(inst.db).GetByKey(ctx, bucket.ID, "logs/app.log")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]tiering.Tier{*logObj.ContentHash: tiering.TierWarm}, mover.moves)
+
+	// A transitioned object is not moved again
+	delete(mover.moves, *logObj.ContentHash)
+	result = lifecycle.RunOnce(ctx)
+	assert.Equal(t, 0, result.ObjectsTransitioned)
+	assert.Empty(t, mover.moves)
+
+	// The rule round-trips through the repository
+	rules, err := lifecycle.GetBucketLifecycle(ctx, "archive", user.ID)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	require.NotNil(t, rules[0].TransitionDays)
+	assert.Equal(t, 30, *rules[0].TransitionDays)
+	assert.Equal(t, domain.TransitionWarm, rules[0].TransitionStorageClass)
+	assert.False(t, rules[0].HasExpiration())
+}
+
+func TestLifecycleRule_TransitionDate(t *testing.T) {
+	date := time.Date(2030, 6, 1, 0, 0, 0, 0, time.UTC)
+	rule := domain.NewLifecycleRule(1, "cold-archive")
+	rule.TransitionDate = &date
+	rule.TransitionStorageClass = domain.TransitionCold
+	require.NoError(t, rule.Validate())
+
+	_, ok := rule.TransitionCutoff(date.Add(-time.Hour))
+	assert.False(t, ok)
+	cutoff, ok := rule.TransitionCutoff(date.Add(time.Hour))
+	assert.True(t, ok)
+	assert.Equal(t, date.Add(time.Hour), cutoff)
+
+	// A transition needs a tier, and must come before the expiration
+	rule.TransitionStorageClass = ""
+	assert.Error(t, rule.Validate())
+	rule.TransitionStorageClass = domain.TransitionCold
+	rule.ExpirationDate = &date
+	assert.Error(t, rule.Validate())
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func uploadKeys(out *ListMultipartUploadsOutput) []string {
+	keys := make([]string, len(out.Uploads))
+	for i, upload := range out.Uploads {
+		keys[i] = upload.Key
+	}
+	return keys
+}
+
+func TestMultipartService_ListMultipartUploadsPrefix(t *testing.T) {
+	ctx := context.Background()
+	dir, ownerID := setupMultipartRestart(t)
+	inst := startMultipartInstance(t, dir)
+	defer inst.db.Close()
+
+	for _, key := range []string{"logs/a.bin", "logs/b.bin", "logs/b.bin", "Logs/c.bin", "logs/2024/d.bin", "media/e.bin", "root.bin"} {
+		_, err := inst.multipart.InitiateMultipartUpload(ctx, InitiateMultipartUploadInput{
+			BucketName: "uploads",
+			Key:        key,
+			OwnerID:    ownerID,
+		})
+		require.NoError(t, err)
+	}
+
+	list := func(input ListMultipartUploadsInput) *ListMultipartUploadsOutput {
+		t.Helper()
+		input.BucketName = "uploads"
+		input.OwnerID = ownerID
+		out, err := inst.multipart.ListMultipartUploads(ctx, input)
+		require.NoError(t, err)
+		return out
+	}
+
+	// Only uploads under the prefix, matched case-sensitively
+	out := list(ListMultipartUploadsInput{Prefix: "logs/"})
+	assert.Equal(t, []string{"logs/2024/d.bin", "logs/a.bin", "logs/b.bin", "logs/b.bin"}, uploadKeys(out))
+	assert.False(t, out.IsTruncated)
+
+	// LIKE wildcards in the prefix match literally
+	out = list(ListMultipartUploadsInput{Prefix: "log_/"})
+	assert.Empty(t, out.Uploads)
+
+	// Pages continue within a key from the upload ID marker
+	out = list(ListMultipartUploadsInput{Prefix: "logs/", MaxUploads: 3})
+	require.True(t, out.IsTruncated)
+	assert.Equal(t, []string{"logs/2024/d.bin", "logs/a.bin", "logs/b.bin"}, uploadKeys(out))
+	first := out.Uploads[2].UploadID
+	out = list(ListMultipartUploadsInput{Prefix: "logs/", MaxUploads: 3, KeyMarker: out.NextKeyMarker, UploadIDMarker: out.NextUploadIDMarker})
+	assert.False(t, out.IsTruncated)
+	require.Equal(t, []string{"logs/b.bin"}, uploadKeys(out))
+	assert.NotEqual(t, first, out.Uploads[0].UploadID)
+
+	// A delimiter rolls up keys below the prefix
+	out = list(ListMultipartUploadsInput{Prefix: "logs/", Delimiter: "/"})
+	assert.Equal(t, []string{"logs/a.bin", "logs/b.bin", "logs/b.bin"}, uploadKeys(out))
+	assert.Equal(t, []string{"logs/2024/"}, out.CommonPrefixes)
+
+	out = list(ListMultipartUploadsInput{Delimiter: "/", MaxUploads: 2})
+	require.True(t, out.IsTruncated)
+	assert.Empty(t, out.Uploads)
+	assert.Equal(t, []string{"Logs/", "logs/"}, out.CommonPrefixes)
+	assert.Equal(t, "logs/", out.NextKeyMarker)
+	out = list(ListMultipartUploadsInput{Delimiter: "/", KeyMarker: out.NextKeyMarker, UploadIDMarker: out.NextUploadIDMarker})
+	assert.Equal(t, []string{"root.bin"}, uploadKeys(out))
+	assert.Equal(t, []string{"media/"}, out.CommonPrefixes)
+}
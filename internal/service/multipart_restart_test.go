@@ -0,0 +1,349 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/lock"
+	"github.com/prn-tf/alexander-storage/internal/metrics"
+	"github.com/prn-tf/alexander-storage/internal/repository/sqlite"
+	"github.com/prn-tf/alexander-storage/internal/storage/filesystem"
+)
+
+// multipartInstance is one server lifetime over a shared database and data directory.
+type multipartInstance struct {
+	db        *sqlite.DB
+	storage   *filesystem.Storage
+	multipart *MultipartService
+	objects   *ObjectService
+}
+
+func startMultipartInstance(t *testing.T, dir string) *multipartInstance {
+	t.Helper()
+	ctx := context.Background()
+
+	db, err := sqlite.NewDB(ctx, sqlite.DefaultConfig(filepath.Join(dir, "alexander.db")), zerolog.Nop())
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(ctx))
+
+	store, err := filesystem.NewStorage(filesystem.Config{
+		DataDir: filepath.Join(dir, "data"),
+		TempDir: filepath.Join(dir, "tmp"),
+	}, zerolog.Nop())
+	require.NoError(t, err)
+
+	multipartRepo := sqlite.NewMultipartRepository(db)
+	objectRepo := sqlite.NewObjectRepository(db)
+	blobRepo := sqlite.NewBlobRepository(db)
+	bucketRepo := sqlite.NewBucketRepository(db)
+
+	return &multipartInstance{
+		db:        db,
+		storage:   store,
+		multipart: NewMultipartService(multipartRepo, objectRepo, blobRepo, bucketRepo, store, lock.NewNoOpLocker(), zerolog.Nop()),
+		objects:   NewObjectService(objectRepo, blobRepo, bucketRepo, store, lock.NewNoOpLocker(), zerolog.Nop()),
+	}
+}
+
+// setupMultipartRestart creates a user and bucket and returns the data directory and owner ID.
+func setupMultipartRestart(t *testing.T) (string, int64) {
+	t.Helper()
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	inst := startMultipartInstance(t, dir)
+	defer inst.db.Close()
+
+	user := domain.NewUser("tester", "tester@example.com", "hash")
+	require.NoError(t, sqlite.NewUserRepository(inst.db).Create(ctx, user))
+	require.NoError(t, sqlite.NewBucketRepository(inst.db).Create(ctx, domain.NewBucket(user.ID, "uploads")))
+
+	return dir, user.ID
+}
+
+func uploadTestPart(t *testing.T, svc *MultipartService, uploadID string, partNumber int, data []byte, ownerID int64) domain.CompletedPart {
+	t.Helper()
+
+	out, err := svc.UploadPart(context.Background(), UploadPartInput{
+		BucketName: "uploads",
+		Key:        "video.bin",
+		UploadID:   uploadID,
+		PartNumber: partNumber,
+		Body:       bytes.NewReader(data),
+		Size:       int64(len(data)),
+		OwnerID:    ownerID,
+	})
+	require.NoError(t, err)
+	return domain.CompletedPart{PartNumber: partNumber, ETag: out.ETag}
+}
+
+func TestMultipartService_CompleteAfterRestart(t *testing.T) {
+	ctx := context.Background()
+	dir, ownerID := setupMultipartRestart(t)
+	part1 := bytes.Repeat([]byte("1"), 1024)
+	part2 := bytes.Repeat([]byte("2"), 512)
+
+	// First lifetime: initiate and upload the first part
+	first := startMultipartInstance(t, dir)
+	initiated, err := first.multipart.InitiateMultipartUpload(ctx, InitiateMultipartUploadInput{
+		BucketName: "uploads",
+		Key:        "video.bin",
+		OwnerID:    ownerID,
+	})
+	require.NoError(t, err)
+	completed1 := uploadTestPart(t, first.multipart, initiated.UploadID, 1, part1, ownerID)
+	require.NoError(t, first.db.Close())
+
+	// Second lifetime: the upload is still known and can be finished
+	second := startMultipartInstance(t, dir)
+	defer second.db.Close()
+
+	removed, err := second.multipart.ReconcileParts(ctx)
+	require.NoError(t, err)
+	require.Zero(t, removed)
+
+	listed, err := second.multipart.ListMultipartUploads(ctx, ListMultipartUploadsInput{BucketName: "uploads", OwnerID: ownerID})
+	require.NoError(t, err)
+	require.Len(t, listed.Uploads, 1)
+	require.Equal(t, initiated.UploadID, listed.Uploads[0].UploadID)
+
+	completed2 := uploadTestPart(t, second.multipart, initiated.UploadID, 2, part2, ownerID)
+
+	_, err = second.multipart.CompleteMultipartUpload(ctx, CompleteMultipartUploadInput{
+		BucketName: "uploads",
+		Key:        "video.bin",
+		UploadID:   initiated.UploadID,
+		Parts:      []domain.CompletedPart{completed1, completed2},
+		OwnerID:    ownerID,
+	})
+	require.NoError(t, err)
+
+	got, err := second.objects.GetObject(ctx, GetObjectInput{BucketName: "uploads", Key: "video.bin", OwnerID: ownerID})
+	require.NoError(t, err)
+	defer got.Body.Close()
+	data, err := io.ReadAll(got.Body)
+	require.NoError(t, err)
+	require.Equal(t, append(part1, part2...), data)
+}
+
+func TestMultipartService_ReconcileRemovesPartsWithMissingBlobs(t *testing.T) {
+	ctx := context.Background()
+	dir, ownerID := setupMultipartRestart(t)
+
+	first := startMultipartInstance(t, dir)
+	initiated, err := first.multipart.InitiateMultipartUpload(ctx, InitiateMultipartUploadInput{
+		BucketName: "uploads",
+		Key:        "video.bin",
+		OwnerID:    ownerID,
+	})
+	require.NoError(t, err)
+	lost := uploadTestPart(t, first.multipart, initiated.UploadID, 1, []byte("lost on disk"), ownerID)
+	kept := uploadTestPart(t, first.multipart, initiated.UploadID, 2, []byte("still here"), ownerID)
+
+	lostPart, err := sqlite.NewMultipartRepository(first.db).GetPart(ctx, uuid.MustParse(initiated.UploadID), 1)
+	require.NoError(t, err)
+	require.NoError(t, os.Remove(first.storage.GetPath(lostPart.ContentHash)))
+	require.NoError(t, first.db.Close())
+
+	second := startMultipartInstance(t, dir)
+	defer second.db.Close()
+
+	removed, err := second.multipart.ReconcileParts(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, removed)
+
+	parts, err := second.multipart.ListParts(ctx, ListPartsInput{BucketName: "uploads", Key: "video.bin", UploadID: initiated.UploadID, OwnerID: ownerID})
+	require.NoError(t, err)
+	require.Len(t, parts.Parts, 1)
+	require.Equal(t, kept.ETag, parts.Parts[0].ETag)
+
+	_, err = second.multipart.CompleteMultipartUpload(ctx, CompleteMultipartUploadInput{
+		BucketName: "uploads",
+		Key:        "video.bin",
+		UploadID:   initiated.UploadID,
+		Parts:      []domain.CompletedPart{lost, kept},
+		OwnerID:    ownerID,
+	})
+	require.ErrorIs(t, err, domain.ErrPartNotFound)
+}
+
+func TestMultipartService_CompleteRejectsMissingPartBlob(t *testing.T) {
+	ctx := context.Background()
+	dir, ownerID := setupMultipartRestart(t)
+
+	inst := startMultipartInstance(t, dir)
+	defer inst.db.Close()
+
+	initiated, err := inst.multipart.InitiateMultipartUpload(ctx, InitiateMultipartUploadInput{
+		BucketName: "uploads",
+		Key:        "video.bin",
+		OwnerID:    ownerID,
+	})
+	require.NoError(t, err)
+	first := uploadTestPart(t, inst.multipart, initiated.UploadID, 1, []byte("first part"), ownerID)
+	second := uploadTestPart(t, inst.multipart, initiated.UploadID, 2, []byte("second part"), ownerID)
+
+	// Lose the second part's blob without reconciling, as a GC bug would
+	lostPart, err := sqlite.NewMultipartRepository(inst.db).GetPart(ctx, uuid.MustParse(initiated.UploadID), 2)
+	require.NoError(t, err)
+	require.NoError(t, os.Remove(inst.storage.GetPath(lostPart.ContentHash)))
+
+	_, err = inst.multipart.CompleteMultipartUpload(ctx, CompleteMultipartUploadInput{
+		BucketName: "uploads",
+		Key:        "video.bin",
+		UploadID:   initiated.UploadID,
+		Parts:      []domain.CompletedPart{first, second},
+		OwnerID:    ownerID,
+	})
+	require.ErrorIs(t, err, domain.ErrPartDataMissing)
+	require.ErrorContains(t, err, "part 2")
+
+	// No truncated object was assembled
+	_, err = inst.objects.GetObject(ctx, GetObjectInput{BucketName: "uploads", Key: "video.bin", OwnerID: ownerID})
+	require.ErrorIs(t, err, domain.ErrObjectNotFound)
+}
+
+func TestMultipartService_UploadPartCopy(t *testing.T) {
+	ctx := context.Background()
+	dir, ownerID := setupMultipartRestart(t)
+	inst := startMultipartInstance(t, dir)
+	defer inst.db.Close()
+
+	source := []byte("0123456789abcdefghij")
+	_, err := inst.objects.PutObject(ctx, PutObjectInput{
+		BucketName: "uploads",
+		Key:        "source.txt",
+		Body:       bytes.NewReader(source),
+		Size:       int64(len(source)),
+		OwnerID:    ownerID,
+	})
+	require.NoError(t, err)
+
+	initiated, err := inst.multipart.InitiateMultipartUpload(ctx, InitiateMultipartUploadInput{
+		BucketName: "uploads",
+		Key:        "video.bin",
+		OwnerID:    ownerID,
+	})
+	require.NoError(t, err)
+
+	copyPart := func(partNumber int, sourceRange *ByteRange) (*UploadPartCopyOutput, error) {
+		return inst.multipart.UploadPartCopy(ctx, UploadPartCopyInput{
+			BucketName:   "uploads",
+			Key:          "video.bin",
+			UploadID:     initiated.UploadID,
+			PartNumber:   partNumber,
+			SourceBucket: "uploads",
+			SourceKey:    "source.txt",
+			SourceRange:  sourceRange,
+			OwnerID:      ownerID,
+		})
+	}
+
+	whole, err := copyPart(1, nil)
+	require.NoError(t, err)
+	ranged, err := copyPart(2, &ByteRange{Start: 10, End: 14})
+	require.NoError(t, err)
+	require.False(t, ranged.LastModified.IsZero())
+
+	_, err = copyPart(3, &ByteRange{Start: 15, End: 20})
+	require.ErrorIs(t, err, domain.ErrInvalidRange)
+
+	_, err = inst.multipart.CompleteMultipartUpload(ctx, CompleteMultipartUploadInput{
+		BucketName: "uploads",
+		Key:        "video.bin",
+		UploadID:   initiated.UploadID,
+		Parts: []domain.CompletedPart{
+			{PartNumber: 1, ETag: whole.ETag},
+			{PartNumber: 2, ETag: ranged.ETag},
+		},
+		OwnerID: ownerID,
+	})
+	require.NoError(t, err)
+
+	got, err := inst.objects.GetObject(ctx, GetObjectInput{BucketName: "uploads", Key: "video.bin", OwnerID: ownerID})
+	require.NoError(t, err)
+	defer got.Body.Close()
+	data, err := io.ReadAll(got.Body)
+	require.NoError(t, err)
+	require.Equal(t, append(append([]byte{}, source...), source[10:15]...), data)
+}
+
+func TestMultipartService_ReportActiveUploads(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	dir, ownerID := setupMultipartRestart(t)
+	inst := startMultipartInstance(t, dir)
+	defer inst.db.Close()
+
+	m := metrics.NewWithRegisterer(prometheus.NewRegistry())
+	inst.multipart.SetMetrics(m)
+
+	for _, key := range []string{"a.bin", "b.bin"} {
+		_, err := inst.multipart.InitiateMultipartUpload(ctx, InitiateMultipartUploadInput{
+			BucketName: "uploads",
+			Key:        key,
+			OwnerID:    ownerID,
+		})
+		require.NoError(t, err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		inst.multipart.ReportActiveUploads(ctx, time.Hour)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(m.MultipartTotal) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-done
+}
+
+func TestMultipartService_CompleteRejectsTruncatedPartBlob(t *testing.T) {
+	ctx := context.Background()
+	dir, ownerID := setupMultipartRestart(t)
+
+	inst := startMultipartInstance(t, dir)
+	defer inst.db.Close()
+
+	initiated, err := inst.multipart.InitiateMultipartUpload(ctx, InitiateMultipartUploadInput{
+		BucketName: "uploads",
+		Key:        "video.bin",
+		OwnerID:    ownerID,
+	})
+	require.NoError(t, err)
+	first := uploadTestPart(t, inst.multipart, initiated.UploadID, 1, []byte("first part"), ownerID)
+	second := uploadTestPart(t, inst.multipart, initiated.UploadID, 2, []byte("second part"), ownerID)
+
+	// The second part's blob loses its tail, so the parts no longer add up
+	truncatedPart, err := sqlite.NewMultipartRepository(inst.db).GetPart(ctx, uuid.MustParse(initiated.UploadID), 2)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(inst.storage.GetPath(truncatedPart.ContentHash), []byte("second"), 0644))
+
+	_, err = inst.multipart.CompleteMultipartUpload(ctx, CompleteMultipartUploadInput{
+		BucketName: "uploads",
+		Key:        "video.bin",
+		UploadID:   initiated.UploadID,
+		Parts:      []domain.CompletedPart{first, second},
+		OwnerID:    ownerID,
+	})
+	require.ErrorIs(t, err, domain.ErrPartDataMissing)
+	require.ErrorContains(t, err, "part 2")
+
+	_, err = inst.objects.GetObject(ctx, GetObjectInput{BucketName: "uploads", Key: "video.bin", OwnerID: ownerID})
+	require.ErrorIs(t, err, domain.ErrObjectNotFound)
+}
@@ -8,13 +8,16 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/rs/zerolog"
 
 	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/events"
 	"github.com/prn-tf/alexander-storage/internal/lock"
+	"github.com/prn-tf/alexander-storage/internal/metrics"
 	"github.com/prn-tf/alexander-storage/internal/repository"
 	"github.com/prn-tf/alexander-storage/internal/storage"
 )
@@ -27,6 +30,13 @@ type MultipartService struct {
 	bucketRepo    repository.BucketRepository
 	storage       storage.Backend
 	locker        lock.Locker
+	events        *events.Bus      // Optional - receives object events
+	metrics       *metrics.Metrics // Optional - counts copy sources missing their data
+	deltas        *DeltaQueue      // Optional - stores new versions as deltas
+	sizePolicy    SizeMismatchPolicy
+	maxKeyDepth   int    // Maximum "/" delimiters in new keys, 0 for unlimited
+	onStorageFull func() // Optional - called when a write finds storage full
+	overwriteMode OverwriteMode
 	logger        zerolog.Logger
 }
 
@@ -47,10 +57,46 @@ func NewMultipartService(
 		bucketRepo:    bucketRepo,
 		storage:       storage,
 		locker:        locker,
+		sizePolicy:    SizeMismatchStrict,
+		overwriteMode: OverwriteReplace,
 		logger:        logger.With().Str("service", "multipart").Logger(),
 	}
 }
 
+// SetEventBus publishes an event to bus for each completed upload.
+func (s *MultipartService) SetEventBus(bus *events.Bus) {
+	s.events = bus
+}
+
+// SetMetrics sets the metrics that record copy sources found missing their data.
+func (s *MultipartService) SetMetrics(m *metrics.Metrics) {
+	s.metrics = m
+}
+
+// SetStorageFullHandler sets a function called, without blocking the
+// request, whenever a write is rejected because storage is full.
+func (s *MultipartService) SetStorageFullHandler(fn func()) {
+	s.onStorageFull = fn
+}
+
+// SetSizeMismatchPolicy sets how UploadPart handles a body that does not
+// match its declared size. The default is SizeMismatchStrict.
+func (s *MultipartService) SetSizeMismatchPolicy(policy SizeMismatchPolicy) {
+	s.sizePolicy = policy
+}
+
+// SetOverwriteMode sets what happens to an object a completed upload
+// replaces without versioning. The default is OverwriteReplace.
+func (s *MultipartService) SetOverwriteMode(mode OverwriteMode) {
+	s.overwriteMode = mode
+}
+
+// SetMaxKeyDepth rejects new upload keys with more than n "/" delimiters.
+// 0, the default, allows any depth.
+func (s *MultipartService) SetMaxKeyDepth(n int) {
+	s.maxKeyDepth = n
+}
+
 // =============================================================================
 // Input/Output Structs
 // =============================================================================
@@ -62,7 +108,19 @@ type InitiateMultipartUploadInput struct {
 	ContentType  string
 	Metadata     map[string]string
 	StorageClass domain.StorageClass
+	ACL          string // Optional canned ACL (x-amz-acl)
 	OwnerID      int64
+
+	// CacheControl, ContentDisposition, ContentEncoding and Expires are
+	// stored with the final object. Optional.
+	CacheControl       string
+	ContentDisposition string
+	ContentEncoding    string
+	Expires            string
+
+	// ChecksumAlgorithm is checksummed over every part and gives the final
+	// object a composite checksum (x-amz-checksum-algorithm). Optional.
+	ChecksumAlgorithm domain.ChecksumAlgorithm
 }
 
 // InitiateMultipartUploadOutput contains the result of initiating a multipart upload.
@@ -81,11 +139,43 @@ type UploadPartInput struct {
 	Body       io.Reader
 	Size       int64
 	OwnerID    int64
+
+	// ChecksumAlgorithm and Checksum are the part's additional checksum,
+	// as for PutObjectInput. The algorithm defaults to the upload's.
+	ChecksumAlgorithm domain.ChecksumAlgorithm
+	Checksum          string
 }
 
 // UploadPartOutput contains the result of uploading a part.
 type UploadPartOutput struct {
-	ETag string
+	ETag         string
+	LastModified time.Time
+
+	// ChecksumAlgorithm and Checksum are set when the part has an additional checksum.
+	ChecksumAlgorithm domain.ChecksumAlgorithm
+	Checksum          string
+}
+
+// UploadPartCopyInput contains the data needed to copy an existing object,
+// or a byte range of it, into a part.
+type UploadPartCopyInput struct {
+	BucketName      string
+	Key             string
+	UploadID        string
+	PartNumber      int
+	SourceBucket    string
+	SourceKey       string
+	SourceVersionID string     // Optional
+	SourceRange     *ByteRange // Optional - x-amz-copy-source-range
+	Conditions      CopySourceConditions
+	OwnerID         int64
+}
+
+// UploadPartCopyOutput contains the result of copying a part.
+type UploadPartCopyOutput struct {
+	ETag            string
+	LastModified    time.Time
+	SourceVersionID string
 }
 
 // CompleteMultipartUploadInput contains the data needed to complete a multipart upload.
@@ -104,6 +194,10 @@ type CompleteMultipartUploadOutput struct {
 	Key       string
 	ETag      string
 	VersionID string
+
+	// ChecksumAlgorithm and Checksum are set when the object has a composite checksum.
+	ChecksumAlgorithm domain.ChecksumAlgorithm
+	Checksum          string
 }
 
 // AbortMultipartUploadInput contains the data needed to abort a multipart upload.
@@ -189,6 +283,16 @@ func (s *MultipartService) InitiateMultipartUpload(ctx context.Context, input In
 	if err := validateObjectKey(input.Key); err != nil {
 		return nil, err
 	}
+	if err := validateKeyDepth(input.Key, s.maxKeyDepth); err != nil {
+		return nil, err
+	}
+
+	if input.ChecksumAlgorithm != "" && !input.ChecksumAlgorithm.IsValid() {
+		return nil, fmt.Errorf("%w: unsupported algorithm %q", domain.ErrInvalidChecksum, input.ChecksumAlgorithm)
+	}
+
+	// Track the write so DeleteBucket can drain it
+	defer bucketWrites.Begin(input.BucketName)()
 
 	// Get bucket
 	bucket, err := s.bucketRepo.GetByName(ctx, input.BucketName)
@@ -201,10 +305,35 @@ func (s *MultipartService) InitiateMultipartUpload(ctx context.Context, input In
 	}
 
 	// Check ownership
-	if input.OwnerID > 0 && bucket.OwnerID != input.OwnerID {
+	if !bucketAccessAllowed(ctx, bucket, input.OwnerID) {
 		return nil, ErrBucketAccessDenied
 	}
 
+	// Reject writes to a bucket that is being deleted
+	if bucket.IsDeleting() {
+		return nil, domain.ErrBucketDeleting
+	}
+
+	// With ACLs disabled, the bucket owner owns the object regardless of x-amz-acl
+	if !bucket.AcceptsCannedACL(input.ACL) {
+		return nil, ErrACLNotSupported
+	}
+
+	// Enforce the bucket's encryption requirement before any part is
+	// uploaded; multipart uploads cannot be encrypted with SSE-C
+	if !bucket.AcceptsEncryption(false) {
+		return nil, domain.ErrEncryptionRequired
+	}
+
+	// Enforce the bucket's content-type policy before any part is uploaded
+	contentType := "application/octet-stream"
+	if ct, ok := input.Metadata["Content-Type"]; ok {
+		contentType = ct
+	}
+	if !bucket.ContentTypePolicy.Allows(contentType) {
+		return nil, domain.ErrContentTypeNotAllowed
+	}
+
 	// Create multipart upload
 	upload := domain.NewMultipartUpload(bucket.ID, input.Key, input.OwnerID)
 	if input.StorageClass != "" {
@@ -213,6 +342,11 @@ func (s *MultipartService) InitiateMultipartUpload(ctx context.Context, input In
 	if input.Metadata != nil {
 		upload.Metadata = input.Metadata
 	}
+	upload.ChecksumAlgorithm = input.ChecksumAlgorithm
+	upload.CacheControl = input.CacheControl
+	upload.ContentDisposition = input.ContentDisposition
+	upload.ContentEncoding = input.ContentEncoding
+	upload.Expires = input.Expires
 
 	if err := s.multipartRepo.Create(ctx, upload); err != nil {
 		s.logger.Error().Err(err).Str("key", input.Key).Msg("failed to create multipart upload")
@@ -241,10 +375,9 @@ func (s *MultipartService) UploadPart(ctx context.Context, input UploadPartInput
 
 	// Validate part size (5MB minimum except for last part, 5GB maximum)
 	// Minimum part size for validation (currently unused, kept for future use).
-	const _ = 5 * 1024 * 1024                  // 5MB minPartSize
-	const maxPartSize = 5 * 1024 * 1024 * 1024 // 5GB
+	const _ = 5 * 1024 * 1024 // 5MB minPartSize
 
-	if input.Size > maxPartSize {
+	if input.Size > domain.MaxPartSize {
 		return nil, domain.ErrPartTooLarge
 	}
 
@@ -254,6 +387,9 @@ func (s *MultipartService) UploadPart(ctx context.Context, input UploadPartInput
 		return nil, domain.ErrMultipartUploadNotFound
 	}
 
+	// Track the write so DeleteBucket can drain it
+	defer bucketWrites.Begin(input.BucketName)()
+
 	// Get bucket
 	bucket, err := s.bucketRepo.GetByName(ctx, input.BucketName)
 	if err != nil {
@@ -264,10 +400,15 @@ func (s *MultipartService) UploadPart(ctx context.Context, input UploadPartInput
 	}
 
 	// Check ownership
-	if input.OwnerID > 0 && bucket.OwnerID != input.OwnerID {
+	if !bucketAccessAllowed(ctx, bucket, input.OwnerID) {
 		return nil, ErrBucketAccessDenied
 	}
 
+	// Reject writes to a bucket that is being deleted
+	if bucket.IsDeleting() {
+		return nil, domain.ErrBucketDeleting
+	}
+
 	// Get multipart upload
 	upload, err := s.multipartRepo.GetByID(ctx, uploadID)
 	if err != nil {
@@ -295,18 +436,41 @@ func (s *MultipartService) UploadPart(ctx context.Context, input UploadPartInput
 		return nil, domain.ErrMultipartUploadExpired
 	}
 
+	// Parts of an upload with a checksum algorithm are all checksummed with it
+	algorithm := input.ChecksumAlgorithm
+	if upload.ChecksumAlgorithm != "" {
+		if algorithm != "" && algorithm != upload.ChecksumAlgorithm {
+			return nil, fmt.Errorf("%w: the upload was initiated with %s", domain.ErrInvalidChecksum, upload.ChecksumAlgorithm)
+		}
+		algorithm = upload.ChecksumAlgorithm
+	}
+
 	// Store part content in CAS storage
-	contentHash, err := s.storage.Store(ctx, input.Body, input.Size)
+	body, expectedSize, received := s.sizePolicy.prepareBody(input.Body, input.Size)
+	body, checksum, err := newChecksumBody(body, algorithm, input.Checksum)
 	if err != nil {
+		return nil, err
+	}
+	contentHash, err := s.storage.Store(ctx, body, expectedSize)
+	if err != nil {
+		if storage.IsStorageFull(err) {
+			return nil, storageFull(s.logger, s.metrics, s.onStorageFull, bucket.Name, input.Key, err)
+		}
 		s.logger.Error().Err(err).Int("part", input.PartNumber).Msg("failed to store part content")
-		return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
+		return nil, storeBodyError(err)
+	}
+	checksumValue, err := checksum.verify()
+	if err != nil {
+		discardBlob(ctx, s.storage, s.blobRepo, s.logger, contentHash)
+		return nil, err
 	}
+	size := receivedSize(s.logger, input.Key, input.Size, received)
 
 	// Get storage path for blob
 	storagePath := s.storage.GetPath(contentHash)
 
 	// Upsert blob metadata
-	_, err = s.blobRepo.UpsertWithRefIncrement(ctx, contentHash, input.Size, storagePath)
+	_, err = s.blobRepo.UpsertWithRefIncrement(ctx, contentHash, size, storagePath)
 	if err != nil {
 		s.logger.Error().Err(err).Str("content_hash", contentHash).Msg("failed to upsert blob")
 		return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
@@ -316,7 +480,9 @@ func (s *MultipartService) UploadPart(ctx context.Context, input UploadPartInput
 	etag := calculatePartETag(contentHash)
 
 	// Create/update part record
-	part := domain.NewUploadPart(uploadID, input.PartNumber, contentHash, etag, input.Size)
+	part := domain.NewUploadPart(uploadID, input.PartNumber, contentHash, etag, size)
+	part.ChecksumAlgorithm = checksum.algorithmOrEmpty()
+	part.Checksum = checksumValue
 	if err := s.multipartRepo.CreatePart(ctx, part); err != nil {
 		s.logger.Error().Err(err).Int("part", input.PartNumber).Msg("failed to create part record")
 		return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
@@ -325,11 +491,105 @@ func (s *MultipartService) UploadPart(ctx context.Context, input UploadPartInput
 	s.logger.Info().
 		Str("upload_id", input.UploadID).
 		Int("part_number", input.PartNumber).
-		Int64("size", input.Size).
+		Int64("size", size).
 		Msg("part uploaded")
 
 	return &UploadPartOutput{
-		ETag: etag,
+		ETag:              etag,
+		LastModified:      part.CreatedAt,
+		ChecksumAlgorithm: part.ChecksumAlgorithm,
+		Checksum:          part.Checksum,
+	}, nil
+}
+
+// UploadPartCopy stores the content of an existing object, or a byte range
+// of it, as a part of a multipart upload.
+func (s *MultipartService) UploadPartCopy(ctx context.Context, input UploadPartCopyInput) (*UploadPartCopyOutput, error) {
+	// Get source bucket
+	sourceBucket, err := s.bucketRepo.GetByName(ctx, input.SourceBucket)
+	if err != nil {
+		if errors.Is(err, domain.ErrBucketNotFound) {
+			return nil, domain.ErrBucketNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+
+	// Check source ownership
+	if !bucketAccessAllowed(ctx, sourceBucket, input.OwnerID) {
+		return nil, ErrBucketAccessDenied
+	}
+
+	// Get source object
+	sourceObj, err := getObjectVersion(ctx, s.objectRepo, sourceBucket, input.SourceKey, input.SourceVersionID)
+	if err != nil {
+		if errors.Is(err, domain.ErrObjectNotFound) || errors.Is(err, domain.ErrInvalidVersionID) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+
+	if sourceObj.IsDeleteMarker || sourceObj.ContentHash == nil {
+		return nil, domain.ErrObjectNotFound
+	}
+
+	// Parts cannot carry customer-provided keys
+	if sourceObj.IsSSECustomerEncrypted() {
+		return nil, ErrSSECustomerKeyRequired
+	}
+
+	// Evaluate x-amz-copy-source-if-* against the source object
+	if err := input.Conditions.Evaluate(sourceObj.ETag, sourceObj.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	// Unlike a GET range, a copy range must lie within the source
+	start, length := int64(0), sourceObj.Size
+	if r := input.SourceRange; r != nil {
+		if r.Start < 0 || r.End < r.Start || r.End >= sourceObj.Size {
+			return nil, domain.ErrInvalidRange
+		}
+		start, length = r.Start, r.End-r.Start+1
+	}
+
+	var reader io.ReadCloser
+	rangeReader, canRange := s.storage.(RangeReader)
+	if canRange && input.SourceRange != nil {
+		reader, err = retrieveBlobRange(ctx, rangeReader, s.blobRepo, *sourceObj.ContentHash, start, length)
+	} else {
+		reader, err = retrieveBlob(ctx, s.storage, s.blobRepo, *sourceObj.ContentHash)
+	}
+	if err != nil {
+		if errors.Is(err, storage.ErrBlobNotFound) {
+			return nil, objectDataMissing(s.logger, s.metrics, input.SourceBucket, sourceObj)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+	defer reader.Close()
+
+	// Without range reads the part is cut out of the whole blob
+	if !canRange && start > 0 {
+		if _, err := io.CopyN(io.Discard, reader, start); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
+		}
+	}
+
+	output, err := s.UploadPart(ctx, UploadPartInput{
+		BucketName: input.BucketName,
+		Key:        input.Key,
+		UploadID:   input.UploadID,
+		PartNumber: input.PartNumber,
+		Body:       io.LimitReader(reader, length),
+		Size:       length,
+		OwnerID:    input.OwnerID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &UploadPartCopyOutput{
+		ETag:            output.ETag,
+		LastModified:    output.LastModified,
+		SourceVersionID: responseVersionID(sourceBucket, sourceObj),
 	}, nil
 }
 
@@ -346,6 +606,9 @@ func (s *MultipartService) CompleteMultipartUpload(ctx context.Context, input Co
 		return nil, domain.ErrMultipartUploadNotFound
 	}
 
+	// Track the write so DeleteBucket can drain it
+	defer bucketWrites.Begin(input.BucketName)()
+
 	// Get bucket
 	bucket, err := s.bucketRepo.GetByName(ctx, input.BucketName)
 	if err != nil {
@@ -356,10 +619,15 @@ func (s *MultipartService) CompleteMultipartUpload(ctx context.Context, input Co
 	}
 
 	// Check ownership
-	if input.OwnerID > 0 && bucket.OwnerID != input.OwnerID {
+	if !bucketAccessAllowed(ctx, bucket, input.OwnerID) {
 		return nil, ErrBucketAccessDenied
 	}
 
+	// Reject writes to a bucket that is being deleted
+	if bucket.IsDeleting() {
+		return nil, domain.ErrBucketDeleting
+	}
+
 	// Get multipart upload
 	upload, err := s.multipartRepo.GetByID(ctx, uploadID)
 	if err != nil {
@@ -409,6 +677,7 @@ func (s *MultipartService) CompleteMultipartUpload(ctx context.Context, input Co
 
 	var totalSize int64
 	etagParts := make([]string, len(input.Parts))
+	checksumParts := make([]string, 0, len(input.Parts))
 	orderedContentHashes := make([]string, len(input.Parts))
 	for i, requestedPart := range input.Parts {
 		storedPart, exists := partMap[requestedPart.PartNumber]
@@ -422,15 +691,51 @@ func (s *MultipartService) CompleteMultipartUpload(ctx context.Context, input Co
 		// Collect ETags for composite ETag calculation
 		etagParts[i] = storedPart.ETag
 		orderedContentHashes[i] = storedPart.ContentHash
+		if storedPart.ChecksumAlgorithm == upload.ChecksumAlgorithm && storedPart.Checksum != "" {
+			checksumParts = append(checksumParts, storedPart.Checksum)
+		}
 	}
 
 	// Calculate composite ETag (MD5 of concatenated part MD5s + "-" + partCount)
 	compositeETag := calculateCompositeETag(etagParts)
 
+	// The composite checksum needs every part's checksum; copied parts have none
+	var compositeChecksum string
+	if upload.ChecksumAlgorithm != "" && len(checksumParts) == len(input.Parts) {
+		compositeChecksum, err = domain.CompositeChecksum(upload.ChecksumAlgorithm, checksumParts)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
+		}
+	}
+
+	// Refuse to assemble an object with a lost or truncated part. Every part
+	// blob must hold its recorded size, so the parts add up to totalSize.
+	for i, hash := range orderedContentHashes {
+		partNumber := input.Parts[i].PartNumber
+		storedSize, err := s.storage.GetSize(ctx, hash)
+		if err != nil && !storage.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
+		}
+		if err != nil || storedSize != partMap[partNumber].Size {
+			s.logger.Error().
+				Str("upload_id", input.UploadID).
+				Int("part_number", partNumber).
+				Str("content_hash", hash).
+				Int64("recorded_size", partMap[partNumber].Size).
+				Int64("stored_size", storedSize).
+				Bool("missing", err != nil).
+				Msg("part blob is missing from storage or has the wrong size")
+			return nil, fmt.Errorf("%w: part %d", domain.ErrPartDataMissing, partNumber)
+		}
+	}
+
 	// Concatenate all parts into a single blob
 	// Create a multi-reader that streams all parts sequentially
 	contentHash, err := s.concatenateParts(ctx, orderedContentHashes, totalSize)
 	if err != nil {
+		if storage.IsStorageFull(err) {
+			return nil, storageFull(s.logger, s.metrics, s.onStorageFull, bucket.Name, input.Key, err)
+		}
 		s.logger.Error().Err(err).Str("upload_id", input.UploadID).Msg("failed to concatenate parts")
 		return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
 	}
@@ -444,16 +749,8 @@ func (s *MultipartService) CompleteMultipartUpload(ctx context.Context, input Co
 	}
 
 	// Handle versioning for destination bucket
-	if bucket.IsVersioningEnabled() {
-		_ = s.objectRepo.MarkNotLatest(ctx, bucket.ID, input.Key)
-	} else {
-		// Non-versioned: clean up existing object
-		existingObj, err := s.objectRepo.GetByKey(ctx, bucket.ID, input.Key)
-		if err == nil && existingObj.ContentHash != nil {
-			_, _ = s.blobRepo.DecrementRef(ctx, *existingObj.ContentHash)
-		}
-		_ = s.objectRepo.MarkNotLatest(ctx, bucket.ID, input.Key)
-	}
+	deltaBaseHash := deltaBase(ctx, s.deltas, s.objectRepo, s.blobRepo, bucket, input.Key)
+	versionID, replaced := prepareObjectWrite(ctx, s.objectRepo, bucket, input.Key)
 
 	// Create final object
 	contentType := "application/octet-stream"
@@ -462,14 +759,31 @@ func (s *MultipartService) CompleteMultipartUpload(ctx context.Context, input Co
 	}
 
 	obj := domain.NewObject(bucket.ID, input.Key, contentHash, contentType, compositeETag, totalSize)
+	obj.NormalizedKey = bucket.NormalizeKey(input.Key)
+	obj.VersionID = versionID
 	obj.Metadata = upload.Metadata
 	obj.StorageClass = upload.StorageClass
+	obj.CacheControl = upload.CacheControl
+	obj.ContentDisposition = upload.ContentDisposition
+	obj.ContentEncoding = upload.ContentEncoding
+	obj.Expires = upload.Expires
+	if compositeChecksum != "" {
+		obj.ChecksumAlgorithm = upload.ChecksumAlgorithm
+		obj.Checksum = compositeChecksum
+	}
+	obj.Lock = newVersionLock(bucket, obj, nil)
 
 	if err := s.objectRepo.Create(ctx, obj); err != nil {
+		// Rollback ref count increment
+		_, _ = s.blobRepo.DecrementRef(ctx, contentHash)
 		s.logger.Error().Err(err).Str("key", input.Key).Msg("failed to create final object")
-		return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
+		return nil, createObjectError(err)
 	}
 
+	s.deltas.Enqueue(deltaBaseHash, obj)
+	releaseReplacedObject(ctx, s.objectRepo, s.blobRepo, s.logger, s.overwriteMode, replaced)
+	enforceVersionLimit(ctx, s.objectRepo, s.blobRepo, s.logger, bucket, input.Key)
+
 	// Update upload status
 	if err := s.multipartRepo.UpdateStatus(ctx, uploadID, domain.MultipartStatusCompleted); err != nil {
 		s.logger.Error().Err(err).Str("upload_id", input.UploadID).Msg("failed to update upload status")
@@ -483,12 +797,26 @@ func (s *MultipartService) CompleteMultipartUpload(ctx context.Context, input Co
 		Int("part_count", len(input.Parts)).
 		Msg("multipart upload completed")
 
+	s.events.Publish(events.Event{
+		Type:        events.ObjectCreatedCompleteMultipartUpload,
+		Bucket:      input.BucketName,
+		Key:         input.Key,
+		VersionID:   responseVersionID(bucket, obj),
+		ETag:        compositeETag,
+		Size:        totalSize,
+		ContentType: obj.ContentType,
+		ContentHash: contentHash,
+		OwnerID:     input.OwnerID,
+	})
+
 	return &CompleteMultipartUploadOutput{
-		Location:  fmt.Sprintf("/%s/%s", input.BucketName, input.Key),
-		Bucket:    input.BucketName,
-		Key:       input.Key,
-		ETag:      compositeETag,
-		VersionID: obj.GetVersionIDString(),
+		Location:          fmt.Sprintf("/%s/%s", input.BucketName, input.Key),
+		Bucket:            input.BucketName,
+		Key:               input.Key,
+		ETag:              compositeETag,
+		VersionID:         responseVersionID(bucket, obj),
+		ChecksumAlgorithm: obj.ChecksumAlgorithm,
+		Checksum:          obj.Checksum,
 	}, nil
 }
 
@@ -510,7 +838,7 @@ func (s *MultipartService) AbortMultipartUpload(ctx context.Context, input Abort
 	}
 
 	// Check ownership
-	if input.OwnerID > 0 && bucket.OwnerID != input.OwnerID {
+	if !bucketAccessAllowed(ctx, bucket, input.OwnerID) {
 		return ErrBucketAccessDenied
 	}
 
@@ -567,7 +895,7 @@ func (s *MultipartService) ListMultipartUploads(ctx context.Context, input ListM
 	}
 
 	// Check ownership
-	if input.OwnerID > 0 && bucket.OwnerID != input.OwnerID {
+	if !bucketAccessAllowed(ctx, bucket, input.OwnerID) {
 		return nil, ErrBucketAccessDenied
 	}
 
@@ -581,7 +909,7 @@ func (s *MultipartService) ListMultipartUploads(ctx context.Context, input ListM
 	}
 
 	// List uploads
-	result, err := s.multipartRepo.List(ctx, bucket.ID, repository.MultipartListOptions{
+	result, err := repository.ListUploadsWithDelimiter(ctx, s.multipartRepo, bucket.ID, repository.MultipartListOptions{
 		Prefix:         input.Prefix,
 		Delimiter:      input.Delimiter,
 		KeyMarker:      input.KeyMarker,
@@ -636,7 +964,7 @@ func (s *MultipartService) ListParts(ctx context.Context, input ListPartsInput)
 	}
 
 	// Check ownership
-	if input.OwnerID > 0 && bucket.OwnerID != input.OwnerID {
+	if !bucketAccessAllowed(ctx, bucket, input.OwnerID) {
 		return nil, ErrBucketAccessDenied
 	}
 
@@ -696,6 +1024,73 @@ func (s *MultipartService) ListParts(ctx context.Context, input ListPartsInput)
 	}, nil
 }
 
+// ReportActiveUploads sets the in-progress multipart upload gauge every
+// interval until ctx is done. It does nothing without metrics.
+func (s *MultipartService) ReportActiveUploads(ctx context.Context, interval time.Duration) {
+	if s.metrics == nil {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		count, err := s.multipartRepo.CountInProgress(ctx)
+		switch {
+		case err == nil:
+			s.metrics.SetMultipartUploads(count)
+		case ctx.Err() == nil:
+			s.logger.Warn().Err(err).Msg("failed to count in-progress multipart uploads")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ReconcileParts drops parts of in-progress uploads whose blobs are missing
+// from storage, e.g. after a crash between writing the part record and the
+// blob, or after blobs were lost on disk. Such parts are omitted from ListParts
+// and rejected as invalid on completion, so clients re-upload them instead of
+// failing late with an internal error. Returns the number of parts removed.
+// It is meant to run once at startup, before requests are served.
+func (s *MultipartService) ReconcileParts(ctx context.Context) (int, error) {
+	parts, err := s.multipartRepo.ListInProgressParts(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+
+	removed := 0
+	for _, part := range parts {
+		exists, err := s.storage.Exists(ctx, part.ContentHash)
+		if err != nil {
+			return removed, fmt.Errorf("%w: %v", ErrInternalError, err)
+		}
+		if exists {
+			continue
+		}
+
+		if err := s.multipartRepo.DeletePart(ctx, part.UploadID, part.PartNumber); err != nil && !errors.Is(err, domain.ErrPartNotFound) {
+			return removed, fmt.Errorf("%w: %v", ErrInternalError, err)
+		}
+		if _, err := s.blobRepo.DecrementRef(ctx, part.ContentHash); err != nil && !errors.Is(err, domain.ErrBlobNotFound) {
+			s.logger.Warn().Err(err).Str("content_hash", part.ContentHash).Msg("failed to release blob of missing part")
+		}
+
+		s.logger.Warn().
+			Str("upload_id", part.UploadID.String()).
+			Int("part_number", part.PartNumber).
+			Str("content_hash", part.ContentHash).
+			Msg("removed multipart part with missing blob")
+		removed++
+	}
+
+	return removed, nil
+}
+
 // =============================================================================
 // Helper Functions
 // =============================================================================
@@ -708,12 +1103,12 @@ func calculatePartETag(contentHash string) string {
 
 // calculateCompositeETag generates a composite ETag for multipart uploads.
 // Format: "{md5-of-concatenated-part-etags}-{partCount}"
+// It depends only on the part ETags as recorded, never on how the parts'
+// content is stored, so an upload's ETag is the same whatever the storage.
 func calculateCompositeETag(partETags []string) string {
 	h := md5.New()
 	for _, etag := range partETags {
-		// Strip quotes from ETag
-		etag = etag[1 : len(etag)-1]
-		data, _ := hex.DecodeString(etag)
+		data, _ := hex.DecodeString(strings.Trim(etag, `"`))
 		h.Write(data)
 	}
 	return fmt.Sprintf("\"%s-%d\"", hex.EncodeToString(h.Sum(nil)), len(partETags))
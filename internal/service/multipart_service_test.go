@@ -69,6 +69,11 @@ func (m *mockMultipartRepository) DeleteExpired(ctx context.Context) (int64, err
 	return args.Get(0).(int64), args.Error(1)
 }
 
+func (m *mockMultipartRepository) CountInProgress(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func (m *mockMultipartRepository) CreatePart(ctx context.Context, part *domain.UploadPart) error {
 	args := m.Called(ctx, part)
 	return args.Error(0)
@@ -103,6 +108,19 @@ func (m *mockMultipartRepository) GetPartsForCompletion(ctx context.Context, upl
 	return args.Get(0).([]*domain.UploadPart), args.Error(1)
 }
 
+func (m *mockMultipartRepository) ListInProgressParts(ctx context.Context) ([]*domain.UploadPart, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.UploadPart), args.Error(1)
+}
+
+func (m *mockMultipartRepository) DeletePart(ctx context.Context, uploadID uuid.UUID, partNumber int) error {
+	args := m.Called(ctx, uploadID, partNumber)
+	return args.Error(0)
+}
+
 // =============================================================================
 // Helper Functions
 // =============================================================================
@@ -467,3 +485,20 @@ func TestMultipartService_ListParts_InvalidUploadID(t *testing.T) {
 
 	require.ErrorIs(t, err, domain.ErrMultipartUploadNotFound)
 }
+
+func TestCalculateCompositeETag(t *testing.T) {
+	// MD5s of "part one", "part two" and "part three", as AWS reports them
+	partETags := []string{
+		`"3303e12af474ca11d85ed2966a932992"`,
+		`"3ea4e15b91a17dc76052c56cfcdf67a2"`,
+		`"3a2d06a813d5a386c320118357b2625b"`,
+	}
+	require.Equal(t, `"9c46b2a5c836d5b6fff429d90cae24cf-3"`, calculateCompositeETag(partETags))
+
+	// Only the recorded ETags matter, however they are quoted
+	unquoted := make([]string, len(partETags))
+	for i, etag := range partETags {
+		unquoted[i] = etag[1 : len(etag)-1]
+	}
+	require.Equal(t, calculateCompositeETag(partETags), calculateCompositeETag(unquoted))
+}
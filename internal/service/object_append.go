@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/lock"
+	"github.com/prn-tf/alexander-storage/internal/storage"
+)
+
+// appendLockTTL bounds how long an append holds its object's upload lock.
+const appendLockTTL = 10 * time.Minute
+
+// appendBase is the current content of an object a PutObject with a write
+// offset (x-amz-write-offset-bytes) appends to. The object stays locked
+// against other appends until Close.
+type appendBase struct {
+	obj     *domain.Object // nil when the append creates the object
+	content io.ReadCloser
+	release func()
+}
+
+// prepareAppend locks the object against concurrent appends and opens its
+// current content, checking that the write offset is the object's size. An
+// append at offset 0 creates an object that does not exist yet.
+func (s *ObjectService) prepareAppend(ctx context.Context, bucket *domain.Bucket, input PutObjectInput) (*appendBase, error) {
+	if !s.allowAppend {
+		return nil, domain.ErrAppendNotEnabled
+	}
+	if input.SSECustomerKey != nil {
+		return nil, domain.ErrAppendNotSupported
+	}
+
+	// A second append at the same offset could only fail the check below
+	lockKey := lock.Keys.ObjectUpload(bucket.ID, bucket.NormalizeKey(input.Key))
+	acquired, err := s.locker.Acquire(ctx, lockKey, appendLockTTL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+	if !acquired {
+		return nil, fmt.Errorf("%w: another append is in progress", domain.ErrWriteOffsetMismatch)
+	}
+	base := &appendBase{release: func() {
+		if _, err := s.locker.Release(context.WithoutCancel(ctx), lockKey); err != nil {
+			s.logger.Warn().Err(err).Str("key", input.Key).Msg("failed to release append lock")
+		}
+	}}
+
+	obj, err := getObjectVersion(ctx, s.objectRepo, bucket, input.Key, "")
+	switch {
+	case errors.Is(err, domain.ErrObjectNotFound):
+	case err != nil:
+		base.Close()
+		return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
+	case !obj.IsDeleteMarker && obj.ContentHash != nil:
+		base.obj = obj
+	}
+
+	if *input.WriteOffset != base.size() {
+		base.Close()
+		return nil, fmt.Errorf("%w: offset %d, object size %d", domain.ErrWriteOffsetMismatch, *input.WriteOffset, base.size())
+	}
+	if base.obj == nil {
+		return base, nil
+	}
+	if base.obj.IsSSECustomerEncrypted() {
+		base.Close()
+		return nil, domain.ErrAppendNotSupported
+	}
+
+	base.content, err = s.retrieveBlob(ctx, *base.obj.ContentHash)
+	if err != nil {
+		base.Close()
+		if errors.Is(err, storage.ErrBlobNotFound) {
+			return nil, s.objectDataMissing(bucket.Name, base.obj)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+	return base, nil
+}
+
+// size returns the size of the content appended to.
+func (b *appendBase) size() int64 {
+	if b == nil || b.obj == nil {
+		return 0
+	}
+	return b.obj.Size
+}
+
+// prepends reports whether stored content precedes the appended body.
+func (b *appendBase) prepends() bool {
+	return b != nil && b.content != nil
+}
+
+// prepend returns body preceded by the current content, and the size to
+// store it with. An expected size of 0 leaves the size unverified.
+func (b *appendBase) prepend(body io.Reader, expectedSize int64) (io.Reader, int64) {
+	if !b.prepends() {
+		return body, expectedSize
+	}
+	if expectedSize > 0 {
+		expectedSize += b.obj.Size
+	}
+	return io.MultiReader(b.content, body), expectedSize
+}
+
+// Close closes the current content and releases the object's lock.
+func (b *appendBase) Close() {
+	if b.content != nil {
+		b.content.Close()
+	}
+	b.release()
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+)
+
+func TestObjectService_PutObject_Append(t *testing.T) {
+	ctx := context.Background()
+	dir, ownerID := setupMultipartRestart(t)
+	inst := startMultipartInstance(t, dir)
+	defer inst.db.Close()
+
+	appendAt := func(offset int64, data string) error {
+		_, err := inst.objects.PutObject(ctx, PutObjectInput{
+			BucketName:  "uploads",
+			Key:         "app.log",
+			Body:        strings.NewReader(data),
+			Size:        int64(len(data)),
+			ContentType: "text/plain",
+			OwnerID:     ownerID,
+			WriteOffset: &offset,
+		})
+		return err
+	}
+	content := func() string {
+		out, err := inst.objects.GetObject(ctx, GetObjectInput{BucketName: "uploads", Key: "app.log", OwnerID: ownerID})
+		require.NoError(t, err)
+		defer out.Body.Close()
+		assert.Equal(t, "text/plain", out.ContentType)
+		data, err := io.ReadAll(out.Body)
+		require.NoError(t, err)
+		assert.Equal(t, int64(len(data)), out.ContentLength)
+		return string(data)
+	}
+
+	assert.ErrorIs(t, appendAt(0, "line 1\n"), domain.ErrAppendNotEnabled)
+	inst.objects.SetAllowAppend(true)
+
+	// An append at offset 0 creates the object
+	require.NoError(t, appendAt(0, "line 1\n"))
+	require.NoError(t, appendAt(7, "line 2\n"))
+	assert.Equal(t, "line 1\nline 2\n", content())
+
+	// A writer that missed the last append is rejected without changing the object
+	assert.ErrorIs(t, appendAt(7, "line 3\n"), domain.ErrWriteOffsetMismatch)
+	assert.ErrorIs(t, appendAt(0, "line 3\n"), domain.ErrWriteOffsetMismatch)
+	assert.ErrorIs(t, appendAt(100, "line 3\n"), domain.ErrWriteOffsetMismatch)
+	assert.Equal(t, "line 1\nline 2\n", content())
+
+	require.NoError(t, appendAt(14, "line 3\n"))
+	assert.Equal(t, "line 1\nline 2\nline 3\n", content())
+}
@@ -0,0 +1,287 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/google/uuid"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/events"
+	"github.com/prn-tf/alexander-storage/internal/repository"
+	"github.com/prn-tf/alexander-storage/internal/storage"
+)
+
+// MaxBatchRecords is the maximum number of records accepted in one batch.
+const MaxBatchRecords = 10000
+
+// batchCommitSize is the number of records whose metadata is committed in
+// one transaction.
+const batchCommitSize = 500
+
+// BatchRecord is a single object in a batch ingestion stream.
+type BatchRecord struct {
+	Key         string
+	ContentType string
+	Size        int64
+	Body        io.Reader
+}
+
+// BatchRecordReader yields batch records in stream order.
+// Next returns io.EOF after the last record.
+type BatchRecordReader interface {
+	Next() (*BatchRecord, error)
+}
+
+// BatchPutObjectsInput contains the data needed to ingest a batch of objects.
+type BatchPutObjectsInput struct {
+	BucketName string
+	Records    BatchRecordReader
+	OwnerID    int64
+
+	// AllowKey optionally restricts which keys may be written.
+	// Rejected records fail with ErrBucketAccessDenied.
+	AllowKey func(key string) bool
+}
+
+// BatchPutResult is the outcome of storing a single batch record.
+type BatchPutResult struct {
+	Key       string
+	ETag      string
+	VersionID string
+	Err       error
+}
+
+// BatchPutObjectsOutput contains the per-record results of a batch ingestion.
+type BatchPutObjectsOutput struct {
+	Results   []BatchPutResult
+	Succeeded int
+	Failed    int
+}
+
+// BatchPutObjects stores every record from input.Records in a single bucket.
+// The bucket and ownership are checked once up front. Each record's content
+// is stored as it is read, and the metadata of up to batchCommitSize records
+// is then committed in one transaction, so deduplication and versioning apply
+// as for PutObject without a commit per object. Repositories that cannot
+// batch writes get a PutObject per record. A failing record does not stop
+// the batch. A malformed stream does, and the results gathered so far are
+// returned together with ErrMalformedBatch.
+func (s *ObjectService) BatchPutObjects(ctx context.Context, input BatchPutObjectsInput) (*BatchPutObjectsOutput, error) {
+	// Track the write so DeleteBucket can drain it
+	defer bucketWrites.Begin(input.BucketName)()
+
+	bucket, err := s.bucketRepo.GetByName(ctx, input.BucketName)
+	if err != nil {
+		if errors.Is(err, domain.ErrBucketNotFound) {
+			return nil, domain.ErrBucketNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+
+	if !bucketAccessAllowed(ctx, bucket, input.OwnerID) {
+		return nil, ErrBucketAccessDenied
+	}
+
+	// Reject writes to a bucket that is being deleted
+	if bucket.IsDeleting() {
+		return nil, domain.ErrBucketDeleting
+	}
+
+	writer, batched := s.objectRepo.(repository.ObjectBatchWriter)
+	output := &BatchPutObjectsOutput{}
+	var pending []*batchWrite
+
+	// flush commits the pending writes and records their results
+	flush := func() {
+		if len(pending) > 0 {
+			s.commitBatchWrites(ctx, writer, bucket, input.OwnerID, pending, output)
+			pending = pending[:0]
+		}
+	}
+	defer flush()
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return output, err
+		}
+
+		record, err := input.Records.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return output, fmt.Errorf("%w: %v", ErrMalformedBatch, err)
+		}
+
+		if len(output.Results) >= MaxBatchRecords {
+			return output, fmt.Errorf("%w: more than %d records", ErrMalformedBatch, MaxBatchRecords)
+		}
+
+		output.Results = append(output.Results, BatchPutResult{Key: record.Key})
+		index := len(output.Results) - 1
+		if input.AllowKey != nil && !input.AllowKey(record.Key) {
+			output.setResult(index, nil, ErrBucketAccessDenied)
+			continue
+		}
+
+		if !batched {
+			put, err := s.PutObject(ctx, PutObjectInput{
+				BucketName:  input.BucketName,
+				Key:         record.Key,
+				Body:        record.Body,
+				Size:        record.Size,
+				ContentType: record.ContentType,
+				OwnerID:     input.OwnerID,
+			})
+			output.setResult(index, put, err)
+			continue
+		}
+
+		write, err := s.stageBatchRecord(ctx, bucket, record)
+		if err != nil {
+			output.setResult(index, nil, err)
+			continue
+		}
+		write.index = index
+		pending = append(pending, write)
+		if len(pending) >= batchCommitSize {
+			flush()
+		}
+	}
+	flush()
+
+	s.logger.Info().
+		Str("bucket", input.BucketName).
+		Int("succeeded", output.Succeeded).
+		Int("failed", output.Failed).
+		Msg("batch ingestion completed")
+
+	return output, nil
+}
+
+// batchWrite is a batch record whose content is stored and whose metadata
+// waits to be committed.
+type batchWrite struct {
+	index     int
+	write     repository.ObjectWrite
+	deltaBase string
+}
+
+// setResult records the outcome of the record at index.
+func (o *BatchPutObjectsOutput) setResult(index int, put *PutObjectOutput, err error) {
+	result := &o.Results[index]
+	if err != nil {
+		result.Err = err
+		o.Failed++
+		return
+	}
+	result.ETag = put.ETag
+	result.VersionID = put.VersionID
+	o.Succeeded++
+}
+
+// stageBatchRecord validates a record and stores its content, returning the
+// object write to commit. It applies the checks PutObject applies to a plain
+// upload.
+func (s *ObjectService) stageBatchRecord(ctx context.Context, bucket *domain.Bucket, record *BatchRecord) (*batchWrite, error) {
+	if err := validateObjectKey(record.Key); err != nil {
+		return nil, err
+	}
+	if err := validateKeyDepth(record.Key, s.maxKeyDepth); err != nil {
+		return nil, err
+	}
+	if !bucket.AcceptsEncryption(false) {
+		return nil, domain.ErrEncryptionRequired
+	}
+
+	contentType := record.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	if !bucket.ContentTypePolicy.Allows(contentType) {
+		return nil, domain.ErrContentTypeNotAllowed
+	}
+
+	body, expectedSize, received := s.sizePolicy.prepareBody(record.Body, record.Size)
+	contentHash, err := s.storage.Store(storage.WithContentType(ctx, contentType), body, expectedSize)
+	if err != nil {
+		if storage.IsStorageFull(err) {
+			return nil, s.storageFull(bucket.Name, record.Key, err)
+		}
+		s.logger.Error().Err(err).Str("key", record.Key).Msg("failed to store content")
+		return nil, storeBodyError(err)
+	}
+	size := receivedSize(s.logger, record.Key, record.Size, received)
+
+	obj := domain.NewObject(bucket.ID, record.Key, contentHash, contentType, calculateETag(contentHash), size)
+	obj.NormalizedKey = bucket.NormalizeKey(record.Key)
+	obj.Lock = newVersionLock(bucket, obj, nil)
+
+	write := &batchWrite{
+		write: repository.ObjectWrite{
+			Object:      obj,
+			StoredSize:  size,
+			StoragePath: s.storage.GetPath(contentHash),
+		},
+		deltaBase: deltaBase(ctx, s.deltas, s.objectRepo, s.blobRepo, bucket, record.Key),
+	}
+	// Only enabled buckets keep every version, see prepareObjectWrite
+	switch bucket.Versioning {
+	case domain.VersioningEnabled:
+		write.write.Replace = repository.ReplaceNone
+	case domain.VersioningSuspended:
+		obj.VersionID = uuid.Nil
+		write.write.Replace = repository.ReplaceNullVersion
+	default:
+		obj.VersionID = uuid.Nil
+		write.write.Replace = repository.ReplaceLatest
+	}
+	return write, nil
+}
+
+// commitBatchWrites commits the metadata of the pending writes in one
+// transaction and finishes each stored object as PutObject does.
+func (s *ObjectService) commitBatchWrites(ctx context.Context, writer repository.ObjectBatchWriter, bucket *domain.Bucket, ownerID int64, pending []*batchWrite, output *BatchPutObjectsOutput) {
+	writes := make([]*repository.ObjectWrite, len(pending))
+	for i, w := range pending {
+		writes[i] = &w.write
+	}
+
+	if err := writer.CreateBatch(ctx, writes); err != nil {
+		s.logger.Error().Err(err).Str("bucket", bucket.Name).Int("records", len(writes)).Msg("failed to commit batch records")
+		for _, w := range pending {
+			output.setResult(w.index, nil, fmt.Errorf("%w: %v", ErrInternalError, err))
+		}
+		return
+	}
+
+	for _, w := range pending {
+		obj := w.write.Object
+		if w.write.Err != nil {
+			s.logger.Error().Err(w.write.Err).Str("key", obj.Key).Msg("failed to create object")
+			output.setResult(w.index, nil, createObjectError(w.write.Err))
+			continue
+		}
+
+		s.deltas.Enqueue(w.deltaBase, obj)
+		releaseReplacedObject(ctx, s.objectRepo, s.blobRepo, s.logger, s.overwriteMode, w.write.Replaced)
+		enforceVersionLimit(ctx, s.objectRepo, s.blobRepo, s.logger, bucket, obj.Key)
+
+		versionID := responseVersionID(bucket, obj)
+		s.events.Publish(events.Event{
+			Type:        events.ObjectCreatedPut,
+			Bucket:      bucket.Name,
+			Key:         obj.Key,
+			VersionID:   versionID,
+			ETag:        obj.ETag,
+			Size:        obj.Size,
+			ContentType: obj.ContentType,
+			ContentHash: *obj.ContentHash,
+			OwnerID:     ownerID,
+		})
+		output.setResult(w.index, &PutObjectOutput{ETag: obj.ETag, VersionID: versionID}, nil)
+	}
+}
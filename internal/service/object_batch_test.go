@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/lock"
+	"github.com/prn-tf/alexander-storage/internal/repository"
+	"github.com/prn-tf/alexander-storage/internal/repository/sqlite"
+)
+
+// sliceRecords yields batch records from a slice.
+type sliceRecords []*BatchRecord
+
+func (r *sliceRecords) Next() (*BatchRecord, error) {
+	if len(*r) == 0 {
+		return nil, io.EOF
+	}
+	record := (*r)[0]
+	*r = (*r)[1:]
+	return record, nil
+}
+
+// countingObjectRepository counts the transactions object writes go through.
+type countingObjectRepository struct {
+	repository.ObjectRepository
+	creates int
+	batches int
+}
+
+func (r *countingObjectRepository) Create(ctx context.Context, obj *domain.Object) error {
+	r.creates++
+	return r.ObjectRepository.Create(ctx, obj)
+}
+
+func (r *countingObjectRepository) CreateBatch(ctx context.Context, writes []*repository.ObjectWrite) error {
+	r.batches++
+	return r.ObjectRepository.(repository.ObjectBatchWriter).CreateBatch(ctx, writes)
+}
+
+func TestBatchPutObjects_CommitsRecordsTogether(t *testing.T) {
+	ctx := context.Background()
+	dir, ownerID := setupMultipartRestart(t)
+	inst := startMultipartInstance(t, dir)
+	defer inst.db.Close()
+
+	objects := &countingObjectRepository{ObjectRepository: sqlite.NewObjectRepository(inst.db)}
+	blobs := sqlite.NewBlobRepository(inst.db)
+	svc := NewObjectService(objects, blobs, sqlite.NewBucketRepository(inst.db), inst.storage, lock.NewNoOpLocker(), zerolog.Nop())
+
+	const n = 2*batchCommitSize + 10
+	var records sliceRecords
+	for i := 0; i < n; i++ {
+		body := fmt.Sprintf("record %d", i%50) // duplicates share blobs
+		records = append(records, &BatchRecord{Key: fmt.Sprintf("logs/%04d.txt", i), Size: int64(len(body)), Body: strings.NewReader(body)})
+	}
+	// An invalid key fails alone, and a key written twice keeps the last body
+	records = append(records,
+		&BatchRecord{Key: "", Size: 3, Body: strings.NewReader("bad")},
+		&BatchRecord{Key: "logs/0000.txt", Size: 9, Body: strings.NewReader("rewritten")},
+	)
+
+	out, err := svc.BatchPutObjects(ctx, BatchPutObjectsInput{BucketName: "uploads", Records: &records, OwnerID: ownerID})
+	require.NoError(t, err)
+	require.Len(t, out.Results, n+2)
+	assert.Equal(t, n+1, out.Succeeded)
+	assert.Equal(t, 1, out.Failed)
+	assert.Error(t, out.Results[n].Err)
+	for i, result := range out.Results {
+		if i != n {
+			require.NoError(t, result.Err, result.Key)
+			assert.NotEmpty(t, result.ETag)
+		}
+	}
+
+	// One transaction per batchCommitSize records rather than one per object
+	assert.Equal(t, 3, objects.batches)
+	assert.Zero(t, objects.creates)
+
+	get, err := svc.GetObject(ctx, GetObjectInput{BucketName: "uploads", Key: "logs/0000.txt", OwnerID: ownerID})
+	require.NoError(t, err)
+	data, err := io.ReadAll(get.Body)
+	get.Body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "rewritten", string(data))
+
+	// Each shared blob holds one reference per object, the overwritten one released
+	sum := sha256.Sum256([]byte("record 0"))
+	blob, err := blobs.GetByHash(ctx, hex.EncodeToString(sum[:]))
+	require.NoError(t, err)
+	assert.EqualValues(t, n/50, blob.RefCount)
+}
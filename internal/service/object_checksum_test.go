@@ -0,0 +1,130 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"hash/crc32"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+)
+
+func crc32cChecksum(data []byte) string {
+	sum := crc32.Checksum(data, crc32.MakeTable(crc32.Castagnoli))
+	return base64.StdEncoding.EncodeToString([]byte{byte(sum >> 24), byte(sum >> 16), byte(sum >> 8), byte(sum)})
+}
+
+func TestPutObject_Checksum(t *testing.T) {
+	ctx := context.Background()
+	dir, ownerID := setupMultipartRestart(t)
+	inst := startMultipartInstance(t, dir)
+	defer inst.db.Close()
+
+	content := []byte("checksummed content")
+	put := func(key string, algorithm domain.ChecksumAlgorithm, checksum string) (*PutObjectOutput, error) {
+		return inst.objects.PutObject(ctx, PutObjectInput{
+			BucketName:        "uploads",
+			Key:               key,
+			Body:              bytes.NewReader(content),
+			Size:              int64(len(content)),
+			OwnerID:           ownerID,
+			ChecksumAlgorithm: algorithm,
+			Checksum:          checksum,
+		})
+	}
+
+	// A correct checksum is stored and returned on reads
+	out, err := put("verified.txt", domain.ChecksumCRC32C, crc32cChecksum(content))
+	require.NoError(t, err)
+	assert.Equal(t, domain.ChecksumCRC32C, out.ChecksumAlgorithm)
+	assert.Equal(t, crc32cChecksum(content), out.Checksum)
+
+	head, err := inst.objects.HeadObject(ctx, HeadObjectInput{BucketName: "uploads", Key: "verified.txt", OwnerID: ownerID})
+	require.NoError(t, err)
+	assert.Equal(t, domain.ChecksumCRC32C, head.ChecksumAlgorithm)
+	assert.Equal(t, crc32cChecksum(content), head.Checksum)
+
+	// Only the algorithm: the server computes the checksum
+	out, err = put("computed.txt", domain.ChecksumCRC32C, "")
+	require.NoError(t, err)
+	assert.Equal(t, crc32cChecksum(content), out.Checksum)
+
+	// A mismatching checksum rejects the upload
+	_, err = put("corrupted.txt", domain.ChecksumCRC32C, crc32cChecksum([]byte("other content")))
+	require.ErrorIs(t, err, domain.ErrChecksumMismatch)
+	_, err = inst.objects.HeadObject(ctx, HeadObjectInput{BucketName: "uploads", Key: "corrupted.txt", OwnerID: ownerID})
+	require.ErrorIs(t, err, domain.ErrObjectNotFound)
+
+	// A checksum that cannot be of the algorithm is invalid
+	_, err = put("invalid.txt", domain.ChecksumSHA256, crc32cChecksum(content))
+	require.ErrorIs(t, err, domain.ErrInvalidChecksum)
+}
+
+func TestMultipartUpload_CompositeChecksum(t *testing.T) {
+	ctx := context.Background()
+	dir, ownerID := setupMultipartRestart(t)
+	inst := startMultipartInstance(t, dir)
+	defer inst.db.Close()
+
+	initiated, err := inst.multipart.InitiateMultipartUpload(ctx, InitiateMultipartUploadInput{
+		BucketName:        "uploads",
+		Key:               "video.bin",
+		OwnerID:           ownerID,
+		ChecksumAlgorithm: domain.ChecksumCRC32C,
+	})
+	require.NoError(t, err)
+
+	parts := [][]byte{bytes.Repeat([]byte("1"), 1024), bytes.Repeat([]byte("2"), 512)}
+	completed := make([]domain.CompletedPart, len(parts))
+	for i, data := range parts {
+		out, err := inst.multipart.UploadPart(ctx, UploadPartInput{
+			BucketName: "uploads",
+			Key:        "video.bin",
+			UploadID:   initiated.UploadID,
+			PartNumber: i + 1,
+			Body:       bytes.NewReader(data),
+			Size:       int64(len(data)),
+			OwnerID:    ownerID,
+			Checksum:   crc32cChecksum(data),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, domain.ChecksumCRC32C, out.ChecksumAlgorithm)
+		completed[i] = domain.CompletedPart{PartNumber: i + 1, ETag: out.ETag}
+	}
+
+	// A part checksummed with another algorithm than the upload's is rejected
+	_, err = inst.multipart.UploadPart(ctx, UploadPartInput{
+		BucketName:        "uploads",
+		Key:               "video.bin",
+		UploadID:          initiated.UploadID,
+		PartNumber:        3,
+		Body:              bytes.NewReader(parts[0]),
+		Size:              int64(len(parts[0])),
+		OwnerID:           ownerID,
+		ChecksumAlgorithm: domain.ChecksumSHA1,
+	})
+	require.ErrorIs(t, err, domain.ErrInvalidChecksum)
+
+	out, err := inst.multipart.CompleteMultipartUpload(ctx, CompleteMultipartUploadInput{
+		BucketName: "uploads",
+		Key:        "video.bin",
+		UploadID:   initiated.UploadID,
+		Parts:      completed,
+		OwnerID:    ownerID,
+	})
+	require.NoError(t, err)
+
+	want, err := domain.CompositeChecksum(domain.ChecksumCRC32C, []string{crc32cChecksum(parts[0]), crc32cChecksum(parts[1])})
+	require.NoError(t, err)
+	assert.Equal(t, domain.ChecksumCRC32C, out.ChecksumAlgorithm)
+	assert.Equal(t, want, out.Checksum)
+	assert.Regexp(t, `-2$`, out.Checksum)
+
+	head, err := inst.objects.HeadObject(ctx, HeadObjectInput{BucketName: "uploads", Key: "video.bin", OwnerID: ownerID})
+	require.NoError(t, err)
+	assert.Equal(t, want, head.Checksum)
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"time"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+)
+
+// ObjectConditions holds the If-Match, If-None-Match, If-Modified-Since and
+// If-Unmodified-Since headers of a GET or HEAD request.
+// Empty strings and nil times mean the header was not sent.
+type ObjectConditions struct {
+	IfMatch           string
+	IfNoneMatch       string
+	IfModifiedSince   *time.Time
+	IfUnmodifiedSince *time.Time
+}
+
+// Evaluate checks the conditions against the object's ETag and last-modified
+// time. It returns domain.ErrPreconditionFailed when If-Match or
+// If-Unmodified-Since fails and domain.ErrNotModified when If-None-Match or
+// If-Modified-Since fails.
+//
+// As in RFC 7232 and S3, If-Match takes precedence over If-Unmodified-Since
+// and If-None-Match over If-Modified-Since, and the 412 conditions are
+// checked before the 304 ones.
+func (c ObjectConditions) Evaluate(etag string, lastModified time.Time) error {
+	// HTTP dates have second precision
+	lastModified = lastModified.UTC().Truncate(time.Second)
+
+	if c.IfMatch != "" {
+		if !etagMatches(c.IfMatch, etag) {
+			return domain.ErrPreconditionFailed
+		}
+	} else if c.IfUnmodifiedSince != nil && lastModified.After(*c.IfUnmodifiedSince) {
+		return domain.ErrPreconditionFailed
+	}
+
+	if c.IfNoneMatch != "" {
+		if etagMatches(c.IfNoneMatch, etag) {
+			return domain.ErrNotModified
+		}
+	} else if c.IfModifiedSince != nil && !lastModified.After(*c.IfModifiedSince) {
+		return domain.ErrNotModified
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"io"
+	"sync/atomic"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/lock"
+	"github.com/prn-tf/alexander-storage/internal/repository/sqlite"
+	"github.com/prn-tf/alexander-storage/internal/storage"
+)
+
+// countingStorage counts the content written to and read from a backend.
+type countingStorage struct {
+	storage.Backend
+	stored    atomic.Int64
+	retrieved atomic.Int64
+}
+
+func (s *countingStorage) Store(ctx context.Context, reader io.Reader, size int64) (string, error) {
+	n := &countingReader{r: reader}
+	hash, err := s.Backend.Store(ctx, n, size)
+	s.stored.Add(n.n)
+	return hash, err
+}
+
+func (s *countingStorage) Retrieve(ctx context.Context, contentHash string) (io.ReadCloser, error) {
+	s.retrieved.Add(1)
+	return s.Backend.Retrieve(ctx, contentHash)
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+func TestObjectService_CopyObjectSharesBlob(t *testing.T) {
+	ctx := context.Background()
+
+	inst := startMultipartInstance(t, t.TempDir())
+	defer inst.db.Close()
+
+	user := domain.NewUser("tester", "tester@example.com", "hash")
+	require.NoError(t, sqlite.NewUserRepository(inst.db).Create(ctx, user))
+
+	buckets := NewBucketService(sqlite.NewBucketRepository(inst.db), zerolog.Nop())
+	_, err := buckets.CreateBucket(ctx, CreateBucketInput{Name: "media", OwnerID: user.ID})
+	require.NoError(t, err)
+
+	bucket, err := sqlite.NewBucketRepository(inst.db).GetByName(ctx, "media")
+	require.NoError(t, err)
+
+	store := &countingStorage{Backend: inst.storage}
+	objectRepo := sqlite.NewObjectRepository(inst.db)
+	blobRepo := sqlite.NewBlobRepository(inst.db)
+	objects := NewObjectService(objectRepo, blobRepo, sqlite.NewBucketRepository(inst.db), store, lock.NewNoOpLocker(), zerolog.Nop())
+
+	content := make([]byte, 100*1024*1024)
+	_, err = rand.Read(content)
+	require.NoError(t, err)
+
+	put, err := objects.PutObject(ctx, PutObjectInput{
+		BucketName: "media",
+		Key:        "video.bin",
+		Body:       bytes.NewReader(content),
+		Size:       int64(len(content)),
+		OwnerID:    user.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(len(content)), store.stored.Load())
+
+	source, err := objectRepo.GetByKey(ctx, bucket.ID, "video.bin")
+	require.NoError(t, err)
+	refs, err := blobRepo.GetRefCount(ctx, *source.ContentHash)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), refs)
+
+	// A same-scheme copy references the source blob without touching its bytes,
+	// also when the metadata is replaced
+	for i, directive := range []string{"COPY", "REPLACE"} {
+		copied, err := objects.CopyObject(ctx, CopyObjectInput{
+			SourceBucket:      "media",
+			SourceKey:         "video.bin",
+			DestBucket:        "media",
+			DestKey:           "copy-" + directive + ".bin",
+			MetadataDirective: directive,
+			ContentType:       "video/mp4",
+			OwnerID:           user.ID,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, put.ETag, copied.ETag)
+
+		refs, err := blobRepo.GetRefCount(ctx, *source.ContentHash)
+		require.NoError(t, err)
+		assert.Equal(t, int32(2+i), refs)
+	}
+
+	assert.Equal(t, int64(len(content)), store.stored.Load(), "copy wrote blob bytes")
+	assert.Zero(t, store.retrieved.Load(), "copy read the source blob")
+
+	dest, err := objectRepo.GetByKey(ctx, bucket.ID, "copy-REPLACE.bin")
+	require.NoError(t, err)
+	assert.Equal(t, *source.ContentHash, *dest.ContentHash)
+	assert.Equal(t, "video/mp4", dest.ContentType)
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/events"
+	"github.com/prn-tf/alexander-storage/internal/repository/sqlite"
+)
+
+// eventCollector is a test subscriber that keeps every event it receives.
+type eventCollector struct {
+	mu     sync.Mutex
+	events []events.Event
+}
+
+func (c *eventCollector) HandleEvent(_ context.Context, event events.Event) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.events = append(c.events, event)
+	return nil
+}
+
+func TestObjectService_PublishesObjectEvents(t *testing.T) {
+	ctx := context.Background()
+
+	inst := startMultipartInstance(t, t.TempDir())
+	defer inst.db.Close()
+
+	user := domain.NewUser("tester", "tester@example.com", "hash")
+	require.NoError(t, sqlite.NewUserRepository(inst.db).Create(ctx, user))
+
+	buckets := NewBucketService(sqlite.NewBucketRepository(inst.db), zerolog.Nop())
+	_, err := buckets.CreateBucket(ctx, CreateBucketInput{Name: "docs", OwnerID: user.ID})
+	require.NoError(t, err)
+
+	collector := &eventCollector{}
+	bus := events.NewBus(0, zerolog.Nop())
+	bus.Subscribe("test", collector)
+	inst.objects.SetEventBus(bus)
+
+	body := "hello events"
+	put, err := inst.objects.PutObject(ctx, PutObjectInput{
+		BucketName: "docs",
+		Key:        "a.txt",
+		Body:       strings.NewReader(body),
+		Size:       int64(len(body)),
+		OwnerID:    user.ID,
+	})
+	require.NoError(t, err)
+
+	_, err = inst.objects.DeleteObject(ctx, DeleteObjectInput{BucketName: "docs", Key: "a.txt", OwnerID: user.ID})
+	require.NoError(t, err)
+
+	// Close waits for the subscriber to drain its queue
+	require.NoError(t, bus.Close(ctx))
+
+	require.Len(t, collector.events, 2)
+
+	created := collector.events[0]
+	assert.Equal(t, events.ObjectCreatedPut, created.Type)
+	assert.Equal(t, "docs", created.Bucket)
+	assert.Equal(t, "a.txt", created.Key)
+	assert.Equal(t, put.ETag, created.ETag)
+	assert.Equal(t, int64(len(body)), created.Size)
+	assert.NotEmpty(t, created.ContentHash)
+	assert.Equal(t, user.ID, created.OwnerID)
+	assert.False(t, created.Time.IsZero())
+
+	assert.Equal(t, events.ObjectRemovedDelete, collector.events[1].Type)
+}
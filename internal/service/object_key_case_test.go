@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/repository/sqlite"
+)
+
+// setupKeyCaseBucket creates a bucket named "docs" in the requested key mode.
+func setupKeyCaseBucket(t *testing.T, caseInsensitive bool) (*ObjectService, *BucketService, int64) {
+	t.Helper()
+	ctx := context.Background()
+
+	inst := startMultipartInstance(t, t.TempDir())
+	t.Cleanup(func() { inst.db.Close() })
+
+	user := domain.NewUser("tester", "tester@example.com", "hash")
+	require.NoError(t, sqlite.NewUserRepository(inst.db).Create(ctx, user))
+
+	buckets := NewBucketService(sqlite.NewBucketRepository(inst.db), zerolog.Nop())
+	_, err := buckets.CreateBucket(ctx, CreateBucketInput{Name: "docs", OwnerID: user.ID})
+	require.NoError(t, err)
+	require.NoError(t, buckets.PutBucketKeyCase(ctx, PutBucketKeyCaseInput{
+		Name:            "docs",
+		OwnerID:         user.ID,
+		CaseInsensitive: caseInsensitive,
+	}))
+
+	return inst.objects, buckets, user.ID
+}
+
+func putKeyCaseObject(t *testing.T, svc *ObjectService, key, body string, ownerID int64) {
+	t.Helper()
+
+	_, err := svc.PutObject(context.Background(), PutObjectInput{
+		BucketName: "docs",
+		Key:        key,
+		Body:       strings.NewReader(body),
+		Size:       int64(len(body)),
+		OwnerID:    ownerID,
+	})
+	require.NoError(t, err)
+}
+
+func getKeyCaseObject(t *testing.T, svc *ObjectService, key string, ownerID int64) (string, error) {
+	t.Helper()
+
+	out, err := svc.GetObject(context.Background(), GetObjectInput{BucketName: "docs", Key: key, OwnerID: ownerID})
+	if err != nil {
+		return "", err
+	}
+	defer out.Body.Close()
+
+	data, err := io.ReadAll(out.Body)
+	require.NoError(t, err)
+	return string(data), nil
+}
+
+func listKeyCaseObjects(t *testing.T, svc *ObjectService, prefix string, ownerID int64) []string {
+	t.Helper()
+
+	out, err := svc.ListObjects(context.Background(), ListObjectsInput{BucketName: "docs", Prefix: prefix, OwnerID: ownerID})
+	require.NoError(t, err)
+
+	keys := make([]string, 0, len(out.Contents))
+	for _, obj := range out.Contents {
+		keys = append(keys, obj.Key)
+	}
+	return keys
+}
+
+func TestObjectService_CaseSensitiveKeys(t *testing.T) {
+	svc, _, ownerID := setupKeyCaseBucket(t, false)
+
+	putKeyCaseObject(t, svc, "File.txt", "upper", ownerID)
+	putKeyCaseObject(t, svc, "file.txt", "lower", ownerID)
+
+	body, err := getKeyCaseObject(t, svc, "File.txt", ownerID)
+	require.NoError(t, err)
+	require.Equal(t, "upper", body)
+
+	body, err = getKeyCaseObject(t, svc, "file.txt", ownerID)
+	require.NoError(t, err)
+	require.Equal(t, "lower", body)
+
+	require.ElementsMatch(t, []string{"File.txt", "file.txt"}, listKeyCaseObjects(t, svc, "", ownerID))
+	require.Equal(t, []string{"file.txt"}, listKeyCaseObjects(t, svc, "f", ownerID))
+
+	_, err = svc.DeleteObject(context.Background(), DeleteObjectInput{BucketName: "docs", Key: "FILE.TXT", OwnerID: ownerID})
+	require.NoError(t, err)
+	require.Len(t, listKeyCaseObjects(t, svc, "", ownerID), 2)
+}
+
+func TestObjectService_CaseInsensitiveKeys(t *testing.T) {
+	svc, _, ownerID := setupKeyCaseBucket(t, true)
+
+	putKeyCaseObject(t, svc, "File.txt", "upper", ownerID)
+	putKeyCaseObject(t, svc, "file.txt", "lower", ownerID)
+
+	// The second write replaced the first under the same normalized key
+	for _, key := range []string{"File.txt", "file.txt", "FILE.TXT"} {
+		body, err := getKeyCaseObject(t, svc, key, ownerID)
+		require.NoError(t, err)
+		require.Equal(t, "lower", body)
+	}
+
+	// Listing shows one entry with the casing of the latest write
+	require.Equal(t, []string{"file.txt"}, listKeyCaseObjects(t, svc, "", ownerID))
+	require.Equal(t, []string{"file.txt"}, listKeyCaseObjects(t, svc, "FI", ownerID))
+
+	_, err := svc.DeleteObject(context.Background(), DeleteObjectInput{BucketName: "docs", Key: "FILE.TXT", OwnerID: ownerID})
+	require.NoError(t, err)
+	require.Empty(t, listKeyCaseObjects(t, svc, "", ownerID))
+
+	_, err = getKeyCaseObject(t, svc, "file.txt", ownerID)
+	require.ErrorIs(t, err, domain.ErrObjectNotFound)
+}
+
+func TestBucketService_PutBucketKeyCaseRequiresEmptyBucket(t *testing.T) {
+	ctx := context.Background()
+	svc, buckets, ownerID := setupKeyCaseBucket(t, false)
+
+	putKeyCaseObject(t, svc, "File.txt", "upper", ownerID)
+
+	err := buckets.PutBucketKeyCase(ctx, PutBucketKeyCaseInput{Name: "docs", OwnerID: ownerID, CaseInsensitive: true})
+	require.ErrorIs(t, err, domain.ErrBucketNotEmpty)
+
+	// Going back to case-sensitive keys never collides
+	svc, buckets, ownerID = setupKeyCaseBucket(t, true)
+	putKeyCaseObject(t, svc, "File.txt", "upper", ownerID)
+	require.NoError(t, buckets.PutBucketKeyCase(ctx, PutBucketKeyCaseInput{Name: "docs", OwnerID: ownerID, CaseInsensitive: false}))
+
+	body, err := getKeyCaseObject(t, svc, "File.txt", ownerID)
+	require.NoError(t, err)
+	require.Equal(t, "upper", body)
+}
@@ -0,0 +1,343 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+)
+
+func TestListObjects_PaginatesWithOpaqueTokens(t *testing.T) {
+	ctx := context.Background()
+	dir, ownerID := setupMultipartRestart(t)
+	inst := startMultipartInstance(t, dir)
+	defer inst.db.Close()
+
+	// Keys with characters some SDKs reject in a raw token
+	const total = 2500
+	for i := 0; i < total; i++ {
+		_, err := inst.objects.PutObject(ctx, PutObjectInput{
+			BucketName: "uploads",
+			Key:        fmt.Sprintf("dir/a+b c&d=%04d%%", i),
+			Body:       strings.NewReader("x"),
+			Size:       1,
+			OwnerID:    ownerID,
+		})
+		require.NoError(t, err)
+	}
+
+	var keys []string
+	var pageSizes []int
+	token := ""
+	for {
+		out, err := inst.objects.ListObjects(ctx, ListObjectsInput{
+			BucketName:        "uploads",
+			ContinuationToken: token,
+			MaxKeys:           1000,
+			OwnerID:           ownerID,
+		})
+		require.NoError(t, err)
+
+		pageSizes = append(pageSizes, len(out.Contents))
+		assert.Equal(t, len(out.Contents), out.KeyCount)
+		for _, obj := range out.Contents {
+			keys = append(keys, obj.Key)
+		}
+
+		if !out.IsTruncated {
+			assert.Empty(t, out.NextContinuationToken, "final page must not carry a token")
+			break
+		}
+		require.NotEmpty(t, out.NextContinuationToken)
+		assert.NotContains(t, out.NextContinuationToken, "dir/", "token must not expose the key")
+		assert.Equal(t, url.QueryEscape(out.NextContinuationToken), out.NextContinuationToken, "token must be query safe")
+		token = out.NextContinuationToken
+	}
+
+	assert.Equal(t, []int{1000, 1000, 500}, pageSizes)
+	require.Len(t, keys, total)
+	for i, key := range keys {
+		assert.Equal(t, fmt.Sprintf("dir/a+b c&d=%04d%%", i), key)
+	}
+}
+
+func TestListObjects_ContinuationTokenOverridesStartAfter(t *testing.T) {
+	ctx := context.Background()
+	dir, ownerID := setupMultipartRestart(t)
+	inst := startMultipartInstance(t, dir)
+	defer inst.db.Close()
+
+	for _, key := range []string{"a", "b", "c", "d"} {
+		_, err := inst.objects.PutObject(ctx, PutObjectInput{
+			BucketName: "uploads",
+			Key:        key,
+			Body:       strings.NewReader("x"),
+			Size:       1,
+			OwnerID:    ownerID,
+		})
+		require.NoError(t, err)
+	}
+
+	first, err := inst.objects.ListObjects(ctx, ListObjectsInput{BucketName: "uploads", MaxKeys: 2, OwnerID: ownerID})
+	require.NoError(t, err)
+	require.True(t, first.IsTruncated)
+
+	// start-after only applies to the first page
+	second, err := inst.objects.ListObjects(ctx, ListObjectsInput{
+		BucketName:        "uploads",
+		StartAfter:        "a",
+		ContinuationToken: first.NextContinuationToken,
+		MaxKeys:           2,
+		OwnerID:           ownerID,
+	})
+	require.NoError(t, err)
+	require.Len(t, second.Contents, 2)
+	assert.Equal(t, "c", second.Contents[0].Key)
+	assert.False(t, second.IsTruncated)
+	assert.Empty(t, second.NextContinuationToken)
+}
+
+func TestListObjects_RejectsInvalidContinuationToken(t *testing.T) {
+	dir, ownerID := setupMultipartRestart(t)
+	inst := startMultipartInstance(t, dir)
+	defer inst.db.Close()
+
+	_, err := inst.objects.ListObjects(context.Background(), ListObjectsInput{
+		BucketName:        "uploads",
+		ContinuationToken: "not a token!",
+		OwnerID:           ownerID,
+	})
+	assert.ErrorIs(t, err, domain.ErrInvalidContinuationToken)
+}
+
+// putListKeys stores an empty-ish object for each key in the uploads bucket.
+func putListKeys(t *testing.T, inst *multipartInstance, ownerID int64, keys ...string) {
+	t.Helper()
+	for _, key := range keys {
+		_, err := inst.objects.PutObject(context.Background(), PutObjectInput{
+			BucketName: "uploads",
+			Key:        key,
+			Body:       strings.NewReader("x"),
+			Size:       1,
+			OwnerID:    ownerID,
+		})
+		require.NoError(t, err)
+	}
+}
+
+func listKeys(out *ListObjectsOutput) []string {
+	keys := make([]string, len(out.Contents))
+	for i, obj := range out.Contents {
+		keys[i] = obj.Key
+	}
+	return keys
+}
+
+func TestListObjects_DelimiterEdgeCases(t *testing.T) {
+	ctx := context.Background()
+	dir, ownerID := setupMultipartRestart(t)
+	inst := startMultipartInstance(t, dir)
+	defer inst.db.Close()
+
+	putListKeys(t, inst, ownerID,
+		"docs/readme", "docs/readme.md", "docs/readme/v1", "docs/readme/v2",
+		"flat-a.txt", "flat-b.txt", "under_score.txt", "underXscore.txt",
+	)
+
+	list := func(prefix, delimiter string) *ListObjectsOutput {
+		t.Helper()
+		out, err := inst.objects.ListObjects(ctx, ListObjectsInput{
+			BucketName: "uploads",
+			Prefix:     prefix,
+			Delimiter:  delimiter,
+			OwnerID:    ownerID,
+		})
+		require.NoError(t, err)
+		return out
+	}
+
+	t.Run("PrefixEqualsKey", func(t *testing.T) {
+		out := list("docs/readme", "/")
+		assert.Equal(t, []string{"docs/readme", "docs/readme.md"}, listKeys(out))
+		assert.Equal(t, []string{"docs/readme/"}, out.CommonPrefixes)
+		assert.Equal(t, 3, out.KeyCount)
+		assert.False(t, out.IsTruncated)
+	})
+
+	t.Run("EmptyPrefixGroupsDirectories", func(t *testing.T) {
+		out := list("", "/")
+		assert.Equal(t, []string{"flat-a.txt", "flat-b.txt", "underXscore.txt", "under_score.txt"}, listKeys(out))
+		assert.Equal(t, []string{"docs/"}, out.CommonPrefixes)
+	})
+
+	t.Run("FlatKeysHaveNoCommonPrefixes", func(t *testing.T) {
+		out := list("flat-", "/")
+		assert.Equal(t, []string{"flat-a.txt", "flat-b.txt"}, listKeys(out))
+		assert.Empty(t, out.CommonPrefixes)
+	})
+
+	t.Run("DelimiterNeverAppears", func(t *testing.T) {
+		out := list("docs/", "|")
+		assert.Equal(t, []string{"docs/readme", "docs/readme.md", "docs/readme/v1", "docs/readme/v2"}, listKeys(out))
+		assert.Empty(t, out.CommonPrefixes)
+	})
+
+	t.Run("NoMatches", func(t *testing.T) {
+		out := list("missing/", "/")
+		assert.Empty(t, out.Contents)
+		assert.Empty(t, out.CommonPrefixes)
+		assert.Equal(t, 0, out.KeyCount)
+		assert.False(t, out.IsTruncated)
+		assert.Empty(t, out.NextContinuationToken)
+	})
+
+	t.Run("PrefixWildcardsMatchLiterally", func(t *testing.T) {
+		out := list("under_", "")
+		assert.Equal(t, []string{"under_score.txt"}, listKeys(out))
+	})
+}
+
+func TestListObjects_DelimiterPagination(t *testing.T) {
+	ctx := context.Background()
+	dir, ownerID := setupMultipartRestart(t)
+	inst := startMultipartInstance(t, dir)
+	defer inst.db.Close()
+
+	putListKeys(t, inst, ownerID, "a/1", "a/2", "a/3", "b", "c/1", "c/2", "d")
+
+	var entries []string
+	token := ""
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 10, "pagination does not terminate")
+		out, err := inst.objects.ListObjects(ctx, ListObjectsInput{
+			BucketName:        "uploads",
+			Delimiter:         "/",
+			ContinuationToken: token,
+			MaxKeys:           1,
+			OwnerID:           ownerID,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1, out.KeyCount)
+		entries = append(entries, listKeys(out)...)
+		entries = append(entries, out.CommonPrefixes...)
+
+		if !out.IsTruncated {
+			break
+		}
+		token = out.NextContinuationToken
+	}
+
+	// Every common prefix is returned once, however many keys it covers
+	assert.Equal(t, []string{"a/", "b", "c/", "d"}, entries)
+}
+
+func TestListObjects_DelimiterSkipsKeysUnderCommonPrefix(t *testing.T) {
+	ctx := context.Background()
+	dir, ownerID := setupMultipartRestart(t)
+	inst := startMultipartInstance(t, dir)
+	defer inst.db.Close()
+
+	// More keys under one prefix than fit in a page of the underlying listing
+	keys := []string{"a.txt", "z.txt"}
+	for i := 0; i < 25; i++ {
+		keys = append(keys, fmt.Sprintf("logs/2024/%02d.log", i))
+	}
+	putListKeys(t, inst, ownerID, keys...)
+
+	out, err := inst.objects.ListObjects(ctx, ListObjectsInput{
+		BucketName: "uploads",
+		Delimiter:  "/",
+		MaxKeys:    10,
+		OwnerID:    ownerID,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.txt", "z.txt"}, listKeys(out))
+	assert.Equal(t, []string{"logs/"}, out.CommonPrefixes)
+	assert.False(t, out.IsTruncated)
+
+	// Resuming after the common prefix continues past all of its keys
+	out, err = inst.objects.ListObjects(ctx, ListObjectsInput{
+		BucketName: "uploads",
+		Delimiter:  "/",
+		StartAfter: "logs/",
+		OwnerID:    ownerID,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"z.txt"}, listKeys(out))
+	assert.Empty(t, out.CommonPrefixes)
+}
+
+func TestListObjects_SnapshotDuringWrites(t *testing.T) {
+	ctx := context.Background()
+	dir, ownerID := setupMultipartRestart(t)
+	inst := startMultipartInstance(t, dir)
+	defer inst.db.Close()
+	// Keep overwritten objects so the snapshot can still list them
+	inst.objects.SetOverwriteMode(OverwriteSoftDelete)
+
+	putListKeys(t, inst, ownerID, "a", "b", "c", "d", "e", "f")
+
+	// scan lists the bucket two keys per page, calling write after the first page
+	scan := func(snapshot bool, write func()) map[string][]int64 {
+		t.Helper()
+		seen := make(map[string][]int64)
+		token := ""
+		for page := 0; ; page++ {
+			out, err := inst.objects.ListObjects(ctx, ListObjectsInput{
+				BucketName:        "uploads",
+				MaxKeys:           2,
+				ContinuationToken: token,
+				OwnerID:           ownerID,
+				Snapshot:          snapshot,
+			})
+			require.NoError(t, err)
+			for _, obj := range out.Contents {
+				seen[obj.Key] = append(seen[obj.Key], obj.Size)
+			}
+			if page == 0 && write != nil {
+				write()
+			}
+			if !out.IsTruncated {
+				return seen
+			}
+			token = out.NextContinuationToken
+		}
+	}
+
+	seen := scan(true, func() {
+		putListKeys(t, inst, ownerID, "aa", "cc", "g")
+		_, err := inst.objects.DeleteObject(ctx, DeleteObjectInput{BucketName: "uploads", Key: "d", OwnerID: ownerID})
+		require.NoError(t, err)
+		_, err = inst.objects.PutObject(ctx, PutObjectInput{
+			BucketName: "uploads",
+			Key:        "e",
+			Body:       strings.NewReader("replaced"),
+			Size:       8,
+			OwnerID:    ownerID,
+		})
+		require.NoError(t, err)
+	})
+
+	// Every key of the snapshot once, as it was when the scan started
+	assert.Equal(t, map[string][]int64{
+		"a": {1}, "b": {1}, "c": {1}, "d": {1}, "e": {1}, "f": {1},
+	}, seen)
+
+	// A scan without a snapshot sees the current state
+	assert.Equal(t, map[string][]int64{
+		"a": {1}, "aa": {1}, "b": {1}, "c": {1}, "cc": {1}, "e": {8}, "f": {1}, "g": {1},
+	}, scan(false, nil))
+
+	_, err := inst.objects.ListObjects(ctx, ListObjectsInput{
+		BucketName:        "uploads",
+		ContinuationToken: encodeContinuationToken("a", nil) + ".12",
+		OwnerID:           ownerID,
+	})
+	assert.ErrorIs(t, err, domain.ErrInvalidContinuationToken)
+}
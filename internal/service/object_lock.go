@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/repository"
+)
+
+// GetObjectRetentionInput contains the data needed to read an object's retention.
+type GetObjectRetentionInput struct {
+	BucketName string
+	Key        string
+	VersionID  string // Optional
+	OwnerID    int64
+}
+
+// GetObjectRetentionOutput contains an object's retention period.
+type GetObjectRetentionOutput struct {
+	VersionID   string
+	Mode        domain.ObjectLockMode
+	RetainUntil time.Time
+}
+
+// PutObjectRetentionInput contains the data needed to set an object's retention.
+// An empty Mode and nil RetainUntil remove a retention that no longer protects
+// the version.
+type PutObjectRetentionInput struct {
+	BucketName  string
+	Key         string
+	VersionID   string // Optional
+	OwnerID     int64
+	Mode        domain.ObjectLockMode
+	RetainUntil *time.Time
+
+	// BypassGovernanceRetention shortens or removes GOVERNANCE retention.
+	// The caller must be authorized to bypass it.
+	BypassGovernanceRetention bool
+}
+
+// GetObjectLegalHoldInput contains the data needed to read an object's legal hold.
+type GetObjectLegalHoldInput struct {
+	BucketName string
+	Key        string
+	VersionID  string // Optional
+	OwnerID    int64
+}
+
+// GetObjectLegalHoldOutput contains an object's legal hold status.
+type GetObjectLegalHoldOutput struct {
+	VersionID string
+	LegalHold bool
+}
+
+// PutObjectLegalHoldInput contains the data needed to place or release a legal hold.
+type PutObjectLegalHoldInput struct {
+	BucketName string
+	Key        string
+	VersionID  string // Optional
+	OwnerID    int64
+	LegalHold  bool
+}
+
+// ObjectLockOutput contains the version whose lock was changed.
+type ObjectLockOutput struct {
+	VersionID string
+}
+
+// GetObjectRetention returns the retention period of an object version.
+func (s *ObjectService) GetObjectRetention(ctx context.Context, input GetObjectRetentionInput) (*GetObjectRetentionOutput, error) {
+	bucket, obj, err := s.getLockableObject(ctx, input.BucketName, input.Key, input.VersionID, input.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+
+	objLock, err := s.objectRepo.GetLock(ctx, obj.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+	if !objLock.HasRetention() {
+		return nil, domain.ErrNoSuchObjectRetention
+	}
+
+	return &GetObjectRetentionOutput{
+		VersionID:   responseVersionID(bucket, obj),
+		Mode:        objLock.Mode,
+		RetainUntil: *objLock.RetainUntil,
+	}, nil
+}
+
+// PutObjectRetention sets the retention period of an object version.
+// Active COMPLIANCE retention can be extended but not shortened or removed;
+// active GOVERNANCE retention only when the caller bypasses it.
+func (s *ObjectService) PutObjectRetention(ctx context.Context, input PutObjectRetentionInput) (*ObjectLockOutput, error) {
+	now := time.Now()
+	if err := domain.ValidateRetention(input.Mode, input.RetainUntil, now); err != nil {
+		return nil, err
+	}
+
+	bucket, obj, err := s.getLockableObject(ctx, input.BucketName, input.Key, input.VersionID, input.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+
+	objLock, err := s.objectRepo.GetLock(ctx, obj.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+	if !objLock.AllowsRetention(input.Mode, input.RetainUntil, now, input.BypassGovernanceRetention) {
+		return nil, domain.ErrObjectLocked
+	}
+
+	objLock.Mode = input.Mode
+	objLock.RetainUntil = input.RetainUntil
+	if err := s.objectRepo.PutLock(ctx, obj.ID, objLock); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+
+	s.logger.Info().
+		Str("bucket", input.BucketName).
+		Str("key", input.Key).
+		Str("mode", string(input.Mode)).
+		Msg("object retention updated")
+
+	return &ObjectLockOutput{VersionID: responseVersionID(bucket, obj)}, nil
+}
+
+// GetObjectLegalHold returns the legal hold status of an object version.
+func (s *ObjectService) GetObjectLegalHold(ctx context.Context, input GetObjectLegalHoldInput) (*GetObjectLegalHoldOutput, error) {
+	bucket, obj, err := s.getLockableObject(ctx, input.BucketName, input.Key, input.VersionID, input.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+
+	objLock, err := s.objectRepo.GetLock(ctx, obj.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+
+	return &GetObjectLegalHoldOutput{
+		VersionID: responseVersionID(bucket, obj),
+		LegalHold: objLock.LegalHold,
+	}, nil
+}
+
+// PutObjectLegalHold places or releases the legal hold of an object version.
+func (s *ObjectService) PutObjectLegalHold(ctx context.Context, input PutObjectLegalHoldInput) (*ObjectLockOutput, error) {
+	bucket, obj, err := s.getLockableObject(ctx, input.BucketName, input.Key, input.VersionID, input.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+
+	objLock, err := s.objectRepo.GetLock(ctx, obj.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+
+	objLock.LegalHold = input.LegalHold
+	if err := s.objectRepo.PutLock(ctx, obj.ID, objLock); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+
+	s.logger.Info().
+		Str("bucket", input.BucketName).
+		Str("key", input.Key).
+		Bool("legal_hold", input.LegalHold).
+		Msg("object legal hold updated")
+
+	return &ObjectLockOutput{VersionID: responseVersionID(bucket, obj)}, nil
+}
+
+// getLockableObject resolves the bucket and object version a retention or
+// legal hold request applies to. The bucket must have object lock enabled.
+func (s *ObjectService) getLockableObject(ctx context.Context, bucketName, key, versionID string, ownerID int64) (*domain.Bucket, *domain.Object, error) {
+	bucket, obj, err := s.getTaggableObject(ctx, bucketName, key, versionID, ownerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !bucket.ObjectLock {
+		return nil, nil, domain.ErrObjectLockNotEnabled
+	}
+	return bucket, obj, nil
+}
+
+// checkObjectLock returns domain.ErrObjectLocked if a retention period or
+// legal hold protects obj from deletion. GOVERNANCE retention is ignored when
+// bypassGovernance is set. Object lock buckets always have versioning
+// enabled, so writes add versions and never replace a locked one.
+func checkObjectLock(ctx context.Context, objectRepo repository.ObjectRepository, bucket *domain.Bucket, obj *domain.Object, bypassGovernance bool) error {
+	if !bucket.ObjectLock || obj.IsDeleteMarker {
+		return nil
+	}
+
+	objLock, err := objectRepo.GetLock(ctx, obj.ID)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+	if objLock.Protects(time.Now(), bypassGovernance) {
+		return domain.ErrObjectLocked
+	}
+	return nil
+}
+
+// newVersionLock returns the lock of a new version, to be created with it: the
+// lock the request asked for or, without a retention in it, the bucket's
+// default retention. A requested retention never weakens a COMPLIANCE
+// default: a shorter period or a GOVERNANCE mode is replaced by the default.
+func newVersionLock(bucket *domain.Bucket, obj *domain.Object, requested *domain.ObjectLock) *domain.ObjectLock {
+	var objLock *domain.ObjectLock
+	if requested != nil {
+		objLock = &domain.ObjectLock{Mode: requested.Mode, RetainUntil: requested.RetainUntil, LegalHold: requested.LegalHold}
+	}
+
+	if bucket.ObjectLock && bucket.DefaultRetention != nil {
+		defaultLock := bucket.DefaultRetention.Lock(obj.CreatedAt)
+		switch {
+		case objLock == nil:
+			objLock = defaultLock
+		case !objLock.HasRetention(), !defaultLock.AllowsRetention(objLock.Mode, objLock.RetainUntil, obj.CreatedAt, true):
+			objLock.Mode = defaultLock.Mode
+			objLock.RetainUntil = defaultLock.RetainUntil
+		}
+	}
+
+	return objLock
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/repository/sqlite"
+)
+
+// startObjectLockInstance creates an object lock bucket named "uploads" with
+// the given default retention and returns the instance and owner ID.
+func startObjectLockInstance(t *testing.T, retention *domain.DefaultRetention, maxVersions int) (*multipartInstance, int64) {
+	t.Helper()
+	ctx := context.Background()
+
+	inst := startMultipartInstance(t, t.TempDir())
+	t.Cleanup(func() { inst.db.Close() })
+
+	user := domain.NewUser("tester", "tester@example.com", "hash")
+	require.NoError(t, sqlite.NewUserRepository(inst.db).Create(ctx, user))
+	bucket := domain.NewBucket(user.ID, "uploads")
+	bucket.Versioning = domain.VersioningEnabled
+	bucket.ObjectLock = true
+	bucket.DefaultRetention = retention
+	bucket.MaxVersionsPerKey = maxVersions
+	require.NoError(t, sqlite.NewBucketRepository(inst.db).Create(ctx, bucket))
+
+	return inst, user.ID
+}
+
+// versionLock returns the lock recorded on a version of uploads/key.
+func versionLock(t *testing.T, inst *multipartInstance, key, versionID string) *domain.ObjectLock {
+	t.Helper()
+	ctx := context.Background()
+
+	bucket, err := sqlite.NewBucketRepository(inst.db).GetByName(ctx, "uploads")
+	require.NoError(t, err)
+	objectRepo := sqlite.NewObjectRepository(inst.db)
+	obj, err := objectRepo.GetByKeyAndVersion(ctx, bucket.ID, key, uuid.MustParse(versionID))
+	require.NoError(t, err)
+	objLock, err := objectRepo.GetLock(ctx, obj.ID)
+	require.NoError(t, err)
+	return objLock
+}
+
+func TestObjectService_DefaultRetentionStampsNewVersions(t *testing.T) {
+	ctx := context.Background()
+	inst, ownerID := startObjectLockInstance(t, &domain.DefaultRetention{Mode: domain.ObjectLockModeGovernance, Days: 30}, 0)
+
+	put, err := inst.objects.PutObject(ctx, PutObjectInput{
+		BucketName: "uploads",
+		Key:        "ledger.csv",
+		Body:       bytes.NewReader([]byte("a,b,c")),
+		Size:       5,
+		OwnerID:    ownerID,
+	})
+	require.NoError(t, err)
+
+	objLock := versionLock(t, inst, "ledger.csv", put.VersionID)
+	assert.Equal(t, domain.ObjectLockModeGovernance, objLock.Mode)
+	require.NotNil(t, objLock.RetainUntil)
+	assert.WithinDuration(t, time.Now().AddDate(0, 0, 30), *objLock.RetainUntil, time.Minute)
+
+	// Retention sent with the request wins over the default
+	retainUntil := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	put, err = inst.objects.PutObject(ctx, PutObjectInput{
+		BucketName: "uploads",
+		Key:        "ledger.csv",
+		Body:       bytes.NewReader([]byte("d,e,f")),
+		Size:       5,
+		OwnerID:    ownerID,
+		ObjectLock: &domain.ObjectLock{Mode: domain.ObjectLockModeCompliance, RetainUntil: &retainUntil},
+	})
+	require.NoError(t, err)
+	objLock = versionLock(t, inst, "ledger.csv", put.VersionID)
+	assert.Equal(t, domain.ObjectLockModeCompliance, objLock.Mode)
+	assert.True(t, retainUntil.Equal(*objLock.RetainUntil))
+
+	// Multipart uploads get the default too
+	initiated, err := inst.multipart.InitiateMultipartUpload(ctx, InitiateMultipartUploadInput{
+		BucketName: "uploads",
+		Key:        "video.bin",
+		OwnerID:    ownerID,
+	})
+	require.NoError(t, err)
+	part := uploadTestPart(t, inst.multipart, initiated.UploadID, 1, []byte("frames"), ownerID)
+	completed, err := inst.multipart.CompleteMultipartUpload(ctx, CompleteMultipartUploadInput{
+		BucketName: "uploads",
+		Key:        "video.bin",
+		UploadID:   initiated.UploadID,
+		Parts:      []domain.CompletedPart{part},
+		OwnerID:    ownerID,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, domain.ObjectLockModeGovernance, versionLock(t, inst, "video.bin", completed.VersionID).Mode)
+
+	// So do copies
+	copied, err := inst.objects.CopyObject(ctx, CopyObjectInput{
+		SourceBucket: "uploads",
+		SourceKey:    "video.bin",
+		DestBucket:   "uploads",
+		DestKey:      "video-copy.bin",
+		OwnerID:      ownerID,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, domain.ObjectLockModeGovernance, versionLock(t, inst, "video-copy.bin", copied.VersionID).Mode)
+}
+
+func TestObjectService_GovernanceRetentionBlocksDeletesUnlessBypassed(t *testing.T) {
+	ctx := context.Background()
+	inst, ownerID := startObjectLockInstance(t, &domain.DefaultRetention{Mode: domain.ObjectLockModeGovernance, Days: 1}, 1)
+
+	put := func(data string) string {
+		out, err := inst.objects.PutObject(ctx, PutObjectInput{
+			BucketName: "uploads",
+			Key:        "ledger.csv",
+			Body:       bytes.NewReader([]byte(data)),
+			Size:       int64(len(data)),
+			OwnerID:    ownerID,
+		})
+		require.NoError(t, err)
+		return out.VersionID
+	}
+
+	first := put("first")
+	second := put("second")
+
+	// The version limit never removes a version under retention
+	versions, err := inst.objects.ListObjectVersions(ctx, ListObjectVersionsInput{BucketName: "uploads", OwnerID: ownerID})
+	require.NoError(t, err)
+	assert.Len(t, versions.Versions, 2)
+
+	_, err = inst.objects.DeleteObject(ctx, DeleteObjectInput{BucketName: "uploads", Key: "ledger.csv", VersionID: first, OwnerID: ownerID})
+	assert.ErrorIs(t, err, domain.ErrObjectLocked)
+
+	_, err = inst.objects.DeleteAllVersions(ctx, DeleteAllVersionsInput{BucketName: "uploads", Key: "ledger.csv", OwnerID: ownerID})
+	assert.ErrorIs(t, err, domain.ErrObjectLocked)
+
+	// Shortening the retention needs the bypass too
+	soon := time.Now().Add(time.Minute)
+	_, err = inst.objects.PutObjectRetention(ctx, PutObjectRetentionInput{
+		BucketName: "uploads", Key: "ledger.csv", VersionID: second, OwnerID: ownerID,
+		Mode: domain.ObjectLockModeGovernance, RetainUntil: &soon,
+	})
+	assert.ErrorIs(t, err, domain.ErrObjectLocked)
+
+	_, err = inst.objects.DeleteObject(ctx, DeleteObjectInput{
+		BucketName: "uploads", Key: "ledger.csv", VersionID: first, OwnerID: ownerID,
+		BypassGovernanceRetention: true,
+	})
+	require.NoError(t, err)
+
+	out, err := inst.objects.DeleteAllVersions(ctx, DeleteAllVersionsInput{
+		BucketName: "uploads", Key: "ledger.csv", OwnerID: ownerID,
+		BypassGovernanceRetention: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), out.Deleted)
+}
+
+func TestObjectService_CopyObjectNeverWeakensComplianceDefault(t *testing.T) {
+	ctx := context.Background()
+	inst, ownerID := startObjectLockInstance(t, &domain.DefaultRetention{Mode: domain.ObjectLockModeCompliance, Days: 7}, 0)
+
+	_, err := inst.objects.PutObject(ctx, PutObjectInput{
+		BucketName: "uploads",
+		Key:        "source.csv",
+		Body:       bytes.NewReader([]byte("a,b,c")),
+		Size:       5,
+		OwnerID:    ownerID,
+	})
+	require.NoError(t, err)
+
+	copyWithLock := func(destKey string, objLock *domain.ObjectLock) *domain.ObjectLock {
+		out, err := inst.objects.CopyObject(ctx, CopyObjectInput{
+			SourceBucket: "uploads",
+			SourceKey:    "source.csv",
+			DestBucket:   "uploads",
+			DestKey:      destKey,
+			OwnerID:      ownerID,
+			ObjectLock:   objLock,
+		})
+		require.NoError(t, err)
+		return versionLock(t, inst, destKey, out.VersionID)
+	}
+	defaultUntil := time.Now().AddDate(0, 0, 7)
+
+	// A shorter GOVERNANCE retention is replaced by the default
+	tomorrow := time.Now().Add(24 * time.Hour)
+	objLock := copyWithLock("shorter.csv", &domain.ObjectLock{Mode: domain.ObjectLockModeGovernance, RetainUntil: &tomorrow})
+	assert.Equal(t, domain.ObjectLockModeCompliance, objLock.Mode)
+	assert.WithinDuration(t, defaultUntil, *objLock.RetainUntil, time.Minute)
+
+	// A longer COMPLIANCE retention is kept
+	nextMonth := time.Now().AddDate(0, 1, 0).UTC().Truncate(time.Second)
+	objLock = copyWithLock("longer.csv", &domain.ObjectLock{Mode: domain.ObjectLockModeCompliance, RetainUntil: &nextMonth})
+	assert.Equal(t, domain.ObjectLockModeCompliance, objLock.Mode)
+	assert.True(t, nextMonth.Equal(*objLock.RetainUntil))
+
+	// A legal hold alone keeps the default retention
+	objLock = copyWithLock("held.csv", &domain.ObjectLock{LegalHold: true})
+	assert.True(t, objLock.LegalHold)
+	assert.Equal(t, domain.ObjectLockModeCompliance, objLock.Mode)
+	assert.WithinDuration(t, defaultUntil, *objLock.RetainUntil, time.Minute)
+}
+
+func TestObjectService_FailedLockWriteLeavesNoVersion(t *testing.T) {
+	ctx := context.Background()
+	inst, ownerID := startObjectLockInstance(t, &domain.DefaultRetention{Mode: domain.ObjectLockModeCompliance, Days: 1}, 0)
+
+	// The lock store fails after the version row is written
+	_, err := inst.db.ExecContext(ctx, `
+		CREATE TRIGGER fail_object_locks BEFORE INSERT ON object_locks
+		BEGIN SELECT RAISE(ABORT, 'lock store unavailable'); END`)
+	require.NoError(t, err)
+
+	_, err = inst.objects.PutObject(ctx, PutObjectInput{
+		BucketName: "uploads",
+		Key:        "ledger.csv",
+		Body:       bytes.NewReader([]byte("a,b,c")),
+		Size:       5,
+		OwnerID:    ownerID,
+		Tags:       map[string]string{"team": "finance"},
+	})
+	require.ErrorIs(t, err, ErrInternalError)
+
+	// No unlocked version is left behind, and its blob reference is released
+	_, err = inst.objects.GetObject(ctx, GetObjectInput{BucketName: "uploads", Key: "ledger.csv", OwnerID: ownerID})
+	require.ErrorIs(t, err, domain.ErrObjectNotFound)
+	sum := sha256.Sum256([]byte("a,b,c"))
+	blob, err := sqlite.NewBlobRepository(inst.db).GetByHash(ctx, hex.EncodeToString(sum[:]))
+	require.NoError(t, err)
+	assert.Zero(t, blob.RefCount)
+}
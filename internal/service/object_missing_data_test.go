@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/metrics"
+	"github.com/prn-tf/alexander-storage/internal/pkg/crypto"
+)
+
+func TestObjectService_GetObject_DataMissing(t *testing.T) {
+	ctx := context.Background()
+	dir, ownerID := setupMultipartRestart(t)
+	inst := startMultipartInstance(t, dir)
+	defer inst.db.Close()
+
+	m := metrics.NewWithRegisterer(prometheus.NewRegistry())
+	inst.objects.SetMetrics(m)
+
+	// The record stays behind while its blob is lost
+	putListKeys(t, inst, ownerID, "a.txt")
+	require.NoError(t, inst.storage.Delete(ctx, crypto.SHA256Hex([]byte("x"))))
+
+	tests := []struct {
+		name  string
+		input GetObjectInput
+	}{
+		{name: "whole object", input: GetObjectInput{BucketName: "uploads", Key: "a.txt", OwnerID: ownerID}},
+		{name: "range", input: GetObjectInput{BucketName: "uploads", Key: "a.txt", OwnerID: ownerID, Range: &ByteRange{Start: 0, End: 0}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := inst.objects.GetObject(ctx, tt.input)
+			assert.ErrorIs(t, err, domain.ErrObjectDataMissing)
+			assert.NotErrorIs(t, err, domain.ErrObjectNotFound)
+		})
+	}
+	assert.Equal(t, float64(len(tests)), testutil.ToFloat64(m.BlobsMissingTotal))
+
+	// A key that was never written is still reported as missing
+	_, err := inst.objects.GetObject(ctx, GetObjectInput{BucketName: "uploads", Key: "b.txt", OwnerID: ownerID})
+	assert.ErrorIs(t, err, domain.ErrObjectNotFound)
+}
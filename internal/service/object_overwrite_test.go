@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/repository/sqlite"
+)
+
+func TestPutObject_OverwriteUnversioned(t *testing.T) {
+	tests := []struct {
+		name         string
+		mode         OverwriteMode
+		wantRows     int
+		wantLiveRefs int
+	}{
+		{name: "replace purges the old object", mode: OverwriteReplace, wantRows: 1, wantLiveRefs: 1},
+		{name: "soft delete keeps the old object", mode: OverwriteSoftDelete, wantRows: 100, wantLiveRefs: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			dir, ownerID := setupMultipartRestart(t)
+			inst := startMultipartInstance(t, dir)
+			defer inst.db.Close()
+			inst.objects.SetOverwriteMode(tt.mode)
+
+			// Every body differs so each write stores its own blob
+			for i := 0; i < 100; i++ {
+				body := fmt.Sprintf("version %03d", i)
+				_, err := inst.objects.PutObject(ctx, PutObjectInput{
+					BucketName: "uploads",
+					Key:        "doc.txt",
+					Body:       strings.NewReader(body),
+					Size:       int64(len(body)),
+					OwnerID:    ownerID,
+				})
+				require.NoError(t, err)
+			}
+
+			var rows int
+			require.NoError(t, inst.db.DB().QueryRowContext(ctx, "SELECT COUNT(*) FROM objects").Scan(&rows))
+			assert.Equal(t, tt.wantRows, rows)
+
+			var blobs, liveRefs int
+			require.NoError(t, inst.db.DB().QueryRowContext(ctx,
+				"SELECT COUNT(*), COALESCE(SUM(ref_count), 0) FROM blobs").Scan(&blobs, &liveRefs))
+			assert.Equal(t, 100, blobs)
+			assert.Equal(t, tt.wantLiveRefs, liveRefs)
+
+			// The current object's blob keeps its single reference
+			obj, err := sqlite.NewObjectRepository(inst.db).GetByKey(ctx, 1, "doc.txt")
+			require.NoError(t, err)
+			refs, err := sqlite.NewBlobRepository(inst.db).GetRefCount(ctx, *obj.ContentHash)
+			require.NoError(t, err)
+			assert.Equal(t, int32(1), refs)
+		})
+	}
+}
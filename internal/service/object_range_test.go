@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/repository/sqlite"
+)
+
+func TestObjectService_GetObjectRange(t *testing.T) {
+	ctx := context.Background()
+
+	inst := startMultipartInstance(t, t.TempDir())
+	defer inst.db.Close()
+
+	user := domain.NewUser("tester", "tester@example.com", "hash")
+	require.NoError(t, sqlite.NewUserRepository(inst.db).Create(ctx, user))
+
+	buckets := NewBucketService(sqlite.NewBucketRepository(inst.db), zerolog.Nop())
+	_, err := buckets.CreateBucket(ctx, CreateBucketInput{Name: "media", OwnerID: user.ID})
+	require.NoError(t, err)
+
+	body := strings.Repeat("0123456789", 100) // 1000 bytes
+	_, err = inst.objects.PutObject(ctx, PutObjectInput{
+		BucketName: "media",
+		Key:        "movie.mp4",
+		Body:       strings.NewReader(body),
+		Size:       int64(len(body)),
+		OwnerID:    user.ID,
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		byteRange   ByteRange
+		wantBody    string
+		wantRange   string
+		wantErrorIs error
+	}{
+		{
+			name:      "suffix",
+			byteRange: ByteRange{SuffixLength: 500},
+			wantBody:  body[500:],
+			wantRange: "bytes 500-999/1000",
+		},
+		{
+			name:      "open ended",
+			byteRange: ByteRange{Start: 500, End: -1},
+			wantBody:  body[500:],
+			wantRange: "bytes 500-999/1000",
+		},
+		{
+			name:      "suffix larger than object",
+			byteRange: ByteRange{SuffixLength: 5000},
+			wantBody:  body,
+			wantRange: "bytes 0-999/1000",
+		},
+		{
+			name:      "end past last byte",
+			byteRange: ByteRange{Start: 990, End: 2000},
+			wantBody:  body[990:],
+			wantRange: "bytes 990-999/1000",
+		},
+		{
+			name:        "start past last byte",
+			byteRange:   ByteRange{Start: 1000, End: -1},
+			wantErrorIs: domain.ErrInvalidRange,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			byteRange := tt.byteRange
+			out, err := inst.objects.GetObject(ctx, GetObjectInput{
+				BucketName: "media",
+				Key:        "movie.mp4",
+				OwnerID:    user.ID,
+				Range:      &byteRange,
+			})
+			if tt.wantErrorIs != nil {
+				assert.ErrorIs(t, err, tt.wantErrorIs)
+				return
+			}
+			require.NoError(t, err)
+			defer out.Body.Close()
+
+			data, err := io.ReadAll(out.Body)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantBody, string(data))
+			assert.Equal(t, int64(len(tt.wantBody)), out.ContentLength)
+			assert.Equal(t, tt.wantRange, out.ContentRange)
+		})
+	}
+}
@@ -2,33 +2,57 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"crypto/md5"
+	"encoding/base64"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/rs/zerolog"
 
 	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/events"
 	"github.com/prn-tf/alexander-storage/internal/lock"
+	"github.com/prn-tf/alexander-storage/internal/metrics"
+	"github.com/prn-tf/alexander-storage/internal/pkg/crypto"
 	"github.com/prn-tf/alexander-storage/internal/repository"
 	"github.com/prn-tf/alexander-storage/internal/storage"
 )
 
 // ObjectService handles object operations.
 type ObjectService struct {
-	objectRepo repository.ObjectRepository
-	blobRepo   repository.BlobRepository
-	bucketRepo repository.BucketRepository
-	storage    storage.Backend
-	locker     lock.Locker
-	logger     zerolog.Logger
+	objectRepo      repository.ObjectRepository
+	blobRepo        repository.BlobRepository
+	bucketRepo      repository.BucketRepository
+	storage         storage.Backend
+	locker          lock.Locker
+	access          *accessBreaker   // Optional - records reads for tiering
+	deltas          *DeltaQueue      // Optional - stores new versions as deltas
+	events          *events.Bus      // Optional - receives object events
+	metrics         *metrics.Metrics // Optional - counts objects missing their data
+	deleteBatchSize int              // Versions soft-deleted per statement by DeleteAllVersions
+	sizePolicy      SizeMismatchPolicy
+	maxKeyDepth     int    // Maximum "/" delimiters in new keys, 0 for unlimited
+	allowAppend     bool   // Accept PutObject with a write offset
+	onStorageFull   func() // Optional - called when a write finds storage full
+	overwriteMode   OverwriteMode
+	logger          zerolog.Logger
 }
 
+// DefaultDeleteBatchSize is the number of versions DeleteAllVersions
+// soft-deletes per statement when no batch size is configured.
+const DefaultDeleteBatchSize = 1000
+
+// MaxListKeys is the largest page a list operation returns.
+const MaxListKeys = 1000
+
 // NewObjectService creates a new ObjectService.
 func NewObjectService(
 	objectRepo repository.ObjectRepository,
@@ -39,13 +63,65 @@ func NewObjectService(
 	logger zerolog.Logger,
 ) *ObjectService {
 	return &ObjectService{
-		objectRepo: objectRepo,
-		blobRepo:   blobRepo,
-		bucketRepo: bucketRepo,
-		storage:    storage,
-		locker:     locker,
-		logger:     logger.With().Str("service", "object").Logger(),
+		objectRepo:      objectRepo,
+		blobRepo:        blobRepo,
+		bucketRepo:      bucketRepo,
+		storage:         storage,
+		locker:          locker,
+		deleteBatchSize: DefaultDeleteBatchSize,
+		sizePolicy:      SizeMismatchStrict,
+		overwriteMode:   OverwriteReplace,
+		logger:          logger.With().Str("service", "object").Logger(),
+	}
+}
+
+// SetEventBus publishes object events to bus after each committed write.
+func (s *ObjectService) SetEventBus(bus *events.Bus) {
+	s.events = bus
+}
+
+// SetMetrics sets the metrics that record objects found missing their data.
+func (s *ObjectService) SetMetrics(m *metrics.Metrics) {
+	s.metrics = m
+}
+
+// SetStorageFullHandler sets a function called, without blocking the
+// request, whenever a write is rejected because storage is full.
+func (s *ObjectService) SetStorageFullHandler(fn func()) {
+	s.onStorageFull = fn
+}
+
+// SetSizeMismatchPolicy sets how PutObject handles a body that does not
+// match its declared size. The default is SizeMismatchStrict.
+func (s *ObjectService) SetSizeMismatchPolicy(policy SizeMismatchPolicy) {
+	s.sizePolicy = policy
+}
+
+// SetOverwriteMode sets what happens to an object a write replaces without
+// versioning. The default is OverwriteReplace.
+func (s *ObjectService) SetOverwriteMode(mode OverwriteMode) {
+	s.overwriteMode = mode
+}
+
+// SetMaxKeyDepth rejects new object keys with more than n "/" delimiters.
+// 0, the default, allows any depth.
+func (s *ObjectService) SetMaxKeyDepth(n int) {
+	s.maxKeyDepth = n
+}
+
+// SetAllowAppend enables appending to objects with PutObject's WriteOffset.
+// Appends are disabled by default.
+func (s *ObjectService) SetAllowAppend(allow bool) {
+	s.allowAppend = allow
+}
+
+// SetDeleteBatchSize sets how many versions DeleteAllVersions soft-deletes
+// per statement. Values below 1 restore DefaultDeleteBatchSize.
+func (s *ObjectService) SetDeleteBatchSize(n int) {
+	if n < 1 {
+		n = DefaultDeleteBatchSize
 	}
+	s.deleteBatchSize = n
 }
 
 // =============================================================================
@@ -60,13 +136,61 @@ type PutObjectInput struct {
 	Size        int64
 	ContentType string
 	Metadata    map[string]string
+	ACL         string // Optional canned ACL (x-amz-acl)
 	OwnerID     int64
+
+	// CacheControl, ContentDisposition, ContentEncoding and Expires are
+	// stored and returned on reads. Optional.
+	CacheControl       string
+	ContentDisposition string
+	ContentEncoding    string
+	Expires            string
+
+	// ExpiresAt schedules the object for deletion by the lifecycle worker
+	// (x-amz-expires-at). Optional; must be in the future.
+	ExpiresAt *time.Time
+
+	// Tags is the object's initial tag set (x-amz-tagging). Optional.
+	Tags map[string]string
+
+	// ObjectLock is the object's initial retention and legal hold
+	// (x-amz-object-lock-*). Optional; the bucket must have object lock.
+	ObjectLock *domain.ObjectLock
+
+	// SSECustomerKey encrypts the object with a customer-provided key (SSE-C).
+	// Optional; the same key must then be supplied to read the object, and
+	// buckets requiring encryption reject uploads without it.
+	SSECustomerKey *crypto.SSECustomerKey
+
+	// ContentMD5 is the decoded Content-MD5 header. Optional; when set, a
+	// body with a different MD5 is rejected with domain.ErrBadDigest.
+	ContentMD5 []byte
+
+	// ChecksumAlgorithm computes an additional checksum stored with the
+	// object. Optional. Checksum is the client's base64 value for it; when
+	// set, a body with a different checksum is rejected with
+	// domain.ErrChecksumMismatch.
+	ChecksumAlgorithm domain.ChecksumAlgorithm
+	Checksum          string
+
+	// WriteOffset appends the body to the object's current content
+	// (x-amz-write-offset-bytes), storing the result as a new version.
+	// Optional; must equal the object's size, or 0 to create the object.
+	// Requires SetAllowAppend.
+	WriteOffset *int64
 }
 
 // PutObjectOutput contains the result of storing an object.
 type PutObjectOutput struct {
 	ETag      string
 	VersionID string
+
+	// SSECustomerKeyMD5 is set when the object was encrypted with SSE-C.
+	SSECustomerKeyMD5 string
+
+	// ChecksumAlgorithm and Checksum are set when an additional checksum was stored.
+	ChecksumAlgorithm domain.ChecksumAlgorithm
+	Checksum          string
 }
 
 // GetObjectInput contains the data needed to retrieve an object.
@@ -76,12 +200,49 @@ type GetObjectInput struct {
 	VersionID  string // Optional
 	OwnerID    int64
 	Range      *ByteRange // Optional
+	Conditions ObjectConditions
+
+	// ResponseHeaders overrides the stored headers in the response
+	// (response-content-type and the like). Optional.
+	ResponseHeaders ResponseHeaders
+
+	// SSECustomerKey is required for objects stored with SSE-C.
+	SSECustomerKey *crypto.SSECustomerKey
 }
 
 // ByteRange represents a byte range for partial content requests.
 type ByteRange struct {
 	Start int64
-	End   int64
+	End   int64 // -1 reads through the last byte (bytes=N-)
+
+	// SuffixLength requests the last SuffixLength bytes (bytes=-N).
+	// When set, Start and End are ignored.
+	SuffixLength int64
+}
+
+// Resolve returns the inclusive byte offsets the range covers in an object
+// of the given size. A suffix longer than the object covers the whole object
+// and an end past the last byte is clamped, as in RFC 7233.
+func (r ByteRange) Resolve(size int64) (start, end int64, err error) {
+	if size <= 0 {
+		return 0, 0, domain.ErrInvalidRange
+	}
+
+	if r.SuffixLength > 0 {
+		return max(size-r.SuffixLength, 0), size - 1, nil
+	}
+
+	if r.Start < 0 || r.Start >= size {
+		return 0, 0, domain.ErrInvalidRange
+	}
+	end = r.End
+	if end < 0 || end >= size {
+		end = size - 1
+	}
+	if end < r.Start {
+		return 0, 0, domain.ErrInvalidRange
+	}
+	return r.Start, end, nil
 }
 
 // GetObjectOutput contains the result of retrieving an object.
@@ -93,7 +254,33 @@ type GetObjectOutput struct {
 	LastModified  time.Time
 	VersionID     string
 	Metadata      map[string]string
+	StorageClass  domain.StorageClass
 	ContentRange  string // For range requests
+
+	// ContentDisposition is the one the bucket's content-type policy forces,
+	// else the requested override, else the one stored with the object.
+	ContentDisposition string
+
+	// CacheControl, ContentEncoding and Expires are the requested overrides,
+	// else the ones stored with the object. ContentLanguage is only set by
+	// an override.
+	CacheControl    string
+	ContentEncoding string
+	Expires         string
+	ContentLanguage string
+
+	// SSECustomerKeyMD5 is set when the object is stored with SSE-C.
+	SSECustomerKeyMD5 string
+
+	// ChecksumAlgorithm and Checksum are set when the object is stored with
+	// an additional checksum. The checksum covers the whole object, also
+	// when only a range is returned.
+	ChecksumAlgorithm domain.ChecksumAlgorithm
+	Checksum          string
+
+	// NotModified is set when the conditions found the object unchanged.
+	// Only ETag, LastModified and VersionID are set and Body is nil.
+	NotModified bool
 }
 
 // HeadObjectInput contains the data needed to get object metadata.
@@ -102,6 +289,22 @@ type HeadObjectInput struct {
 	Key        string
 	VersionID  string // Optional
 	OwnerID    int64
+	Conditions ObjectConditions
+
+	// Range reports the length and Content-Range a GET of the range would
+	// return. Optional.
+	Range *ByteRange
+
+	// ResponseHeaders overrides the stored headers in the response
+	// (response-content-type and the like). Optional.
+	ResponseHeaders ResponseHeaders
+
+	// IncludeEncryption reports how the object's content is encrypted at rest.
+	// It exposes storage internals and is meant for operators only.
+	IncludeEncryption bool
+
+	// SSECustomerKey is required for objects stored with SSE-C.
+	SSECustomerKey *crypto.SSECustomerKey
 }
 
 // HeadObjectOutput contains object metadata.
@@ -113,6 +316,51 @@ type HeadObjectOutput struct {
 	VersionID     string
 	Metadata      map[string]string
 	StorageClass  domain.StorageClass
+	ContentRange  string            // For range requests
+	Encryption    *ObjectEncryption // Only set when IncludeEncryption is requested
+
+	// ContentDisposition is the one the bucket's content-type policy forces,
+	// else the requested override, else the one stored with the object.
+	ContentDisposition string
+
+	// CacheControl, ContentEncoding and Expires are the requested overrides,
+	// else the ones stored with the object. ContentLanguage is only set by
+	// an override.
+	CacheControl    string
+	ContentEncoding string
+	Expires         string
+	ContentLanguage string
+
+	// SSECustomerKeyMD5 is set when the object is stored with SSE-C.
+	SSECustomerKeyMD5 string
+
+	// ChecksumAlgorithm and Checksum are set when the object is stored with
+	// an additional checksum.
+	ChecksumAlgorithm domain.ChecksumAlgorithm
+	Checksum          string
+
+	// NotModified is set when the conditions found the object unchanged.
+	// Only ETag, LastModified and VersionID are set.
+	NotModified bool
+}
+
+// ObjectEncryption describes how an object version is stored at rest.
+type ObjectEncryption struct {
+	ContentHash string
+	Encrypted   bool
+
+	// Scheme is the encryption scheme recorded for the blob. It is empty for
+	// blobs written before schemes were recorded, which use the server default.
+	Scheme domain.EncryptionScheme
+}
+
+// PutObjectACLInput contains the data needed to set the canned ACL of an object.
+type PutObjectACLInput struct {
+	BucketName string
+	Key        string
+	VersionID  string // Optional
+	ACL        string
+	OwnerID    int64
 }
 
 // DeleteObjectInput contains the data needed to delete an object.
@@ -121,6 +369,10 @@ type DeleteObjectInput struct {
 	Key        string
 	VersionID  string // Optional - if provided, deletes specific version
 	OwnerID    int64
+
+	// BypassGovernanceRetention deletes a version under GOVERNANCE
+	// retention. The caller must be authorized to bypass it.
+	BypassGovernanceRetention bool
 }
 
 // DeleteObjectOutput contains the result of deleting an object.
@@ -130,6 +382,26 @@ type DeleteObjectOutput struct {
 	DeleteMarkerVersionID string
 }
 
+// DeleteAllVersionsInput contains the data needed to delete every version of a key.
+type DeleteAllVersionsInput struct {
+	BucketName string
+	Key        string
+	OwnerID    int64
+
+	// BypassGovernanceRetention deletes versions under GOVERNANCE
+	// retention. The caller must be authorized to bypass it.
+	BypassGovernanceRetention bool
+
+	// Progress is called after each batch with the running total. Optional.
+	Progress func(deleted int64)
+}
+
+// DeleteAllVersionsOutput contains the result of deleting every version of a key.
+type DeleteAllVersionsOutput struct {
+	Deleted int64
+	Batches int
+}
+
 // ListObjectsInput contains the data needed to list objects.
 type ListObjectsInput struct {
 	BucketName        string
@@ -140,6 +412,12 @@ type ListObjectsInput struct {
 	StartAfter        string // v2
 	ContinuationToken string // v2
 	OwnerID           int64
+
+	// Snapshot lists every page as of when the first page was listed, so
+	// writes during the scan do not change later pages (v2 only). The
+	// snapshot is carried in the continuation token. Objects overwritten under
+	// OverwriteReplace are purged at once and drop out of an open snapshot.
+	Snapshot bool
 }
 
 // ListObjectsOutput contains the result of listing objects.
@@ -175,14 +453,42 @@ type CopyObjectInput struct {
 	ContentType       string            // Optional - override content type
 	Metadata          map[string]string // Optional - new metadata
 	MetadataDirective string            // COPY or REPLACE
+	Conditions        CopySourceConditions
+	ACL               string            // Optional canned ACL (x-amz-acl)
+	TaggingDirective  string            // COPY (default) or REPLACE
+	Tags              map[string]string // Replacement tags for REPLACE
 	OwnerID           int64
+
+	// CacheControl, ContentDisposition, ContentEncoding and Expires replace
+	// the source's under REPLACE, where empty clears them. StorageClass
+	// replaces the source's under REPLACE when set. All are ignored under COPY.
+	CacheControl       string
+	ContentDisposition string
+	ContentEncoding    string
+	Expires            string
+	StorageClass       domain.StorageClass
+
+	// SourceSSECustomerKey is required when the source is stored with SSE-C.
+	SourceSSECustomerKey *crypto.SSECustomerKey
+
+	// SSECustomerKey encrypts the copy with a customer-provided key. Optional.
+	SSECustomerKey *crypto.SSECustomerKey
+
+	// ObjectLock is the copy's initial retention and legal hold
+	// (x-amz-object-lock-* headers). Without it the destination bucket's
+	// default retention applies. Optional.
+	ObjectLock *domain.ObjectLock
 }
 
 // CopyObjectOutput contains the result of copying an object.
 type CopyObjectOutput struct {
-	ETag         string
-	LastModified time.Time
-	VersionID    string
+	ETag            string
+	LastModified    time.Time
+	VersionID       string
+	SourceVersionID string
+
+	// SSECustomerKeyMD5 is set when the copy was encrypted with SSE-C.
+	SSECustomerKeyMD5 string
 }
 
 // ListObjectVersionsInput contains the data needed to list object versions.
@@ -241,6 +547,27 @@ func (s *ObjectService) PutObject(ctx context.Context, input PutObjectInput) (*P
 	if err := validateObjectKey(input.Key); err != nil {
 		return nil, err
 	}
+	if err := validateKeyDepth(input.Key, s.maxKeyDepth); err != nil {
+		return nil, err
+	}
+
+	// An expiration in the past would delete the object on the next worker run
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		return nil, domain.ErrInvalidObjectExpiry
+	}
+
+	if err := domain.ValidateTags(input.Tags); err != nil {
+		return nil, err
+	}
+
+	if input.ObjectLock != nil {
+		if err := domain.ValidateRetention(input.ObjectLock.Mode, input.ObjectLock.RetainUntil, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	// Track the write so DeleteBucket can drain it
+	defer bucketWrites.Begin(input.BucketName)()
 
 	// Get bucket
 	bucket, err := s.bucketRepo.GetByName(ctx, input.BucketName)
@@ -253,22 +580,112 @@ func (s *ObjectService) PutObject(ctx context.Context, input PutObjectInput) (*P
 	}
 
 	// Check ownership
-	if input.OwnerID > 0 && bucket.OwnerID != input.OwnerID {
+	if !bucketAccessAllowed(ctx, bucket, input.OwnerID) {
 		return nil, ErrBucketAccessDenied
 	}
 
+	// Reject writes to a bucket that is being deleted
+	if bucket.IsDeleting() {
+		return nil, domain.ErrBucketDeleting
+	}
+
+	// With ACLs disabled, the bucket owner owns the object regardless of x-amz-acl
+	if !bucket.AcceptsCannedACL(input.ACL) {
+		return nil, ErrACLNotSupported
+	}
+
+	if input.ObjectLock != nil && !bucket.ObjectLock {
+		return nil, domain.ErrObjectLockNotEnabled
+	}
+
+	// Enforce the bucket's encryption requirement before storing anything
+	if !bucket.AcceptsEncryption(input.SSECustomerKey != nil) {
+		return nil, domain.ErrEncryptionRequired
+	}
+
+	// An append stores the current content followed by the body
+	var base *appendBase
+	if input.WriteOffset != nil {
+		base, err = s.prepareAppend(ctx, bucket, input)
+		if err != nil {
+			return nil, err
+		}
+		defer base.Close()
+	}
+
+	// Set default content type, or keep the type of the content appended to
+	contentType := input.ContentType
+	if contentType == "" && base.prepends() {
+		contentType = base.obj.ContentType
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	// Enforce the bucket's content-type policy before storing anything
+	if !bucket.ContentTypePolicy.Allows(contentType) {
+		return nil, domain.ErrContentTypeNotAllowed
+	}
+
+	body, expectedSize, received := s.sizePolicy.prepareBody(input.Body, input.Size)
+
+	// The digest and checksum cover the body as sent, before any encryption
+	var digest *crypto.MD5Reader
+	if input.ContentMD5 != nil {
+		digest = crypto.NewMD5Reader(body)
+		body = digest
+	}
+	body, checksum, err := newChecksumBody(body, input.ChecksumAlgorithm, input.Checksum)
+	if err != nil {
+		return nil, err
+	}
+	body, expectedSize = base.prepend(body, expectedSize)
+
+	// SSE-C content is encrypted before it reaches storage, so the blob
+	// holds (and is addressed by) the ciphertext
+	if input.SSECustomerKey != nil {
+		encrypted, size, err := encryptSSEC(input.SSECustomerKey, bucket.Name, input.Key, body, input.Size)
+		if err != nil {
+			return nil, err
+		}
+		defer encrypted.Close()
+		body = encrypted
+		if received == nil { // a lenient body is counted rather than verified
+			expectedSize = size
+		}
+	}
+
 	// Store content in CAS storage
-	contentHash, err := s.storage.Store(ctx, input.Body, input.Size)
+	contentHash, err := s.storage.Store(storage.WithContentType(ctx, contentType), body, expectedSize)
 	if err != nil {
+		if storage.IsStorageFull(err) {
+			return nil, s.storageFull(bucket.Name, input.Key, err)
+		}
 		s.logger.Error().Err(err).Str("key", input.Key).Msg("failed to store content")
-		return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
+		return nil, storeBodyError(err)
+	}
+
+	if digest != nil && !bytes.Equal(digest.Sum(), input.ContentMD5) {
+		s.discardBlob(ctx, contentHash)
+		return nil, domain.ErrBadDigest
+	}
+	checksumValue, err := checksum.verify()
+	if err != nil {
+		s.discardBlob(ctx, contentHash)
+		return nil, err
+	}
+
+	size := receivedSize(s.logger, input.Key, input.Size, received) + base.size()
+	storedSize := size
+	if input.SSECustomerKey != nil {
+		storedSize = crypto.CalculateEncryptedSize(size)
 	}
 
 	// Get storage path for blob
 	storagePath := s.storage.GetPath(contentHash)
 
 	// Upsert blob metadata (handles deduplication via ref_count)
-	_, err = s.blobRepo.UpsertWithRefIncrement(ctx, contentHash, input.Size, storagePath)
+	_, err = s.blobRepo.UpsertWithRefIncrement(ctx, contentHash, storedSize, storagePath)
 	if err != nil {
 		s.logger.Error().Err(err).Str("content_hash", contentHash).Msg("failed to upsert blob")
 		return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
@@ -277,48 +694,79 @@ func (s *ObjectService) PutObject(ctx context.Context, input PutObjectInput) (*P
 	// Calculate ETag (MD5 of content hash for simplicity, or we could stream MD5)
 	etag := calculateETag(contentHash)
 
-	// Set default content type
-	contentType := input.ContentType
-	if contentType == "" {
-		contentType = "application/octet-stream"
-	}
-
 	// Handle versioning logic
-	if bucket.IsVersioningEnabled() {
-		// Versioning is enabled: mark existing latest as not latest (keep all versions)
-		_ = s.objectRepo.MarkNotLatest(ctx, bucket.ID, input.Key)
-	} else {
-		// Non-versioned or suspended: replace existing object
-		existingObj, err := s.objectRepo.GetByKey(ctx, bucket.ID, input.Key)
-		if err == nil && existingObj.ContentHash != nil {
-			// Decrement ref count for old blob
-			_, _ = s.blobRepo.DecrementRef(ctx, *existingObj.ContentHash)
-		}
-		// Mark existing as not latest
-		_ = s.objectRepo.MarkNotLatest(ctx, bucket.ID, input.Key)
-	}
+	deltaBaseHash := deltaBase(ctx, s.deltas, s.objectRepo, s.blobRepo, bucket, input.Key)
+	versionID, replaced := prepareObjectWrite(ctx, s.objectRepo, bucket, input.Key)
 
 	// Create new object
-	obj := domain.NewObject(bucket.ID, input.Key, contentHash, contentType, etag, input.Size)
+	obj := domain.NewObject(bucket.ID, input.Key, contentHash, contentType, etag, size)
+	obj.NormalizedKey = bucket.NormalizeKey(input.Key)
+	obj.VersionID = versionID
+	obj.ExpiresAt = input.ExpiresAt
 	if input.Metadata != nil {
 		obj.Metadata = input.Metadata
+	} else if base.prepends() {
+		obj.Metadata = base.obj.Metadata
+	}
+	obj.CacheControl = input.CacheControl
+	obj.ContentDisposition = input.ContentDisposition
+	obj.ContentEncoding = input.ContentEncoding
+	obj.Expires = input.Expires
+	if base.prepends() && input.CacheControl == "" && input.ContentDisposition == "" && input.ContentEncoding == "" && input.Expires == "" {
+		obj.CacheControl = base.obj.CacheControl
+		obj.ContentDisposition = base.obj.ContentDisposition
+		obj.ContentEncoding = base.obj.ContentEncoding
+		obj.Expires = base.obj.Expires
 	}
+	if input.SSECustomerKey != nil {
+		obj.SSECustomerAlgorithm = crypto.SSECAlgorithmAES256
+		obj.SSECustomerKeyMD5 = input.SSECustomerKey.KeyMD5
+	}
+	// The checksum covers the body only, not content appended to
+	if !base.prepends() {
+		obj.ChecksumAlgorithm = checksum.algorithmOrEmpty()
+		obj.Checksum = checksumValue
+	}
+	// Created with the version, so it is never visible unlocked or untagged
+	obj.Lock = newVersionLock(bucket, obj, input.ObjectLock)
+	obj.Tags = input.Tags
 
 	if err := s.objectRepo.Create(ctx, obj); err != nil {
+		// Rollback ref count increment
+		_, _ = s.blobRepo.DecrementRef(ctx, contentHash)
 		s.logger.Error().Err(err).Str("key", input.Key).Msg("failed to create object")
-		return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
+		return nil, createObjectError(err)
 	}
 
+	s.deltas.Enqueue(deltaBaseHash, obj)
+	releaseReplacedObject(ctx, s.objectRepo, s.blobRepo, s.logger, s.overwriteMode, replaced)
+	enforceVersionLimit(ctx, s.objectRepo, s.blobRepo, s.logger, bucket, input.Key)
+
 	s.logger.Info().
 		Str("bucket", input.BucketName).
 		Str("key", input.Key).
-		Int64("size", input.Size).
+		Int64("size", size).
 		Str("etag", etag).
 		Msg("object stored")
 
+	s.events.Publish(events.Event{
+		Type:        events.ObjectCreatedPut,
+		Bucket:      input.BucketName,
+		Key:         input.Key,
+		VersionID:   responseVersionID(bucket, obj),
+		ETag:        etag,
+		Size:        size,
+		ContentType: obj.ContentType,
+		ContentHash: contentHash,
+		OwnerID:     input.OwnerID,
+	})
+
 	return &PutObjectOutput{
-		ETag:      etag,
-		VersionID: obj.GetVersionIDString(),
+		ETag:              etag,
+		VersionID:         responseVersionID(bucket, obj),
+		SSECustomerKeyMD5: obj.SSECustomerKeyMD5,
+		ChecksumAlgorithm: obj.ChecksumAlgorithm,
+		Checksum:          obj.Checksum,
 	}, nil
 }
 
@@ -334,32 +782,24 @@ func (s *ObjectService) GetObject(ctx context.Context, input GetObjectInput) (*G
 	}
 
 	// Check ownership
-	if input.OwnerID > 0 && bucket.OwnerID != input.OwnerID {
+	if !bucketAccessAllowed(ctx, bucket, input.OwnerID) {
 		return nil, ErrBucketAccessDenied
 	}
 
 	// Get object
-	var obj *domain.Object
-	var getErr error
-	if input.VersionID != "" && input.VersionID != "null" {
-		versionUUID, parseErr := uuid.Parse(input.VersionID)
-		if parseErr != nil {
-			return nil, domain.ErrInvalidVersionID
-		}
-		obj, getErr = s.objectRepo.GetByKeyAndVersion(ctx, bucket.ID, input.Key, versionUUID)
-	} else {
-		obj, getErr = s.objectRepo.GetByKey(ctx, bucket.ID, input.Key)
-	}
-
+	obj, getErr := getObjectVersion(ctx, s.objectRepo, bucket, input.Key, input.VersionID)
 	if getErr != nil {
-		if errors.Is(getErr, domain.ErrObjectNotFound) {
-			return nil, domain.ErrObjectNotFound
+		if errors.Is(getErr, domain.ErrObjectNotFound) || errors.Is(getErr, domain.ErrInvalidVersionID) {
+			return nil, getErr
 		}
 		return nil, fmt.Errorf("%w: %v", ErrInternalError, getErr)
 	}
 
 	// Check if it's a delete marker
 	if obj.IsDeleteMarker {
+		if input.VersionID != "" {
+			return nil, domain.ErrVersionIsDeleteMarker
+		}
 		return nil, domain.ErrObjectDeleted
 	}
 
@@ -367,43 +807,94 @@ func (s *ObjectService) GetObject(ctx context.Context, input GetObjectInput) (*G
 		return nil, domain.ErrObjectNotFound
 	}
 
+	if err := checkSSECustomerKey(obj, input.SSECustomerKey); err != nil {
+		return nil, err
+	}
+
+	// Conditions are decided before any content is read
+	if err := input.Conditions.Evaluate(obj.ETag, obj.CreatedAt); err != nil {
+		if errors.Is(err, domain.ErrNotModified) {
+			return &GetObjectOutput{
+				ETag:         obj.ETag,
+				LastModified: obj.CreatedAt,
+				VersionID:    responseVersionID(bucket, obj),
+				NotModified:  true,
+			}, nil
+		}
+		return nil, err
+	}
+
 	// Retrieve content from storage
 	var reader io.ReadCloser
 	var contentLength int64
 	var contentRange string
 
-	if input.Range != nil {
+	if obj.IsSSECustomerEncrypted() {
+		// Offsets refer to the plaintext, so the whole blob is read and
+		// decrypted up to the end of the range
+		start, length := int64(0), obj.Size
+		if input.Range != nil {
+			rangeStart, end, rangeErr := input.Range.Resolve(obj.Size)
+			if rangeErr != nil {
+				return nil, rangeErr
+			}
+			start, length = rangeStart, end-rangeStart+1
+			contentRange = fmt.Sprintf("bytes %d-%d/%d", start, end, obj.Size)
+		}
+		reader, err = s.retrieveBlob(ctx, *obj.ContentHash)
+		if err == nil {
+			reader, err = decryptSSEC(input.SSECustomerKey, bucket.Name, obj.Key, reader, start, length)
+		}
+		contentLength = length
+	} else if input.Range != nil {
 		// Check if storage supports range reads
 		rangeReader, ok := s.storage.(RangeReader)
 		if !ok {
 			return nil, fmt.Errorf("storage backend does not support range requests")
 		}
 		// Range request
-		length := input.Range.End - input.Range.Start + 1
-		reader, err = rangeReader.RetrieveRange(ctx, *obj.ContentHash, input.Range.Start, length)
+		start, end, rangeErr := input.Range.Resolve(obj.Size)
+		if rangeErr != nil {
+			return nil, rangeErr
+		}
+		length := end - start + 1
+		reader, err = retrieveBlobRange(ctx, rangeReader, s.blobRepo, *obj.ContentHash, start, length)
 		contentLength = length
-		contentRange = fmt.Sprintf("bytes %d-%d/%d", input.Range.Start, input.Range.End, obj.Size)
+		contentRange = fmt.Sprintf("bytes %d-%d/%d", start, end, obj.Size)
 	} else {
-		reader, err = s.storage.Retrieve(ctx, *obj.ContentHash)
+		reader, err = s.retrieveBlob(ctx, *obj.ContentHash)
 		contentLength = obj.Size
 	}
 
 	if err != nil {
 		if errors.Is(err, storage.ErrBlobNotFound) {
-			return nil, domain.ErrObjectNotFound
+			return nil, s.objectDataMissing(input.BucketName, obj)
 		}
 		return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
 	}
 
+	s.recordAccess(ctx, *obj.ContentHash)
+
+	headers := serveHeaders(bucket, obj, input.ResponseHeaders)
+
 	return &GetObjectOutput{
-		Body:          reader,
-		ContentLength: contentLength,
-		ContentType:   obj.ContentType,
-		ETag:          obj.ETag,
-		LastModified:  obj.CreatedAt,
-		VersionID:     obj.GetVersionIDString(),
-		Metadata:      obj.Metadata,
-		ContentRange:  contentRange,
+		Body:               reader,
+		ContentLength:      contentLength,
+		ContentType:        headers.ContentType,
+		ETag:               obj.ETag,
+		LastModified:       obj.CreatedAt,
+		VersionID:          responseVersionID(bucket, obj),
+		Metadata:           obj.Metadata,
+		StorageClass:       obj.StorageClass,
+		ContentRange:       contentRange,
+		ContentDisposition: headers.ContentDisposition,
+		CacheControl:       headers.CacheControl,
+		ContentEncoding:    headers.ContentEncoding,
+		Expires:            headers.Expires,
+		ContentLanguage:    headers.ContentLanguage,
+		SSECustomerKeyMD5:  obj.SSECustomerKeyMD5,
+		ChecksumAlgorithm:  obj.ChecksumAlgorithm,
+		Checksum:           obj.Checksum,
 	}, nil
 }
 
@@ -419,44 +910,131 @@ func (s *ObjectService) HeadObject(ctx context.Context, input HeadObjectInput) (
 	}
 
 	// Check ownership
-	if input.OwnerID > 0 && bucket.OwnerID != input.OwnerID {
+	if !bucketAccessAllowed(ctx, bucket, input.OwnerID) {
 		return nil, ErrBucketAccessDenied
 	}
 
 	// Get object
-	var obj *domain.Object
-	var getErr error
-	if input.VersionID != "" && input.VersionID != "null" {
-		versionUUID, parseErr := uuid.Parse(input.VersionID)
-		if parseErr != nil {
-			return nil, domain.ErrInvalidVersionID
-		}
-		obj, getErr = s.objectRepo.GetByKeyAndVersion(ctx, bucket.ID, input.Key, versionUUID)
-	} else {
-		obj, getErr = s.objectRepo.GetByKey(ctx, bucket.ID, input.Key)
-	}
-
+	obj, getErr := getObjectVersion(ctx, s.objectRepo, bucket, input.Key, input.VersionID)
 	if getErr != nil {
-		if errors.Is(getErr, domain.ErrObjectNotFound) {
-			return nil, domain.ErrObjectNotFound
+		if errors.Is(getErr, domain.ErrObjectNotFound) || errors.Is(getErr, domain.ErrInvalidVersionID) {
+			return nil, getErr
 		}
 		return nil, fmt.Errorf("%w: %v", ErrInternalError, getErr)
 	}
 
 	// Check if it's a delete marker
 	if obj.IsDeleteMarker {
+		if input.VersionID != "" {
+			return nil, domain.ErrVersionIsDeleteMarker
+		}
 		return nil, domain.ErrObjectDeleted
 	}
 
-	return &HeadObjectOutput{
-		ContentLength: obj.Size,
-		ContentType:   obj.ContentType,
-		ETag:          obj.ETag,
-		LastModified:  obj.CreatedAt,
-		VersionID:     obj.GetVersionIDString(),
-		Metadata:      obj.Metadata,
-		StorageClass:  obj.StorageClass,
-	}, nil
+	if err := checkSSECustomerKey(obj, input.SSECustomerKey); err != nil {
+		return nil, err
+	}
+
+	if err := input.Conditions.Evaluate(obj.ETag, obj.CreatedAt); err != nil {
+		if errors.Is(err, domain.ErrNotModified) {
+			return &HeadObjectOutput{
+				ETag:         obj.ETag,
+				LastModified: obj.CreatedAt,
+				VersionID:    responseVersionID(bucket, obj),
+				NotModified:  true,
+			}, nil
+		}
+		return nil, err
+	}
+
+	// A ranged HEAD describes the response a ranged GET would return
+	contentLength := obj.Size
+	var contentRange string
+	if input.Range != nil {
+		start, end, err := input.Range.Resolve(obj.Size)
+		if err != nil {
+			return nil, err
+		}
+		contentLength = end - start + 1
+		contentRange = fmt.Sprintf("bytes %d-%d/%d", start, end, obj.Size)
+	}
+
+	headers := serveHeaders(bucket, obj, input.ResponseHeaders)
+
+	output := &HeadObjectOutput{
+		ContentLength:      contentLength,
+		ContentType:        headers.ContentType,
+		ETag:               obj.ETag,
+		LastModified:       obj.CreatedAt,
+		VersionID:          responseVersionID(bucket, obj),
+		Metadata:           obj.Metadata,
+		StorageClass:       obj.StorageClass,
+		ContentRange:       contentRange,
+		ContentDisposition: headers.ContentDisposition,
+		CacheControl:       headers.CacheControl,
+		ContentEncoding:    headers.ContentEncoding,
+		Expires:            headers.Expires,
+		ContentLanguage:    headers.ContentLanguage,
+		SSECustomerKeyMD5:  obj.SSECustomerKeyMD5,
+		ChecksumAlgorithm:  obj.ChecksumAlgorithm,
+		Checksum:           obj.Checksum,
+	}
+
+	if input.IncludeEncryption && obj.ContentHash != nil {
+		blob, err := s.blobRepo.GetByHash(ctx, *obj.ContentHash)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
+		}
+		output.Encryption = &ObjectEncryption{
+			ContentHash: blob.ContentHash,
+			Encrypted:   blob.IsEncrypted,
+			Scheme:      blob.EncryptionScheme,
+		}
+	}
+
+	return output, nil
+}
+
+// PutObjectACL applies a canned ACL to an object. Objects carry no ACLs of
+// their own, so ACLs granting only the bucket owner access are accepted as
+// no-ops; with ACLs disabled any other ACL is rejected.
+func (s *ObjectService) PutObjectACL(ctx context.Context, input PutObjectACLInput) error {
+	bucket, err := s.bucketRepo.GetByName(ctx, input.BucketName)
+	if err != nil {
+		if errors.Is(err, domain.ErrBucketNotFound) {
+			return domain.ErrBucketNotFound
+		}
+		return fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+
+	// Check ownership
+	if !bucketAccessAllowed(ctx, bucket, input.OwnerID) {
+		return ErrBucketAccessDenied
+	}
+
+	if !domain.IsOwnerOnlyACL(input.ACL) {
+		if bucket.ACLsDisabled() {
+			return ErrACLNotSupported
+		}
+		return ErrObjectACLNotImplemented
+	}
+
+	// The object must exist
+	obj, getErr := getObjectVersion(ctx, s.objectRepo, bucket, input.Key, input.VersionID)
+	if getErr != nil {
+		if errors.Is(getErr, domain.ErrObjectNotFound) || errors.Is(getErr, domain.ErrInvalidVersionID) {
+			return getErr
+		}
+		return fmt.Errorf("%w: %v", ErrInternalError, getErr)
+	}
+	if obj.IsDeleteMarker {
+		if input.VersionID != "" {
+			return domain.ErrVersionIsDeleteMarker
+		}
+		return domain.ErrObjectDeleted
+	}
+
+	return nil
 }
 
 // DeleteObject deletes an object or creates a delete marker.
@@ -471,19 +1049,19 @@ func (s *ObjectService) DeleteObject(ctx context.Context, input DeleteObjectInpu
 	}
 
 	// Check ownership
-	if input.OwnerID > 0 && bucket.OwnerID != input.OwnerID {
+	if !bucketAccessAllowed(ctx, bucket, input.OwnerID) {
 		return nil, ErrBucketAccessDenied
 	}
 
-	// If versioning is enabled and no version specified, create delete marker
-	if bucket.IsVersioningEnabled() && input.VersionID == "" {
+	// Without a version ID, enabled and suspended buckets get a delete marker.
+	// In a suspended bucket the marker replaces the null version.
+	if bucket.IsVersioningEverEnabled() && input.VersionID == "" {
 		deleteMarker := domain.NewDeleteMarker(bucket.ID, input.Key)
-
-		// Mark current version as not latest
-		_ = s.objectRepo.MarkNotLatest(ctx, bucket.ID, input.Key)
+		deleteMarker.NormalizedKey = bucket.NormalizeKey(input.Key)
+		deleteMarker.VersionID, _ = prepareObjectWrite(ctx, s.objectRepo, bucket, input.Key)
 
 		if err := s.objectRepo.Create(ctx, deleteMarker); err != nil {
-			return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
+			return nil, createObjectError(err)
 		}
 
 		s.logger.Info().
@@ -492,6 +1070,14 @@ func (s *ObjectService) DeleteObject(ctx context.Context, input DeleteObjectInpu
 			Str("version_id", deleteMarker.GetVersionIDString()).
 			Msg("delete marker created")
 
+		s.events.Publish(events.Event{
+			Type:      events.ObjectRemovedDeleteMarkerCreated,
+			Bucket:    input.BucketName,
+			Key:       input.Key,
+			VersionID: deleteMarker.GetVersionIDString(),
+			OwnerID:   input.OwnerID,
+		})
+
 		return &DeleteObjectOutput{
 			DeleteMarker:          true,
 			VersionID:             deleteMarker.GetVersionIDString(),
@@ -500,19 +1086,11 @@ func (s *ObjectService) DeleteObject(ctx context.Context, input DeleteObjectInpu
 	}
 
 	// Delete specific version or non-versioned object
-	var obj *domain.Object
-	var getErr error
-	if input.VersionID != "" && input.VersionID != "null" {
-		versionUUID, parseErr := uuid.Parse(input.VersionID)
-		if parseErr != nil {
-			return nil, domain.ErrInvalidVersionID
-		}
-		obj, getErr = s.objectRepo.GetByKeyAndVersion(ctx, bucket.ID, input.Key, versionUUID)
-	} else {
-		obj, getErr = s.objectRepo.GetByKey(ctx, bucket.ID, input.Key)
-	}
-
+	obj, getErr := getObjectVersion(ctx, s.objectRepo, bucket, input.Key, input.VersionID)
 	if getErr != nil {
+		if errors.Is(getErr, domain.ErrInvalidVersionID) {
+			return nil, getErr
+		}
 		if errors.Is(getErr, domain.ErrObjectNotFound) {
 			// S3 returns success even if object doesn't exist
 			return &DeleteObjectOutput{}, nil
@@ -520,14 +1098,12 @@ func (s *ObjectService) DeleteObject(ctx context.Context, input DeleteObjectInpu
 		return nil, fmt.Errorf("%w: %v", ErrInternalError, getErr)
 	}
 
-	// Decrement blob ref count if object has content
-	if obj.ContentHash != nil {
-		if _, err := s.blobRepo.DecrementRef(ctx, *obj.ContentHash); err != nil {
-			s.logger.Error().Err(err).Str("content_hash", *obj.ContentHash).Msg("failed to decrement ref count")
-		}
+	if err := checkObjectLock(ctx, s.objectRepo, bucket, obj, input.BypassGovernanceRetention); err != nil {
+		return nil, err
 	}
 
-	// Delete the object record
+	// Soft-delete the object record. The blob reference is released when the
+	// garbage collector purges the record after the retention period.
 	if err := s.objectRepo.Delete(ctx, obj.ID); err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
 	}
@@ -537,12 +1113,97 @@ func (s *ObjectService) DeleteObject(ctx context.Context, input DeleteObjectInpu
 		Str("key", input.Key).
 		Msg("object deleted")
 
+	s.events.Publish(events.Event{
+		Type:      events.ObjectRemovedDelete,
+		Bucket:    input.BucketName,
+		Key:       input.Key,
+		VersionID: responseVersionID(bucket, obj),
+		OwnerID:   input.OwnerID,
+	})
+
 	return &DeleteObjectOutput{
 		DeleteMarker: obj.IsDeleteMarker,
-		VersionID:    obj.GetVersionIDString(),
+		VersionID:    responseVersionID(bucket, obj),
 	}, nil
 }
 
+// DeleteAllVersions soft-deletes every version of a key, including delete
+// markers, in batches of the configured size so that a key with millions of
+// versions never holds one huge transaction. Like DeleteObject, each version
+// keeps its blob reference until the garbage collector purges it after the
+// retention period, which releases the references one version at a time.
+func (s *ObjectService) DeleteAllVersions(ctx context.Context, input DeleteAllVersionsInput) (*DeleteAllVersionsOutput, error) {
+	bucket, err := s.bucketRepo.GetByName(ctx, input.BucketName)
+	if err != nil {
+		if errors.Is(err, domain.ErrBucketNotFound) {
+			return nil, domain.ErrBucketNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+
+	// Check ownership
+	if !bucketAccessAllowed(ctx, bucket, input.OwnerID) {
+		return nil, ErrBucketAccessDenied
+	}
+
+	key := bucket.NormalizeKey(input.Key)
+
+	// Refuse up front rather than stop partway through the versions
+	if bucket.ObjectLock {
+		versions, err := s.objectRepo.ListKeyVersions(ctx, bucket.ID, key)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
+		}
+		for _, obj := range versions {
+			if err := checkObjectLock(ctx, s.objectRepo, bucket, obj, input.BypassGovernanceRetention); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	output := &DeleteAllVersionsOutput{}
+	for {
+		if err := ctx.Err(); err != nil {
+			return output, err
+		}
+
+		deleted, err := s.objectRepo.DeleteAllVersions(ctx, bucket.ID, key, s.deleteBatchSize)
+		if err != nil {
+			return output, fmt.Errorf("%w: %v", ErrInternalError, err)
+		}
+		if deleted == 0 {
+			break
+		}
+
+		output.Deleted += deleted
+		output.Batches++
+		if input.Progress != nil {
+			input.Progress(output.Deleted)
+		}
+
+		s.logger.Debug().
+			Str("bucket", input.BucketName).
+			Str("key", input.Key).
+			Int64("deleted", output.Deleted).
+			Msg("deleted version batch")
+
+		if deleted < int64(s.deleteBatchSize) {
+			break
+		}
+	}
+
+	if output.Deleted > 0 {
+		s.logger.Info().
+			Str("bucket", input.BucketName).
+			Str("key", input.Key).
+			Int64("versions", output.Deleted).
+			Int("batches", output.Batches).
+			Msg("all versions deleted")
+	}
+
+	return output, nil
+}
+
 // ListObjects lists objects in a bucket (v1 and v2 compatible).
 func (s *ObjectService) ListObjects(ctx context.Context, input ListObjectsInput) (*ListObjectsOutput, error) {
 	// Get bucket
@@ -555,35 +1216,42 @@ func (s *ObjectService) ListObjects(ctx context.Context, input ListObjectsInput)
 	}
 
 	// Check ownership
-	if input.OwnerID > 0 && bucket.OwnerID != input.OwnerID {
+	if !bucketAccessAllowed(ctx, bucket, input.OwnerID) {
 		return nil, ErrBucketAccessDenied
 	}
 
 	// Set defaults
 	maxKeys := input.MaxKeys
-	if maxKeys <= 0 {
-		maxKeys = 1000
-	}
-	if maxKeys > 1000 {
-		maxKeys = 1000
+	if maxKeys <= 0 || maxKeys > MaxListKeys {
+		maxKeys = MaxListKeys
 	}
 
-	// Determine start key
+	// Determine start key. A continuation token takes precedence over
+	// start-after, which only applies to the first page.
 	startAfter := input.StartAfter
 	if startAfter == "" {
 		startAfter = input.Marker
 	}
-	if startAfter == "" && input.ContinuationToken != "" {
-		// Decode continuation token (simple base64 of key)
-		startAfter = decodeContinuationToken(input.ContinuationToken)
+	var snapshot *repository.ListSnapshot
+	if input.ContinuationToken != "" {
+		startAfter, snapshot, err = decodeContinuationToken(input.ContinuationToken)
+		if err != nil {
+			return nil, err
+		}
+	} else if input.Snapshot {
+		snapshot, err = s.objectRepo.ListSnapshot(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
+		}
 	}
 
-	// List objects from repository
-	result, err := s.objectRepo.List(ctx, bucket.ID, repository.ObjectListOptions{
-		Prefix:     input.Prefix,
+	// List objects from repository, rolling up keys by delimiter
+	result, err := repository.ListWithDelimiter(ctx, s.objectRepo, bucket, repository.ObjectListOptions{
+		Prefix:     bucket.NormalizeKey(input.Prefix),
 		Delimiter:  input.Delimiter,
-		StartAfter: startAfter,
+		StartAfter: bucket.NormalizeKey(startAfter),
 		MaxKeys:    maxKeys,
+		Snapshot:   snapshot,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
@@ -612,17 +1280,29 @@ func (s *ObjectService) ListObjects(ctx context.Context, input ListObjectsInput)
 		KeyCount:       result.KeyCount,
 	}
 
-	if result.IsTruncated && len(contents) > 0 {
-		lastKey := contents[len(contents)-1].Key
-		output.NextMarker = lastKey
-		output.NextContinuationToken = encodeContinuationToken(lastKey)
+	// The repository cursor is the last key of the page and is only set
+	// when another page follows
+	if result.IsTruncated && result.NextContinuationToken != "" {
+		output.NextMarker = result.NextContinuationToken
+		output.NextContinuationToken = encodeContinuationToken(result.NextContinuationToken, snapshot)
 	}
 
 	return output, nil
 }
 
-// CopyObject copies an object within or between buckets.
+// CopyObject copies an object within or between buckets. The copy references
+// the source blob and only takes another reference on it, so no content is
+// read or written; SSE-C objects are the exception and are stored again.
 func (s *ObjectService) CopyObject(ctx context.Context, input CopyObjectInput) (*CopyObjectOutput, error) {
+	if input.ObjectLock != nil {
+		if err := domain.ValidateRetention(input.ObjectLock.Mode, input.ObjectLock.RetainUntil, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	// Track the write so DeleteBucket can drain it
+	defer bucketWrites.Begin(input.DestBucket)()
+
 	// Get source bucket
 	sourceBucket, err := s.bucketRepo.GetByName(ctx, input.SourceBucket)
 	if err != nil {
@@ -633,7 +1313,7 @@ func (s *ObjectService) CopyObject(ctx context.Context, input CopyObjectInput) (
 	}
 
 	// Check source ownership
-	if input.OwnerID > 0 && sourceBucket.OwnerID != input.OwnerID {
+	if !bucketAccessAllowed(ctx, sourceBucket, input.OwnerID) {
 		return nil, ErrBucketAccessDenied
 	}
 
@@ -647,26 +1327,28 @@ func (s *ObjectService) CopyObject(ctx context.Context, input CopyObjectInput) (
 	}
 
 	// Check destination ownership
-	if input.OwnerID > 0 && destBucket.OwnerID != input.OwnerID {
+	if !bucketAccessAllowed(ctx, destBucket, input.OwnerID) {
 		return nil, ErrBucketAccessDenied
 	}
 
-	// Get source object
-	var sourceObj *domain.Object
-	var getErr error
-	if input.SourceVersionID != "" && input.SourceVersionID != "null" {
-		versionUUID, parseErr := uuid.Parse(input.SourceVersionID)
-		if parseErr != nil {
-			return nil, domain.ErrInvalidVersionID
-		}
-		sourceObj, getErr = s.objectRepo.GetByKeyAndVersion(ctx, sourceBucket.ID, input.SourceKey, versionUUID)
-	} else {
-		sourceObj, getErr = s.objectRepo.GetByKey(ctx, sourceBucket.ID, input.SourceKey)
+	// Reject writes to a bucket that is being deleted
+	if destBucket.IsDeleting() {
+		return nil, domain.ErrBucketDeleting
 	}
 
+	if input.ObjectLock != nil && !destBucket.ObjectLock {
+		return nil, domain.ErrObjectLockNotEnabled
+	}
+
+	if !destBucket.AcceptsCannedACL(input.ACL) {
+		return nil, ErrACLNotSupported
+	}
+
+	// Get source object
+	sourceObj, getErr := getObjectVersion(ctx, s.objectRepo, sourceBucket, input.SourceKey, input.SourceVersionID)
 	if getErr != nil {
-		if errors.Is(getErr, domain.ErrObjectNotFound) {
-			return nil, domain.ErrObjectNotFound
+		if errors.Is(getErr, domain.ErrObjectNotFound) || errors.Is(getErr, domain.ErrInvalidVersionID) {
+			return nil, getErr
 		}
 		return nil, fmt.Errorf("%w: %v", ErrInternalError, getErr)
 	}
@@ -675,19 +1357,29 @@ func (s *ObjectService) CopyObject(ctx context.Context, input CopyObjectInput) (
 		return nil, domain.ErrObjectNotFound
 	}
 
+	if err := checkSSECustomerKey(sourceObj, input.SourceSSECustomerKey); err != nil {
+		return nil, err
+	}
+
+	// Evaluate x-amz-copy-source-if-* against the source object
+	if err := input.Conditions.Evaluate(sourceObj.ETag, sourceObj.CreatedAt); err != nil {
+		return nil, err
+	}
+
 	// Validate destination key
 	if err := validateObjectKey(input.DestKey); err != nil {
 		return nil, err
 	}
-
-	// Increment blob ref count (same content, new object)
-	if err := s.blobRepo.IncrementRef(ctx, *sourceObj.ContentHash); err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
+	if err := validateKeyDepth(input.DestKey, s.maxKeyDepth); err != nil {
+		return nil, err
 	}
 
-	// Determine content type and metadata
+	// Determine content type and metadata: COPY keeps all of the source's,
+	// REPLACE takes them from the request
 	contentType := sourceObj.ContentType
 	metadata := sourceObj.Metadata
+	cacheControl, disposition, encoding, expires := sourceObj.CacheControl, sourceObj.ContentDisposition, sourceObj.ContentEncoding, sourceObj.Expires
+	storageClass := sourceObj.StorageClass
 	if input.MetadataDirective == "REPLACE" {
 		if input.ContentType != "" {
 			contentType = input.ContentType
@@ -695,28 +1387,84 @@ func (s *ObjectService) CopyObject(ctx context.Context, input CopyObjectInput) (
 		if input.Metadata != nil {
 			metadata = input.Metadata
 		}
+		cacheControl, disposition, encoding, expires = input.CacheControl, input.ContentDisposition, input.ContentEncoding, input.Expires
+		if input.StorageClass != "" {
+			storageClass = input.StorageClass
+		}
+	}
+
+	// Enforce the destination bucket's content-type policy
+	if !destBucket.ContentTypePolicy.Allows(contentType) {
+		return nil, domain.ErrContentTypeNotAllowed
 	}
 
-	// Mark existing destination as not latest
-	if destBucket.Versioning != domain.VersioningEnabled {
-		existingObj, err := s.objectRepo.GetByKey(ctx, destBucket.ID, input.DestKey)
-		if err == nil && existingObj.ContentHash != nil {
-			_, _ = s.blobRepo.DecrementRef(ctx, *existingObj.ContentHash)
+	// A copy is an upload to the destination bucket
+	if !destBucket.AcceptsEncryption(input.SSECustomerKey != nil) {
+		return nil, domain.ErrEncryptionRequired
+	}
+
+	// Determine tags: COPY carries the source version's tags over
+	var tags map[string]string
+	switch input.TaggingDirective {
+	case "", TaggingDirectiveCopy:
+		tags, err = s.objectRepo.GetTags(ctx, sourceObj.ID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
 		}
-		_ = s.objectRepo.MarkNotLatest(ctx, destBucket.ID, input.DestKey)
+	case TaggingDirectiveReplace:
+		if err := domain.ValidateTags(input.Tags); err != nil {
+			return nil, err
+		}
+		tags = input.Tags
+	default:
+		return nil, ErrInvalidTaggingDirective
 	}
 
+	contentHash, etag := *sourceObj.ContentHash, sourceObj.ETag
+	if sourceObj.IsSSECustomerEncrypted() || input.SSECustomerKey != nil {
+		// SSE-C ciphertext is bound to its key and location, so the content
+		// is decrypted and stored again instead of shared
+		contentHash, err = s.rewriteSSECContent(ctx, sourceBucket.Name, sourceObj, input)
+		if err != nil {
+			return nil, err
+		}
+		etag = calculateETag(contentHash)
+	} else if err := s.blobRepo.IncrementRef(ctx, contentHash); err != nil {
+		// Increment blob ref count (same content, new object)
+		return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+
+	// Make room for the new destination version
+	versionID, replaced := prepareObjectWrite(ctx, s.objectRepo, destBucket, input.DestKey)
+
 	// Create new object
-	newObj := domain.NewObject(destBucket.ID, input.DestKey, *sourceObj.ContentHash, contentType, sourceObj.ETag, sourceObj.Size)
+	newObj := domain.NewObject(destBucket.ID, input.DestKey, contentHash, contentType, etag, sourceObj.Size)
+	newObj.NormalizedKey = destBucket.NormalizeKey(input.DestKey)
+	newObj.VersionID = versionID
 	newObj.Metadata = metadata
-	newObj.StorageClass = sourceObj.StorageClass
+	newObj.StorageClass = storageClass
+	newObj.CacheControl = cacheControl
+	newObj.ContentDisposition = disposition
+	newObj.ContentEncoding = encoding
+	newObj.Expires = expires
+	newObj.ChecksumAlgorithm = sourceObj.ChecksumAlgorithm // The plaintext is unchanged
+	newObj.Checksum = sourceObj.Checksum
+	if input.SSECustomerKey != nil {
+		newObj.SSECustomerAlgorithm = crypto.SSECAlgorithmAES256
+		newObj.SSECustomerKeyMD5 = input.SSECustomerKey.KeyMD5
+	}
+	newObj.Lock = newVersionLock(destBucket, newObj, input.ObjectLock)
+	newObj.Tags = tags
 
 	if err := s.objectRepo.Create(ctx, newObj); err != nil {
 		// Rollback ref count increment
-		_, _ = s.blobRepo.DecrementRef(ctx, *sourceObj.ContentHash)
-		return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
+		_, _ = s.blobRepo.DecrementRef(ctx, contentHash)
+		return nil, createObjectError(err)
 	}
 
+	releaseReplacedObject(ctx, s.objectRepo, s.blobRepo, s.logger, s.overwriteMode, replaced)
+	enforceVersionLimit(ctx, s.objectRepo, s.blobRepo, s.logger, destBucket, input.DestKey)
+
 	s.logger.Info().
 		Str("source_bucket", input.SourceBucket).
 		Str("source_key", input.SourceKey).
@@ -724,10 +1472,24 @@ func (s *ObjectService) CopyObject(ctx context.Context, input CopyObjectInput) (
 		Str("dest_key", input.DestKey).
 		Msg("object copied")
 
+	s.events.Publish(events.Event{
+		Type:        events.ObjectCreatedCopy,
+		Bucket:      input.DestBucket,
+		Key:         input.DestKey,
+		VersionID:   responseVersionID(destBucket, newObj),
+		ETag:        newObj.ETag,
+		Size:        newObj.Size,
+		ContentType: newObj.ContentType,
+		ContentHash: contentHash,
+		OwnerID:     input.OwnerID,
+	})
+
 	return &CopyObjectOutput{
-		ETag:         newObj.ETag,
-		LastModified: newObj.CreatedAt,
-		VersionID:    newObj.GetVersionIDString(),
+		ETag:              newObj.ETag,
+		LastModified:      newObj.CreatedAt,
+		VersionID:         responseVersionID(destBucket, newObj),
+		SourceVersionID:   responseVersionID(sourceBucket, sourceObj),
+		SSECustomerKeyMD5: newObj.SSECustomerKeyMD5,
 	}, nil
 }
 
@@ -746,6 +1508,118 @@ func validateObjectKey(key string) error {
 	return nil
 }
 
+// validateKeyDepth rejects a key with more than maxDepth "/" delimiters.
+// A maxDepth of 0 allows any depth.
+func validateKeyDepth(key string, maxDepth int) error {
+	if maxDepth > 0 && strings.Count(key, "/") > maxDepth {
+		return fmt.Errorf("%w of %d", domain.ErrObjectKeyTooDeep, maxDepth)
+	}
+	return nil
+}
+
+// getObjectVersion resolves an object version. An empty versionID selects the
+// latest version and domain.NullVersionID selects the null version.
+func getObjectVersion(ctx context.Context, objectRepo repository.ObjectRepository, bucket *domain.Bucket, key, versionID string) (*domain.Object, error) {
+	key = bucket.NormalizeKey(key)
+	if versionID == "" {
+		return objectRepo.GetByKey(ctx, bucket.ID, key)
+	}
+
+	id, err := domain.ParseVersionID(versionID)
+	if err != nil {
+		return nil, err
+	}
+	return objectRepo.GetByKeyAndVersion(ctx, bucket.ID, key, id)
+}
+
+// prepareObjectWrite makes room for a new version of key and returns the
+// version ID the new object must be stored with. Enabled buckets keep every
+// version. Suspended buckets replace only the null version, and buckets that
+// never had versioning replace the current object. The replaced object, if
+// any, is soft-deleted and returned for releaseReplacedObject.
+func prepareObjectWrite(ctx context.Context, objectRepo repository.ObjectRepository, bucket *domain.Bucket, key string) (uuid.UUID, *domain.Object) {
+	key = bucket.NormalizeKey(key)
+
+	var replaced *domain.Object
+	switch bucket.Versioning {
+	case domain.VersioningEnabled:
+		_ = objectRepo.MarkNotLatest(ctx, bucket.ID, key)
+		return uuid.New(), nil
+	case domain.VersioningSuspended:
+		replaced, _ = objectRepo.GetByKeyAndVersion(ctx, bucket.ID, key, uuid.Nil)
+	default:
+		replaced, _ = objectRepo.GetByKey(ctx, bucket.ID, key)
+	}
+
+	if replaced != nil {
+		if err := objectRepo.Delete(ctx, replaced.ID); err != nil {
+			replaced = nil
+		}
+	}
+	_ = objectRepo.MarkNotLatest(ctx, bucket.ID, key)
+
+	return uuid.Nil, replaced
+}
+
+// enforceVersionLimit permanently removes the oldest versions of key beyond
+// the bucket's MaxVersionsPerKey and releases their blob references. It runs
+// after a new version is created; failures are logged and leave the extra
+// versions for the next write. Versions under object lock, including
+// GOVERNANCE retention, are kept.
+func enforceVersionLimit(ctx context.Context, objectRepo repository.ObjectRepository, blobRepo repository.BlobRepository, logger zerolog.Logger, bucket *domain.Bucket, key string) {
+	if bucket.MaxVersionsPerKey <= 0 {
+		return
+	}
+
+	versions, err := objectRepo.ListKeyVersions(ctx, bucket.ID, bucket.NormalizeKey(key))
+	if err != nil {
+		logger.Error().Err(err).Str("bucket", bucket.Name).Str("key", key).Msg("failed to list versions for version limit")
+		return
+	}
+	if len(versions) <= bucket.MaxVersionsPerKey {
+		return
+	}
+
+	for _, obj := range versions[bucket.MaxVersionsPerKey:] {
+		if err := checkObjectLock(ctx, objectRepo, bucket, obj, false); err != nil {
+			if !errors.Is(err, domain.ErrObjectLocked) {
+				logger.Error().Err(err).Int64("object_id", obj.ID).Msg("failed to check object lock")
+			}
+			continue
+		}
+		// Purge only removes deleted rows; if it fails the soft-deleted
+		// version is still purged by the garbage collector after retention
+		if err := objectRepo.Delete(ctx, obj.ID); err != nil {
+			logger.Error().Err(err).Int64("object_id", obj.ID).Msg("failed to delete version beyond limit")
+			continue
+		}
+		if err := objectRepo.Purge(ctx, obj.ID); err != nil {
+			logger.Error().Err(err).Int64("object_id", obj.ID).Msg("failed to purge version beyond limit")
+			continue
+		}
+		if obj.ContentHash != nil {
+			if _, err := blobRepo.DecrementRef(ctx, *obj.ContentHash); err != nil {
+				logger.Error().Err(err).Str("content_hash", *obj.ContentHash).Msg("failed to decrement ref count")
+			}
+		}
+	}
+
+	logger.Debug().
+		Str("bucket", bucket.Name).
+		Str("key", key).
+		Int("removed", len(versions)-bucket.MaxVersionsPerKey).
+		Msg("removed versions beyond limit")
+}
+
+// responseVersionID returns the version ID to report for obj.
+// Buckets that never had versioning enabled report no version.
+func responseVersionID(bucket *domain.Bucket, obj *domain.Object) string {
+	if !bucket.IsVersioningEverEnabled() {
+		return ""
+	}
+	return obj.GetVersionIDString()
+}
+
 // calculateETag generates an ETag from the content hash.
 // For simple uploads, we use MD5 of the SHA256 hash.
 func calculateETag(contentHash string) string {
@@ -753,15 +1627,43 @@ func calculateETag(contentHash string) string {
 	return fmt.Sprintf("\"%s\"", hex.EncodeToString(hash[:]))
 }
 
-// encodeContinuationToken encodes a key as a continuation token.
-func encodeContinuationToken(key string) string {
-	// Simple encoding - in production, might want to encrypt/sign
-	return key
+// encodeContinuationToken encodes the last key of a page as an opaque
+// continuation token, so keys with special characters survive the round trip
+// through the query string. A snapshot listing appends the snapshot, which
+// the base64 alphabet keeps apart from the key with ".".
+func encodeContinuationToken(key string, snapshot *repository.ListSnapshot) string {
+	token := base64.RawURLEncoding.EncodeToString([]byte(key))
+	if snapshot != nil {
+		token += fmt.Sprintf(".%d.%d", snapshot.MaxID, snapshot.TakenAt.UnixNano())
+	}
+	return token
 }
 
-// decodeContinuationToken decodes a continuation token to a key.
-func decodeContinuationToken(token string) string {
-	return token
+// decodeContinuationToken decodes a continuation token to the key and, for a
+// snapshot listing, the snapshot it was encoded from.
+func decodeContinuationToken(token string) (string, *repository.ListSnapshot, error) {
+	encoded, snapshotPart, hasSnapshot := strings.Cut(token, ".")
+	key, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(key) == 0 {
+		return "", nil, domain.ErrInvalidContinuationToken
+	}
+	if !hasSnapshot {
+		return string(key), nil, nil
+	}
+
+	maxIDPart, takenAtPart, ok := strings.Cut(snapshotPart, ".")
+	if !ok {
+		return "", nil, domain.ErrInvalidContinuationToken
+	}
+	maxID, err := strconv.ParseInt(maxIDPart, 10, 64)
+	if err != nil || maxID < 0 {
+		return "", nil, domain.ErrInvalidContinuationToken
+	}
+	takenAt, err := strconv.ParseInt(takenAtPart, 10, 64)
+	if err != nil {
+		return "", nil, domain.ErrInvalidContinuationToken
+	}
+	return string(key), &repository.ListSnapshot{MaxID: maxID, TakenAt: time.Unix(0, takenAt).UTC()}, nil
 }
 
 // RangeReader is an interface for storage backends that support range reads.
@@ -769,6 +1671,130 @@ type RangeReader interface {
 	RetrieveRange(ctx context.Context, contentHash string, offset, length int64) (io.ReadCloser, error)
 }
 
+// SchemeReader is an interface for storage backends that can decrypt blobs
+// written with more than one encryption scheme.
+type SchemeReader interface {
+	RetrieveWithScheme(ctx context.Context, contentHash string, scheme string) (io.ReadCloser, error)
+}
+
+// RangeSchemeReader is a SchemeReader that also serves byte ranges.
+type RangeSchemeReader interface {
+	RetrieveRangeWithScheme(ctx context.Context, contentHash string, scheme string, offset, length int64) (io.ReadCloser, error)
+}
+
+// retrieveBlob reads a blob, routing through the scheme recorded in the blob's
+// metadata when the backend supports several schemes. Blobs without a recorded
+// scheme use the backend's default.
+func (s *ObjectService) retrieveBlob(ctx context.Context, contentHash string) (io.ReadCloser, error) {
+	return retrieveBlob(ctx, s.storage, s.blobRepo, contentHash)
+}
+
+// retrieveBlob reads a blob from backend, see ObjectService.retrieveBlob.
+func retrieveBlob(ctx context.Context, backend storage.Backend, blobRepo repository.BlobRepository, contentHash string) (io.ReadCloser, error) {
+	schemeReader, ok := backend.(SchemeReader)
+	if !ok {
+		return backend.Retrieve(ctx, contentHash)
+	}
+
+	blob, err := blobRepo.GetByHash(ctx, contentHash)
+	if err != nil && !errors.Is(err, domain.ErrBlobNotFound) {
+		return nil, err
+	}
+	if blob == nil || !blob.IsEncrypted || blob.EncryptionScheme == domain.EncryptionSchemeNone {
+		return backend.Retrieve(ctx, contentHash)
+	}
+
+	return schemeReader.RetrieveWithScheme(ctx, contentHash, string(blob.EncryptionScheme))
+}
+
+// retrieveBlobRange reads a byte range of a blob through ranged, routing
+// through the recorded scheme the same way retrieveBlob does.
+func retrieveBlobRange(ctx context.Context, ranged RangeReader, blobRepo repository.BlobRepository, contentHash string, offset, length int64) (io.ReadCloser, error) {
+	schemeReader, ok := ranged.(RangeSchemeReader)
+	if !ok {
+		return ranged.RetrieveRange(ctx, contentHash, offset, length)
+	}
+
+	blob, err := blobRepo.GetByHash(ctx, contentHash)
+	if err != nil && !errors.Is(err, domain.ErrBlobNotFound) {
+		return nil, err
+	}
+	if blob == nil || !blob.IsEncrypted || blob.EncryptionScheme == domain.EncryptionSchemeNone {
+		return ranged.RetrieveRange(ctx, contentHash, offset, length)
+	}
+
+	return schemeReader.RetrieveRangeWithScheme(ctx, contentHash, string(blob.EncryptionScheme), offset, length)
+}
+
+// objectDataMissing reports an object whose record exists but whose blob is
+// gone from storage, see objectDataMissing.
+func (s *ObjectService) objectDataMissing(bucketName string, obj *domain.Object) error {
+	return objectDataMissing(s.logger, s.metrics, bucketName, obj)
+}
+
+// objectDataMissing reports an object whose record exists but whose blob is
+// gone from storage. Unlike a missing key this means stored data was lost,
+// so it is logged and counted rather than answered as NoSuchKey.
+func objectDataMissing(logger zerolog.Logger, m *metrics.Metrics, bucketName string, obj *domain.Object) error {
+	logger.Error().
+		Str("bucket", bucketName).
+		Str("key", obj.Key).
+		Str("version_id", obj.VersionID.String()).
+		Str("content_hash", *obj.ContentHash).
+		Msg("object data is missing from storage")
+	if m != nil {
+		m.RecordMissingBlob()
+	}
+	return fmt.Errorf("%w: %s", domain.ErrObjectDataMissing, *obj.ContentHash)
+}
+
+// storageFull reports a write rejected because storage is full, see storageFull.
+func (s *ObjectService) storageFull(bucketName, key string, err error) error {
+	return storageFull(s.logger, s.metrics, s.onStorageFull, bucketName, key, err)
+}
+
+// storageFull reports a write rejected because storage has no space left.
+// Backends remove what they wrote of a failed blob, so nothing is left to
+// clean up; the failure is logged, counted and handed to onFull, which may
+// free space, and answered with a distinct error rather than InternalError.
+func storageFull(logger zerolog.Logger, m *metrics.Metrics, onFull func(), bucketName, key string, err error) error {
+	logger.Error().
+		Err(err).
+		Str("bucket", bucketName).
+		Str("key", key).
+		Msg("storage is full, rejecting write")
+	if m != nil {
+		m.RecordStorageFull()
+	}
+	if onFull != nil {
+		onFull()
+	}
+	return fmt.Errorf("%w: %v", domain.ErrStorageFull, err)
+}
+
+// discardBlob deletes a stored blob that was rejected before any object
+// referenced it, see discardBlob.
+func (s *ObjectService) discardBlob(ctx context.Context, contentHash string) {
+	discardBlob(ctx, s.storage, s.blobRepo, s.logger, contentHash)
+}
+
+// discardBlob deletes a stored blob that was rejected before any object
+// referenced it. A blob already known to the blob repository holds content
+// shared with other objects and is kept.
+func discardBlob(ctx context.Context, backend storage.Backend, blobRepo repository.BlobRepository, logger zerolog.Logger, contentHash string) {
+	exists, err := blobRepo.Exists(ctx, contentHash)
+	if err != nil {
+		logger.Warn().Err(err).Str("content_hash", contentHash).Msg("failed to check rejected blob")
+		return
+	}
+	if exists {
+		return
+	}
+	if err := backend.Delete(ctx, contentHash); err != nil {
+		logger.Warn().Err(err).Str("content_hash", contentHash).Msg("failed to delete rejected blob")
+	}
+}
+
 // ListObjectVersions lists all versions of objects in a bucket.
 func (s *ObjectService) ListObjectVersions(ctx context.Context, input ListObjectVersionsInput) (*ListObjectVersionsOutput, error) {
 	// Get bucket
@@ -781,25 +1807,33 @@ func (s *ObjectService) ListObjectVersions(ctx context.Context, input ListObject
 	}
 
 	// Check ownership
-	if input.OwnerID > 0 && bucket.OwnerID != input.OwnerID {
+	if !bucketAccessAllowed(ctx, bucket, input.OwnerID) {
 		return nil, ErrBucketAccessDenied
 	}
 
 	// Set defaults
 	maxKeys := input.MaxKeys
-	if maxKeys <= 0 {
-		maxKeys = 1000
+	if maxKeys <= 0 || maxKeys > MaxListKeys {
+		maxKeys = MaxListKeys
 	}
-	if maxKeys > 1000 {
-		maxKeys = 1000
+
+	// The version ID marker only applies together with a key marker
+	var versionIDMarker string
+	if input.KeyMarker != "" && input.VersionIDMarker != "" {
+		id, err := domain.ParseVersionID(input.VersionIDMarker)
+		if err != nil {
+			return nil, err
+		}
+		versionIDMarker = id.String()
 	}
 
 	// List versions from repository
 	result, err := s.objectRepo.ListVersions(ctx, bucket.ID, repository.ObjectListOptions{
-		Prefix:     input.Prefix,
-		Delimiter:  input.Delimiter,
-		StartAfter: input.KeyMarker,
-		MaxKeys:    maxKeys,
+		Prefix:          bucket.NormalizeKey(input.Prefix),
+		Delimiter:       input.Delimiter,
+		StartAfter:      bucket.NormalizeKey(input.KeyMarker),
+		VersionIDMarker: versionIDMarker,
+		MaxKeys:         maxKeys,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
@@ -4,7 +4,13 @@ package service
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -15,7 +21,10 @@ import (
 
 	"github.com/prn-tf/alexander-storage/internal/domain"
 	"github.com/prn-tf/alexander-storage/internal/lock"
+	"github.com/prn-tf/alexander-storage/internal/pkg/crypto"
 	"github.com/prn-tf/alexander-storage/internal/repository"
+	"github.com/prn-tf/alexander-storage/internal/storage"
+	"github.com/prn-tf/alexander-storage/internal/storage/filesystem"
 )
 
 // =============================================================================
@@ -63,6 +72,14 @@ func (m *mockObjectRepository) List(ctx context.Context, bucketID int64, opts re
 	return args.Get(0).(*repository.ObjectListResult), args.Error(1)
 }
 
+func (m *mockObjectRepository) ListSnapshot(ctx context.Context) (*repository.ListSnapshot, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.ListSnapshot), args.Error(1)
+}
+
 func (m *mockObjectRepository) ListVersions(ctx context.Context, bucketID int64, opts repository.ObjectListOptions) (*repository.ObjectVersionListResult, error) {
 	args := m.Called(ctx, bucketID, opts)
 	if args.Get(0) == nil {
@@ -86,8 +103,42 @@ func (m *mockObjectRepository) Delete(ctx context.Context, id int64) error {
 	return args.Error(0)
 }
 
-func (m *mockObjectRepository) DeleteAllVersions(ctx context.Context, bucketID int64, key string) error {
+func (m *mockObjectRepository) DeleteAllVersions(ctx context.Context, bucketID int64, key string, limit int) (int64, error) {
+	args := m.Called(ctx, bucketID, key, limit)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockObjectRepository) ListKeyVersions(ctx context.Context, bucketID int64, key string) ([]*domain.Object, error) {
+	args := m.Called(ctx, bucketID, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Object), args.Error(1)
+}
+
+func (m *mockObjectRepository) GetLatestDeleted(ctx context.Context, bucketID int64, key string) (*domain.Object, error) {
 	args := m.Called(ctx, bucketID, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Object), args.Error(1)
+}
+
+func (m *mockObjectRepository) ListSoftDeleted(ctx context.Context, deletedBefore time.Time, limit int) ([]*domain.Object, error) {
+	args := m.Called(ctx, deletedBefore, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Object), args.Error(1)
+}
+
+func (m *mockObjectRepository) Undelete(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *mockObjectRepository) Purge(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
@@ -104,6 +155,48 @@ func (m *mockObjectRepository) GetContentHashForVersion(ctx context.Context, buc
 	return args.Get(0).(*string), args.Error(1)
 }
 
+func (m *mockObjectRepository) GetTags(ctx context.Context, objectID int64) (map[string]string, error) {
+	args := m.Called(ctx, objectID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]string), args.Error(1)
+}
+
+func (m *mockObjectRepository) PutTags(ctx context.Context, objectID int64, tags map[string]string) error {
+	args := m.Called(ctx, objectID, tags)
+	return args.Error(0)
+}
+
+func (m *mockObjectRepository) GetLock(ctx context.Context, objectID int64) (*domain.ObjectLock, error) {
+	args := m.Called(ctx, objectID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ObjectLock), args.Error(1)
+}
+
+func (m *mockObjectRepository) PutLock(ctx context.Context, objectID int64, lock *domain.ObjectLock) error {
+	args := m.Called(ctx, objectID, lock)
+	return args.Error(0)
+}
+
+func (m *mockObjectRepository) ListAfterID(ctx context.Context, bucketID int64, afterID int64, limit int) ([]*domain.Object, error) {
+	args := m.Called(ctx, bucketID, afterID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Object), args.Error(1)
+}
+
+func (m *mockObjectRepository) ListPastExpiry(ctx context.Context, now time.Time, limit int) ([]*domain.Object, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Object), args.Error(1)
+}
+
 func (m *mockObjectRepository) ListExpiredObjects(ctx context.Context, bucketID int64, prefix string, olderThan time.Time, limit int) ([]*domain.Object, error) {
 	args := m.Called(ctx, bucketID, prefix, olderThan, limit)
 	if args.Get(0) == nil {
@@ -154,6 +247,11 @@ func (m *mockBlobRepository2) Delete(ctx context.Context, contentHash string) er
 	return args.Error(0)
 }
 
+func (m *mockBlobRepository2) IsReferenced(ctx context.Context, contentHash string) (bool, error) {
+	args := m.Called(ctx, contentHash)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *mockBlobRepository2) ListOrphans(ctx context.Context, gracePeriod time.Duration, limit int) ([]*domain.Blob, error) {
 	args := m.Called(ctx, gracePeriod, limit)
 	if args.Get(0) == nil {
@@ -219,6 +317,14 @@ func (m *mockBlobRepository2) ListAll(ctx context.Context, limit int) ([]*domain
 	return args.Get(0).([]*domain.Blob), args.Error(1)
 }
 
+func (m *mockBlobRepository2) ListAfter(ctx context.Context, afterHash string, limit int) ([]*domain.Blob, error) {
+	args := m.Called(ctx, afterHash, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Blob), args.Error(1)
+}
+
 type mockStorageBackend2 struct {
 	mock.Mock
 }
@@ -325,6 +431,10 @@ func (m *mockBucketRepository) ExistsByName(ctx context.Context, name string) (b
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *mockBucketRepository) LockName(ctx context.Context, name string) (func(), error) {
+	return func() {}, nil
+}
+
 func (m *mockBucketRepository) IsEmpty(ctx context.Context, id int64) (bool, error) {
 	args := m.Called(ctx, id)
 	return args.Bool(0), args.Error(1)
@@ -345,6 +455,46 @@ func (m *mockBucketRepository) UpdateACL(ctx context.Context, id int64, acl doma
 	return args.Error(0)
 }
 
+func (m *mockBucketRepository) UpdateObjectOwnership(ctx context.Context, id int64, ownership domain.ObjectOwnership) error {
+	args := m.Called(ctx, id, ownership)
+	return args.Error(0)
+}
+
+func (m *mockBucketRepository) UpdateState(ctx context.Context, id int64, state domain.BucketState) error {
+	args := m.Called(ctx, id, state)
+	return args.Error(0)
+}
+
+func (m *mockBucketRepository) UpdateMaxVersionsPerKey(ctx context.Context, id int64, maxVersions int) error {
+	args := m.Called(ctx, id, maxVersions)
+	return args.Error(0)
+}
+
+func (m *mockBucketRepository) UpdateCaseInsensitiveKeys(ctx context.Context, id int64, enabled bool) error {
+	args := m.Called(ctx, id, enabled)
+	return args.Error(0)
+}
+
+func (m *mockBucketRepository) UpdateContentTypePolicy(ctx context.Context, id int64, policy *domain.ContentTypePolicy) error {
+	args := m.Called(ctx, id, policy)
+	return args.Error(0)
+}
+
+func (m *mockBucketRepository) UpdateRequireEncryption(ctx context.Context, id int64, required bool) error {
+	args := m.Called(ctx, id, required)
+	return args.Error(0)
+}
+
+func (m *mockBucketRepository) EnableObjectLock(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *mockBucketRepository) UpdateDefaultRetention(ctx context.Context, id int64, retention *domain.DefaultRetention) error {
+	args := m.Called(ctx, id, retention)
+	return args.Error(0)
+}
+
 // =============================================================================
 // Helper Functions
 // =============================================================================
@@ -461,6 +611,25 @@ func TestObjectService_PutObject(t *testing.T) {
 	}
 }
 
+func TestObjectService_PutObject_MaxKeyDepth(t *testing.T) {
+	svc, objRepo, blobRepo, bucketRepo, storageBackend := newTestObjectService()
+	svc.SetMaxKeyDepth(3)
+
+	_, err := svc.PutObject(context.Background(), PutObjectInput{
+		BucketName: "test-bucket",
+		Key:        "a/b/c/d/e.txt",
+		Body:       bytes.NewReader([]byte("hello")),
+		Size:       5,
+		OwnerID:    1,
+	})
+	require.ErrorIs(t, err, domain.ErrObjectKeyTooDeep)
+
+	// Rejected before the bucket is looked up or any content is stored
+	mock.AssertExpectationsForObjects(t, objRepo, blobRepo, bucketRepo, storageBackend)
+	bucketRepo.AssertNotCalled(t, "GetByName", mock.Anything, mock.Anything)
+	storageBackend.AssertNotCalled(t, "Store", mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestObjectService_GetObject(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -632,6 +801,73 @@ func TestObjectService_HeadObject(t *testing.T) {
 	}
 }
 
+func TestObjectService_HeadObject_Encryption(t *testing.T) {
+	svc, objRepo, blobRepo, bucketRepo, _ := newTestObjectService()
+
+	bucketRepo.On("GetByName", mock.Anything, "test-bucket").Return(&domain.Bucket{ID: 1, Name: "test-bucket", OwnerID: 1}, nil)
+
+	aesHash, chachaHash := "aes-hash", "chacha-hash"
+	objRepo.On("GetByKey", mock.Anything, int64(1), "legacy.bin").Return(&domain.Object{ID: 1, BucketID: 1, Key: "legacy.bin", ContentHash: &aesHash, IsLatest: true}, nil)
+	objRepo.On("GetByKey", mock.Anything, int64(1), "current.bin").Return(&domain.Object{ID: 2, BucketID: 1, Key: "current.bin", ContentHash: &chachaHash, IsLatest: true}, nil)
+	blobRepo.On("GetByHash", mock.Anything, aesHash).Return(&domain.Blob{ContentHash: aesHash, IsEncrypted: true, EncryptionScheme: domain.EncryptionSchemeAESGCM}, nil)
+	blobRepo.On("GetByHash", mock.Anything, chachaHash).Return(&domain.Blob{ContentHash: chachaHash, IsEncrypted: true, EncryptionScheme: domain.EncryptionSchemeChaCha}, nil)
+
+	for key, want := range map[string]domain.EncryptionScheme{
+		"legacy.bin":  domain.EncryptionSchemeAESGCM,
+		"current.bin": domain.EncryptionSchemeChaCha,
+	} {
+		output, err := svc.HeadObject(context.Background(), HeadObjectInput{BucketName: "test-bucket", Key: key, IncludeEncryption: true})
+		require.NoError(t, err)
+		require.NotNil(t, output.Encryption)
+		require.True(t, output.Encryption.Encrypted)
+		require.Equal(t, want, output.Encryption.Scheme)
+	}
+
+	// Regular HEAD requests do not look up the blob
+	output, err := svc.HeadObject(context.Background(), HeadObjectInput{BucketName: "test-bucket", Key: "legacy.bin", OwnerID: 1})
+	require.NoError(t, err)
+	require.Nil(t, output.Encryption)
+	blobRepo.AssertNumberOfCalls(t, "GetByHash", 2)
+}
+
+func TestObjectService_GetObjectRangeDecryptsRecordedScheme(t *testing.T) {
+	ctx := context.Background()
+	masterKey := bytes.Repeat([]byte{0x42}, 32)
+	dir := t.TempDir()
+	backend, err := filesystem.NewStreamingEncryptedStorage(filesystem.StreamingEncryptedConfig{
+		DataDir:   filepath.Join(dir, "data"),
+		TempDir:   filepath.Join(dir, "tmp"),
+		MasterKey: masterKey,
+	}, zerolog.Nop())
+	require.NoError(t, err)
+
+	// A blob written by the AES-GCM backend before the ChaCha migration
+	plaintext := []byte("written before the ChaCha migration")
+	aesHash := crypto.SHA256Hex(plaintext)
+	sse, err := crypto.NewSSEEncryptor(masterKey)
+	require.NoError(t, err)
+	ciphertext, err := sse.EncryptBlob(plaintext, aesHash)
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Dir(backend.GetPath(aesHash)), 0755))
+	require.NoError(t, os.WriteFile(backend.GetPath(aesHash), ciphertext, 0644))
+
+	objRepo, blobRepo, bucketRepo := new(mockObjectRepository), new(mockBlobRepository2), new(mockBucketRepository)
+	svc := NewObjectService(objRepo, blobRepo, bucketRepo, backend, lock.NewNoOpLocker(), zerolog.Nop())
+	bucketRepo.On("GetByName", mock.Anything, "test-bucket").Return(&domain.Bucket{ID: 1, Name: "test-bucket", OwnerID: 1}, nil)
+	objRepo.On("GetByKey", mock.Anything, int64(1), "legacy.bin").Return(&domain.Object{
+		ID: 1, BucketID: 1, Key: "legacy.bin", ContentHash: &aesHash, Size: int64(len(plaintext)), IsLatest: true,
+	}, nil)
+	blobRepo.On("GetByHash", mock.Anything, aesHash).Return(&domain.Blob{ContentHash: aesHash, IsEncrypted: true, EncryptionScheme: domain.EncryptionSchemeAESGCM}, nil)
+
+	output, err := svc.GetObject(ctx, GetObjectInput{BucketName: "test-bucket", Key: "legacy.bin", OwnerID: 1, Range: &ByteRange{Start: 8, End: 13}})
+	require.NoError(t, err)
+	defer output.Body.Close()
+	data, err := io.ReadAll(output.Body)
+	require.NoError(t, err)
+	require.Equal(t, plaintext[8:14], data)
+	require.Equal(t, fmt.Sprintf("bytes 8-13/%d", len(plaintext)), output.ContentRange)
+}
+
 func TestObjectService_DeleteObject(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -664,9 +900,6 @@ func TestObjectService_DeleteObject(t *testing.T) {
 				}
 				objRepo.On("GetByKey", mock.Anything, int64(1), "test-key.txt").Return(object, nil)
 
-				// Decrement blob ref count
-				blobRepo.On("DecrementRef", mock.Anything, "abc123hash").Return(int32(0), nil)
-
 				// Delete object record
 				objRepo.On("Delete", mock.Anything, int64(1)).Return(nil)
 			},
@@ -909,10 +1142,7 @@ func TestObjectService_DeleteObject_Versioned(t *testing.T) {
 				}
 				objRepo.On("GetByKeyAndVersion", mock.Anything, int64(1), "test-key.txt", versionUUID).Return(obj, nil)
 
-				// Should decrement ref count
-				blobRepo.On("DecrementRef", mock.Anything, "abc123hash").Return(int32(0), nil)
-
-				// Should hard delete
+				// Should soft delete; the blob ref is released on purge
 				objRepo.On("Delete", mock.Anything, int64(1)).Return(nil)
 			},
 			wantErr:       nil,
@@ -1019,6 +1249,35 @@ func TestObjectService_GetObject_Versioned(t *testing.T) {
 			},
 			wantErr: domain.ErrObjectDeleted,
 		},
+		{
+			name: "get delete marker by version id",
+			input: GetObjectInput{
+				BucketName: "versioned-bucket",
+				Key:        "deleted-key.txt",
+				VersionID:  "550e8400-e29b-41d4-a716-446655440001",
+				OwnerID:    1,
+			},
+			setup: func(objRepo *mockObjectRepository, blobRepo *mockBlobRepository2, bucketRepo *mockBucketRepository, storageBackend *mockStorageBackend2) {
+				bucket := &domain.Bucket{
+					ID:         1,
+					Name:       "versioned-bucket",
+					OwnerID:    1,
+					Versioning: domain.VersioningEnabled,
+				}
+				bucketRepo.On("GetByName", mock.Anything, "versioned-bucket").Return(bucket, nil)
+
+				versionUUID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440001")
+				obj := &domain.Object{
+					ID:             2,
+					BucketID:       1,
+					Key:            "deleted-key.txt",
+					VersionID:      versionUUID,
+					IsDeleteMarker: true,
+				}
+				objRepo.On("GetByKeyAndVersion", mock.Anything, int64(1), "deleted-key.txt", versionUUID).Return(obj, nil)
+			},
+			wantErr: domain.ErrVersionIsDeleteMarker,
+		},
 	}
 
 	for _, tt := range tests {
@@ -1043,6 +1302,38 @@ func TestObjectService_GetObject_Versioned(t *testing.T) {
 	}
 }
 
+func TestObjectService_HeadObject_DeleteMarkerVersion(t *testing.T) {
+	svc, objRepo, blobRepo, bucketRepo, storageBackend := newTestObjectService()
+
+	bucket := &domain.Bucket{
+		ID:         1,
+		Name:       "versioned-bucket",
+		OwnerID:    1,
+		Versioning: domain.VersioningEnabled,
+	}
+	bucketRepo.On("GetByName", mock.Anything, "versioned-bucket").Return(bucket, nil)
+
+	versionUUID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440002")
+	obj := &domain.Object{
+		ID:             3,
+		BucketID:       1,
+		Key:            "deleted-key.txt",
+		VersionID:      versionUUID,
+		IsDeleteMarker: true,
+	}
+	objRepo.On("GetByKeyAndVersion", mock.Anything, int64(1), "deleted-key.txt", versionUUID).Return(obj, nil)
+
+	_, err := svc.HeadObject(context.Background(), HeadObjectInput{
+		BucketName: "versioned-bucket",
+		Key:        "deleted-key.txt",
+		VersionID:  versionUUID.String(),
+		OwnerID:    1,
+	})
+	require.ErrorIs(t, err, domain.ErrVersionIsDeleteMarker)
+
+	mock.AssertExpectationsForObjects(t, objRepo, blobRepo, bucketRepo, storageBackend)
+}
+
 func TestObjectService_ListObjectVersions(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -1146,3 +1437,359 @@ func TestObjectService_ListObjectVersions(t *testing.T) {
 		})
 	}
 }
+
+func TestObjectService_PutObject_NullVersion(t *testing.T) {
+	tests := []struct {
+		name          string
+		versioning    domain.VersioningStatus
+		setup         func(*mockObjectRepository, *mockBlobRepository2)
+		wantVersionID string
+	}{
+		{
+			name:       "suspended bucket replaces the null version",
+			versioning: domain.VersioningSuspended,
+			setup: func(objRepo *mockObjectRepository, blobRepo *mockBlobRepository2) {
+				oldHash := "oldhash"
+				existing := &domain.Object{ID: 7, BucketID: 1, Key: "doc.txt", VersionID: uuid.Nil, ContentHash: &oldHash}
+				objRepo.On("GetByKeyAndVersion", mock.Anything, int64(1), "doc.txt", uuid.Nil).Return(existing, nil)
+				objRepo.On("Delete", mock.Anything, int64(7)).Return(nil)
+				objRepo.On("Purge", mock.Anything, int64(7)).Return(nil)
+				blobRepo.On("DecrementRef", mock.Anything, "oldhash").Return(int32(0), nil)
+			},
+			wantVersionID: domain.NullVersionID,
+		},
+		{
+			name:       "unversioned bucket reports no version",
+			versioning: domain.VersioningDisabled,
+			setup: func(objRepo *mockObjectRepository, blobRepo *mockBlobRepository2) {
+				objRepo.On("GetByKey", mock.Anything, int64(1), "doc.txt").Return(nil, domain.ErrObjectNotFound)
+			},
+			wantVersionID: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, objRepo, blobRepo, bucketRepo, storageBackend := newTestObjectService()
+
+			bucket := &domain.Bucket{ID: 1, Name: "test-bucket", OwnerID: 1, Versioning: tt.versioning}
+			bucketRepo.On("GetByName", mock.Anything, "test-bucket").Return(bucket, nil)
+			storageBackend.On("Store", mock.Anything, mock.Anything, int64(5)).Return("newhash", nil)
+			storageBackend.On("GetPath", "newhash").Return("/data/ne/wh/newhash")
+			blobRepo.On("UpsertWithRefIncrement", mock.Anything, "newhash", int64(5), "/data/ne/wh/newhash").Return(true, nil)
+			objRepo.On("MarkNotLatest", mock.Anything, int64(1), "doc.txt").Return(nil)
+			objRepo.On("Create", mock.Anything, mock.MatchedBy(func(obj *domain.Object) bool {
+				return obj.VersionID == uuid.Nil
+			})).Return(nil)
+			tt.setup(objRepo, blobRepo)
+
+			output, err := svc.PutObject(context.Background(), PutObjectInput{
+				BucketName: "test-bucket",
+				Key:        "doc.txt",
+				Body:       bytes.NewReader([]byte("hello")),
+				Size:       5,
+				OwnerID:    1,
+			})
+
+			require.NoError(t, err)
+			require.Equal(t, tt.wantVersionID, output.VersionID)
+			mock.AssertExpectationsForObjects(t, objRepo, blobRepo, bucketRepo, storageBackend)
+		})
+	}
+}
+
+func TestObjectService_NullVersionID(t *testing.T) {
+	hash := "abc123hash"
+	nullVersion := &domain.Object{
+		ID:          3,
+		BucketID:    1,
+		Key:         "doc.txt",
+		VersionID:   uuid.Nil,
+		ContentHash: &hash,
+		Size:        11,
+		ETag:        "\"etag\"",
+	}
+	bucket := &domain.Bucket{ID: 1, Name: "test-bucket", OwnerID: 1, Versioning: domain.VersioningEnabled}
+
+	t.Run("get resolves the null version", func(t *testing.T) {
+		svc, objRepo, blobRepo, bucketRepo, storageBackend := newTestObjectService()
+		bucketRepo.On("GetByName", mock.Anything, "test-bucket").Return(bucket, nil)
+		objRepo.On("GetByKeyAndVersion", mock.Anything, int64(1), "doc.txt", uuid.Nil).Return(nullVersion, nil)
+		storageBackend.On("Retrieve", mock.Anything, hash).Return(io.NopCloser(bytes.NewReader([]byte("hello world"))), nil)
+
+		output, err := svc.GetObject(context.Background(), GetObjectInput{
+			BucketName: "test-bucket",
+			Key:        "doc.txt",
+			VersionID:  domain.NullVersionID,
+			OwnerID:    1,
+		})
+
+		require.NoError(t, err)
+		defer output.Body.Close()
+		require.Equal(t, domain.NullVersionID, output.VersionID)
+		mock.AssertExpectationsForObjects(t, objRepo, blobRepo, bucketRepo, storageBackend)
+	})
+
+	t.Run("head resolves the null version", func(t *testing.T) {
+		svc, objRepo, blobRepo, bucketRepo, storageBackend := newTestObjectService()
+		bucketRepo.On("GetByName", mock.Anything, "test-bucket").Return(bucket, nil)
+		objRepo.On("GetByKeyAndVersion", mock.Anything, int64(1), "doc.txt", uuid.Nil).Return(nullVersion, nil)
+
+		output, err := svc.HeadObject(context.Background(), HeadObjectInput{
+			BucketName: "test-bucket",
+			Key:        "doc.txt",
+			VersionID:  domain.NullVersionID,
+			OwnerID:    1,
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, domain.NullVersionID, output.VersionID)
+		mock.AssertExpectationsForObjects(t, objRepo, blobRepo, bucketRepo, storageBackend)
+	})
+
+	t.Run("delete removes the null version", func(t *testing.T) {
+		svc, objRepo, blobRepo, bucketRepo, storageBackend := newTestObjectService()
+		bucketRepo.On("GetByName", mock.Anything, "test-bucket").Return(bucket, nil)
+		objRepo.On("GetByKeyAndVersion", mock.Anything, int64(1), "doc.txt", uuid.Nil).Return(nullVersion, nil)
+		objRepo.On("Delete", mock.Anything, int64(3)).Return(nil)
+
+		output, err := svc.DeleteObject(context.Background(), DeleteObjectInput{
+			BucketName: "test-bucket",
+			Key:        "doc.txt",
+			VersionID:  domain.NullVersionID,
+			OwnerID:    1,
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, domain.NullVersionID, output.VersionID)
+		require.False(t, output.DeleteMarker)
+		mock.AssertExpectationsForObjects(t, objRepo, blobRepo, bucketRepo, storageBackend)
+	})
+
+	t.Run("copy reads the null source version", func(t *testing.T) {
+		svc, objRepo, blobRepo, bucketRepo, storageBackend := newTestObjectService()
+		bucketRepo.On("GetByName", mock.Anything, "test-bucket").Return(bucket, nil)
+		objRepo.On("GetByKeyAndVersion", mock.Anything, int64(1), "doc.txt", uuid.Nil).Return(nullVersion, nil)
+		blobRepo.On("IncrementRef", mock.Anything, hash).Return(nil)
+		objRepo.On("GetTags", mock.Anything, int64(3)).Return(map[string]string{}, nil)
+		objRepo.On("MarkNotLatest", mock.Anything, int64(1), "copy.txt").Return(nil)
+		objRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Object")).Return(nil)
+
+		output, err := svc.CopyObject(context.Background(), CopyObjectInput{
+			SourceBucket:    "test-bucket",
+			SourceKey:       "doc.txt",
+			SourceVersionID: domain.NullVersionID,
+			DestBucket:      "test-bucket",
+			DestKey:         "copy.txt",
+			OwnerID:         1,
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, domain.NullVersionID, output.SourceVersionID)
+		require.NotEqual(t, domain.NullVersionID, output.VersionID)
+		mock.AssertExpectationsForObjects(t, objRepo, blobRepo, bucketRepo, storageBackend)
+	})
+
+	t.Run("invalid version id", func(t *testing.T) {
+		svc, objRepo, blobRepo, bucketRepo, storageBackend := newTestObjectService()
+		bucketRepo.On("GetByName", mock.Anything, "test-bucket").Return(bucket, nil)
+
+		_, err := svc.GetObject(context.Background(), GetObjectInput{
+			BucketName: "test-bucket",
+			Key:        "doc.txt",
+			VersionID:  "not-a-version",
+			OwnerID:    1,
+		})
+
+		require.ErrorIs(t, err, domain.ErrInvalidVersionID)
+		mock.AssertExpectationsForObjects(t, objRepo, blobRepo, bucketRepo, storageBackend)
+	})
+}
+
+func TestObjectService_DeleteObject_SuspendedCreatesNullDeleteMarker(t *testing.T) {
+	svc, objRepo, blobRepo, bucketRepo, storageBackend := newTestObjectService()
+
+	bucket := &domain.Bucket{ID: 1, Name: "test-bucket", OwnerID: 1, Versioning: domain.VersioningSuspended}
+	bucketRepo.On("GetByName", mock.Anything, "test-bucket").Return(bucket, nil)
+	objRepo.On("GetByKeyAndVersion", mock.Anything, int64(1), "doc.txt", uuid.Nil).Return(nil, domain.ErrObjectNotFound)
+	objRepo.On("MarkNotLatest", mock.Anything, int64(1), "doc.txt").Return(nil)
+	objRepo.On("Create", mock.Anything, mock.MatchedBy(func(obj *domain.Object) bool {
+		return obj.IsDeleteMarker && obj.VersionID == uuid.Nil
+	})).Return(nil)
+
+	output, err := svc.DeleteObject(context.Background(), DeleteObjectInput{
+		BucketName: "test-bucket",
+		Key:        "doc.txt",
+		OwnerID:    1,
+	})
+
+	require.NoError(t, err)
+	require.True(t, output.DeleteMarker)
+	require.Equal(t, domain.NullVersionID, output.VersionID)
+	mock.AssertExpectationsForObjects(t, objRepo, blobRepo, bucketRepo, storageBackend)
+}
+
+func TestObjectService_CopyObject_Conditions(t *testing.T) {
+	lastModified := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	before := lastModified.Add(-time.Hour)
+	after := lastModified.Add(time.Hour)
+
+	tests := []struct {
+		name       string
+		conditions CopySourceConditions
+		wantErr    error
+	}{
+		{name: "no conditions"},
+		{name: "if-match matches", conditions: CopySourceConditions{IfMatch: `"abc123"`}},
+		{name: "if-match unquoted", conditions: CopySourceConditions{IfMatch: "abc123"}},
+		{name: "if-match wildcard", conditions: CopySourceConditions{IfMatch: "*"}},
+		{name: "if-match list", conditions: CopySourceConditions{IfMatch: `"other", "abc123"`}},
+		{name: "if-match differs", conditions: CopySourceConditions{IfMatch: `"other"`}, wantErr: domain.ErrPreconditionFailed},
+		{name: "if-none-match differs", conditions: CopySourceConditions{IfNoneMatch: `"other"`}},
+		{name: "if-none-match matches", conditions: CopySourceConditions{IfNoneMatch: `"abc123"`}, wantErr: domain.ErrPreconditionFailed},
+		{name: "if-none-match wildcard", conditions: CopySourceConditions{IfNoneMatch: "*"}, wantErr: domain.ErrPreconditionFailed},
+		{name: "if-modified-since earlier", conditions: CopySourceConditions{IfModifiedSince: &before}},
+		{name: "if-modified-since equal", conditions: CopySourceConditions{IfModifiedSince: &lastModified}, wantErr: domain.ErrPreconditionFailed},
+		{name: "if-modified-since later", conditions: CopySourceConditions{IfModifiedSince: &after}, wantErr: domain.ErrPreconditionFailed},
+		{name: "if-unmodified-since later", conditions: CopySourceConditions{IfUnmodifiedSince: &after}},
+		{name: "if-unmodified-since equal", conditions: CopySourceConditions{IfUnmodifiedSince: &lastModified}},
+		{name: "if-unmodified-since earlier", conditions: CopySourceConditions{IfUnmodifiedSince: &before}, wantErr: domain.ErrPreconditionFailed},
+		{
+			name:       "if-match true overrides if-unmodified-since false",
+			conditions: CopySourceConditions{IfMatch: `"abc123"`, IfUnmodifiedSince: &before},
+		},
+		{
+			name:       "if-match false with if-unmodified-since true",
+			conditions: CopySourceConditions{IfMatch: `"other"`, IfUnmodifiedSince: &after},
+			wantErr:    domain.ErrPreconditionFailed,
+		},
+		{
+			name:       "if-none-match false with if-modified-since true",
+			conditions: CopySourceConditions{IfNoneMatch: `"abc123"`, IfModifiedSince: &before},
+			wantErr:    domain.ErrPreconditionFailed,
+		},
+		{
+			name:       "if-none-match true overrides if-modified-since false",
+			conditions: CopySourceConditions{IfNoneMatch: `"other"`, IfModifiedSince: &after},
+		},
+		{
+			name:       "if-match and if-none-match both hold",
+			conditions: CopySourceConditions{IfMatch: `"abc123"`, IfNoneMatch: `"other"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, objRepo, blobRepo, bucketRepo, storageBackend := newTestObjectService()
+
+			bucket := &domain.Bucket{ID: 1, Name: "test-bucket", OwnerID: 1}
+			bucketRepo.On("GetByName", mock.Anything, "test-bucket").Return(bucket, nil)
+
+			contentHash := "abc123hash"
+			source := &domain.Object{
+				ID:          1,
+				BucketID:    1,
+				Key:         "source.txt",
+				Size:        11,
+				ContentType: "text/plain",
+				ETag:        `"abc123"`,
+				ContentHash: &contentHash,
+				IsLatest:    true,
+				CreatedAt:   lastModified.Add(300 * time.Millisecond),
+			}
+			objRepo.On("GetByKey", mock.Anything, int64(1), "source.txt").Return(source, nil)
+
+			if tt.wantErr == nil {
+				objRepo.On("GetTags", mock.Anything, source.ID).Return(map[string]string{}, nil)
+				blobRepo.On("IncrementRef", mock.Anything, contentHash).Return(nil)
+				objRepo.On("GetByKey", mock.Anything, int64(1), "dest.txt").Return(nil, domain.ErrObjectNotFound)
+				objRepo.On("MarkNotLatest", mock.Anything, int64(1), "dest.txt").Return(nil)
+				objRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.Object")).Return(nil)
+			}
+
+			_, err := svc.CopyObject(context.Background(), CopyObjectInput{
+				SourceBucket: "test-bucket",
+				SourceKey:    "source.txt",
+				DestBucket:   "test-bucket",
+				DestKey:      "dest.txt",
+				Conditions:   tt.conditions,
+				OwnerID:      1,
+			})
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				blobRepo.AssertNotCalled(t, "IncrementRef", mock.Anything, mock.Anything)
+			} else {
+				require.NoError(t, err)
+			}
+
+			mock.AssertExpectationsForObjects(t, objRepo, blobRepo, bucketRepo, storageBackend)
+		})
+	}
+}
+
+// hashingStorage addresses content by its SHA-256 without keeping it.
+type hashingStorage struct {
+	storage.Backend
+}
+
+func (s *hashingStorage) Store(ctx context.Context, reader io.Reader, size int64) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, reader); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (s *hashingStorage) GetPath(contentHash string) string {
+	return "/data/" + contentHash
+}
+
+func TestObjectService_PutObject_MaxVersionsPerKey(t *testing.T) {
+	ctx := context.Background()
+
+	bucketRepo := NewMockBucketRepository()
+	require.NoError(t, bucketRepo.Create(ctx, &domain.Bucket{
+		OwnerID:           1,
+		Name:              "test-bucket",
+		Versioning:        domain.VersioningEnabled,
+		State:             domain.BucketStateActive,
+		MaxVersionsPerKey: 5,
+	}))
+	objectRepo := &retentionObjectRepository{objects: map[int64]*domain.Object{}}
+	blobRepo := &retentionBlobRepository{refs: map[string]int32{}}
+	svc := NewObjectService(objectRepo, blobRepo, bucketRepo, &hashingStorage{}, lock.NewNoOpLocker(), zerolog.Nop())
+
+	var versionIDs []string
+	for i := 1; i <= 7; i++ {
+		body := fmt.Sprintf("version %d", i)
+		output, err := svc.PutObject(ctx, PutObjectInput{
+			BucketName: "test-bucket",
+			Key:        "doc.txt",
+			Body:       strings.NewReader(body),
+			Size:       int64(len(body)),
+		})
+		require.NoError(t, err)
+		versionIDs = append(versionIDs, output.VersionID)
+	}
+
+	// Only the newest five versions remain, and nothing is left for the
+	// garbage collector to purge later
+	versions, err := objectRepo.ListKeyVersions(ctx, 1, "doc.txt")
+	require.NoError(t, err)
+	require.Len(t, versions, 5)
+	for i, obj := range versions {
+		require.Equal(t, versionIDs[6-i], obj.VersionID.String())
+	}
+	require.Len(t, objectRepo.objects, 5)
+
+	// The removed versions released their blobs
+	for i := 1; i <= 7; i++ {
+		sum := sha256.Sum256([]byte(fmt.Sprintf("version %d", i)))
+		want := int32(1)
+		if i <= 2 {
+			want = 0
+		}
+		require.Equal(t, want, blobRepo.refs[hex.EncodeToString(sum[:])], "version %d", i)
+	}
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/pkg/crypto"
+	"github.com/prn-tf/alexander-storage/internal/storage"
+)
+
+// checkSSECustomerKey verifies the key supplied with a read against the MD5
+// the object was written with. SSE-C objects cannot be read without their
+// key, and a key sent for any other object is rejected like in S3.
+func checkSSECustomerKey(obj *domain.Object, key *crypto.SSECustomerKey) error {
+	if !obj.IsSSECustomerEncrypted() {
+		if key != nil {
+			return ErrSSECustomerKeyNotExpected
+		}
+		return nil
+	}
+
+	if key == nil {
+		return ErrSSECustomerKeyRequired
+	}
+	if !key.Matches(obj.SSECustomerKeyMD5) {
+		return ErrSSECustomerKeyMismatch
+	}
+	return nil
+}
+
+// ssecObjectID is the identifier SSE-C object keys are derived with, which
+// ties the ciphertext to the bucket and key it was written under.
+func ssecObjectID(bucketName, objectKey string) string {
+	return bucketName + "/" + objectKey
+}
+
+// encryptSSEC wraps body so it is encrypted with the customer key as it is
+// stored, and returns the size of the stored ciphertext.
+func encryptSSEC(key *crypto.SSECustomerKey, bucketName, objectKey string, body io.Reader, size int64) (io.ReadCloser, int64, error) {
+	encryptor, err := crypto.NewSSECEncryptor(key)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	reader, err := encryptor.EncryptReader(body, ssecObjectID(bucketName, objectKey))
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", ErrEncryptionFailed, err)
+	}
+
+	return reader, crypto.CalculateEncryptedSize(size), nil
+}
+
+// decryptSSEC wraps a stored SSE-C blob and returns length plaintext bytes
+// from offset start. Ranges are served by decrypting from the first chunk,
+// since the chunk boundaries of the ciphertext are not exposed.
+func decryptSSEC(key *crypto.SSECustomerKey, bucketName, objectKey string, blob io.ReadCloser, start, length int64) (io.ReadCloser, error) {
+	encryptor, err := crypto.NewSSECEncryptor(key)
+	if err != nil {
+		_ = blob.Close()
+		return nil, err
+	}
+
+	reader, err := encryptor.DecryptReader(blob, ssecObjectID(bucketName, objectKey))
+	if err != nil {
+		_ = blob.Close()
+		return nil, fmt.Errorf("%w: %v", ErrDecryptionFailed, err)
+	}
+
+	if start > 0 {
+		if _, err := io.CopyN(io.Discard, reader, start); err != nil {
+			_ = blob.Close()
+			return nil, fmt.Errorf("%w: %v", ErrDecryptionFailed, err)
+		}
+	}
+
+	return &ssecReadCloser{Reader: io.LimitReader(reader, length), blob: blob}, nil
+}
+
+// ssecReadCloser reads decrypted content and closes the underlying blob.
+type ssecReadCloser struct {
+	io.Reader
+	blob io.Closer
+}
+
+// Close closes the stored blob.
+func (r *ssecReadCloser) Close() error {
+	return r.blob.Close()
+}
+
+// rewriteSSECContent stores the content of a copy source again, decrypting
+// it with the source key and encrypting it with the destination key as
+// requested. The returned blob already carries the copy's reference.
+func (s *ObjectService) rewriteSSECContent(ctx context.Context, sourceBucketName string, sourceObj *domain.Object, input CopyObjectInput) (string, error) {
+	reader, err := s.retrieveBlob(ctx, *sourceObj.ContentHash)
+	if err != nil {
+		if errors.Is(err, storage.ErrBlobNotFound) {
+			return "", s.objectDataMissing(sourceBucketName, sourceObj)
+		}
+		return "", fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+	if sourceObj.IsSSECustomerEncrypted() {
+		reader, err = decryptSSEC(input.SourceSSECustomerKey, sourceBucketName, sourceObj.Key, reader, 0, sourceObj.Size)
+		if err != nil {
+			return "", err
+		}
+	}
+	defer reader.Close()
+
+	var body io.Reader = reader
+	size := sourceObj.Size
+	if input.SSECustomerKey != nil {
+		encrypted, encryptedSize, err := encryptSSEC(input.SSECustomerKey, input.DestBucket, input.DestKey, reader, size)
+		if err != nil {
+			return "", err
+		}
+		defer encrypted.Close()
+		body, size = encrypted, encryptedSize
+	}
+
+	contentHash, err := s.storage.Store(ctx, body, size)
+	if err != nil {
+		if storage.IsStorageFull(err) {
+			return "", s.storageFull(input.DestBucket, input.DestKey, err)
+		}
+		s.logger.Error().Err(err).Str("key", input.DestKey).Msg("failed to store copied content")
+		return "", fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+
+	if _, err := s.blobRepo.UpsertWithRefIncrement(ctx, contentHash, size, s.storage.GetPath(contentHash)); err != nil {
+		s.logger.Error().Err(err).Str("content_hash", contentHash).Msg("failed to upsert blob")
+		return "", fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+
+	return contentHash, nil
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"io"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/pkg/crypto"
+	"github.com/prn-tf/alexander-storage/internal/repository/sqlite"
+)
+
+func newTestCustomerKey(t *testing.T, fill byte) *crypto.SSECustomerKey {
+	t.Helper()
+	key := bytes.Repeat([]byte{fill}, crypto.SSEKeySize)
+	sum := md5.Sum(key)
+	customerKey, err := crypto.ParseSSECustomerKey(crypto.SSECAlgorithmAES256,
+		base64.StdEncoding.EncodeToString(key), base64.StdEncoding.EncodeToString(sum[:]))
+	require.NoError(t, err)
+	return customerKey
+}
+
+// setupSSECBucket creates a bucket named "secrets" and returns the instance and owner ID.
+func setupSSECBucket(t *testing.T) (*multipartInstance, int64) {
+	t.Helper()
+	ctx := context.Background()
+
+	inst := startMultipartInstance(t, t.TempDir())
+	t.Cleanup(func() { inst.db.Close() })
+
+	user := domain.NewUser("tester", "tester@example.com", "hash")
+	require.NoError(t, sqlite.NewUserRepository(inst.db).Create(ctx, user))
+
+	buckets := NewBucketService(sqlite.NewBucketRepository(inst.db), zerolog.Nop())
+	_, err := buckets.CreateBucket(ctx, CreateBucketInput{Name: "secrets", OwnerID: user.ID})
+	require.NoError(t, err)
+
+	return inst, user.ID
+}
+
+func readSSECObject(t *testing.T, svc *ObjectService, input GetObjectInput) []byte {
+	t.Helper()
+	out, err := svc.GetObject(context.Background(), input)
+	require.NoError(t, err)
+	defer out.Body.Close()
+
+	data, err := io.ReadAll(out.Body)
+	require.NoError(t, err)
+	assert.Equal(t, out.ContentLength, int64(len(data)))
+	return data
+}
+
+func TestSSEC_PutGetRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	inst, ownerID := setupSSECBucket(t)
+	svc := inst.objects
+	key := newTestCustomerKey(t, 0x01)
+
+	content := bytes.Repeat([]byte("confidential "), 2*crypto.SSEChunkSize/13+7)
+	put, err := svc.PutObject(ctx, PutObjectInput{
+		BucketName:     "secrets",
+		Key:            "report.txt",
+		Body:           bytes.NewReader(content),
+		Size:           int64(len(content)),
+		OwnerID:        ownerID,
+		SSECustomerKey: key,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, key.KeyMD5, put.SSECustomerKeyMD5)
+
+	// The stored blob is ciphertext and only the key MD5 is recorded
+	obj, err := sqlite.NewObjectRepository(inst.db).GetByKey(ctx, 1, "report.txt")
+	require.NoError(t, err)
+	assert.Equal(t, crypto.SSECAlgorithmAES256, obj.SSECustomerAlgorithm)
+	assert.Equal(t, key.KeyMD5, obj.SSECustomerKeyMD5)
+	assert.Equal(t, int64(len(content)), obj.Size)
+
+	stored, err := inst.storage.Retrieve(ctx, *obj.ContentHash)
+	require.NoError(t, err)
+	raw, err := io.ReadAll(stored)
+	stored.Close()
+	require.NoError(t, err)
+	assert.False(t, bytes.Contains(raw, []byte("confidential")))
+
+	got := readSSECObject(t, svc, GetObjectInput{BucketName: "secrets", Key: "report.txt", OwnerID: ownerID, SSECustomerKey: key})
+	assert.Equal(t, content, got)
+
+	// Ranges are served over the plaintext, including across chunk boundaries
+	start := int64(crypto.SSEChunkSize - 5)
+	got = readSSECObject(t, svc, GetObjectInput{
+		BucketName:     "secrets",
+		Key:            "report.txt",
+		OwnerID:        ownerID,
+		Range:          &ByteRange{Start: start, End: start + 99},
+		SSECustomerKey: key,
+	})
+	assert.Equal(t, content[start:start+100], got)
+
+	head, err := svc.HeadObject(ctx, HeadObjectInput{BucketName: "secrets", Key: "report.txt", OwnerID: ownerID, SSECustomerKey: key})
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(content)), head.ContentLength)
+	assert.Equal(t, key.KeyMD5, head.SSECustomerKeyMD5)
+}
+
+func TestSSEC_KeyChecks(t *testing.T) {
+	ctx := context.Background()
+	inst, ownerID := setupSSECBucket(t)
+	svc := inst.objects
+	key := newTestCustomerKey(t, 0x01)
+
+	for name, customerKey := range map[string]*crypto.SSECustomerKey{"sealed": key, "plain": nil} {
+		_, err := svc.PutObject(ctx, PutObjectInput{
+			BucketName:     "secrets",
+			Key:            name,
+			Body:           bytes.NewReader([]byte("payload")),
+			Size:           7,
+			OwnerID:        ownerID,
+			SSECustomerKey: customerKey,
+		})
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		name    string
+		key     string
+		ssecKey *crypto.SSECustomerKey
+		wantErr error
+	}{
+		{"missing key", "sealed", nil, ErrSSECustomerKeyRequired},
+		{"wrong key", "sealed", newTestCustomerKey(t, 0x02), ErrSSECustomerKeyMismatch},
+		{"key for plain object", "plain", key, ErrSSECustomerKeyNotExpected},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.GetObject(ctx, GetObjectInput{BucketName: "secrets", Key: tt.key, OwnerID: ownerID, SSECustomerKey: tt.ssecKey})
+			assert.ErrorIs(t, err, tt.wantErr)
+
+			_, err = svc.HeadObject(ctx, HeadObjectInput{BucketName: "secrets", Key: tt.key, OwnerID: ownerID, SSECustomerKey: tt.ssecKey})
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestSSEC_CopyObject(t *testing.T) {
+	ctx := context.Background()
+	inst, ownerID := setupSSECBucket(t)
+	svc := inst.objects
+	sourceKey := newTestCustomerKey(t, 0x01)
+	destKey := newTestCustomerKey(t, 0x02)
+
+	content := []byte("rotate me")
+	_, err := svc.PutObject(ctx, PutObjectInput{
+		BucketName:     "secrets",
+		Key:            "source",
+		Body:           bytes.NewReader(content),
+		Size:           int64(len(content)),
+		OwnerID:        ownerID,
+		SSECustomerKey: sourceKey,
+	})
+	require.NoError(t, err)
+
+	copyInput := func(dest string, source, target *crypto.SSECustomerKey) CopyObjectInput {
+		return CopyObjectInput{
+			SourceBucket:         "secrets",
+			SourceKey:            "source",
+			DestBucket:           "secrets",
+			DestKey:              dest,
+			OwnerID:              ownerID,
+			SourceSSECustomerKey: source,
+			SSECustomerKey:       target,
+		}
+	}
+
+	// The source key is required
+	_, err = svc.CopyObject(ctx, copyInput("rotated", nil, destKey))
+	assert.ErrorIs(t, err, ErrSSECustomerKeyRequired)
+
+	// Re-encrypt under a new key
+	out, err := svc.CopyObject(ctx, copyInput("rotated", sourceKey, destKey))
+	require.NoError(t, err)
+	assert.Equal(t, destKey.KeyMD5, out.SSECustomerKeyMD5)
+
+	got := readSSECObject(t, svc, GetObjectInput{BucketName: "secrets", Key: "rotated", OwnerID: ownerID, SSECustomerKey: destKey})
+	assert.Equal(t, content, got)
+	_, err = svc.GetObject(ctx, GetObjectInput{BucketName: "secrets", Key: "rotated", OwnerID: ownerID, SSECustomerKey: sourceKey})
+	assert.ErrorIs(t, err, ErrSSECustomerKeyMismatch)
+
+	// Decrypt into a plain copy
+	out, err = svc.CopyObject(ctx, copyInput("plain", sourceKey, nil))
+	require.NoError(t, err)
+	assert.Empty(t, out.SSECustomerKeyMD5)
+
+	got = readSSECObject(t, svc, GetObjectInput{BucketName: "secrets", Key: "plain", OwnerID: ownerID})
+	assert.Equal(t, content, got)
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+)
+
+// TaggingDirectiveCopy and TaggingDirectiveReplace are the values of
+// x-amz-tagging-directive on CopyObject.
+const (
+	TaggingDirectiveCopy    = "COPY"
+	TaggingDirectiveReplace = "REPLACE"
+)
+
+// GetObjectTaggingInput contains the data needed to read an object's tags.
+type GetObjectTaggingInput struct {
+	BucketName string
+	Key        string
+	VersionID  string // Optional
+	OwnerID    int64
+}
+
+// GetObjectTaggingOutput contains an object's tags.
+type GetObjectTaggingOutput struct {
+	VersionID string
+	Tags      map[string]string
+}
+
+// PutObjectTaggingInput contains the data needed to replace an object's tags.
+type PutObjectTaggingInput struct {
+	BucketName string
+	Key        string
+	VersionID  string // Optional
+	OwnerID    int64
+	Tags       map[string]string
+}
+
+// DeleteObjectTaggingInput contains the data needed to remove an object's tags.
+type DeleteObjectTaggingInput struct {
+	BucketName string
+	Key        string
+	VersionID  string // Optional
+	OwnerID    int64
+}
+
+// ObjectTaggingOutput contains the version whose tags were changed.
+type ObjectTaggingOutput struct {
+	VersionID string
+}
+
+// GetObjectTagging returns the tag set of an object version.
+func (s *ObjectService) GetObjectTagging(ctx context.Context, input GetObjectTaggingInput) (*GetObjectTaggingOutput, error) {
+	bucket, obj, err := s.getTaggableObject(ctx, input.BucketName, input.Key, input.VersionID, input.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := s.objectRepo.GetTags(ctx, obj.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+
+	return &GetObjectTaggingOutput{
+		VersionID: responseVersionID(bucket, obj),
+		Tags:      tags,
+	}, nil
+}
+
+// PutObjectTagging replaces the tag set of an object version.
+func (s *ObjectService) PutObjectTagging(ctx context.Context, input PutObjectTaggingInput) (*ObjectTaggingOutput, error) {
+	if err := domain.ValidateTags(input.Tags); err != nil {
+		return nil, err
+	}
+
+	bucket, obj, err := s.getTaggableObject(ctx, input.BucketName, input.Key, input.VersionID, input.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.objectRepo.PutTags(ctx, obj.ID, input.Tags); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+
+	return &ObjectTaggingOutput{VersionID: responseVersionID(bucket, obj)}, nil
+}
+
+// DeleteObjectTagging removes all tags from an object version.
+func (s *ObjectService) DeleteObjectTagging(ctx context.Context, input DeleteObjectTaggingInput) (*ObjectTaggingOutput, error) {
+	bucket, obj, err := s.getTaggableObject(ctx, input.BucketName, input.Key, input.VersionID, input.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.objectRepo.PutTags(ctx, obj.ID, nil); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+
+	return &ObjectTaggingOutput{VersionID: responseVersionID(bucket, obj)}, nil
+}
+
+// getTaggableObject resolves the bucket and object version a tagging or
+// object lock request applies to. Delete markers carry no tags or locks.
+func (s *ObjectService) getTaggableObject(ctx context.Context, bucketName, key, versionID string, ownerID int64) (*domain.Bucket, *domain.Object, error) {
+	bucket, err := s.bucketRepo.GetByName(ctx, bucketName)
+	if err != nil {
+		if errors.Is(err, domain.ErrBucketNotFound) {
+			return nil, nil, domain.ErrBucketNotFound
+		}
+		return nil, nil, fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+
+	// Check ownership
+	if !bucketAccessAllowed(ctx, bucket, ownerID) {
+		return nil, nil, ErrBucketAccessDenied
+	}
+
+	obj, err := getObjectVersion(ctx, s.objectRepo, bucket, key, versionID)
+	if err != nil {
+		if errors.Is(err, domain.ErrObjectNotFound) || errors.Is(err, domain.ErrInvalidVersionID) {
+			return nil, nil, err
+		}
+		return nil, nil, fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+	if obj.IsDeleteMarker {
+		if versionID != "" {
+			return nil, nil, domain.ErrVersionIsDeleteMarker
+		}
+		return nil, nil, domain.ErrObjectDeleted
+	}
+
+	return bucket, obj, nil
+}
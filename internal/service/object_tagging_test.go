@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/repository/sqlite"
+)
+
+// setupTaggingBucket creates a bucket named "tagged" and returns the object service and owner ID.
+func setupTaggingBucket(t *testing.T) (*ObjectService, int64) {
+	t.Helper()
+	ctx := context.Background()
+
+	inst := startMultipartInstance(t, t.TempDir())
+	t.Cleanup(func() { inst.db.Close() })
+
+	user := domain.NewUser("tester", "tester@example.com", "hash")
+	require.NoError(t, sqlite.NewUserRepository(inst.db).Create(ctx, user))
+
+	buckets := NewBucketService(sqlite.NewBucketRepository(inst.db), zerolog.Nop())
+	_, err := buckets.CreateBucket(ctx, CreateBucketInput{Name: "tagged", OwnerID: user.ID})
+	require.NoError(t, err)
+
+	return inst.objects, user.ID
+}
+
+func putTaggedObject(t *testing.T, svc *ObjectService, key string, ownerID int64, tags map[string]string) {
+	t.Helper()
+	body := "payload"
+	_, err := svc.PutObject(context.Background(), PutObjectInput{
+		BucketName: "tagged",
+		Key:        key,
+		Body:       strings.NewReader(body),
+		Size:       int64(len(body)),
+		OwnerID:    ownerID,
+		Tags:       tags,
+	})
+	require.NoError(t, err)
+}
+
+func getTags(t *testing.T, svc *ObjectService, key string, ownerID int64) map[string]string {
+	t.Helper()
+	out, err := svc.GetObjectTagging(context.Background(), GetObjectTaggingInput{BucketName: "tagged", Key: key, OwnerID: ownerID})
+	require.NoError(t, err)
+	return out.Tags
+}
+
+func TestObjectTagging_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	svc, ownerID := setupTaggingBucket(t)
+
+	putTaggedObject(t, svc, "doc.txt", ownerID, map[string]string{"team": "storage"})
+	assert.Equal(t, map[string]string{"team": "storage"}, getTags(t, svc, "doc.txt", ownerID))
+
+	_, err := svc.PutObjectTagging(ctx, PutObjectTaggingInput{
+		BucketName: "tagged",
+		Key:        "doc.txt",
+		OwnerID:    ownerID,
+		Tags:       map[string]string{"env": "prod", "tier": ""},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"env": "prod", "tier": ""}, getTags(t, svc, "doc.txt", ownerID))
+
+	_, err = svc.DeleteObjectTagging(ctx, DeleteObjectTaggingInput{BucketName: "tagged", Key: "doc.txt", OwnerID: ownerID})
+	require.NoError(t, err)
+	assert.Empty(t, getTags(t, svc, "doc.txt", ownerID))
+}
+
+func TestObjectTagging_Limits(t *testing.T) {
+	ctx := context.Background()
+	svc, ownerID := setupTaggingBucket(t)
+	putTaggedObject(t, svc, "doc.txt", ownerID, nil)
+
+	tooMany := make(map[string]string)
+	for i := 0; i <= domain.MaxObjectTags; i++ {
+		tooMany[fmt.Sprintf("k%d", i)] = "v"
+	}
+
+	tests := []struct {
+		name string
+		tags map[string]string
+		err  error
+	}{
+		{name: "too many tags", tags: tooMany, err: domain.ErrTooManyTags},
+		{name: "empty key", tags: map[string]string{"": "v"}, err: domain.ErrInvalidTag},
+		{name: "long key", tags: map[string]string{strings.Repeat("k", domain.MaxTagKeyLength+1): "v"}, err: domain.ErrInvalidTag},
+		{name: "long value", tags: map[string]string{"k": strings.Repeat("v", domain.MaxTagValueLength+1)}, err: domain.ErrInvalidTag},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.PutObjectTagging(ctx, PutObjectTaggingInput{BucketName: "tagged", Key: "doc.txt", OwnerID: ownerID, Tags: tt.tags})
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+
+	// Multi-byte characters count once towards the limits
+	_, err := svc.PutObjectTagging(ctx, PutObjectTaggingInput{
+		BucketName: "tagged",
+		Key:        "doc.txt",
+		OwnerID:    ownerID,
+		Tags:       map[string]string{strings.Repeat("é", domain.MaxTagKeyLength): "v"},
+	})
+	require.NoError(t, err)
+}
+
+func TestObjectTagging_MissingObject(t *testing.T) {
+	svc, ownerID := setupTaggingBucket(t)
+
+	_, err := svc.GetObjectTagging(context.Background(), GetObjectTaggingInput{BucketName: "tagged", Key: "missing.txt", OwnerID: ownerID})
+	assert.ErrorIs(t, err, domain.ErrObjectNotFound)
+}
+
+func TestObjectTagging_CopyDirective(t *testing.T) {
+	ctx := context.Background()
+	svc, ownerID := setupTaggingBucket(t)
+	putTaggedObject(t, svc, "src.txt", ownerID, map[string]string{"team": "storage"})
+
+	copyWith := func(destKey, directive string, tags map[string]string) error {
+		_, err := svc.CopyObject(ctx, CopyObjectInput{
+			SourceBucket:     "tagged",
+			SourceKey:        "src.txt",
+			DestBucket:       "tagged",
+			DestKey:          destKey,
+			TaggingDirective: directive,
+			Tags:             tags,
+			OwnerID:          ownerID,
+		})
+		return err
+	}
+
+	require.NoError(t, copyWith("default.txt", "", nil))
+	assert.Equal(t, map[string]string{"team": "storage"}, getTags(t, svc, "default.txt", ownerID))
+
+	require.NoError(t, copyWith("copy.txt", TaggingDirectiveCopy, nil))
+	assert.Equal(t, map[string]string{"team": "storage"}, getTags(t, svc, "copy.txt", ownerID))
+
+	require.NoError(t, copyWith("replace.txt", TaggingDirectiveReplace, map[string]string{"env": "dev"}))
+	assert.Equal(t, map[string]string{"env": "dev"}, getTags(t, svc, "replace.txt", ownerID))
+
+	// REPLACE without tags drops the source's tags
+	require.NoError(t, copyWith("untagged.txt", TaggingDirectiveReplace, nil))
+	assert.Empty(t, getTags(t, svc, "untagged.txt", ownerID))
+
+	err := copyWith("bad.txt", TaggingDirectiveReplace, map[string]string{"k": strings.Repeat("v", domain.MaxTagValueLength+1)})
+	assert.ErrorIs(t, err, domain.ErrInvalidTag)
+}
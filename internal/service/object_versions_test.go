@@ -0,0 +1,188 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/repository/sqlite"
+)
+
+func TestObjectService_DeleteAllVersionsInBatches(t *testing.T) {
+	ctx := context.Background()
+	inst := startMultipartInstance(t, t.TempDir())
+	defer inst.db.Close()
+
+	user := domain.NewUser("tester", "tester@example.com", "hash")
+	require.NoError(t, sqlite.NewUserRepository(inst.db).Create(ctx, user))
+	bucket := domain.NewBucket(user.ID, "history")
+	bucket.Versioning = domain.VersioningEnabled
+	bucketRepo := sqlite.NewBucketRepository(inst.db)
+	require.NoError(t, bucketRepo.Create(ctx, bucket))
+
+	objectRepo := sqlite.NewObjectRepository(inst.db)
+	blobRepo := sqlite.NewBlobRepository(inst.db)
+
+	content := []byte("same payload")
+	for _, key := range []string{"log.txt", "other.txt"} {
+		_, err := inst.objects.PutObject(ctx, PutObjectInput{
+			BucketName: "history",
+			Key:        key,
+			Body:       bytes.NewReader(content),
+			Size:       int64(len(content)),
+			OwnerID:    user.ID,
+		})
+		require.NoError(t, err)
+	}
+	first, err := objectRepo.GetByKey(ctx, bucket.ID, "log.txt")
+	require.NoError(t, err)
+	contentHash := *first.ContentHash
+
+	// Thousands of versions sharing one blob, interleaved with delete markers
+	const versions = 2000
+	const batchSize = 300
+	for i := 1; i < versions; i++ {
+		obj := domain.NewObject(bucket.ID, "log.txt", contentHash, "text/plain", first.ETag, first.Size)
+		if i%2 == 1 {
+			obj = domain.NewDeleteMarker(bucket.ID, "log.txt")
+		} else {
+			require.NoError(t, blobRepo.IncrementRef(ctx, contentHash))
+		}
+		require.NoError(t, objectRepo.MarkNotLatest(ctx, bucket.ID, "log.txt"))
+		require.NoError(t, objectRepo.Create(ctx, obj))
+	}
+
+	refs, err := blobRepo.GetRefCount(ctx, contentHash)
+	require.NoError(t, err)
+	require.EqualValues(t, versions/2+1, refs)
+
+	inst.objects.SetDeleteBatchSize(batchSize)
+	var progress []int64
+	out, err := inst.objects.DeleteAllVersions(ctx, DeleteAllVersionsInput{
+		BucketName: "history",
+		Key:        "log.txt",
+		OwnerID:    user.ID,
+		Progress:   func(deleted int64) { progress = append(progress, deleted) },
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, versions, out.Deleted)
+	require.Equal(t, (versions+batchSize-1)/batchSize, out.Batches)
+	require.Len(t, progress, out.Batches)
+	for i, deleted := range progress[:len(progress)-1] {
+		require.EqualValues(t, (i+1)*batchSize, deleted)
+	}
+
+	remaining, err := objectRepo.ListKeyVersions(ctx, bucket.ID, "log.txt")
+	require.NoError(t, err)
+	require.Empty(t, remaining)
+	_, err = objectRepo.GetByKey(ctx, bucket.ID, "other.txt")
+	require.NoError(t, err)
+
+	// Soft-deleted versions keep their blob references until purged,
+	// which releases one reference per version
+	refs, err = blobRepo.GetRefCount(ctx, contentHash)
+	require.NoError(t, err)
+	require.EqualValues(t, versions/2+1, refs)
+
+	// A negative retention puts the cutoff in the future so every deleted version is purged
+	retention := NewRetentionService(objectRepo, blobRepo, bucketRepo, zerolog.Nop(), RetentionConfig{Retention: -time.Minute, BatchSize: versions})
+	purged, err := retention.PurgeExpired(ctx)
+	require.NoError(t, err)
+	require.Equal(t, versions, purged)
+
+	// Only other.txt still references the blob
+	refs, err = blobRepo.GetRefCount(ctx, contentHash)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, refs)
+}
+
+func TestObjectService_ListObjectVersionsPaginates(t *testing.T) {
+	ctx := context.Background()
+	inst := startMultipartInstance(t, t.TempDir())
+	defer inst.db.Close()
+
+	user := domain.NewUser("tester", "tester@example.com", "hash")
+	require.NoError(t, sqlite.NewUserRepository(inst.db).Create(ctx, user))
+	bucket := domain.NewBucket(user.ID, "history")
+	bucket.Versioning = domain.VersioningEnabled
+	require.NoError(t, sqlite.NewBucketRepository(inst.db).Create(ctx, bucket))
+	objectRepo := sqlite.NewObjectRepository(inst.db)
+
+	// Versions created within the same second, interleaved with delete markers
+	const perKey = 500
+	keys := []string{"a.txt", "b.txt", "c.txt"}
+	created := make(map[string]bool)
+	for _, key := range keys {
+		for i := 0; i < perKey; i++ {
+			obj := domain.NewObject(bucket.ID, key, "hash-"+key, "text/plain", "etag", 1)
+			if i%3 == 2 {
+				obj = domain.NewDeleteMarker(bucket.ID, key)
+			}
+			require.NoError(t, objectRepo.MarkNotLatest(ctx, bucket.ID, key))
+			require.NoError(t, objectRepo.Create(ctx, obj))
+			created[key+"/"+domain.FormatVersionID(obj.VersionID)] = true
+		}
+	}
+
+	seen := make(map[string]bool)
+	latest := make(map[string]int)
+	pages := 0
+	keyMarker, versionIDMarker := "", ""
+	for {
+		out, err := inst.objects.ListObjectVersions(ctx, ListObjectVersionsInput{
+			BucketName:      "history",
+			KeyMarker:       keyMarker,
+			VersionIDMarker: versionIDMarker,
+			MaxKeys:         400,
+			OwnerID:         user.ID,
+		})
+		require.NoError(t, err)
+		pages++
+
+		for _, v := range out.Versions {
+			id := v.Key + "/" + v.VersionID
+			require.False(t, seen[id], "version %s listed twice", id)
+			seen[id] = true
+			if v.IsLatest {
+				latest[v.Key]++
+			}
+		}
+		for _, dm := range out.DeleteMarkers {
+			id := dm.Key + "/" + dm.VersionID
+			require.False(t, seen[id], "delete marker %s listed twice", id)
+			seen[id] = true
+			if dm.IsLatest {
+				latest[dm.Key]++
+			}
+		}
+
+		if !out.IsTruncated {
+			require.Empty(t, out.NextKeyMarker)
+			require.Empty(t, out.NextVersionIDMarker)
+			break
+		}
+		require.Equal(t, 400, len(out.Versions)+len(out.DeleteMarkers))
+		require.NotEmpty(t, out.NextKeyMarker)
+		require.NotEmpty(t, out.NextVersionIDMarker)
+		keyMarker, versionIDMarker = out.NextKeyMarker, out.NextVersionIDMarker
+	}
+
+	require.Equal(t, 4, pages)
+	require.Equal(t, created, seen)
+	for _, key := range keys {
+		require.Equal(t, 1, latest[key], "key %s", key)
+	}
+
+	_, err := inst.objects.ListObjectVersions(ctx, ListObjectVersionsInput{
+		BucketName:      "history",
+		KeyMarker:       "a.txt",
+		VersionIDMarker: "not-a-version",
+		OwnerID:         user.ID,
+	})
+	require.ErrorIs(t, err, domain.ErrInvalidVersionID)
+}
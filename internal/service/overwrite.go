@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/repository"
+)
+
+// OverwriteMode decides what happens to the object a write replaces in a
+// bucket without versioning, or to the null version in a suspended bucket.
+type OverwriteMode string
+
+const (
+	// OverwriteReplace removes the replaced object for good and releases its
+	// blob reference as soon as the new object is stored, so repeated
+	// overwrites leave one row per key.
+	OverwriteReplace OverwriteMode = "replace"
+
+	// OverwriteSoftDelete keeps the replaced object as a soft-deleted row an
+	// operator can inspect until the retention period purges it.
+	OverwriteSoftDelete OverwriteMode = "soft_delete"
+)
+
+// releaseReplacedObject purges the object a write replaced and drops its blob
+// reference under OverwriteReplace. It runs after the new object is created,
+// so a failed write never loses the old one; failures are logged and leave
+// the soft-deleted row to the retention purge.
+func releaseReplacedObject(ctx context.Context, objectRepo repository.ObjectRepository, blobRepo repository.BlobRepository, logger zerolog.Logger, mode OverwriteMode, replaced *domain.Object) {
+	if replaced == nil || mode == OverwriteSoftDelete {
+		return
+	}
+
+	if err := objectRepo.Purge(ctx, replaced.ID); err != nil {
+		logger.Error().Err(err).Int64("object_id", replaced.ID).Msg("failed to purge replaced object")
+		return
+	}
+	if replaced.ContentHash != nil {
+		if _, err := blobRepo.DecrementRef(ctx, *replaced.ContentHash); err != nil {
+			logger.Error().Err(err).Str("content_hash", *replaced.ContentHash).Msg("failed to decrement ref count")
+		}
+	}
+}
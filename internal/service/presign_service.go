@@ -344,7 +344,8 @@ func getCanonicalQueryString(query url.Values) string {
 		values := query[key]
 		sort.Strings(values)
 		for _, value := range values {
-			pairs = append(pairs, url.QueryEscape(key)+"="+url.QueryEscape(value))
+			// SigV4 encodes spaces as %20, not +
+			pairs = append(pairs, strings.ReplaceAll(url.QueryEscape(key), "+", "%20")+"="+strings.ReplaceAll(url.QueryEscape(value), "+", "%20"))
 		}
 	}
 
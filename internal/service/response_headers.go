@@ -0,0 +1,39 @@
+package service
+
+import (
+	"cmp"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+)
+
+// ResponseHeaders are the standard headers of a GET or HEAD object response.
+// As request input they are the response-* query overrides, where empty
+// fields keep the stored value.
+type ResponseHeaders struct {
+	ContentType        string
+	ContentDisposition string
+	ContentLanguage    string
+	CacheControl       string
+	ContentEncoding    string
+	Expires            string
+}
+
+// IsZero reports whether no header is set.
+func (h ResponseHeaders) IsZero() bool {
+	return h == ResponseHeaders{}
+}
+
+// serveHeaders returns the headers an object is served with: the overrides,
+// else the stored values. The bucket's content-type policy applies last, so
+// an override can not get around it.
+func serveHeaders(bucket *domain.Bucket, obj *domain.Object, overrides ResponseHeaders) ResponseHeaders {
+	contentType, disposition := bucket.ContentTypePolicy.ServeHeaders(cmp.Or(overrides.ContentType, obj.ContentType))
+	return ResponseHeaders{
+		ContentType:        contentType,
+		ContentDisposition: cmp.Or(disposition, overrides.ContentDisposition, obj.ContentDisposition),
+		ContentLanguage:    overrides.ContentLanguage,
+		CacheControl:       cmp.Or(overrides.CacheControl, obj.CacheControl),
+		ContentEncoding:    cmp.Or(overrides.ContentEncoding, obj.ContentEncoding),
+		Expires:            cmp.Or(overrides.Expires, obj.Expires),
+	}
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+)
+
+func TestServeHeaders_PolicyWinsOverOverrides(t *testing.T) {
+	obj := &domain.Object{ContentType: "text/html", ContentDisposition: `inline; filename="page.html"`}
+	overrides := ResponseHeaders{ContentType: "image/svg+xml", ContentDisposition: "inline"}
+
+	safe := &domain.Bucket{ContentTypePolicy: &domain.ContentTypePolicy{Serve: domain.ServeSafe}}
+	headers := serveHeaders(safe, obj, overrides)
+	assert.Equal(t, "text/plain", headers.ContentType)
+	assert.Equal(t, "inline", headers.ContentDisposition)
+
+	attachment := &domain.Bucket{ContentTypePolicy: &domain.ContentTypePolicy{Serve: domain.ServeAttachment}}
+	headers = serveHeaders(attachment, obj, overrides)
+	assert.Equal(t, "image/svg+xml", headers.ContentType)
+	assert.Equal(t, "attachment", headers.ContentDisposition)
+
+	headers = serveHeaders(&domain.Bucket{}, obj, ResponseHeaders{})
+	assert.Equal(t, "text/html", headers.ContentType)
+	assert.Equal(t, `inline; filename="page.html"`, headers.ContentDisposition)
+}
+
+func TestMultipartService_CompleteKeepsStandardHeaders(t *testing.T) {
+	ctx := context.Background()
+	dir, ownerID := setupMultipartRestart(t)
+	inst := startMultipartInstance(t, dir)
+	defer inst.db.Close()
+
+	initiated, err := inst.multipart.InitiateMultipartUpload(ctx, InitiateMultipartUploadInput{
+		BucketName:         "uploads",
+		Key:                "video.bin",
+		OwnerID:            ownerID,
+		CacheControl:       "max-age=3600",
+		ContentDisposition: `attachment; filename="x.pdf"`,
+		ContentEncoding:    "gzip",
+		Expires:            "Thu, 01 Dec 2033 16:00:00 GMT",
+	})
+	require.NoError(t, err)
+	part := uploadTestPart(t, inst.multipart, initiated.UploadID, 1, []byte("content"), ownerID)
+
+	_, err = inst.multipart.CompleteMultipartUpload(ctx, CompleteMultipartUploadInput{
+		BucketName: "uploads",
+		Key:        "video.bin",
+		UploadID:   initiated.UploadID,
+		Parts:      []domain.CompletedPart{part},
+		OwnerID:    ownerID,
+	})
+	require.NoError(t, err)
+
+	head, err := inst.objects.HeadObject(ctx, HeadObjectInput{BucketName: "uploads", Key: "video.bin", OwnerID: ownerID})
+	require.NoError(t, err)
+	assert.Equal(t, "max-age=3600", head.CacheControl)
+	assert.Equal(t, `attachment; filename="x.pdf"`, head.ContentDisposition)
+	assert.Equal(t, "gzip", head.ContentEncoding)
+	assert.Equal(t, "Thu, 01 Dec 2033 16:00:00 GMT", head.Expires)
+}
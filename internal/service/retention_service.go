@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/repository"
+)
+
+// RetentionService manages soft-deleted objects. Deleted objects keep their
+// row and blob reference for the retention period, during which an operator
+// can restore them. Afterwards they are purged and their blob reference is
+// released, so the garbage collector can reclaim the content.
+type RetentionService struct {
+	objectRepo repository.ObjectRepository
+	blobRepo   repository.BlobRepository
+	bucketRepo repository.BucketRepository
+	logger     zerolog.Logger
+	config     RetentionConfig
+}
+
+// RetentionConfig contains soft-delete retention configuration.
+type RetentionConfig struct {
+	// Retention is how long soft-deleted objects can be restored before they are purged.
+	Retention time.Duration
+
+	// BatchSize is the maximum number of objects to purge per run.
+	BatchSize int
+}
+
+// DefaultRetentionConfig returns sensible defaults.
+func DefaultRetentionConfig() RetentionConfig {
+	return RetentionConfig{
+		Retention: 24 * time.Hour,
+		BatchSize: 1000,
+	}
+}
+
+// NewRetentionService creates a new RetentionService.
+func NewRetentionService(
+	objectRepo repository.ObjectRepository,
+	blobRepo repository.BlobRepository,
+	bucketRepo repository.BucketRepository,
+	logger zerolog.Logger,
+	config RetentionConfig,
+) *RetentionService {
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultRetentionConfig().BatchSize
+	}
+	return &RetentionService{
+		objectRepo: objectRepo,
+		blobRepo:   blobRepo,
+		bucketRepo: bucketRepo,
+		logger:     logger.With().Str("service", "retention").Logger(),
+		config:     config,
+	}
+}
+
+// UndeleteObjectInput contains the data needed to restore a deleted object.
+type UndeleteObjectInput struct {
+	BucketName string
+	Key        string
+}
+
+// UndeleteObjectOutput contains the result of restoring a deleted object.
+type UndeleteObjectOutput struct {
+	VersionID string
+	DeletedAt time.Time
+}
+
+// UndeleteObject restores the most recently deleted version of an object,
+// provided it was deleted within the retention period.
+func (s *RetentionService) UndeleteObject(ctx context.Context, input UndeleteObjectInput) (*UndeleteObjectOutput, error) {
+	bucket, err := s.bucketRepo.GetByName(ctx, input.BucketName)
+	if err != nil {
+		if errors.Is(err, domain.ErrBucketNotFound) {
+			return nil, domain.ErrBucketNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+
+	obj, err := s.objectRepo.GetLatestDeleted(ctx, bucket.ID, bucket.NormalizeKey(input.Key))
+	if err != nil {
+		if errors.Is(err, domain.ErrObjectNotFound) {
+			return nil, domain.ErrObjectNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+
+	// Objects past their retention are about to be purged and may already
+	// have released their blob
+	if obj.DeletedAt == nil || time.Since(*obj.DeletedAt) >= s.config.Retention {
+		return nil, domain.ErrObjectNotFound
+	}
+	deletedAt := *obj.DeletedAt
+
+	// Without versioning a newer write replaced the deleted object, and
+	// restoring it would leave two objects under one key
+	if !bucket.IsVersioningEverEnabled() && !obj.IsLatest {
+		return nil, domain.ErrObjectRestoreConflict
+	}
+
+	if err := s.objectRepo.Undelete(ctx, obj.ID); err != nil {
+		if errors.Is(err, domain.ErrObjectNotFound) {
+			return nil, domain.ErrObjectNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInternalError, err)
+	}
+
+	s.logger.Info().
+		Str("bucket", input.BucketName).
+		Str("key", input.Key).
+		Str("version_id", obj.GetVersionIDString()).
+		Time("deleted_at", deletedAt).
+		Msg("object restored")
+
+	return &UndeleteObjectOutput{
+		VersionID: responseVersionID(bucket, obj),
+		DeletedAt: deletedAt,
+	}, nil
+}
+
+// PurgeExpired permanently removes objects deleted longer ago than the
+// retention period and releases their blob references.
+// It returns the number of purged objects.
+func (s *RetentionService) PurgeExpired(ctx context.Context) (int, error) {
+	cutoff := time.Now().Add(-s.config.Retention)
+	objects, err := s.objectRepo.ListSoftDeleted(ctx, cutoff, s.config.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list soft-deleted objects: %w", err)
+	}
+
+	purged := 0
+	for _, obj := range objects {
+		// Purge only succeeds while the object is still deleted, so an object
+		// restored since it was listed keeps its blob reference
+		if err := s.objectRepo.Purge(ctx, obj.ID); err != nil {
+			if !errors.Is(err, domain.ErrObjectNotFound) {
+				s.logger.Error().Err(err).Int64("object_id", obj.ID).Msg("failed to purge object")
+			}
+			continue
+		}
+
+		if obj.ContentHash != nil {
+			if _, err := s.blobRepo.DecrementRef(ctx, *obj.ContentHash); err != nil {
+				s.logger.Error().Err(err).Str("content_hash", *obj.ContentHash).Msg("failed to decrement ref count")
+			}
+		}
+		purged++
+	}
+
+	if purged > 0 {
+		s.logger.Info().
+			Int("count", purged).
+			Dur("retention", s.config.Retention).
+			Msg("purged soft-deleted objects")
+	}
+
+	return purged, nil
+}
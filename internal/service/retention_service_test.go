@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/lock"
+	"github.com/prn-tf/alexander-storage/internal/repository"
+)
+
+// retentionObjectRepository is an in-memory object store that keeps
+// soft-deleted rows like the database does.
+type retentionObjectRepository struct {
+	repository.ObjectRepository
+	mu      sync.Mutex
+	objects map[int64]*domain.Object
+}
+
+func (r *retentionObjectRepository) Create(ctx context.Context, obj *domain.Object) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var maxID int64
+	for id := range r.objects {
+		maxID = max(maxID, id)
+	}
+	obj.ID = maxID + 1
+	r.objects[obj.ID] = obj
+	return nil
+}
+
+func (r *retentionObjectRepository) ListKeyVersions(ctx context.Context, bucketID int64, key string) ([]*domain.Object, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var versions []*domain.Object
+	for _, obj := range r.objects {
+		if obj.BucketID == bucketID && obj.Key == key && obj.DeletedAt == nil {
+			versions = append(versions, obj)
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].ID > versions[j].ID })
+	return versions, nil
+}
+
+func (r *retentionObjectRepository) GetByKey(ctx context.Context, bucketID int64, key string) (*domain.Object, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, obj := range r.objects {
+		if obj.BucketID == bucketID && obj.Key == key && obj.IsLatest && obj.DeletedAt == nil {
+			return obj, nil
+		}
+	}
+	return nil, domain.ErrObjectNotFound
+}
+
+func (r *retentionObjectRepository) MarkNotLatest(ctx context.Context, bucketID int64, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, obj := range r.objects {
+		if obj.BucketID == bucketID && obj.Key == key {
+			obj.IsLatest = false
+		}
+	}
+	return nil
+}
+
+func (r *retentionObjectRepository) Delete(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	obj, ok := r.objects[id]
+	if !ok {
+		return domain.ErrObjectNotFound
+	}
+	now := time.Now().UTC()
+	obj.DeletedAt = &now
+	return nil
+}
+
+func (r *retentionObjectRepository) GetLatestDeleted(ctx context.Context, bucketID int64, key string) (*domain.Object, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var latest *domain.Object
+	for _, obj := range r.objects {
+		if obj.BucketID != bucketID || obj.Key != key || obj.DeletedAt == nil {
+			continue
+		}
+		if latest == nil || obj.DeletedAt.After(*latest.DeletedAt) {
+			latest = obj
+		}
+	}
+	if latest == nil {
+		return nil, domain.ErrObjectNotFound
+	}
+	return latest, nil
+}
+
+func (r *retentionObjectRepository) ListSoftDeleted(ctx context.Context, deletedBefore time.Time, limit int) ([]*domain.Object, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var objects []*domain.Object
+	for _, obj := range r.objects {
+		if obj.DeletedAt != nil && obj.DeletedAt.Before(deletedBefore) && len(objects) < limit {
+			objects = append(objects, obj)
+		}
+	}
+	return objects, nil
+}
+
+func (r *retentionObjectRepository) Undelete(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	obj, ok := r.objects[id]
+	if !ok || obj.DeletedAt == nil {
+		return domain.ErrObjectNotFound
+	}
+	obj.DeletedAt = nil
+	return nil
+}
+
+func (r *retentionObjectRepository) Purge(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	obj, ok := r.objects[id]
+	if !ok || obj.DeletedAt == nil {
+		return domain.ErrObjectNotFound
+	}
+	delete(r.objects, id)
+	return nil
+}
+
+// backdate moves the deletion time of an object into the past.
+func (r *retentionObjectRepository) backdate(id int64, d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	deletedAt := r.objects[id].DeletedAt.Add(-d)
+	r.objects[id].DeletedAt = &deletedAt
+}
+
+// retentionBlobRepository counts blob references.
+type retentionBlobRepository struct {
+	repository.BlobRepository
+	mu   sync.Mutex
+	refs map[string]int32
+}
+
+func (r *retentionBlobRepository) UpsertWithRefIncrement(ctx context.Context, contentHash string, size int64, storagePath string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.refs[contentHash]++
+	return r.refs[contentHash] == 1, nil
+}
+
+func (r *retentionBlobRepository) DecrementRef(ctx context.Context, contentHash string) (int32, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.refs[contentHash]--
+	return r.refs[contentHash], nil
+}
+
+func newTestRetention(t *testing.T, retention time.Duration) (*RetentionService, *ObjectService, *retentionObjectRepository, *retentionBlobRepository) {
+	t.Helper()
+
+	bucketRepo := NewMockBucketRepository()
+	require.NoError(t, bucketRepo.Create(context.Background(), &domain.Bucket{
+		OwnerID:    1,
+		Name:       "test-bucket",
+		Versioning: domain.VersioningDisabled,
+		State:      domain.BucketStateActive,
+	}))
+
+	hash := "abc123hash"
+	objectRepo := &retentionObjectRepository{objects: map[int64]*domain.Object{
+		1: {ID: 1, BucketID: 1, Key: "doc.txt", IsLatest: true, ContentHash: &hash, Size: 5},
+	}}
+	blobRepo := &retentionBlobRepository{refs: map[string]int32{hash: 1}}
+
+	objectSvc := NewObjectService(objectRepo, blobRepo, bucketRepo, nil, lock.NewNoOpLocker(), zerolog.Nop())
+	retentionSvc := NewRetentionService(objectRepo, blobRepo, bucketRepo, zerolog.Nop(), RetentionConfig{
+		Retention: retention,
+		BatchSize: 100,
+	})
+	return retentionSvc, objectSvc, objectRepo, blobRepo
+}
+
+func TestRetentionService_UndeleteAndPurge(t *testing.T) {
+	ctx := context.Background()
+	retentionSvc, objectSvc, objectRepo, blobRepo := newTestRetention(t, time.Hour)
+
+	// Soft delete keeps the row and its blob reference
+	_, err := objectSvc.DeleteObject(ctx, DeleteObjectInput{BucketName: "test-bucket", Key: "doc.txt"})
+	require.NoError(t, err)
+	_, err = objectRepo.GetByKey(ctx, 1, "doc.txt")
+	require.ErrorIs(t, err, domain.ErrObjectNotFound)
+	require.Equal(t, int32(1), blobRepo.refs["abc123hash"])
+
+	// Within the retention period the object is not purged and can be restored
+	purged, err := retentionSvc.PurgeExpired(ctx)
+	require.NoError(t, err)
+	require.Zero(t, purged)
+
+	output, err := retentionSvc.UndeleteObject(ctx, UndeleteObjectInput{BucketName: "test-bucket", Key: "doc.txt"})
+	require.NoError(t, err)
+	require.False(t, output.DeletedAt.IsZero())
+
+	restored, err := objectRepo.GetByKey(ctx, 1, "doc.txt")
+	require.NoError(t, err)
+	require.Equal(t, int64(1), restored.ID)
+
+	// Once the retention period has passed the object can no longer be
+	// restored and is purged, releasing its blob reference
+	_, err = objectSvc.DeleteObject(ctx, DeleteObjectInput{BucketName: "test-bucket", Key: "doc.txt"})
+	require.NoError(t, err)
+	objectRepo.backdate(1, 2*time.Hour)
+
+	_, err = retentionSvc.UndeleteObject(ctx, UndeleteObjectInput{BucketName: "test-bucket", Key: "doc.txt"})
+	require.ErrorIs(t, err, domain.ErrObjectNotFound)
+
+	purged, err = retentionSvc.PurgeExpired(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, purged)
+	require.Empty(t, objectRepo.objects)
+	require.Equal(t, int32(0), blobRepo.refs["abc123hash"])
+
+	_, err = retentionSvc.UndeleteObject(ctx, UndeleteObjectInput{BucketName: "test-bucket", Key: "doc.txt"})
+	require.ErrorIs(t, err, domain.ErrObjectNotFound)
+}
+
+func TestRetentionService_UndeleteOverwrittenKey(t *testing.T) {
+	ctx := context.Background()
+	retentionSvc, objectSvc, objectRepo, _ := newTestRetention(t, time.Hour)
+
+	_, err := objectSvc.DeleteObject(ctx, DeleteObjectInput{BucketName: "test-bucket", Key: "doc.txt"})
+	require.NoError(t, err)
+
+	// A new write to the key marks the deleted object as no longer latest
+	require.NoError(t, objectRepo.MarkNotLatest(ctx, 1, "doc.txt"))
+	objectRepo.objects[2] = &domain.Object{ID: 2, BucketID: 1, Key: "doc.txt", IsLatest: true}
+
+	_, err = retentionSvc.UndeleteObject(ctx, UndeleteObjectInput{BucketName: "test-bucket", Key: "doc.txt"})
+	require.ErrorIs(t, err, domain.ErrObjectRestoreConflict)
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/prn-tf/alexander-storage/internal/repository"
+	"github.com/prn-tf/alexander-storage/internal/storage"
+)
+
+// BlobBackfiller copies blobs from the old to the new backend of a storage
+// migration.
+type BlobBackfiller interface {
+	// Backfill copies a blob to the new backend unless it is already there,
+	// and reports whether it was copied.
+	Backfill(ctx context.Context, contentHash string) (bool, error)
+}
+
+// StorageBackfill walks every blob in the background and backfills it into
+// the new backend of a dual-write storage migration. Blobs written during the
+// migration already land in both backends, so once a full pass succeeds the
+// old backend is no longer needed.
+type StorageBackfill struct {
+	blobRepo   repository.BlobRepository
+	backfiller BlobBackfiller
+	logger     zerolog.Logger
+	config     StorageBackfillConfig
+
+	// Progress of the current pass
+	progressMu sync.Mutex
+	cursor     string
+	passErrors int
+	complete   bool
+
+	// Control
+	mu       sync.Mutex
+	running  bool
+	stopChan chan struct{}
+	doneChan chan struct{}
+}
+
+// StorageBackfillConfig contains backfill configuration.
+type StorageBackfillConfig struct {
+	// Interval is the pause between batches.
+	Interval time.Duration
+
+	// BatchSize is the maximum number of blobs to process per batch.
+	BatchSize int
+}
+
+// DefaultStorageBackfillConfig returns sensible defaults.
+func DefaultStorageBackfillConfig() StorageBackfillConfig {
+	return StorageBackfillConfig{
+		Interval:  time.Second,
+		BatchSize: 100,
+	}
+}
+
+// NewStorageBackfill creates a new storage backfill job.
+func NewStorageBackfill(
+	blobRepo repository.BlobRepository,
+	backfiller BlobBackfiller,
+	logger zerolog.Logger,
+	config StorageBackfillConfig,
+) *StorageBackfill {
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultStorageBackfillConfig().BatchSize
+	}
+	if config.Interval <= 0 {
+		config.Interval = DefaultStorageBackfillConfig().Interval
+	}
+	return &StorageBackfill{
+		blobRepo:   blobRepo,
+		backfiller: backfiller,
+		logger:     logger.With().Str("service", "storage_backfill").Logger(),
+		config:     config,
+		stopChan:   make(chan struct{}),
+		doneChan:   make(chan struct{}),
+	}
+}
+
+// Start begins backfilling in the background. It stops by itself once
+// every blob has been backfilled.
+func (b *StorageBackfill) Start() {
+	b.mu.Lock()
+	if b.running {
+		b.mu.Unlock()
+		return
+	}
+	b.running = true
+	b.mu.Unlock()
+
+	b.logger.Info().
+		Dur("interval", b.config.Interval).
+		Int("batch_size", b.config.BatchSize).
+		Msg("Starting storage backfill")
+
+	go b.runLoop()
+}
+
+// Stop stops the backfill and waits for the current batch to finish.
+func (b *StorageBackfill) Stop() {
+	b.mu.Lock()
+	if !b.running {
+		b.mu.Unlock()
+		return
+	}
+	b.running = false
+	b.mu.Unlock()
+
+	close(b.stopChan)
+	<-b.doneChan
+
+	b.logger.Info().Msg("Storage backfill stopped")
+}
+
+// runLoop processes batches until the backfill completes or is stopped.
+func (b *StorageBackfill) runLoop() {
+	defer close(b.doneChan)
+
+	ticker := time.NewTicker(b.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if result := b.RunOnce(context.Background()); result.Complete {
+			return
+		}
+		select {
+		case <-ticker.C:
+		case <-b.stopChan:
+			return
+		}
+	}
+}
+
+// StorageBackfillResult contains the result of a backfill batch.
+type StorageBackfillResult struct {
+	// BlobsCopied is the number of blobs copied to the new backend.
+	BlobsCopied int
+
+	// BlobsSkipped is the number of blobs the new backend already held.
+	BlobsSkipped int
+
+	// BlobsMissing is the number of blobs neither backend holds.
+	BlobsMissing int
+
+	// Errors is the number of blobs that failed to copy.
+	Errors int
+
+	// Complete reports that a full pass over all blobs succeeded, so the
+	// old backend can be retired.
+	Complete bool
+}
+
+// RunOnce backfills the next batch of blobs. A pass that ends with errors
+// starts over, so failed blobs are retried.
+func (b *StorageBackfill) RunOnce(ctx context.Context) StorageBackfillResult {
+	b.progressMu.Lock()
+	defer b.progressMu.Unlock()
+
+	result := StorageBackfillResult{Complete: b.complete}
+	if b.complete {
+		return result
+	}
+
+	blobs, err := b.blobRepo.ListAfter(ctx, b.cursor, b.config.BatchSize)
+	if err != nil {
+		b.logger.Error().Err(err).Msg("Failed to list blobs for backfill")
+		result.Errors++
+		return result
+	}
+
+	for _, blob := range blobs {
+		copied, err := b.backfiller.Backfill(ctx, blob.ContentHash)
+		switch {
+		case storage.IsNotFound(err):
+			// Nothing to migrate; the blob is reported by integrity checks
+			b.logger.Warn().Str("content_hash", blob.ContentHash).Msg("Blob missing from both storage backends")
+			result.BlobsMissing++
+		case err != nil:
+			b.logger.Error().Err(err).Str("content_hash", blob.ContentHash).Msg("Failed to backfill blob")
+			result.Errors++
+		case copied:
+			result.BlobsCopied++
+		default:
+			result.BlobsSkipped++
+		}
+		b.cursor = blob.ContentHash
+	}
+	b.passErrors += result.Errors
+
+	if len(blobs) < b.config.BatchSize {
+		// End of the pass
+		if b.passErrors == 0 {
+			b.complete = true
+			result.Complete = true
+			b.logger.Info().Msg("Storage backfill complete; the old backend can be retired")
+		} else {
+			b.logger.Warn().Int("errors", b.passErrors).Msg("Storage backfill pass finished with errors, retrying")
+		}
+		b.cursor = ""
+		b.passErrors = 0
+	}
+
+	if result.BlobsCopied > 0 || result.Errors > 0 {
+		b.logger.Info().
+			Int("blobs_copied", result.BlobsCopied).
+			Int("blobs_skipped", result.BlobsSkipped).
+			Int("blobs_missing", result.BlobsMissing).
+			Int("errors", result.Errors).
+			Msg("Storage backfill batch completed")
+	}
+
+	return result
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/repository"
+	"github.com/prn-tf/alexander-storage/internal/storage"
+)
+
+// backfillBlobRepository lists a fixed set of blobs by content hash.
+type backfillBlobRepository struct {
+	repository.BlobRepository
+	hashes []string
+}
+
+func (r *backfillBlobRepository) ListAfter(ctx context.Context, afterHash string, limit int) ([]*domain.Blob, error) {
+	var blobs []*domain.Blob
+	for _, hash := range r.hashes {
+		if hash > afterHash && len(blobs) < limit {
+			blobs = append(blobs, &domain.Blob{ContentHash: hash})
+		}
+	}
+	return blobs, nil
+}
+
+// fakeBackfiller records backfilled blobs and fails those in failures.
+type fakeBackfiller struct {
+	present  map[string]bool
+	failures map[string]error
+	copied   []string
+}
+
+func (b *fakeBackfiller) Backfill(ctx context.Context, contentHash string) (bool, error) {
+	if err := b.failures[contentHash]; err != nil {
+		return false, err
+	}
+	if b.present[contentHash] {
+		return false, nil
+	}
+	b.present[contentHash] = true
+	b.copied = append(b.copied, contentHash)
+	return true, nil
+}
+
+func TestStorageBackfill_RunsUntilComplete(t *testing.T) {
+	ctx := context.Background()
+	hashes := []string{"a1", "b2", "c3", "d4", "e5"}
+	backfiller := &fakeBackfiller{
+		present:  map[string]bool{"b2": true},
+		failures: map[string]error{"d4": errors.New("connection reset")},
+	}
+	backfill := NewStorageBackfill(&backfillBlobRepository{hashes: hashes}, backfiller, zerolog.Nop(), StorageBackfillConfig{BatchSize: 2})
+
+	// First pass: two full batches, then the last one ends the pass with an error
+	result := backfill.RunOnce(ctx)
+	assert.Equal(t, StorageBackfillResult{BlobsCopied: 1, BlobsSkipped: 1}, result)
+	result = backfill.RunOnce(ctx)
+	assert.Equal(t, 1, result.BlobsCopied)
+	assert.Equal(t, 1, result.Errors)
+	result = backfill.RunOnce(ctx)
+	assert.Equal(t, 1, result.BlobsCopied)
+	assert.False(t, result.Complete, "a pass with errors does not complete the backfill")
+
+	// The next pass retries the failed blob and completes
+	delete(backfiller.failures, "d4")
+	backfiller.failures["e5"] = storage.ErrBlobNotFound
+	for i := 0; i < 2; i++ {
+		result = backfill.RunOnce(ctx)
+		require.False(t, result.Complete)
+	}
+	result = backfill.RunOnce(ctx)
+	assert.Equal(t, 1, result.BlobsMissing)
+	assert.True(t, result.Complete, "missing blobs do not hold up completion")
+	assert.Equal(t, []string{"a1", "c3", "e5", "d4"}, backfiller.copied)
+
+	assert.True(t, backfill.RunOnce(ctx).Complete)
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"github.com/rs/zerolog"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/repository"
+)
+
+// VersionDeltifier stores a blob as a delta against a similar blob.
+// storage.DeltaBackend satisfies it.
+type VersionDeltifier interface {
+	Deltify(ctx context.Context, baseHash, contentHash string) (bool, error)
+}
+
+// DefaultDeltaQueueSize is the number of versions DeltaQueue buffers.
+const DefaultDeltaQueueSize = 1024
+
+// DeltaQueue stores new versions as deltas in the background, so writes do
+// not wait while their content is chunked and compared with the version
+// they supersede. A fixed number of workers bounds the work done at once.
+// When the queue is full a version is simply kept in full.
+//
+// A nil *DeltaQueue is valid and stores nothing as a delta.
+type DeltaQueue struct {
+	deltifier VersionDeltifier
+	logger    zerolog.Logger
+
+	// ctx is cancelled when Close gives up waiting for queued versions
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu     sync.RWMutex
+	jobs   chan deltaJob
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// deltaJob is a version waiting to be stored as a delta against baseHash.
+type deltaJob struct {
+	baseHash    string
+	contentHash string
+	key         string
+	versionID   string
+}
+
+// NewDeltaQueue creates a DeltaQueue running workers workers. A workers
+// value below 1 runs one worker, and a queueSize of 0 or less uses
+// DefaultDeltaQueueSize.
+func NewDeltaQueue(deltifier VersionDeltifier, workers, queueSize int, logger zerolog.Logger) *DeltaQueue {
+	if queueSize <= 0 {
+		queueSize = DefaultDeltaQueueSize
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	q := &DeltaQueue{
+		deltifier: deltifier,
+		logger:    logger.With().Str("component", "version-delta").Logger(),
+		ctx:       ctx,
+		cancel:    cancel,
+		jobs:      make(chan deltaJob, queueSize),
+	}
+
+	for i := 0; i < max(workers, 1); i++ {
+		q.wg.Add(1)
+		go q.work()
+	}
+	return q
+}
+
+// Enqueue queues obj to be stored as a delta against the blob baseHash
+// without blocking. It does nothing if baseHash is empty.
+func (q *DeltaQueue) Enqueue(baseHash string, obj *domain.Object) {
+	if q == nil || baseHash == "" || obj.ContentHash == nil || obj.IsSSECustomerEncrypted() {
+		return
+	}
+	job := deltaJob{
+		baseHash:    baseHash,
+		contentHash: *obj.ContentHash,
+		key:         obj.Key,
+		versionID:   obj.VersionID.String(),
+	}
+
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.closed {
+		return
+	}
+	select {
+	case q.jobs <- job:
+	default:
+		q.logger.Warn().
+			Str("key", job.key).
+			Str("content_hash", job.contentHash).
+			Msg("delta queue full, keeping version in full")
+	}
+}
+
+// Close stops accepting versions and waits until the queued ones are stored
+// or ctx is done, when the work still running is cancelled.
+func (q *DeltaQueue) Close(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.jobs)
+	}
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		q.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// work stores queued versions until the queue is closed.
+func (q *DeltaQueue) work() {
+	defer q.wg.Done()
+
+	for job := range q.jobs {
+		if q.ctx.Err() != nil {
+			continue // Closing: the rest stay in full
+		}
+		q.deltify(job)
+	}
+}
+
+// deltify stores one version as a delta. Failures are logged: the blob is
+// then simply kept in full.
+func (q *DeltaQueue) deltify(job deltaJob) {
+	stored, err := q.deltifier.Deltify(q.ctx, job.baseHash, job.contentHash)
+	if err != nil {
+		q.logger.Warn().Err(err).
+			Str("key", job.key).
+			Str("content_hash", job.contentHash).
+			Str("base_hash", job.baseHash).
+			Msg("failed to store version as delta")
+		return
+	}
+	if stored {
+		q.logger.Debug().
+			Str("key", job.key).
+			Str("version_id", job.versionID).
+			Str("base_hash", job.baseHash).
+			Msg("version stored as delta")
+	}
+}
+
+// SetDeltaQueue stores each new version written by PutObject to a versioned
+// bucket as a delta against the version it supersedes, when their content
+// is similar enough.
+func (s *ObjectService) SetDeltaQueue(queue *DeltaQueue) {
+	s.deltas = queue
+}
+
+// SetDeltaQueue stores each new version written by CompleteMultipartUpload
+// to a versioned bucket as a delta against the version it supersedes, when
+// their content is similar enough.
+func (s *MultipartService) SetDeltaQueue(queue *DeltaQueue) {
+	s.deltas = queue
+}
+
+// deltaBase returns the blob of the latest version of key, which a new
+// version may be stored as a delta against, or "" if there is none. Only
+// versioned buckets keep the superseded version, and customer-encrypted or
+// encrypted-at-rest blobs never share content.
+func deltaBase(ctx context.Context, deltas *DeltaQueue, objectRepo repository.ObjectRepository, blobRepo repository.BlobRepository, bucket *domain.Bucket, key string) string {
+	if deltas == nil || bucket.Versioning != domain.VersioningEnabled {
+		return ""
+	}
+
+	latest, err := objectRepo.GetByKey(ctx, bucket.ID, bucket.NormalizeKey(key))
+	if err != nil || latest.IsDeleteMarker || latest.ContentHash == nil || latest.IsSSECustomerEncrypted() {
+		return ""
+	}
+
+	blob, err := blobRepo.GetByHash(ctx, *latest.ContentHash)
+	if err != nil || (blob.IsEncrypted && blob.EncryptionScheme != domain.EncryptionSchemeNone) {
+		return ""
+	}
+	return blob.ContentHash
+}
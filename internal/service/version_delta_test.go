@@ -0,0 +1,239 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"io"
+	"math/rand"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/delta"
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/lock"
+	"github.com/prn-tf/alexander-storage/internal/repository"
+	"github.com/prn-tf/alexander-storage/internal/repository/sqlite"
+	"github.com/prn-tf/alexander-storage/internal/storage"
+	"github.com/prn-tf/alexander-storage/internal/storage/filesystem"
+)
+
+// deltaTestService is an ObjectService storing versions as deltas on the
+// filesystem, with a versioned bucket "versions".
+type deltaTestService struct {
+	svc        *ObjectService
+	queue      *DeltaQueue
+	store      storage.Backend
+	chains     storage.DeltaChainStore
+	objectRepo repository.ObjectRepository
+	blobRepo   repository.BlobRepository
+	bucket     *domain.Bucket
+	ownerID    int64
+}
+
+func newDeltaTestService(t *testing.T, chunker delta.Chunker) *deltaTestService {
+	t.Helper()
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	db, err := sqlite.NewDB(ctx, sqlite.DefaultConfig(filepath.Join(dir, "alexander.db")), zerolog.Nop())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, db.Migrate(ctx))
+
+	store, err := filesystem.NewStorage(filesystem.Config{
+		DataDir: filepath.Join(dir, "data"),
+		TempDir: filepath.Join(dir, "tmp"),
+	}, zerolog.Nop())
+	require.NoError(t, err)
+
+	chains := sqlite.NewDeltaChainRepository(db)
+	config := storage.DefaultDeltaConfig()
+	config.TempDir = filepath.Join(dir, "tmp")
+	deltaBackend := storage.NewDeltaBackend(store, chains, chunker, config, zerolog.Nop())
+	queue := NewDeltaQueue(deltaBackend, 2, 0, zerolog.Nop())
+	t.Cleanup(func() { queue.Close(context.Background()) })
+
+	objectRepo := sqlite.NewObjectRepository(db)
+	blobRepo := sqlite.NewBlobRepository(db)
+	bucketRepo := sqlite.NewBucketRepository(db)
+	svc := NewObjectService(objectRepo, blobRepo, bucketRepo, deltaBackend, lock.NewNoOpLocker(), zerolog.Nop())
+	svc.SetDeltaQueue(queue)
+
+	user := domain.NewUser("tester", "tester@example.com", "hash")
+	require.NoError(t, sqlite.NewUserRepository(db).Create(ctx, user))
+	bucket := domain.NewBucket(user.ID, "versions")
+	bucket.Versioning = domain.VersioningEnabled
+	require.NoError(t, bucketRepo.Create(ctx, bucket))
+
+	return &deltaTestService{
+		svc:        svc,
+		queue:      queue,
+		store:      store,
+		chains:     chains,
+		objectRepo: objectRepo,
+		blobRepo:   blobRepo,
+		bucket:     bucket,
+		ownerID:    user.ID,
+	}
+}
+
+func TestObjectService_PutObjectStoresSimilarVersionAsDelta(t *testing.T) {
+	ctx := context.Background()
+	env := newDeltaTestService(t, delta.NewFastCDC(delta.FastCDCConfig{MinSize: 2 * 1024, AvgSize: 8 * 1024, MaxSize: 64 * 1024, NormalizationLevel: 2}))
+	svc, store, chains, objectRepo, blobRepo, bucket := env.svc, env.store, env.chains, env.objectRepo, env.blobRepo, env.bucket
+	deltaBackend := svc.storage.(*storage.DeltaBackend)
+
+	put := func(data []byte) string {
+		out, err := svc.PutObject(ctx, PutObjectInput{
+			BucketName: "versions",
+			Key:        "disk.img",
+			Body:       bytes.NewReader(data),
+			Size:       int64(len(data)),
+			OwnerID:    env.ownerID,
+		})
+		require.NoError(t, err)
+		return out.VersionID
+	}
+	get := func(versionID string) []byte {
+		out, err := svc.GetObject(ctx, GetObjectInput{BucketName: "versions", Key: "disk.img", VersionID: versionID, OwnerID: env.ownerID})
+		require.NoError(t, err)
+		defer out.Body.Close()
+		data, err := io.ReadAll(out.Body)
+		require.NoError(t, err)
+		return data
+	}
+
+	original := make([]byte, 4*1024*1024)
+	rand.New(rand.NewSource(1)).Read(original)
+	edited := bytes.Clone(original)
+	copy(edited[3*1024*1024:], "a small edit to a large object")
+
+	firstVersion := put(original)
+	secondVersion := put(edited)
+
+	// Versions are stored as deltas in the background
+	require.NoError(t, env.queue.Close(ctx))
+
+	// The new version keeps only the changed chunks next to its base
+	first, err := objectRepo.GetByKeyAndVersion(ctx, bucket.ID, "disk.img", uuid.MustParse(firstVersion))
+	require.NoError(t, err)
+	second, err := objectRepo.GetByKey(ctx, bucket.ID, "disk.img")
+	require.NoError(t, err)
+	link, err := chains.GetDelta(ctx, *second.ContentHash)
+	require.NoError(t, err)
+	assert.Equal(t, *first.ContentHash, link.BaseHash)
+	assert.Equal(t, 1, link.Depth)
+	assert.Less(t, link.DataSize, int64(len(edited)/10))
+
+	exists, err := store.Exists(ctx, *second.ContentHash)
+	require.NoError(t, err)
+	assert.False(t, exists)
+	dataSize, err := store.GetSize(ctx, link.DataHash)
+	require.NoError(t, err)
+	assert.Equal(t, link.DataSize, dataSize)
+
+	// The delta keeps its base and data blobs from garbage collection
+	refCount, err := blobRepo.GetRefCount(ctx, link.BaseHash)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), refCount)
+	referenced, err := blobRepo.IsReferenced(ctx, link.DataHash)
+	require.NoError(t, err)
+	assert.True(t, referenced)
+
+	assert.True(t, bytes.Equal(original, get(firstVersion)))
+	assert.True(t, bytes.Equal(edited, get(secondVersion)))
+
+	// Deleting the new version's blob releases the references of its delta
+	require.NoError(t, deltaBackend.Delete(ctx, *second.ContentHash))
+	refCount, err = blobRepo.GetRefCount(ctx, link.BaseHash)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), refCount)
+	refCount, err = blobRepo.GetRefCount(ctx, link.DataHash)
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), refCount)
+}
+
+// editedReader yields size pseudo-random bytes from seed, with edit written
+// over them at offset, without holding them in memory.
+type editedReader struct {
+	rng    *rand.Rand
+	pos    int64
+	size   int64
+	offset int64
+	edit   []byte
+}
+
+func newEditedReader(seed, size, offset int64, edit string) *editedReader {
+	return &editedReader{rng: rand.New(rand.NewSource(seed)), size: size, offset: offset, edit: []byte(edit)}
+}
+
+func (r *editedReader) Read(p []byte) (int, error) {
+	if r.pos >= r.size {
+		return 0, io.EOF
+	}
+	p = p[:min(int64(len(p)), r.size-r.pos)]
+	n, _ := r.rng.Read(p)
+	for i := range p {
+		if at := r.pos + int64(i) - r.offset; at >= 0 && at < int64(len(r.edit)) {
+			p[i] = r.edit[at]
+		}
+	}
+	r.pos += int64(n)
+	return n, nil
+}
+
+func TestObjectService_SmallEditToLargeObjectStoresOnlyDelta(t *testing.T) {
+	if testing.Short() {
+		t.Skip("writes two large versions")
+	}
+	ctx := context.Background()
+	env := newDeltaTestService(t, delta.NewFastCDCDefault())
+
+	// Larger than deltas could once be built in memory for
+	const size = 300 * 1024 * 1024
+	put := func(edit string) *PutObjectOutput {
+		out, err := env.svc.PutObject(ctx, PutObjectInput{
+			BucketName: "versions",
+			Key:        "disk.img",
+			Body:       newEditedReader(1, size, 200*1024*1024, edit),
+			Size:       size,
+			OwnerID:    env.ownerID,
+		})
+		require.NoError(t, err)
+		return out
+	}
+	first := put("")
+	second := put("a small edit to a large object")
+	require.NoError(t, env.queue.Close(ctx))
+
+	latest, err := env.objectRepo.GetByKey(ctx, env.bucket.ID, "disk.img")
+	require.NoError(t, err)
+	link, err := env.chains.GetDelta(ctx, *latest.ContentHash)
+	require.NoError(t, err)
+	assert.Equal(t, int64(size), link.Size)
+	assert.Less(t, link.DataSize, int64(4*1024*1024))
+
+	exists, err := env.store.Exists(ctx, *latest.ContentHash)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	// The version reads back from its delta
+	out, err := env.svc.GetObject(ctx, GetObjectInput{BucketName: "versions", Key: "disk.img", VersionID: second.VersionID, OwnerID: env.ownerID})
+	require.NoError(t, err)
+	defer out.Body.Close()
+	want := sha256.New()
+	_, err = io.Copy(want, newEditedReader(1, size, 200*1024*1024, "a small edit to a large object"))
+	require.NoError(t, err)
+	got := sha256.New()
+	n, err := io.Copy(got, out.Body)
+	require.NoError(t, err)
+	assert.Equal(t, int64(size), n)
+	assert.Equal(t, want.Sum(nil), got.Sum(nil))
+	assert.NotEqual(t, first.VersionID, second.VersionID)
+}
@@ -0,0 +1,362 @@
+package storage
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+// cacheTempPrefix names cache files still being written. They are removed on
+// startup, as a crash can leave them behind.
+const cacheTempPrefix = ".tmp-"
+
+// CacheConfig configures a CachingBackend.
+type CacheConfig struct {
+	// Dir is the local directory holding cached blobs. It must not be used
+	// by anything else.
+	Dir string
+
+	// MaxSize is the most bytes of blobs kept in Dir. Blobs larger than
+	// MaxSize are never cached.
+	MaxSize int64
+}
+
+// CachingBackend keeps recently used blobs of a slow or remote backend in a
+// local directory. Blobs are cached as they are stored (write-through) and
+// when a read from the wrapped backend completes; the least recently used
+// blobs are evicted once the cache exceeds its size.
+//
+// Blobs are content addressed, so a cached blob never goes stale: it is
+// served as long as it is cached, and only checked against its hash when it
+// is first cached.
+type CachingBackend struct {
+	Backend
+	dir     string
+	maxSize int64
+	logger  zerolog.Logger
+
+	mu      sync.Mutex
+	lru     *list.List // of *cacheEntry, most recently used first
+	entries map[string]*list.Element
+	size    int64
+}
+
+type cacheEntry struct {
+	contentHash string
+	size        int64
+}
+
+// NewCachingBackend creates a CachingBackend around backend. Blobs already
+// in the cache directory are kept, oldest first in line for eviction.
+func NewCachingBackend(backend Backend, config CacheConfig, logger zerolog.Logger) (*CachingBackend, error) {
+	if config.Dir == "" {
+		return nil, fmt.Errorf("cache directory is required")
+	}
+	if config.MaxSize <= 0 {
+		return nil, fmt.Errorf("cache size must be positive")
+	}
+	if err := os.MkdirAll(config.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	b := &CachingBackend{
+		Backend: backend,
+		dir:     config.Dir,
+		maxSize: config.MaxSize,
+		logger:  logger.With().Str("component", "storage-cache").Logger(),
+		lru:     list.New(),
+		entries: make(map[string]*list.Element),
+	}
+	if err := b.load(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// load indexes the blobs left in the cache directory by an earlier run.
+func (b *CachingBackend) load() error {
+	dirEntries, err := os.ReadDir(b.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read cache directory: %w", err)
+	}
+
+	type cached struct {
+		contentHash string
+		size        int64
+		modTime     int64
+	}
+	var found []cached
+	for _, dirEntry := range dirEntries {
+		name := dirEntry.Name()
+		if strings.HasPrefix(name, cacheTempPrefix) {
+			_ = os.Remove(filepath.Join(b.dir, name))
+			continue
+		}
+		if dirEntry.IsDir() || !isContentHash(name) {
+			continue
+		}
+		info, err := dirEntry.Info()
+		if err != nil {
+			continue
+		}
+		found = append(found, cached{contentHash: name, size: info.Size(), modTime: info.ModTime().UnixNano()})
+	}
+
+	// Oldest last, so they are evicted first
+	sort.Slice(found, func(i, j int) bool { return found[i].modTime > found[j].modTime })
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, c := range found {
+		b.entries[c.contentHash] = b.lru.PushBack(&cacheEntry{contentHash: c.contentHash, size: c.size})
+		b.size += c.size
+	}
+	b.evictLocked(0)
+
+	b.logger.Info().Int("blobs", b.lru.Len()).Int64("bytes", b.size).Msg("storage cache loaded")
+	return nil
+}
+
+// Store stores content in the wrapped backend and caches a copy.
+func (b *CachingBackend) Store(ctx context.Context, reader io.Reader, size int64) (string, error) {
+	if size > b.maxSize {
+		return b.Backend.Store(ctx, reader, size)
+	}
+
+	fill, err := b.newFill()
+	if err != nil {
+		b.logger.Warn().Err(err).Msg("failed to create cache file")
+		return b.Backend.Store(ctx, reader, size)
+	}
+
+	contentHash, err := b.Backend.Store(ctx, io.TeeReader(reader, fill), size)
+	if err != nil {
+		fill.discard()
+		return "", err
+	}
+	b.commit(fill, contentHash)
+	return contentHash, nil
+}
+
+// Retrieve serves content from the cache, or from the wrapped backend while
+// caching it. A blob read from the wrapped backend is only cached if it is
+// read to the end.
+func (b *CachingBackend) Retrieve(ctx context.Context, contentHash string) (io.ReadCloser, error) {
+	if file := b.open(contentHash); file != nil {
+		return file, nil
+	}
+
+	reader, err := b.Backend.Retrieve(ctx, contentHash)
+	if err != nil {
+		return nil, err
+	}
+
+	fill, err := b.newFill()
+	if err != nil {
+		b.logger.Warn().Err(err).Msg("failed to create cache file")
+		return reader, nil
+	}
+	return &cachingReader{backend: b, reader: reader, fill: fill, contentHash: contentHash}, nil
+}
+
+// RetrieveRange serves a byte range from the cache, or from the wrapped
+// backend without caching it. It fails on a cache miss if the wrapped
+// backend does not support range reads.
+func (b *CachingBackend) RetrieveRange(ctx context.Context, contentHash string, offset, length int64) (io.ReadCloser, error) {
+	if file := b.open(contentHash); file != nil {
+		if _, err := file.Seek(offset, io.SeekStart); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to seek cached blob: %w", err)
+		}
+		return struct {
+			io.Reader
+			io.Closer
+		}{io.LimitReader(file, length), file}, nil
+	}
+
+	ranged, ok := b.Backend.(interface {
+		RetrieveRange(ctx context.Context, contentHash string, offset, length int64) (io.ReadCloser, error)
+	})
+	if !ok {
+		return nil, fmt.Errorf("storage backend does not support range requests")
+	}
+	return ranged.RetrieveRange(ctx, contentHash, offset, length)
+}
+
+// Delete removes content from the cache and the wrapped backend.
+func (b *CachingBackend) Delete(ctx context.Context, contentHash string) error {
+	b.mu.Lock()
+	if elem, ok := b.entries[contentHash]; ok {
+		b.removeLocked(elem)
+	}
+	b.mu.Unlock()
+
+	return b.Backend.Delete(ctx, contentHash)
+}
+
+// Unwrap returns the wrapped backend.
+func (b *CachingBackend) Unwrap() Backend {
+	return b.Backend
+}
+
+// open returns the cached file of a blob and marks it recently used, or nil
+// if the blob is not cached.
+func (b *CachingBackend) open(contentHash string) *os.File {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	elem, ok := b.entries[contentHash]
+	if !ok {
+		return nil
+	}
+	file, err := os.Open(b.path(contentHash))
+	if err != nil {
+		b.logger.Warn().Err(err).Str("content_hash", contentHash).Msg("cached blob unreadable, dropping it")
+		b.removeLocked(elem)
+		return nil
+	}
+	b.lru.MoveToFront(elem)
+	return file
+}
+
+// newFill creates a temporary file to fill with a blob.
+func (b *CachingBackend) newFill() (*cacheFill, error) {
+	file, err := os.CreateTemp(b.dir, cacheTempPrefix+"*")
+	if err != nil {
+		return nil, err
+	}
+	return &cacheFill{file: file, hash: sha256.New()}, nil
+}
+
+// commit adds a filled file to the cache as contentHash, unless its content
+// does not have that hash or does not fit.
+func (b *CachingBackend) commit(fill *cacheFill, contentHash string) {
+	if err := fill.file.Close(); err != nil || fill.err != nil {
+		_ = os.Remove(fill.file.Name())
+		return
+	}
+	if hex.EncodeToString(fill.hash.Sum(nil)) != contentHash || fill.size > b.maxSize {
+		_ = os.Remove(fill.file.Name())
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if elem, ok := b.entries[contentHash]; ok {
+		// Filled concurrently by another read
+		_ = os.Remove(fill.file.Name())
+		b.lru.MoveToFront(elem)
+		return
+	}
+	if err := os.Rename(fill.file.Name(), b.path(contentHash)); err != nil {
+		b.logger.Warn().Err(err).Str("content_hash", contentHash).Msg("failed to add blob to cache")
+		_ = os.Remove(fill.file.Name())
+		return
+	}
+	b.evictLocked(fill.size)
+	b.entries[contentHash] = b.lru.PushFront(&cacheEntry{contentHash: contentHash, size: fill.size})
+	b.size += fill.size
+}
+
+// evictLocked removes the least recently used blobs until incoming more
+// bytes fit.
+func (b *CachingBackend) evictLocked(incoming int64) {
+	for b.size+incoming > b.maxSize && b.lru.Len() > 0 {
+		b.removeLocked(b.lru.Back())
+	}
+}
+
+// removeLocked removes a blob from the cache.
+func (b *CachingBackend) removeLocked(elem *list.Element) {
+	entry := b.lru.Remove(elem).(*cacheEntry)
+	delete(b.entries, entry.contentHash)
+	b.size -= entry.size
+	if err := os.Remove(b.path(entry.contentHash)); err != nil && !os.IsNotExist(err) {
+		b.logger.Warn().Err(err).Str("content_hash", entry.contentHash).Msg("failed to remove cached blob")
+	}
+}
+
+// path returns the cache file of a blob.
+func (b *CachingBackend) path(contentHash string) string {
+	return filepath.Join(b.dir, contentHash)
+}
+
+// cacheFill is a cache file being written, hashed as it is written.
+type cacheFill struct {
+	file *os.File
+	hash hash.Hash
+	size int64
+	err  error
+}
+
+// Write writes to the cache file. A failed write is remembered rather than
+// returned, so it never fails the operation the cache is filled from.
+func (f *cacheFill) Write(p []byte) (int, error) {
+	if f.err == nil {
+		if _, err := f.file.Write(p); err != nil {
+			f.err = err
+		}
+		f.hash.Write(p)
+		f.size += int64(len(p))
+	}
+	return len(p), nil
+}
+
+// discard removes the cache file.
+func (f *cacheFill) discard() {
+	f.file.Close()
+	_ = os.Remove(f.file.Name())
+}
+
+// cachingReader reads a blob from the wrapped backend and caches it once it
+// is read to the end.
+type cachingReader struct {
+	backend     *CachingBackend
+	reader      io.ReadCloser
+	fill        *cacheFill
+	contentHash string
+	complete    bool
+}
+
+func (r *cachingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		_, _ = r.fill.Write(p[:n])
+	}
+	if err == io.EOF {
+		r.complete = true
+	} else if err != nil {
+		r.fill.err = err
+	}
+	return n, err
+}
+
+func (r *cachingReader) Close() error {
+	err := r.reader.Close()
+	if r.complete && err == nil {
+		r.backend.commit(r.fill, r.contentHash)
+	} else {
+		r.fill.discard()
+	}
+	return err
+}
+
+// isContentHash reports whether name is a SHA-256 hex digest.
+func isContentHash(name string) bool {
+	if len(name) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(name)
+	return err == nil
+}
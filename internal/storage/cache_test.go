@@ -0,0 +1,169 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// remoteBackend is an in-memory backend counting the reads it serves.
+type remoteBackend struct {
+	Backend
+	blobs     map[string][]byte
+	retrieves int
+}
+
+func newRemoteBackend() *remoteBackend {
+	return &remoteBackend{blobs: make(map[string][]byte)}
+}
+
+func (b *remoteBackend) Store(ctx context.Context, reader io.Reader, size int64) (string, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	contentHash := hex.EncodeToString(sum[:])
+	b.blobs[contentHash] = data
+	return contentHash, nil
+}
+
+func (b *remoteBackend) Retrieve(ctx context.Context, contentHash string) (io.ReadCloser, error) {
+	data, ok := b.blobs[contentHash]
+	if !ok {
+		return nil, ErrBlobNotFound
+	}
+	b.retrieves++
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (b *remoteBackend) Delete(ctx context.Context, contentHash string) error {
+	delete(b.blobs, contentHash)
+	return nil
+}
+
+// put stores data in the remote directly, bypassing the cache.
+func (b *remoteBackend) put(data string) string {
+	contentHash, _ := b.Store(context.Background(), strings.NewReader(data), int64(len(data)))
+	return contentHash
+}
+
+func newTestCachingBackend(t *testing.T, remote Backend, dir string, maxSize int64) *CachingBackend {
+	t.Helper()
+	backend, err := NewCachingBackend(remote, CacheConfig{Dir: dir, MaxSize: maxSize}, zerolog.Nop())
+	require.NoError(t, err)
+	return backend
+}
+
+func readBlob(t *testing.T, backend Backend, contentHash string) string {
+	t.Helper()
+	reader, err := backend.Retrieve(context.Background(), contentHash)
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.NoError(t, reader.Close())
+	return string(data)
+}
+
+func TestCachingBackend_SecondReadServedFromCache(t *testing.T) {
+	remote := newRemoteBackend()
+	backend := newTestCachingBackend(t, remote, t.TempDir(), 1024)
+	contentHash := remote.put("cold content")
+
+	assert.Equal(t, "cold content", readBlob(t, backend, contentHash))
+	assert.Equal(t, 1, remote.retrieves)
+
+	assert.Equal(t, "cold content", readBlob(t, backend, contentHash))
+	assert.Equal(t, 1, remote.retrieves, "second read must not fetch from the remote")
+
+	reader, err := backend.RetrieveRange(context.Background(), contentHash, 5, 4)
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	reader.Close()
+	assert.Equal(t, "cont", string(data))
+	assert.Equal(t, 1, remote.retrieves)
+}
+
+func TestCachingBackend_StoreWritesThrough(t *testing.T) {
+	remote := newRemoteBackend()
+	backend := newTestCachingBackend(t, remote, t.TempDir(), 1024)
+
+	contentHash, err := backend.Store(context.Background(), strings.NewReader("fresh"), 5)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("fresh"), remote.blobs[contentHash])
+
+	assert.Equal(t, "fresh", readBlob(t, backend, contentHash))
+	assert.Equal(t, 0, remote.retrieves)
+}
+
+func TestCachingBackend_PartialOrCorruptReadsNotCached(t *testing.T) {
+	remote := newRemoteBackend()
+	backend := newTestCachingBackend(t, remote, t.TempDir(), 1024)
+	contentHash := remote.put("abandoned early")
+
+	reader, err := backend.Retrieve(context.Background(), contentHash)
+	require.NoError(t, err)
+	_, err = reader.Read(make([]byte, 4))
+	require.NoError(t, err)
+	require.NoError(t, reader.Close())
+
+	corruptHash := remote.put("original")
+	remote.blobs[corruptHash] = []byte("bit rot!")
+	readBlob(t, backend, corruptHash)
+
+	readBlob(t, backend, contentHash)
+	readBlob(t, backend, corruptHash)
+	assert.Equal(t, 4, remote.retrieves)
+}
+
+func TestCachingBackend_EvictsLeastRecentlyUsed(t *testing.T) {
+	remote := newRemoteBackend()
+	backend := newTestCachingBackend(t, remote, t.TempDir(), 20)
+	first := remote.put("0123456789")
+	second := remote.put("abcdefghij")
+	third := remote.put("ABCDEFGHIJ")
+
+	readBlob(t, backend, first)
+	readBlob(t, backend, second)
+	readBlob(t, backend, first) // second is now least recently used
+	readBlob(t, backend, third)
+	require.Equal(t, 3, remote.retrieves)
+
+	readBlob(t, backend, first)
+	readBlob(t, backend, third)
+	assert.Equal(t, 3, remote.retrieves)
+	readBlob(t, backend, second)
+	assert.Equal(t, 4, remote.retrieves)
+
+	// Blobs larger than the cache pass through uncached
+	large := remote.put(strings.Repeat("x", 21))
+	readBlob(t, backend, large)
+	readBlob(t, backend, large)
+	assert.Equal(t, 6, remote.retrieves)
+}
+
+func TestCachingBackend_KeepsCacheAcrossRestarts(t *testing.T) {
+	remote := newRemoteBackend()
+	dir := t.TempDir()
+	contentHash := remote.put("persisted")
+
+	readBlob(t, newTestCachingBackend(t, remote, dir, 1024), contentHash)
+	require.Equal(t, 1, remote.retrieves)
+
+	backend := newTestCachingBackend(t, remote, dir, 1024)
+	assert.Equal(t, "persisted", readBlob(t, backend, contentHash))
+	assert.Equal(t, 1, remote.retrieves)
+
+	require.NoError(t, backend.Delete(context.Background(), contentHash))
+	_, err := backend.Retrieve(context.Background(), contentHash)
+	assert.True(t, IsNotFound(err))
+}
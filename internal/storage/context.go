@@ -0,0 +1,20 @@
+package storage
+
+import "context"
+
+// contentTypeKey is the context key for the content type of a blob being stored.
+type contentTypeKey struct{}
+
+// WithContentType returns a context carrying the content type of the blob
+// passed to Store. Backends that store content differently by type, such as
+// compressing backends, read it with ContentTypeFromContext.
+func WithContentType(ctx context.Context, contentType string) context.Context {
+	return context.WithValue(ctx, contentTypeKey{}, contentType)
+}
+
+// ContentTypeFromContext returns the content type set by WithContentType,
+// or an empty string if none was set.
+func ContentTypeFromContext(ctx context.Context) string {
+	contentType, _ := ctx.Value(contentTypeKey{}).(string)
+	return contentType
+}
@@ -0,0 +1,514 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/prn-tf/alexander-storage/internal/delta"
+)
+
+// DeltaLink records that a blob is stored as a delta against a base blob:
+// its content is the base blob rewritten by the instructions, with inserted
+// bytes read from a separate data blob.
+type DeltaLink struct {
+	// ContentHash is the hash of the blob the delta rebuilds.
+	ContentHash string
+
+	// BaseHash is the hash of the blob copied from. It may itself be a delta.
+	BaseHash string
+
+	// DataHash is the hash of the blob holding the inserted bytes, in the
+	// order the instructions insert them.
+	DataHash string
+
+	// DataPath is the storage path of the data blob, used when its blob
+	// record is created.
+	DataPath string
+
+	// Depth is the number of deltas applied to rebuild the blob from a
+	// full blob; a delta against a full blob has depth 1.
+	Depth int
+
+	// Size is the size of the rebuilt blob.
+	Size int64
+
+	// DataSize is the size of the data blob.
+	DataSize int64
+
+	// Instructions are the copy/insert operations rebuilding the blob.
+	Instructions []delta.Instruction
+
+	// CreatedAt is when the delta was stored.
+	CreatedAt time.Time
+}
+
+// DeltaChainStore persists delta links. A link holds a blob reference on its
+// base and data blobs, so garbage collection keeps them while it exists.
+type DeltaChainStore interface {
+	// GetDelta returns the link of a blob stored as a delta.
+	// Returns ErrDeltaNotFound if the blob is stored in full.
+	GetDelta(ctx context.Context, contentHash string) (*DeltaLink, error)
+
+	// CreateDelta records a link and takes a reference on its base and data
+	// blobs, creating the data blob record if needed. It returns false
+	// without changes if the blob already has a link, and ErrBlobNotFound if
+	// the base blob has no record.
+	CreateDelta(ctx context.Context, link *DeltaLink) (created bool, err error)
+
+	// DeleteDelta removes the link of a blob and releases its references.
+	// Returns ErrDeltaNotFound if the blob is stored in full.
+	DeleteDelta(ctx context.Context, contentHash string) error
+
+	// HasDeltas reports whether any link uses the blob as its base.
+	HasDeltas(ctx context.Context, baseHash string) (bool, error)
+}
+
+// DeltaConfig configures a DeltaBackend.
+type DeltaConfig struct {
+	// MaxChainDepth caps the deltas applied to rebuild a blob. A blob that
+	// would exceed it is kept in full and starts a new chain.
+	MaxChainDepth int
+
+	// MinSavings is the smallest fraction of a blob (0.0-1.0) a delta must
+	// save for the blob to be stored as a delta.
+	MinSavings float64
+
+	// MaxSize is the largest blob stored as a delta. Zero means no limit.
+	MaxSize int64
+
+	// TempDir holds blobs rebuilt from deltas while they are read, and bases
+	// the wrapped backend cannot seek in. Empty means the system default.
+	TempDir string
+}
+
+// DefaultDeltaConfig returns the default delta configuration.
+func DefaultDeltaConfig() DeltaConfig {
+	return DeltaConfig{
+		MaxChainDepth: 10,
+		MinSavings:    0.2,
+		MaxSize:       5 * 1024 * 1024 * 1024,
+	}
+}
+
+// DeltaBackend stores blobs that are similar to another blob, typically a
+// new version of an object, as a delta against it. Store always writes full
+// blobs; Deltify later replaces a full blob by a delta against a base blob.
+// Reads of a blob missing from the wrapped backend rebuild it from its delta
+// into a temporary file and are checked against its hash. Blobs are streamed
+// throughout, so memory use does not grow with their size.
+type DeltaBackend struct {
+	Backend
+	chains   DeltaChainStore
+	computer *delta.Computer
+	applier  *delta.Applier
+	config   DeltaConfig
+	logger   zerolog.Logger
+}
+
+// NewDeltaBackend creates a DeltaBackend around backend, splitting blobs into
+// chunks with chunker to find the content they share.
+func NewDeltaBackend(backend Backend, chains DeltaChainStore, chunker delta.Chunker, config DeltaConfig, logger zerolog.Logger) *DeltaBackend {
+	if config.MaxChainDepth < 1 {
+		config.MaxChainDepth = 1
+	}
+	return &DeltaBackend{
+		Backend:  backend,
+		chains:   chains,
+		computer: delta.NewComputer(chunker),
+		applier:  delta.NewApplier(),
+		config:   config,
+		logger:   logger.With().Str("component", "storage-delta").Logger(),
+	}
+}
+
+// SetMonitor sets the monitor that records delta savings and
+// reconstruction failures.
+func (b *DeltaBackend) SetMonitor(monitor *delta.Monitor) {
+	b.computer.SetMonitor(monitor)
+	b.applier.SetMonitor(monitor)
+}
+
+// Deltify stores the blob contentHash as a delta against the blob baseHash if
+// that saves enough space, and removes its full copy. It reports whether a
+// delta was stored. A blob already stored as a delta, used as the base of
+// another delta, too large, or too deep in its chain is left in full.
+func (b *DeltaBackend) Deltify(ctx context.Context, baseHash, contentHash string) (bool, error) {
+	if baseHash == contentHash {
+		return false, nil
+	}
+
+	if _, err := b.chains.GetDelta(ctx, contentHash); err == nil {
+		return false, nil
+	} else if !errors.Is(err, ErrDeltaNotFound) {
+		return false, fmt.Errorf("failed to get delta: %w", err)
+	}
+
+	// Rebuilding a base from its dependents would make chains circular
+	hasDeltas, err := b.chains.HasDeltas(ctx, contentHash)
+	if err != nil {
+		return false, fmt.Errorf("failed to check delta dependents: %w", err)
+	}
+	if hasDeltas {
+		return false, nil
+	}
+
+	depth := 1
+	baseLink, err := b.chains.GetDelta(ctx, baseHash)
+	switch {
+	case err == nil:
+		depth = baseLink.Depth + 1
+	case !errors.Is(err, ErrDeltaNotFound):
+		return false, fmt.Errorf("failed to get delta: %w", err)
+	}
+	if depth > b.config.MaxChainDepth {
+		// Too deep: the blob stays full and later versions chain from it
+		return false, nil
+	}
+
+	for _, hash := range []string{contentHash, baseHash} {
+		if tooLarge, err := b.tooLarge(ctx, hash); err != nil || tooLarge {
+			return false, err
+		}
+	}
+
+	d, err := b.compute(ctx, baseHash, contentHash)
+	if err != nil {
+		return false, err
+	}
+	if d.TotalSize == 0 || d.SavingsRatio < b.config.MinSavings {
+		return false, nil
+	}
+
+	// Never drop the full copy unless the delta rebuilds it exactly
+	dataHash, err := b.verify(ctx, baseHash, contentHash, d)
+	if err != nil {
+		return false, err
+	}
+	if dataHash == contentHash {
+		return false, nil
+	}
+
+	data := b.extract(ctx, contentHash, d)
+	storedHash, err := b.Backend.Store(ctx, data, d.DeltaSize)
+	data.Close()
+	if err != nil {
+		return false, fmt.Errorf("failed to store delta data: %w", err)
+	}
+	if storedHash != dataHash {
+		return false, fmt.Errorf("delta data of %s changed while it was stored", contentHash)
+	}
+
+	created, err := b.chains.CreateDelta(ctx, &DeltaLink{
+		ContentHash:  contentHash,
+		BaseHash:     baseHash,
+		DataHash:     dataHash,
+		DataPath:     b.Backend.GetPath(dataHash),
+		Depth:        depth,
+		Size:         d.TotalSize,
+		DataSize:     d.DeltaSize,
+		Instructions: d.Instructions,
+		CreatedAt:    time.Now().UTC(),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to create delta: %w", err)
+	}
+	if !created {
+		return false, nil
+	}
+
+	if err := b.Backend.Delete(ctx, contentHash); err != nil && !IsNotFound(err) {
+		// The delta is recorded, so the full copy only wastes space
+		b.logger.Warn().Err(err).Str("content_hash", contentHash).Msg("failed to remove blob stored as delta")
+	}
+
+	b.logger.Debug().
+		Str("content_hash", contentHash).
+		Str("base_hash", baseHash).
+		Int("depth", depth).
+		Int64("size", d.TotalSize).
+		Int64("delta_size", d.DeltaSize).
+		Msg("blob stored as delta")
+	return true, nil
+}
+
+// Retrieve retrieves content from the wrapped backend, or rebuilds it from
+// its delta.
+func (b *DeltaBackend) Retrieve(ctx context.Context, contentHash string) (io.ReadCloser, error) {
+	reader, err := b.Backend.Retrieve(ctx, contentHash)
+	if !IsNotFound(err) {
+		return reader, err
+	}
+
+	return b.reconstruct(ctx, contentHash)
+}
+
+// RetrieveRange retrieves a byte range from the wrapped backend, or from the
+// blob rebuilt from its delta. It fails if the wrapped backend does not
+// support range reads.
+func (b *DeltaBackend) RetrieveRange(ctx context.Context, contentHash string, offset, length int64) (io.ReadCloser, error) {
+	ranged, ok := b.Backend.(interface {
+		RetrieveRange(ctx context.Context, contentHash string, offset, length int64) (io.ReadCloser, error)
+	})
+	if !ok {
+		return nil, fmt.Errorf("storage backend does not support range requests")
+	}
+	reader, err := ranged.RetrieveRange(ctx, contentHash, offset, length)
+	if !IsNotFound(err) {
+		return reader, err
+	}
+
+	file, err := b.reconstruct(ctx, contentHash)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to stat rebuilt blob: %w", err)
+	}
+	size := info.Size()
+	offset = min(max(offset, 0), size)
+	if length <= 0 {
+		length = size - offset
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to seek to offset: %w", err)
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(file, length), file}, nil
+}
+
+// Delete removes content and releases its delta, if it has one.
+func (b *DeltaBackend) Delete(ctx context.Context, contentHash string) error {
+	err := b.chains.DeleteDelta(ctx, contentHash)
+	if err != nil && !errors.Is(err, ErrDeltaNotFound) {
+		return fmt.Errorf("failed to delete delta: %w", err)
+	}
+	deltified := err == nil
+
+	if err := b.Backend.Delete(ctx, contentHash); err != nil && !(deltified && IsNotFound(err)) {
+		return err
+	}
+	return nil
+}
+
+// Exists checks if content is stored in full or as a delta.
+func (b *DeltaBackend) Exists(ctx context.Context, contentHash string) (bool, error) {
+	exists, err := b.Backend.Exists(ctx, contentHash)
+	if err != nil || exists {
+		return exists, err
+	}
+
+	if _, err := b.chains.GetDelta(ctx, contentHash); err != nil {
+		if errors.Is(err, ErrDeltaNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get delta: %w", err)
+	}
+	return true, nil
+}
+
+// GetSize returns the size of content stored in full or as a delta.
+func (b *DeltaBackend) GetSize(ctx context.Context, contentHash string) (int64, error) {
+	size, err := b.Backend.GetSize(ctx, contentHash)
+	if !IsNotFound(err) {
+		return size, err
+	}
+
+	link, err := b.chains.GetDelta(ctx, contentHash)
+	if err != nil {
+		if errors.Is(err, ErrDeltaNotFound) {
+			return 0, ErrBlobNotFound
+		}
+		return 0, fmt.Errorf("failed to get delta: %w", err)
+	}
+	return link.Size, nil
+}
+
+// Unwrap returns the wrapped backend.
+func (b *DeltaBackend) Unwrap() Backend {
+	return b.Backend
+}
+
+// reconstruct rebuilds a blob from its delta into a temporary file, checks
+// it against its hash and returns the file positioned at its start.
+// Returns ErrBlobNotFound if the blob is not stored as a delta.
+func (b *DeltaBackend) reconstruct(ctx context.Context, contentHash string) (*tempFile, error) {
+	link, err := b.chains.GetDelta(ctx, contentHash)
+	if err != nil {
+		if errors.Is(err, ErrDeltaNotFound) {
+			return nil, ErrBlobNotFound
+		}
+		return nil, fmt.Errorf("failed to get delta: %w", err)
+	}
+
+	// A missing base or data blob means a broken chain, not a missing blob
+	base, err := b.open(ctx, link.BaseHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read delta base %s: %v", link.BaseHash, err)
+	}
+	defer base.Close()
+	data, err := b.Backend.Retrieve(ctx, link.DataHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read delta data %s: %v", link.DataHash, err)
+	}
+	defer data.Close()
+
+	file, err := b.createTemp()
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.New()
+	err = b.applier.ApplyTo(ctx, base, &delta.Delta{
+		Instructions: link.Instructions,
+		TotalSize:    link.Size,
+	}, data, io.MultiWriter(file, sum))
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to apply delta: %w", err)
+	}
+	if hex.EncodeToString(sum.Sum(nil)) != contentHash {
+		file.Close()
+		return nil, fmt.Errorf("blob %s rebuilt from its delta does not match its hash", contentHash)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to rewind rebuilt blob: %w", err)
+	}
+	return file, nil
+}
+
+// compute chunks the base and target blobs and computes the delta between
+// them.
+func (b *DeltaBackend) compute(ctx context.Context, baseHash, contentHash string) (*delta.Delta, error) {
+	base, err := b.Retrieve(ctx, baseHash)
+	if err != nil {
+		return nil, err
+	}
+	defer base.Close()
+	target, err := b.Retrieve(ctx, contentHash)
+	if err != nil {
+		return nil, err
+	}
+	defer target.Close()
+
+	d, err := b.computer.Compute(ctx, base, target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute delta: %w", err)
+	}
+	return d, nil
+}
+
+// verify rebuilds the blob contentHash from its base and the data d inserts,
+// without storing either, and returns the hash of the data. It fails unless
+// the rebuilt blob matches contentHash.
+func (b *DeltaBackend) verify(ctx context.Context, baseHash, contentHash string, d *delta.Delta) (string, error) {
+	base, err := b.open(ctx, baseHash)
+	if err != nil {
+		return "", err
+	}
+	defer base.Close()
+
+	data := b.extract(ctx, contentHash, d)
+	defer data.Close()
+
+	dataSum := sha256.New()
+	rebuiltSum := sha256.New()
+	err = b.applier.ApplyTo(ctx, base, &delta.Delta{
+		Instructions: d.Instructions,
+		TotalSize:    d.TotalSize,
+	}, io.TeeReader(data, dataSum), rebuiltSum)
+	if err != nil {
+		return "", fmt.Errorf("failed to apply delta: %w", err)
+	}
+	if hex.EncodeToString(rebuiltSum.Sum(nil)) != contentHash {
+		return "", fmt.Errorf("delta of %s does not rebuild it", contentHash)
+	}
+	return hex.EncodeToString(dataSum.Sum(nil)), nil
+}
+
+// extract streams the bytes d inserts, read from the blob contentHash. The
+// caller must close the returned reader.
+func (b *DeltaBackend) extract(ctx context.Context, contentHash string, d *delta.Delta) io.ReadCloser {
+	reader, writer := io.Pipe()
+	go func() {
+		target, err := b.Retrieve(ctx, contentHash)
+		if err == nil {
+			_, err = b.computer.WriteDeltaData(ctx, target, d, writer)
+			target.Close()
+		}
+		writer.CloseWithError(err)
+	}()
+	return reader
+}
+
+// open opens a blob stored in full or as a delta for random access, copying
+// it to a temporary file if the wrapped backend cannot seek in it.
+func (b *DeltaBackend) open(ctx context.Context, contentHash string) (io.ReadSeekCloser, error) {
+	reader, err := b.Retrieve(ctx, contentHash)
+	if err != nil {
+		return nil, err
+	}
+	if seeker, ok := reader.(io.ReadSeekCloser); ok {
+		return seeker, nil
+	}
+	defer reader.Close()
+
+	file, err := b.createTemp()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(file, reader); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to rewind blob copy: %w", err)
+	}
+	return file, nil
+}
+
+// tempFile is a temporary file removed when it is closed.
+type tempFile struct {
+	*os.File
+}
+
+// createTemp creates a temporary file in the configured directory.
+func (b *DeltaBackend) createTemp() (*tempFile, error) {
+	file, err := os.CreateTemp(b.config.TempDir, "delta-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	return &tempFile{File: file}, nil
+}
+
+// Close closes and removes the file.
+func (f *tempFile) Close() error {
+	err := f.File.Close()
+	os.Remove(f.Name())
+	return err
+}
+
+// tooLarge reports whether a blob is larger than delta storage handles.
+func (b *DeltaBackend) tooLarge(ctx context.Context, contentHash string) (bool, error) {
+	if b.config.MaxSize <= 0 {
+		return false, nil
+	}
+	size, err := b.GetSize(ctx, contentHash)
+	if err != nil {
+		return false, err
+	}
+	return size > b.config.MaxSize, nil
+}
@@ -0,0 +1,239 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/delta"
+)
+
+func (b *memoryBackend) GetPath(contentHash string) string {
+	return "/memory/" + contentHash
+}
+
+// memoryDeltaChains is an in-memory DeltaChainStore counting the blob
+// references its links hold.
+type memoryDeltaChains struct {
+	mu    sync.Mutex
+	links map[string]*DeltaLink
+	refs  map[string]int
+}
+
+func newMemoryDeltaChains() *memoryDeltaChains {
+	return &memoryDeltaChains{links: make(map[string]*DeltaLink), refs: make(map[string]int)}
+}
+
+func (s *memoryDeltaChains) GetDelta(ctx context.Context, contentHash string) (*DeltaLink, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	link, ok := s.links[contentHash]
+	if !ok {
+		return nil, ErrDeltaNotFound
+	}
+	return link, nil
+}
+
+func (s *memoryDeltaChains) CreateDelta(ctx context.Context, link *DeltaLink) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.links[link.ContentHash]; ok {
+		return false, nil
+	}
+	s.links[link.ContentHash] = link
+	s.refs[link.BaseHash]++
+	s.refs[link.DataHash]++
+	return true, nil
+}
+
+func (s *memoryDeltaChains) DeleteDelta(ctx context.Context, contentHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	link, ok := s.links[contentHash]
+	if !ok {
+		return ErrDeltaNotFound
+	}
+	delete(s.links, contentHash)
+	s.refs[link.BaseHash]--
+	s.refs[link.DataHash]--
+	return nil
+}
+
+func (s *memoryDeltaChains) HasDeltas(ctx context.Context, baseHash string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, link := range s.links {
+		if link.BaseHash == baseHash {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func newTestDeltaBackend(inner Backend, chains DeltaChainStore, maxChainDepth int) *DeltaBackend {
+	chunker := delta.NewFastCDC(delta.FastCDCConfig{
+		MinSize:            2 * 1024,
+		AvgSize:            8 * 1024,
+		MaxSize:            64 * 1024,
+		NormalizationLevel: 2,
+	})
+	config := DefaultDeltaConfig()
+	config.MaxChainDepth = maxChainDepth
+	return NewDeltaBackend(inner, chains, chunker, config, zerolog.Nop())
+}
+
+func storeBytes(t *testing.T, backend Backend, data []byte) string {
+	t.Helper()
+	contentHash, err := backend.Store(context.Background(), bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	return contentHash
+}
+
+// edit returns a copy of data with a few bytes overwritten at offset.
+func edit(data []byte, offset int) []byte {
+	edited := bytes.Clone(data)
+	copy(edited[offset:], "a small edit to a large object")
+	return edited
+}
+
+func TestDeltaBackend_SmallEditStoresOnlyDelta(t *testing.T) {
+	ctx := context.Background()
+	inner := newMemoryBackend()
+	chains := newMemoryDeltaChains()
+	backend := newTestDeltaBackend(inner, chains, 10)
+
+	original := make([]byte, 4*1024*1024)
+	rand.New(rand.NewSource(1)).Read(original)
+	edited := edit(original, 2*1024*1024)
+
+	baseHash := storeBytes(t, backend, original)
+	contentHash := storeBytes(t, backend, edited)
+
+	stored, err := backend.Deltify(ctx, baseHash, contentHash)
+	require.NoError(t, err)
+	require.True(t, stored)
+
+	// Only the base and the edited chunks remain in the wrapped backend
+	link, err := chains.GetDelta(ctx, contentHash)
+	require.NoError(t, err)
+	assert.Equal(t, 1, link.Depth)
+	assert.Less(t, link.DataSize, int64(len(edited)/10))
+	assert.Len(t, inner.blobs, 2)
+	assert.NotContains(t, inner.blobs, contentHash)
+	assert.Len(t, inner.blobs[link.DataHash], int(link.DataSize))
+	assert.Equal(t, 1, chains.refs[baseHash])
+	assert.Equal(t, 1, chains.refs[link.DataHash])
+
+	assert.Equal(t, string(edited), readBlob(t, backend, contentHash))
+	exists, err := backend.Exists(ctx, contentHash)
+	require.NoError(t, err)
+	assert.True(t, exists)
+	size, err := backend.GetSize(ctx, contentHash)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(edited)), size)
+
+	reader, err := backend.RetrieveRange(ctx, contentHash, 2*1024*1024, 30)
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "a small edit to a large object", string(data))
+}
+
+func TestDeltaBackend_DissimilarContentStaysFull(t *testing.T) {
+	ctx := context.Background()
+	inner := newMemoryBackend()
+	chains := newMemoryDeltaChains()
+	backend := newTestDeltaBackend(inner, chains, 10)
+
+	random := rand.New(rand.NewSource(2))
+	first := make([]byte, 256*1024)
+	random.Read(first)
+	second := make([]byte, 256*1024)
+	random.Read(second)
+
+	baseHash := storeBytes(t, backend, first)
+	contentHash := storeBytes(t, backend, second)
+
+	stored, err := backend.Deltify(ctx, baseHash, contentHash)
+	require.NoError(t, err)
+	assert.False(t, stored)
+	assert.Contains(t, inner.blobs, contentHash)
+	assert.Empty(t, chains.links)
+}
+
+func TestDeltaBackend_ChainDepthCapMaterializesFullBlob(t *testing.T) {
+	ctx := context.Background()
+	inner := newMemoryBackend()
+	chains := newMemoryDeltaChains()
+	backend := newTestDeltaBackend(inner, chains, 2)
+
+	version := make([]byte, 1024*1024)
+	rand.New(rand.NewSource(3)).Read(version)
+	versions := [][]byte{version}
+	hashes := []string{storeBytes(t, backend, version)}
+	for i := 1; i < 5; i++ {
+		version = edit(version, i*128*1024)
+		versions = append(versions, version)
+		hashes = append(hashes, storeBytes(t, backend, version))
+	}
+
+	var depths []int
+	for i := 1; i < len(hashes); i++ {
+		stored, err := backend.Deltify(ctx, hashes[i-1], hashes[i])
+		require.NoError(t, err)
+		depth := 0
+		if stored {
+			link, err := chains.GetDelta(ctx, hashes[i])
+			require.NoError(t, err)
+			depth = link.Depth
+		}
+		depths = append(depths, depth)
+	}
+
+	// The third version would be three deltas deep, so it stays full and the
+	// next version chains from it
+	assert.Equal(t, []int{1, 2, 0, 1}, depths)
+	assert.Contains(t, inner.blobs, hashes[3])
+	for i, contentHash := range hashes {
+		assert.Equal(t, string(versions[i]), readBlob(t, backend, contentHash))
+	}
+}
+
+func TestDeltaBackend_DeleteReleasesDelta(t *testing.T) {
+	ctx := context.Background()
+	inner := newMemoryBackend()
+	chains := newMemoryDeltaChains()
+	backend := newTestDeltaBackend(inner, chains, 10)
+
+	original := make([]byte, 1024*1024)
+	rand.New(rand.NewSource(4)).Read(original)
+	baseHash := storeBytes(t, backend, original)
+	contentHash := storeBytes(t, backend, edit(original, 1000))
+
+	stored, err := backend.Deltify(ctx, baseHash, contentHash)
+	require.NoError(t, err)
+	require.True(t, stored)
+	link, err := chains.GetDelta(ctx, contentHash)
+	require.NoError(t, err)
+
+	// A base with dependents is never turned into a delta itself
+	stored, err = backend.Deltify(ctx, contentHash, baseHash)
+	require.NoError(t, err)
+	assert.False(t, stored)
+
+	require.NoError(t, backend.Delete(ctx, contentHash))
+	assert.Empty(t, chains.links)
+	assert.Equal(t, 0, chains.refs[baseHash])
+	assert.Equal(t, 0, chains.refs[link.DataHash])
+
+	_, err = backend.Retrieve(ctx, contentHash)
+	assert.ErrorIs(t, err, ErrBlobNotFound)
+	assert.ErrorIs(t, backend.Delete(ctx, contentHash), ErrBlobNotFound)
+}
@@ -0,0 +1,178 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/rs/zerolog"
+)
+
+// DualWriteBackend migrates blobs from an old backend to a new one without
+// downtime. During the migration window every blob is written to both
+// backends, so either can serve the data if the migration is rolled back.
+// Reads prefer the new backend and fall back to the old one for blobs that
+// have not been backfilled yet; Backfill copies those across.
+//
+// Once every blob is backfilled the old backend can be retired by pointing
+// the server at the new backend alone.
+type DualWriteBackend struct {
+	newBackend Backend
+	oldBackend Backend
+	logger     zerolog.Logger
+}
+
+// NewDualWriteBackend creates a DualWriteBackend migrating from oldBackend to newBackend.
+func NewDualWriteBackend(newBackend, oldBackend Backend, logger zerolog.Logger) *DualWriteBackend {
+	return &DualWriteBackend{
+		newBackend: newBackend,
+		oldBackend: oldBackend,
+		logger:     logger.With().Str("component", "storage-dual-write").Logger(),
+	}
+}
+
+// Store stores content in the new backend, then copies it to the old one.
+// The store fails unless the content lands in both backends. A blob already
+// written to the new backend is left there on failure, as it may be shared
+// with existing objects; an unreferenced blob is collected like any other.
+func (b *DualWriteBackend) Store(ctx context.Context, reader io.Reader, size int64) (string, error) {
+	contentHash, err := b.newBackend.Store(ctx, reader, size)
+	if err != nil {
+		return "", err
+	}
+	if err := b.copyBlob(ctx, b.newBackend, b.oldBackend, contentHash); err != nil {
+		return "", fmt.Errorf("failed to write blob to old backend: %w", err)
+	}
+	return contentHash, nil
+}
+
+// Retrieve retrieves content from the new backend, falling back to the old
+// one if the blob has not been backfilled.
+func (b *DualWriteBackend) Retrieve(ctx context.Context, contentHash string) (io.ReadCloser, error) {
+	reader, err := b.newBackend.Retrieve(ctx, contentHash)
+	if IsNotFound(err) {
+		return b.oldBackend.Retrieve(ctx, contentHash)
+	}
+	return reader, err
+}
+
+// RetrieveRange retrieves a byte range like Retrieve. It fails if either
+// backend does not support range reads.
+func (b *DualWriteBackend) RetrieveRange(ctx context.Context, contentHash string, offset, length int64) (io.ReadCloser, error) {
+	type rangeReader interface {
+		RetrieveRange(ctx context.Context, contentHash string, offset, length int64) (io.ReadCloser, error)
+	}
+	newRanged, newOK := b.newBackend.(rangeReader)
+	oldRanged, oldOK := b.oldBackend.(rangeReader)
+	if !newOK || !oldOK {
+		return nil, fmt.Errorf("storage backend does not support range requests")
+	}
+
+	reader, err := newRanged.RetrieveRange(ctx, contentHash, offset, length)
+	if IsNotFound(err) {
+		return oldRanged.RetrieveRange(ctx, contentHash, offset, length)
+	}
+	return reader, err
+}
+
+// Delete removes content from both backends. It returns ErrBlobNotFound only
+// if neither backend holds the blob.
+func (b *DualWriteBackend) Delete(ctx context.Context, contentHash string) error {
+	newErr := b.newBackend.Delete(ctx, contentHash)
+	oldErr := b.oldBackend.Delete(ctx, contentHash)
+	switch {
+	case newErr != nil && !IsNotFound(newErr):
+		return newErr
+	case oldErr != nil && !IsNotFound(oldErr):
+		return oldErr
+	case newErr != nil && oldErr != nil:
+		return ErrBlobNotFound
+	}
+	return nil
+}
+
+// Exists checks whether either backend holds the content.
+func (b *DualWriteBackend) Exists(ctx context.Context, contentHash string) (bool, error) {
+	exists, err := b.newBackend.Exists(ctx, contentHash)
+	if err != nil || exists {
+		return exists, err
+	}
+	return b.oldBackend.Exists(ctx, contentHash)
+}
+
+// GetSize returns the size of stored content, falling back to the old backend.
+func (b *DualWriteBackend) GetSize(ctx context.Context, contentHash string) (int64, error) {
+	size, err := b.newBackend.GetSize(ctx, contentHash)
+	if IsNotFound(err) {
+		return b.oldBackend.GetSize(ctx, contentHash)
+	}
+	return size, err
+}
+
+// GetPath returns the path of the content in the new backend.
+func (b *DualWriteBackend) GetPath(contentHash string) string {
+	return b.newBackend.GetPath(contentHash)
+}
+
+// HealthCheck verifies both backends.
+func (b *DualWriteBackend) HealthCheck(ctx context.Context) error {
+	if err := b.newBackend.HealthCheck(ctx); err != nil {
+		return fmt.Errorf("new backend: %w", err)
+	}
+	if err := b.oldBackend.HealthCheck(ctx); err != nil {
+		return fmt.Errorf("old backend: %w", err)
+	}
+	return nil
+}
+
+// Backfill copies a blob from the old backend to the new one unless the new
+// backend already holds it. It reports whether the blob was copied, and
+// returns ErrBlobNotFound if neither backend holds it.
+func (b *DualWriteBackend) Backfill(ctx context.Context, contentHash string) (bool, error) {
+	exists, err := b.newBackend.Exists(ctx, contentHash)
+	if err != nil {
+		return false, err
+	}
+	if exists {
+		return false, nil
+	}
+	if err := b.copyBlob(ctx, b.oldBackend, b.newBackend, contentHash); err != nil {
+		return false, err
+	}
+
+	b.logger.Debug().Str("content_hash", contentHash).Msg("blob backfilled to new backend")
+	return true, nil
+}
+
+// copyBlob copies a blob from src to dst, unless dst already holds it, and
+// checks the copy has the same content hash.
+func (b *DualWriteBackend) copyBlob(ctx context.Context, src, dst Backend, contentHash string) error {
+	exists, err := dst.Exists(ctx, contentHash)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	size, err := src.GetSize(ctx, contentHash)
+	if err != nil {
+		return err
+	}
+	reader, err := src.Retrieve(ctx, contentHash)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	copied, err := dst.Store(ctx, reader, size)
+	if err != nil {
+		return err
+	}
+	if copied != contentHash {
+		// The source is corrupt. The copy is left alone: content stored under
+		// its own hash may be shared with another blob
+		return fmt.Errorf("%w: copy of %s has hash %s", ErrInvalidContentHash, contentHash, copied)
+	}
+	return nil
+}
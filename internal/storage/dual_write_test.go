@@ -0,0 +1,180 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryBackend is a content-addressed in-memory Backend.
+type memoryBackend struct {
+	Backend
+	mu       sync.Mutex
+	blobs    map[string][]byte
+	storeErr error
+}
+
+func newMemoryBackend() *memoryBackend {
+	return &memoryBackend{blobs: make(map[string][]byte)}
+}
+
+func (b *memoryBackend) Store(ctx context.Context, reader io.Reader, size int64) (string, error) {
+	if b.storeErr != nil {
+		return "", b.storeErr
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	contentHash := hex.EncodeToString(sum[:])
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.blobs[contentHash] = data
+	return contentHash, nil
+}
+
+func (b *memoryBackend) Retrieve(ctx context.Context, contentHash string) (io.ReadCloser, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	data, ok := b.blobs[contentHash]
+	if !ok {
+		return nil, ErrBlobNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (b *memoryBackend) RetrieveRange(ctx context.Context, contentHash string, offset, length int64) (io.ReadCloser, error) {
+	reader, err := b.Retrieve(ctx, contentHash)
+	if err != nil {
+		return nil, err
+	}
+	data, _ := io.ReadAll(reader)
+	return io.NopCloser(bytes.NewReader(data[offset : offset+length])), nil
+}
+
+func (b *memoryBackend) Delete(ctx context.Context, contentHash string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.blobs[contentHash]; !ok {
+		return ErrBlobNotFound
+	}
+	delete(b.blobs, contentHash)
+	return nil
+}
+
+func (b *memoryBackend) Exists(ctx context.Context, contentHash string) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	_, ok := b.blobs[contentHash]
+	return ok, nil
+}
+
+func (b *memoryBackend) GetSize(ctx context.Context, contentHash string) (int64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	data, ok := b.blobs[contentHash]
+	if !ok {
+		return 0, ErrBlobNotFound
+	}
+	return int64(len(data)), nil
+}
+
+func TestDualWriteBackend_StoreWritesBothBackends(t *testing.T) {
+	ctx := context.Background()
+	newBackend, oldBackend := newMemoryBackend(), newMemoryBackend()
+	backend := NewDualWriteBackend(newBackend, oldBackend, zerolog.Nop())
+
+	hash, err := backend.Store(ctx, bytes.NewReader([]byte("payload")), 7)
+	require.NoError(t, err)
+
+	assert.Equal(t, []byte("payload"), newBackend.blobs[hash])
+	assert.Equal(t, []byte("payload"), oldBackend.blobs[hash])
+
+	// A write the old backend cannot take fails, so a rollback loses nothing
+	oldBackend.storeErr = errors.New("disk full")
+	_, err = backend.Store(ctx, bytes.NewReader([]byte("other payload")), 13)
+	require.ErrorContains(t, err, "disk full")
+
+	// Deletes remove the blob from both
+	require.NoError(t, backend.Delete(ctx, hash))
+	assert.Empty(t, oldBackend.blobs)
+	assert.ErrorIs(t, backend.Delete(ctx, hash), ErrBlobNotFound)
+}
+
+func TestDualWriteBackend_ReadsFallBackToOldBackend(t *testing.T) {
+	ctx := context.Background()
+	newBackend, oldBackend := newMemoryBackend(), newMemoryBackend()
+	backend := NewDualWriteBackend(newBackend, oldBackend, zerolog.Nop())
+
+	// Written before the migration, so only the old backend has it
+	hash, err := oldBackend.Store(ctx, bytes.NewReader([]byte("legacy content")), 14)
+	require.NoError(t, err)
+
+	reader, err := backend.Retrieve(ctx, hash)
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "legacy content", string(data))
+
+	reader, err = backend.RetrieveRange(ctx, hash, 7, 7)
+	require.NoError(t, err)
+	data, err = io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "content", string(data))
+
+	size, err := backend.GetSize(ctx, hash)
+	require.NoError(t, err)
+	assert.Equal(t, int64(14), size)
+
+	exists, err := backend.Exists(ctx, hash)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	_, err = backend.Retrieve(ctx, "missing")
+	assert.ErrorIs(t, err, ErrBlobNotFound)
+
+	// Reads prefer the new backend once it holds the blob
+	newBackend.blobs[hash] = []byte("new copy")
+	reader, err = backend.Retrieve(ctx, hash)
+	require.NoError(t, err)
+	data, err = io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "new copy", string(data))
+}
+
+func TestDualWriteBackend_Backfill(t *testing.T) {
+	ctx := context.Background()
+	newBackend, oldBackend := newMemoryBackend(), newMemoryBackend()
+	backend := NewDualWriteBackend(newBackend, oldBackend, zerolog.Nop())
+
+	hash, err := oldBackend.Store(ctx, bytes.NewReader([]byte("legacy content")), 14)
+	require.NoError(t, err)
+
+	copied, err := backend.Backfill(ctx, hash)
+	require.NoError(t, err)
+	assert.True(t, copied)
+	assert.Equal(t, []byte("legacy content"), newBackend.blobs[hash])
+
+	copied, err = backend.Backfill(ctx, hash)
+	require.NoError(t, err)
+	assert.False(t, copied, "backfilled blobs are not copied again")
+
+	_, err = backend.Backfill(ctx, "missing")
+	assert.ErrorIs(t, err, ErrBlobNotFound)
+
+	// A corrupt source blob is not backfilled under its address
+	oldBackend.blobs["corrupt"] = []byte("bit rot")
+	_, err = backend.Backfill(ctx, "corrupt")
+	assert.ErrorIs(t, err, ErrInvalidContentHash)
+	assert.NotContains(t, newBackend.blobs, "corrupt")
+}
@@ -1,6 +1,9 @@
 package storage
 
-import "errors"
+import (
+	"errors"
+	"syscall"
+)
 
 // Storage errors
 var (
@@ -15,9 +18,33 @@ var (
 
 	// ErrInvalidContentHash indicates that the content hash is invalid.
 	ErrInvalidContentHash = errors.New("invalid content hash")
+
+	// ErrTransient marks an error as temporary so the operation may be retried.
+	// Backends wrap it around failures such as remote timeouts.
+	ErrTransient = errors.New("transient storage error")
+
+	// ErrSizeMismatch indicates that stored content was not as long as the
+	// size it was declared with.
+	ErrSizeMismatch = errors.New("content size does not match the expected size")
+
+	// ErrUnsupportedEncryptionScheme indicates that a blob was written with an
+	// encryption scheme the backend cannot decrypt.
+	ErrUnsupportedEncryptionScheme = errors.New("unsupported encryption scheme")
+
+	// ErrDeltaNotFound indicates that a blob is not stored as a delta.
+	ErrDeltaNotFound = errors.New("delta not found")
 )
 
 // IsNotFound returns true if the error is ErrBlobNotFound.
 func IsNotFound(err error) bool {
 	return errors.Is(err, ErrBlobNotFound)
 }
+
+// IsStorageFull returns true if the error means the storage has no space
+// left, either ErrStorageFull or an out-of-space or quota-exceeded error
+// from the operating system.
+func IsStorageFull(err error) bool {
+	return errors.Is(err, ErrStorageFull) ||
+		errors.Is(err, syscall.ENOSPC) ||
+		errors.Is(err, syscall.EDQUOT)
+}
@@ -0,0 +1,453 @@
+package filesystem
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/rs/zerolog"
+
+	"github.com/prn-tf/alexander-storage/internal/storage"
+)
+
+const (
+	// compressionMagic starts the header of every blob CompressedStorage
+	// writes. It is followed by the mode byte, the original size as a
+	// big-endian uint64 and the blob's SHA-256, then the content.
+	compressionMagic = "AXZ\x00"
+
+	// compressionStoredRaw marks content stored as received.
+	compressionStoredRaw byte = 0
+
+	// compressionAlgorithmZstd marks a Zstandard compressed stream.
+	compressionAlgorithmZstd byte = 1
+
+	// compressionHeaderSize is the size of the header before the content.
+	compressionHeaderSize = len(compressionMagic) + 1 + 8 + sha256.Size
+)
+
+// CompressedStorage provides transparent Zstandard compression of blobs.
+// Blobs are still addressed by the hash of their original content, and
+// GetSize and range reads work on the original content.
+//
+// Content whose type is listed in SkipContentTypes, and content that does not
+// shrink when compressed, is stored as received. Every blob written starts
+// with a header recording whether it is compressed, which is only trusted if
+// it also holds the blob's own hash: content can never contain its own hash,
+// so it is never mistaken for a header. Blobs written before compression was
+// enabled have no header and are read as they are.
+type CompressedStorage struct {
+	storage   *Storage
+	level     zstd.EncoderLevel
+	skipTypes []string
+	logger    zerolog.Logger
+}
+
+// CompressedConfig holds configuration for compressed storage.
+type CompressedConfig struct {
+	DataDir string
+	TempDir string
+
+	// Level is the Zstandard level: "fastest", "default", "better" or "best".
+	// Default: "default".
+	Level string
+
+	// SkipContentTypes lists content types that are already compressed and
+	// stored as received. "image/*" matches every image type.
+	SkipContentTypes []string
+}
+
+// NewCompressedStorage creates a new compressed filesystem storage backend.
+func NewCompressedStorage(cfg CompressedConfig, logger zerolog.Logger) (*CompressedStorage, error) {
+	level := zstd.SpeedDefault
+	if cfg.Level != "" {
+		var ok bool
+		if ok, level = zstd.EncoderLevelFromString(cfg.Level); !ok {
+			return nil, fmt.Errorf("unknown compression level %q", cfg.Level)
+		}
+	}
+
+	baseStorage, err := NewStorage(Config{
+		DataDir: cfg.DataDir,
+		TempDir: cfg.TempDir,
+	}, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create base storage: %w", err)
+	}
+
+	skipTypes := make([]string, 0, len(cfg.SkipContentTypes))
+	for _, contentType := range cfg.SkipContentTypes {
+		skipTypes = append(skipTypes, strings.ToLower(strings.TrimSpace(contentType)))
+	}
+
+	logger.Info().
+		Str("data_dir", cfg.DataDir).
+		Str("level", level.String()).
+		Msg("compressed filesystem storage initialized")
+
+	return &CompressedStorage{
+		storage:   baseStorage,
+		level:     level,
+		skipTypes: skipTypes,
+		logger:    logger,
+	}, nil
+}
+
+// Store stores content compressed with Zstandard.
+// The content type set with storage.WithContentType decides whether the
+// content is worth compressing. Returns the content hash of the ORIGINAL
+// (uncompressed) content.
+func (s *CompressedStorage) Store(ctx context.Context, reader io.Reader, size int64) (string, error) {
+	// The hash names the blob and goes in its header, so the content is
+	// spooled to a temp file before it is written into place
+	tempFile, err := os.CreateTemp(s.storage.tempDir, "compress-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tempPath := tempFile.Name()
+	defer func() {
+		tempFile.Close()
+		os.Remove(tempPath)
+	}()
+
+	hasher := sha256.New()
+	written, err := io.Copy(tempFile, io.TeeReader(reader, hasher))
+	if err != nil {
+		return "", fmt.Errorf("failed to write to temp file: %w", err)
+	}
+
+	// Verify size if provided
+	if size > 0 && written != size {
+		return "", fmt.Errorf("%w: expected %d, got %d", storage.ErrSizeMismatch, size, written)
+	}
+
+	hash := hasher.Sum(nil)
+	contentHash := hex.EncodeToString(hash)
+
+	s.storage.shards.Lock(contentHash)
+	defer s.storage.shards.Unlock(contentHash)
+
+	fullPath := storage.ComputePath(s.storage.pathConfig, contentHash)
+
+	// Check if blob already exists (deduplication)
+	if _, err := os.Stat(fullPath); err == nil {
+		s.storage.touch(fullPath)
+		s.logger.Debug().
+			Str("content_hash", contentHash).
+			Msg("compressed blob already exists, skipping storage")
+		return contentHash, nil
+	}
+
+	if err := os.MkdirAll(storage.ComputeDir(s.storage.pathConfig, contentHash), 0755); err != nil {
+		return "", fmt.Errorf("failed to create target directory: %w", err)
+	}
+
+	outputPath := fullPath + ".compressing"
+	defer os.Remove(outputPath)
+
+	mode := compressionStoredRaw
+	if !s.skipsContentType(storage.ContentTypeFromContext(ctx)) {
+		mode = compressionAlgorithmZstd
+	}
+	storedSize, err := s.writeBlob(tempFile, outputPath, mode, hash, written)
+	if err != nil {
+		return "", err
+	}
+
+	// Content that does not shrink is kept as received
+	if mode == compressionAlgorithmZstd && storedSize >= written+int64(compressionHeaderSize) {
+		mode = compressionStoredRaw
+		if storedSize, err = s.writeBlob(tempFile, outputPath, mode, hash, written); err != nil {
+			return "", err
+		}
+	}
+
+	if err := os.Rename(outputPath, fullPath); err != nil {
+		return "", fmt.Errorf("failed to finalize blob: %w", err)
+	}
+
+	s.logger.Debug().
+		Str("content_hash", contentHash).
+		Int64("size", written).
+		Int64("stored_size", storedSize).
+		Bool("compressed", mode == compressionAlgorithmZstd).
+		Msg("blob stored")
+
+	return contentHash, nil
+}
+
+// writeBlob writes the header and the content of src, from its start,
+// to a new file at path and returns the file's size. mode decides whether
+// the content is compressed.
+func (s *CompressedStorage) writeBlob(src io.ReadSeeker, path string, mode byte, hash []byte, originalSize int64) (int64, error) {
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return 0, fmt.Errorf("failed to seek temp file: %w", err)
+	}
+
+	outputFile, err := os.Create(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer outputFile.Close()
+
+	header := make([]byte, compressionHeaderSize)
+	copy(header, compressionMagic)
+	header[len(compressionMagic)] = mode
+	binary.BigEndian.PutUint64(header[len(compressionMagic)+1:], uint64(originalSize))
+	copy(header[len(compressionMagic)+1+8:], hash)
+	if _, err := outputFile.Write(header); err != nil {
+		return 0, fmt.Errorf("failed to write blob header: %w", err)
+	}
+
+	if mode == compressionAlgorithmZstd {
+		encoder, err := zstd.NewWriter(outputFile, zstd.WithEncoderLevel(s.level), zstd.WithEncoderConcurrency(1))
+		if err != nil {
+			return 0, fmt.Errorf("failed to create zstd encoder: %w", err)
+		}
+		if _, err := io.Copy(encoder, src); err != nil {
+			encoder.Close()
+			return 0, fmt.Errorf("failed to compress content: %w", err)
+		}
+		if err := encoder.Close(); err != nil {
+			return 0, fmt.Errorf("failed to compress content: %w", err)
+		}
+	} else if _, err := io.Copy(outputFile, src); err != nil {
+		return 0, fmt.Errorf("failed to write content: %w", err)
+	}
+
+	// Sync before rename
+	if err := outputFile.Sync(); err != nil {
+		return 0, fmt.Errorf("failed to sync output file: %w", err)
+	}
+	info, err := outputFile.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat output file: %w", err)
+	}
+	return info.Size(), nil
+}
+
+// skipsContentType reports whether content of contentType is stored as received.
+func (s *CompressedStorage) skipsContentType(contentType string) bool {
+	if contentType == "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(contentType)
+	}
+	for _, skip := range s.skipTypes {
+		if prefix, ok := strings.CutSuffix(skip, "/*"); ok {
+			if strings.HasPrefix(mediaType, prefix+"/") {
+				return true
+			}
+		} else if mediaType == skip {
+			return true
+		}
+	}
+	return false
+}
+
+// blobLayout is where the original content of a blob is in its file.
+type blobLayout struct {
+	// compressed is set if the content is a Zstandard stream.
+	compressed bool
+
+	// offset is where the content starts.
+	offset int64
+
+	// size is the size of the original content.
+	size int64
+}
+
+// openBlob opens a blob and reads its header. A header only counts if it
+// holds the hash of the blob being opened; a blob without one is original
+// content written before compression was enabled.
+func (s *CompressedStorage) openBlob(contentHash string) (*os.File, blobLayout, error) {
+	s.storage.shards.RLock(contentHash)
+	defer s.storage.shards.RUnlock(contentHash)
+
+	file, err := os.Open(storage.ComputePath(s.storage.pathConfig, contentHash))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, blobLayout{}, storage.ErrBlobNotFound
+		}
+		return nil, blobLayout{}, fmt.Errorf("failed to open blob: %w", err)
+	}
+
+	header := make([]byte, compressionHeaderSize)
+	n, err := io.ReadFull(file, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		file.Close()
+		return nil, blobLayout{}, fmt.Errorf("failed to read blob header: %w", err)
+	}
+
+	hash, _ := hex.DecodeString(contentHash)
+	mode := header[len(compressionMagic)]
+	if n == len(header) &&
+		string(header[:len(compressionMagic)]) == compressionMagic &&
+		(mode == compressionStoredRaw || mode == compressionAlgorithmZstd) &&
+		bytes.Equal(header[len(compressionMagic)+1+8:], hash) {
+		return file, blobLayout{
+			compressed: mode == compressionAlgorithmZstd,
+			offset:     int64(compressionHeaderSize),
+			size:       int64(binary.BigEndian.Uint64(header[len(compressionMagic)+1:])),
+		}, nil
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, blobLayout{}, fmt.Errorf("failed to get blob size: %w", err)
+	}
+	return file, blobLayout{size: info.Size()}, nil
+}
+
+// Retrieve retrieves content, decompressing it if it was stored compressed.
+func (s *CompressedStorage) Retrieve(ctx context.Context, contentHash string) (io.ReadCloser, error) {
+	file, layout, err := s.openBlob(contentHash)
+	if err != nil {
+		return nil, err
+	}
+	if !layout.compressed {
+		return &sectionReadCloser{SectionReader: io.NewSectionReader(file, layout.offset, layout.size), file: file}, nil
+	}
+	return newDecompressingReadCloser(file, layout.offset)
+}
+
+// RetrieveRange retrieves length bytes of the original content starting at
+// offset. Compressed blobs are decompressed from the start up to the end of
+// the range. A length of zero or less reads to the end of the blob.
+func (s *CompressedStorage) RetrieveRange(ctx context.Context, contentHash string, offset, length int64) (io.ReadCloser, error) {
+	file, layout, err := s.openBlob(contentHash)
+	if err != nil {
+		return nil, err
+	}
+
+	offset = min(max(offset, 0), layout.size)
+	if length <= 0 || length > layout.size-offset {
+		length = layout.size - offset
+	}
+
+	if !layout.compressed {
+		return &sectionReadCloser{SectionReader: io.NewSectionReader(file, layout.offset+offset, length), file: file}, nil
+	}
+
+	decompressing, err := newDecompressingReadCloser(file, layout.offset)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.CopyN(io.Discard, decompressing, offset); err != nil && err != io.EOF {
+		decompressing.Close()
+		return nil, fmt.Errorf("failed to skip to offset: %w", err)
+	}
+	return &limitedReadCloser{
+		reader: io.LimitReader(decompressing, length),
+		closer: decompressing,
+	}, nil
+}
+
+// Delete removes a blob from storage.
+func (s *CompressedStorage) Delete(ctx context.Context, contentHash string) error {
+	return s.storage.Delete(ctx, contentHash)
+}
+
+// Exists checks if a blob exists in storage.
+func (s *CompressedStorage) Exists(ctx context.Context, contentHash string) (bool, error) {
+	return s.storage.Exists(ctx, contentHash)
+}
+
+// GetSize returns the size of the ORIGINAL content, as recorded in the
+// blob's header.
+func (s *CompressedStorage) GetSize(ctx context.Context, contentHash string) (int64, error) {
+	file, layout, err := s.openBlob(contentHash)
+	if err != nil {
+		return 0, err
+	}
+	file.Close()
+	return layout.size, nil
+}
+
+// WalkBlobs calls fn for every blob with its on-disk size.
+func (s *CompressedStorage) WalkBlobs(ctx context.Context, fn func(contentHash string, size int64, modTime time.Time) error) error {
+	return s.storage.WalkBlobs(ctx, fn)
+}
+
+// DeleteIfUnmodifiedSince deletes a blob unless it was modified after cutoff.
+func (s *CompressedStorage) DeleteIfUnmodifiedSince(ctx context.Context, contentHash string, cutoff time.Time) (bool, error) {
+	return s.storage.DeleteIfUnmodifiedSince(ctx, contentHash, cutoff)
+}
+
+// GetPath returns the storage path for a blob.
+func (s *CompressedStorage) GetPath(contentHash string) string {
+	return s.storage.GetPath(contentHash)
+}
+
+// HealthCheck verifies the storage backend is accessible.
+func (s *CompressedStorage) HealthCheck(ctx context.Context) error {
+	return s.storage.HealthCheck(ctx)
+}
+
+// GetDataDir returns the data directory path.
+func (s *CompressedStorage) GetDataDir() string {
+	return s.storage.GetDataDir()
+}
+
+// GetTempDir returns the temp directory path.
+func (s *CompressedStorage) GetTempDir() string {
+	return s.storage.GetTempDir()
+}
+
+// decompressingReadCloser decompresses a blob and closes its file when done.
+type decompressingReadCloser struct {
+	decoder *zstd.Decoder
+	file    *os.File
+}
+
+// newDecompressingReadCloser decompresses the stream starting at offset.
+func newDecompressingReadCloser(file *os.File, offset int64) (*decompressingReadCloser, error) {
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to seek blob: %w", err)
+	}
+	decoder, err := zstd.NewReader(file, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to create zstd decoder: %w", err)
+	}
+	return &decompressingReadCloser{decoder: decoder, file: file}, nil
+}
+
+func (d *decompressingReadCloser) Read(p []byte) (int, error) {
+	return d.decoder.Read(p)
+}
+
+func (d *decompressingReadCloser) Close() error {
+	d.decoder.Close()
+	return d.file.Close()
+}
+
+// sectionReadCloser reads the content of a blob stored as received and
+// closes its file when done.
+type sectionReadCloser struct {
+	*io.SectionReader
+	file *os.File
+}
+
+func (r *sectionReadCloser) Close() error {
+	return r.file.Close()
+}
+
+// Ensure CompressedStorage implements storage.Backend and storage.BlobSweeper
+var (
+	_ storage.Backend     = (*CompressedStorage)(nil)
+	_ storage.BlobSweeper = (*CompressedStorage)(nil)
+)
@@ -0,0 +1,194 @@
+package filesystem
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/pkg/crypto"
+	"github.com/prn-tf/alexander-storage/internal/storage"
+)
+
+func newTestCompressedStorage(t *testing.T, dir string) *CompressedStorage {
+	t.Helper()
+
+	s, err := NewCompressedStorage(CompressedConfig{
+		DataDir:          filepath.Join(dir, "data"),
+		TempDir:          filepath.Join(dir, "tmp"),
+		SkipContentTypes: []string{"image/*", "application/gzip"},
+	}, zerolog.Nop())
+	require.NoError(t, err)
+	return s
+}
+
+// compressibleContent returns size bytes of repetitive JSON log lines.
+func compressibleContent(size int) []byte {
+	line := `{"level":"info","msg":"request served","status":200,"path":"/api/v1/items"}` + "\n"
+	return []byte(strings.Repeat(line, size/len(line)+1)[:size])
+}
+
+func readAllAndClose(t *testing.T, reader io.ReadCloser) []byte {
+	t.Helper()
+
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return data
+}
+
+func onDiskSize(t *testing.T, s *CompressedStorage, hash string) int64 {
+	t.Helper()
+
+	info, err := os.Stat(s.GetPath(hash))
+	require.NoError(t, err)
+	return info.Size()
+}
+
+func TestCompressedStorage_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	s := newTestCompressedStorage(t, t.TempDir())
+	content := compressibleContent(64 * 1024)
+
+	hash, err := s.Store(ctx, bytes.NewReader(content), int64(len(content)))
+	require.NoError(t, err)
+	assert.Equal(t, crypto.SHA256Hex(content), hash)
+
+	assert.Less(t, onDiskSize(t, s, hash), int64(len(content)/4), "compressible content is smaller on disk")
+
+	reader, err := s.Retrieve(ctx, hash)
+	require.NoError(t, err)
+	assert.Equal(t, content, readAllAndClose(t, reader))
+
+	size, err := s.GetSize(ctx, hash)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(content)), size)
+
+	for _, r := range testRanges(len(content), 1024) {
+		t.Run(r.name, func(t *testing.T) {
+			reader, err := s.RetrieveRange(ctx, hash, r.offset, r.length)
+			require.NoError(t, err)
+			assert.Equal(t, content[r.start:r.end], readAllAndClose(t, reader))
+		})
+	}
+}
+
+func TestCompressedStorage_StoresUncompressed(t *testing.T) {
+	ctx := context.Background()
+	s := newTestCompressedStorage(t, t.TempDir())
+
+	random := make([]byte, 16*1024)
+	_, err := rand.Read(random)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		content     []byte
+		contentType string
+	}{
+		{name: "skipped content type", content: compressibleContent(8 * 1024), contentType: "image/png"},
+		{name: "skipped content type with parameters", content: compressibleContent(9 * 1024), contentType: "application/gzip; charset=binary"},
+		{name: "incompressible content", content: random, contentType: "application/octet-stream"},
+		{name: "empty content", content: []byte{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storeCtx := storage.WithContentType(ctx, tt.contentType)
+			hash, err := s.Store(storeCtx, bytes.NewReader(tt.content), int64(len(tt.content)))
+			require.NoError(t, err)
+
+			assert.Equal(t, int64(len(tt.content)+compressionHeaderSize), onDiskSize(t, s, hash))
+
+			reader, err := s.Retrieve(ctx, hash)
+			require.NoError(t, err)
+			assert.Equal(t, tt.content, readAllAndClose(t, reader))
+
+			size, err := s.GetSize(ctx, hash)
+			require.NoError(t, err)
+			assert.Equal(t, int64(len(tt.content)), size)
+		})
+	}
+}
+
+func TestCompressedStorage_ReadsBlobsStoredBeforeCompression(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	content := compressibleContent(32 * 1024)
+
+	plain, err := NewStorage(Config{
+		DataDir: filepath.Join(dir, "data"),
+		TempDir: filepath.Join(dir, "tmp"),
+	}, zerolog.Nop())
+	require.NoError(t, err)
+	hash, err := plain.Store(ctx, bytes.NewReader(content), int64(len(content)))
+	require.NoError(t, err)
+
+	s := newTestCompressedStorage(t, dir)
+
+	reader, err := s.Retrieve(ctx, hash)
+	require.NoError(t, err)
+	assert.Equal(t, content, readAllAndClose(t, reader))
+
+	reader, err = s.RetrieveRange(ctx, hash, 100, 50)
+	require.NoError(t, err)
+	assert.Equal(t, content[100:150], readAllAndClose(t, reader))
+
+	// Storing the same content again deduplicates against the existing blob
+	again, err := s.Store(ctx, bytes.NewReader(content), int64(len(content)))
+	require.NoError(t, err)
+	assert.Equal(t, hash, again)
+	assert.Equal(t, int64(len(content)), onDiskSize(t, s, hash))
+
+	_, err = s.Retrieve(ctx, "0000000000000000000000000000000000000000000000000000000000000000")
+	assert.ErrorIs(t, err, storage.ErrBlobNotFound)
+}
+
+func TestCompressedStorage_ContentLookingCompressedIsReadAsStored(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	// A compressed blob of other content, as it sits on disk
+	other := newTestCompressedStorage(t, t.TempDir())
+	otherHash, err := other.Store(ctx, bytes.NewReader(compressibleContent(8*1024)), 8*1024)
+	require.NoError(t, err)
+	lookalike, err := os.ReadFile(other.GetPath(otherHash))
+	require.NoError(t, err)
+	require.Equal(t, compressionMagic, string(lookalike[:len(compressionMagic)]))
+
+	plain, err := NewStorage(Config{
+		DataDir: filepath.Join(dir, "data"),
+		TempDir: filepath.Join(dir, "tmp"),
+	}, zerolog.Nop())
+	require.NoError(t, err)
+	s := newTestCompressedStorage(t, dir)
+
+	// Stored before compression was enabled, or as received by a skipped type
+	legacyHash, err := plain.Store(ctx, bytes.NewReader(lookalike), int64(len(lookalike)))
+	require.NoError(t, err)
+	edited := append(bytes.Clone(lookalike), 'x')
+	skippedHash, err := s.Store(storage.WithContentType(ctx, "application/gzip"), bytes.NewReader(edited), int64(len(edited)))
+	require.NoError(t, err)
+
+	for hash, content := range map[string][]byte{legacyHash: lookalike, skippedHash: edited} {
+		reader, err := s.Retrieve(ctx, hash)
+		require.NoError(t, err)
+		assert.Equal(t, content, readAllAndClose(t, reader))
+
+		size, err := s.GetSize(ctx, hash)
+		require.NoError(t, err)
+		assert.Equal(t, int64(len(content)), size)
+
+		reader, err = s.RetrieveRange(ctx, hash, 4, 20)
+		require.NoError(t, err)
+		assert.Equal(t, content[4:24], readAllAndClose(t, reader))
+	}
+}
@@ -58,23 +58,36 @@ func NewEncryptedStorage(cfg EncryptedConfig, logger zerolog.Logger) (*Encrypted
 }
 
 // Store stores content with SSE-S3 encryption.
-// The content is encrypted before being written to disk.
+// The content is spooled to a temp file while it is hashed, then encrypted
+// chunk by chunk into the blob path, so memory use does not grow with size.
 // Returns the content hash of the ORIGINAL (unencrypted) content.
 func (s *EncryptedStorage) Store(ctx context.Context, reader io.Reader, size int64) (string, error) {
-	// First, read all content to calculate hash and encrypt
-	// Note: For very large files, a streaming approach would be better
-	plaintext, err := io.ReadAll(reader)
+	// The key is derived from the content hash, so the whole plaintext has
+	// to be seen before encryption can start
+	tempFile, err := os.CreateTemp(s.storage.tempDir, "sse-encrypt-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tempPath := tempFile.Name()
+	defer func() {
+		tempFile.Close()
+		os.Remove(tempPath)
+	}()
+
+	// Stream content to temp file while calculating hash
+	hasher := crypto.NewHashingWriter(tempFile)
+	bytesWritten, err := io.Copy(hasher, reader)
 	if err != nil {
 		return "", fmt.Errorf("failed to read content: %w", err)
 	}
 
 	// Verify size if provided
-	if size > 0 && int64(len(plaintext)) != size {
-		return "", fmt.Errorf("size mismatch: expected %d, got %d", size, len(plaintext))
+	if size > 0 && bytesWritten != size {
+		return "", fmt.Errorf("%w: expected %d, got %d", storage.ErrSizeMismatch, size, bytesWritten)
 	}
 
 	// Calculate content hash (of plaintext, for CAS addressing)
-	contentHash := crypto.SHA256Hex(plaintext)
+	contentHash := hasher.Sum()
 
 	// Acquire sharded lock for this specific hash
 	s.storage.shards.Lock(contentHash)
@@ -85,16 +98,15 @@ func (s *EncryptedStorage) Store(ctx context.Context, reader io.Reader, size int
 
 	// Check if blob already exists (deduplication)
 	if _, err := os.Stat(fullPath); err == nil {
+		s.storage.touch(fullPath)
 		s.logger.Debug().
 			Str("content_hash", contentHash).
 			Msg("encrypted blob already exists, skipping storage")
 		return contentHash, nil
 	}
 
-	// Encrypt the content
-	ciphertext, err := s.encryptor.EncryptBlob(plaintext, contentHash)
-	if err != nil {
-		return "", fmt.Errorf("failed to encrypt content: %w", err)
+	if _, err := tempFile.Seek(0, io.SeekStart); err != nil {
+		return "", fmt.Errorf("failed to seek temp file: %w", err)
 	}
 
 	// Create target directory
@@ -103,20 +115,55 @@ func (s *EncryptedStorage) Store(ctx context.Context, reader io.Reader, size int
 		return "", fmt.Errorf("failed to create target directory: %w", err)
 	}
 
-	// Write encrypted content to file
-	if err := os.WriteFile(fullPath, ciphertext, 0644); err != nil {
-		return "", fmt.Errorf("failed to write encrypted blob: %w", err)
+	encryptedSize, err := s.encryptToPath(tempFile, contentHash, fullPath, fullPath+".encrypting")
+	if err != nil {
+		return "", err
 	}
 
 	s.logger.Debug().
 		Str("content_hash", contentHash).
-		Int("plaintext_size", len(plaintext)).
-		Int("encrypted_size", len(ciphertext)).
+		Int64("plaintext_size", bytesWritten).
+		Int64("encrypted_size", encryptedSize).
 		Msg("blob stored with SSE-S3 encryption")
 
 	return contentHash, nil
 }
 
+// encryptToPath encrypts plaintext into tempPath and atomically renames it
+// to fullPath. tempPath is removed on failure.
+func (s *EncryptedStorage) encryptToPath(plaintext io.Reader, contentHash, fullPath, tempPath string) (int64, error) {
+	encryptingReader, err := s.encryptor.EncryptReader(plaintext, contentHash)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create encrypting reader: %w", err)
+	}
+	defer encryptingReader.Close()
+
+	outputFile, err := os.OpenFile(tempPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create output file: %w", err)
+	}
+
+	encryptedSize, err := io.Copy(outputFile, encryptingReader)
+	if err == nil {
+		err = outputFile.Sync()
+	}
+	if closeErr := outputFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tempPath)
+		return 0, fmt.Errorf("failed to write encrypted blob: %w", err)
+	}
+
+	// Atomic rename
+	if err := os.Rename(tempPath, fullPath); err != nil {
+		os.Remove(tempPath)
+		return 0, fmt.Errorf("failed to finalize blob: %w", err)
+	}
+
+	return encryptedSize, nil
+}
+
 // Retrieve retrieves and decrypts content.
 // This method assumes the content is encrypted.
 // For mixed mode (supporting both encrypted and unencrypted), use RetrieveMixedMode.
@@ -137,28 +184,27 @@ func (s *EncryptedStorage) RetrieveMixedMode(ctx context.Context, contentHash st
 		return s.storage.Retrieve(ctx, contentHash)
 	}
 
-	// Acquire sharded read lock for this specific hash
-	s.storage.shards.RLock(contentHash)
-	defer s.storage.shards.RUnlock(contentHash)
-
 	fullPath := storage.ComputePath(s.storage.pathConfig, contentHash)
 
-	// Read encrypted content
-	ciphertext, err := os.ReadFile(fullPath)
+	file, err := os.Open(fullPath)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil, storage.ErrBlobNotFound
 		}
-		return nil, fmt.Errorf("failed to read encrypted blob: %w", err)
+		return nil, fmt.Errorf("failed to open encrypted blob: %w", err)
 	}
 
-	// Decrypt
-	plaintext, err := s.encryptor.DecryptBlob(ciphertext, contentHash)
+	// Decrypts chunk by chunk; legacy single-seal blobs are buffered
+	decryptingReader, err := s.encryptor.DecryptReader(file, contentHash)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decrypt blob: %w", err)
+		file.Close()
+		return nil, fmt.Errorf("failed to create decrypting reader: %w", err)
 	}
 
-	return &bytesReadCloser{data: plaintext}, nil
+	return &aesDecryptReadCloser{
+		reader: decryptingReader,
+		file:   file,
+	}, nil
 }
 
 // Delete removes a blob from storage.
@@ -206,66 +252,43 @@ func (s *EncryptedStorage) EncryptExistingBlob(ctx context.Context, contentHash
 
 	fullPath := storage.ComputePath(s.storage.pathConfig, contentHash)
 
-	// Read existing (unencrypted) content
-	plaintext, err := os.ReadFile(fullPath)
+	sourceFile, err := os.Open(fullPath)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return storage.ErrBlobNotFound
 		}
-		return fmt.Errorf("failed to read blob: %w", err)
+		return fmt.Errorf("failed to open blob: %w", err)
 	}
+	defer sourceFile.Close()
 
 	// Verify the content hash matches
-	actualHash := crypto.SHA256Hex(plaintext)
-	if actualHash != contentHash {
+	hasher := crypto.NewHashingWriter(io.Discard)
+	plaintextSize, err := io.Copy(hasher, sourceFile)
+	if err != nil {
+		return fmt.Errorf("failed to read blob: %w", err)
+	}
+	if actualHash := hasher.Sum(); actualHash != contentHash {
 		return fmt.Errorf("content hash mismatch: expected %s, got %s", contentHash, actualHash)
 	}
 
-	// Encrypt the content
-	ciphertext, err := s.encryptor.EncryptBlob(plaintext, contentHash)
-	if err != nil {
-		return fmt.Errorf("failed to encrypt content: %w", err)
+	if _, err := sourceFile.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek blob: %w", err)
 	}
 
 	// Write encrypted content (atomic via temp file)
-	tempPath := fullPath + ".encrypting"
-	if err := os.WriteFile(tempPath, ciphertext, 0644); err != nil {
-		return fmt.Errorf("failed to write encrypted blob: %w", err)
-	}
-
-	// Atomic rename
-	if err := os.Rename(tempPath, fullPath); err != nil {
-		_ = os.Remove(tempPath)
-		return fmt.Errorf("failed to replace blob: %w", err)
+	encryptedSize, err := s.encryptToPath(sourceFile, contentHash, fullPath, fullPath+".encrypting")
+	if err != nil {
+		return err
 	}
 
 	s.logger.Debug().
 		Str("content_hash", contentHash).
-		Int("plaintext_size", len(plaintext)).
-		Int("encrypted_size", len(ciphertext)).
+		Int64("plaintext_size", plaintextSize).
+		Int64("encrypted_size", encryptedSize).
 		Msg("existing blob encrypted")
 
 	return nil
 }
 
-// bytesReadCloser wraps a byte slice as an io.ReadCloser.
-type bytesReadCloser struct {
-	data  []byte
-	index int
-}
-
-func (b *bytesReadCloser) Read(p []byte) (int, error) {
-	if b.index >= len(b.data) {
-		return 0, io.EOF
-	}
-	n := copy(p, b.data[b.index:])
-	b.index += n
-	return n, nil
-}
-
-func (b *bytesReadCloser) Close() error {
-	return nil
-}
-
 // Ensure EncryptedStorage implements storage.Backend
 var _ storage.Backend = (*EncryptedStorage)(nil)
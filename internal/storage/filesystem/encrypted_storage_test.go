@@ -0,0 +1,99 @@
+package filesystem
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/pkg/crypto"
+)
+
+func newTestEncryptedStorage(t *testing.T, masterKey []byte) *EncryptedStorage {
+	t.Helper()
+
+	dir := t.TempDir()
+	s, err := NewEncryptedStorage(EncryptedConfig{
+		DataDir:   filepath.Join(dir, "data"),
+		TempDir:   filepath.Join(dir, "tmp"),
+		MasterKey: masterKey,
+	}, zerolog.Nop())
+	require.NoError(t, err)
+	return s
+}
+
+func TestEncryptedStorage_StoreStreamsChunks(t *testing.T) {
+	ctx := context.Background()
+	s := newTestEncryptedStorage(t, bytes.Repeat([]byte{0x42}, 32))
+
+	// Not a multiple of the chunk size, and not a seekable reader
+	plaintext := bytes.Repeat([]byte("0123456789abcdef"), 3*crypto.SSEChunkSize/16+100)
+	hash, err := s.Store(ctx, io.MultiReader(bytes.NewReader(plaintext)), int64(len(plaintext)))
+	require.NoError(t, err)
+	assert.Equal(t, crypto.SHA256Hex(plaintext), hash)
+
+	onDisk, err := os.ReadFile(s.GetPath(hash))
+	require.NoError(t, err)
+	assert.Equal(t, crypto.CalculateEncryptedSize(int64(len(plaintext))), int64(len(onDisk)))
+	assert.NotContains(t, string(onDisk), "0123456789abcdef")
+
+	// Nothing is left behind in the temp dir or next to the blob
+	temps, err := os.ReadDir(s.GetTempDir())
+	require.NoError(t, err)
+	assert.Empty(t, temps)
+	_, err = os.Stat(s.GetPath(hash) + ".encrypting")
+	assert.True(t, os.IsNotExist(err))
+
+	reader, err := s.Retrieve(ctx, hash)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	// Read with a small buffer to cross chunk boundaries mid-read
+	var got bytes.Buffer
+	_, err = io.CopyBuffer(&got, struct{ io.Reader }{reader}, make([]byte, 1000))
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, got.Bytes())
+}
+
+func TestEncryptedStorage_RetrieveSingleSealBlob(t *testing.T) {
+	ctx := context.Background()
+	masterKey := bytes.Repeat([]byte{0x42}, 32)
+	s := newTestEncryptedStorage(t, masterKey)
+
+	plaintext := []byte("stored before AES chunking")
+	hash := crypto.SHA256Hex(plaintext)
+	writeBlobFile(t, s.GetPath(hash), sealSingleSSE(t, masterKey, plaintext, hash))
+
+	reader, err := s.Retrieve(ctx, hash)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, data)
+}
+
+func TestEncryptedStorage_EncryptExistingBlob(t *testing.T) {
+	ctx := context.Background()
+	s := newTestEncryptedStorage(t, bytes.Repeat([]byte{0x42}, 32))
+
+	plaintext := bytes.Repeat([]byte("p"), crypto.SSEChunkSize+1)
+	hash, err := s.storage.Store(ctx, bytes.NewReader(plaintext), int64(len(plaintext)))
+	require.NoError(t, err)
+
+	require.NoError(t, s.EncryptExistingBlob(ctx, hash))
+
+	reader, err := s.Retrieve(ctx, hash)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, data)
+}
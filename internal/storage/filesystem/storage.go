@@ -7,9 +7,11 @@ import (
 	"encoding/hex"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/rs/zerolog"
 
@@ -180,7 +182,7 @@ func (s *Storage) Store(ctx context.Context, reader io.Reader, size int64) (stri
 
 	// Verify size if provided
 	if size > 0 && written != size {
-		return "", fmt.Errorf("size mismatch: expected %d, got %d", size, written)
+		return "", fmt.Errorf("%w: expected %d, got %d", storage.ErrSizeMismatch, size, written)
 	}
 
 	// Get the content hash
@@ -197,6 +199,7 @@ func (s *Storage) Store(ctx context.Context, reader io.Reader, size int64) (stri
 	if _, err := os.Stat(fullPath); err == nil {
 		// Blob already exists, just remove temp file
 		_ = os.Remove(tempPath)
+		s.touch(fullPath)
 		s.logger.Debug().
 			Str("content_hash", contentHash).
 			Msg("blob already exists, skipping storage")
@@ -344,6 +347,88 @@ func (s *Storage) GetSize(ctx context.Context, contentHash string) (int64, error
 	return info.Size(), nil
 }
 
+// WalkBlobs calls fn for every blob in the data directory.
+// In-progress writes and other files not named by a content hash are skipped.
+func (s *Storage) WalkBlobs(ctx context.Context, fn func(contentHash string, size int64, modTime time.Time) error) error {
+	return filepath.WalkDir(s.dataDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if entry.IsDir() || !isContentHash(entry.Name()) {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil // deleted while walking
+			}
+			return fmt.Errorf("failed to stat blob: %w", err)
+		}
+		return fn(entry.Name(), info.Size(), info.ModTime())
+	})
+}
+
+// DeleteIfUnmodifiedSince deletes a blob unless it was modified after cutoff.
+// Uses sharded write lock for the specific hash, so a concurrent Store either
+// refreshes the blob first or writes it anew after the delete.
+func (s *Storage) DeleteIfUnmodifiedSince(ctx context.Context, contentHash string, cutoff time.Time) (bool, error) {
+	s.shards.Lock(contentHash)
+	defer s.shards.Unlock(contentHash)
+
+	fullPath := storage.ComputePath(s.pathConfig, contentHash)
+
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, storage.ErrBlobNotFound
+		}
+		return false, fmt.Errorf("failed to stat blob: %w", err)
+	}
+	if info.ModTime().After(cutoff) {
+		return false, nil
+	}
+
+	if err := os.Remove(fullPath); err != nil {
+		if os.IsNotExist(err) {
+			return false, storage.ErrBlobNotFound
+		}
+		return false, fmt.Errorf("failed to delete blob: %w", err)
+	}
+	s.cleanupEmptyDirs(filepath.Dir(fullPath))
+
+	s.logger.Debug().
+		Str("content_hash", contentHash).
+		Msg("unmodified blob deleted")
+
+	return true, nil
+}
+
+// touch refreshes the modification time of a blob that Store deduplicated
+// against, see DeleteIfUnmodifiedSince. Must be called with the hash's shard
+// lock held.
+func (s *Storage) touch(fullPath string) {
+	now := time.Now()
+	if err := os.Chtimes(fullPath, now, now); err != nil {
+		s.logger.Warn().Err(err).Str("path", fullPath).Msg("failed to refresh blob modification time")
+	}
+}
+
+// isContentHash reports whether name is a SHA-256 content hash: 64 lowercase hex characters.
+func isContentHash(name string) bool {
+	if len(name) != 64 {
+		return false
+	}
+	for _, c := range name {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
 // GetPath returns the storage path for a blob (for database records).
 func (s *Storage) GetPath(contentHash string) string {
 	return storage.ComputePath(s.pathConfig, contentHash)
@@ -374,6 +459,7 @@ func (s *Storage) cleanupEmptyDirs(dir string) {
 }
 
 // copyFile copies a file from src to dst.
+// A failed copy removes dst, so a partial file is never left at a blob path.
 func copyFile(src, dst string) error {
 	sourceFile, err := os.Open(src)
 	if err != nil {
@@ -385,9 +471,14 @@ func copyFile(src, dst string) error {
 	if err != nil {
 		return err
 	}
-	defer destFile.Close()
 
 	_, err = io.Copy(destFile, sourceFile)
+	if closeErr := destFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(dst)
+	}
 	return err
 }
 
@@ -430,5 +521,8 @@ func (s *Storage) HealthCheck(ctx context.Context) error {
 	return nil
 }
 
-// Ensure Storage implements storage.Backend
-var _ storage.Backend = (*Storage)(nil)
+// Ensure Storage implements storage.Backend and storage.BlobSweeper
+var (
+	_ storage.Backend     = (*Storage)(nil)
+	_ storage.BlobSweeper = (*Storage)(nil)
+)
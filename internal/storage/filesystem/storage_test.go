@@ -0,0 +1,130 @@
+package filesystem
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/storage"
+)
+
+// blobRange is a range request and the slice of the content it must return.
+type blobRange struct {
+	name           string
+	offset, length int64
+	start, end     int
+}
+
+// testRanges returns ranges over content of size bytes that start and end
+// inside, on and across boundaries of chunkSize.
+func testRanges(size, chunkSize int) []blobRange {
+	return []blobRange{
+		{name: "whole blob", offset: 0, length: 0, start: 0, end: size},
+		{name: "prefix", offset: 0, length: 10, start: 0, end: 10},
+		{name: "within first chunk", offset: 5, length: 100, start: 5, end: 105},
+		{name: "across chunk boundary", offset: int64(chunkSize - 7), length: 20, start: chunkSize - 7, end: chunkSize + 13},
+		{name: "starts on chunk boundary", offset: int64(chunkSize), length: 50, start: chunkSize, end: chunkSize + 50},
+		{name: "spans several chunks", offset: int64(chunkSize/2 + 1), length: int64(3 * chunkSize), start: chunkSize/2 + 1, end: chunkSize/2 + 1 + 3*chunkSize},
+		{name: "to end", offset: int64(size - 100), length: 0, start: size - 100, end: size},
+		{name: "length past end", offset: int64(size - 10), length: 1000, start: size - 10, end: size},
+		{name: "offset past end", offset: int64(size + 10), length: 5, start: size, end: size},
+	}
+}
+
+func TestStorage_RetrieveRange(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	s, err := NewStorage(Config{
+		DataDir: filepath.Join(dir, "data"),
+		TempDir: filepath.Join(dir, "tmp"),
+	}, zerolog.Nop())
+	require.NoError(t, err)
+
+	content := make([]byte, 10*1024)
+	_, err = rand.Read(content)
+	require.NoError(t, err)
+
+	hash, err := s.Store(ctx, bytes.NewReader(content), int64(len(content)))
+	require.NoError(t, err)
+
+	for _, r := range testRanges(len(content), 1024) {
+		t.Run(r.name, func(t *testing.T) {
+			reader, err := s.RetrieveRange(ctx, hash, r.offset, r.length)
+			require.NoError(t, err)
+			defer reader.Close()
+
+			data, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			assert.Equal(t, content[r.start:r.end], data)
+		})
+	}
+
+	_, err = s.RetrieveRange(ctx, "0000000000000000000000000000000000000000000000000000000000000000", 0, 10)
+	assert.ErrorIs(t, err, storage.ErrBlobNotFound)
+}
+
+func TestStorage_StoreRefreshesDeduplicatedBlob(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	s, err := NewStorage(Config{
+		DataDir: filepath.Join(dir, "data"),
+		TempDir: filepath.Join(dir, "tmp"),
+	}, zerolog.Nop())
+	require.NoError(t, err)
+
+	hash, err := s.Store(ctx, bytes.NewReader([]byte("again")), 5)
+	require.NoError(t, err)
+	old := time.Now().Add(-48 * time.Hour)
+	require.NoError(t, os.Chtimes(s.GetPath(hash), old, old))
+
+	cutoff := time.Now().Add(-24 * time.Hour)
+	var walked []string
+	require.NoError(t, s.WalkBlobs(ctx, func(contentHash string, size int64, modTime time.Time) error {
+		walked = append(walked, contentHash)
+		assert.Equal(t, int64(5), size)
+		assert.False(t, modTime.After(cutoff))
+		return nil
+	}))
+	assert.Equal(t, []string{hash}, walked)
+
+	// Storing the content again keeps a sweep from deleting it
+	_, err = s.Store(ctx, bytes.NewReader([]byte("again")), 5)
+	require.NoError(t, err)
+
+	deleted, err := s.DeleteIfUnmodifiedSince(ctx, hash, cutoff)
+	require.NoError(t, err)
+	assert.False(t, deleted)
+
+	deleted, err = s.DeleteIfUnmodifiedSince(ctx, hash, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.True(t, deleted)
+
+	_, err = s.DeleteIfUnmodifiedSince(ctx, hash, time.Now())
+	assert.ErrorIs(t, err, storage.ErrBlobNotFound)
+}
+
+func TestCopyFile_RemovesPartialDestination(t *testing.T) {
+	dir := t.TempDir()
+	dst := filepath.Join(dir, "blob")
+
+	// Reading a directory fails after the destination was created
+	require.Error(t, copyFile(dir, dst))
+	_, err := os.Stat(dst)
+	assert.True(t, os.IsNotExist(err), "a failed copy must not leave a partial blob")
+
+	src := filepath.Join(dir, "source")
+	require.NoError(t, os.WriteFile(src, []byte("content"), 0644))
+	require.NoError(t, copyFile(src, dst))
+	data, err := os.ReadFile(dst)
+	require.NoError(t, err)
+	assert.Equal(t, "content", string(data))
+}
@@ -15,11 +15,15 @@ import (
 )
 
 // StreamingEncryptedStorage provides transparent streaming encryption using ChaCha20-Poly1305.
-// Unlike EncryptedStorage (which loads entire files into memory), this implementation
-// uses streaming encryption that processes data in chunks, making it suitable for large files.
+// Data is processed in chunks, making it suitable for large files.
+//
+// Blobs written before the switch to ChaCha20 remain AES-256-GCM encrypted until
+// migrated. They are decrypted with a legacy SSEEncryptor derived from the same
+// master key, see RetrieveWithScheme.
 type StreamingEncryptedStorage struct {
 	storage   *Storage
 	encryptor *crypto.ChaChaStreamEncryptor
+	legacy    *crypto.SSEEncryptor
 	logger    zerolog.Logger
 	scheme    string
 }
@@ -51,6 +55,12 @@ func NewStreamingEncryptedStorage(cfg StreamingEncryptedConfig, logger zerolog.L
 		return nil, fmt.Errorf("failed to create stream encryptor: %w", err)
 	}
 
+	// Legacy AES-GCM decryptor for blobs not yet migrated to ChaCha
+	legacy, err := crypto.NewSSEEncryptor(cfg.MasterKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create legacy AES encryptor: %w", err)
+	}
+
 	// Set custom chunk size if provided
 	if cfg.ChunkSize > 0 {
 		encryptor.SetChunkSize(cfg.ChunkSize)
@@ -64,6 +74,7 @@ func NewStreamingEncryptedStorage(cfg StreamingEncryptedConfig, logger zerolog.L
 	return &StreamingEncryptedStorage{
 		storage:   baseStorage,
 		encryptor: encryptor,
+		legacy:    legacy,
 		logger:    logger,
 		scheme:    crypto.ChaChaEncryptionScheme,
 	}, nil
@@ -94,7 +105,7 @@ func (s *StreamingEncryptedStorage) Store(ctx context.Context, reader io.Reader,
 
 	// Verify size if provided
 	if size > 0 && bytesWritten != size {
-		return "", fmt.Errorf("size mismatch: expected %d, got %d", size, bytesWritten)
+		return "", fmt.Errorf("%w: expected %d, got %d", storage.ErrSizeMismatch, size, bytesWritten)
 	}
 
 	contentHash := hasher.Sum()
@@ -108,6 +119,7 @@ func (s *StreamingEncryptedStorage) Store(ctx context.Context, reader io.Reader,
 
 	// Check if blob already exists (deduplication)
 	if _, err := os.Stat(fullPath); err == nil {
+		s.storage.touch(fullPath)
 		s.logger.Debug().
 			Str("content_hash", contentHash).
 			Msg("streaming encrypted blob already exists, skipping storage")
@@ -196,6 +208,9 @@ func (s *StreamingEncryptedStorage) RetrieveMixedMode(ctx context.Context, conte
 		return s.storage.Retrieve(ctx, contentHash)
 	}
 
+	s.storage.shards.RLock(contentHash)
+	defer s.storage.shards.RUnlock(contentHash)
+
 	fullPath := storage.ComputePath(s.storage.pathConfig, contentHash)
 
 	// Open encrypted file
@@ -221,20 +236,112 @@ func (s *StreamingEncryptedStorage) RetrieveMixedMode(ctx context.Context, conte
 	}, nil
 }
 
+// RetrieveRange retrieves length plaintext bytes of an encrypted blob starting
+// at offset. Only the chunks overlapping the range are read and decrypted. A
+// length of zero or less reads to the end of the blob.
+func (s *StreamingEncryptedStorage) RetrieveRange(ctx context.Context, contentHash string, offset, length int64) (io.ReadCloser, error) {
+	s.storage.shards.RLock(contentHash)
+	defer s.storage.shards.RUnlock(contentHash)
+
+	fullPath := storage.ComputePath(s.storage.pathConfig, contentHash)
+
+	file, err := os.Open(fullPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, storage.ErrBlobNotFound
+		}
+		return nil, fmt.Errorf("failed to open encrypted blob: %w", err)
+	}
+
+	rangeReader, err := s.encryptor.NewRangeDecryptingReader(file, []byte(contentHash), offset, length)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to create range decrypting reader: %w", err)
+	}
+
+	return &streamingDecryptReadCloser{
+		reader: rangeReader,
+		file:   file,
+	}, nil
+}
+
 // RetrieveWithScheme retrieves content and decrypts based on the encryption scheme.
-// Supports both ChaCha20-Poly1305 streaming and legacy AES-256-GCM.
+// The scheme is the one recorded in the blob's metadata, so blobs still encrypted
+// with legacy AES-256-GCM are readable alongside ChaCha20-Poly1305 blobs while a
+// migration is in progress.
 func (s *StreamingEncryptedStorage) RetrieveWithScheme(ctx context.Context, contentHash string, scheme string) (io.ReadCloser, error) {
 	switch scheme {
 	case "", "none":
 		return s.storage.Retrieve(ctx, contentHash)
 	case crypto.ChaChaEncryptionScheme:
 		return s.RetrieveMixedMode(ctx, contentHash, true)
+	case crypto.AESEncryptionScheme:
+		return s.retrieveAES(contentHash)
 	default:
-		// Unknown scheme, try to read as-is (may be legacy AES-GCM)
-		return nil, fmt.Errorf("unsupported encryption scheme: %s", scheme)
+		return nil, fmt.Errorf("%w: %s", storage.ErrUnsupportedEncryptionScheme, scheme)
 	}
 }
 
+// RetrieveRangeWithScheme retrieves a byte range like RetrieveRange, decrypting
+// based on the encryption scheme like RetrieveWithScheme. AES-256-GCM blobs
+// cannot be entered mid-stream, so they are decrypted from the start and the
+// bytes before offset are discarded.
+func (s *StreamingEncryptedStorage) RetrieveRangeWithScheme(ctx context.Context, contentHash string, scheme string, offset, length int64) (io.ReadCloser, error) {
+	switch scheme {
+	case "", "none":
+		return s.storage.RetrieveRange(ctx, contentHash, offset, length)
+	case crypto.ChaChaEncryptionScheme:
+		return s.RetrieveRange(ctx, contentHash, offset, length)
+	case crypto.AESEncryptionScheme:
+		reader, err := s.retrieveAES(contentHash)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.CopyN(io.Discard, reader, offset); err != nil && err != io.EOF {
+			reader.Close()
+			return nil, fmt.Errorf("failed to skip to offset: %w", err)
+		}
+		if length <= 0 {
+			return reader, nil
+		}
+		return &limitedReadCloser{
+			reader: io.LimitReader(reader, length),
+			closer: reader,
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", storage.ErrUnsupportedEncryptionScheme, scheme)
+	}
+}
+
+// retrieveAES retrieves a blob encrypted with AES-256-GCM. Chunked blobs are
+// decrypted as they are read; legacy single-seal blobs authenticate the whole
+// blob at once, so their ciphertext is buffered before the first byte is returned.
+func (s *StreamingEncryptedStorage) retrieveAES(contentHash string) (io.ReadCloser, error) {
+	s.storage.shards.RLock(contentHash)
+	defer s.storage.shards.RUnlock(contentHash)
+
+	fullPath := storage.ComputePath(s.storage.pathConfig, contentHash)
+
+	file, err := os.Open(fullPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, storage.ErrBlobNotFound
+		}
+		return nil, fmt.Errorf("failed to open AES blob: %w", err)
+	}
+
+	decryptingReader, err := s.legacy.DecryptReader(file, contentHash)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to create AES decrypting reader: %w", err)
+	}
+
+	return &aesDecryptReadCloser{
+		reader: decryptingReader,
+		file:   file,
+	}, nil
+}
+
 // Delete removes a blob from storage.
 func (s *StreamingEncryptedStorage) Delete(ctx context.Context, contentHash string) error {
 	return s.storage.Delete(ctx, contentHash)
@@ -428,7 +535,7 @@ func (s *StreamingEncryptedStorage) MigrateFromAES(ctx context.Context, contentH
 
 // streamingDecryptReadCloser wraps a decrypting reader with file cleanup.
 type streamingDecryptReadCloser struct {
-	reader *crypto.DecryptingReader
+	reader io.Reader
 	file   *os.File
 }
 
@@ -440,5 +547,20 @@ func (r *streamingDecryptReadCloser) Close() error {
 	return r.file.Close()
 }
 
+// aesDecryptReadCloser wraps a legacy AES decrypting reader and closes the underlying file.
+type aesDecryptReadCloser struct {
+	reader *crypto.SSEDecryptingReader
+	file   *os.File
+}
+
+func (r *aesDecryptReadCloser) Read(p []byte) (int, error) {
+	return r.reader.Read(p)
+}
+
+func (r *aesDecryptReadCloser) Close() error {
+	r.reader.Close()
+	return r.file.Close()
+}
+
 // Ensure StreamingEncryptedStorage implements storage.Backend
 var _ storage.Backend = (*StreamingEncryptedStorage)(nil)
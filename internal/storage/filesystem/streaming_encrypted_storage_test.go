@@ -0,0 +1,266 @@
+package filesystem
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/pkg/crypto"
+	"github.com/prn-tf/alexander-storage/internal/storage"
+)
+
+func newTestStreamingStorage(t *testing.T, masterKey []byte) *StreamingEncryptedStorage {
+	t.Helper()
+
+	dir := t.TempDir()
+	s, err := NewStreamingEncryptedStorage(StreamingEncryptedConfig{
+		DataDir:   filepath.Join(dir, "data"),
+		TempDir:   filepath.Join(dir, "tmp"),
+		MasterKey: masterKey,
+	}, zerolog.Nop())
+	require.NoError(t, err)
+	return s
+}
+
+// storeAESBlob writes plaintext the way the AES-GCM backend does.
+func storeAESBlob(t *testing.T, s *StreamingEncryptedStorage, masterKey, plaintext []byte) string {
+	t.Helper()
+
+	sse, err := crypto.NewSSEEncryptor(masterKey)
+	require.NoError(t, err)
+
+	hash := crypto.SHA256Hex(plaintext)
+	ciphertext, err := sse.EncryptBlob(plaintext, hash)
+	require.NoError(t, err)
+
+	writeBlobFile(t, s.GetPath(hash), ciphertext)
+	return hash
+}
+
+// storeSingleSealAESBlob writes plaintext in the legacy single-seal AES-GCM
+// format used before chunking: nonce || ciphertext || tag.
+func storeSingleSealAESBlob(t *testing.T, s *StreamingEncryptedStorage, masterKey, plaintext []byte) string {
+	t.Helper()
+
+	hash := crypto.SHA256Hex(plaintext)
+	writeBlobFile(t, s.GetPath(hash), sealSingleSSE(t, masterKey, plaintext, hash))
+	return hash
+}
+
+func sealSingleSSE(t *testing.T, masterKey, plaintext []byte, hash string) []byte {
+	t.Helper()
+
+	sse, err := crypto.NewSSEEncryptor(masterKey)
+	require.NoError(t, err)
+	key, err := sse.DeriveKey(hash)
+	require.NoError(t, err)
+
+	block, err := aes.NewCipher(key)
+	require.NoError(t, err)
+	gcm, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+
+	nonce := bytes.Repeat([]byte{0x07}, crypto.SSENonceSize)
+	return gcm.Seal(nonce, nonce, plaintext, nil)
+}
+
+func writeBlobFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, data, 0644))
+}
+
+func TestStreamingEncryptedStorage_RetrieveWithSchemeMixedBlobs(t *testing.T) {
+	ctx := context.Background()
+	masterKey := bytes.Repeat([]byte{0x42}, 32)
+	s := newTestStreamingStorage(t, masterKey)
+
+	blobs := []struct {
+		name      string
+		scheme    string
+		plaintext []byte
+		hash      string
+	}{
+		{name: "aes single seal", scheme: "aes-single-seal", plaintext: []byte("written before AES chunking")},
+		{name: "aes legacy", scheme: crypto.AESEncryptionScheme, plaintext: []byte("written before the ChaCha migration")},
+		{name: "chacha", scheme: crypto.ChaChaEncryptionScheme, plaintext: []byte("written by the streaming backend")},
+		{name: "aes legacy 2", scheme: crypto.AESEncryptionScheme, plaintext: bytes.Repeat([]byte("a"), 100*1024)},
+		{name: "chacha 2", scheme: crypto.ChaChaEncryptionScheme, plaintext: bytes.Repeat([]byte("c"), 100*1024)},
+	}
+
+	for i := range blobs {
+		b := &blobs[i]
+		if b.scheme == crypto.AESEncryptionScheme {
+			b.hash = storeAESBlob(t, s, masterKey, b.plaintext)
+			continue
+		}
+		if b.scheme == "aes-single-seal" {
+			b.hash = storeSingleSealAESBlob(t, s, masterKey, b.plaintext)
+			b.scheme = crypto.AESEncryptionScheme
+			continue
+		}
+		hash, err := s.Store(ctx, bytes.NewReader(b.plaintext), int64(len(b.plaintext)))
+		require.NoError(t, err)
+		b.hash = hash
+	}
+
+	for _, b := range blobs {
+		t.Run(b.name, func(t *testing.T) {
+			reader, err := s.RetrieveWithScheme(ctx, b.hash, b.scheme)
+			require.NoError(t, err)
+			defer reader.Close()
+
+			data, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			assert.Equal(t, b.plaintext, data)
+		})
+	}
+}
+
+func TestStreamingEncryptedStorage_RetrieveWithSchemeErrors(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStreamingStorage(t, bytes.Repeat([]byte{0x42}, 32))
+
+	hash := crypto.SHA256Hex([]byte("missing"))
+
+	_, err := s.RetrieveWithScheme(ctx, hash, crypto.AESEncryptionScheme)
+	assert.ErrorIs(t, err, storage.ErrBlobNotFound)
+
+	_, err = s.RetrieveWithScheme(ctx, hash, "rot13")
+	assert.ErrorIs(t, err, storage.ErrUnsupportedEncryptionScheme)
+}
+
+func TestStreamingEncryptedStorage_RetrieveRange(t *testing.T) {
+	ctx := context.Background()
+	const chunkSize = 1024
+
+	dir := t.TempDir()
+	s, err := NewStreamingEncryptedStorage(StreamingEncryptedConfig{
+		DataDir:   filepath.Join(dir, "data"),
+		TempDir:   filepath.Join(dir, "tmp"),
+		MasterKey: bytes.Repeat([]byte{0x42}, 32),
+		ChunkSize: chunkSize,
+	}, zerolog.Nop())
+	require.NoError(t, err)
+
+	// Not a multiple of the chunk size, so the last chunk is short
+	content := make([]byte, 10*chunkSize+300)
+	_, err = rand.Read(content)
+	require.NoError(t, err)
+
+	hash, err := s.Store(ctx, bytes.NewReader(content), int64(len(content)))
+	require.NoError(t, err)
+
+	for _, r := range testRanges(len(content), chunkSize) {
+		t.Run(r.name, func(t *testing.T) {
+			reader, err := s.RetrieveRange(ctx, hash, r.offset, r.length)
+			require.NoError(t, err)
+			defer reader.Close()
+
+			data, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			assert.Equal(t, content[r.start:r.end], data)
+		})
+	}
+
+	t.Run("tampered chunk in range", func(t *testing.T) {
+		path := s.GetPath(hash)
+		ciphertext, err := os.ReadFile(path)
+		require.NoError(t, err)
+
+		// Flip a byte in the third chunk's ciphertext
+		chunkOnDisk := crypto.ChaChaHeaderSize + chunkSize + crypto.ChaChaOverhead
+		ciphertext[2*chunkOnDisk+crypto.ChaChaHeaderSize] ^= 0xff
+		require.NoError(t, os.WriteFile(path, ciphertext, 0644))
+
+		// Ranges that skip the chunk are unaffected
+		reader, err := s.RetrieveRange(ctx, hash, 3*chunkSize, 10)
+		require.NoError(t, err)
+		data, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, content[3*chunkSize:3*chunkSize+10], data)
+		reader.Close()
+
+		reader, err = s.RetrieveRange(ctx, hash, 2*chunkSize+10, 10)
+		if err == nil {
+			_, err = io.ReadAll(reader)
+			reader.Close()
+		}
+		assert.ErrorIs(t, err, crypto.ErrChaChaDecryptionFailed)
+	})
+
+	_, err = s.RetrieveRange(ctx, crypto.SHA256Hex([]byte("missing")), 0, 10)
+	assert.ErrorIs(t, err, storage.ErrBlobNotFound)
+}
+
+func TestStreamingEncryptedStorage_RetrieveRangeWithScheme(t *testing.T) {
+	ctx := context.Background()
+	masterKey := bytes.Repeat([]byte{0x42}, 32)
+	s := newTestStreamingStorage(t, masterKey)
+
+	content := make([]byte, 100*1024)
+	_, err := rand.Read(content)
+	require.NoError(t, err)
+
+	// The same plaintext under every scheme, at distinct hashes
+	variant := func(tag byte) []byte {
+		plaintext := append([]byte(nil), content...)
+		plaintext[0] = tag
+		return plaintext
+	}
+	blobs := []struct {
+		name      string
+		scheme    string
+		plaintext []byte
+		hash      string
+	}{
+		{name: "unencrypted", scheme: "none", plaintext: variant(1)},
+		{name: "aes single seal", scheme: crypto.AESEncryptionScheme, plaintext: variant(2)},
+		{name: "aes", scheme: crypto.AESEncryptionScheme, plaintext: variant(3)},
+		{name: "chacha", scheme: crypto.ChaChaEncryptionScheme, plaintext: variant(4)},
+	}
+	for i := range blobs {
+		b := &blobs[i]
+		switch b.name {
+		case "unencrypted":
+			b.hash = crypto.SHA256Hex(b.plaintext)
+			writeBlobFile(t, s.GetPath(b.hash), b.plaintext)
+		case "aes single seal":
+			b.hash = storeSingleSealAESBlob(t, s, masterKey, b.plaintext)
+		case "aes":
+			b.hash = storeAESBlob(t, s, masterKey, b.plaintext)
+		default:
+			b.hash, err = s.Store(ctx, bytes.NewReader(b.plaintext), int64(len(b.plaintext)))
+			require.NoError(t, err)
+		}
+	}
+
+	for _, b := range blobs {
+		for _, r := range testRanges(len(b.plaintext), 1024) {
+			t.Run(b.name+"/"+r.name, func(t *testing.T) {
+				reader, err := s.RetrieveRangeWithScheme(ctx, b.hash, b.scheme, r.offset, r.length)
+				require.NoError(t, err)
+				defer reader.Close()
+
+				data, err := io.ReadAll(reader)
+				require.NoError(t, err)
+				assert.Equal(t, b.plaintext[r.start:r.end], data)
+			})
+		}
+	}
+
+	_, err = s.RetrieveRangeWithScheme(ctx, crypto.SHA256Hex([]byte("missing")), crypto.AESEncryptionScheme, 0, 10)
+	assert.ErrorIs(t, err, storage.ErrBlobNotFound)
+	_, err = s.RetrieveRangeWithScheme(ctx, blobs[0].hash, "rot13", 0, 10)
+	assert.ErrorIs(t, err, storage.ErrUnsupportedEncryptionScheme)
+}
@@ -6,6 +6,7 @@ package storage
 import (
 	"context"
 	"io"
+	"time"
 )
 
 // Backend defines the interface for storage backends.
@@ -91,6 +92,24 @@ type Backend interface {
 	HealthCheck(ctx context.Context) error
 }
 
+// BlobSweeper is implemented by backends that can enumerate the blobs they
+// hold. Garbage collection uses it to find blobs that no database row tracks,
+// such as blobs left behind by uploads that failed after storing content.
+type BlobSweeper interface {
+	// WalkBlobs calls fn for every stored blob with its on-disk size and
+	// modification time. Walking stops at the first error fn returns.
+	WalkBlobs(ctx context.Context, fn func(contentHash string, size int64, modTime time.Time) error) error
+
+	// DeleteIfUnmodifiedSince deletes a blob unless it was modified after
+	// cutoff. Store refreshes the modification time of a blob it deduplicates
+	// against, so a blob being stored again is never deleted.
+	//
+	// Returns:
+	//   - deleted: true if the blob was deleted
+	//   - err: ErrBlobNotFound if content doesn't exist, or other error
+	DeleteIfUnmodifiedSince(ctx context.Context, contentHash string, cutoff time.Time) (deleted bool, err error)
+}
+
 // ContentAddressableStorage extends Backend with reference counting support.
 // This interface is used when deduplication tracking is managed by the storage layer.
 // In our architecture, reference counting is handled by PostgreSQL, but this interface
@@ -0,0 +1,192 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// RetryConfig configures automatic retries of transient storage errors.
+type RetryConfig struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	// Values below 2 disable retries.
+	MaxAttempts int
+
+	// InitialBackoff is the delay before the first retry.
+	// The delay doubles after every attempt.
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the delay between attempts.
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryConfig returns the default retry configuration.
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxAttempts:    3,
+		InitialBackoff: 50 * time.Millisecond,
+		MaxBackoff:     time.Second,
+	}
+}
+
+// transientErrnos are system errors that may succeed when retried.
+var transientErrnos = []syscall.Errno{
+	syscall.EINTR,
+	syscall.EAGAIN,
+	syscall.EBUSY,
+	syscall.ENOSPC,
+	syscall.ETIMEDOUT,
+	syscall.ECONNRESET,
+	syscall.ECONNREFUSED,
+	syscall.EPIPE,
+}
+
+// IsTransient reports whether err is worth retrying.
+// Only errors known to be temporary are transient; everything else, including
+// ErrBlobNotFound, ErrInvalidContentHash and integrity failures, is permanent.
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, ErrBlobNotFound) || errors.Is(err, ErrInvalidContentHash) {
+		return false
+	}
+	if errors.Is(err, ErrTransient) {
+		return true
+	}
+	for _, errno := range transientErrnos {
+		if errors.Is(err, errno) {
+			return true
+		}
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return false
+}
+
+// RetryingBackend wraps a Backend and retries Store and Retrieve calls that
+// fail with transient errors, backing off exponentially between attempts.
+type RetryingBackend struct {
+	Backend
+	config RetryConfig
+	logger zerolog.Logger
+}
+
+// NewRetryingBackend creates a RetryingBackend around backend.
+func NewRetryingBackend(backend Backend, config RetryConfig, logger zerolog.Logger) *RetryingBackend {
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = DefaultRetryConfig().InitialBackoff
+	}
+	if config.MaxBackoff < config.InitialBackoff {
+		config.MaxBackoff = config.InitialBackoff
+	}
+	return &RetryingBackend{
+		Backend: backend,
+		config:  config,
+		logger:  logger.With().Str("component", "storage-retry").Logger(),
+	}
+}
+
+// Store stores content, retrying transient failures.
+// A retry needs to replay the content, so only readers implementing io.Seeker
+// are retried; other readers get a single attempt.
+func (b *RetryingBackend) Store(ctx context.Context, reader io.Reader, size int64) (string, error) {
+	seeker, ok := reader.(io.Seeker)
+	if !ok {
+		return b.Backend.Store(ctx, reader, size)
+	}
+	start, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return b.Backend.Store(ctx, reader, size)
+	}
+
+	var contentHash string
+	err = b.do(ctx, "store", func(attempt int) error {
+		if attempt > 1 {
+			if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+				return fmt.Errorf("failed to rewind content for retry: %w", err)
+			}
+		}
+		var storeErr error
+		contentHash, storeErr = b.Backend.Store(ctx, reader, size)
+		return storeErr
+	})
+	return contentHash, err
+}
+
+// Retrieve retrieves content, retrying transient failures to open it.
+func (b *RetryingBackend) Retrieve(ctx context.Context, contentHash string) (io.ReadCloser, error) {
+	var reader io.ReadCloser
+	err := b.do(ctx, "retrieve", func(int) error {
+		var retrieveErr error
+		reader, retrieveErr = b.Backend.Retrieve(ctx, contentHash)
+		return retrieveErr
+	})
+	return reader, err
+}
+
+// RetrieveRange retrieves a byte range, retrying transient failures.
+// It fails if the wrapped backend does not support range reads.
+func (b *RetryingBackend) RetrieveRange(ctx context.Context, contentHash string, offset, length int64) (io.ReadCloser, error) {
+	ranged, ok := b.Backend.(interface {
+		RetrieveRange(ctx context.Context, contentHash string, offset, length int64) (io.ReadCloser, error)
+	})
+	if !ok {
+		return nil, fmt.Errorf("storage backend does not support range requests")
+	}
+
+	var reader io.ReadCloser
+	err := b.do(ctx, "retrieve_range", func(int) error {
+		var retrieveErr error
+		reader, retrieveErr = ranged.RetrieveRange(ctx, contentHash, offset, length)
+		return retrieveErr
+	})
+	return reader, err
+}
+
+// Unwrap returns the wrapped backend.
+func (b *RetryingBackend) Unwrap() Backend {
+	return b.Backend
+}
+
+// do runs fn until it succeeds, fails permanently or runs out of attempts.
+func (b *RetryingBackend) do(ctx context.Context, op string, fn func(attempt int) error) error {
+	backoff := b.config.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		err := fn(attempt)
+		if err == nil || attempt >= b.config.MaxAttempts || !IsTransient(err) {
+			return err
+		}
+
+		b.logger.Warn().
+			Err(err).
+			Str("operation", op).
+			Int("attempt", attempt).
+			Dur("backoff", backoff).
+			Msg("transient storage error, retrying")
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+
+		backoff *= 2
+		if backoff > b.config.MaxBackoff {
+			backoff = b.config.MaxBackoff
+		}
+	}
+}
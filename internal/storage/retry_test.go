@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyBackend fails the first failures calls with err.
+type flakyBackend struct {
+	Backend
+	err      error
+	failures int
+	calls    int
+	stored   [][]byte
+}
+
+func (b *flakyBackend) Store(ctx context.Context, reader io.Reader, size int64) (string, error) {
+	b.calls++
+	data, _ := io.ReadAll(reader)
+	if b.calls <= b.failures {
+		return "", b.err
+	}
+	b.stored = append(b.stored, data)
+	return "hash", nil
+}
+
+func (b *flakyBackend) Retrieve(ctx context.Context, contentHash string) (io.ReadCloser, error) {
+	b.calls++
+	if b.calls <= b.failures {
+		return nil, b.err
+	}
+	return io.NopCloser(bytes.NewReader([]byte("content"))), nil
+}
+
+func newTestRetryingBackend(inner Backend) *RetryingBackend {
+	return NewRetryingBackend(inner, RetryConfig{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+	}, zerolog.Nop())
+}
+
+func TestRetryingBackend_TransientErrorRetriesAndSucceeds(t *testing.T) {
+	inner := &flakyBackend{
+		err:      fmt.Errorf("failed to write to temp file: %w", syscall.ENOSPC),
+		failures: 2,
+	}
+	backend := newTestRetryingBackend(inner)
+
+	hash, err := backend.Store(context.Background(), bytes.NewReader([]byte("payload")), 7)
+	require.NoError(t, err)
+	assert.Equal(t, "hash", hash)
+	assert.Equal(t, 3, inner.calls)
+	require.Len(t, inner.stored, 1)
+	assert.Equal(t, "payload", string(inner.stored[0]), "content must be replayed from the start")
+
+	inner.calls = 0
+	reader, err := backend.Retrieve(context.Background(), "hash")
+	require.NoError(t, err)
+	defer reader.Close()
+	assert.Equal(t, 3, inner.calls)
+}
+
+func TestRetryingBackend_PermanentErrorFailsImmediately(t *testing.T) {
+	for _, permanent := range []error{
+		ErrBlobNotFound,
+		ErrInvalidContentHash,
+		fmt.Errorf("size mismatch: expected %d, got %d", 7, 3),
+	} {
+		inner := &flakyBackend{err: permanent, failures: 10}
+		backend := newTestRetryingBackend(inner)
+
+		_, err := backend.Retrieve(context.Background(), "hash")
+		assert.ErrorIs(t, err, permanent)
+		assert.Equal(t, 1, inner.calls, "permanent error %q must not be retried", permanent)
+	}
+}
+
+func TestRetryingBackend_GivesUpAfterMaxAttempts(t *testing.T) {
+	inner := &flakyBackend{err: fmt.Errorf("upload: %w", ErrTransient), failures: 10}
+	backend := newTestRetryingBackend(inner)
+
+	_, err := backend.Store(context.Background(), bytes.NewReader([]byte("payload")), 7)
+	assert.ErrorIs(t, err, ErrTransient)
+	assert.Equal(t, 3, inner.calls)
+}
+
+func TestRetryingBackend_NonSeekableReaderIsNotRetried(t *testing.T) {
+	inner := &flakyBackend{err: syscall.EINTR, failures: 1}
+	backend := newTestRetryingBackend(inner)
+
+	_, err := backend.Store(context.Background(), io.LimitReader(bytes.NewReader([]byte("payload")), 7), 7)
+	assert.ErrorIs(t, err, syscall.EINTR)
+	assert.Equal(t, 1, inner.calls)
+}
+
+func TestIsStorageFull(t *testing.T) {
+	assert.True(t, IsStorageFull(fmt.Errorf("failed to write to temp file: %w", syscall.ENOSPC)))
+	assert.True(t, IsStorageFull(fmt.Errorf("failed to move file to storage: %w", syscall.EDQUOT)))
+	assert.True(t, IsStorageFull(ErrStorageFull))
+	assert.False(t, IsStorageFull(errors.New("disk full")))
+	assert.False(t, IsStorageFull(ErrBlobNotFound))
+}
@@ -3,6 +3,8 @@ package tiering
 
 import (
 	"context"
+	"fmt"
+	"regexp"
 	"sync"
 	"time"
 
@@ -87,10 +89,22 @@ func (t *MemoryAccessTracker) GetBlobsForTiering(ctx context.Context, policy Pol
 	t.mu.RLock()
 	defer t.mu.RUnlock()
 
+	var bucketFilter *regexp.Regexp
+	if policy.BucketFilter != "" {
+		var err error
+		if bucketFilter, err = regexp.Compile(policy.BucketFilter); err != nil {
+			return nil, fmt.Errorf("%w: bucket filter: %v", ErrInvalidPolicy, err)
+		}
+	}
+
 	var candidates []*BlobAccessInfo
 	now := time.Now()
 
 	for _, info := range t.blobs {
+		if bucketFilter != nil && !bucketFilter.MatchString(info.BucketName) {
+			continue
+		}
+
 		// Check size constraints
 		if policy.MinSize > 0 && info.Size < policy.MinSize {
 			continue
@@ -212,6 +212,44 @@ func TestMemoryAccessTracker_GetBlobsForTiering(t *testing.T) {
 	require.True(t, hashes["warm-old"])
 }
 
+func TestMemoryAccessTracker_GetBlobsForTieringBucketFilter(t *testing.T) {
+	tracker := NewMemoryAccessTracker(zerolog.Nop())
+	ctx := context.Background()
+	stale := time.Now().AddDate(0, 0, -60)
+
+	for hash, bucket := range map[string]string{
+		"a1": "archive-2023",
+		"a2": "archive-2024",
+		"l1": "logs",
+		"m1": "media-archive-backup",
+	} {
+		require.NoError(t, tracker.RegisterBlob(ctx, &BlobAccessInfo{
+			ContentHash:    hash,
+			CurrentTier:    TierHot,
+			Size:           2 * 1024 * 1024,
+			LastAccessedAt: stale,
+			BucketName:     bucket,
+		}))
+	}
+
+	policy := DefaultPolicyConfig()
+	policy.BucketFilter = "^archive-.*"
+
+	blobs, err := tracker.GetBlobsForTiering(ctx, policy, 0)
+	require.NoError(t, err)
+
+	var hashes []string
+	for _, b := range blobs {
+		hashes = append(hashes, b.ContentHash)
+	}
+	require.ElementsMatch(t, []string{"a1", "a2"}, hashes)
+
+	// An invalid pattern is an error, not a match-all
+	policy.BucketFilter = "archive-("
+	_, err = tracker.GetBlobsForTiering(ctx, policy, 0)
+	require.ErrorIs(t, err, ErrInvalidPolicy)
+}
+
 func TestMemoryAccessTracker_Cleanup(t *testing.T) {
 	tracker := NewMemoryAccessTracker(zerolog.Nop())
 	ctx := context.Background()
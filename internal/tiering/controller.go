@@ -5,20 +5,25 @@ package tiering
 import (
 	"context"
 	"errors"
+	"fmt"
+	"regexp"
 	"sync"
 	"time"
 
 	"github.com/rs/zerolog"
 
 	"github.com/prn-tf/alexander-storage/internal/cluster"
+	"github.com/prn-tf/alexander-storage/internal/metrics"
 )
 
 // Common errors for the tiering package.
 var (
-	ErrNoTargetNode      = errors.New("no suitable target node found")
-	ErrTieringInProgress = errors.New("tiering already in progress for this blob")
-	ErrInvalidPolicy     = errors.New("invalid tiering policy")
-	ErrMigrationFailed   = errors.New("migration failed")
+	ErrNoTargetNode       = errors.New("no suitable target node found")
+	ErrTieringInProgress  = errors.New("tiering already in progress for this blob")
+	ErrInvalidPolicy      = errors.New("invalid tiering policy")
+	ErrMigrationFailed    = errors.New("migration failed")
+	ErrMigrationNotFound  = errors.New("no active migration for this blob")
+	ErrMigrationCancelled = errors.New("migration cancelled")
 )
 
 // Tier represents a storage tier.
@@ -138,7 +143,7 @@ type MigrationStatus struct {
 	TargetTier Tier `json:"target_tier"`
 
 	// Status is the current migration status.
-	Status string `json:"status"` // "pending", "in_progress", "completed", "failed"
+	Status string `json:"status"` // "pending", "in_progress", "completed", "failed", "cancelled"
 
 	// StartedAt is when the migration started.
 	StartedAt time.Time `json:"started_at,omitempty"`
@@ -202,6 +207,7 @@ type TieringController struct {
 	clusterMgr    cluster.ClusterManager
 	nodeSelector  cluster.NodeSelector
 	accessTracker AccessTracker
+	metrics       *metrics.Metrics // Optional - counts finished migrations
 
 	// Policies
 	policiesMu sync.RWMutex
@@ -209,7 +215,8 @@ type TieringController struct {
 
 	// In-flight migrations
 	migrationsMu sync.RWMutex
-	migrations   map[string]*MigrationStatus // contentHash -> status
+	migrations   map[string]*MigrationStatus   // contentHash -> status
+	cancels      map[string]context.CancelFunc // contentHash -> cancel of an active migration
 
 	// Migration semaphore
 	migrationSem chan struct{}
@@ -219,7 +226,9 @@ type TieringController struct {
 	wg         sync.WaitGroup
 }
 
-// NewTieringController creates a new tiering controller.
+// NewTieringController creates a new tiering controller. clusterMgr and
+// nodeSelector may be nil on a single node, where policies are evaluated but
+// migrations fail with ErrNoTargetNode.
 func NewTieringController(
 	config ControllerConfig,
 	clusterMgr cluster.ClusterManager,
@@ -245,6 +254,7 @@ func NewTieringController(
 		accessTracker: accessTracker,
 		policies:      make(map[string]PolicyConfig),
 		migrations:    make(map[string]*MigrationStatus),
+		cancels:       make(map[string]context.CancelFunc),
 		migrationSem:  make(chan struct{}, config.MaxConcurrentMigrations),
 		shutdownCh:    make(chan struct{}),
 	}
@@ -255,6 +265,11 @@ func NewTieringController(
 	return c
 }
 
+// SetMetrics sets the metrics that count finished migrations.
+func (c *TieringController) SetMetrics(m *metrics.Metrics) {
+	c.metrics = m
+}
+
 // Start begins the tiering controller's background processing.
 func (c *TieringController) Start(ctx context.Context) error {
 	c.logger.Info().
@@ -320,34 +335,96 @@ func (c *TieringController) scan(ctx context.Context) {
 		default:
 		}
 
-		c.processPolicy(ctx, policy)
+		c.processPolicy(ctx, policy, "", false)
 	}
 
 	c.logger.Debug().Msg("Tiering scan completed")
 }
 
-// processPolicy evaluates and executes a single policy.
-func (c *TieringController) processPolicy(ctx context.Context, policy PolicyConfig) {
-	blobs, err := c.accessTracker.GetBlobsForTiering(ctx, policy, c.config.MigrationBatchSize)
+// processPolicy evaluates a single policy and returns the decisions made.
+// A non-empty bucket restricts evaluation to the blobs of that bucket.
+// Decisions are executed unless dryRun is set.
+func (c *TieringController) processPolicy(ctx context.Context, policy PolicyConfig, bucket string, dryRun bool) []*TieringDecision {
+	limit := c.config.MigrationBatchSize
+	if bucket != "" {
+		// Trackers that honor the bucket filter narrow the candidates themselves;
+		// the rest are filtered below, so the batch limit applies after filtering.
+		policy.BucketFilter = "^" + regexp.QuoteMeta(bucket) + "$"
+		limit = 0
+	}
+
+	blobs, err := c.accessTracker.GetBlobsForTiering(ctx, policy, limit)
 	if err != nil {
 		c.logger.Error().Err(err).Str("policy_id", policy.ID).Msg("Failed to get blobs for tiering")
-		return
+		return nil
 	}
 
+	var decisions []*TieringDecision
+	evaluated := 0
 	for _, blob := range blobs {
 		select {
 		case <-c.shutdownCh:
-			return
+			return decisions
 		case <-ctx.Done():
-			return
+			return decisions
 		default:
 		}
 
+		if bucket != "" && blob.BucketName != bucket {
+			continue
+		}
+		if evaluated++; evaluated > c.config.MigrationBatchSize {
+			break
+		}
+
 		decision := c.evaluateBlob(blob, policy)
-		if decision != nil {
+		if decision == nil {
+			continue
+		}
+		decisions = append(decisions, decision)
+		if !dryRun {
 			c.executeTiering(ctx, decision)
 		}
 	}
+
+	return decisions
+}
+
+// EvaluateBucket runs every enabled policy whose bucket filter matches bucket
+// against that bucket's blobs only, and returns the decisions made. Unless
+// dryRun is set, the decisions are executed in the background like those of
+// a regular scan.
+func (c *TieringController) EvaluateBucket(ctx context.Context, bucket string, dryRun bool) []*TieringDecision {
+	// Migrations must outlive the request that triggered them
+	ctx = context.WithoutCancel(ctx)
+
+	c.policiesMu.RLock()
+	policies := make([]PolicyConfig, 0, len(c.policies))
+	for _, p := range c.policies {
+		if p.Enabled {
+			policies = append(policies, p)
+		}
+	}
+	c.policiesMu.RUnlock()
+
+	decisions := []*TieringDecision{}
+	for _, policy := range policies {
+		if policy.BucketFilter != "" {
+			matched, err := regexp.MatchString(policy.BucketFilter, bucket)
+			if err != nil || !matched {
+				continue
+			}
+		}
+		decisions = append(decisions, c.processPolicy(ctx, policy, bucket, dryRun)...)
+	}
+
+	c.logger.Info().
+		Str("bucket", bucket).
+		Bool("dry_run", dryRun).
+		Int("decisions", len(decisions)).
+		Msg("Bucket tiering evaluation completed")
+
+	return decisions
 }
 
 // evaluateBlob evaluates a blob against a policy and returns a tiering decision.
@@ -362,12 +439,12 @@ func (c *TieringController) evaluateBlob(blob *BlobAccessInfo, policy PolicyConf
 	case TierHot:
 		if daysSinceAccess >= policy.HotToWarmDays {
 			targetTier = TierWarm
-			reason = "No access for " + string(rune(daysSinceAccess)) + " days (threshold: " + string(rune(policy.HotToWarmDays)) + ")"
+			reason = fmt.Sprintf("No access for %d days (threshold: %d)", daysSinceAccess, policy.HotToWarmDays)
 		}
 	case TierWarm:
 		if daysSinceAccess >= policy.WarmToColdDays {
 			targetTier = TierCold
-			reason = "No access for " + string(rune(daysSinceAccess)) + " days (threshold: " + string(rune(policy.WarmToColdDays)) + ")"
+			reason = fmt.Sprintf("No access for %d days (threshold: %d)", daysSinceAccess, policy.WarmToColdDays)
 		}
 	case TierCold:
 		// Already in coldest tier, no action needed
@@ -419,6 +496,7 @@ func (c *TieringController) executeTiering(ctx context.Context, decision *Tierin
 }
 
 // migrateBlob performs the actual migration of a blob.
+// The migration runs under its own context so CancelMigration can abort it.
 func (c *TieringController) migrateBlob(ctx context.Context, decision *TieringDecision) {
 	logger := c.logger.With().
 		Str("content_hash", decision.ContentHash).
@@ -426,6 +504,9 @@ func (c *TieringController) migrateBlob(ctx context.Context, decision *TieringDe
 		Str("target_tier", string(decision.TargetTier)).
 		Logger()
 
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	// Create migration status
 	status := &MigrationStatus{
 		ContentHash: decision.ContentHash,
@@ -436,54 +517,112 @@ func (c *TieringController) migrateBlob(ctx context.Context, decision *TieringDe
 
 	c.migrationsMu.Lock()
 	c.migrations[decision.ContentHash] = status
+	c.cancels[decision.ContentHash] = cancel
 	c.migrationsMu.Unlock()
 
 	defer func() {
+		c.migrationsMu.Lock()
+		delete(c.cancels, decision.ContentHash)
+		finished := *status
+		c.migrationsMu.Unlock()
+
+		if c.metrics != nil {
+			c.metrics.RecordTieringMigration(string(finished.SourceTier), string(finished.TargetTier), finished.Status, finished.BytesTransferred)
+		}
+
 		// Keep completed/failed status for a while before removing
 		time.AfterFunc(5*time.Minute, func() {
 			c.migrationsMu.Lock()
-			delete(c.migrations, decision.ContentHash)
+			if c.migrations[decision.ContentHash] == status {
+				delete(c.migrations, decision.ContentHash)
+			}
 			c.migrationsMu.Unlock()
 		})
 	}()
 
+	// fail records a failed or cancelled migration.
+	fail := func(err error) {
+		if ctx.Err() != nil {
+			logger.Warn().Msg("Blob migration cancelled")
+			c.updateStatus(status, func(s *MigrationStatus) {
+				s.Status = "cancelled"
+				s.Error = ErrMigrationCancelled.Error()
+				s.CompletedAt = time.Now()
+			})
+			return
+		}
+		c.updateStatus(status, func(s *MigrationStatus) {
+			s.Status = "failed"
+			s.Error = err.Error()
+		})
+	}
+
+	// Without a cluster there is no node of another tier to move the blob to
+	if c.clusterMgr == nil || c.nodeSelector == nil {
+		logger.Warn().Msg("Clustering is disabled, no target node for migration")
+		fail(ErrNoTargetNode)
+		return
+	}
+
 	// Find target node
 	targetRole := cluster.NodeRole(decision.TargetTier)
 	targetNode, err := c.nodeSelector.SelectForTiering(ctx, decision.ContentHash, targetRole)
 	if err != nil {
 		logger.Error().Err(err).Msg("Failed to select target node")
-		status.Status = "failed"
-		status.Error = err.Error()
+		fail(err)
 		return
 	}
 
 	if targetNode == nil {
 		logger.Warn().Msg("No suitable target node found")
-		status.Status = "failed"
-		status.Error = ErrNoTargetNode.Error()
+		fail(ErrNoTargetNode)
 		return
 	}
 
-	status.TargetNodeID = targetNode.ID
-	status.Status = "in_progress"
-	status.StartedAt = time.Now()
+	c.updateStatus(status, func(s *MigrationStatus) {
+		s.TargetNodeID = targetNode.ID
+		s.Status = "in_progress"
+		s.StartedAt = time.Now()
+	})
 
 	logger.Info().
 		Str("target_node", targetNode.ID).
 		Msg("Starting blob migration")
 
+	// Get target client
+	targetClient, err := c.clusterMgr.GetClientForNode(ctx, targetNode.ID)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to get target client")
+		fail(err)
+		return
+	}
+
+	// The target may already hold the blob, e.g. from an earlier replication.
+	// Nodes store blobs under the hash of the content they received, so an
+	// existing blob is a verified copy and only its location is missing.
+	exists, err := targetClient.BlobExists(ctx, decision.ContentHash)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Failed to check target for blob, transferring anyway")
+	} else if exists {
+		c.registerTargetLocation(ctx, decision.ContentHash, targetNode.ID)
+		c.updateStatus(status, func(s *MigrationStatus) {
+			s.Status = "completed"
+			s.CompletedAt = time.Now()
+		})
+		logger.Info().Msg("Blob already present on target, registered location without transfer")
+		return
+	}
+
 	// Get source locations
 	locations, err := c.clusterMgr.GetBlobLocations(ctx, decision.ContentHash)
 	if err != nil {
 		logger.Error().Err(err).Msg("Failed to get blob locations")
-		status.Status = "failed"
-		status.Error = err.Error()
+		fail(err)
 		return
 	}
 	if len(locations) == 0 {
 		logger.Error().Msg("No source locations found for blob")
-		status.Status = "failed"
-		status.Error = "no source locations"
+		fail(errors.New("no source locations"))
 		return
 	}
 
@@ -506,68 +645,100 @@ func (c *TieringController) migrateBlob(ctx context.Context, decision *TieringDe
 
 	if sourceClient == nil {
 		logger.Error().Msg("No healthy source node found")
-		status.Status = "failed"
-		status.Error = "no healthy source node"
+		fail(errors.New("no healthy source node"))
 		return
 	}
 
-	status.SourceNodeID = sourceNodeID
+	c.updateStatus(status, func(s *MigrationStatus) {
+		s.SourceNodeID = sourceNodeID
+	})
 
 	// Retrieve blob from source
 	reader, err := sourceClient.RetrieveBlob(ctx, decision.ContentHash)
 	if err != nil {
 		logger.Error().Err(err).Msg("Failed to retrieve blob from source")
-		status.Status = "failed"
-		status.Error = err.Error()
+		fail(err)
 		return
 	}
 	defer reader.Close()
 
-	// Get target client
-	targetClient, err := c.clusterMgr.GetClientForNode(ctx, targetNode.ID)
-	if err != nil {
-		logger.Error().Err(err).Msg("Failed to get target client")
-		status.Status = "failed"
-		status.Error = err.Error()
-		return
-	}
-
 	// Get blob size
 	accessInfo, err := c.accessTracker.GetAccessInfo(ctx, decision.ContentHash)
 	if err != nil {
 		logger.Error().Err(err).Msg("Failed to get blob access info")
-		status.Status = "failed"
-		status.Error = err.Error()
+		fail(err)
 		return
 	}
 
 	// Transfer blob to target
 	err = targetClient.TransferBlob(ctx, decision.ContentHash, accessInfo.Size, reader)
+	if err == nil {
+		err = ctx.Err()
+	}
 	if err != nil {
+		// The target may hold a partial copy
+		c.rollbackTarget(targetClient, decision.ContentHash, logger)
 		logger.Error().Err(err).Msg("Failed to transfer blob to target")
-		status.Status = "failed"
-		status.Error = err.Error()
+		fail(err)
 		return
 	}
 
-	status.BytesTransferred = accessInfo.Size
+	c.registerTargetLocation(ctx, decision.ContentHash, targetNode.ID)
 
-	// Register new location
-	newLocation := &cluster.BlobLocation{
-		ContentHash: decision.ContentHash,
-		NodeID:      targetNode.ID,
+	c.updateStatus(status, func(s *MigrationStatus) {
+		s.BytesTransferred = accessInfo.Size
+		s.Status = "completed"
+		s.CompletedAt = time.Now()
+	})
+
+	logger.Info().
+		Int64("bytes_transferred", accessInfo.Size).
+		Dur("duration", status.CompletedAt.Sub(status.StartedAt)).
+		Msg("Blob migration completed")
+}
+
+// registerTargetLocation records that the target node holds a copy of the blob.
+func (c *TieringController) registerTargetLocation(ctx context.Context, contentHash, nodeID string) {
+	c.clusterMgr.RegisterBlobLocation(ctx, &cluster.BlobLocation{
+		ContentHash: contentHash,
+		NodeID:      nodeID,
 		IsPrimary:   false,
 		SyncedAt:    time.Now(),
+	})
+}
+
+// updateStatus applies fn to a migration status under the migrations lock.
+func (c *TieringController) updateStatus(status *MigrationStatus, fn func(*MigrationStatus)) {
+	c.migrationsMu.Lock()
+	fn(status)
+	c.migrationsMu.Unlock()
+}
+
+// rollbackTarget removes a partially transferred blob from the target node.
+func (c *TieringController) rollbackTarget(target cluster.NodeClient, contentHash string, logger zerolog.Logger) {
+	// The migration context may already be cancelled
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := target.DeleteBlob(ctx, contentHash); err != nil {
+		logger.Error().Err(err).Msg("Failed to remove partial blob from target")
 	}
-	c.clusterMgr.RegisterBlobLocation(ctx, newLocation)
+}
 
-	status.Status = "completed"
-	status.CompletedAt = time.Now()
+// CancelMigration aborts the active migration of a blob.
+// The partial copy on the target node is removed and the status becomes "cancelled".
+func (c *TieringController) CancelMigration(contentHash string) error {
+	c.migrationsMu.RLock()
+	cancel, exists := c.cancels[contentHash]
+	c.migrationsMu.RUnlock()
 
-	logger.Info().
-		Int64("bytes_transferred", status.BytesTransferred).
-		Dur("duration", status.CompletedAt.Sub(status.StartedAt)).
-		Msg("Blob migration completed")
+	if !exists {
+		return ErrMigrationNotFound
+	}
+
+	c.logger.Info().Str("content_hash", contentHash).Msg("Cancelling blob migration")
+	cancel()
+	return nil
 }
 
 // AddPolicy adds or updates a tiering policy.
@@ -575,6 +746,11 @@ func (c *TieringController) AddPolicy(policy PolicyConfig) error {
 	if policy.ID == "" {
 		return ErrInvalidPolicy
 	}
+	if policy.BucketFilter != "" {
+		if _, err := regexp.Compile(policy.BucketFilter); err != nil {
+			return fmt.Errorf("%w: bucket filter: %v", ErrInvalidPolicy, err)
+		}
+	}
 
 	c.policiesMu.Lock()
 	c.policies[policy.ID] = policy
@@ -659,6 +835,7 @@ func (c *TieringController) ForceMove(ctx context.Context, contentHash string, t
 	// Get current blob info
 	accessInfo, err := c.accessTracker.GetAccessInfo(ctx, contentHash)
 	if err != nil {
+		c.logger.Error().Err(err).Str("content_hash", contentHash).Msg("Failed to get blob access info")
 		return err
 	}
 
@@ -683,7 +860,10 @@ func (c *TieringController) ForceMove(ctx context.Context, contentHash string, t
 		return ErrMigrationFailed
 	}
 
-	if status.Status == "failed" {
+	switch status.Status {
+	case "cancelled":
+		return ErrMigrationCancelled
+	case "failed":
 		return errors.New(status.Error)
 	}
 
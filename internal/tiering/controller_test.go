@@ -0,0 +1,291 @@
+package tiering
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/cluster"
+	"github.com/prn-tf/alexander-storage/internal/metrics"
+)
+
+// fakeClusterManager serves fixed clients and records registered locations.
+type fakeClusterManager struct {
+	cluster.ClusterManager
+	clients   map[string]cluster.NodeClient
+	locations []*cluster.BlobLocation
+
+	mu         sync.Mutex
+	registered []*cluster.BlobLocation
+}
+
+func (m *fakeClusterManager) GetBlobLocations(ctx context.Context, contentHash string) ([]*cluster.BlobLocation, error) {
+	return m.locations, nil
+}
+
+func (m *fakeClusterManager) GetClientForNode(ctx context.Context, nodeID string) (cluster.NodeClient, error) {
+	return m.clients[nodeID], nil
+}
+
+func (m *fakeClusterManager) RegisterBlobLocation(ctx context.Context, location *cluster.BlobLocation) error {
+	m.mu.Lock()
+	m.registered = append(m.registered, location)
+	m.mu.Unlock()
+	return nil
+}
+
+// fakeNodeSelector always selects the same target node.
+type fakeNodeSelector struct {
+	cluster.NodeSelector
+	target *cluster.Node
+}
+
+func (s *fakeNodeSelector) SelectForTiering(ctx context.Context, contentHash string, targetRole cluster.NodeRole) (*cluster.Node, error) {
+	return s.target, nil
+}
+
+// stallingClient stores part of a transfer and then blocks until cancelled.
+type stallingClient struct {
+	*cluster.MockClient
+	started chan struct{}
+}
+
+func (c *stallingClient) TransferBlob(ctx context.Context, contentHash string, size int64, reader io.Reader) error {
+	if err := c.MockClient.TransferBlob(ctx, contentHash, size/2, io.LimitReader(reader, size/2)); err != nil {
+		return err
+	}
+	close(c.started)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestTieringController_CancelMigration(t *testing.T) {
+	ctx := context.Background()
+	const contentHash = "abc123"
+	payload := []byte("0123456789abcdef")
+
+	source := cluster.NewMockClient("hot-1", "hot-1:9090", cluster.NodeRoleHot)
+	require.NoError(t, source.TransferBlob(ctx, contentHash, int64(len(payload)), bytes.NewReader(payload)))
+	target := &stallingClient{
+		MockClient: cluster.NewMockClient("cold-1", "cold-1:9090", cluster.NodeRoleCold),
+		started:    make(chan struct{}),
+	}
+
+	clusterMgr := &fakeClusterManager{
+		clients:   map[string]cluster.NodeClient{"hot-1": source, "cold-1": target},
+		locations: []*cluster.BlobLocation{{ContentHash: contentHash, NodeID: "hot-1", IsPrimary: true}},
+	}
+	selector := &fakeNodeSelector{target: &cluster.Node{ID: "cold-1", Role: cluster.NodeRoleCold}}
+
+	tracker := NewMemoryAccessTracker(zerolog.Nop())
+	require.NoError(t, tracker.RegisterBlob(ctx, &BlobAccessInfo{
+		ContentHash: contentHash,
+		CurrentTier: TierHot,
+		Size:        int64(len(payload)),
+	}))
+
+	c := NewTieringController(DefaultControllerConfig(), clusterMgr, selector, tracker, zerolog.Nop())
+
+	result := make(chan error, 1)
+	go func() {
+		result <- c.ForceMove(ctx, contentHash, TierCold)
+	}()
+
+	select {
+	case <-target.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("migration never started transferring")
+	}
+
+	active := c.GetActiveMigrations()
+	require.Len(t, active, 1)
+	require.Equal(t, contentHash, active[0].ContentHash)
+	require.Equal(t, "in_progress", active[0].Status)
+
+	require.NoError(t, c.CancelMigration(contentHash))
+
+	select {
+	case err := <-result:
+		require.ErrorIs(t, err, ErrMigrationCancelled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("migration did not abort after cancellation")
+	}
+
+	status, ok := c.GetMigrationStatus(contentHash)
+	require.True(t, ok)
+	require.Equal(t, "cancelled", status.Status)
+	require.Empty(t, c.GetActiveMigrations())
+
+	// The partial copy is rolled back and no location is registered
+	exists, err := target.BlobExists(ctx, contentHash)
+	require.NoError(t, err)
+	require.False(t, exists)
+	require.Empty(t, clusterMgr.registered)
+
+	// The source copy is untouched
+	exists, err = source.BlobExists(ctx, contentHash)
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	require.ErrorIs(t, c.CancelMigration(contentHash), ErrMigrationNotFound)
+}
+
+// countingClient counts the transfers it receives.
+type countingClient struct {
+	*cluster.MockClient
+	transfers int
+}
+
+func (c *countingClient) TransferBlob(ctx context.Context, contentHash string, size int64, reader io.Reader) error {
+	c.transfers++
+	return c.MockClient.TransferBlob(ctx, contentHash, size, reader)
+}
+
+func TestTieringController_MigrateBlobAlreadyOnTarget(t *testing.T) {
+	ctx := context.Background()
+	const contentHash = "def456"
+	payload := []byte("already replicated")
+
+	source := cluster.NewMockClient("hot-1", "hot-1:9090", cluster.NodeRoleHot)
+	require.NoError(t, source.TransferBlob(ctx, contentHash, int64(len(payload)), bytes.NewReader(payload)))
+	target := &countingClient{MockClient: cluster.NewMockClient("cold-1", "cold-1:9090", cluster.NodeRoleCold)}
+	require.NoError(t, target.MockClient.TransferBlob(ctx, contentHash, int64(len(payload)), bytes.NewReader(payload)))
+
+	clusterMgr := &fakeClusterManager{
+		clients:   map[string]cluster.NodeClient{"hot-1": source, "cold-1": target},
+		locations: []*cluster.BlobLocation{{ContentHash: contentHash, NodeID: "hot-1", IsPrimary: true}},
+	}
+	selector := &fakeNodeSelector{target: &cluster.Node{ID: "cold-1", Role: cluster.NodeRoleCold}}
+
+	tracker := NewMemoryAccessTracker(zerolog.Nop())
+	require.NoError(t, tracker.RegisterBlob(ctx, &BlobAccessInfo{
+		ContentHash: contentHash,
+		CurrentTier: TierHot,
+		Size:        int64(len(payload)),
+	}))
+
+	c := NewTieringController(DefaultControllerConfig(), clusterMgr, selector, tracker, zerolog.Nop())
+	require.NoError(t, c.ForceMove(ctx, contentHash, TierCold))
+
+	require.Zero(t, target.transfers)
+	require.Len(t, clusterMgr.registered, 1)
+	require.Equal(t, "cold-1", clusterMgr.registered[0].NodeID)
+	require.Equal(t, contentHash, clusterMgr.registered[0].ContentHash)
+
+	status, ok := c.GetMigrationStatus(contentHash)
+	require.True(t, ok)
+	require.Equal(t, "completed", status.Status)
+	require.Zero(t, status.BytesTransferred)
+}
+
+func TestTieringController_MigrateBlobRecordsMetrics(t *testing.T) {
+	ctx := context.Background()
+	const contentHash = "0a1b2c"
+	payload := []byte("cold data")
+
+	source := cluster.NewMockClient("hot-1", "hot-1:9090", cluster.NodeRoleHot)
+	require.NoError(t, source.TransferBlob(ctx, contentHash, int64(len(payload)), bytes.NewReader(payload)))
+	target := cluster.NewMockClient("cold-1", "cold-1:9090", cluster.NodeRoleCold)
+
+	clusterMgr := &fakeClusterManager{
+		clients:   map[string]cluster.NodeClient{"hot-1": source, "cold-1": target},
+		locations: []*cluster.BlobLocation{{ContentHash: contentHash, NodeID: "hot-1", IsPrimary: true}},
+	}
+	selector := &fakeNodeSelector{target: &cluster.Node{ID: "cold-1", Role: cluster.NodeRoleCold}}
+
+	tracker := NewMemoryAccessTracker(zerolog.Nop())
+	require.NoError(t, tracker.RegisterBlob(ctx, &BlobAccessInfo{
+		ContentHash: contentHash,
+		CurrentTier: TierHot,
+		Size:        int64(len(payload)),
+	}))
+
+	m := metrics.NewWithRegisterer(prometheus.NewRegistry())
+	c := NewTieringController(DefaultControllerConfig(), clusterMgr, selector, tracker, zerolog.Nop())
+	c.SetMetrics(m)
+	require.NoError(t, c.ForceMove(ctx, contentHash, TierCold))
+
+	require.Equal(t, 1.0, testutil.ToFloat64(m.TieringMigrationsTotal.WithLabelValues("hot", "cold", "completed")))
+	require.Equal(t, float64(len(payload)), testutil.ToFloat64(m.TieringBytesMigrated))
+}
+
+func TestTieringController_EvaluateBucket(t *testing.T) {
+	ctx := context.Background()
+	stale := time.Now().AddDate(0, 0, -60)
+
+	tracker := NewMemoryAccessTracker(zerolog.Nop())
+	for hash, bucket := range map[string]string{
+		"logs-1":  "logs",
+		"logs-2":  "logs",
+		"media-1": "media",
+		"other-1": "logs-archive",
+	} {
+		require.NoError(t, tracker.RegisterBlob(ctx, &BlobAccessInfo{
+			ContentHash:    hash,
+			CurrentTier:    TierHot,
+			Size:           2 * 1024 * 1024,
+			LastAccessedAt: stale,
+			BucketName:     bucket,
+		}))
+	}
+
+	c := NewTieringController(DefaultControllerConfig(), &fakeClusterManager{}, &fakeNodeSelector{}, tracker, zerolog.Nop())
+	// A policy scoped to another bucket does not apply
+	require.NoError(t, c.AddPolicy(PolicyConfig{ID: "media-only", Enabled: true, HotToWarmDays: 1, BucketFilter: "^media$"}))
+
+	decisions := c.EvaluateBucket(ctx, "logs", true)
+
+	var hashes []string
+	for _, d := range decisions {
+		require.Equal(t, "default", d.PolicyID)
+		require.Equal(t, TierWarm, d.TargetTier)
+		hashes = append(hashes, d.ContentHash)
+	}
+	require.ElementsMatch(t, []string{"logs-1", "logs-2"}, hashes)
+
+	// A dry run only reports decisions
+	require.Empty(t, c.GetActiveMigrations())
+	_, ok := c.GetMigrationStatus("logs-1")
+	require.False(t, ok)
+}
+
+func TestTieringController_EvaluateBlobReason(t *testing.T) {
+	c := NewTieringController(DefaultControllerConfig(), &fakeClusterManager{}, &fakeNodeSelector{}, NewMemoryAccessTracker(zerolog.Nop()), zerolog.Nop())
+	policy := PolicyConfig{ID: "p", Enabled: true, HotToWarmDays: 30, WarmToColdDays: 90}
+
+	decision := c.evaluateBlob(&BlobAccessInfo{
+		ContentHash:    "hot",
+		CurrentTier:    TierHot,
+		LastAccessedAt: time.Now().Add(-45 * 24 * time.Hour),
+	}, policy)
+	require.NotNil(t, decision)
+	require.Equal(t, "No access for 45 days (threshold: 30)", decision.Reason)
+
+	decision = c.evaluateBlob(&BlobAccessInfo{
+		ContentHash:    "warm",
+		CurrentTier:    TierWarm,
+		LastAccessedAt: time.Now().Add(-120 * 24 * time.Hour),
+	}, policy)
+	require.NotNil(t, decision)
+	require.Equal(t, "No access for 120 days (threshold: 90)", decision.Reason)
+}
+
+func TestTieringController_AddPolicyRejectsInvalidBucketFilter(t *testing.T) {
+	c := NewTieringController(DefaultControllerConfig(), nil, nil, NewMemoryAccessTracker(zerolog.Nop()), zerolog.Nop())
+
+	err := c.AddPolicy(PolicyConfig{ID: "bad", Enabled: true, BucketFilter: "archive-("})
+	require.ErrorIs(t, err, ErrInvalidPolicy)
+	for _, p := range c.GetPolicies() {
+		require.NotEqual(t, "bad", p.ID)
+	}
+
+	require.NoError(t, c.AddPolicy(PolicyConfig{ID: "archive", Enabled: true, BucketFilter: "^archive-.*"}))
+}
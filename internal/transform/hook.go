@@ -0,0 +1,127 @@
+package transform
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog"
+
+	"github.com/prn-tf/alexander-storage/internal/events"
+	"github.com/prn-tf/alexander-storage/internal/service"
+)
+
+// DefaultMaxSourceSize is the largest object the Hook transforms when no
+// limit is configured.
+const DefaultMaxSourceSize = 32 * 1024 * 1024 // 32MB
+
+// Config configures the Hook.
+type Config struct {
+	// ContentTypes restricts which uploads are transformed. Empty means every
+	// content type some transformer accepts.
+	ContentTypes []string
+
+	// MaxSourceSize skips objects larger than this many bytes.
+	MaxSourceSize int64
+}
+
+// Hook is an event subscriber that runs transformers on newly created
+// objects and stores their artifacts as derived objects.
+type Hook struct {
+	objects       *service.ObjectService
+	transformers  []Transformer
+	contentTypes  map[string]bool
+	maxSourceSize int64
+	logger        zerolog.Logger
+}
+
+// NewHook creates a Hook that reads sources from and writes artifacts to objects.
+func NewHook(objects *service.ObjectService, transformers []Transformer, config Config, logger zerolog.Logger) *Hook {
+	if config.MaxSourceSize <= 0 {
+		config.MaxSourceSize = DefaultMaxSourceSize
+	}
+
+	var contentTypes map[string]bool
+	if len(config.ContentTypes) > 0 {
+		contentTypes = make(map[string]bool, len(config.ContentTypes))
+		for _, ct := range config.ContentTypes {
+			contentTypes[baseContentType(ct)] = true
+		}
+	}
+
+	return &Hook{
+		objects:       objects,
+		transformers:  transformers,
+		contentTypes:  contentTypes,
+		maxSourceSize: config.MaxSourceSize,
+		logger:        logger.With().Str("component", "transform").Logger(),
+	}
+}
+
+// HandleEvent transforms the object of an ObjectCreated event with every
+// transformer that accepts its content type.
+func (h *Hook) HandleEvent(ctx context.Context, event events.Event) error {
+	if !event.Type.IsCreated() || IsDerivedKey(event.Key) || event.Size > h.maxSourceSize {
+		return nil
+	}
+
+	contentType := baseContentType(event.ContentType)
+	if h.contentTypes != nil && !h.contentTypes[contentType] {
+		return nil
+	}
+
+	var errs []error
+	for _, t := range h.transformers {
+		if !t.Accepts(contentType) {
+			continue
+		}
+		if err := h.apply(ctx, t, event, contentType); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", t.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// apply runs one transformer on the event's object version and stores the artifact.
+func (h *Hook) apply(ctx context.Context, t Transformer, event events.Event, contentType string) error {
+	src, err := h.objects.GetObject(ctx, service.GetObjectInput{
+		BucketName: event.Bucket,
+		Key:        event.Key,
+		VersionID:  event.VersionID,
+	})
+	if err != nil {
+		// SSE-C content cannot be read without the customer's key
+		if errors.Is(err, service.ErrSSECustomerKeyRequired) {
+			return nil
+		}
+		return fmt.Errorf("failed to read source: %w", err)
+	}
+	defer src.Body.Close()
+
+	artifact, err := t.Transform(ctx, src.Body, contentType)
+	if err != nil {
+		return err
+	}
+
+	derivedKey := DerivedKey(t.Name(), event.Key)
+	if _, err := h.objects.PutObject(ctx, service.PutObjectInput{
+		BucketName:  event.Bucket,
+		Key:         derivedKey,
+		Body:        bytes.NewReader(artifact.Data),
+		Size:        int64(len(artifact.Data)),
+		ContentType: artifact.ContentType,
+		Metadata:    map[string]string{"source-key": event.Key, "source-etag": event.ETag},
+	}); err != nil {
+		return fmt.Errorf("failed to store derived object: %w", err)
+	}
+
+	h.logger.Debug().
+		Str("transformer", t.Name()).
+		Str("bucket", event.Bucket).
+		Str("key", event.Key).
+		Str("derived_key", derivedKey).
+		Msg("derived object stored")
+
+	return nil
+}
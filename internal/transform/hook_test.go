@@ -0,0 +1,127 @@
+package transform
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prn-tf/alexander-storage/internal/domain"
+	"github.com/prn-tf/alexander-storage/internal/events"
+	"github.com/prn-tf/alexander-storage/internal/lock"
+	"github.com/prn-tf/alexander-storage/internal/repository/sqlite"
+	"github.com/prn-tf/alexander-storage/internal/service"
+	"github.com/prn-tf/alexander-storage/internal/storage/filesystem"
+)
+
+// upperTransformer upper-cases text/plain objects.
+type upperTransformer struct{}
+
+func (upperTransformer) Name() string { return "upper" }
+
+func (upperTransformer) Accepts(contentType string) bool { return contentType == "text/plain" }
+
+func (upperTransformer) Transform(_ context.Context, src io.Reader, _ string) (*Artifact, error) {
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return nil, err
+	}
+	return &Artifact{Data: bytes.ToUpper(data), ContentType: "text/plain"}, nil
+}
+
+func newTestObjectService(t *testing.T) (*service.ObjectService, int64) {
+	t.Helper()
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	db, err := sqlite.NewDB(ctx, sqlite.DefaultConfig(filepath.Join(dir, "alexander.db")), zerolog.Nop())
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(ctx))
+	t.Cleanup(func() { db.Close() })
+
+	store, err := filesystem.NewStorage(filesystem.Config{
+		DataDir: filepath.Join(dir, "data"),
+		TempDir: filepath.Join(dir, "tmp"),
+	}, zerolog.Nop())
+	require.NoError(t, err)
+
+	user := domain.NewUser("tester", "tester@example.com", "hash")
+	require.NoError(t, sqlite.NewUserRepository(db).Create(ctx, user))
+	bucketRepo := sqlite.NewBucketRepository(db)
+	require.NoError(t, bucketRepo.Create(ctx, domain.NewBucket(user.ID, "media")))
+
+	svc := service.NewObjectService(sqlite.NewObjectRepository(db), sqlite.NewBlobRepository(db), bucketRepo, store, lock.NewNoOpLocker(), zerolog.Nop())
+	return svc, user.ID
+}
+
+func TestHook_StoresDerivedObject(t *testing.T) {
+	ctx := context.Background()
+	svc, ownerID := newTestObjectService(t)
+
+	bus := events.NewBus(0, zerolog.Nop())
+	bus.Subscribe("transform", NewHook(svc, []Transformer{NoOp{}, upperTransformer{}}, Config{}, zerolog.Nop()))
+	svc.SetEventBus(bus)
+
+	put := func(key, contentType, body string) {
+		_, err := svc.PutObject(ctx, service.PutObjectInput{
+			BucketName:  "media",
+			Key:         key,
+			Body:        strings.NewReader(body),
+			Size:        int64(len(body)),
+			ContentType: contentType,
+			OwnerID:     ownerID,
+		})
+		require.NoError(t, err)
+	}
+	put("notes/readme.txt", "text/plain; charset=utf-8", "hello world")
+	put("photos/cat.png", "image/png", "not really a png")
+
+	require.NoError(t, bus.Close(ctx))
+
+	derived, err := svc.GetObject(ctx, service.GetObjectInput{BucketName: "media", Key: "_derived/upper/notes/readme.txt"})
+	require.NoError(t, err)
+	defer derived.Body.Close()
+	data, err := io.ReadAll(derived.Body)
+	require.NoError(t, err)
+	require.Equal(t, "HELLO WORLD", string(data))
+	require.Equal(t, "text/plain", derived.ContentType)
+	require.Equal(t, "notes/readme.txt", derived.Metadata["source-key"])
+
+	// No transformer accepts image/png, and derived objects are not transformed again
+	_, err = svc.GetObject(ctx, service.GetObjectInput{BucketName: "media", Key: "_derived/upper/photos/cat.png"})
+	require.ErrorIs(t, err, domain.ErrObjectNotFound)
+	_, err = svc.GetObject(ctx, service.GetObjectInput{BucketName: "media", Key: "_derived/upper/_derived/upper/notes/readme.txt"})
+	require.ErrorIs(t, err, domain.ErrObjectNotFound)
+}
+
+func TestHook_ContentTypeFilter(t *testing.T) {
+	ctx := context.Background()
+	svc, ownerID := newTestObjectService(t)
+
+	hook := NewHook(svc, []Transformer{upperTransformer{}}, Config{ContentTypes: []string{"image/png"}}, zerolog.Nop())
+	_, err := svc.PutObject(ctx, service.PutObjectInput{
+		BucketName:  "media",
+		Key:         "readme.txt",
+		Body:        strings.NewReader("hi"),
+		Size:        2,
+		ContentType: "text/plain",
+		OwnerID:     ownerID,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, hook.HandleEvent(ctx, events.Event{
+		Type:        events.ObjectCreatedPut,
+		Bucket:      "media",
+		Key:         "readme.txt",
+		Size:        2,
+		ContentType: "text/plain",
+	}))
+
+	_, err = svc.GetObject(ctx, service.GetObjectInput{BucketName: "media", Key: DerivedKey("upper", "readme.txt")})
+	require.ErrorIs(t, err, domain.ErrObjectNotFound)
+}
@@ -0,0 +1,112 @@
+//go:build imagetransform
+
+package transform
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	// Register the GIF decoder; GIF thumbnails are encoded as PNG
+	_ "image/gif"
+)
+
+// ImageResizer is the reference thumbnail transformer. It scales JPEG, PNG
+// and GIF images down to fit a square of MaxDimension pixels, keeping the
+// aspect ratio, using only the standard library. It is compiled in with the
+// imagetransform build tag.
+type ImageResizer struct {
+	maxDimension int
+}
+
+// NewImageResizer creates an ImageResizer producing thumbnails no larger
+// than maxDimension pixels on either side.
+func NewImageResizer(maxDimension int) (Transformer, error) {
+	if maxDimension <= 0 {
+		return nil, fmt.Errorf("image resize: max dimension must be positive, got %d", maxDimension)
+	}
+	return &ImageResizer{maxDimension: maxDimension}, nil
+}
+
+// Name returns "thumbnail".
+func (r *ImageResizer) Name() string { return "thumbnail" }
+
+// Accepts reports whether the content type is a decodable image.
+func (r *ImageResizer) Accepts(contentType string) bool {
+	switch contentType {
+	case "image/jpeg", "image/png", "image/gif":
+		return true
+	}
+	return false
+}
+
+// Transform decodes the image, scales it and encodes the thumbnail as JPEG
+// for JPEG sources and PNG otherwise.
+func (r *ImageResizer) Transform(ctx context.Context, src io.Reader, contentType string) (*Artifact, error) {
+	img, _, err := image.Decode(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	thumb := r.scale(img)
+
+	var buf bytes.Buffer
+	if contentType == "image/jpeg" {
+		if err := jpeg.Encode(&buf, thumb, &jpeg.Options{Quality: 85}); err != nil {
+			return nil, fmt.Errorf("failed to encode thumbnail: %w", err)
+		}
+		return &Artifact{Data: buf.Bytes(), ContentType: "image/jpeg"}, nil
+	}
+	if err := png.Encode(&buf, thumb); err != nil {
+		return nil, fmt.Errorf("failed to encode thumbnail: %w", err)
+	}
+	return &Artifact{Data: buf.Bytes(), ContentType: "image/png"}, nil
+}
+
+// scale box-filters img down to fit maxDimension. Images that already fit
+// are returned unchanged.
+func (r *ImageResizer) scale(img image.Image) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w <= r.maxDimension && h <= r.maxDimension {
+		return img
+	}
+
+	tw, th := r.maxDimension, h*r.maxDimension/w
+	if h > w {
+		tw, th = w*r.maxDimension/h, r.maxDimension
+	}
+	tw, th = max(tw, 1), max(th, 1)
+
+	dst := image.NewRGBA(image.Rect(0, 0, tw, th))
+	for y := 0; y < th; y++ {
+		y0 := bounds.Min.Y + y*h/th
+		y1 := max(bounds.Min.Y+(y+1)*h/th, y0+1)
+		for x := 0; x < tw; x++ {
+			x0 := bounds.Min.X + x*w/tw
+			x1 := max(bounds.Min.X+(x+1)*w/tw, x0+1)
+
+			// Average the source pixels covered by the destination pixel
+			var sr, sg, sb, sa, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := img.At(sx, sy).RGBA()
+					sr, sg, sb, sa = sr+uint64(cr), sg+uint64(cg), sb+uint64(cb), sa+uint64(ca)
+					n++
+				}
+			}
+			dst.Set(x, y, color.RGBA64{
+				R: uint16(sr / n),
+				G: uint16(sg / n),
+				B: uint16(sb / n),
+				A: uint16(sa / n),
+			})
+		}
+	}
+	return dst
+}
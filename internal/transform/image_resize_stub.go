@@ -0,0 +1,9 @@
+//go:build !imagetransform
+
+package transform
+
+// NewImageResizer reports ErrNotAvailable; build with the imagetransform
+// tag to include the reference thumbnail transformer.
+func NewImageResizer(maxDimension int) (Transformer, error) {
+	return nil, ErrNotAvailable
+}
@@ -0,0 +1,40 @@
+//go:build imagetransform
+
+package transform
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestImageResizer_FitsMaxDimension(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 400, 100))
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 400; x++ {
+			src.Set(x, y, color.RGBA{R: 200, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, src))
+
+	resizer, err := NewImageResizer(64)
+	require.NoError(t, err)
+	require.True(t, resizer.Accepts("image/png"))
+	require.False(t, resizer.Accepts("text/plain"))
+
+	artifact, err := resizer.Transform(context.Background(), &buf, "image/png")
+	require.NoError(t, err)
+	require.Equal(t, "image/png", artifact.ContentType)
+
+	thumb, err := png.Decode(bytes.NewReader(artifact.Data))
+	require.NoError(t, err)
+	require.Equal(t, image.Rect(0, 0, 64, 16), thumb.Bounds())
+	r, _, _, _ := thumb.At(10, 5).RGBA()
+	require.Equal(t, uint32(200), r>>8)
+}
@@ -0,0 +1,70 @@
+// Package transform derives artifacts, such as image thumbnails, from newly
+// created objects. Transformers are pluggable; the Hook runs them from the
+// event bus after the upload has committed, so they never slow down or fail
+// the write path.
+package transform
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+)
+
+// DerivedPrefix is the key prefix of derived objects. An artifact of the
+// transformer "thumbnail" for photos/cat.jpg is stored in the same bucket as
+// _derived/thumbnail/photos/cat.jpg.
+const DerivedPrefix = "_derived/"
+
+// ErrNotAvailable indicates a transformer was not compiled into this binary.
+var ErrNotAvailable = errors.New("transformer not available in this build")
+
+// Artifact is the output of a transformer.
+type Artifact struct {
+	Data        []byte
+	ContentType string
+}
+
+// Transformer derives an artifact from an object's content.
+type Transformer interface {
+	// Name identifies the transformer in derived keys and logs.
+	Name() string
+
+	// Accepts reports whether the transformer handles the content type.
+	Accepts(contentType string) bool
+
+	// Transform reads the source content and returns the derived artifact.
+	Transform(ctx context.Context, src io.Reader, contentType string) (*Artifact, error)
+}
+
+// DerivedKey returns the key the named transformer's artifact of key is stored under.
+func DerivedKey(name, key string) string {
+	return DerivedPrefix + name + "/" + key
+}
+
+// IsDerivedKey reports whether key belongs to a derived object.
+func IsDerivedKey(key string) bool {
+	return strings.HasPrefix(key, DerivedPrefix)
+}
+
+// NoOp is the default transformer. It accepts no content type.
+type NoOp struct{}
+
+// Name returns "noop".
+func (NoOp) Name() string { return "noop" }
+
+// Accepts always returns false.
+func (NoOp) Accepts(string) bool { return false }
+
+// Transform is never called because NoOp accepts nothing.
+func (NoOp) Transform(context.Context, io.Reader, string) (*Artifact, error) {
+	return nil, errors.New("noop transformer does not transform")
+}
+
+// baseContentType strips parameters such as "; charset=utf-8" and lowercases.
+func baseContentType(contentType string) string {
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = contentType[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
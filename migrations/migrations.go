@@ -0,0 +1,9 @@
+// Package migrations embeds the SQL schema migrations shipped with the server.
+package migrations
+
+import "embed"
+
+// Postgres holds the PostgreSQL migrations under postgres/.
+//
+//go:embed postgres/*.sql
+var Postgres embed.FS
@@ -0,0 +1,4 @@
+-- Rollback: 000004_access_key_namespace
+
+ALTER TABLE access_keys DROP COLUMN IF EXISTS namespace_prefix;
+ALTER TABLE access_keys DROP COLUMN IF EXISTS namespace_bucket;
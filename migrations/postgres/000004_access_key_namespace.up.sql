@@ -0,0 +1,9 @@
+-- Alexander Storage Database Schema
+-- Migration: 000004_access_key_namespace
+-- Description: Confine an access key to a key prefix inside a shared bucket
+
+ALTER TABLE access_keys ADD COLUMN IF NOT EXISTS namespace_bucket VARCHAR(63) NOT NULL DEFAULT '';
+ALTER TABLE access_keys ADD COLUMN IF NOT EXISTS namespace_prefix VARCHAR(1024) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN access_keys.namespace_bucket IS 'Shared bucket in which this key is confined to namespace_prefix (empty = unrestricted)';
+COMMENT ON COLUMN access_keys.namespace_prefix IS 'Object key prefix this key may access within namespace_bucket';
@@ -0,0 +1,4 @@
+-- Rollback: 000005_bucket_state
+
+ALTER TABLE buckets DROP CONSTRAINT IF EXISTS buckets_state_valid;
+ALTER TABLE buckets DROP COLUMN IF EXISTS state;
@@ -0,0 +1,9 @@
+-- Alexander Storage Database Schema
+-- Migration: 000005_bucket_state
+-- Description: Track bucket lifecycle state for two-phase bucket deletion
+
+ALTER TABLE buckets ADD COLUMN IF NOT EXISTS state VARCHAR(16) NOT NULL DEFAULT 'active';
+
+ALTER TABLE buckets ADD CONSTRAINT buckets_state_valid CHECK (state IN ('active', 'deleting'));
+
+COMMENT ON COLUMN buckets.state IS 'Lifecycle state: active, or deleting while in-flight writes drain';
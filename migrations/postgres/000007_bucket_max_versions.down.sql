@@ -0,0 +1,4 @@
+-- Rollback: 000007_bucket_max_versions
+
+ALTER TABLE buckets DROP CONSTRAINT IF EXISTS buckets_max_versions_per_key_valid;
+ALTER TABLE buckets DROP COLUMN IF EXISTS max_versions_per_key;
@@ -0,0 +1,9 @@
+-- Alexander Storage Database Schema
+-- Migration: 000007_bucket_max_versions
+-- Description: Cap the number of versions kept per object key
+
+ALTER TABLE buckets ADD COLUMN IF NOT EXISTS max_versions_per_key INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE buckets ADD CONSTRAINT buckets_max_versions_per_key_valid CHECK (max_versions_per_key >= 0);
+
+COMMENT ON COLUMN buckets.max_versions_per_key IS 'Maximum versions kept per key; older versions are removed on write. 0 = unlimited';
@@ -0,0 +1,4 @@
+-- Rollback: 000008_bucket_object_ownership
+
+ALTER TABLE buckets DROP CONSTRAINT IF EXISTS buckets_object_ownership_valid;
+ALTER TABLE buckets DROP COLUMN IF EXISTS object_ownership;
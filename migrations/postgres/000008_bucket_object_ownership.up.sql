@@ -0,0 +1,11 @@
+-- Alexander Storage Database Schema
+-- Migration: 000008_bucket_object_ownership
+-- Description: Object ownership controls; BucketOwnerEnforced disables ACLs
+
+-- Existing buckets keep honoring their canned ACLs
+ALTER TABLE buckets ADD COLUMN IF NOT EXISTS object_ownership VARCHAR(32) NOT NULL DEFAULT 'ObjectWriter';
+
+ALTER TABLE buckets ADD CONSTRAINT buckets_object_ownership_valid
+    CHECK (object_ownership IN ('BucketOwnerEnforced', 'BucketOwnerPreferred', 'ObjectWriter'));
+
+COMMENT ON COLUMN buckets.object_ownership IS 'Object ownership: BucketOwnerEnforced (ACLs disabled), BucketOwnerPreferred or ObjectWriter';
@@ -0,0 +1,3 @@
+-- Rollback: 000009_blob_encryption_iv
+
+ALTER TABLE blobs DROP COLUMN IF EXISTS encryption_iv;
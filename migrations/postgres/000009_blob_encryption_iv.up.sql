@@ -0,0 +1,7 @@
+-- Alexander Storage Database Schema
+-- Migration: 000009_blob_encryption_iv
+-- Description: Add the blobs.encryption_iv column the blob repository already writes
+
+ALTER TABLE blobs ADD COLUMN IF NOT EXISTS encryption_iv TEXT;
+
+COMMENT ON COLUMN blobs.encryption_iv IS 'Base64 IV or base nonce used to encrypt the blob';
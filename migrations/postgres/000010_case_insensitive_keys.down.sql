@@ -0,0 +1,12 @@
+-- Rollback: 000010_case_insensitive_keys
+
+DROP INDEX IF EXISTS idx_objects_normalized_prefix;
+DROP INDEX IF EXISTS idx_objects_normalized_versions;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_objects_latest
+    ON objects (bucket_id, key)
+    WHERE is_latest = TRUE;
+DROP INDEX IF EXISTS idx_objects_latest_normalized;
+
+ALTER TABLE objects DROP COLUMN IF EXISTS normalized_key;
+ALTER TABLE buckets DROP COLUMN IF EXISTS case_insensitive_keys;
@@ -0,0 +1,26 @@
+-- Alexander Storage Database Schema
+-- Migration: 000010_case_insensitive_keys
+-- Description: Optional per-bucket case-insensitive keys via a normalized key column
+
+ALTER TABLE buckets ADD COLUMN IF NOT EXISTS case_insensitive_keys BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN buckets.case_insensitive_keys IS 'Match object keys case-insensitively; the original key is kept for display';
+
+-- normalized_key equals key unless the bucket is case-insensitive
+ALTER TABLE objects ADD COLUMN IF NOT EXISTS normalized_key VARCHAR(1024);
+UPDATE objects SET normalized_key = key WHERE normalized_key IS NULL;
+ALTER TABLE objects ALTER COLUMN normalized_key SET NOT NULL;
+
+COMMENT ON COLUMN objects.normalized_key IS 'Key used for lookups, uniqueness and list ordering';
+
+-- Uniqueness and lookups move from key to normalized_key
+CREATE UNIQUE INDEX idx_objects_latest_normalized
+    ON objects (bucket_id, normalized_key)
+    WHERE is_latest = TRUE;
+DROP INDEX IF EXISTS idx_objects_latest;
+
+CREATE INDEX idx_objects_normalized_versions ON objects (bucket_id, normalized_key, created_at DESC);
+
+CREATE INDEX idx_objects_normalized_prefix
+    ON objects (bucket_id, normalized_key text_pattern_ops)
+    WHERE is_latest = TRUE AND is_delete_marker = FALSE;
@@ -0,0 +1,3 @@
+-- Rollback: 000011_bucket_content_type_policy
+
+ALTER TABLE buckets DROP COLUMN IF EXISTS content_type_policy;
@@ -0,0 +1,7 @@
+-- Alexander Storage Database Schema
+-- Migration: 000011_bucket_content_type_policy
+-- Description: Optional per-bucket content-type allow/deny policy
+
+ALTER TABLE buckets ADD COLUMN IF NOT EXISTS content_type_policy JSONB;
+
+COMMENT ON COLUMN buckets.content_type_policy IS 'JSON-encoded content-type allow/deny lists and serve mode; NULL means unrestricted';
@@ -0,0 +1,11 @@
+-- Alexander Storage Database Schema
+-- Migration: 000012_object_expires_at
+-- Description: Optional per-object expiration time enforced by the lifecycle worker
+
+ALTER TABLE objects ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_objects_expires_at
+    ON objects (expires_at)
+    WHERE expires_at IS NOT NULL AND is_latest = TRUE AND deleted_at IS NULL;
+
+COMMENT ON COLUMN objects.expires_at IS 'Time after which the lifecycle worker deletes the object; NULL means never';
@@ -0,0 +1,12 @@
+-- Alexander Storage Database Schema
+-- Migration: 000013_object_tags
+-- Description: Object tags (x-amz-tagging), stored per object version
+
+CREATE TABLE IF NOT EXISTS object_tags (
+    object_id   BIGINT NOT NULL REFERENCES objects(id) ON DELETE CASCADE,
+    tag_key     VARCHAR(128) NOT NULL,
+    tag_value   VARCHAR(256) NOT NULL DEFAULT '',
+    PRIMARY KEY (object_id, tag_key)
+);
+
+COMMENT ON TABLE object_tags IS 'Tag set of each object version, at most 10 tags per version';
@@ -0,0 +1,4 @@
+-- Rollback: 000015_object_sse_customer
+
+ALTER TABLE objects DROP COLUMN IF EXISTS sse_customer_key_md5;
+ALTER TABLE objects DROP COLUMN IF EXISTS sse_customer_algorithm;
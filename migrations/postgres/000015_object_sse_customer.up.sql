@@ -0,0 +1,8 @@
+-- Alexander Storage Database Schema
+-- Migration: 000015_object_sse_customer
+-- Description: SSE-C algorithm and key MD5 of objects encrypted with customer-provided keys
+
+ALTER TABLE objects ADD COLUMN IF NOT EXISTS sse_customer_algorithm VARCHAR(16) NOT NULL DEFAULT '';
+ALTER TABLE objects ADD COLUMN IF NOT EXISTS sse_customer_key_md5 VARCHAR(24) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN objects.sse_customer_key_md5 IS 'Base64 MD5 of the customer key; the key itself is never stored';
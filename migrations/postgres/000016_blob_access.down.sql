@@ -0,0 +1,3 @@
+-- Rollback: 000016_blob_access
+
+DROP TABLE IF EXISTS blob_access;
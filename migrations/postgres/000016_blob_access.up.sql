@@ -0,0 +1,21 @@
+-- Alexander Storage Database Schema
+-- Migration: 000016_blob_access
+-- Description: Persistent blob access tracking for automatic tiering
+
+CREATE TABLE IF NOT EXISTS blob_access (
+    content_hash CHAR(64) PRIMARY KEY,
+    current_tier VARCHAR(16) NOT NULL DEFAULT 'hot',
+    size BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_accessed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    access_count BIGINT NOT NULL DEFAULT 0,
+    bucket_name VARCHAR(63) NOT NULL DEFAULT '',
+
+    CONSTRAINT blob_access_tier_check CHECK (current_tier IN ('hot', 'warm', 'cold'))
+);
+
+-- Tiering scans select idle blobs per tier
+CREATE INDEX IF NOT EXISTS idx_blob_access_tier_last_accessed ON blob_access(current_tier, last_accessed_at);
+
+COMMENT ON TABLE blob_access IS 'Access history used by the tiering controller; survives restarts';
+COMMENT ON COLUMN blob_access.access_count IS 'Total recorded reads; increments are batched by the server';
@@ -0,0 +1,3 @@
+-- Rollback: 000017_lifecycle_expiration_date
+
+ALTER TABLE lifecycle_rules DROP COLUMN IF EXISTS expiration_date;
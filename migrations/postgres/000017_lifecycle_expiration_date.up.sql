@@ -0,0 +1,7 @@
+-- Alexander Storage Database Schema
+-- Migration: 000017_lifecycle_expiration_date
+-- Description: Lifecycle rules expiring objects on a fixed date
+
+ALTER TABLE lifecycle_rules ADD COLUMN IF NOT EXISTS expiration_date TIMESTAMPTZ;
+
+COMMENT ON COLUMN lifecycle_rules.expiration_date IS 'Date from which matching objects expire; exclusive with expiration_days';
@@ -0,0 +1,7 @@
+-- Rollback: 000018_lifecycle_transitions
+
+DROP TABLE IF EXISTS object_transitions;
+
+ALTER TABLE lifecycle_rules DROP COLUMN IF EXISTS transition_storage_class;
+ALTER TABLE lifecycle_rules DROP COLUMN IF EXISTS transition_date;
+ALTER TABLE lifecycle_rules DROP COLUMN IF EXISTS transition_days;
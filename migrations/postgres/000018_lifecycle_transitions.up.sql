@@ -0,0 +1,19 @@
+-- Alexander Storage Database Schema
+-- Migration: 000018_lifecycle_transitions
+-- Description: Lifecycle rules moving objects to the warm or cold tier
+
+ALTER TABLE lifecycle_rules ADD COLUMN IF NOT EXISTS transition_days INTEGER;
+ALTER TABLE lifecycle_rules ADD COLUMN IF NOT EXISTS transition_date TIMESTAMPTZ;
+ALTER TABLE lifecycle_rules ADD COLUMN IF NOT EXISTS transition_storage_class VARCHAR(16) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN lifecycle_rules.transition_days IS 'Days after creation when matching objects are transitioned';
+COMMENT ON COLUMN lifecycle_rules.transition_date IS 'Date from which matching objects are transitioned; exclusive with transition_days';
+COMMENT ON COLUMN lifecycle_rules.transition_storage_class IS 'Tier objects are transitioned to (WARM or COLD), empty without a transition';
+
+CREATE TABLE IF NOT EXISTS object_transitions (
+    object_id       BIGINT PRIMARY KEY REFERENCES objects(id) ON DELETE CASCADE,
+    storage_class   VARCHAR(16) NOT NULL,
+    transitioned_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE object_transitions IS 'Tier each object version was moved to by a lifecycle transition';
@@ -0,0 +1,9 @@
+-- Rollback: 000019_object_checksums
+
+ALTER TABLE upload_parts DROP COLUMN IF EXISTS checksum;
+ALTER TABLE upload_parts DROP COLUMN IF EXISTS checksum_algorithm;
+
+ALTER TABLE multipart_uploads DROP COLUMN IF EXISTS checksum_algorithm;
+
+ALTER TABLE objects DROP COLUMN IF EXISTS checksum;
+ALTER TABLE objects DROP COLUMN IF EXISTS checksum_algorithm;
@@ -0,0 +1,13 @@
+-- Alexander Storage Database Schema
+-- Migration: 000019_object_checksums
+-- Description: Additional checksums (x-amz-checksum-*) of objects and upload parts
+
+ALTER TABLE objects ADD COLUMN IF NOT EXISTS checksum_algorithm VARCHAR(16) NOT NULL DEFAULT '';
+ALTER TABLE objects ADD COLUMN IF NOT EXISTS checksum VARCHAR(64) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN objects.checksum IS 'Base64 checksum of the content; composite (suffixed -N) for multipart objects';
+
+ALTER TABLE multipart_uploads ADD COLUMN IF NOT EXISTS checksum_algorithm VARCHAR(16) NOT NULL DEFAULT '';
+
+ALTER TABLE upload_parts ADD COLUMN IF NOT EXISTS checksum_algorithm VARCHAR(16) NOT NULL DEFAULT '';
+ALTER TABLE upload_parts ADD COLUMN IF NOT EXISTS checksum VARCHAR(64) NOT NULL DEFAULT '';
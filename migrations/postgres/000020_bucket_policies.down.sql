@@ -0,0 +1,3 @@
+-- Rollback: 000020_bucket_policies
+
+DROP TABLE IF EXISTS bucket_policies;
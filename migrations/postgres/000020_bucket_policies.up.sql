@@ -0,0 +1,12 @@
+-- Alexander Storage Database Schema
+-- Migration: 000020_bucket_policies
+-- Description: JSON access policies attached to buckets
+
+CREATE TABLE IF NOT EXISTS bucket_policies (
+    bucket_id   BIGINT PRIMARY KEY REFERENCES buckets(id) ON DELETE CASCADE,
+    policy      TEXT NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE bucket_policies IS 'Bucket policy document of each bucket that has one';
@@ -0,0 +1,3 @@
+-- Rollback: 000021_bucket_cors
+
+DROP TABLE IF EXISTS bucket_cors;
@@ -0,0 +1,12 @@
+-- Alexander Storage Database Schema
+-- Migration: 000021_bucket_cors
+-- Description: CORS configurations attached to buckets
+
+CREATE TABLE IF NOT EXISTS bucket_cors (
+    bucket_id   BIGINT PRIMARY KEY REFERENCES buckets(id) ON DELETE CASCADE,
+    rules       JSONB NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE bucket_cors IS 'CORS rules of each bucket that has a CORS configuration';
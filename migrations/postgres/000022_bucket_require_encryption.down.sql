@@ -0,0 +1,3 @@
+-- Rollback: 000022_bucket_require_encryption
+
+ALTER TABLE buckets DROP COLUMN IF EXISTS require_encryption;
@@ -0,0 +1,7 @@
+-- Alexander Storage Database Schema
+-- Migration: 000022_bucket_require_encryption
+-- Description: Per-bucket requirement that uploads request server-side encryption
+
+ALTER TABLE buckets ADD COLUMN IF NOT EXISTS require_encryption BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN buckets.require_encryption IS 'Reject uploads without x-amz-server-side-encryption or SSE-C headers';
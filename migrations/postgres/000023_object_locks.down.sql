@@ -0,0 +1,3 @@
+-- Rollback: 000023_object_locks
+
+DROP TABLE IF EXISTS object_locks;
@@ -0,0 +1,12 @@
+-- Alexander Storage Database Schema
+-- Migration: 000023_object_locks
+-- Description: Object lock retention and legal hold, stored per object version
+
+CREATE TABLE IF NOT EXISTS object_locks (
+    object_id    BIGINT PRIMARY KEY REFERENCES objects(id) ON DELETE CASCADE,
+    mode         VARCHAR(16) NOT NULL DEFAULT '',
+    retain_until TIMESTAMPTZ,
+    legal_hold   BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+COMMENT ON TABLE object_locks IS 'Retention (GOVERNANCE or COMPLIANCE until retain_until) and legal hold of each object version';
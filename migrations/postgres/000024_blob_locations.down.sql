@@ -0,0 +1,3 @@
+-- Rollback: 000024_blob_locations
+
+DROP TABLE IF EXISTS blob_locations;
@@ -0,0 +1,15 @@
+-- Alexander Storage Database Schema
+-- Migration: 000024_blob_locations
+-- Description: Cluster nodes holding each blob, shared by coordinators
+
+CREATE TABLE IF NOT EXISTS blob_locations (
+    content_hash VARCHAR(64) NOT NULL,
+    node_id      VARCHAR(255) NOT NULL,
+    is_primary   BOOLEAN NOT NULL DEFAULT FALSE,
+    synced_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (content_hash, node_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_blob_locations_node_id ON blob_locations(node_id);
+
+COMMENT ON TABLE blob_locations IS 'Cluster nodes storing a copy of each blob, so locations survive coordinator restarts';
@@ -0,0 +1,3 @@
+-- Rollback: 000025_bucket_notifications
+
+DROP TABLE IF EXISTS bucket_notifications;
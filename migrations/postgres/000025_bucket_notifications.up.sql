@@ -0,0 +1,12 @@
+-- Alexander Storage Database Schema
+-- Migration: 000025_bucket_notifications
+-- Description: Event notification webhooks attached to buckets
+
+CREATE TABLE IF NOT EXISTS bucket_notifications (
+    bucket_id   BIGINT PRIMARY KEY REFERENCES buckets(id) ON DELETE CASCADE,
+    webhooks    JSONB NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE bucket_notifications IS 'Webhook targets of each bucket that has a notification configuration';
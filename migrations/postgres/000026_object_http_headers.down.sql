@@ -0,0 +1,5 @@
+-- Rollback: 000026_object_http_headers
+
+ALTER TABLE objects DROP COLUMN IF EXISTS content_encoding;
+ALTER TABLE objects DROP COLUMN IF EXISTS content_disposition;
+ALTER TABLE objects DROP COLUMN IF EXISTS cache_control;
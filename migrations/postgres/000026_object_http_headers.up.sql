@@ -0,0 +1,7 @@
+-- Alexander Storage Database Schema
+-- Migration: 000026_object_http_headers
+-- Description: Standard HTTP headers stored with objects
+
+ALTER TABLE objects ADD COLUMN IF NOT EXISTS cache_control TEXT NOT NULL DEFAULT '';
+ALTER TABLE objects ADD COLUMN IF NOT EXISTS content_disposition TEXT NOT NULL DEFAULT '';
+ALTER TABLE objects ADD COLUMN IF NOT EXISTS content_encoding VARCHAR(255) NOT NULL DEFAULT '';
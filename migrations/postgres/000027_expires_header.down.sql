@@ -0,0 +1,8 @@
+-- Rollback: 000027_expires_header
+
+ALTER TABLE multipart_uploads DROP COLUMN IF EXISTS expires;
+ALTER TABLE multipart_uploads DROP COLUMN IF EXISTS content_encoding;
+ALTER TABLE multipart_uploads DROP COLUMN IF EXISTS content_disposition;
+ALTER TABLE multipart_uploads DROP COLUMN IF EXISTS cache_control;
+
+ALTER TABLE objects DROP COLUMN IF EXISTS expires;
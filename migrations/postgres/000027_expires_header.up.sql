@@ -0,0 +1,12 @@
+-- Alexander Storage Database Schema
+-- Migration: 000027_expires_header
+-- Description: Expires header of objects, and standard HTTP headers of multipart uploads
+
+ALTER TABLE objects ADD COLUMN IF NOT EXISTS expires TEXT NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN objects.expires IS 'Expires header as sent on upload; unrelated to expires_at';
+
+ALTER TABLE multipart_uploads ADD COLUMN IF NOT EXISTS cache_control TEXT NOT NULL DEFAULT '';
+ALTER TABLE multipart_uploads ADD COLUMN IF NOT EXISTS content_disposition TEXT NOT NULL DEFAULT '';
+ALTER TABLE multipart_uploads ADD COLUMN IF NOT EXISTS content_encoding VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE multipart_uploads ADD COLUMN IF NOT EXISTS expires TEXT NOT NULL DEFAULT '';
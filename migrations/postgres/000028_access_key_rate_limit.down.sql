@@ -0,0 +1,4 @@
+-- Rollback: 000028_access_key_rate_limit
+
+ALTER TABLE access_keys DROP COLUMN IF EXISTS rate_limit_burst;
+ALTER TABLE access_keys DROP COLUMN IF EXISTS rate_limit_rps;
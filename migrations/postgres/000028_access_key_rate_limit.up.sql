@@ -0,0 +1,9 @@
+-- Alexander Storage Database Schema
+-- Migration: 000028_access_key_rate_limit
+-- Description: Per access key overrides of the request rate limit
+
+ALTER TABLE access_keys ADD COLUMN IF NOT EXISTS rate_limit_rps DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE access_keys ADD COLUMN IF NOT EXISTS rate_limit_burst INTEGER NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN access_keys.rate_limit_rps IS 'Requests per second allowed for this key (0 = server default)';
+COMMENT ON COLUMN access_keys.rate_limit_burst IS 'Request burst allowed for this key (0 = server default)';
@@ -0,0 +1,3 @@
+-- Rollback: 000029_delta_chains
+
+DROP TABLE IF EXISTS delta_chains;
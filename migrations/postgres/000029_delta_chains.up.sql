@@ -0,0 +1,21 @@
+-- Alexander Storage Database Schema
+-- Migration: 000029_delta_chains
+-- Description: Blobs stored as deltas against a base blob
+
+CREATE TABLE IF NOT EXISTS delta_chains (
+    content_hash    CHAR(64) PRIMARY KEY,           -- Blob rebuilt from the delta
+    base_hash       CHAR(64) NOT NULL,              -- Blob the delta copies from
+    data_hash       CHAR(64) NOT NULL,              -- Blob holding the inserted bytes
+    depth           INTEGER NOT NULL,               -- Deltas between this blob and a full blob
+    size            BIGINT NOT NULL,
+    data_size       BIGINT NOT NULL,
+    instructions    JSONB NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT delta_chains_depth_positive CHECK (depth > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_delta_chains_base_hash ON delta_chains (base_hash);
+CREATE INDEX IF NOT EXISTS idx_delta_chains_data_hash ON delta_chains (data_hash);
+
+COMMENT ON TABLE delta_chains IS 'Blobs stored as a delta against a base blob; each row holds a blob reference on its base and data blobs';
@@ -0,0 +1,3 @@
+-- Rollback: 000030_bucket_default_retention
+
+ALTER TABLE buckets DROP COLUMN IF EXISTS default_retention;
@@ -0,0 +1,7 @@
+-- Alexander Storage Database Schema
+-- Migration: 000030_bucket_default_retention
+-- Description: Default object lock retention applied to new object versions
+
+ALTER TABLE buckets ADD COLUMN IF NOT EXISTS default_retention JSONB;
+
+COMMENT ON COLUMN buckets.default_retention IS 'JSON-encoded default retention mode and period in days or years; NULL means none';
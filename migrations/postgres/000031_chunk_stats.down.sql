@@ -0,0 +1,7 @@
+-- Rollback: 000031_chunk_stats
+
+DROP TRIGGER IF EXISTS trg_cdc_chunk_stats_delete ON cdc_chunks;
+DROP TRIGGER IF EXISTS trg_cdc_chunk_stats_update ON cdc_chunks;
+DROP TRIGGER IF EXISTS trg_cdc_chunk_stats_insert ON cdc_chunks;
+DROP FUNCTION IF EXISTS update_cdc_chunk_stats();
+DROP TABLE IF EXISTS cdc_chunk_stats;
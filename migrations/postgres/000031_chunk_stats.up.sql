@@ -0,0 +1,87 @@
+-- Alexander Storage Database Schema
+-- Migration: 000031_chunk_stats
+-- Description: Maintained chunk index statistics, so reading them never scans cdc_chunks
+
+CREATE TABLE IF NOT EXISTS cdc_chunk_stats (
+    id SMALLINT PRIMARY KEY DEFAULT 1 CHECK (id = 1),
+    unique_chunks BIGINT NOT NULL DEFAULT 0,
+    total_references BIGINT NOT NULL DEFAULT 0,
+    stored_bytes BIGINT NOT NULL DEFAULT 0,
+    logical_bytes BIGINT NOT NULL DEFAULT 0
+);
+
+COMMENT ON TABLE cdc_chunk_stats IS 'Single-row aggregate of referenced cdc_chunks, kept current by statement triggers';
+
+-- Statement-level triggers fold every row a statement changed into the
+-- counters with one update, so bulk writes stay cheap. Chunks without
+-- references do not count, matching ListOrphans.
+CREATE OR REPLACE FUNCTION update_cdc_chunk_stats()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        UPDATE cdc_chunk_stats SET
+            unique_chunks = unique_chunks - s.chunks,
+            total_references = total_references - s.refs,
+            stored_bytes = stored_bytes - s.stored,
+            logical_bytes = logical_bytes - s.logical
+        FROM (
+            SELECT COUNT(*) AS chunks,
+                COALESCE(SUM(ref_count), 0) AS refs,
+                COALESCE(SUM(chunk_size::BIGINT), 0) AS stored,
+                COALESCE(SUM(chunk_size::BIGINT * ref_count), 0) AS logical
+            FROM old_rows WHERE ref_count > 0
+        ) s
+        WHERE id = 1 AND s.chunks > 0;
+    END IF;
+
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        UPDATE cdc_chunk_stats SET
+            unique_chunks = unique_chunks + s.chunks,
+            total_references = total_references + s.refs,
+            stored_bytes = stored_bytes + s.stored,
+            logical_bytes = logical_bytes + s.logical
+        FROM (
+            SELECT COUNT(*) AS chunks,
+                COALESCE(SUM(ref_count), 0) AS refs,
+                COALESCE(SUM(chunk_size::BIGINT), 0) AS stored,
+                COALESCE(SUM(chunk_size::BIGINT * ref_count), 0) AS logical
+            FROM new_rows WHERE ref_count > 0
+        ) s
+        WHERE id = 1 AND s.chunks > 0;
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_cdc_chunk_stats_insert ON cdc_chunks;
+CREATE TRIGGER trg_cdc_chunk_stats_insert
+    AFTER INSERT ON cdc_chunks
+    REFERENCING NEW TABLE AS new_rows
+    FOR EACH STATEMENT
+    EXECUTE FUNCTION update_cdc_chunk_stats();
+
+DROP TRIGGER IF EXISTS trg_cdc_chunk_stats_update ON cdc_chunks;
+CREATE TRIGGER trg_cdc_chunk_stats_update
+    AFTER UPDATE ON cdc_chunks
+    REFERENCING OLD TABLE AS old_rows NEW TABLE AS new_rows
+    FOR EACH STATEMENT
+    EXECUTE FUNCTION update_cdc_chunk_stats();
+
+DROP TRIGGER IF EXISTS trg_cdc_chunk_stats_delete ON cdc_chunks;
+CREATE TRIGGER trg_cdc_chunk_stats_delete
+    AFTER DELETE ON cdc_chunks
+    REFERENCING OLD TABLE AS old_rows
+    FOR EACH STATEMENT
+    EXECUTE FUNCTION update_cdc_chunk_stats();
+
+-- Seed the counters from the existing index; this is the last full scan
+INSERT INTO cdc_chunk_stats (id, unique_chunks, total_references, stored_bytes, logical_bytes)
+SELECT 1,
+    COUNT(*),
+    COALESCE(SUM(ref_count), 0),
+    COALESCE(SUM(chunk_size::BIGINT), 0),
+    COALESCE(SUM(chunk_size::BIGINT * ref_count), 0)
+FROM cdc_chunks
+WHERE ref_count > 0
+ON CONFLICT (id) DO NOTHING;
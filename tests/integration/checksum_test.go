@@ -0,0 +1,160 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/require"
+)
+
+// TestChecksumCRC32C tests additional checksums computed by the SDK.
+func TestChecksumCRC32C(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	cfg := getTestConfig()
+	client := newS3Client(t, cfg)
+	ctx := context.Background()
+
+	bucketName := "test-checksum-" + time.Now().Format("20060102150405")
+
+	_, err := client.CreateBucket(ctx, &s3.CreateBucketInput{
+		Bucket: aws.String(bucketName),
+	})
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		listResult, _ := client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+			Bucket: aws.String(bucketName),
+		})
+		if listResult != nil {
+			for _, obj := range listResult.Contents {
+				_, _ = client.DeleteObject(ctx, &s3.DeleteObjectInput{
+					Bucket: aws.String(bucketName),
+					Key:    obj.Key,
+				})
+			}
+		}
+		_, _ = client.DeleteBucket(ctx, &s3.DeleteBucketInput{
+			Bucket: aws.String(bucketName),
+		})
+	})
+
+	objectContent := make([]byte, 64*1024)
+	_, err = rand.Read(objectContent)
+	require.NoError(t, err)
+
+	var putChecksum string
+
+	t.Run("PutObject", func(t *testing.T) {
+		result, err := client.PutObject(ctx, &s3.PutObjectInput{
+			Bucket:            aws.String(bucketName),
+			Key:               aws.String("single.bin"),
+			Body:              bytes.NewReader(objectContent),
+			ChecksumAlgorithm: types.ChecksumAlgorithmCrc32c,
+		})
+		require.NoError(t, err)
+		require.NotNil(t, result.ChecksumCRC32C)
+		putChecksum = *result.ChecksumCRC32C
+	})
+
+	t.Run("GetObject", func(t *testing.T) {
+		result, err := client.GetObject(ctx, &s3.GetObjectInput{
+			Bucket:       aws.String(bucketName),
+			Key:          aws.String("single.bin"),
+			ChecksumMode: types.ChecksumModeEnabled,
+		})
+		require.NoError(t, err)
+		defer result.Body.Close()
+
+		// The SDK validates the body against the returned checksum
+		data, err := io.ReadAll(result.Body)
+		require.NoError(t, err)
+		require.Equal(t, objectContent, data)
+		require.Equal(t, putChecksum, aws.ToString(result.ChecksumCRC32C))
+	})
+
+	t.Run("HeadObject", func(t *testing.T) {
+		result, err := client.HeadObject(ctx, &s3.HeadObjectInput{
+			Bucket:       aws.String(bucketName),
+			Key:          aws.String("single.bin"),
+			ChecksumMode: types.ChecksumModeEnabled,
+		})
+		require.NoError(t, err)
+		require.Equal(t, putChecksum, aws.ToString(result.ChecksumCRC32C))
+	})
+
+	t.Run("GetObjectAttributes", func(t *testing.T) {
+		result, err := client.GetObjectAttributes(ctx, &s3.GetObjectAttributesInput{
+			Bucket: aws.String(bucketName),
+			Key:    aws.String("single.bin"),
+			ObjectAttributes: []types.ObjectAttributes{
+				types.ObjectAttributesChecksum,
+				types.ObjectAttributesObjectSize,
+			},
+		})
+		require.NoError(t, err)
+		require.NotNil(t, result.Checksum)
+		require.Equal(t, putChecksum, aws.ToString(result.Checksum.ChecksumCRC32C))
+		require.Equal(t, int64(len(objectContent)), aws.ToInt64(result.ObjectSize))
+	})
+
+	t.Run("MultipartUpload", func(t *testing.T) {
+		createResult, err := client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+			Bucket:            aws.String(bucketName),
+			Key:               aws.String("multipart.bin"),
+			ChecksumAlgorithm: types.ChecksumAlgorithmCrc32c,
+		})
+		require.NoError(t, err)
+
+		partSize := 5 * 1024 * 1024
+		content := make([]byte, partSize+1024)
+		_, err = rand.Read(content)
+		require.NoError(t, err)
+
+		var parts []types.CompletedPart
+		for i, chunk := range [][]byte{content[:partSize], content[partSize:]} {
+			partResult, err := client.UploadPart(ctx, &s3.UploadPartInput{
+				Bucket:            aws.String(bucketName),
+				Key:               aws.String("multipart.bin"),
+				UploadId:          createResult.UploadId,
+				PartNumber:        aws.Int32(int32(i + 1)),
+				Body:              bytes.NewReader(chunk),
+				ChecksumAlgorithm: types.ChecksumAlgorithmCrc32c,
+			})
+			require.NoError(t, err)
+			require.NotNil(t, partResult.ChecksumCRC32C)
+			parts = append(parts, types.CompletedPart{
+				PartNumber:     aws.Int32(int32(i + 1)),
+				ETag:           partResult.ETag,
+				ChecksumCRC32C: partResult.ChecksumCRC32C,
+			})
+		}
+
+		completeResult, err := client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+			Bucket:          aws.String(bucketName),
+			Key:             aws.String("multipart.bin"),
+			UploadId:        createResult.UploadId,
+			MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+		})
+		require.NoError(t, err)
+		require.NotNil(t, completeResult.ChecksumCRC32C)
+		require.Regexp(t, `-2$`, *completeResult.ChecksumCRC32C)
+
+		headResult, err := client.HeadObject(ctx, &s3.HeadObjectInput{
+			Bucket:       aws.String(bucketName),
+			Key:          aws.String("multipart.bin"),
+			ChecksumMode: types.ChecksumModeEnabled,
+		})
+		require.NoError(t, err)
+		require.Equal(t, *completeResult.ChecksumCRC32C, aws.ToString(headResult.ChecksumCRC32C))
+	})
+}
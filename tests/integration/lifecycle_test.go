@@ -0,0 +1,113 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBucketLifecycleConfiguration tests the lifecycle configuration round trip.
+func TestBucketLifecycleConfiguration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	cfg := getTestConfig()
+	client := newS3Client(t, cfg)
+	ctx := context.Background()
+
+	bucketName := "test-lifecycle-" + time.Now().Format("20060102150405")
+
+	_, err := client.CreateBucket(ctx, &s3.CreateBucketInput{
+		Bucket: aws.String(bucketName),
+	})
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_, _ = client.DeleteBucket(ctx, &s3.DeleteBucketInput{
+			Bucket: aws.String(bucketName),
+		})
+	})
+
+	requireNoSuchConfig := func(t *testing.T) {
+		t.Helper()
+		_, err := client.GetBucketLifecycleConfiguration(ctx, &s3.GetBucketLifecycleConfigurationInput{
+			Bucket: aws.String(bucketName),
+		})
+		require.Error(t, err)
+		var apiErr smithy.APIError
+		require.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, "NoSuchLifecycleConfiguration", apiErr.ErrorCode())
+	}
+
+	t.Run("GetWithoutConfiguration", func(t *testing.T) {
+		requireNoSuchConfig(t)
+	})
+
+	expiry := time.Now().UTC().AddDate(1, 0, 0).Truncate(24 * time.Hour)
+
+	t.Run("PutAndGet", func(t *testing.T) {
+		_, err := client.PutBucketLifecycleConfiguration(ctx, &s3.PutBucketLifecycleConfigurationInput{
+			Bucket: aws.String(bucketName),
+			LifecycleConfiguration: &types.BucketLifecycleConfiguration{
+				Rules: []types.LifecycleRule{
+					{
+						ID:         aws.String("expire-logs"),
+						Filter:     &types.LifecycleRuleFilter{Prefix: aws.String("logs/")},
+						Status:     types.ExpirationStatusEnabled,
+						Expiration: &types.LifecycleExpiration{Days: aws.Int32(30)},
+					},
+					{
+						ID:         aws.String("expire-tmp"),
+						Filter:     &types.LifecycleRuleFilter{Prefix: aws.String("tmp/")},
+						Status:     types.ExpirationStatusDisabled,
+						Expiration: &types.LifecycleExpiration{Date: aws.Time(expiry)},
+					},
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		result, err := client.GetBucketLifecycleConfiguration(ctx, &s3.GetBucketLifecycleConfigurationInput{
+			Bucket: aws.String(bucketName),
+		})
+		require.NoError(t, err)
+		require.Len(t, result.Rules, 2)
+
+		rules := make(map[string]types.LifecycleRule, len(result.Rules))
+		for _, rule := range result.Rules {
+			rules[aws.ToString(rule.ID)] = rule
+		}
+
+		logs := rules["expire-logs"]
+		require.NotNil(t, logs.Filter)
+		assert.Equal(t, "logs/", aws.ToString(logs.Filter.Prefix))
+		assert.Equal(t, types.ExpirationStatusEnabled, logs.Status)
+		require.NotNil(t, logs.Expiration)
+		assert.Equal(t, int32(30), aws.ToInt32(logs.Expiration.Days))
+
+		tmp := rules["expire-tmp"]
+		require.NotNil(t, tmp.Filter)
+		assert.Equal(t, "tmp/", aws.ToString(tmp.Filter.Prefix))
+		assert.Equal(t, types.ExpirationStatusDisabled, tmp.Status)
+		require.NotNil(t, tmp.Expiration)
+		assert.True(t, expiry.Equal(aws.ToTime(tmp.Expiration.Date)))
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		_, err := client.DeleteBucketLifecycle(ctx, &s3.DeleteBucketLifecycleInput{
+			Bucket: aws.String(bucketName),
+		})
+		require.NoError(t, err)
+
+		requireNoSuchConfig(t)
+	})
+}
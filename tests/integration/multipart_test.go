@@ -132,6 +132,82 @@ func TestMultipartUpload(t *testing.T) {
 		require.Equal(t, data, downloaded)
 	})
 
+	t.Run("UploadPartCopy", func(t *testing.T) {
+		sourceKey := "multipart-copy-source.bin"
+		objectKey := "multipart-copy.bin"
+		partSize := 5 * 1024 * 1024
+
+		part1 := make([]byte, partSize)
+		_, err := rand.Read(part1)
+		require.NoError(t, err)
+
+		source := make([]byte, 2*1024*1024)
+		_, err = rand.Read(source)
+		require.NoError(t, err)
+
+		_, err = client.PutObject(ctx, &s3.PutObjectInput{
+			Bucket: aws.String(bucketName),
+			Key:    aws.String(sourceKey),
+			Body:   bytes.NewReader(source),
+		})
+		require.NoError(t, err)
+
+		initResult, err := client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+			Bucket: aws.String(bucketName),
+			Key:    aws.String(objectKey),
+		})
+		require.NoError(t, err)
+		uploadID := initResult.UploadId
+
+		// Part 1 is uploaded directly
+		uploadResult, err := client.UploadPart(ctx, &s3.UploadPartInput{
+			Bucket:     aws.String(bucketName),
+			Key:        aws.String(objectKey),
+			UploadId:   uploadID,
+			PartNumber: aws.Int32(1),
+			Body:       bytes.NewReader(part1),
+		})
+		require.NoError(t, err)
+
+		// Part 2 is copied from a range of the source object
+		copyResult, err := client.UploadPartCopy(ctx, &s3.UploadPartCopyInput{
+			Bucket:          aws.String(bucketName),
+			Key:             aws.String(objectKey),
+			UploadId:        uploadID,
+			PartNumber:      aws.Int32(2),
+			CopySource:      aws.String(bucketName + "/" + sourceKey),
+			CopySourceRange: aws.String("bytes=1024-1048575"),
+		})
+		require.NoError(t, err)
+		require.NotNil(t, copyResult.CopyPartResult)
+		require.NotEmpty(t, aws.ToString(copyResult.CopyPartResult.ETag))
+		require.NotNil(t, copyResult.CopyPartResult.LastModified)
+
+		_, err = client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+			Bucket:   aws.String(bucketName),
+			Key:      aws.String(objectKey),
+			UploadId: uploadID,
+			MultipartUpload: &types.CompletedMultipartUpload{
+				Parts: []types.CompletedPart{
+					{ETag: uploadResult.ETag, PartNumber: aws.Int32(1)},
+					{ETag: copyResult.CopyPartResult.ETag, PartNumber: aws.Int32(2)},
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		getResult, err := client.GetObject(ctx, &s3.GetObjectInput{
+			Bucket: aws.String(bucketName),
+			Key:    aws.String(objectKey),
+		})
+		require.NoError(t, err)
+		defer getResult.Body.Close()
+
+		downloaded, err := io.ReadAll(getResult.Body)
+		require.NoError(t, err)
+		require.Equal(t, append(part1, source[1024:1048576]...), downloaded)
+	})
+
 	t.Run("AbortMultipartUpload", func(t *testing.T) {
 		objectKey := "multipart-abort.bin"
 
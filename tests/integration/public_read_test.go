@@ -0,0 +1,125 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/require"
+)
+
+// newAnonymousS3Client creates an S3 client that sends unsigned requests.
+func newAnonymousS3Client(cfg TestConfig) *s3.Client {
+	return s3.New(s3.Options{
+		Region:       cfg.Region,
+		Credentials:  aws.AnonymousCredentials{},
+		UsePathStyle: true,
+		BaseEndpoint: aws.String(cfg.Endpoint),
+	})
+}
+
+// TestPublicReadBucket tests unsigned reads from a public-read bucket.
+func TestPublicReadBucket(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	cfg := getTestConfig()
+	client := newS3Client(t, cfg)
+	anonymous := newAnonymousS3Client(cfg)
+	ctx := context.Background()
+
+	bucketName := "test-public-read-" + time.Now().Format("20060102150405")
+	content := []byte("hello from a public bucket")
+
+	// ACLs are disabled by default, so the bucket opts in to them
+	_, err := client.CreateBucket(ctx, &s3.CreateBucketInput{
+		Bucket:          aws.String(bucketName),
+		ACL:             types.BucketCannedACLPublicRead,
+		ObjectOwnership: types.ObjectOwnershipObjectWriter,
+	})
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_, _ = client.DeleteObject(ctx, &s3.DeleteObjectInput{
+			Bucket: aws.String(bucketName),
+			Key:    aws.String("public.txt"),
+		})
+		_, _ = client.DeleteBucket(ctx, &s3.DeleteBucketInput{
+			Bucket: aws.String(bucketName),
+		})
+	})
+
+	_, err = client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(bucketName),
+		Key:    aws.String("public.txt"),
+		Body:   bytes.NewReader(content),
+	})
+	require.NoError(t, err)
+
+	t.Run("UnsignedGetObject", func(t *testing.T) {
+		result, err := anonymous.GetObject(ctx, &s3.GetObjectInput{
+			Bucket: aws.String(bucketName),
+			Key:    aws.String("public.txt"),
+		})
+		require.NoError(t, err)
+		defer result.Body.Close()
+
+		body, err := io.ReadAll(result.Body)
+		require.NoError(t, err)
+		require.Equal(t, content, body)
+	})
+
+	t.Run("UnsignedHeadObject", func(t *testing.T) {
+		result, err := anonymous.HeadObject(ctx, &s3.HeadObjectInput{
+			Bucket: aws.String(bucketName),
+			Key:    aws.String("public.txt"),
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(len(content)), aws.ToInt64(result.ContentLength))
+	})
+
+	t.Run("PlainHTTPGet", func(t *testing.T) {
+		resp, err := http.Get(cfg.Endpoint + "/" + bucketName + "/public.txt")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Equal(t, content, body)
+	})
+
+	t.Run("UnsignedPutObjectDenied", func(t *testing.T) {
+		_, err := anonymous.PutObject(ctx, &s3.PutObjectInput{
+			Bucket: aws.String(bucketName),
+			Key:    aws.String("anonymous.txt"),
+			Body:   bytes.NewReader([]byte("not allowed")),
+		})
+		var apiErr smithy.APIError
+		require.True(t, errors.As(err, &apiErr), "expected an API error, got %v", err)
+		require.Equal(t, "AccessDenied", apiErr.ErrorCode())
+	})
+
+	t.Run("PrivateBucketDenied", func(t *testing.T) {
+		_, err := client.PutBucketAcl(ctx, &s3.PutBucketAclInput{
+			Bucket: aws.String(bucketName),
+			ACL:    types.BucketCannedACLPrivate,
+		})
+		require.NoError(t, err)
+
+		_, err = anonymous.GetObject(ctx, &s3.GetObjectInput{
+			Bucket: aws.String(bucketName),
+			Key:    aws.String("public.txt"),
+		})
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,192 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/require"
+)
+
+// ssecKey is a customer-provided key in the encodings the SSE-C headers use.
+type ssecKey struct {
+	Key    string // base64
+	KeyMD5 string // base64
+}
+
+func newSSECKey(t *testing.T) ssecKey {
+	t.Helper()
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+
+	sum := md5.Sum(key)
+	return ssecKey{
+		Key:    base64.StdEncoding.EncodeToString(key),
+		KeyMD5: base64.StdEncoding.EncodeToString(sum[:]),
+	}
+}
+
+// TestSSECustomerKeyRoundTrip tests SSE-C object operations.
+func TestSSECustomerKeyRoundTrip(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	cfg := getTestConfig()
+	client := newS3Client(t, cfg)
+	ctx := context.Background()
+
+	bucketName := "test-ssec-" + time.Now().Format("20060102150405")
+
+	_, err := client.CreateBucket(ctx, &s3.CreateBucketInput{
+		Bucket: aws.String(bucketName),
+	})
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		listResult, _ := client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+			Bucket: aws.String(bucketName),
+		})
+		if listResult != nil {
+			for _, obj := range listResult.Contents {
+				_, _ = client.DeleteObject(ctx, &s3.DeleteObjectInput{
+					Bucket: aws.String(bucketName),
+					Key:    obj.Key,
+				})
+			}
+		}
+		_, _ = client.DeleteBucket(ctx, &s3.DeleteBucketInput{
+			Bucket: aws.String(bucketName),
+		})
+	})
+
+	key := newSSECKey(t)
+	objectKey := "sealed.bin"
+	objectContent := make([]byte, 200*1024)
+	_, err = rand.Read(objectContent)
+	require.NoError(t, err)
+
+	t.Run("PutObject", func(t *testing.T) {
+		result, err := client.PutObject(ctx, &s3.PutObjectInput{
+			Bucket:               aws.String(bucketName),
+			Key:                  aws.String(objectKey),
+			Body:                 bytes.NewReader(objectContent),
+			SSECustomerAlgorithm: aws.String("AES256"),
+			SSECustomerKey:       aws.String(key.Key),
+			SSECustomerKeyMD5:    aws.String(key.KeyMD5),
+		})
+		require.NoError(t, err)
+		require.Equal(t, "AES256", aws.ToString(result.SSECustomerAlgorithm))
+		require.Equal(t, key.KeyMD5, aws.ToString(result.SSECustomerKeyMD5))
+	})
+
+	t.Run("HeadObject", func(t *testing.T) {
+		result, err := client.HeadObject(ctx, &s3.HeadObjectInput{
+			Bucket:               aws.String(bucketName),
+			Key:                  aws.String(objectKey),
+			SSECustomerAlgorithm: aws.String("AES256"),
+			SSECustomerKey:       aws.String(key.Key),
+			SSECustomerKeyMD5:    aws.String(key.KeyMD5),
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(len(objectContent)), aws.ToInt64(result.ContentLength))
+		require.Equal(t, key.KeyMD5, aws.ToString(result.SSECustomerKeyMD5))
+	})
+
+	t.Run("GetObject", func(t *testing.T) {
+		result, err := client.GetObject(ctx, &s3.GetObjectInput{
+			Bucket:               aws.String(bucketName),
+			Key:                  aws.String(objectKey),
+			SSECustomerAlgorithm: aws.String("AES256"),
+			SSECustomerKey:       aws.String(key.Key),
+			SSECustomerKeyMD5:    aws.String(key.KeyMD5),
+		})
+		require.NoError(t, err)
+		defer result.Body.Close()
+
+		body, err := io.ReadAll(result.Body)
+		require.NoError(t, err)
+		require.Equal(t, objectContent, body)
+	})
+
+	t.Run("GetObject_Range", func(t *testing.T) {
+		result, err := client.GetObject(ctx, &s3.GetObjectInput{
+			Bucket:               aws.String(bucketName),
+			Key:                  aws.String(objectKey),
+			Range:                aws.String("bytes=65530-65629"),
+			SSECustomerAlgorithm: aws.String("AES256"),
+			SSECustomerKey:       aws.String(key.Key),
+			SSECustomerKeyMD5:    aws.String(key.KeyMD5),
+		})
+		require.NoError(t, err)
+		defer result.Body.Close()
+
+		body, err := io.ReadAll(result.Body)
+		require.NoError(t, err)
+		require.Equal(t, objectContent[65530:65630], body)
+	})
+
+	t.Run("GetObject_MissingKey", func(t *testing.T) {
+		_, err := client.GetObject(ctx, &s3.GetObjectInput{
+			Bucket: aws.String(bucketName),
+			Key:    aws.String(objectKey),
+		})
+		var apiErr smithy.APIError
+		require.True(t, errors.As(err, &apiErr))
+		require.Equal(t, "InvalidArgument", apiErr.ErrorCode())
+	})
+
+	t.Run("GetObject_WrongKey", func(t *testing.T) {
+		other := newSSECKey(t)
+		_, err := client.GetObject(ctx, &s3.GetObjectInput{
+			Bucket:               aws.String(bucketName),
+			Key:                  aws.String(objectKey),
+			SSECustomerAlgorithm: aws.String("AES256"),
+			SSECustomerKey:       aws.String(other.Key),
+			SSECustomerKeyMD5:    aws.String(other.KeyMD5),
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("CopyObject", func(t *testing.T) {
+		copyKey := "sealed-copy.bin"
+		newKey := newSSECKey(t)
+
+		_, err := client.CopyObject(ctx, &s3.CopyObjectInput{
+			Bucket:                         aws.String(bucketName),
+			Key:                            aws.String(copyKey),
+			CopySource:                     aws.String(bucketName + "/" + objectKey),
+			CopySourceSSECustomerAlgorithm: aws.String("AES256"),
+			CopySourceSSECustomerKey:       aws.String(key.Key),
+			CopySourceSSECustomerKeyMD5:    aws.String(key.KeyMD5),
+			SSECustomerAlgorithm:           aws.String("AES256"),
+			SSECustomerKey:                 aws.String(newKey.Key),
+			SSECustomerKeyMD5:              aws.String(newKey.KeyMD5),
+		})
+		require.NoError(t, err)
+
+		result, err := client.GetObject(ctx, &s3.GetObjectInput{
+			Bucket:               aws.String(bucketName),
+			Key:                  aws.String(copyKey),
+			SSECustomerAlgorithm: aws.String("AES256"),
+			SSECustomerKey:       aws.String(newKey.Key),
+			SSECustomerKeyMD5:    aws.String(newKey.KeyMD5),
+		})
+		require.NoError(t, err)
+		defer result.Body.Close()
+
+		body, err := io.ReadAll(result.Body)
+		require.NoError(t, err)
+		require.Equal(t, objectContent, body)
+	})
+}